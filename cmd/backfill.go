@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/approvalrouting"
+	routingPostgres "github.com/frahmantamala/expense-management/internal/approvalrouting/postgres"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/category"
+	categoryPostgres "github.com/frahmantamala/expense-management/internal/category/postgres"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	expensePostgres "github.com/frahmantamala/expense-management/internal/expense/postgres"
+	"github.com/frahmantamala/expense-management/internal/payment"
+	paymentPostgres "github.com/frahmantamala/expense-management/internal/payment/postgres"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/snapshot"
+	snapshotPostgres "github.com/frahmantamala/expense-management/internal/snapshot/postgres"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backfillOlderThan time.Duration
+	backfillLimit     int
+	backfillDryRun    bool
+	backfillFrom      string
+	backfillTo        string
+)
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "One-off data repair commands",
+}
+
+var backfillPaymentStatusCmd = &cobra.Command{
+	Use:   "payment-status",
+	Short: "Reconcile historical pending payments against the gateway",
+	Long: `Look up every payment still pending after --older-than at the gateway and
+settle it. Successful payments complete their expense the same way a gateway
+callback would.`,
+	Example: `  expense-management backfill payment-status --older-than 24h --dry-run
+  expense-management backfill payment-status --older-than 1h --limit 500`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := loadConfig(".")
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := installEncryption(cfg); err != nil {
+			return err
+		}
+
+		db, err := initDB(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to init db: %w", err)
+		}
+
+		log := logger.LoggerWrapper()
+
+		// Every provider is asked, since a payment may have been failed over.
+		providers, err := newGatewayProviders(cfg.Payment)
+		if err != nil {
+			return err
+		}
+		httpClients, err := newHTTPClientFactory(cfg.HTTPClient)
+		if err != nil {
+			return err
+		}
+		transport, err := newGatewayTransport(cfg.Payment, db, httpClients, log)
+		if err != nil {
+			return err
+		}
+		gateway := paymentgateway.NewClient(paymentgateway.Config{
+			Providers:      providers,
+			Transport:      transport,
+			HTTP:           httpClients,
+			WebhookURL:     cfg.Payment.WebhookURL,
+			PaymentTimeout: cfg.Payment.PaymentTimeout,
+			MinWorkers:     1,
+			MaxWorkers:     1,
+			Budgets: paymentgateway.LatencyBudgets{
+				Initiate: cfg.Payment.Budgets.Initiate,
+				Status:   cfg.Payment.Budgets.Status,
+			},
+			Hedge: paymentgateway.HedgeConfig{Delay: cfg.Payment.Hedge.Delay},
+		}, nil, log)
+		defer gateway.Shutdown()
+
+		eventBus := events.NewEventBus(log)
+		paymentRepo := paymentPostgres.NewPaymentRepository(db)
+		paymentService := payment.NewPaymentService(log, paymentRepo, gateway, nil, nil, payment.RetryPolicy{})
+		orchestrator := payment.NewPaymentOrchestrator(paymentService, log)
+
+		// Subscribes the expense status update to payment completion events.
+		categoryService := category.NewService(categoryPostgres.NewCategoryRepository(db), log)
+		routingService := approvalrouting.NewService(routingPostgres.NewRoutingRepository(db), categoryService, log)
+		expense.NewCommandService(expensePostgres.NewExpenseRepository(db), orchestrator, categoryService, routingService, newSpendingLimitService(cfg, db, log), nil, nil, nil, nil, nil, newTenantSettingsService(cfg, db, log), auth.NewPermissionChecker(), expense.DefaultStateMachine(), eventBus, log)
+
+		reconciler := payment.NewReconciler(paymentRepo, gateway, eventBus, log)
+		result, err := reconciler.Reconcile(cmd.Context(), payment.ReconcileOptions{
+			CreatedBefore: time.Now().Add(-backfillOlderThan),
+			Limit:         backfillLimit,
+			DryRun:        backfillDryRun,
+		})
+		if err != nil {
+			return err
+		}
+
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	},
+}
+
+var backfillDailyFactsCmd = &cobra.Command{
+	Use:   "daily-facts",
+	Short: "Rewrite the reporting schema's daily facts for a range of days",
+	Long: `Snapshot every day from --from to --to (UTC, both included) as the
+daily_snapshot job does, replacing the facts already written for those days.
+Facts describe expenses and payments as they are now, so rewriting an old day
+changes the trend it reports.`,
+	Example: `  expense-management backfill daily-facts --from 2025-10-01 --to 2025-10-31`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		from, err := time.Parse(time.DateOnly, backfillFrom)
+		if err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		to, err := time.Parse(time.DateOnly, backfillTo)
+		if err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+		if to.Before(from) {
+			return fmt.Errorf("--to must not be before --from")
+		}
+
+		cfg, err := loadConfig(".")
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		db, err := initDB(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to init db: %w", err)
+		}
+
+		service := snapshot.NewService(snapshotPostgres.NewSnapshotRepository(db), logger.LoggerWrapper())
+		result, err := service.Snapshot(cmd.Context(), from, to, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	},
+}
+
+func init() {
+	backfillPaymentStatusCmd.Flags().DurationVar(&backfillOlderThan, "older-than", 24*time.Hour, "only reconcile payments pending for longer than this")
+	backfillPaymentStatusCmd.Flags().IntVar(&backfillLimit, "limit", 0, "maximum number of payments to check (0 = no limit)")
+	backfillPaymentStatusCmd.Flags().BoolVar(&backfillDryRun, "dry-run", false, "report gateway statuses without updating anything")
+
+	backfillDailyFactsCmd.Flags().StringVar(&backfillFrom, "from", "", "first day to snapshot (YYYY-MM-DD)")
+	backfillDailyFactsCmd.Flags().StringVar(&backfillTo, "to", "", "last day to snapshot (YYYY-MM-DD)")
+	backfillDailyFactsCmd.MarkFlagRequired("from")
+	backfillDailyFactsCmd.MarkFlagRequired("to")
+
+	backfillCmd.AddCommand(backfillPaymentStatusCmd)
+	backfillCmd.AddCommand(backfillDailyFactsCmd)
+	rootCmd.AddCommand(backfillCmd)
+}
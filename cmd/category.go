@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/frahmantamala/expense-management/internal/category"
+	categoryPostgres "github.com/frahmantamala/expense-management/internal/category/postgres"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	categoryName   string
+	categoryParent string
+	categoryRoot   bool
+	categoryTenant string
+)
+
+var categoryCmd = &cobra.Command{
+	Use:   "category",
+	Short: "Expense category administration",
+}
+
+var categorySetParentCmd = &cobra.Command{
+	Use:   "set-parent",
+	Short: "Move a category under a parent category, or back to the top level",
+	Example: `  expense-management category set-parent --name flights --parent perjalanan
+  expense-management category set-parent --name flights --root`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if categoryParent == "" && !categoryRoot {
+			return errors.New("either --parent or --root is required")
+		}
+
+		cfg, err := loadConfig(".")
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		db, err := initDB(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to init db: %w", err)
+		}
+
+		ctx, err := tenantContext(cmd.Context(), db, categoryTenant)
+		if err != nil {
+			return err
+		}
+
+		svc := category.NewService(categoryPostgres.NewCategoryRepository(db), logger.LoggerWrapper())
+		if err := svc.SetParent(ctx, categoryName, categoryParent); err != nil {
+			return err
+		}
+
+		if categoryRoot {
+			fmt.Fprintf(cmd.OutOrStdout(), "moved %s to the top level\n", categoryName)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "moved %s under %s\n", categoryName, categoryParent)
+		}
+		return nil
+	},
+}
+
+func init() {
+	categorySetParentCmd.Flags().StringVar(&categoryName, "name", "", "category to move")
+	categorySetParentCmd.MarkFlagRequired("name")
+	categorySetParentCmd.Flags().StringVar(&categoryParent, "parent", "", "new parent category")
+	categorySetParentCmd.Flags().BoolVar(&categoryRoot, "root", false, "move the category to the top level")
+	categorySetParentCmd.MarkFlagsMutuallyExclusive("parent", "root")
+	categorySetParentCmd.Flags().StringVar(&categoryTenant, "tenant", "default", "slug of the tenant that owns the category")
+
+	categoryCmd.AddCommand(categorySetParentCmd)
+	rootCmd.AddCommand(categoryCmd)
+}
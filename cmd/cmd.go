@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/encryption"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	tenantPostgres "github.com/frahmantamala/expense-management/internal/tenant/postgres"
+	"github.com/frahmantamala/expense-management/pkg/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gorm.io/gorm"
 )
 
 var (
@@ -56,6 +62,31 @@ func loadConfig(path string) (*internal.Config, error) {
 	return &cfg, nil
 }
 
+// installEncryption makes the configured keyring available to the encrypted
+// column serializers. Without a key, encrypted columns cannot be written.
+func installEncryption(cfg *internal.Config) error {
+	if cfg.Encryption.Key == "" {
+		return nil
+	}
+	keyring, err := encryption.NewKeyring(cfg.Encryption.Key, cfg.Encryption.PreviousKeys...)
+	if err != nil {
+		return fmt.Errorf("failed to load encryption keys: %w", err)
+	}
+	encryption.Use(keyring)
+	return nil
+}
+
+// tenantContext scopes ctx to the tenant named slug, so commands that work
+// on one tenant's data cannot touch another's.
+func tenantContext(ctx context.Context, db *gorm.DB, slug string) (context.Context, error) {
+	svc := tenant.NewService(tenantPostgres.NewTenantRepository(db), logger.LoggerWrapper())
+	t, err := svc.Resolve(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", slug, err)
+	}
+	return tenant.NewContext(ctx, t.ID), nil
+}
+
 func init() {
 	seedCmd.Flags().BoolVar(&clearData, "clear", false, "Clear existing data before seeding")
 
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configPath         string
+	configCheckTimeout time.Duration
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration tools",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration and the services it points at before a deploy",
+	Long: `Load and validate the configuration, then check that the database answers,
+every payment gateway and the payment webhook URL are reachable, and the
+session secret is not a development default or too weak. Prints the result
+of each check followed by the effective configuration with secrets redacted,
+and exits non-zero when any check fails.
+
+A gateway or webhook counts as reachable when it answers HTTP at all, since
+their endpoints do not accept a bare GET.`,
+	Example: `  expense-management config validate
+  APP_ENV=production expense-management config validate --timeout 10s`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		out := cmd.OutOrStdout()
+
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		failed := 0
+		report := func(name string, err error) {
+			if err != nil {
+				failed++
+				fmt.Fprintf(out, "FAIL  %s: %v\n", name, err)
+				return
+			}
+			fmt.Fprintf(out, "ok    %s\n", name)
+		}
+		skip := func(name, reason string) {
+			fmt.Fprintf(out, "skip  %s: %s\n", name, reason)
+		}
+
+		report("config", cfg.Validate())
+		report("session secret", cfg.Security.CheckSessionSecret())
+		report("database", checkDatabase(cfg.Database))
+
+		client := &http.Client{Timeout: configCheckTimeout}
+		for _, p := range configuredProviders(cfg.Payment) {
+			name := fmt.Sprintf("payment gateway %q", p.Name)
+			switch {
+			case p.Driver == "sandbox":
+				skip(name, "sandbox driver runs in process")
+			case cfg.Payment.Recorder.Mode == "replay":
+				skip(name, "recorder replays from "+cfg.Payment.Recorder.Path)
+			default:
+				report(name, checkReachable(cmd.Context(), client, p.APIURL))
+			}
+		}
+		if cfg.Payment.WebhookURL == "" {
+			skip("payment webhook", "webhook_url is not set")
+		} else {
+			report("payment webhook", checkReachable(cmd.Context(), client, cfg.Payment.WebhookURL))
+		}
+
+		effective, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode effective config: %w", err)
+		}
+		fmt.Fprintf(out, "\neffective config:\n%s\n", effective)
+
+		if failed > 0 {
+			return fmt.Errorf("%d configuration checks failed", failed)
+		}
+		return nil
+	},
+}
+
+// configuredProviders mirrors newGatewayProviders without starting a sandbox
+// gateway.
+func configuredProviders(cfg internal.PaymentConfig) []internal.PaymentProviderConfig {
+	if len(cfg.Providers) > 0 {
+		return cfg.Providers
+	}
+	return []internal.PaymentProviderConfig{{
+		Name:   paymentgateway.DefaultProviderName,
+		Driver: cfg.Driver,
+		APIURL: cfg.MockAPIURL,
+	}}
+}
+
+func checkDatabase(cfg internal.DatabaseConfig) error {
+	db, err := initDB(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// checkReachable reports whether url answers HTTP; any status will do.
+func checkReachable(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+func init() {
+	configValidateCmd.Flags().StringVar(&configPath, "config-dir", ".", "directory holding config.yml")
+	configValidateCmd.Flags().DurationVar(&configCheckTimeout, "timeout", 5*time.Second, "timeout for each reachability check")
+
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/export"
+	exportPostgres "github.com/frahmantamala/expense-management/internal/export/postgres"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFrom   string
+	exportTo     string
+	exportFormat string
+	exportOutput string
+	exportUpload bool
+)
+
+// exportLinkExpiry is how long the link printed by --upload stays valid.
+const exportLinkExpiry = 24 * time.Hour
+
+var exportCmd = &cobra.Command{
+	Use:   "export [expenses|payments]",
+	Short: "Export expenses or payments for a date range",
+	Long: `Stream expenses (by expense_date) or payments (by created_at) for a date
+range to CSV or JSONL. Both --from and --to are inclusive calendar days in UTC.`,
+	Example: `  expense-management export expenses --from 2025-01-01 --to 2025-03-31 --format csv --output q1.csv
+  expense-management export payments --from 2025-01-01 --to 2025-01-31 --format jsonl > payments.jsonl
+  expense-management export expenses --from 2025-01-01 --to 2025-03-31 --upload`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"expenses", "payments"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := export.ParseFormat(exportFormat)
+		if err != nil {
+			return err
+		}
+
+		rng, err := export.ParseDayRange(exportFrom, exportTo)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := loadConfig(".")
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := installEncryption(cfg); err != nil {
+			return err
+		}
+
+		db, err := initDB(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to init db: %w", err)
+		}
+
+		var out io.Writer
+		var closeOut func()
+		var tmp *os.File
+		if exportUpload {
+			tmp, err = os.CreateTemp("", "export-*."+string(format))
+			if err != nil {
+				return fmt.Errorf("failed to create temporary file: %w", err)
+			}
+			defer os.Remove(tmp.Name())
+			out, closeOut = tmp, func() { tmp.Close() }
+		} else {
+			out, closeOut, err = openOutput(cmd.OutOrStdout(), exportOutput)
+			if err != nil {
+				return err
+			}
+		}
+		defer closeOut()
+
+		buffered := bufio.NewWriter(out)
+		svc := export.NewService(exportPostgres.NewExportRepository(db), logger.LoggerWrapper())
+
+		var count int
+		switch args[0] {
+		case "expenses":
+			count, err = svc.ExportExpenses(cmd.Context(), buffered, format, rng)
+		case "payments":
+			count, err = svc.ExportPayments(cmd.Context(), buffered, format, rng)
+		}
+		if err != nil {
+			return err
+		}
+		if err := buffered.Flush(); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "exported %d %s\n", count, args[0])
+
+		if exportUpload {
+			key := fmt.Sprintf("exports/%s/%s_%s_%d.%s", args[0], exportFrom, exportTo, time.Now().Unix(), format)
+			url, err := uploadExport(cmd.Context(), cfg, tmp, key, format)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), url)
+		}
+		return nil
+	},
+}
+
+func openOutput(stdout io.Writer, path string) (io.Writer, func(), error) {
+	if path == "" || path == "-" {
+		return stdout, func() {}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// uploadExport stores the finished export and returns a download link.
+func uploadExport(ctx context.Context, cfg *internal.Config, f *os.File, key string, format export.Format) (string, error) {
+	blob, err := newBlobStorage(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob storage: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	if err := blob.Put(ctx, key, f, storage.PutOptions{ContentType: format.ContentType()}); err != nil {
+		return "", fmt.Errorf("failed to upload export: %w", err)
+	}
+	return blob.SignedURL(ctx, key, exportLinkExpiry)
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "first day to include (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "last day to include (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "output format: csv or jsonl")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "output file (default stdout)")
+	exportCmd.Flags().BoolVar(&exportUpload, "upload", false, "store the export in blob storage and print a download link")
+	exportCmd.MarkFlagRequired("from")
+	exportCmd.MarkFlagRequired("to")
+	exportCmd.MarkFlagsMutuallyExclusive("upload", "output")
+
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/httpclient"
+	paymentPostgres "github.com/frahmantamala/expense-management/internal/payment/postgres"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/calllog"
+)
+
+// newGatewayDriver returns nil for the gateway driver, which leaves settlement
+// to the gateway's own callbacks.
+func newGatewayDriver(driver string, mock internal.MockGatewayConfig) (paymentgateway.Driver, error) {
+	switch driver {
+	case "", paymentgateway.DriverGateway, "sandbox":
+		return nil, nil
+	case "mock":
+		return newMockGatewayDriver(mock)
+	default:
+		return nil, fmt.Errorf("unknown payment driver %q", driver)
+	}
+}
+
+// gatewayURL points the client at an in-process sandbox gateway when the
+// sandbox driver is selected. The sandbox signs its callbacks with
+// webhookSecret.
+func gatewayURL(driver, apiURL, webhookSecret string) (string, error) {
+	if driver == "sandbox" {
+		return startSandboxGateway(webhookSecret)
+	}
+	return apiURL, nil
+}
+
+// newGatewayProviders builds the failover chain, or a single provider from
+// mock_api_url, api_key, driver and response_version when no providers are
+// configured.
+func newGatewayProviders(cfg internal.PaymentConfig) ([]paymentgateway.Provider, error) {
+	configured := cfg.Providers
+	if len(configured) == 0 {
+		configured = []internal.PaymentProviderConfig{{
+			Name:            paymentgateway.DefaultProviderName,
+			Driver:          cfg.Driver,
+			APIURL:          cfg.MockAPIURL,
+			APIKey:          cfg.APIKey,
+			ResponseVersion: cfg.ResponseVersion,
+		}}
+	}
+
+	providers := make([]paymentgateway.Provider, 0, len(configured))
+	for _, p := range configured {
+		driver, err := newGatewayDriver(p.Driver, cfg.Mock)
+		if err != nil {
+			return nil, fmt.Errorf("payment provider %q: %w", p.Name, err)
+		}
+		response, err := paymentgateway.NewResponseAdapter(p.ResponseVersion)
+		if err != nil {
+			return nil, fmt.Errorf("payment provider %q: %w", p.Name, err)
+		}
+		apiURL, err := gatewayURL(p.Driver, p.APIURL, cfg.WebhookSecret)
+		if err != nil {
+			return nil, fmt.Errorf("payment provider %q: %w", p.Name, err)
+		}
+		providers = append(providers, paymentgateway.Provider{
+			Name:     p.Name,
+			APIURL:   apiURL,
+			APIKey:   p.APIKey,
+			Driver:   driver,
+			Response: response,
+		})
+	}
+	return providers, nil
+}
+
+// newGatewayTransport returns nil, the factory's transport, unless the
+// cassette recorder or the call log is configured. The call log wraps the
+// recorder, so replayed answers are stored too.
+func newGatewayTransport(cfg internal.PaymentConfig, db *gorm.DB, factory *httpclient.Factory, logger *slog.Logger) (http.RoundTripper, error) {
+	var transport http.RoundTripper
+	if cfg.Recorder.Mode != "" {
+		recorder, err := newGatewayRecorder(cfg.Recorder, factory.Transport())
+		if err != nil {
+			return nil, err
+		}
+		transport = recorder
+	}
+	if !cfg.CallLog.Enabled {
+		return transport, nil
+	}
+	if transport == nil {
+		transport = factory.Transport()
+	}
+	return calllog.New(paymentPostgres.NewGatewayCallRepository(db), transport, cfg.CallLog.MaxBodyBytes, logger), nil
+}
+
+func newHTTPClientFactory(cfg internal.HTTPClientConfig) (*httpclient.Factory, error) {
+	factory, err := httpclient.New(httpclient.Config{
+		Timeout:               cfg.Timeout,
+		DialTimeout:           cfg.DialTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ProxyURL:              cfg.ProxyURL,
+		TLS: httpclient.TLSConfig{
+			CAFile:             cfg.TLS.CAFile,
+			MinVersion:         cfg.TLS.MinVersion,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		},
+		Retries:      cfg.Retries,
+		RetryBackoff: cfg.RetryBackoff,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("http client: %w", err)
+	}
+	return factory, nil
+}
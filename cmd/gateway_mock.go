@@ -0,0 +1,33 @@
+//go:build !production
+
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/cassette"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/mockgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/sandbox"
+)
+
+func newMockGatewayDriver(cfg internal.MockGatewayConfig) (paymentgateway.Driver, error) {
+	return mockgateway.New(mockgateway.Config{
+		Mode:        mockgateway.Mode(cfg.Mode),
+		SuccessRate: cfg.SuccessRate,
+		MinDelay:    cfg.MinDelay,
+		MaxDelay:    cfg.MaxDelay,
+	})
+}
+
+// startSandboxGateway runs for the lifetime of the process.
+func startSandboxGateway(webhookSecret string) (string, error) {
+	s := sandbox.New()
+	s.SetWebhookSecret(webhookSecret)
+	return s.URL(), nil
+}
+
+func newGatewayRecorder(cfg internal.PaymentRecorderConfig, next http.RoundTripper) (http.RoundTripper, error) {
+	return cassette.New(cassette.Mode(cfg.Mode), cfg.Path, next)
+}
@@ -0,0 +1,23 @@
+//go:build production
+
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+func newMockGatewayDriver(internal.MockGatewayConfig) (paymentgateway.Driver, error) {
+	return nil, errors.New("the mock payment driver is not available in production builds")
+}
+
+func startSandboxGateway(string) (string, error) {
+	return "", errors.New("the sandbox payment gateway is not available in production builds")
+}
+
+func newGatewayRecorder(internal.PaymentRecorderConfig, http.RoundTripper) (http.RoundTripper, error) {
+	return nil, errors.New("the payment gateway recorder is not available in production builds")
+}
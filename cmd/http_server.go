@@ -6,22 +6,84 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/approvalaction"
+	actionPostgres "github.com/frahmantamala/expense-management/internal/approvalaction/postgres"
+	"github.com/frahmantamala/expense-management/internal/approvalrouting"
+	routingPostgres "github.com/frahmantamala/expense-management/internal/approvalrouting/postgres"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	auditPostgres "github.com/frahmantamala/expense-management/internal/audit/postgres"
 	auth "github.com/frahmantamala/expense-management/internal/auth"
 	authPostgres "github.com/frahmantamala/expense-management/internal/auth/postgres"
+	"github.com/frahmantamala/expense-management/internal/bankaccount"
+	accountPostgres "github.com/frahmantamala/expense-management/internal/bankaccount/postgres"
+	"github.com/frahmantamala/expense-management/internal/calendar"
+	calendarPostgres "github.com/frahmantamala/expense-management/internal/calendar/postgres"
+	"github.com/frahmantamala/expense-management/internal/cannedresponse"
+	cannedResponsePostgres "github.com/frahmantamala/expense-management/internal/cannedresponse/postgres"
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/cardfeed"
+	cardFeedPostgres "github.com/frahmantamala/expense-management/internal/cardfeed/postgres"
 	"github.com/frahmantamala/expense-management/internal/category"
 	categoryPostgres "github.com/frahmantamala/expense-management/internal/category/postgres"
+	"github.com/frahmantamala/expense-management/internal/changefeed"
+	changefeedPostgres "github.com/frahmantamala/expense-management/internal/changefeed/postgres"
 	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/core/metrics"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+	schedulerPostgres "github.com/frahmantamala/expense-management/internal/core/scheduler/postgres"
+	"github.com/frahmantamala/expense-management/internal/core/tracing"
+	"github.com/frahmantamala/expense-management/internal/dashboard"
+	dashboardPostgres "github.com/frahmantamala/expense-management/internal/dashboard/postgres"
+	"github.com/frahmantamala/expense-management/internal/delegation"
+	delegationPostgres "github.com/frahmantamala/expense-management/internal/delegation/postgres"
+	"github.com/frahmantamala/expense-management/internal/digest"
+	digestPostgres "github.com/frahmantamala/expense-management/internal/digest/postgres"
+	"github.com/frahmantamala/expense-management/internal/exchangerate"
+	exchangeRatePostgres "github.com/frahmantamala/expense-management/internal/exchangerate/postgres"
 	"github.com/frahmantamala/expense-management/internal/expense"
 	expensePostgres "github.com/frahmantamala/expense-management/internal/expense/postgres"
+	"github.com/frahmantamala/expense-management/internal/expenseimport"
+	importPostgres "github.com/frahmantamala/expense-management/internal/expenseimport/postgres"
+	"github.com/frahmantamala/expense-management/internal/expensetemplate"
+	templatePostgres "github.com/frahmantamala/expense-management/internal/expensetemplate/postgres"
+	"github.com/frahmantamala/expense-management/internal/export"
+	exportPostgres "github.com/frahmantamala/expense-management/internal/export/postgres"
+	"github.com/frahmantamala/expense-management/internal/integration"
+	integrationPostgres "github.com/frahmantamala/expense-management/internal/integration/postgres"
+	"github.com/frahmantamala/expense-management/internal/ledger"
+	ledgerPostgres "github.com/frahmantamala/expense-management/internal/ledger/postgres"
+	"github.com/frahmantamala/expense-management/internal/notification"
 	"github.com/frahmantamala/expense-management/internal/payment"
 	paymentPostgres "github.com/frahmantamala/expense-management/internal/payment/postgres"
 	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/webhooksig"
+	"github.com/frahmantamala/expense-management/internal/periodlock"
+	periodLockPostgres "github.com/frahmantamala/expense-management/internal/periodlock/postgres"
+	"github.com/frahmantamala/expense-management/internal/privacy"
+	privacyPostgres "github.com/frahmantamala/expense-management/internal/privacy/postgres"
+	"github.com/frahmantamala/expense-management/internal/receipt"
+	receiptPostgres "github.com/frahmantamala/expense-management/internal/receipt/postgres"
+	"github.com/frahmantamala/expense-management/internal/receipt/thumbnail"
+	"github.com/frahmantamala/expense-management/internal/report"
+	reportPostgres "github.com/frahmantamala/expense-management/internal/report/postgres"
+	"github.com/frahmantamala/expense-management/internal/scim"
+	"github.com/frahmantamala/expense-management/internal/slack"
+	"github.com/frahmantamala/expense-management/internal/snapshot"
+	snapshotPostgres "github.com/frahmantamala/expense-management/internal/snapshot/postgres"
+	"github.com/frahmantamala/expense-management/internal/spendinglimit"
+	limitPostgres "github.com/frahmantamala/expense-management/internal/spendinglimit/postgres"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	tenantPostgres "github.com/frahmantamala/expense-management/internal/tenant/postgres"
 	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/frahmantamala/expense-management/internal/transport/middleware"
 	"github.com/frahmantamala/expense-management/internal/transport/rest"
 	"github.com/frahmantamala/expense-management/internal/user"
 	userPostgres "github.com/frahmantamala/expense-management/internal/user/postgres"
@@ -53,6 +115,13 @@ type Dependencies struct {
 	UserHandler    *user.Handler
 	ExpenseHandler *expense.Handler
 	PaymentHandler *payment.Handler
+	Scheduler      *scheduler.Scheduler
+	ExportWorker   *export.Worker
+	ImportWorker   *expenseimport.Worker
+	InboxWorker    *payment.InboxWorker
+	ReportWorker   *report.Worker
+	ThumbWorker    *receipt.ThumbnailWorker
+	AuditSink      *audit.Sink
 }
 
 func startHTTPServer() {
@@ -62,7 +131,31 @@ func startHTTPServer() {
 		os.Exit(1)
 	}
 
-	setupRoutes(deps)
+	if err := setupRoutes(deps); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up routes: %v\n", err)
+		os.Exit(1)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if deps.Scheduler != nil {
+		go deps.Scheduler.Run(workerCtx)
+	}
+	if deps.ExportWorker != nil {
+		go deps.ExportWorker.Run(workerCtx)
+	}
+	if deps.ImportWorker != nil {
+		go deps.ImportWorker.Run(workerCtx)
+	}
+	if deps.InboxWorker != nil {
+		go deps.InboxWorker.Run(workerCtx)
+	}
+	if deps.ReportWorker != nil {
+		go deps.ReportWorker.Run(workerCtx)
+	}
+	if deps.ThumbWorker != nil {
+		go deps.ThumbWorker.Run(workerCtx)
+	}
 
 	addr := fmt.Sprintf(":%d", deps.Config.Server.Port)
 	slog.Info("Starting HTTP server", "address", addr)
@@ -86,6 +179,7 @@ func startHTTPServer() {
 	select {
 	case sig := <-sigChan:
 		slog.Info("Received signal, shutting down...", "signal", sig)
+		stopWorkers()
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		if err := server.Shutdown(ctx); err != nil {
@@ -99,6 +193,10 @@ func startHTTPServer() {
 		} else {
 			slog.Error("failed to get underlying sql DB for close", "error", err)
 		}
+
+		if err := deps.AuditSink.Close(); err != nil {
+			slog.Error("Audit log close error", "error", err)
+		}
 	case err := <-serverErrChan:
 		if err != nil && err != http.ErrServerClosed {
 			slog.Error("Server failed to start", "error", err)
@@ -109,7 +207,7 @@ func startHTTPServer() {
 	slog.Info("Server stopped")
 }
 
-func setupRoutes(deps *Dependencies) {
+func setupRoutes(deps *Dependencies) error {
 	authRepo := authPostgres.NewRepository(deps.DB)
 	tokenGen := auth.NewJWTTokenGenerator(
 		deps.Config.Security.SessionSecret,
@@ -117,59 +215,728 @@ func setupRoutes(deps *Dependencies) {
 		deps.Config.Security.AccessTokenDuration,
 		deps.Config.Security.RefreshTokenDuration,
 	)
-	authService := auth.NewService(authRepo, tokenGen, deps.Config.Security.BCryptCost, deps.Logger)
-	authHandler := auth.NewHandler(authService)
+	tokenGen.Issuer = deps.Config.Security.Issuer
+	tokenGen.Audience = deps.Config.Security.Audience
+	tokenGen.ClockSkew = deps.Config.Security.ClockSkew
+	sessions, err := newSessionRecorder(deps.Config, deps.DB, deps.Logger)
+	if err != nil {
+		return err
+	}
+	authService, err := auth.NewService(authRepo, tokenGen, deps.Config.Security.BCryptCost, newLoginThrottle(deps.Config.Login), nil, sessions, deps.Logger)
+	if err != nil {
+		return err
+	}
+	authHandler := auth.NewHandler(authService, deps.Config.Security.RefreshGracePeriod)
 	deps.AuthHandler = authHandler
 
-	userRepo := userPostgres.NewRepository(deps.DB)
-	userSvc := user.NewService(userRepo)
-	userHandler := user.NewHandler(userSvc)
-	deps.UserHandler = userHandler
-
 	expenseRepo := expensePostgres.NewExpenseRepository(deps.DB)
 
 	eventBus := events.NewEventBus(deps.Logger)
 
 	paymentRepo := paymentPostgres.NewPaymentRepository(deps.DB)
+	paymentJobRepo := paymentPostgres.NewPaymentJobRepository(deps.DB)
+	paymentJobService := payment.NewJobService(deps.Logger, paymentJobRepo)
+
+	gatewayProviders, err := newGatewayProviders(deps.Config.Payment)
+	if err != nil {
+		return err
+	}
+	httpClients, err := newHTTPClientFactory(deps.Config.HTTPClient)
+	if err != nil {
+		return err
+	}
+	gatewayTransport, err := newGatewayTransport(deps.Config.Payment, deps.DB, httpClients, deps.Logger)
+	if err != nil {
+		return err
+	}
 
 	paymentGateway := paymentgateway.NewClient(
 		paymentgateway.Config{
-			MockAPIURL:     deps.Config.Payment.MockAPIURL,
-			APIKey:         deps.Config.Payment.APIKey,
-			WebhookURL:     deps.Config.Payment.WebhookURL,
-			PaymentTimeout: deps.Config.Payment.PaymentTimeout,
-			MaxWorkers:     deps.Config.Payment.MaxWorkers,
-			JobQueueSize:   deps.Config.Payment.JobQueueSize,
-			WorkerPoolSize: deps.Config.Payment.WorkerPoolSize,
+			WebhookURL:       deps.Config.Payment.WebhookURL,
+			WebhookSecret:    deps.Config.Payment.WebhookSecret,
+			PaymentTimeout:   deps.Config.Payment.PaymentTimeout,
+			MinWorkers:       deps.Config.Payment.MinWorkers,
+			MaxWorkers:       deps.Config.Payment.MaxWorkers,
+			JobQueueSize:     deps.Config.Payment.JobQueueSize,
+			WorkerPoolSize:   deps.Config.Payment.WorkerPoolSize,
+			OverflowStrategy: paymentgateway.OverflowStrategy(deps.Config.Payment.OverflowStrategy),
+			EnqueueTimeout:   deps.Config.Payment.EnqueueTimeout,
+			ScaleInterval:    deps.Config.Payment.ScaleInterval,
+			PoisonThreshold:  deps.Config.Payment.PoisonThreshold,
+			Providers:        gatewayProviders,
+			Failover: paymentgateway.FailoverConfig{
+				FailureThreshold: deps.Config.Payment.Failover.FailureThreshold,
+				OpenDuration:     deps.Config.Payment.Failover.OpenDuration,
+			},
+			Budgets: paymentgateway.LatencyBudgets{
+				Initiate: deps.Config.Payment.Budgets.Initiate,
+				Status:   deps.Config.Payment.Budgets.Status,
+			},
+			Hedge:     paymentgateway.HedgeConfig{Delay: deps.Config.Payment.Hedge.Delay},
+			Transport: gatewayTransport,
+			HTTP:      httpClients,
 		},
+		paymentJobService,
 		deps.Logger,
 	)
 
-	paymentService := payment.NewPaymentService(deps.Logger, paymentRepo, paymentGateway)
+	bankAccountService := newBankAccountService(deps.Config, deps.DB, deps.Logger)
+	// Assigned only when enabled so the interfaces stay nil rather than
+	// holding a nil *bankaccount.Service.
+	var payouts payment.PayoutResolver
+	var payoutAccounts expense.PayoutAccountChecker
+	if bankAccountService != nil {
+		payouts = bankAccountService
+		payoutAccounts = bankAccountService
+	}
+
+	// Assigned only when configured so the interface stays nil.
+	var shadowGateway payment.ShadowGateway
+	if shadow := deps.Config.Payment.Shadow; shadow.APIURL != "" {
+		response, err := paymentgateway.NewResponseAdapter(shadow.ResponseVersion)
+		if err != nil {
+			return fmt.Errorf("shadow gateway: %w", err)
+		}
+		shadowGateway = paymentgateway.NewShadow(paymentgateway.ShadowConfig{
+			Name:       shadow.Name,
+			APIURL:     shadow.APIURL,
+			APIKey:     shadow.APIKey,
+			WebhookURL: shadow.WebhookURL,
+			Timeout:    deps.Config.Payment.PaymentTimeout,
+			HTTP:       httpClients,
+			Response:   response,
+		}, deps.Logger)
+	}
+
+	var retryPolicy payment.RetryPolicy
+	if retryCfg := deps.Config.Scheduler.Retry; retryCfg.Enabled {
+		retryPolicy = payment.RetryPolicy{MaxRetries: retryCfg.MaxRetries, Backoff: retryCfg.Backoff}
+	}
+	paymentService := payment.NewPaymentService(deps.Logger, paymentRepo, paymentGateway, payouts, shadowGateway, retryPolicy)
 	paymentOrchestrator := payment.NewPaymentOrchestrator(paymentService, deps.Logger)
 
 	permissionChecker := auth.NewPermissionChecker()
 
-	expenseService := expense.NewService(expenseRepo, paymentOrchestrator, permissionChecker, eventBus, deps.Logger)
+	categoryRepo := categoryPostgres.NewCategoryRepository(deps.DB)
+	categoryService := category.NewService(categoryRepo, deps.Logger)
+
+	routingRepo := routingPostgres.NewRoutingRepository(deps.DB)
+	routingService := approvalrouting.NewService(routingRepo, categoryService, deps.Logger)
+
+	limitService := newSpendingLimitService(deps.Config, deps.DB, deps.Logger)
+
+	settingsService := newTenantSettingsService(deps.Config, deps.DB, deps.Logger)
+
+	periodLockService := periodlock.NewService(periodLockPostgres.NewLockRepository(deps.DB), deps.Logger)
+
+	exchangeRateService := exchangerate.NewService(exchangeRatePostgres.NewRateRepository(deps.DB), deps.Logger)
+
+	calendarService, err := newCalendarService(deps.Config, deps.DB, deps.Logger)
+	if err != nil {
+		return err
+	}
+
+	cannedResponseService := cannedresponse.NewService(cannedResponsePostgres.NewResponseRepository(deps.DB), deps.Logger)
+
+	auditSink, err := audit.OpenSink(deps.Config.Observability.Audit, deps.Logger)
+	if err != nil {
+		return err
+	}
+	deps.AuditSink = auditSink
+	auditSink.RegisterEventHandlers(eventBus)
+
+	auditRepo := auditPostgres.NewAuditRepository(deps.DB)
+	auditService := audit.NewService(auditRepo, auditSink, deps.Logger)
+
+	delegationService := delegation.NewService(delegationPostgres.NewGrantRepository(deps.DB), auditService, deps.Logger)
+
+	expenseStates := expense.DefaultStateMachine()
+	expenseCommands := expense.NewCommandService(expenseRepo, paymentOrchestrator, categoryService, routingService, limitService, periodLockService, payoutAccounts, exchangeRateService, cannedResponseService, delegationService, settingsService, permissionChecker, expenseStates, eventBus, deps.Logger)
+	expenseQueries := expense.NewQueryService(expenseRepo, settingsService, permissionChecker, deps.Logger)
 
 	paymentEventHandler := payment.NewEventHandler(paymentOrchestrator, deps.Logger)
 	paymentEventHandler.RegisterEventHandlers(eventBus)
 
-	expenseHandler := expense.NewHandler(expenseService)
+	ledgerService := ledger.NewService(ledgerPostgres.NewEntryRepository(deps.DB), deps.Logger)
+	ledgerService.RegisterEventHandlers(eventBus)
+
+	expenseHandler := expense.NewHandler(expenseCommands, expenseQueries)
 	deps.ExpenseHandler = expenseHandler
 
-	categoryRepo := categoryPostgres.NewCategoryRepository(deps.DB)
-	categoryService := category.NewService(categoryRepo, deps.Logger)
 	baseHandler := transport.NewBaseHandler(deps.Logger)
+	tenantHandler := tenant.NewHandler(baseHandler, tenant.NewService(tenantPostgres.NewTenantRepository(deps.DB), deps.Logger))
+	settingsHandler := tenant.NewSettingsHandler(baseHandler, settingsService)
 	categoryHandler := category.NewHandler(baseHandler, categoryService)
+	routingHandler := approvalrouting.NewHandler(baseHandler, routingService, approvalrouting.NewMatrixService(routingRepo, categoryService, settingsService, deps.Logger))
+	limitHandler := spendinglimit.NewHandler(baseHandler, limitService)
+	periodLockHandler := periodlock.NewHandler(baseHandler, periodLockService)
+	exchangeRateHandler := exchangerate.NewHandler(baseHandler, exchangeRateService)
+	calendarHandler := calendar.NewHandler(baseHandler, calendarService)
+	cannedResponseHandler := cannedresponse.NewHandler(baseHandler, cannedResponseService)
+	delegationHandler := delegation.NewHandler(baseHandler, delegationService)
+	ledgerHandler := ledger.NewHandler(baseHandler, ledgerService)
+	changeFeedHandler := changefeed.NewHandler(baseHandler, changefeed.NewService(changefeedPostgres.NewChangeRepository(deps.DB), deps.Logger))
+	cardFeedHandler := newCardFeedHandler(deps.Config, deps.DB, baseHandler, deps.Logger)
+	var bankAccountHandler *bankaccount.Handler
+	if bankAccountService != nil {
+		bankAccountHandler = bankaccount.NewHandler(baseHandler, bankAccountService)
+	}
+
+	dashboardService := dashboard.NewService(dashboardPostgres.NewDashboardRepository(deps.DB), permissionChecker, paymentGateway, eventBus, deps.Logger)
+	dashboardHandler := dashboard.NewHandler(baseHandler, dashboardService)
+
+	blob, err := newBlobStorage(deps.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create blob storage: %w", err)
+	}
+	userSvc := user.NewService(userPostgres.NewRepository(deps.DB), bcryptHasher{cost: deps.Config.Security.BCryptCost}, blob, deps.Logger)
+	userHandler := user.NewHandler(userSvc)
+	deps.UserHandler = userHandler
+
+	capabilities := newCapabilityIssuer(deps.Config)
+	capabilityMiddleware := capability.NewMiddleware(baseHandler, capabilities)
+	receiptService := receipt.NewService(receiptPostgres.NewReceiptRepository(deps.DB), expenseQueries, periodLockService, blob, capabilities, deps.Logger)
+	receiptHandler := receipt.NewHandler(baseHandler, receiptService)
+	if deps.Config.Thumbnails.Enabled {
+		deps.ThumbWorker = newThumbnailWorker(deps.Config, deps.DB, blob, deps.Logger)
+	}
 
-	paymentHandler := payment.NewHandler(expenseService, paymentService, deps.Logger)
+	exportJobService, err := newExportJobService(deps.Config, deps.DB, blob, permissionChecker, capabilities, deps.Logger)
+	if err != nil {
+		return err
+	}
+	exportHandler := export.NewHandler(baseHandler, exportJobService)
+	pollInterval := deps.Config.Export.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 5 * time.Second
+	}
+	deps.ExportWorker = export.NewWorker(exportJobService, pollInterval, deps.Logger)
+
+	reportHandler, reportWorker, err := newReports(deps.Config, deps.DB, baseHandler, deps.Logger)
+	if err != nil {
+		return err
+	}
+	deps.ReportWorker = reportWorker
+
+	importHandler, importWorker := newExpenseImport(deps.Config, deps.DB, expenseCommands, categoryService, blob, baseHandler, deps.Logger)
+	deps.ImportWorker = importWorker
+
+	paymentHandler := payment.NewHandler(expenseCommands, expenseQueries, paymentService, paymentJobService, deps.Logger)
 	deps.PaymentHandler = paymentHandler
 
-	webhookHandler := payment.NewWebhookHandler(baseHandler, paymentService, eventBus, deps.Logger)
+	expenseStates.OnTransition(expense.PublishStatusChanges(eventBus))
+	expenseStates.OnTransition(expense.AuditStatusChanges(auditService))
+
+	callbackProcessor := payment.NewCallbackProcessor(paymentService, eventBus, auditService, deps.Logger)
+	var callbackQueue payment.CallbackQueue
+	// Assigned only when the inbox is enabled; inline callbacks are not
+	// stored, so there is nothing to replay.
+	var webhookReplayer payment.WebhookReplayerAPI
+	if inboxCfg := deps.Config.Payment.Inbox; inboxCfg.Enabled {
+		inbox := payment.NewCallbackInbox(paymentPostgres.NewCallbackInboxRepository(deps.DB), callbackProcessor, payment.InboxConfig{
+			MaxAttempts: inboxCfg.MaxAttempts,
+			BaseBackoff: inboxCfg.BaseBackoff,
+			MaxBackoff:  inboxCfg.MaxBackoff,
+		}, auditService, deps.Logger)
+		callbackQueue = inbox
+		webhookReplayer = inbox
+		interval := inboxCfg.PollInterval
+		if interval == 0 {
+			interval = time.Second
+		}
+		deps.InboxWorker = payment.NewInboxWorker(inbox, interval, deps.Logger)
+	}
+	webhookHandler := payment.NewWebhookHandler(baseHandler, callbackProcessor, callbackQueue, webhooksig.New(deps.Config.Payment.WebhookSecret), deps.Logger)
+	paymentAdminService := payment.NewAdminService(paymentRepo, paymentJobRepo, paymentService, eventBus, auditService, deps.Logger)
+	gatewayCallService := payment.NewGatewayCallService(paymentRepo, paymentPostgres.NewGatewayCallRepository(deps.DB), deps.Config.Payment.CallLog.Retention, deps.Logger)
+	paymentAdminHandler := payment.NewAdminHandler(paymentAdminService, gatewayCallService, webhookReplayer, deps.Logger)
+
+	approvalActionService := newApprovalActionService(deps.Config, deps.DB, userSvc, expenseCommands, auditService, deps.Logger)
+	var approvalActionHandler *approvalaction.Handler
+	if approvalActionService != nil {
+		approvalActionHandler = approvalaction.NewHandler(baseHandler, approvalActionService)
+	}
+
+	var actionLinks digest.ActionLinker
+	if deps.Config.Notification.ActionLinks.Enabled {
+		actionLinks = approvalActionService
+	}
+	digestService, err := newDigestService(deps.Config, deps.DB, expenseQueries, actionLinks, settingsService, deps.Logger)
+	if err != nil {
+		return err
+	}
+	digestHandler := digest.NewHandler(baseHandler, digestService)
+	var locker scheduler.Locker
+	if deps.Config.Scheduler.DistributedLock {
+		locker = schedulerPostgres.NewAdvisoryLocker(deps.DB)
+	}
+	deps.Scheduler = scheduler.New(locker, deps.Logger)
+	if digestCfg := deps.Config.Notification.Digest; digestCfg.Enabled {
+		weekday, _ := digestCfg.Weekday()
+		job, err := digest.Job(digestService, digestCfg.Hour, weekday)
+		if err != nil {
+			return err
+		}
+		if err := deps.Scheduler.Register(job); err != nil {
+			return err
+		}
+	}
+	if reconcileCfg := deps.Config.Scheduler.Reconcile; reconcileCfg.Enabled {
+		schedule, err := scheduler.Parse(reconcileCfg.Schedule)
+		if err != nil {
+			return err
+		}
+		reconciler := payment.NewReconciler(paymentRepo, paymentGateway, eventBus, deps.Logger)
+		if err := deps.Scheduler.Register(payment.ReconcileJob(reconciler, schedule, reconcileCfg.OlderThan, reconcileCfg.Limit)); err != nil {
+			return err
+		}
+	}
+	if retryCfg := deps.Config.Scheduler.Retry; retryCfg.Enabled {
+		schedule, err := scheduler.Parse(retryCfg.Schedule)
+		if err != nil {
+			return err
+		}
+		retrier := payment.NewRetrier(paymentRepo, paymentService, eventBus, deps.Logger)
+		if err := deps.Scheduler.Register(payment.RetryJob(retrier, schedule, retryCfg.Limit)); err != nil {
+			return err
+		}
+	}
+	if callLogCfg := deps.Config.Payment.CallLog; callLogCfg.Retention > 0 {
+		schedule, err := scheduler.Parse(callLogCfg.PurgeSchedule)
+		if err != nil {
+			return err
+		}
+		if err := deps.Scheduler.Register(payment.GatewayCallPurgeJob(gatewayCallService, schedule)); err != nil {
+			return err
+		}
+	}
+	if retentionCfg := deps.Config.Retention; retentionCfg.AuditLogs > 0 || retentionCfg.GatewayPayloads > 0 {
+		schedule, err := scheduler.Parse(retentionCfg.PurgeSchedule)
+		if err != nil {
+			return err
+		}
+		privacyService := privacy.NewService(privacyPostgres.NewRepository(deps.DB), blob, auditService, retentionCfg, deps.Logger)
+		if err := deps.Scheduler.Register(privacy.PurgeJob(privacyService, schedule)); err != nil {
+			return err
+		}
+	}
+	snapshotService := snapshot.NewService(snapshotPostgres.NewSnapshotRepository(deps.DB), deps.Logger)
+	if snapshotCfg := deps.Config.Scheduler.Snapshot; snapshotCfg.Enabled {
+		schedule, err := scheduler.Parse(snapshotCfg.Schedule)
+		if err != nil {
+			return err
+		}
+		if err := deps.Scheduler.Register(snapshot.Job(snapshotService, schedule)); err != nil {
+			return err
+		}
+	}
+	snapshotHandler := snapshot.NewHandler(baseHandler, snapshotService)
+
+	introspectionHandler := newIntrospectionHandler(deps.Config, authService, baseHandler)
+	var impersonationHandler *auth.ImpersonationHandler
+	if ttl := deps.Config.Security.ImpersonationTTL; ttl > 0 {
+		impersonationService := auth.NewImpersonationService(authRepo, tokenGen, auditService, ttl, deps.Logger)
+		impersonationHandler = auth.NewImpersonationHandler(baseHandler, impersonationService)
+	}
+	integrationHandler := newIntegrationHandler(deps.Config, deps.DB, userSvc, expenseCommands, auditService, baseHandler, deps.Logger)
+
+	templateService := expensetemplate.NewService(templatePostgres.NewTemplateRepository(deps.DB), categoryService, expenseCommands, deps.Logger)
+	templateHandler := expensetemplate.NewHandler(baseHandler, templateService)
+
+	slackHandler := newSlackHandler(deps.Config, userSvc, expenseQueries, expenseCommands, auditService, eventBus, baseHandler, deps.Logger)
+
+	scimHandler, err := newSCIMHandler(deps.Config, deps.DB, auditService, baseHandler, deps.Logger)
+	if err != nil {
+		return err
+	}
+
+	logCfg, err := newLogConfig(deps.Config.Observability.Logging)
+	if err != nil {
+		return err
+	}
 
 	sqlDBForRoutes, _ := deps.DB.DB()
-	rest.RegisterAllRoutes(deps.Router, sqlDBForRoutes, deps.AuthHandler, authService, deps.UserHandler, deps.ExpenseHandler, categoryHandler, deps.PaymentHandler, webhookHandler, deps.Logger)
+	rest.RegisterAllRoutes(deps.Router, sqlDBForRoutes, deps.AuthHandler, authService, tenantHandler, deps.UserHandler, deps.ExpenseHandler, categoryHandler, deps.PaymentHandler, webhookHandler, paymentAdminHandler, digestHandler, routingHandler, dashboardHandler, snapshotHandler, receiptHandler, exportHandler, importHandler, limitHandler, periodLockHandler, exchangeRateHandler, calendarHandler, cannedResponseHandler, ledgerHandler, changeFeedHandler, cardFeedHandler, reportHandler, approvalActionHandler, slackHandler, integrationHandler, introspectionHandler, impersonationHandler, templateHandler, bankAccountHandler, delegationHandler, settingsHandler, scimHandler, capabilityMiddleware, logCfg, middleware.DeadlineConfig{
+		Timeout:       deps.Config.Server.RequestTimeout,
+		SlowThreshold: deps.Config.Server.SlowRequestThreshold,
+		SkipPaths:     deps.Config.Server.RequestTimeoutSkipPaths,
+	}, deps.Logger)
+
+	// Local storage links point back at this server; object stores serve
+	// their own signed URLs.
+	if local, ok := blob.(*storage.Local); ok {
+		deps.Router.Handle(storage.LocalPathPrefix+"*", local.Handler())
+	}
+
+	if deps.Config.Observability.Metrics.Enabled {
+		deps.Router.Handle(deps.Config.Observability.Metrics.Path, metrics.Default().Handler())
+	}
+
+	return nil
+}
+
+func newSpendingLimitService(cfg *internal.Config, db *gorm.DB, logger *slog.Logger) *spendinglimit.Service {
+	limits := spendinglimit.Limits{
+		DailyIDR:   cfg.Limits.DailyIDR,
+		MonthlyIDR: cfg.Limits.MonthlyIDR,
+	}
+	return spendinglimit.NewService(limitPostgres.NewLimitRepository(db), limits, logger)
+}
+
+// newBankAccountService returns nil when no encryption key is configured.
+func newBankAccountService(cfg *internal.Config, db *gorm.DB, logger *slog.Logger) *bankaccount.Service {
+	if cfg.Encryption.Key == "" {
+		logger.Info("bank accounts disabled: no encryption key configured")
+		return nil
+	}
+	return bankaccount.NewService(accountPostgres.NewAccountRepository(db), logger)
+}
+
+func newDigestService(cfg *internal.Config, db *gorm.DB, expenses digest.ExpenseLister, links digest.ActionLinker, channels digest.ChannelFilter, logger *slog.Logger) (*digest.Service, error) {
+	mailer, err := newMailer(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return digest.NewService(digestPostgres.NewDigestRepository(db), expenses, mailer, links, channels, cfg.Server.BaseURL, logger), nil
+}
+
+// newSlackHandler returns nil when the Slack app is disabled.
+func newSlackHandler(cfg *internal.Config, users slack.UserLookup, expenses slack.ExpenseLister, decider slack.ExpenseDecider, audit slack.AuditRecorder, eventBus *events.EventBus, baseHandler *transport.BaseHandler, logger *slog.Logger) *slack.Handler {
+	slackCfg := cfg.Slack
+	if !slackCfg.Enabled {
+		return nil
+	}
+
+	client := slack.NewClient(slackCfg.APIURL, slackCfg.BotToken)
+	service := slack.NewService(client, users, expenses, decider, audit, slackCfg.AlertChannel, logger)
+	service.RegisterEventHandlers(eventBus)
+	return slack.NewHandler(baseHandler, service, slack.NewVerifier(slackCfg.SigningSecret))
+}
+
+// newIntegrationHandler returns nil when no integration clients are
+// configured.
+func newIntegrationHandler(cfg *internal.Config, db *gorm.DB, users integration.UserLookup, decider integration.ExpenseDecider, audit integration.AuditRecorder, baseHandler *transport.BaseHandler, logger *slog.Logger) *integration.Handler {
+	if len(cfg.Integrations.Clients) == 0 {
+		return nil
+	}
+
+	clients := make([]*integration.Client, len(cfg.Integrations.Clients))
+	for i, c := range cfg.Integrations.Clients {
+		clients[i] = &integration.Client{
+			Name:          c.Name,
+			APIKey:        c.APIKey,
+			SigningSecret: c.SigningSecret,
+			User:          c.User,
+		}
+	}
+	service := integration.NewService(integrationPostgres.NewRequestRepository(db), users, decider, audit, logger)
+	return integration.NewHandler(baseHandler, service, clients)
+}
+
+// newIntrospectionHandler returns nil when no introspection clients are
+// configured.
+func newIntrospectionHandler(cfg *internal.Config, service auth.IntrospectorAPI, baseHandler *transport.BaseHandler) *auth.IntrospectionHandler {
+	if len(cfg.Introspection.Clients) == 0 {
+		return nil
+	}
+
+	clients := make([]auth.IntrospectionClient, len(cfg.Introspection.Clients))
+	for i, c := range cfg.Introspection.Clients {
+		clients[i] = auth.IntrospectionClient{ID: c.ClientID, Secret: c.ClientSecret}
+	}
+	return auth.NewIntrospectionHandler(baseHandler, service, clients, cfg.Introspection.CacheTTL)
+}
+
+// newSCIMHandler returns nil when provisioning is disabled. The tenant is
+// resolved once at startup, so a misspelt slug fails fast.
+func newSCIMHandler(cfg *internal.Config, db *gorm.DB, audit user.AuditRecorder, baseHandler *transport.BaseHandler, logger *slog.Logger) (*scim.Handler, error) {
+	scimCfg := cfg.SCIM
+	if !scimCfg.Enabled {
+		return nil, nil
+	}
+	for group, permissions := range scimCfg.GroupPermissions {
+		for _, permission := range permissions {
+			if err := auth.ValidatePermission(permission); err != nil {
+				return nil, fmt.Errorf("scim group %q: %w", group, err)
+			}
+		}
+	}
+
+	t, err := tenant.NewService(tenantPostgres.NewTenantRepository(db), logger).Resolve(context.Background(), scimCfg.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve scim tenant %q: %w", scimCfg.Tenant, err)
+	}
+
+	users := user.NewAdminService(userPostgres.NewRepository(db), bcryptHasher{cost: cfg.Security.BCryptCost}, audit, logger)
+	service := scim.NewService(users, scimCfg.GroupPermissions, strings.TrimSuffix(cfg.Server.BaseURL, "/")+"/scim/v2", logger)
+	return scim.NewHandler(baseHandler, service, scimCfg.APIKey, t.ID), nil
+}
+
+// newLogConfig builds the access log settings. An unset success sample rate
+// keeps every request.
+func newLogConfig(cfg internal.LoggingConfig) (middleware.LogConfig, error) {
+	routes := make([]middleware.RouteRedaction, len(cfg.Redaction.Routes))
+	for i, route := range cfg.Redaction.Routes {
+		routes[i] = middleware.RouteRedaction{PathPrefix: route.PathPrefix, Allow: route.Allow, Deny: route.Deny}
+	}
+	redactor, err := middleware.NewRedactor(middleware.RedactionPolicy{
+		Fields:             cfg.Redaction.Fields,
+		MaskEmails:         cfg.Redaction.MaskEmails,
+		MaskAccountNumbers: cfg.Redaction.MaskAccountNumbers,
+		Patterns:           cfg.Redaction.Patterns,
+		Routes:             routes,
+	})
+	if err != nil {
+		return middleware.LogConfig{}, err
+	}
+
+	successRate := cfg.SuccessSampleRate
+	if successRate == 0 {
+		successRate = 1
+	}
+
+	return middleware.LogConfig{
+		Body: middleware.BodyLogConfig{
+			Enabled:      cfg.Body.Enabled,
+			MaxBytes:     cfg.Body.MaxBytes,
+			ContentTypes: cfg.Body.ContentTypes,
+			SampleRate:   cfg.Body.SampleRate,
+			SkipPaths:    cfg.Body.SkipPaths,
+		},
+		Redactor:          redactor,
+		SuccessSampleRate: successRate,
+	}, nil
+}
+
+// newLoginThrottle returns nil, leaving logins unthrottled, when neither the
+// CAPTCHA step nor the lockout is configured.
+func newLoginThrottle(cfg internal.LoginConfig) *auth.LoginThrottle {
+	if cfg.CaptchaAfter == 0 && cfg.LockAfter == 0 {
+		return nil
+	}
+	var verifier auth.CaptchaVerifier
+	switch cfg.Captcha.Provider {
+	case "hcaptcha":
+		verifier = auth.NewHCaptchaVerifier(cfg.Captcha.Secret)
+	case "turnstile":
+		verifier = auth.NewTurnstileVerifier(cfg.Captcha.Secret)
+	}
+	return auth.NewLoginThrottle(cfg.Window, cfg.CaptchaAfter, cfg.LockAfter, verifier)
+}
+
+// newSessionRecorder records every login; locations are looked up only when
+// login.geo has a URL.
+func newSessionRecorder(cfg *internal.Config, db *gorm.DB, logger *slog.Logger) (*auth.SessionRecorder, error) {
+	var geo auth.GeoResolver
+	if cfg.Login.Geo.URL != "" {
+		timeout := cfg.Login.Geo.Timeout
+		if timeout == 0 {
+			timeout = 2 * time.Second
+		}
+		geo = auth.NewHTTPGeoResolver(cfg.Login.Geo.URL, timeout)
+	}
+
+	var alerter auth.LoginAlerter
+	if cfg.Login.NewLoginAlerts {
+		mailer, err := newMailer(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		alerter = auth.NewMailLoginAlerter(mailer)
+	}
+	return auth.NewSessionRecorder(authPostgres.NewSessionRepository(db), geo, alerter, logger), nil
+}
+
+// newTenantSettingsService fills in defaults for config files written before
+// tenant settings existed.
+func newTenantSettingsService(cfg *internal.Config, db *gorm.DB, logger *slog.Logger) *tenant.SettingsService {
+	tenantCfg := cfg.Tenants
+	defaults := tenant.Settings{
+		AutoApprovalThresholdIDR: expense.AutoApprovalThreshold,
+		Currency:                 tenantCfg.Currency,
+		ApprovalChain:            tenantCfg.ApprovalChain,
+		NotificationChannels:     tenantCfg.NotificationChannels,
+		ReceiptRequiredAboveIDR:  tenantCfg.ReceiptRequiredAboveIDR,
+		MaxPendingExpenses:       tenantCfg.MaxPendingExpenses,
+		MinExpenseAmountIDR:      tenantCfg.MinExpenseAmountIDR,
+		MaxExpenseAmountIDR:      tenantCfg.MaxExpenseAmountIDR,
+	}
+	if tenantCfg.AutoApprovalThresholdIDR != nil {
+		defaults.AutoApprovalThresholdIDR = *tenantCfg.AutoApprovalThresholdIDR
+	}
+	if defaults.MinExpenseAmountIDR == 0 {
+		defaults.MinExpenseAmountIDR = expense.MinExpenseAmount
+	}
+	if defaults.MaxExpenseAmountIDR == 0 {
+		defaults.MaxExpenseAmountIDR = expense.MaxExpenseAmount
+	}
+	if defaults.Currency == "" {
+		defaults.Currency = "IDR"
+	}
+	if defaults.NotificationChannels == nil {
+		defaults.NotificationChannels = []string{tenant.ChannelEmail}
+	}
+	ttl := tenantCfg.CacheTTL
+	if ttl == 0 {
+		ttl = time.Minute
+	}
+	return tenant.NewSettingsService(tenantPostgres.NewSettingsRepository(db), defaults, ttl, logger)
+}
+
+// newApprovalActionService builds the service whenever a signing key is set,
+// so links already sent keep working after action_links is turned off. It
+// returns nil without a key: no link can have been signed.
+func newApprovalActionService(cfg *internal.Config, db *gorm.DB, users approvalaction.UserLookup, expenses approvalaction.ExpenseDecider, audit approvalaction.AuditRecorder, logger *slog.Logger) *approvalaction.Service {
+	linkCfg := cfg.Notification.ActionLinks
+	signingKey := linkCfg.SigningKey
+	if signingKey == "" {
+		return nil
+	}
+	ttl := linkCfg.TTL
+	if ttl == 0 {
+		ttl = 72 * time.Hour
+	}
+	return approvalaction.NewService(actionPostgres.NewTokenRepository(db), approvalaction.NewSigner(signingKey), users, expenses, audit, cfg.Server.BaseURL, ttl, logger)
+}
+
+func newMailer(cfg *internal.Config, logger *slog.Logger) (notification.Mailer, error) {
+	mailerCfg := cfg.Notification.Mailer
+	mailer, err := notification.NewMailer(notification.MailerConfig{
+		Driver:   mailerCfg.Driver,
+		Host:     mailerCfg.Host,
+		Port:     mailerCfg.Port,
+		Username: mailerCfg.Username,
+		Password: mailerCfg.Password,
+		From:     mailerCfg.From,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mailer: %w", err)
+	}
+	return mailer, nil
+}
+
+// newExportJobService fills in defaults for config files written before
+// background exports existed.
+func newExportJobService(cfg *internal.Config, db *gorm.DB, blob storage.Blob, permissionChecker auth.PermissionChecker, links *capability.Issuer, logger *slog.Logger) (*export.JobService, error) {
+	mailer, err := newMailer(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	jobCfg := export.JobConfig{
+		BaseURL:    cfg.Server.BaseURL,
+		Retention:  cfg.Export.Retention,
+		LinkExpiry: cfg.Export.LinkExpiry,
+	}
+	if jobCfg.Retention == 0 {
+		jobCfg.Retention = 7 * 24 * time.Hour
+	}
+	if jobCfg.LinkExpiry == 0 {
+		jobCfg.LinkExpiry = time.Hour
+	}
+
+	exporter := export.NewService(exportPostgres.NewExportRepository(db), logger)
+	return export.NewJobService(exportPostgres.NewJobRepository(db), exporter, blob, mailer, permissionChecker, links, jobCfg, logger), nil
+}
+
+// newCapabilityIssuer signs shared receipt and export links with the
+// session secret unless shared_links has its own key.
+func newCapabilityIssuer(cfg *internal.Config) *capability.Issuer {
+	linkCfg := cfg.SharedLinks
+	signingKey := linkCfg.SigningKey
+	if signingKey == "" {
+		signingKey = cfg.Security.SessionSecret
+	}
+	ttl := linkCfg.TTL
+	if ttl == 0 {
+		ttl = 15 * time.Minute
+	}
+	return capability.NewIssuer(signingKey, cfg.Server.BaseURL, ttl)
+}
+
+func newCalendarService(cfg *internal.Config, db *gorm.DB, logger *slog.Logger) (*calendar.Service, error) {
+	loc, err := time.LoadLocation(cfg.Calendar.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load calendar time zone: %w", err)
+	}
+	weekend := make([]time.Weekday, len(cfg.Calendar.Weekend))
+	for i, name := range cfg.Calendar.Weekend {
+		if weekend[i], err = calendar.ParseWeekday(name); err != nil {
+			return nil, err
+		}
+	}
+	return calendar.NewService(calendarPostgres.NewDayRepository(db), loc, weekend, cfg.Calendar.NationalHolidays, logger), nil
+}
+
+func newReports(cfg *internal.Config, db *gorm.DB, baseHandler *transport.BaseHandler, logger *slog.Logger) (*report.Handler, *report.Worker, error) {
+	mailer, err := newMailer(cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pollInterval := cfg.Reports.PollInterval
+	if pollInterval == 0 {
+		pollInterval = time.Minute
+	}
+
+	service := report.NewService(reportPostgres.NewScheduleRepository(db), mailer, cfg.Reports.ApprovalSLA, logger)
+	return report.NewHandler(baseHandler, service), report.NewWorker(service, pollInterval, logger), nil
+}
+
+// newExpenseImport fills in defaults for config files written before CSV
+// imports existed.
+func newExpenseImport(cfg *internal.Config, db *gorm.DB, expenses expenseimport.ExpenseCreator, categories expense.CategoryValidator, blob storage.Blob, baseHandler *transport.BaseHandler, logger *slog.Logger) (*expenseimport.Handler, *expenseimport.Worker) {
+	importCfg := cfg.Import
+	if importCfg.PollInterval == 0 {
+		importCfg.PollInterval = 5 * time.Second
+	}
+	if importCfg.SyncMaxBytes == 0 {
+		importCfg.SyncMaxBytes = 256 << 10
+	}
+	if importCfg.MaxFileBytes == 0 {
+		importCfg.MaxFileBytes = 50 << 20
+	}
+
+	service := expenseimport.NewService(importPostgres.NewJobRepository(db), expenses, categories, blob, expenseimport.Config{SyncMaxBytes: importCfg.SyncMaxBytes}, logger)
+	return expenseimport.NewHandler(baseHandler, service, importCfg.MaxFileBytes), expenseimport.NewWorker(service, importCfg.PollInterval, logger)
+}
+
+// newThumbnailWorker fills in defaults for config files written before
+// receipt previews existed. PDFs are left without a preview when the PDF
+// renderer is not installed.
+func newThumbnailWorker(cfg *internal.Config, db *gorm.DB, blob storage.Blob, logger *slog.Logger) *receipt.ThumbnailWorker {
+	thumbCfg := cfg.Thumbnails
+	if thumbCfg.PollInterval == 0 {
+		thumbCfg.PollInterval = 10 * time.Second
+	}
+
+	var pdf thumbnail.PDFRenderer
+	if thumbCfg.PDFRenderer != "" {
+		if path, err := exec.LookPath(thumbCfg.PDFRenderer); err != nil {
+			logger.Warn("PDF renderer not found, PDF receipts get no preview", "pdf_renderer", thumbCfg.PDFRenderer, "error", err)
+		} else {
+			pdf = thumbnail.Pdftoppm{Path: path}
+		}
+	}
+
+	service := receipt.NewThumbnailService(receiptPostgres.NewThumbnailRepository(db), blob, thumbnail.New(thumbCfg.Size, pdf), logger)
+	return receipt.NewThumbnailWorker(service, thumbCfg.PollInterval, logger)
+}
+
+// newCardFeedHandler fills in defaults for config files written before card
+// feeds existed.
+func newCardFeedHandler(cfg *internal.Config, db *gorm.DB, baseHandler *transport.BaseHandler, logger *slog.Logger) *cardfeed.Handler {
+	feedCfg := cfg.CardFeed
+	if feedCfg.MaxFileBytes == 0 {
+		feedCfg.MaxFileBytes = 10 << 20
+	}
+	if feedCfg.MatchWindowDays == 0 {
+		feedCfg.MatchWindowDays = 3
+	}
+
+	service := cardfeed.NewService(cardFeedPostgres.NewTransactionRepository(db), cardfeed.Config{MatchWindowDays: feedCfg.MatchWindowDays}, logger)
+	return cardfeed.NewHandler(baseHandler, service, feedCfg.MaxFileBytes)
 }
 
 func initializeDependencies() (*Dependencies, error) {
@@ -200,6 +967,10 @@ func initializeDependencies() (*Dependencies, error) {
 
 	slog.Info("Configuration validated successfully")
 
+	if err := installEncryption(config); err != nil {
+		return nil, err
+	}
+
 	db, err := initDB(config.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
@@ -251,6 +1022,12 @@ func initDB(cfg internal.DatabaseConfig) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open gorm db: %w", err)
 	}
+	if err := gormDB.Use(tenant.Scoping{}); err != nil {
+		return nil, fmt.Errorf("failed to install tenant scoping: %w", err)
+	}
+	if err := gormDB.Use(tracing.GormSpans{}); err != nil {
+		return nil, fmt.Errorf("failed to install query tracing: %w", err)
+	}
 
 	sqlDB, err := gormDB.DB()
 	if err != nil {
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	auditPostgres "github.com/frahmantamala/expense-management/internal/audit/postgres"
+	"github.com/frahmantamala/expense-management/internal/privacy"
+	privacyPostgres "github.com/frahmantamala/expense-management/internal/privacy/postgres"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var privacyUserID int64
+
+var privacyCmd = &cobra.Command{
+	Use:   "privacy",
+	Short: "Personal data erasure and retention",
+	Long:  `Erase the personal data of employees who have left, and purge records past their retention.`,
+}
+
+var privacyEraseUserCmd = &cobra.Command{
+	Use:   "erase-user",
+	Short: "Anonymize a departed employee's personal data",
+	Long: `Anonymize a deactivated user's name, email, bank accounts, receipts, avatar,
+imported and exported files, corporate card details, sign-in history and
+payment gateway payloads. Their expenses and payments keep their amounts,
+categories and dates, so reports and the ledger still add up.
+
+Erasure cannot be undone. It is refused while the user is active or has
+expenses awaiting approval or payment.`,
+	Example: `  expense-management user deactivate --email jane@mail.com
+  expense-management privacy erase-user --id 42`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		svc, err := newPrivacyService()
+		if err != nil {
+			return err
+		}
+
+		erasure, err := svc.EraseUser(cmd.Context(), privacyUserID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "erased user %d: %d bank accounts, %d receipts, %d files, %d card transactions, %d gateway payloads, %d login sessions\n",
+			erasure.UserID, erasure.BankAccounts, erasure.Receipts, erasure.Files, erasure.CardTransactions, erasure.GatewayPayloads, erasure.LoginSessions)
+		return nil
+	},
+}
+
+var privacyPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Purge records past their retention now",
+	Long: `Purge audit log entries and payment gateway payloads older than the
+retention.audit_logs and retention.gateway_payloads periods, as the scheduled
+retention_purge job does.`,
+	Example: `  RETENTION_AUDIT_LOGS=17520h expense-management privacy purge`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		svc, err := newPrivacyService()
+		if err != nil {
+			return err
+		}
+
+		purge, err := svc.Purge(cmd.Context(), time.Now())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "purged %d audit log entries and %d gateway payloads\n", purge.AuditLogs, purge.GatewayPayloads)
+		return nil
+	},
+}
+
+func newPrivacyService() (*privacy.Service, error) {
+	cfg, err := loadConfig(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := initDB(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init db: %w", err)
+	}
+
+	blob, err := newBlobStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init storage: %w", err)
+	}
+
+	auditSink, err := audit.OpenSink(cfg.Observability.Audit, logger.LoggerWrapper())
+	if err != nil {
+		return nil, err
+	}
+	auditService := audit.NewService(auditPostgres.NewAuditRepository(db), auditSink, logger.LoggerWrapper())
+
+	return privacy.NewService(privacyPostgres.NewRepository(db), blob, auditService, cfg.Retention, logger.LoggerWrapper()), nil
+}
+
+func init() {
+	privacyEraseUserCmd.Flags().Int64Var(&privacyUserID, "id", 0, "ID of the user to erase")
+	privacyEraseUserCmd.MarkFlagRequired("id")
+
+	privacyCmd.AddCommand(privacyEraseUserCmd, privacyPurgeCmd)
+	rootCmd.AddCommand(privacyCmd)
+}
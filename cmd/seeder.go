@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/tenant"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 var seedCmd = &cobra.Command{
@@ -59,25 +62,14 @@ var seedCmd = &cobra.Command{
 			fmt.Println("Seeded admin user:", adminEmail)
 		}
 
-		permissions := []struct {
-			Name string
-			Desc string
-		}{
-			{"admin", "full administrator"},
-			{"approve_expenses", "Can approve expenses"},
-			{"view_expenses", "Can view expenses"},
-			{"reject_expenses", "Can reject expenses"},
-			{"create_expenses", "Can create expenses"},
-			{"edit_expenses", "Can edit expenses"},
-			{"retry_payments", "Can retry payments"},
-		}
+		permissions := auth.Permissions()
 
 		for _, p := range permissions {
 			var pid int64
 			row := db.Raw("SELECT id FROM permissions WHERE name = ?", p.Name).Row()
 			if err := row.Scan(&pid); err != nil {
 
-				if err := db.Exec("INSERT INTO permissions (name, description, created_at) VALUES (?, ?, now())", p.Name, p.Desc).Error; err != nil {
+				if err := db.Exec("INSERT INTO permissions (name, description, created_at) VALUES (?, ?, now())", p.Name, p.Description).Error; err != nil {
 					log.Fatalf("failed to insert permission %s: %v", p.Name, err)
 				}
 			}
@@ -111,24 +103,29 @@ var seedCmd = &cobra.Command{
 			log.Fatalf("failed to lookup fadhil user id: %v", err)
 		}
 
-		fadhilUserPermissions := []string{"view_expenses", "create_expenses"}
-		for _, permName := range fadhilUserPermissions {
-			var pid int64
-			if err := db.Raw("SELECT id FROM permissions WHERE name = ?", permName).Row().Scan(&pid); err != nil {
-				log.Fatalf("permission not found %s: %v", permName, err)
-			}
+		grantSeedPermissions(db, fadhilUserID, "fadhil", auth.PermViewExpenses, auth.PermCreateExpenses)
 
-			var exists int
-			if err := db.Raw("SELECT 1 FROM user_permissions WHERE user_id = ? AND permission_id = ?", fadhilUserID, pid).Row().Scan(&exists); err == nil {
-				continue
-			}
+		fmt.Println("Granted limited permissions to fadhil user (can only create expenses):", fadhilEmail)
 
-			if err := db.Exec("INSERT INTO user_permissions (user_id, permission_id, granted_by, created_at) VALUES (?, ?, NULL, now())", fadhilUserID, pid).Error; err != nil {
-				log.Fatalf("failed to grant permission %s to fadhil user: %v", permName, err)
+		financeEmail := "finance@mail.com"
+		financeName := "Finance Viewer"
+		if err := db.Raw("SELECT 1 FROM users WHERE email = ?", financeEmail).Row().Scan(&exists); err == nil {
+			fmt.Println("finance user already exists; will ensure permissions")
+		} else {
+			if err := db.Exec("INSERT INTO users (email, name, password_hash, is_active, created_at, updated_at) VALUES (?, ?, ?, true, now(), now())", financeEmail, financeName, string(hash)).Error; err != nil {
+				log.Fatalf("failed to insert finance user: %v", err)
 			}
+			fmt.Println("Seeded finance user:", financeEmail)
 		}
 
-		fmt.Println("Granted limited permissions to fadhil user (can only create expenses):", fadhilEmail)
+		var financeUserID int64
+		if err := db.Raw("SELECT id FROM users WHERE email = ?", financeEmail).Row().Scan(&financeUserID); err != nil {
+			log.Fatalf("failed to lookup finance user id: %v", err)
+		}
+
+		grantSeedPermissions(db, financeUserID, "finance", auth.PermViewExpenses, auth.PermFinanceViewer)
+
+		fmt.Println("Granted read-only finance permissions to finance user (can view all expenses and payments):", financeEmail)
 
 		categories := []struct {
 			Name string
@@ -143,7 +140,7 @@ var seedCmd = &cobra.Command{
 
 		for _, c := range categories {
 			var exists int
-			row := db.Raw("SELECT 1 FROM expense_categories WHERE name = ?", c.Name).Row()
+			row := db.Raw("SELECT 1 FROM expense_categories WHERE tenant_id = ? AND name = ?", tenant.DefaultID, c.Name).Row()
 			if err := row.Scan(&exists); err != nil {
 
 				if err := db.Exec("INSERT INTO expense_categories (name, description, is_active, created_at) VALUES (?, ?, true, now())", c.Name, c.Desc).Error; err != nil {
@@ -156,3 +153,23 @@ var seedCmd = &cobra.Command{
 		fmt.Println("Expense categories seeded successfully")
 	},
 }
+
+// grantSeedPermissions grants permissions to a seeded user unless they
+// already hold them.
+func grantSeedPermissions(db *gorm.DB, userID int64, label string, permissions ...auth.Permission) {
+	for _, permName := range permissions {
+		var pid int64
+		if err := db.Raw("SELECT id FROM permissions WHERE name = ?", permName).Row().Scan(&pid); err != nil {
+			log.Fatalf("permission not found %s: %v", permName, err)
+		}
+
+		var exists int
+		if err := db.Raw("SELECT 1 FROM user_permissions WHERE user_id = ? AND permission_id = ?", userID, pid).Row().Scan(&exists); err == nil {
+			continue
+		}
+
+		if err := db.Exec("INSERT INTO user_permissions (user_id, permission_id, granted_by, created_at) VALUES (?, ?, NULL, now())", userID, pid).Error; err != nil {
+			log.Fatalf("failed to grant permission %s to %s user: %v", permName, label, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/storage"
+)
+
+// newBlobStorage builds the configured store. Local download links are served
+// by this server, so they are rooted at the server's base URL.
+func newBlobStorage(cfg *internal.Config) (storage.Blob, error) {
+	signingKey := cfg.Storage.SigningKey
+	if signingKey == "" {
+		signingKey = cfg.Security.SessionSecret
+	}
+
+	return storage.NewBlob(storage.Config{
+		Driver:          cfg.Storage.Driver,
+		Root:            cfg.Storage.LocalRoot,
+		BaseURL:         cfg.Server.BaseURL,
+		SigningKey:      signingKey,
+		Bucket:          cfg.Storage.Bucket,
+		Region:          cfg.Storage.Region,
+		Endpoint:        cfg.Storage.Endpoint,
+		AccessKeyID:     cfg.Storage.AccessKeyID,
+		SecretAccessKey: cfg.Storage.SecretAccessKey,
+		UsePathStyle:    cfg.Storage.UsePathStyle,
+	})
+}
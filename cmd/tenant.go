@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	tenantPostgres "github.com/frahmantamala/expense-management/internal/tenant/postgres"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tenantSlug string
+	tenantName string
+)
+
+var tenantCmd = &cobra.Command{
+	Use:   "tenant",
+	Short: "Tenant administration",
+}
+
+var tenantCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a tenant with a copy of the default tenant's categories",
+	Example: `  expense-management tenant create --slug acme --name "Acme Corp"
+  expense-management user create --tenant acme --email jane@acme.com --name Jane --password-stdin`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := loadConfig(".")
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		db, err := initDB(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to init db: %w", err)
+		}
+
+		svc := tenant.NewService(tenantPostgres.NewTenantRepository(db), logger.LoggerWrapper())
+		t, created, err := svc.Ensure(cmd.Context(), tenantSlug, tenantName)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			fmt.Fprintf(cmd.OutOrStdout(), "created tenant %d (%s)\n", t.ID, t.Slug)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "tenant %s already exists as %d\n", t.Slug, t.ID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	tenantCreateCmd.Flags().StringVar(&tenantSlug, "slug", "", "tenant slug, sent by clients in the X-Tenant header")
+	tenantCreateCmd.MarkFlagRequired("slug")
+	tenantCreateCmd.Flags().StringVar(&tenantName, "name", "", "display name (defaults to the slug)")
+
+	tenantCmd.AddCommand(tenantCreateCmd)
+	rootCmd.AddCommand(tenantCmd)
+}
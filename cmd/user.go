@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	auditPostgres "github.com/frahmantamala/expense-management/internal/audit/postgres"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/user"
+	userPostgres "github.com/frahmantamala/expense-management/internal/user/postgres"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	userEmail         string
+	userName          string
+	userDepartment    string
+	userPassword      string
+	userPasswordStdin bool
+	userPermissions   []string
+	userPermission    string
+	userTenant        string
+	userReassignTo    string
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "User and permission administration",
+	Long:  `Create users, grant permissions, deactivate accounts and reset passwords through the service layer.`,
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a user",
+	Example: `  expense-management user create --email jane@mail.com --name "Jane" --department finance --permission create_expenses
+  echo "$PASSWORD" | expense-management user create --email jane@mail.com --name Jane --password-stdin`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		password, err := readPassword(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+
+		svc, db, err := newUserAdminService()
+		if err != nil {
+			return err
+		}
+		ctx, err := tenantContext(cmd.Context(), db, userTenant)
+		if err != nil {
+			return err
+		}
+
+		u, err := svc.CreateUser(ctx, user.CreateUserDTO{
+			Email:       userEmail,
+			Name:        userName,
+			Password:    password,
+			Department:  userDepartment,
+			Permissions: userPermissions,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "created user %d <%s>\n", u.ID, u.Email)
+		return nil
+	},
+}
+
+var userGrantPermissionCmd = &cobra.Command{
+	Use:     "grant-permission",
+	Short:   "Grant a permission to a user",
+	Example: `  expense-management user grant-permission --email jane@mail.com --permission approve_expenses`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		svc, _, err := newUserAdminService()
+		if err != nil {
+			return err
+		}
+
+		if err := svc.GrantPermission(cmd.Context(), userEmail, userPermission); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "granted %s to %s\n", userPermission, userEmail)
+		return nil
+	},
+}
+
+var userDeactivateCmd = &cobra.Command{
+	Use:   "deactivate",
+	Short: "Deactivate a user so they can no longer log in",
+	Long: `Deactivate a user so they can no longer log in. Their current sessions end
+at once and their approver permissions are revoked; --reassign-to grants those
+permissions to another user so pending approvals keep an approver.`,
+	Example: `  expense-management user deactivate --email jane@mail.com --reassign-to lead@mail.com`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		svc, _, err := newUserAdminService()
+		if err != nil {
+			return err
+		}
+
+		if err := svc.Deactivate(cmd.Context(), userEmail, userReassignTo); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "deactivated %s\n", userEmail)
+		return nil
+	},
+}
+
+var userResetPasswordCmd = &cobra.Command{
+	Use:     "reset-password",
+	Short:   "Set a new password for a user",
+	Example: `  echo "$PASSWORD" | expense-management user reset-password --email jane@mail.com --password-stdin`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		password, err := readPassword(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+
+		svc, _, err := newUserAdminService()
+		if err != nil {
+			return err
+		}
+
+		if err := svc.ResetPassword(cmd.Context(), userEmail, password); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "password reset for %s\n", userEmail)
+		return nil
+	},
+}
+
+func newUserAdminService() (*user.AdminService, *gorm.DB, error) {
+	cfg, err := loadConfig(".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := initDB(cfg.Database)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to init db: %w", err)
+	}
+
+	cost := cfg.Security.BCryptCost
+	if cost == 0 {
+		cost = 12
+	}
+
+	auditSink, err := audit.OpenSink(cfg.Observability.Audit, logger.LoggerWrapper())
+	if err != nil {
+		return nil, nil, err
+	}
+	auditService := audit.NewService(auditPostgres.NewAuditRepository(db), auditSink, logger.LoggerWrapper())
+
+	return user.NewAdminService(userPostgres.NewRepository(db), bcryptHasher{cost: cost}, auditService, logger.LoggerWrapper()), db, nil
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h bcryptHasher) HashPassword(password string) (string, error) {
+	return auth.HashPassword(password, h.cost)
+}
+
+// readPassword prefers --password-stdin so secrets stay out of shell history
+// and process listings.
+func readPassword(stdin io.Reader) (string, error) {
+	if userPasswordStdin {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read password from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	if userPassword == "" {
+		return "", errors.New("either --password or --password-stdin is required")
+	}
+	fmt.Fprintln(os.Stderr, "warning: passing --password on the command line exposes it in shell history; prefer --password-stdin")
+	return userPassword, nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{userCreateCmd, userGrantPermissionCmd, userDeactivateCmd, userResetPasswordCmd} {
+		c.Flags().StringVar(&userEmail, "email", "", "user email")
+		c.MarkFlagRequired("email")
+		userCmd.AddCommand(c)
+	}
+
+	userCreateCmd.Flags().StringVar(&userName, "name", "", "display name")
+	userCreateCmd.MarkFlagRequired("name")
+	userCreateCmd.Flags().StringVar(&userDepartment, "department", "", "department name")
+	userCreateCmd.Flags().StringSliceVar(&userPermissions, "permission", nil, "permission to grant (repeatable)")
+	userCreateCmd.Flags().StringVar(&userTenant, "tenant", "default", "slug of the tenant the user belongs to")
+
+	for _, c := range []*cobra.Command{userCreateCmd, userResetPasswordCmd} {
+		c.Flags().StringVar(&userPassword, "password", "", "password (prefer --password-stdin)")
+		c.Flags().BoolVar(&userPasswordStdin, "password-stdin", false, "read the password from stdin")
+		c.MarkFlagsMutuallyExclusive("password", "password-stdin")
+	}
+
+	userGrantPermissionCmd.Flags().StringVar(&userPermission, "permission", "", "permission name, e.g. approve_expenses")
+	userGrantPermissionCmd.MarkFlagRequired("permission")
+
+	userDeactivateCmd.Flags().StringVar(&userReassignTo, "reassign-to", "", "email of the user who takes over the approver permissions")
+
+	rootCmd.AddCommand(userCmd)
+}
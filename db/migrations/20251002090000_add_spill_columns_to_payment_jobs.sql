@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payment_jobs
+  ADD COLUMN amount_idr BIGINT,
+  ADD COLUMN gateway_payment_id VARCHAR(255);
+
+CREATE INDEX idx_payment_jobs_spilled ON payment_jobs(queued_at) WHERE status = 'spilled';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payment_jobs_spilled;
+ALTER TABLE payment_jobs
+  DROP COLUMN IF EXISTS gateway_payment_id,
+  DROP COLUMN IF EXISTS amount_idr;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE departments (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL UNIQUE,
+  parent_id BIGINT REFERENCES departments(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_departments_parent_id ON departments(parent_id);
+CREATE INDEX idx_users_department ON users(department);
+
+INSERT INTO departments (name)
+SELECT DISTINCT department FROM users WHERE department IS NOT NULL AND department <> ''
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+  ('view_team_expenses', 'Can view expenses of users in own department and its sub-departments'),
+  ('view_all_expenses', 'Can view all expenses')
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('view_team_expenses', 'view_all_expenses');
+DROP INDEX IF EXISTS idx_users_department;
+DROP TABLE IF EXISTS departments;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expenses
+  ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(description, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(category, '')), 'B')
+  ) STORED;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_expenses_search_vector ON expenses USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_expenses_search_vector;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
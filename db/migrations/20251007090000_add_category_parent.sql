@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expense_categories
+  ADD COLUMN parent_id BIGINT REFERENCES expense_categories(id),
+  ADD CONSTRAINT chk_expense_categories_parent_not_self CHECK (parent_id <> id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_expense_categories_parent_id ON expense_categories(parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_expense_categories_parent_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expense_categories
+  DROP CONSTRAINT IF EXISTS chk_expense_categories_parent_not_self,
+  DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
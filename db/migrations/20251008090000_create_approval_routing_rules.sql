@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE approval_routing_rules (
+  category VARCHAR(255) PRIMARY KEY REFERENCES expense_categories(name) ON UPDATE CASCADE ON DELETE CASCADE,
+  approver_permission VARCHAR(255) NOT NULL REFERENCES permissions(name) ON UPDATE CASCADE,
+  require_approval BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS approval_routing_rules;
+-- +goose StatementEnd
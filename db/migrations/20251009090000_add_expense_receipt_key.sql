@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expenses ADD COLUMN receipt_key VARCHAR(512);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses DROP COLUMN IF EXISTS receipt_key;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE export_jobs (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  resource VARCHAR(20) NOT NULL CHECK (resource IN ('expenses', 'payments')),
+  format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'jsonl')),
+  range_from TIMESTAMP WITH TIME ZONE NOT NULL,
+  range_to TIMESTAMP WITH TIME ZONE NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed', 'expired')),
+  blob_key VARCHAR(512),
+  row_count INTEGER,
+  error TEXT,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  started_at TIMESTAMP WITH TIME ZONE,
+  completed_at TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_export_jobs_queued ON export_jobs(id) WHERE status = 'queued';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_export_jobs_expires_at ON export_jobs(expires_at) WHERE status = 'completed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS export_jobs;
+-- +goose StatementEnd
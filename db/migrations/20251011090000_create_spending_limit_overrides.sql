@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE spending_limit_overrides (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  period VARCHAR(10) NOT NULL CHECK (period IN ('day', 'month')),
+  period_start DATE NOT NULL,
+  amount_idr BIGINT NOT NULL CHECK (amount_idr > 0),
+  reason TEXT NOT NULL,
+  granted_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_spending_limit_overrides_window ON spending_limit_overrides(user_id, period, period_start);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS spending_limit_overrides;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE approval_action_tokens (
+  id VARCHAR(64) PRIMARY KEY,
+  expense_id BIGINT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+  approver_id BIGINT NOT NULL REFERENCES users(id),
+  action VARCHAR(10) NOT NULL CHECK (action IN ('approve', 'reject')),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_approval_action_tokens_expense ON approval_action_tokens(expense_id) WHERE used_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS approval_action_tokens;
+-- +goose StatementEnd
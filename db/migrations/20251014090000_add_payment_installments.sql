@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payments
+  ADD COLUMN settled_amount BIGINT NOT NULL DEFAULT 0;
+
+UPDATE payments SET settled_amount = amount_idr WHERE status = 'success';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE payment_installments (
+  id BIGSERIAL PRIMARY KEY,
+  payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+  gateway_payment_id VARCHAR(255) NOT NULL,
+  amount_idr BIGINT NOT NULL CHECK (amount_idr > 0),
+  settled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  UNIQUE (payment_id, gateway_payment_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payment_installments;
+ALTER TABLE payments DROP COLUMN IF EXISTS settled_amount;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_bank_accounts (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  bank_code VARCHAR(20) NOT NULL,
+  account_holder VARCHAR(100) NOT NULL,
+  account_number_encrypted TEXT NOT NULL,
+  account_number_last4 VARCHAR(4) NOT NULL,
+  verification_status VARCHAR(20) NOT NULL DEFAULT 'unverified' CHECK (verification_status IN ('unverified', 'verified', 'rejected')),
+  verification_note TEXT,
+  verified_by BIGINT REFERENCES users(id),
+  verified_at TIMESTAMP WITH TIME ZONE,
+  is_default BOOLEAN NOT NULL DEFAULT false,
+  deleted_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_user_bank_accounts_user ON user_bank_accounts(user_id) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX idx_user_bank_accounts_default ON user_bank_accounts(user_id) WHERE is_default AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses
+  ADD COLUMN payout_account_id BIGINT REFERENCES user_bank_accounts(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses DROP COLUMN IF EXISTS payout_account_id;
+DROP TABLE IF EXISTS user_bank_accounts;
+-- +goose StatementEnd
@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE tenants (
+  id BIGSERIAL PRIMARY KEY,
+  slug VARCHAR(63) NOT NULL UNIQUE,
+  name VARCHAR(255) NOT NULL,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Existing data belongs to the default tenant.
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('tenants_id_seq', 1);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE expense_categories ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE expenses ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE payments ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE approval_routing_rules ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE export_jobs ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Category names only need to be unique within a tenant, so everything
+-- keyed by name now carries the tenant too.
+ALTER TABLE expenses DROP CONSTRAINT fk_expense_category;
+ALTER TABLE approval_routing_rules DROP CONSTRAINT approval_routing_rules_category_fkey;
+ALTER TABLE approval_routing_rules DROP CONSTRAINT approval_routing_rules_pkey;
+ALTER TABLE expense_categories DROP CONSTRAINT expense_categories_name_key;
+
+ALTER TABLE expense_categories ADD CONSTRAINT expense_categories_tenant_name_key UNIQUE (tenant_id, name);
+ALTER TABLE approval_routing_rules ADD PRIMARY KEY (tenant_id, category);
+ALTER TABLE approval_routing_rules
+  ADD CONSTRAINT approval_routing_rules_category_fkey
+  FOREIGN KEY (tenant_id, category) REFERENCES expense_categories(tenant_id, name) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE expenses
+  ADD CONSTRAINT fk_expense_category
+  FOREIGN KEY (tenant_id, category) REFERENCES expense_categories(tenant_id, name);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_users_tenant ON users(tenant_id);
+CREATE INDEX idx_expenses_tenant_status ON expenses(tenant_id, expense_status);
+CREATE INDEX idx_payments_tenant ON payments(tenant_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payments_tenant;
+DROP INDEX IF EXISTS idx_expenses_tenant_status;
+DROP INDEX IF EXISTS idx_users_tenant;
+
+ALTER TABLE expenses DROP CONSTRAINT fk_expense_category;
+ALTER TABLE approval_routing_rules DROP CONSTRAINT approval_routing_rules_category_fkey;
+ALTER TABLE approval_routing_rules DROP CONSTRAINT approval_routing_rules_pkey;
+ALTER TABLE expense_categories DROP CONSTRAINT expense_categories_tenant_name_key;
+
+ALTER TABLE expense_categories ADD CONSTRAINT expense_categories_name_key UNIQUE (name);
+ALTER TABLE approval_routing_rules ADD PRIMARY KEY (category);
+ALTER TABLE approval_routing_rules
+  ADD CONSTRAINT approval_routing_rules_category_fkey
+  FOREIGN KEY (category) REFERENCES expense_categories(name) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE expenses
+  ADD CONSTRAINT fk_expense_category
+  FOREIGN KEY (category) REFERENCES expense_categories(name);
+
+ALTER TABLE export_jobs DROP COLUMN tenant_id;
+ALTER TABLE approval_routing_rules DROP COLUMN tenant_id;
+ALTER TABLE payments DROP COLUMN tenant_id;
+ALTER TABLE expenses DROP COLUMN tenant_id;
+ALTER TABLE expense_categories DROP COLUMN tenant_id;
+ALTER TABLE users DROP COLUMN tenant_id;
+DROP TABLE IF EXISTS tenants;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per overridden setting; a missing key means the tenant uses the
+-- server default.
+CREATE TABLE tenant_settings (
+  tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  key VARCHAR(64) NOT NULL,
+  value JSONB NOT NULL,
+  updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tenant_settings;
+-- +goose StatementEnd
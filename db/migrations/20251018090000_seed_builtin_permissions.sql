@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The built-in permissions of auth.Permissions(), so grants work without
+-- running the seeder. Keep in sync with internal/auth/permissions.go.
+INSERT INTO permissions (name, description) VALUES
+  ('admin', 'Full administrator'),
+  ('manager', 'Can act as a manager: approve, reject and see team expenses'),
+  ('create_expenses', 'Can create expenses'),
+  ('edit_expenses', 'Can edit expenses'),
+  ('view_expenses', 'Can view expenses'),
+  ('view_team_expenses', 'Can view expenses of own department and its sub-departments'),
+  ('view_all_expenses', 'Can view all expenses'),
+  ('approve_expenses', 'Can approve expenses'),
+  ('reject_expenses', 'Can reject expenses'),
+  ('retry_payments', 'Can retry payments')
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Permissions may already be granted, and may have existed before this
+-- migration, so they are left in place.
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE expense_import_jobs (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  file_name VARCHAR(255) NOT NULL,
+  blob_key VARCHAR(512) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+  total_rows INTEGER,
+  processed_rows INTEGER NOT NULL DEFAULT 0,
+  imported_rows INTEGER NOT NULL DEFAULT 0,
+  failed_rows INTEGER NOT NULL DEFAULT 0,
+  row_errors JSONB,
+  error TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  started_at TIMESTAMP WITH TIME ZONE,
+  completed_at TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_expense_import_jobs_queued ON expense_import_jobs(id) WHERE status = 'queued';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS expense_import_jobs;
+-- +goose StatementEnd
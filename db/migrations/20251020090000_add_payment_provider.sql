@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payments ADD COLUMN provider VARCHAR(64);
+ALTER TABLE payment_jobs ADD COLUMN provider VARCHAR(64);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payment_jobs DROP COLUMN IF EXISTS provider;
+ALTER TABLE payments DROP COLUMN IF EXISTS provider;
+-- +goose StatementEnd
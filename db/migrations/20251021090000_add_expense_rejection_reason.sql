@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expenses
+  ADD COLUMN rejection_code VARCHAR(32) CHECK (rejection_code IN ('policy_violation', 'missing_receipt', 'duplicate', 'other')),
+  ADD COLUMN rejection_reason TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS rejection_reason,
+  DROP COLUMN IF EXISTS rejection_code;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expenses
+  ADD COLUMN approved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN rejected_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+-- Decisions made through email links are the only ones with a recorded
+-- actor; the latest one per expense wins.
+UPDATE expenses e
+SET approved_by = a.actor_id
+FROM (
+  SELECT DISTINCT ON (resource_id) resource_id, actor_id
+  FROM audit_logs
+  WHERE action = 'expense.approved_via_link' AND resource_type = 'expense' AND actor_id IS NOT NULL
+  ORDER BY resource_id, created_at DESC
+) a
+WHERE a.resource_id = e.id::text AND e.expense_status IN ('approved', 'completed');
+
+UPDATE expenses e
+SET rejected_by = a.actor_id
+FROM (
+  SELECT DISTINCT ON (resource_id) resource_id, actor_id
+  FROM audit_logs
+  WHERE action = 'expense.rejected_via_link' AND resource_type = 'expense' AND actor_id IS NOT NULL
+  ORDER BY resource_id, created_at DESC
+) a
+WHERE a.resource_id = e.id::text AND e.expense_status = 'rejected';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS rejected_by,
+  DROP COLUMN IF EXISTS approved_by;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per locked month; period_start is the first day of the month.
+CREATE TABLE period_locks (
+  tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  period_start DATE NOT NULL CHECK (EXTRACT(DAY FROM period_start) = 1),
+  reason VARCHAR(500) NOT NULL DEFAULT '',
+  locked_by BIGINT NOT NULL REFERENCES users(id),
+  locked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, period_start)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+  ('close_periods', 'Can lock and unlock months for month-end close')
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS period_locks;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Two rows per posting, a debit and a credit of the same amount sharing a
+-- reference; the unique key makes re-posting an event a no-op.
+CREATE TABLE ledger_entries (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  reference VARCHAR(100) NOT NULL,
+  entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('payable', 'cash_out', 'refund')),
+  account VARCHAR(30) NOT NULL CHECK (account IN ('expense', 'expenses_payable', 'cash')),
+  direction VARCHAR(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+  amount_idr BIGINT NOT NULL CHECK (amount_idr > 0),
+  expense_id BIGINT NOT NULL REFERENCES expenses(id),
+  payment_id BIGINT REFERENCES payments(id),
+  external_id VARCHAR(255),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, reference, direction)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_ledger_entries_expense ON ledger_entries(expense_id);
+CREATE INDEX idx_ledger_entries_payment ON ledger_entries(payment_id) WHERE payment_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ledger_entries;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- external_id is the issuer's transaction ID, so re-importing a feed skips
+-- rows already imported; an expense is matched to at most one transaction.
+CREATE TABLE card_transactions (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  external_id VARCHAR(255) NOT NULL,
+  cardholder_email VARCHAR(255) NOT NULL,
+  user_id BIGINT REFERENCES users(id),
+  card_last4 VARCHAR(4) NOT NULL DEFAULT '',
+  merchant VARCHAR(255) NOT NULL,
+  amount_idr BIGINT NOT NULL CHECK (amount_idr > 0),
+  transaction_date DATE NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'unmatched' CHECK (status IN ('unmatched', 'matched')),
+  expense_id BIGINT UNIQUE REFERENCES expenses(id),
+  matched_at TIMESTAMP WITH TIME ZONE,
+  imported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, external_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_card_transactions_unmatched ON card_transactions(tenant_id, transaction_date) WHERE status = 'unmatched';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+  ('reconcile_cards', 'Can import corporate card transactions and review unmatched ones')
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS card_transactions;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- next_run_at is advanced when the report worker claims a schedule, so a
+-- schedule is sent once per period even with several servers running.
+CREATE TABLE report_schedules (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  name VARCHAR(255) NOT NULL,
+  report_type VARCHAR(40) NOT NULL CHECK (report_type IN ('spend_by_department', 'spend_by_category')),
+  format VARCHAR(10) NOT NULL CHECK (format IN ('xlsx', 'csv')),
+  frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+  weekday SMALLINT NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+  hour SMALLINT NOT NULL DEFAULT 7 CHECK (hour BETWEEN 0 AND 23),
+  recipients JSONB NOT NULL DEFAULT '[]',
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  last_run_at TIMESTAMP WITH TIME ZONE,
+  last_error TEXT,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_report_schedules_due ON report_schedules(next_run_at) WHERE enabled;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS report_schedules;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE expense_templates (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  name VARCHAR(100) NOT NULL,
+  category VARCHAR(255) NOT NULL,
+  amount_idr BIGINT CHECK (amount_idr > 0),
+  description VARCHAR(500) NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_expense_templates_user ON expense_templates(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS expense_templates;
+-- +goose StatementEnd
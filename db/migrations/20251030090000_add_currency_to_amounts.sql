@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- First step of moving amounts off rupiah-only columns. amount_idr already
+-- holds minor units (IDR has none below the rupiah), so every existing row
+-- is correct as IDR. Once code writes currency, amount_idr is renamed to
+-- amount_minor and the default dropped.
+ALTER TABLE expenses
+  ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE payments
+  ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payments
+  DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS currency;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Daily rates into IDR, one row per tenant, currency and day. rate is what
+-- one major unit of currency is worth in rupiah.
+CREATE TABLE exchange_rates (
+  tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  currency CHAR(3) NOT NULL,
+  rate_date DATE NOT NULL,
+  rate NUMERIC(24, 10) NOT NULL CHECK (rate > 0),
+  source VARCHAR(100) NOT NULL,
+  set_by BIGINT REFERENCES users(id),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, currency, rate_date)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- The rate an expense was converted at is copied onto it, so reports still
+-- reproduce its rupiah amount after the rate table is corrected.
+ALTER TABLE expenses
+  ADD COLUMN original_amount BIGINT,
+  ADD COLUMN original_currency CHAR(3),
+  ADD COLUMN exchange_rate NUMERIC(24, 10),
+  ADD COLUMN exchange_rate_source VARCHAR(100),
+  ADD COLUMN exchange_rate_date DATE,
+  ADD COLUMN exchange_rate_quoted_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS exchange_rate_quoted_at,
+  DROP COLUMN IF EXISTS exchange_rate_date,
+  DROP COLUMN IF EXISTS exchange_rate_source,
+  DROP COLUMN IF EXISTS exchange_rate,
+  DROP COLUMN IF EXISTS original_currency,
+  DROP COLUMN IF EXISTS original_amount;
+
+DROP TABLE IF EXISTS exchange_rates;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Stock rejection comments a tenant's approvers pick from. Archived
+-- responses stay so the expenses rejected with them still report by them.
+CREATE TABLE canned_responses (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  code VARCHAR(32) NOT NULL CHECK (code IN ('policy_violation', 'missing_receipt', 'duplicate', 'other')),
+  title VARCHAR(100) NOT NULL,
+  body VARCHAR(1000) NOT NULL,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  archived_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_canned_responses_tenant ON canned_responses(tenant_id) WHERE archived_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses
+  ADD COLUMN canned_response_id BIGINT REFERENCES canned_responses(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS canned_response_id;
+
+DROP TABLE IF EXISTS canned_responses;
+-- +goose StatementEnd
@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Change records for downstream warehouses, written by triggers in the
+-- transaction that changed the row, so no code path can skip them. data is
+-- the row after the change; diff holds {"column": {"old": .., "new": ..}}
+-- for updates.
+CREATE TABLE outbound_changes (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  source_table VARCHAR(64) NOT NULL,
+  row_id BIGINT NOT NULL,
+  operation VARCHAR(10) NOT NULL CHECK (operation IN ('insert', 'update')),
+  data JSONB NOT NULL,
+  diff JSONB,
+  changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_outbound_changes_tenant ON outbound_changes(tenant_id, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- How far each consumer has read, so it can resume without keeping its own
+-- offset.
+CREATE TABLE change_consumers (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  name VARCHAR(64) NOT NULL,
+  last_change_id BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- The trigger's arguments name columns left out of data and diff. An update
+-- that only touches updated_at is not recorded.
+CREATE OR REPLACE FUNCTION record_outbound_change() RETURNS trigger AS $$
+DECLARE
+  excluded TEXT[] := COALESCE(TG_ARGV, '{}');
+  new_row JSONB := to_jsonb(NEW) - excluded;
+  changes JSONB;
+BEGIN
+  IF TG_OP = 'UPDATE' THEN
+    SELECT jsonb_object_agg(n.key, jsonb_build_object('old', o.value, 'new', n.value))
+      INTO changes
+      FROM jsonb_each(new_row) n
+      JOIN jsonb_each(to_jsonb(OLD) - excluded) o ON o.key = n.key
+     WHERE n.value IS DISTINCT FROM o.value
+       AND n.key <> 'updated_at';
+    IF changes IS NULL THEN
+      RETURN NULL;
+    END IF;
+  END IF;
+
+  INSERT INTO outbound_changes (tenant_id, source_table, row_id, operation, data, diff)
+  VALUES (NEW.tenant_id, TG_TABLE_NAME, NEW.id, lower(TG_OP), new_row, changes);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER expenses_outbound_changes
+  AFTER INSERT OR UPDATE ON expenses
+  FOR EACH ROW EXECUTE FUNCTION record_outbound_change();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- gateway_response is encrypted at rest and stays out of the feed.
+CREATE TRIGGER payments_outbound_changes
+  AFTER INSERT OR UPDATE ON payments
+  FOR EACH ROW EXECUTE FUNCTION record_outbound_change('gateway_response');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS payments_outbound_changes ON payments;
+DROP TRIGGER IF EXISTS expenses_outbound_changes ON expenses;
+DROP FUNCTION IF EXISTS record_outbound_change();
+DROP TABLE IF EXISTS change_consumers;
+DROP TABLE IF EXISTS outbound_changes;
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Aggregates written once a day by the daily_snapshot job, for dashboards
+-- and warehouses to read instead of scanning expenses and payments. A day's
+-- rows describe the expenses submitted and payments created that day (UTC),
+-- as they stood when the snapshot was taken.
+CREATE SCHEMA IF NOT EXISTS reporting;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- department is the submitter's department when the snapshot was taken.
+CREATE TABLE reporting.daily_expense_facts (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  fact_date DATE NOT NULL,
+  category VARCHAR(100) NOT NULL DEFAULT '',
+  department VARCHAR(100) NOT NULL DEFAULT '',
+  expense_status VARCHAR(50) NOT NULL,
+  expense_count BIGINT NOT NULL,
+  amount_idr BIGINT NOT NULL,
+  snapshot_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_daily_expense_facts_tenant_date ON reporting.daily_expense_facts(tenant_id, fact_date);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE reporting.daily_payment_facts (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  fact_date DATE NOT NULL,
+  payment_count BIGINT NOT NULL,
+  succeeded BIGINT NOT NULL,
+  failed BIGINT NOT NULL,
+  amount_idr BIGINT NOT NULL,
+  settled_amount_idr BIGINT NOT NULL,
+  snapshot_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, fact_date)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- One row per day snapshotted, so a run knows which days it missed even
+-- when a day had nothing to count.
+CREATE TABLE reporting.daily_snapshots (
+  fact_date DATE PRIMARY KEY,
+  expense_facts INTEGER NOT NULL,
+  payment_facts INTEGER NOT NULL,
+  snapshot_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reporting.daily_snapshots;
+DROP TABLE IF EXISTS reporting.daily_payment_facts;
+DROP TABLE IF EXISTS reporting.daily_expense_facts;
+DROP SCHEMA IF EXISTS reporting;
+-- +goose StatementEnd
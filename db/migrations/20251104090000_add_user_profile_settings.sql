@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- login_alerts and export_emails let users opt out of new-login and
+-- export-ready emails; avatar_key is the blob storage key of their avatar.
+ALTER TABLE users
+  ADD COLUMN login_alerts BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN export_emails BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN avatar_key VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+  DROP COLUMN IF EXISTS avatar_key,
+  DROP COLUMN IF EXISTS export_emails,
+  DROP COLUMN IF EXISTS login_alerts;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tokens issued before sessions_revoked_at are refused; deactivating a user
+-- sets it so their sessions end at once instead of when their tokens expire.
+ALTER TABLE users ADD COLUMN sessions_revoked_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS sessions_revoked_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+  ('finance_viewer', 'Can view all expenses and payments, without approving, rejecting or retrying them')
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The permission may already be granted, so it is left in place.
+SELECT 1;
+-- +goose StatementEnd
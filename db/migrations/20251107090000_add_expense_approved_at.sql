@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- approved_at is when an expense was approved; processed_at moves on when
+-- the expense is paid, so it cannot measure time to approve.
+ALTER TABLE expenses ADD COLUMN approved_at TIMESTAMP WITH TIME ZONE;
+
+-- Expenses still in approved have not moved on, so processed_at is still
+-- their approval time. Those already paid are left without one.
+UPDATE expenses SET approved_at = processed_at
+WHERE expense_status = 'approved' AND approved_by IS NOT NULL;
+
+CREATE INDEX idx_expenses_approved_at ON expenses (tenant_id, approved_at)
+WHERE approved_by IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_expenses_approved_at;
+ALTER TABLE expenses DROP COLUMN IF EXISTS approved_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- failure_class says whether a failed payment is worth retrying
+-- (transient) or not (permanent); next_retry_at is when a transient
+-- failure is retried automatically.
+ALTER TABLE payments ADD COLUMN failure_class VARCHAR(20);
+ALTER TABLE payments ADD COLUMN next_retry_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_payments_next_retry_at ON payments (next_retry_at)
+WHERE status = 'failed' AND next_retry_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payments_next_retry_at;
+ALTER TABLE payments DROP COLUMN IF EXISTS next_retry_at;
+ALTER TABLE payments DROP COLUMN IF EXISTS failure_class;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- gateway_calls keeps every request sent to a payment gateway and its
+-- answer, secrets redacted, for disputes with the provider. Rows are purged
+-- once older than payment.call_log.retention.
+CREATE TABLE gateway_calls (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT REFERENCES payments(id) ON DELETE SET NULL,
+    external_id VARCHAR(255) NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL,
+    url TEXT NOT NULL,
+    request_header JSONB,
+    request_body TEXT,
+    status_code INTEGER,
+    response_header JSONB,
+    response_body TEXT,
+    error TEXT,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_gateway_calls_payment_id ON gateway_calls (payment_id, created_at);
+CREATE INDEX idx_gateway_calls_external_id ON gateway_calls (external_id);
+CREATE INDEX idx_gateway_calls_created_at ON gateway_calls (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS gateway_calls;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- approved_amount_idr is set when a manager approved less than amount_idr;
+-- the expense is paid that amount and adjustment_reason says why.
+ALTER TABLE expenses ADD COLUMN approved_amount_idr BIGINT;
+ALTER TABLE expenses ADD COLUMN adjustment_reason TEXT;
+ALTER TABLE expenses ADD CONSTRAINT chk_expenses_approved_amount
+    CHECK (approved_amount_idr IS NULL OR (approved_amount_idr > 0 AND approved_amount_idr <= amount_idr));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS chk_expenses_approved_amount;
+ALTER TABLE expenses DROP COLUMN IF EXISTS adjustment_reason;
+ALTER TABLE expenses DROP COLUMN IF EXISTS approved_amount_idr;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- receipt_thumbnail_key is the JPEG preview of the receipt, stored next to
+-- it once the thumbnail worker has rendered it. receipt_thumbnail_error is
+-- why a receipt could not be previewed; such receipts are not tried again.
+-- Both are cleared when the receipt is replaced. Existing receipts have
+-- neither, so the worker previews them too.
+ALTER TABLE expenses ADD COLUMN receipt_thumbnail_key VARCHAR(512);
+ALTER TABLE expenses ADD COLUMN receipt_thumbnail_error TEXT;
+
+CREATE INDEX idx_expenses_receipt_thumbnail_pending ON expenses (id)
+WHERE receipt_key IS NOT NULL AND receipt_thumbnail_key IS NULL AND receipt_thumbnail_error IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_expenses_receipt_thumbnail_pending;
+ALTER TABLE expenses DROP COLUMN IF EXISTS receipt_thumbnail_error;
+ALTER TABLE expenses DROP COLUMN IF EXISTS receipt_thumbnail_key;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A tenant's own holidays, and the weekend days or national holidays it
+-- works on anyway.
+CREATE TABLE calendar_days (
+  tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  day DATE NOT NULL,
+  kind VARCHAR(20) NOT NULL CHECK (kind IN ('holiday', 'working_day')),
+  name VARCHAR(200) NOT NULL DEFAULT '',
+  set_by BIGINT NOT NULL REFERENCES users(id),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, day)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS calendar_days;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Grants letting a delegate, such as an executive assistant, file expenses
+-- for the principal who granted them. Revoked grants stay for the audit
+-- trail.
+CREATE TABLE delegation_grants (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants(id),
+  principal_id BIGINT NOT NULL REFERENCES users(id),
+  delegate_id BIGINT NOT NULL REFERENCES users(id),
+  granted_by BIGINT NOT NULL REFERENCES users(id),
+  expires_at TIMESTAMP WITH TIME ZONE,
+  revoked_at TIMESTAMP WITH TIME ZONE,
+  revoked_by BIGINT REFERENCES users(id),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  CHECK (principal_id <> delegate_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX idx_delegation_grants_active ON delegation_grants(principal_id, delegate_id) WHERE revoked_at IS NULL;
+CREATE INDEX idx_delegation_grants_delegate ON delegation_grants(delegate_id) WHERE revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- The delegate who filed the expense; user_id stays its owner.
+ALTER TABLE expenses
+  ADD COLUMN submitted_by BIGINT REFERENCES users(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS submitted_by;
+
+DROP TABLE IF EXISTS delegation_grants;
+-- +goose StatementEnd
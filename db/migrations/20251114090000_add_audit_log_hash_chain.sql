@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Entries written from now on are chained: each stores the hash of the one
+-- before it and its own. Earlier entries keep a NULL seq and are not chained.
+ALTER TABLE audit_logs
+  ADD COLUMN seq BIGINT,
+  ADD COLUMN prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_audit_logs_seq ON audit_logs(seq);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_logs_seq;
+ALTER TABLE audit_logs
+  DROP COLUMN IF EXISTS hash,
+  DROP COLUMN IF EXISTS prev_hash,
+  DROP COLUMN IF EXISTS seq;
+-- +goose StatementEnd
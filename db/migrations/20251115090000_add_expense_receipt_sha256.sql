@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SHA-256 of the uploaded receipt file, to spot one receipt claimed on more
+-- than one expense. Receipts linked by URL or uploaded before are not hashed.
+ALTER TABLE expenses ADD COLUMN receipt_sha256 VARCHAR(64);
+
+CREATE INDEX idx_expenses_receipt_sha256 ON expenses(tenant_id, receipt_sha256) WHERE receipt_sha256 IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_expenses_receipt_sha256;
+ALTER TABLE expenses DROP COLUMN IF EXISTS receipt_sha256;
+-- +goose StatementEnd
@@ -0,0 +1,102 @@
+package approvalaction
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+)
+
+type Action string
+
+const (
+	ActionApprove Action = "approve"
+	ActionReject  Action = "reject"
+)
+
+// Claims is the signed payload of an approval link. ID is looked up in the
+// database so each link works once.
+type Claims struct {
+	ID         string `json:"jti"`
+	ExpenseID  int64  `json:"eid"`
+	ApproverID int64  `json:"sub"`
+	Action     Action `json:"act"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// Result is what a followed link reports back.
+type Result struct {
+	ExpenseID int64  `json:"expense_id"`
+	Action    Action `json:"action"`
+	Status    string `json:"status"`
+}
+
+var (
+	ErrInvalidLink = errors.NewUnauthorizedError("Invalid approval link", errors.ErrCodeInvalidToken)
+	ErrLinkExpired = errors.NewUnauthorizedError("Approval link has expired", errors.ErrCodeTokenExpired)
+	ErrLinkUsed    = errors.NewConflictError("Approval link has already been used", errors.ErrCodeActionLinkUsed)
+)
+
+// Signer encodes claims as base64url(JSON) + "." + base64url(HMAC-SHA256).
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key string) *Signer {
+	return &Signer{key: []byte(key)}
+}
+
+func (s *Signer) Sign(c Claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + s.mac(body), nil
+}
+
+// Parse verifies the signature and expiry of token. Expired links still
+// return their claims so the refusal can be attributed.
+func (s *Signer) Parse(token string, now time.Time) (Claims, error) {
+	var c Claims
+
+	body, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.mac(body))) {
+		return c, ErrInvalidLink
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return c, ErrInvalidLink
+	}
+	if err := json.Unmarshal(payload, &c); err != nil || c.ID == "" {
+		return c, ErrInvalidLink
+	}
+	if c.Action != ActionApprove && c.Action != ActionReject {
+		return c, ErrInvalidLink
+	}
+	if !now.Before(time.Unix(c.ExpiresAt, 0)) {
+		return c, ErrLinkExpired
+	}
+	return c, nil
+}
+
+func (s *Signer) mac(body string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,13 @@
+package approvalaction_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestApprovalAction(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Approval Action Suite")
+}
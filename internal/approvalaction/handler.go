@@ -0,0 +1,57 @@
+package approvalaction
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	Act(ctx context.Context, token, remoteAddr string) (*Result, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// Act godoc
+// @Summary      Approve or reject an expense from an email link
+// @Description  Follows a signed link from a notification email. The token identifies the expense, the approver and the decision; it expires and works only once. No bearer token is needed.
+// @Tags         expenses
+// @Produce      json
+// @Param        token  query     string  true  "Signed action token"
+// @Success      200    {object}  Result
+// @Failure      400    {object}  transport.AppErrorResponse
+// @Failure      401    {object}  transport.AppErrorResponse
+// @Failure      403    {object}  transport.AppErrorResponse
+// @Failure      404    {object}  transport.AppErrorResponse
+// @Failure      409    {object}  transport.AppErrorResponse
+// @Router       /approvals/act [get]
+func (h *Handler) Act(w http.ResponseWriter, r *http.Request) {
+	// The token is a credential; keep it out of caches and Referer headers.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		h.WriteError(w, r, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	result, err := h.Service.Act(r.Context(), token, r.RemoteAddr)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
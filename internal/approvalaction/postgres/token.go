@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/approvalaction"
+	actionDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalaction"
+	"gorm.io/gorm"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) approvalaction.RepositoryAPI {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) Create(token *actionDatamodel.Token) error {
+	return r.db.Create(token).Error
+}
+
+// Consume is a single conditional UPDATE so two requests racing on the same
+// link cannot both succeed.
+func (r *TokenRepository) Consume(id string, now time.Time) (bool, error) {
+	result := r.db.Model(&actionDatamodel.Token{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TokenRepository) RevokeForExpense(expenseID int64, now time.Time) error {
+	return r.db.Model(&actionDatamodel.Token{}).
+		Where("expense_id = ? AND used_at IS NULL", expenseID).
+		Update("used_at", now).Error
+}
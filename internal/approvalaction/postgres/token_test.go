@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/approvalaction"
+	actionDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalaction"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestTokenRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TokenRepository Suite")
+}
+
+var _ = Describe("TokenRepository", func() {
+	var (
+		db   *gorm.DB
+		repo approvalaction.RepositoryAPI
+		now  time.Time
+	)
+
+	create := func(id string, expenseID int64) {
+		Expect(repo.Create(&actionDatamodel.Token{ID: id, ExpenseID: expenseID, ApproverID: 7, Action: "approve", ExpiresAt: now.Add(time.Hour)})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = testdb.Open(&actionDatamodel.Token{})
+		Expect(err).NotTo(HaveOccurred())
+
+		repo = NewTokenRepository(db)
+		now = time.Date(2025, 10, 12, 9, 0, 0, 0, time.UTC)
+	})
+
+	It("consumes a token only once", func() {
+		create("a", 1)
+
+		first, err := repo.Consume("a", now)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := repo.Consume("a", now)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first).To(BeTrue())
+		Expect(second).To(BeFalse())
+	})
+
+	It("does not consume unknown tokens", func() {
+		ok, err := repo.Consume("missing", now)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("revokes only the expense's outstanding tokens", func() {
+		create("a", 1)
+		create("b", 1)
+		create("c", 2)
+
+		Expect(repo.RevokeForExpense(1, now)).To(Succeed())
+
+		for id, want := range map[string]bool{"a": false, "b": false, "c": true} {
+			ok, err := repo.Consume(id, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(Equal(want), id)
+		}
+	})
+})
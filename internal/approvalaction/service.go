@@ -0,0 +1,222 @@
+package approvalaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	actionDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalaction"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/user"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// LinkPath is where approval links are followed, relative to the base URL.
+const LinkPath = "/api/v1/approvals/act"
+
+const rejectReason = "Rejected from email link"
+
+type RepositoryAPI interface {
+	Create(token *actionDatamodel.Token) error
+	// Consume marks the token used and reports false when it was already
+	// used or does not exist.
+	Consume(id string, now time.Time) (bool, error)
+	// RevokeForExpense marks every unused link for the expense used, so the
+	// sibling approve/reject links and other approvers' links stop working.
+	RevokeForExpense(expenseID int64, now time.Time) error
+}
+
+type UserLookup interface {
+	GetByID(userID int64) (*user.User, error)
+}
+
+type ExpenseDecider interface {
+	ApproveExpense(ctx context.Context, expenseID, managerID int64, userPermissions []string) error
+	RejectExpense(ctx context.Context, expenseID, managerID int64, code, reason string, userPermissions []string) error
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+type Service struct {
+	repo     RepositoryAPI
+	signer   *Signer
+	users    UserLookup
+	expenses ExpenseDecider
+	audit    AuditRecorder
+	baseURL  string
+	ttl      time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewService(repo RepositoryAPI, signer *Signer, users UserLookup, expenses ExpenseDecider, audit AuditRecorder, baseURL string, ttl time.Duration, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		signer:   signer,
+		users:    users,
+		expenses: expenses,
+		audit:    audit,
+		baseURL:  baseURL,
+		ttl:      ttl,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// ActionLinks issues a fresh approve and reject link for approverID to act on
+// expenseID.
+func (s *Service) ActionLinks(ctx context.Context, expenseID, approverID int64) (string, string, error) {
+	approveURL, err := s.issue(expenseID, approverID, ActionApprove)
+	if err != nil {
+		return "", "", err
+	}
+	rejectURL, err := s.issue(expenseID, approverID, ActionReject)
+	if err != nil {
+		return "", "", err
+	}
+	return approveURL, rejectURL, nil
+}
+
+func (s *Service) issue(expenseID, approverID int64, action Action) (string, error) {
+	id, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate approval link ID: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.ttl)
+	token, err := s.signer.Sign(Claims{
+		ID:         id,
+		ExpenseID:  expenseID,
+		ApproverID: approverID,
+		Action:     action,
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign approval link: %w", err)
+	}
+
+	row := &actionDatamodel.Token{
+		ID:         id,
+		ExpenseID:  expenseID,
+		ApproverID: approverID,
+		Action:     string(action),
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.repo.Create(row); err != nil {
+		return "", fmt.Errorf("failed to save approval link: %w", err)
+	}
+
+	return s.baseURL + LinkPath + "?token=" + url.QueryEscape(token), nil
+}
+
+// Act carries out the decision encoded in token on behalf of the approver it
+// was issued to. The approver's current permissions apply, so a link does
+// not outlive a revoked role.
+func (s *Service) Act(ctx context.Context, token, remoteAddr string) (*Result, error) {
+	now := s.now()
+
+	claims, err := s.signer.Parse(token, now)
+	if err == ErrInvalidLink {
+		s.log(ctx).Warn("approval link refused: bad signature", "remote_addr", remoteAddr)
+		return nil, err
+	}
+	if err != nil {
+		s.refuse(ctx, claims, "expired", remoteAddr)
+		return nil, err
+	}
+
+	consumed, err := s.repo.Consume(claims.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume approval link: %w", err)
+	}
+	if !consumed {
+		s.refuse(ctx, claims, "already_used", remoteAddr)
+		return nil, ErrLinkUsed
+	}
+
+	approver, err := s.users.GetByID(claims.ApproverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approver: %w", err)
+	}
+	if !approver.IsActive {
+		s.refuse(ctx, claims, "approver_inactive", remoteAddr)
+		return nil, errors.ErrUserInactive
+	}
+	// The link is anonymous; act within the approver's tenant.
+	ctx = tenant.NewContext(ctx, approver.TenantID)
+
+	result := &Result{ExpenseID: claims.ExpenseID, Action: claims.Action}
+	auditAction := audit.ActionExpenseApprovedViaLink
+	if claims.Action == ActionApprove {
+		err = s.expenses.ApproveExpense(ctx, claims.ExpenseID, approver.ID, approver.Permissions)
+		result.Status = expense.ExpenseStatusApproved
+	} else {
+		err = s.expenses.RejectExpense(ctx, claims.ExpenseID, approver.ID, expense.RejectionOther, rejectReason, approver.Permissions)
+		result.Status = expense.ExpenseStatusRejected
+		auditAction = audit.ActionExpenseRejectedViaLink
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RevokeForExpense(claims.ExpenseID, now); err != nil {
+		s.log(ctx).Error("failed to revoke remaining approval links", "error", err, "expense_id", claims.ExpenseID)
+	}
+
+	s.record(ctx, &audit.Entry{
+		Action:       auditAction,
+		ResourceType: audit.ResourceExpense,
+		ResourceID:   strconv.FormatInt(claims.ExpenseID, 10),
+		ActorID:      &approver.ID,
+		Metadata: map[string]interface{}{
+			"link_id":     claims.ID,
+			"remote_addr": remoteAddr,
+		},
+	})
+
+	s.log(ctx).Info("expense decided via approval link",
+		"expense_id", claims.ExpenseID,
+		"approver_id", approver.ID,
+		"action", claims.Action)
+	return result, nil
+}
+
+func (s *Service) refuse(ctx context.Context, claims Claims, reason, remoteAddr string) {
+	s.log(ctx).Warn("approval link refused",
+		"reason", reason,
+		"link_id", claims.ID,
+		"expense_id", claims.ExpenseID,
+		"approver_id", claims.ApproverID)
+
+	s.record(ctx, &audit.Entry{
+		Action:       audit.ActionApprovalLinkRefused,
+		ResourceType: audit.ResourceExpense,
+		ResourceID:   strconv.FormatInt(claims.ExpenseID, 10),
+		ActorID:      &claims.ApproverID,
+		Metadata: map[string]interface{}{
+			"reason":      reason,
+			"link_id":     claims.ID,
+			"action":      string(claims.Action),
+			"remote_addr": remoteAddr,
+		},
+	})
+}
+
+// record never fails the request; the audit service logs its own errors.
+func (s *Service) record(ctx context.Context, entry *audit.Entry) {
+	if err := s.audit.Record(entry); err != nil {
+		s.log(ctx).Error("failed to audit approval link", "error", err, "action", entry.Action)
+	}
+}
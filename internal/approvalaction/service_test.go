@@ -0,0 +1,225 @@
+package approvalaction_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/approvalaction"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	actionDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalaction"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+type mockRepository struct {
+	tokens map[string]*actionDatamodel.Token
+}
+
+func (m *mockRepository) Create(token *actionDatamodel.Token) error {
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *mockRepository) Consume(id string, now time.Time) (bool, error) {
+	t, ok := m.tokens[id]
+	if !ok || t.UsedAt != nil {
+		return false, nil
+	}
+	t.UsedAt = &now
+	return true, nil
+}
+
+func (m *mockRepository) RevokeForExpense(expenseID int64, now time.Time) error {
+	for _, t := range m.tokens {
+		if t.ExpenseID == expenseID && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type mockUsers struct {
+	users map[int64]*user.User
+}
+
+func (m *mockUsers) GetByID(userID int64) (*user.User, error) {
+	return m.users[userID], nil
+}
+
+type decision struct {
+	action    string
+	expenseID int64
+	managerID int64
+}
+
+type mockExpenses struct {
+	decisions []decision
+	err       error
+}
+
+func (m *mockExpenses) ApproveExpense(_ context.Context, expenseID, managerID int64, _ []string) error {
+	m.decisions = append(m.decisions, decision{"approve", expenseID, managerID})
+	return m.err
+}
+
+func (m *mockExpenses) RejectExpense(_ context.Context, expenseID, managerID int64, _, _ string, _ []string) error {
+	m.decisions = append(m.decisions, decision{"reject", expenseID, managerID})
+	return m.err
+}
+
+type mockAudit struct {
+	entries []*audit.Entry
+}
+
+func (m *mockAudit) Record(entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func tokenFrom(link string) string {
+	u, err := url.Parse(link)
+	Expect(err).NotTo(HaveOccurred())
+	return u.Query().Get("token")
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx      context.Context
+		repo     *mockRepository
+		users    *mockUsers
+		expenses *mockExpenses
+		auditLog *mockAudit
+		signer   *approvalaction.Signer
+		svc      *approvalaction.Service
+		logger   *slog.Logger
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{tokens: map[string]*actionDatamodel.Token{}}
+		users = &mockUsers{users: map[int64]*user.User{
+			7: {ID: 7, IsActive: true, Permissions: []string{"approve_expenses", "reject_expenses"}},
+		}}
+		expenses = &mockExpenses{}
+		auditLog = &mockAudit{}
+		signer = approvalaction.NewSigner("test-secret")
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		svc = approvalaction.NewService(repo, signer, users, expenses, auditLog, "https://expenses.example.com", time.Hour, logger)
+	})
+
+	It("issues approve and reject links for the approver", func() {
+		approveURL, rejectURL, err := svc.ActionLinks(ctx, 42, 7)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(approveURL).To(HavePrefix("https://expenses.example.com/api/v1/approvals/act?token="))
+		Expect(repo.tokens).To(HaveLen(2))
+
+		claims, err := signer.Parse(tokenFrom(rejectURL), time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims.ExpenseID).To(Equal(int64(42)))
+		Expect(claims.ApproverID).To(Equal(int64(7)))
+		Expect(claims.Action).To(Equal(approvalaction.ActionReject))
+	})
+
+	It("approves as the approver and audits the decision", func() {
+		approveURL, _, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := svc.Act(ctx, tokenFrom(approveURL), "10.0.0.1:5000")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Status).To(Equal("approved"))
+		Expect(expenses.decisions).To(Equal([]decision{{"approve", 42, 7}}))
+		Expect(auditLog.entries).To(HaveLen(1))
+		Expect(auditLog.entries[0].Action).To(Equal(audit.ActionExpenseApprovedViaLink))
+		Expect(*auditLog.entries[0].ActorID).To(Equal(int64(7)))
+	})
+
+	It("refuses a replayed link and the sibling link once one was used", func() {
+		approveURL, rejectURL, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+		Expect(err).To(Equal(approvalaction.ErrLinkUsed))
+		_, err = svc.Act(ctx, tokenFrom(rejectURL), "")
+		Expect(err).To(Equal(approvalaction.ErrLinkUsed))
+
+		Expect(expenses.decisions).To(HaveLen(1))
+		Expect(auditLog.entries[1].Action).To(Equal(audit.ActionApprovalLinkRefused))
+		Expect(auditLog.entries[1].Metadata["reason"]).To(Equal("already_used"))
+	})
+
+	It("refuses expired links", func() {
+		svc = approvalaction.NewService(repo, signer, users, expenses, auditLog, "https://expenses.example.com", -time.Minute, logger)
+		approveURL, _, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+
+		Expect(err).To(Equal(approvalaction.ErrLinkExpired))
+		Expect(expenses.decisions).To(BeEmpty())
+		Expect(auditLog.entries[0].Metadata["reason"]).To(Equal("expired"))
+	})
+
+	It("refuses tampered links", func() {
+		approveURL, _, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+		body, signature, _ := strings.Cut(tokenFrom(approveURL), ".")
+
+		_, err = svc.Act(ctx, body+"x."+signature, "")
+		Expect(err).To(Equal(approvalaction.ErrInvalidLink))
+
+		forged, err := approvalaction.NewSigner("other-secret").Sign(approvalaction.Claims{ID: "x", ExpenseID: 42, ApproverID: 7, Action: approvalaction.ActionApprove, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = svc.Act(ctx, forged, "")
+		Expect(err).To(Equal(approvalaction.ErrInvalidLink))
+
+		Expect(expenses.decisions).To(BeEmpty())
+	})
+
+	It("refuses links of deactivated approvers", func() {
+		approveURL, _, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+		users.users[7].IsActive = false
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+
+		Expect(err).To(Equal(errors.ErrUserInactive))
+		Expect(expenses.decisions).To(BeEmpty())
+	})
+
+	It("passes on a decision refused for the approver as forbidden", func() {
+		expenses.err = expense.ErrOwnExpenseDecision
+		approveURL, _, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Type).To(Equal(errors.ErrorTypeForbidden))
+	})
+
+	It("burns the link even when the decision is refused", func() {
+		expenses.err = errors.ErrInvalidExpenseStatus
+		approveURL, _, err := svc.ActionLinks(ctx, 42, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+		Expect(err).To(Equal(errors.ErrInvalidExpenseStatus))
+
+		_, err = svc.Act(ctx, tokenFrom(approveURL), "")
+		Expect(err).To(Equal(approvalaction.ErrLinkUsed))
+	})
+})
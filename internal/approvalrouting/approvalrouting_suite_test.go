@@ -0,0 +1,13 @@
+package approvalrouting_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestApprovalRouting(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Approval Routing Suite")
+}
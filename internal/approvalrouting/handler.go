@@ -0,0 +1,200 @@
+package approvalrouting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	ListRules(ctx context.Context) ([]*Rule, error)
+	SaveRule(ctx context.Context, category string, dto SaveRuleDTO) (*Rule, error)
+	DeleteRule(ctx context.Context, category string) error
+}
+
+type MatrixServiceAPI interface {
+	Export(ctx context.Context) (*Matrix, error)
+	Import(ctx context.Context, format string, body io.Reader, dryRun bool, importedBy int64) (*MatrixReport, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+	Matrix  MatrixServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI, matrix MatrixServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+		Matrix:      matrix,
+	}
+}
+
+// ListRules godoc
+// @Summary      List approval routing rules
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  RulesResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/approval-routes [get]
+func (h *Handler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.Service.ListRules(r.Context())
+	if err != nil {
+		h.Log(r).Error("ListRules: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list approval routing rules")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, RulesResponse{Rules: rules})
+}
+
+// SaveRule godoc
+// @Summary      Create or replace a category's approval routing rule
+// @Description  Expenses in the category and its subcategories can only be approved or rejected by holders of approver_permission (or admins). require_approval disables auto-approval regardless of amount.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        category  path      string       true  "Category name"
+// @Param        body      body      SaveRuleDTO  true  "Rule"
+// @Success      200       {object}  Rule
+// @Failure      400       {object}  transport.AppErrorResponse
+// @Failure      401       {object}  transport.ErrorResponse
+// @Failure      403       {object}  transport.ErrorResponse
+// @Failure      413       {object}  transport.AppErrorResponse
+// @Router       /admin/approval-routes/{category} [put]
+func (h *Handler) SaveRule(w http.ResponseWriter, r *http.Request) {
+	var dto SaveRuleDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	rule, err := h.Service.SaveRule(r.Context(), chi.URLParam(r, "category"), dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary      Remove a category's approval routing rule
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        category  path  string  true  "Category name"
+// @Success      204
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/approval-routes/{category} [delete]
+func (h *Handler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.DeleteRule(r.Context(), chi.URLParam(r, "category")); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportMatrix godoc
+// @Summary      Export the approval matrix
+// @Description  Downloads the tenant's routing rules, category amount limits and tenant-wide approval chain and thresholds as one file, in the layout PUT /admin/approval-matrix reads. In CSV the tenant-wide policy is the row whose category is *, with the approval chain space-separated in approver_permission.
+// @Tags         admin
+// @Produce      application/yaml
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        format  query     string  false  "yaml (default) or csv"
+// @Success      200     {object}  Matrix
+// @Failure      400     {object}  transport.AppErrorResponse
+// @Failure      401     {object}  transport.ErrorResponse
+// @Failure      403     {object}  transport.ErrorResponse
+// @Router       /admin/approval-matrix [get]
+func (h *Handler) ExportMatrix(w http.ResponseWriter, r *http.Request) {
+	format, err := ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	m, err := h.Matrix.Export(r.Context())
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+	body, err := EncodeMatrix(format, m)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	contentType := "application/yaml"
+	if format == FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "approval-matrix."+format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// ImportMatrix godoc
+// @Summary      Import the approval matrix
+// @Description  Replaces the tenant's routing rules and category amount limits with the file in the body, in the layout GET /admin/approval-matrix exports; categories left out lose their rule and limits. The tenant-wide approval chain and thresholds are replaced too when the file has them. The whole file is checked first: if any row has a problem nothing changes and every problem is listed in the error details. With dry_run=true nothing changes and the report says what would.
+// @Tags         admin
+// @Accept       application/yaml
+// @Accept       text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format   query     string  false  "yaml (default) or csv"
+// @Param        dry_run  query     bool    false  "Validate only"
+// @Success      200      {object}  MatrixReport
+// @Failure      400      {object}  transport.AppErrorResponse
+// @Failure      401      {object}  transport.ErrorResponse
+// @Failure      403      {object}  transport.ErrorResponse
+// @Failure      413      {object}  transport.AppErrorResponse
+// @Router       /admin/approval-matrix [put]
+func (h *Handler) ImportMatrix(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	format, err := ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxMatrixBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.HandleError(w, r, ErrMatrixTooLarge)
+			return
+		}
+		h.WriteError(w, r, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	report, err := h.Matrix.Import(r.Context(), format, bytes.NewReader(body), dryRun, user.ID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, report)
+}
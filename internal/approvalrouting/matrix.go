@@ -0,0 +1,315 @@
+package approvalrouting
+
+import (
+	"bytes"
+	"encoding/csv"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats an approval matrix is exported and imported in.
+const (
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// MaxMatrixBytes bounds an imported matrix file.
+const MaxMatrixBytes = 1 << 20
+
+// DefaultCategory names the CSV row holding the tenant-wide policy.
+const DefaultCategory = "*"
+
+// MatrixColumns are the CSV columns, in the order exports write them.
+// Header names are matched case-insensitively; only category is required.
+var MatrixColumns = []string{
+	"category",
+	"approver_permission",
+	"require_approval",
+	"min_amount_idr",
+	"max_amount_idr",
+	"auto_approval_threshold_idr",
+	"receipt_required_above_idr",
+}
+
+// Matrix is a tenant's whole approval policy: the tenant-wide approval
+// chain and thresholds, and each category's routing rule and amount limits.
+type Matrix struct {
+	// Default is nil when a file leaves the tenant-wide policy as it is.
+	Default    *MatrixDefault `json:"default,omitempty" yaml:"default,omitempty"`
+	Categories []*MatrixRule  `json:"categories" yaml:"categories"`
+}
+
+// MatrixDefault is the tenant-wide policy, which applies to categories
+// without a rule or limits of their own.
+type MatrixDefault struct {
+	// ApprovalChain lists the permissions allowed to decide expenses in
+	// categories without a rule; empty lets any approver decide.
+	ApprovalChain            []string `json:"approval_chain" yaml:"approval_chain"`
+	AutoApprovalThresholdIDR int64    `json:"auto_approval_threshold_idr" yaml:"auto_approval_threshold_idr"`
+	ReceiptRequiredAboveIDR  int64    `json:"receipt_required_above_idr" yaml:"receipt_required_above_idr"`
+	MinAmountIDR             int64    `json:"min_amount_idr" yaml:"min_amount_idr"`
+	MaxAmountIDR             int64    `json:"max_amount_idr" yaml:"max_amount_idr"`
+}
+
+// MatrixRule is one category's routing rule, when it has an approver
+// permission, and amount limits, zero when the default applies.
+type MatrixRule struct {
+	Category           string `json:"category" yaml:"category"`
+	ApproverPermission string `json:"approver_permission,omitempty" yaml:"approver_permission,omitempty"`
+	RequireApproval    bool   `json:"require_approval,omitempty" yaml:"require_approval,omitempty"`
+	MinAmountIDR       int64  `json:"min_amount_idr,omitempty" yaml:"min_amount_idr,omitempty"`
+	MaxAmountIDR       int64  `json:"max_amount_idr,omitempty" yaml:"max_amount_idr,omitempty"`
+
+	// line is where a CSV file has the rule, for problems.
+	line int
+}
+
+// MatrixProblem is why an imported matrix was refused. Line is set for CSV
+// files, counting the header as line 1.
+type MatrixProblem struct {
+	Line     int    `json:"line,omitempty"`
+	Category string `json:"category,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// MatrixChanges counts what an import does to the routing rules.
+type MatrixChanges struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+}
+
+// MatrixReport says what an import changed, or would change on a dry run.
+type MatrixReport struct {
+	DryRun bool          `json:"dry_run"`
+	Rules  MatrixChanges `json:"rules"`
+	// Settings lists the tenant settings the import overrides.
+	Settings []string        `json:"settings"`
+	Problems []MatrixProblem `json:"problems,omitempty"`
+}
+
+var (
+	ErrUnknownFormat    = errors.NewValidationFieldError("format", "format must be yaml or csv", errors.ErrCodeValidationFailed)
+	ErrMatrixTooLarge   = errors.NewRequestTooLargeError("approval matrix file is too large", errors.ErrCodeRequestTooLarge)
+	ErrMatrixMalformed  = errors.NewValidationFieldError("file", "approval matrix is not valid for its format", errors.ErrCodeInvalidImportFile)
+	ErrMatrixNoCategory = errors.NewValidationFieldError("file", "approval matrix CSV needs a category column", errors.ErrCodeInvalidImportFile)
+)
+
+func newMatrixRefusedError(report *MatrixReport) *errors.AppError {
+	message := fmt.Sprintf("approval matrix has %d problem(s); nothing was changed", len(report.Problems))
+	return errors.NewValidationError(message, errors.ErrCodeInvalidImportFile).WithDetails(report)
+}
+
+// ParseFormat returns the matrix format named by a query parameter, YAML
+// when empty.
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatYAML, "yml":
+		return FormatYAML, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// DecodeMatrix reads a matrix in format. Values that do not parse are
+// returned as problems, with whatever else of the file could be read.
+func DecodeMatrix(format string, r io.Reader) (*Matrix, []MatrixProblem, error) {
+	switch format {
+	case FormatYAML:
+		return decodeYAML(r)
+	case FormatCSV:
+		return decodeCSV(r)
+	default:
+		return nil, nil, ErrUnknownFormat
+	}
+}
+
+func decodeYAML(r io.Reader) (*Matrix, []MatrixProblem, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var m Matrix
+	if err := dec.Decode(&m); err != nil && err != io.EOF {
+		return nil, nil, errors.NewValidationFieldError("file", "approval matrix is not valid YAML: "+err.Error(), errors.ErrCodeInvalidImportFile)
+	}
+	return &m, nil, nil
+}
+
+func decodeCSV(r io.Reader) (*Matrix, []MatrixProblem, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, ErrMatrixMalformed
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a UTF-8 byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["category"]; !ok {
+		return nil, nil, ErrMatrixNoCategory
+	}
+
+	m := &Matrix{Categories: []*MatrixRule{}}
+	var problems []MatrixProblem
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if stderrors.As(err, &parseErr) {
+			problems = append(problems, MatrixProblem{Line: parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read approval matrix: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		category := field("category")
+		invalid := func(column, message string) {
+			problems = append(problems, MatrixProblem{Line: line, Category: category, Field: column, Message: message})
+		}
+		amount := func(column string) int64 {
+			raw := field(column)
+			if raw == "" {
+				return 0
+			}
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				invalid(column, column+" must be a whole number of rupiah")
+			}
+			return v
+		}
+
+		if category == DefaultCategory {
+			if m.Default != nil {
+				invalid("category", "only one row may hold the tenant-wide policy")
+				continue
+			}
+			if field("require_approval") != "" {
+				invalid("require_approval", "require_approval applies to categories; set auto_approval_threshold_idr to 0 instead")
+			}
+			m.Default = &MatrixDefault{
+				ApprovalChain:            strings.Fields(field("approver_permission")),
+				AutoApprovalThresholdIDR: amount("auto_approval_threshold_idr"),
+				ReceiptRequiredAboveIDR:  amount("receipt_required_above_idr"),
+				MinAmountIDR:             amount("min_amount_idr"),
+				MaxAmountIDR:             amount("max_amount_idr"),
+			}
+			continue
+		}
+
+		rule := &MatrixRule{
+			Category:           category,
+			ApproverPermission: field("approver_permission"),
+			MinAmountIDR:       amount("min_amount_idr"),
+			MaxAmountIDR:       amount("max_amount_idr"),
+			line:               line,
+		}
+		if raw := field("require_approval"); raw != "" {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				invalid("require_approval", "require_approval must be true or false")
+			}
+			rule.RequireApproval = v
+		}
+		for _, column := range []string{"auto_approval_threshold_idr", "receipt_required_above_idr"} {
+			if field(column) != "" {
+				invalid(column, column+" is only read from the "+DefaultCategory+" row")
+			}
+		}
+		m.Categories = append(m.Categories, rule)
+	}
+	return m, problems, nil
+}
+
+// EncodeMatrix writes m in format.
+func EncodeMatrix(format string, m *Matrix) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(m); err != nil {
+			return nil, fmt.Errorf("failed to encode approval matrix: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode approval matrix: %w", err)
+		}
+		return buf.Bytes(), nil
+	case FormatCSV:
+		return encodeCSV(m)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func encodeCSV(m *Matrix) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	amount := func(v int64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v, 10)
+	}
+
+	records := [][]string{MatrixColumns}
+	if d := m.Default; d != nil {
+		records = append(records, []string{
+			DefaultCategory,
+			strings.Join(d.ApprovalChain, " "),
+			"",
+			amount(d.MinAmountIDR),
+			amount(d.MaxAmountIDR),
+			strconv.FormatInt(d.AutoApprovalThresholdIDR, 10),
+			strconv.FormatInt(d.ReceiptRequiredAboveIDR, 10),
+		})
+	}
+	for _, rule := range m.Categories {
+		requireApproval := ""
+		if rule.ApproverPermission != "" {
+			requireApproval = strconv.FormatBool(rule.RequireApproval)
+		}
+		records = append(records, []string{
+			rule.Category,
+			rule.ApproverPermission,
+			requireApproval,
+			amount(rule.MinAmountIDR),
+			amount(rule.MaxAmountIDR),
+			"",
+			"",
+		})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to encode approval matrix: %w", err)
+	}
+	return buf.Bytes(), nil
+}
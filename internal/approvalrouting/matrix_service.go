@@ -0,0 +1,344 @@
+package approvalrouting
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+
+	routingDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalrouting"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// PolicySettings is satisfied by tenant.SettingsService.
+type PolicySettings interface {
+	Get(ctx context.Context) (*tenant.SettingsResponse, error)
+	Update(ctx context.Context, updatedBy int64, dto tenant.UpdateSettingsDTO) (*tenant.SettingsResponse, error)
+}
+
+// MatrixService exports a tenant's approval routing rules and policy
+// thresholds as one file, and imports them back in bulk.
+type MatrixService struct {
+	repo       RepositoryAPI
+	categories CategoryLookup
+	settings   PolicySettings
+	logger     *slog.Logger
+}
+
+func NewMatrixService(repo RepositoryAPI, categories CategoryLookup, settings PolicySettings, logger *slog.Logger) *MatrixService {
+	return &MatrixService{
+		repo:       repo,
+		categories: categories,
+		settings:   settings,
+		logger:     logger,
+	}
+}
+
+func (s *MatrixService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Export returns the matrix of the tenant ctx is scoped to, its categories
+// sorted by name.
+func (s *MatrixService) Export(ctx context.Context) (*Matrix, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval routing rules: %w", err)
+	}
+	settings, err := s.settings.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+
+	byCategory := make(map[string]*MatrixRule, len(rows))
+	rule := func(category string) *MatrixRule {
+		if r, ok := byCategory[category]; ok {
+			return r
+		}
+		r := &MatrixRule{Category: category}
+		byCategory[category] = r
+		return r
+	}
+	for _, row := range rows {
+		r := rule(row.Category)
+		r.ApproverPermission = row.ApproverPermission
+		r.RequireApproval = row.RequireApproval
+	}
+	for category, limits := range settings.AmountLimitsByCategory {
+		r := rule(category)
+		r.MinAmountIDR = limits.MinIDR
+		r.MaxAmountIDR = limits.MaxIDR
+	}
+
+	m := &Matrix{
+		Default: &MatrixDefault{
+			ApprovalChain:            slices.Clone(settings.ApprovalChain),
+			AutoApprovalThresholdIDR: settings.AutoApprovalThresholdIDR,
+			ReceiptRequiredAboveIDR:  settings.ReceiptRequiredAboveIDR,
+			MinAmountIDR:             settings.MinExpenseAmountIDR,
+			MaxAmountIDR:             settings.MaxExpenseAmountIDR,
+		},
+		Categories: make([]*MatrixRule, 0, len(byCategory)),
+	}
+	if m.Default.ApprovalChain == nil {
+		m.Default.ApprovalChain = []string{}
+	}
+	for _, r := range byCategory {
+		m.Categories = append(m.Categories, r)
+	}
+	sort.Slice(m.Categories, func(i, j int) bool { return m.Categories[i].Category < m.Categories[j].Category })
+	return m, nil
+}
+
+// Import replaces the tenant's routing rules and category amount limits with
+// the matrix in body, and its tenant-wide policy too when the matrix has
+// one. A file with any problem changes nothing and fails with a report
+// listing every problem. On a dry run the file is checked and the report
+// says what would change, but nothing is.
+//
+// The settings are saved before the rules, so a rejected settings update
+// leaves everything as it was.
+func (s *MatrixService) Import(ctx context.Context, format string, body io.Reader, dryRun bool, importedBy int64) (*MatrixReport, error) {
+	m, problems, err := DecodeMatrix(format, body)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.settings.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	existing, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval routing rules: %w", err)
+	}
+
+	checked, err := s.check(ctx, m, current)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, checked...)
+
+	rules, limits := planRules(m)
+	dto, changed := planSettings(m, limits, current)
+	report := &MatrixReport{
+		DryRun:   dryRun,
+		Rules:    diffRules(existing, rules),
+		Settings: changed,
+		Problems: problems,
+	}
+	if len(problems) > 0 {
+		return nil, newMatrixRefusedError(report)
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	if len(changed) > 0 {
+		if _, err := s.settings.Update(ctx, importedBy, dto); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Replace(ctx, rules); err != nil {
+		return nil, fmt.Errorf("failed to replace approval routing rules: %w", err)
+	}
+
+	s.log(ctx).Info("approval matrix imported",
+		"format", format,
+		"rules_created", report.Rules.Created,
+		"rules_updated", report.Rules.Updated,
+		"rules_deleted", report.Rules.Deleted,
+		"settings", changed,
+		"imported_by", importedBy)
+	return report, nil
+}
+
+// check lists what is wrong with m beyond what decoding found: unknown
+// categories and permissions, duplicates, and amounts that are negative or
+// leave a maximum below its minimum.
+func (s *MatrixService) check(ctx context.Context, m *Matrix, current *tenant.SettingsResponse) ([]MatrixProblem, error) {
+	var problems []MatrixProblem
+	permissions := map[string]bool{}
+	permissionExists := func(name string) (bool, error) {
+		if exists, ok := permissions[name]; ok {
+			return exists, nil
+		}
+		exists, err := s.repo.PermissionExists(ctx, name)
+		if err != nil {
+			return false, fmt.Errorf("failed to check permission: %w", err)
+		}
+		permissions[name] = exists
+		return exists, nil
+	}
+
+	minIDR, maxIDR := current.MinExpenseAmountIDR, current.MaxExpenseAmountIDR
+	if d := m.Default; d != nil {
+		invalid := func(field, message string) {
+			problems = append(problems, MatrixProblem{Category: DefaultCategory, Field: field, Message: message})
+		}
+		for _, permission := range d.ApprovalChain {
+			exists, err := permissionExists(permission)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				invalid("approval_chain", fmt.Sprintf("permission %q does not exist", permission))
+			}
+		}
+		if d.AutoApprovalThresholdIDR < 0 {
+			invalid("auto_approval_threshold_idr", "auto_approval_threshold_idr must not be negative")
+		}
+		if d.ReceiptRequiredAboveIDR < 0 {
+			invalid("receipt_required_above_idr", "receipt_required_above_idr must not be negative")
+		}
+		if d.MinAmountIDR < 1 {
+			invalid("min_amount_idr", "min_amount_idr must be at least 1")
+		}
+		if d.MaxAmountIDR < 1 {
+			invalid("max_amount_idr", "max_amount_idr must be at least 1")
+		}
+		if d.MaxAmountIDR < d.MinAmountIDR {
+			invalid("max_amount_idr", "max_amount_idr must not be below min_amount_idr")
+		}
+		minIDR, maxIDR = d.MinAmountIDR, d.MaxAmountIDR
+	}
+
+	seen := make(map[string]bool, len(m.Categories))
+	for _, rule := range m.Categories {
+		invalid := func(field, message string) {
+			problems = append(problems, MatrixProblem{Line: rule.line, Category: rule.Category, Field: field, Message: message})
+		}
+		switch {
+		case rule.Category == "":
+			invalid("category", "category is required")
+			continue
+		case seen[rule.Category]:
+			invalid("category", "category is listed more than once")
+			continue
+		case !s.categories.IsValidCategory(ctx, rule.Category):
+			invalid("category", "category does not exist")
+			continue
+		}
+		seen[rule.Category] = true
+
+		if rule.ApproverPermission != "" {
+			exists, err := permissionExists(rule.ApproverPermission)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				invalid("approver_permission", fmt.Sprintf("permission %q does not exist", rule.ApproverPermission))
+			}
+		} else if rule.RequireApproval {
+			invalid("require_approval", "require_approval needs an approver_permission")
+		}
+
+		if rule.MinAmountIDR < 0 {
+			invalid("min_amount_idr", "min_amount_idr must not be negative")
+		}
+		if rule.MaxAmountIDR < 0 {
+			invalid("max_amount_idr", "max_amount_idr must not be negative")
+		}
+		// A zero bound falls back to the tenant-wide one.
+		lo, hi := minIDR, maxIDR
+		if rule.MinAmountIDR > 0 {
+			lo = rule.MinAmountIDR
+		}
+		if rule.MaxAmountIDR > 0 {
+			hi = rule.MaxAmountIDR
+		}
+		if hi < lo {
+			invalid("max_amount_idr", fmt.Sprintf("the maximum claim amount (%d) must not be below the minimum (%d)", hi, lo))
+		}
+	}
+	return problems, nil
+}
+
+// planRules splits m into the routing rules to keep and the category
+// amount limits to set.
+func planRules(m *Matrix) ([]*routingDatamodel.Rule, map[string]tenant.AmountLimits) {
+	rules := []*routingDatamodel.Rule{}
+	limits := map[string]tenant.AmountLimits{}
+	for _, rule := range m.Categories {
+		if rule.ApproverPermission != "" {
+			rules = append(rules, &routingDatamodel.Rule{
+				Category:           rule.Category,
+				ApproverPermission: rule.ApproverPermission,
+				RequireApproval:    rule.RequireApproval,
+			})
+		}
+		if rule.MinAmountIDR != 0 || rule.MaxAmountIDR != 0 {
+			limits[rule.Category] = tenant.AmountLimits{MinIDR: rule.MinAmountIDR, MaxIDR: rule.MaxAmountIDR}
+		}
+	}
+	return rules, limits
+}
+
+// planSettings returns the settings update that applies m and the keys it
+// changes, sorted.
+func planSettings(m *Matrix, limits map[string]tenant.AmountLimits, current *tenant.SettingsResponse) (tenant.UpdateSettingsDTO, []string) {
+	var (
+		dto     tenant.UpdateSettingsDTO
+		changed = []string{}
+	)
+	if !amountLimitsEqual(current.AmountLimitsByCategory, limits) {
+		dto.AmountLimitsByCategory = limits
+		changed = append(changed, tenant.SettingAmountLimitsByCat)
+	}
+
+	if d := m.Default; d != nil {
+		if !slices.Equal(current.ApprovalChain, d.ApprovalChain) {
+			dto.ApprovalChain = append([]string{}, d.ApprovalChain...)
+			changed = append(changed, tenant.SettingApprovalChain)
+		}
+		int64Setting := func(key string, from, to int64, field **int64) {
+			if from != to {
+				*field = &to
+				changed = append(changed, key)
+			}
+		}
+		int64Setting(tenant.SettingAutoApprovalThreshold, current.AutoApprovalThresholdIDR, d.AutoApprovalThresholdIDR, &dto.AutoApprovalThresholdIDR)
+		int64Setting(tenant.SettingReceiptRequiredAbove, current.ReceiptRequiredAboveIDR, d.ReceiptRequiredAboveIDR, &dto.ReceiptRequiredAboveIDR)
+		int64Setting(tenant.SettingMinExpenseAmount, current.MinExpenseAmountIDR, d.MinAmountIDR, &dto.MinExpenseAmountIDR)
+		int64Setting(tenant.SettingMaxExpenseAmount, current.MaxExpenseAmountIDR, d.MaxAmountIDR, &dto.MaxExpenseAmountIDR)
+	}
+	sort.Strings(changed)
+	return dto, changed
+}
+
+func amountLimitsEqual(a, b map[string]tenant.AmountLimits) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for category, limits := range a {
+		if other, ok := b[category]; !ok || other != limits {
+			return false
+		}
+	}
+	return true
+}
+
+// diffRules counts how replacing existing with rules changes them.
+func diffRules(existing, rules []*routingDatamodel.Rule) MatrixChanges {
+	var changes MatrixChanges
+	before := make(map[string]*routingDatamodel.Rule, len(existing))
+	for _, row := range existing {
+		before[row.Category] = row
+	}
+	for _, rule := range rules {
+		old, ok := before[rule.Category]
+		switch {
+		case !ok:
+			changes.Created++
+		case old.ApproverPermission != rule.ApproverPermission || old.RequireApproval != rule.RequireApproval:
+			changes.Updated++
+		default:
+			changes.Unchanged++
+		}
+		delete(before, rule.Category)
+	}
+	changes.Deleted = len(before)
+	return changes
+}
@@ -0,0 +1,204 @@
+package approvalrouting_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/approvalrouting"
+	routingDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalrouting"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type mockSettings struct {
+	settings tenant.Settings
+	updates  []tenant.UpdateSettingsDTO
+}
+
+func (m *mockSettings) Get(_ context.Context) (*tenant.SettingsResponse, error) {
+	return &tenant.SettingsResponse{Settings: m.settings}, nil
+}
+
+func (m *mockSettings) Update(_ context.Context, _ int64, dto tenant.UpdateSettingsDTO) (*tenant.SettingsResponse, error) {
+	m.updates = append(m.updates, dto)
+	if dto.ApprovalChain != nil {
+		m.settings.ApprovalChain = dto.ApprovalChain
+	}
+	if dto.AutoApprovalThresholdIDR != nil {
+		m.settings.AutoApprovalThresholdIDR = *dto.AutoApprovalThresholdIDR
+	}
+	if dto.ReceiptRequiredAboveIDR != nil {
+		m.settings.ReceiptRequiredAboveIDR = *dto.ReceiptRequiredAboveIDR
+	}
+	if dto.MinExpenseAmountIDR != nil {
+		m.settings.MinExpenseAmountIDR = *dto.MinExpenseAmountIDR
+	}
+	if dto.MaxExpenseAmountIDR != nil {
+		m.settings.MaxExpenseAmountIDR = *dto.MaxExpenseAmountIDR
+	}
+	if dto.AmountLimitsByCategory != nil {
+		m.settings.AmountLimitsByCategory = dto.AmountLimitsByCategory
+	}
+	return m.Get(context.Background())
+}
+
+var _ = Describe("MatrixService", func() {
+	var (
+		ctx      context.Context
+		repo     *mockRepository
+		settings *mockSettings
+		svc      *approvalrouting.MatrixService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{
+			rules: map[string]*routingDatamodel.Rule{
+				"it_equipment": {Category: "it_equipment", ApproverPermission: "approve_it", RequireApproval: true},
+				"makan":        {Category: "makan", ApproverPermission: "admin"},
+			},
+			permissions: map[string]bool{"approve_it": true, "admin": true, "approve_finance": true},
+		}
+		settings = &mockSettings{settings: tenant.Settings{
+			AutoApprovalThresholdIDR: 1_000_000,
+			ApprovalChain:            []string{"admin"},
+			ReceiptRequiredAboveIDR:  500_000,
+			MinExpenseAmountIDR:      10_000,
+			MaxExpenseAmountIDR:      50_000_000,
+			AmountLimitsByCategory:   map[string]tenant.AmountLimits{"laptops": {MaxIDR: 30_000_000}},
+		}}
+		categories := mockCategories{"it_equipment": "", "laptops": "it_equipment", "makan": ""}
+		svc = approvalrouting.NewMatrixService(repo, categories, settings, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	importCSV := func(body string, dryRun bool) (*approvalrouting.MatrixReport, error) {
+		return svc.Import(ctx, approvalrouting.FormatCSV, strings.NewReader(body), dryRun, 1)
+	}
+
+	problemsOf := func(err error) []approvalrouting.MatrixProblem {
+		appErr, ok := err.(*errors.AppError)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeInvalidImportFile))
+		report, ok := appErr.Details.(*approvalrouting.MatrixReport)
+		Expect(ok).To(BeTrue())
+		return report.Problems
+	}
+
+	Describe("Export", func() {
+		It("merges the routing rules with the category limits", func() {
+			m, err := svc.Export(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Default.ApprovalChain).To(Equal([]string{"admin"}))
+			Expect(m.Categories).To(HaveLen(3))
+			Expect(m.Categories[0].Category).To(Equal("it_equipment"))
+			Expect(m.Categories[1].Category).To(Equal("laptops"))
+			Expect(m.Categories[1].ApproverPermission).To(BeEmpty())
+			Expect(m.Categories[1].MaxAmountIDR).To(Equal(int64(30_000_000)))
+		})
+
+		for _, format := range []string{approvalrouting.FormatCSV, approvalrouting.FormatYAML} {
+			format := format
+			It("imports back unchanged as "+format, func() {
+				m, err := svc.Export(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				body, err := approvalrouting.EncodeMatrix(format, m)
+				Expect(err).NotTo(HaveOccurred())
+
+				report, err := svc.Import(ctx, format, bytes.NewReader(body), false, 1)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Rules).To(Equal(approvalrouting.MatrixChanges{Unchanged: 2}))
+				Expect(report.Settings).To(BeEmpty())
+				Expect(settings.updates).To(BeEmpty())
+			})
+		}
+	})
+
+	Describe("Import", func() {
+		const file = "category,approver_permission,require_approval,min_amount_idr,max_amount_idr,auto_approval_threshold_idr,receipt_required_above_idr\n" +
+			"*,admin approve_finance,,10000,50000000,0,500000\n" +
+			"it_equipment,approve_finance,true,,,,\n" +
+			"laptops,approve_it,false,,25000000,,\n"
+
+		It("replaces the rules and changed settings", func() {
+			report, err := importCSV(file, false)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Rules).To(Equal(approvalrouting.MatrixChanges{Created: 1, Updated: 1, Deleted: 1}))
+			Expect(report.Settings).To(Equal([]string{
+				tenant.SettingApprovalChain,
+				tenant.SettingAutoApprovalThreshold,
+				tenant.SettingAmountLimitsByCat,
+			}))
+			Expect(repo.rules).To(HaveLen(2))
+			Expect(repo.rules).NotTo(HaveKey("makan"))
+			Expect(repo.rules["it_equipment"].ApproverPermission).To(Equal("approve_finance"))
+			Expect(settings.settings.ApprovalChain).To(Equal([]string{"admin", "approve_finance"}))
+			Expect(settings.settings.AutoApprovalThresholdIDR).To(BeZero())
+			Expect(settings.settings.AmountLimitsByCategory).To(Equal(map[string]tenant.AmountLimits{"laptops": {MaxIDR: 25_000_000}}))
+		})
+
+		It("changes nothing on a dry run", func() {
+			report, err := importCSV(file, true)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.DryRun).To(BeTrue())
+			Expect(report.Rules.Created).To(Equal(1))
+			Expect(repo.rules).To(HaveKey("makan"))
+			Expect(settings.updates).To(BeEmpty())
+		})
+
+		It("leaves the tenant-wide policy alone without a * row", func() {
+			report, err := importCSV("category,approver_permission\nmakan,admin\n", false)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Settings).To(Equal([]string{tenant.SettingAmountLimitsByCat}))
+			Expect(settings.settings.ApprovalChain).To(Equal([]string{"admin"}))
+			Expect(settings.settings.AmountLimitsByCategory).To(BeEmpty())
+		})
+
+		It("lists every problem and changes nothing", func() {
+			_, err := importCSV("category,approver_permission,require_approval,max_amount_idr\n"+
+				"unknown,admin,,\n"+
+				"makan,approve_nothing,,\n"+
+				"makan,admin,,\n"+
+				"laptops,,true,5000\n"+
+				"it_equipment,admin,maybe,lots\n", false)
+
+			problems := problemsOf(err)
+			Expect(problems).To(ConsistOf(
+				approvalrouting.MatrixProblem{Line: 2, Category: "unknown", Field: "category", Message: "category does not exist"},
+				approvalrouting.MatrixProblem{Line: 3, Category: "makan", Field: "approver_permission", Message: `permission "approve_nothing" does not exist`},
+				approvalrouting.MatrixProblem{Line: 4, Category: "makan", Field: "category", Message: "category is listed more than once"},
+				approvalrouting.MatrixProblem{Line: 5, Category: "laptops", Field: "require_approval", Message: "require_approval needs an approver_permission"},
+				approvalrouting.MatrixProblem{Line: 5, Category: "laptops", Field: "max_amount_idr", Message: "the maximum claim amount (5000) must not be below the minimum (10000)"},
+				approvalrouting.MatrixProblem{Line: 6, Category: "it_equipment", Field: "max_amount_idr", Message: "max_amount_idr must be a whole number of rupiah"},
+				approvalrouting.MatrixProblem{Line: 6, Category: "it_equipment", Field: "require_approval", Message: "require_approval must be true or false"},
+			))
+			Expect(repo.rules).To(HaveLen(2))
+			Expect(settings.updates).To(BeEmpty())
+		})
+
+		It("refuses unknown YAML fields", func() {
+			_, err := svc.Import(ctx, approvalrouting.FormatYAML, strings.NewReader("categories:\n  - category: makan\n    approver: admin\n"), false, 1)
+
+			appErr, ok := err.(*errors.AppError)
+			Expect(ok).To(BeTrue())
+			Expect(appErr.Type).To(Equal(errors.ErrorTypeValidation))
+			Expect(repo.rules).To(HaveLen(2))
+		})
+
+		It("refuses a CSV file without a category column", func() {
+			_, err := importCSV("approver_permission\nadmin\n", false)
+
+			Expect(err).To(Equal(approvalrouting.ErrMatrixNoCategory))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/frahmantamala/expense-management/internal/approvalrouting"
+	routingDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalrouting"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoutingRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutingRepository(db *gorm.DB) approvalrouting.RepositoryAPI {
+	return &RoutingRepository{db: db}
+}
+
+func (r *RoutingRepository) List(ctx context.Context) ([]*routingDatamodel.Rule, error) {
+	var rules []*routingDatamodel.Rule
+	err := r.db.WithContext(ctx).Order("category ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *RoutingRepository) GetByCategories(ctx context.Context, categories []string) ([]*routingDatamodel.Rule, error) {
+	var rules []*routingDatamodel.Rule
+	err := r.db.WithContext(ctx).Where("category IN ?", categories).Find(&rules).Error
+	return rules, err
+}
+
+func (r *RoutingRepository) Save(ctx context.Context, rule *routingDatamodel.Rule) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"approver_permission", "require_approval", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func (r *RoutingRepository) Replace(ctx context.Context, rules []*routingDatamodel.Rule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		categories := make([]string, len(rules))
+		for i, rule := range rules {
+			categories[i] = rule.Category
+		}
+		stale := tx.Where("TRUE")
+		if len(categories) > 0 {
+			stale = tx.Where("category NOT IN ?", categories)
+		}
+		if err := stale.Delete(&routingDatamodel.Rule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "category"}},
+			DoUpdates: clause.AssignmentColumns([]string{"approver_permission", "require_approval", "updated_at"}),
+		}).Create(rules).Error
+	})
+}
+
+func (r *RoutingRepository) Delete(ctx context.Context, category string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("category = ?", category).Delete(&routingDatamodel.Rule{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *RoutingRepository) PermissionExists(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("permissions").Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
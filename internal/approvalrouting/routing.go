@@ -0,0 +1,49 @@
+package approvalrouting
+
+import (
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	routingDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalrouting"
+)
+
+// Rule routes every expense in Category, and in its subcategories, to holders
+// of ApproverPermission.
+type Rule struct {
+	Category           string    `json:"category"`
+	ApproverPermission string    `json:"approver_permission"`
+	RequireApproval    bool      `json:"require_approval"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type SaveRuleDTO struct {
+	ApproverPermission string `json:"approver_permission" validate:"required,max=255"`
+	// RequireApproval disables auto-approval for small amounts.
+	RequireApproval bool `json:"require_approval"`
+}
+
+func (dto SaveRuleDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+type RulesResponse struct {
+	Rules []*Rule `json:"rules"`
+}
+
+var (
+	ErrRuleNotFound      = errors.NewNotFoundError("Approval routing rule not found", errors.ErrCodeRoutingRuleNotFound)
+	ErrUnknownPermission = errors.NewLocalizedFieldError("approver_permission", "validation.permission", nil, errors.ErrCodeValidationFailed)
+)
+
+func fromDataModel(r *routingDatamodel.Rule) *Rule {
+	return &Rule{
+		Category:           r.Category,
+		ApproverPermission: r.ApproverPermission,
+		RequireApproval:    r.RequireApproval,
+		UpdatedAt:          r.UpdatedAt,
+	}
+}
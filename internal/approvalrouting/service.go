@@ -0,0 +1,132 @@
+package approvalrouting
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	routingDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalrouting"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type RepositoryAPI interface {
+	List(ctx context.Context) ([]*routingDatamodel.Rule, error)
+	GetByCategories(ctx context.Context, categories []string) ([]*routingDatamodel.Rule, error)
+	Save(ctx context.Context, rule *routingDatamodel.Rule) error
+	// Replace makes rules the tenant's only rules, in one transaction.
+	Replace(ctx context.Context, rules []*routingDatamodel.Rule) error
+	Delete(ctx context.Context, category string) (bool, error)
+	PermissionExists(ctx context.Context, name string) (bool, error)
+}
+
+// CategoryLookup is satisfied by category.Service.
+type CategoryLookup interface {
+	IsValidCategory(ctx context.Context, name string) bool
+	AncestorNames(ctx context.Context, name string) ([]string, error)
+}
+
+type Service struct {
+	repo       RepositoryAPI
+	categories CategoryLookup
+	logger     *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, categories CategoryLookup, logger *slog.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		categories: categories,
+		logger:     logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) ListRules(ctx context.Context) ([]*Rule, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval routing rules: %w", err)
+	}
+
+	rules := make([]*Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = fromDataModel(row)
+	}
+	return rules, nil
+}
+
+func (s *Service) SaveRule(ctx context.Context, category string, dto SaveRuleDTO) (*Rule, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	if !s.categories.IsValidCategory(ctx, category) {
+		return nil, expense.ErrInvalidCategory
+	}
+
+	exists, err := s.repo.PermissionExists(ctx, dto.ApproverPermission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !exists {
+		return nil, ErrUnknownPermission
+	}
+
+	row := &routingDatamodel.Rule{
+		Category:           category,
+		ApproverPermission: dto.ApproverPermission,
+		RequireApproval:    dto.RequireApproval,
+	}
+	if err := s.repo.Save(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to save approval routing rule: %w", err)
+	}
+
+	s.log(ctx).Info("approval routing rule saved",
+		"category", category,
+		"approver_permission", dto.ApproverPermission,
+		"require_approval", dto.RequireApproval)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) DeleteRule(ctx context.Context, category string) error {
+	deleted, err := s.repo.Delete(ctx, category)
+	if err != nil {
+		return fmt.Errorf("failed to delete approval routing rule: %w", err)
+	}
+	if !deleted {
+		return ErrRuleNotFound
+	}
+
+	s.log(ctx).Info("approval routing rule deleted", "category", category)
+	return nil
+}
+
+// RouteFor returns the rule of the category or of its nearest ancestor that
+// has one, so a rule on a parent covers all of its subcategories.
+func (s *Service) RouteFor(ctx context.Context, category string) (*expense.ApprovalRoute, error) {
+	ancestors, err := s.categories.AncestorNames(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve category ancestors: %w", err)
+	}
+	candidates := append([]string{category}, ancestors...)
+
+	rows, err := s.repo.GetByCategories(ctx, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approval routing rules: %w", err)
+	}
+
+	byCategory := make(map[string]*routingDatamodel.Rule, len(rows))
+	for _, row := range rows {
+		byCategory[row.Category] = row
+	}
+	for _, name := range candidates {
+		if row, ok := byCategory[name]; ok {
+			return &expense.ApprovalRoute{
+				ApproverPermission: row.ApproverPermission,
+				RequireApproval:    row.RequireApproval,
+			}, nil
+		}
+	}
+	return nil, nil
+}
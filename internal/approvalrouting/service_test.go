@@ -0,0 +1,157 @@
+package approvalrouting_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/approvalrouting"
+	routingDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/approvalrouting"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+type mockRepository struct {
+	rules       map[string]*routingDatamodel.Rule
+	permissions map[string]bool
+}
+
+func (m *mockRepository) List(_ context.Context) ([]*routingDatamodel.Rule, error) {
+	var out []*routingDatamodel.Rule
+	for _, r := range m.rules {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Category < out[j].Category })
+	return out, nil
+}
+
+func (m *mockRepository) GetByCategories(_ context.Context, categories []string) ([]*routingDatamodel.Rule, error) {
+	var out []*routingDatamodel.Rule
+	for _, c := range categories {
+		if r, ok := m.rules[c]; ok {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) Save(_ context.Context, rule *routingDatamodel.Rule) error {
+	m.rules[rule.Category] = rule
+	return nil
+}
+
+func (m *mockRepository) Replace(_ context.Context, rules []*routingDatamodel.Rule) error {
+	m.rules = make(map[string]*routingDatamodel.Rule, len(rules))
+	for _, rule := range rules {
+		m.rules[rule.Category] = rule
+	}
+	return nil
+}
+
+func (m *mockRepository) Delete(_ context.Context, category string) (bool, error) {
+	_, ok := m.rules[category]
+	delete(m.rules, category)
+	return ok, nil
+}
+
+func (m *mockRepository) PermissionExists(_ context.Context, name string) (bool, error) {
+	return m.permissions[name], nil
+}
+
+// mockCategories maps each category to its parent.
+type mockCategories map[string]string
+
+func (m mockCategories) IsValidCategory(_ context.Context, name string) bool {
+	_, ok := m[name]
+	return ok
+}
+
+func (m mockCategories) AncestorNames(_ context.Context, name string) ([]string, error) {
+	var out []string
+	for parent := m[name]; parent != ""; parent = m[parent] {
+		out = append(out, parent)
+	}
+	return out, nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx  context.Context
+		repo *mockRepository
+		svc  *approvalrouting.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{
+			rules:       map[string]*routingDatamodel.Rule{},
+			permissions: map[string]bool{"approve_it": true, "admin": true},
+		}
+		categories := mockCategories{"it_equipment": "", "laptops": "it_equipment", "makan": ""}
+		svc = approvalrouting.NewService(repo, categories, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	Describe("SaveRule", func() {
+		It("stores a rule for a known category and permission", func() {
+			rule, err := svc.SaveRule(ctx, "it_equipment", approvalrouting.SaveRuleDTO{ApproverPermission: "approve_it", RequireApproval: true})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rule.Category).To(Equal("it_equipment"))
+			Expect(repo.rules).To(HaveKey("it_equipment"))
+		})
+
+		It("rejects unknown categories", func() {
+			_, err := svc.SaveRule(ctx, "nope", approvalrouting.SaveRuleDTO{ApproverPermission: "approve_it"})
+			Expect(err).To(MatchError(expense.ErrInvalidCategory))
+		})
+
+		It("rejects unknown permissions", func() {
+			_, err := svc.SaveRule(ctx, "makan", approvalrouting.SaveRuleDTO{ApproverPermission: "approve_snacks"})
+			Expect(err).To(MatchError(approvalrouting.ErrUnknownPermission))
+		})
+
+		It("requires an approver permission", func() {
+			_, err := svc.SaveRule(ctx, "makan", approvalrouting.SaveRuleDTO{})
+
+			appErr, ok := errors.IsAppError(err)
+			Expect(ok).To(BeTrue())
+			Expect(appErr.Error()).To(Equal("approver_permission is required"))
+		})
+	})
+
+	Describe("RouteFor", func() {
+		BeforeEach(func() {
+			_, err := svc.SaveRule(ctx, "it_equipment", approvalrouting.SaveRuleDTO{ApproverPermission: "approve_it", RequireApproval: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the category's own rule", func() {
+			Expect(svc.RouteFor(ctx, "it_equipment")).To(Equal(&expense.ApprovalRoute{ApproverPermission: "approve_it", RequireApproval: true}))
+		})
+
+		It("inherits the nearest ancestor's rule", func() {
+			Expect(svc.RouteFor(ctx, "laptops")).To(Equal(&expense.ApprovalRoute{ApproverPermission: "approve_it", RequireApproval: true}))
+		})
+
+		It("prefers a subcategory's own rule over its parent's", func() {
+			_, err := svc.SaveRule(ctx, "laptops", approvalrouting.SaveRuleDTO{ApproverPermission: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(svc.RouteFor(ctx, "laptops")).To(Equal(&expense.ApprovalRoute{ApproverPermission: "admin"}))
+		})
+
+		It("returns nil when no rule applies", func() {
+			Expect(svc.RouteFor(ctx, "makan")).To(BeNil())
+		})
+	})
+
+	Describe("DeleteRule", func() {
+		It("reports missing rules", func() {
+			Expect(svc.DeleteRule(ctx, "makan")).To(MatchError(approvalrouting.ErrRuleNotFound))
+		})
+	})
+})
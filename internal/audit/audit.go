@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+)
+
+const (
+	ActionPaymentCallbackMismatch = "payment.callback_mismatch"
+	ActionExpenseApprovedViaLink  = "expense.approved_via_link"
+	ActionExpenseRejectedViaLink  = "expense.rejected_via_link"
+	ActionApprovalLinkRefused     = "approval_link.refused"
+	ActionExpenseApprovedViaSlack = "expense.approved_via_slack"
+	ActionExpenseRejectedViaSlack = "expense.rejected_via_slack"
+
+	ActionExpenseApprovedViaIntegration = "expense.approved_via_integration"
+	ActionExpenseRejectedViaIntegration = "expense.rejected_via_integration"
+
+	ActionExpenseStatusChanged      = "expense.status_changed"
+	ActionPaymentCallbackOutOfOrder = "payment.callback_out_of_order"
+	ActionPaymentRequeued           = "payment.requeued"
+	ActionPaymentCancelled          = "payment.cancelled"
+	ActionPaymentWebhookReplayed    = "payment.webhook_replayed"
+
+	ActionPermissionGranted = "user.permission_granted"
+	ActionPermissionRevoked = "user.permission_revoked"
+
+	ActionImpersonationStarted = "user.impersonation_started"
+	ActionImpersonatedRequest  = "user.impersonated_request"
+
+	ActionDelegationGranted      = "delegation.granted"
+	ActionDelegationRevoked      = "delegation.revoked"
+	ActionExpenseFiledAsDelegate = "expense.filed_as_delegate"
+
+	ActionUserErased = "user.erased"
+)
+
+const (
+	ResourcePayment = "payment"
+	ResourceExpense = "expense"
+	ResourceUser    = "user"
+
+	ResourceDelegation = "delegation"
+)
+
+type Entry struct {
+	ID           int64                  `json:"id"`
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	ActorID      *int64                 `json:"actor_id,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	// Seq and Hash place the entry in the hash chain; see chain.go.
+	Seq  *int64 `json:"seq,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+func ToDataModel(e *Entry) (*auditDatamodel.AuditLog, error) {
+	var metadata json.RawMessage
+	if len(e.Metadata) > 0 {
+		raw, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		metadata = raw
+	}
+
+	return &auditDatamodel.AuditLog{
+		ID:           e.ID,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		ActorID:      e.ActorID,
+		Metadata:     metadata,
+		CreatedAt:    e.CreatedAt,
+	}, nil
+}
+
+func FromDataModel(l *auditDatamodel.AuditLog) *Entry {
+	entry := &Entry{
+		ID:           l.ID,
+		Action:       l.Action,
+		ResourceType: l.ResourceType,
+		ResourceID:   l.ResourceID,
+		ActorID:      l.ActorID,
+		CreatedAt:    l.CreatedAt,
+		Seq:          l.Seq,
+		Hash:         l.Hash,
+	}
+	if len(l.Metadata) > 0 {
+		_ = json.Unmarshal(l.Metadata, &entry.Metadata)
+	}
+	return entry
+}
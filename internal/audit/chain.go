@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+)
+
+// Audit log entries form a hash chain: each stores the hash of the entry
+// before it, and its own hash covers that and its content. Editing an entry
+// changes its hash; deleting one leaves a gap in Seq and a PrevHash that no
+// longer matches. The newest hash also goes to the sink, so an external copy
+// anchors the end of the chain.
+
+// Problem kinds found by VerifyChain.
+const (
+	// ProblemTampered entries no longer match their own hash.
+	ProblemTampered = "tampered"
+	// ProblemGap is reported where entries are missing from the chain.
+	ProblemGap = "gap"
+	// ProblemBrokenLink entries do not carry the hash of the entry before
+	// them.
+	ProblemBrokenLink = "broken_link"
+	// ProblemUnchained entries were written after the chain started but
+	// outside it, such as rows inserted by hand.
+	ProblemUnchained = "unchained"
+)
+
+// ChainProblem is one place where the audit log was altered.
+type ChainProblem struct {
+	ID      int64  `json:"id"`
+	Seq     *int64 `json:"seq,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// ChainReport sums up a verification of the audit log.
+type ChainReport struct {
+	// Entries counts the chained entries checked, from FirstSeq to LastSeq.
+	// Retention purges the oldest entries, so FirstSeq may be above 1.
+	Entries  int64          `json:"entries"`
+	FirstSeq int64          `json:"first_seq"`
+	LastSeq  int64          `json:"last_seq"`
+	LastHash string         `json:"last_hash"`
+	Problems []ChainProblem `json:"problems"`
+}
+
+// chainedEntry is the content an entry's hash covers, in a fixed order.
+type chainedEntry struct {
+	Seq          int64           `json:"seq"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	ActorID      *int64          `json:"actor_id"`
+	Metadata     json.RawMessage `json:"metadata"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// Seal makes l the entry after prev, or the first entry of the chain when
+// prev is nil. l.CreatedAt is cut to the microseconds Postgres keeps, so the
+// stored entry still matches its hash.
+func Seal(l *auditDatamodel.AuditLog, prev *auditDatamodel.AuditLog) error {
+	seq := int64(1)
+	l.PrevHash = ""
+	if prev != nil && prev.Seq != nil {
+		seq = *prev.Seq + 1
+		l.PrevHash = prev.Hash
+	}
+	l.Seq = &seq
+	l.CreatedAt = l.CreatedAt.Truncate(time.Microsecond)
+
+	hash, err := ChainHash(l)
+	if err != nil {
+		return err
+	}
+	l.Hash = hash
+	return nil
+}
+
+// ChainHash returns the hex SHA-256 of l's PrevHash and content.
+func ChainHash(l *auditDatamodel.AuditLog) (string, error) {
+	if l.Seq == nil {
+		return "", fmt.Errorf("audit log entry %d is not chained", l.ID)
+	}
+
+	// Postgres rewrites jsonb, so metadata is hashed in Go's encoding,
+	// which sorts object keys.
+	var metadata json.RawMessage
+	if len(l.Metadata) > 0 {
+		var decoded interface{}
+		if err := json.Unmarshal(l.Metadata, &decoded); err != nil {
+			return "", fmt.Errorf("failed to decode audit metadata: %w", err)
+		}
+		canonical, err := json.Marshal(decoded)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode audit metadata: %w", err)
+		}
+		metadata = canonical
+	}
+
+	content, err := json.Marshal(chainedEntry{
+		Seq:          *l.Seq,
+		Action:       l.Action,
+		ResourceType: l.ResourceType,
+		ResourceID:   l.ResourceID,
+		ActorID:      l.ActorID,
+		Metadata:     metadata,
+		CreatedAt:    l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(l.PrevHash))
+	h.Write([]byte("\n"))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// check adds the problems of l, the chained entry after prev, to report.
+// prev is nil for the oldest entry kept, whose predecessor may have been
+// purged.
+func (report *ChainReport) check(l, prev *auditDatamodel.AuditLog) {
+	seq := *l.Seq
+	hash, err := ChainHash(l)
+	if err != nil || hash != l.Hash {
+		report.add(l, ProblemTampered, "entry does not match its hash")
+	}
+
+	switch {
+	case prev == nil:
+		report.FirstSeq = seq
+	case seq != *prev.Seq+1:
+		report.add(l, ProblemGap, fmt.Sprintf("entries %d to %d are missing", *prev.Seq+1, seq-1))
+	case l.PrevHash != prev.Hash:
+		report.add(l, ProblemBrokenLink, fmt.Sprintf("previous hash does not match entry %d", *prev.Seq))
+	}
+
+	report.Entries++
+	report.LastSeq = seq
+	report.LastHash = l.Hash
+}
+
+func (report *ChainReport) add(l *auditDatamodel.AuditLog, kind, message string) {
+	report.Problems = append(report.Problems, ChainProblem{ID: l.ID, Seq: l.Seq, Kind: kind, Message: message})
+}
@@ -0,0 +1,127 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+)
+
+var _ = Describe("Hash chain", func() {
+	var (
+		repo    *memoryRepository
+		service *audit.Service
+	)
+
+	BeforeEach(func() {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		repo = &memoryRepository{}
+		service = audit.NewService(repo, nil, logger)
+
+		at := time.Date(2025, 11, 14, 9, 0, 0, 123456789, time.UTC)
+		for i := 1; i <= 5; i++ {
+			Expect(service.Record(&audit.Entry{
+				Action:       audit.ActionExpenseStatusChanged,
+				ResourceType: audit.ResourceExpense,
+				ResourceID:   fmt.Sprint(i),
+				Metadata:     map[string]interface{}{"from": "pending_approval", "to": "approved", "amount_idr": 150000},
+				CreatedAt:    at.Add(time.Duration(i) * time.Minute),
+			})).To(Succeed())
+		}
+	})
+
+	kinds := func(report *audit.ChainReport) []string {
+		var out []string
+		for _, p := range report.Problems {
+			out = append(out, p.Kind)
+		}
+		return out
+	}
+
+	It("links each entry to the one before", func() {
+		Expect(*repo.logs[0].Seq).To(Equal(int64(1)))
+		Expect(repo.logs[0].PrevHash).To(BeEmpty())
+		Expect(repo.logs[1].PrevHash).To(Equal(repo.logs[0].Hash))
+		Expect(repo.logs[0].CreatedAt.Nanosecond() % 1000).To(BeZero())
+	})
+
+	It("verifies an untouched log", func() {
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Problems).To(BeEmpty())
+		Expect(report.Entries).To(Equal(int64(5)))
+		Expect(report.FirstSeq).To(Equal(int64(1)))
+		Expect(report.LastSeq).To(Equal(int64(5)))
+		Expect(report.LastHash).To(Equal(repo.logs[4].Hash))
+	})
+
+	It("accepts metadata rewritten the way jsonb stores it", func() {
+		repo.logs[2].Metadata = json.RawMessage(`{"to": "approved", "from": "pending_approval", "amount_idr": 150000}`)
+
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Problems).To(BeEmpty())
+	})
+
+	It("finds an edited entry", func() {
+		repo.logs[2].Metadata = json.RawMessage(`{"from":"pending_approval","to":"rejected","amount_idr":150000}`)
+
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds(report)).To(Equal([]string{audit.ProblemTampered}))
+		Expect(*report.Problems[0].Seq).To(Equal(int64(3)))
+	})
+
+	It("finds an entry edited along with its hash", func() {
+		repo.logs[2].ResourceID = "99"
+		hash, err := audit.ChainHash(repo.logs[2])
+		Expect(err).NotTo(HaveOccurred())
+		repo.logs[2].Hash = hash
+
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds(report)).To(Equal([]string{audit.ProblemBrokenLink}))
+		Expect(*report.Problems[0].Seq).To(Equal(int64(4)))
+	})
+
+	It("finds deleted entries", func() {
+		repo.logs = append(repo.logs[:1], repo.logs[3:]...)
+
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds(report)).To(Equal([]string{audit.ProblemGap}))
+		Expect(report.Problems[0].Message).To(Equal("entries 2 to 3 are missing"))
+	})
+
+	It("starts from the oldest entry kept after a purge", func() {
+		repo.logs = repo.logs[2:]
+
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Problems).To(BeEmpty())
+		Expect(report.FirstSeq).To(Equal(int64(3)))
+	})
+
+	It("finds entries written outside the chain", func() {
+		repo.logs = append(repo.logs, &auditDatamodel.AuditLog{ID: 6, Action: audit.ActionPaymentCancelled, ResourceType: audit.ResourcePayment, ResourceID: "1"})
+
+		report, err := service.VerifyChain()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds(report)).To(Equal([]string{audit.ProblemUnchained}))
+		Expect(report.Problems[0].ID).To(Equal(int64(6)))
+	})
+})
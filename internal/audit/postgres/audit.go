@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"github.com/frahmantamala/expense-management/internal/audit"
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) audit.RepositoryAPI {
+	return &AuditRepository{db: db}
+}
+
+// chainLockKey is the transaction advisory lock that serializes appends to
+// the hash chain across instances, so no two entries claim the same Seq.
+const chainLockKey int64 = 0x61756469745f6c67 // "audit_lg"
+
+func (r *AuditRepository) Create(log *auditDatamodel.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockKey).Error; err != nil {
+			return err
+		}
+
+		var last []*auditDatamodel.AuditLog
+		if err := tx.Where("seq IS NOT NULL").Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		var prev *auditDatamodel.AuditLog
+		if len(last) == 1 {
+			prev = last[0]
+		}
+		if err := audit.Seal(log, prev); err != nil {
+			return err
+		}
+		return tx.Create(log).Error
+	})
+}
+
+func (r *AuditRepository) ListByResource(resourceType, resourceID string) ([]*auditDatamodel.AuditLog, error) {
+	var logs []*auditDatamodel.AuditLog
+	err := r.db.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *AuditRepository) ListChain(afterSeq int64, limit int) ([]*auditDatamodel.AuditLog, error) {
+	var logs []*auditDatamodel.AuditLog
+	err := r.db.Where("seq > ?", afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *AuditRepository) ListUnchained(afterID int64, limit int) ([]*auditDatamodel.AuditLog, error) {
+	var logs []*auditDatamodel.AuditLog
+	err := r.db.Where("seq IS NULL AND id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
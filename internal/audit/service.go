@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+)
+
+type RepositoryAPI interface {
+	// Create seals log onto the end of the hash chain and writes it.
+	Create(log *auditDatamodel.AuditLog) error
+	ListByResource(resourceType, resourceID string) ([]*auditDatamodel.AuditLog, error)
+	// ListChain returns up to limit chained entries after afterSeq, in
+	// Seq order.
+	ListChain(afterSeq int64, limit int) ([]*auditDatamodel.AuditLog, error)
+	// ListUnchained returns up to limit entries without a Seq whose ID is
+	// above afterID.
+	ListUnchained(afterID int64, limit int) ([]*auditDatamodel.AuditLog, error)
+}
+
+const (
+	verifyBatchSize = 1000
+	// maxUnchained bounds the unchained entries VerifyChain lists.
+	maxUnchained = 100
+)
+
+type Service struct {
+	repo   RepositoryAPI
+	sink   *Sink
+	logger *slog.Logger
+}
+
+// NewService takes a nil sink when entries only go to the audit_logs table.
+func NewService(repo RepositoryAPI, sink *Sink, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		sink:   sink,
+		logger: logger,
+	}
+}
+
+func (s *Service) Record(entry *Entry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	data, err := ToDataModel(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	if err := s.repo.Create(data); err != nil {
+		s.logger.Error("failed to write audit log",
+			"error", err,
+			"action", entry.Action,
+			"resource_type", entry.ResourceType,
+			"resource_id", entry.ResourceID)
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	entry.ID = data.ID
+	entry.Seq = data.Seq
+	entry.Hash = data.Hash
+	s.sink.Write(context.Background(), entry)
+	return nil
+}
+
+func (s *Service) ListByResource(resourceType, resourceID string) ([]*Entry, error) {
+	logs, err := s.repo.ListByResource(resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(logs))
+	for _, l := range logs {
+		entries = append(entries, FromDataModel(l))
+	}
+	return entries, nil
+}
+
+// VerifyChain walks the hash chain from its oldest entry and reports every
+// entry that was edited, deleted or slipped in outside the chain. Entries
+// written before the chain existed are not checked.
+func (s *Service) VerifyChain() (*ChainReport, error) {
+	report := &ChainReport{Problems: []ChainProblem{}}
+
+	var prev, first *auditDatamodel.AuditLog
+	afterSeq := int64(0)
+	for {
+		batch, err := s.repo.ListChain(afterSeq, verifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		for _, l := range batch {
+			if first == nil {
+				first = l
+			}
+			report.check(l, prev)
+			prev = l
+		}
+		if len(batch) < verifyBatchSize {
+			break
+		}
+		afterSeq = *prev.Seq
+	}
+	if first == nil {
+		return report, nil
+	}
+
+	unchained, err := s.repo.ListUnchained(first.ID, maxUnchained)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	for _, l := range unchained {
+		report.add(l, ProblemUnchained, "entry was written outside the chain")
+	}
+
+	s.logger.Info("verified audit log chain",
+		"entries", report.Entries,
+		"first_seq", report.FirstSeq,
+		"last_seq", report.LastSeq,
+		"problems", len(report.Problems))
+	return report, nil
+}
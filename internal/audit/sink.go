@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+)
+
+// warnActions are the refusals and anomalies a sink at level warn keeps;
+// everything else is written at info.
+var warnActions = map[string]bool{
+	ActionApprovalLinkRefused:       true,
+	ActionPaymentCallbackMismatch:   true,
+	ActionPaymentCallbackOutOfOrder: true,
+	events.EventTypePaymentFailed:   true,
+}
+
+// Sink writes business events as JSON lines to a destination of their own,
+// so they can be shipped and retained apart from the application log. A nil
+// *Sink discards everything.
+type Sink struct {
+	handler slog.Handler
+	closer  io.Closer
+	logger  *slog.Logger
+}
+
+// NewSink writes JSON lines at level or above to w; logger reports lines
+// that could not be written.
+func NewSink(w io.Writer, level slog.Level, logger *slog.Logger) *Sink {
+	return &Sink{
+		handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}),
+		logger:  logger,
+	}
+}
+
+// OpenSink returns nil when cfg has no driver. A file sink appends to
+// cfg.Path, creating it and its directory; a webhook sink POSTs each line to
+// cfg.WebhookURL.
+func OpenSink(cfg internal.AuditLogConfig, logger *slog.Logger) (*Sink, error) {
+	level := slog.LevelInfo
+	if cfg.Level == "warn" {
+		level = slog.LevelWarn
+	}
+
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "file":
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+		f, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		sink := NewSink(f, level, logger)
+		sink.closer = f
+		return sink, nil
+	case "webhook":
+		timeout := cfg.Timeout
+		if timeout == 0 {
+			timeout = 5 * time.Second
+		}
+		return NewSink(&webhookWriter{url: cfg.WebhookURL, client: &http.Client{Timeout: timeout}}, level, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown audit log driver %q", cfg.Driver)
+	}
+}
+
+// Write writes entry as one line, timestamped with its CreatedAt. Failures
+// are logged rather than returned: the audit_logs row is already written.
+func (s *Sink) Write(ctx context.Context, entry *Entry) {
+	if s == nil {
+		return
+	}
+
+	level := slog.LevelInfo
+	if warnActions[entry.Action] {
+		level = slog.LevelWarn
+	}
+	if !s.handler.Enabled(ctx, level) {
+		return
+	}
+
+	createdAt := entry.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	record := slog.NewRecord(createdAt.UTC(), level, entry.Action, 0)
+	record.AddAttrs(
+		slog.String("resource_type", entry.ResourceType),
+		slog.String("resource_id", entry.ResourceID),
+	)
+	if entry.ActorID != nil {
+		record.AddAttrs(slog.Int64("actor_id", *entry.ActorID))
+	}
+	if len(entry.Metadata) > 0 {
+		record.AddAttrs(slog.Any("metadata", entry.Metadata))
+	}
+	if entry.Seq != nil {
+		record.AddAttrs(slog.Int64("seq", *entry.Seq), slog.String("hash", entry.Hash))
+	}
+
+	if err := s.handler.Handle(ctx, record); err != nil {
+		s.logger.Error("failed to write audit sink entry",
+			"error", err,
+			"action", entry.Action,
+			"resource_type", entry.ResourceType,
+			"resource_id", entry.ResourceID)
+	}
+}
+
+// RegisterEventHandlers writes settled and failed payments to the sink.
+// They are not audit_logs rows, so they only reach the sink this way.
+func (s *Sink) RegisterEventHandlers(eventBus *events.EventBus) {
+	if s == nil {
+		return
+	}
+	eventBus.Subscribe(events.EventTypePaymentCompleted, s.handlePaymentEvent)
+	eventBus.Subscribe(events.EventTypePaymentFailed, s.handlePaymentEvent)
+}
+
+func (s *Sink) handlePaymentEvent(ctx context.Context, event events.Event) error {
+	metadata, _ := event.Payload().(map[string]interface{})
+	s.Write(ctx, &Entry{
+		Action:       event.EventType(),
+		ResourceType: ResourcePayment,
+		ResourceID:   fmt.Sprint(metadata["payment_id"]),
+		Metadata:     metadata,
+		CreatedAt:    event.OccurredAt(),
+	})
+	return nil
+}
+
+// Close closes the file behind a file sink.
+func (s *Sink) Close() error {
+	if s == nil || s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// webhookWriter POSTs every write, which slog makes one JSON line each, as
+// the body of its own request.
+type webhookWriter struct {
+	url    string
+	client *http.Client
+}
+
+func (w *webhookWriter) Write(p []byte) (int, error) {
+	resp, err := w.client.Post(w.url, "application/x-ndjson", bytes.NewReader(p))
+	if err != nil {
+		return 0, fmt.Errorf("audit webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("audit webhook answered %d", resp.StatusCode)
+	}
+	return len(p), nil
+}
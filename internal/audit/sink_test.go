@@ -0,0 +1,181 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+)
+
+type memoryRepository struct {
+	logs []*auditDatamodel.AuditLog
+}
+
+func (r *memoryRepository) Create(log *auditDatamodel.AuditLog) error {
+	var prev *auditDatamodel.AuditLog
+	for _, l := range r.logs {
+		if l.Seq != nil {
+			prev = l
+		}
+	}
+	if err := audit.Seal(log, prev); err != nil {
+		return err
+	}
+	log.ID = int64(len(r.logs) + 1)
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *memoryRepository) ListByResource(resourceType, resourceID string) ([]*auditDatamodel.AuditLog, error) {
+	return r.logs, nil
+}
+
+func (r *memoryRepository) ListChain(afterSeq int64, limit int) ([]*auditDatamodel.AuditLog, error) {
+	var out []*auditDatamodel.AuditLog
+	for _, l := range r.logs {
+		if l.Seq != nil && *l.Seq > afterSeq && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepository) ListUnchained(afterID int64, limit int) ([]*auditDatamodel.AuditLog, error) {
+	var out []*auditDatamodel.AuditLog
+	for _, l := range r.logs {
+		if l.Seq == nil && l.ID > afterID && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func lines(buf *bytes.Buffer) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var decoded map[string]interface{}
+		Expect(json.Unmarshal([]byte(line), &decoded)).To(Succeed())
+		out = append(out, decoded)
+	}
+	return out
+}
+
+var _ = Describe("Sink", func() {
+	var (
+		buf    *bytes.Buffer
+		logger *slog.Logger
+		at     time.Time
+	)
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		at = time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC)
+	})
+
+	It("writes recorded entries as JSON lines after storing them", func() {
+		repo := &memoryRepository{}
+		service := audit.NewService(repo, audit.NewSink(buf, slog.LevelInfo, logger), logger)
+		actorID := int64(4)
+
+		Expect(service.Record(&audit.Entry{
+			Action:       audit.ActionExpenseStatusChanged,
+			ResourceType: audit.ResourceExpense,
+			ResourceID:   "12",
+			ActorID:      &actorID,
+			Metadata:     map[string]interface{}{"from": "pending_approval", "to": "approved"},
+			CreatedAt:    at,
+		})).To(Succeed())
+
+		Expect(repo.logs).To(HaveLen(1))
+		written := lines(buf)
+		Expect(written).To(HaveLen(1))
+		Expect(written[0]).To(HaveKeyWithValue("msg", audit.ActionExpenseStatusChanged))
+		Expect(written[0]).To(HaveKeyWithValue("level", "INFO"))
+		Expect(written[0]).To(HaveKeyWithValue("time", "2025-11-03T09:00:00Z"))
+		Expect(written[0]).To(HaveKeyWithValue("resource_id", "12"))
+		Expect(written[0]).To(HaveKeyWithValue("actor_id", 4.0))
+		Expect(written[0]["metadata"]).To(HaveKeyWithValue("to", "approved"))
+		Expect(written[0]).To(HaveKeyWithValue("seq", 1.0))
+		Expect(written[0]).To(HaveKeyWithValue("hash", repo.logs[0].Hash))
+	})
+
+	It("keeps only refusals and anomalies at level warn", func() {
+		sink := audit.NewSink(buf, slog.LevelWarn, logger)
+		sink.Write(context.Background(), &audit.Entry{Action: audit.ActionExpenseStatusChanged, ResourceType: audit.ResourceExpense, ResourceID: "1"})
+		sink.Write(context.Background(), &audit.Entry{Action: audit.ActionApprovalLinkRefused, ResourceType: audit.ResourceExpense, ResourceID: "2"})
+
+		written := lines(buf)
+		Expect(written).To(HaveLen(1))
+		Expect(written[0]).To(HaveKeyWithValue("msg", audit.ActionApprovalLinkRefused))
+		Expect(written[0]).To(HaveKeyWithValue("level", "WARN"))
+	})
+
+	It("writes settled and failed payments from the event bus", func() {
+		bus := events.NewEventBus(logger)
+		audit.NewSink(buf, slog.LevelInfo, logger).RegisterEventHandlers(bus)
+
+		Expect(bus.PublishSync(context.Background(), events.NewPaymentFailedEvent("7", 12, "ext-7", 50000, "insufficient balance", 1))).To(Succeed())
+
+		written := lines(buf)
+		Expect(written).To(HaveLen(1))
+		Expect(written[0]).To(HaveKeyWithValue("msg", events.EventTypePaymentFailed))
+		Expect(written[0]).To(HaveKeyWithValue("level", "WARN"))
+		Expect(written[0]).To(HaveKeyWithValue("resource_type", audit.ResourcePayment))
+		Expect(written[0]).To(HaveKeyWithValue("resource_id", "7"))
+		Expect(written[0]["metadata"]).To(HaveKeyWithValue("failure_reason", "insufficient balance"))
+	})
+
+	It("appends to a file sink", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "audit", "audit.jsonl")
+		sink, err := audit.OpenSink(internal.AuditLogConfig{Driver: "file", Path: path}, logger)
+		Expect(err).NotTo(HaveOccurred())
+		sink.Write(context.Background(), &audit.Entry{Action: audit.ActionPermissionGranted, ResourceType: audit.ResourceUser, ResourceID: "3"})
+		Expect(sink.Close()).To(Succeed())
+
+		contents, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lines(bytes.NewBuffer(contents))).To(HaveLen(1))
+	})
+
+	It("posts each line to a webhook sink", func() {
+		received := make(chan []byte, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- body
+		}))
+		defer server.Close()
+
+		sink, err := audit.OpenSink(internal.AuditLogConfig{Driver: "webhook", WebhookURL: server.URL}, logger)
+		Expect(err).NotTo(HaveOccurred())
+		sink.Write(context.Background(), &audit.Entry{Action: audit.ActionPaymentRequeued, ResourceType: audit.ResourcePayment, ResourceID: "9"})
+
+		Eventually(received).Should(Receive(ContainSubstring(`"msg":"payment.requeued"`)))
+	})
+
+	It("is off without a driver", func() {
+		sink, err := audit.OpenSink(internal.AuditLogConfig{}, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sink).To(BeNil())
+		sink.Write(context.Background(), &audit.Entry{Action: audit.ActionPaymentRequeued})
+		Expect(sink.Close()).To(Succeed())
+	})
+})
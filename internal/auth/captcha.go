@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	hCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// CaptchaVerifier checks a CAPTCHA token solved by the client. It returns
+// ErrCaptchaInvalid for rejected tokens and any other error when the
+// provider could not be asked.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// SiteVerifyCaptcha verifies tokens against a siteverify endpoint; hCaptcha
+// and Cloudflare Turnstile share the same protocol.
+type SiteVerifyCaptcha struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+func NewHCaptchaVerifier(secret string) *SiteVerifyCaptcha {
+	return newSiteVerifyCaptcha(hCaptchaVerifyURL, secret)
+}
+
+func NewTurnstileVerifier(secret string) *SiteVerifyCaptcha {
+	return newSiteVerifyCaptcha(turnstileVerifyURL, secret)
+}
+
+func newSiteVerifyCaptcha(verifyURL, secret string) *SiteVerifyCaptcha {
+	return &SiteVerifyCaptcha{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (c *SiteVerifyCaptcha) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{"secret": {c.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha verification failed: status %d", resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("captcha verification failed: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrCaptchaInvalid, strings.Join(result.ErrorCodes, ","))
+	}
+	return nil
+}
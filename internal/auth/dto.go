@@ -3,6 +3,12 @@ package auth
 type LoginDTO struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// CaptchaToken is required once a client has failed to log in too
+	// often; the login answers 428 until it is sent.
+	CaptchaToken string `json:"captcha_token,omitempty"`
+	// RemoteIP and UserAgent describe the client; the handler fills them in.
+	RemoteIP  string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type RefreshTokenDTO struct {
@@ -31,3 +37,12 @@ func (d RefreshTokenDTO) Validate() error {
 	}
 	return nil
 }
+
+// GrantRefreshToken is the only OAuth2 grant the token endpoint supports.
+const GrantRefreshToken = "refresh_token"
+
+// TokenRequestDTO is an OAuth2 token request, sent as a form or as JSON.
+type TokenRequestDTO struct {
+	GrantType    string `json:"grant_type"`
+	RefreshToken string `json:"refresh_token"`
+}
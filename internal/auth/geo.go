@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GeoIPPlaceholder marks where HTTPGeoResolver puts the address in its URL.
+const GeoIPPlaceholder = "{ip}"
+
+// HTTPGeoResolver looks addresses up with a JSON API answering country,
+// region and city fields, such as ipinfo.io
+// (https://ipinfo.io/{ip}/json?token=...).
+type HTTPGeoResolver struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+func NewHTTPGeoResolver(urlTemplate string, timeout time.Duration) *HTTPGeoResolver {
+	return &HTTPGeoResolver{
+		urlTemplate: urlTemplate,
+		client:      &http.Client{Timeout: timeout},
+	}
+}
+
+type geoResponse struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+	City    string `json:"city"`
+}
+
+func (g *HTTPGeoResolver) Resolve(ctx context.Context, ip string) (Location, error) {
+	target := strings.ReplaceAll(g.urlTemplate, GeoIPPlaceholder, url.PathEscape(ip))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return Location{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Location{}, fmt.Errorf("geo lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Location{}, fmt.Errorf("geo lookup failed: status %d", resp.StatusCode)
+	}
+
+	var result geoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Location{}, fmt.Errorf("geo lookup failed: %w", err)
+	}
+	return Location{Country: result.Country, Region: result.Region, City: result.City}, nil
+}
@@ -2,52 +2,101 @@ package auth
 
 import (
 	"encoding/json"
-	"log/slog"
+	"errors"
+	"math"
+	"mime"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/tenant"
 	"github.com/frahmantamala/expense-management/internal/transport"
 	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
+// TokenRefreshHeader tells a client to refresh its access token. It is
+// RefreshRecommended while the token is about to expire and RefreshRequired
+// once it has, for as long as the grace period still accepts it.
+const TokenRefreshHeader = "X-Token-Refresh"
+
+const (
+	RefreshRecommended = "recommended"
+	RefreshRequired    = "required"
+)
+
+// ImpersonatorHeader carries the id of the administrator acting as the user
+// on every response to an impersonation token, so clients can show that
+// someone else is signed in.
+const ImpersonatorHeader = "X-Impersonator-ID"
+
+// maxTokenRequestBytes bounds the token request body, which holds a single
+// refresh token.
+const maxTokenRequestBytes = 16 << 10
+
 type Handler struct {
 	*transport.BaseHandler
 	Service ServiceAPI
+	// refreshGrace is how long after expiry an access token is still
+	// accepted, with a refresh hint; zero accepts none.
+	refreshGrace time.Duration
 }
 
-func NewHandler(svc ServiceAPI) *Handler {
-	lg := logger.LoggerWrapper()
-	if lg == nil {
-		lg = slog.Default()
-	}
+func NewHandler(svc ServiceAPI, refreshGrace time.Duration) *Handler {
 	return &Handler{
-		BaseHandler: transport.NewBaseHandler(lg),
-		Service:     svc,
+		BaseHandler:  transport.NewBaseHandler(logger.LoggerWrapper()),
+		Service:      svc,
+		refreshGrace: refreshGrace,
 	}
 }
 
+// Login godoc
+// @Summary      Log in
+// @Description  Exchanges email and password for an access and refresh token pair.
+// @Description  After repeated failures the login answers 428 until a CAPTCHA token is sent in captcha_token, and 429 with Retry-After once the client IP is locked out.
+// @Description  Each successful login is recorded with the client's IP, user agent and location; a login from a new device or location emails the user.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      LoginDTO  true  "Credentials"
+// @Success      200   {object}  AuthTokens
+// @Failure      400   {object}  transport.ErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Failure      428   {object}  transport.ErrorResponse
+// @Failure      429   {object}  transport.ErrorResponse
+// @Router       /auth/login [post]
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var dto LoginDTO
-	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
-		h.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if !h.DecodeJSON(w, r, &dto) {
 		return
 	}
+	dto.RemoteIP = remoteIP(r)
+	dto.UserAgent = r.UserAgent()
 
-	tokens, err := h.Service.Authenticate(dto)
+	tokens, err := h.Service.Authenticate(r.Context(), dto)
 	if err != nil {
-		h.Logger.Error("authentication failed", "error", err)
+		h.Log(r).Error("authentication failed", "error", err)
 
-		switch err {
-		case ErrInvalidCredentials:
-			h.WriteError(w, http.StatusUnauthorized, "invalid credentials")
-		case ErrUserInactive:
-			h.WriteError(w, http.StatusUnauthorized, "user is inactive")
+		var throttled *ThrottledError
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			h.WriteError(w, r, http.StatusUnauthorized, "invalid credentials")
+		case errors.Is(err, ErrUserInactive):
+			h.WriteError(w, r, http.StatusUnauthorized, "user is inactive")
+		case errors.Is(err, ErrCaptchaRequired):
+			h.WriteError(w, r, http.StatusPreconditionRequired, "captcha required")
+		case errors.Is(err, ErrCaptchaInvalid):
+			h.WriteError(w, r, http.StatusBadRequest, "invalid captcha")
+		case errors.As(err, &throttled):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			h.WriteError(w, r, http.StatusTooManyRequests, "too many failed login attempts")
 		default:
 			if _, ok := err.(ValidationError); ok {
-				h.WriteError(w, http.StatusBadRequest, err.Error())
+				h.WriteError(w, r, http.StatusBadRequest, err.Error())
 			} else {
-				h.WriteError(w, http.StatusInternalServerError, "internal server error")
+				h.WriteError(w, r, http.StatusInternalServerError, "internal server error")
 			}
 		}
 		return
@@ -56,29 +105,39 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	h.WriteJSON(w, http.StatusOK, tokens)
 }
 
+// RefreshToken godoc
+// @Summary      Refresh tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      RefreshTokenDTO  true  "Refresh token"
+// @Success      200   {object}  AuthTokens
+// @Failure      400   {object}  transport.ErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /auth/refresh [post]
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	var dto RefreshTokenDTO
-	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
-		h.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if !h.DecodeJSON(w, r, &dto) {
 		return
 	}
 
 	if err := dto.Validate(); err != nil {
-		h.WriteError(w, http.StatusBadRequest, err.Error())
+		h.WriteError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	tokens, err := h.Service.RefreshTokens(dto.RefreshToken)
 	if err != nil {
-		h.Logger.Error("token refresh failed", "error", err)
+		h.Log(r).Error("token refresh failed", "error", err)
 
 		switch err {
-		case ErrInvalidToken, ErrTokenExpired:
-			h.WriteError(w, http.StatusUnauthorized, "invalid refresh token")
+		case ErrInvalidToken, ErrTokenExpired, ErrSessionRevoked:
+			h.WriteError(w, r, http.StatusUnauthorized, "invalid refresh token")
 		case ErrUserInactive:
-			h.WriteError(w, http.StatusUnauthorized, "user is inactive")
+			h.WriteError(w, r, http.StatusUnauthorized, "user is inactive")
 		default:
-			h.WriteError(w, http.StatusInternalServerError, "internal server error")
+			h.WriteError(w, r, http.StatusInternalServerError, "internal server error")
 		}
 		return
 	}
@@ -86,29 +145,124 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	h.WriteJSON(w, http.StatusOK, tokens)
 }
 
+// Token godoc
+// @Summary      OAuth2 token exchange
+// @Description  OAuth2 token endpoint (RFC 6749) for standard clients. Only the refresh_token grant is supported: it answers a new access and refresh token pair with the access token's lifetime in expires_in. The request may be a form or JSON. Errors use the OAuth2 shape: invalid_request, unsupported_grant_type, or invalid_grant for a refresh token that is invalid, expired or belongs to an inactive user.
+// @Tags         auth
+// @Accept       x-www-form-urlencoded
+// @Accept       json
+// @Produce      json
+// @Param        grant_type     formData  string  true  "refresh_token"
+// @Param        refresh_token  formData  string  true  "Refresh token"
+// @Success      200  {object}  OAuthToken
+// @Failure      400  {object}  OAuthError
+// @Failure      500  {object}  OAuthError
+// @Router       /auth/token [post]
+func (h *Handler) Token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	dto, ok := h.decodeTokenRequest(w, r)
+	if !ok {
+		return
+	}
+	switch {
+	case dto.GrantType == "":
+		h.writeOAuthError(w, r, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		return
+	case dto.GrantType != GrantRefreshToken:
+		h.writeOAuthError(w, r, http.StatusBadRequest, "unsupported_grant_type", "only the refresh_token grant is supported")
+		return
+	case dto.RefreshToken == "":
+		h.writeOAuthError(w, r, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+		return
+	}
+
+	token, err := h.Service.ExchangeRefreshToken(dto.RefreshToken)
+	if err != nil {
+		h.Log(r).Error("token exchange failed", "error", err)
+
+		switch err {
+		case ErrInvalidToken, ErrTokenExpired, ErrSessionRevoked:
+			h.writeOAuthError(w, r, http.StatusBadRequest, "invalid_grant", "invalid refresh token")
+		case ErrUserInactive:
+			h.writeOAuthError(w, r, http.StatusBadRequest, "invalid_grant", "user is inactive")
+		default:
+			h.writeOAuthError(w, r, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, token)
+}
+
+// decodeTokenRequest reads a token request from a form body or, when the
+// client sends JSON, a JSON body.
+func (h *Handler) decodeTokenRequest(w http.ResponseWriter, r *http.Request) (TokenRequestDTO, bool) {
+	var dto TokenRequestDTO
+	r.Body = http.MaxBytesReader(w, r.Body, maxTokenRequestBytes)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			h.writeOAuthError(w, r, http.StatusBadRequest, "invalid_request", "invalid JSON body")
+			return dto, false
+		}
+		return dto, true
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.writeOAuthError(w, r, http.StatusBadRequest, "invalid_request", "invalid form body")
+		return dto, false
+	}
+	dto.GrantType = r.PostForm.Get("grant_type")
+	dto.RefreshToken = r.PostForm.Get("refresh_token")
+	return dto, true
+}
+
+func (h *Handler) writeOAuthError(w http.ResponseWriter, r *http.Request, status int, code, description string) {
+	h.Log(r).Error("oauth error", "status", status, "error", code, "description", description)
+	h.WriteJSON(w, status, OAuthError{Error: code, ErrorDescription: description})
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Tags         auth
+// @Security     BearerAuth
+// @Success      204
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /auth/logout [post]
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	token := h.ExtractTokenFromHeader(r)
 	if token == "" {
-		h.WriteError(w, http.StatusUnauthorized, "missing authorization token")
+		h.WriteError(w, r, http.StatusUnauthorized, "missing authorization token")
 		return
 	}
 
 	// Validate token
 	_, err := h.Service.ValidateAccessToken(token)
 	if err != nil {
-		h.WriteError(w, http.StatusUnauthorized, "invalid token")
+		h.WriteError(w, r, http.StatusUnauthorized, "invalid token")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// remoteIP is the client address without its port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := h.ExtractTokenFromHeader(r)
 		if token == "" {
-			h.Logger.Error("[auth middleware] missing authorization token")
-			h.WriteError(w, http.StatusUnauthorized, "missing authorization token")
+			h.Log(r).Error("[auth middleware] missing authorization token")
+			h.WriteError(w, r, http.StatusUnauthorized, "missing authorization token")
 			return
 		}
 
@@ -116,42 +270,88 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		if len(token) > 20 {
 			tokenPrefix = token[:20]
 		}
-		h.Logger.Info("[auth middleware] validating token", "token_prefix", tokenPrefix)
+		h.Log(r).Info("[auth middleware] validating token", "token_prefix", tokenPrefix)
 
 		claims, err := h.Service.ValidateAccessToken(token)
+		if errors.Is(err, ErrTokenExpired) && h.refreshGrace > 0 {
+			claims, err = h.Service.ValidateAccessTokenWithin(token, h.refreshGrace)
+		}
 		if err != nil {
-			h.Logger.Error("token validation failed", "error", err, "token_prefix", tokenPrefix)
-			h.WriteError(w, http.StatusUnauthorized, "invalid token")
+			h.Log(r).Error("token validation failed", "error", err, "token_prefix", tokenPrefix)
+			h.WriteError(w, r, http.StatusUnauthorized, "invalid token")
 			return
 		}
+		if hint := h.refreshHint(claims, time.Now()); hint != "" {
+			w.Header().Set(TokenRefreshHeader, hint)
+		}
 
-		h.Logger.Info("[auth middleware] token validated successfully", "user_id", claims.UserID, "email", claims.Email)
+		h.Log(r).Info("[auth middleware] token validated successfully", "user_id", claims.UserID, "email", claims.Email)
 
-		var uid int64
-		if claims.UserID != "" {
-			if parsed, perr := strconv.ParseInt(claims.UserID, 10, 64); perr == nil {
-				uid = parsed
-			} else {
-				h.Logger.Warn("failed to parse user id from token claims", "value", claims.UserID, "error", perr)
+		coreUser, err := h.Service.TokenUser(claims)
+		if err != nil {
+			h.Log(r).Error("[auth middleware] token user rejected", "user_id", claims.UserID, "error", err)
+			switch {
+			case errors.Is(err, ErrUserInactive):
+				h.WriteError(w, r, http.StatusUnauthorized, "user is inactive")
+			case errors.Is(err, ErrSessionRevoked):
+				h.WriteError(w, r, http.StatusUnauthorized, "session revoked")
+			case errors.Is(err, ErrImpersonationEnded):
+				h.WriteError(w, r, http.StatusUnauthorized, "impersonation ended")
+			default:
+				h.WriteError(w, r, http.StatusUnauthorized, "user not found")
 			}
+			return
 		}
+		uid := coreUser.ID
 
-		coreUser, err := h.Service.GetUserWithPermissions(uid)
-		if err != nil {
-			h.Logger.Error("[auth middleware] failed to load user permissions", "user_id", uid, "error", err)
-			h.WriteError(w, http.StatusUnauthorized, "user not found")
+		if claims.TenantID != 0 && claims.TenantID != coreUser.TenantID {
+			h.Log(r).Warn("[auth middleware] token tenant does not match user", "user_id", uid, "claim_tenant_id", claims.TenantID, "tenant_id", coreUser.TenantID)
+			h.WriteError(w, r, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if requested, ok := tenant.FromContext(r.Context()); ok && requested != coreUser.TenantID {
+			h.Log(r).Warn("[auth middleware] request tenant does not match user", "user_id", uid, "requested_tenant_id", requested, "tenant_id", coreUser.TenantID)
+			h.HandleError(w, r, tenant.ErrTenantMismatch)
 			return
 		}
 
-		h.Logger.Info("[auth middleware] adding user to context", "user_id", uid, "email", claims.Email)
+		h.Log(r).Info("[auth middleware] adding user to context", "user_id", uid, "email", claims.Email)
 
 		internalUser := &internal.User{
 			ID:          coreUser.ID,
+			TenantID:    coreUser.TenantID,
 			Email:       coreUser.Email,
 			Permissions: coreUser.Permissions,
 		}
+		if claims.ImpersonatorID != "" {
+			// TokenUser has already parsed the impersonator's id.
+			internalUser.ImpersonatorID, _ = strconv.ParseInt(claims.ImpersonatorID, 10, 64)
+			w.Header().Set(ImpersonatorHeader, claims.ImpersonatorID)
+		}
 
 		ctx := internal.ContextWithUser(r.Context(), internalUser)
+		ctx = tenant.NewContext(ctx, coreUser.TenantID)
+		log := h.Log(r).With("user_id", internalUser.ID, "tenant_id", coreUser.TenantID)
+		if internalUser.Impersonated() {
+			log = log.With("impersonator_id", internalUser.ImpersonatorID)
+		}
+		ctx = logger.NewContext(ctx, log)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// refreshHint is the TokenRefreshHeader value for a token accepted at now:
+// required once it has expired, recommended within the grace period before
+// it does, and empty otherwise or when there is no grace period.
+func (h *Handler) refreshHint(claims *Claims, now time.Time) string {
+	if h.refreshGrace <= 0 || claims.ExpiresAt == nil {
+		return ""
+	}
+	switch left := claims.ExpiresAt.Time.Sub(now); {
+	case left <= 0:
+		return RefreshRequired
+	case left <= h.refreshGrace:
+		return RefreshRecommended
+	}
+	return ""
+}
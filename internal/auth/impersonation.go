@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/go-chi/chi"
+)
+
+// maxImpersonationReason bounds the reason recorded with an impersonation.
+const maxImpersonationReason = 500
+
+var (
+	ErrImpersonateSelf     = internal.NewValidationError("You cannot impersonate yourself", internal.ErrCodeImpersonationNotAllowed)
+	ErrImpersonateAdmin    = internal.NewForbiddenError("Administrators cannot be impersonated", internal.ErrCodeImpersonationNotAllowed)
+	ErrImpersonateInactive = internal.NewForbiddenError("Inactive users cannot be impersonated", internal.ErrCodeImpersonationNotAllowed)
+	ErrImpersonateNested   = internal.NewForbiddenError("You cannot impersonate while impersonating", internal.ErrCodeImpersonationNotAllowed)
+	ErrImpersonationTarget = internal.NewNotFoundError("User not found", internal.ErrCodeUserNotFound)
+	// ErrImpersonationRestricted answers requests an impersonation token
+	// may not make.
+	ErrImpersonationRestricted = internal.NewForbiddenError("This action is not available while impersonating a user", internal.ErrCodeImpersonationRestricted)
+)
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+// ImpersonationTokenIssuer issues the access token an administrator acts
+// with; JWTTokenGenerator is one.
+type ImpersonationTokenIssuer interface {
+	GenerateImpersonationToken(userID, email string, tenantID int64, impersonatorID string, ttl time.Duration) (string, error)
+}
+
+type ImpersonateDTO struct {
+	// Reason is recorded in the audit log, for example a support ticket.
+	Reason string `json:"reason"`
+}
+
+func (d ImpersonateDTO) Validate() error {
+	reason := strings.TrimSpace(d.Reason)
+	if reason == "" {
+		return internal.NewValidationFieldError("reason", "reason is required", internal.ErrCodeValidationFailed)
+	}
+	if len(reason) > maxImpersonationReason {
+		return internal.NewValidationFieldError("reason", fmt.Sprintf("reason must be at most %d characters", maxImpersonationReason), internal.ErrCodeValidationFailed)
+	}
+	return nil
+}
+
+// Impersonation is the token an administrator acts as another user with.
+type Impersonation struct {
+	AccessToken    string    `json:"access_token"`
+	TokenType      string    `json:"token_type"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	UserID         int64     `json:"user_id"`
+	Email          string    `json:"email"`
+	ImpersonatorID int64     `json:"impersonator_id"`
+}
+
+// ImpersonationService lets support administrators act as another user of
+// their tenant to see what the user sees. The token lasts ttl, cannot be
+// refreshed, stops working once the administrator is no longer an active
+// admin, and everything done with it is audited.
+type ImpersonationService struct {
+	users  RepositoryAPI
+	tokens ImpersonationTokenIssuer
+	audit  AuditRecorder
+	ttl    time.Duration
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+func NewImpersonationService(users RepositoryAPI, tokens ImpersonationTokenIssuer, audit AuditRecorder, ttl time.Duration, logger *slog.Logger) *ImpersonationService {
+	return &ImpersonationService{
+		users:  users,
+		tokens: tokens,
+		audit:  audit,
+		ttl:    ttl,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+func (s *ImpersonationService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Start issues admin a token acting as targetID. Only active users of the
+// admin's tenant who are not administrators themselves can be impersonated.
+// The impersonation is refused if it cannot be audited.
+func (s *ImpersonationService) Start(ctx context.Context, admin *internal.User, targetID int64, dto ImpersonateDTO) (*Impersonation, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	if admin.Impersonated() {
+		return nil, ErrImpersonateNested
+	}
+	if targetID == admin.ID {
+		return nil, ErrImpersonateSelf
+	}
+
+	target, err := s.users.GetUserWithPermissions(targetID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrImpersonationTarget
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if target.TenantID != admin.TenantID {
+		return nil, ErrImpersonationTarget
+	}
+	if !target.IsActive {
+		return nil, ErrImpersonateInactive
+	}
+	if target.IsAdmin() {
+		return nil, ErrImpersonateAdmin
+	}
+
+	issuedAt := s.now()
+	token, err := s.tokens.GenerateImpersonationToken(strconv.FormatInt(target.ID, 10), target.Email, target.TenantID, strconv.FormatInt(admin.ID, 10), s.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue impersonation token: %w", err)
+	}
+	expiresAt := issuedAt.Add(s.ttl).UTC()
+
+	entry := &audit.Entry{
+		Action:       audit.ActionImpersonationStarted,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   strconv.FormatInt(target.ID, 10),
+		ActorID:      &admin.ID,
+		Metadata: map[string]interface{}{
+			"reason":     strings.TrimSpace(dto.Reason),
+			"expires_at": expiresAt,
+		},
+	}
+	if err := s.audit.Record(entry); err != nil {
+		return nil, fmt.Errorf("failed to audit impersonation: %w", err)
+	}
+
+	s.log(ctx).Warn("impersonation started", "impersonator_id", admin.ID, "user_id", target.ID, "expires_at", expiresAt)
+	return &Impersonation{
+		AccessToken:    token,
+		TokenType:      "Bearer",
+		ExpiresAt:      expiresAt,
+		UserID:         target.ID,
+		Email:          target.Email,
+		ImpersonatorID: admin.ID,
+	}, nil
+}
+
+// RecordRequest audits one request made with an impersonation token.
+func (s *ImpersonationService) RecordRequest(ctx context.Context, user *internal.User, method, path string, status int) {
+	entry := &audit.Entry{
+		Action:       audit.ActionImpersonatedRequest,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   strconv.FormatInt(user.ID, 10),
+		ActorID:      &user.ImpersonatorID,
+		Metadata: map[string]interface{}{
+			"method": method,
+			"path":   path,
+			"status": status,
+		},
+	}
+	if err := s.audit.Record(entry); err != nil {
+		s.log(ctx).Error("failed to record impersonated request audit entry", "error", err, "user_id", user.ID, "impersonator_id", user.ImpersonatorID)
+	}
+}
+
+type ImpersonationServiceAPI interface {
+	Start(ctx context.Context, admin *internal.User, targetID int64, dto ImpersonateDTO) (*Impersonation, error)
+	RecordRequest(ctx context.Context, user *internal.User, method, path string, status int)
+}
+
+type ImpersonationHandler struct {
+	*transport.BaseHandler
+	Service ImpersonationServiceAPI
+}
+
+func NewImpersonationHandler(baseHandler *transport.BaseHandler, service ImpersonationServiceAPI) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// Impersonate godoc
+// @Summary      Impersonate a user
+// @Description  Issues a short-lived access token acting as another active, non-admin user of the caller's tenant, for support. The token carries an impersonator_id claim and cannot be refreshed. Every response to it carries X-Impersonator-ID so clients can show a banner. Impersonation tokens cannot make DELETE requests, approve or reject expenses, retry payments, close periods, or change the user's password, profile, avatar or bank accounts. Starting an impersonation and every request made with the token are audit-logged; the reason is required.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int             true  "User ID"
+// @Param        body  body      ImpersonateDTO  true  "Why the user is impersonated"
+// @Success      201   {object}  Impersonation
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /admin/users/{id}/impersonate [post]
+func (h *ImpersonationHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	admin, ok := internal.UserFromContext(r.Context())
+	if !ok || admin == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	targetID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	var dto ImpersonateDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	result, err := h.Service.Start(r.Context(), admin, targetID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.WriteJSON(w, http.StatusCreated, result)
+}
+
+// Guard goes after AuthMiddleware. It refuses DELETE requests made with an
+// impersonation token and audits every other one with the status it was
+// answered with. Requests of users acting for themselves pass untouched.
+func (h *ImpersonationHandler) Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := internal.UserFromContext(r.Context())
+		if !ok || user == nil || !user.Impersonated() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			h.Log(r).Warn("delete refused while impersonating", "path", r.URL.Path)
+			h.Service.RecordRequest(r.Context(), user, r.Method, r.URL.Path, http.StatusForbidden)
+			h.HandleError(w, r, ErrImpersonationRestricted)
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		h.Service.RecordRequest(r.Context(), user, r.Method, r.URL.Path, sw.status)
+	})
+}
+
+// statusWriter remembers the status a response was written with.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type recordingAudit struct {
+	entries []*audit.Entry
+	err     error
+}
+
+func (r *recordingAudit) Record(entry *audit.Entry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+var _ = ginkgo.Describe("Impersonation", func() {
+	var (
+		service       *Service
+		impersonation *ImpersonationService
+		mockRepo      *mockUserRepository
+		tokenGen      *JWTTokenGenerator
+		auditLog      *recordingAudit
+		logger        *slog.Logger
+		admin         *internal.User
+	)
+
+	ginkgo.BeforeEach(func() {
+		mockRepo = newMockUserRepository()
+		mockRepo.usersByID[10] = &User{ID: 10, TenantID: 7, Email: "support@example.com", Permissions: []string{string(PermAdmin)}, IsActive: true}
+		mockRepo.usersByID[11] = &User{ID: 11, TenantID: 7, Email: "other-admin@example.com", Permissions: []string{string(PermAdmin)}, IsActive: true}
+		mockRepo.usersByID[12] = &User{ID: 12, TenantID: 7, Email: "gone@example.com", IsActive: false}
+		tokenGen = NewJWTTokenGenerator("test-access-secret", "test-refresh-secret", 15*time.Minute, 24*time.Hour)
+		auditLog = &recordingAudit{}
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		var err error
+		service, err = NewService(mockRepo, tokenGen, bcrypt.DefaultCost, nil, nil, nil, logger)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		impersonation = NewImpersonationService(mockRepo, tokenGen, auditLog, 10*time.Minute, logger)
+		admin = &internal.User{ID: 10, TenantID: 7, Email: "support@example.com", Permissions: []string{string(PermAdmin)}}
+	})
+
+	start := func(targetID int64) (*Impersonation, error) {
+		return impersonation.Start(context.Background(), admin, targetID, ImpersonateDTO{Reason: "ticket 4521"})
+	}
+
+	ginkgo.Describe("Start", func() {
+		ginkgo.It("issues a short-lived token naming the administrator and audits it", func() {
+			result, err := start(2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.UserID).To(gomega.Equal(int64(2)))
+			gomega.Expect(result.ImpersonatorID).To(gomega.Equal(int64(10)))
+			gomega.Expect(result.ExpiresAt).To(gomega.BeTemporally("~", time.Now().Add(10*time.Minute), 5*time.Second))
+
+			claims, err := service.ValidateAccessToken(result.AccessToken)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(claims.UserID).To(gomega.Equal("2"))
+			gomega.Expect(claims.ImpersonatorID).To(gomega.Equal("10"))
+			gomega.Expect(claims.ExpiresAt.Time).To(gomega.BeTemporally("~", time.Now().Add(10*time.Minute), 5*time.Second))
+
+			gomega.Expect(auditLog.entries).To(gomega.HaveLen(1))
+			entry := auditLog.entries[0]
+			gomega.Expect(entry.Action).To(gomega.Equal(audit.ActionImpersonationStarted))
+			gomega.Expect(entry.ResourceID).To(gomega.Equal("2"))
+			gomega.Expect(*entry.ActorID).To(gomega.Equal(int64(10)))
+			gomega.Expect(entry.Metadata["reason"]).To(gomega.Equal("ticket 4521"))
+		})
+
+		ginkgo.DescribeTable("refuses targets that cannot be impersonated",
+			func(targetID int64, want error) {
+				_, err := start(targetID)
+				gomega.Expect(err).To(gomega.MatchError(want))
+				gomega.Expect(auditLog.entries).To(gomega.BeEmpty())
+			},
+			ginkgo.Entry("themself", int64(10), ErrImpersonateSelf),
+			ginkgo.Entry("another administrator", int64(11), ErrImpersonateAdmin),
+			ginkgo.Entry("an inactive user", int64(12), ErrImpersonateInactive),
+			ginkgo.Entry("a user of another tenant", int64(1), ErrImpersonationTarget),
+			ginkgo.Entry("an unknown user", int64(99), ErrImpersonationTarget),
+		)
+
+		ginkgo.It("requires a reason", func() {
+			_, err := impersonation.Start(context.Background(), admin, 2, ImpersonateDTO{Reason: "  "})
+			gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("reason is required")))
+		})
+
+		ginkgo.It("is refused when it cannot be audited", func() {
+			auditLog.err = errors.New("audit store down")
+			result, err := start(2)
+			gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("failed to audit impersonation")))
+			gomega.Expect(result).To(gomega.BeNil())
+		})
+	})
+
+	ginkgo.Describe("TokenUser", func() {
+		ginkgo.It("accepts the token while the administrator still is one", func() {
+			result, err := start(2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			claims, err := service.ValidateAccessToken(result.AccessToken)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			user, err := service.TokenUser(claims)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(user.ID).To(gomega.Equal(int64(2)))
+		})
+
+		ginkgo.It("ends the impersonation when the administrator loses admin or is deactivated", func() {
+			result, err := start(2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			claims, err := service.ValidateAccessToken(result.AccessToken)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			mockRepo.usersByID[10].Permissions = []string{string(PermViewExpenses)}
+			_, err = service.TokenUser(claims)
+			gomega.Expect(err).To(gomega.MatchError(ErrImpersonationEnded))
+
+			mockRepo.usersByID[10].Permissions = []string{string(PermAdmin)}
+			mockRepo.usersByID[10].IsActive = false
+			_, err = service.TokenUser(claims)
+			gomega.Expect(err).To(gomega.MatchError(ErrImpersonationEnded))
+		})
+	})
+
+	ginkgo.Describe("Handler", func() {
+		var (
+			handler     *ImpersonationHandler
+			authHandler *Handler
+		)
+
+		ginkgo.BeforeEach(func() {
+			handler = NewImpersonationHandler(transport.NewBaseHandler(logger), impersonation)
+			authHandler = NewHandler(service, 0)
+		})
+
+		serve := func(token, method string, next http.Handler) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, "/api/v1/expenses/5", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			authHandler.AuthMiddleware(handler.Guard(next)).ServeHTTP(rec, req)
+			return rec
+		}
+
+		ginkgo.It("marks impersonated responses and audits every request", func() {
+			result, err := start(2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			var seen *internal.User
+			rec := serve(result.AccessToken, http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen, _ = internal.UserFromContext(r.Context())
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusAccepted))
+			gomega.Expect(rec.Header().Get(ImpersonatorHeader)).To(gomega.Equal("10"))
+			gomega.Expect(seen.ID).To(gomega.Equal(int64(2)))
+			gomega.Expect(seen.ImpersonatorID).To(gomega.Equal(int64(10)))
+
+			gomega.Expect(auditLog.entries).To(gomega.HaveLen(2))
+			entry := auditLog.entries[1]
+			gomega.Expect(entry.Action).To(gomega.Equal(audit.ActionImpersonatedRequest))
+			gomega.Expect(*entry.ActorID).To(gomega.Equal(int64(10)))
+			gomega.Expect(entry.Metadata).To(gomega.HaveKeyWithValue("method", http.MethodGet))
+			gomega.Expect(entry.Metadata).To(gomega.HaveKeyWithValue("status", http.StatusAccepted))
+		})
+
+		ginkgo.It("refuses deletes while impersonating", func() {
+			result, err := start(2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			rec := serve(result.AccessToken, http.MethodDelete, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ginkgo.Fail("delete reached the handler")
+			}))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusForbidden))
+			gomega.Expect(auditLog.entries[1].Metadata).To(gomega.HaveKeyWithValue("status", http.StatusForbidden))
+		})
+
+		ginkgo.It("leaves users acting for themselves alone", func() {
+			token, err := tokenGen.GenerateAccessToken("2", "admin@example.com", 7)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			rec := serve(token, http.MethodDelete, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusNoContent))
+			gomega.Expect(rec.Header().Get(ImpersonatorHeader)).To(gomega.BeEmpty())
+			gomega.Expect(auditLog.entries).To(gomega.BeEmpty())
+		})
+	})
+})
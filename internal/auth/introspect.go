@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+// RevocationChecker reports whether an access token has been revoked, for
+// example by logout. Until a token store provides one, tokens stay valid
+// until they expire.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
+
+// Introspection answers a token introspection request (RFC 7662). Only
+// Active is set for a token that is not.
+type Introspection struct {
+	Active      bool     `json:"active"`
+	Subject     string   `json:"sub,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	UserID      int64    `json:"user_id,omitempty"`
+	TenantID    int64    `json:"tenant_id,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Scope is Permissions joined by spaces, as RFC 7662 expects.
+	Scope     string `json:"scope,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	// ImpersonatorID is set when an administrator is acting as the user.
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+}
+
+// Introspect reports whether this API would accept token as a bearer token
+// right now and, if so, whose it is. Revoked tokens, tokens of users who
+// are gone or inactive, and tokens whose tenant no longer matches the user
+// are inactive. An error means the answer could not be worked out.
+func (s *Service) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	inactive := &Introspection{Active: false}
+
+	claims, err := s.tokenGenerator.ValidateAccessToken(token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	if s.revocations != nil {
+		revoked, err := s.revocations.IsRevoked(ctx, claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return inactive, nil
+		}
+	}
+
+	user, err := s.TokenUser(claims)
+	if err != nil {
+		s.logger.Debug("introspected token's user not found, inactive or revoked", "user_id", claims.UserID, "error", err)
+		return inactive, nil
+	}
+	if claims.TenantID != 0 && claims.TenantID != user.TenantID {
+		return inactive, nil
+	}
+
+	result := &Introspection{
+		Active:         true,
+		Subject:        claims.UserID,
+		Username:       user.Email,
+		UserID:         user.ID,
+		TenantID:       user.TenantID,
+		Permissions:    user.Permissions,
+		Scope:          strings.Join(user.Permissions, " "),
+		TokenType:      "Bearer",
+		ImpersonatorID: claims.ImpersonatorID,
+	}
+	if claims.ExpiresAt != nil {
+		result.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		result.IssuedAt = claims.IssuedAt.Unix()
+	}
+	return result, nil
+}
+
+// IntrospectionClient is a service allowed to introspect tokens, which it
+// authenticates as with HTTP Basic auth.
+type IntrospectionClient struct {
+	ID     string
+	Secret string
+}
+
+type IntrospectorAPI interface {
+	Introspect(ctx context.Context, token string) (*Introspection, error)
+}
+
+// maxIntrospectionBytes bounds the form body, which holds a single token.
+const maxIntrospectionBytes = 16 << 10
+
+type IntrospectionHandler struct {
+	*transport.BaseHandler
+	Service  IntrospectorAPI
+	clients  []IntrospectionClient
+	cacheTTL time.Duration
+}
+
+// NewIntrospectionHandler serves token introspection to clients. Answers
+// for active tokens may be cached for cacheTTL, cut short by the token's
+// expiry.
+func NewIntrospectionHandler(baseHandler *transport.BaseHandler, service IntrospectorAPI, clients []IntrospectionClient, cacheTTL time.Duration) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		BaseHandler: baseHandler,
+		Service:     service,
+		clients:     clients,
+		cacheTTL:    cacheTTL,
+	}
+}
+
+// Introspect godoc
+// @Summary      Introspect an access token
+// @Description  RFC 7662 token introspection for sibling services. The client authenticates with HTTP Basic auth using its client_id and client_secret and posts the token as a form field. An active token's answer carries the user, tenant and permissions and may be cached as Cache-Control says, never past the token's expiry. Invalid, expired, revoked and foreign tokens all answer {"active": false}, which must not be cached.
+// @Tags         auth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        Authorization    header    string  true   "Basic base64(client_id:client_secret)"
+// @Param        token            formData  string  true   "Access token"
+// @Param        token_type_hint  formData  string  false  "access_token"
+// @Success      200  {object}  Introspection
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      500  {object}  transport.ErrorResponse
+// @Router       /auth/introspect [post]
+func (h *IntrospectionHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.authenticate(r)
+	if !ok {
+		h.Log(r).Warn("introspection request with invalid client credentials", "remote_addr", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+		h.WriteError(w, r, http.StatusUnauthorized, "invalid client credentials")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxIntrospectionBytes)
+	if err := r.ParseForm(); err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		h.WriteError(w, r, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	result, err := h.Service.Introspect(r.Context(), token)
+	if err != nil {
+		h.Log(r).Error("Introspect: service error", "error", err, "client_id", client.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to introspect token")
+		return
+	}
+
+	if maxAge := h.maxAge(result, time.Now()); maxAge > 0 {
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	h.Log(r).Debug("token introspected", "client_id", client.ID, "active", result.Active, "user_id", result.UserID)
+	h.WriteJSON(w, http.StatusOK, result)
+}
+
+func (h *IntrospectionHandler) authenticate(r *http.Request) (IntrospectionClient, bool) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return IntrospectionClient{}, false
+	}
+	var found IntrospectionClient
+	matched := false
+	for _, c := range h.clients {
+		idMatch := subtle.ConstantTimeCompare([]byte(id), []byte(c.ID))
+		secretMatch := subtle.ConstantTimeCompare([]byte(secret), []byte(c.Secret))
+		if idMatch&secretMatch == 1 {
+			found, matched = c, true
+		}
+	}
+	return found, matched
+}
+
+// maxAge is how many seconds an answer may be cached: zero for inactive
+// tokens, otherwise cacheTTL or the token's remaining lifetime, whichever
+// is shorter.
+func (h *IntrospectionHandler) maxAge(result *Introspection, now time.Time) int {
+	if !result.Active || h.cacheTTL <= 0 {
+		return 0
+	}
+	ttl := h.cacheTTL
+	if result.ExpiresAt > 0 {
+		ttl = min(ttl, time.Unix(result.ExpiresAt, 0).Sub(now))
+	}
+	return int(ttl / time.Second)
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type fakeRevocations struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeRevocations) IsRevoked(_ context.Context, claims *Claims) (bool, error) {
+	return f.revoked[claims.UserID], f.err
+}
+
+var _ = ginkgo.Describe("Token introspection", func() {
+	var (
+		service     *Service
+		mockRepo    *mockUserRepository
+		tokenGen    *JWTTokenGenerator
+		revocations *fakeRevocations
+		logger      *slog.Logger
+	)
+
+	ginkgo.BeforeEach(func() {
+		mockRepo = newMockUserRepository()
+		tokenGen = NewJWTTokenGenerator("test-access-secret", "test-refresh-secret", 15*time.Minute, 24*time.Hour)
+		revocations = &fakeRevocations{revoked: map[string]bool{}}
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		var err error
+		service, err = NewService(mockRepo, tokenGen, bcrypt.DefaultCost, nil, revocations, nil, logger)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+	})
+
+	accessToken := func(userID string, tenantID int64) string {
+		token, err := tokenGen.GenerateAccessToken(userID, "admin@example.com", tenantID)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return token
+	}
+
+	ginkgo.Describe("Service", func() {
+		ginkgo.It("reports the user, tenant and permissions of an active token", func() {
+			result, err := service.Introspect(context.Background(), accessToken("2", 7))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.Active).To(gomega.BeTrue())
+			gomega.Expect(result.Subject).To(gomega.Equal("2"))
+			gomega.Expect(result.UserID).To(gomega.Equal(int64(2)))
+			gomega.Expect(result.TenantID).To(gomega.Equal(int64(7)))
+			gomega.Expect(result.Username).To(gomega.Equal("admin@example.com"))
+			gomega.Expect(result.Scope).To(gomega.Equal("view_expenses approve_expenses reject_expenses"))
+			gomega.Expect(result.ExpiresAt).To(gomega.BeNumerically(">", time.Now().Unix()))
+		})
+
+		ginkgo.It("reports an invalid token as inactive", func() {
+			result, err := service.Introspect(context.Background(), "not-a-token")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(*result).To(gomega.Equal(Introspection{Active: false}))
+		})
+
+		ginkgo.It("reports a revoked token as inactive", func() {
+			revocations.revoked["2"] = true
+			result, err := service.Introspect(context.Background(), accessToken("2", 7))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.Active).To(gomega.BeFalse())
+		})
+
+		ginkgo.It("reports tokens of missing users or another tenant as inactive", func() {
+			result, err := service.Introspect(context.Background(), accessToken("99", 0))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.Active).To(gomega.BeFalse())
+
+			result, err = service.Introspect(context.Background(), accessToken("2", 8))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.Active).To(gomega.BeFalse())
+		})
+
+		ginkgo.It("fails when revocation cannot be checked", func() {
+			revocations.err = errors.New("token store down")
+			_, err := service.Introspect(context.Background(), accessToken("2", 7))
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+	})
+
+	ginkgo.Describe("Handler", func() {
+		var handler *IntrospectionHandler
+
+		ginkgo.BeforeEach(func() {
+			handler = NewIntrospectionHandler(transport.NewBaseHandler(logger), service,
+				[]IntrospectionClient{{ID: "reports", Secret: "reports-secret-reports-secret-123"}}, time.Minute)
+		})
+
+		introspect := func(clientID, secret, token string) *httptest.ResponseRecorder {
+			form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/introspect", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if clientID != "" {
+				req.SetBasicAuth(clientID, secret)
+			}
+			rec := httptest.NewRecorder()
+			handler.Introspect(rec, req)
+			return rec
+		}
+
+		ginkgo.It("answers an authenticated client with a cacheable result", func() {
+			rec := introspect("reports", "reports-secret-reports-secret-123", accessToken("2", 7))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(rec.Header().Get("Cache-Control")).To(gomega.Equal("private, max-age=60"))
+
+			var body map[string]interface{}
+			gomega.Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(gomega.Succeed())
+			gomega.Expect(body["active"]).To(gomega.BeTrue())
+			gomega.Expect(body["user_id"]).To(gomega.BeEquivalentTo(2))
+		})
+
+		ginkgo.It("forbids caching an inactive answer", func() {
+			rec := introspect("reports", "reports-secret-reports-secret-123", "not-a-token")
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(rec.Header().Get("Cache-Control")).To(gomega.Equal("no-store"))
+			gomega.Expect(strings.TrimSpace(rec.Body.String())).To(gomega.Equal(`{"active":false}`))
+		})
+
+		ginkgo.It("refuses clients with wrong or missing credentials", func() {
+			rec := introspect("reports", "wrong", accessToken("2", 7))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(rec.Header().Get("WWW-Authenticate")).NotTo(gomega.BeEmpty())
+
+			rec = introspect("", "", accessToken("2", 7))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+
+		ginkgo.It("requires a token", func() {
+			rec := introspect("reports", "reports-secret-reports-secret-123", "")
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusBadRequest))
+		})
+	})
+})
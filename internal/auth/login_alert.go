@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/frahmantamala/expense-management/internal/notification"
+)
+
+// MailLoginAlerter emails users about logins from new devices or locations.
+type MailLoginAlerter struct {
+	mailer notification.Mailer
+}
+
+func NewMailLoginAlerter(mailer notification.Mailer) *MailLoginAlerter {
+	return &MailLoginAlerter{mailer: mailer}
+}
+
+func (a *MailLoginAlerter) NewLogin(ctx context.Context, user *User, session *LoginSession) error {
+	var what []string
+	if session.NewDevice {
+		what = append(what, "device")
+	}
+	if session.NewLocation {
+		what = append(what, "location")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Your account was just signed in to from a new %s.\n\n", strings.Join(what, " and "))
+	fmt.Fprintf(&b, "Device:     %s\n", session.Device)
+	fmt.Fprintf(&b, "IP address: %s\n", session.IPAddress)
+	fmt.Fprintf(&b, "Location:   %s\n", session.Location.String())
+	fmt.Fprintf(&b, "Time:       %s\n\n", session.CreatedAt.UTC().Format("2 Jan 2006 15:04 MST"))
+	b.WriteString("If this was you, there is nothing to do. If not, change your password and tell your administrator.\n")
+
+	return a.mailer.Send(ctx, notification.Message{
+		To:      user.Email,
+		Subject: "New sign-in to your expense account",
+		Body:    b.String(),
+	})
+}
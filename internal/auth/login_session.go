@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+// LoginSession records one successful login: where it came from, and
+// whether the user had logged in from that device and place before.
+type LoginSession struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	TenantID  int64  `json:"tenant_id"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	// Device is the browser and operating system named by UserAgent,
+	// without versions, so a browser update is not a new device.
+	Device string `json:"device"`
+	Location
+	NewDevice   bool      `json:"new_device"`
+	NewLocation bool      `json:"new_location"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Location is where an IP address is, as far as a GeoResolver can tell;
+// fields it cannot tell are empty.
+type Location struct {
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+func (l Location) String() string {
+	var parts []string
+	for _, p := range []string{l.City, l.Region, l.Country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GeoResolver locates a public IP address.
+type GeoResolver interface {
+	Resolve(ctx context.Context, ip string) (Location, error)
+}
+
+// LoginHistory is what a user's earlier logins say about a new one.
+type LoginHistory struct {
+	Logins       int64
+	DeviceSeen   bool
+	LocationSeen bool
+	// AlertsOff is set when the user opted out of new login alerts.
+	AlertsOff bool
+}
+
+type SessionRepositoryAPI interface {
+	CreateLoginSession(session *LoginSession) error
+	// LoginHistory counts userID's earlier logins and reports whether any
+	// came from device, or from the country and city of loc.
+	LoginHistory(userID int64, device string, loc Location) (LoginHistory, error)
+}
+
+// LoginAlerter tells a user about a login from a new device or location.
+type LoginAlerter interface {
+	NewLogin(ctx context.Context, user *User, session *LoginSession) error
+}
+
+const (
+	// maxUserAgentLength and maxFieldLength bound what is stored of a
+	// client's user agent and of the other session fields.
+	maxUserAgentLength = 512
+	maxFieldLength     = 100
+	// geoTimeout bounds the location lookup a login waits for.
+	geoTimeout = 2 * time.Second
+)
+
+// SessionRecorder stores a LoginSession for every successful login and
+// alerts the user when it comes from a device or location they have not
+// logged in from before. A user's first login never alerts.
+type SessionRecorder struct {
+	repo    SessionRepositoryAPI
+	geo     GeoResolver
+	alerter LoginAlerter
+	now     func() time.Time
+	logger  *slog.Logger
+}
+
+// NewSessionRecorder takes a nil geo when locations are not looked up and a
+// nil alerter when users are not alerted.
+func NewSessionRecorder(repo SessionRepositoryAPI, geo GeoResolver, alerter LoginAlerter, logger *slog.Logger) *SessionRecorder {
+	return &SessionRecorder{
+		repo:    repo,
+		geo:     geo,
+		alerter: alerter,
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+// Record stores the login of user from ip with userAgent. The alert, if
+// any, is sent in the background so the login does not wait for it.
+func (r *SessionRecorder) Record(ctx context.Context, user *User, ip, userAgent string) (*LoginSession, error) {
+	userAgent = truncate(userAgent, maxUserAgentLength)
+	loc := r.locate(ctx, ip)
+	session := &LoginSession{
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Device:    truncate(DeviceName(userAgent), maxFieldLength),
+		Location: Location{
+			Country: truncate(loc.Country, maxFieldLength),
+			Region:  truncate(loc.Region, maxFieldLength),
+			City:    truncate(loc.City, maxFieldLength),
+		},
+		CreatedAt: r.now().UTC(),
+	}
+
+	history, err := r.repo.LoginHistory(user.ID, session.Device, session.Location)
+	if err != nil {
+		return nil, err
+	}
+	if history.Logins > 0 {
+		session.NewDevice = !history.DeviceSeen
+		session.NewLocation = session.Country != "" && !history.LocationSeen
+	}
+
+	if err := r.repo.CreateLoginSession(session); err != nil {
+		return nil, err
+	}
+
+	if (session.NewDevice || session.NewLocation) && r.alerter != nil && !history.AlertsOff {
+		r.logger.Info("login from new device or location",
+			"user_id", user.ID, "device", session.Device, "location", session.Location.String(),
+			"new_device", session.NewDevice, "new_location", session.NewLocation)
+		go r.alert(context.WithoutCancel(ctx), user, session)
+	}
+	return session, nil
+}
+
+// locate skips addresses no resolver could place, such as private ones, and
+// treats a failed lookup as an unknown location.
+func (r *SessionRecorder) locate(ctx context.Context, ip string) Location {
+	addr := net.ParseIP(ip)
+	if r.geo == nil || addr == nil || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return Location{}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, geoTimeout)
+	defer cancel()
+	loc, err := r.geo.Resolve(ctx, ip)
+	if err != nil {
+		r.logger.Warn("failed to resolve login location", "error", err, "ip", ip)
+		return Location{}
+	}
+	return loc
+}
+
+func (r *SessionRecorder) alert(ctx context.Context, user *User, session *LoginSession) {
+	if err := r.alerter.NewLogin(ctx, user, session); err != nil {
+		r.logger.Error("failed to send new login alert", "error", err, "user_id", user.ID)
+	}
+}
+
+// DeviceName names the browser and operating system in a user agent, such
+// as "Chrome on Windows". Clients that are not browsers are named by their
+// first product token.
+func DeviceName(userAgent string) string {
+	if strings.TrimSpace(userAgent) == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(userAgent, "Edg/"), strings.Contains(userAgent, "EdgA/"), strings.Contains(userAgent, "EdgiOS/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"), strings.Contains(userAgent, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	default:
+		product, _, _ := strings.Cut(strings.Fields(userAgent)[0], "/")
+		return product
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(userAgent, "iPhone"):
+		os = "iOS"
+	case strings.Contains(userAgent, "iPad"):
+		os = "iPadOS"
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "CrOS"):
+		os = "ChromeOS"
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	default:
+		return browser
+	}
+	return browser + " on " + os
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	chromeOnWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+	safariOnIPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+)
+
+type fakeSessionRepository struct {
+	sessions  []*LoginSession
+	alertsOff bool
+}
+
+func (f *fakeSessionRepository) CreateLoginSession(session *LoginSession) error {
+	session.ID = int64(len(f.sessions) + 1)
+	f.sessions = append(f.sessions, session)
+	return nil
+}
+
+func (f *fakeSessionRepository) LoginHistory(userID int64, device string, loc Location) (LoginHistory, error) {
+	h := LoginHistory{AlertsOff: f.alertsOff}
+	for _, s := range f.sessions {
+		if s.UserID != userID {
+			continue
+		}
+		h.Logins++
+		h.DeviceSeen = h.DeviceSeen || s.Device == device
+		h.LocationSeen = h.LocationSeen || (s.Country != "" && s.Country == loc.Country && s.City == loc.City)
+	}
+	return h, nil
+}
+
+type fakeGeoResolver struct {
+	locations map[string]Location
+	lookups   []string
+}
+
+func (f *fakeGeoResolver) Resolve(_ context.Context, ip string) (Location, error) {
+	f.lookups = append(f.lookups, ip)
+	loc, ok := f.locations[ip]
+	if !ok {
+		return Location{}, errors.New("not found")
+	}
+	return loc, nil
+}
+
+type recordingAlerter struct {
+	mu     sync.Mutex
+	alerts []*LoginSession
+}
+
+func (r *recordingAlerter) NewLogin(_ context.Context, _ *User, session *LoginSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, session)
+	return nil
+}
+
+func (r *recordingAlerter) Alerts() []*LoginSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*LoginSession(nil), r.alerts...)
+}
+
+var _ = ginkgo.Describe("SessionRecorder", func() {
+	var (
+		ctx      context.Context
+		repo     *fakeSessionRepository
+		geo      *fakeGeoResolver
+		alerter  *recordingAlerter
+		recorder *SessionRecorder
+		user     *User
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		repo = &fakeSessionRepository{}
+		geo = &fakeGeoResolver{locations: map[string]Location{
+			"203.0.113.10": {Country: "ID", Region: "Jakarta", City: "Jakarta"},
+			"198.51.100.7": {Country: "SG", City: "Singapore"},
+		}}
+		alerter = &recordingAlerter{}
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		recorder = NewSessionRecorder(repo, geo, alerter, logger)
+		user = &User{ID: 1, TenantID: 7, Email: "user@example.com"}
+	})
+
+	ginkgo.It("stores the device and location without alerting on the first login", func() {
+		session, err := recorder.Record(ctx, user, "203.0.113.10", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(session.Device).To(gomega.Equal("Chrome on Windows"))
+		gomega.Expect(session.Location).To(gomega.Equal(Location{Country: "ID", Region: "Jakarta", City: "Jakarta"}))
+		gomega.Expect(session.TenantID).To(gomega.Equal(int64(7)))
+		gomega.Expect(session.NewDevice || session.NewLocation).To(gomega.BeFalse())
+		gomega.Expect(repo.sessions).To(gomega.HaveLen(1))
+		gomega.Consistently(alerter.Alerts, 50*time.Millisecond).Should(gomega.BeEmpty())
+	})
+
+	ginkgo.It("does not alert on a known device and location", func() {
+		_, err := recorder.Record(ctx, user, "203.0.113.10", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		updated := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/127.0.0.0 Safari/537.36"
+		session, err := recorder.Record(ctx, user, "203.0.113.10", updated)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(session.NewDevice || session.NewLocation).To(gomega.BeFalse())
+		gomega.Consistently(alerter.Alerts, 50*time.Millisecond).Should(gomega.BeEmpty())
+	})
+
+	ginkgo.It("alerts on a new device", func() {
+		_, err := recorder.Record(ctx, user, "203.0.113.10", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		session, err := recorder.Record(ctx, user, "203.0.113.10", safariOnIPhone)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(session.NewDevice).To(gomega.BeTrue())
+		gomega.Expect(session.NewLocation).To(gomega.BeFalse())
+		gomega.Eventually(alerter.Alerts).Should(gomega.ConsistOf(session))
+	})
+
+	ginkgo.It("does not alert users who turned login alerts off", func() {
+		repo.alertsOff = true
+		_, err := recorder.Record(ctx, user, "203.0.113.10", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		session, err := recorder.Record(ctx, user, "203.0.113.10", safariOnIPhone)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(session.NewDevice).To(gomega.BeTrue())
+		gomega.Consistently(alerter.Alerts, 50*time.Millisecond).Should(gomega.BeEmpty())
+	})
+
+	ginkgo.It("alerts on a new location", func() {
+		_, err := recorder.Record(ctx, user, "203.0.113.10", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		session, err := recorder.Record(ctx, user, "198.51.100.7", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(session.NewLocation).To(gomega.BeTrue())
+		gomega.Eventually(alerter.Alerts).Should(gomega.ConsistOf(session))
+	})
+
+	ginkgo.It("never looks up private addresses and treats unknown locations as not new", func() {
+		_, err := recorder.Record(ctx, user, "203.0.113.10", chromeOnWindows)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		for _, ip := range []string{"10.0.0.1", "127.0.0.1", "192.0.2.1"} {
+			session, err := recorder.Record(ctx, user, ip, chromeOnWindows)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(session.Country).To(gomega.BeEmpty())
+			gomega.Expect(session.NewLocation).To(gomega.BeFalse())
+		}
+		gomega.Expect(geo.lookups).To(gomega.Equal([]string{"203.0.113.10", "192.0.2.1"}))
+		gomega.Consistently(alerter.Alerts, 50*time.Millisecond).Should(gomega.BeEmpty())
+	})
+
+	ginkgo.It("is fed by the auth service on each login", func() {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		tokenGen := NewJWTTokenGenerator("access", "refresh", time.Minute, time.Hour)
+		service, err := NewService(newMockUserRepository(), tokenGen, bcrypt.DefaultCost, nil, nil, recorder, logger)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		_, err = service.Authenticate(ctx, LoginDTO{Email: "user@example.com", Password: "correct_password", RemoteIP: "203.0.113.10", UserAgent: safariOnIPhone})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.sessions).To(gomega.HaveLen(1))
+		gomega.Expect(repo.sessions[0].Device).To(gomega.Equal("Safari on iOS"))
+		gomega.Expect(repo.sessions[0].IPAddress).To(gomega.Equal("203.0.113.10"))
+
+		_, err = service.Authenticate(ctx, LoginDTO{Email: "user@example.com", Password: "wrong", RemoteIP: "203.0.113.10"})
+		gomega.Expect(err).To(gomega.MatchError(ErrInvalidCredentials))
+		gomega.Expect(repo.sessions).To(gomega.HaveLen(1))
+	})
+})
+
+var _ = ginkgo.Describe("DeviceName", func() {
+	ginkgo.DescribeTable("names the browser and operating system",
+		func(userAgent, want string) {
+			gomega.Expect(DeviceName(userAgent)).To(gomega.Equal(want))
+		},
+		ginkgo.Entry("Chrome", chromeOnWindows, "Chrome on Windows"),
+		ginkgo.Entry("Safari", safariOnIPhone, "Safari on iOS"),
+		ginkgo.Entry("Edge", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0", "Edge on macOS"),
+		ginkgo.Entry("Firefox", "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", "Firefox on Linux"),
+		ginkgo.Entry("Chrome on Android", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36", "Chrome on Android"),
+		ginkgo.Entry("a command line client", "curl/8.6.0", "curl"),
+		ginkgo.Entry("no user agent", "", "Unknown device"),
+	)
+})
+
+var _ = ginkgo.Describe("HTTPGeoResolver", func() {
+	ginkgo.It("reads the location answered for the address", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gomega.Expect(r.URL.Path).To(gomega.Equal("/203.0.113.10/json"))
+			w.Write([]byte(`{"ip": "203.0.113.10", "city": "Jakarta", "region": "Jakarta", "country": "ID"}`))
+		}))
+		defer server.Close()
+
+		loc, err := NewHTTPGeoResolver(server.URL+"/{ip}/json", time.Second).Resolve(context.Background(), "203.0.113.10")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(loc).To(gomega.Equal(Location{Country: "ID", Region: "Jakarta", City: "Jakarta"}))
+	})
+
+	ginkgo.It("fails on an error status", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		_, err := NewHTTPGeoResolver(server.URL+"/{ip}", time.Second).Resolve(context.Background(), "203.0.113.10")
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("status 429")))
+	})
+})
@@ -7,6 +7,8 @@ type PermissionChecker interface {
 	CanRejectExpenses(userPermissions []string) bool
 	CanRetryPayments(userPermissions []string) bool
 	CanViewAllExpenses(userPermissions []string) bool
+	CanViewTeamExpenses(userPermissions []string) bool
+	CanViewAllPayments(userPermissions []string) bool
 	HasAnyPermission(userPermissions []string, requiredPermissions []string) bool
 	IsManager(userPermissions []string) bool
 	IsAdmin(userPermissions []string) bool
@@ -18,8 +20,8 @@ func NewPermissionChecker() PermissionChecker {
 	return &DefaultPermissionChecker{}
 }
 
-func (c *DefaultPermissionChecker) HasPermission(ctx context.Context, userPermissions []string, permission string) (bool, error) {
-	return c.HasAnyPermission(userPermissions, []string{permission}), nil
+func (c *DefaultPermissionChecker) HasPermission(ctx context.Context, userPermissions []string, permission Permission) (bool, error) {
+	return hasAny(userPermissions, permission), nil
 }
 
 func (c *DefaultPermissionChecker) CanApproveExpensesCtx(ctx context.Context, userPermissions []string) (bool, error) {
@@ -34,6 +36,18 @@ func (c *DefaultPermissionChecker) CanRetryPaymentsCtx(ctx context.Context, user
 	return c.CanRetryPayments(userPermissions), nil
 }
 
+func (c *DefaultPermissionChecker) CanClosePeriodsCtx(ctx context.Context, userPermissions []string) (bool, error) {
+	return c.CanClosePeriods(userPermissions), nil
+}
+
+func (c *DefaultPermissionChecker) CanReconcileCardsCtx(ctx context.Context, userPermissions []string) (bool, error) {
+	return c.CanReconcileCards(userPermissions), nil
+}
+
+func (c *DefaultPermissionChecker) CanViewAllPaymentsCtx(ctx context.Context, userPermissions []string) (bool, error) {
+	return c.CanViewAllPayments(userPermissions), nil
+}
+
 func (c *DefaultPermissionChecker) IsManagerCtx(ctx context.Context, userPermissions []string) (bool, error) {
 	return c.IsManager(userPermissions), nil
 }
@@ -43,22 +57,45 @@ func (c *DefaultPermissionChecker) IsAdminCtx(ctx context.Context, userPermissio
 }
 
 func (c *DefaultPermissionChecker) CanApproveExpenses(userPermissions []string) bool {
-	return c.HasAnyPermission(userPermissions, []string{"approve_expenses", "admin"})
+	return hasAny(userPermissions, PermApproveExpenses, PermAdmin)
 }
 
 func (c *DefaultPermissionChecker) CanRejectExpenses(userPermissions []string) bool {
-	return c.HasAnyPermission(userPermissions, []string{"reject_expenses", "admin"})
+	return hasAny(userPermissions, PermRejectExpenses, PermAdmin)
 }
 
 func (c *DefaultPermissionChecker) CanRetryPayments(userPermissions []string) bool {
-	return c.HasAnyPermission(userPermissions, []string{"retry_payments", "admin"})
+	return hasAny(userPermissions, PermRetryPayments, PermAdmin)
+}
+
+func (c *DefaultPermissionChecker) CanClosePeriods(userPermissions []string) bool {
+	return hasAny(userPermissions, PermClosePeriods, PermAdmin)
+}
+
+func (c *DefaultPermissionChecker) CanReconcileCards(userPermissions []string) bool {
+	return hasAny(userPermissions, PermReconcileCards, PermAdmin)
 }
 
+// CanViewAllExpenses, like CanViewAllPayments, is granted by finance_viewer,
+// which grants nothing beyond reading: approve, reject and retry checks do
+// not look at it.
 func (c *DefaultPermissionChecker) CanViewAllExpenses(userPermissions []string) bool {
-	managerPerms := []string{"admin", "approve_expenses", "reject_expenses", "manager"}
-	return c.HasAnyPermission(userPermissions, managerPerms)
+	return hasAny(userPermissions, PermAdmin, PermViewAllExpenses, PermFinanceViewer)
+}
+
+// CanViewAllPayments covers the ledger and payment reconciliation.
+func (c *DefaultPermissionChecker) CanViewAllPayments(userPermissions []string) bool {
+	return hasAny(userPermissions, PermAdmin, PermFinanceViewer)
+}
+
+// CanViewTeamExpenses covers managers and approvers, who see expenses of their
+// department and its sub-departments.
+func (c *DefaultPermissionChecker) CanViewTeamExpenses(userPermissions []string) bool {
+	return hasAny(userPermissions, PermViewTeamExpenses, PermApproveExpenses, PermRejectExpenses, PermManager)
 }
 
+// HasAnyPermission takes plain names because approval routing checks
+// operator-defined approver permissions that are not constants.
 func (c *DefaultPermissionChecker) HasAnyPermission(userPermissions []string, requiredPermissions []string) bool {
 	for _, userPerm := range userPermissions {
 		for _, requiredPerm := range requiredPermissions {
@@ -71,10 +108,9 @@ func (c *DefaultPermissionChecker) HasAnyPermission(userPermissions []string, re
 }
 
 func (c *DefaultPermissionChecker) IsManager(userPermissions []string) bool {
-	managerPerms := []string{"manager", "admin", "approve_expenses", "reject_expenses"}
-	return c.HasAnyPermission(userPermissions, managerPerms)
+	return hasAny(userPermissions, PermManager, PermAdmin, PermApproveExpenses, PermRejectExpenses)
 }
 
 func (c *DefaultPermissionChecker) IsAdmin(userPermissions []string) bool {
-	return c.HasAnyPermission(userPermissions, []string{"admin"})
+	return hasAny(userPermissions, PermAdmin)
 }
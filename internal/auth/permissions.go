@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Permission names a capability granted to users through user_permissions.
+// Checks take Permission rather than string so a misspelt name does not
+// compile.
+type Permission string
+
+const (
+	PermAdmin            Permission = "admin"
+	PermManager          Permission = "manager"
+	PermCreateExpenses   Permission = "create_expenses"
+	PermEditExpenses     Permission = "edit_expenses"
+	PermViewExpenses     Permission = "view_expenses"
+	PermViewTeamExpenses Permission = "view_team_expenses"
+	PermViewAllExpenses  Permission = "view_all_expenses"
+	PermApproveExpenses  Permission = "approve_expenses"
+	PermRejectExpenses   Permission = "reject_expenses"
+	PermRetryPayments    Permission = "retry_payments"
+	PermClosePeriods     Permission = "close_periods"
+	PermReconcileCards   Permission = "reconcile_cards"
+	// PermFinanceViewer reads every expense and payment for audits and
+	// reporting; it never decides on expenses or retries payments.
+	PermFinanceViewer Permission = "finance_viewer"
+)
+
+// PermissionInfo describes a built-in permission.
+type PermissionInfo struct {
+	Name        Permission
+	Description string
+}
+
+// registry lists every built-in permission; the seeder and the
+// 20251018090000 and later permission migrations create exactly these rows.
+var registry = []PermissionInfo{
+	{PermAdmin, "Full administrator"},
+	{PermManager, "Can act as a manager: approve, reject and see team expenses"},
+	{PermCreateExpenses, "Can create expenses"},
+	{PermEditExpenses, "Can edit expenses"},
+	{PermViewExpenses, "Can view expenses"},
+	{PermViewTeamExpenses, "Can view expenses of own department and its sub-departments"},
+	{PermViewAllExpenses, "Can view all expenses"},
+	{PermApproveExpenses, "Can approve expenses"},
+	{PermRejectExpenses, "Can reject expenses"},
+	{PermRetryPayments, "Can retry payments"},
+	{PermClosePeriods, "Can lock and unlock months for month-end close"},
+	{PermReconcileCards, "Can import corporate card transactions and review unmatched ones"},
+	{PermFinanceViewer, "Can view all expenses and payments, without approving, rejecting or retrying them"},
+}
+
+// scopedApprover matches the approver permissions operators add for
+// approval routing, e.g. approve_it; they are not built in.
+var scopedApprover = regexp.MustCompile(`^approve_[a-z0-9]+(_[a-z0-9]+)*$`)
+
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// Permissions returns the built-in permissions.
+func Permissions() []PermissionInfo {
+	return append([]PermissionInfo(nil), registry...)
+}
+
+// LookupPermission returns the built-in permission called name.
+func LookupPermission(name string) (Permission, bool) {
+	for _, info := range registry {
+		if string(info.Name) == name {
+			return info.Name, true
+		}
+	}
+	return "", false
+}
+
+// ValidatePermission accepts built-in permissions and scoped approver
+// permissions (approve_<scope>); anything else is a typo or a permission no
+// check will ever look at. Whether the permission row exists is still up to
+// the database.
+func ValidatePermission(name string) error {
+	if _, ok := LookupPermission(name); ok || scopedApprover.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownPermission, name)
+}
+
+// IsApproverPermission reports whether name lets its holder decide on other
+// people's expenses: approve, reject, manager, or a scoped approver
+// permission used by approval routing.
+func IsApproverPermission(name string) bool {
+	switch Permission(name) {
+	case PermApproveExpenses, PermRejectExpenses, PermManager:
+		return true
+	}
+	return scopedApprover.MatchString(name)
+}
+
+// hasAny reports whether userPermissions holds any of required.
+func hasAny(userPermissions []string, required ...Permission) bool {
+	for _, p := range userPermissions {
+		for _, r := range required {
+			if p == string(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
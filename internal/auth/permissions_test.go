@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Permission registry", func() {
+	ginkgo.It("registers every permission once", func() {
+		seen := map[Permission]bool{}
+		for _, info := range Permissions() {
+			gomega.Expect(seen[info.Name]).To(gomega.BeFalse(), string(info.Name))
+			gomega.Expect(info.Description).ToNot(gomega.BeEmpty())
+			seen[info.Name] = true
+		}
+		gomega.Expect(seen).To(gomega.HaveKey(PermRetryPayments))
+	})
+
+	ginkgo.It("looks up built-in permissions by name", func() {
+		p, ok := LookupPermission("approve_expenses")
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(p).To(gomega.Equal(PermApproveExpenses))
+
+		_, ok = LookupPermission("can_approve")
+		gomega.Expect(ok).To(gomega.BeFalse())
+	})
+
+	ginkgo.DescribeTable("ValidatePermission",
+		func(name string, valid bool) {
+			err := ValidatePermission(name)
+			if valid {
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			} else {
+				gomega.Expect(errors.Is(err, ErrUnknownPermission)).To(gomega.BeTrue())
+			}
+		},
+		ginkgo.Entry("built-in", "retry_payments", true),
+		ginkgo.Entry("scoped approver", "approve_it", true),
+		ginkgo.Entry("multi-word scoped approver", "approve_office_supplies", true),
+		ginkgo.Entry("legacy literal", "can_approve", false),
+		ginkgo.Entry("misspelt built-in", "approve_expense_", false),
+		ginkgo.Entry("bare prefix", "approve_", false),
+		ginkgo.Entry("upper case", "Approve_IT", false),
+		ginkgo.Entry("empty", "", false),
+	)
+})
+
+var _ = ginkgo.Describe("Finance viewer", func() {
+	checker := NewPermissionChecker()
+	viewer := []string{string(PermViewExpenses), string(PermFinanceViewer)}
+
+	ginkgo.It("reads every expense and payment", func() {
+		gomega.Expect(checker.CanViewAllExpenses(viewer)).To(gomega.BeTrue())
+		gomega.Expect(checker.CanViewAllPayments(viewer)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("cannot approve, reject or retry", func() {
+		gomega.Expect(checker.CanApproveExpenses(viewer)).To(gomega.BeFalse())
+		gomega.Expect(checker.CanRejectExpenses(viewer)).To(gomega.BeFalse())
+		gomega.Expect(checker.CanRetryPayments(viewer)).To(gomega.BeFalse())
+		gomega.Expect(checker.IsManager(viewer)).To(gomega.BeFalse())
+		gomega.Expect(IsApproverPermission(string(PermFinanceViewer))).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("is the only way besides admin to read all payments", func() {
+		approver := []string{string(PermApproveExpenses)}
+		gomega.Expect(checker.CanViewAllPayments(approver)).To(gomega.BeFalse())
+	})
+})
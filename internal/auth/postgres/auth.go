@@ -2,7 +2,6 @@ package auth
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/frahmantamala/expense-management/internal/auth"
 	"gorm.io/gorm"
@@ -21,12 +20,12 @@ func NewRepository(db *gorm.DB) auth.RepositoryAPI {
 func (r *Repository) GetPasswordForUsername(email string) (string, string, error) {
 	var passwordHash string
 	var userID string
-	query := `SELECT id, password_hash FROM users WHERE email = ? AND is_active = true`
+	query := `SELECT id, password_hash FROM users WHERE email = ?`
 
 	row := r.db.Raw(query, email).Row()
 	if err := row.Scan(&userID, &passwordHash); err != nil {
 		if err == sql.ErrNoRows {
-			return "", "", fmt.Errorf("user not found")
+			return "", "", auth.ErrUserNotFound
 		}
 		return "", "", err
 	}
@@ -36,12 +35,12 @@ func (r *Repository) GetPasswordForUsername(email string) (string, string, error
 func (r *Repository) GetUserWithPermissions(userID int64) (*auth.User, error) {
 	var user auth.User
 
-	query := `SELECT id, email FROM users WHERE id = ? AND is_active = true`
+	query := `SELECT id, tenant_id, email, is_active, sessions_revoked_at FROM users WHERE id = ?`
 
 	row := r.db.Raw(query, userID).Row()
-	if err := row.Scan(&user.ID, &user.Email); err != nil {
+	if err := row.Scan(&user.ID, &user.TenantID, &user.Email, &user.IsActive, &user.SessionsRevokedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, auth.ErrUserNotFound
 		}
 		return nil, err
 	}
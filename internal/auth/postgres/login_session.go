@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) auth.SessionRepositoryAPI {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func (r *SessionRepository) CreateLoginSession(session *auth.LoginSession) error {
+	query := `INSERT INTO login_sessions
+	             (tenant_id, user_id, ip_address, user_agent, device, country, region, city, new_device, new_location, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	          RETURNING id`
+
+	row := r.db.Raw(query,
+		session.TenantID, session.UserID, session.IPAddress, session.UserAgent, session.Device,
+		session.Country, session.Region, session.City, session.NewDevice, session.NewLocation, session.CreatedAt,
+	).Row()
+	return row.Scan(&session.ID)
+}
+
+func (r *SessionRepository) LoginHistory(userID int64, device string, loc auth.Location) (auth.LoginHistory, error) {
+	var history auth.LoginHistory
+
+	query := `SELECT COUNT(*),
+	                 COALESCE(BOOL_OR(device = ?), false),
+	                 COALESCE(BOOL_OR(country <> '' AND country = ? AND city = ?), false),
+	                 COALESCE((SELECT NOT login_alerts FROM users WHERE id = ?), false)
+	          FROM login_sessions
+	          WHERE user_id = ?`
+
+	row := r.db.Raw(query, device, loc.Country, loc.City, userID, userID).Row()
+	if err := row.Scan(&history.Logins, &history.DeviceSeen, &history.LocationSeen, &history.AlertsOff); err != nil {
+		return history, err
+	}
+	return history, nil
+}
@@ -6,13 +6,17 @@ import (
 	"net/http"
 
 	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
 type PermissionAuthorizer interface {
-	HasPermission(ctx context.Context, userPermissions []string, permission string) (bool, error)
+	HasPermission(ctx context.Context, userPermissions []string, permission Permission) (bool, error)
 	CanApproveExpensesCtx(ctx context.Context, userPermissions []string) (bool, error)
 	CanRejectExpensesCtx(ctx context.Context, userPermissions []string) (bool, error)
 	CanRetryPaymentsCtx(ctx context.Context, userPermissions []string) (bool, error)
+	CanClosePeriodsCtx(ctx context.Context, userPermissions []string) (bool, error)
+	CanReconcileCardsCtx(ctx context.Context, userPermissions []string) (bool, error)
+	CanViewAllPaymentsCtx(ctx context.Context, userPermissions []string) (bool, error)
 	IsManagerCtx(ctx context.Context, userPermissions []string) (bool, error)
 	IsAdminCtx(ctx context.Context, userPermissions []string) (bool, error)
 }
@@ -29,24 +33,28 @@ func NewRBACAuthorization(authorizer PermissionAuthorizer, logger *slog.Logger)
 	}
 }
 
-func (ra *RBACAuthorization) Check(next http.HandlerFunc, permission string) http.HandlerFunc {
+func (ra *RBACAuthorization) log(r *http.Request) *slog.Logger {
+	return logger.FromOr(r.Context(), ra.logger)
+}
+
+func (ra *RBACAuthorization) Check(next http.HandlerFunc, permission Permission) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user, ok := internal.UserFromContext(r.Context())
 		if !ok || user == nil {
-			ra.logger.Warn("authorization check failed: user not found in context")
+			ra.log(r).Warn("authorization check failed: user not found in context")
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
 		hasAccess, err := ra.authorizer.HasPermission(r.Context(), user.Permissions, permission)
 		if err != nil {
-			ra.logger.ErrorContext(r.Context(), "authorization check failed", "error", err, "user_id", user.ID, "permission", permission)
+			ra.log(r).Error("authorization check failed", "error", err, "user_id", user.ID, "permission", permission)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
 		if !hasAccess {
-			ra.logger.WarnContext(r.Context(), "access denied: insufficient permissions",
+			ra.log(r).Warn("access denied: insufficient permissions",
 				"user_id", user.ID,
 				"required_permission", permission,
 				"user_permissions", user.Permissions)
@@ -58,7 +66,7 @@ func (ra *RBACAuthorization) Check(next http.HandlerFunc, permission string) htt
 	}
 }
 
-func (ra *RBACAuthorization) Middleware(permission string) func(http.Handler) http.Handler {
+func (ra *RBACAuthorization) Middleware(permission Permission) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return ra.Check(next.ServeHTTP, permission)
 	}
@@ -75,13 +83,13 @@ func (ra *RBACAuthorization) RequireApproveExpense() func(http.Handler) http.Han
 
 			canApprove, err := ra.authorizer.CanApproveExpensesCtx(r.Context(), user.Permissions)
 			if err != nil {
-				ra.logger.ErrorContext(r.Context(), "approval check failed", "error", err, "user_id", user.ID)
+				ra.log(r).Error("approval check failed", "error", err, "user_id", user.ID)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			if !canApprove {
-				ra.logger.WarnContext(r.Context(), "access denied: cannot approve expenses", "user_id", user.ID)
+				ra.log(r).Warn("access denied: cannot approve expenses", "user_id", user.ID)
 				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
 				return
 			}
@@ -102,13 +110,13 @@ func (ra *RBACAuthorization) RequireRejectExpense() func(http.Handler) http.Hand
 
 			canReject, err := ra.authorizer.CanRejectExpensesCtx(r.Context(), user.Permissions)
 			if err != nil {
-				ra.logger.ErrorContext(r.Context(), "rejection check failed", "error", err, "user_id", user.ID)
+				ra.log(r).Error("rejection check failed", "error", err, "user_id", user.ID)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			if !canReject {
-				ra.logger.WarnContext(r.Context(), "access denied: cannot reject expenses", "user_id", user.ID)
+				ra.log(r).Warn("access denied: cannot reject expenses", "user_id", user.ID)
 				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
 				return
 			}
@@ -129,13 +137,94 @@ func (ra *RBACAuthorization) RequireRetryPayment() func(http.Handler) http.Handl
 
 			canRetry, err := ra.authorizer.CanRetryPaymentsCtx(r.Context(), user.Permissions)
 			if err != nil {
-				ra.logger.ErrorContext(r.Context(), "retry payment check failed", "error", err, "user_id", user.ID)
+				ra.log(r).Error("retry payment check failed", "error", err, "user_id", user.ID)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			if !canRetry {
-				ra.logger.WarnContext(r.Context(), "access denied: cannot retry payments", "user_id", user.ID)
+				ra.log(r).Warn("access denied: cannot retry payments", "user_id", user.ID)
+				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (ra *RBACAuthorization) RequireClosePeriods() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := internal.UserFromContext(r.Context())
+			if !ok || user == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			canClose, err := ra.authorizer.CanClosePeriodsCtx(r.Context(), user.Permissions)
+			if err != nil {
+				ra.log(r).Error("close periods check failed", "error", err, "user_id", user.ID)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if !canClose {
+				ra.log(r).Warn("access denied: cannot close periods", "user_id", user.ID)
+				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (ra *RBACAuthorization) RequireReconcileCards() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := internal.UserFromContext(r.Context())
+			if !ok || user == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			canReconcile, err := ra.authorizer.CanReconcileCardsCtx(r.Context(), user.Permissions)
+			if err != nil {
+				ra.log(r).Error("reconcile cards check failed", "error", err, "user_id", user.ID)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if !canReconcile {
+				ra.log(r).Warn("access denied: cannot reconcile cards", "user_id", user.ID)
+				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (ra *RBACAuthorization) RequireViewAllPayments() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := internal.UserFromContext(r.Context())
+			if !ok || user == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			canView, err := ra.authorizer.CanViewAllPaymentsCtx(r.Context(), user.Permissions)
+			if err != nil {
+				ra.log(r).Error("view payments check failed", "error", err, "user_id", user.ID)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if !canView {
+				ra.log(r).Warn("access denied: cannot view all payments", "user_id", user.ID)
 				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
 				return
 			}
@@ -156,13 +245,13 @@ func (ra *RBACAuthorization) RequireManager() func(http.Handler) http.Handler {
 
 			isManager, err := ra.authorizer.IsManagerCtx(r.Context(), user.Permissions)
 			if err != nil {
-				ra.logger.ErrorContext(r.Context(), "manager check failed", "error", err, "user_id", user.ID)
+				ra.log(r).Error("manager check failed", "error", err, "user_id", user.ID)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			if !isManager {
-				ra.logger.WarnContext(r.Context(), "access denied: manager permissions required", "user_id", user.ID)
+				ra.log(r).Warn("access denied: manager permissions required", "user_id", user.ID)
 				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
 				return
 			}
@@ -183,13 +272,13 @@ func (ra *RBACAuthorization) RequireAdmin() func(http.Handler) http.Handler {
 
 			isAdmin, err := ra.authorizer.IsAdminCtx(r.Context(), user.Permissions)
 			if err != nil {
-				ra.logger.ErrorContext(r.Context(), "admin check failed", "error", err, "user_id", user.ID)
+				ra.log(r).Error("admin check failed", "error", err, "user_id", user.ID)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			if !isAdmin {
-				ra.logger.WarnContext(r.Context(), "access denied: admin permissions required", "user_id", user.ID)
+				ra.log(r).Warn("access denied: admin permissions required", "user_id", user.ID)
 				http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
 				return
 			}
@@ -198,3 +287,25 @@ func (ra *RBACAuthorization) RequireAdmin() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// DenyImpersonation refuses requests made with an impersonation token, for
+// routes that move money, decide on expenses or change credentials.
+func (ra *RBACAuthorization) DenyImpersonation() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := internal.UserFromContext(r.Context())
+			if !ok || user == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if user.Impersonated() {
+				ra.log(r).Warn("access denied: not allowed while impersonating", "user_id", user.ID, "impersonator_id", user.ImpersonatorID)
+				http.Error(w, "Forbidden: not allowed while impersonating", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Service struct {
@@ -14,20 +17,42 @@ type Service struct {
 	tokenGenerator    TokenGeneratorAPI
 	permissionChecker PermissionChecker
 	rbacAuthorization *RBACAuthorization
+	throttle          *LoginThrottle
+	revocations       RevocationChecker
+	sessions          *SessionRecorder
 	bcryptCost        int
-	logger            *slog.Logger
+	// dummyHash stands in for the password hash of unknown emails.
+	dummyHash string
+	verify    func(hashedPassword, password string) error
+	logger    *slog.Logger
 }
 
-func NewService(userRepo RepositoryAPI, tokenGen TokenGeneratorAPI, bcryptCost int, logger *slog.Logger) *Service {
+// dummyPassword is hashed at the configured cost when the service starts;
+// it never logs anyone in.
+const dummyPassword = "constant-time-login-dummy-password"
+
+// NewService builds the auth service; a nil throttle leaves logins
+// unthrottled, nil revocations treats tokens as valid until they expire, and
+// nil sessions keeps no record of logins.
+func NewService(userRepo RepositoryAPI, tokenGen TokenGeneratorAPI, bcryptCost int, throttle *LoginThrottle, revocations RevocationChecker, sessions *SessionRecorder, logger *slog.Logger) (*Service, error) {
 	permChecker := NewPermissionChecker()
+	dummyHash, err := HashPassword(dummyPassword, bcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
 	return &Service{
 		userRepo:          userRepo,
 		tokenGenerator:    tokenGen,
 		permissionChecker: permChecker,
 		rbacAuthorization: NewRBACAuthorization(permChecker.(*DefaultPermissionChecker), logger),
+		throttle:          throttle,
+		revocations:       revocations,
+		sessions:          sessions,
 		bcryptCost:        bcryptCost,
+		dummyHash:         dummyHash,
+		verify:            VerifyPassword,
 		logger:            logger,
-	}
+	}, nil
 }
 
 func NewJWTTokenGenerator(accessSecret, refreshSecret string, accessTTL, refreshTTL time.Duration) *JWTTokenGenerator {
@@ -39,30 +64,59 @@ func NewJWTTokenGenerator(accessSecret, refreshSecret string, accessTTL, refresh
 	}
 }
 
-func (s *Service) Authenticate(dto LoginDTO) (AuthTokens, error) {
+func (s *Service) Authenticate(ctx context.Context, dto LoginDTO) (AuthTokens, error) {
 	if err := dto.Validate(); err != nil {
 		return AuthTokens{}, err
 	}
 
+	if err := s.throttle.Admit(ctx, dto.RemoteIP, dto.Email, dto.CaptchaToken); err != nil {
+		return AuthTokens{}, err
+	}
+
+	// Every attempt pays for exactly one bcrypt comparison, against the
+	// dummy hash when the email is unknown or has no usable password, so
+	// response times do not reveal which emails have accounts.
 	storedHash, userID, err := s.userRepo.GetPasswordForUsername(dto.Email)
-	if err != nil {
+	known := err == nil && isBcryptHash(storedHash)
+	if !known {
+		storedHash = s.dummyHash
+	}
+	if err := s.verify(storedHash, dto.Password); err != nil || !known {
+		s.throttle.Fail(dto.RemoteIP, dto.Email)
 		return AuthTokens{}, ErrInvalidCredentials
 	}
+	s.throttle.Succeed(dto.Email)
 
-	if err := VerifyPassword(storedHash, dto.Password); err != nil {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return AuthTokens{}, fmt.Errorf("invalid user id %q: %w", userID, err)
+	}
+	user, err := s.userRepo.GetUserWithPermissions(id)
+	if err != nil {
 		return AuthTokens{}, ErrInvalidCredentials
 	}
+	// Only someone who knows the password learns the account is inactive.
+	if !user.IsActive {
+		return AuthTokens{}, ErrUserInactive
+	}
 
-	accessToken, err := s.tokenGenerator.GenerateAccessToken(userID, dto.Email)
+	accessToken, err := s.tokenGenerator.GenerateAccessToken(userID, dto.Email, user.TenantID)
 	if err != nil {
 		return AuthTokens{}, err
 	}
 
-	refreshToken, err := s.tokenGenerator.GenerateRefreshToken(userID, dto.Email)
+	refreshToken, err := s.tokenGenerator.GenerateRefreshToken(userID, dto.Email, user.TenantID)
 	if err != nil {
 		return AuthTokens{}, err
 	}
 
+	// A login is not refused because it could not be recorded.
+	if s.sessions != nil {
+		if _, err := s.sessions.Record(ctx, user, dto.RemoteIP, dto.UserAgent); err != nil {
+			s.logger.Error("failed to record login session", "error", err, "user_id", user.ID)
+		}
+	}
+
 	return AuthTokens{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -71,17 +125,23 @@ func (s *Service) Authenticate(dto LoginDTO) (AuthTokens, error) {
 
 func (s *Service) RefreshTokens(refreshToken string) (AuthTokens, error) {
 
-	claims, err := s.tokenGenerator.ValidateToken(refreshToken)
+	claims, err := s.tokenGenerator.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		return AuthTokens{}, err
 	}
+	if _, err := s.TokenUser(claims); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return AuthTokens{}, ErrInvalidToken
+		}
+		return AuthTokens{}, err
+	}
 
-	accessToken, err := s.tokenGenerator.GenerateAccessToken(claims.UserID, claims.Email)
+	accessToken, err := s.tokenGenerator.GenerateAccessToken(claims.UserID, claims.Email, claims.TenantID)
 	if err != nil {
 		return AuthTokens{}, err
 	}
 
-	newRefreshToken, err := s.tokenGenerator.GenerateRefreshToken(claims.UserID, claims.Email)
+	newRefreshToken, err := s.tokenGenerator.GenerateRefreshToken(claims.UserID, claims.Email, claims.TenantID)
 	if err != nil {
 		return AuthTokens{}, err
 	}
@@ -93,50 +153,124 @@ func (s *Service) RefreshTokens(refreshToken string) (AuthTokens, error) {
 }
 
 func (s *Service) ValidateAccessToken(tokenString string) (*Claims, error) {
-	return s.tokenGenerator.ValidateToken(tokenString)
+	return s.tokenGenerator.ValidateAccessToken(tokenString)
+}
+
+// ValidateAccessTokenWithin accepts an access token that expired no more than
+// grace ago, so a client whose token lapses mid-session is told to refresh
+// instead of being logged out.
+func (s *Service) ValidateAccessTokenWithin(tokenString string, grace time.Duration) (*Claims, error) {
+	return s.tokenGenerator.ValidateAccessTokenWithin(tokenString, grace)
+}
+
+// ExchangeRefreshToken answers the OAuth2 refresh_token grant with a new
+// token pair.
+func (s *Service) ExchangeRefreshToken(refreshToken string) (OAuthToken, error) {
+	tokens, err := s.RefreshTokens(refreshToken)
+	if err != nil {
+		return OAuthToken{}, err
+	}
+	claims, err := s.tokenGenerator.ValidateAccessToken(tokens.AccessToken)
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("failed to read issued access token: %w", err)
+	}
+	return OAuthToken{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(claims.ExpiresAt.Time).Round(time.Second).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+	}, nil
 }
 
 func (s *Service) GetUserWithPermissions(userID int64) (*User, error) {
 	return s.userRepo.GetUserWithPermissions(userID)
 }
 
-func (j *JWTTokenGenerator) GenerateAccessToken(userID string, email string) (string, error) {
-	expiresAt := time.Now().Add(j.AccessTokenTTL)
-
-	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
-		},
+// TokenUser loads the user a validated token was issued to and checks the
+// token still speaks for them: the user must be active, and the token must
+// have been issued after their sessions were last revoked. An impersonation
+// token also needs its impersonator to still be an active administrator of
+// the user's tenant.
+func (s *Service) TokenUser(claims *Claims) (*User, error) {
+	userID, err := strconv.ParseInt(claims.UserID, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.userRepo.GetUserWithPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	// iat has whole seconds, so a token issued within the second of the
+	// revocation counts as issued before it.
+	if user.SessionsRevokedAt != nil && (claims.IssuedAt == nil || !claims.IssuedAt.Time.After(*user.SessionsRevokedAt)) {
+		return nil, ErrSessionRevoked
 	}
+	if claims.ImpersonatorID != "" {
+		if err := s.checkImpersonator(claims.ImpersonatorID, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.AccessTokenSecret)
+func (s *Service) checkImpersonator(impersonatorID string, user *User) error {
+	id, err := strconv.ParseInt(impersonatorID, 10, 64)
 	if err != nil {
-		return "", err
+		return ErrInvalidToken
+	}
+	impersonator, err := s.userRepo.GetUserWithPermissions(id)
+	if errors.Is(err, ErrUserNotFound) {
+		return ErrImpersonationEnded
+	}
+	if err != nil {
+		return err
+	}
+	if !impersonator.IsActive || !impersonator.IsAdmin() || impersonator.TenantID != user.TenantID {
+		return ErrImpersonationEnded
 	}
+	return nil
+}
 
-	return tokenString, nil
+func (j *JWTTokenGenerator) GenerateAccessToken(userID string, email string, tenantID int64) (string, error) {
+	return j.generate(TokenTypeAccess, userID, email, tenantID, "", j.AccessTokenTTL, j.AccessTokenSecret)
 }
 
-func (j *JWTTokenGenerator) GenerateRefreshToken(userID string, email string) (string, error) {
-	expiresAt := time.Now().Add(j.RefreshTokenTTL)
+func (j *JWTTokenGenerator) GenerateRefreshToken(userID string, email string, tenantID int64) (string, error) {
+	return j.generate(TokenTypeRefresh, userID, email, tenantID, "", j.RefreshTokenTTL, j.RefreshTokenSecret)
+}
+
+// GenerateImpersonationToken issues an access token for userID that names
+// impersonatorID as the one acting and lasts ttl. No refresh token goes with
+// it; the impersonation ends when it expires.
+func (j *JWTTokenGenerator) GenerateImpersonationToken(userID, email string, tenantID int64, impersonatorID string, ttl time.Duration) (string, error) {
+	return j.generate(TokenTypeAccess, userID, email, tenantID, impersonatorID, ttl, j.AccessTokenSecret)
+}
+
+func (j *JWTTokenGenerator) generate(tokenType TokenType, userID, email string, tenantID int64, impersonatorID string, ttl time.Duration, secret []byte) (string, error) {
+	now := time.Now()
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:         userID,
+		Email:          email,
+		TenantID:       tenantID,
+		TokenType:      tokenType,
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Subject:   userID,
+			Issuer:    j.Issuer,
 		},
 	}
+	if j.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.Audience}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.RefreshTokenSecret)
+	tokenString, err := token.SignedString(secret)
 	if err != nil {
 		return "", err
 	}
@@ -144,20 +278,45 @@ func (j *JWTTokenGenerator) GenerateRefreshToken(userID string, email string) (s
 	return tokenString, nil
 }
 
-func (j *JWTTokenGenerator) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+func (j *JWTTokenGenerator) ValidateAccessToken(tokenString string) (*Claims, error) {
+	return j.validate(tokenString, TokenTypeAccess, 0)
+}
 
-		if claims, ok := token.Claims.(*Claims); ok {
-			if time.Until(claims.ExpiresAt.Time) > j.AccessTokenTTL {
-				return j.RefreshTokenSecret, nil
-			}
-		}
-		return j.AccessTokenSecret, nil
-	})
+// ValidateAccessTokenWithin is ValidateAccessToken for a token that may have
+// expired no more than grace ago.
+func (j *JWTTokenGenerator) ValidateAccessTokenWithin(tokenString string, grace time.Duration) (*Claims, error) {
+	return j.validate(tokenString, TokenTypeAccess, grace)
+}
+
+func (j *JWTTokenGenerator) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	return j.validate(tokenString, TokenTypeRefresh, 0)
+}
 
+// validate checks tokenString against the secret of the type the caller
+// expects, never one picked from the token's own claims, and rejects a
+// token of any other type. Issuer and audience are checked when set, and
+// expiry is checked allowing ClockSkew plus grace.
+func (j *JWTTokenGenerator) validate(tokenString string, want TokenType, grace time.Duration) (*Claims, error) {
+	secret := j.AccessTokenSecret
+	if want == TokenTypeRefresh {
+		secret = j.RefreshTokenSecret
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(j.ClockSkew + grace),
+	}
+	if j.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.Issuer))
+	}
+	if j.Audience != "" {
+		opts = append(opts, jwt.WithAudience(j.Audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(*jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, opts...)
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrTokenExpired
@@ -165,11 +324,18 @@ func (j *JWTTokenGenerator) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.TokenType != want {
+		return nil, ErrInvalidToken
 	}
+	return claims, nil
+}
 
-	return nil, ErrInvalidToken
+// isBcryptHash reports whether hash can be compared against at full cost; a
+// malformed hash would fail the comparison instantly.
+func isBcryptHash(hash string) bool {
+	_, err := bcrypt.Cost([]byte(hash))
+	return err == nil
 }
 
 func (s *Service) HashPassword(password string) (string, error) {
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"os"
@@ -40,9 +41,9 @@ func newMockUserRepository() *mockUserRepository {
 			"manager@example.com": "3",
 		},
 		usersByID: map[int64]*User{
-			1: {ID: 1, Email: "user@example.com", Permissions: []string{"can_read_expense"}},
-			2: {ID: 2, Email: "admin@example.com", Permissions: []string{"can_read_expense", "can_approve", "can_reject"}},
-			3: {ID: 3, Email: "manager@example.com", Permissions: []string{"can_read_expense", "can_approve"}},
+			1: {ID: 1, Email: "user@example.com", Permissions: []string{string(PermViewExpenses)}, IsActive: true},
+			2: {ID: 2, TenantID: 7, Email: "admin@example.com", Permissions: []string{string(PermViewExpenses), string(PermApproveExpenses), string(PermRejectExpenses)}, IsActive: true},
+			3: {ID: 3, Email: "manager@example.com", Permissions: []string{string(PermViewExpenses), string(PermApproveExpenses)}, IsActive: true},
 		},
 	}
 }
@@ -57,7 +58,7 @@ func (m *mockUserRepository) GetPasswordForUsername(username string) (string, st
 			return hash, userID, nil
 		}
 	}
-	return "", "", errors.New("user not found")
+	return "", "", ErrUserNotFound
 }
 
 func (m *mockUserRepository) GetUserWithPermissions(userID int64) (*User, error) {
@@ -68,7 +69,7 @@ func (m *mockUserRepository) GetUserWithPermissions(userID int64) (*User, error)
 	if user, exists := m.usersByID[userID]; exists {
 		return user, nil
 	}
-	return nil, errors.New("user not found")
+	return nil, ErrUserNotFound
 }
 
 func (m *mockUserRepository) setError(err error) {
@@ -97,7 +98,9 @@ var _ = ginkgo.Describe("AuthService", func() {
 		mockRepo = newMockUserRepository()
 		tokenGen = NewJWTTokenGenerator(accessSecret, refreshSecret, accessTTL, refreshTTL)
 		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-		service = NewService(mockRepo, tokenGen, bcrypt.DefaultCost, logger)
+		var err error
+		service, err = NewService(mockRepo, tokenGen, bcrypt.DefaultCost, nil, nil, nil, logger)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
 	})
 
 	ginkgo.Describe("Authenticate", func() {
@@ -109,7 +112,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "correct_password",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 				gomega.Expect(tokens.AccessToken).ToNot(gomega.BeEmpty())
@@ -124,7 +127,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "correct_password",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
@@ -132,6 +135,19 @@ var _ = ginkgo.Describe("AuthService", func() {
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 				gomega.Expect(claims.UserID).To(gomega.Equal("2"))
 				gomega.Expect(claims.Email).To(gomega.Equal("admin@example.com"))
+				gomega.Expect(claims.TenantID).To(gomega.Equal(int64(7)))
+			})
+
+			ginkgo.It("should keep the tenant claim across refreshes", func() {
+				tokens, err := service.Authenticate(context.Background(), LoginDTO{Email: "admin@example.com", Password: "correct_password"})
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				refreshed, err := service.RefreshTokens(tokens.RefreshToken)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				claims, err := service.ValidateAccessToken(refreshed.AccessToken)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(claims.TenantID).To(gomega.Equal(int64(7)))
 			})
 		})
 
@@ -143,7 +159,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "any_password",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
@@ -151,6 +167,16 @@ var _ = ginkgo.Describe("AuthService", func() {
 				gomega.Expect(tokens.RefreshToken).To(gomega.BeEmpty())
 			})
 
+			ginkgo.It("should return ErrUserInactive for a deactivated user's correct password", func() {
+				mockRepo.usersByID[1].IsActive = false
+
+				_, err := service.Authenticate(context.Background(), LoginDTO{Email: "user@example.com", Password: "correct_password"})
+				gomega.Expect(err).To(gomega.Equal(ErrUserInactive))
+
+				_, err = service.Authenticate(context.Background(), LoginDTO{Email: "user@example.com", Password: "wrong_password"})
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
+			})
+
 			ginkgo.It("should return error for invalid password", func() {
 
 				dto := LoginDTO{
@@ -158,7 +184,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "wrong_password",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
@@ -167,6 +193,50 @@ var _ = ginkgo.Describe("AuthService", func() {
 			})
 		})
 
+		ginkgo.Context("when comparing failed logins", func() {
+			var compared []string
+
+			// Every attempt must pay for one bcrypt comparison; record which
+			// hashes were compared instead of timing the attempts.
+			ginkgo.BeforeEach(func() {
+				compared = nil
+				service.verify = func(hashedPassword, password string) error {
+					compared = append(compared, hashedPassword)
+					return VerifyPassword(hashedPassword, password)
+				}
+			})
+
+			ginkgo.It("should compare the dummy hash for an unknown email", func() {
+				_, err := service.Authenticate(context.Background(), LoginDTO{Email: "nonexistent@example.com", Password: "wrong_password"})
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
+				gomega.Expect(compared).To(gomega.Equal([]string{service.dummyHash}))
+			})
+
+			ginkgo.It("should compare the dummy hash for an account without a usable password", func() {
+				mockRepo.users["sso@example.com"] = ""
+				mockRepo.userIDs["sso@example.com"] = "4"
+
+				_, err := service.Authenticate(context.Background(), LoginDTO{Email: "sso@example.com", Password: "wrong_password"})
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
+				gomega.Expect(compared).To(gomega.Equal([]string{service.dummyHash}))
+			})
+
+			ginkgo.It("should compare the stored hash for a wrong password", func() {
+				_, err := service.Authenticate(context.Background(), LoginDTO{Email: "user@example.com", Password: "wrong_password"})
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
+				gomega.Expect(compared).To(gomega.Equal([]string{mockRepo.users["user@example.com"]}))
+			})
+
+			ginkgo.It("should never log in with the dummy password", func() {
+				tokens, err := service.Authenticate(context.Background(), LoginDTO{Email: "nonexistent@example.com", Password: dummyPassword})
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
+				gomega.Expect(tokens.AccessToken).To(gomega.BeEmpty())
+			})
+		})
+
 		ginkgo.Context("when input validation fails", func() {
 			ginkgo.It("should return validation error for empty email", func() {
 
@@ -175,7 +245,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "password",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(err.Error()).To(gomega.ContainSubstring("email is required"))
@@ -189,7 +259,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(err.Error()).To(gomega.ContainSubstring("password is required"))
@@ -206,7 +276,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 					Password: "correct_password",
 				}
 
-				tokens, err := service.Authenticate(dto)
+				tokens, err := service.Authenticate(context.Background(), dto)
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(err).To(gomega.Equal(ErrInvalidCredentials))
@@ -224,7 +294,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 				Email:    "user@example.com",
 				Password: "correct_password",
 			}
-			tokens, err := service.Authenticate(dto)
+			tokens, err := service.Authenticate(context.Background(), dto)
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			validRefreshToken = tokens.RefreshToken
 		})
@@ -255,6 +325,33 @@ var _ = ginkgo.Describe("AuthService", func() {
 			})
 		})
 
+		ginkgo.Context("when the user was deactivated", func() {
+			ginkgo.It("should refuse the refresh token", func() {
+				mockRepo.usersByID[1].IsActive = false
+
+				_, err := service.RefreshTokens(validRefreshToken)
+				gomega.Expect(err).To(gomega.Equal(ErrUserInactive))
+			})
+		})
+
+		ginkgo.Context("when the user's sessions were revoked", func() {
+			ginkgo.It("should refuse tokens issued before the revocation", func() {
+				revokedAt := time.Now().Add(time.Second)
+				mockRepo.usersByID[1].SessionsRevokedAt = &revokedAt
+
+				_, err := service.RefreshTokens(validRefreshToken)
+				gomega.Expect(err).To(gomega.Equal(ErrSessionRevoked))
+			})
+
+			ginkgo.It("should accept tokens issued after it", func() {
+				revokedAt := time.Now().Add(-time.Minute)
+				mockRepo.usersByID[1].SessionsRevokedAt = &revokedAt
+
+				_, err := service.RefreshTokens(validRefreshToken)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			})
+		})
+
 		ginkgo.Context("when refresh token is invalid", func() {
 			ginkgo.It("should return error for malformed token", func() {
 
@@ -268,7 +365,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 			ginkgo.It("should return error for expired token", func() {
 
 				expiredTokenGen := NewJWTTokenGenerator(accessSecret, refreshSecret, -1*time.Hour, -1*time.Hour)
-				expiredToken, err := expiredTokenGen.GenerateRefreshToken("1", "user@example.com")
+				expiredToken, err := expiredTokenGen.GenerateRefreshToken("1", "user@example.com", 0)
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 				tokens, err := service.RefreshTokens(expiredToken)
@@ -290,7 +387,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 				Email:    "manager@example.com",
 				Password: "correct_password",
 			}
-			tokens, err := service.Authenticate(dto)
+			tokens, err := service.Authenticate(context.Background(), dto)
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			validAccessToken = tokens.AccessToken
 		})
@@ -328,7 +425,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 			ginkgo.It("should return error for expired token", func() {
 
 				expiredTokenGen := NewJWTTokenGenerator(accessSecret, refreshSecret, -1*time.Hour, refreshTTL)
-				expiredToken, err := expiredTokenGen.GenerateAccessToken("1", "user@example.com")
+				expiredToken, err := expiredTokenGen.GenerateAccessToken("1", "user@example.com", 0)
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 				claims, err := service.ValidateAccessToken(expiredToken)
@@ -350,7 +447,7 @@ var _ = ginkgo.Describe("AuthService", func() {
 				gomega.Expect(user).ToNot(gomega.BeNil())
 				gomega.Expect(user.ID).To(gomega.Equal(int64(2)))
 				gomega.Expect(user.Email).To(gomega.Equal("admin@example.com"))
-				gomega.Expect(user.Permissions).To(gomega.ContainElements("can_read_expense", "can_approve", "can_reject"))
+				gomega.Expect(user.Permissions).To(gomega.ContainElements(string(PermViewExpenses), string(PermApproveExpenses), string(PermRejectExpenses)))
 			})
 		})
 
@@ -428,12 +525,12 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 			userID := "123"
 			email := "test@example.com"
 
-			token, err := tokenGen.GenerateAccessToken(userID, email)
+			token, err := tokenGen.GenerateAccessToken(userID, email, 0)
 
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			gomega.Expect(token).ToNot(gomega.BeEmpty())
 
-			claims, err := tokenGen.ValidateToken(token)
+			claims, err := tokenGen.ValidateAccessToken(token)
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			gomega.Expect(claims.UserID).To(gomega.Equal(userID))
 			gomega.Expect(claims.Email).To(gomega.Equal(email))
@@ -446,12 +543,12 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 			userID := "456"
 			email := "refresh@example.com"
 
-			token, err := tokenGen.GenerateRefreshToken(userID, email)
+			token, err := tokenGen.GenerateRefreshToken(userID, email, 0)
 
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			gomega.Expect(token).ToNot(gomega.BeEmpty())
 
-			claims, err := tokenGen.ValidateToken(token)
+			claims, err := tokenGen.ValidateRefreshToken(token)
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			gomega.Expect(claims.UserID).To(gomega.Equal(userID))
 			gomega.Expect(claims.Email).To(gomega.Equal(email))
@@ -464,10 +561,10 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 
 				userID := "789"
 				email := "validate@example.com"
-				token, err := tokenGen.GenerateAccessToken(userID, email)
+				token, err := tokenGen.GenerateAccessToken(userID, email, 0)
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
-				claims, err := tokenGen.ValidateToken(token)
+				claims, err := tokenGen.ValidateAccessToken(token)
 
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 				gomega.Expect(claims.UserID).To(gomega.Equal(userID))
@@ -481,10 +578,10 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 
 				userID := "101"
 				email := "refresh-validate@example.com"
-				token, err := tokenGen.GenerateRefreshToken(userID, email)
+				token, err := tokenGen.GenerateRefreshToken(userID, email, 0)
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
-				claims, err := tokenGen.ValidateToken(token)
+				claims, err := tokenGen.ValidateRefreshToken(token)
 
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 				gomega.Expect(claims.UserID).To(gomega.Equal(userID))
@@ -496,7 +593,7 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 		ginkgo.Context("with invalid token", func() {
 			ginkgo.It("should return error for malformed token", func() {
 
-				claims, err := tokenGen.ValidateToken("invalid.token.here")
+				claims, err := tokenGen.ValidateAccessToken("invalid.token.here")
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(claims).To(gomega.BeNil())
@@ -504,7 +601,7 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 
 			ginkgo.It("should return error for empty token", func() {
 
-				claims, err := tokenGen.ValidateToken("")
+				claims, err := tokenGen.ValidateAccessToken("")
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(claims).To(gomega.BeNil())
@@ -515,15 +612,97 @@ var _ = ginkgo.Describe("JWTTokenGenerator", func() {
 			ginkgo.It("should return ErrTokenExpired", func() {
 
 				expiredGen := NewJWTTokenGenerator(accessSecret, refreshSecret, -1*time.Hour, -1*time.Hour)
-				token, err := expiredGen.GenerateAccessToken("123", "expired@example.com")
+				token, err := expiredGen.GenerateAccessToken("123", "expired@example.com", 0)
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
-				claims, err := tokenGen.ValidateToken(token)
+				claims, err := tokenGen.ValidateAccessToken(token)
 
 				gomega.Expect(err).To(gomega.HaveOccurred())
 				gomega.Expect(err).To(gomega.Equal(ErrTokenExpired))
 				gomega.Expect(claims).To(gomega.BeNil())
 			})
+
+			ginkgo.It("should accept a token expired within the clock skew", func() {
+
+				expiredGen := NewJWTTokenGenerator(accessSecret, refreshSecret, -10*time.Second, refreshTTL)
+				token, err := expiredGen.GenerateAccessToken("123", "skew@example.com", 0)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				tokenGen.ClockSkew = 30 * time.Second
+				claims, err := tokenGen.ValidateAccessToken(token)
+
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(claims.UserID).To(gomega.Equal("123"))
+			})
+		})
+
+		ginkgo.Context("with a token of the wrong type", func() {
+			ginkgo.It("should reject a refresh token used as an access token", func() {
+
+				sameSecretGen := NewJWTTokenGenerator(accessSecret, accessSecret, accessTTL, refreshTTL)
+				token, err := sameSecretGen.GenerateRefreshToken("123", "type@example.com", 0)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				claims, err := sameSecretGen.ValidateAccessToken(token)
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidToken))
+				gomega.Expect(claims).To(gomega.BeNil())
+			})
+
+			ginkgo.It("should reject an access token used as a refresh token", func() {
+
+				token, err := tokenGen.GenerateAccessToken("123", "type@example.com", 0)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				claims, err := tokenGen.ValidateRefreshToken(token)
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidToken))
+				gomega.Expect(claims).To(gomega.BeNil())
+			})
+		})
+
+		ginkgo.Context("with issuer and audience set", func() {
+			ginkgo.BeforeEach(func() {
+				tokenGen.Issuer = "expense-management"
+				tokenGen.Audience = "expense-management-api"
+			})
+
+			ginkgo.It("should accept its own tokens", func() {
+
+				token, err := tokenGen.GenerateAccessToken("123", "iss@example.com", 0)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				claims, err := tokenGen.ValidateAccessToken(token)
+
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(claims.Issuer).To(gomega.Equal("expense-management"))
+				gomega.Expect(claims.Audience).To(gomega.ConsistOf("expense-management-api"))
+			})
+
+			ginkgo.It("should reject a token from another issuer", func() {
+
+				other := NewJWTTokenGenerator(accessSecret, refreshSecret, accessTTL, refreshTTL)
+				other.Issuer = "someone-else"
+				other.Audience = tokenGen.Audience
+				token, err := other.GenerateAccessToken("123", "iss@example.com", 0)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				_, err = tokenGen.ValidateAccessToken(token)
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidToken))
+			})
+
+			ginkgo.It("should reject a token without the audience", func() {
+
+				other := NewJWTTokenGenerator(accessSecret, refreshSecret, accessTTL, refreshTTL)
+				other.Issuer = tokenGen.Issuer
+				token, err := other.GenerateAccessToken("123", "aud@example.com", 0)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				_, err = tokenGen.ValidateAccessToken(token)
+
+				gomega.Expect(err).To(gomega.Equal(ErrInvalidToken))
+			})
 		})
 	})
 })
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultThrottleWindow is used when a LoginThrottle is given no window.
+const DefaultThrottleWindow = 15 * time.Minute
+
+// maxTrackedKeys bounds the attempt table; expired entries are swept once it
+// grows past this.
+const maxTrackedKeys = 10000
+
+// ThrottledError is returned while a client IP is locked out of logging in.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+type attempts struct {
+	failures int
+	since    time.Time
+}
+
+// LoginThrottle counts failed logins per client IP and per email within a
+// fixed window. Once either count reaches captchaAfter the login needs a
+// CAPTCHA token; once the IP's count reaches lockAfter the IP is refused
+// until its window ends. Accounts are never locked, so a guessed email
+// cannot be used to lock its owner out. Counts live in memory and are
+// per instance. A nil *LoginThrottle admits everything.
+type LoginThrottle struct {
+	window       time.Duration
+	captchaAfter int
+	lockAfter    int
+	verifier     CaptchaVerifier
+	now          func() time.Time
+
+	mu       sync.Mutex
+	attempts map[string]*attempts
+}
+
+// NewLoginThrottle builds a throttle; a zero captchaAfter or lockAfter
+// disables that step, as does a nil verifier for the CAPTCHA step.
+func NewLoginThrottle(window time.Duration, captchaAfter, lockAfter int, verifier CaptchaVerifier) *LoginThrottle {
+	if window <= 0 {
+		window = DefaultThrottleWindow
+	}
+	return &LoginThrottle{
+		window:       window,
+		captchaAfter: captchaAfter,
+		lockAfter:    lockAfter,
+		verifier:     verifier,
+		now:          time.Now,
+		attempts:     make(map[string]*attempts),
+	}
+}
+
+func ipKey(ip string) string       { return "ip:" + ip }
+func emailKey(email string) string { return "email:" + strings.ToLower(strings.TrimSpace(email)) }
+
+// Admit decides whether a login from ip for email may be attempted. It
+// returns a *ThrottledError while ip is locked, ErrCaptchaRequired when a
+// CAPTCHA is due but captchaToken is empty, and ErrCaptchaInvalid when the
+// token is rejected; a rejected token counts as a failed attempt.
+func (t *LoginThrottle) Admit(ctx context.Context, ip, email, captchaToken string) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := t.now()
+	ipAttempts := *t.current(ipKey(ip), now)
+	emailAttempts := *t.current(emailKey(email), now)
+	t.mu.Unlock()
+
+	if t.lockAfter > 0 && ipAttempts.failures >= t.lockAfter {
+		return &ThrottledError{RetryAfter: ipAttempts.since.Add(t.window).Sub(now)}
+	}
+
+	if t.verifier == nil || t.captchaAfter <= 0 {
+		return nil
+	}
+	if ipAttempts.failures < t.captchaAfter && emailAttempts.failures < t.captchaAfter {
+		return nil
+	}
+	if captchaToken == "" {
+		return ErrCaptchaRequired
+	}
+	if err := t.verifier.Verify(ctx, captchaToken, ip); err != nil {
+		if errors.Is(err, ErrCaptchaInvalid) {
+			t.Fail(ip, email)
+		}
+		return err
+	}
+	return nil
+}
+
+// Fail records a failed login.
+func (t *LoginThrottle) Fail(ip, email string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if len(t.attempts) >= maxTrackedKeys {
+		t.sweep(now)
+	}
+	for _, key := range []string{ipKey(ip), emailKey(email)} {
+		a := t.current(key, now)
+		if a.failures == 0 {
+			a = &attempts{since: now}
+			t.attempts[key] = a
+		}
+		a.failures++
+	}
+}
+
+// Succeed clears the account's failures after a successful login. The IP's
+// failures stand, so one valid account does not reset guessing at others.
+func (t *LoginThrottle) Succeed(email string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.attempts, emailKey(email))
+}
+
+// current returns the attempts for key in the window containing now; the
+// zero value when there are none. Callers hold mu.
+func (t *LoginThrottle) current(key string, now time.Time) *attempts {
+	a, ok := t.attempts[key]
+	if !ok || !now.Before(a.since.Add(t.window)) {
+		return &attempts{}
+	}
+	return a
+}
+
+func (t *LoginThrottle) sweep(now time.Time) {
+	for key, a := range t.attempts {
+		if !now.Before(a.since.Add(t.window)) {
+			delete(t.attempts, key)
+		}
+	}
+}
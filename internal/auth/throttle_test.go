@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeCaptchaVerifier struct {
+	valid  string
+	tokens []string
+}
+
+func (f *fakeCaptchaVerifier) Verify(_ context.Context, token, _ string) error {
+	f.tokens = append(f.tokens, token)
+	if token != f.valid {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
+
+var _ = ginkgo.Describe("LoginThrottle", func() {
+	var (
+		ctx      context.Context
+		now      time.Time
+		verifier *fakeCaptchaVerifier
+		throttle *LoginThrottle
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+		verifier = &fakeCaptchaVerifier{valid: "solved"}
+		throttle = NewLoginThrottle(10*time.Minute, 3, 5, verifier)
+		throttle.now = func() time.Time { return now }
+	})
+
+	failTimes := func(n int, ip, email string) {
+		for i := 0; i < n; i++ {
+			throttle.Fail(ip, email)
+		}
+	}
+
+	ginkgo.It("admits logins below the CAPTCHA threshold", func() {
+		failTimes(2, "10.0.0.1", "user@example.com")
+
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.1", "user@example.com", "")).To(gomega.Succeed())
+		gomega.Expect(verifier.tokens).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("requires a CAPTCHA after repeated failures for an email from any IP", func() {
+		throttle.Fail("10.0.0.1", "User@Example.com")
+		throttle.Fail("10.0.0.2", "user@example.com")
+		throttle.Fail("10.0.0.3", "user@example.com")
+
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.4", "user@example.com", "")).To(gomega.MatchError(ErrCaptchaRequired))
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.4", "user@example.com", "solved")).To(gomega.Succeed())
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.4", "other@example.com", "")).To(gomega.Succeed())
+	})
+
+	ginkgo.It("counts a rejected CAPTCHA as a failure", func() {
+		failTimes(4, "10.0.0.1", "user@example.com")
+
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.1", "user@example.com", "guess")).To(gomega.MatchError(ErrCaptchaInvalid))
+
+		var throttled *ThrottledError
+		gomega.Expect(errors.As(throttle.Admit(ctx, "10.0.0.1", "user@example.com", "solved"), &throttled)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("locks out an IP until its window ends", func() {
+		failTimes(5, "10.0.0.1", "user@example.com")
+		now = now.Add(4 * time.Minute)
+
+		err := throttle.Admit(ctx, "10.0.0.1", "someone@example.com", "solved")
+		var throttled *ThrottledError
+		gomega.Expect(errors.As(err, &throttled)).To(gomega.BeTrue())
+		gomega.Expect(throttled.RetryAfter).To(gomega.Equal(6 * time.Minute))
+
+		now = now.Add(6 * time.Minute)
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.1", "someone@example.com", "")).To(gomega.Succeed())
+	})
+
+	ginkgo.It("never locks an account out from other IPs", func() {
+		for i := 0; i < 10; i++ {
+			throttle.Fail("10.0.0.1", "user@example.com")
+			throttle.Fail("10.0.0.2", "user@example.com")
+		}
+
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.3", "user@example.com", "solved")).To(gomega.Succeed())
+	})
+
+	ginkgo.It("clears the account but not the IP on success", func() {
+		failTimes(3, "10.0.0.1", "user@example.com")
+
+		throttle.Succeed("user@example.com")
+
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.2", "user@example.com", "")).To(gomega.Succeed())
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.1", "other@example.com", "")).To(gomega.MatchError(ErrCaptchaRequired))
+	})
+
+	ginkgo.It("skips the CAPTCHA step without a verifier", func() {
+		throttle = NewLoginThrottle(time.Minute, 3, 5, nil)
+		failTimes(4, "10.0.0.1", "user@example.com")
+
+		gomega.Expect(throttle.Admit(ctx, "10.0.0.1", "user@example.com", "")).To(gomega.Succeed())
+	})
+
+	ginkgo.Context("in the auth service", func() {
+		ginkgo.It("asks for a CAPTCHA after wrong passwords and clears it on success", func() {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+			tokenGen := NewJWTTokenGenerator("access", "refresh", time.Minute, time.Hour)
+			service, err := NewService(newMockUserRepository(), tokenGen, bcrypt.DefaultCost, throttle, nil, nil, logger)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			wrong := LoginDTO{Email: "user@example.com", Password: "wrong", RemoteIP: "10.0.0.1"}
+			for i := 0; i < 3; i++ {
+				_, err := service.Authenticate(ctx, wrong)
+				gomega.Expect(err).To(gomega.MatchError(ErrInvalidCredentials))
+			}
+
+			right := LoginDTO{Email: "user@example.com", Password: "correct_password", RemoteIP: "10.0.0.2"}
+			_, err = service.Authenticate(ctx, right)
+			gomega.Expect(err).To(gomega.MatchError(ErrCaptchaRequired))
+
+			right.CaptchaToken = "solved"
+			_, err = service.Authenticate(ctx, right)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			right.CaptchaToken = ""
+			_, err = service.Authenticate(ctx, right)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		})
+	})
+})
+
+var _ = ginkgo.Describe("SiteVerifyCaptcha", func() {
+	var (
+		server  *httptest.Server
+		form    map[string]string
+		respond string
+	)
+
+	ginkgo.BeforeEach(func() {
+		respond = `{"success": true}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gomega.Expect(r.ParseForm()).To(gomega.Succeed())
+			form = map[string]string{
+				"secret":   r.PostForm.Get("secret"),
+				"response": r.PostForm.Get("response"),
+				"remoteip": r.PostForm.Get("remoteip"),
+			}
+			_, _ = w.Write([]byte(respond))
+		}))
+	})
+
+	ginkgo.AfterEach(func() {
+		server.Close()
+	})
+
+	ginkgo.It("posts the secret, token and client IP", func() {
+		verifier := newSiteVerifyCaptcha(server.URL, "s3cret")
+
+		gomega.Expect(verifier.Verify(context.Background(), "token", "10.0.0.1")).To(gomega.Succeed())
+		gomega.Expect(form).To(gomega.Equal(map[string]string{"secret": "s3cret", "response": "token", "remoteip": "10.0.0.1"}))
+	})
+
+	ginkgo.It("rejects tokens the provider does not accept", func() {
+		respond = `{"success": false, "error-codes": ["invalid-input-response"]}`
+		verifier := newSiteVerifyCaptcha(server.URL, "s3cret")
+
+		err := verifier.Verify(context.Background(), "token", "")
+		gomega.Expect(err).To(gomega.MatchError(ErrCaptchaInvalid))
+		gomega.Expect(err.Error()).To(gomega.ContainSubstring("invalid-input-response"))
+	})
+
+	ginkgo.It("reports provider outages as a plain error", func() {
+		server.Close()
+		verifier := newSiteVerifyCaptcha(server.URL, "s3cret")
+
+		err := verifier.Verify(context.Background(), "token", "")
+		gomega.Expect(err).To(gomega.HaveOccurred())
+		gomega.Expect(errors.Is(err, ErrCaptchaInvalid)).To(gomega.BeFalse())
+	})
+})
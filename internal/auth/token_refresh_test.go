@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = ginkgo.Describe("Token refresh", func() {
+	const (
+		accessSecret  = "test-access-secret"
+		refreshSecret = "test-refresh-secret"
+	)
+
+	var (
+		service  *Service
+		tokenGen *JWTTokenGenerator
+		handler  *Handler
+	)
+
+	ginkgo.BeforeEach(func() {
+		tokenGen = NewJWTTokenGenerator(accessSecret, refreshSecret, 15*time.Minute, 24*time.Hour)
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		var err error
+		service, err = NewService(newMockUserRepository(), tokenGen, bcrypt.DefaultCost, nil, nil, nil, logger)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		handler = NewHandler(service, 5*time.Minute)
+		handler.Logger = logger
+	})
+
+	// accessTokenExpiringIn issues user 2's access token expiring in ttl,
+	// which may be negative.
+	accessTokenExpiringIn := func(ttl time.Duration) string {
+		gen := NewJWTTokenGenerator(accessSecret, refreshSecret, ttl, 24*time.Hour)
+		token, err := gen.GenerateAccessToken("2", "admin@example.com", 7)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return token
+	}
+
+	ginkgo.Describe("AuthMiddleware", func() {
+		serve := func(h *Handler, token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			h.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(rec, req)
+			return rec
+		}
+
+		ginkgo.It("accepts a token that expired within the grace period and requires a refresh", func() {
+			rec := serve(handler, accessTokenExpiringIn(-time.Minute))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(rec.Header().Get(TokenRefreshHeader)).To(gomega.Equal(RefreshRequired))
+		})
+
+		ginkgo.It("recommends a refresh for a token about to expire", func() {
+			rec := serve(handler, accessTokenExpiringIn(time.Minute))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(rec.Header().Get(TokenRefreshHeader)).To(gomega.Equal(RefreshRecommended))
+		})
+
+		ginkgo.It("sends no hint for a fresh token", func() {
+			rec := serve(handler, accessTokenExpiringIn(15*time.Minute))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(rec.Header().Get(TokenRefreshHeader)).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("rejects a token that expired before the grace period", func() {
+			rec := serve(handler, accessTokenExpiringIn(-10*time.Minute))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+
+		ginkgo.It("rejects any expired token without a grace period", func() {
+			strict := NewHandler(service, 0)
+			strict.Logger = handler.Logger
+			rec := serve(strict, accessTokenExpiringIn(-time.Minute))
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(rec.Header().Get(TokenRefreshHeader)).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Describe("Token", func() {
+		post := func(contentType, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/token", strings.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			handler.Token(rec, req)
+
+			var resp map[string]interface{}
+			gomega.Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(gomega.Succeed())
+			return rec, resp
+		}
+
+		refreshToken := func() string {
+			token, err := tokenGen.GenerateRefreshToken("2", "admin@example.com", 7)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			return token
+		}
+
+		ginkgo.It("exchanges a refresh token sent as a form", func() {
+			form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken()}}
+			rec, resp := post("application/x-www-form-urlencoded", form.Encode())
+
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(rec.Header().Get("Cache-Control")).To(gomega.Equal("no-store"))
+			gomega.Expect(resp["token_type"]).To(gomega.Equal("Bearer"))
+			gomega.Expect(resp["expires_in"]).To(gomega.BeNumerically("~", 900, 1))
+			gomega.Expect(resp["access_token"]).NotTo(gomega.BeEmpty())
+			gomega.Expect(resp["refresh_token"]).NotTo(gomega.BeEmpty())
+
+			claims, err := tokenGen.ValidateAccessToken(resp["access_token"].(string))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(claims.TenantID).To(gomega.Equal(int64(7)))
+		})
+
+		ginkgo.It("exchanges a refresh token sent as JSON", func() {
+			rec, resp := post("application/json", `{"grant_type":"refresh_token","refresh_token":"`+refreshToken()+`"}`)
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(resp["token_type"]).To(gomega.Equal("Bearer"))
+		})
+
+		ginkgo.It("refuses other grants", func() {
+			rec, resp := post("application/x-www-form-urlencoded", "grant_type=password&username=a&password=b")
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusBadRequest))
+			gomega.Expect(resp["error"]).To(gomega.Equal("unsupported_grant_type"))
+		})
+
+		ginkgo.It("requires the refresh token", func() {
+			rec, resp := post("application/x-www-form-urlencoded", "grant_type=refresh_token")
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusBadRequest))
+			gomega.Expect(resp["error"]).To(gomega.Equal("invalid_request"))
+		})
+
+		ginkgo.It("answers invalid_grant for a bad refresh token", func() {
+			rec, resp := post("application/x-www-form-urlencoded", "grant_type=refresh_token&refresh_token=not.a.token")
+			gomega.Expect(rec.Code).To(gomega.Equal(http.StatusBadRequest))
+			gomega.Expect(resp["error"]).To(gomega.Equal("invalid_grant"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -9,28 +10,40 @@ import (
 )
 
 type ServiceAPI interface {
-	Authenticate(dto LoginDTO) (AuthTokens, error)
+	Authenticate(ctx context.Context, dto LoginDTO) (AuthTokens, error)
 	RefreshTokens(refreshToken string) (AuthTokens, error)
 	ValidateAccessToken(tokenString string) (*Claims, error)
+	ValidateAccessTokenWithin(tokenString string, grace time.Duration) (*Claims, error)
+	ExchangeRefreshToken(refreshToken string) (OAuthToken, error)
 	GetUserWithPermissions(userID int64) (*User, error)
+	TokenUser(claims *Claims) (*User, error)
 	HashPassword(password string) (string, error)
 }
 
 type RepositoryAPI interface {
+	// GetPasswordForUsername and GetUserWithPermissions find inactive users
+	// too, and return ErrUserNotFound for unknown ones.
 	GetPasswordForUsername(username string) (passwordHash string, userID string, err error)
 	GetUserWithPermissions(userID int64) (*User, error)
 }
 
 type TokenGeneratorAPI interface {
-	GenerateAccessToken(userID string, email string) (token string, err error)
-	GenerateRefreshToken(userID string, email string) (token string, err error)
-	ValidateToken(tokenString string) (*Claims, error)
+	GenerateAccessToken(userID string, email string, tenantID int64) (token string, err error)
+	GenerateRefreshToken(userID string, email string, tenantID int64) (token string, err error)
+	ValidateAccessToken(tokenString string) (*Claims, error)
+	ValidateAccessTokenWithin(tokenString string, grace time.Duration) (*Claims, error)
+	ValidateRefreshToken(tokenString string) (*Claims, error)
 }
 
 type User struct {
 	ID          int64    `json:"id"`
+	TenantID    int64    `json:"tenant_id"`
 	Email       string   `json:"email"`
 	Permissions []string `json:"permissions,omitempty"`
+	IsActive    bool     `json:"-"`
+	// SessionsRevokedAt is when the user's sessions were last revoked;
+	// tokens issued before it are no longer accepted.
+	SessionsRevokedAt *time.Time `json:"-"`
 }
 
 func (u *User) HasPermission(permission string) bool {
@@ -54,12 +67,11 @@ func (u *User) HasAnyPermission(permissions []string) bool {
 }
 
 func (u *User) IsManager() bool {
-	managerPerms := []string{"approve_expenses", "reject_expenses", "admin"}
-	return u.HasAnyPermission(managerPerms)
+	return hasAny(u.Permissions, PermApproveExpenses, PermRejectExpenses, PermAdmin)
 }
 
 func (u *User) IsAdmin() bool {
-	return u.HasPermission("admin")
+	return hasAny(u.Permissions, PermAdmin)
 }
 
 type AuthInfo struct {
@@ -89,9 +101,38 @@ type AuthTokens struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// OAuthToken is an OAuth2 access token response (RFC 6749 section 5.1).
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// OAuthError is an OAuth2 token endpoint error (RFC 6749 section 5.2).
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// TokenType is the token_type claim; a token is only accepted where its
+// type is expected.
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
+	// TenantID is zero in tokens issued before multi-tenancy.
+	TenantID  int64     `json:"tenant_id,omitempty"`
+	TokenType TokenType `json:"token_type"`
+	// ImpersonatorID is set in tokens an administrator was issued to act
+	// as the user; see ImpersonationService.
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -100,6 +141,13 @@ type JWTTokenGenerator struct {
 	RefreshTokenSecret []byte
 	AccessTokenTTL     time.Duration
 	RefreshTokenTTL    time.Duration
+	// Issuer and Audience are written into every token and, when set,
+	// required of every token validated.
+	Issuer   string
+	Audience string
+	// ClockSkew is how far expiry and not-before may be off between
+	// servers.
+	ClockSkew time.Duration
 }
 
 var (
@@ -107,6 +155,11 @@ var (
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrTokenExpired       = errors.New("token expired")
 	ErrUserInactive       = errors.New("user is inactive")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrSessionRevoked     = errors.New("session revoked")
+	ErrImpersonationEnded = errors.New("impersonation ended")
+	ErrCaptchaRequired    = errors.New("captcha required")
+	ErrCaptchaInvalid     = errors.New("invalid captcha")
 )
 
 func (a AuthInfo) ToV1() AuthResponseV1 {
@@ -0,0 +1,117 @@
+package bankaccount
+
+import (
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	accountDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/bankaccount"
+)
+
+const (
+	StatusUnverified = "unverified"
+	StatusVerified   = "verified"
+	StatusRejected   = "rejected"
+)
+
+func init() {
+	validation.RegisterRule("account_number", func(fv *validation.FieldValidator, _ string) {
+		fv.Custom(func(v interface{}) *errors.AppError {
+			s, _ := v.(string)
+			if s == "" || strings.Trim(s, "0123456789") != "" {
+				return errors.NewValidationFieldError(fv.FieldName, "account_number must contain digits only", errors.ErrCodeValidationFailed)
+			}
+			return nil
+		})
+	})
+}
+
+// Account is a user's bank account as shown to clients. The full account
+// number never leaves the service; only its last four digits do.
+type Account struct {
+	ID                  int64      `json:"id"`
+	UserID              int64      `json:"user_id"`
+	BankCode            string     `json:"bank_code"`
+	AccountHolder       string     `json:"account_holder"`
+	AccountNumberMasked string     `json:"account_number_masked"`
+	VerificationStatus  string     `json:"verification_status"`
+	VerificationNote    *string    `json:"verification_note,omitempty"`
+	VerifiedAt          *time.Time `json:"verified_at,omitempty"`
+	IsDefault           bool       `json:"is_default"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+type CreateAccountDTO struct {
+	BankCode      string `json:"bank_code" validate:"required,max=20"`
+	AccountNumber string `json:"account_number" validate:"required,min=6,max=20,account_number"`
+	AccountHolder string `json:"account_holder" validate:"required,max=100"`
+	// MakeDefault selects the account for payouts; a user's first account is
+	// always the default.
+	MakeDefault bool `json:"make_default"`
+}
+
+func (dto CreateAccountDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+type VerifyAccountDTO struct {
+	Status string `json:"status" validate:"required,oneof=verified rejected"`
+	Note   string `json:"note" validate:"max=500"`
+}
+
+func (dto VerifyAccountDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+type AccountsResponse struct {
+	Accounts []*Account `json:"accounts"`
+}
+
+var (
+	ErrAccountNotFound    = errors.NewNotFoundError("Bank account not found", errors.ErrCodeBankAccountNotFound)
+	ErrAccountRejected    = errors.NewValidationError("Bank account was rejected and cannot receive payouts", errors.ErrCodePayoutAccountUnverified)
+	ErrAccountNotVerified = errors.NewValidationError("Payout bank account has not been verified", errors.ErrCodePayoutAccountUnverified)
+	ErrNoPayoutAccount    = errors.NewValidationError("No payout bank account on file", errors.ErrCodeBankAccountNotFound)
+	ErrUserNotFound       = errors.NewNotFoundError("User not found", errors.ErrCodeUserNotFound)
+)
+
+func mask(last4 string) string {
+	return "****" + last4
+}
+
+func lastFour(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return number[len(number)-4:]
+}
+
+func fromDataModel(a *accountDatamodel.Account) *Account {
+	return &Account{
+		ID:                  a.ID,
+		UserID:              a.UserID,
+		BankCode:            a.BankCode,
+		AccountHolder:       a.AccountHolder,
+		AccountNumberMasked: mask(a.AccountNumberLast4),
+		VerificationStatus:  a.VerificationStatus,
+		VerificationNote:    a.VerificationNote,
+		VerifiedAt:          a.VerifiedAt,
+		IsDefault:           a.IsDefault,
+		CreatedAt:           a.CreatedAt,
+	}
+}
+
+func fromDataModelSlice(rows []*accountDatamodel.Account) []*Account {
+	accounts := make([]*Account, len(rows))
+	for i, row := range rows {
+		accounts[i] = fromDataModel(row)
+	}
+	return accounts
+}
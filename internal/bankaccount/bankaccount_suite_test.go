@@ -0,0 +1,13 @@
+package bankaccount_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBankAccount(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bank Account Suite")
+}
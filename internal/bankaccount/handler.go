@@ -0,0 +1,227 @@
+package bankaccount
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	List(ctx context.Context, userID int64) ([]*Account, error)
+	ListForUser(ctx context.Context, userID int64) ([]*Account, error)
+	Create(ctx context.Context, userID int64, dto CreateAccountDTO) (*Account, error)
+	SetDefault(ctx context.Context, userID, accountID int64) (*Account, error)
+	Delete(ctx context.Context, userID, accountID int64) error
+	Verify(ctx context.Context, accountID, adminID int64, dto VerifyAccountDTO) (*Account, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListAccounts godoc
+// @Summary      List my bank accounts
+// @Description  Account numbers are masked to their last four digits.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  AccountsResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /users/me/bank-accounts [get]
+func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	accounts, err := h.Service.List(r.Context(), user.ID)
+	if err != nil {
+		h.Log(r).Error("ListAccounts: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list bank accounts")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, AccountsResponse{Accounts: accounts})
+}
+
+// CreateAccount godoc
+// @Summary      Add a bank account for reimbursements
+// @Description  New accounts are unverified; an admin must verify an account before it is paid into. The user's first account becomes the default.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      CreateAccountDTO  true  "Bank account"
+// @Success      201   {object}  Account
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /users/me/bank-accounts [post]
+func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto CreateAccountDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	account, err := h.Service.Create(r.Context(), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, account)
+}
+
+// SetDefaultAccount godoc
+// @Summary      Make a bank account the default for payouts
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Bank account ID"
+// @Success      200  {object}  Account
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /users/me/bank-accounts/{id}/default [put]
+func (h *Handler) SetDefaultAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid bank account ID")
+		return
+	}
+
+	account, err := h.Service.SetDefault(r.Context(), user.ID, accountID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, account)
+}
+
+// DeleteAccount godoc
+// @Summary      Remove a bank account
+// @Description  Expenses that picked the account are paid into the default account instead.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Bank account ID"
+// @Success      204
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /users/me/bank-accounts/{id} [delete]
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid bank account ID")
+		return
+	}
+
+	if err := h.Service.Delete(r.Context(), user.ID, accountID); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUserAccounts godoc
+// @Summary      List a user's bank accounts
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  AccountsResponse
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/users/{id}/bank-accounts [get]
+func (h *Handler) ListUserAccounts(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	accounts, err := h.Service.ListForUser(r.Context(), userID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, AccountsResponse{Accounts: accounts})
+}
+
+// VerifyAccount godoc
+// @Summary      Verify or reject a bank account
+// @Description  Only verified accounts are paid into. The verifying admin and time are recorded.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int               true  "Bank account ID"
+// @Param        body  body      VerifyAccountDTO  true  "Verification decision"
+// @Success      200   {object}  Account
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /admin/bank-accounts/{id}/verification [put]
+func (h *Handler) VerifyAccount(w http.ResponseWriter, r *http.Request) {
+	admin, ok := internal.UserFromContext(r.Context())
+	if !ok || admin == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid bank account ID")
+		return
+	}
+
+	var dto VerifyAccountDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	account, err := h.Service.Verify(r.Context(), accountID, admin.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, account)
+}
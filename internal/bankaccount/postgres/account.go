@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/bankaccount"
+	accountDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/bankaccount"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"gorm.io/gorm"
+)
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) bankaccount.RepositoryAPI {
+	return &AccountRepository{db: db}
+}
+
+func (r *AccountRepository) Create(account *accountDatamodel.Account) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if account.IsDefault {
+			if err := clearDefault(tx, account.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(account).Error
+	})
+}
+
+func (r *AccountRepository) GetByID(id int64) (*accountDatamodel.Account, error) {
+	var account accountDatamodel.Account
+	err := r.db.Where("id = ? AND deleted_at IS NULL", id).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AccountRepository) ListByUser(userID int64) ([]*accountDatamodel.Account, error) {
+	var accounts []*accountDatamodel.Account
+	err := r.db.Where("user_id = ? AND deleted_at IS NULL", userID).
+		Order("is_default DESC, id ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *AccountRepository) SetDefault(userID, id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefault(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&accountDatamodel.Account{}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).
+			Update("is_default", true).Error
+	})
+}
+
+func (r *AccountRepository) SoftDelete(id int64, at time.Time) error {
+	return r.db.Model(&accountDatamodel.Account{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": at,
+			"is_default": false,
+		}).Error
+}
+
+func (r *AccountRepository) UpdateVerification(id int64, status string, note *string, verifiedBy int64, at time.Time) error {
+	return r.db.Model(&accountDatamodel.Account{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"verification_status": status,
+			"verification_note":   note,
+			"verified_by":         verifiedBy,
+			"verified_at":         at,
+		}).Error
+}
+
+func (r *AccountRepository) GetPayoutForExpense(expenseID int64) (*accountDatamodel.Account, error) {
+	var exp expenseDatamodel.Expense
+	err := r.db.Select("id", "user_id", "payout_account_id").Where("id = ?", expenseID).First(&exp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.PayoutAccountID != nil {
+		account, err := r.GetByID(*exp.PayoutAccountID)
+		if err != nil || account != nil {
+			return account, err
+		}
+	}
+
+	var account accountDatamodel.Account
+	err = r.db.Where("user_id = ? AND is_default AND deleted_at IS NULL", exp.UserID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AccountRepository) UserExists(userID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&userDatamodel.User{}).Where("id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
+
+func clearDefault(tx *gorm.DB, userID int64) error {
+	return tx.Model(&accountDatamodel.Account{}).
+		Where("user_id = ? AND is_default", userID).
+		Update("is_default", false).Error
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/bankaccount"
+	accountDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/bankaccount"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	"github.com/frahmantamala/expense-management/internal/core/encryption"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestAccountRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AccountRepository Suite")
+}
+
+var _ = Describe("AccountRepository", func() {
+	var (
+		db   *gorm.DB
+		repo bankaccount.RepositoryAPI
+		now  time.Time
+	)
+
+	create := func(userID int64, isDefault bool) *accountDatamodel.Account {
+		account := &accountDatamodel.Account{
+			UserID:             userID,
+			BankCode:           "BCA",
+			AccountHolder:      "Jane Doe",
+			AccountNumber:      "1234567890",
+			AccountNumberLast4: "7890",
+			VerificationStatus: bankaccount.StatusVerified,
+			IsDefault:          isDefault,
+		}
+		Expect(repo.Create(account)).To(Succeed())
+		return account
+	}
+
+	createExpense := func(userID int64, payoutAccountID *int64) int64 {
+		exp := &expenseDatamodel.Expense{
+			UserID:          userID,
+			AmountIDR:       50000,
+			Description:     "Taxi",
+			ExpenseDate:     now,
+			SubmittedAt:     now,
+			PayoutAccountID: payoutAccountID,
+		}
+		Expect(db.Create(exp).Error).To(Succeed())
+		return exp.ID
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = testdb.Open(&accountDatamodel.Account{}, &expenseDatamodel.Expense{})
+		Expect(err).NotTo(HaveOccurred())
+
+		keyring, err := encryption.NewKeyring(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")))
+		Expect(err).NotTo(HaveOccurred())
+		encryption.Use(keyring)
+
+		repo = NewAccountRepository(db)
+		now = time.Date(2025, 10, 15, 9, 0, 0, 0, time.UTC)
+	})
+
+	It("keeps a single default account per user", func() {
+		first := create(1, true)
+		second := create(1, true)
+		other := create(2, true)
+
+		accounts, err := repo.ListByUser(1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accounts).To(HaveLen(2))
+		Expect(accounts[0].ID).To(Equal(second.ID))
+		Expect(accounts[0].IsDefault).To(BeTrue())
+		Expect(accounts[1].IsDefault).To(BeFalse())
+
+		Expect(repo.SetDefault(1, first.ID)).To(Succeed())
+
+		reloaded, err := repo.GetByID(second.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded.IsDefault).To(BeFalse())
+		untouched, err := repo.GetByID(other.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(untouched.IsDefault).To(BeTrue())
+	})
+
+	It("stores account numbers encrypted", func() {
+		account := create(1, true)
+
+		var stored string
+		Expect(db.Raw("SELECT account_number_encrypted FROM user_bank_accounts WHERE id = ?", account.ID).Scan(&stored).Error).To(Succeed())
+		Expect(stored).To(HavePrefix("enc:v1:"))
+		Expect(stored).NotTo(ContainSubstring("1234567890"))
+
+		found, err := repo.GetByID(account.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.AccountNumber).To(Equal("1234567890"))
+	})
+
+	It("hides deleted accounts", func() {
+		account := create(1, true)
+
+		Expect(repo.SoftDelete(account.ID, now)).To(Succeed())
+
+		found, err := repo.GetByID(account.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeNil())
+		accounts, err := repo.ListByUser(1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accounts).To(BeEmpty())
+	})
+
+	Describe("GetPayoutForExpense", func() {
+		It("uses the account picked on the expense", func() {
+			create(1, true)
+			picked := create(1, false)
+			expenseID := createExpense(1, &picked.ID)
+
+			account, err := repo.GetPayoutForExpense(expenseID)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(account.ID).To(Equal(picked.ID))
+		})
+
+		It("falls back to the default account when the picked one was deleted", func() {
+			fallback := create(1, true)
+			picked := create(1, false)
+			expenseID := createExpense(1, &picked.ID)
+			Expect(repo.SoftDelete(picked.ID, now)).To(Succeed())
+
+			account, err := repo.GetPayoutForExpense(expenseID)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(account.ID).To(Equal(fallback.ID))
+		})
+
+		It("returns nil when the owner has no default account", func() {
+			create(1, false)
+			expenseID := createExpense(1, nil)
+
+			account, err := repo.GetPayoutForExpense(expenseID)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(account).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,214 @@
+package bankaccount
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	accountDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/bankaccount"
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type RepositoryAPI interface {
+	// Create stores account, clearing the user's other default first when
+	// account is the default.
+	Create(account *accountDatamodel.Account) error
+	// GetByID returns nil when the account does not exist or was deleted.
+	GetByID(id int64) (*accountDatamodel.Account, error)
+	ListByUser(userID int64) ([]*accountDatamodel.Account, error)
+	SetDefault(userID, id int64) error
+	SoftDelete(id int64, at time.Time) error
+	UpdateVerification(id int64, status string, note *string, verifiedBy int64, at time.Time) error
+	// GetPayoutForExpense returns the account picked on the expense, or its
+	// owner's default account when none was picked or the picked one was
+	// deleted. It returns nil when neither exists.
+	GetPayoutForExpense(expenseID int64) (*accountDatamodel.Account, error)
+	UserExists(userID int64) (bool, error)
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService needs an installed encryption keyring; account numbers are
+// sealed by the datamodel's serializer.
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]*Account, error) {
+	rows, err := s.repo.ListByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+	return fromDataModelSlice(rows), nil
+}
+
+// ListForUser is the admin view of another user's accounts.
+func (s *Service) ListForUser(ctx context.Context, userID int64) ([]*Account, error) {
+	exists, err := s.repo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	return s.List(ctx, userID)
+}
+
+// Create adds an unverified account. Changing bank details means adding a
+// new account, so every set of details an admin verified stays as verified.
+func (s *Service) Create(ctx context.Context, userID int64, dto CreateAccountDTO) (*Account, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+
+	row := &accountDatamodel.Account{
+		UserID:             userID,
+		BankCode:           dto.BankCode,
+		AccountHolder:      dto.AccountHolder,
+		AccountNumber:      dto.AccountNumber,
+		AccountNumberLast4: lastFour(dto.AccountNumber),
+		VerificationStatus: StatusUnverified,
+		IsDefault:          dto.MakeDefault || len(existing) == 0,
+	}
+	if err := s.repo.Create(row); err != nil {
+		return nil, fmt.Errorf("failed to save bank account: %w", err)
+	}
+
+	s.log(ctx).Info("bank account added",
+		"account_id", row.ID,
+		"user_id", userID,
+		"bank_code", row.BankCode,
+		"is_default", row.IsDefault)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) SetDefault(ctx context.Context, userID, accountID int64) (*Account, error) {
+	row, err := s.owned(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetDefault(userID, accountID); err != nil {
+		return nil, fmt.Errorf("failed to set default bank account: %w", err)
+	}
+
+	row.IsDefault = true
+	s.log(ctx).Info("default bank account changed", "account_id", accountID, "user_id", userID)
+	return fromDataModel(row), nil
+}
+
+// Delete hides the account. Expenses that picked it fall back to the user's
+// default account at payout time.
+func (s *Service) Delete(ctx context.Context, userID, accountID int64) error {
+	if _, err := s.owned(userID, accountID); err != nil {
+		return err
+	}
+	if err := s.repo.SoftDelete(accountID, s.now()); err != nil {
+		return fmt.Errorf("failed to delete bank account: %w", err)
+	}
+
+	s.log(ctx).Info("bank account deleted", "account_id", accountID, "user_id", userID)
+	return nil
+}
+
+// Verify records an admin's check of the account details.
+func (s *Service) Verify(ctx context.Context, accountID, adminID int64, dto VerifyAccountDTO) (*Account, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	row, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank account: %w", err)
+	}
+	if row == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	var note *string
+	if dto.Note != "" {
+		note = &dto.Note
+	}
+	now := s.now()
+	if err := s.repo.UpdateVerification(accountID, dto.Status, note, adminID, now); err != nil {
+		return nil, fmt.Errorf("failed to update bank account verification: %w", err)
+	}
+
+	row.VerificationStatus = dto.Status
+	row.VerificationNote = note
+	row.VerifiedBy = &adminID
+	row.VerifiedAt = &now
+
+	s.log(ctx).Info("bank account verification recorded",
+		"account_id", accountID,
+		"user_id", row.UserID,
+		"status", dto.Status,
+		"verified_by", adminID)
+	return fromDataModel(row), nil
+}
+
+// CheckPayoutAccount reports whether userID may pick accountID to be paid
+// into. Unverified accounts are accepted here since verification can still
+// happen before the expense is paid.
+func (s *Service) CheckPayoutAccount(ctx context.Context, userID, accountID int64) error {
+	row, err := s.owned(userID, accountID)
+	if err != nil {
+		return err
+	}
+	if row.VerificationStatus == StatusRejected {
+		s.log(ctx).Warn("rejected bank account picked for payout", "account_id", accountID, "user_id", userID)
+		return ErrAccountRejected
+	}
+	return nil
+}
+
+// PayoutForExpense returns the payout details for expenseID. Only verified
+// accounts are paid into.
+func (s *Service) PayoutForExpense(expenseID int64) (*paymentgatewaytypes.Payout, error) {
+	row, err := s.repo.GetPayoutForExpense(expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payout account: %w", err)
+	}
+	if row == nil {
+		return nil, ErrNoPayoutAccount
+	}
+	if row.VerificationStatus != StatusVerified {
+		return nil, ErrAccountNotVerified
+	}
+
+	return &paymentgatewaytypes.Payout{
+		BankCode:      row.BankCode,
+		AccountNumber: row.AccountNumber,
+		AccountHolder: row.AccountHolder,
+	}, nil
+}
+
+// owned hides other users' accounts behind the same not found error.
+func (s *Service) owned(userID, accountID int64) (*accountDatamodel.Account, error) {
+	row, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank account: %w", err)
+	}
+	if row == nil || row.UserID != userID {
+		return nil, ErrAccountNotFound
+	}
+	return row, nil
+}
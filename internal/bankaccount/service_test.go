@@ -0,0 +1,182 @@
+package bankaccount_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/bankaccount"
+	accountDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/bankaccount"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeRepository struct {
+	accounts map[int64]*accountDatamodel.Account
+	payouts  map[int64]int64
+	nextID   int64
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{accounts: map[int64]*accountDatamodel.Account{}, payouts: map[int64]int64{}, nextID: 1}
+}
+
+func (f *fakeRepository) Create(account *accountDatamodel.Account) error {
+	if account.IsDefault {
+		for _, a := range f.accounts {
+			if a.UserID == account.UserID {
+				a.IsDefault = false
+			}
+		}
+	}
+	account.ID = f.nextID
+	f.nextID++
+	f.accounts[account.ID] = account
+	return nil
+}
+
+func (f *fakeRepository) GetByID(id int64) (*accountDatamodel.Account, error) {
+	a, ok := f.accounts[id]
+	if !ok || a.DeletedAt != nil {
+		return nil, nil
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (f *fakeRepository) ListByUser(userID int64) ([]*accountDatamodel.Account, error) {
+	var out []*accountDatamodel.Account
+	for _, a := range f.accounts {
+		if a.UserID == userID && a.DeletedAt == nil {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) SetDefault(userID, id int64) error {
+	for _, a := range f.accounts {
+		if a.UserID == userID {
+			a.IsDefault = a.ID == id
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) SoftDelete(id int64, at time.Time) error {
+	f.accounts[id].DeletedAt = &at
+	f.accounts[id].IsDefault = false
+	return nil
+}
+
+func (f *fakeRepository) UpdateVerification(id int64, status string, note *string, verifiedBy int64, at time.Time) error {
+	a := f.accounts[id]
+	a.VerificationStatus = status
+	a.VerificationNote = note
+	a.VerifiedBy = &verifiedBy
+	a.VerifiedAt = &at
+	return nil
+}
+
+func (f *fakeRepository) GetPayoutForExpense(expenseID int64) (*accountDatamodel.Account, error) {
+	id, ok := f.payouts[expenseID]
+	if !ok {
+		return nil, nil
+	}
+	return f.GetByID(id)
+}
+
+func (f *fakeRepository) UserExists(userID int64) (bool, error) {
+	return userID == 1 || userID == 2, nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		repo *fakeRepository
+		svc  *bankaccount.Service
+		ctx  context.Context
+		dto  bankaccount.CreateAccountDTO
+	)
+
+	BeforeEach(func() {
+		repo = newFakeRepository()
+		svc = bankaccount.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		ctx = context.Background()
+		dto = bankaccount.CreateAccountDTO{BankCode: "BCA", AccountNumber: "1234567890", AccountHolder: "Jane Doe"}
+	})
+
+	Describe("Create", func() {
+		It("shows only the last four digits", func() {
+			account, err := svc.Create(ctx, 1, dto)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(account.AccountNumberMasked).To(Equal("****7890"))
+			Expect(account.VerificationStatus).To(Equal(bankaccount.StatusUnverified))
+			Expect(account.IsDefault).To(BeTrue())
+			Expect(repo.accounts[account.ID].AccountNumber).To(Equal("1234567890"))
+		})
+
+		It("keeps the existing default unless asked otherwise", func() {
+			first, _ := svc.Create(ctx, 1, dto)
+
+			second, err := svc.Create(ctx, 1, dto)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second.IsDefault).To(BeFalse())
+			Expect(repo.accounts[first.ID].IsDefault).To(BeTrue())
+		})
+
+		It("rejects account numbers with anything but digits", func() {
+			dto.AccountNumber = "1234-5678"
+
+			_, err := svc.Create(ctx, 1, dto)
+
+			Expect(err).To(MatchError(ContainSubstring("digits only")))
+			Expect(repo.accounts).To(BeEmpty())
+		})
+	})
+
+	It("hides other users' accounts", func() {
+		account, _ := svc.Create(ctx, 1, dto)
+
+		_, err := svc.SetDefault(ctx, 2, account.ID)
+		Expect(err).To(MatchError(bankaccount.ErrAccountNotFound))
+		Expect(svc.Delete(ctx, 2, account.ID)).To(MatchError(bankaccount.ErrAccountNotFound))
+		Expect(svc.CheckPayoutAccount(ctx, 2, account.ID)).To(MatchError(bankaccount.ErrAccountNotFound))
+	})
+
+	It("does not accept rejected accounts for payouts", func() {
+		account, _ := svc.Create(ctx, 1, dto)
+		_, err := svc.Verify(ctx, account.ID, 9, bankaccount.VerifyAccountDTO{Status: bankaccount.StatusRejected, Note: "name mismatch"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(svc.CheckPayoutAccount(ctx, 1, account.ID)).To(MatchError(bankaccount.ErrAccountRejected))
+	})
+
+	Describe("PayoutForExpense", func() {
+		It("pays only into verified accounts", func() {
+			account, _ := svc.Create(ctx, 1, dto)
+			repo.payouts[42] = account.ID
+
+			_, err := svc.PayoutForExpense(42)
+			Expect(err).To(MatchError(bankaccount.ErrAccountNotVerified))
+
+			verified, err := svc.Verify(ctx, account.ID, 9, bankaccount.VerifyAccountDTO{Status: bankaccount.StatusVerified})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verified.VerifiedAt).NotTo(BeNil())
+
+			payout, err := svc.PayoutForExpense(42)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payout.BankCode).To(Equal("BCA"))
+			Expect(payout.AccountNumber).To(Equal("1234567890"))
+			Expect(payout.AccountHolder).To(Equal("Jane Doe"))
+		})
+
+		It("reports a missing payout account", func() {
+			_, err := svc.PayoutForExpense(42)
+
+			Expect(err).To(MatchError(bankaccount.ErrNoPayoutAccount))
+		})
+	})
+})
@@ -0,0 +1,210 @@
+// Package calendar tells working days from days off: the weekend, Indonesia's
+// national holidays and each tenant's own holidays. It measures working time,
+// for deadlines such as approval SLAs, and counts working days for per-diem
+// claims.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	// Embedded so the time zone loads in images without zoneinfo.
+	_ "time/tzdata"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	calendarDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/calendar"
+)
+
+// DateLayout formats a calendar day.
+const DateLayout = time.DateOnly
+
+// Kinds of tenant overrides.
+const (
+	// KindHoliday makes a day off of a day that would be worked.
+	KindHoliday = "holiday"
+	// KindWorkingDay makes a working day of a weekend day or national
+	// holiday.
+	KindWorkingDay = "working_day"
+)
+
+// Sources of holidays.
+const (
+	SourceNational = "national"
+	SourceTenant   = "tenant"
+)
+
+// MaxRangeDays bounds the days counted by one WorkingDays call.
+const MaxRangeDays = 366
+
+// Holiday is a day off other than the weekend.
+type Holiday struct {
+	Date   string `json:"date" example:"2025-08-17"`
+	Name   string `json:"name"`
+	Source string `json:"source" example:"national"`
+}
+
+// Day is a tenant's override of one day.
+type Day struct {
+	Date      string    `json:"date" example:"2025-12-24"`
+	Kind      string    `json:"kind" example:"holiday"`
+	Name      string    `json:"name,omitempty"`
+	SetBy     int64     `json:"set_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SetDayDTO struct {
+	Kind string `json:"kind" validate:"required,oneof=holiday working_day"`
+	Name string `json:"name" validate:"max=200"`
+}
+
+func (dto SetDayDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// CalendarResponse is a tenant's working calendar for one year.
+type CalendarResponse struct {
+	Year     int      `json:"year"`
+	TimeZone string   `json:"time_zone" example:"Asia/Jakarta"`
+	Weekend  []string `json:"weekend" example:"saturday,sunday"`
+	// Holidays lists the days off other than the weekend, national ones
+	// the tenant works on left out.
+	Holidays []*Holiday `json:"holidays"`
+	// Overrides lists the tenant's own days, holidays and working days.
+	Overrides []*Day `json:"overrides"`
+}
+
+// WorkingDaysResponse counts the working days from From to To, both
+// included.
+type WorkingDaysResponse struct {
+	From        string `json:"from" example:"2025-08-11"`
+	To          string `json:"to" example:"2025-08-22"`
+	Days        int    `json:"days"`
+	WorkingDays int    `json:"working_days"`
+}
+
+var (
+	ErrDayNotFound  = errors.NewNotFoundError("Day has no calendar override", errors.ErrCodeCalendarDayNotFound)
+	ErrInvalidDate  = errors.NewValidationFieldError("date", "date must be formatted as YYYY-MM-DD", errors.ErrCodeInvalidDate)
+	ErrInvalidYear  = errors.NewValidationFieldError("year", "year must be between 2000 and 2100", errors.ErrCodeValidationFailed)
+	ErrInvalidFrom  = errors.NewValidationFieldError("from", "from must be formatted as YYYY-MM-DD", errors.ErrCodeInvalidDate)
+	ErrInvalidRange = errors.NewValidationFieldError("to", fmt.Sprintf("to must be formatted as YYYY-MM-DD, on or after from and at most %d days later", MaxRangeDays-1), errors.ErrCodeInvalidDate)
+)
+
+// ParseDate parses date (YYYY-MM-DD) as a UTC day.
+func ParseDate(date string) (time.Time, error) {
+	day, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return day, nil
+}
+
+// ParseWeekday parses an English day name such as "saturday".
+func ParseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", name)
+}
+
+// Calendar knows which days are worked. Instants are placed on days in its
+// time zone.
+type Calendar struct {
+	loc     *time.Location
+	weekend [7]bool
+	// holidays and working are keyed by date.
+	holidays map[string]bool
+	working  map[string]bool
+}
+
+// New returns a calendar in which the weekend days and holidays are off,
+// except the workingDays (YYYY-MM-DD), which are worked whatever else they
+// are.
+func New(loc *time.Location, weekend []time.Weekday, holidays []*Holiday, workingDays []string) *Calendar {
+	c := &Calendar{
+		loc:      loc,
+		holidays: make(map[string]bool, len(holidays)),
+		working:  make(map[string]bool, len(workingDays)),
+	}
+	for _, d := range weekend {
+		c.weekend[d] = true
+	}
+	for _, h := range holidays {
+		c.holidays[h.Date] = true
+	}
+	for _, date := range workingDays {
+		c.working[date] = true
+	}
+	return c
+}
+
+// Location is the time zone days are placed in.
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// IsWorkingDay reports whether the day t falls on is worked.
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	return c.worked(t.In(c.loc))
+}
+
+// worked looks t up by its own date, whatever its zone.
+func (c *Calendar) worked(t time.Time) bool {
+	date := t.Format(DateLayout)
+	if c.working[date] {
+		return true
+	}
+	return !c.weekend[t.Weekday()] && !c.holidays[date]
+}
+
+// WorkingTime is how much of [from, to) falls on working days, zero when to
+// is not after from.
+func (c *Calendar) WorkingTime(from, to time.Time) time.Duration {
+	var total time.Duration
+	for start := from.In(c.loc); start.Before(to); {
+		y, m, d := start.Date()
+		end := time.Date(y, m, d+1, 0, 0, 0, 0, c.loc)
+		if c.worked(start) {
+			if to.Before(end) {
+				total += to.Sub(start)
+			} else {
+				total += end.Sub(start)
+			}
+		}
+		start = end
+	}
+	return total
+}
+
+// WorkingDays counts the working days from the day from to the day to,
+// both included. Both are days as ParseDate returns them.
+func (c *Calendar) WorkingDays(from, to time.Time) int {
+	n := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if c.worked(day) {
+			n++
+		}
+	}
+	return n
+}
+
+func fromDataModel(d *calendarDatamodel.Day) *Day {
+	return &Day{
+		Date:      d.Day.Format(DateLayout),
+		Kind:      d.Kind,
+		Name:      d.Name,
+		SetBy:     d.SetBy,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+func sortHolidays(holidays []*Holiday) {
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+}
@@ -0,0 +1,68 @@
+package calendar_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/calendar"
+)
+
+var _ = Describe("Calendar", func() {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	weekend := []time.Weekday{time.Saturday, time.Sunday}
+	day := func(date string) time.Time {
+		d, err := calendar.ParseDate(date)
+		Expect(err).NotTo(HaveOccurred())
+		return d
+	}
+
+	It("places instants on days in its time zone", func() {
+		cal := calendar.New(jakarta, weekend, nil, nil)
+		// 18:00 UTC on Friday is already Saturday in Jakarta.
+		Expect(cal.IsWorkingDay(time.Date(2025, 10, 24, 16, 0, 0, 0, time.UTC))).To(BeTrue())
+		Expect(cal.IsWorkingDay(time.Date(2025, 10, 24, 18, 0, 0, 0, time.UTC))).To(BeFalse())
+	})
+
+	It("works on working days even on the weekend or a holiday", func() {
+		holidays := []*calendar.Holiday{{Date: "2025-08-18", Name: "Independence Day"}}
+		cal := calendar.New(time.UTC, weekend, holidays, []string{"2025-08-16"})
+		Expect(cal.WorkingDays(day("2025-08-15"), day("2025-08-19"))).To(Equal(3))
+	})
+
+	It("measures only the time on working days", func() {
+		holidays := []*calendar.Holiday{{Date: "2025-10-27"}}
+		cal := calendar.New(jakarta, weekend, holidays, nil)
+		// Friday 20:00 to Tuesday 10:00 in Jakarta: four hours on Friday
+		// and ten on Tuesday.
+		from := time.Date(2025, 10, 24, 20, 0, 0, 0, jakarta)
+		to := time.Date(2025, 10, 28, 10, 0, 0, 0, jakarta)
+		Expect(cal.WorkingTime(from, to)).To(Equal(14 * time.Hour))
+		Expect(cal.WorkingTime(to, from)).To(BeZero())
+		Expect(cal.WorkingTime(from, from.Add(time.Hour))).To(Equal(time.Hour))
+	})
+
+	It("lists the national holidays of a year by date", func() {
+		holidays := calendar.NationalHolidays(2025)
+		Expect(holidays).To(HaveLen(17))
+		Expect(holidays[0]).To(Equal(&calendar.Holiday{Date: "2025-01-01", Name: "New Year's Day", Source: calendar.SourceNational}))
+		Expect(holidays).To(ContainElement(&calendar.Holiday{Date: "2025-08-17", Name: "Independence Day", Source: calendar.SourceNational}))
+	})
+
+	It("keeps the fixed-date holidays of years without a decree", func() {
+		var dates []string
+		for _, h := range calendar.NationalHolidays(2040) {
+			dates = append(dates, h.Date)
+		}
+		Expect(dates).To(Equal([]string{"2040-01-01", "2040-05-01", "2040-06-01", "2040-08-17", "2040-12-25"}))
+	})
+
+	It("parses weekday names", func() {
+		d, err := calendar.ParseWeekday("Friday")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(time.Friday))
+		_, err = calendar.ParseWeekday("weekend")
+		Expect(err).To(HaveOccurred())
+	})
+})
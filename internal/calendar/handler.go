@@ -0,0 +1,140 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	GetCalendar(ctx context.Context, year string, now time.Time) (*CalendarResponse, error)
+	SetDay(ctx context.Context, date string, setBy int64, dto SetDayDTO) (*Day, error)
+	DeleteDay(ctx context.Context, date string, deletedBy int64) error
+	WorkingDays(ctx context.Context, from, to string) (*WorkingDaysResponse, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// GetCalendar godoc
+// @Summary      Show the working calendar of a year
+// @Description  The weekend, and the holidays of the year: Indonesia's national holidays, less those the tenant works on, and the tenant's own. Working days are counted on this calendar. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        year  query     int  false  "Year; the current one when omitted"
+// @Success      200   {object}  CalendarResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Router       /admin/calendar [get]
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	result, err := h.Service.GetCalendar(r.Context(), r.URL.Query().Get("year"), time.Now())
+	if err != nil {
+		h.Log(r).Error("GetCalendar: service error", "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
+
+// SetDay godoc
+// @Summary      Make a day a holiday or a working day
+// @Description  kind holiday adds a day off, such as collective leave or a company holiday; working_day makes a weekend day or national holiday worked. Replaces the day's previous override. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        date  path      string     true  "Day, YYYY-MM-DD"
+// @Param        body  body      SetDayDTO  true  "Override"
+// @Success      200   {object}  Day
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /admin/calendar/days/{date} [put]
+func (h *Handler) SetDay(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto SetDayDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	day, err := h.Service.SetDay(r.Context(), chi.URLParam(r, "date"), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, day)
+}
+
+// DeleteDay godoc
+// @Summary      Remove a day's override
+// @Description  The day is then worked or not as the weekend and national holidays say. Admin only.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        date  path  string  true  "Day, YYYY-MM-DD"
+// @Success      204
+// @Failure      400  {object}  transport.AppErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/calendar/days/{date} [delete]
+func (h *Handler) DeleteDay(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.Service.DeleteDay(r.Context(), chi.URLParam(r, "date"), user.ID); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WorkingDays godoc
+// @Summary      Count working days
+// @Description  How many of the days from from to to, both included, are worked in the caller's tenant, such as the days of a trip a per-diem is paid for. At most 366 days.
+// @Tags         calendar
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  true  "First day, YYYY-MM-DD"
+// @Param        to    query     string  true  "Last day, YYYY-MM-DD"
+// @Success      200   {object}  WorkingDaysResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Router       /calendar/working-days [get]
+func (h *Handler) WorkingDays(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	result, err := h.Service.WorkingDays(r.Context(), query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.Log(r).Error("WorkingDays: service error", "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,75 @@
+package calendar
+
+import "strconv"
+
+// nationalHolidays are Indonesia's public holidays (hari libur nasional) as
+// set by the joint ministerial decree for each year. Collective leave (cuti
+// bersama) is not included; tenants that give it add it as their own
+// holidays. Years not listed here only get the fixed-date holidays, so each
+// new decree has to be added.
+var nationalHolidays = map[string]string{
+	"2025-01-01": "New Year's Day",
+	"2025-01-27": "Isra Mi'raj",
+	"2025-01-29": "Chinese New Year",
+	"2025-03-29": "Nyepi",
+	"2025-03-31": "Eid al-Fitr",
+	"2025-04-01": "Eid al-Fitr",
+	"2025-04-18": "Good Friday",
+	"2025-04-20": "Easter Sunday",
+	"2025-05-01": "Labour Day",
+	"2025-05-12": "Vesak",
+	"2025-05-29": "Ascension Day",
+	"2025-06-01": "Pancasila Day",
+	"2025-06-06": "Eid al-Adha",
+	"2025-06-27": "Islamic New Year",
+	"2025-08-17": "Independence Day",
+	"2025-09-05": "Prophet Muhammad's Birthday",
+	"2025-12-25": "Christmas Day",
+
+	"2026-01-01": "New Year's Day",
+	"2026-01-16": "Isra Mi'raj",
+	"2026-02-17": "Chinese New Year",
+	"2026-03-19": "Nyepi",
+	"2026-03-21": "Eid al-Fitr",
+	"2026-03-22": "Eid al-Fitr",
+	"2026-04-03": "Good Friday",
+	"2026-04-05": "Easter Sunday",
+	"2026-05-01": "Labour Day",
+	"2026-05-14": "Ascension Day",
+	"2026-05-27": "Eid al-Adha",
+	"2026-05-31": "Vesak",
+	"2026-06-01": "Pancasila Day",
+	"2026-06-16": "Islamic New Year",
+	"2026-08-17": "Independence Day",
+	"2026-08-25": "Prophet Muhammad's Birthday",
+	"2026-12-25": "Christmas Day",
+}
+
+// fixedHolidays fall on the same date every year, by MM-DD.
+var fixedHolidays = map[string]string{
+	"01-01": "New Year's Day",
+	"05-01": "Labour Day",
+	"06-01": "Pancasila Day",
+	"08-17": "Independence Day",
+	"12-25": "Christmas Day",
+}
+
+// NationalHolidays lists Indonesia's public holidays in year by date.
+func NationalHolidays(year int) []*Holiday {
+	prefix := strconv.Itoa(year) + "-"
+	seen := make(map[string]bool)
+	var holidays []*Holiday
+	for date, name := range nationalHolidays {
+		if date[:5] == prefix {
+			holidays = append(holidays, &Holiday{Date: date, Name: name, Source: SourceNational})
+			seen[date] = true
+		}
+	}
+	for monthDay, name := range fixedHolidays {
+		if date := prefix + monthDay; !seen[date] {
+			holidays = append(holidays, &Holiday{Date: date, Name: name, Source: SourceNational})
+		}
+	}
+	sortHolidays(holidays)
+	return holidays
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/calendar"
+	calendarDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/calendar"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DayRepository struct {
+	db *gorm.DB
+}
+
+func NewDayRepository(db *gorm.DB) calendar.RepositoryAPI {
+	return &DayRepository{db: db}
+}
+
+// Days are compared as YYYY-MM-DD so the DATE column is not shifted by the
+// session time zone.
+func (r *DayRepository) List(ctx context.Context, from, to time.Time) ([]*calendarDatamodel.Day, error) {
+	var days []*calendarDatamodel.Day
+	err := r.db.WithContext(ctx).
+		Where("day >= ? AND day <= ?", from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order("day").
+		Find(&days).Error
+	return days, err
+}
+
+func (r *DayRepository) Upsert(ctx context.Context, day *calendarDatamodel.Day) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"kind", "name", "set_by", "updated_at"}),
+	}).Create(day).Error
+}
+
+func (r *DayRepository) Delete(ctx context.Context, day time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Where("day = ?", day.Format(time.DateOnly)).Delete(&calendarDatamodel.Day{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,217 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	calendarDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/calendar"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the overrides of the tenant ctx is scoped
+// to.
+type RepositoryAPI interface {
+	// List returns the overrides dated from from to to, both included, by
+	// date.
+	List(ctx context.Context, from, to time.Time) ([]*calendarDatamodel.Day, error)
+	// Upsert replaces the override of the same day.
+	Upsert(ctx context.Context, day *calendarDatamodel.Day) error
+	Delete(ctx context.Context, day time.Time) (bool, error)
+}
+
+// Service builds each tenant's working calendar from the server's working
+// week, the national holidays and the tenant's overrides.
+type Service struct {
+	repo    RepositoryAPI
+	loc     *time.Location
+	weekend []time.Weekday
+	// national counts the national holidays as days off.
+	national bool
+	logger   *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, loc *time.Location, weekend []time.Weekday, national bool, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		loc:      loc,
+		weekend:  weekend,
+		national: national,
+		logger:   logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Calendar returns the working calendar of the tenant ctx is scoped to,
+// complete for the days from from to to.
+func (s *Service) Calendar(ctx context.Context, from, to time.Time) (*Calendar, error) {
+	return s.calendar(ctx, day(from.In(s.loc)), day(to.In(s.loc)))
+}
+
+// calendar is Calendar for the days from first to last, as ParseDate
+// returns them.
+func (s *Service) calendar(ctx context.Context, first, last time.Time) (*Calendar, error) {
+	holidays, overrides, err := s.holidays(ctx, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	var working []string
+	for _, o := range overrides {
+		if o.Kind == KindWorkingDay {
+			working = append(working, o.Date)
+		}
+	}
+	return New(s.loc, s.weekend, holidays, working), nil
+}
+
+// holidays returns the days off other than the weekend from from to to,
+// and the tenant's overrides in that range.
+func (s *Service) holidays(ctx context.Context, from, to time.Time) ([]*Holiday, []*Day, error) {
+	rows, err := s.repo.List(ctx, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list calendar days: %w", err)
+	}
+	overrides := make([]*Day, len(rows))
+	overridden := make(map[string]bool, len(rows))
+	for i, row := range rows {
+		overrides[i] = fromDataModel(row)
+		overridden[overrides[i].Date] = true
+	}
+
+	var holidays []*Holiday
+	if s.national {
+		first, last := from.Format(DateLayout), to.Format(DateLayout)
+		for year := from.Year(); year <= to.Year(); year++ {
+			for _, h := range NationalHolidays(year) {
+				if h.Date >= first && h.Date <= last && !overridden[h.Date] {
+					holidays = append(holidays, h)
+				}
+			}
+		}
+	}
+	for _, o := range overrides {
+		if o.Kind == KindHoliday {
+			holidays = append(holidays, &Holiday{Date: o.Date, Name: o.Name, Source: SourceTenant})
+		}
+	}
+	sortHolidays(holidays)
+	return holidays, overrides, nil
+}
+
+// GetCalendar lists the holidays and overrides of year (YYYY), the current
+// year when empty.
+func (s *Service) GetCalendar(ctx context.Context, year string, now time.Time) (*CalendarResponse, error) {
+	y := now.In(s.loc).Year()
+	if year != "" {
+		var err error
+		if y, err = strconv.Atoi(year); err != nil || y < 2000 || y > 2100 {
+			return nil, ErrInvalidYear
+		}
+	}
+
+	from := time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+	holidays, overrides, err := s.holidays(ctx, from, from.AddDate(1, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	weekend := make([]string, len(s.weekend))
+	for i, d := range s.weekend {
+		weekend[i] = strings.ToLower(d.String())
+	}
+	if holidays == nil {
+		holidays = []*Holiday{}
+	}
+	return &CalendarResponse{
+		Year:      y,
+		TimeZone:  s.loc.String(),
+		Weekend:   weekend,
+		Holidays:  holidays,
+		Overrides: overrides,
+	}, nil
+}
+
+// SetDay makes date (YYYY-MM-DD) a holiday or a working day for the tenant,
+// replacing its previous override.
+func (s *Service) SetDay(ctx context.Context, date string, setBy int64, dto SetDayDTO) (*Day, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	d, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &calendarDatamodel.Day{
+		Day:   d,
+		Kind:  dto.Kind,
+		Name:  strings.TrimSpace(dto.Name),
+		SetBy: setBy,
+	}
+	if err := s.repo.Upsert(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to set calendar day: %w", err)
+	}
+
+	s.log(ctx).Info("calendar day set", "date", date, "kind", dto.Kind, "set_by", setBy)
+	return fromDataModel(row), nil
+}
+
+// DeleteDay removes the tenant's override of date, which is then worked or
+// not as the weekend and national holidays say.
+func (s *Service) DeleteDay(ctx context.Context, date string, deletedBy int64) error {
+	d, err := ParseDate(date)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, d)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar day: %w", err)
+	}
+	if !deleted {
+		return ErrDayNotFound
+	}
+
+	s.log(ctx).Info("calendar day deleted", "date", date, "deleted_by", deletedBy)
+	return nil
+}
+
+// WorkingDays counts the tenant's working days from from to to (YYYY-MM-DD),
+// both included, such as the days of a trip a per-diem is paid for.
+func (s *Service) WorkingDays(ctx context.Context, from, to string) (*WorkingDaysResponse, error) {
+	first, err := time.Parse(DateLayout, from)
+	if err != nil {
+		return nil, ErrInvalidFrom
+	}
+	last, err := time.Parse(DateLayout, to)
+	if err != nil {
+		return nil, ErrInvalidRange
+	}
+	days := int(last.Sub(first)/(24*time.Hour)) + 1
+	if days < 1 || days > MaxRangeDays {
+		return nil, ErrInvalidRange
+	}
+
+	cal, err := s.calendar(ctx, first, last)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkingDaysResponse{
+		From:        first.Format(DateLayout),
+		To:          last.Format(DateLayout),
+		Days:        days,
+		WorkingDays: cal.WorkingDays(first, last),
+	}, nil
+}
+
+// day returns midnight UTC of the date t shows.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,123 @@
+package calendar_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/calendar"
+	calendarDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/calendar"
+)
+
+type mockRepository struct {
+	days map[time.Time]*calendarDatamodel.Day
+}
+
+func (m *mockRepository) List(_ context.Context, from, to time.Time) ([]*calendarDatamodel.Day, error) {
+	var out []*calendarDatamodel.Day
+	for d, day := range m.days {
+		if !d.Before(from) && !d.After(to) {
+			out = append(out, day)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day.Before(out[j].Day) })
+	return out, nil
+}
+
+func (m *mockRepository) Upsert(_ context.Context, day *calendarDatamodel.Day) error {
+	day.UpdatedAt = time.Now()
+	m.days[day.Day] = day
+	return nil
+}
+
+func (m *mockRepository) Delete(_ context.Context, day time.Time) (bool, error) {
+	_, ok := m.days[day]
+	delete(m.days, day)
+	return ok, nil
+}
+
+var _ = Describe("Calendar service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *calendar.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{days: map[time.Time]*calendarDatamodel.Day{}}
+		jakarta, err := time.LoadLocation("Asia/Jakarta")
+		Expect(err).NotTo(HaveOccurred())
+		service = calendar.NewService(repo, jakarta, []time.Weekday{time.Saturday, time.Sunday}, true, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("adds the tenant's holidays to the national ones and drops those it works on", func() {
+		_, err := service.SetDay(ctx, "2025-12-26", 1, calendar.SetDayDTO{Kind: calendar.KindHoliday, Name: " Boxing Day "})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.SetDay(ctx, "2025-05-01", 1, calendar.SetDayDTO{Kind: calendar.KindWorkingDay})
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := service.GetCalendar(ctx, "2025", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TimeZone).To(Equal("Asia/Jakarta"))
+		Expect(result.Weekend).To(Equal([]string{"saturday", "sunday"}))
+		Expect(result.Holidays).To(HaveLen(17))
+		Expect(result.Holidays).NotTo(ContainElement(HaveField("Date", "2025-05-01")))
+		Expect(result.Holidays[16]).To(Equal(&calendar.Holiday{Date: "2025-12-26", Name: "Boxing Day", Source: calendar.SourceTenant}))
+		Expect(result.Overrides).To(HaveLen(2))
+	})
+
+	It("defaults to the current year", func() {
+		result, err := service.GetCalendar(ctx, "", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Year).To(Equal(2026))
+
+		_, err = service.GetCalendar(ctx, "26", time.Now())
+		Expect(err).To(Equal(calendar.ErrInvalidYear))
+	})
+
+	It("counts working days from the weekend, national holidays and overrides", func() {
+		// Two weeks around Independence Day, a Sunday in 2025.
+		result, err := service.WorkingDays(ctx, "2025-08-11", "2025-08-24")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Days).To(Equal(14))
+		Expect(result.WorkingDays).To(Equal(10))
+
+		_, err = service.SetDay(ctx, "2025-08-18", 1, calendar.SetDayDTO{Kind: calendar.KindHoliday, Name: "Collective leave"})
+		Expect(err).NotTo(HaveOccurred())
+		result, err = service.WorkingDays(ctx, "2025-08-11", "2025-08-24")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.WorkingDays).To(Equal(9))
+	})
+
+	It("refuses ranges that are backwards or longer than a year", func() {
+		_, err := service.WorkingDays(ctx, "2025-08-11", "2025-08-10")
+		Expect(err).To(Equal(calendar.ErrInvalidRange))
+		_, err = service.WorkingDays(ctx, "2025-01-01", "2026-01-02")
+		Expect(err).To(Equal(calendar.ErrInvalidRange))
+		_, err = service.WorkingDays(ctx, "11-08-2025", "2025-08-24")
+		Expect(err).To(Equal(calendar.ErrInvalidFrom))
+	})
+
+	It("rejects overrides of an unknown kind", func() {
+		_, err := service.SetDay(ctx, "2025-12-26", 1, calendar.SetDayDTO{Kind: "half_day"})
+		var appErr *errors.AppError
+		Expect(err).To(BeAssignableToTypeOf(appErr))
+		Expect(repo.days).To(BeEmpty())
+	})
+
+	It("reports deleting a day without an override as not found", func() {
+		_, err := service.SetDay(ctx, "2025-12-26", 1, calendar.SetDayDTO{Kind: calendar.KindHoliday})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.DeleteDay(ctx, "2025-12-26", 1)).To(Succeed())
+		Expect(service.DeleteDay(ctx, "2025-12-26", 1)).To(Equal(calendar.ErrDayNotFound))
+		Expect(service.DeleteDay(ctx, "26/12/2025", 1)).To(Equal(calendar.ErrInvalidDate))
+	})
+})
@@ -0,0 +1,67 @@
+package cannedresponse
+
+import (
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	responseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cannedresponse"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+var ErrResponseNotFound = errors.NewNotFoundError("Canned response not found", errors.ErrCodeCannedResponseNotFound)
+
+// Response is a stock rejection comment a tenant's approvers pick instead of
+// typing one, so the same reason reads the same way on every expense and
+// can be counted.
+type Response struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ResponseDTO creates a canned response or replaces one. Code is the
+// rejection code expenses rejected with it are filed under.
+type ResponseDTO struct {
+	Code  string `json:"code" validate:"required" enums:"policy_violation,missing_receipt,duplicate,other"`
+	Title string `json:"title" validate:"required,max=100"`
+	Body  string `json:"body" validate:"required,max=1000"`
+}
+
+func (dto ResponseDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if !expense.IsRejectionCode(dto.Code) {
+		return errors.NewValidationFieldError("code", "code must be one of "+strings.Join(expense.RejectionCodes, ", "), errors.ErrCodeValidationFailed)
+	}
+	return nil
+}
+
+type ResponsesResponse struct {
+	Responses []*Response `json:"canned_responses"`
+}
+
+// RejectionReasonsResponse is what an approver picks from when rejecting:
+// the rejection codes and the tenant's canned responses.
+type RejectionReasonsResponse struct {
+	Codes     []string    `json:"codes"`
+	Responses []*Response `json:"canned_responses"`
+}
+
+func fromDataModel(r *responseDatamodel.Response) *Response {
+	return &Response{
+		ID:        r.ID,
+		Code:      r.Code,
+		Title:     r.Title,
+		Body:      r.Body,
+		CreatedBy: r.CreatedBy,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package cannedresponse_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCannedResponse(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Canned Response Suite")
+}
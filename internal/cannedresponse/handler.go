@@ -0,0 +1,176 @@
+package cannedresponse
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	List(ctx context.Context) ([]*Response, error)
+	RejectionReasons(ctx context.Context) (*RejectionReasonsResponse, error)
+	Create(ctx context.Context, createdBy int64, dto ResponseDTO) (*Response, error)
+	Update(ctx context.Context, id int64, dto ResponseDTO) (*Response, error)
+	Archive(ctx context.Context, id int64) error
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListRejectionReasons godoc
+// @Summary      List rejection reasons
+// @Description  The rejection codes and the tenant's canned responses, for approvers to pick from when rejecting. Requires reject_expenses or admin.
+// @Tags         expenses
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  RejectionReasonsResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /expenses/rejection-reasons [get]
+func (h *Handler) ListRejectionReasons(w http.ResponseWriter, r *http.Request) {
+	reasons, err := h.Service.RejectionReasons(r.Context())
+	if err != nil {
+		h.Log(r).Error("ListRejectionReasons: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list rejection reasons")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, reasons)
+}
+
+// ListResponses godoc
+// @Summary      List canned responses
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  ResponsesResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/canned-responses [get]
+func (h *Handler) ListResponses(w http.ResponseWriter, r *http.Request) {
+	responses, err := h.Service.List(r.Context())
+	if err != nil {
+		h.Log(r).Error("ListResponses: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list canned responses")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, ResponsesResponse{Responses: responses})
+}
+
+// CreateResponse godoc
+// @Summary      Create a canned response
+// @Description  Approvers reject with it by passing canned_response_id to PATCH /expenses/{id}/reject.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      ResponseDTO  true  "Canned response"
+// @Success      201   {object}  Response
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Router       /admin/canned-responses [post]
+func (h *Handler) CreateResponse(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto ResponseDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	response, err := h.Service.Create(r.Context(), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, response)
+}
+
+// UpdateResponse godoc
+// @Summary      Replace a canned response
+// @Description  Expenses already rejected with it keep the comment they were rejected with.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int          true  "Canned response ID"
+// @Param        body  body      ResponseDTO  true  "Canned response"
+// @Success      200   {object}  Response
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /admin/canned-responses/{id} [put]
+func (h *Handler) UpdateResponse(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.responseID(w, r)
+	if !ok {
+		return
+	}
+
+	var dto ResponseDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	response, err := h.Service.Update(r.Context(), id, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, response)
+}
+
+// ArchiveResponse godoc
+// @Summary      Archive a canned response
+// @Description  Approvers can no longer pick it; expenses rejected with it keep its ID.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Canned response ID"
+// @Success      204
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/canned-responses/{id} [delete]
+func (h *Handler) ArchiveResponse(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.responseID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Archive(r.Context(), id); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) responseID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid canned response ID")
+		return 0, false
+	}
+	return id, true
+}
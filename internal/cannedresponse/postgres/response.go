@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/frahmantamala/expense-management/internal/cannedresponse"
+	responseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cannedresponse"
+	"gorm.io/gorm"
+)
+
+type ResponseRepository struct {
+	db *gorm.DB
+}
+
+func NewResponseRepository(db *gorm.DB) cannedresponse.RepositoryAPI {
+	return &ResponseRepository{db: db}
+}
+
+func (r *ResponseRepository) List(ctx context.Context) ([]*responseDatamodel.Response, error) {
+	var responses []*responseDatamodel.Response
+	err := r.db.WithContext(ctx).Where("archived_at IS NULL").Order("code, title, id").Find(&responses).Error
+	return responses, err
+}
+
+func (r *ResponseRepository) Get(ctx context.Context, id int64) (*responseDatamodel.Response, error) {
+	var response responseDatamodel.Response
+	err := r.db.WithContext(ctx).First(&response, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (r *ResponseRepository) Create(ctx context.Context, response *responseDatamodel.Response) error {
+	return r.db.WithContext(ctx).Create(response).Error
+}
+
+func (r *ResponseRepository) Update(ctx context.Context, response *responseDatamodel.Response) error {
+	return r.db.WithContext(ctx).Save(response).Error
+}
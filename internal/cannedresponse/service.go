@@ -0,0 +1,147 @@
+package cannedresponse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	responseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cannedresponse"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the canned responses of the tenant ctx is
+// scoped to.
+type RepositoryAPI interface {
+	// List returns the responses that are not archived.
+	List(ctx context.Context) ([]*responseDatamodel.Response, error)
+	// Get returns nil when there is no such response; archived ones are
+	// returned.
+	Get(ctx context.Context, id int64) (*responseDatamodel.Response, error)
+	Create(ctx context.Context, r *responseDatamodel.Response) error
+	Update(ctx context.Context, r *responseDatamodel.Response) error
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) List(ctx context.Context) ([]*Response, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+
+	responses := make([]*Response, len(rows))
+	for i, row := range rows {
+		responses[i] = fromDataModel(row)
+	}
+	return responses, nil
+}
+
+// RejectionReasons returns the rejection codes with the canned responses
+// approvers can pick from.
+func (s *Service) RejectionReasons(ctx context.Context) (*RejectionReasonsResponse, error) {
+	responses, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &RejectionReasonsResponse{Codes: expense.RejectionCodes, Responses: responses}, nil
+}
+
+func (s *Service) Create(ctx context.Context, createdBy int64, dto ResponseDTO) (*Response, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	row := &responseDatamodel.Response{CreatedBy: createdBy}
+	apply(row, dto)
+	if err := s.repo.Create(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to create canned response: %w", err)
+	}
+
+	s.log(ctx).Info("canned response created", "response_id", row.ID, "code", row.Code, "created_by", createdBy)
+	return fromDataModel(row), nil
+}
+
+// Update replaces a response. Expenses already rejected with it keep the
+// comment they were rejected with.
+func (s *Service) Update(ctx context.Context, id int64, dto ResponseDTO) (*Response, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	row, err := s.active(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	apply(row, dto)
+	if err := s.repo.Update(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to update canned response: %w", err)
+	}
+
+	s.log(ctx).Info("canned response updated", "response_id", id, "code", row.Code)
+	return fromDataModel(row), nil
+}
+
+// Archive hides a response from approvers. It is kept so the expenses
+// rejected with it still report by it.
+func (s *Service) Archive(ctx context.Context, id int64) error {
+	row, err := s.active(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	row.ArchivedAt = &now
+	if err := s.repo.Update(ctx, row); err != nil {
+		return fmt.Errorf("failed to archive canned response: %w", err)
+	}
+
+	s.log(ctx).Info("canned response archived", "response_id", id)
+	return nil
+}
+
+// CannedResponse returns a response approvers can still reject with.
+func (s *Service) CannedResponse(ctx context.Context, id int64) (*expense.CannedResponse, error) {
+	row, err := s.active(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &expense.CannedResponse{ID: row.ID, Code: row.Code, Body: row.Body}, nil
+}
+
+// active returns the response unless it is missing or archived; both are
+// reported as not found.
+func (s *Service) active(ctx context.Context, id int64) (*responseDatamodel.Response, error) {
+	row, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load canned response: %w", err)
+	}
+	if row == nil || row.ArchivedAt != nil {
+		return nil, ErrResponseNotFound
+	}
+	return row, nil
+}
+
+func apply(row *responseDatamodel.Response, dto ResponseDTO) {
+	row.Code = dto.Code
+	row.Title = dto.Title
+	row.Body = dto.Body
+}
@@ -0,0 +1,116 @@
+package cannedresponse_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/cannedresponse"
+	responseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cannedresponse"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+type mockRepository struct {
+	responses map[int64]*responseDatamodel.Response
+	nextID    int64
+}
+
+func (m *mockRepository) List(_ context.Context) ([]*responseDatamodel.Response, error) {
+	var out []*responseDatamodel.Response
+	for _, r := range m.responses {
+		if r.ArchivedAt == nil {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (m *mockRepository) Get(_ context.Context, id int64) (*responseDatamodel.Response, error) {
+	return m.responses[id], nil
+}
+
+func (m *mockRepository) Create(_ context.Context, r *responseDatamodel.Response) error {
+	m.nextID++
+	r.ID = m.nextID
+	m.responses[r.ID] = r
+	return nil
+}
+
+func (m *mockRepository) Update(_ context.Context, r *responseDatamodel.Response) error {
+	m.responses[r.ID] = r
+	return nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *cannedresponse.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{responses: map[int64]*responseDatamodel.Response{}}
+		service = cannedresponse.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	missingReceipt := cannedresponse.ResponseDTO{
+		Code:  expense.RejectionMissingReceipt,
+		Title: "Itemised receipt",
+		Body:  "Please attach an itemised receipt.",
+	}
+
+	It("returns responses alongside the rejection codes", func() {
+		created, err := service.Create(ctx, 9, missingReceipt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.CreatedBy).To(Equal(int64(9)))
+
+		reasons, err := service.RejectionReasons(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reasons.Codes).To(Equal(expense.RejectionCodes))
+		Expect(reasons.Responses).To(HaveLen(1))
+		Expect(reasons.Responses[0].Title).To(Equal("Itemised receipt"))
+	})
+
+	It("hands the expense service the code and body to reject with", func() {
+		created, err := service.Create(ctx, 9, missingReceipt)
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := service.CannedResponse(ctx, created.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal(&expense.CannedResponse{ID: created.ID, Code: expense.RejectionMissingReceipt, Body: "Please attach an itemised receipt."}))
+	})
+
+	It("keeps archived responses but stops offering them", func() {
+		created, err := service.Create(ctx, 9, missingReceipt)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.Archive(ctx, created.ID)).To(Succeed())
+		Expect(repo.responses).To(HaveKey(created.ID))
+
+		listed, err := service.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listed).To(BeEmpty())
+
+		_, err = service.CannedResponse(ctx, created.ID)
+		Expect(err).To(MatchError(cannedresponse.ErrResponseNotFound))
+		_, err = service.Update(ctx, created.ID, missingReceipt)
+		Expect(err).To(MatchError(cannedresponse.ErrResponseNotFound))
+	})
+
+	It("refuses codes that are not rejection codes", func() {
+		dto := missingReceipt
+		dto.Code = "too_expensive"
+
+		_, err := service.Create(ctx, 9, dto)
+		_, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(repo.responses).To(BeEmpty())
+	})
+})
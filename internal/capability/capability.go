@@ -0,0 +1,149 @@
+// Package capability issues short-lived tokens that grant one action on one
+// resource, such as downloading export 123 or viewing the receipt of expense
+// 456. They go into links people can share without handing over their
+// access token.
+package capability
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+// Scope is the single action a token allows.
+type Scope string
+
+const (
+	ScopeExportDownload Scope = "export:download"
+	ScopeReceiptView    Scope = "receipt:view"
+)
+
+// Claims is the signed payload of a token. Subject is the user who shared
+// the link.
+type Claims struct {
+	Scope      Scope `json:"scp"`
+	ResourceID int64 `json:"rid"`
+	Subject    int64 `json:"sub"`
+	TenantID   int64 `json:"tid,omitempty"`
+	ExpiresAt  int64 `json:"exp"`
+}
+
+// Link is a shareable URL and when it stops working.
+type Link struct {
+	URL       string    `json:"url"`
+	Scope     Scope     `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+var (
+	ErrInvalidToken = errors.NewUnauthorizedError("Invalid link", errors.ErrCodeInvalidToken)
+	ErrTokenExpired = errors.NewUnauthorizedError("Link has expired", errors.ErrCodeTokenExpired)
+	ErrWrongScope   = errors.NewForbiddenError("Link does not grant access to this resource", errors.ErrCodeUnauthorizedAccess)
+)
+
+// tokenDomain keeps capability signatures apart from other tokens signed
+// with the same key.
+const tokenDomain = "capability\n"
+
+// Issuer signs tokens as base64url(JSON) + "." + base64url(HMAC-SHA256) and
+// checks them again.
+type Issuer struct {
+	key     []byte
+	baseURL string
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+func NewIssuer(key, baseURL string, ttl time.Duration) *Issuer {
+	return &Issuer{
+		key:     []byte(key),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Issue signs a token granting scope on resourceID on behalf of subject. It
+// is bound to the tenant ctx is scoped to.
+func (i *Issuer) Issue(ctx context.Context, scope Scope, resourceID, subject int64) (string, time.Time, error) {
+	expiresAt := i.now().Add(i.ttl).Truncate(time.Second)
+	c := Claims{
+		Scope:      scope,
+		ResourceID: resourceID,
+		Subject:    subject,
+		ExpiresAt:  expiresAt.Unix(),
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		c.TenantID = tenantID
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + i.mac(body), expiresAt.UTC(), nil
+}
+
+// Link issues a token and appends it to path, which is relative to the
+// server's base URL.
+func (i *Issuer) Link(ctx context.Context, scope Scope, resourceID, subject int64, path string) (*Link, error) {
+	token, expiresAt, err := i.Issue(ctx, scope, resourceID, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &Link{
+		URL:       i.baseURL + path + "?token=" + url.QueryEscape(token),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Parse verifies the signature and expiry of token.
+func (i *Issuer) Parse(token string) (Claims, error) {
+	var c Claims
+
+	body, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(i.mac(body))) {
+		return c, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return c, ErrInvalidToken
+	}
+	if err := json.Unmarshal(payload, &c); err != nil || c.Scope == "" || c.ResourceID == 0 {
+		return c, ErrInvalidToken
+	}
+	if !i.now().Before(time.Unix(c.ExpiresAt, 0)) {
+		return c, ErrTokenExpired
+	}
+	return c, nil
+}
+
+func (i *Issuer) mac(body string) string {
+	mac := hmac.New(sha256.New, i.key)
+	mac.Write([]byte(tokenDomain + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type ctxKey struct{}
+
+// NewContext stores the claims of the token a request was let in with.
+func NewContext(ctx context.Context, c Claims) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// FromContext returns the claims stored by Middleware.
+func FromContext(ctx context.Context) (Claims, bool) {
+	c, ok := ctx.Value(ctxKey{}).(Claims)
+	return c, ok
+}
@@ -0,0 +1,129 @@
+package capability_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+var _ = Describe("Issuer", func() {
+	var (
+		ctx    context.Context
+		issuer *capability.Issuer
+	)
+
+	BeforeEach(func() {
+		ctx = tenant.NewContext(context.Background(), 3)
+		issuer = capability.NewIssuer("capability-secret", "http://localhost:8080/", 15*time.Minute)
+	})
+
+	It("round-trips the scope, resource, subject and tenant", func() {
+		token, expiresAt, err := issuer.Issue(ctx, capability.ScopeExportDownload, 123, 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expiresAt).To(BeTemporally("~", time.Now().Add(15*time.Minute), 2*time.Second))
+
+		claims, err := issuer.Parse(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims.Scope).To(Equal(capability.ScopeExportDownload))
+		Expect(claims.ResourceID).To(Equal(int64(123)))
+		Expect(claims.Subject).To(Equal(int64(7)))
+		Expect(claims.TenantID).To(Equal(int64(3)))
+	})
+
+	It("builds links on the base URL", func() {
+		link, err := issuer.Link(ctx, capability.ScopeReceiptView, 456, 7, "/api/v1/shared/receipts/456")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.URL).To(HavePrefix("http://localhost:8080/api/v1/shared/receipts/456?token="))
+		Expect(link.Scope).To(Equal(capability.ScopeReceiptView))
+	})
+
+	It("rejects tampered tokens and tokens signed with another key", func() {
+		token, _, err := issuer.Issue(ctx, capability.ScopeReceiptView, 456, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		body, signature, _ := strings.Cut(token, ".")
+		_, err = issuer.Parse(body + "x." + signature)
+		Expect(err).To(Equal(capability.ErrInvalidToken))
+
+		other := capability.NewIssuer("another-secret", "http://localhost:8080", 15*time.Minute)
+		_, err = other.Parse(token)
+		Expect(err).To(Equal(capability.ErrInvalidToken))
+
+		_, err = issuer.Parse("not-a-token")
+		Expect(err).To(Equal(capability.ErrInvalidToken))
+	})
+
+	It("rejects expired tokens", func() {
+		expired := capability.NewIssuer("capability-secret", "http://localhost:8080", -time.Minute)
+		token, _, err := expired.Issue(ctx, capability.ScopeReceiptView, 456, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = issuer.Parse(token)
+		Expect(err).To(Equal(capability.ErrTokenExpired))
+	})
+})
+
+var _ = Describe("Middleware", func() {
+	var (
+		issuer *capability.Issuer
+		router *chi.Mux
+		seen   capability.Claims
+	)
+
+	BeforeEach(func() {
+		issuer = capability.NewIssuer("capability-secret", "http://localhost:8080", 15*time.Minute)
+		m := capability.NewMiddleware(transport.NewBaseHandler(slog.New(slog.NewTextHandler(io.Discard, nil))), issuer)
+
+		seen = capability.Claims{}
+		router = chi.NewRouter()
+		router.With(m.Require(capability.ScopeReceiptView, "id")).Get("/shared/receipts/{id}", func(w http.ResponseWriter, r *http.Request) {
+			seen, _ = capability.FromContext(r.Context())
+			tenantID, _ := tenant.FromContext(r.Context())
+			Expect(tenantID).To(Equal(seen.TenantID))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path+"?token="+url.QueryEscape(token), nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	issue := func(scope capability.Scope, resourceID int64) string {
+		token, _, err := issuer.Issue(tenant.NewContext(context.Background(), 3), scope, resourceID, 7)
+		Expect(err).NotTo(HaveOccurred())
+		return token
+	}
+
+	It("lets in a token for the requested resource", func() {
+		rec := get("/shared/receipts/456", issue(capability.ScopeReceiptView, 456))
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("no-store"))
+		Expect(seen.Subject).To(Equal(int64(7)))
+	})
+
+	It("refuses tokens for another resource or scope", func() {
+		Expect(get("/shared/receipts/457", issue(capability.ScopeReceiptView, 456)).Code).To(Equal(http.StatusForbidden))
+		Expect(get("/shared/receipts/456", issue(capability.ScopeExportDownload, 456)).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("refuses missing and invalid tokens", func() {
+		Expect(get("/shared/receipts/456", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(get("/shared/receipts/456", "forged.token").Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -0,0 +1,69 @@
+package capability
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/go-chi/chi"
+)
+
+// Middleware lets in requests carrying a capability token in the token
+// query parameter instead of a bearer token.
+type Middleware struct {
+	*transport.BaseHandler
+	issuer *Issuer
+}
+
+func NewMiddleware(baseHandler *transport.BaseHandler, issuer *Issuer) *Middleware {
+	return &Middleware{BaseHandler: baseHandler, issuer: issuer}
+}
+
+// Require refuses requests whose token does not grant scope on the resource
+// named by the param URL parameter. Let-in requests are scoped to the
+// token's tenant and carry its claims.
+func (m *Middleware) Require(scope Scope, param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The token is a credential; keep it out of caches and Referer headers.
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("Referrer-Policy", "no-referrer")
+
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				m.WriteError(w, r, http.StatusUnauthorized, "token is required")
+				return
+			}
+
+			claims, err := m.issuer.Parse(token)
+			if err != nil {
+				m.Log(r).Warn("capability token refused", "error", err, "scope", scope)
+				m.HandleError(w, r, err)
+				return
+			}
+
+			resourceID, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+			if err != nil || claims.Scope != scope || claims.ResourceID != resourceID {
+				m.Log(r).Warn("capability token used outside its scope",
+					"scope", scope, "token_scope", claims.Scope,
+					"resource_id", chi.URLParam(r, param), "token_resource_id", claims.ResourceID)
+				m.HandleError(w, r, ErrWrongScope)
+				return
+			}
+
+			ctx := r.Context()
+			if claims.TenantID != 0 {
+				if requested, ok := tenant.FromContext(ctx); ok && requested != claims.TenantID {
+					m.HandleError(w, r, tenant.ErrTenantMismatch)
+					return
+				}
+				ctx = tenant.NewContext(ctx, claims.TenantID)
+			}
+			ctx = NewContext(ctx, claims)
+			ctx = logger.NewContext(ctx, m.Log(r).With("capability_scope", claims.Scope, "shared_by", claims.Subject))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
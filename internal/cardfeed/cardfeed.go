@@ -0,0 +1,270 @@
+package cardfeed
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	cardDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cardfeed"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+)
+
+const (
+	StatusUnmatched = "unmatched"
+	StatusMatched   = "matched"
+)
+
+// DateLayout is the format of transaction dates in feeds.
+const DateLayout = "2006-01-02"
+
+// MaxReportedErrors caps the row errors kept in a report; the counts still
+// cover every row.
+const MaxReportedErrors = 100
+
+var (
+	ErrFileTooLarge   = errors.NewRequestTooLargeError("card feed file is too large", errors.ErrCodeRequestTooLarge)
+	ErrEmptyFile      = errors.NewValidationFieldError("file", "card feed file has no header row", errors.ErrCodeInvalidImportFile)
+	ErrMalformedFile  = errors.NewValidationFieldError("file", "card feed file is not valid CSV", errors.ErrCodeInvalidImportFile)
+	ErrNoTransactions = errors.NewValidationFieldError("transactions", "at least one transaction is required", errors.ErrCodeValidationFailed)
+	ErrInvalidStatus  = errors.NewValidationFieldError("status", "status must be unmatched or matched", errors.ErrCodeValidationFailed)
+)
+
+// Transaction is a card charge as finance sees it. ExpenseID is set once the
+// charge has been matched to the expense it was claimed on.
+type Transaction struct {
+	ID              int64      `json:"id"`
+	ExternalID      string     `json:"transaction_id"`
+	CardholderEmail string     `json:"cardholder_email"`
+	UserID          *int64     `json:"user_id,omitempty"`
+	CardLast4       string     `json:"card_last4,omitempty"`
+	Merchant        string     `json:"merchant"`
+	AmountIDR       int64      `json:"amount_idr"`
+	TransactionDate string     `json:"transaction_date"`
+	Status          string     `json:"status"`
+	ExpenseID       *int64     `json:"expense_id,omitempty"`
+	MatchedAt       *time.Time `json:"matched_at,omitempty"`
+	ImportedAt      time.Time  `json:"imported_at"`
+}
+
+// TransactionDTO is one charge in a feed pushed through the API or read from
+// a CSV row. TransactionID is the issuer's ID; importing it again is a no-op.
+type TransactionDTO struct {
+	TransactionID   string `json:"transaction_id"`
+	CardholderEmail string `json:"cardholder_email"`
+	CardLast4       string `json:"card_last4,omitempty"`
+	Merchant        string `json:"merchant"`
+	AmountIDR       int64  `json:"amount_idr"`
+	// TransactionDate is formatted as YYYY-MM-DD.
+	TransactionDate string `json:"transaction_date"`
+}
+
+type ImportTransactionsDTO struct {
+	Transactions []TransactionDTO `json:"transactions"`
+}
+
+// RowError explains why one feed row was skipped. Row is the line number in a
+// CSV file, counting the header as line 1, or the 1-based position in an API
+// feed.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarises an import. Duplicates were imported before and
+// are left as they are; Matched counts new transactions linked to an expense
+// straight away.
+type ImportReport struct {
+	TotalRows  int        `json:"total_rows"`
+	Imported   int        `json:"imported"`
+	Duplicates int        `json:"duplicates"`
+	Matched    int        `json:"matched"`
+	FailedRows int        `json:"failed_rows"`
+	Errors     []RowError `json:"errors"`
+	// ErrorsTruncated is set when more than MaxReportedErrors rows failed.
+	ErrorsTruncated bool `json:"errors_truncated,omitempty"`
+}
+
+func (r *ImportReport) fail(errs []RowError) {
+	r.FailedRows++
+	for _, e := range errs {
+		if len(r.Errors) >= MaxReportedErrors {
+			r.ErrorsTruncated = true
+			return
+		}
+		r.Errors = append(r.Errors, e)
+	}
+}
+
+// MatchReport summarises a matching run over the unmatched transactions.
+type MatchReport struct {
+	Checked   int `json:"checked"`
+	Matched   int `json:"matched"`
+	Unmatched int `json:"unmatched"`
+}
+
+// ListQuery filters transactions; an empty Status lists every transaction.
+type ListQuery struct {
+	Status  string
+	Page    int
+	PerPage int
+}
+
+func (q *ListQuery) SetDefaults() {
+	if q.PerPage <= 0 || q.PerPage > 100 {
+		q.PerPage = 50
+	}
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+}
+
+// ParseFromRequest reads the filters from the query string. Status is kept
+// as given so an unknown one can be reported; page numbers that do not
+// parse are ignored.
+func (q *ListQuery) ParseFromRequest(r *http.Request) {
+	query := r.URL.Query()
+
+	q.Status = query.Get("status")
+	if pp, err := strconv.Atoi(query.Get("per_page")); err == nil {
+		q.PerPage = pp
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil {
+		q.Page = p
+	}
+
+	q.SetDefaults()
+}
+
+type TransactionsResponse struct {
+	Transactions []*Transaction `json:"transactions"`
+	Total        int64          `json:"total"`
+	Page         int            `json:"page"`
+	PerPage      int            `json:"per_page"`
+}
+
+// toDataModel checks dto and converts it, reporting every problem against
+// row.
+func (dto TransactionDTO) toDataModel(row int) (*cardDatamodel.Transaction, []RowError) {
+	var errs []RowError
+	invalid := func(field, message string, code errors.ErrorCode) {
+		errs = append(errs, RowError{Row: row, Field: field, Code: string(code), Message: message})
+	}
+
+	t := &cardDatamodel.Transaction{
+		ExternalID:      strings.TrimSpace(dto.TransactionID),
+		CardholderEmail: strings.ToLower(strings.TrimSpace(dto.CardholderEmail)),
+		CardLast4:       strings.TrimSpace(dto.CardLast4),
+		Merchant:        strings.TrimSpace(dto.Merchant),
+		AmountIDR:       dto.AmountIDR,
+		Status:          StatusUnmatched,
+	}
+
+	if t.ExternalID == "" || len(t.ExternalID) > 255 {
+		invalid("transaction_id", "transaction_id is required and at most 255 characters", errors.ErrCodeValidationFailed)
+	}
+	if t.CardholderEmail == "" {
+		invalid("cardholder_email", "cardholder_email is required", errors.ErrCodeValidationFailed)
+	}
+	if len(t.CardLast4) > 4 {
+		invalid("card_last4", "card_last4 must be at most 4 characters", errors.ErrCodeValidationFailed)
+	}
+	if t.Merchant == "" || len(t.Merchant) > 255 {
+		invalid("merchant", "merchant is required and at most 255 characters", errors.ErrCodeValidationFailed)
+	}
+	if t.AmountIDR <= 0 {
+		invalid("amount_idr", "amount_idr must be a positive whole number of rupiah", errors.ErrCodeInvalidAmount)
+	}
+	date, err := time.Parse(DateLayout, strings.TrimSpace(dto.TransactionDate))
+	if err != nil {
+		invalid("transaction_date", "transaction_date must be formatted as YYYY-MM-DD", errors.ErrCodeInvalidDate)
+	}
+	t.TransactionDate = date
+
+	return t, errs
+}
+
+func fromDataModel(t *cardDatamodel.Transaction) *Transaction {
+	return &Transaction{
+		ID:              t.ID,
+		ExternalID:      t.ExternalID,
+		CardholderEmail: t.CardholderEmail,
+		UserID:          t.UserID,
+		CardLast4:       t.CardLast4,
+		Merchant:        t.Merchant,
+		AmountIDR:       t.AmountIDR,
+		TransactionDate: t.TransactionDate.Format(DateLayout),
+		Status:          t.Status,
+		ExpenseID:       t.ExpenseID,
+		MatchedAt:       t.MatchedAt,
+		ImportedAt:      t.ImportedAt,
+	}
+}
+
+// pickExpense chooses the candidate t should be matched to, or nil when
+// there is none or the best two are equally good.
+func pickExpense(t *cardDatamodel.Transaction, candidates []*expenseDatamodel.Expense) *expenseDatamodel.Expense {
+	type score struct {
+		merchant bool
+		days     int
+	}
+	better := func(a, b score) bool {
+		if a.merchant != b.merchant {
+			return a.merchant
+		}
+		return a.days < b.days
+	}
+
+	var best *expenseDatamodel.Expense
+	var bestScore score
+	tied := false
+	for _, e := range candidates {
+		sc := score{
+			merchant: mentionsMerchant(e.Description, t.Merchant),
+			days:     daysApart(e.ExpenseDate, t.TransactionDate),
+		}
+		switch {
+		case best == nil || better(sc, bestScore):
+			best, bestScore, tied = e, sc, false
+		case !better(bestScore, sc):
+			tied = true
+		}
+	}
+	if tied {
+		return nil
+	}
+	return best
+}
+
+// mentionsMerchant reports whether description contains a word of the
+// merchant name, ignoring words too short to tell merchants apart.
+func mentionsMerchant(description, merchant string) bool {
+	words := strings.FieldsFunc(strings.ToLower(description), isSeparator)
+	for _, m := range strings.FieldsFunc(strings.ToLower(merchant), isSeparator) {
+		if len(m) < 3 {
+			continue
+		}
+		for _, w := range words {
+			if w == m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func daysApart(a, b time.Time) int {
+	days := int(a.Sub(b).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
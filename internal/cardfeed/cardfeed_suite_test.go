@@ -0,0 +1,13 @@
+package cardfeed_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCardFeed(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Card Feed Suite")
+}
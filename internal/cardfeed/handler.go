@@ -0,0 +1,152 @@
+package cardfeed
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	ImportCSV(ctx context.Context, body io.Reader) (*ImportReport, error)
+	Import(ctx context.Context, dto ImportTransactionsDTO) (*ImportReport, error)
+	MatchUnmatched(ctx context.Context) (*MatchReport, error)
+	List(ctx context.Context, q ListQuery) (*TransactionsResponse, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+	// MaxFileBytes bounds uploaded files.
+	MaxFileBytes int64
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI, maxFileBytes int64) *Handler {
+	return &Handler{
+		BaseHandler:  baseHandler,
+		Service:      service,
+		MaxFileBytes: maxFileBytes,
+	}
+}
+
+// ImportCSV godoc
+// @Summary      Import a card feed from CSV
+// @Description  The file needs the columns transaction_id, cardholder_email, merchant, amount_idr and transaction_date (YYYY-MM-DD); card_last4 is optional and other columns are ignored. Transactions already imported under the same transaction_id are counted as duplicates and left as they are. New transactions are matched to the cardholder's expenses straight away. Requires reconcile_cards or admin.
+// @Tags         card-transactions
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file  formData  file  true  "CSV file"
+// @Success      200   {object}  ImportReport
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /card-transactions/import [post]
+func (h *Handler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxFileBytes+transport.MultipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.HandleError(w, r, ErrFileTooLarge)
+			return
+		}
+		h.WriteError(w, r, http.StatusBadRequest, "multipart form with a file field is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > h.MaxFileBytes {
+		h.HandleError(w, r, ErrFileTooLarge)
+		return
+	}
+
+	report, err := h.Service.ImportCSV(r.Context(), file)
+	if err != nil {
+		h.Log(r).Error("ImportCSV: service error", "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, report)
+}
+
+// ImportTransactions godoc
+// @Summary      Push card transactions
+// @Description  For issuers that deliver their feed through an API. Same rules as the CSV import; errors name the 1-based position of the transaction in the list. Requires reconcile_cards or admin.
+// @Tags         card-transactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      ImportTransactionsDTO  true  "Transactions"
+// @Success      200   {object}  ImportReport
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /card-transactions [post]
+func (h *Handler) ImportTransactions(w http.ResponseWriter, r *http.Request) {
+	var dto ImportTransactionsDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	report, err := h.Service.Import(r.Context(), dto)
+	if err != nil {
+		h.Log(r).Error("ImportTransactions: service error", "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, report)
+}
+
+// MatchTransactions godoc
+// @Summary      Match unmatched card transactions
+// @Description  Tries every unmatched transaction against the cardholder's expenses again, picking up expenses submitted since the feed was imported. Requires reconcile_cards or admin.
+// @Tags         card-transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  MatchReport
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /card-transactions/match [post]
+func (h *Handler) MatchTransactions(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Service.MatchUnmatched(r.Context())
+	if err != nil {
+		h.Log(r).Error("MatchTransactions: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to match card transactions")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, report)
+}
+
+// ListTransactions godoc
+// @Summary      List card transactions
+// @Description  Newest charge first. Use status=unmatched to see charges no expense has been matched to. Requires reconcile_cards or admin.
+// @Tags         card-transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query     string  false  "unmatched or matched"
+// @Param        page      query     int     false  "Page"
+// @Param        per_page  query     int     false  "Items per page (max 100)"
+// @Success      200       {object}  TransactionsResponse
+// @Failure      400       {object}  transport.AppErrorResponse
+// @Failure      401       {object}  transport.ErrorResponse
+// @Failure      403       {object}  transport.ErrorResponse
+// @Router       /card-transactions [get]
+func (h *Handler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	var q ListQuery
+	q.ParseFromRequest(r)
+
+	resp, err := h.Service.List(r.Context(), q)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/cardfeed"
+	cardDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cardfeed"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) cardfeed.RepositoryAPI {
+	return &TransactionRepository{db: db}
+}
+
+func (r *TransactionRepository) Create(ctx context.Context, t *cardDatamodel.Transaction) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(t)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *TransactionRepository) List(ctx context.Context, q cardfeed.ListQuery) ([]*cardDatamodel.Transaction, int64, error) {
+	query := r.db.WithContext(ctx).Model(&cardDatamodel.Transaction{})
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []*cardDatamodel.Transaction
+	err := query.Order("transaction_date DESC, id DESC").Limit(q.PerPage).Offset((q.Page - 1) * q.PerPage).Find(&transactions).Error
+	return transactions, total, err
+}
+
+func (r *TransactionRepository) ListUnmatched(ctx context.Context) ([]*cardDatamodel.Transaction, error) {
+	var transactions []*cardDatamodel.Transaction
+	err := r.db.WithContext(ctx).Where("status = ?", cardfeed.StatusUnmatched).Order("transaction_date, id").Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *TransactionRepository) SetUserID(ctx context.Context, id, userID int64) error {
+	return r.db.WithContext(ctx).Model(&cardDatamodel.Transaction{}).Where("id = ?", id).Update("user_id", userID).Error
+}
+
+func (r *TransactionRepository) Link(ctx context.Context, id, expenseID int64, matchedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&cardDatamodel.Transaction{}).
+		Where("id = ? AND status = ?", id, cardfeed.StatusUnmatched).
+		Updates(map[string]interface{}{
+			"status":     cardfeed.StatusMatched,
+			"expense_id": expenseID,
+			"matched_at": matchedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindUserID is tenant scoped through the user model.
+func (r *TransactionRepository) FindUserID(ctx context.Context, email string) (int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&userDatamodel.User{}).
+		Where("LOWER(email) = LOWER(?) AND is_active = ?", email, true).
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// CandidateExpenses compares dates as YYYY-MM-DD so the DATE column is not
+// shifted by the session time zone.
+func (r *TransactionRepository) CandidateExpenses(ctx context.Context, userID, amountIDR int64, from, to time.Time) ([]*expenseDatamodel.Expense, error) {
+	var expenses []*expenseDatamodel.Expense
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND amount_idr = ? AND expense_status NOT IN ?", userID, amountIDR, []string{expense.ExpenseStatusRejected, expense.ExpenseStatusCancelled}).
+		Where("expense_date BETWEEN ? AND ?", from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Where("NOT EXISTS (SELECT 1 FROM card_transactions ct WHERE ct.expense_id = expenses.id)").
+		Order("expense_date, id").
+		Find(&expenses).Error
+	return expenses, err
+}
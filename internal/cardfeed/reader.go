@@ -0,0 +1,114 @@
+package cardfeed
+
+import (
+	"encoding/csv"
+	stderrors "errors"
+	"io"
+	"strconv"
+	"strings"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+)
+
+// Columns are the CSV columns a card feed needs. card_last4 may be added;
+// other columns are ignored, so issuer exports can be uploaded as they are.
+var Columns = []string{"transaction_id", "cardholder_email", "merchant", "amount_idr", "transaction_date"}
+
+// Row is one parsed data row. Errors holds problems found while parsing;
+// Transaction is only meaningful when it is empty.
+type Row struct {
+	Line        int
+	Transaction TransactionDTO
+	Errors      []RowError
+}
+
+// Reader streams rows from a CSV card feed.
+type Reader struct {
+	csv     *csv.Reader
+	columns map[string]int
+}
+
+// NewReader reads the header row and checks every column in Columns is
+// present.
+func NewReader(r io.Reader) (*Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, ErrMalformedFile
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+
+	var missing []string
+	for _, name := range Columns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.NewValidationFieldError("file", "missing columns: "+strings.Join(missing, ", "), errors.ErrCodeInvalidImportFile)
+	}
+
+	return &Reader{csv: cr, columns: columns}, nil
+}
+
+// Next returns the next row, or io.EOF after the last one. Malformed lines
+// come back as rows with errors; only read failures are returned as errors.
+func (r *Reader) Next() (*Row, error) {
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if stderrors.As(err, &parseErr) {
+		return &Row{
+			Line:   parseErr.StartLine,
+			Errors: []RowError{{Row: parseErr.StartLine, Code: string(errors.ErrCodeInvalidImportFile), Message: parseErr.Err.Error()}},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	row := &Row{Line: line}
+	field := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.Transaction = TransactionDTO{
+		TransactionID:   field("transaction_id"),
+		CardholderEmail: field("cardholder_email"),
+		CardLast4:       field("card_last4"),
+		Merchant:        field("merchant"),
+		TransactionDate: field("transaction_date"),
+	}
+	if raw := field("amount_idr"); raw != "" {
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			row.Errors = append(row.Errors, RowError{Row: line, Field: "amount_idr", Code: string(errors.ErrCodeInvalidAmount), Message: "amount_idr must be a whole number of rupiah"})
+		}
+		row.Transaction.AmountIDR = amount
+	}
+
+	return row, nil
+}
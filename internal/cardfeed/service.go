@@ -0,0 +1,256 @@
+package cardfeed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	cardDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cardfeed"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the card transactions of the tenant ctx is
+// scoped to.
+type RepositoryAPI interface {
+	// Create leaves a transaction already imported under the same external
+	// ID untouched and reports whether it inserted one.
+	Create(ctx context.Context, t *cardDatamodel.Transaction) (bool, error)
+	List(ctx context.Context, q ListQuery) ([]*cardDatamodel.Transaction, int64, error)
+	ListUnmatched(ctx context.Context) ([]*cardDatamodel.Transaction, error)
+	SetUserID(ctx context.Context, id, userID int64) error
+	// Link matches an unmatched transaction to expenseID and reports whether
+	// it did; a transaction matched in the meantime is left as it is.
+	Link(ctx context.Context, id, expenseID int64, matchedAt time.Time) (bool, error)
+	// FindUserID returns the active user with email, or 0 when there is
+	// none.
+	FindUserID(ctx context.Context, email string) (int64, error)
+	// CandidateExpenses returns userID's submitted, unrejected expenses of
+	// exactly amountIDR dated between from and to that no transaction is
+	// matched to yet.
+	CandidateExpenses(ctx context.Context, userID, amountIDR int64, from, to time.Time) ([]*expenseDatamodel.Expense, error)
+}
+
+type Config struct {
+	// MatchWindowDays is how many days an expense date may be from the
+	// transaction date and still match.
+	MatchWindowDays int
+}
+
+// Service imports card feeds and matches their transactions to the
+// expenses cardholders submit for them. Transactions that cannot be matched
+// yet stay unmatched for finance to chase.
+type Service struct {
+	repo   RepositoryAPI
+	cfg    Config
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, cfg Config, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// ImportCSV imports every valid row of a CSV feed, skipping rows that fail
+// validation.
+func (s *Service) ImportCSV(ctx context.Context, body io.Reader) (*ImportReport, error) {
+	reader, err := NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Errors: []RowError{}}
+	users := map[string]int64{}
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read card feed: %w", err)
+		}
+
+		report.TotalRows++
+		if len(row.Errors) > 0 {
+			report.fail(row.Errors)
+			continue
+		}
+		if err := s.importRow(ctx, row.Line, row.Transaction, users, report); err != nil {
+			return nil, err
+		}
+	}
+
+	s.log(ctx).Info("card feed imported", "source", "csv", "rows", report.TotalRows,
+		"imported", report.Imported, "duplicates", report.Duplicates, "matched", report.Matched, "failed", report.FailedRows)
+	return report, nil
+}
+
+// Import imports transactions pushed through the API, reporting rows by
+// their position in the list.
+func (s *Service) Import(ctx context.Context, dto ImportTransactionsDTO) (*ImportReport, error) {
+	if len(dto.Transactions) == 0 {
+		return nil, ErrNoTransactions
+	}
+
+	report := &ImportReport{Errors: []RowError{}}
+	users := map[string]int64{}
+	for i, t := range dto.Transactions {
+		report.TotalRows++
+		if err := s.importRow(ctx, i+1, t, users, report); err != nil {
+			return nil, err
+		}
+	}
+
+	s.log(ctx).Info("card feed imported", "source", "api", "rows", report.TotalRows,
+		"imported", report.Imported, "duplicates", report.Duplicates, "matched", report.Matched, "failed", report.FailedRows)
+	return report, nil
+}
+
+// importRow stores one transaction and tries to match it straight away.
+// Cardholders without an active user are still imported; they are looked up
+// again on every matching run.
+func (s *Service) importRow(ctx context.Context, line int, dto TransactionDTO, users map[string]int64, report *ImportReport) error {
+	t, rowErrs := dto.toDataModel(line)
+	if len(rowErrs) > 0 {
+		report.fail(rowErrs)
+		return nil
+	}
+
+	userID, cached := users[t.CardholderEmail]
+	if !cached {
+		var err error
+		if userID, err = s.repo.FindUserID(ctx, t.CardholderEmail); err != nil {
+			return fmt.Errorf("failed to look up cardholder: %w", err)
+		}
+		users[t.CardholderEmail] = userID
+	}
+	if userID > 0 {
+		t.UserID = &userID
+	}
+
+	created, err := s.repo.Create(ctx, t)
+	if err != nil {
+		return fmt.Errorf("failed to store card transaction: %w", err)
+	}
+	if !created {
+		report.Duplicates++
+		return nil
+	}
+	report.Imported++
+
+	matched, err := s.match(ctx, t)
+	if err != nil {
+		return err
+	}
+	if matched {
+		report.Matched++
+	}
+	return nil
+}
+
+// MatchUnmatched tries every unmatched transaction again, picking up
+// expenses submitted since the feed was imported.
+func (s *Service) MatchUnmatched(ctx context.Context) (*MatchReport, error) {
+	rows, err := s.repo.ListUnmatched(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unmatched card transactions: %w", err)
+	}
+
+	report := &MatchReport{Checked: len(rows)}
+	for _, t := range rows {
+		if t.UserID == nil {
+			userID, err := s.repo.FindUserID(ctx, t.CardholderEmail)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up cardholder: %w", err)
+			}
+			if userID > 0 {
+				if err := s.repo.SetUserID(ctx, t.ID, userID); err != nil {
+					return nil, fmt.Errorf("failed to set cardholder: %w", err)
+				}
+				t.UserID = &userID
+			}
+		}
+
+		matched, err := s.match(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			report.Matched++
+		}
+	}
+	report.Unmatched = report.Checked - report.Matched
+
+	s.log(ctx).Info("card transactions matched", "checked", report.Checked, "matched", report.Matched, "unmatched", report.Unmatched)
+	return report, nil
+}
+
+// match links t to the cardholder's expense of the same amount dated within
+// the match window. A candidate whose description names the merchant beats
+// one that does not, then the closest date wins; when two candidates tie,
+// t is left for finance to match by hand.
+func (s *Service) match(ctx context.Context, t *cardDatamodel.Transaction) (bool, error) {
+	if t.UserID == nil {
+		return false, nil
+	}
+
+	window := time.Duration(s.cfg.MatchWindowDays) * 24 * time.Hour
+	candidates, err := s.repo.CandidateExpenses(ctx, *t.UserID, t.AmountIDR, t.TransactionDate.Add(-window), t.TransactionDate.Add(window))
+	if err != nil {
+		return false, fmt.Errorf("failed to find matching expenses: %w", err)
+	}
+
+	best := pickExpense(t, candidates)
+	if best == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	linked, err := s.repo.Link(ctx, t.ID, best.ID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to match card transaction: %w", err)
+	}
+	if !linked {
+		return false, nil
+	}
+
+	t.Status = StatusMatched
+	t.ExpenseID = &best.ID
+	t.MatchedAt = &now
+	s.log(ctx).Info("card transaction matched", "transaction_id", t.ID, "expense_id", best.ID)
+	return true, nil
+}
+
+// List returns transactions, newest charge first; finance filters it by
+// status=unmatched to see the charges nobody has claimed.
+func (s *Service) List(ctx context.Context, q ListQuery) (*TransactionsResponse, error) {
+	if q.Status != "" && q.Status != StatusUnmatched && q.Status != StatusMatched {
+		return nil, ErrInvalidStatus
+	}
+	q.SetDefaults()
+
+	rows, total, err := s.repo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list card transactions: %w", err)
+	}
+
+	transactions := make([]*Transaction, len(rows))
+	for i, row := range rows {
+		transactions[i] = fromDataModel(row)
+	}
+	return &TransactionsResponse{
+		Transactions: transactions,
+		Total:        total,
+		Page:         q.Page,
+		PerPage:      q.PerPage,
+	}, nil
+}
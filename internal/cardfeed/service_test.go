@@ -0,0 +1,240 @@
+package cardfeed_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/cardfeed"
+	cardDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cardfeed"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+)
+
+type mockRepository struct {
+	transactions []*cardDatamodel.Transaction
+	expenses     []*expenseDatamodel.Expense
+	users        map[string]int64
+}
+
+func (m *mockRepository) Create(_ context.Context, t *cardDatamodel.Transaction) (bool, error) {
+	for _, existing := range m.transactions {
+		if existing.ExternalID == t.ExternalID {
+			return false, nil
+		}
+	}
+	t.ID = int64(len(m.transactions) + 1)
+	m.transactions = append(m.transactions, t)
+	return true, nil
+}
+
+func (m *mockRepository) List(_ context.Context, q cardfeed.ListQuery) ([]*cardDatamodel.Transaction, int64, error) {
+	var out []*cardDatamodel.Transaction
+	for _, t := range m.transactions {
+		if q.Status == "" || t.Status == q.Status {
+			out = append(out, t)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (m *mockRepository) ListUnmatched(ctx context.Context) ([]*cardDatamodel.Transaction, error) {
+	out, _, err := m.List(ctx, cardfeed.ListQuery{Status: cardfeed.StatusUnmatched})
+	return out, err
+}
+
+func (m *mockRepository) SetUserID(_ context.Context, id, userID int64) error {
+	m.transactions[id-1].UserID = &userID
+	return nil
+}
+
+func (m *mockRepository) Link(_ context.Context, id, expenseID int64, matchedAt time.Time) (bool, error) {
+	t := m.transactions[id-1]
+	if t.Status != cardfeed.StatusUnmatched {
+		return false, nil
+	}
+	t.Status = cardfeed.StatusMatched
+	t.ExpenseID = &expenseID
+	t.MatchedAt = &matchedAt
+	return true, nil
+}
+
+func (m *mockRepository) FindUserID(_ context.Context, email string) (int64, error) {
+	return m.users[email], nil
+}
+
+func (m *mockRepository) CandidateExpenses(_ context.Context, userID, amountIDR int64, from, to time.Time) ([]*expenseDatamodel.Expense, error) {
+	var out []*expenseDatamodel.Expense
+	for _, e := range m.expenses {
+		if e.UserID != userID || e.AmountIDR != amountIDR || e.ExpenseDate.Before(from) || e.ExpenseDate.After(to) {
+			continue
+		}
+		if m.linked(e.ID) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (m *mockRepository) linked(expenseID int64) bool {
+	for _, t := range m.transactions {
+		if t.ExpenseID != nil && *t.ExpenseID == expenseID {
+			return true
+		}
+	}
+	return false
+}
+
+var _ = Describe("Card feed service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *cardfeed.Service
+	)
+
+	date := func(day int) time.Time {
+		return time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC)
+	}
+	addExpense := func(id, userID, amount int64, description string, day int) {
+		repo.expenses = append(repo.expenses, &expenseDatamodel.Expense{
+			ID: id, UserID: userID, AmountIDR: amount, Description: description, ExpenseDate: date(day), ExpenseStatus: "pending_approval",
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{users: map[string]int64{"ana@example.com": 7}}
+		service = cardfeed.NewService(repo, cardfeed.Config{MatchWindowDays: 3}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	Describe("ImportCSV", func() {
+		const header = "Transaction_ID,cardholder_email,card_last4,merchant,amount_idr,transaction_date\n"
+
+		It("imports rows, skips duplicates and matches expenses straight away", func() {
+			addExpense(1, 7, 250000, "Client dinner at Sate Khas Senayan", 2)
+
+			report, err := service.ImportCSV(ctx, strings.NewReader(header+
+				"tx-1,Ana@Example.com,4242,SATE KHAS SENAYAN,250000,2025-03-01\n"+
+				"tx-2,ana@example.com,4242,Grab,80000,2025-03-01\n"+
+				"tx-1,ana@example.com,4242,SATE KHAS SENAYAN,250000,2025-03-01\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.TotalRows).To(Equal(3))
+			Expect(report.Imported).To(Equal(2))
+			Expect(report.Duplicates).To(Equal(1))
+			Expect(report.Matched).To(Equal(1))
+			Expect(report.FailedRows).To(BeZero())
+
+			Expect(repo.transactions[0].Status).To(Equal(cardfeed.StatusMatched))
+			Expect(*repo.transactions[0].ExpenseID).To(Equal(int64(1)))
+			Expect(repo.transactions[1].Status).To(Equal(cardfeed.StatusUnmatched))
+		})
+
+		It("reports invalid rows against their line and imports the rest", func() {
+			report, err := service.ImportCSV(ctx, strings.NewReader(header+
+				"tx-1,ana@example.com,4242,Grab,80.000,2025-03-01\n"+
+				"tx-2,ana@example.com,4242,,80000,01/03/2025\n"+
+				"tx-3,stranger@example.com,4242,Grab,80000,2025-03-01\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Imported).To(Equal(1))
+			Expect(report.FailedRows).To(Equal(2))
+			Expect(report.Errors).To(HaveLen(3))
+			Expect(report.Errors[0].Row).To(Equal(2))
+			Expect(report.Errors[0].Field).To(Equal("amount_idr"))
+			Expect(report.Errors[2].Row).To(Equal(3))
+			Expect(report.Errors[2].Code).To(Equal(string(errors.ErrCodeInvalidDate)))
+
+			Expect(repo.transactions[0].UserID).To(BeNil())
+		})
+
+		It("rejects files missing required columns", func() {
+			_, err := service.ImportCSV(ctx, strings.NewReader("transaction_id,merchant\n"))
+			appErr, ok := errors.IsAppError(err)
+			Expect(ok).To(BeTrue())
+			Expect(appErr.Error()).To(ContainSubstring("cardholder_email, amount_idr, transaction_date"))
+		})
+	})
+
+	Describe("Import", func() {
+		It("requires at least one transaction", func() {
+			_, err := service.Import(ctx, cardfeed.ImportTransactionsDTO{})
+			Expect(err).To(Equal(cardfeed.ErrNoTransactions))
+		})
+	})
+
+	Describe("matching", func() {
+		importOne := func(merchant string, amount int64, day int) {
+			_, err := service.Import(ctx, cardfeed.ImportTransactionsDTO{Transactions: []cardfeed.TransactionDTO{{
+				TransactionID:   "tx-" + merchant,
+				CardholderEmail: "ana@example.com",
+				Merchant:        merchant,
+				AmountIDR:       amount,
+				TransactionDate: date(day).Format(cardfeed.DateLayout),
+			}}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("prefers the expense naming the merchant over a closer date", func() {
+			addExpense(1, 7, 120000, "Taxi to airport", 10)
+			addExpense(2, 7, 120000, "Lunch at Hokben", 12)
+
+			importOne("HOKBEN GRAND INDONESIA", 120000, 10)
+			Expect(*repo.transactions[0].ExpenseID).To(Equal(int64(2)))
+		})
+
+		It("falls back to the closest date when no description names the merchant", func() {
+			addExpense(1, 7, 120000, "Taxi", 8)
+			addExpense(2, 7, 120000, "Taxi", 11)
+
+			importOne("BLUEBIRD", 120000, 10)
+			Expect(*repo.transactions[0].ExpenseID).To(Equal(int64(2)))
+		})
+
+		It("leaves ties and expenses outside the window for finance", func() {
+			addExpense(1, 7, 120000, "Taxi", 9)
+			addExpense(2, 7, 120000, "Taxi", 11)
+			addExpense(3, 7, 50000, "Coffee", 20)
+
+			importOne("BLUEBIRD", 120000, 10)
+			importOne("STARBUCKS", 50000, 10)
+
+			resp, err := service.List(ctx, cardfeed.ListQuery{Status: cardfeed.StatusUnmatched})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Transactions).To(HaveLen(2))
+		})
+
+		It("matches expenses submitted after the import on the next run", func() {
+			importOne("GRAB", 80000, 10)
+			Expect(repo.transactions[0].Status).To(Equal(cardfeed.StatusUnmatched))
+
+			addExpense(1, 7, 80000, "Grab to client office", 10)
+			report, err := service.MatchUnmatched(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*report).To(Equal(cardfeed.MatchReport{Checked: 1, Matched: 1, Unmatched: 0}))
+		})
+
+		It("looks up cardholders who were not users at import time", func() {
+			_, err := service.Import(ctx, cardfeed.ImportTransactionsDTO{Transactions: []cardfeed.TransactionDTO{{
+				TransactionID: "tx-1", CardholderEmail: "new@example.com", Merchant: "GRAB", AmountIDR: 80000, TransactionDate: "2025-03-10",
+			}}})
+			Expect(err).NotTo(HaveOccurred())
+
+			repo.users["new@example.com"] = 9
+			addExpense(1, 9, 80000, "Grab", 10)
+			report, err := service.MatchUnmatched(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Matched).To(Equal(1))
+			Expect(*repo.transactions[0].UserID).To(Equal(int64(9)))
+		})
+	})
+
+	It("rejects unknown statuses when listing", func() {
+		_, err := service.List(ctx, cardfeed.ListQuery{Status: "pending"})
+		Expect(err).To(Equal(cardfeed.ErrInvalidStatus))
+	})
+})
@@ -10,6 +10,7 @@ type Category struct {
 	ID          int64     `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	ParentID    *int64    `json:"parent_id,omitempty"`
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -52,6 +53,7 @@ func ToDataModel(c *Category) *categoryDatamodel.ExpenseCategory {
 		ID:          c.ID,
 		Name:        c.Name,
 		Description: c.Description,
+		ParentID:    c.ParentID,
 		IsActive:    c.IsActive,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
@@ -63,6 +65,7 @@ func FromDataModel(c *categoryDatamodel.ExpenseCategory) *Category {
 		ID:          c.ID,
 		Name:        c.Name,
 		Description: c.Description,
+		ParentID:    c.ParentID,
 		IsActive:    c.IsActive,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
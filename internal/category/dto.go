@@ -8,3 +8,13 @@ type CategoryResponse struct {
 type CategoriesResponse struct {
 	Categories []CategoryResponse `json:"categories"`
 }
+
+type CategoryTreeNode struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Children    []CategoryTreeNode `json:"children,omitempty"`
+}
+
+type CategoryTreeResponse struct {
+	Categories []CategoryTreeNode `json:"categories"`
+}
@@ -1,17 +1,26 @@
 package category
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 
+	"github.com/frahmantamala/expense-management/internal/tenant"
 	"github.com/frahmantamala/expense-management/internal/transport"
 )
 
 type ServiceAPI interface {
-	GetAllCategories() ([]CategoryResponse, error)
-	GetCategoryByName(name string) (*CategoryResponse, error)
-	IsValidCategory(name string) bool
+	GetAllCategories(ctx context.Context) ([]CategoryResponse, error)
+	GetCategoryByName(ctx context.Context, name string) (*CategoryResponse, error)
+	IsValidCategory(ctx context.Context, name string) bool
+	GetCategoryTree(ctx context.Context) ([]CategoryTreeNode, error)
+	Revision(ctx context.Context) (string, error)
 }
 
+// categoriesCacheControl lets clients reuse a listing briefly; after that they
+// revalidate with the ETag, which changes with any category mutation.
+const categoriesCacheControl = "public, max-age=60"
+
 type Handler struct {
 	*transport.BaseHandler
 	Service ServiceAPI
@@ -24,11 +33,47 @@ func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler
 	}
 }
 
+// GetCategories godoc
+// @Summary      List categories
+// @Description  Returns a flat list, or nested parent/child categories with tree=true. Expenses can only use leaf categories.
+// @Tags         categories
+// @Produce      json
+// @Param        tree           query     bool    false  "Return categories nested under their parents"
+// @Param        If-None-Match  header    string  false  "ETag from a previous response"
+// @Param        X-Tenant       header    string  false  "Tenant slug; defaults to the default tenant"
+// @Success      200   {object}  CategoriesResponse
+// @Success      200   {object}  CategoryTreeResponse
+// @Success      304   "Not modified"
+// @Failure      500   {object}  transport.ErrorResponse
+// @Router       /categories [get]
 func (h *Handler) GetCategories(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.Service.GetAllCategories()
+	tree, _ := strconv.ParseBool(r.URL.Query().Get("tree"))
+
+	// Each tenant has its own categories behind the same URL.
+	w.Header().Add("Vary", tenant.Header)
+	ctx, tenantID := tenant.OrDefault(r.Context())
+
+	if revision, err := h.Service.Revision(ctx); err != nil {
+		h.Log(r).Warn("GetCategories: failed to get category revision", "error", err)
+	} else if h.NotModified(w, r, transport.ETag("categories", strconv.FormatInt(tenantID, 10), revision, strconv.FormatBool(tree)), categoriesCacheControl) {
+		return
+	}
+
+	if tree {
+		nodes, err := h.Service.GetCategoryTree(ctx)
+		if err != nil {
+			h.Log(r).Error("GetCategories: failed to get category tree", "error", err)
+			h.WriteError(w, r, http.StatusInternalServerError, "failed to get categories")
+			return
+		}
+		h.WriteJSON(w, http.StatusOK, CategoryTreeResponse{Categories: nodes})
+		return
+	}
+
+	categories, err := h.Service.GetAllCategories(ctx)
 	if err != nil {
-		h.Logger.Error("GetCategories: failed to get categories", "error", err)
-		h.WriteError(w, http.StatusInternalServerError, "failed to get categories")
+		h.Log(r).Error("GetCategories: failed to get categories", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to get categories")
 		return
 	}
 
@@ -10,12 +10,11 @@ import (
 	"github.com/frahmantamala/expense-management/internal/category"
 	categoryPostgres "github.com/frahmantamala/expense-management/internal/category/postgres"
 	categoryDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/category"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
 	"github.com/frahmantamala/expense-management/internal/transport"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var _ = Describe("Category Handler Integration", func() {
@@ -31,12 +30,7 @@ var _ = Describe("Category Handler Integration", func() {
 		var err error
 		slogger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
-		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Silent),
-		})
-		Expect(err).NotTo(HaveOccurred())
-
-		err = db.AutoMigrate(&categoryDatamodel.ExpenseCategory{})
+		db, err = testdb.Open(&categoryDatamodel.ExpenseCategory{})
 		Expect(err).NotTo(HaveOccurred())
 
 		repo = categoryPostgres.NewCategoryRepository(db)
@@ -50,7 +44,7 @@ var _ = Describe("Category Handler Integration", func() {
 		}
 
 		for _, cat := range testCategories {
-			err := repo.Create(category.ToDataModel(cat))
+			err := repo.Create(ctx, category.ToDataModel(cat))
 			Expect(err).NotTo(HaveOccurred())
 		}
 
@@ -59,11 +53,11 @@ var _ = Describe("Category Handler Integration", func() {
 			Description: "Inactive category",
 			IsActive:    true,
 		}
-		err = repo.Create(inactiveCategory)
+		err = repo.Create(ctx, inactiveCategory)
 		Expect(err).NotTo(HaveOccurred())
 
 		inactiveCategory.IsActive = false
-		err = repo.Update(inactiveCategory)
+		err = repo.Update(ctx, inactiveCategory)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -104,4 +98,70 @@ var _ = Describe("Category Handler Integration", func() {
 			Expect(cat.Description).NotTo(BeEmpty())
 		}
 	})
+
+	It("should return nested categories with tree=true", func() {
+		flights := &categoryDatamodel.ExpenseCategory{Name: "flights", Description: "Air travel", IsActive: true}
+		Expect(repo.Create(ctx, flights)).To(Succeed())
+		Expect(service.SetParent(ctx, "flights", "perjalanan")).To(Succeed())
+		Expect(service.SetParent(ctx, "perjalanan", "flights")).To(MatchError(category.ErrCategoryCycle))
+
+		req := httptest.NewRequest(http.MethodGet, "/categories?tree=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetCategories(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var response category.CategoryTreeResponse
+		Expect(json.NewDecoder(w.Body).Decode(&response)).To(Succeed())
+
+		Expect(response.Categories).To(HaveLen(2))
+		Expect(response.Categories[1].Name).To(Equal("perjalanan"))
+		Expect(response.Categories[1].Children).To(ConsistOf(category.CategoryTreeNode{Name: "flights", Description: "Air travel"}))
+		Expect(service.IsLeafCategory(ctx, "perjalanan")).To(BeFalse())
+		Expect(service.IsLeafCategory(ctx, "flights")).To(BeTrue())
+	})
+
+	Describe("caching", func() {
+		get := func(url, etag string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			w := httptest.NewRecorder()
+			handler.GetCategories(w, req)
+			return w
+		}
+
+		It("revalidates an unchanged listing with 304", func() {
+			first := get("/categories", "")
+			etag := first.Header().Get("ETag")
+			Expect(etag).NotTo(BeEmpty())
+			Expect(first.Header().Get("Cache-Control")).To(ContainSubstring("max-age"))
+
+			w := get("/categories", etag)
+
+			Expect(w.Code).To(Equal(http.StatusNotModified))
+			Expect(w.Body.Len()).To(BeZero())
+		})
+
+		It("tags the flat and tree listings differently", func() {
+			Expect(get("/categories", "").Header().Get("ETag")).
+				NotTo(Equal(get("/categories?tree=true", "").Header().Get("ETag")))
+		})
+
+		It("changes the ETag when a category is mutated", func() {
+			etag := get("/categories?tree=true", "").Header().Get("ETag")
+
+			flights := &categoryDatamodel.ExpenseCategory{Name: "flights", Description: "Air travel", IsActive: true}
+			Expect(repo.Create(ctx, flights)).To(Succeed())
+			Expect(get("/categories?tree=true", etag).Code).To(Equal(http.StatusOK))
+
+			etag = get("/categories?tree=true", "").Header().Get("ETag")
+			Expect(service.SetParent(ctx, "flights", "perjalanan")).To(Succeed())
+
+			w := get("/categories?tree=true", etag)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("ETag")).NotTo(Equal(etag))
+		})
+	})
 })
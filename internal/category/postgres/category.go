@@ -1,6 +1,9 @@
 package postgres
 
 import (
+	"context"
+	"time"
+
 	"github.com/frahmantamala/expense-management/internal/category"
 	categoryDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/category"
 	"gorm.io/gorm"
@@ -14,15 +17,15 @@ func NewCategoryRepository(db *gorm.DB) category.RepositoryAPI {
 	return &CategoryRepository{db: db}
 }
 
-func (r *CategoryRepository) GetAll() ([]*categoryDatamodel.ExpenseCategory, error) {
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]*categoryDatamodel.ExpenseCategory, error) {
 	var categories []*categoryDatamodel.ExpenseCategory
-	err := r.db.Order("name ASC").Find(&categories).Error
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&categories).Error
 	return categories, err
 }
 
-func (r *CategoryRepository) GetByName(name string) (*categoryDatamodel.ExpenseCategory, error) {
+func (r *CategoryRepository) GetByName(ctx context.Context, name string) (*categoryDatamodel.ExpenseCategory, error) {
 	var cat categoryDatamodel.ExpenseCategory
-	err := r.db.Where("name = ?", name).First(&cat).Error
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&cat).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -32,9 +35,9 @@ func (r *CategoryRepository) GetByName(name string) (*categoryDatamodel.ExpenseC
 	return &cat, nil
 }
 
-func (r *CategoryRepository) GetByID(id int64) (*categoryDatamodel.ExpenseCategory, error) {
+func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*categoryDatamodel.ExpenseCategory, error) {
 	var cat categoryDatamodel.ExpenseCategory
-	err := r.db.Where("id = ?", id).First(&cat).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&cat).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -44,14 +47,57 @@ func (r *CategoryRepository) GetByID(id int64) (*categoryDatamodel.ExpenseCatego
 	return &cat, nil
 }
 
-func (r *CategoryRepository) Create(cat *categoryDatamodel.ExpenseCategory) error {
-	return r.db.Create(cat).Error
+func (r *CategoryRepository) Create(ctx context.Context, cat *categoryDatamodel.ExpenseCategory) error {
+	return r.db.WithContext(ctx).Create(cat).Error
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, cat *categoryDatamodel.ExpenseCategory) error {
+	return r.db.WithContext(ctx).Save(cat).Error
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Model(&categoryDatamodel.ExpenseCategory{}).Where("id = ?", id).Update("is_active", false).Error
+}
+
+// GetAncestorIDs walks parent_id upwards from id, nearest parent first. UNION
+// keeps the walk finite if the stored data already contains a cycle.
+func (r *CategoryRepository) GetAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Raw(`WITH RECURSIVE ancestors(id, parent_id, depth) AS (
+	SELECT c.id, c.parent_id, 0 FROM expense_categories c WHERE c.id = ?
+	UNION
+	SELECT p.id, p.parent_id, a.depth + 1 FROM expense_categories p JOIN ancestors a ON p.id = a.parent_id
+)
+SELECT id FROM ancestors WHERE depth > 0 ORDER BY depth`, id).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *CategoryRepository) HasActiveChildren(ctx context.Context, id int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&categoryDatamodel.ExpenseCategory{}).
+		Where("parent_id = ? AND is_active = ?", id, true).
+		Count(&count).Error
+	return count > 0, err
 }
 
-func (r *CategoryRepository) Update(cat *categoryDatamodel.ExpenseCategory) error {
-	return r.db.Save(cat).Error
+func (r *CategoryRepository) SetParent(ctx context.Context, id int64, parentID *int64) error {
+	return r.db.WithContext(ctx).Model(&categoryDatamodel.ExpenseCategory{}).Where("id = ?", id).Update("parent_id", parentID).Error
 }
 
-func (r *CategoryRepository) Delete(id int64) error {
-	return r.db.Model(&categoryDatamodel.ExpenseCategory{}).Where("id = ?", id).Update("is_active", false).Error
+// GetRevision returns the row count and latest updated_at, which together
+// change whenever a category is created or modified.
+func (r *CategoryRepository) GetRevision(ctx context.Context) (int64, time.Time, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&categoryDatamodel.ExpenseCategory{}).Count(&count).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var latest []categoryDatamodel.ExpenseCategory
+	if err := r.db.WithContext(ctx).Select("updated_at").Order("updated_at DESC").Limit(1).Find(&latest).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	if len(latest) == 0 {
+		return count, time.Time{}, nil
+	}
+	return count, latest[0].UpdatedAt, nil
 }
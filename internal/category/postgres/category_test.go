@@ -1,17 +1,18 @@
 package postgres_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/frahmantamala/expense-management/internal/category"
 	categoryPostgres "github.com/frahmantamala/expense-management/internal/category/postgres"
 	categoryDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/category"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	"github.com/frahmantamala/expense-management/internal/tenant"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func TestCategoryPostgres(t *testing.T) {
@@ -19,18 +20,7 @@ func TestCategoryPostgres(t *testing.T) {
 	RunSpecs(t, "Category Postgres Suite")
 }
 
-type SQLiteCategory struct {
-	ID          int64     `gorm:"primaryKey"`
-	Name        string    `gorm:"column:name;uniqueIndex;not null"`
-	Description string    `gorm:"column:description"`
-	IsActive    bool      `gorm:"column:is_active;default:true"`
-	CreatedAt   time.Time `gorm:"column:created_at"`
-	UpdatedAt   time.Time `gorm:"column:updated_at"`
-}
-
-func (SQLiteCategory) TableName() string {
-	return "expense_categories"
-}
+var ctx = context.Background()
 
 var _ = Describe("Category PostgreSQL Repository", func() {
 	var (
@@ -40,12 +30,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 
 	BeforeEach(func() {
 		var err error
-		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Silent),
-		})
-		Expect(err).NotTo(HaveOccurred())
-
-		err = db.AutoMigrate(&SQLiteCategory{})
+		db, err = testdb.Open(&categoryDatamodel.ExpenseCategory{})
 		Expect(err).NotTo(HaveOccurred())
 
 		repo = categoryPostgres.NewCategoryRepository(db)
@@ -59,7 +44,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				IsActive:    true,
 			}
 
-			err := repo.Create(cat)
+			err := repo.Create(ctx, cat)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(cat.ID).To(BeNumerically(">", 0))
 			Expect(cat.CreatedAt).NotTo(BeZero())
@@ -72,7 +57,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				IsActive:    true,
 			}
 
-			err := repo.Create(cat1)
+			err := repo.Create(ctx, cat1)
 			Expect(err).NotTo(HaveOccurred())
 
 			cat2 := &categoryDatamodel.ExpenseCategory{
@@ -81,7 +66,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				IsActive:    true,
 			}
 
-			err = repo.Create(cat2)
+			err = repo.Create(ctx, cat2)
 			Expect(err).To(HaveOccurred())
 		})
 	})
@@ -102,7 +87,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 			}
 
 			for _, cat := range activeCategories {
-				err := repo.Create(cat)
+				err := repo.Create(ctx, cat)
 				Expect(err).NotTo(HaveOccurred())
 			}
 
@@ -111,16 +96,16 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Description: "Office supplies",
 				IsActive:    true,
 			}
-			err := repo.Create(inactiveCategory)
+			err := repo.Create(ctx, inactiveCategory)
 			Expect(err).NotTo(HaveOccurred())
 
 			inactiveCategory.IsActive = false
-			err = repo.Update(inactiveCategory)
+			err = repo.Update(ctx, inactiveCategory)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should retrieve all categories ordered by name", func() {
-			categories, err := repo.GetAll()
+			categories, err := repo.GetAll(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(categories).To(HaveLen(3))
 
@@ -130,7 +115,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 		})
 
 		It("should include both active and inactive categories", func() {
-			categories, err := repo.GetAll()
+			categories, err := repo.GetAll(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			activeCount := 0
@@ -157,12 +142,12 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Description: "Meals and entertainment",
 				IsActive:    true,
 			}
-			err := repo.Create(testCategory)
+			err := repo.Create(ctx, testCategory)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should retrieve category by name successfully", func() {
-			result, err := repo.GetByName("makan")
+			result, err := repo.GetByName(ctx, "makan")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.Name).To(Equal("makan"))
@@ -171,13 +156,13 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 		})
 
 		It("should return nil for non-existent category", func() {
-			result, err := repo.GetByName("nonexistent")
+			result, err := repo.GetByName(ctx, "nonexistent")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(BeNil())
 		})
 
 		It("should be case sensitive", func() {
-			result, err := repo.GetByName("MAKAN")
+			result, err := repo.GetByName(ctx, "MAKAN")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(BeNil())
 		})
@@ -192,7 +177,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Description: "Meals and entertainment",
 				IsActive:    true,
 			}
-			err := repo.Create(testCategory)
+			err := repo.Create(ctx, testCategory)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -200,10 +185,10 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 			testCategory.Description = "Updated description"
 			testCategory.IsActive = false
 
-			err := repo.Update(testCategory)
+			err := repo.Update(ctx, testCategory)
 			Expect(err).NotTo(HaveOccurred())
 
-			result, err := repo.GetByName("makan")
+			result, err := repo.GetByName(ctx, "makan")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.Description).To(Equal("Updated description"))
 			Expect(result.IsActive).To(BeFalse())
@@ -214,10 +199,10 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 			time.Sleep(10 * time.Millisecond)
 
 			testCategory.Description = "New description"
-			err := repo.Update(testCategory)
+			err := repo.Update(ctx, testCategory)
 			Expect(err).NotTo(HaveOccurred())
 
-			result, err := repo.GetByName("makan")
+			result, err := repo.GetByName(ctx, "makan")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.UpdatedAt).To(BeTemporally(">", originalUpdatedAt))
 		})
@@ -232,25 +217,25 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Description: "Meals and entertainment",
 				IsActive:    true,
 			}
-			err := repo.Create(testCategory)
+			err := repo.Create(ctx, testCategory)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should soft delete category by setting is_active to false", func() {
-			err := repo.Delete(testCategory.ID)
+			err := repo.Delete(ctx, testCategory.ID)
 			Expect(err).NotTo(HaveOccurred())
 
-			result, err := repo.GetByName("makan")
+			result, err := repo.GetByName(ctx, "makan")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsActive).To(BeFalse())
 		})
 
 		It("should handle non-existent ID gracefully", func() {
-			err := repo.Delete(999)
+			err := repo.Delete(ctx, 999)
 			Expect(err).NotTo(HaveOccurred())
 
-			result, err := repo.GetByName("makan")
+			result, err := repo.GetByName(ctx, "makan")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.IsActive).To(BeTrue())
 		})
@@ -263,7 +248,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Description: "First category",
 				IsActive:    true,
 			}
-			err := repo.Create(cat1)
+			err := repo.Create(ctx, cat1)
 			Expect(err).NotTo(HaveOccurred())
 
 			cat2 := &categoryDatamodel.ExpenseCategory{
@@ -271,7 +256,7 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Description: "Second category",
 				IsActive:    true,
 			}
-			err = repo.Create(cat2)
+			err = repo.Create(ctx, cat2)
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -280,14 +265,55 @@ var _ = Describe("Category PostgreSQL Repository", func() {
 				Name:        "test",
 				Description: "Test category",
 			}
-			err := repo.Create(cat)
+			err := repo.Create(ctx, cat)
 			Expect(err).NotTo(HaveOccurred())
 
-			result, err := repo.GetByName("test")
+			result, err := repo.GetByName(ctx, "test")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.IsActive).To(BeTrue())
 			Expect(result.CreatedAt).NotTo(BeZero())
 			Expect(result.UpdatedAt).NotTo(BeZero())
 		})
 	})
+
+	Describe("Tenants", func() {
+		var acme, globex context.Context
+
+		BeforeEach(func() {
+			acme = tenant.NewContext(ctx, 2)
+			globex = tenant.NewContext(ctx, 3)
+
+			Expect(repo.Create(acme, &categoryDatamodel.ExpenseCategory{Name: "makan", IsActive: true})).To(Succeed())
+			Expect(repo.Create(globex, &categoryDatamodel.ExpenseCategory{Name: "makan", IsActive: true})).To(Succeed())
+			Expect(repo.Create(globex, &categoryDatamodel.ExpenseCategory{Name: "travel", IsActive: true})).To(Succeed())
+		})
+
+		It("allows the same name in different tenants", func() {
+			all, err := repo.GetAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(all).To(HaveLen(3))
+		})
+
+		It("only returns the tenant's own categories", func() {
+			categories, err := repo.GetAll(acme)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(categories).To(HaveLen(1))
+			Expect(categories[0].TenantID).To(Equal(int64(2)))
+
+			travel, err := repo.GetByName(acme, "travel")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(travel).To(BeNil())
+		})
+
+		It("does not update another tenant's category", func() {
+			travel, err := repo.GetByName(globex, "travel")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.Delete(acme, travel.ID)).To(Succeed())
+
+			travel, err = repo.GetByName(globex, "travel")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(travel.IsActive).To(BeTrue())
+		})
+	})
 })
@@ -1,20 +1,35 @@
 package category
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"sort"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
 
 	categoryDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/category"
 )
 
 type RepositoryAPI interface {
-	GetAll() ([]*categoryDatamodel.ExpenseCategory, error)
-	GetByID(id int64) (*categoryDatamodel.ExpenseCategory, error)
-	GetByName(name string) (*categoryDatamodel.ExpenseCategory, error)
-	Create(category *categoryDatamodel.ExpenseCategory) error
-	Update(category *categoryDatamodel.ExpenseCategory) error
-	Delete(id int64) error
+	GetAll(ctx context.Context) ([]*categoryDatamodel.ExpenseCategory, error)
+	GetByID(ctx context.Context, id int64) (*categoryDatamodel.ExpenseCategory, error)
+	GetByName(ctx context.Context, name string) (*categoryDatamodel.ExpenseCategory, error)
+	Create(ctx context.Context, category *categoryDatamodel.ExpenseCategory) error
+	Update(ctx context.Context, category *categoryDatamodel.ExpenseCategory) error
+	Delete(ctx context.Context, id int64) error
+	GetAncestorIDs(ctx context.Context, id int64) ([]int64, error)
+	HasActiveChildren(ctx context.Context, id int64) (bool, error)
+	SetParent(ctx context.Context, id int64, parentID *int64) error
+	GetRevision(ctx context.Context) (int64, time.Time, error)
 }
 
+var (
+	ErrCategoryNotFound = errors.NewNotFoundError("Category not found", errors.ErrCodeCategoryNotFound)
+	ErrCategoryCycle    = errors.NewValidationError("a category cannot be placed under itself or one of its subcategories", errors.ErrCodeCategoryCycle)
+)
+
 type Service struct {
 	repo   RepositoryAPI
 	logger *slog.Logger
@@ -27,8 +42,8 @@ func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
 	}
 }
 
-func (s *Service) GetAllCategories() ([]CategoryResponse, error) {
-	dataCategories, err := s.repo.GetAll()
+func (s *Service) GetAllCategories(ctx context.Context) ([]CategoryResponse, error) {
+	dataCategories, err := s.repo.GetAll(ctx)
 	if err != nil {
 		s.logger.Error("failed to get categories from repository", "error", err)
 		return nil, err
@@ -46,8 +61,18 @@ func (s *Service) GetAllCategories() ([]CategoryResponse, error) {
 	return responses, nil
 }
 
-func (s *Service) GetCategoryByName(name string) (*CategoryResponse, error) {
-	dataCategories, err := s.repo.GetAll()
+// Revision identifies the current state of the category table. Every mutation
+// bumps updated_at, so it changes whenever a listing could.
+func (s *Service) Revision(ctx context.Context) (string, error) {
+	count, updatedAt, err := s.repo.GetRevision(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", count, updatedAt.UnixNano()), nil
+}
+
+func (s *Service) GetCategoryByName(ctx context.Context, name string) (*CategoryResponse, error) {
+	dataCategories, err := s.repo.GetAll(ctx)
 	if err != nil {
 		s.logger.Error("failed to get categories from repository", "error", err)
 		return nil, err
@@ -66,11 +91,150 @@ func (s *Service) GetCategoryByName(name string) (*CategoryResponse, error) {
 	return nil, nil
 }
 
-func (s *Service) IsValidCategory(name string) bool {
-	category, err := s.GetCategoryByName(name)
+func (s *Service) IsValidCategory(ctx context.Context, name string) bool {
+	category, err := s.GetCategoryByName(ctx, name)
 	if err != nil {
 		s.logger.Warn("error checking category validity", "name", name, "error", err)
 		return false
 	}
 	return category != nil
 }
+
+// IsLeafCategory reports whether name is an active category without active
+// subcategories; expenses may only be filed against leaves.
+func (s *Service) IsLeafCategory(ctx context.Context, name string) bool {
+	cat, err := s.repo.GetByName(ctx, name)
+	if err != nil || cat == nil {
+		return false
+	}
+	hasChildren, err := s.repo.HasActiveChildren(ctx, cat.ID)
+	if err != nil {
+		s.logger.Warn("error checking category children", "name", name, "error", err)
+		return false
+	}
+	return !hasChildren
+}
+
+// GetCategoryTree nests active categories under their parents. Subcategories
+// of an inactive parent are hidden with it.
+func (s *Service) GetCategoryTree(ctx context.Context) ([]CategoryTreeNode, error) {
+	dataCategories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to get categories from repository", "error", err)
+		return nil, err
+	}
+
+	children := make(map[int64][]*Category)
+	var roots []*Category
+	for _, dataCategory := range dataCategories {
+		c := FromDataModel(dataCategory)
+		if !c.IsActiveCategory() {
+			continue
+		}
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		} else {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		}
+	}
+
+	nodes := buildTree(roots, children, make(map[int64]bool))
+	if nodes == nil {
+		nodes = []CategoryTreeNode{}
+	}
+	return nodes, nil
+}
+
+func buildTree(level []*Category, children map[int64][]*Category, seen map[int64]bool) []CategoryTreeNode {
+	if len(level) == 0 {
+		return nil
+	}
+	sort.Slice(level, func(i, j int) bool { return level[i].Name < level[j].Name })
+
+	nodes := make([]CategoryTreeNode, 0, len(level))
+	for _, c := range level {
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		nodes = append(nodes, CategoryTreeNode{
+			Name:        c.Name,
+			Description: c.Description,
+			Children:    buildTree(children[c.ID], children, seen),
+		})
+	}
+	return nodes
+}
+
+// SetParent moves a category under parentName, or to the top level when
+// parentName is empty. Moves that would create a cycle are rejected.
+func (s *Service) SetParent(ctx context.Context, name, parentName string) error {
+	cat, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to load category: %w", err)
+	}
+	if cat == nil {
+		return ErrCategoryNotFound
+	}
+
+	var parentID *int64
+	if parentName != "" {
+		parent, err := s.repo.GetByName(ctx, parentName)
+		if err != nil {
+			return fmt.Errorf("failed to load parent category: %w", err)
+		}
+		if parent == nil {
+			return ErrCategoryNotFound
+		}
+
+		ancestors, err := s.repo.GetAncestorIDs(ctx, parent.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load category ancestors: %w", err)
+		}
+		if parent.ID == cat.ID || containsID(ancestors, cat.ID) {
+			return ErrCategoryCycle
+		}
+		parentID = &parent.ID
+	}
+
+	if err := s.repo.SetParent(ctx, cat.ID, parentID); err != nil {
+		return fmt.Errorf("failed to update category parent: %w", err)
+	}
+
+	s.logger.Info("category parent updated", "name", name, "parent", parentName)
+	return nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AncestorNames lists the parents of name, nearest first.
+func (s *Service) AncestorNames(ctx context.Context, name string) ([]string, error) {
+	cat, err := s.repo.GetByName(ctx, name)
+	if err != nil || cat == nil {
+		return nil, err
+	}
+
+	ids, err := s.repo.GetAncestorIDs(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		ancestor, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ancestor != nil {
+			names = append(names, ancestor.Name)
+		}
+	}
+	return names, nil
+}
@@ -1,10 +1,12 @@
 package category_test
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/frahmantamala/expense-management/internal/category"
 	categoryDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/category"
@@ -30,7 +32,7 @@ func NewMockRepository() *MockRepository {
 	}
 }
 
-func (m *MockRepository) GetAll() ([]*categoryDatamodel.ExpenseCategory, error) {
+func (m *MockRepository) GetAll(_ context.Context) ([]*categoryDatamodel.ExpenseCategory, error) {
 	if m.shouldFail {
 		return nil, m.failError
 	}
@@ -42,7 +44,7 @@ func (m *MockRepository) GetAll() ([]*categoryDatamodel.ExpenseCategory, error)
 	return result, nil
 }
 
-func (m *MockRepository) GetByName(name string) (*categoryDatamodel.ExpenseCategory, error) {
+func (m *MockRepository) GetByName(_ context.Context, name string) (*categoryDatamodel.ExpenseCategory, error) {
 	if m.shouldFail {
 		return nil, m.failError
 	}
@@ -54,7 +56,7 @@ func (m *MockRepository) GetByName(name string) (*categoryDatamodel.ExpenseCateg
 	return cat, nil
 }
 
-func (m *MockRepository) Create(cat *categoryDatamodel.ExpenseCategory) error {
+func (m *MockRepository) Create(_ context.Context, cat *categoryDatamodel.ExpenseCategory) error {
 	if m.shouldFail {
 		return m.failError
 	}
@@ -62,7 +64,7 @@ func (m *MockRepository) Create(cat *categoryDatamodel.ExpenseCategory) error {
 	return nil
 }
 
-func (m *MockRepository) Update(cat *categoryDatamodel.ExpenseCategory) error {
+func (m *MockRepository) Update(_ context.Context, cat *categoryDatamodel.ExpenseCategory) error {
 	if m.shouldFail {
 		return m.failError
 	}
@@ -70,7 +72,7 @@ func (m *MockRepository) Update(cat *categoryDatamodel.ExpenseCategory) error {
 	return nil
 }
 
-func (m *MockRepository) GetByID(id int64) (*categoryDatamodel.ExpenseCategory, error) {
+func (m *MockRepository) GetByID(_ context.Context, id int64) (*categoryDatamodel.ExpenseCategory, error) {
 	if m.shouldFail {
 		return nil, m.failError
 	}
@@ -82,7 +84,7 @@ func (m *MockRepository) GetByID(id int64) (*categoryDatamodel.ExpenseCategory,
 	return nil, nil
 }
 
-func (m *MockRepository) Delete(id int64) error {
+func (m *MockRepository) Delete(_ context.Context, id int64) error {
 	if m.shouldFail {
 		return m.failError
 	}
@@ -96,6 +98,53 @@ func (m *MockRepository) Delete(id int64) error {
 	return nil
 }
 
+func (m *MockRepository) GetAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
+	if m.shouldFail {
+		return nil, m.failError
+	}
+	var ids []int64
+	cat, _ := m.GetByID(ctx, id)
+	for cat != nil && cat.ParentID != nil && len(ids) <= len(m.categories) {
+		ids = append(ids, *cat.ParentID)
+		cat, _ = m.GetByID(ctx, *cat.ParentID)
+	}
+	return ids, nil
+}
+
+func (m *MockRepository) HasActiveChildren(_ context.Context, id int64) (bool, error) {
+	if m.shouldFail {
+		return false, m.failError
+	}
+	for _, cat := range m.categories {
+		if cat.IsActive && cat.ParentID != nil && *cat.ParentID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockRepository) GetRevision(_ context.Context) (int64, time.Time, error) {
+	if m.shouldFail {
+		return 0, time.Time{}, m.failError
+	}
+	var latest time.Time
+	for _, cat := range m.categories {
+		if cat.UpdatedAt.After(latest) {
+			latest = cat.UpdatedAt
+		}
+	}
+	return int64(len(m.categories)), latest, nil
+}
+
+func (m *MockRepository) SetParent(_ context.Context, id int64, parentID *int64) error {
+	if m.shouldFail {
+		return m.failError
+	}
+	cat, _ := m.GetByID(ctx, id)
+	cat.ParentID = parentID
+	return nil
+}
+
 func (m *MockRepository) SetShouldFail(shouldFail bool, err error) {
 	m.shouldFail = shouldFail
 	m.failError = err
@@ -106,6 +155,8 @@ func (m *MockRepository) AddCategory(cat *category.Category) {
 	m.categories[dataCategory.Name] = dataCategory
 }
 
+var ctx = context.Background()
+
 var _ = Describe("Category Service", func() {
 	var (
 		mockRepo *MockRepository
@@ -143,7 +194,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return only active categories", func() {
-				categories, err := service.GetAllCategories()
+				categories, err := service.GetAllCategories(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(categories).To(HaveLen(2))
 
@@ -155,7 +206,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return category responses with correct structure", func() {
-				categories, err := service.GetAllCategories()
+				categories, err := service.GetAllCategories(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(categories[0].Name).NotTo(BeEmpty())
 				Expect(categories[0].Description).NotTo(BeEmpty())
@@ -168,7 +219,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return error", func() {
-				categories, err := service.GetAllCategories()
+				categories, err := service.GetAllCategories(ctx)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("database error"))
 				Expect(categories).To(BeNil())
@@ -177,7 +228,7 @@ var _ = Describe("Category Service", func() {
 
 		Context("when repository is empty", func() {
 			It("should return empty slice", func() {
-				categories, err := service.GetAllCategories()
+				categories, err := service.GetAllCategories(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(categories).To(HaveLen(0))
 			})
@@ -196,7 +247,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return the category", func() {
-				result, err := service.GetCategoryByName("makan")
+				result, err := service.GetCategoryByName(ctx, "makan")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).NotTo(BeNil())
 				Expect(result.Name).To(Equal("makan"))
@@ -215,7 +266,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return nil", func() {
-				result, err := service.GetCategoryByName("inactive")
+				result, err := service.GetCategoryByName(ctx, "inactive")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).To(BeNil())
 			})
@@ -223,7 +274,7 @@ var _ = Describe("Category Service", func() {
 
 		Context("when category does not exist", func() {
 			It("should return nil", func() {
-				result, err := service.GetCategoryByName("nonexistent")
+				result, err := service.GetCategoryByName(ctx, "nonexistent")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).To(BeNil())
 			})
@@ -235,7 +286,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return error", func() {
-				result, err := service.GetCategoryByName("makan")
+				result, err := service.GetCategoryByName(ctx, "makan")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("connection error"))
 				Expect(result).To(BeNil())
@@ -255,14 +306,14 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return true", func() {
-				result := service.IsValidCategory("makan")
+				result := service.IsValidCategory(ctx, "makan")
 				Expect(result).To(BeTrue())
 			})
 		})
 
 		Context("when category does not exist", func() {
 			It("should return false", func() {
-				result := service.IsValidCategory("nonexistent")
+				result := service.IsValidCategory(ctx, "nonexistent")
 				Expect(result).To(BeFalse())
 			})
 		})
@@ -278,7 +329,7 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return false", func() {
-				result := service.IsValidCategory("inactive")
+				result := service.IsValidCategory(ctx, "inactive")
 				Expect(result).To(BeFalse())
 			})
 		})
@@ -289,9 +340,62 @@ var _ = Describe("Category Service", func() {
 			})
 
 			It("should return false and log warning", func() {
-				result := service.IsValidCategory("makan")
+				result := service.IsValidCategory(ctx, "makan")
 				Expect(result).To(BeFalse())
 			})
 		})
 	})
+
+	Describe("Category hierarchy", func() {
+		parentOf := func(id int64) *int64 { return &id }
+
+		BeforeEach(func() {
+			mockRepo.AddCategory(&category.Category{ID: 1, Name: "travel", IsActive: true})
+			mockRepo.AddCategory(&category.Category{ID: 2, Name: "hotels", ParentID: parentOf(1), IsActive: true})
+			mockRepo.AddCategory(&category.Category{ID: 3, Name: "flights", ParentID: parentOf(1), IsActive: true})
+			mockRepo.AddCategory(&category.Category{ID: 4, Name: "domestic", ParentID: parentOf(3), IsActive: true})
+			mockRepo.AddCategory(&category.Category{ID: 5, Name: "makan", IsActive: true})
+			mockRepo.AddCategory(&category.Category{ID: 6, Name: "retired", ParentID: parentOf(5), IsActive: false})
+		})
+
+		It("nests active categories under their parents", func() {
+			tree, err := service.GetCategoryTree(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tree).To(Equal([]category.CategoryTreeNode{
+				{Name: "makan"},
+				{Name: "travel", Children: []category.CategoryTreeNode{
+					{Name: "flights", Children: []category.CategoryTreeNode{{Name: "domestic"}}},
+					{Name: "hotels"},
+				}},
+			}))
+		})
+
+		It("treats only categories without active children as leaves", func() {
+			Expect(service.IsLeafCategory(ctx, "travel")).To(BeFalse())
+			Expect(service.IsLeafCategory(ctx, "flights")).To(BeFalse())
+			Expect(service.IsLeafCategory(ctx, "hotels")).To(BeTrue())
+			Expect(service.IsLeafCategory(ctx, "makan")).To(BeTrue())
+			Expect(service.IsLeafCategory(ctx, "nonexistent")).To(BeFalse())
+		})
+
+		It("moves a category under a new parent", func() {
+			Expect(service.SetParent(ctx, "hotels", "makan")).To(Succeed())
+			Expect(*mockRepo.categories["hotels"].ParentID).To(Equal(int64(5)))
+
+			Expect(service.SetParent(ctx, "hotels", "")).To(Succeed())
+			Expect(mockRepo.categories["hotels"].ParentID).To(BeNil())
+		})
+
+		It("rejects moves that would create a cycle", func() {
+			Expect(service.SetParent(ctx, "travel", "travel")).To(MatchError(category.ErrCategoryCycle))
+			Expect(service.SetParent(ctx, "travel", "domestic")).To(MatchError(category.ErrCategoryCycle))
+			Expect(mockRepo.categories["travel"].ParentID).To(BeNil())
+		})
+
+		It("rejects unknown categories", func() {
+			Expect(service.SetParent(ctx, "hotels", "nonexistent")).To(MatchError(category.ErrCategoryNotFound))
+			Expect(service.SetParent(ctx, "nonexistent", "travel")).To(MatchError(category.ErrCategoryNotFound))
+		})
+	})
 })
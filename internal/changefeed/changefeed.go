@@ -0,0 +1,114 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	changeDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/changefeed"
+)
+
+// Operations a change records.
+const (
+	OperationInsert = "insert"
+	OperationUpdate = "update"
+)
+
+var consumerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+var (
+	ErrConsumerNotFound = errors.NewNotFoundError("Change consumer not found", errors.ErrCodeChangeConsumerNotFound)
+	ErrInvalidConsumer  = errors.NewValidationFieldError("consumer", "consumer must be 1-64 lowercase letters, digits, - or _", errors.ErrCodeValidationFailed)
+	ErrInvalidOffset    = errors.NewValidationFieldError("offset", "offset must not be negative", errors.ErrCodeValidationFailed)
+)
+
+// Change is an expense or payment insert or update, in the order it was
+// written. Data is the row after the change; Diff maps each changed column
+// to its old and new value and is only set for updates.
+type Change struct {
+	ID        int64           `json:"id"`
+	Table     string          `json:"table" enums:"expenses,payments"`
+	RowID     int64           `json:"row_id"`
+	Operation string          `json:"operation" enums:"insert,update"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	Diff      json.RawMessage `json:"diff,omitempty" swaggertype:"object"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// ListQuery reads the changes after After, or after the offset Consumer
+// last committed when After is not set.
+type ListQuery struct {
+	Consumer string
+	After    *int64
+	Limit    int
+}
+
+func (q *ListQuery) SetDefaults() {
+	if q.Limit <= 0 || q.Limit > 1000 {
+		q.Limit = 500
+	}
+}
+
+// ParseFromRequest reads the query string, ignoring values that do not
+// parse.
+func (q *ListQuery) ParseFromRequest(r *http.Request) {
+	query := r.URL.Query()
+
+	q.Consumer = query.Get("consumer")
+	if after, err := strconv.ParseInt(query.Get("after"), 10, 64); err == nil && after >= 0 {
+		q.After = &after
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil {
+		q.Limit = limit
+	}
+
+	q.SetDefaults()
+}
+
+// ChangesResponse is one page of the feed. A consumer commits NextOffset
+// once it has stored the changes, and reads again while HasMore is set.
+type ChangesResponse struct {
+	Changes    []*Change `json:"changes"`
+	NextOffset int64     `json:"next_offset"`
+	HasMore    bool      `json:"has_more"`
+}
+
+// Consumer is how far a consumer has read.
+type Consumer struct {
+	Name      string    `json:"name"`
+	Offset    int64     `json:"offset"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CommitDTO moves a consumer to Offset, the ID of the last change it has
+// stored. Moving it back replays the changes after the new offset.
+type CommitDTO struct {
+	Offset int64 `json:"offset"`
+}
+
+func validConsumerName(name string) bool {
+	return consumerNamePattern.MatchString(name)
+}
+
+func fromDataModel(c *changeDatamodel.Change) *Change {
+	return &Change{
+		ID:        c.ID,
+		Table:     c.SourceTable,
+		RowID:     c.RowID,
+		Operation: c.Operation,
+		Data:      c.Data,
+		Diff:      c.Diff,
+		ChangedAt: c.ChangedAt,
+	}
+}
+
+func consumerFromDataModel(c *changeDatamodel.Consumer) *Consumer {
+	return &Consumer{
+		Name:      c.Name,
+		Offset:    c.LastChangeID,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package changefeed_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestChangefeed(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Changefeed Suite")
+}
@@ -0,0 +1,105 @@
+package changefeed
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	List(ctx context.Context, q ListQuery) (*ChangesResponse, error)
+	GetConsumer(ctx context.Context, name string) (*Consumer, error)
+	Commit(ctx context.Context, name string, dto CommitDTO) (*Consumer, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListChanges godoc
+// @Summary      Read the change feed
+// @Description  Inserts and updates of expenses and payments, oldest first, for warehouses to sync incrementally. Pass after, or consumer to resume from its committed offset; then commit next_offset with PUT /admin/changes/consumers/{name}. Changes appear about 10 seconds after they are written. Requires admin.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        consumer  query     string  false  "Consumer whose committed offset to resume from"
+// @Param        after     query     int     false  "Return changes with an ID above this; overrides consumer"
+// @Param        limit     query     int     false  "Changes per page, at most 1000"
+// @Success      200       {object}  ChangesResponse
+// @Failure      400       {object}  transport.AppErrorResponse
+// @Failure      401       {object}  transport.ErrorResponse
+// @Failure      403       {object}  transport.ErrorResponse
+// @Router       /admin/changes [get]
+func (h *Handler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	var q ListQuery
+	q.ParseFromRequest(r)
+
+	resp, err := h.Service.List(r.Context(), q)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetConsumer godoc
+// @Summary      Get a change consumer's offset
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Consumer name"
+// @Success      200   {object}  Consumer
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /admin/changes/consumers/{name} [get]
+func (h *Handler) GetConsumer(w http.ResponseWriter, r *http.Request) {
+	consumer, err := h.Service.GetConsumer(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, consumer)
+}
+
+// CommitOffset godoc
+// @Summary      Commit a change consumer's offset
+// @Description  Creates the consumer on its first commit. A lower offset than the current one replays the changes after it.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string     true  "Consumer name"
+// @Param        body  body      CommitDTO  true  "ID of the last change stored"
+// @Success      200   {object}  Consumer
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Router       /admin/changes/consumers/{name} [put]
+func (h *Handler) CommitOffset(w http.ResponseWriter, r *http.Request) {
+	var dto CommitDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	consumer, err := h.Service.Commit(r.Context(), chi.URLParam(r, "name"), dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, consumer)
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/changefeed"
+	changeDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/changefeed"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewChangeRepository(db *gorm.DB) changefeed.RepositoryAPI {
+	return &ChangeRepository{db: db}
+}
+
+func (r *ChangeRepository) ListAfter(ctx context.Context, afterID int64, before time.Time, limit int) ([]*changeDatamodel.Change, error) {
+	var changes []*changeDatamodel.Change
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND changed_at < ?", afterID, before).
+		Order("id").
+		Limit(limit).
+		Find(&changes).Error
+	return changes, err
+}
+
+func (r *ChangeRepository) GetConsumer(ctx context.Context, name string) (*changeDatamodel.Consumer, error) {
+	var consumer changeDatamodel.Consumer
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&consumer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, changefeed.ErrConsumerNotFound
+		}
+		return nil, err
+	}
+	return &consumer, nil
+}
+
+func (r *ChangeRepository) SaveOffset(ctx context.Context, name string, offset int64) (*changeDatamodel.Consumer, error) {
+	consumer := &changeDatamodel.Consumer{Name: name, LastChangeID: offset}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_change_id", "updated_at"}),
+	}).Create(consumer).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetConsumer(ctx, name)
+}
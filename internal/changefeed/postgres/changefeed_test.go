@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/changefeed"
+	changeDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/changefeed"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestChangeRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ChangeRepository Suite")
+}
+
+var _ = Describe("ChangeRepository", func() {
+	var (
+		db   *gorm.DB
+		repo changefeed.RepositoryAPI
+		ctx  context.Context
+		now  time.Time
+	)
+
+	record := func(ctx context.Context, rowID int64, changedAt time.Time) *changeDatamodel.Change {
+		change := &changeDatamodel.Change{
+			SourceTable: "expenses",
+			RowID:       rowID,
+			Operation:   changefeed.OperationInsert,
+			Data:        json.RawMessage(`{"id":1}`),
+			ChangedAt:   changedAt,
+		}
+		Expect(db.WithContext(ctx).Create(change).Error).To(Succeed())
+		return change
+	}
+
+	BeforeEach(func() {
+		var err error
+		db, err = testdb.Open(&changeDatamodel.Change{}, &changeDatamodel.Consumer{})
+		Expect(err).NotTo(HaveOccurred())
+
+		repo = NewChangeRepository(db)
+		ctx = tenant.NewContext(context.Background(), 2)
+		now = time.Date(2025, 11, 2, 9, 0, 0, 0, time.UTC)
+	})
+
+	It("lists changes after an ID written before the cut-off, in order", func() {
+		first := record(ctx, 1, now.Add(-time.Minute))
+		second := record(ctx, 2, now.Add(-time.Minute))
+		record(ctx, 3, now)
+		record(tenant.NewContext(context.Background(), 3), 4, now.Add(-time.Minute))
+
+		changes, err := repo.ListAfter(ctx, 0, now, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].ID).To(Equal(first.ID))
+		Expect(changes[1].ID).To(Equal(second.ID))
+
+		changes, err = repo.ListAfter(ctx, first.ID, now, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].ID).To(Equal(second.ID))
+	})
+
+	It("creates a consumer on its first commit and moves it afterwards", func() {
+		_, err := repo.GetConsumer(ctx, "warehouse")
+		Expect(err).To(Equal(changefeed.ErrConsumerNotFound))
+
+		consumer, err := repo.SaveOffset(ctx, "warehouse", 5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(consumer.LastChangeID).To(Equal(int64(5)))
+		Expect(consumer.TenantID).To(Equal(int64(2)))
+
+		consumer, err = repo.SaveOffset(ctx, "warehouse", 9)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(consumer.LastChangeID).To(Equal(int64(9)))
+
+		_, err = repo.GetConsumer(tenant.NewContext(context.Background(), 3), "warehouse")
+		Expect(err).To(Equal(changefeed.ErrConsumerNotFound))
+	})
+})
@@ -0,0 +1,115 @@
+package changefeed
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	changeDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/changefeed"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// settleDelay holds back changes younger than it. IDs are assigned when a
+// row is written, not when its transaction commits, so a change may become
+// visible after one with a higher ID; reading only settled changes keeps a
+// consumer from moving past it.
+const settleDelay = 10 * time.Second
+
+// RepositoryAPI reads the feed and the consumer offsets of the tenant ctx is
+// scoped to.
+type RepositoryAPI interface {
+	// ListAfter returns up to limit changes with an ID above afterID written
+	// before before, in ID order.
+	ListAfter(ctx context.Context, afterID int64, before time.Time, limit int) ([]*changeDatamodel.Change, error)
+	// GetConsumer returns ErrConsumerNotFound for a name never committed.
+	GetConsumer(ctx context.Context, name string) (*changeDatamodel.Consumer, error)
+	// SaveOffset creates the consumer or moves it to offset.
+	SaveOffset(ctx context.Context, name string, offset int64) (*changeDatamodel.Consumer, error)
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// List returns the settled changes after q.After, or after the consumer's
+// committed offset; a consumer that never committed starts from the
+// beginning. Listing does not move the offset.
+func (s *Service) List(ctx context.Context, q ListQuery) (*ChangesResponse, error) {
+	q.SetDefaults()
+
+	var after int64
+	switch {
+	case q.After != nil:
+		after = *q.After
+	case q.Consumer != "":
+		if !validConsumerName(q.Consumer) {
+			return nil, ErrInvalidConsumer
+		}
+		consumer, err := s.repo.GetConsumer(ctx, q.Consumer)
+		if err != nil && err != ErrConsumerNotFound {
+			return nil, err
+		}
+		if consumer != nil {
+			after = consumer.LastChangeID
+		}
+	}
+
+	// One extra row tells whether another page follows.
+	rows, err := s.repo.ListAfter(ctx, after, s.now().Add(-settleDelay), q.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ChangesResponse{Changes: make([]*Change, 0, len(rows)), NextOffset: after}
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		resp.HasMore = true
+	}
+	for _, row := range rows {
+		resp.Changes = append(resp.Changes, fromDataModel(row))
+		resp.NextOffset = row.ID
+	}
+	return resp, nil
+}
+
+func (s *Service) GetConsumer(ctx context.Context, name string) (*Consumer, error) {
+	if !validConsumerName(name) {
+		return nil, ErrInvalidConsumer
+	}
+	consumer, err := s.repo.GetConsumer(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return consumerFromDataModel(consumer), nil
+}
+
+// Commit stores the offset a consumer resumes from.
+func (s *Service) Commit(ctx context.Context, name string, dto CommitDTO) (*Consumer, error) {
+	if !validConsumerName(name) {
+		return nil, ErrInvalidConsumer
+	}
+	if dto.Offset < 0 {
+		return nil, ErrInvalidOffset
+	}
+
+	consumer, err := s.repo.SaveOffset(ctx, name, dto.Offset)
+	if err != nil {
+		return nil, err
+	}
+	s.log(ctx).Info("change consumer committed", "consumer", name, "offset", dto.Offset)
+	return consumerFromDataModel(consumer), nil
+}
@@ -0,0 +1,117 @@
+package changefeed_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/changefeed"
+	changeDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/changefeed"
+)
+
+type mockRepository struct {
+	changes   []*changeDatamodel.Change
+	consumers map[string]*changeDatamodel.Consumer
+	before    time.Time
+}
+
+func (m *mockRepository) ListAfter(_ context.Context, afterID int64, before time.Time, limit int) ([]*changeDatamodel.Change, error) {
+	m.before = before
+	var out []*changeDatamodel.Change
+	for _, c := range m.changes {
+		if c.ID > afterID && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) GetConsumer(_ context.Context, name string) (*changeDatamodel.Consumer, error) {
+	consumer, ok := m.consumers[name]
+	if !ok {
+		return nil, changefeed.ErrConsumerNotFound
+	}
+	return consumer, nil
+}
+
+func (m *mockRepository) SaveOffset(_ context.Context, name string, offset int64) (*changeDatamodel.Consumer, error) {
+	consumer := &changeDatamodel.Consumer{Name: name, LastChangeID: offset}
+	m.consumers[name] = consumer
+	return consumer, nil
+}
+
+var _ = Describe("Changefeed service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *changefeed.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{consumers: map[string]*changeDatamodel.Consumer{}}
+		for id := int64(1); id <= 5; id++ {
+			repo.changes = append(repo.changes, &changeDatamodel.Change{ID: id, SourceTable: "expenses", RowID: id, Operation: changefeed.OperationInsert})
+		}
+		service = changefeed.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("pages through changes and reports where to resume", func() {
+		resp, err := service.List(ctx, changefeed.ListQuery{Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Changes).To(HaveLen(2))
+		Expect(resp.NextOffset).To(Equal(int64(2)))
+		Expect(resp.HasMore).To(BeTrue())
+
+		after := resp.NextOffset
+		resp, err = service.List(ctx, changefeed.ListQuery{After: &after, Limit: 5})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Changes).To(HaveLen(3))
+		Expect(resp.NextOffset).To(Equal(int64(5)))
+		Expect(resp.HasMore).To(BeFalse())
+	})
+
+	It("holds back changes that may not have settled", func() {
+		_, err := service.List(ctx, changefeed.ListQuery{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo.before).To(BeTemporally("~", time.Now().Add(-10*time.Second), time.Second))
+	})
+
+	It("resumes a consumer from its committed offset", func() {
+		_, err := service.Commit(ctx, "warehouse", changefeed.CommitDTO{Offset: 3})
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := service.List(ctx, changefeed.ListQuery{Consumer: "warehouse"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Changes).To(HaveLen(2))
+		Expect(resp.Changes[0].ID).To(Equal(int64(4)))
+
+		resp, err = service.List(ctx, changefeed.ListQuery{Consumer: "new-sink"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Changes).To(HaveLen(5))
+		Expect(resp.NextOffset).To(Equal(int64(5)))
+	})
+
+	It("keeps the offset when a page is empty", func() {
+		after := int64(5)
+		resp, err := service.List(ctx, changefeed.ListQuery{After: &after})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Changes).To(BeEmpty())
+		Expect(resp.NextOffset).To(Equal(int64(5)))
+	})
+
+	It("rejects invalid consumer names and offsets", func() {
+		_, err := service.List(ctx, changefeed.ListQuery{Consumer: "Warehouse!"})
+		Expect(err).To(Equal(changefeed.ErrInvalidConsumer))
+
+		_, err = service.Commit(ctx, "warehouse", changefeed.CommitDTO{Offset: -1})
+		Expect(err).To(Equal(changefeed.ErrInvalidOffset))
+
+		_, err = service.GetConsumer(ctx, "warehouse")
+		Expect(err).To(Equal(changefeed.ErrConsumerNotFound))
+	})
+})
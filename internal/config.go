@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/encryption"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
 )
 
 type Config struct {
@@ -16,6 +20,26 @@ type Config struct {
 	Security      SecurityConfig      `mapstructure:"security" validate:"required"`
 	Observability ObservabilityConfig `mapstructure:"observability"`
 	Payment       PaymentConfig       `mapstructure:"payment"`
+	Notification  NotificationConfig  `mapstructure:"notification"`
+	Storage       StorageConfig       `mapstructure:"storage"`
+	Export        ExportConfig        `mapstructure:"export"`
+	Import        ImportConfig        `mapstructure:"import"`
+	Thumbnails    ThumbnailsConfig    `mapstructure:"thumbnails"`
+	CardFeed      CardFeedConfig      `mapstructure:"card_feed"`
+	Reports       ReportsConfig       `mapstructure:"reports"`
+	Calendar      CalendarConfig      `mapstructure:"calendar"`
+	Limits        LimitsConfig        `mapstructure:"spending_limits"`
+	Encryption    EncryptionConfig    `mapstructure:"encryption"`
+	Tenants       TenantsConfig       `mapstructure:"tenants"`
+	SCIM          SCIMConfig          `mapstructure:"scim"`
+	Slack         SlackConfig         `mapstructure:"slack"`
+	Integrations  IntegrationsConfig  `mapstructure:"integrations"`
+	Introspection IntrospectionConfig `mapstructure:"introspection"`
+	SharedLinks   SharedLinksConfig   `mapstructure:"shared_links"`
+	Login         LoginConfig         `mapstructure:"login"`
+	Scheduler     SchedulerConfig     `mapstructure:"scheduler"`
+	HTTPClient    HTTPClientConfig    `mapstructure:"http_client"`
+	Retention     RetentionConfig     `mapstructure:"retention"`
 }
 
 type ServerConfig struct {
@@ -26,6 +50,14 @@ type ServerConfig struct {
 	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
 	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
 	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	// RequestTimeout is the deadline put on each request's context; zero
+	// disables it. It must stay below WriteTimeout so the timeout response
+	// can still be written.
+	RequestTimeout          time.Duration `mapstructure:"request_timeout"`
+	RequestTimeoutSkipPaths []string      `mapstructure:"request_timeout_skip_paths"`
+	// SlowRequestThreshold logs requests slower than it with their spans;
+	// zero disables slow-request logging.
+	SlowRequestThreshold time.Duration `mapstructure:"slow_request_threshold"`
 }
 
 type DatabaseConfig struct {
@@ -41,22 +73,498 @@ type SecurityConfig struct {
 	RefreshTokenDuration time.Duration `mapstructure:"refresh_token_duration" validate:"required,min=1h"`
 	BCryptCost           int           `mapstructure:"bcrypt_cost" validate:"required,min=10,max=15"`
 	SessionSecret        string        `mapstructure:"session_secret" validate:"required,min=32"`
+	// RefreshGracePeriod is how long after expiry an access token is still
+	// accepted, with an X-Token-Refresh hint; zero accepts none. Within the
+	// same period before expiry the hint recommends refreshing.
+	RefreshGracePeriod time.Duration `mapstructure:"refresh_grace_period"`
+	// Issuer and Audience are the iss and aud claims every token carries
+	// and must match; empty leaves the claim out and unchecked.
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// ClockSkew is how far token expiry may be off between servers.
+	ClockSkew time.Duration `mapstructure:"clock_skew"`
+	// ImpersonationTTL is how long a token an administrator impersonates a
+	// user with lasts; zero turns impersonation off.
+	ImpersonationTTL time.Duration `mapstructure:"impersonation_ttl"`
 }
 
 type PaymentConfig struct {
-	MockAPIURL     string        `mapstructure:"mock_api_url" validate:"required,url"`
+	MockAPIURL     string        `mapstructure:"mock_api_url" validate:"required_without=Providers,omitempty,url"`
 	APIKey         string        `mapstructure:"api_key"`
 	PaymentTimeout time.Duration `mapstructure:"payment_timeout" validate:"required,min=1s"`
 	WebhookURL     string        `mapstructure:"webhook_url" validate:"omitempty,url"`
+	MinWorkers     int           `mapstructure:"min_workers" validate:"min=0,max=100"`
 	MaxWorkers     int           `mapstructure:"max_workers" validate:"min=1,max=100"`
 	JobQueueSize   int           `mapstructure:"job_queue_size" validate:"min=10,max=10000"`
 	WorkerPoolSize int           `mapstructure:"worker_pool_size" validate:"min=1,max=100"`
+	// OverflowStrategy is one of block, spill or shed.
+	OverflowStrategy string        `mapstructure:"overflow_strategy" validate:"omitempty,oneof=block spill shed"`
+	EnqueueTimeout   time.Duration `mapstructure:"enqueue_timeout"`
+	ScaleInterval    time.Duration `mapstructure:"scale_interval"`
+	// PoisonThreshold is how many times one payment job may crash a worker
+	// before it is dead-lettered rather than re-queued.
+	PoisonThreshold int `mapstructure:"poison_threshold" validate:"min=0,max=100"`
+	// WebhookSecret is shared with the gateway. Every callback to the
+	// webhook, the gateway's and the workers' own, is signed with it, and
+	// callbacks that are not are refused.
+	WebhookSecret string `mapstructure:"webhook_secret"`
+	// Driver is gateway (the gateway calls back on its own), mock (simulated
+	// settlement) or sandbox (an in-process fake gateway). mock and sandbox
+	// are unavailable in production builds.
+	Driver string `mapstructure:"driver" validate:"omitempty,oneof=gateway mock sandbox"`
+	// ResponseVersion is the shape of the gateway's answers: v1 wraps the
+	// payment in a data envelope, v2 does not. Empty means v1.
+	ResponseVersion string             `mapstructure:"response_version"`
+	Mock            MockGatewayConfig  `mapstructure:"mock"`
+	Inbox           WebhookInboxConfig `mapstructure:"webhook_inbox"`
+	// Providers, when set, replace mock_api_url, api_key, driver and
+	// response_version with an
+	// ordered failover chain.
+	Providers []PaymentProviderConfig `mapstructure:"providers"`
+	Failover  PaymentFailoverConfig   `mapstructure:"failover"`
+	Recorder  PaymentRecorderConfig   `mapstructure:"recorder"`
+	CallLog   PaymentCallLogConfig    `mapstructure:"call_log"`
+	Shadow    PaymentShadowConfig     `mapstructure:"shadow"`
+	Budgets   PaymentBudgetConfig     `mapstructure:"latency_budgets"`
+	Hedge     PaymentHedgeConfig      `mapstructure:"hedge"`
+}
+
+// PaymentBudgetConfig bounds each gateway endpoint; zero falls back to
+// payment_timeout.
+type PaymentBudgetConfig struct {
+	Initiate time.Duration `mapstructure:"initiate"`
+	Status   time.Duration `mapstructure:"status"`
+}
+
+// PaymentHedgeConfig hedges status checks: after Delay without an answer a
+// second request is sent and the first answer wins. Zero disables it.
+type PaymentHedgeConfig struct {
+	Delay time.Duration `mapstructure:"delay"`
+}
+
+// PaymentShadowConfig turns on shadow mode when APIURL is set: every payment
+// is also initiated with this gateway, normally in its test mode, and the
+// answers are compared with the live gateway's. Shadow answers are never
+// applied to payments.
+type PaymentShadowConfig struct {
+	Name            string `mapstructure:"name"`
+	APIURL          string `mapstructure:"api_url" validate:"omitempty,url"`
+	APIKey          string `mapstructure:"api_key"`
+	ResponseVersion string `mapstructure:"response_version"`
+	// WebhookURL is the callback URL sent to the shadow gateway. It must not
+	// be the payment webhook; empty asks for no callback.
+	WebhookURL string `mapstructure:"webhook_url" validate:"omitempty,url"`
+}
+
+// PaymentRecorderConfig records gateway requests and responses to a cassette
+// file, or answers them from one without calling the gateway. An empty mode
+// turns it off. Unavailable in production builds.
+type PaymentRecorderConfig struct {
+	// Mode is record or replay.
+	Mode string `mapstructure:"mode" validate:"omitempty,oneof=record replay"`
+	Path string `mapstructure:"path"`
+}
+
+// PaymentCallLogConfig stores every request sent to the gateways and the
+// answer in gateway_calls, secrets redacted, for disputes with a provider.
+type PaymentCallLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Retention is how long calls are kept; zero keeps them forever.
+	Retention time.Duration `mapstructure:"retention"`
+	// MaxBodyBytes cuts longer request and response bodies short; zero
+	// means 64KiB.
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// PurgeSchedule is when calls past their retention are deleted, a cron
+	// expression in UTC or @every <duration>.
+	PurgeSchedule string `mapstructure:"purge_schedule"`
+}
+
+// PaymentProviderConfig is one gateway in the failover chain. Driver takes
+// the same values as PaymentConfig.Driver; mock providers share its mock
+// settings.
+type PaymentProviderConfig struct {
+	Name            string `mapstructure:"name"`
+	Driver          string `mapstructure:"driver"`
+	APIURL          string `mapstructure:"api_url"`
+	APIKey          string `mapstructure:"api_key"`
+	ResponseVersion string `mapstructure:"response_version"`
+}
+
+// PaymentFailoverConfig controls when a provider is skipped. Zero values fall
+// back to 5 consecutive failures and 30s.
+type PaymentFailoverConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold" validate:"min=0"`
+	OpenDuration     time.Duration `mapstructure:"open_duration"`
+}
+
+// WebhookInboxConfig controls the durable inbox gateway callbacks are stored
+// in before they are applied. Zero values fall back to 1s polling, 10
+// attempts and 5s backoff doubling up to 1h.
+type WebhookInboxConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	MaxAttempts  int           `mapstructure:"max_attempts" validate:"min=0"`
+	BaseBackoff  time.Duration `mapstructure:"base_backoff"`
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+}
+
+type MockGatewayConfig struct {
+	// Mode is random, success or failure.
+	Mode        string        `mapstructure:"mode"`
+	SuccessRate float64       `mapstructure:"success_rate" validate:"min=0,max=1"`
+	MinDelay    time.Duration `mapstructure:"min_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+}
+
+type NotificationConfig struct {
+	Mailer      MailerConfig      `mapstructure:"mailer"`
+	Digest      DigestConfig      `mapstructure:"digest"`
+	ActionLinks ActionLinksConfig `mapstructure:"action_links"`
+}
+
+type MailerConfig struct {
+	// Driver is smtp or log; log only writes the message to the application log.
+	Driver   string `mapstructure:"driver" validate:"omitempty,oneof=smtp log"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+type DigestConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Hour (UTC) at which the daily manager digest and the weekly employee digest go out.
+	Hour int `mapstructure:"hour" validate:"min=0,max=23"`
+	// WeeklyDay is the weekday name the employee digest is sent on.
+	WeeklyDay string `mapstructure:"weekly_day"`
+}
+
+// ActionLinksConfig controls the one-click approve/reject links in manager
+// digests.
+type ActionLinksConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TTL is how long a link stays valid; at most 7 days, 72h when zero.
+	TTL time.Duration `mapstructure:"ttl" validate:"max=168h"`
+	// SigningKey signs the links. It is required when enabled and must
+	// differ from the session secret, so a leaked link key cannot mint
+	// sessions and the other way round.
+	SigningKey string `mapstructure:"signing_key"`
+}
+
+// StorageConfig selects where receipts and export files are kept.
+type StorageConfig struct {
+	// Driver is local, s3, gcs or minio.
+	Driver string `mapstructure:"driver" validate:"omitempty,oneof=local s3 gcs minio"`
+	// LocalRoot is the directory the local driver writes to.
+	LocalRoot string `mapstructure:"local_root"`
+	// SigningKey signs the local driver's download links; defaults to the
+	// session secret.
+	SigningKey      string `mapstructure:"signing_key"`
+	Bucket          string `mapstructure:"bucket"`
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	UsePathStyle    bool   `mapstructure:"use_path_style"`
+}
+
+// ExportConfig tunes background exports; zero durations fall back to 5s
+// polling, 7 day retention and 1 hour links.
+type ExportConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Retention is how long finished export files are kept.
+	Retention time.Duration `mapstructure:"retention"`
+	// LinkExpiry bounds each signed download link; at most 7 days.
+	LinkExpiry time.Duration `mapstructure:"link_expiry" validate:"max=168h"`
+}
+
+// ImportConfig tunes CSV expense imports; zero values fall back to 5s
+// polling, 256 KiB inline imports and 50 MiB files.
+type ImportConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// SyncMaxBytes is the largest file imported within the request; larger
+	// files are queued for the background worker.
+	SyncMaxBytes int64 `mapstructure:"sync_max_bytes"`
+	MaxFileBytes int64 `mapstructure:"max_file_bytes"`
+}
+
+// ThumbnailsConfig tunes the receipt previews shown in the approval UI;
+// zero values fall back to 10s polling and 320px previews.
+type ThumbnailsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Size is the longest side of a preview in pixels.
+	Size int `mapstructure:"size"`
+	// PDFRenderer is the pdftoppm binary that renders the first page of PDF
+	// receipts. PDFs get no preview when it is empty or not installed.
+	PDFRenderer string `mapstructure:"pdf_renderer"`
+}
+
+// CardFeedConfig tunes corporate card feed imports; zero values fall back
+// to 10 MiB files and a 3 day match window.
+type CardFeedConfig struct {
+	MaxFileBytes int64 `mapstructure:"max_file_bytes"`
+	// MatchWindowDays is how far an expense date may be from the card
+	// transaction date for the two to match.
+	MatchWindowDays int `mapstructure:"match_window_days"`
+}
+
+// ReportsConfig tunes the worker that sends scheduled reports; a zero
+// PollInterval falls back to one minute.
+type ReportsConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// ApprovalSLA is the time within which expenses should be approved;
+	// the approval SLA report counts the approvals that met it.
+	ApprovalSLA time.Duration `mapstructure:"approval_sla" validate:"min=0"`
+}
+
+// CalendarConfig is the working week working time is measured in and
+// working days are counted by. Tenant admins add their own holidays and
+// working days through the admin API.
+type CalendarConfig struct {
+	// TimeZone decides which day an instant falls on.
+	TimeZone string `mapstructure:"time_zone"`
+	// Weekend lists the days off every week by English name.
+	Weekend []string `mapstructure:"weekend"`
+	// NationalHolidays makes Indonesia's public holidays days off.
+	NationalHolidays bool `mapstructure:"national_holidays"`
+}
+
+// LimitsConfig caps each user's spending by expense date; zero disables a
+// cap. Admins can raise a user's limit for a single day or month.
+type LimitsConfig struct {
+	DailyIDR   int64 `mapstructure:"daily_idr" validate:"min=0"`
+	MonthlyIDR int64 `mapstructure:"monthly_idr" validate:"min=0"`
+}
+
+// TenantsConfig holds the settings every tenant starts with; tenant admins
+// can override them through the admin API.
+type TenantsConfig struct {
+	// AutoApprovalThresholdIDR approves expenses below it on submission;
+	// nil keeps the built-in 1,000,000 IDR and zero disables auto-approval.
+	AutoApprovalThresholdIDR *int64   `mapstructure:"auto_approval_threshold_idr"`
+	Currency                 string   `mapstructure:"currency"`
+	ApprovalChain            []string `mapstructure:"approval_chain"`
+	// NotificationChannels defaults to email when unset.
+	NotificationChannels []string `mapstructure:"notification_channels"`
+	// ReceiptRequiredAboveIDR makes receipts mandatory on expenses above it;
+	// zero leaves them optional.
+	ReceiptRequiredAboveIDR int64 `mapstructure:"receipt_required_above_idr"`
+	// MaxPendingExpenses caps the expenses each user can have waiting for
+	// approval at once; zero leaves it uncapped.
+	MaxPendingExpenses int64 `mapstructure:"max_pending_expenses"`
+	// MinExpenseAmountIDR and MaxExpenseAmountIDR bound each claim; zero
+	// keeps the built-in 10,000 and 50,000,000 IDR.
+	MinExpenseAmountIDR int64 `mapstructure:"min_expense_amount_idr"`
+	MaxExpenseAmountIDR int64 `mapstructure:"max_expense_amount_idr"`
+	// CacheTTL is how long each tenant's settings are cached; 0 means 1m.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+// SCIMConfig enables SCIM 2.0 provisioning at /scim/v2. Identity providers
+// authenticate with APIKey and provision users into Tenant.
+type SCIMConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	APIKey  string `mapstructure:"api_key"`
+	// Tenant is the slug of the tenant users are provisioned into.
+	Tenant string `mapstructure:"tenant"`
+	// GroupPermissions maps identity provider groups to the permissions
+	// their members get.
+	GroupPermissions map[string][]string `mapstructure:"group_permissions"`
+}
+
+// SlackConfig enables the Slack app: the /expenses slash command, approve
+// and reject buttons and payment failure alerts. Slack users are matched to
+// users by the email on their Slack profile.
+type SlackConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SigningSecret verifies that commands and interactions come from Slack.
+	SigningSecret string `mapstructure:"signing_secret"`
+	BotToken      string `mapstructure:"bot_token"`
+	// AlertChannel is the channel ID payment failures are posted to; empty
+	// disables the alerts.
+	AlertChannel string `mapstructure:"alert_channel"`
+	// APIURL overrides the Slack Web API base URL, for tests.
+	APIURL string `mapstructure:"api_url"`
+}
+
+// IntegrationsConfig lists the external workflow engines allowed to decide
+// expenses through /api/v1/integrations/approvals. No clients disables the
+// endpoint.
+type IntegrationsConfig struct {
+	Clients []IntegrationClientConfig `mapstructure:"clients"`
+}
+
+type IntegrationClientConfig struct {
+	// Name identifies the client in the audit log.
+	Name string `mapstructure:"name"`
+	// APIKey is sent as a bearer token and picks the client.
+	APIKey string `mapstructure:"api_key"`
+	// SigningSecret signs each request body.
+	SigningSecret string `mapstructure:"signing_secret"`
+	// User is the email of the service account the client acts as; its
+	// tenant and permissions apply.
+	User string `mapstructure:"user"`
+}
+
+// IntrospectionConfig lists the sibling services allowed to validate access
+// tokens through /api/v1/auth/introspect. No clients disables the endpoint.
+type IntrospectionConfig struct {
+	Clients []IntrospectionClientConfig `mapstructure:"clients"`
+	// CacheTTL is how long clients may cache an active token's answer; it
+	// never outlives the token.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+type IntrospectionClientConfig struct {
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+}
+
+// SharedLinksConfig controls the capability links that share one receipt or
+// export without a bearer token.
+type SharedLinksConfig struct {
+	// TTL is how long a link stays valid; at most 24h, 15m when zero.
+	TTL time.Duration `mapstructure:"ttl"`
+	// SigningKey signs the links; defaults to the session secret.
+	SigningKey string `mapstructure:"signing_key"`
+}
+
+// LoginConfig throttles failed logins. After CaptchaAfter failures from a
+// client IP or for an email within Window, logins need a CAPTCHA token; after
+// LockAfter failures from an IP, that IP is refused until the window ends.
+// Zero disables a step.
+type LoginConfig struct {
+	// Window is how long failures are counted; 0 means 15m.
+	Window       time.Duration `mapstructure:"window"`
+	CaptchaAfter int           `mapstructure:"captcha_after" validate:"min=0"`
+	LockAfter    int           `mapstructure:"lock_after" validate:"min=0"`
+	Captcha      CaptchaConfig `mapstructure:"captcha"`
+	// NewLoginAlerts emails users when they log in from a device or location
+	// they have not logged in from before.
+	NewLoginAlerts bool      `mapstructure:"new_login_alerts"`
+	Geo            GeoConfig `mapstructure:"geo"`
+}
+
+// CaptchaConfig selects the CAPTCHA provider whose tokens logins are checked
+// against; without a provider the CAPTCHA step is skipped.
+type CaptchaConfig struct {
+	// Provider is hcaptcha or turnstile.
+	Provider string `mapstructure:"provider" validate:"omitempty,oneof=hcaptcha turnstile"`
+	Secret   string `mapstructure:"secret"`
+}
+
+// GeoConfig locates the IP address of each login. Without a URL, logins
+// are recorded without a location and never count as a new location.
+type GeoConfig struct {
+	// URL is a JSON lookup API answering country, region and city, with
+	// {ip} where the address goes, e.g. https://ipinfo.io/{ip}/json.
+	URL     string        `mapstructure:"url"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// RetentionConfig purges records kept for a while only: audit log entries,
+// and the raw payloads payment gateways sent, which can carry bank details.
+// A zero period keeps them forever; gateway_calls has its own retention
+// under payment.call_log.
+type RetentionConfig struct {
+	AuditLogs time.Duration `mapstructure:"audit_logs"`
+	// GatewayPayloads covers the gateway responses stored on finished
+	// payments and applied or refused callbacks in the webhook inbox.
+	GatewayPayloads time.Duration `mapstructure:"gateway_payloads"`
+	// PurgeSchedule is when records past their retention are purged, a
+	// cron expression in UTC or @every <duration>.
+	PurgeSchedule string `mapstructure:"purge_schedule"`
+}
+
+// HTTPClientConfig tunes the shared HTTP client used for outgoing calls
+// such as payment gateway requests and webhook callbacks. Zero values take
+// the defaults in the httpclient package.
+type HTTPClientConfig struct {
+	Timeout               time.Duration `mapstructure:"timeout"`
+	DialTimeout           time.Duration `mapstructure:"dial_timeout"`
+	TLSHandshakeTimeout   time.Duration `mapstructure:"tls_handshake_timeout"`
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout"`
+	IdleConnTimeout       time.Duration `mapstructure:"idle_conn_timeout"`
+	MaxIdleConns          int           `mapstructure:"max_idle_conns" validate:"min=0"`
+	MaxIdleConnsPerHost   int           `mapstructure:"max_idle_conns_per_host" validate:"min=0"`
+	MaxConnsPerHost       int           `mapstructure:"max_conns_per_host" validate:"min=0"`
+	// ProxyURL overrides the HTTP_PROXY and HTTPS_PROXY environment
+	// variables.
+	ProxyURL string        `mapstructure:"proxy_url"`
+	TLS      HTTPTLSConfig `mapstructure:"tls"`
+	// Retries resends only requests that are safe to repeat: GET and other
+	// idempotent methods, or requests carrying an Idempotency-Key.
+	Retries      int           `mapstructure:"retries" validate:"min=0,max=5"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+}
+
+type HTTPTLSConfig struct {
+	CAFile             string `mapstructure:"ca_file"`
+	MinVersion         string `mapstructure:"min_version" validate:"omitempty,oneof=1.2 1.3"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// SchedulerConfig controls the recurring job scheduler the server runs
+// digests, payment reconciliation and retries, and the daily snapshot on.
+type SchedulerConfig struct {
+	// DistributedLock takes a Postgres advisory lock per job run, so only one
+	// instance runs a job at a time. Turn it off only for a single instance.
+	DistributedLock bool                  `mapstructure:"distributed_lock"`
+	Reconcile       ReconcileJobConfig    `mapstructure:"payment_reconciliation"`
+	Retry           PaymentRetryJobConfig `mapstructure:"payment_retry"`
+	Snapshot        SnapshotJobConfig     `mapstructure:"daily_snapshot"`
+}
+
+// ReconcileJobConfig asks the gateway for the status of payments still
+// pending after OlderThan, as backfill payment-status does.
+type ReconcileJobConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Schedule is a cron expression in UTC, or @every <duration>.
+	Schedule  string        `mapstructure:"schedule"`
+	OlderThan time.Duration `mapstructure:"older_than"`
+	// Limit caps the payments checked per run; zero checks all of them.
+	Limit int `mapstructure:"limit" validate:"min=0"`
+}
+
+// PaymentRetryJobConfig retries payments that failed transiently, such as
+// on a network error or a 5xx from the gateway, waiting Backoff before the
+// first retry and twice as long before each next one. Payments that failed
+// permanently are never retried automatically.
+type PaymentRetryJobConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Schedule is a cron expression in UTC, or @every <duration>.
+	Schedule   string        `mapstructure:"schedule"`
+	MaxRetries int           `mapstructure:"max_retries"`
+	Backoff    time.Duration `mapstructure:"backoff"`
+	// Limit caps the payments retried per run; zero retries all that are
+	// due.
+	Limit int `mapstructure:"limit"`
+}
+
+// SnapshotJobConfig writes the previous days' aggregated facts to the
+// reporting schema.
+type SnapshotJobConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Schedule is a cron expression in UTC, or @every <duration>.
+	Schedule string `mapstructure:"schedule"`
+}
+
+// EncryptionConfig holds the AES keys for fields encrypted at rest, each a
+// base64-encoded 32 byte key. New values are sealed with Key; PreviousKeys
+// only open values sealed before a rotation. Without a Key, gateway
+// responses are stored in plaintext and bank accounts are disabled.
+type EncryptionConfig struct {
+	Key          string   `mapstructure:"key"`
+	PreviousKeys []string `mapstructure:"previous_keys"`
 }
 
 type ObservabilityConfig struct {
-	Metrics MetricsConfig `mapstructure:"metrics"`
-	Tracing TracingConfig `mapstructure:"tracing"`
-	Logging LoggingConfig `mapstructure:"logging"`
+	Metrics MetricsConfig  `mapstructure:"metrics"`
+	Tracing TracingConfig  `mapstructure:"tracing"`
+	Logging LoggingConfig  `mapstructure:"logging"`
+	Audit   AuditLogConfig `mapstructure:"audit"`
 }
 
 type MetricsConfig struct {
@@ -72,8 +580,56 @@ type TracingConfig struct {
 }
 
 type LoggingConfig struct {
-	Level  string `mapstructure:"level" validate:"required,oneof=debug info warn error"`
-	Format string `mapstructure:"format" validate:"required,oneof=json text"`
+	Level     string            `mapstructure:"level" validate:"required,oneof=debug info warn error"`
+	Format    string            `mapstructure:"format" validate:"required,oneof=json text"`
+	Body      BodyLoggingConfig `mapstructure:"body"`
+	Redaction RedactionConfig   `mapstructure:"redaction"`
+	// SuccessSampleRate is the fraction of requests answered with a 2xx
+	// that the access log keeps; 0 or unset keeps all of them.
+	SuccessSampleRate float64 `mapstructure:"success_sample_rate" validate:"min=0,max=1"`
+}
+
+// RedactionConfig adds to the fields the access log always masks, such as
+// passwords and tokens.
+type RedactionConfig struct {
+	Fields             []string `mapstructure:"fields"`
+	MaskEmails         bool     `mapstructure:"mask_emails"`
+	MaskAccountNumbers bool     `mapstructure:"mask_account_numbers"`
+	// Patterns are regular expressions whose matches are masked.
+	Patterns []string               `mapstructure:"patterns"`
+	Routes   []RedactionRouteConfig `mapstructure:"routes"`
+}
+
+// RedactionRouteConfig allows or denies fields on the routes under
+// PathPrefix; the longest matching prefix applies.
+type RedactionRouteConfig struct {
+	PathPrefix string   `mapstructure:"path_prefix"`
+	Allow      []string `mapstructure:"allow"`
+	Deny       []string `mapstructure:"deny"`
+}
+
+// AuditLogConfig sends business events (approvals, rejections, payments and
+// permission changes) as JSON lines to a sink of their own, apart from the
+// application log. Without a driver they are only kept in the audit_logs
+// table.
+type AuditLogConfig struct {
+	// Driver is file or webhook.
+	Driver string `mapstructure:"driver" validate:"omitempty,oneof=file webhook"`
+	// Level is info for every event or warn for refusals and anomalies
+	// only; it does not follow the application log level.
+	Level      string        `mapstructure:"level" validate:"omitempty,oneof=info warn"`
+	Path       string        `mapstructure:"path"`
+	WebhookURL string        `mapstructure:"webhook_url"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// BodyLoggingConfig bounds request/response body capture in the access log.
+type BodyLoggingConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MaxBytes     int      `mapstructure:"max_bytes" validate:"min=0"`
+	ContentTypes []string `mapstructure:"content_types"`
+	SampleRate   float64  `mapstructure:"sample_rate" validate:"min=0,max=1"`
+	SkipPaths    []string `mapstructure:"skip_paths"`
 }
 
 func getEnv(key, defaultVal string) string {
@@ -92,6 +648,74 @@ func getEnvAsInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvAsInt64(key string, defaultVal int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intVal
+		}
+	}
+	return defaultVal
+}
+
+// getEnvAsOptionalInt64 returns nil when key is unset or not a number.
+func getEnvAsOptionalInt64(key string) *int64 {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return &intVal
+		}
+	}
+	return nil
+}
+
+// getEnvAsRedactionRoutes builds routes from the "path_prefix:field" pairs
+// of allowKey and denyKey, separated by commas.
+func getEnvAsRedactionRoutes(allowKey, denyKey string) []RedactionRouteConfig {
+	var routes []RedactionRouteConfig
+	index := map[string]int{}
+	add := func(key string, deny bool) {
+		for _, pair := range getEnvAsSlice(key, nil) {
+			prefix, field, ok := strings.Cut(pair, ":")
+			if !ok {
+				continue
+			}
+			prefix, field = strings.TrimSpace(prefix), strings.TrimSpace(field)
+			i, seen := index[prefix]
+			if !seen {
+				i = len(routes)
+				index[prefix] = i
+				routes = append(routes, RedactionRouteConfig{PathPrefix: prefix})
+			}
+			if deny {
+				routes[i].Deny = append(routes[i].Deny, field)
+			} else {
+				routes[i].Allow = append(routes[i].Allow, field)
+			}
+		}
+	}
+	add(allowKey, false)
+	add(denyKey, true)
+	return routes
+}
+
+// getEnvAsGroupPermissions parses "group:permission" pairs separated by
+// commas; a group listed several times gets every permission named.
+func getEnvAsGroupPermissions(key string) map[string][]string {
+	pairs := getEnvAsSlice(key, nil)
+	if len(pairs) == 0 {
+		return nil
+	}
+	groups := make(map[string][]string)
+	for _, pair := range pairs {
+		group, permission, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		group, permission = strings.TrimSpace(group), strings.TrimSpace(permission)
+		groups[group] = append(groups[group], permission)
+	}
+	return groups
+}
+
 func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -101,16 +725,42 @@ func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
 	return defaultVal
 }
 
+func getEnvAsFloat(key string, defaultVal float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultVal
+}
+
+func getEnvAsSlice(key string, defaultVal []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultVal
+	}
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func LoadConfigFromEnv() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:              getEnvAsInt("APP_PORT", 8080),
-			BaseURL:           getEnv("APP_BASE_URL", "http://localhost:8080"),
-			AllowedOrigins:    getEnv("CORS_ALLOWED_ORIGINS", "*"),
-			ReadHeaderTimeout: getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
-			ReadTimeout:       getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
-			IdleTimeout:       getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
-			WriteTimeout:      getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			Port:                    getEnvAsInt("APP_PORT", 8080),
+			BaseURL:                 getEnv("APP_BASE_URL", "http://localhost:8080"),
+			AllowedOrigins:          getEnv("CORS_ALLOWED_ORIGINS", "*"),
+			ReadHeaderTimeout:       getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			ReadTimeout:             getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
+			IdleTimeout:             getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			WriteTimeout:            getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			RequestTimeout:          getEnvAsDuration("SERVER_REQUEST_TIMEOUT", 0),
+			RequestTimeoutSkipPaths: getEnvAsSlice("SERVER_REQUEST_TIMEOUT_SKIP_PATHS", nil),
+			SlowRequestThreshold:    getEnvAsDuration("SERVER_SLOW_REQUEST_THRESHOLD", 0),
 		},
 		Database: DatabaseConfig{
 			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 10),
@@ -123,21 +773,253 @@ func LoadConfigFromEnv() *Config {
 			AccessTokenDuration:  getEnvAsDuration("JWT_EXPIRY", 24*time.Hour),
 			RefreshTokenDuration: getEnvAsDuration("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
 			BCryptCost:           getEnvAsInt("BCRYPT_COST", 12),
-			SessionSecret:        getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
+			SessionSecret:        getEnv("JWT_SECRET", DevSessionSecret),
+			RefreshGracePeriod:   getEnvAsDuration("JWT_REFRESH_GRACE_PERIOD", 0),
+			Issuer:               getEnv("JWT_ISSUER", "expense-management"),
+			Audience:             getEnv("JWT_AUDIENCE", "expense-management-api"),
+			ClockSkew:            getEnvAsDuration("JWT_CLOCK_SKEW", 30*time.Second),
+			ImpersonationTTL:     getEnvAsDuration("JWT_IMPERSONATION_TTL", 15*time.Minute),
 		},
 		Payment: PaymentConfig{
-			MockAPIURL:     getEnv("PAYMENT_MOCK_API_URL", "https://1620e98f-7759-431c-a2aa-f449d591150b.mock.pstmn.io"),
-			APIKey:         getEnv("PAYMENT_API_KEY", "mock-postman-api-key"),
-			WebhookURL:     getEnv("PAYMENT_WEBHOOK_URL", "http://localhost:8080/webhooks/payment/callback"),
-			MaxWorkers:     getEnvAsInt("PAYMENT_MAX_WORKERS", 10),
-			JobQueueSize:   getEnvAsInt("PAYMENT_JOB_QUEUE_SIZE", 100),
-			WorkerPoolSize: getEnvAsInt("PAYMENT_WORKER_POOL_SIZE", 10),
-			PaymentTimeout: getEnvAsDuration("PAYMENT_TIMEOUT", 15*time.Second),
+			MockAPIURL:       getEnv("PAYMENT_MOCK_API_URL", "https://1620e98f-7759-431c-a2aa-f449d591150b.mock.pstmn.io"),
+			APIKey:           getEnv("PAYMENT_API_KEY", "mock-postman-api-key"),
+			WebhookURL:       getEnv("PAYMENT_WEBHOOK_URL", "http://localhost:8080/api/v1/payment/callback"),
+			WebhookSecret:    getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+			MinWorkers:       getEnvAsInt("PAYMENT_MIN_WORKERS", 2),
+			MaxWorkers:       getEnvAsInt("PAYMENT_MAX_WORKERS", 10),
+			JobQueueSize:     getEnvAsInt("PAYMENT_JOB_QUEUE_SIZE", 100),
+			WorkerPoolSize:   getEnvAsInt("PAYMENT_WORKER_POOL_SIZE", 10),
+			PaymentTimeout:   getEnvAsDuration("PAYMENT_TIMEOUT", 15*time.Second),
+			OverflowStrategy: getEnv("PAYMENT_OVERFLOW_STRATEGY", "shed"),
+			EnqueueTimeout:   getEnvAsDuration("PAYMENT_ENQUEUE_TIMEOUT", 2*time.Second),
+			ScaleInterval:    getEnvAsDuration("PAYMENT_SCALE_INTERVAL", 5*time.Second),
+			PoisonThreshold:  getEnvAsInt("PAYMENT_POISON_THRESHOLD", 3),
+			Driver:           getEnv("PAYMENT_DRIVER", "gateway"),
+			ResponseVersion:  getEnv("PAYMENT_RESPONSE_VERSION", "v1"),
+			Mock: MockGatewayConfig{
+				Mode:        getEnv("PAYMENT_MOCK_MODE", "random"),
+				SuccessRate: getEnvAsFloat("PAYMENT_MOCK_SUCCESS_RATE", 0.9),
+				MinDelay:    getEnvAsDuration("PAYMENT_MOCK_MIN_DELAY", 1*time.Second),
+				MaxDelay:    getEnvAsDuration("PAYMENT_MOCK_MAX_DELAY", 4*time.Second),
+			},
+			Inbox: WebhookInboxConfig{
+				Enabled:      getEnv("PAYMENT_WEBHOOK_INBOX_ENABLED", "true") == "true",
+				PollInterval: getEnvAsDuration("PAYMENT_WEBHOOK_INBOX_POLL_INTERVAL", time.Second),
+				MaxAttempts:  getEnvAsInt("PAYMENT_WEBHOOK_INBOX_MAX_ATTEMPTS", 10),
+				BaseBackoff:  getEnvAsDuration("PAYMENT_WEBHOOK_INBOX_BASE_BACKOFF", 5*time.Second),
+				MaxBackoff:   getEnvAsDuration("PAYMENT_WEBHOOK_INBOX_MAX_BACKOFF", time.Hour),
+			},
+			Failover: PaymentFailoverConfig{
+				FailureThreshold: getEnvAsInt("PAYMENT_FAILOVER_FAILURE_THRESHOLD", 5),
+				OpenDuration:     getEnvAsDuration("PAYMENT_FAILOVER_OPEN_DURATION", 30*time.Second),
+			},
+			Budgets: PaymentBudgetConfig{
+				Initiate: getEnvAsDuration("PAYMENT_BUDGET_INITIATE", 0),
+				Status:   getEnvAsDuration("PAYMENT_BUDGET_STATUS", 0),
+			},
+			Hedge: PaymentHedgeConfig{
+				Delay: getEnvAsDuration("PAYMENT_HEDGE_DELAY", 0),
+			},
+			Recorder: PaymentRecorderConfig{
+				Mode: getEnv("PAYMENT_RECORDER_MODE", ""),
+				Path: getEnv("PAYMENT_RECORDER_PATH", "testdata/gateway_cassette.json"),
+			},
+			CallLog: PaymentCallLogConfig{
+				Enabled:       getEnv("PAYMENT_CALL_LOG_ENABLED", "true") == "true",
+				Retention:     getEnvAsDuration("PAYMENT_CALL_LOG_RETENTION", 180*24*time.Hour),
+				MaxBodyBytes:  getEnvAsInt("PAYMENT_CALL_LOG_MAX_BODY_BYTES", 64<<10),
+				PurgeSchedule: getEnv("PAYMENT_CALL_LOG_PURGE_SCHEDULE", "0 3 * * *"),
+			},
+			Shadow: PaymentShadowConfig{
+				Name:            getEnv("PAYMENT_SHADOW_NAME", "shadow"),
+				APIURL:          getEnv("PAYMENT_SHADOW_API_URL", ""),
+				APIKey:          getEnv("PAYMENT_SHADOW_API_KEY", ""),
+				WebhookURL:      getEnv("PAYMENT_SHADOW_WEBHOOK_URL", ""),
+				ResponseVersion: getEnv("PAYMENT_SHADOW_RESPONSE_VERSION", "v1"),
+			},
+		},
+		Notification: NotificationConfig{
+			Mailer: MailerConfig{
+				Driver:   getEnv("MAILER_DRIVER", "log"),
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getEnvAsInt("SMTP_PORT", 587),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("MAIL_FROM", ""),
+			},
+			Digest: DigestConfig{
+				Enabled:   getEnv("DIGEST_ENABLED", "false") == "true",
+				Hour:      getEnvAsInt("DIGEST_HOUR", 1),
+				WeeklyDay: getEnv("DIGEST_WEEKLY_DAY", "monday"),
+			},
+			ActionLinks: ActionLinksConfig{
+				Enabled:    getEnv("APPROVAL_LINKS_ENABLED", "false") == "true",
+				TTL:        getEnvAsDuration("APPROVAL_LINK_TTL", 72*time.Hour),
+				SigningKey: getEnv("APPROVAL_LINK_SIGNING_KEY", ""),
+			},
+		},
+		Storage: StorageConfig{
+			Driver:          getEnv("STORAGE_DRIVER", "local"),
+			LocalRoot:       getEnv("STORAGE_LOCAL_ROOT", "./data/storage"),
+			SigningKey:      getEnv("STORAGE_SIGNING_KEY", ""),
+			Bucket:          getEnv("STORAGE_BUCKET", ""),
+			Region:          getEnv("STORAGE_REGION", ""),
+			Endpoint:        getEnv("STORAGE_ENDPOINT", ""),
+			AccessKeyID:     getEnv("STORAGE_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("STORAGE_SECRET_ACCESS_KEY", ""),
+			UsePathStyle:    getEnv("STORAGE_USE_PATH_STYLE", "false") == "true",
+		},
+		Export: ExportConfig{
+			PollInterval: getEnvAsDuration("EXPORT_POLL_INTERVAL", 5*time.Second),
+			Retention:    getEnvAsDuration("EXPORT_RETENTION", 7*24*time.Hour),
+			LinkExpiry:   getEnvAsDuration("EXPORT_LINK_EXPIRY", time.Hour),
+		},
+		Import: ImportConfig{
+			PollInterval: getEnvAsDuration("IMPORT_POLL_INTERVAL", 5*time.Second),
+			SyncMaxBytes: getEnvAsInt64("IMPORT_SYNC_MAX_BYTES", 256<<10),
+			MaxFileBytes: getEnvAsInt64("IMPORT_MAX_FILE_BYTES", 50<<20),
+		},
+		Thumbnails: ThumbnailsConfig{
+			Enabled:      getEnv("THUMBNAILS_ENABLED", "true") == "true",
+			PollInterval: getEnvAsDuration("THUMBNAILS_POLL_INTERVAL", 10*time.Second),
+			Size:         getEnvAsInt("THUMBNAILS_SIZE", 320),
+			PDFRenderer:  getEnv("THUMBNAILS_PDF_RENDERER", "pdftoppm"),
+		},
+		CardFeed: CardFeedConfig{
+			MaxFileBytes:    getEnvAsInt64("CARD_FEED_MAX_FILE_BYTES", 10<<20),
+			MatchWindowDays: getEnvAsInt("CARD_FEED_MATCH_WINDOW_DAYS", 3),
+		},
+		Reports: ReportsConfig{
+			PollInterval: getEnvAsDuration("REPORTS_POLL_INTERVAL", time.Minute),
+			ApprovalSLA:  getEnvAsDuration("REPORTS_APPROVAL_SLA", 48*time.Hour),
+		},
+		Calendar: CalendarConfig{
+			TimeZone:         getEnv("CALENDAR_TIME_ZONE", "Asia/Jakarta"),
+			Weekend:          getEnvAsSlice("CALENDAR_WEEKEND", []string{"saturday", "sunday"}),
+			NationalHolidays: getEnv("CALENDAR_NATIONAL_HOLIDAYS", "true") == "true",
+		},
+		Limits: LimitsConfig{
+			DailyIDR:   getEnvAsInt64("SPENDING_LIMIT_DAILY_IDR", 0),
+			MonthlyIDR: getEnvAsInt64("SPENDING_LIMIT_MONTHLY_IDR", 0),
+		},
+		Encryption: EncryptionConfig{
+			Key:          getEnv("ENCRYPTION_KEY", ""),
+			PreviousKeys: getEnvAsSlice("ENCRYPTION_PREVIOUS_KEYS", nil),
+		},
+		SCIM: SCIMConfig{
+			Enabled:          getEnv("SCIM_ENABLED", "false") == "true",
+			APIKey:           getEnv("SCIM_API_KEY", ""),
+			Tenant:           getEnv("SCIM_TENANT", "default"),
+			GroupPermissions: getEnvAsGroupPermissions("SCIM_GROUP_PERMISSIONS"),
+		},
+		Slack: SlackConfig{
+			Enabled:       getEnv("SLACK_ENABLED", "false") == "true",
+			SigningSecret: getEnv("SLACK_SIGNING_SECRET", ""),
+			BotToken:      getEnv("SLACK_BOT_TOKEN", ""),
+			AlertChannel:  getEnv("SLACK_ALERT_CHANNEL", ""),
+			APIURL:        getEnv("SLACK_API_URL", ""),
+		},
+		Introspection: IntrospectionConfig{
+			CacheTTL: getEnvAsDuration("INTROSPECTION_CACHE_TTL", time.Minute),
+		},
+		SharedLinks: SharedLinksConfig{
+			TTL:        getEnvAsDuration("SHARED_LINK_TTL", 15*time.Minute),
+			SigningKey: getEnv("SHARED_LINK_SIGNING_KEY", ""),
+		},
+		Login: LoginConfig{
+			Window:       getEnvAsDuration("LOGIN_THROTTLE_WINDOW", 15*time.Minute),
+			CaptchaAfter: getEnvAsInt("LOGIN_CAPTCHA_AFTER", 3),
+			LockAfter:    getEnvAsInt("LOGIN_LOCK_AFTER", 20),
+			Captcha: CaptchaConfig{
+				Provider: getEnv("CAPTCHA_PROVIDER", ""),
+				Secret:   getEnv("CAPTCHA_SECRET", ""),
+			},
+			NewLoginAlerts: getEnv("LOGIN_NEW_LOGIN_ALERTS", "true") == "true",
+			Geo: GeoConfig{
+				URL:     getEnv("LOGIN_GEO_URL", ""),
+				Timeout: getEnvAsDuration("LOGIN_GEO_TIMEOUT", 2*time.Second),
+			},
+		},
+		Scheduler: SchedulerConfig{
+			DistributedLock: getEnv("SCHEDULER_DISTRIBUTED_LOCK", "true") == "true",
+			Reconcile: ReconcileJobConfig{
+				Enabled:   getEnv("PAYMENT_RECONCILE_ENABLED", "false") == "true",
+				Schedule:  getEnv("PAYMENT_RECONCILE_SCHEDULE", "*/30 * * * *"),
+				OlderThan: getEnvAsDuration("PAYMENT_RECONCILE_OLDER_THAN", time.Hour),
+				Limit:     getEnvAsInt("PAYMENT_RECONCILE_LIMIT", 500),
+			},
+			Retry: PaymentRetryJobConfig{
+				Enabled:    getEnv("PAYMENT_RETRY_ENABLED", "false") == "true",
+				Schedule:   getEnv("PAYMENT_RETRY_SCHEDULE", "@every 1m"),
+				MaxRetries: getEnvAsInt("PAYMENT_RETRY_MAX_RETRIES", 3),
+				Backoff:    getEnvAsDuration("PAYMENT_RETRY_BACKOFF", 2*time.Minute),
+				Limit:      getEnvAsInt("PAYMENT_RETRY_LIMIT", 100),
+			},
+			Snapshot: SnapshotJobConfig{
+				Enabled:  getEnv("DAILY_SNAPSHOT_ENABLED", "false") == "true",
+				Schedule: getEnv("DAILY_SNAPSHOT_SCHEDULE", "30 0 * * *"),
+			},
+		},
+		HTTPClient: HTTPClientConfig{
+			Timeout:               getEnvAsDuration("HTTP_CLIENT_TIMEOUT", 30*time.Second),
+			DialTimeout:           getEnvAsDuration("HTTP_CLIENT_DIAL_TIMEOUT", 5*time.Second),
+			TLSHandshakeTimeout:   getEnvAsDuration("HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT", 5*time.Second),
+			ResponseHeaderTimeout: getEnvAsDuration("HTTP_CLIENT_RESPONSE_HEADER_TIMEOUT", 0),
+			IdleConnTimeout:       getEnvAsDuration("HTTP_CLIENT_IDLE_CONN_TIMEOUT", 90*time.Second),
+			MaxIdleConns:          getEnvAsInt("HTTP_CLIENT_MAX_IDLE_CONNS", 100),
+			MaxIdleConnsPerHost:   getEnvAsInt("HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST", 10),
+			MaxConnsPerHost:       getEnvAsInt("HTTP_CLIENT_MAX_CONNS_PER_HOST", 0),
+			ProxyURL:              getEnv("HTTP_CLIENT_PROXY_URL", ""),
+			TLS: HTTPTLSConfig{
+				CAFile:             getEnv("HTTP_CLIENT_TLS_CA_FILE", ""),
+				MinVersion:         getEnv("HTTP_CLIENT_TLS_MIN_VERSION", "1.2"),
+				InsecureSkipVerify: getEnv("HTTP_CLIENT_TLS_INSECURE_SKIP_VERIFY", "false") == "true",
+			},
+			Retries:      getEnvAsInt("HTTP_CLIENT_RETRIES", 2),
+			RetryBackoff: getEnvAsDuration("HTTP_CLIENT_RETRY_BACKOFF", 100*time.Millisecond),
+		},
+		Retention: RetentionConfig{
+			AuditLogs:       getEnvAsDuration("RETENTION_AUDIT_LOGS", 0),
+			GatewayPayloads: getEnvAsDuration("RETENTION_GATEWAY_PAYLOADS", 0),
+			PurgeSchedule:   getEnv("RETENTION_PURGE_SCHEDULE", "30 3 * * *"),
+		},
+		Tenants: TenantsConfig{
+			AutoApprovalThresholdIDR: getEnvAsOptionalInt64("TENANT_AUTO_APPROVAL_THRESHOLD_IDR"),
+			Currency:                 getEnv("TENANT_CURRENCY", "IDR"),
+			ApprovalChain:            getEnvAsSlice("TENANT_APPROVAL_CHAIN", nil),
+			NotificationChannels:     getEnvAsSlice("TENANT_NOTIFICATION_CHANNELS", nil),
+			ReceiptRequiredAboveIDR:  getEnvAsInt64("TENANT_RECEIPT_REQUIRED_ABOVE_IDR", 0),
+			MaxPendingExpenses:       getEnvAsInt64("TENANT_MAX_PENDING_EXPENSES", 0),
+			MinExpenseAmountIDR:      getEnvAsInt64("TENANT_MIN_EXPENSE_AMOUNT_IDR", 0),
+			MaxExpenseAmountIDR:      getEnvAsInt64("TENANT_MAX_EXPENSE_AMOUNT_IDR", 0),
+			CacheTTL:                 getEnvAsDuration("TENANT_SETTINGS_CACHE_TTL", time.Minute),
 		},
 		Observability: ObservabilityConfig{
 			Logging: LoggingConfig{
 				Level:  getEnv("LOG_LEVEL", "info"),
 				Format: getEnv("LOG_FORMAT", "json"),
+				Body: BodyLoggingConfig{
+					Enabled:      getEnv("LOG_BODY_ENABLED", "true") == "true",
+					MaxBytes:     getEnvAsInt("LOG_BODY_MAX_BYTES", 4096),
+					ContentTypes: getEnvAsSlice("LOG_BODY_CONTENT_TYPES", []string{"application/json", "text/plain", "application/x-www-form-urlencoded"}),
+					SampleRate:   getEnvAsFloat("LOG_BODY_SAMPLE_RATE", 1.0),
+					SkipPaths:    getEnvAsSlice("LOG_BODY_SKIP_PATHS", nil),
+				},
+				Redaction: RedactionConfig{
+					Fields:             getEnvAsSlice("LOG_REDACT_FIELDS", nil),
+					MaskEmails:         getEnv("LOG_MASK_EMAILS", "false") == "true",
+					MaskAccountNumbers: getEnv("LOG_MASK_ACCOUNT_NUMBERS", "false") == "true",
+					Patterns:           getEnvAsSlice("LOG_REDACT_PATTERNS", nil),
+					Routes:             getEnvAsRedactionRoutes("LOG_REDACT_ALLOW", "LOG_REDACT_DENY"),
+				},
+				SuccessSampleRate: getEnvAsFloat("LOG_SUCCESS_SAMPLE_RATE", 1.0),
+			},
+			Audit: AuditLogConfig{
+				Driver:     getEnv("AUDIT_LOG_DRIVER", ""),
+				Level:      getEnv("AUDIT_LOG_LEVEL", "info"),
+				Path:       getEnv("AUDIT_LOG_PATH", ""),
+				WebhookURL: getEnv("AUDIT_LOG_WEBHOOK_URL", ""),
+				Timeout:    getEnvAsDuration("AUDIT_LOG_TIMEOUT", 5*time.Second),
 			},
 			Metrics: MetricsConfig{
 				Enabled: getEnv("METRICS_ENABLED", "false") == "true",
@@ -186,10 +1068,116 @@ func (c *Config) Validate() error {
 		errs = append(errs, fmt.Sprintf("database config: %v", err))
 	}
 
+	if err := c.Security.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("security config: %v", err))
+	}
+
 	if err := c.Payment.Validate(); err != nil {
 		errs = append(errs, fmt.Sprintf("payment config: %v", err))
 	}
 
+	if err := c.Notification.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("notification config: %v", err))
+	}
+	if key := c.Notification.ActionLinks.SigningKey; key != "" && key == c.Security.SessionSecret {
+		errs = append(errs, "notification config: action link signing_key must differ from the session secret")
+	}
+
+	if err := c.Storage.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("storage config: %v", err))
+	}
+
+	if err := c.Export.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("export config: %v", err))
+	}
+
+	if err := c.Import.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("import config: %v", err))
+	}
+
+	if err := c.Thumbnails.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("thumbnails config: %v", err))
+	}
+
+	if err := c.CardFeed.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("card feed config: %v", err))
+	}
+
+	if c.Reports.PollInterval < 0 {
+		errs = append(errs, "reports config: poll_interval must not be negative")
+	}
+
+	if err := c.Calendar.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("calendar config: %v", err))
+	}
+
+	if err := c.Limits.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("spending limits config: %v", err))
+	}
+
+	if err := c.Encryption.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("encryption config: %v", err))
+	}
+
+	if err := c.Tenants.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("tenants config: %v", err))
+	}
+
+	if err := c.SCIM.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("scim config: %v", err))
+	}
+
+	if err := c.Slack.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("slack config: %v", err))
+	}
+
+	if err := c.Integrations.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("integrations config: %v", err))
+	}
+
+	if err := c.Introspection.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("introspection config: %v", err))
+	}
+
+	if err := c.SharedLinks.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("shared links config: %v", err))
+	}
+
+	if err := c.Login.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("login config: %v", err))
+	}
+
+	if err := c.Login.Geo.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("login geo config: %v", err))
+	}
+
+	if err := c.Scheduler.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("scheduler config: %v", err))
+	}
+
+	if err := c.HTTPClient.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("http client config: %v", err))
+	}
+
+	if err := c.Retention.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("retention config: %v", err))
+	}
+
+	if err := c.Observability.Logging.Body.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("logging config: %v", err))
+	}
+
+	if err := c.Observability.Logging.Redaction.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("logging config: %v", err))
+	}
+	if c.Observability.Logging.SuccessSampleRate < 0 || c.Observability.Logging.SuccessSampleRate > 1 {
+		errs = append(errs, "logging config: success_sample_rate must be between 0 and 1")
+	}
+
+	if err := c.Observability.Audit.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("audit log config: %v", err))
+	}
+
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
 	}
@@ -197,6 +1185,22 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (c *SecurityConfig) Validate() error {
+	if c.RefreshGracePeriod < 0 {
+		return errors.New("refresh_grace_period must not be negative")
+	}
+	if c.AccessTokenDuration > 0 && c.RefreshGracePeriod > c.AccessTokenDuration {
+		return errors.New("refresh_grace_period must not exceed access_token_duration")
+	}
+	if c.ClockSkew < 0 || c.ClockSkew > 5*time.Minute {
+		return errors.New("clock_skew must be between 0 and 5m")
+	}
+	if c.ImpersonationTTL < 0 || c.ImpersonationTTL > time.Hour {
+		return errors.New("impersonation_ttl must be between 0 and 1h")
+	}
+	return nil
+}
+
 func (c *ServerConfig) Validate() error {
 	if c.AllowedOrigins != "" {
 		origins := strings.Split(c.AllowedOrigins, ",")
@@ -213,6 +1217,17 @@ func (c *ServerConfig) Validate() error {
 	if c.ReadTimeout < c.ReadHeaderTimeout {
 		return errors.New("read_timeout must be >= read_header_timeout")
 	}
+	if c.RequestTimeout < 0 || c.SlowRequestThreshold < 0 {
+		return errors.New("request_timeout and slow_request_threshold must not be negative")
+	}
+	if c.RequestTimeout > 0 && c.WriteTimeout > 0 && c.RequestTimeout >= c.WriteTimeout {
+		return errors.New("request_timeout must be < write_timeout")
+	}
+	for _, prefix := range c.RequestTimeoutSkipPaths {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("request_timeout_skip_paths entry %q must start with /", prefix)
+		}
+	}
 	return nil
 }
 
@@ -265,8 +1280,547 @@ func (c *DatabaseConfig) GetDSN() string {
 }
 
 func (c *PaymentConfig) Validate() error {
-	if c.MockAPIURL == "" {
+	if c.MockAPIURL == "" && len(c.Providers) == 0 {
 		return errors.New("mock_api_url is required")
 	}
+	switch c.OverflowStrategy {
+	case "", "block", "spill", "shed":
+	default:
+		return fmt.Errorf("invalid overflow_strategy %q, must be one of block, spill, shed", c.OverflowStrategy)
+	}
+	if c.MinWorkers > c.MaxWorkers {
+		return errors.New("min_workers cannot be greater than max_workers")
+	}
+	if len(c.WebhookSecret) < minSecretLength {
+		return fmt.Errorf("webhook_secret must be at least %d characters", minSecretLength)
+	}
+	if err := validatePaymentDriver(c.Driver); err != nil {
+		return err
+	}
+	if err := validateResponseVersion(c.ResponseVersion); err != nil {
+		return err
+	}
+	mock := c.Driver == "mock"
+	names := make(map[string]bool, len(c.Providers))
+	for i, p := range c.Providers {
+		if p.Name == "" {
+			return fmt.Errorf("payment provider %d requires a name", i+1)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicate payment provider %q", p.Name)
+		}
+		names[p.Name] = true
+		if err := validatePaymentDriver(p.Driver); err != nil {
+			return fmt.Errorf("payment provider %q: %w", p.Name, err)
+		}
+		if err := validateResponseVersion(p.ResponseVersion); err != nil {
+			return fmt.Errorf("payment provider %q: %w", p.Name, err)
+		}
+		if p.APIURL == "" && p.Driver != "sandbox" {
+			return fmt.Errorf("payment provider %q requires api_url", p.Name)
+		}
+		mock = mock || p.Driver == "mock"
+	}
+	if mock {
+		switch c.Mock.Mode {
+		case "", "random", "success", "failure":
+		default:
+			return fmt.Errorf("invalid mock mode %q, must be one of random, success, failure", c.Mock.Mode)
+		}
+		if c.Mock.SuccessRate < 0 || c.Mock.SuccessRate > 1 {
+			return errors.New("mock success_rate must be between 0 and 1")
+		}
+		if c.Mock.MaxDelay < c.Mock.MinDelay {
+			return errors.New("mock max_delay cannot be less than min_delay")
+		}
+	}
+	if c.Failover.FailureThreshold < 0 || c.Failover.OpenDuration < 0 {
+		return errors.New("failover settings must not be negative")
+	}
+	if c.Budgets.Initiate < 0 || c.Budgets.Status < 0 || c.Hedge.Delay < 0 {
+		return errors.New("latency_budgets and hedge settings must not be negative")
+	}
+	statusBudget := c.Budgets.Status
+	if statusBudget == 0 {
+		statusBudget = c.PaymentTimeout
+	}
+	if c.Hedge.Delay > 0 && c.Hedge.Delay >= statusBudget {
+		return fmt.Errorf("hedge delay %s must be shorter than the status budget %s", c.Hedge.Delay, statusBudget)
+	}
+	if c.Inbox.PollInterval < 0 || c.Inbox.MaxAttempts < 0 || c.Inbox.BaseBackoff < 0 || c.Inbox.MaxBackoff < 0 {
+		return errors.New("webhook_inbox settings must not be negative")
+	}
+	if c.Inbox.MaxBackoff > 0 && c.Inbox.BaseBackoff > c.Inbox.MaxBackoff {
+		return errors.New("webhook_inbox base_backoff cannot exceed max_backoff")
+	}
+	switch c.Recorder.Mode {
+	case "":
+	case "record", "replay":
+		if c.Recorder.Path == "" {
+			return errors.New("recorder path is required")
+		}
+	default:
+		return fmt.Errorf("invalid recorder mode %q, must be one of record, replay", c.Recorder.Mode)
+	}
+	if c.CallLog.Retention < 0 || c.CallLog.MaxBodyBytes < 0 {
+		return errors.New("call_log settings must not be negative")
+	}
+	// Calls stored while the log was on are purged even once it is off.
+	if c.CallLog.Retention > 0 {
+		if _, err := scheduler.Parse(c.CallLog.PurgeSchedule); err != nil {
+			return fmt.Errorf("call_log purge_schedule: %w", err)
+		}
+	}
+	if c.Shadow.APIURL != "" {
+		if _, err := url.ParseRequestURI(c.Shadow.APIURL); err != nil {
+			return fmt.Errorf("invalid shadow api_url: %w", err)
+		}
+		if err := validateResponseVersion(c.Shadow.ResponseVersion); err != nil {
+			return fmt.Errorf("shadow: %w", err)
+		}
+		if c.Shadow.WebhookURL != "" && c.Shadow.WebhookURL == c.WebhookURL {
+			return errors.New("shadow webhook_url must not be the payment webhook_url")
+		}
+	}
 	return nil
 }
+
+func validatePaymentDriver(driver string) error {
+	switch driver {
+	case "", "gateway", "mock", "sandbox":
+		return nil
+	default:
+		return fmt.Errorf("invalid driver %q, must be one of gateway, mock, sandbox", driver)
+	}
+}
+
+func validateResponseVersion(version string) error {
+	switch version {
+	case "", "v1", "v2":
+		return nil
+	default:
+		return fmt.Errorf("invalid response_version %q, must be one of v1, v2", version)
+	}
+}
+
+func (c *SchedulerConfig) Validate() error {
+	if c.Snapshot.Enabled {
+		if _, err := scheduler.Parse(c.Snapshot.Schedule); err != nil {
+			return fmt.Errorf("daily_snapshot: %w", err)
+		}
+	}
+	if c.Retry.Enabled {
+		if _, err := scheduler.Parse(c.Retry.Schedule); err != nil {
+			return fmt.Errorf("payment_retry: %w", err)
+		}
+		// payment.MaxRetries bounds every retry, automatic or not.
+		if c.Retry.MaxRetries < 1 || c.Retry.MaxRetries > 3 {
+			return errors.New("payment_retry max_retries must be between 1 and 3")
+		}
+		if c.Retry.Backoff <= 0 {
+			return errors.New("payment_retry backoff must be positive")
+		}
+		if c.Retry.Limit < 0 {
+			return errors.New("payment_retry limit must not be negative")
+		}
+	}
+	if !c.Reconcile.Enabled {
+		return nil
+	}
+	if _, err := scheduler.Parse(c.Reconcile.Schedule); err != nil {
+		return fmt.Errorf("payment_reconciliation: %w", err)
+	}
+	if c.Reconcile.OlderThan <= 0 {
+		return errors.New("payment_reconciliation older_than must be positive")
+	}
+	if c.Reconcile.Limit < 0 {
+		return errors.New("payment_reconciliation limit must not be negative")
+	}
+	return nil
+}
+
+func (c *HTTPClientConfig) Validate() error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"timeout", c.Timeout},
+		{"dial_timeout", c.DialTimeout},
+		{"tls_handshake_timeout", c.TLSHandshakeTimeout},
+		{"response_header_timeout", c.ResponseHeaderTimeout},
+		{"idle_conn_timeout", c.IdleConnTimeout},
+		{"retry_backoff", c.RetryBackoff},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("%s must not be negative", d.name)
+		}
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConnsPerHost < 0 || c.MaxConnsPerHost < 0 {
+		return errors.New("connection limits must not be negative")
+	}
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy_url %q", c.ProxyURL)
+		}
+	}
+	switch c.TLS.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("invalid tls min_version %q, must be 1.2 or 1.3", c.TLS.MinVersion)
+	}
+	if c.Retries < 0 || c.Retries > 5 {
+		return errors.New("retries must be between 0 and 5")
+	}
+	return nil
+}
+
+func (c *BodyLoggingConfig) Validate() error {
+	if c.MaxBytes < 0 {
+		return errors.New("body max_bytes must not be negative")
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return errors.New("body sample_rate must be between 0 and 1")
+	}
+	return nil
+}
+
+func (c *RedactionConfig) Validate() error {
+	for _, pattern := range c.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("redaction pattern %q: %w", pattern, err)
+		}
+	}
+	for _, route := range c.Routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return fmt.Errorf("redaction route %q must start with /", route.PathPrefix)
+		}
+	}
+	return nil
+}
+
+func (c *AuditLogConfig) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	switch c.Driver {
+	case "":
+	case "file":
+		if c.Path == "" {
+			return errors.New("file driver requires path")
+		}
+	case "webhook":
+		u, err := url.Parse(c.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook_url %q must be an http or https URL", c.WebhookURL)
+		}
+	default:
+		return fmt.Errorf("invalid driver %q, must be one of file, webhook", c.Driver)
+	}
+	switch c.Level {
+	case "", "info", "warn":
+	default:
+		return fmt.Errorf("invalid level %q, must be one of info, warn", c.Level)
+	}
+	return nil
+}
+
+func (c *NotificationConfig) Validate() error {
+	switch c.Mailer.Driver {
+	case "", "log":
+	case "smtp":
+		if c.Mailer.Host == "" || c.Mailer.From == "" {
+			return errors.New("smtp mailer requires host and from")
+		}
+	default:
+		return fmt.Errorf("invalid mailer driver %q, must be one of smtp, log", c.Mailer.Driver)
+	}
+	if c.Digest.Hour < 0 || c.Digest.Hour > 23 {
+		return errors.New("digest hour must be between 0 and 23")
+	}
+	if _, err := c.Digest.Weekday(); err != nil {
+		return err
+	}
+	if c.ActionLinks.TTL < 0 || c.ActionLinks.TTL > 7*24*time.Hour {
+		return errors.New("action link ttl must be between 0 and 168h")
+	}
+	if c.ActionLinks.Enabled && c.ActionLinks.SigningKey == "" {
+		return errors.New("action links require signing_key")
+	}
+	if c.ActionLinks.SigningKey != "" && len(c.ActionLinks.SigningKey) < 32 {
+		return errors.New("action link signing_key must be at least 32 characters")
+	}
+	return nil
+}
+
+func (c *StorageConfig) Validate() error {
+	switch c.Driver {
+	case "", "local":
+		return nil
+	case "s3", "gcs", "minio":
+	default:
+		return fmt.Errorf("invalid driver %q, must be one of local, s3, gcs, minio", c.Driver)
+	}
+	if c.Bucket == "" {
+		return fmt.Errorf("%s storage requires a bucket", c.Driver)
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return fmt.Errorf("%s storage requires access_key_id and secret_access_key", c.Driver)
+	}
+	if c.Driver == "minio" && c.Endpoint == "" {
+		return errors.New("minio storage requires an endpoint")
+	}
+	return nil
+}
+
+func (c *ExportConfig) Validate() error {
+	if c.PollInterval < 0 || c.Retention < 0 || c.LinkExpiry < 0 {
+		return errors.New("export durations must not be negative")
+	}
+	if c.LinkExpiry > 7*24*time.Hour {
+		return errors.New("export link_expiry must be at most 168h")
+	}
+	return nil
+}
+
+func (c *ImportConfig) Validate() error {
+	if c.PollInterval < 0 || c.SyncMaxBytes < 0 || c.MaxFileBytes < 0 {
+		return errors.New("import settings must not be negative")
+	}
+	if c.MaxFileBytes > 0 && c.SyncMaxBytes > c.MaxFileBytes {
+		return errors.New("import sync_max_bytes must not exceed max_file_bytes")
+	}
+	return nil
+}
+
+func (c *ThumbnailsConfig) Validate() error {
+	if c.PollInterval < 0 || c.Size < 0 {
+		return errors.New("thumbnails settings must not be negative")
+	}
+	if c.Size > 2048 {
+		return errors.New("thumbnails size must be at most 2048")
+	}
+	return nil
+}
+
+func (c *CalendarConfig) Validate() error {
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		return fmt.Errorf("time_zone: %w", err)
+	}
+	days := make(map[time.Weekday]bool)
+	for _, name := range c.Weekend {
+		known := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(d.String(), name) {
+				days[d], known = true, true
+			}
+		}
+		if !known {
+			return fmt.Errorf("weekend: unknown day %q", name)
+		}
+	}
+	if len(days) == 7 {
+		return errors.New("weekend must leave at least one working day")
+	}
+	return nil
+}
+
+func (c *CardFeedConfig) Validate() error {
+	if c.MaxFileBytes < 0 || c.MatchWindowDays < 0 {
+		return errors.New("card_feed settings must not be negative")
+	}
+	return nil
+}
+
+func (c *RetentionConfig) Validate() error {
+	if c.AuditLogs < 0 || c.GatewayPayloads < 0 {
+		return errors.New("retention periods must not be negative")
+	}
+	if c.AuditLogs > 0 || c.GatewayPayloads > 0 {
+		if _, err := scheduler.Parse(c.PurgeSchedule); err != nil {
+			return fmt.Errorf("purge_schedule: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *LimitsConfig) Validate() error {
+	if c.DailyIDR < 0 || c.MonthlyIDR < 0 {
+		return errors.New("spending limits must not be negative")
+	}
+	if c.DailyIDR > 0 && c.MonthlyIDR > 0 && c.DailyIDR > c.MonthlyIDR {
+		return errors.New("daily_idr must not exceed monthly_idr")
+	}
+	return nil
+}
+
+func (c *TenantsConfig) Validate() error {
+	if c.AutoApprovalThresholdIDR != nil && *c.AutoApprovalThresholdIDR < 0 {
+		return errors.New("auto_approval_threshold_idr must not be negative")
+	}
+	if c.ReceiptRequiredAboveIDR < 0 {
+		return errors.New("receipt_required_above_idr must not be negative")
+	}
+	if c.MaxPendingExpenses < 0 {
+		return errors.New("max_pending_expenses must not be negative")
+	}
+	if c.MinExpenseAmountIDR < 0 || c.MaxExpenseAmountIDR < 0 {
+		return errors.New("expense amount limits must not be negative")
+	}
+	if c.MinExpenseAmountIDR > 0 && c.MaxExpenseAmountIDR > 0 && c.MaxExpenseAmountIDR < c.MinExpenseAmountIDR {
+		return errors.New("max_expense_amount_idr must not be below min_expense_amount_idr")
+	}
+	if c.Currency != "" && len(c.Currency) != 3 {
+		return errors.New("currency must be a three-letter code")
+	}
+	if c.CacheTTL < 0 {
+		return errors.New("cache_ttl must not be negative")
+	}
+	return nil
+}
+
+func (c *SCIMConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.APIKey) < 32 {
+		return errors.New("api_key must be at least 32 characters")
+	}
+	for group, permissions := range c.GroupPermissions {
+		if len(permissions) == 0 {
+			return fmt.Errorf("group %q maps to no permissions", group)
+		}
+	}
+	return nil
+}
+
+func (c *SlackConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.SigningSecret == "" || c.BotToken == "" {
+		return errors.New("signing_secret and bot_token are required")
+	}
+	return nil
+}
+
+func (c *IntegrationsConfig) Validate() error {
+	names := map[string]bool{}
+	keys := map[string]bool{}
+	for _, client := range c.Clients {
+		if client.Name == "" || client.User == "" {
+			return errors.New("every client needs a name and a user")
+		}
+		if names[client.Name] {
+			return fmt.Errorf("client %q is listed twice", client.Name)
+		}
+		if len(client.APIKey) < 32 || len(client.SigningSecret) < 32 {
+			return fmt.Errorf("client %q: api_key and signing_secret must be at least 32 characters", client.Name)
+		}
+		if keys[client.APIKey] {
+			return fmt.Errorf("client %q reuses another client's api_key", client.Name)
+		}
+		names[client.Name] = true
+		keys[client.APIKey] = true
+	}
+	return nil
+}
+
+func (c *IntrospectionConfig) Validate() error {
+	if c.CacheTTL < 0 {
+		return errors.New("cache_ttl must not be negative")
+	}
+	ids := map[string]bool{}
+	for _, client := range c.Clients {
+		if client.ClientID == "" {
+			return errors.New("every client needs a client_id")
+		}
+		if ids[client.ClientID] {
+			return fmt.Errorf("client %q is listed twice", client.ClientID)
+		}
+		if len(client.ClientSecret) < 32 {
+			return fmt.Errorf("client %q: client_secret must be at least 32 characters", client.ClientID)
+		}
+		ids[client.ClientID] = true
+	}
+	return nil
+}
+
+func (c *SharedLinksConfig) Validate() error {
+	if c.TTL < 0 || c.TTL > 24*time.Hour {
+		return errors.New("ttl must be between 0 and 24h")
+	}
+	if c.SigningKey != "" && len(c.SigningKey) < 32 {
+		return errors.New("signing_key must be at least 32 characters")
+	}
+	return nil
+}
+
+func (c *LoginConfig) Validate() error {
+	if c.Window < 0 || c.CaptchaAfter < 0 || c.LockAfter < 0 {
+		return errors.New("login throttle settings must not be negative")
+	}
+	if c.CaptchaAfter > 0 && c.LockAfter > 0 && c.LockAfter <= c.CaptchaAfter {
+		return errors.New("lock_after must be greater than captcha_after")
+	}
+	switch c.Captcha.Provider {
+	case "":
+		return nil
+	case "hcaptcha", "turnstile":
+	default:
+		return fmt.Errorf("invalid captcha provider %q, must be one of hcaptcha, turnstile", c.Captcha.Provider)
+	}
+	if c.Captcha.Secret == "" {
+		return fmt.Errorf("%s captcha requires a secret", c.Captcha.Provider)
+	}
+	return nil
+}
+
+func (c *GeoConfig) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if c.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an http or https URL", c.URL)
+	}
+	if !strings.Contains(c.URL, "{ip}") {
+		return errors.New("url must contain {ip}")
+	}
+	return nil
+}
+
+func (c *EncryptionConfig) Validate() error {
+	if c.Key == "" {
+		if len(c.PreviousKeys) > 0 {
+			return errors.New("previous_keys require a key")
+		}
+		return nil
+	}
+	if err := encryption.ValidateKey(c.Key); err != nil {
+		return fmt.Errorf("key: %w", err)
+	}
+	for i, key := range c.PreviousKeys {
+		if err := encryption.ValidateKey(key); err != nil {
+			return fmt.Errorf("previous_keys[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Weekday parses WeeklyDay, defaulting to Monday.
+func (c *DigestConfig) Weekday() (time.Weekday, error) {
+	if c.WeeklyDay == "" {
+		return time.Monday, nil
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), c.WeeklyDay) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid digest weekly_day %q", c.WeeklyDay)
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DevSessionSecret is the session secret LoadConfigFromEnv falls back to.
+// It is public, so tokens signed with it can be forged.
+const DevSessionSecret = "your-super-secret-jwt-key-change-in-production"
+
+// knownSessionSecrets are the development secrets shipped with the repo.
+var knownSessionSecrets = []string{DevSessionSecret, "jwt-secret-key"}
+
+// minSecretLength and minSecretDistinct bound how weak a session secret may
+// be; a long run of one or two characters is as guessable as a short one.
+const (
+	minSecretLength   = 32
+	minSecretDistinct = 8
+)
+
+// CheckSessionSecret rejects session secrets that are missing, shipped with
+// the repo or too simple to resist guessing.
+func (c *SecurityConfig) CheckSessionSecret() error {
+	for _, known := range knownSessionSecrets {
+		if c.SessionSecret == known {
+			return errors.New("session_secret is a development default; generate a random one")
+		}
+	}
+	if len(c.SessionSecret) < minSecretLength {
+		return fmt.Errorf("session_secret must be at least %d characters", minSecretLength)
+	}
+	distinct := map[rune]bool{}
+	for _, r := range c.SessionSecret {
+		distinct[r] = true
+	}
+	if len(distinct) < minSecretDistinct {
+		return fmt.Errorf("session_secret must use at least %d different characters", minSecretDistinct)
+	}
+	return nil
+}
+
+// Redacted is the value shown in place of a secret that is set.
+const Redacted = "REDACTED"
+
+var dsnPassword = regexp.MustCompile(`(password=)\S+`)
+
+// Redacted returns the effective configuration keyed by its config file
+// names, with secrets masked and database passwords removed from the DSN.
+// Secrets that are not set are shown empty, so a missing one stands out.
+func (c *Config) Redacted() map[string]interface{} {
+	out, _ := redactValue(reflect.ValueOf(*c), "").(map[string]interface{})
+	return out
+}
+
+func redactValue(v reflect.Value, name string) interface{} {
+	if isSecretKey(name) {
+		return maskSecret(v)
+	}
+	if name == "source" && v.Kind() == reflect.String {
+		return redactDSN(v.String())
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := map[string]interface{}{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			key := t.Field(i).Tag.Get("mapstructure")
+			if key == "" || key == "-" {
+				continue
+			}
+			out[key] = redactValue(v.Field(i), key)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i), name)
+		}
+		return out
+	case reflect.Map:
+		out := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value(), "")
+		}
+		return out
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), name)
+	default:
+		return v.Interface()
+	}
+}
+
+func isSecretKey(name string) bool {
+	switch name {
+	case "key", "secret", "password", "previous_keys":
+		return true
+	}
+	for _, suffix := range []string{"_key", "_secret", "_token", "_password"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskSecret(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() == "" {
+			return ""
+		}
+		return Redacted
+	case reflect.Slice:
+		out := make([]string, v.Len())
+		for i := range out {
+			out[i] = Redacted
+		}
+		return out
+	default:
+		return Redacted
+	}
+}
+
+// redactDSN masks the password of a URL or key=value Postgres DSN.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+Redacted)
+}
@@ -17,8 +17,17 @@ var ErrForbidden = errors.New("forbidden")
 
 type User struct {
 	ID          int64    `json:"id"`
+	TenantID    int64    `json:"tenant_id"`
 	Email       string   `json:"email"`
 	Permissions []string `json:"permissions,omitempty"`
+	// ImpersonatorID is the administrator acting as this user, or zero
+	// when the user is acting for themself.
+	ImpersonatorID int64 `json:"impersonator_id,omitempty"`
+}
+
+// Impersonated reports whether an administrator is acting as the user.
+func (u *User) Impersonated() bool {
+	return u.ImpersonatorID != 0
 }
 
 func UserIDFromContext(ctx context.Context) string {
@@ -0,0 +1,180 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Locale string
+
+const (
+	English    Locale = "en"
+	Indonesian Locale = "id"
+
+	Default = English
+)
+
+// Params are substituted into {name} placeholders of a catalog message.
+// Field and Money values are rendered per locale.
+type Params map[string]interface{}
+
+// Field is a field name rendered through its "field.<name>" catalog label,
+// falling back to the name itself.
+type Field string
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+//go:embed catalog/*.json
+var catalogFS embed.FS
+
+var catalogs = mustLoad(English, Indonesian)
+
+func mustLoad(locales ...Locale) map[Locale]map[string]string {
+	out := make(map[Locale]map[string]string, len(locales))
+	for _, loc := range locales {
+		raw, err := catalogFS.ReadFile("catalog/" + string(loc) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", loc, err))
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", loc, err))
+		}
+		out[loc] = messages
+	}
+	return out
+}
+
+func Supported() []Locale {
+	return []Locale{English, Indonesian}
+}
+
+// lookup falls back to the default locale and then to the key itself.
+func lookup(loc Locale, key string) (string, bool) {
+	if msg, ok := catalogs[loc][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
+
+func Translate(loc Locale, key string, params Params) string {
+	msg, ok := lookup(loc, key)
+	if !ok {
+		return key
+	}
+	if len(params) == 0 {
+		return msg
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", format(loc, value))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+func format(loc Locale, value interface{}) string {
+	switch v := value.(type) {
+	case Field:
+		if label, ok := lookup(loc, "field."+string(v)); ok {
+			return label
+		}
+		return string(v)
+	case Money:
+		return FormatMoney(loc, v)
+	case int:
+		return FormatNumber(loc, int64(v))
+	case int64:
+		return FormatNumber(loc, v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// FormatNumber groups thousands with the locale's separator.
+func FormatNumber(loc Locale, n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	sep, _ := lookup(loc, "number.group")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+func FormatMoney(loc Locale, m Money) string {
+	return Translate(loc, "money.pattern", Params{
+		"amount":   FormatNumber(loc, m.Amount),
+		"currency": m.Currency,
+	})
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// honouring q-values and matching on the primary language subtag.
+func Negotiate(acceptLanguage string) Locale {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := parseLanguageRange(part)
+		if q <= bestQ {
+			continue
+		}
+		if tag == "*" {
+			best, bestQ = Default, q
+			continue
+		}
+		primary, _, _ := strings.Cut(tag, "-")
+		for _, loc := range Supported() {
+			if primary == string(loc) {
+				best, bestQ = loc, q
+				break
+			}
+		}
+	}
+	return best
+}
+
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	q := 1.0
+	for _, p := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if ok && strings.EqualFold(name, "q") {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", 0
+			}
+			q = parsed
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(tag)), q
+}
+
+type contextKey struct{}
+
+func WithLocale(ctx context.Context, loc Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, loc)
+}
+
+func FromContext(ctx context.Context) Locale {
+	if loc, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return loc
+	}
+	return Default
+}
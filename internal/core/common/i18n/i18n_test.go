@@ -0,0 +1,69 @@
+package i18n_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+)
+
+var _ = Describe("i18n", func() {
+	DescribeTable("Negotiate",
+		func(header string, want i18n.Locale) {
+			Expect(i18n.Negotiate(header)).To(Equal(want))
+		},
+		Entry("empty header", "", i18n.English),
+		Entry("region subtag", "id-ID", i18n.Indonesian),
+		Entry("q-values", "en;q=0.4, id;q=0.9", i18n.Indonesian),
+		Entry("unsupported first choice", "fr-FR, id;q=0.5", i18n.Indonesian),
+		Entry("rejected language", "id;q=0, en;q=0.1", i18n.English),
+		Entry("wildcard", "fr, *;q=0.5", i18n.English),
+	)
+
+	It("formats money per locale", func() {
+		m := i18n.Money{Amount: 50000000, Currency: "IDR"}
+		Expect(i18n.FormatMoney(i18n.English, m)).To(Equal("50,000,000 IDR"))
+		Expect(i18n.FormatMoney(i18n.Indonesian, m)).To(Equal("IDR 50.000.000"))
+	})
+
+	It("renders field labels and falls back to the default catalog and key", func() {
+		params := i18n.Params{"field": i18n.Field("amount_idr")}
+		Expect(i18n.Translate(i18n.English, "validation.required", params)).To(Equal("amount is required"))
+		Expect(i18n.Translate(i18n.Indonesian, "validation.required", params)).To(Equal("jumlah wajib diisi"))
+		Expect(i18n.Translate(i18n.Locale("fr"), "validation.required", params)).To(Equal("amount is required"))
+		Expect(i18n.Translate(i18n.English, "no.such.key", nil)).To(Equal("no.such.key"))
+	})
+
+	Describe("localized validation errors", func() {
+		validate := func(amount int64) *errors.AppError {
+			v := validation.NewValidator()
+			v.Field("amount_idr", amount).
+				Currency("IDR").
+				Required().
+				MinInt(10000, errors.ErrCodeAmountTooLow)
+			return v.Validate()
+		}
+
+		It("renders in English by default", func() {
+			appErr := validate(5000)
+			Expect(appErr.Error()).To(Equal("amount must be at least 10,000 IDR"))
+		})
+
+		It("re-renders messages in the requested locale without mutating the original", func() {
+			appErr := validate(5000)
+			localized := appErr.Localize(i18n.Indonesian)
+
+			Expect(localized.Message).To(Equal("Validasi gagal"))
+			details := localized.Details.(errors.ValidationErrors)
+			Expect(details.Errors[0].Message).To(Equal("jumlah minimal IDR 10.000"))
+			Expect(details.Errors[0].Code).To(Equal(string(errors.ErrCodeAmountTooLow)))
+			Expect(appErr.Error()).To(Equal("amount must be at least 10,000 IDR"))
+		})
+
+		It("leaves non-validation errors untouched", func() {
+			Expect(errors.ErrExpenseNotFound.Localize(i18n.Indonesian)).To(BeIdenticalTo(errors.ErrExpenseNotFound))
+		})
+	})
+})
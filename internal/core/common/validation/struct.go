@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+)
+
+// Rule applies one entry of a `validate:"..."` tag to a field. param is the
+// text after "=", or empty.
+type Rule func(fv *FieldValidator, param string)
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{
+		"required":  func(fv *FieldValidator, _ string) { fv.Required() },
+		"notfuture": func(fv *FieldValidator, _ string) { fv.NotFuture() },
+		"currency":  func(fv *FieldValidator, code string) { fv.Currency(code) },
+		"oneof":     func(fv *FieldValidator, values string) { fv.OneOf(strings.Fields(values)...) },
+		"min":       minRule,
+		"max":       maxRule,
+	}
+)
+
+// RegisterRule adds a domain-specific tag rule, typically from an init func.
+// Registering the same name twice panics.
+func RegisterRule(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if _, exists := rules[name]; exists {
+		panic(fmt.Sprintf("validation: rule %q already registered", name))
+	}
+	rules[name] = rule
+}
+
+func lookupRule(name string) (Rule, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+// min and max bound numbers by value and strings by length.
+func minRule(fv *FieldValidator, param string) {
+	n := mustInt(fv, "min", param)
+	if _, ok := fv.Value.(string); ok {
+		fv.MinLength(int(n))
+		return
+	}
+	fv.MinInt(n, errors.ErrCodeValidationFailed)
+}
+
+func maxRule(fv *FieldValidator, param string) {
+	n := mustInt(fv, "max", param)
+	if _, ok := fv.Value.(string); ok {
+		fv.MaxLength(int(n))
+		return
+	}
+	fv.MaxInt(n, errors.ErrCodeValidationFailed)
+}
+
+func mustInt(fv *FieldValidator, rule, param string) int64 {
+	n, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: %s=%q on %s is not an integer", rule, param, fv.FieldName))
+	}
+	return n
+}
+
+// Struct validates v using its `validate:` tags and reports errors under the
+// field's json name, in the same shape as ValidationBuilder.Validate.
+// "omitempty" skips the remaining rules for a zero value. Unknown rules panic
+// since they are programming errors.
+func Struct(v interface{}) *errors.AppError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: Struct called with %T", v))
+	}
+
+	validator := NewValidator()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok || tag == "" || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		value := rv.Field(i)
+		fv := validator.Field(fieldName(sf), normalize(value))
+		for _, entry := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(entry, "=")
+			if name == "omitempty" {
+				if value.IsZero() {
+					break
+				}
+				continue
+			}
+
+			rule, ok := lookupRule(name)
+			if !ok {
+				panic(fmt.Sprintf("validation: unknown rule %q on %s.%s", name, rt.Name(), sf.Name))
+			}
+			rule(fv, param)
+		}
+	}
+
+	return validator.Validate()
+}
+
+func fieldName(sf reflect.StructField) string {
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return sf.Name
+}
+
+// normalize widens integer kinds to int64, which the numeric validators expect.
+func normalize(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.String:
+		return v.String()
+	}
+	return v.Interface()
+}
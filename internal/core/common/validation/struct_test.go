@@ -0,0 +1,92 @@
+package validation_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+)
+
+type sampleDTO struct {
+	Amount   int64     `json:"amount" validate:"currency=IDR,required,min=1000,max=5000"`
+	Name     string    `json:"name" validate:"required,max=5"`
+	Kind     string    `json:"kind" validate:"omitempty,oneof=a b"`
+	When     time.Time `json:"when" validate:"notfuture"`
+	Count    int       `json:"count" validate:"min=2"`
+	Optional *string   `json:"optional,omitempty"`
+	Code     string    `validate:"sample_code"`
+}
+
+func fieldErrors(appErr *errors.AppError) map[string]string {
+	Expect(appErr).NotTo(BeNil())
+	details := appErr.Details.(errors.ValidationErrors)
+	out := map[string]string{}
+	for _, e := range details.Errors {
+		if _, seen := out[e.Field]; !seen {
+			out[e.Field] = e.Message
+		}
+	}
+	return out
+}
+
+func init() {
+	validation.RegisterRule("sample_code", func(fv *validation.FieldValidator, _ string) {
+		fv.Custom(func(v interface{}) *errors.AppError {
+			if v.(string) != "ok" {
+				return errors.NewValidationFieldError("Code", "code must be ok", errors.ErrCodeValidationFailed)
+			}
+			return nil
+		})
+	})
+}
+
+var _ = Describe("Struct", func() {
+	valid := func() sampleDTO {
+		return sampleDTO{Amount: 2000, Name: "abc", When: time.Now().Add(-time.Hour), Count: 3, Code: "ok"}
+	}
+
+	It("accepts a valid struct and pointers to it", func() {
+		dto := valid()
+		Expect(validation.Struct(dto)).To(BeNil())
+		Expect(validation.Struct(&dto)).To(BeNil())
+	})
+
+	It("reports every failing field under its json name", func() {
+		dto := sampleDTO{Amount: 9000, Name: "too long", Kind: "c", When: time.Now().Add(time.Hour), Count: 1, Code: "no"}
+
+		Expect(fieldErrors(validation.Struct(dto))).To(Equal(map[string]string{
+			"amount": "amount must not exceed 5,000 IDR",
+			"name":   "name must not exceed 5 characters",
+			"kind":   "kind must be one of a, b",
+			"when":   "when cannot be in the future",
+			"count":  "count must be at least 2",
+			"Code":   "code must be ok",
+		}))
+	})
+
+	It("uses the money message for a missing amount", func() {
+		dto := valid()
+		dto.Amount = 0
+		Expect(fieldErrors(validation.Struct(dto))["amount"]).To(Equal("amount must be positive"))
+	})
+
+	It("skips omitempty fields when zero", func() {
+		dto := valid()
+		dto.Kind = ""
+		Expect(validation.Struct(dto)).To(BeNil())
+	})
+
+	It("rejects duplicate rule names", func() {
+		Expect(func() { validation.RegisterRule("required", nil) }).To(Panic())
+	})
+
+	It("panics on unknown rules", func() {
+		type broken struct {
+			Name string `validate:"no_such_rule"`
+		}
+		Expect(func() { validation.Struct(broken{}) }).To(PanicWith(ContainSubstring("no_such_rule")))
+	})
+})
@@ -1,10 +1,11 @@
 package validation
 
 import (
-	"fmt"
+	"strings"
 	"time"
 
 	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
 )
 
 type ValidatorFunc func(interface{}) *errors.AppError
@@ -13,6 +14,8 @@ type FieldValidator struct {
 	FieldName  string
 	Value      interface{}
 	Validators []ValidatorFunc
+
+	currency string
 }
 
 type ValidationBuilder struct {
@@ -37,24 +40,44 @@ func (v *ValidationBuilder) Field(name string, value interface{}) *FieldValidato
 	return &v.fields[len(v.fields)-1]
 }
 
+// Currency marks the field as a money amount so limits render as currency.
+func (fv *FieldValidator) Currency(code string) *FieldValidator {
+	fv.currency = code
+	return fv
+}
+
+func (fv *FieldValidator) fail(key string, params i18n.Params, code errors.ErrorCode) *errors.AppError {
+	return errors.NewLocalizedFieldError(fv.FieldName, key, params, code)
+}
+
+func (fv *FieldValidator) amount(n int64) interface{} {
+	if fv.currency == "" {
+		return n
+	}
+	return i18n.Money{Amount: n, Currency: fv.currency}
+}
+
 func (fv *FieldValidator) Required() *FieldValidator {
 	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
 		switch v := value.(type) {
 		case string:
 			if v == "" {
-				return errors.NewValidationFieldError(fv.FieldName, fmt.Sprintf("%s is required", fv.FieldName), errors.ErrCodeValidationFailed)
+				return fv.fail("validation.required", nil, errors.ErrCodeValidationFailed)
 			}
 		case int64:
 			if v == 0 {
-
-				if fv.FieldName == "amount_idr" {
-					return errors.NewValidationFieldError(fv.FieldName, "amount must be positive", errors.ErrCodeValidationFailed)
+				if fv.currency != "" {
+					return fv.fail("validation.positive", nil, errors.ErrCodeValidationFailed)
 				}
-				return errors.NewValidationFieldError(fv.FieldName, fmt.Sprintf("%s is required", fv.FieldName), errors.ErrCodeValidationFailed)
+				return fv.fail("validation.required", nil, errors.ErrCodeValidationFailed)
 			}
 		case *string:
 			if v == nil || *v == "" {
-				return errors.NewValidationFieldError(fv.FieldName, fmt.Sprintf("%s is required", fv.FieldName), errors.ErrCodeValidationFailed)
+				return fv.fail("validation.required", nil, errors.ErrCodeValidationFailed)
+			}
+		case time.Time:
+			if v.IsZero() {
+				return fv.fail("validation.required", nil, errors.ErrCodeValidationFailed)
 			}
 		}
 		return nil
@@ -64,21 +87,8 @@ func (fv *FieldValidator) Required() *FieldValidator {
 
 func (fv *FieldValidator) MinInt(min int64, code errors.ErrorCode) *FieldValidator {
 	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
-		if v, ok := value.(int64); ok {
-			if v < min {
-				var message string
-
-				if fv.FieldName == "amount_idr" {
-					if min == 10000 {
-						message = "amount must be at least 10,000 IDR"
-					} else {
-						message = fmt.Sprintf("amount must be at least %d", min)
-					}
-				} else {
-					message = fmt.Sprintf("%s must be at least %d", fv.FieldName, min)
-				}
-				return errors.NewValidationFieldError(fv.FieldName, message, code)
-			}
+		if v, ok := value.(int64); ok && v < min {
+			return fv.fail("validation.min", i18n.Params{"min": fv.amount(min)}, code)
 		}
 		return nil
 	})
@@ -87,17 +97,8 @@ func (fv *FieldValidator) MinInt(min int64, code errors.ErrorCode) *FieldValidat
 
 func (fv *FieldValidator) MaxInt(max int64, code errors.ErrorCode) *FieldValidator {
 	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
-		if v, ok := value.(int64); ok {
-			if v > max {
-				var message string
-
-				if fv.FieldName == "amount_idr" && max == 50000000 {
-					message = "amount must not exceed 50,000,000 IDR"
-				} else {
-					message = fmt.Sprintf("%s must not exceed %d", fv.FieldName, max)
-				}
-				return errors.NewValidationFieldError(fv.FieldName, message, code)
-			}
+		if v, ok := value.(int64); ok && v > max {
+			return fv.fail("validation.max", i18n.Params{"max": fv.amount(max)}, code)
 		}
 		return nil
 	})
@@ -106,11 +107,8 @@ func (fv *FieldValidator) MaxInt(max int64, code errors.ErrorCode) *FieldValidat
 
 func (fv *FieldValidator) MinLength(min int) *FieldValidator {
 	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
-		if v, ok := value.(string); ok {
-			if len(v) < min {
-				message := fmt.Sprintf("%s must be at least %d characters", fv.FieldName, min)
-				return errors.NewValidationFieldError(fv.FieldName, message, errors.ErrCodeValidationFailed)
-			}
+		if v, ok := value.(string); ok && len(v) < min {
+			return fv.fail("validation.min_length", i18n.Params{"min": min}, errors.ErrCodeValidationFailed)
 		}
 		return nil
 	})
@@ -119,25 +117,35 @@ func (fv *FieldValidator) MinLength(min int) *FieldValidator {
 
 func (fv *FieldValidator) MaxLength(max int) *FieldValidator {
 	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
-		if v, ok := value.(string); ok {
-			if len(v) > max {
-				message := fmt.Sprintf("%s must not exceed %d characters", fv.FieldName, max)
-				return errors.NewValidationFieldError(fv.FieldName, message, errors.ErrCodeValidationFailed)
-			}
+		if v, ok := value.(string); ok && len(v) > max {
+			return fv.fail("validation.max_length", i18n.Params{"max": max}, errors.ErrCodeValidationFailed)
 		}
 		return nil
 	})
 	return fv
 }
 
-func (fv *FieldValidator) NotFuture() *FieldValidator {
+func (fv *FieldValidator) OneOf(allowed ...string) *FieldValidator {
 	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
-		if v, ok := value.(time.Time); ok {
-			if v.After(time.Now()) {
-				message := fmt.Sprintf("%s cannot be in the future", fv.FieldName)
-				return errors.NewValidationFieldError(fv.FieldName, message, errors.ErrCodeInvalidDate)
+		v, ok := value.(string)
+		if !ok || v == "" {
+			return nil
+		}
+		for _, a := range allowed {
+			if v == a {
+				return nil
 			}
 		}
+		return fv.fail("validation.oneof", i18n.Params{"values": strings.Join(allowed, ", ")}, errors.ErrCodeValidationFailed)
+	})
+	return fv
+}
+
+func (fv *FieldValidator) NotFuture() *FieldValidator {
+	fv.Validators = append(fv.Validators, func(value interface{}) *errors.AppError {
+		if v, ok := value.(time.Time); ok && v.After(time.Now()) {
+			return fv.fail("validation.not_future", nil, errors.ErrCodeInvalidDate)
+		}
 		return nil
 	})
 	return fv
@@ -183,20 +191,23 @@ func (v *ValidationBuilder) Validate() *errors.AppError {
 	}
 
 	if len(validationErrors) > 0 {
-		return errors.NewValidationError("Validation failed", errors.ErrCodeValidationFailed).
+		return errors.NewValidationError(i18n.Translate(i18n.Default, "validation.failed", nil), errors.ErrCodeValidationFailed).
 			WithDetails(errors.ValidationErrors{Errors: validationErrors})
 	}
 
 	return nil
 }
 
-func ValidateExpenseAmount(amount int64) *errors.AppError {
+// ValidateExpenseAmount checks a rupiah amount against the claim limits of
+// its tenant and category.
+func ValidateExpenseAmount(amount, min, max int64) *errors.AppError {
 	validator := NewValidator()
 	validator.Field("amount_idr", amount).
+		Currency("IDR").
 		Required().
 		MinInt(1, errors.ErrCodeInvalidAmount).
-		MinInt(10000, errors.ErrCodeAmountTooLow).
-		MaxInt(50000000, errors.ErrCodeAmountTooHigh)
+		MinInt(min, errors.ErrCodeAmountTooLow).
+		MaxInt(max, errors.ErrCodeAmountTooHigh)
 	return validator.Validate()
 }
 
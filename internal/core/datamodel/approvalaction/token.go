@@ -0,0 +1,17 @@
+package approvalaction
+
+import "time"
+
+type Token struct {
+	ID         string     `gorm:"primaryKey;column:id"`
+	ExpenseID  int64      `gorm:"column:expense_id;not null"`
+	ApproverID int64      `gorm:"column:approver_id;not null"`
+	Action     string     `gorm:"column:action;not null"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt     *time.Time `gorm:"column:used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Token) TableName() string {
+	return "approval_action_tokens"
+}
@@ -0,0 +1,16 @@
+package approvalrouting
+
+import "time"
+
+type Rule struct {
+	TenantID           int64     `gorm:"primaryKey;column:tenant_id;autoIncrement:false"`
+	Category           string    `gorm:"primaryKey;column:category"`
+	ApproverPermission string    `gorm:"column:approver_permission;not null"`
+	RequireApproval    bool      `gorm:"column:require_approval;not null;default:true"`
+	CreatedAt          time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Rule) TableName() string {
+	return "approval_routing_rules"
+}
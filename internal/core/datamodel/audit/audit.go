@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLog struct {
+	ID           int64           `gorm:"primaryKey"`
+	Action       string          `gorm:"column:action;not null"`
+	ResourceType string          `gorm:"column:resource_type;not null"`
+	ResourceID   string          `gorm:"column:resource_id;not null"`
+	ActorID      *int64          `gorm:"column:actor_id"`
+	Metadata     json.RawMessage `gorm:"column:metadata;type:jsonb"`
+	CreatedAt    time.Time       `gorm:"column:created_at"`
+	// Seq numbers chained entries from 1 without gaps; it is nil for
+	// entries written before the hash chain.
+	Seq      *int64 `gorm:"column:seq"`
+	PrevHash string `gorm:"column:prev_hash;not null;default:''"`
+	Hash     string `gorm:"column:hash;not null;default:''"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,29 @@
+package bankaccount
+
+import (
+	"time"
+
+	// Registers the encrypted serializer.
+	_ "github.com/frahmantamala/expense-management/internal/core/encryption"
+)
+
+type Account struct {
+	ID                 int64      `gorm:"primaryKey"`
+	UserID             int64      `gorm:"column:user_id;not null"`
+	BankCode           string     `gorm:"column:bank_code;not null"`
+	AccountHolder      string     `gorm:"column:account_holder;not null"`
+	AccountNumber      string     `gorm:"column:account_number_encrypted;serializer:encrypted;not null"`
+	AccountNumberLast4 string     `gorm:"column:account_number_last4;not null"`
+	VerificationStatus string     `gorm:"column:verification_status;not null;default:unverified"`
+	VerificationNote   *string    `gorm:"column:verification_note"`
+	VerifiedBy         *int64     `gorm:"column:verified_by"`
+	VerifiedAt         *time.Time `gorm:"column:verified_at"`
+	IsDefault          bool       `gorm:"column:is_default;not null;default:false"`
+	DeletedAt          *time.Time `gorm:"column:deleted_at"`
+	CreatedAt          time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Account) TableName() string {
+	return "user_bank_accounts"
+}
@@ -0,0 +1,17 @@
+package calendar
+
+import "time"
+
+// Day overrides the working calendar for one day of a tenant.
+type Day struct {
+	TenantID  int64     `gorm:"primaryKey;column:tenant_id;autoIncrement:false"`
+	Day       time.Time `gorm:"primaryKey;column:day;type:date"`
+	Kind      string    `gorm:"column:kind;not null"`
+	Name      string    `gorm:"column:name;not null;default:''"`
+	SetBy     int64     `gorm:"column:set_by;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Day) TableName() string {
+	return "calendar_days"
+}
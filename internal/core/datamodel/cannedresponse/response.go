@@ -0,0 +1,20 @@
+package cannedresponse
+
+import "time"
+
+// Response is a stock rejection comment filed under a rejection code.
+type Response struct {
+	ID         int64      `gorm:"primaryKey"`
+	TenantID   int64      `gorm:"column:tenant_id;not null;default:1"`
+	Code       string     `gorm:"column:code;not null"`
+	Title      string     `gorm:"column:title;not null"`
+	Body       string     `gorm:"column:body;not null"`
+	CreatedBy  int64      `gorm:"column:created_by;not null"`
+	ArchivedAt *time.Time `gorm:"column:archived_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Response) TableName() string {
+	return "canned_responses"
+}
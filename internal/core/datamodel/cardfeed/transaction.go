@@ -0,0 +1,25 @@
+package cardfeed
+
+import "time"
+
+// Transaction is one corporate card charge imported from the card issuer's
+// feed, linked to the expense it was claimed on once matched.
+type Transaction struct {
+	ID              int64      `gorm:"primaryKey"`
+	TenantID        int64      `gorm:"column:tenant_id;not null;default:1"`
+	ExternalID      string     `gorm:"column:external_id;not null"`
+	CardholderEmail string     `gorm:"column:cardholder_email;not null"`
+	UserID          *int64     `gorm:"column:user_id"`
+	CardLast4       string     `gorm:"column:card_last4;not null;default:''"`
+	Merchant        string     `gorm:"column:merchant;not null"`
+	AmountIDR       int64      `gorm:"column:amount_idr;not null"`
+	TransactionDate time.Time  `gorm:"column:transaction_date;type:date"`
+	Status          string     `gorm:"column:status;not null;default:unmatched"`
+	ExpenseID       *int64     `gorm:"column:expense_id"`
+	MatchedAt       *time.Time `gorm:"column:matched_at"`
+	ImportedAt      time.Time  `gorm:"column:imported_at;autoCreateTime"`
+}
+
+func (Transaction) TableName() string {
+	return "card_transactions"
+}
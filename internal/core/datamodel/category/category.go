@@ -4,8 +4,10 @@ import "time"
 
 type ExpenseCategory struct {
 	ID          int64     `gorm:"primaryKey"`
-	Name        string    `gorm:"column:name;uniqueIndex;not null"`
+	TenantID    int64     `gorm:"column:tenant_id;not null;default:1;uniqueIndex:idx_category_tenant_name"`
+	Name        string    `gorm:"column:name;not null;uniqueIndex:idx_category_tenant_name"`
 	Description string    `gorm:"column:description"`
+	ParentID    *int64    `gorm:"column:parent_id;index"`
 	IsActive    bool      `gorm:"column:is_active;default:true"`
 	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
@@ -0,0 +1,37 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Change is a row inserted into or updated in a captured table, written by
+// the record_outbound_change trigger.
+type Change struct {
+	ID          int64           `gorm:"primaryKey"`
+	TenantID    int64           `gorm:"column:tenant_id;not null;default:1"`
+	SourceTable string          `gorm:"column:source_table;not null"`
+	RowID       int64           `gorm:"column:row_id;not null"`
+	Operation   string          `gorm:"column:operation;not null"`
+	Data        json.RawMessage `gorm:"column:data;type:jsonb;not null"`
+	Diff        json.RawMessage `gorm:"column:diff;type:jsonb"`
+	ChangedAt   time.Time       `gorm:"column:changed_at;autoCreateTime"`
+}
+
+func (Change) TableName() string {
+	return "outbound_changes"
+}
+
+// Consumer is the ID of the last change a named consumer has committed.
+type Consumer struct {
+	ID           int64     `gorm:"primaryKey"`
+	TenantID     int64     `gorm:"column:tenant_id;not null;default:1;uniqueIndex:idx_change_consumers_name"`
+	Name         string    `gorm:"column:name;not null;uniqueIndex:idx_change_consumers_name"`
+	LastChangeID int64     `gorm:"column:last_change_id;not null;default:0"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Consumer) TableName() string {
+	return "change_consumers"
+}
@@ -0,0 +1,21 @@
+package delegation
+
+import "time"
+
+// Grant lets DelegateID file expenses for PrincipalID until it expires or is
+// revoked.
+type Grant struct {
+	ID          int64      `gorm:"primaryKey"`
+	TenantID    int64      `gorm:"column:tenant_id;not null;default:1"`
+	PrincipalID int64      `gorm:"column:principal_id;not null"`
+	DelegateID  int64      `gorm:"column:delegate_id;not null"`
+	GrantedBy   int64      `gorm:"column:granted_by;not null"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at"`
+	RevokedAt   *time.Time `gorm:"column:revoked_at"`
+	RevokedBy   *int64     `gorm:"column:revoked_by"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Grant) TableName() string {
+	return "delegation_grants"
+}
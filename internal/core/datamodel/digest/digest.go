@@ -0,0 +1,15 @@
+package digest
+
+import "time"
+
+// Preference rows are optional: a user without one receives every digest.
+type Preference struct {
+	UserID         int64     `gorm:"primaryKey;column:user_id"`
+	ManagerDaily   bool      `gorm:"column:manager_daily;not null;default:true"`
+	EmployeeWeekly bool      `gorm:"column:employee_weekly;not null;default:true"`
+	UpdatedAt      time.Time `gorm:"column:updated_at"`
+}
+
+func (Preference) TableName() string {
+	return "digest_preferences"
+}
@@ -0,0 +1,17 @@
+package exchangerate
+
+import "time"
+
+type Rate struct {
+	TenantID  int64     `gorm:"primaryKey;column:tenant_id;autoIncrement:false"`
+	Currency  string    `gorm:"primaryKey;column:currency"`
+	RateDate  time.Time `gorm:"primaryKey;column:rate_date;type:date"`
+	Rate      string    `gorm:"column:rate;type:numeric(24,10);not null"`
+	Source    string    `gorm:"column:source;not null"`
+	SetBy     *int64    `gorm:"column:set_by"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Rate) TableName() string {
+	return "exchange_rates"
+}
@@ -3,19 +3,61 @@ package expense
 import "time"
 
 type Expense struct {
-	ID              int64      `gorm:"primaryKey"`
-	UserID          int64      `gorm:"column:user_id;not null"`
-	AmountIDR       int64      `gorm:"column:amount_idr;not null"`
-	Description     string     `gorm:"not null"`
-	Category        string     `gorm:"column:category"`
-	ReceiptURL      *string    `gorm:"column:receipt_url"`
-	ReceiptFileName *string    `gorm:"column:receipt_filename"`
-	ExpenseStatus   string     `gorm:"column:expense_status;default:pending_approval"`
-	ExpenseDate     time.Time  `gorm:"column:expense_date;type:date"`
-	SubmittedAt     time.Time  `gorm:"column:submitted_at"`
-	ProcessedAt     *time.Time `gorm:"column:processed_at"`
-	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt       time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	ID               int64      `gorm:"primaryKey"`
+	TenantID         int64      `gorm:"column:tenant_id;not null;default:1"`
+	UserID           int64      `gorm:"column:user_id;not null"`
+	AmountIDR        int64      `gorm:"column:amount_idr;not null"`
+	Description      string     `gorm:"not null"`
+	Category         string     `gorm:"column:category"`
+	ReceiptURL       *string    `gorm:"column:receipt_url"`
+	ReceiptFileName  *string    `gorm:"column:receipt_filename"`
+	ReceiptKey       *string    `gorm:"column:receipt_key"`
+	PayoutAccountID  *int64     `gorm:"column:payout_account_id"`
+	ExpenseStatus    string     `gorm:"column:expense_status;default:pending_approval"`
+	RejectionCode    *string    `gorm:"column:rejection_code"`
+	RejectionReason  *string    `gorm:"column:rejection_reason"`
+	CannedResponseID *int64     `gorm:"column:canned_response_id"`
+	ApprovedBy       *int64     `gorm:"column:approved_by"`
+	ApprovedAt       *time.Time `gorm:"column:approved_at"`
+	RejectedBy       *int64     `gorm:"column:rejected_by"`
+	ExpenseDate      time.Time  `gorm:"column:expense_date;type:date"`
+	SubmittedAt      time.Time  `gorm:"column:submitted_at"`
+	ProcessedAt      *time.Time `gorm:"column:processed_at"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+
+	// Set only on expenses submitted in another currency.
+	OriginalAmount       *int64     `gorm:"column:original_amount"`
+	OriginalCurrency     *string    `gorm:"column:original_currency"`
+	ExchangeRate         *string    `gorm:"column:exchange_rate;type:numeric(24,10)"`
+	ExchangeRateSource   *string    `gorm:"column:exchange_rate_source"`
+	ExchangeRateDate     *time.Time `gorm:"column:exchange_rate_date;type:date"`
+	ExchangeRateQuotedAt *time.Time `gorm:"column:exchange_rate_quoted_at"`
+
+	// Set only when the approver approved less than AmountIDR.
+	ApprovedAmountIDR *int64  `gorm:"column:approved_amount_idr"`
+	AdjustmentReason  *string `gorm:"column:adjustment_reason"`
+
+	// Set by the receipt thumbnail worker and cleared with a new receipt.
+	ReceiptThumbnailKey   *string `gorm:"column:receipt_thumbnail_key"`
+	ReceiptThumbnailError *string `gorm:"column:receipt_thumbnail_error"`
+
+	// Set on uploaded receipts; receipts linked by URL are not hashed.
+	ReceiptSHA256 *string `gorm:"column:receipt_sha256"`
+
+	// Set only when a delegate filed the expense for UserID.
+	SubmittedBy *int64 `gorm:"column:submitted_by"`
+
+	// Payment is loaded by list queries that ask for it and is never stored
+	// with the expense.
+	Payment *PaymentSummary `gorm:"-"`
+}
+
+// PaymentSummary is the state of an expense's latest payment.
+type PaymentSummary struct {
+	Status      string
+	RetryCount  int
+	ProcessedAt *time.Time
 }
 
 type ExpenseCategory struct {
@@ -23,5 +65,5 @@ type ExpenseCategory struct {
 	Name        string    `gorm:"column:name;not null"`
 	Description string    `gorm:"column:description"`
 	IsActive    bool      `gorm:"column:is_active;default:true"`
-	CreatedAt   time.Time `gorm:"column:created_at;default:now()"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
 }
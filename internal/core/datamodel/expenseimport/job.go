@@ -0,0 +1,28 @@
+package expenseimport
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Job struct {
+	ID            int64           `gorm:"primaryKey"`
+	TenantID      int64           `gorm:"column:tenant_id;not null;default:1"`
+	UserID        int64           `gorm:"column:user_id;not null"`
+	FileName      string          `gorm:"column:file_name;not null"`
+	BlobKey       string          `gorm:"column:blob_key;not null"`
+	Status        string          `gorm:"column:status;not null;default:queued"`
+	TotalRows     *int            `gorm:"column:total_rows"`
+	ProcessedRows int             `gorm:"column:processed_rows;not null;default:0"`
+	ImportedRows  int             `gorm:"column:imported_rows;not null;default:0"`
+	FailedRows    int             `gorm:"column:failed_rows;not null;default:0"`
+	RowErrors     json.RawMessage `gorm:"column:row_errors;type:jsonb"`
+	Error         *string         `gorm:"column:error"`
+	CreatedAt     time.Time       `gorm:"column:created_at;autoCreateTime"`
+	StartedAt     *time.Time      `gorm:"column:started_at"`
+	CompletedAt   *time.Time      `gorm:"column:completed_at"`
+}
+
+func (Job) TableName() string {
+	return "expense_import_jobs"
+}
@@ -0,0 +1,20 @@
+package expensetemplate
+
+import "time"
+
+// Template holds a user's defaults for an expense they claim often.
+type Template struct {
+	ID          int64     `gorm:"primaryKey"`
+	TenantID    int64     `gorm:"column:tenant_id;not null;default:1"`
+	UserID      int64     `gorm:"column:user_id;not null"`
+	Name        string    `gorm:"column:name;not null"`
+	Category    string    `gorm:"column:category;not null"`
+	AmountIDR   *int64    `gorm:"column:amount_idr"`
+	Description string    `gorm:"column:description;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Template) TableName() string {
+	return "expense_templates"
+}
@@ -0,0 +1,25 @@
+package export
+
+import "time"
+
+type Job struct {
+	ID          int64      `gorm:"primaryKey"`
+	TenantID    int64      `gorm:"column:tenant_id;not null;default:1"`
+	UserID      int64      `gorm:"column:user_id;not null"`
+	Resource    string     `gorm:"column:resource;not null"`
+	Format      string     `gorm:"column:format;not null"`
+	RangeFrom   time.Time  `gorm:"column:range_from;not null"`
+	RangeTo     time.Time  `gorm:"column:range_to;not null"`
+	Status      string     `gorm:"column:status;not null;default:queued"`
+	BlobKey     *string    `gorm:"column:blob_key"`
+	RowCount    *int       `gorm:"column:row_count"`
+	Error       *string    `gorm:"column:error"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	StartedAt   *time.Time `gorm:"column:started_at"`
+	CompletedAt *time.Time `gorm:"column:completed_at"`
+}
+
+func (Job) TableName() string {
+	return "export_jobs"
+}
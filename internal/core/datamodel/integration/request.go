@@ -0,0 +1,24 @@
+package integration
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Request records an integration call under its idempotency key so a retry
+// gets the original outcome instead of deciding twice.
+type Request struct {
+	ID             int64           `gorm:"primaryKey"`
+	TenantID       int64           `gorm:"column:tenant_id;not null;default:1"`
+	Client         string          `gorm:"column:client;not null"`
+	IdempotencyKey string          `gorm:"column:idempotency_key;not null"`
+	RequestHash    string          `gorm:"column:request_hash;not null"`
+	StatusCode     int             `gorm:"column:status_code;not null"`
+	Response       json.RawMessage `gorm:"column:response;type:jsonb"`
+	CreatedAt      time.Time       `gorm:"column:created_at;autoCreateTime"`
+	CompletedAt    *time.Time      `gorm:"column:completed_at"`
+}
+
+func (Request) TableName() string {
+	return "integration_requests"
+}
@@ -0,0 +1,23 @@
+package ledger
+
+import "time"
+
+// Entry is one leg of a double-entry posting. Every posting writes a debit
+// and a credit of the same amount under a shared reference.
+type Entry struct {
+	ID         int64     `gorm:"primaryKey"`
+	TenantID   int64     `gorm:"column:tenant_id;not null;default:1"`
+	Reference  string    `gorm:"column:reference;not null"`
+	EntryType  string    `gorm:"column:entry_type;not null"`
+	Account    string    `gorm:"column:account;not null"`
+	Direction  string    `gorm:"column:direction;not null"`
+	AmountIDR  int64     `gorm:"column:amount_idr;not null"`
+	ExpenseID  int64     `gorm:"column:expense_id;not null"`
+	PaymentID  *int64    `gorm:"column:payment_id"`
+	ExternalID *string   `gorm:"column:external_id"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Entry) TableName() string {
+	return "ledger_entries"
+}
@@ -0,0 +1,24 @@
+package payment
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CallbackInboxEntry struct {
+	ID            int64           `gorm:"primaryKey"`
+	DedupeKey     string          `gorm:"column:dedupe_key;not null;uniqueIndex"`
+	ExternalID    string          `gorm:"column:external_id;not null"`
+	Payload       json.RawMessage `gorm:"column:payload;type:jsonb;not null"`
+	Status        string          `gorm:"column:status;not null;default:pending"`
+	Attempts      int             `gorm:"column:attempts;not null;default:0"`
+	LastError     *string         `gorm:"column:last_error"`
+	NextAttemptAt time.Time       `gorm:"column:next_attempt_at;not null"`
+	ProcessedAt   *time.Time      `gorm:"column:processed_at"`
+	ReceivedAt    time.Time       `gorm:"column:received_at;not null"`
+	UpdatedAt     time.Time       `gorm:"column:updated_at"`
+}
+
+func (CallbackInboxEntry) TableName() string {
+	return "payment_callback_inbox"
+}
@@ -0,0 +1,32 @@
+package payment
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GatewayCall is one request sent to a payment gateway and what came back,
+// kept as evidence when a charge is disputed with the provider. Secrets are
+// redacted and bodies may be cut short before they are stored.
+type GatewayCall struct {
+	ID int64 `gorm:"primaryKey"`
+	// PaymentID is nil for calls about a payment this service has no row
+	// for, such as a status check for an unknown external ID.
+	PaymentID      *int64          `gorm:"column:payment_id"`
+	ExternalID     string          `gorm:"column:external_id;not null;default:''"`
+	Method         string          `gorm:"column:method;not null"`
+	URL            string          `gorm:"column:url;not null"`
+	RequestHeader  json.RawMessage `gorm:"column:request_header;type:jsonb"`
+	RequestBody    string          `gorm:"column:request_body"`
+	StatusCode     *int            `gorm:"column:status_code"`
+	ResponseHeader json.RawMessage `gorm:"column:response_header;type:jsonb"`
+	ResponseBody   string          `gorm:"column:response_body"`
+	// Error is why no response came back, such as a timeout.
+	Error     *string   `gorm:"column:error"`
+	LatencyMS int64     `gorm:"column:latency_ms;not null;default:0"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (GatewayCall) TableName() string {
+	return "gateway_calls"
+}
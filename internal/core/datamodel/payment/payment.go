@@ -3,19 +3,46 @@ package payment
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/money"
 )
 
 type Payment struct {
 	ID              int64           `gorm:"primaryKey"`
+	TenantID        int64           `gorm:"column:tenant_id;not null;default:1"`
 	ExpenseID       int64           `gorm:"column:expense_id;not null"`
 	ExternalID      string          `gorm:"column:external_id;not null;uniqueIndex"`
 	AmountIDR       int64           `gorm:"column:amount_idr;not null"`
+	SettledAmount   int64           `gorm:"column:settled_amount;not null;default:0"`
 	Status          string          `gorm:"column:status;default:pending"`
 	PaymentMethod   *string         `gorm:"column:payment_method"`
-	GatewayResponse json.RawMessage `gorm:"column:gateway_response;type:jsonb"`
+	Provider        *string         `gorm:"column:provider"`
+	GatewayResponse json.RawMessage `gorm:"column:gateway_response;type:jsonb;serializer:encrypted_json"`
 	FailureReason   *string         `gorm:"column:failure_reason"`
+	FailureClass    *string         `gorm:"column:failure_class"`
 	RetryCount      int             `gorm:"column:retry_count;default:0"`
+	NextRetryAt     *time.Time      `gorm:"column:next_retry_at"`
 	ProcessedAt     *time.Time      `gorm:"column:processed_at"`
-	CreatedAt       time.Time       `gorm:"column:created_at;default:now()"`
-	UpdatedAt       time.Time       `gorm:"column:updated_at;default:now()"`
+	CreatedAt       time.Time       `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time       `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// Amount is AmountIDR as Money; payments are made in rupiah until the
+// amount_idr column carries its own currency.
+func (p *Payment) Amount() money.Money {
+	return money.Rupiah(p.AmountIDR)
+}
+
+// Installment is one transfer towards a payment that settles in parts.
+type Installment struct {
+	ID               int64     `gorm:"primaryKey"`
+	PaymentID        int64     `gorm:"column:payment_id;not null;uniqueIndex:idx_installment_transfer"`
+	GatewayPaymentID string    `gorm:"column:gateway_payment_id;not null;uniqueIndex:idx_installment_transfer"`
+	AmountIDR        int64     `gorm:"column:amount_idr;not null"`
+	SettledAt        time.Time `gorm:"column:settled_at;not null"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Installment) TableName() string {
+	return "payment_installments"
 }
@@ -0,0 +1,21 @@
+package payment
+
+import "time"
+
+type PaymentJob struct {
+	ID          int64      `gorm:"primaryKey"`
+	ExternalID  string     `gorm:"column:external_id;not null;uniqueIndex"`
+	Status      string     `gorm:"column:status;not null;default:queued"`
+	WorkerID    *int       `gorm:"column:worker_id"`
+	Attempts    int        `gorm:"column:attempts;not null;default:0"`
+	LastError   *string    `gorm:"column:last_error"`
+	AmountIDR   *int64     `gorm:"column:amount_idr"`
+	GatewayID   *string    `gorm:"column:gateway_payment_id"`
+	Provider    *string    `gorm:"column:provider"`
+	QueuedAt    time.Time  `gorm:"column:queued_at"`
+	StartedAt   *time.Time `gorm:"column:started_at"`
+	CompletedAt *time.Time `gorm:"column:completed_at"`
+	FailedAt    *time.Time `gorm:"column:failed_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
@@ -1,7 +1,10 @@
 package paymentgateway
 
 import (
+	"encoding/json"
 	"errors"
+
+	"github.com/frahmantamala/expense-management/internal/core/money"
 )
 
 type PaymentStatus string
@@ -12,10 +15,34 @@ const (
 	PaymentStatusFailed  PaymentStatus = "FAILED"
 )
 
+// PaymentRequest keeps the gateway's flat amount and currency fields; build
+// it with NewPaymentRequest so the two always come from one Money.
 type PaymentRequest struct {
-	ExternalID string `json:"external_id"`
-	Amount     int64  `json:"amount"`
-	Currency   string `json:"currency"`
+	ExternalID string  `json:"external_id"`
+	Amount     int64   `json:"amount"`
+	Currency   string  `json:"currency"`
+	Payout     *Payout `json:"payout,omitempty"`
+}
+
+func NewPaymentRequest(externalID string, amount money.Money, payout *Payout) *PaymentRequest {
+	return &PaymentRequest{
+		ExternalID: externalID,
+		Amount:     amount.Amount,
+		Currency:   amount.Currency,
+		Payout:     payout,
+	}
+}
+
+// Money is the requested amount in minor units of its currency.
+func (r *PaymentRequest) Money() money.Money {
+	return money.New(r.Amount, r.Currency)
+}
+
+// Payout is the bank account the gateway transfers the reimbursement to.
+type Payout struct {
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountHolder string `json:"account_holder"`
 }
 
 func (r *PaymentRequest) Validate() error {
@@ -28,6 +55,9 @@ func (r *PaymentRequest) Validate() error {
 	if r.Currency == "" {
 		return errors.New("currency is required")
 	}
+	if !money.IsSupported(r.Currency) {
+		return errors.New("currency is not supported")
+	}
 	return nil
 }
 
@@ -35,6 +65,12 @@ type PaymentData struct {
 	ID         string        `json:"id"`
 	ExternalID string        `json:"external_id"`
 	Status     PaymentStatus `json:"status"`
+	// Provider names the configured provider that handled the payment.
+	Provider string `json:"provider,omitempty"`
+	// ResponseVersion names the adapter that decoded the gateway's answer
+	// and Raw is that answer as received, kept for debugging.
+	ResponseVersion string          `json:"response_version,omitempty"`
+	Raw             json.RawMessage `json:"raw,omitempty"`
 }
 
 type PaymentResponse struct {
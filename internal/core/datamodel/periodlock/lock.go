@@ -0,0 +1,15 @@
+package periodlock
+
+import "time"
+
+type Lock struct {
+	TenantID    int64     `gorm:"primaryKey;column:tenant_id;autoIncrement:false"`
+	PeriodStart time.Time `gorm:"primaryKey;column:period_start;type:date"`
+	Reason      string    `gorm:"column:reason;not null;default:''"`
+	LockedBy    int64     `gorm:"column:locked_by;not null"`
+	LockedAt    time.Time `gorm:"column:locked_at;autoCreateTime"`
+}
+
+func (Lock) TableName() string {
+	return "period_locks"
+}
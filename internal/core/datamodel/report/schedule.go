@@ -0,0 +1,28 @@
+package report
+
+import "time"
+
+// Schedule is a report emailed to a distribution list every day, week or
+// month.
+type Schedule struct {
+	ID         int64      `gorm:"primaryKey"`
+	TenantID   int64      `gorm:"column:tenant_id;not null;default:1"`
+	Name       string     `gorm:"column:name;not null"`
+	ReportType string     `gorm:"column:report_type;not null"`
+	Format     string     `gorm:"column:format;not null"`
+	Frequency  string     `gorm:"column:frequency;not null"`
+	Weekday    int        `gorm:"column:weekday;not null"`
+	Hour       int        `gorm:"column:hour;not null"`
+	Recipients []string   `gorm:"column:recipients;serializer:json;not null"`
+	Enabled    bool       `gorm:"column:enabled;not null"`
+	NextRunAt  time.Time  `gorm:"column:next_run_at;not null"`
+	LastRunAt  *time.Time `gorm:"column:last_run_at"`
+	LastError  *string    `gorm:"column:last_error"`
+	CreatedBy  int64      `gorm:"column:created_by;not null"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Schedule) TableName() string {
+	return "report_schedules"
+}
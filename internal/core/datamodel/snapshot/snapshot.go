@@ -0,0 +1,50 @@
+package snapshot
+
+import "time"
+
+// ExpenseFact totals the expenses submitted on FactDate that share a
+// category, submitter department and status.
+type ExpenseFact struct {
+	ID            int64     `gorm:"primaryKey"`
+	TenantID      int64     `gorm:"column:tenant_id;not null;default:1"`
+	FactDate      time.Time `gorm:"column:fact_date;type:date;not null"`
+	Category      string    `gorm:"column:category;not null"`
+	Department    string    `gorm:"column:department;not null"`
+	ExpenseStatus string    `gorm:"column:expense_status;not null"`
+	ExpenseCount  int64     `gorm:"column:expense_count;not null"`
+	AmountIDR     int64     `gorm:"column:amount_idr;not null"`
+	SnapshotAt    time.Time `gorm:"column:snapshot_at;not null"`
+}
+
+func (ExpenseFact) TableName() string {
+	return "reporting.daily_expense_facts"
+}
+
+// PaymentFact totals the payments created on FactDate.
+type PaymentFact struct {
+	ID               int64     `gorm:"primaryKey"`
+	TenantID         int64     `gorm:"column:tenant_id;not null;default:1"`
+	FactDate         time.Time `gorm:"column:fact_date;type:date;not null"`
+	PaymentCount     int64     `gorm:"column:payment_count;not null"`
+	Succeeded        int64     `gorm:"column:succeeded;not null"`
+	Failed           int64     `gorm:"column:failed;not null"`
+	AmountIDR        int64     `gorm:"column:amount_idr;not null"`
+	SettledAmountIDR int64     `gorm:"column:settled_amount_idr;not null"`
+	SnapshotAt       time.Time `gorm:"column:snapshot_at;not null"`
+}
+
+func (PaymentFact) TableName() string {
+	return "reporting.daily_payment_facts"
+}
+
+// Day records that FactDate was snapshotted, for every tenant at once.
+type Day struct {
+	FactDate     time.Time `gorm:"column:fact_date;type:date;primaryKey"`
+	ExpenseFacts int       `gorm:"column:expense_facts;not null"`
+	PaymentFacts int       `gorm:"column:payment_facts;not null"`
+	SnapshotAt   time.Time `gorm:"column:snapshot_at;not null"`
+}
+
+func (Day) TableName() string {
+	return "reporting.daily_snapshots"
+}
@@ -0,0 +1,18 @@
+package spendinglimit
+
+import "time"
+
+type Override struct {
+	ID          int64     `gorm:"primaryKey"`
+	UserID      int64     `gorm:"column:user_id;not null"`
+	Period      string    `gorm:"column:period;not null"`
+	PeriodStart time.Time `gorm:"column:period_start;type:date;not null"`
+	AmountIDR   int64     `gorm:"column:amount_idr;not null"`
+	Reason      string    `gorm:"column:reason;not null"`
+	GrantedBy   int64     `gorm:"column:granted_by;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Override) TableName() string {
+	return "spending_limit_overrides"
+}
@@ -0,0 +1,18 @@
+package tenant
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Setting struct {
+	TenantID  int64           `gorm:"primaryKey;column:tenant_id;autoIncrement:false"`
+	Key       string          `gorm:"primaryKey;column:key"`
+	Value     json.RawMessage `gorm:"column:value;type:jsonb;not null"`
+	UpdatedBy *int64          `gorm:"column:updated_by"`
+	UpdatedAt time.Time       `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Setting) TableName() string {
+	return "tenant_settings"
+}
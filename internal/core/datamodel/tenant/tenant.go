@@ -0,0 +1,16 @@
+package tenant
+
+import "time"
+
+type Tenant struct {
+	ID        int64     `gorm:"primaryKey"`
+	Slug      string    `gorm:"column:slug;uniqueIndex;not null"`
+	Name      string    `gorm:"column:name;not null"`
+	IsActive  bool      `gorm:"column:is_active;not null;default:true"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Tenant) TableName() string {
+	return "tenants"
+}
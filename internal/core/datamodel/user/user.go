@@ -3,21 +3,27 @@ package user
 import "time"
 
 type User struct {
-	ID           int64     `gorm:"primaryKey"`
-	Email        string    `gorm:"column:email;uniqueIndex;not null"`
-	Name         string    `gorm:"column:name;not null"`
-	PasswordHash string    `gorm:"column:password_hash;not null"`
-	Department   string    `gorm:"column:department"`
-	IsActive     bool      `gorm:"column:is_active;default:true"`
-	CreatedAt    time.Time `gorm:"column:created_at;default:now()"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;default:now()"`
+	ID           int64   `gorm:"primaryKey"`
+	TenantID     int64   `gorm:"column:tenant_id;not null;default:1"`
+	Email        string  `gorm:"column:email;uniqueIndex;not null"`
+	Name         string  `gorm:"column:name;not null"`
+	PasswordHash string  `gorm:"column:password_hash;not null"`
+	Department   string  `gorm:"column:department"`
+	IsActive     bool    `gorm:"column:is_active;default:true"`
+	LoginAlerts  bool    `gorm:"column:login_alerts;not null;default:true"`
+	ExportEmails bool    `gorm:"column:export_emails;not null;default:true"`
+	AvatarKey    *string `gorm:"column:avatar_key"`
+	// SessionsRevokedAt invalidates every token issued before it.
+	SessionsRevokedAt *time.Time `gorm:"column:sessions_revoked_at"`
+	CreatedAt         time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 type Permission struct {
 	ID          int64     `gorm:"primaryKey"`
 	Name        string    `gorm:"column:name;uniqueIndex;not null"`
 	Description string    `gorm:"column:description"`
-	CreatedAt   time.Time `gorm:"column:created_at;default:now()"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
 }
 
 type UserPermission struct {
@@ -25,5 +31,13 @@ type UserPermission struct {
 	UserID       int64     `gorm:"column:user_id;not null"`
 	PermissionID int64     `gorm:"column:permission_id;not null"`
 	GrantedBy    *int64    `gorm:"column:granted_by"`
-	CreatedAt    time.Time `gorm:"column:created_at;default:now()"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+type Department struct {
+	ID        int64     `gorm:"primaryKey"`
+	Name      string    `gorm:"column:name;uniqueIndex;not null"`
+	ParentID  *int64    `gorm:"column:parent_id"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
 }
@@ -0,0 +1,126 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// prefix marks sealed values so plaintext written before encryption was
+// enabled can still be read.
+const prefix = "enc:v1:"
+
+var (
+	ErrNotConfigured = errors.New("encryption key not configured")
+	ErrUnknownKey    = errors.New("value was sealed with an unknown key")
+	ErrMalformed     = errors.New("malformed encrypted value")
+)
+
+// Keyring seals with its primary key and opens with any key it holds, so a
+// key can be rotated by making it a previous key.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring takes base64-encoded 32 byte AES keys.
+func NewKeyring(primary string, previous ...string) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[string]cipher.AEAD)}
+
+	for i, encoded := range append([]string{primary}, previous...) {
+		id, aead, err := newAEAD(encoded)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("primary key: %w", err)
+			}
+			return nil, fmt.Errorf("previous key %d: %w", i, err)
+		}
+		if i == 0 {
+			k.primary = id
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// ValidateKey reports whether encoded is usable as a key.
+func ValidateKey(encoded string) error {
+	_, _, err := newAEAD(encoded)
+	return err
+}
+
+func newAEAD(encoded string) (string, cipher.AEAD, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, errors.New("key is not valid base64")
+	}
+	if len(key) != 32 {
+		return "", nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The ID names the key in each ciphertext without revealing it.
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4]), aead, nil
+}
+
+// Encrypt returns "enc:v1:<key id>:<base64(nonce || sealed)>".
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	id, body, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok || !IsEncrypted(value) {
+		return nil, ErrMalformed
+	}
+	aead, found := k.aeads[id]
+	if !found {
+		return nil, ErrUnknownKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(body)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+var active atomic.Pointer[Keyring]
+
+// Use installs the keyring the GORM serializers seal and open values with.
+// Passing nil turns encryption off.
+func Use(k *Keyring) {
+	active.Store(k)
+}
+
+// Active returns the installed keyring, or nil.
+func Active() *Keyring {
+	return active.Load()
+}
@@ -0,0 +1,147 @@
+package encryption_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/frahmantamala/expense-management/internal/core/encryption"
+)
+
+var (
+	oldKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", 32)))
+	newKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("n", 32)))
+)
+
+type secret struct {
+	ID       int64
+	Number   string          `gorm:"serializer:encrypted"`
+	Response json.RawMessage `gorm:"serializer:encrypted_json"`
+}
+
+var _ = Describe("Keyring", func() {
+	It("round-trips and never repeats a ciphertext", func() {
+		keyring, err := encryption.NewKeyring(newKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		first, err := keyring.Encrypt([]byte("1234567890"))
+		Expect(err).NotTo(HaveOccurred())
+		second, err := keyring.Encrypt([]byte("1234567890"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first).To(HavePrefix("enc:v1:"))
+		Expect(first).NotTo(Equal(second))
+		Expect(first).NotTo(ContainSubstring("1234567890"))
+
+		plain, err := keyring.Decrypt(first)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plain)).To(Equal("1234567890"))
+	})
+
+	It("opens values sealed with a previous key after rotation", func() {
+		before, _ := encryption.NewKeyring(oldKey)
+		sealed, _ := before.Encrypt([]byte("1234567890"))
+
+		rotated, err := encryption.NewKeyring(newKey, oldKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		plain, err := rotated.Decrypt(sealed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plain)).To(Equal("1234567890"))
+
+		resealed, _ := rotated.Encrypt(plain)
+		onlyNew, _ := encryption.NewKeyring(newKey)
+		_, err = onlyNew.Decrypt(resealed)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("refuses values sealed with a key it does not hold", func() {
+		other, _ := encryption.NewKeyring(oldKey)
+		sealed, _ := other.Encrypt([]byte("1234567890"))
+		keyring, _ := encryption.NewKeyring(newKey)
+
+		_, err := keyring.Decrypt(sealed)
+
+		Expect(err).To(MatchError(encryption.ErrUnknownKey))
+	})
+
+	It("rejects keys that are not 32 bytes", func() {
+		Expect(encryption.ValidateKey(base64.StdEncoding.EncodeToString([]byte("short")))).
+			To(MatchError(ContainSubstring("must be 32 bytes")))
+		_, err := encryption.NewKeyring(newKey, "not base64!")
+		Expect(err).To(MatchError(ContainSubstring("previous key 1")))
+	})
+})
+
+var _ = Describe("Serializers", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		var err error
+		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.AutoMigrate(&secret{})).To(Succeed())
+
+		keyring, err := encryption.NewKeyring(newKey)
+		Expect(err).NotTo(HaveOccurred())
+		encryption.Use(keyring)
+		DeferCleanup(encryption.Use, (*encryption.Keyring)(nil))
+	})
+
+	raw := func(id int64) (string, string) {
+		var row struct{ Number, Response string }
+		Expect(db.Raw("SELECT number, response FROM secrets WHERE id = ?", id).Scan(&row).Error).To(Succeed())
+		return row.Number, row.Response
+	}
+
+	It("seals on write and opens on read", func() {
+		row := &secret{Number: "1234567890", Response: json.RawMessage(`{"card":"4111"}`)}
+		Expect(db.Create(row).Error).To(Succeed())
+
+		number, response := raw(row.ID)
+		Expect(number).To(HavePrefix("enc:v1:"))
+		Expect(response).To(HavePrefix(`"enc:v1:`))
+		Expect(response).NotTo(ContainSubstring("4111"))
+
+		var loaded secret
+		Expect(db.First(&loaded, row.ID).Error).To(Succeed())
+		Expect(loaded.Number).To(Equal("1234567890"))
+		Expect(loaded.Response).To(MatchJSON(`{"card":"4111"}`))
+	})
+
+	It("reads plaintext stored before encryption was enabled", func() {
+		Expect(db.Exec(`INSERT INTO secrets (id, number, response) VALUES (1, '1234567890', '{"ok":true}')`).Error).To(Succeed())
+
+		var loaded secret
+		Expect(db.First(&loaded, 1).Error).To(Succeed())
+		Expect(loaded.Number).To(Equal("1234567890"))
+		Expect(loaded.Response).To(MatchJSON(`{"ok":true}`))
+	})
+
+	It("seals map-based updates through SealJSON", func() {
+		row := &secret{Number: "1234567890"}
+		Expect(db.Create(row).Error).To(Succeed())
+
+		sealed, err := encryption.SealJSON(json.RawMessage(`{"status":"paid"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Model(&secret{}).Where("id = ?", row.ID).Updates(map[string]interface{}{"response": sealed}).Error).To(Succeed())
+
+		_, response := raw(row.ID)
+		Expect(response).To(HavePrefix(`"enc:v1:`))
+		var loaded secret
+		Expect(db.First(&loaded, row.ID).Error).To(Succeed())
+		Expect(loaded.Response).To(MatchJSON(`{"status":"paid"}`))
+	})
+
+	It("refuses to write encrypted strings without a key", func() {
+		encryption.Use(nil)
+
+		err := db.Create(&secret{Number: "1234567890"}).Error
+
+		Expect(err).To(MatchError(ContainSubstring(encryption.ErrNotConfigured.Error())))
+	})
+})
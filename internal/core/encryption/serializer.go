@@ -0,0 +1,125 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", StringSerializer{})
+	schema.RegisterSerializer("encrypted_json", JSONSerializer{})
+}
+
+// StringSerializer seals string fields tagged `serializer:encrypted`. It
+// refuses to write when no keyring is installed, so a missing key cannot
+// silently store plaintext.
+type StringSerializer struct{}
+
+func (StringSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("encrypted field %s: unsupported database type %T", field.Name, dbValue)
+	}
+
+	plain := stored
+	if IsEncrypted(stored) {
+		keyring := Active()
+		if keyring == nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, ErrNotConfigured)
+		}
+		opened, err := keyring.Decrypt(stored)
+		if err != nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, err)
+		}
+		plain = string(opened)
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(plain).Convert(field.FieldType))
+	return nil
+}
+
+func (StringSerializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plain, _ := fieldValue.(string)
+	if plain == "" {
+		return "", nil
+	}
+	keyring := Active()
+	if keyring == nil {
+		return nil, fmt.Errorf("encrypted field %s: %w", field.Name, ErrNotConfigured)
+	}
+	return keyring.Encrypt([]byte(plain))
+}
+
+// JSONSerializer seals json.RawMessage fields tagged
+// `serializer:encrypted_json` as a JSON string, so the column stays valid
+// JSON. Without a keyring values are stored as they are, which is how they
+// were stored before encryption existed.
+type JSONSerializer struct{}
+
+func (JSONSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored []byte
+	switch v := dbValue.(type) {
+	case nil:
+		field.ReflectValueOf(ctx, dst).Set(reflect.Zero(field.FieldType))
+		return nil
+	case string:
+		stored = []byte(v)
+	case []byte:
+		stored = bytes.Clone(v)
+	default:
+		return fmt.Errorf("encrypted field %s: unsupported database type %T", field.Name, dbValue)
+	}
+
+	if bytes.HasPrefix(stored, []byte(`"`+prefix)) {
+		var sealed string
+		if err := json.Unmarshal(stored, &sealed); err != nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, ErrMalformed)
+		}
+		keyring := Active()
+		if keyring == nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, ErrNotConfigured)
+		}
+		opened, err := keyring.Decrypt(sealed)
+		if err != nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, err)
+		}
+		stored = opened
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(json.RawMessage(stored)).Convert(field.FieldType))
+	return nil
+}
+
+func (JSONSerializer) Value(_ context.Context, _ *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	raw, _ := fieldValue.(json.RawMessage)
+	return SealJSON(raw)
+}
+
+// SealJSON is what JSONSerializer writes for raw. Map-based updates bypass
+// serializers and must store its result instead.
+func SealJSON(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	keyring := Active()
+	if keyring == nil {
+		return []byte(raw), nil
+	}
+
+	sealed, err := keyring.Encrypt(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealed)
+}
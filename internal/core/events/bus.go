@@ -41,15 +41,21 @@ func (e BaseEvent) Payload() interface{} {
 type Handler func(ctx context.Context, event Event) error
 
 type EventBus struct {
-	handlers map[string][]Handler
+	handlers map[string][]*subscription
 	logger   *slog.Logger
 	mu       sync.RWMutex
+
+	statsMu   sync.Mutex
+	published map[string]*publishStats
+	now       func() time.Time
 }
 
 func NewEventBus(logger *slog.Logger) *EventBus {
 	return &EventBus{
-		handlers: make(map[string][]Handler),
-		logger:   logger,
+		handlers:  make(map[string][]*subscription),
+		logger:    logger,
+		published: make(map[string]*publishStats),
+		now:       time.Now,
 	}
 }
 
@@ -57,13 +63,17 @@ func (eb *EventBus) Subscribe(eventType string, handler Handler) {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 
-	eb.handlers[eventType] = append(eb.handlers[eventType], handler)
+	sub := &subscription{name: handlerName(handler), handler: handler}
+	eb.handlers[eventType] = append(eb.handlers[eventType], sub)
 	eb.logger.Info("event handler registered",
 		"event_type", eventType,
+		"handler", sub.name,
 		"total_handlers", len(eb.handlers[eventType]))
 }
 
 func (eb *EventBus) Publish(ctx context.Context, event Event) error {
+	eb.recordPublish(event)
+
 	eb.mu.RLock()
 	handlers, exists := eb.handlers[event.EventType()]
 	eb.mu.RUnlock()
@@ -78,21 +88,24 @@ func (eb *EventBus) Publish(ctx context.Context, event Event) error {
 		"event_id", event.EventID(),
 		"handlers_count", len(handlers))
 
-	for _, handler := range handlers {
-		go func(h Handler) {
-			if err := h(ctx, event); err != nil {
+	for _, sub := range handlers {
+		go func(sub *subscription) {
+			if err := eb.run(ctx, sub, event); err != nil {
 				eb.logger.Error("event handler failed",
 					"event_type", event.EventType(),
 					"event_id", event.EventID(),
+					"handler", sub.name,
 					"error", err)
 			}
-		}(handler)
+		}(sub)
 	}
 
 	return nil
 }
 
 func (eb *EventBus) PublishSync(ctx context.Context, event Event) error {
+	eb.recordPublish(event)
+
 	eb.mu.RLock()
 	handlers, exists := eb.handlers[event.EventType()]
 	eb.mu.RUnlock()
@@ -107,11 +120,12 @@ func (eb *EventBus) PublishSync(ctx context.Context, event Event) error {
 		"event_id", event.EventID(),
 		"handlers_count", len(handlers))
 
-	for _, handler := range handlers {
-		if err := handler(ctx, event); err != nil {
+	for _, sub := range handlers {
+		if err := eb.run(ctx, sub, event); err != nil {
 			eb.logger.Error("event handler failed",
 				"event_type", event.EventType(),
 				"event_id", event.EventID(),
+				"handler", sub.name,
 				"error", err)
 			return fmt.Errorf("handler failed for event %s: %w", event.EventType(), err)
 		}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const EventTypeExpenseStatusChanged = "expense.status_changed"
+
+// ExpenseStatusChangedEvent is published for every stored expense status
+// change. ActorID is nil when the change was not made by a user, such as a
+// payment completing.
+type ExpenseStatusChangedEvent struct {
+	BaseEvent
+	ExpenseID int64  `json:"expense_id"`
+	UserID    int64  `json:"user_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	ActorID   *int64 `json:"actor_id,omitempty"`
+}
+
+func NewExpenseStatusChangedEvent(expenseID, userID int64, from, to string, actorID *int64) *ExpenseStatusChangedEvent {
+	return &ExpenseStatusChangedEvent{
+		BaseEvent: BaseEvent{
+			ID:        uuid.New().String(),
+			Type:      EventTypeExpenseStatusChanged,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"expense_id": expenseID,
+				"user_id":    userID,
+				"from":       from,
+				"to":         to,
+				"actor_id":   actorID,
+			},
+		},
+		ExpenseID: expenseID,
+		UserID:    userID,
+		From:      from,
+		To:        to,
+		ActorID:   actorID,
+	}
+}
@@ -10,6 +10,7 @@ const (
 	EventTypeExpenseApproved  = "expense.approved"
 	EventTypePaymentCompleted = "payment.completed"
 	EventTypePaymentFailed    = "payment.failed"
+	EventTypePaymentMismatch  = "payment.mismatch"
 )
 
 type ExpenseApprovedEvent struct {
@@ -107,3 +108,42 @@ func NewPaymentFailedEvent(paymentID string, expenseID int64, externalID string,
 		RetryCount:    retryCount,
 	}
 }
+
+// PaymentMismatchEvent is an alert raised when a gateway callback does not
+// match the stored payment record.
+type PaymentMismatchEvent struct {
+	BaseEvent
+	PaymentID          string `json:"payment_id"`
+	ExpenseID          int64  `json:"expense_id"`
+	ExternalID         string `json:"external_id"`
+	ExpectedAmount     int64  `json:"expected_amount"`
+	ReceivedAmount     int64  `json:"received_amount"`
+	ReceivedExternalID string `json:"received_external_id"`
+	GatewayStatus      string `json:"gateway_status"`
+}
+
+func NewPaymentMismatchEvent(paymentID string, expenseID int64, externalID string, expectedAmount, receivedAmount int64, receivedExternalID, gatewayStatus string) *PaymentMismatchEvent {
+	return &PaymentMismatchEvent{
+		BaseEvent: BaseEvent{
+			ID:        uuid.New().String(),
+			Type:      EventTypePaymentMismatch,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"payment_id":           paymentID,
+				"expense_id":           expenseID,
+				"external_id":          externalID,
+				"expected_amount":      expectedAmount,
+				"received_amount":      receivedAmount,
+				"received_external_id": receivedExternalID,
+				"gateway_status":       gatewayStatus,
+			},
+		},
+		PaymentID:          paymentID,
+		ExpenseID:          expenseID,
+		ExternalID:         externalID,
+		ExpectedAmount:     expectedAmount,
+		ReceivedAmount:     receivedAmount,
+		ReceivedExternalID: receivedExternalID,
+		GatewayStatus:      gatewayStatus,
+	}
+}
@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/tracing"
+)
+
+// BusStats is what an EventBus has seen since the process started. Each
+// instance has its own bus, so it only covers events published here.
+type BusStats struct {
+	EventTypes []EventTypeStats `json:"event_types"`
+}
+
+// EventTypeStats covers one event type. A type that is published but has
+// no handlers is listed with none, which is usually the bug.
+type EventTypeStats struct {
+	EventType       string         `json:"event_type"`
+	Published       int64          `json:"published"`
+	LastPublishedAt *time.Time     `json:"last_published_at,omitempty"`
+	LastEventID     string         `json:"last_event_id,omitempty"`
+	Handlers        []HandlerStats `json:"handlers"`
+}
+
+// HandlerStats covers one subscribed handler, named after the function that
+// registered it, such as "expense.(*CommandService).RegisterEventHandlers.func1".
+type HandlerStats struct {
+	Name     string `json:"name"`
+	Calls    int64  `json:"calls"`
+	Failures int64  `json:"failures"`
+	// LastError is the error of the latest failure and LastFailedEventID
+	// the event it failed on; they stay set after later calls succeed.
+	LastError         string     `json:"last_error,omitempty"`
+	LastFailedAt      *time.Time `json:"last_failed_at,omitempty"`
+	LastFailedEventID string     `json:"last_failed_event_id,omitempty"`
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+	stats   HandlerStats
+}
+
+type publishStats struct {
+	count   int64
+	lastAt  time.Time
+	eventID string
+}
+
+// Stats returns every event type that has handlers or has been published,
+// by name.
+func (eb *EventBus) Stats() BusStats {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	eb.statsMu.Lock()
+	defer eb.statsMu.Unlock()
+
+	types := make(map[string]bool)
+	for eventType := range eb.handlers {
+		types[eventType] = true
+	}
+	for eventType := range eb.published {
+		types[eventType] = true
+	}
+
+	stats := BusStats{EventTypes: make([]EventTypeStats, 0, len(types))}
+	for eventType := range types {
+		typeStats := EventTypeStats{EventType: eventType, Handlers: []HandlerStats{}}
+		if p, ok := eb.published[eventType]; ok {
+			lastAt := p.lastAt
+			typeStats.Published = p.count
+			typeStats.LastPublishedAt = &lastAt
+			typeStats.LastEventID = p.eventID
+		}
+		for _, sub := range eb.handlers[eventType] {
+			h := sub.stats
+			h.Name = sub.name
+			if h.LastFailedAt != nil {
+				failedAt := *h.LastFailedAt
+				h.LastFailedAt = &failedAt
+			}
+			typeStats.Handlers = append(typeStats.Handlers, h)
+		}
+		stats.EventTypes = append(stats.EventTypes, typeStats)
+	}
+	sort.Slice(stats.EventTypes, func(i, j int) bool {
+		return stats.EventTypes[i].EventType < stats.EventTypes[j].EventType
+	})
+	return stats
+}
+
+func (eb *EventBus) recordPublish(event Event) {
+	eb.statsMu.Lock()
+	defer eb.statsMu.Unlock()
+
+	p, ok := eb.published[event.EventType()]
+	if !ok {
+		p = &publishStats{}
+		eb.published[event.EventType()] = p
+	}
+	p.count++
+	p.lastAt = eb.now()
+	p.eventID = event.EventID()
+}
+
+// run calls sub's handler and records how it went.
+func (eb *EventBus) run(ctx context.Context, sub *subscription, event Event) error {
+	endSpan := tracing.StartSpan(ctx, "event "+event.EventType()+" "+sub.name)
+	err := sub.handler(ctx, event)
+	endSpan()
+
+	eb.statsMu.Lock()
+	defer eb.statsMu.Unlock()
+	sub.stats.Calls++
+	if err != nil {
+		failedAt := eb.now()
+		sub.stats.Failures++
+		sub.stats.LastError = err.Error()
+		sub.stats.LastFailedAt = &failedAt
+		sub.stats.LastFailedEventID = event.EventID()
+	}
+	return err
+}
+
+// handlerName names handler after its function, without the module path.
+func handlerName(handler Handler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
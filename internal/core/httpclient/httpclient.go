@@ -0,0 +1,194 @@
+// Package httpclient builds the HTTP clients used to call other services,
+// such as payment gateways. Every client from one Factory shares a pooled
+// transport, so connections are reused across callers and bounded per host.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config tunes a Factory. Zero values take the defaults noted.
+type Config struct {
+	// Timeout bounds a whole request when the caller gives no timeout of
+	// its own; 30s.
+	Timeout time.Duration
+	// DialTimeout and TLSHandshakeTimeout bound connecting; 5s each.
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout bounds the wait for response headers once the
+	// request is sent; zero leaves it to Timeout.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout is how long an unused connection stays pooled; 90s.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns and MaxIdleConnsPerHost bound the pool; 100 and 10.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps connections to one host, idle or not; zero is
+	// unlimited.
+	MaxConnsPerHost int
+	// ProxyURL sends every request through a proxy; empty honours the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyURL string
+	TLS      TLSConfig
+	// Retries is how many times a request that is safe to repeat is sent
+	// again after a connection error or a 502, 503 or 504. Backoff doubles
+	// from RetryBackoff (100ms) between attempts.
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+// TLSConfig adjusts how servers are verified.
+type TLSConfig struct {
+	// CAFile is a PEM bundle trusted on top of the system roots.
+	CAFile string
+	// MinVersion is "1.2" or "1.3"; empty means 1.2.
+	MinVersion string
+	// InsecureSkipVerify turns off certificate verification. Only for
+	// local development against self-signed servers.
+	InsecureSkipVerify bool
+}
+
+// Factory hands out clients over one shared transport.
+type Factory struct {
+	cfg       Config
+	transport http.RoundTripper
+}
+
+// New builds a Factory; it fails when the proxy URL, CA file or TLS version
+// is invalid.
+func New(cfg Config) (*Factory, error) {
+	cfg = withDefaults(cfg)
+
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy url %q", cfg.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	pooled := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	var transport http.RoundTripper = pooled
+	if cfg.Retries > 0 {
+		transport = &retryTransport{next: pooled, retries: cfg.Retries, backoff: cfg.RetryBackoff}
+	}
+	return &Factory{cfg: cfg, transport: transport}, nil
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultFactory *Factory
+)
+
+// Default is a Factory with every default, for callers that were given
+// none.
+func Default() *Factory {
+	defaultOnce.Do(func() {
+		// The zero Config has no proxy URL, CA file or TLS version to
+		// reject.
+		defaultFactory, _ = New(Config{})
+	})
+	return defaultFactory
+}
+
+// Client returns a client over the shared transport. A positive timeout
+// replaces Config.Timeout for this client.
+func (f *Factory) Client(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = f.cfg.Timeout
+	}
+	return &http.Client{Timeout: timeout, Transport: f.transport}
+}
+
+// Transport is the shared transport, for wrapping by callers that record or
+// rewrite requests.
+func (f *Factory) Transport() http.RoundTripper {
+	return f.transport
+}
+
+func withDefaults(cfg Config) Config {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.TLSHandshakeTimeout <= 0 {
+		cfg.TLSHandshakeTimeout = 5 * time.Second
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = 90 * time.Second
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = 100
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = 10
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	return cfg
+}
+
+func newTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("invalid TLS min version %q, must be 1.2 or 1.3", cfg.MinVersion)
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA file holds no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,129 @@
+package httpclient_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/httpclient"
+)
+
+var _ = Describe("Factory", func() {
+	var (
+		calls  atomic.Int32
+		status atomic.Int32
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		calls.Store(0)
+		status.Store(http.StatusServiceUnavailable)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(int(status.Load()))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	newFactory := func(retries int) *httpclient.Factory {
+		factory, err := httpclient.New(httpclient.Config{Retries: retries, RetryBackoff: time.Millisecond})
+		Expect(err).NotTo(HaveOccurred())
+		return factory
+	}
+
+	send := func(factory *httpclient.Factory, method string, header http.Header) int {
+		req, err := http.NewRequest(method, server.URL, strings.NewReader(`{}`))
+		Expect(err).NotTo(HaveOccurred())
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := factory.Client(time.Second).Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	It("retries idempotent requests on a 503", func() {
+		Expect(send(newFactory(2), http.MethodGet, nil)).To(Equal(http.StatusOK))
+		Expect(calls.Load()).To(Equal(int32(3)))
+	})
+
+	It("retries a POST carrying an Idempotency-Key", func() {
+		header := http.Header{"Idempotency-Key": {"exp-1"}}
+		Expect(send(newFactory(2), http.MethodPost, header)).To(Equal(http.StatusOK))
+		Expect(calls.Load()).To(Equal(int32(3)))
+	})
+
+	It("never resends a POST without an idempotency key", func() {
+		Expect(send(newFactory(2), http.MethodPost, nil)).To(Equal(http.StatusServiceUnavailable))
+		Expect(calls.Load()).To(Equal(int32(1)))
+	})
+
+	It("stops after the configured retries", func() {
+		Expect(send(newFactory(1), http.MethodGet, nil)).To(Equal(http.StatusServiceUnavailable))
+		Expect(calls.Load()).To(Equal(int32(2)))
+	})
+
+	It("does not retry client errors", func() {
+		status.Store(http.StatusBadRequest)
+		Expect(send(newFactory(2), http.MethodGet, nil)).To(Equal(http.StatusBadRequest))
+		Expect(calls.Load()).To(Equal(int32(1)))
+	})
+
+	It("sends requests through the configured proxy", func() {
+		var proxied atomic.Bool
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied.Store(r.URL.Host == "gateway.invalid")
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(proxy.Close)
+
+		factory, err := httpclient.New(httpclient.Config{ProxyURL: proxy.URL})
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := factory.Client(time.Second).Get("http://gateway.invalid/payments")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(proxied.Load()).To(BeTrue())
+	})
+
+	It("trusts servers signed by the configured CA", func() {
+		tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		DeferCleanup(tlsServer.Close)
+
+		_, err := newFactory(0).Client(time.Second).Get(tlsServer.URL)
+		Expect(err).To(HaveOccurred())
+
+		caFile := filepath.Join(GinkgoT().TempDir(), "ca.pem")
+		Expect(os.WriteFile(caFile, pemFor(tlsServer), 0o600)).To(Succeed())
+		factory, err := httpclient.New(httpclient.Config{TLS: httpclient.TLSConfig{CAFile: caFile}})
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := factory.Client(time.Second).Get(tlsServer.URL)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+	})
+
+	DescribeTable("refuses invalid settings",
+		func(cfg httpclient.Config, message string) {
+			_, err := httpclient.New(cfg)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("proxy without a host", httpclient.Config{ProxyURL: "proxy"}, `invalid proxy url "proxy"`),
+		Entry("unknown TLS version", httpclient.Config{TLS: httpclient.TLSConfig{MinVersion: "1.1"}}, `invalid TLS min version "1.1"`),
+		Entry("missing CA file", httpclient.Config{TLS: httpclient.TLSConfig{CAFile: "/does/not/exist.pem"}}, "failed to read CA file"),
+	)
+})
+
+func pemFor(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
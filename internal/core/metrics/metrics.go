@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Provider returns the current value of a metric group. It is invoked on every
+// scrape, so it must be cheap and safe for concurrent use.
+type Provider func() interface{}
+
+// Registry collects named metric providers and renders them as a JSON snapshot.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+var defaultRegistry = NewRegistry()
+
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds or replaces the provider published under name.
+func Register(name string, provider Provider) {
+	defaultRegistry.Register(name, provider)
+}
+
+func (r *Registry) Register(name string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = provider
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.providers, name)
+}
+
+func (r *Registry) Snapshot() map[string]interface{} {
+	r.mu.RLock()
+	providers := make(map[string]Provider, len(r.providers))
+	for name, p := range r.providers {
+		providers[name] = p
+	}
+	r.mu.RUnlock()
+
+	snapshot := make(map[string]interface{}, len(providers))
+	for name, p := range providers {
+		snapshot[name] = p()
+	}
+	return snapshot
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(r.Snapshot())
+	})
+}
@@ -0,0 +1,206 @@
+// Package money holds amounts as integer minor units of a currency, so
+// currencies with cents can be carried without floating point.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// IDR is the currency every amount is in until expenses carry their own.
+const IDR = "IDR"
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrOverflow         = errors.New("amount overflows")
+)
+
+// minorDigits is how many decimal digits each supported currency's minor
+// unit has. IDR is 0 rather than ISO 4217's 2: sen are not in circulation
+// and amounts have always been stored in whole rupiah.
+var minorDigits = map[string]int{
+	"IDR": 0,
+	"JPY": 0,
+	"AUD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"MYR": 2,
+	"SGD": 2,
+	"USD": 2,
+}
+
+// MinorDigits reports how many decimal digits code's minor unit has.
+func MinorDigits(code string) (int, bool) {
+	digits, ok := minorDigits[code]
+	return digits, ok
+}
+
+// IsSupported reports whether code is a currency amounts can be held in.
+func IsSupported(code string) bool {
+	_, ok := minorDigits[code]
+	return ok
+}
+
+// Money is Amount minor units of Currency, e.g. 1250 USD is $12.50 and
+// 50000 IDR is Rp 50.000.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Rupiah is amount whole rupiah.
+func Rupiah(amount int64) Money {
+	return Money{Amount: amount, Currency: IDR}
+}
+
+// Parse reads a decimal amount such as "12.50" in currency, refusing more
+// fractional digits than the currency's minor unit has.
+func Parse(s, currency string) (Money, error) {
+	digits, ok := MinorDigits(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	whole, frac, hasFrac := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" || (hasFrac && frac == "") || len(frac) > digits || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q in %s", ErrInvalidAmount, s, currency)
+	}
+
+	minor, err := strconv.ParseInt(whole+frac+strings.Repeat("0", digits-len(frac)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate fails for currencies amounts cannot be held in.
+func (m Money) Validate() error {
+	if !IsSupported(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, m.Currency)
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul is m times n, such as a per-day allowance times the days claimed.
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+	product := m.Amount * n
+	if product/n != m.Amount || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Cmp is -1, 0 or +1 as m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Decimal renders the amount in major units, e.g. "12.50" for 1250 USD.
+// Unknown currencies are rendered in minor units.
+func (m Money) Decimal() string {
+	digits, _ := MinorDigits(m.Currency)
+	s := strconv.FormatInt(m.Amount, 10)
+	if digits == 0 {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// UnmarshalJSON reads {"amount": 1250, "currency": "USD"}, amount in minor
+// units, and refuses currencies amounts cannot be held in.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Amount   *int64 `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Amount == nil {
+		return fmt.Errorf("%w: amount is required", ErrInvalidAmount)
+	}
+
+	parsed := Money{Amount: *raw.Amount, Currency: strings.ToUpper(strings.TrimSpace(raw.Currency))}
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,149 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/money"
+)
+
+var _ = Describe("Money", func() {
+	DescribeTable("Parse",
+		func(s, currency string, want int64) {
+			m, err := money.Parse(s, currency)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(m).To(Equal(money.New(want, currency)))
+		},
+		Entry("cents", "12.50", "USD", int64(1250)),
+		Entry("one fractional digit", "12.5", "USD", int64(1250)),
+		Entry("whole dollars", "12", "USD", int64(1200)),
+		Entry("negative", "-0.05", "EUR", int64(-5)),
+		Entry("rupiah", "50000", "IDR", int64(50000)),
+	)
+
+	DescribeTable("Parse refuses",
+		func(s, currency string, want error) {
+			_, err := money.Parse(s, currency)
+			Expect(err).To(MatchError(want))
+		},
+		Entry("too many fractional digits", "12.505", "USD", money.ErrInvalidAmount),
+		Entry("fractional rupiah", "500.50", "IDR", money.ErrInvalidAmount),
+		Entry("grouping separators", "1,000", "USD", money.ErrInvalidAmount),
+		Entry("a trailing point", "12.", "USD", money.ErrInvalidAmount),
+		Entry("unknown currencies", "12", "XYZ", money.ErrUnknownCurrency),
+		Entry("overflowing amounts", "99999999999999999999", "IDR", money.ErrOverflow),
+	)
+
+	DescribeTable("Decimal",
+		func(m money.Money, want string) {
+			Expect(m.Decimal()).To(Equal(want))
+		},
+		Entry("cents", money.New(1250, "USD"), "12.50"),
+		Entry("less than a unit", money.New(5, "USD"), "0.05"),
+		Entry("negative", money.New(-105, "SGD"), "-1.05"),
+		Entry("rupiah", money.Rupiah(50000), "50000"),
+	)
+
+	It("adds, subtracts and compares amounts in one currency", func() {
+		sum, err := money.New(1250, "USD").Add(money.New(75, "USD"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sum.String()).To(Equal("13.25 USD"))
+
+		diff, err := sum.Sub(money.New(1325, "USD"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.IsZero()).To(BeTrue())
+
+		cmp, err := money.Rupiah(10000).Cmp(money.Rupiah(20000))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cmp).To(Equal(-1))
+	})
+
+	It("refuses to mix currencies", func() {
+		_, err := money.Rupiah(10000).Add(money.New(100, "USD"))
+		Expect(err).To(MatchError(money.ErrCurrencyMismatch))
+
+		_, err = money.Rupiah(10000).Cmp(money.New(100, "USD"))
+		Expect(err).To(MatchError(money.ErrCurrencyMismatch))
+	})
+
+	It("multiplies and reports overflow", func() {
+		product, err := money.New(1250, "USD").Mul(3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(product).To(Equal(money.New(3750, "USD")))
+
+		_, err = money.Rupiah(math.MaxInt64).Add(money.Rupiah(1))
+		Expect(err).To(MatchError(money.ErrOverflow))
+		_, err = money.Rupiah(math.MaxInt64 / 2).Mul(3)
+		Expect(err).To(MatchError(money.ErrOverflow))
+		_, err = money.Rupiah(math.MinInt64).Mul(-1)
+		Expect(err).To(MatchError(money.ErrOverflow))
+	})
+
+	Describe("JSON", func() {
+		It("round-trips amounts in minor units", func() {
+			data, err := json.Marshal(money.New(1250, "USD"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"amount": 1250, "currency": "USD"}`))
+
+			var m money.Money
+			Expect(json.Unmarshal([]byte(`{"amount": 1250, "currency": "usd"}`), &m)).To(Succeed())
+			Expect(m).To(Equal(money.New(1250, "USD")))
+		})
+
+		It("refuses unknown currencies and missing amounts", func() {
+			var m money.Money
+			Expect(json.Unmarshal([]byte(`{"amount": 1250, "currency": "XYZ"}`), &m)).To(MatchError(money.ErrUnknownCurrency))
+			Expect(json.Unmarshal([]byte(`{"amount": 1250}`), &m)).To(MatchError(money.ErrUnknownCurrency))
+			Expect(json.Unmarshal([]byte(`{"currency": "IDR"}`), &m)).To(MatchError(money.ErrInvalidAmount))
+			Expect(json.Unmarshal([]byte(`{"amount": 12.5, "currency": "USD"}`), &m)).ToNot(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Rate", func() {
+	usd := money.Rate{From: "USD", To: money.IDR, Value: "16250.25"}
+
+	DescribeTable("Convert",
+		func(rate money.Rate, m money.Money, want money.Money) {
+			got, err := rate.Convert(m)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(want))
+		},
+		Entry("cents into whole rupiah", usd, money.New(1250, "USD"), money.Rupiah(203128)),
+		Entry("a whole dollar", usd, money.New(100, "USD"), money.Rupiah(16250)),
+		Entry("half a rupiah rounds up", money.Rate{From: "USD", To: money.IDR, Value: "50"}, money.New(1, "USD"), money.Rupiah(1)),
+		Entry("refunds round away from zero", money.Rate{From: "USD", To: money.IDR, Value: "50"}, money.New(-1, "USD"), money.Rupiah(-1)),
+		Entry("into a currency with cents", money.Rate{From: "JPY", To: "USD", Value: "0.0067"}, money.New(1000, "JPY"), money.New(670, "USD")),
+	)
+
+	It("refuses amounts in another currency", func() {
+		_, err := usd.Convert(money.New(100, "EUR"))
+		Expect(err).To(MatchError(money.ErrCurrencyMismatch))
+	})
+
+	It("reports overflow", func() {
+		_, err := usd.Convert(money.New(math.MaxInt64, "USD"))
+		Expect(err).To(MatchError(money.ErrOverflow))
+	})
+
+	DescribeTable("ValidateRateValue refuses",
+		func(value string) {
+			Expect(money.ValidateRateValue(value)).To(MatchError(money.ErrInvalidRate))
+		},
+		Entry("empty", ""),
+		Entry("zero", "0.000"),
+		Entry("negative", "-1"),
+		Entry("a fraction", "1/3"),
+		Entry("an exponent", "1e3"),
+		Entry("a trailing point", "12."),
+	)
+
+	It("trims the padding of a fixed-scale column", func() {
+		Expect(money.TrimRateValue("16250.2500000000")).To(Equal("16250.25"))
+		Expect(money.TrimRateValue("15000.0000000000")).To(Equal("15000"))
+		Expect(money.TrimRateValue("1500")).To(Equal("1500"))
+	})
+})
@@ -0,0 +1,81 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// Rate is what one major unit of From is worth in To on Date, as quoted by
+// Source at QuotedAt. Value is a decimal string, such as "16250.25", so it
+// is stored and applied exactly.
+type Rate struct {
+	From     string
+	To       string
+	Value    string
+	Source   string
+	Date     time.Time
+	QuotedAt time.Time
+}
+
+// ValidateRateValue accepts positive decimals without exponents or signs.
+func ValidateRateValue(value string) error {
+	whole, frac, hasFrac := strings.Cut(value, ".")
+	if whole == "" || (hasFrac && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return fmt.Errorf("%w: %q", ErrInvalidRate, value)
+	}
+	if strings.Trim(whole+frac, "0") == "" {
+		return fmt.Errorf("%w: %q is not positive", ErrInvalidRate, value)
+	}
+	return nil
+}
+
+// Convert is m in r.To, rounded half away from zero to To's minor unit.
+func (r Rate) Convert(m Money) (Money, error) {
+	if m.Currency != r.From {
+		return Money{}, fmt.Errorf("%w: rate is for %s, amount is %s", ErrCurrencyMismatch, r.From, m.Currency)
+	}
+	fromDigits, ok := MinorDigits(r.From)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, r.From)
+	}
+	toDigits, ok := MinorDigits(r.To)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, r.To)
+	}
+	if err := ValidateRateValue(r.Value); err != nil {
+		return Money{}, err
+	}
+
+	value, _ := new(big.Rat).SetString(r.Value)
+	x := new(big.Rat).SetInt64(m.Amount)
+	x.Mul(x, value)
+	scale := new(big.Rat).SetFrac(pow10(toDigits), pow10(fromDigits))
+	x.Mul(x, scale)
+
+	quo, rem := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(x.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(x.Sign())))
+	}
+	if !quo.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: quo.Int64(), Currency: r.To}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// TrimRateValue drops the trailing fractional zeros a fixed-scale NUMERIC
+// column pads a rate with, so "16250.2500000000" reads back as "16250.25".
+func TrimRateValue(value string) string {
+	if !strings.Contains(value, ".") {
+		return value
+	}
+	return strings.TrimSuffix(strings.TrimRight(value, "0"), ".")
+}
@@ -0,0 +1,53 @@
+package scheduler_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+)
+
+var _ = Describe("Parse", func() {
+	// Monday 6 October 2025, 10:20 UTC.
+	now := time.Date(2025, 10, 6, 10, 20, 30, 0, time.UTC)
+
+	DescribeTable("next run",
+		func(spec string, want time.Time) {
+			schedule, err := scheduler.Parse(spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.Next(now)).To(Equal(want))
+		},
+		Entry("every minute", "* * * * *", time.Date(2025, 10, 6, 10, 21, 0, 0, time.UTC)),
+		Entry("step", "*/15 * * * *", time.Date(2025, 10, 6, 10, 30, 0, 0, time.UTC)),
+		Entry("later today", "0 14 * * *", time.Date(2025, 10, 6, 14, 0, 0, 0, time.UTC)),
+		Entry("tomorrow once today's slot passed", "0 9 * * *", time.Date(2025, 10, 7, 9, 0, 0, 0, time.UTC)),
+		Entry("list and range", "5 8-9,12 * * 1-5", time.Date(2025, 10, 6, 12, 5, 0, 0, time.UTC)),
+		Entry("day of week", "0 0 * * 0", time.Date(2025, 10, 12, 0, 0, 0, 0, time.UTC)),
+		Entry("day of month rolls the month", "0 0 1 * *", time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("either day field when both are set", "0 0 15 * 3", time.Date(2025, 10, 8, 0, 0, 0, 0, time.UTC)),
+		Entry("month and year", "0 0 1 2 *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("shorthand", "@daily", time.Date(2025, 10, 7, 0, 0, 0, 0, time.UTC)),
+		Entry("interval", "@every 90s", now.Add(90*time.Second)),
+	)
+
+	DescribeTable("invalid expressions",
+		func(spec string) {
+			_, err := scheduler.Parse(spec)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("backwards range", "0 9-5 * * *"),
+		Entry("zero step", "*/0 * * * *"),
+		Entry("not a number", "0 noon * * *"),
+		Entry("bad interval", "@every soon"),
+	)
+
+	It("has no next run for a date that never comes", func() {
+		schedule, err := scheduler.Parse("0 0 30 2 *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Next(now).IsZero()).To(BeTrue())
+	})
+})
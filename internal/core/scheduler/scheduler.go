@@ -0,0 +1,255 @@
+// Package scheduler runs recurring background jobs, such as digests and
+// payment reconciliation, on cron-like schedules. With a Locker, each run
+// takes a lock named after its job first, so when several instances are
+// deployed only one of them runs a given job at a time.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/metrics"
+)
+
+// Job is a named unit of recurring work. Run gets a context that is
+// cancelled when the scheduler stops or Timeout passes.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Timeout bounds one run; zero leaves it unbounded.
+	Timeout time.Duration
+}
+
+// Locker takes a lock shared by every instance. TryLock reports false
+// without waiting when another instance holds key; release frees the lock
+// and must be called once ok is true.
+type Locker interface {
+	TryLock(ctx context.Context, key int64) (release func(), ok bool, err error)
+}
+
+// JobStats is what the scheduler reports for one job, under scheduler_jobs
+// in the metrics.
+type JobStats struct {
+	Name     string `json:"name"`
+	Runs     int64  `json:"runs"`
+	Failures int64  `json:"failures"`
+	// Skipped counts runs left to another instance that held the lock.
+	Skipped        int64      `json:"skipped"`
+	Running        bool       `json:"running"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+var (
+	ErrDuplicateJob = errors.New("job already registered")
+	ErrStarted      = errors.New("scheduler already started")
+)
+
+type entry struct {
+	job Job
+	key int64
+
+	mu    sync.Mutex
+	stats JobStats
+}
+
+type Scheduler struct {
+	locker Locker
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries []*entry
+	started bool
+}
+
+// New takes a nil locker when only one instance runs jobs.
+func New(locker Locker, logger *slog.Logger) *Scheduler {
+	s := &Scheduler{
+		locker: locker,
+		logger: logger,
+		now:    time.Now,
+	}
+	metrics.Register("scheduler_jobs", func() interface{} {
+		return s.Stats()
+	})
+	return s
+}
+
+// Register adds a job. Jobs must be registered before Run.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return errors.New("job needs a name, a schedule and a run function")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrStarted
+	}
+	for _, e := range s.entries {
+		if e.job.Name == job.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+		}
+	}
+	s.entries = append(s.entries, &entry{job: job, key: lockKey(job.Name), stats: JobStats{Name: job.Name}})
+	return nil
+}
+
+// Run starts every job and blocks until ctx is cancelled and running jobs
+// have returned.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	entries := append([]*entry(nil), s.entries...)
+	s.mu.Unlock()
+
+	s.logger.Info("scheduler started", "jobs", len(entries), "distributed_lock", s.locker != nil)
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+	s.logger.Info("scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		next := e.job.Schedule.Next(s.now())
+		if next.IsZero() {
+			s.logger.Warn("scheduled job has no further runs", "job", e.job.Name)
+			return
+		}
+		e.mu.Lock()
+		e.stats.NextRunAt = &next
+		e.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.RunNow(ctx, e.job.Name)
+	}
+}
+
+// RunNow runs the named job once, under its lock, and reports whether it
+// ran. Scheduled runs go through it too.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (bool, error) {
+	e := s.entry(name)
+	if e == nil {
+		return false, fmt.Errorf("unknown job %q", name)
+	}
+	log := s.logger.With("job", name)
+
+	if s.locker != nil {
+		release, ok, err := s.locker.TryLock(ctx, e.key)
+		if err != nil {
+			log.Error("failed to take job lock", "error", err)
+			return false, err
+		}
+		if !ok {
+			e.mu.Lock()
+			e.stats.Skipped++
+			e.mu.Unlock()
+			log.Debug("job is running on another instance, skipping")
+			return false, nil
+		}
+		defer release()
+	}
+
+	runCtx := ctx
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	started := s.now()
+	e.mu.Lock()
+	e.stats.Running = true
+	e.stats.LastStartedAt = &started
+	e.mu.Unlock()
+
+	err := s.run(runCtx, e.job)
+	duration := s.now().Sub(started)
+
+	e.mu.Lock()
+	e.stats.Running = false
+	e.stats.Runs++
+	e.stats.LastDurationMs = duration.Milliseconds()
+	e.stats.LastError = ""
+	if err != nil {
+		e.stats.Failures++
+		e.stats.LastError = err.Error()
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		log.Error("scheduled job failed", "error", err, "duration_ms", duration.Milliseconds())
+		return true, err
+	}
+	log.Info("scheduled job finished", "duration_ms", duration.Milliseconds())
+	return true, nil
+}
+
+// run keeps a panicking job from taking the scheduler down with it.
+func (s *Scheduler) run(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) entry(name string) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.job.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Stats reports every job, sorted by name.
+func (s *Scheduler) Stats() []JobStats {
+	s.mu.Lock()
+	entries := append([]*entry(nil), s.entries...)
+	s.mu.Unlock()
+
+	stats := make([]JobStats, len(entries))
+	for i, e := range entries {
+		e.mu.Lock()
+		stats[i] = e.stats
+		e.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// lockKey maps a job name to an advisory lock key, the same on every
+// instance.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,150 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+)
+
+// memoryLocker shares locks between schedulers like Postgres advisory locks
+// share them between instances.
+type memoryLocker struct {
+	mu   sync.Mutex
+	held map[int64]bool
+}
+
+func (l *memoryLocker) TryLock(_ context.Context, key int64) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, key)
+	}, true, nil
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		locker *memoryLocker
+		logger *slog.Logger
+	)
+
+	BeforeEach(func() {
+		locker = &memoryLocker{held: map[int64]bool{}}
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	})
+
+	It("runs jobs on their schedule until stopped", func() {
+		var runs atomic.Int64
+		s := scheduler.New(locker, logger)
+		Expect(s.Register(scheduler.Job{
+			Name:     "tick",
+			Schedule: scheduler.Every(10 * time.Millisecond),
+			Run: func(context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		})).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.Run(ctx)
+			close(done)
+		}()
+
+		Eventually(runs.Load).Should(BeNumerically(">=", 3))
+		cancel()
+		Eventually(done).Should(BeClosed())
+		Expect(s.Stats()[0].Runs).To(BeNumerically(">=", 3))
+	})
+
+	It("skips a run while another instance holds the job's lock", func() {
+		release := make(chan struct{})
+		first := scheduler.New(locker, logger)
+		second := scheduler.New(locker, logger)
+		job := scheduler.Job{
+			Name:     "reconcile",
+			Schedule: scheduler.Every(time.Hour),
+			Run: func(context.Context) error {
+				<-release
+				return nil
+			},
+		}
+		Expect(first.Register(job)).To(Succeed())
+		Expect(second.Register(job)).To(Succeed())
+
+		go first.RunNow(context.Background(), "reconcile")
+		Eventually(func() bool { return first.Stats()[0].Running }).Should(BeTrue())
+
+		ran, err := second.RunNow(context.Background(), "reconcile")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ran).To(BeFalse())
+		Expect(second.Stats()[0].Skipped).To(Equal(int64(1)))
+
+		close(release)
+		Eventually(func() int64 { return first.Stats()[0].Runs }).Should(Equal(int64(1)))
+		ran, err = second.RunNow(context.Background(), "reconcile")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ran).To(BeTrue())
+	})
+
+	It("records failures and panics without stopping", func() {
+		s := scheduler.New(nil, logger)
+		Expect(s.Register(scheduler.Job{
+			Name: "failing", Schedule: scheduler.Every(time.Hour),
+			Run: func(context.Context) error { return errors.New("gateway down") },
+		})).To(Succeed())
+		Expect(s.Register(scheduler.Job{
+			Name: "panicking", Schedule: scheduler.Every(time.Hour),
+			Run: func(context.Context) error { panic("boom") },
+		})).To(Succeed())
+
+		_, err := s.RunNow(context.Background(), "failing")
+		Expect(err).To(MatchError("gateway down"))
+		_, err = s.RunNow(context.Background(), "panicking")
+		Expect(err).To(MatchError(ContainSubstring("boom")))
+
+		stats := s.Stats()
+		Expect(stats[0].Name).To(Equal("failing"))
+		Expect(stats[0].Failures).To(Equal(int64(1)))
+		Expect(stats[0].LastError).To(Equal("gateway down"))
+		Expect(stats[1].Name).To(Equal("panicking"))
+		Expect(stats[1].Failures).To(Equal(int64(1)))
+	})
+
+	It("bounds a run by its timeout", func() {
+		s := scheduler.New(nil, logger)
+		Expect(s.Register(scheduler.Job{
+			Name: "slow", Schedule: scheduler.Every(time.Hour), Timeout: 10 * time.Millisecond,
+			Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})).To(Succeed())
+
+		_, err := s.RunNow(context.Background(), "slow")
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("refuses duplicate and incomplete jobs", func() {
+		s := scheduler.New(nil, logger)
+		job := scheduler.Job{Name: "digests", Schedule: scheduler.Every(time.Hour), Run: func(context.Context) error { return nil }}
+		Expect(s.Register(job)).To(Succeed())
+		Expect(s.Register(job)).To(MatchError(scheduler.ErrDuplicateJob))
+		Expect(s.Register(scheduler.Job{Name: "no-schedule", Run: job.Run})).NotTo(Succeed())
+	})
+})
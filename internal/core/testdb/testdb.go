@@ -0,0 +1,35 @@
+// Package testdb opens in-memory SQLite databases for repository tests. The
+// tables are auto-migrated from the production datamodels, so a test cannot
+// pass against a schema that has drifted from what the repositories use.
+package testdb
+
+import (
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+// Open returns a fresh in-memory database with the tables of models and
+// tenant scoping installed, as the server sets it up. Timestamps are UTC.
+func Open(models ...interface{}) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tenant.Scoping{}); err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
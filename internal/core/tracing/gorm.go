@@ -0,0 +1,71 @@
+package tracing
+
+import "gorm.io/gorm"
+
+const spanEndKey = "tracing:span_end"
+
+// GormSpans is a GORM plugin that records a span for every statement run with
+// a request context, named after the operation and table, e.g.
+// "db query expenses".
+type GormSpans struct{}
+
+func (GormSpans) Name() string {
+	return "tracing:spans"
+}
+
+func (GormSpans) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tracing:start", start("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("tracing:end", end); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tracing:start", start("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("tracing:end", end); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tracing:start", start("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tracing:end", end); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tracing:start", start("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("tracing:end", end); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tracing:start", start("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("tracing:end", end); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tracing:start", start("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("tracing:end", end)
+}
+
+func start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		name := "db " + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		db.InstanceSet(spanEndKey, StartSpan(db.Statement.Context, name))
+	}
+}
+
+func end(db *gorm.DB) {
+	if endSpan, ok := db.InstanceGet(spanEndKey); ok {
+		endSpan.(func())()
+	}
+}
@@ -0,0 +1,71 @@
+// Package tracing records lightweight, in-process spans for a single request
+// so slow requests can be logged with where their time went.
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxSpans bounds how many spans one request keeps; later spans are counted
+// but dropped.
+const maxSpans = 64
+
+// Span is a named piece of work within a request.
+type Span struct {
+	Name string
+	// Offset is when the span started, relative to the start of the request.
+	Offset   time.Duration
+	Duration time.Duration
+}
+
+// Recorder collects the spans of one request. It is safe for concurrent use,
+// since handlers may fan work out to goroutines.
+type Recorder struct {
+	mu      sync.Mutex
+	start   time.Time
+	spans   []Span
+	dropped int
+}
+
+type recorderKey struct{}
+
+// NewContext returns a copy of ctx that collects spans into the returned
+// Recorder.
+func NewContext(ctx context.Context) (context.Context, *Recorder) {
+	rec := &Recorder{start: time.Now()}
+	return context.WithValue(ctx, recorderKey{}, rec), rec
+}
+
+// StartSpan starts a span named name and returns the function that ends it.
+// Without a Recorder in ctx, as in background jobs, it records nothing.
+func StartSpan(ctx context.Context, name string) func() {
+	rec, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		rec.add(Span{Name: name, Offset: start.Sub(rec.start), Duration: time.Since(start)})
+	}
+}
+
+func (r *Recorder) add(span Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.spans) >= maxSpans {
+		r.dropped++
+		return
+	}
+	r.spans = append(r.spans, span)
+}
+
+// Spans returns the spans ended so far, in the order they ended, and how many
+// were dropped past the limit.
+func (r *Recorder) Spans() ([]Span, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Span(nil), r.spans...), r.dropped
+}
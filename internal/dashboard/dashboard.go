@@ -0,0 +1,40 @@
+package dashboard
+
+import "time"
+
+// Dashboard is the summary shown on the caller's landing page.
+type Dashboard struct {
+	StatusCounts          map[string]int64  `json:"status_counts"`
+	MonthToDate           Spend             `json:"month_to_date"`
+	PendingApprovals      *PendingApprovals `json:"pending_approvals,omitempty"`
+	RecentPaymentFailures []PaymentFailure  `json:"recent_payment_failures"`
+}
+
+// Spend totals the caller's approved and completed expenses dated in a range.
+type Spend struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Count     int64     `json:"count"`
+	AmountIDR int64     `json:"amount_idr"`
+}
+
+// PendingApprovals is only reported to users who can approve expenses and
+// excludes their own submissions.
+type PendingApprovals struct {
+	Count             int64      `json:"count"`
+	AmountIDR         int64      `json:"amount_idr"`
+	OldestSubmittedAt *time.Time `json:"oldest_submitted_at,omitempty"`
+}
+
+type PaymentFailure struct {
+	PaymentID     int64     `json:"payment_id"`
+	ExpenseID     int64     `json:"expense_id"`
+	ExternalID    string    `json:"external_id"`
+	AmountIDR     int64     `json:"amount_idr"`
+	FailureReason *string   `json:"failure_reason,omitempty"`
+	RetryCount    int       `json:"retry_count"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+// recentFailuresLimit caps recent_payment_failures.
+const recentFailuresLimit = 5
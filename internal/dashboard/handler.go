@@ -0,0 +1,101 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	Get(ctx context.Context, userID int64, userPermissions []string, now time.Time) (*Dashboard, error)
+	OpsStats(ctx context.Context, adminID int64, days int, now time.Time) (*OpsStats, error)
+	EventBusStats(ctx context.Context) events.BusStats
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// GetDashboard godoc
+// @Summary      Expense summary dashboard
+// @Description  Counts of the current user's expenses by status, month-to-date approved spend and recent payment failures. Approvers also get the pending approvals they can act on.
+// @Tags         dashboard
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  Dashboard
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      500  {object}  transport.ErrorResponse
+// @Router       /dashboard [get]
+func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	d, err := h.Service.Get(r.Context(), user.ID, user.Permissions, time.Now())
+	if err != nil {
+		h.Log(r).Error("GetDashboard: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load dashboard")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, d)
+}
+
+// GetOpsStats godoc
+// @Summary      Operational stats for the ops dashboard
+// @Description  Payments created in the window by current status, expenses submitted in the window with the auto-approval rate and the average time to a manual decision, and this instance's payment queue. Covers the admin's tenant, except the queue, which is shared. Requires admin.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days  query     int  false  "Window in days, 30 by default and at most 365"
+// @Success      200   {object}  OpsStats
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      500   {object}  transport.ErrorResponse
+// @Router       /admin/stats [get]
+func (h *Handler) GetOpsStats(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	stats, err := h.Service.OpsStats(r.Context(), user.ID, days, time.Now())
+	if err != nil {
+		h.Log(r).Error("GetOpsStats: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load stats")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, stats)
+}
+
+// GetEventBusStats godoc
+// @Summary      Event bus diagnostics
+// @Description  Event types on this instance's event bus with how often each was published, the handlers subscribed to it, and per handler the calls, failures and last error. Counts start at zero when the instance starts and are not tenant scoped. Use it to see why an event such as payment.completed did not update an expense. Requires admin.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  events.BusStats
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/diagnostics/events [get]
+func (h *Handler) GetEventBusStats(w http.ResponseWriter, r *http.Request) {
+	h.WriteJSON(w, http.StatusOK, h.Service.EventBusStats(r.Context()))
+}
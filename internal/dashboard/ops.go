@@ -0,0 +1,57 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+// OpsStats is the operational summary behind the internal ops dashboard,
+// covering the admin's tenant over the window starting at Since.
+type OpsStats struct {
+	Since     time.Time            `json:"since"`
+	Payments  []PaymentStatusStats `json:"payments_by_status"`
+	Approvals ApprovalStats        `json:"approvals"`
+	// PaymentQueue is the gateway worker pool of this instance, shared by
+	// every tenant; it is omitted when the server runs without one.
+	PaymentQueue *paymentgateway.QueueStats `json:"payment_queue,omitempty"`
+}
+
+// PaymentStatusStats counts the payments created in the window that are
+// now in Status.
+type PaymentStatusStats struct {
+	Status    string `json:"status"`
+	Count     int64  `json:"count"`
+	AmountIDR int64  `json:"amount_idr"`
+}
+
+// ApprovalStats covers expenses submitted in the window. Auto-approved
+// expenses are approved or completed without an approver on record.
+type ApprovalStats struct {
+	Submitted        int64   `json:"submitted"`
+	AutoApproved     int64   `json:"auto_approved"`
+	AutoApprovalRate float64 `json:"auto_approval_rate"`
+	// ManualDecisions counts expenses an approver approved or rejected;
+	// AverageLatencySeconds is their mean time from submission to decision.
+	ManualDecisions       int64   `json:"manual_decisions"`
+	AverageLatencySeconds float64 `json:"average_latency_seconds"`
+}
+
+// QueueStatsProvider reports the payment worker pool;
+// paymentgateway.Client satisfies it.
+type QueueStatsProvider interface {
+	Stats() paymentgateway.QueueStats
+}
+
+// EventStatsProvider reports what the event bus has dispatched;
+// events.EventBus satisfies it.
+type EventStatsProvider interface {
+	Stats() events.BusStats
+}
+
+const (
+	// DefaultOpsWindowDays and MaxOpsWindowDays bound the ops stats window.
+	DefaultOpsWindowDays = 30
+	MaxOpsWindowDays     = 365
+)
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/dashboard"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	expensePostgres "github.com/frahmantamala/expense-management/internal/expense/postgres"
+	"gorm.io/gorm"
+)
+
+type DashboardRepository struct {
+	db *gorm.DB
+}
+
+func NewDashboardRepository(db *gorm.DB) dashboard.RepositoryAPI {
+	return &DashboardRepository{db: db}
+}
+
+// spendStatuses are the statuses that count as money spent.
+var spendStatuses = expense.ApprovedStatuses
+
+func (r *DashboardRepository) CountByStatus(userID int64) (map[string]int64, error) {
+	var rows []struct {
+		ExpenseStatus string
+		Count         int64
+	}
+	err := r.db.Table("expenses").
+		Select("expense_status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("expense_status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ExpenseStatus] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *DashboardRepository) SpendBetween(userID int64, from, to time.Time) (int64, int64, error) {
+	var row struct {
+		Count  int64
+		Amount int64
+	}
+	err := r.db.Table("expenses").
+		Select("COUNT(*) AS count, COALESCE(SUM(amount_idr), 0) AS amount").
+		Where("user_id = ? AND expense_status IN ?", userID, spendStatuses).
+		Where("expense_date >= ? AND expense_date <= ?", from, to).
+		Scan(&row).Error
+	return row.Count, row.Amount, err
+}
+
+func (r *DashboardRepository) PendingApprovals(approverID int64, scope expense.ViewScope) (*dashboard.PendingApprovals, error) {
+	// All-scope approvers see every department, but only of their tenant.
+	query := r.db.Table("expenses").Session(&gorm.Session{}).
+		Where("expense_status = ? AND user_id <> ?", expense.ExpenseStatusPendingApproval, approverID).
+		Where("tenant_id = (SELECT tenant_id FROM users WHERE id = ?)", approverID)
+	if scope != expense.ViewScopeAll {
+		query = query.Where("user_id IN ("+expensePostgres.TeamMembersSQL+")", approverID, approverID)
+	}
+
+	var row struct {
+		Count  int64
+		Amount int64
+	}
+	err := query.Select("COUNT(*) AS count, COALESCE(SUM(amount_idr), 0) AS amount").
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &dashboard.PendingApprovals{Count: row.Count, AmountIDR: row.Amount}
+	if row.Count == 0 {
+		return pending, nil
+	}
+
+	var oldest struct {
+		SubmittedAt time.Time
+	}
+	if err := query.Select("submitted_at").Order("submitted_at ASC").Limit(1).Scan(&oldest).Error; err != nil {
+		return nil, err
+	}
+	pending.OldestSubmittedAt = &oldest.SubmittedAt
+	return pending, nil
+}
+
+func (r *DashboardRepository) RecentPaymentFailures(userID int64, limit int) ([]dashboard.PaymentFailure, error) {
+	failures := []dashboard.PaymentFailure{}
+	err := r.db.Table("payments p").
+		Select("p.id AS payment_id, p.expense_id, p.external_id, p.amount_idr, p.failure_reason, p.retry_count, p.updated_at AS failed_at").
+		Joins("JOIN expenses e ON e.id = p.expense_id").
+		Where("e.user_id = ? AND p.status = ?", userID, "failed").
+		Order("p.updated_at DESC").
+		Limit(limit).
+		Scan(&failures).Error
+	return failures, err
+}
+
+// tenantOfSQL limits a query to the tenant of the user bound to it.
+const tenantOfSQL = "tenant_id = (SELECT tenant_id FROM users WHERE id = ?)"
+
+func (r *DashboardRepository) GetPaymentStats(adminID int64, since time.Time) ([]dashboard.PaymentStatusStats, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+		Amount int64
+	}
+	err := r.db.Table("payments").
+		Select("status, COUNT(*) AS count, COALESCE(SUM(amount_idr), 0) AS amount").
+		Where(tenantOfSQL, adminID).
+		Where("created_at >= ?", since).
+		Group("status").
+		Order("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]dashboard.PaymentStatusStats, len(rows))
+	for i, row := range rows {
+		stats[i] = dashboard.PaymentStatusStats{Status: row.Status, Count: row.Count, AmountIDR: row.Amount}
+	}
+	return stats, nil
+}
+
+// ApprovalStats counts in SQL but averages latency in Go, which keeps the
+// query portable; it only reads two timestamps per decision.
+func (r *DashboardRepository) ApprovalStats(adminID int64, since time.Time) (*dashboard.ApprovalStats, error) {
+	submitted := r.db.Table("expenses").Session(&gorm.Session{}).
+		Where(tenantOfSQL, adminID).
+		Where("submitted_at >= ?", since)
+
+	var counts struct {
+		Submitted    int64
+		AutoApproved int64
+	}
+	err := submitted.Select("COUNT(*) AS submitted, "+
+		"COALESCE(SUM(CASE WHEN expense_status IN ? AND approved_by IS NULL THEN 1 ELSE 0 END), 0) AS auto_approved", spendStatuses).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var decisions []struct {
+		SubmittedAt time.Time
+		ProcessedAt time.Time
+	}
+	err = submitted.Select("submitted_at, processed_at").
+		Where("processed_at IS NOT NULL AND (approved_by IS NOT NULL OR rejected_by IS NOT NULL)").
+		Scan(&decisions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dashboard.ApprovalStats{
+		Submitted:       counts.Submitted,
+		AutoApproved:    counts.AutoApproved,
+		ManualDecisions: int64(len(decisions)),
+	}
+	if len(decisions) > 0 {
+		var total time.Duration
+		for _, d := range decisions {
+			total += d.ProcessedAt.Sub(d.SubmittedAt)
+		}
+		stats.AverageLatencySeconds = (total / time.Duration(len(decisions))).Seconds()
+	}
+	return stats, nil
+}
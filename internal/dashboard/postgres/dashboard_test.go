@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	"github.com/frahmantamala/expense-management/internal/dashboard"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestDashboardRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DashboardRepository Suite")
+}
+
+var _ = Describe("DashboardRepository", func() {
+	var (
+		db   *gorm.DB
+		repo dashboard.RepositoryAPI
+		now  time.Time
+	)
+
+	addExpense := func(userID, amount int64, status string, date time.Time) int64 {
+		e := expenseDatamodel.Expense{
+			UserID:        userID,
+			AmountIDR:     amount,
+			Description:   "expense",
+			Category:      "travel",
+			ExpenseStatus: status,
+			ExpenseDate:   date,
+			SubmittedAt:   date,
+		}
+		Expect(db.Create(&e).Error).NotTo(HaveOccurred())
+		return e.ID
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = testdb.Open(&expenseDatamodel.Expense{}, &paymentDatamodel.Payment{}, &userDatamodel.User{}, &userDatamodel.Department{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(db.Create(&[]userDatamodel.Department{{ID: 1, Name: "engineering"}, {ID: 2, Name: "sales"}}).Error).NotTo(HaveOccurred())
+		Expect(db.Create(&[]userDatamodel.User{
+			{ID: 1, Email: "manager@example.com", Department: "engineering"},
+			{ID: 2, Email: "dev@example.com", Department: "engineering"},
+			{ID: 3, Email: "sales@example.com", Department: "sales"},
+		}).Error).NotTo(HaveOccurred())
+
+		repo = NewDashboardRepository(db)
+		now = time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		sqlDB, err := db.DB()
+		Expect(err).NotTo(HaveOccurred())
+		sqlDB.Close()
+	})
+
+	It("counts the user's expenses by status", func() {
+		addExpense(2, 10000, expense.ExpenseStatusApproved, now)
+		addExpense(2, 20000, expense.ExpenseStatusApproved, now)
+		addExpense(2, 30000, expense.ExpenseStatusRejected, now)
+		addExpense(3, 40000, expense.ExpenseStatusApproved, now)
+
+		counts, err := repo.CountByStatus(2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(Equal(map[string]int64{
+			expense.ExpenseStatusApproved: 2,
+			expense.ExpenseStatusRejected: 1,
+		}))
+	})
+
+	It("sums approved and completed spend within the range", func() {
+		monthStart := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+		addExpense(2, 10000, expense.ExpenseStatusApproved, now)
+		addExpense(2, 20000, expense.ExpenseStatusCompleted, monthStart)
+		addExpense(2, 40000, expense.ExpenseStatusPendingApproval, now)
+		addExpense(2, 80000, expense.ExpenseStatusApproved, monthStart.AddDate(0, 0, -1))
+
+		count, amount, err := repo.SpendBetween(2, monthStart, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(2)))
+		Expect(amount).To(Equal(int64(30000)))
+	})
+
+	Describe("PendingApprovals", func() {
+		BeforeEach(func() {
+			addExpense(1, 10000, expense.ExpenseStatusPendingApproval, now)
+			addExpense(2, 20000, expense.ExpenseStatusPendingApproval, now.Add(-48*time.Hour))
+			addExpense(2, 25000, expense.ExpenseStatusApproved, now)
+			addExpense(3, 40000, expense.ExpenseStatusPendingApproval, now.Add(-72*time.Hour))
+		})
+
+		It("limits a team approver to their department and skips their own", func() {
+			pending, err := repo.PendingApprovals(1, expense.ViewScopeTeam)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending.Count).To(Equal(int64(1)))
+			Expect(pending.AmountIDR).To(Equal(int64(20000)))
+			Expect(pending.OldestSubmittedAt).NotTo(BeNil())
+			Expect(*pending.OldestSubmittedAt).To(Equal(now.Add(-48 * time.Hour)))
+		})
+
+		It("includes every department for an all-scope approver", func() {
+			pending, err := repo.PendingApprovals(1, expense.ViewScopeAll)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending.Count).To(Equal(int64(2)))
+			Expect(pending.AmountIDR).To(Equal(int64(60000)))
+			Expect(pending.OldestSubmittedAt.Equal(now.Add(-72 * time.Hour))).To(BeTrue())
+		})
+
+		It("keeps an all-scope approver to their own tenant", func() {
+			Expect(db.Create(&userDatamodel.User{ID: 4, TenantID: 2, Email: "admin@other.example.com", Department: "engineering"}).Error).NotTo(HaveOccurred())
+
+			pending, err := repo.PendingApprovals(4, expense.ViewScopeAll)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending.Count).To(BeZero())
+		})
+
+		It("leaves the oldest submission empty when nothing is pending", func() {
+			pending, err := repo.PendingApprovals(3, expense.ViewScopeTeam)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending.Count).To(BeZero())
+			Expect(pending.OldestSubmittedAt).To(BeNil())
+		})
+	})
+
+	It("lists the user's most recent failed payments first", func() {
+		own := addExpense(2, 10000, expense.ExpenseStatusApproved, now)
+		other := addExpense(3, 10000, expense.ExpenseStatusApproved, now)
+		reason := "insufficient balance"
+		Expect(db.Create(&[]paymentDatamodel.Payment{
+			{ExpenseID: own, ExternalID: "old", AmountIDR: 10000, Status: "failed", FailureReason: &reason, UpdatedAt: now.Add(-time.Hour)},
+			{ExpenseID: own, ExternalID: "new", AmountIDR: 10000, Status: "failed", RetryCount: 1, UpdatedAt: now},
+			{ExpenseID: own, ExternalID: "ok", AmountIDR: 10000, Status: "success", UpdatedAt: now},
+			{ExpenseID: other, ExternalID: "theirs", AmountIDR: 10000, Status: "failed", UpdatedAt: now},
+		}).Error).NotTo(HaveOccurred())
+
+		failures, err := repo.RecentPaymentFailures(2, 5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(HaveLen(2))
+		Expect(failures[0].ExternalID).To(Equal("new"))
+		Expect(failures[0].RetryCount).To(Equal(1))
+		Expect(failures[1].ExternalID).To(Equal("old"))
+		Expect(*failures[1].FailureReason).To(Equal("insufficient balance"))
+
+		limited, err := repo.RecentPaymentFailures(2, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limited).To(HaveLen(1))
+	})
+
+	Describe("ops stats", func() {
+		var since time.Time
+
+		BeforeEach(func() {
+			since = now.AddDate(0, 0, -30)
+			Expect(db.Create(&userDatamodel.User{ID: 4, TenantID: 2, Email: "admin@other.example.com", Department: "engineering"}).Error).NotTo(HaveOccurred())
+		})
+
+		decide := func(id int64, approvedBy, rejectedBy *int64, after time.Duration) {
+			var e expenseDatamodel.Expense
+			Expect(db.First(&e, id).Error).NotTo(HaveOccurred())
+			processed := e.SubmittedAt.Add(after)
+			Expect(db.Model(&e).Updates(map[string]interface{}{
+				"approved_by":  approvedBy,
+				"rejected_by":  rejectedBy,
+				"processed_at": processed,
+			}).Error).NotTo(HaveOccurred())
+		}
+
+		It("counts the tenant's payments in the window by status", func() {
+			id := addExpense(2, 10000, expense.ExpenseStatusApproved, now)
+			Expect(db.Create(&[]paymentDatamodel.Payment{
+				{ExpenseID: id, ExternalID: "a", AmountIDR: 10000, Status: "pending", CreatedAt: now},
+				{ExpenseID: id, ExternalID: "b", AmountIDR: 20000, Status: "pending", CreatedAt: now},
+				{ExpenseID: id, ExternalID: "c", AmountIDR: 5000, Status: "success", CreatedAt: now},
+				{ExpenseID: id, ExternalID: "old", AmountIDR: 5000, Status: "failed", CreatedAt: since.Add(-time.Hour)},
+				{ExpenseID: id, ExternalID: "theirs", TenantID: 2, AmountIDR: 5000, Status: "failed", CreatedAt: now},
+			}).Error).NotTo(HaveOccurred())
+
+			stats, err := repo.GetPaymentStats(1, since)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(Equal([]dashboard.PaymentStatusStats{
+				{Status: "pending", Count: 2, AmountIDR: 30000},
+				{Status: "success", Count: 1, AmountIDR: 5000},
+			}))
+		})
+
+		It("counts auto-approvals and averages manual decision latency", func() {
+			manager := int64(1)
+			addExpense(2, 10000, expense.ExpenseStatusCompleted, now)
+			approved := addExpense(2, 2000000, expense.ExpenseStatusApproved, now.Add(-4*time.Hour))
+			decide(approved, &manager, nil, time.Hour)
+			rejected := addExpense(3, 3000000, expense.ExpenseStatusRejected, now.Add(-4*time.Hour))
+			decide(rejected, nil, &manager, 3*time.Hour)
+			addExpense(3, 3000000, expense.ExpenseStatusPendingApproval, now)
+			addExpense(2, 10000, expense.ExpenseStatusApproved, since.Add(-time.Hour))
+
+			stats, err := repo.ApprovalStats(1, since)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*stats).To(Equal(dashboard.ApprovalStats{
+				Submitted:             4,
+				AutoApproved:          1,
+				ManualDecisions:       2,
+				AverageLatencySeconds: 7200,
+			}))
+
+			other, err := repo.ApprovalStats(4, since)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(other.Submitted).To(BeZero())
+		})
+	})
+})
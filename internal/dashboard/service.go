@@ -0,0 +1,143 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type RepositoryAPI interface {
+	CountByStatus(userID int64) (map[string]int64, error)
+	SpendBetween(userID int64, from, to time.Time) (count, amountIDR int64, err error)
+	PendingApprovals(approverID int64, scope expense.ViewScope) (*PendingApprovals, error)
+	RecentPaymentFailures(userID int64, limit int) ([]PaymentFailure, error)
+	// GetPaymentStats and ApprovalStats cover the tenant of adminID.
+	GetPaymentStats(adminID int64, since time.Time) ([]PaymentStatusStats, error)
+	ApprovalStats(adminID int64, since time.Time) (*ApprovalStats, error)
+}
+
+type Service struct {
+	repo              RepositoryAPI
+	permissionChecker auth.PermissionChecker
+	queue             QueueStatsProvider
+	events            EventStatsProvider
+	logger            *slog.Logger
+}
+
+// NewService takes a nil queue when the server runs without a payment
+// worker pool, and a nil eventBus to report no events.
+func NewService(repo RepositoryAPI, permissionChecker auth.PermissionChecker, queue QueueStatsProvider, eventBus EventStatsProvider, logger *slog.Logger) *Service {
+	return &Service{
+		repo:              repo,
+		permissionChecker: permissionChecker,
+		queue:             queue,
+		events:            eventBus,
+		logger:            logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Get assembles the dashboard with one aggregate query per section.
+func (s *Service) Get(ctx context.Context, userID int64, userPermissions []string, now time.Time) (*Dashboard, error) {
+	counts, err := s.repo.CountByStatus(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count expenses by status: %w", err)
+	}
+	for _, status := range []string{
+		expense.ExpenseStatusPendingApproval,
+		expense.ExpenseStatusApproved,
+		expense.ExpenseStatusRejected,
+		expense.ExpenseStatusCompleted,
+	} {
+		if _, ok := counts[status]; !ok {
+			counts[status] = 0
+		}
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	count, amount, err := s.repo.SpendBetween(userID, monthStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum month-to-date spend: %w", err)
+	}
+
+	failures, err := s.repo.RecentPaymentFailures(userID, recentFailuresLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment failures: %w", err)
+	}
+
+	d := &Dashboard{
+		StatusCounts:          counts,
+		MonthToDate:           Spend{From: monthStart, To: now, Count: count, AmountIDR: amount},
+		RecentPaymentFailures: failures,
+	}
+
+	if s.permissionChecker.CanApproveExpenses(userPermissions) {
+		scope := expense.ViewScopeTeam
+		if s.permissionChecker.CanViewAllExpenses(userPermissions) {
+			scope = expense.ViewScopeAll
+		}
+		d.PendingApprovals, err = s.repo.PendingApprovals(userID, scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarise pending approvals: %w", err)
+		}
+	}
+
+	s.log(ctx).Debug("dashboard assembled", "user_id", userID, "is_approver", d.PendingApprovals != nil)
+	return d, nil
+}
+
+// OpsStats reports payments, approvals and the payment queue for the ops
+// dashboard over the last days days, clamped to MaxOpsWindowDays.
+func (s *Service) OpsStats(ctx context.Context, adminID int64, days int, now time.Time) (*OpsStats, error) {
+	if days <= 0 {
+		days = DefaultOpsWindowDays
+	}
+	days = min(days, MaxOpsWindowDays)
+	since := now.AddDate(0, 0, -days)
+
+	payments, err := s.repo.GetPaymentStats(adminID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count payments by status: %w", err)
+	}
+
+	approvals, err := s.repo.ApprovalStats(adminID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise approvals: %w", err)
+	}
+	if approvals.Submitted > 0 {
+		approvals.AutoApprovalRate = float64(approvals.AutoApproved) / float64(approvals.Submitted)
+	}
+
+	stats := &OpsStats{
+		Since:     since,
+		Payments:  payments,
+		Approvals: *approvals,
+	}
+	if s.queue != nil {
+		queue := s.queue.Stats()
+		stats.PaymentQueue = &queue
+	}
+
+	s.log(ctx).Debug("ops stats assembled", "admin_id", adminID, "days", days)
+	return stats, nil
+}
+
+// EventBusStats reports the handlers registered on this instance's event bus
+// and how their calls went, for debugging events that changed nothing.
+func (s *Service) EventBusStats(ctx context.Context) events.BusStats {
+	if s.events == nil {
+		return events.BusStats{EventTypes: []events.EventTypeStats{}}
+	}
+	stats := s.events.Stats()
+	s.log(ctx).Debug("event bus stats assembled", "event_types", len(stats.EventTypes))
+	return stats
+}
@@ -0,0 +1,212 @@
+package dashboard_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/dashboard"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+type mockRepository struct {
+	counts       map[string]int64
+	spendFrom    time.Time
+	spendTo      time.Time
+	pendingScope *expense.ViewScope
+	failureLimit int
+	statsSince   time.Time
+	approvals    dashboard.ApprovalStats
+}
+
+func (m *mockRepository) CountByStatus(userID int64) (map[string]int64, error) {
+	return m.counts, nil
+}
+
+func (m *mockRepository) SpendBetween(userID int64, from, to time.Time) (int64, int64, error) {
+	m.spendFrom, m.spendTo = from, to
+	return 2, 150000, nil
+}
+
+func (m *mockRepository) PendingApprovals(approverID int64, scope expense.ViewScope) (*dashboard.PendingApprovals, error) {
+	m.pendingScope = &scope
+	return &dashboard.PendingApprovals{Count: 3, AmountIDR: 90000}, nil
+}
+
+func (m *mockRepository) RecentPaymentFailures(userID int64, limit int) ([]dashboard.PaymentFailure, error) {
+	m.failureLimit = limit
+	return []dashboard.PaymentFailure{}, nil
+}
+
+func (m *mockRepository) GetPaymentStats(adminID int64, since time.Time) ([]dashboard.PaymentStatusStats, error) {
+	m.statsSince = since
+	return []dashboard.PaymentStatusStats{{Status: "pending", Count: 2, AmountIDR: 30000}}, nil
+}
+
+func (m *mockRepository) ApprovalStats(adminID int64, since time.Time) (*dashboard.ApprovalStats, error) {
+	approvals := m.approvals
+	return &approvals, nil
+}
+
+type fixedQueue paymentgateway.QueueStats
+
+func (q fixedQueue) Stats() paymentgateway.QueueStats {
+	return paymentgateway.QueueStats(q)
+}
+
+var _ = Describe("Dashboard Service", func() {
+	var (
+		repo    *mockRepository
+		service *dashboard.Service
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		repo = &mockRepository{counts: map[string]int64{expense.ExpenseStatusApproved: 4}}
+		service = dashboard.NewService(repo, auth.NewPermissionChecker(), nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		now = time.Date(2025, 10, 15, 9, 30, 0, 0, time.UTC)
+	})
+
+	It("reports every status, filling in zero counts", func() {
+		d, err := service.Get(context.Background(), 1, nil, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.StatusCounts).To(Equal(map[string]int64{
+			expense.ExpenseStatusPendingApproval: 0,
+			expense.ExpenseStatusApproved:        4,
+			expense.ExpenseStatusRejected:        0,
+			expense.ExpenseStatusCompleted:       0,
+		}))
+	})
+
+	It("sums spend from the start of the month", func() {
+		d, err := service.Get(context.Background(), 1, nil, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo.spendFrom).To(Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(repo.spendTo).To(Equal(now))
+		Expect(d.MonthToDate.Count).To(Equal(int64(2)))
+		Expect(d.MonthToDate.AmountIDR).To(Equal(int64(150000)))
+		Expect(repo.failureLimit).To(Equal(5))
+	})
+
+	It("omits pending approvals for users who cannot approve", func() {
+		d, err := service.Get(context.Background(), 1, []string{"view_own_expenses"}, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.PendingApprovals).To(BeNil())
+		Expect(repo.pendingScope).To(BeNil())
+	})
+
+	It("scopes a manager's pending approvals to their team", func() {
+		d, err := service.Get(context.Background(), 1, []string{"approve_expenses"}, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.PendingApprovals.Count).To(Equal(int64(3)))
+		Expect(*repo.pendingScope).To(Equal(expense.ViewScopeTeam))
+	})
+
+	It("shows admins every pending approval", func() {
+		_, err := service.Get(context.Background(), 1, []string{"admin"}, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*repo.pendingScope).To(Equal(expense.ViewScopeAll))
+	})
+})
+
+var _ = Describe("Ops stats", func() {
+	var (
+		repo *mockRepository
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = &mockRepository{approvals: dashboard.ApprovalStats{Submitted: 8, AutoApproved: 2, ManualDecisions: 5, AverageLatencySeconds: 3600}}
+		now = time.Date(2025, 10, 15, 9, 30, 0, 0, time.UTC)
+	})
+
+	newService := func(queue dashboard.QueueStatsProvider) *dashboard.Service {
+		return dashboard.NewService(repo, auth.NewPermissionChecker(), queue, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	}
+
+	It("reports payments, approvals with the auto-approval rate, and the queue", func() {
+		stats, err := newService(fixedQueue{QueueDepth: 4, QueueCapacity: 100}).OpsStats(context.Background(), 1, 7, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Since).To(Equal(now.AddDate(0, 0, -7)))
+		Expect(repo.statsSince).To(Equal(stats.Since))
+		Expect(stats.Payments).To(ConsistOf(dashboard.PaymentStatusStats{Status: "pending", Count: 2, AmountIDR: 30000}))
+		Expect(stats.Approvals.AutoApprovalRate).To(Equal(0.25))
+		Expect(stats.Approvals.AverageLatencySeconds).To(Equal(3600.0))
+		Expect(stats.PaymentQueue.QueueDepth).To(Equal(4))
+	})
+
+	It("defaults and clamps the window", func() {
+		stats, err := newService(nil).OpsStats(context.Background(), 1, 0, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Since).To(Equal(now.AddDate(0, 0, -dashboard.DefaultOpsWindowDays)))
+
+		stats, err = newService(nil).OpsStats(context.Background(), 1, 5000, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Since).To(Equal(now.AddDate(0, 0, -dashboard.MaxOpsWindowDays)))
+	})
+
+	It("leaves the rate at zero with nothing submitted and omits a missing queue", func() {
+		repo.approvals = dashboard.ApprovalStats{}
+		stats, err := newService(nil).OpsStats(context.Background(), 1, 30, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Approvals.AutoApprovalRate).To(BeZero())
+		Expect(stats.PaymentQueue).To(BeNil())
+	})
+})
+
+var _ = Describe("Dashboard Service EventBusStats", func() {
+	var (
+		bus     *events.EventBus
+		service *dashboard.Service
+	)
+
+	BeforeEach(func() {
+		log := slog.New(slog.NewTextHandler(io.Discard, nil))
+		bus = events.NewEventBus(log)
+		service = dashboard.NewService(&mockRepository{}, auth.NewPermissionChecker(), nil, bus, log)
+	})
+
+	It("reports handlers per event type with their calls, failures and last error", func() {
+		bus.Subscribe("payment.completed", func(ctx context.Context, event events.Event) error { return nil })
+		bus.Subscribe("payment.completed", func(ctx context.Context, event events.Event) error {
+			return errors.New("expense not found")
+		})
+
+		event := events.BaseEvent{ID: "evt-1", Type: "payment.completed"}
+		Expect(bus.PublishSync(context.Background(), event)).To(HaveOccurred())
+		Expect(bus.PublishSync(context.Background(), events.BaseEvent{ID: "evt-2", Type: "payment.failed"})).To(Succeed())
+
+		stats := service.EventBusStats(context.Background())
+		Expect(stats.EventTypes).To(HaveLen(2))
+
+		completed := stats.EventTypes[0]
+		Expect(completed.EventType).To(Equal("payment.completed"))
+		Expect(completed.Published).To(Equal(int64(1)))
+		Expect(completed.LastEventID).To(Equal("evt-1"))
+		Expect(completed.Handlers).To(HaveLen(2))
+		Expect(completed.Handlers[0].Name).To(ContainSubstring("dashboard_test"))
+		Expect(completed.Handlers[0].Calls).To(Equal(int64(1)))
+		Expect(completed.Handlers[0].Failures).To(BeZero())
+		Expect(completed.Handlers[1].Failures).To(Equal(int64(1)))
+		Expect(completed.Handlers[1].LastError).To(Equal("expense not found"))
+		Expect(completed.Handlers[1].LastFailedEventID).To(Equal("evt-1"))
+
+		failed := stats.EventTypes[1]
+		Expect(failed.EventType).To(Equal("payment.failed"))
+		Expect(failed.Published).To(Equal(int64(1)))
+		Expect(failed.Handlers).To(BeEmpty())
+	})
+
+	It("reports no event types without an event bus", func() {
+		service = dashboard.NewService(&mockRepository{}, auth.NewPermissionChecker(), nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		Expect(service.EventBusStats(context.Background()).EventTypes).To(BeEmpty())
+	})
+})
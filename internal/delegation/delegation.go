@@ -0,0 +1,75 @@
+// Package delegation lets users grant others, such as executive assistants,
+// the right to file expenses for them. An expense filed by a delegate
+// belongs to the principal who granted the delegation: they see it, are
+// paid for it and have it counted against their limits. The delegate is
+// recorded on the expense and in the audit log.
+package delegation
+
+import (
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	grantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/delegation"
+)
+
+// Grant lets DelegateID file expenses for PrincipalID.
+type Grant struct {
+	ID          int64 `json:"id"`
+	PrincipalID int64 `json:"principal_id"`
+	DelegateID  int64 `json:"delegate_id"`
+	GrantedBy   int64 `json:"granted_by"`
+	// ExpiresAt is unset for grants that last until they are revoked.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type GrantDTO struct {
+	DelegateID int64      `json:"delegate_id" validate:"required"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+func (dto GrantDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+type GrantsResponse struct {
+	Grants []*Grant `json:"grants"`
+}
+
+var (
+	ErrGrantNotFound  = errors.NewNotFoundError("Delegation grant not found", errors.ErrCodeDelegationNotFound)
+	ErrUserNotFound   = errors.NewNotFoundError("User not found", errors.ErrCodeUserNotFound)
+	ErrSelfDelegation = errors.NewValidationFieldError("delegate_id", "delegate_id must be another user", errors.ErrCodeValidationFailed)
+	ErrExpiresInPast  = errors.NewValidationFieldError("expires_at", "expires_at must be in the future", errors.ErrCodeInvalidDate)
+	// ErrNotDelegate is returned when filing for a user who has not granted
+	// the filer delegation, or whose grant has expired or was revoked.
+	ErrNotDelegate = errors.NewForbiddenError("You may not file expenses for this user", errors.ErrCodeNotDelegate)
+)
+
+// active reports whether g still lets its delegate file at now.
+func active(g *grantDatamodel.Grant, now time.Time) bool {
+	return g.RevokedAt == nil && (g.ExpiresAt == nil || g.ExpiresAt.After(now))
+}
+
+func fromDataModel(g *grantDatamodel.Grant) *Grant {
+	return &Grant{
+		ID:          g.ID,
+		PrincipalID: g.PrincipalID,
+		DelegateID:  g.DelegateID,
+		GrantedBy:   g.GrantedBy,
+		ExpiresAt:   g.ExpiresAt,
+		CreatedAt:   g.CreatedAt,
+	}
+}
+
+func fromDataModelSlice(rows []*grantDatamodel.Grant) []*Grant {
+	grants := make([]*Grant, len(rows))
+	for i, row := range rows {
+		grants[i] = fromDataModel(row)
+	}
+	return grants
+}
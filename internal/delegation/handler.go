@@ -0,0 +1,148 @@
+package delegation
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	ListDelegates(ctx context.Context, principalID int64) ([]*Grant, error)
+	ListPrincipals(ctx context.Context, delegateID int64) ([]*Grant, error)
+	Grant(ctx context.Context, principalID int64, dto GrantDTO) (*Grant, error)
+	Revoke(ctx context.Context, principalID, delegateID int64) error
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListDelegates godoc
+// @Summary      List my delegates
+// @Description  The users who may file expenses on my behalf.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  GrantsResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /users/me/delegates [get]
+func (h *Handler) ListDelegates(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	grants, err := h.Service.ListDelegates(r.Context(), user.ID)
+	if err != nil {
+		h.Log(r).Error("ListDelegates: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list delegates")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, GrantsResponse{Grants: grants})
+}
+
+// GrantDelegate godoc
+// @Summary      Let a user file expenses on my behalf
+// @Description  The delegate files expenses with submitted_for_user_id set to my ID. Those expenses are mine: I see them, am paid for them and have them counted against my limits. Granting the same delegate again replaces the expiry.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      GrantDTO  true  "Delegate"
+// @Success      201   {object}  Grant
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /users/me/delegates [post]
+func (h *Handler) GrantDelegate(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto GrantDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	grant, err := h.Service.Grant(r.Context(), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, grant)
+}
+
+// RevokeDelegate godoc
+// @Summary      Stop a user filing expenses on my behalf
+// @Description  Expenses the delegate already filed stay mine.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Delegate user ID"
+// @Success      204
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /users/me/delegates/{id} [delete]
+func (h *Handler) RevokeDelegate(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	delegateID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.Service.Revoke(r.Context(), user.ID, delegateID); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPrincipals godoc
+// @Summary      List the users I may file expenses for
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  GrantsResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /users/me/principals [get]
+func (h *Handler) ListPrincipals(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	grants, err := h.Service.ListPrincipals(r.Context(), user.ID)
+	if err != nil {
+		h.Log(r).Error("ListPrincipals: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list principals")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, GrantsResponse{Grants: grants})
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	grantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/delegation"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/delegation"
+	"gorm.io/gorm"
+)
+
+type GrantRepository struct {
+	db *gorm.DB
+}
+
+func NewGrantRepository(db *gorm.DB) delegation.RepositoryAPI {
+	return &GrantRepository{db: db}
+}
+
+func (r *GrantRepository) GetActive(ctx context.Context, principalID, delegateID int64) (*grantDatamodel.Grant, error) {
+	var grant grantDatamodel.Grant
+	err := r.db.WithContext(ctx).
+		Where("principal_id = ? AND delegate_id = ? AND revoked_at IS NULL", principalID, delegateID).
+		First(&grant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *GrantRepository) ListByPrincipal(ctx context.Context, principalID int64) ([]*grantDatamodel.Grant, error) {
+	var grants []*grantDatamodel.Grant
+	err := r.db.WithContext(ctx).
+		Where("principal_id = ? AND revoked_at IS NULL", principalID).
+		Order("id").
+		Find(&grants).Error
+	return grants, err
+}
+
+func (r *GrantRepository) ListByDelegate(ctx context.Context, delegateID int64) ([]*grantDatamodel.Grant, error) {
+	var grants []*grantDatamodel.Grant
+	err := r.db.WithContext(ctx).
+		Where("delegate_id = ? AND revoked_at IS NULL", delegateID).
+		Order("id").
+		Find(&grants).Error
+	return grants, err
+}
+
+func (r *GrantRepository) Create(ctx context.Context, grant *grantDatamodel.Grant) error {
+	return r.db.WithContext(ctx).Create(grant).Error
+}
+
+func (r *GrantRepository) Revoke(ctx context.Context, id, revokedBy int64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&grantDatamodel.Grant{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": at,
+			"revoked_by": revokedBy,
+		}).Error
+}
+
+func (r *GrantRepository) UserExists(ctx context.Context, userID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&userDatamodel.User{}).Where("id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
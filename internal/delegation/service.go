@@ -0,0 +1,198 @@
+package delegation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	grantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/delegation"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the grants of the tenant ctx is scoped to.
+type RepositoryAPI interface {
+	// GetActive returns the unrevoked grant from principalID to delegateID,
+	// expired or not, or nil when there is none.
+	GetActive(ctx context.Context, principalID, delegateID int64) (*grantDatamodel.Grant, error)
+	// ListByPrincipal and ListByDelegate return unrevoked grants, expired
+	// ones included.
+	ListByPrincipal(ctx context.Context, principalID int64) ([]*grantDatamodel.Grant, error)
+	ListByDelegate(ctx context.Context, delegateID int64) ([]*grantDatamodel.Grant, error)
+	Create(ctx context.Context, g *grantDatamodel.Grant) error
+	Revoke(ctx context.Context, id, revokedBy int64, at time.Time) error
+	UserExists(ctx context.Context, userID int64) (bool, error)
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+type Service struct {
+	repo          RepositoryAPI
+	auditRecorder AuditRecorder
+	logger        *slog.Logger
+	now           func() time.Time
+}
+
+// NewService takes a nil auditRecorder when grants and delegated filings are
+// only logged.
+func NewService(repo RepositoryAPI, auditRecorder AuditRecorder, logger *slog.Logger) *Service {
+	return &Service{
+		repo:          repo,
+		auditRecorder: auditRecorder,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// ListDelegates returns the grants principalID has made.
+func (s *Service) ListDelegates(ctx context.Context, principalID int64) ([]*Grant, error) {
+	rows, err := s.repo.ListByPrincipal(ctx, principalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegation grants: %w", err)
+	}
+	return fromDataModelSlice(s.unexpired(rows)), nil
+}
+
+// ListPrincipals returns the grants letting delegateID file for others.
+func (s *Service) ListPrincipals(ctx context.Context, delegateID int64) ([]*Grant, error) {
+	rows, err := s.repo.ListByDelegate(ctx, delegateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegation grants: %w", err)
+	}
+	return fromDataModelSlice(s.unexpired(rows)), nil
+}
+
+// Grant lets dto.DelegateID file expenses for principalID. Granting a
+// delegate again replaces their expiry.
+func (s *Service) Grant(ctx context.Context, principalID int64, dto GrantDTO) (*Grant, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	if dto.DelegateID == principalID {
+		return nil, ErrSelfDelegation
+	}
+	now := s.now()
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		return nil, ErrExpiresInPast
+	}
+
+	exists, err := s.repo.UserExists(ctx, dto.DelegateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := s.repo.GetActive(ctx, principalID, dto.DelegateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load delegation grant: %w", err)
+	}
+	if existing != nil {
+		if err := s.repo.Revoke(ctx, existing.ID, principalID, now); err != nil {
+			return nil, fmt.Errorf("failed to replace delegation grant: %w", err)
+		}
+	}
+
+	row := &grantDatamodel.Grant{
+		PrincipalID: principalID,
+		DelegateID:  dto.DelegateID,
+		GrantedBy:   principalID,
+		ExpiresAt:   dto.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to save delegation grant: %w", err)
+	}
+
+	s.record(ctx, audit.ActionDelegationGranted, audit.ResourceDelegation, row.ID, principalID, map[string]interface{}{
+		"principal_id": principalID,
+		"delegate_id":  dto.DelegateID,
+		"expires_at":   dto.ExpiresAt,
+	})
+	s.log(ctx).Info("delegation granted",
+		"grant_id", row.ID,
+		"principal_id", principalID,
+		"delegate_id", dto.DelegateID)
+	return fromDataModel(row), nil
+}
+
+// Revoke stops delegateID filing for principalID. The grant is kept for the
+// audit trail; expenses already filed stay with principalID.
+func (s *Service) Revoke(ctx context.Context, principalID, delegateID int64) error {
+	row, err := s.repo.GetActive(ctx, principalID, delegateID)
+	if err != nil {
+		return fmt.Errorf("failed to load delegation grant: %w", err)
+	}
+	if row == nil {
+		return ErrGrantNotFound
+	}
+	if err := s.repo.Revoke(ctx, row.ID, principalID, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke delegation grant: %w", err)
+	}
+
+	s.record(ctx, audit.ActionDelegationRevoked, audit.ResourceDelegation, row.ID, principalID, map[string]interface{}{
+		"principal_id": principalID,
+		"delegate_id":  delegateID,
+	})
+	s.log(ctx).Info("delegation revoked", "grant_id", row.ID, "principal_id", principalID, "delegate_id", delegateID)
+	return nil
+}
+
+// CheckDelegation reports whether delegateID may file expenses for
+// principalID.
+func (s *Service) CheckDelegation(ctx context.Context, principalID, delegateID int64) error {
+	row, err := s.repo.GetActive(ctx, principalID, delegateID)
+	if err != nil {
+		return fmt.Errorf("failed to load delegation grant: %w", err)
+	}
+	if row == nil || !active(row, s.now()) {
+		s.log(ctx).Warn("expense filing refused without delegation", "principal_id", principalID, "delegate_id", delegateID)
+		return ErrNotDelegate
+	}
+	return nil
+}
+
+// RecordFiling audits that delegateID filed expenseID for principalID.
+func (s *Service) RecordFiling(ctx context.Context, expenseID, principalID, delegateID int64) {
+	s.record(ctx, audit.ActionExpenseFiledAsDelegate, audit.ResourceExpense, expenseID, delegateID, map[string]interface{}{
+		"principal_id": principalID,
+		"delegate_id":  delegateID,
+	})
+}
+
+func (s *Service) record(ctx context.Context, action, resourceType string, resourceID, actorID int64, metadata map[string]interface{}) {
+	if s.auditRecorder == nil {
+		return
+	}
+
+	entry := &audit.Entry{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   strconv.FormatInt(resourceID, 10),
+		ActorID:      &actorID,
+		Metadata:     metadata,
+	}
+	if err := s.auditRecorder.Record(entry); err != nil {
+		s.log(ctx).Error("failed to record delegation audit entry", "error", err, "action", action, "resource_id", resourceID)
+	}
+}
+
+// unexpired drops grants that have lapsed.
+func (s *Service) unexpired(rows []*grantDatamodel.Grant) []*grantDatamodel.Grant {
+	now := s.now()
+	out := rows[:0]
+	for _, row := range rows {
+		if active(row, now) {
+			out = append(out, row)
+		}
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package delegation_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	grantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/delegation"
+	"github.com/frahmantamala/expense-management/internal/delegation"
+)
+
+type mockRepository struct {
+	grants []*grantDatamodel.Grant
+	users  map[int64]bool
+}
+
+func (m *mockRepository) GetActive(_ context.Context, principalID, delegateID int64) (*grantDatamodel.Grant, error) {
+	for _, g := range m.grants {
+		if g.PrincipalID == principalID && g.DelegateID == delegateID && g.RevokedAt == nil {
+			return g, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListByPrincipal(_ context.Context, principalID int64) ([]*grantDatamodel.Grant, error) {
+	var out []*grantDatamodel.Grant
+	for _, g := range m.grants {
+		if g.PrincipalID == principalID && g.RevokedAt == nil {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) ListByDelegate(_ context.Context, delegateID int64) ([]*grantDatamodel.Grant, error) {
+	var out []*grantDatamodel.Grant
+	for _, g := range m.grants {
+		if g.DelegateID == delegateID && g.RevokedAt == nil {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) Create(_ context.Context, g *grantDatamodel.Grant) error {
+	g.ID = int64(len(m.grants) + 1)
+	m.grants = append(m.grants, g)
+	return nil
+}
+
+func (m *mockRepository) Revoke(_ context.Context, id, revokedBy int64, at time.Time) error {
+	for _, g := range m.grants {
+		if g.ID == id {
+			g.RevokedAt = &at
+			g.RevokedBy = &revokedBy
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) UserExists(_ context.Context, userID int64) (bool, error) {
+	return m.users[userID], nil
+}
+
+type mockRecorder struct {
+	entries []*audit.Entry
+}
+
+func (m *mockRecorder) Record(entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+var _ = Describe("Service", func() {
+	const (
+		executive = int64(1)
+		assistant = int64(2)
+		stranger  = int64(3)
+	)
+
+	var (
+		ctx      context.Context
+		repo     *mockRepository
+		recorder *mockRecorder
+		service  *delegation.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{users: map[int64]bool{executive: true, assistant: true, stranger: true}}
+		recorder = &mockRecorder{}
+		service = delegation.NewService(repo, recorder, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	Describe("Grant", func() {
+		It("lets the delegate file for the principal", func() {
+			grant, err := service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: assistant})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(grant.PrincipalID).To(Equal(executive))
+			Expect(grant.GrantedBy).To(Equal(executive))
+			Expect(service.CheckDelegation(ctx, executive, assistant)).To(Succeed())
+			Expect(service.CheckDelegation(ctx, assistant, executive)).To(MatchError(delegation.ErrNotDelegate))
+			Expect(recorder.entries).To(HaveLen(1))
+			Expect(recorder.entries[0].Action).To(Equal(audit.ActionDelegationGranted))
+			Expect(*recorder.entries[0].ActorID).To(Equal(executive))
+		})
+
+		It("replaces an earlier grant to the same delegate", func() {
+			_, err := service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: assistant})
+			Expect(err).NotTo(HaveOccurred())
+			expiresAt := time.Now().Add(24 * time.Hour)
+
+			_, err = service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: assistant, ExpiresAt: &expiresAt})
+
+			Expect(err).NotTo(HaveOccurred())
+			grants, err := service.ListDelegates(ctx, executive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(grants).To(HaveLen(1))
+			Expect(grants[0].ExpiresAt).To(Equal(&expiresAt))
+		})
+
+		It("refuses delegating to oneself", func() {
+			_, err := service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: executive})
+
+			Expect(err).To(Equal(delegation.ErrSelfDelegation))
+		})
+
+		It("refuses an unknown delegate", func() {
+			_, err := service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: 99})
+
+			Expect(err).To(Equal(delegation.ErrUserNotFound))
+			Expect(repo.grants).To(BeEmpty())
+		})
+
+		It("refuses an expiry in the past", func() {
+			expiresAt := time.Now().Add(-time.Hour)
+
+			_, err := service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: assistant, ExpiresAt: &expiresAt})
+
+			Expect(err).To(Equal(delegation.ErrExpiresInPast))
+		})
+	})
+
+	Describe("CheckDelegation", func() {
+		It("refuses once the grant has expired", func() {
+			expired := time.Now().Add(-time.Minute)
+			repo.grants = []*grantDatamodel.Grant{{ID: 1, PrincipalID: executive, DelegateID: assistant, ExpiresAt: &expired}}
+
+			Expect(service.CheckDelegation(ctx, executive, assistant)).To(MatchError(delegation.ErrNotDelegate))
+			Expect(service.ListPrincipals(ctx, assistant)).To(BeEmpty())
+		})
+
+		It("refuses once the grant is revoked", func() {
+			_, err := service.Grant(ctx, executive, delegation.GrantDTO{DelegateID: assistant})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(service.Revoke(ctx, executive, assistant)).To(Succeed())
+
+			Expect(service.CheckDelegation(ctx, executive, assistant)).To(MatchError(delegation.ErrNotDelegate))
+			Expect(*repo.grants[0].RevokedBy).To(Equal(executive))
+			Expect(recorder.entries[1].Action).To(Equal(audit.ActionDelegationRevoked))
+		})
+	})
+
+	It("reports revoking a delegate without a grant as not found", func() {
+		Expect(service.Revoke(ctx, executive, stranger)).To(MatchError(delegation.ErrGrantNotFound))
+	})
+
+	It("audits filings with the delegate as actor and both parties in the metadata", func() {
+		service.RecordFiling(ctx, 42, executive, assistant)
+
+		Expect(recorder.entries).To(HaveLen(1))
+		entry := recorder.entries[0]
+		Expect(entry.Action).To(Equal(audit.ActionExpenseFiledAsDelegate))
+		Expect(entry.ResourceType).To(Equal(audit.ResourceExpense))
+		Expect(entry.ResourceID).To(Equal("42"))
+		Expect(*entry.ActorID).To(Equal(assistant))
+		Expect(entry.Metadata).To(Equal(map[string]interface{}{"principal_id": executive, "delegate_id": assistant}))
+	})
+})
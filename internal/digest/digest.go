@@ -0,0 +1,134 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	digestDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/digest"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+type Kind string
+
+const (
+	KindManagerDaily   Kind = "manager_daily"
+	KindEmployeeWeekly Kind = "employee_weekly"
+)
+
+// maxItems caps how many expenses are listed in one email; the total is
+// still reported.
+const maxItems = 20
+
+// Recipient is an active user eligible for a digest kind. Permissions decide
+// which expenses a manager digest covers, exactly as in the expenses API.
+type Recipient struct {
+	ID          int64
+	TenantID    int64
+	Email       string
+	Name        string
+	Permissions []string
+}
+
+type Preferences struct {
+	ManagerDaily   bool `json:"manager_daily"`
+	EmployeeWeekly bool `json:"employee_weekly"`
+}
+
+func DefaultPreferences() *Preferences {
+	return &Preferences{ManagerDaily: true, EmployeeWeekly: true}
+}
+
+type UpdatePreferencesDTO struct {
+	ManagerDaily   *bool `json:"manager_daily"`
+	EmployeeWeekly *bool `json:"employee_weekly"`
+}
+
+func (p *Preferences) Apply(dto UpdatePreferencesDTO) {
+	if dto.ManagerDaily != nil {
+		p.ManagerDaily = *dto.ManagerDaily
+	}
+	if dto.EmployeeWeekly != nil {
+		p.EmployeeWeekly = *dto.EmployeeWeekly
+	}
+}
+
+func FromDataModel(p *digestDatamodel.Preference) *Preferences {
+	return &Preferences{ManagerDaily: p.ManagerDaily, EmployeeWeekly: p.EmployeeWeekly}
+}
+
+func ToDataModel(userID int64, p *Preferences) *digestDatamodel.Preference {
+	return &digestDatamodel.Preference{
+		UserID:         userID,
+		ManagerDaily:   p.ManagerDaily,
+		EmployeeWeekly: p.EmployeeWeekly,
+		UpdatedAt:      time.Now(),
+	}
+}
+
+type managerDigest struct {
+	Name        string
+	BaseURL     string
+	Total       int
+	TotalAmount int64
+	Items       []managerItem
+}
+
+type managerItem struct {
+	*expense.Expense
+	ApproveURL string
+	RejectURL  string
+}
+
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+type employeeDigest struct {
+	Name    string
+	BaseURL string
+	From    time.Time
+	To      time.Time
+	Counts  []statusCount
+	Items   []*expense.Expense
+}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"idr":  formatIDR,
+	"date": func(t time.Time) string { return t.Format("2 Jan 2006") },
+	"sub":  func(a, b int) int { return a - b },
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+func render(name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// formatIDR renders 1500000 as "IDR 1.500.000".
+func formatIDR(amount int64) string {
+	digits := strconv.FormatInt(amount, 10)
+	sign := ""
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return "IDR " + sign + b.String()
+}
@@ -0,0 +1,87 @@
+package digest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	GetPreferences(ctx context.Context, userID int64) (*Preferences, error)
+	UpdatePreferences(ctx context.Context, userID int64, dto UpdatePreferencesDTO) (*Preferences, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// GetPreferences godoc
+// @Summary      Digest email preferences
+// @Description  Which digest emails the current user receives. Users without saved preferences receive all of them.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  Preferences
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /users/me/digest-preferences [get]
+func (h *Handler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	prefs, err := h.Service.GetPreferences(r.Context(), user.ID)
+	if err != nil {
+		h.Log(r).Error("GetPreferences: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load digest preferences")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences godoc
+// @Summary      Opt in or out of digest emails
+// @Description  Omitted fields keep their current value.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      UpdatePreferencesDTO  true  "Preferences to change"
+// @Success      200   {object}  Preferences
+// @Failure      400   {object}  transport.ErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /users/me/digest-preferences [put]
+func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto UpdatePreferencesDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	prefs, err := h.Service.UpdatePreferences(r.Context(), user.ID, dto)
+	if err != nil {
+		h.Log(r).Error("UpdatePreferences: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to save digest preferences")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, prefs)
+}
@@ -0,0 +1,38 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+)
+
+const JobName = "digests"
+
+// Job sends the manager digest every day and the employee digest once a
+// week on weekday, both at hour:00 UTC.
+func Job(service *Service, hour int, weekday time.Weekday) (scheduler.Job, error) {
+	schedule, err := scheduler.Parse(fmt.Sprintf("0 %d * * *", hour))
+	if err != nil {
+		return scheduler.Job{}, err
+	}
+	return scheduler.Job{
+		Name:     JobName,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			now := time.Now().UTC()
+			var errs []error
+			if _, err := service.Send(ctx, KindManagerDaily, now); err != nil {
+				errs = append(errs, fmt.Errorf("manager digest: %w", err))
+			}
+			if now.Weekday() == weekday {
+				if _, err := service.Send(ctx, KindEmployeeWeekly, now); err != nil {
+					errs = append(errs, fmt.Errorf("employee digest: %w", err))
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}, nil
+}
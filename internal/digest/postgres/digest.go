@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/frahmantamala/expense-management/internal/auth"
+	digestDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/digest"
+	"github.com/frahmantamala/expense-management/internal/digest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DigestRepository struct {
+	db *gorm.DB
+}
+
+func NewDigestRepository(db *gorm.DB) digest.RepositoryAPI {
+	return &DigestRepository{db: db}
+}
+
+// approverPermissions mirrors auth.DefaultPermissionChecker.CanApproveExpenses.
+var approverPermissions = []string{string(auth.PermApproveExpenses), string(auth.PermAdmin)}
+
+func (r *DigestRepository) ListRecipients(kind digest.Kind) ([]*digest.Recipient, error) {
+	query := r.db.Table("users u").
+		Select("u.id, u.tenant_id, u.email, u.name").
+		Joins("LEFT JOIN digest_preferences dp ON dp.user_id = u.id").
+		Where("u.is_active = ?", true).
+		Order("u.id")
+
+	switch kind {
+	case digest.KindManagerDaily:
+		query = query.Where("COALESCE(dp.manager_daily, ?) = ?", true, true).
+			Where(`EXISTS (SELECT 1 FROM user_permissions up JOIN permissions p ON p.id = up.permission_id
+				WHERE up.user_id = u.id AND p.name IN ?)`, approverPermissions)
+	case digest.KindEmployeeWeekly:
+		query = query.Where("COALESCE(dp.employee_weekly, ?) = ?", true, true)
+	default:
+		return nil, fmt.Errorf("unknown digest kind %q", kind)
+	}
+
+	var recipients []*digest.Recipient
+	if err := query.Scan(&recipients).Error; err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return recipients, nil
+	}
+
+	ids := make([]int64, len(recipients))
+	byID := make(map[int64]*digest.Recipient, len(recipients))
+	for i, rcpt := range recipients {
+		ids[i] = rcpt.ID
+		byID[rcpt.ID] = rcpt
+	}
+
+	var grants []struct {
+		UserID int64
+		Name   string
+	}
+	err := r.db.Table("user_permissions up").
+		Select("up.user_id, p.name").
+		Joins("JOIN permissions p ON p.id = up.permission_id").
+		Where("up.user_id IN ?", ids).
+		Scan(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range grants {
+		byID[g.UserID].Permissions = append(byID[g.UserID].Permissions, g.Name)
+	}
+
+	return recipients, nil
+}
+
+func (r *DigestRepository) GetPreference(userID int64) (*digestDatamodel.Preference, error) {
+	var p digestDatamodel.Preference
+	err := r.db.Where("user_id = ?", userID).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *DigestRepository) SavePreference(p *digestDatamodel.Preference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"manager_daily", "employee_weekly", "updated_at"}),
+	}).Create(p).Error
+}
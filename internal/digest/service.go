@@ -0,0 +1,270 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	digestDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/digest"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/notification"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type RepositoryAPI interface {
+	// ListRecipients returns active users eligible for kind who have not
+	// opted out of it.
+	ListRecipients(kind Kind) ([]*Recipient, error)
+	GetPreference(userID int64) (*digestDatamodel.Preference, error)
+	SavePreference(p *digestDatamodel.Preference) error
+}
+
+// ExpenseLister is the slice of the expense service the digests read from,
+// so a digest never shows an expense the recipient could not open.
+type ExpenseLister interface {
+	GetExpensesForUser(ctx context.Context, userID int64, userPermissions []string, params *expense.ExpenseQueryParams) ([]*expense.Expense, error)
+}
+
+// ActionLinker issues one-click approve and reject links for the manager
+// digest. A nil linker leaves them out.
+type ActionLinker interface {
+	ActionLinks(ctx context.Context, expenseID, approverID int64) (approveURL, rejectURL string, err error)
+}
+
+// ChannelFilter reports whether the tenant ctx is scoped to has a
+// notification channel enabled; tenant.SettingsService satisfies it. A nil
+// filter sends digests to every tenant.
+type ChannelFilter interface {
+	NotifiesBy(ctx context.Context, channel string) bool
+}
+
+type Service struct {
+	repo     RepositoryAPI
+	expenses ExpenseLister
+	mailer   notification.Mailer
+	links    ActionLinker
+	channels ChannelFilter
+	baseURL  string
+	logger   *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, expenses ExpenseLister, mailer notification.Mailer, links ActionLinker, channels ChannelFilter, baseURL string, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		expenses: expenses,
+		mailer:   mailer,
+		links:    links,
+		channels: channels,
+		baseURL:  baseURL,
+		logger:   logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) GetPreferences(ctx context.Context, userID int64) (*Preferences, error) {
+	p, err := s.repo.GetPreference(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load digest preferences: %w", err)
+	}
+	if p == nil {
+		return DefaultPreferences(), nil
+	}
+	return FromDataModel(p), nil
+}
+
+func (s *Service) UpdatePreferences(ctx context.Context, userID int64, dto UpdatePreferencesDTO) (*Preferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs.Apply(dto)
+	if err := s.repo.SavePreference(ToDataModel(userID, prefs)); err != nil {
+		return nil, fmt.Errorf("failed to save digest preferences: %w", err)
+	}
+
+	s.log(ctx).Info("digest preferences updated",
+		"user_id", userID,
+		"manager_daily", prefs.ManagerDaily,
+		"employee_weekly", prefs.EmployeeWeekly)
+	return prefs, nil
+}
+
+// Send delivers kind to every eligible recipient and returns how many emails
+// were sent. A failure for one recipient is logged and does not stop the run.
+func (s *Service) Send(ctx context.Context, kind Kind, now time.Time) (int, error) {
+	recipients, err := s.repo.ListRecipients(kind)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s recipients: %w", kind, err)
+	}
+
+	sent := 0
+	for _, rcpt := range recipients {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		msg, err := s.compose(ctx, kind, rcpt, now)
+		if err != nil {
+			s.log(ctx).Error("failed to build digest", "kind", kind, "user_id", rcpt.ID, "error", err)
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+
+		if err := s.mailer.Send(ctx, *msg); err != nil {
+			s.log(ctx).Error("failed to send digest", "kind", kind, "user_id", rcpt.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	s.log(ctx).Info("digest run finished", "kind", kind, "recipients", len(recipients), "sent", sent)
+	return sent, nil
+}
+
+// compose runs within the recipient's tenant, so a manager digest never lists
+// another tenant's expenses. Tenants that turned email off get no digest.
+func (s *Service) compose(ctx context.Context, kind Kind, rcpt *Recipient, now time.Time) (*notification.Message, error) {
+	ctx = tenant.NewContext(ctx, rcpt.TenantID)
+	if s.channels != nil && !s.channels.NotifiesBy(ctx, tenant.ChannelEmail) {
+		return nil, nil
+	}
+	switch kind {
+	case KindManagerDaily:
+		return s.composeManagerDigest(ctx, rcpt)
+	case KindEmployeeWeekly:
+		return s.composeEmployeeDigest(ctx, rcpt, now)
+	default:
+		return nil, fmt.Errorf("unknown digest kind %q", kind)
+	}
+}
+
+// The compose functions return a nil message when there is nothing to report,
+// so empty digests are never sent.
+func (s *Service) composeManagerDigest(ctx context.Context, rcpt *Recipient) (*notification.Message, error) {
+	params := &expense.ExpenseQueryParams{
+		Status:    expense.ExpenseStatusPendingApproval,
+		PerPage:   100,
+		SortBy:    "submittedAt",
+		SortOrder: "asc",
+	}
+
+	expenses, err := s.expenses.GetExpensesForUser(ctx, rcpt.ID, rcpt.Permissions, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Managers do not approve their own expenses.
+	pending := make([]*expense.Expense, 0, len(expenses))
+	var totalAmount int64
+	for _, e := range expenses {
+		if e.UserID == rcpt.ID {
+			continue
+		}
+		pending = append(pending, e)
+		totalAmount += e.AmountIDR
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	total := len(pending)
+	if len(pending) > maxItems {
+		pending = pending[:maxItems]
+	}
+
+	data := managerDigest{
+		Name:        rcpt.Name,
+		BaseURL:     s.baseURL,
+		Total:       total,
+		TotalAmount: totalAmount,
+		Items:       make([]managerItem, len(pending)),
+	}
+	for i, e := range pending {
+		data.Items[i].Expense = e
+		if s.links == nil {
+			continue
+		}
+		// Without links the item still lists; the manager can act in the app.
+		approveURL, rejectURL, err := s.links.ActionLinks(ctx, e.ID, rcpt.ID)
+		if err != nil {
+			s.log(ctx).Error("failed to issue approval links", "expense_id", e.ID, "user_id", rcpt.ID, "error", err)
+			continue
+		}
+		data.Items[i].ApproveURL = approveURL
+		data.Items[i].RejectURL = rejectURL
+	}
+
+	body, err := render("manager_daily.txt.tmpl", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification.Message{
+		To:      rcpt.Email,
+		Subject: fmt.Sprintf("%d expense(s) awaiting your approval", data.Total),
+		Body:    body,
+	}, nil
+}
+
+func (s *Service) composeEmployeeDigest(ctx context.Context, rcpt *Recipient, now time.Time) (*notification.Message, error) {
+	from := now.AddDate(0, 0, -7)
+	params := &expense.ExpenseQueryParams{
+		PerPage:   100,
+		SortBy:    "submittedAt",
+		SortOrder: "desc",
+	}
+
+	// No permissions: the digest only ever covers the employee's own expenses.
+	expenses, err := s.expenses.GetExpensesForUser(ctx, rcpt.ID, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	var recent []*expense.Expense
+	for _, e := range expenses {
+		if e.UpdatedAt.Before(from) && e.SubmittedAt.Before(from) {
+			continue
+		}
+		recent = append(recent, e)
+		counts[e.ExpenseStatus]++
+	}
+	if len(recent) == 0 {
+		return nil, nil
+	}
+
+	data := employeeDigest{
+		Name:    rcpt.Name,
+		BaseURL: s.baseURL,
+		From:    from,
+		To:      now,
+		Items:   recent,
+	}
+	for status, n := range counts {
+		data.Counts = append(data.Counts, statusCount{Status: status, Count: n})
+	}
+	sort.Slice(data.Counts, func(i, j int) bool { return data.Counts[i].Status < data.Counts[j].Status })
+	if len(data.Items) > maxItems {
+		data.Items = data.Items[:maxItems]
+	}
+
+	body, err := render("employee_weekly.txt.tmpl", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification.Message{
+		To:      rcpt.Email,
+		Subject: "Your weekly expense summary",
+		Body:    body,
+	}, nil
+}
@@ -0,0 +1,249 @@
+package digest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	digestDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/digest"
+	"github.com/frahmantamala/expense-management/internal/digest"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/notification"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type fakeDigestRepository struct {
+	recipients  map[digest.Kind][]*digest.Recipient
+	preferences map[int64]*digestDatamodel.Preference
+}
+
+func (f *fakeDigestRepository) ListRecipients(kind digest.Kind) ([]*digest.Recipient, error) {
+	return f.recipients[kind], nil
+}
+
+func (f *fakeDigestRepository) GetPreference(userID int64) (*digestDatamodel.Preference, error) {
+	return f.preferences[userID], nil
+}
+
+func (f *fakeDigestRepository) SavePreference(p *digestDatamodel.Preference) error {
+	f.preferences[p.UserID] = p
+	return nil
+}
+
+type fakeExpenseLister struct {
+	expenses []*expense.Expense
+	calls    []fakeListCall
+}
+
+type fakeListCall struct {
+	userID      int64
+	tenantID    int64
+	permissions []string
+	status      string
+}
+
+func (f *fakeExpenseLister) GetExpensesForUser(ctx context.Context, userID int64, permissions []string, params *expense.ExpenseQueryParams) ([]*expense.Expense, error) {
+	tenantID, _ := tenant.FromContext(ctx)
+	f.calls = append(f.calls, fakeListCall{userID: userID, tenantID: tenantID, permissions: permissions, status: params.Status})
+
+	var out []*expense.Expense
+	for _, e := range f.expenses {
+		if params.Status != "" && e.ExpenseStatus != params.Status {
+			continue
+		}
+		if permissions == nil && e.UserID != userID {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+type capturingMailer struct {
+	sent []notification.Message
+}
+
+func (m *capturingMailer) Send(_ context.Context, msg notification.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+type fakeActionLinker struct {
+	issued [][2]int64
+}
+
+func (f *fakeActionLinker) ActionLinks(_ context.Context, expenseID, approverID int64) (string, string, error) {
+	f.issued = append(f.issued, [2]int64{expenseID, approverID})
+	return fmt.Sprintf("https://links/approve/%d", expenseID), fmt.Sprintf("https://links/reject/%d", expenseID), nil
+}
+
+// fakeChannelFilter enables channels per tenant.
+type fakeChannelFilter map[int64][]string
+
+func (f fakeChannelFilter) NotifiesBy(ctx context.Context, channel string) bool {
+	id, _ := tenant.FromContext(ctx)
+	return slices.Contains(f[id], channel)
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx     context.Context
+		now     time.Time
+		repo    *fakeDigestRepository
+		lister  *fakeExpenseLister
+		mailer  *capturingMailer
+		service *digest.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 10, 6, 1, 0, 0, 0, time.UTC)
+		repo = &fakeDigestRepository{
+			recipients:  map[digest.Kind][]*digest.Recipient{},
+			preferences: map[int64]*digestDatamodel.Preference{},
+		}
+		lister = &fakeExpenseLister{}
+		mailer = &capturingMailer{}
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		service = digest.NewService(repo, lister, mailer, nil, nil, "https://expenses.example.com", logger)
+	})
+
+	Describe("manager digest", func() {
+		BeforeEach(func() {
+			repo.recipients[digest.KindManagerDaily] = []*digest.Recipient{
+				{ID: 1, TenantID: 3, Email: "manager@mail.com", Name: "Maya", Permissions: []string{"approve_expenses"}},
+			}
+		})
+
+		It("lists expenses within the manager's tenant", func() {
+			_, err := service.Send(ctx, digest.KindManagerDaily, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lister.calls).To(HaveLen(1))
+			Expect(lister.calls[0].tenantID).To(Equal(int64(3)))
+		})
+
+		It("lists pending expenses the manager can see, excluding their own", func() {
+			lister.expenses = []*expense.Expense{
+				{ID: 10, UserID: 2, AmountIDR: 1500000, Description: "Flight", Category: "travel", ExpenseStatus: expense.ExpenseStatusPendingApproval, SubmittedAt: now},
+				{ID: 11, UserID: 1, AmountIDR: 90000, Description: "Own lunch", Category: "meals", ExpenseStatus: expense.ExpenseStatusPendingApproval, SubmittedAt: now},
+				{ID: 12, UserID: 3, AmountIDR: 50000, Description: "Taxi", Category: "travel", ExpenseStatus: expense.ExpenseStatusApproved, SubmittedAt: now},
+			}
+
+			sent, err := service.Send(ctx, digest.KindManagerDaily, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent).To(Equal(1))
+			Expect(lister.calls[0].permissions).To(Equal([]string{"approve_expenses"}))
+
+			msg := mailer.sent[0]
+			Expect(msg.To).To(Equal("manager@mail.com"))
+			Expect(msg.Subject).To(Equal("1 expense(s) awaiting your approval"))
+			Expect(msg.Body).To(ContainSubstring("#10 Flight — IDR 1.500.000"))
+			Expect(msg.Body).NotTo(ContainSubstring("Own lunch"))
+			Expect(msg.Body).NotTo(ContainSubstring("Taxi"))
+			Expect(msg.Body).To(ContainSubstring("https://expenses.example.com/expenses?status=pending_approval"))
+		})
+
+		It("adds one-click links issued to the manager when a linker is set", func() {
+			links := &fakeActionLinker{}
+			service = digest.NewService(repo, lister, mailer, links, nil, "https://expenses.example.com", slog.New(slog.NewTextHandler(io.Discard, nil)))
+			lister.expenses = []*expense.Expense{
+				{ID: 10, UserID: 2, AmountIDR: 1500000, Description: "Flight", Category: "travel", ExpenseStatus: expense.ExpenseStatusPendingApproval, SubmittedAt: now},
+			}
+
+			_, err := service.Send(ctx, digest.KindManagerDaily, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(links.issued).To(Equal([][2]int64{{10, 1}}))
+			Expect(mailer.sent[0].Body).To(ContainSubstring("Approve: https://links/approve/10"))
+			Expect(mailer.sent[0].Body).To(ContainSubstring("Reject:  https://links/reject/10"))
+		})
+
+		It("skips tenants that turned email notifications off", func() {
+			service = digest.NewService(repo, lister, mailer, nil, fakeChannelFilter{3: {}}, "https://expenses.example.com", slog.New(slog.NewTextHandler(io.Discard, nil)))
+			lister.expenses = []*expense.Expense{
+				{ID: 10, UserID: 2, AmountIDR: 1500000, Description: "Flight", Category: "travel", ExpenseStatus: expense.ExpenseStatusPendingApproval, SubmittedAt: now},
+			}
+
+			sent, err := service.Send(ctx, digest.KindManagerDaily, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent).To(BeZero())
+			Expect(lister.calls).To(BeEmpty())
+		})
+
+		It("sends nothing when no expense is waiting", func() {
+			sent, err := service.Send(ctx, digest.KindManagerDaily, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent).To(BeZero())
+			Expect(mailer.sent).To(BeEmpty())
+		})
+	})
+
+	Describe("employee digest", func() {
+		BeforeEach(func() {
+			repo.recipients[digest.KindEmployeeWeekly] = []*digest.Recipient{
+				{ID: 2, Email: "employee@mail.com", Name: "Eko", Permissions: []string{"approve_expenses"}},
+			}
+		})
+
+		It("summarises only the employee's own activity from the last week", func() {
+			lister.expenses = []*expense.Expense{
+				{ID: 20, UserID: 2, AmountIDR: 75000, Description: "Parking", ExpenseStatus: expense.ExpenseStatusApproved, SubmittedAt: now.AddDate(0, 0, -2), UpdatedAt: now.AddDate(0, 0, -1)},
+				{ID: 21, UserID: 2, AmountIDR: 30000, Description: "Old coffee", ExpenseStatus: expense.ExpenseStatusCompleted, SubmittedAt: now.AddDate(0, -1, 0), UpdatedAt: now.AddDate(0, -1, 0)},
+				{ID: 22, UserID: 5, AmountIDR: 10000, Description: "Someone else", ExpenseStatus: expense.ExpenseStatusApproved, SubmittedAt: now, UpdatedAt: now},
+			}
+
+			sent, err := service.Send(ctx, digest.KindEmployeeWeekly, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent).To(Equal(1))
+			Expect(lister.calls[0].permissions).To(BeNil())
+
+			body := mailer.sent[0].Body
+			Expect(body).To(ContainSubstring("approved: 1"))
+			Expect(body).To(ContainSubstring("#20 Parking"))
+			Expect(body).NotTo(ContainSubstring("Old coffee"))
+			Expect(body).NotTo(ContainSubstring("Someone else"))
+		})
+	})
+
+	Describe("preferences", func() {
+		It("defaults to receiving every digest", func() {
+			prefs, err := service.GetPreferences(ctx, 7)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*prefs).To(Equal(digest.Preferences{ManagerDaily: true, EmployeeWeekly: true}))
+		})
+
+		It("only changes the fields that were sent", func() {
+			off := false
+			prefs, err := service.UpdatePreferences(ctx, 7, digest.UpdatePreferencesDTO{EmployeeWeekly: &off})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*prefs).To(Equal(digest.Preferences{ManagerDaily: true, EmployeeWeekly: false}))
+			Expect(repo.preferences[7].EmployeeWeekly).To(BeFalse())
+		})
+	})
+
+	Describe("Job", func() {
+		It("picks today's slot when it is still ahead and tomorrow's otherwise", func() {
+			job, err := digest.Job(service, 1, time.Monday)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(job.Schedule.Next(time.Date(2025, 10, 6, 0, 30, 0, 0, time.UTC))).
+				To(Equal(time.Date(2025, 10, 6, 1, 0, 0, 0, time.UTC)))
+			Expect(job.Schedule.Next(time.Date(2025, 10, 6, 1, 0, 0, 0, time.UTC))).
+				To(Equal(time.Date(2025, 10, 7, 1, 0, 0, 0, time.UTC)))
+		})
+	})
+})
@@ -2,9 +2,12 @@ package internal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
 )
 
 type ErrorType string
@@ -15,8 +18,11 @@ const (
 	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED"
 	ErrorTypeForbidden    ErrorType = "FORBIDDEN"
 	ErrorTypeConflict     ErrorType = "CONFLICT"
+	ErrorTypeRateLimited  ErrorType = "RATE_LIMITED"
+	ErrorTypeTooLarge     ErrorType = "PAYLOAD_TOO_LARGE"
 	ErrorTypeInternal     ErrorType = "INTERNAL_ERROR"
 	ErrorTypeExternal     ErrorType = "EXTERNAL_ERROR"
+	ErrorTypeUnavailable  ErrorType = "SERVICE_UNAVAILABLE"
 )
 
 type ErrorCode string
@@ -30,18 +36,88 @@ const (
 	ErrCodeAmountTooLow       ErrorCode = "AMOUNT_TOO_LOW"
 	ErrCodeAmountTooHigh      ErrorCode = "AMOUNT_TOO_HIGH"
 
+	ErrCodeInvalidRequestBody ErrorCode = "INVALID_REQUEST_BODY"
+	ErrCodeRequestTooLarge    ErrorCode = "REQUEST_TOO_LARGE"
+	ErrCodeRequestTimeout     ErrorCode = "REQUEST_TIMEOUT"
+
+	ErrCodeCategoryNotFound ErrorCode = "CATEGORY_NOT_FOUND"
+	ErrCodeCategoryCycle    ErrorCode = "CATEGORY_CYCLE"
+
+	ErrCodeRoutingRuleNotFound ErrorCode = "ROUTING_RULE_NOT_FOUND"
+
+	ErrCodeReceiptNotFound ErrorCode = "RECEIPT_NOT_FOUND"
+	ErrCodeInvalidReceipt  ErrorCode = "INVALID_RECEIPT"
+	ErrCodeReceiptRequired ErrorCode = "RECEIPT_REQUIRED"
+	ErrCodePreviewNotReady ErrorCode = "RECEIPT_PREVIEW_NOT_READY"
+	ErrCodeNoPreview       ErrorCode = "RECEIPT_PREVIEW_UNAVAILABLE"
+
+	ErrCodeLimitExceeded        ErrorCode = "LIMIT_EXCEEDED"
+	ErrCodePendingLimitExceeded ErrorCode = "PENDING_LIMIT_EXCEEDED"
+	ErrCodeUserNotFound         ErrorCode = "USER_NOT_FOUND"
+	ErrCodeAvatarNotFound       ErrorCode = "AVATAR_NOT_FOUND"
+	ErrCodeInvalidAvatar        ErrorCode = "INVALID_AVATAR"
+
+	ErrCodeActionLinkUsed ErrorCode = "ACTION_LINK_USED"
+
+	ErrCodeBankAccountNotFound     ErrorCode = "BANK_ACCOUNT_NOT_FOUND"
+	ErrCodePayoutAccountUnverified ErrorCode = "PAYOUT_ACCOUNT_UNVERIFIED"
+
+	ErrCodeTenantNotFound        ErrorCode = "TENANT_NOT_FOUND"
+	ErrCodeTenantInactive        ErrorCode = "TENANT_INACTIVE"
+	ErrCodeTenantMismatch        ErrorCode = "TENANT_MISMATCH"
+	ErrCodeTenantSettingNotFound ErrorCode = "TENANT_SETTING_NOT_FOUND"
+
+	ErrCodeExportJobNotFound ErrorCode = "EXPORT_JOB_NOT_FOUND"
+	ErrCodeExportForbidden   ErrorCode = "EXPORT_FORBIDDEN"
+	ErrCodeExportNotReady    ErrorCode = "EXPORT_NOT_READY"
+
+	ErrCodeImportJobNotFound ErrorCode = "IMPORT_JOB_NOT_FOUND"
+	ErrCodeInvalidImportFile ErrorCode = "INVALID_IMPORT_FILE"
+
+	ErrCodeReportScheduleNotFound ErrorCode = "REPORT_SCHEDULE_NOT_FOUND"
+
+	ErrCodeExpenseTemplateNotFound ErrorCode = "EXPENSE_TEMPLATE_NOT_FOUND"
+
+	ErrCodeCannedResponseNotFound ErrorCode = "CANNED_RESPONSE_NOT_FOUND"
+
+	ErrCodeDelegationNotFound ErrorCode = "DELEGATION_NOT_FOUND"
+	ErrCodeNotDelegate        ErrorCode = "NOT_DELEGATE"
+
+	ErrCodeChangeConsumerNotFound ErrorCode = "CHANGE_CONSUMER_NOT_FOUND"
+
+	ErrCodeIdempotencyConflict ErrorCode = "IDEMPOTENCY_CONFLICT"
+
+	ErrCodePeriodLocked       ErrorCode = "PERIOD_LOCKED"
+	ErrCodePeriodLockNotFound ErrorCode = "PERIOD_LOCK_NOT_FOUND"
+
+	ErrCodeCalendarDayNotFound ErrorCode = "CALENDAR_DAY_NOT_FOUND"
+
+	ErrCodeExchangeRateUnavailable ErrorCode = "EXCHANGE_RATE_UNAVAILABLE"
+
 	ErrCodeExpenseNotFound      ErrorCode = "EXPENSE_NOT_FOUND"
 	ErrCodeUnauthorizedAccess   ErrorCode = "UNAUTHORIZED_ACCESS"
 	ErrCodeInvalidExpenseStatus ErrorCode = "INVALID_EXPENSE_STATUS"
 	ErrCodeCannotModifyExpense  ErrorCode = "CANNOT_MODIFY_EXPENSE"
+	ErrCodeOwnExpenseDecision   ErrorCode = "OWN_EXPENSE_DECISION"
 
 	ErrCodeInvalidCredentials ErrorCode = "INVALID_CREDENTIALS"
 	ErrCodeUserInactive       ErrorCode = "USER_INACTIVE"
 	ErrCodeInvalidToken       ErrorCode = "INVALID_TOKEN"
 	ErrCodeTokenExpired       ErrorCode = "TOKEN_EXPIRED"
 
+	ErrCodeImpersonationNotAllowed ErrorCode = "IMPERSONATION_NOT_ALLOWED"
+	ErrCodeImpersonationRestricted ErrorCode = "IMPERSONATION_RESTRICTED"
+
 	ErrCodePaymentFailed      ErrorCode = "PAYMENT_FAILED"
 	ErrCodePaymentRetryFailed ErrorCode = "PAYMENT_RETRY_FAILED"
+	ErrCodePaymentJobNotFound ErrorCode = "PAYMENT_JOB_NOT_FOUND"
+	ErrCodePaymentQueueFull   ErrorCode = "PAYMENT_QUEUE_FULL"
+
+	ErrCodePaymentNotFound    ErrorCode = "PAYMENT_NOT_FOUND"
+	ErrCodePaymentJobConflict ErrorCode = "PAYMENT_JOB_CONFLICT"
+
+	ErrCodeWebhookNotFound       ErrorCode = "WEBHOOK_NOT_FOUND"
+	ErrCodeWebhookReplayConflict ErrorCode = "WEBHOOK_REPLAY_CONFLICT"
 )
 
 type AppError struct {
@@ -101,6 +177,10 @@ type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
 	Code    string `json:"code"`
+
+	// Key and Params let Localize re-render Message in the caller's locale.
+	Key    string      `json:"-"`
+	Params i18n.Params `json:"-"`
 }
 
 type ValidationErrors struct {
@@ -120,7 +200,7 @@ func NewValidationFieldError(field, message string, code ErrorCode) *AppError {
 	return &AppError{
 		Type:       ErrorTypeValidation,
 		Code:       ErrCodeValidationFailed,
-		Message:    "Validation failed",
+		Message:    i18n.Translate(i18n.Default, "validation.failed", nil),
 		StatusCode: http.StatusBadRequest,
 		Details: ValidationErrors{
 			Errors: []ValidationError{
@@ -130,6 +210,45 @@ func NewValidationFieldError(field, message string, code ErrorCode) *AppError {
 	}
 }
 
+// NewLocalizedFieldError renders a catalog message for field in the default
+// locale; {field} is filled in automatically.
+func NewLocalizedFieldError(field, key string, params i18n.Params, code ErrorCode) *AppError {
+	withField := i18n.Params{"field": i18n.Field(field)}
+	for name, value := range params {
+		withField[name] = value
+	}
+
+	appErr := NewValidationFieldError(field, i18n.Translate(i18n.Default, key, withField), code)
+	details := appErr.Details.(ValidationErrors)
+	details.Errors[0].Key = key
+	details.Errors[0].Params = withField
+	return appErr
+}
+
+// Localize returns a copy of a validation error with its catalog messages
+// rendered in loc. Other errors are returned unchanged.
+func (e *AppError) Localize(loc i18n.Locale) *AppError {
+	details, ok := e.Details.(ValidationErrors)
+	if !ok || loc == i18n.Default {
+		return e
+	}
+
+	localized := *e
+	if e.Message == i18n.Translate(i18n.Default, "validation.failed", nil) {
+		localized.Message = i18n.Translate(loc, "validation.failed", nil)
+	}
+
+	errs := make([]ValidationError, len(details.Errors))
+	for i, ve := range details.Errors {
+		if ve.Key != "" {
+			ve.Message = i18n.Translate(loc, ve.Key, ve.Params)
+		}
+		errs[i] = ve
+	}
+	localized.Details = ValidationErrors{Errors: errs}
+	return &localized
+}
+
 func NewNotFoundError(message string, code ErrorCode) *AppError {
 	return &AppError{
 		Type:       ErrorTypeNotFound,
@@ -176,6 +295,33 @@ func NewConflictError(message string, code ErrorCode) *AppError {
 	}
 }
 
+func NewTooManyRequestsError(message string, code ErrorCode) *AppError {
+	return &AppError{
+		Type:       ErrorTypeRateLimited,
+		Code:       code,
+		Message:    message,
+		StatusCode: http.StatusTooManyRequests,
+	}
+}
+
+func NewRequestTooLargeError(message string, code ErrorCode) *AppError {
+	return &AppError{
+		Type:       ErrorTypeTooLarge,
+		Code:       code,
+		Message:    message,
+		StatusCode: http.StatusRequestEntityTooLarge,
+	}
+}
+
+func NewServiceUnavailableError(message string, code ErrorCode) *AppError {
+	return &AppError{
+		Type:       ErrorTypeUnavailable,
+		Code:       code,
+		Message:    message,
+		StatusCode: http.StatusServiceUnavailable,
+	}
+}
+
 var (
 	ErrExpenseNotFound      = NewNotFoundError("Expense not found", ErrCodeExpenseNotFound)
 	ErrUnauthorizedAccess   = NewForbiddenError("unauthorized access to expense", ErrCodeUnauthorizedAccess)
@@ -186,10 +332,19 @@ var (
 	ErrUserInactive       = NewForbiddenError("User account is inactive", ErrCodeUserInactive)
 	ErrInvalidToken       = NewUnauthorizedError("Invalid token", ErrCodeInvalidToken)
 	ErrTokenExpired       = NewUnauthorizedError("Token has expired", ErrCodeTokenExpired)
+
+	ErrPaymentJobNotFound = NewNotFoundError("Payment job not found", ErrCodePaymentJobNotFound)
+	ErrPaymentQueueFull   = NewTooManyRequestsError("Payment queue is full, please try again later", ErrCodePaymentQueueFull)
+
+	ErrPaymentNotFound = NewNotFoundError("Payment not found", ErrCodePaymentNotFound)
+	ErrWebhookNotFound = NewNotFoundError("Webhook not found", ErrCodeWebhookNotFound)
+
+	ErrRequestTimeout = NewServiceUnavailableError("Request timed out, please try again later", ErrCodeRequestTimeout)
 )
 
 func IsAppError(err error) (*AppError, bool) {
-	if appErr, ok := err.(*AppError); ok {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
 		return appErr, true
 	}
 	return nil, false
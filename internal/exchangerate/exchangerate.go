@@ -0,0 +1,104 @@
+package exchangerate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	rateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/exchangerate"
+	"github.com/frahmantamala/expense-management/internal/core/money"
+)
+
+// DateLayout formats the day a rate applies to.
+const DateLayout = time.DateOnly
+
+// MaxRateAge is how far back a rate may be carried forward to a day that
+// has none of its own, covering weekends and bank holidays.
+const MaxRateAge = 7 * 24 * time.Hour
+
+// SourceManual marks rates an operator typed in.
+const SourceManual = "manual"
+
+// Rate is what one major unit of Currency was worth in rupiah on Date.
+type Rate struct {
+	Currency  string    `json:"currency"`
+	Date      string    `json:"date"`
+	Rate      string    `json:"rate"`
+	Source    string    `json:"source"`
+	SetBy     *int64    `json:"set_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SetRateDTO struct {
+	// Rate is a decimal string such as "16250.25", with at most 14 digits
+	// before the point and 10 after it.
+	Rate   string `json:"rate" validate:"required"`
+	Source string `json:"source,omitempty" validate:"max=100"`
+}
+
+func (dto SetRateDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if err := money.ValidateRateValue(dto.Rate); err != nil {
+		return errors.NewValidationFieldError("rate", "rate must be a positive decimal such as 16250.25", errors.ErrCodeValidationFailed)
+	}
+	whole, frac, _ := strings.Cut(dto.Rate, ".")
+	if len(strings.TrimLeft(whole, "0")) > 14 || len(frac) > 10 {
+		return errors.NewValidationFieldError("rate", "rate allows at most 14 digits before the point and 10 after it", errors.ErrCodeValidationFailed)
+	}
+	return nil
+}
+
+// RatesResponse lists the rates in effect on Date: each currency's rate for
+// that day, or its latest earlier one.
+type RatesResponse struct {
+	Date  string  `json:"date"`
+	Rates []*Rate `json:"rates"`
+}
+
+var (
+	ErrInvalidDate     = errors.NewValidationFieldError("date", "date must be formatted as YYYY-MM-DD", errors.ErrCodeInvalidDate)
+	ErrInvalidCurrency = errors.NewValidationFieldError("currency", "currency must be a supported ISO 4217 code other than "+money.IDR, errors.ErrCodeValidationFailed)
+)
+
+// ParseDate parses date (YYYY-MM-DD) as a UTC day.
+func ParseDate(date string) (time.Time, error) {
+	day, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return day, nil
+}
+
+// Day returns midnight UTC of the calendar day t falls on in UTC.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == money.IDR || !money.IsSupported(currency) {
+		return "", ErrInvalidCurrency
+	}
+	return currency, nil
+}
+
+func newRateUnavailableError(currency string, day time.Time) *errors.AppError {
+	message := fmt.Sprintf("no %s exchange rate is set for %s or the %d days before it", currency, day.Format(DateLayout), int(MaxRateAge/(24*time.Hour)))
+	return errors.NewValidationError(message, errors.ErrCodeExchangeRateUnavailable)
+}
+
+func fromDataModel(r *rateDatamodel.Rate) *Rate {
+	return &Rate{
+		Currency:  r.Currency,
+		Date:      r.RateDate.Format(DateLayout),
+		Rate:      money.TrimRateValue(r.Rate),
+		Source:    r.Source,
+		SetBy:     r.SetBy,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package exchangerate_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestExchangeRate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Exchange Rate Suite")
+}
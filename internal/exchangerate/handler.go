@@ -0,0 +1,87 @@
+package exchangerate
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	ListRates(ctx context.Context, date string) (*RatesResponse, error)
+	SetRate(ctx context.Context, date, currency string, setBy int64, dto SetRateDTO) (*Rate, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListRates godoc
+// @Summary      List exchange rates in effect on a day
+// @Description  Each currency's rate into IDR for the day, or its latest earlier one. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        date  query     string  false  "Day, YYYY-MM-DD; today when omitted"
+// @Success      200   {object}  RatesResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Router       /admin/exchange-rates [get]
+func (h *Handler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.Service.ListRates(r.Context(), r.URL.Query().Get("date"))
+	if err != nil {
+		h.Log(r).Error("ListRates: service error", "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, rates)
+}
+
+// SetRate godoc
+// @Summary      Set or override a daily exchange rate
+// @Description  Sets what one unit of the currency is worth in IDR on the day. Expenses already converted keep the rate they were converted at. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        date      path      string      true  "Day, YYYY-MM-DD"
+// @Param        currency  path      string      true  "ISO 4217 code"
+// @Param        body      body      SetRateDTO  true  "Rate"
+// @Success      200       {object}  Rate
+// @Failure      400       {object}  transport.AppErrorResponse
+// @Failure      401       {object}  transport.ErrorResponse
+// @Failure      403       {object}  transport.ErrorResponse
+// @Failure      413       {object}  transport.AppErrorResponse
+// @Router       /admin/exchange-rates/{date}/{currency} [put]
+func (h *Handler) SetRate(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto SetRateDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	rate, err := h.Service.SetRate(r.Context(), chi.URLParam(r, "date"), chi.URLParam(r, "currency"), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, rate)
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	rateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/exchangerate"
+	"github.com/frahmantamala/expense-management/internal/exchangerate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateRepository struct {
+	db *gorm.DB
+}
+
+func NewRateRepository(db *gorm.DB) exchangerate.RepositoryAPI {
+	return &RateRepository{db: db}
+}
+
+// Days are compared as YYYY-MM-DD so the DATE column is not shifted by the
+// session time zone.
+func (r *RateRepository) ListOn(ctx context.Context, day time.Time) ([]*rateDatamodel.Rate, error) {
+	var rates []*rateDatamodel.Rate
+	err := r.db.WithContext(ctx).
+		Where(`rate_date = (SELECT MAX(latest.rate_date) FROM exchange_rates latest
+			WHERE latest.tenant_id = exchange_rates.tenant_id
+			AND latest.currency = exchange_rates.currency
+			AND latest.rate_date <= ?)`, day.Format(time.DateOnly)).
+		Order("currency").
+		Find(&rates).Error
+	return rates, err
+}
+
+func (r *RateRepository) LatestOn(ctx context.Context, currency string, day time.Time) (*rateDatamodel.Rate, error) {
+	var rate rateDatamodel.Rate
+	err := r.db.WithContext(ctx).
+		Where("currency = ? AND rate_date <= ?", currency, day.Format(time.DateOnly)).
+		Order("rate_date DESC").
+		First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *RateRepository) Upsert(ctx context.Context, rate *rateDatamodel.Rate) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "set_by", "updated_at"}),
+	}).Create(rate).Error
+}
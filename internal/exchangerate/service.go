@@ -0,0 +1,130 @@
+package exchangerate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	rateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/exchangerate"
+	"github.com/frahmantamala/expense-management/internal/core/money"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the rates of the tenant ctx is scoped to.
+type RepositoryAPI interface {
+	// ListOn returns, for every currency, its rate for day or its latest
+	// earlier one.
+	ListOn(ctx context.Context, day time.Time) ([]*rateDatamodel.Rate, error)
+	// LatestOn returns the rate of currency for day or its latest earlier
+	// one, or nil when there is none.
+	LatestOn(ctx context.Context, currency string, day time.Time) (*rateDatamodel.Rate, error)
+	// Upsert replaces the rate of the same currency and day.
+	Upsert(ctx context.Context, rate *rateDatamodel.Rate) error
+}
+
+// Service keeps the daily rate table expenses in other currencies are
+// converted to rupiah with.
+type Service struct {
+	repo   RepositoryAPI
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// ListRates lists the rates in effect on date (YYYY-MM-DD), today when date
+// is empty.
+func (s *Service) ListRates(ctx context.Context, date string) (*RatesResponse, error) {
+	day := Day(s.now())
+	if date != "" {
+		var err error
+		if day, err = ParseDate(date); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.repo.ListOn(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+
+	rates := make([]*Rate, len(rows))
+	for i, row := range rows {
+		rates[i] = fromDataModel(row)
+	}
+	return &RatesResponse{Date: day.Format(DateLayout), Rates: rates}, nil
+}
+
+// SetRate sets or overrides the rate of currency on date (YYYY-MM-DD).
+// Expenses already converted keep the rate they were converted at.
+func (s *Service) SetRate(ctx context.Context, date, currency string, setBy int64, dto SetRateDTO) (*Rate, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	day, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	currency, err = parseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	source := dto.Source
+	if source == "" {
+		source = SourceManual
+	}
+	row := &rateDatamodel.Rate{
+		Currency: currency,
+		RateDate: day,
+		Rate:     dto.Rate,
+		Source:   source,
+		SetBy:    &setBy,
+	}
+	if err := s.repo.Upsert(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to set exchange rate: %w", err)
+	}
+
+	s.log(ctx).Info("exchange rate set", "currency", currency, "date", date, "rate", dto.Rate, "source", source, "set_by", setBy)
+	return fromDataModel(row), nil
+}
+
+// RateOn returns the rate to convert currency into rupiah with on day: its
+// rate for that day, or the latest one within MaxRateAge before it. It
+// reports an EXCHANGE_RATE_UNAVAILABLE error when there is none.
+func (s *Service) RateOn(ctx context.Context, currency string, day time.Time) (*money.Rate, error) {
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	day = Day(day)
+
+	row, err := s.repo.LatestOn(ctx, currency, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rate: %w", err)
+	}
+	if row == nil || day.Sub(Day(row.RateDate)) > MaxRateAge {
+		s.log(ctx).Warn("no exchange rate to convert with", "currency", currency, "date", day.Format(DateLayout))
+		return nil, newRateUnavailableError(currency, day)
+	}
+
+	return &money.Rate{
+		From:     row.Currency,
+		To:       money.IDR,
+		Value:    money.TrimRateValue(row.Rate),
+		Source:   row.Source,
+		Date:     Day(row.RateDate),
+		QuotedAt: row.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package exchangerate_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	rateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/exchangerate"
+	"github.com/frahmantamala/expense-management/internal/core/money"
+	"github.com/frahmantamala/expense-management/internal/exchangerate"
+)
+
+type rateKey struct {
+	currency string
+	day      time.Time
+}
+
+// mockRepository pads rates to ten places as the NUMERIC column does.
+type mockRepository struct {
+	rates map[rateKey]*rateDatamodel.Rate
+}
+
+func (m *mockRepository) ListOn(ctx context.Context, day time.Time) ([]*rateDatamodel.Rate, error) {
+	latest := map[string]*rateDatamodel.Rate{}
+	for key := range m.rates {
+		if _, ok := latest[key.currency]; !ok {
+			latest[key.currency], _ = m.LatestOn(ctx, key.currency, day)
+		}
+	}
+
+	var out []*rateDatamodel.Rate
+	for _, r := range latest {
+		if r != nil {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out, nil
+}
+
+func (m *mockRepository) LatestOn(_ context.Context, currency string, day time.Time) (*rateDatamodel.Rate, error) {
+	var found *rateDatamodel.Rate
+	for key, r := range m.rates {
+		if key.currency == currency && !key.day.After(day) && (found == nil || key.day.After(found.RateDate)) {
+			found = r
+		}
+	}
+	return found, nil
+}
+
+func (m *mockRepository) Upsert(_ context.Context, rate *rateDatamodel.Rate) error {
+	stored := *rate
+	stored.Rate = pad(rate.Rate)
+	stored.UpdatedAt = time.Now()
+	m.rates[rateKey{rate.Currency, rate.RateDate}] = &stored
+	return nil
+}
+
+func pad(rate string) string {
+	whole, frac, _ := strings.Cut(rate, ".")
+	return whole + "." + frac + strings.Repeat("0", 10-len(frac))
+}
+
+var _ = Describe("Exchange rate service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *exchangerate.Service
+	)
+
+	day := func(date string) time.Time {
+		d, _ := time.Parse(time.DateOnly, date)
+		return d
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{rates: map[rateKey]*rateDatamodel.Rate{}}
+		service = exchangerate.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("converts at the rate of the day, carried over a weekend", func() {
+		rate, err := service.SetRate(ctx, "2026-03-13", "usd", 9, exchangerate.SetRateDTO{Rate: "16250.25", Source: "bank-indonesia"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rate.Currency).To(Equal("USD"))
+		Expect(*rate.SetBy).To(Equal(int64(9)))
+
+		got, err := service.RateOn(ctx, "USD", day("2026-03-15"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Value).To(Equal("16250.25"))
+		Expect(got.Source).To(Equal("bank-indonesia"))
+		Expect(got.Date).To(Equal(day("2026-03-13")))
+
+		converted, err := got.Convert(money.New(1000, "USD"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(converted).To(Equal(money.Rupiah(162503)))
+	})
+
+	It("refuses to convert with a rate older than a week", func() {
+		_, err := service.SetRate(ctx, "2026-03-01", "USD", 9, exchangerate.SetRateDTO{Rate: "16250"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = service.RateOn(ctx, "USD", day("2026-03-09"))
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeExchangeRateUnavailable))
+
+		_, err = service.RateOn(ctx, "EUR", day("2026-03-01"))
+		appErr, ok = errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeExchangeRateUnavailable))
+	})
+
+	It("overrides a day's rate and lists what is in effect", func() {
+		_, err := service.SetRate(ctx, "2026-03-10", "USD", 9, exchangerate.SetRateDTO{Rate: "16000"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.SetRate(ctx, "2026-03-12", "EUR", 9, exchangerate.SetRateDTO{Rate: "17500.5"})
+		Expect(err).NotTo(HaveOccurred())
+		rate, err := service.SetRate(ctx, "2026-03-10", "USD", 10, exchangerate.SetRateDTO{Rate: "16100"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rate.Source).To(Equal(exchangerate.SourceManual))
+
+		listed, err := service.ListRates(ctx, "2026-03-11")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listed.Rates).To(HaveLen(1))
+		Expect(listed.Rates[0].Rate).To(Equal("16100"))
+		Expect(*listed.Rates[0].SetBy).To(Equal(int64(10)))
+
+		listed, err = service.ListRates(ctx, "2026-03-12")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listed.Rates).To(HaveLen(2))
+		Expect(listed.Rates[0].Currency).To(Equal("EUR"))
+		Expect(listed.Rates[0].Rate).To(Equal("17500.5"))
+	})
+
+	DescribeTable("refuses bad rates",
+		func(date, currency, rate string) {
+			_, err := service.SetRate(ctx, date, currency, 9, exchangerate.SetRateDTO{Rate: rate})
+			_, ok := errors.IsAppError(err)
+			Expect(ok).To(BeTrue())
+			Expect(repo.rates).To(BeEmpty())
+		},
+		Entry("a zero rate", "2026-03-10", "USD", "0"),
+		Entry("too many decimals", "2026-03-10", "USD", "1.00000000001"),
+		Entry("rupiah itself", "2026-03-10", "IDR", "1"),
+		Entry("an unknown currency", "2026-03-10", "XYZ", "1"),
+		Entry("a malformed day", "10/03/2026", "USD", "16000"),
+	)
+})
@@ -1,49 +1,100 @@
 package expense
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
 	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	"github.com/frahmantamala/expense-management/internal/core/money"
 )
 
-type CreateExpenseDTO struct {
-	AmountIDR       int64     `json:"amount_idr" validate:"required,min=1"`
-	Description     string    `json:"description" validate:"required,min=1,max=500"`
-	Category        string    `json:"category" validate:"required"`
-	ExpenseDate     time.Time `json:"expense_date" validate:"required"`
-	ReceiptURL      *string   `json:"receipt_url,omitempty"`
-	ReceiptFileName *string   `json:"receipt_filename,omitempty"`
-}
+// MinExpenseAmount and MaxExpenseAmount bound claims when the tenant sets
+// no limits of its own.
+const (
+	MinExpenseAmount int64 = 10000
+	MaxExpenseAmount int64 = 50000000
+)
 
-func (dto CreateExpenseDTO) Validate() error {
-	validator := validation.NewValidator()
+// AmountLimits bounds the amount of a new expense, in rupiah.
+type AmountLimits struct {
+	Min int64
+	Max int64
+}
 
-	validator.Field("amount_idr", dto.AmountIDR).
-		Required().
-		MinInt(1, errors.ErrCodeInvalidAmount).
-		MinInt(10000, errors.ErrCodeAmountTooLow).
-		MaxInt(50000000, errors.ErrCodeAmountTooHigh)
+var DefaultAmountLimits = AmountLimits{Min: MinExpenseAmount, Max: MaxExpenseAmount}
 
-	validator.Field("description", dto.Description).
-		Required().
-		MinLength(1).
-		MaxLength(500)
+func init() {
+	// The bounds depend on the tenant and category, so they are checked by
+	// Validate rather than by the tag.
+	validation.RegisterRule("expense_amount", func(fv *validation.FieldValidator, _ string) {
+		fv.MinInt(1, errors.ErrCodeInvalidAmount)
+	})
+}
 
-	validator.Field("category", dto.Category).
-		Required()
+type CreateExpenseDTO struct {
+	AmountIDR int64 `json:"amount_idr" validate:"currency=IDR,required,expense_amount"`
+	// Amount may be sent instead of AmountIDR as {"amount", "currency"} in
+	// minor units. Other currencies are converted into AmountIDR at the rate
+	// of the expense date when exchange rates are enabled.
+	Amount          *money.Money `json:"amount,omitempty"`
+	Description     string       `json:"description" validate:"required,min=1,max=500"`
+	Category        string       `json:"category" validate:"required"`
+	ExpenseDate     time.Time    `json:"expense_date" validate:"required,notfuture"`
+	ReceiptURL      *string      `json:"receipt_url,omitempty"`
+	ReceiptFileName *string      `json:"receipt_filename,omitempty"`
+	// PayoutAccountID picks one of the user's bank accounts to be paid into;
+	// the default account is used when omitted.
+	PayoutAccountID *int64 `json:"payout_account_id,omitempty"`
+	// SubmittedForUserID files the expense for another user who granted the
+	// caller delegation. The expense is theirs; the caller is recorded as
+	// its submitter.
+	SubmittedForUserID *int64 `json:"submitted_for_user_id,omitempty"`
+}
 
-	validator.Field("expense_date", dto.ExpenseDate).
-		NotFuture()
+// resolveAmount folds Amount into AmountIDR.
+func (dto *CreateExpenseDTO) resolveAmount() error {
+	if dto.Amount == nil {
+		return nil
+	}
+	if dto.Amount.Currency != DefaultCurrency {
+		return errors.NewValidationFieldError("amount", "currency must be "+DefaultCurrency, errors.ErrCodeValidationFailed)
+	}
+	if dto.AmountIDR != 0 && dto.AmountIDR != dto.Amount.Amount {
+		return errors.NewValidationFieldError("amount", "amount and amount_idr disagree", errors.ErrCodeValidationFailed)
+	}
+	dto.AmountIDR = dto.Amount.Amount
+	return nil
+}
 
-	if appErr := validator.Validate(); appErr != nil {
+// Validate checks the fields and that the amount lies within limits.
+func (dto CreateExpenseDTO) Validate(limits AmountLimits) error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if appErr := validation.ValidateExpenseAmount(dto.AmountIDR, limits.Min, limits.Max); appErr != nil {
 		return appErr
 	}
 	return nil
 }
 
+// ValidateReceipt requires a receipt_url when the amount is above
+// requiredAbove; a zero requiredAbove leaves receipts optional.
+func (dto CreateExpenseDTO) ValidateReceipt(requiredAbove int64) error {
+	if requiredAbove <= 0 || dto.AmountIDR <= requiredAbove {
+		return nil
+	}
+	if dto.ReceiptURL != nil && strings.TrimSpace(*dto.ReceiptURL) != "" {
+		return nil
+	}
+	return errors.NewLocalizedFieldError("receipt_url", "validation.receipt_required",
+		i18n.Params{"min": i18n.Money{Amount: requiredAbove, Currency: "IDR"}}, errors.ErrCodeReceiptRequired)
+}
+
 type UpdateExpenseStatusDTO struct {
 	Status string `json:"status" validate:"required,oneof=approved rejected"`
 	Reason string `json:"reason,omitempty"`
@@ -62,13 +113,67 @@ func (dto UpdateExpenseStatusDTO) Validate() error {
 	return nil
 }
 
+// ApproveExpenseDTO is the optional body of an approval. ApprovedAmountIDR
+// approves less than was claimed, and Reason, which the submitter sees on
+// the expense, says why.
+type ApproveExpenseDTO struct {
+	ApprovedAmountIDR *int64 `json:"approved_amount_idr,omitempty" example:"150000"`
+	Reason            string `json:"reason,omitempty" validate:"max=1000"`
+}
+
+// MaxAdjustmentReasonLength bounds the reason for approving less.
+const MaxAdjustmentReasonLength = 1000
+
+func (dto ApproveExpenseDTO) Validate() error {
+	if dto.ApprovedAmountIDR == nil {
+		if strings.TrimSpace(dto.Reason) != "" {
+			return errors.NewValidationFieldError("reason", "reason is only taken with approved_amount_idr", errors.ErrCodeValidationFailed)
+		}
+		return nil
+	}
+	if *dto.ApprovedAmountIDR <= 0 {
+		return errors.NewValidationFieldError("approved_amount_idr", "approved_amount_idr must be positive", errors.ErrCodeInvalidAmount)
+	}
+	if strings.TrimSpace(dto.Reason) == "" {
+		return errors.NewValidationFieldError("reason", "reason is required when approving a reduced amount", errors.ErrCodeValidationFailed)
+	}
+	if len(dto.Reason) > MaxAdjustmentReasonLength {
+		return errors.NewValidationFieldError("reason", "reason must be at most "+strconv.Itoa(MaxAdjustmentReasonLength)+" characters", errors.ErrCodeValidationFailed)
+	}
+	return nil
+}
+
+// RejectExpenseDTO explains a rejection: Code is one of RejectionCodes and
+// Reason is the approver's comment, which the submitter sees on the expense.
+// With CannedResponseID the code and comment come from that canned response,
+// and Reason, when given, is added below it.
 type RejectExpenseDTO struct {
-	Reason string `json:"reason" validate:"required"`
+	Code             string `json:"code,omitempty" enums:"policy_violation,missing_receipt,duplicate,other"`
+	Reason           string `json:"reason,omitempty" validate:"max=1000"`
+	CannedResponseID *int64 `json:"canned_response_id,omitempty"`
 }
 
+// MaxRejectionReasonLength bounds the rejection comment.
+const MaxRejectionReasonLength = 1000
+
 func (dto RejectExpenseDTO) Validate() error {
-	if dto.Reason == "" {
-		return errors.NewValidationError("reason is required when rejecting an expense", errors.ErrCodeValidationFailed)
+	if dto.CannedResponseID != nil {
+		if dto.Code != "" && !IsRejectionCode(dto.Code) {
+			return errors.NewValidationFieldError("code", "code must be one of "+strings.Join(RejectionCodes, ", "), errors.ErrCodeValidationFailed)
+		}
+		if len(dto.Reason) > MaxRejectionReasonLength {
+			return errors.NewValidationFieldError("reason", "reason must be at most "+strconv.Itoa(MaxRejectionReasonLength)+" characters", errors.ErrCodeValidationFailed)
+		}
+		return nil
+	}
+	if !IsRejectionCode(dto.Code) {
+		return errors.NewValidationFieldError("code", "code must be one of "+strings.Join(RejectionCodes, ", "), errors.ErrCodeValidationFailed)
+	}
+	if strings.TrimSpace(dto.Reason) == "" {
+		return errors.NewValidationFieldError("reason", "reason is required when rejecting an expense", errors.ErrCodeValidationFailed)
+	}
+	if len(dto.Reason) > MaxRejectionReasonLength {
+		return errors.NewValidationFieldError("reason", "reason must be at most "+strconv.Itoa(MaxRejectionReasonLength)+" characters", errors.ErrCodeValidationFailed)
 	}
 	return nil
 }
@@ -81,8 +186,33 @@ type ExpenseQueryParams struct {
 	Status     string `json:"status"`
 	SortBy     string `json:"sort_by"`
 	SortOrder  string `json:"sort_order"`
+
+	// DateFrom and DateTo bound expense_date, both days inclusive; MinAmount
+	// and MaxAmount bound amount_idr. Zero values do not filter.
+	DateFrom  time.Time `json:"date_from"`
+	DateTo    time.Time `json:"date_to"`
+	MinAmount int64     `json:"min_amount"`
+	MaxAmount int64     `json:"max_amount"`
+
+	// SearchQuery is Search parsed into full-text terms.
+	SearchQuery SearchQuery `json:"-"`
+
+	// IncludePayment adds each expense's latest payment to the list.
+	IncludePayment bool `json:"-"`
 }
 
+// IncludePayment is the include query parameter value that asks for
+// payment summaries.
+const IncludePayment = "payment"
+
+// QueryDateLayout is the format of the date_from and date_to query
+// parameters.
+const QueryDateLayout = "2006-01-02"
+
+// SortByRelevance orders full-text matches by rank; it is the default sort
+// whenever a search term is present.
+const SortByRelevance = "relevance"
+
 func (q *ExpenseQueryParams) SetDefaults() {
 	if q.PerPage <= 0 || q.PerPage > 100 {
 		q.PerPage = 20
@@ -90,8 +220,14 @@ func (q *ExpenseQueryParams) SetDefaults() {
 	if q.Page <= 0 {
 		q.Page = 1
 	}
+	if q.Search != "" && q.SearchQuery.IsEmpty() {
+		q.SearchQuery = ParseSearch(q.Search)
+	}
 	if q.SortBy == "" {
 		q.SortBy = "created_at"
+		if !q.SearchQuery.IsEmpty() {
+			q.SortBy = SortByRelevance
+		}
 	}
 	if q.SortOrder == "" {
 		q.SortOrder = "desc"
@@ -132,9 +268,29 @@ func (q *ExpenseQueryParams) ParseFromRequest(r *http.Request) {
 
 	q.Status = r.URL.Query().Get("status")
 
+	if d, err := time.Parse(QueryDateLayout, r.URL.Query().Get("date_from")); err == nil {
+		q.DateFrom = d
+	}
+	if d, err := time.Parse(QueryDateLayout, r.URL.Query().Get("date_to")); err == nil {
+		q.DateTo = d
+	}
+
+	if a, err := strconv.ParseInt(r.URL.Query().Get("min_amount"), 10, 64); err == nil && a > 0 {
+		q.MinAmount = a
+	}
+	if a, err := strconv.ParseInt(r.URL.Query().Get("max_amount"), 10, 64); err == nil && a > 0 {
+		q.MaxAmount = a
+	}
+
 	q.SortBy = r.URL.Query().Get("sort_by")
 	q.SortOrder = r.URL.Query().Get("sort_order")
 
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(include) == IncludePayment {
+			q.IncludePayment = true
+		}
+	}
+
 	q.SetDefaults()
 }
 
@@ -151,4 +307,33 @@ var (
 	ErrUnauthorizedAccess   = errors.ErrUnauthorizedAccess
 	ErrInvalidExpenseStatus = errors.ErrInvalidExpenseStatus
 	ErrCannotModifyExpense  = errors.ErrCannotModifyExpense
+	ErrInvalidCategory      = errors.NewLocalizedFieldError("category", "validation.category", nil, errors.ErrCodeInvalidCategory)
+	ErrCategoryNotLeaf      = errors.NewLocalizedFieldError("category", "validation.category_leaf", nil, errors.ErrCodeInvalidCategory)
+	// ErrPayoutAccountsDisabled is returned for a payout_account_id when no
+	// bank account encryption key is configured.
+	ErrPayoutAccountsDisabled = errors.NewValidationFieldError("payout_account_id", "payout bank accounts are not enabled", errors.ErrCodeValidationFailed)
+	// ErrDelegationDisabled is returned for a submitted_for_user_id when the
+	// service was built without delegations.
+	ErrDelegationDisabled = errors.NewValidationFieldError("submitted_for_user_id", "filing expenses for other users is not enabled", errors.ErrCodeValidationFailed)
+	// ErrReceiptRequired is returned when approving an expense above the
+	// tenant's receipt threshold that has no receipt attached.
+	ErrReceiptRequired = errors.NewValidationError("expense needs a receipt before it can be approved", errors.ErrCodeReceiptRequired)
+	// ErrCannedResponsesDisabled is returned for a canned_response_id when
+	// the service was built without canned responses.
+	ErrCannedResponsesDisabled = errors.NewValidationFieldError("canned_response_id", "canned responses are not enabled", errors.ErrCodeValidationFailed)
+	// ErrOwnExpenseDecision is returned when an approver decides an expense
+	// they own or filed as a delegate.
+	ErrOwnExpenseDecision = errors.NewForbiddenError("you cannot approve or reject an expense you own or filed", errors.ErrCodeOwnExpenseDecision)
 )
+
+// PendingLimitExceeded is the detail attached to a PENDING_LIMIT_EXCEEDED
+// error.
+type PendingLimitExceeded struct {
+	Limit   int64 `json:"limit"`
+	Pending int64 `json:"pending"`
+}
+
+func newPendingLimitError(detail PendingLimitExceeded) *errors.AppError {
+	message := fmt.Sprintf("at most %d expenses can be waiting for approval at once", detail.Limit)
+	return errors.NewValidationError(message, errors.ErrCodePendingLimitExceeded).WithDetails(detail)
+}
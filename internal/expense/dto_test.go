@@ -0,0 +1,61 @@
+package expense_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+var _ = Describe("RejectExpenseDTO", func() {
+	It("accepts a known code with a comment", func() {
+		dto := expense.RejectExpenseDTO{Code: expense.RejectionDuplicate, Reason: "Already claimed in #41"}
+		Expect(dto.Validate()).To(Succeed())
+	})
+
+	DescribeTable("rejects incomplete rejections",
+		func(dto expense.RejectExpenseDTO, field string) {
+			appErr, ok := errors.IsAppError(dto.Validate())
+			Expect(ok).To(BeTrue())
+			details := appErr.Details.(errors.ValidationErrors)
+			Expect(details.Errors[0].Field).To(Equal(field))
+		},
+		Entry("missing code", expense.RejectExpenseDTO{Reason: "No receipt"}, "code"),
+		Entry("unknown code", expense.RejectExpenseDTO{Code: "too_expensive", Reason: "No receipt"}, "code"),
+		Entry("blank comment", expense.RejectExpenseDTO{Code: expense.RejectionOther, Reason: "  "}, "reason"),
+		Entry("long comment", expense.RejectExpenseDTO{Code: expense.RejectionOther, Reason: strings.Repeat("x", 1001)}, "reason"),
+	)
+})
+
+var _ = Describe("ApproveExpenseDTO", func() {
+	It("accepts an empty body", func() {
+		Expect(expense.ApproveExpenseDTO{}.Validate()).To(Succeed())
+	})
+
+	It("accepts a reduced amount with a reason", func() {
+		amount := int64(150000)
+		dto := expense.ApproveExpenseDTO{ApprovedAmountIDR: &amount, Reason: "Alcohol is not reimbursed"}
+		Expect(dto.Validate()).To(Succeed())
+	})
+
+	DescribeTable("rejects incomplete adjustments",
+		func(amount *int64, reason, field string) {
+			dto := expense.ApproveExpenseDTO{ApprovedAmountIDR: amount, Reason: reason}
+			appErr, ok := errors.IsAppError(dto.Validate())
+			Expect(ok).To(BeTrue())
+			details := appErr.Details.(errors.ValidationErrors)
+			Expect(details.Errors[0].Field).To(Equal(field))
+		},
+		Entry("zero amount", ptrInt64(0), "Too much", "approved_amount_idr"),
+		Entry("missing reason", ptrInt64(150000), " ", "reason"),
+		Entry("long reason", ptrInt64(150000), strings.Repeat("x", 1001), "reason"),
+		Entry("reason without amount", nil, "Too much", "reason"),
+	)
+})
+
+func ptrInt64(v int64) *int64 {
+	return &v
+}
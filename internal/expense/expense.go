@@ -4,53 +4,226 @@ import (
 	"time"
 
 	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	"github.com/frahmantamala/expense-management/internal/core/money"
 )
 
 type Expense struct {
-	ID              int64      `json:"id"`
-	UserID          int64      `json:"user_id"`
-	AmountIDR       int64      `json:"amount_idr"`
-	Description     string     `json:"description"`
-	Category        string     `json:"category"`
-	ReceiptURL      *string    `json:"receipt_url,omitempty"`
-	ReceiptFileName *string    `json:"receipt_filename,omitempty"`
-	ExpenseStatus   string     `json:"expense_status"`
-	ExpenseDate     time.Time  `json:"expense_date"`
-	SubmittedAt     time.Time  `json:"submitted_at"`
-	ProcessedAt     *time.Time `json:"processed_at,omitempty"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID              int64   `json:"id"`
+	UserID          int64   `json:"user_id"`
+	AmountIDR       int64   `json:"amount_idr"`
+	Description     string  `json:"description"`
+	Category        string  `json:"category"`
+	ReceiptURL      *string `json:"receipt_url,omitempty"`
+	ReceiptFileName *string `json:"receipt_filename,omitempty"`
+	ReceiptKey      *string `json:"-"`
+	PayoutAccountID *int64  `json:"payout_account_id,omitempty"`
+	ExpenseStatus   string  `json:"expense_status"`
+	// SubmittedBy is the delegate who filed the expense for UserID, who
+	// owns it; unset when UserID filed it.
+	SubmittedBy *int64 `json:"submitted_by,omitempty"`
+	// RejectionCode is one of RejectionCodes; it and RejectionReason are set
+	// once the expense is rejected.
+	RejectionCode   *string `json:"rejection_code,omitempty"`
+	RejectionReason *string `json:"rejection_reason,omitempty"`
+	// CannedResponseID is the canned response the rejection was made with,
+	// if any.
+	CannedResponseID *int64 `json:"canned_response_id,omitempty"`
+	// ApprovedBy and RejectedBy are the users who decided the expense;
+	// auto-approved expenses have neither.
+	ApprovedBy  *int64     `json:"approved_by,omitempty"`
+	RejectedBy  *int64     `json:"rejected_by,omitempty"`
+	ExpenseDate time.Time  `json:"expense_date"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// ApprovedAt is when the expense was approved, by a user or
+	// automatically; ApprovalSeconds is how long it waited after
+	// submission, set only when a user approved it.
+	ApprovedAt      *time.Time `json:"approved_at,omitempty"`
+	ApprovalSeconds *int64     `json:"approval_seconds,omitempty"`
+	// ApprovedAmountIDR is set when the approver approved less than
+	// AmountIDR; the expense is paid that amount instead, for the reason in
+	// AdjustmentReason.
+	ApprovedAmountIDR *int64  `json:"approved_amount_idr,omitempty"`
+	AdjustmentReason  *string `json:"adjustment_reason,omitempty"`
+	// ReceiptMissing flags pending expenses that cannot be approved until a
+	// receipt is attached. It is computed on read, not stored.
+	ReceiptMissing bool `json:"receipt_missing,omitempty"`
+	// ReceiptThumbnailKey is the receipt's preview once the thumbnail worker
+	// has rendered it; ReceiptThumbnailError is why it could not.
+	ReceiptThumbnailKey   *string `json:"-"`
+	ReceiptThumbnailError *string `json:"-"`
+	// ReceiptSHA256 is the hash of the uploaded receipt file.
+	// DuplicateReceiptOf lists the other claimed expenses whose receipt has
+	// the same hash that the reader may view, a possible double claim; it is
+	// computed on read for team and all scopes.
+	ReceiptSHA256      *string `json:"-"`
+	DuplicateReceiptOf []int64 `json:"duplicate_receipt_of,omitempty"`
+	// ExchangeRate is set when the expense was submitted in another currency
+	// and converted into AmountIDR.
+	ExchangeRate *RateSnapshot `json:"exchange_rate,omitempty"`
+	// Payment summarises the latest payment. Lists fill it in only when
+	// asked with include=payment, and only for expenses that have one.
+	Payment *PaymentSummary `json:"payment,omitempty"`
 }
 
+type PaymentSummary struct {
+	Status      string     `json:"status"`
+	RetryCount  int        `json:"retry_count"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+// RateSnapshot is the rate an expense was converted into rupiah with when
+// it was submitted. It is kept on the expense so AmountIDR can be
+// reproduced after the rate table is corrected.
+type RateSnapshot struct {
+	OriginalAmount money.Money `json:"original_amount"`
+	Rate           string      `json:"rate"`
+	Source         string      `json:"source"`
+	RateDate       time.Time   `json:"rate_date"`
+	QuotedAt       time.Time   `json:"quoted_at"`
+}
+
+const (
+	ExpenseStatusDraft             = "draft"
+	ExpenseStatusPendingApproval   = "pending_approval"
+	ExpenseStatusApproved          = "approved"
+	ExpenseStatusProcessingPayment = "processing_payment"
+	ExpenseStatusPaymentFailed     = "payment_failed"
+	ExpenseStatusCompleted         = "completed"
+	ExpenseStatusRejected          = "rejected"
+	ExpenseStatusCancelled         = "cancelled"
+	AutoApprovalThreshold          = 1000000
+)
+
+// ApprovedStatuses are the statuses of approved expenses, paid or not; they
+// count as spend.
+var ApprovedStatuses = []string{ExpenseStatusApproved, ExpenseStatusProcessingPayment, ExpenseStatusPaymentFailed, ExpenseStatusCompleted}
+
+// UnpaidStatuses are the statuses of approved expenses still to be paid.
+var UnpaidStatuses = []string{ExpenseStatusApproved, ExpenseStatusProcessingPayment, ExpenseStatusPaymentFailed}
+
+// Rejection reason codes. Every rejection carries one of these along with the
+// approver's comment.
+const (
+	RejectionPolicyViolation = "policy_violation"
+	RejectionMissingReceipt  = "missing_receipt"
+	RejectionDuplicate       = "duplicate"
+	RejectionOther           = "other"
+)
+
+var RejectionCodes = []string{RejectionPolicyViolation, RejectionMissingReceipt, RejectionDuplicate, RejectionOther}
+
+func IsRejectionCode(code string) bool {
+	for _, c := range RejectionCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// CannedResponse is a tenant's stock comment for rejections under Code,
+// picked by ID instead of typing a reason.
+type CannedResponse struct {
+	ID   int64
+	Code string
+	Body string
+}
+
+// ApprovalRoute overrides the default approval flow for a category: only
+// holders of ApproverPermission (or admins) may decide, and RequireApproval
+// disables auto-approval regardless of amount.
+type ApprovalRoute struct {
+	ApproverPermission string
+	RequireApproval    bool
+}
+
+// ReceiptClaim is an expense claimed with a given receipt.
+type ReceiptClaim struct {
+	ExpenseID int64
+	UserID    int64
+}
+
+// ViewScope is how much of the expense data a user may read.
+type ViewScope int
+
 const (
-	ExpenseStatusPendingApproval = "pending_approval"
-	ExpenseStatusApproved        = "approved"
-	ExpenseStatusRejected        = "rejected"
-	ExpenseStatusCompleted       = "completed"
-	AutoApprovalThreshold        = 1000000
+	ViewScopeOwn ViewScope = iota
+	ViewScopeTeam
+	ViewScopeAll
 )
 
-func (e *Expense) CanBeApproved() bool {
-	return e.ExpenseStatus == ExpenseStatusPendingApproval
+// Amount is AmountIDR as Money. Expenses are booked in rupiah until the
+// amount_idr column carries its own currency.
+func (e *Expense) Amount() money.Money {
+	return money.Rupiah(e.AmountIDR)
+}
+
+// PayableAmountIDR is what the expense is paid: the approved amount when
+// the approver reduced the claim, AmountIDR otherwise.
+func (e *Expense) PayableAmountIDR() int64 {
+	if e.ApprovedAmountIDR != nil {
+		return *e.ApprovedAmountIDR
+	}
+	return e.AmountIDR
+}
+
+// HasReceipt reports whether a receipt was linked or uploaded.
+func (e *Expense) HasReceipt() bool {
+	return (e.ReceiptURL != nil && *e.ReceiptURL != "") || e.ReceiptKey != nil
 }
 
-func (e *Expense) CanBeRejected() bool {
-	return e.ExpenseStatus == ExpenseStatusPendingApproval
+// NeedsReceipt reports whether the expense is above requiredAbove without a
+// receipt; a zero requiredAbove never requires one.
+func (e *Expense) NeedsReceipt(requiredAbove int64) bool {
+	return requiredAbove > 0 && e.AmountIDR > requiredAbove && !e.HasReceipt()
 }
 
-func (e *Expense) ShouldBeAutoApproved() bool {
-	return e.AmountIDR < AutoApprovalThreshold
+// ShouldBeAutoApproved reports whether the expense is below threshold; a
+// zero threshold never auto-approves.
+func (e *Expense) ShouldBeAutoApproved(threshold int64) bool {
+	return e.AmountIDR < threshold
 }
 
 func (e *Expense) Approve() {
 	e.ExpenseStatus = ExpenseStatusApproved
 	now := time.Now()
 	e.ProcessedAt = &now
+	e.ApprovedAt = &now
 	e.UpdatedAt = now
 }
 
-func (e *Expense) Reject() {
+// ApproveBy approves on behalf of approverID.
+func (e *Expense) ApproveBy(approverID int64) {
+	e.Approve()
+	e.ApprovedBy = &approverID
+	e.ApprovalSeconds = approvalSeconds(e.ApprovedBy, e.SubmittedAt, e.ApprovedAt)
+}
+
+// ApproveAdjustedBy approves on behalf of approverID for amountIDR, less
+// than the amount claimed, for reason.
+func (e *Expense) ApproveAdjustedBy(approverID, amountIDR int64, reason string) {
+	e.ApproveBy(approverID)
+	e.ApprovedAmountIDR = &amountIDR
+	e.AdjustmentReason = &reason
+}
+
+// approvalSeconds is the time to approve of an expense a user approved.
+func approvalSeconds(approvedBy *int64, submittedAt time.Time, approvedAt *time.Time) *int64 {
+	if approvedBy == nil || approvedAt == nil || submittedAt.IsZero() {
+		return nil
+	}
+	seconds := int64(approvedAt.Sub(submittedAt) / time.Second)
+	return &seconds
+}
+
+func (e *Expense) Reject(approverID int64, code, reason string) {
 	e.ExpenseStatus = ExpenseStatusRejected
+	e.RejectedBy = &approverID
+	e.RejectionCode = &code
+	e.RejectionReason = &reason
 	now := time.Now()
 	e.ProcessedAt = &now
 	e.UpdatedAt = now
@@ -67,7 +240,9 @@ func (e *Expense) NeedsPaymentProcessing() bool {
 	return e.ExpenseStatus == ExpenseStatusApproved
 }
 
-func NewExpense(userID int64, dto CreateExpenseDTO) *Expense {
+// NewExpense builds a submitted expense, approved at once when it is below
+// threshold. route may be nil when the category has no routing rule.
+func NewExpense(userID int64, dto CreateExpenseDTO, route *ApprovalRoute, threshold int64) *Expense {
 	now := time.Now()
 
 	expense := &Expense{
@@ -77,6 +252,7 @@ func NewExpense(userID int64, dto CreateExpenseDTO) *Expense {
 		Category:        dto.Category,
 		ReceiptURL:      dto.ReceiptURL,
 		ReceiptFileName: dto.ReceiptFileName,
+		PayoutAccountID: dto.PayoutAccountID,
 		ExpenseStatus:   ExpenseStatusPendingApproval,
 		ExpenseDate:     dto.ExpenseDate,
 		SubmittedAt:     now,
@@ -84,7 +260,7 @@ func NewExpense(userID int64, dto CreateExpenseDTO) *Expense {
 		UpdatedAt:       now,
 	}
 
-	if expense.ShouldBeAutoApproved() {
+	if expense.ShouldBeAutoApproved(threshold) && (route == nil || !route.RequireApproval) {
 		expense.Approve()
 	}
 
@@ -92,39 +268,97 @@ func NewExpense(userID int64, dto CreateExpenseDTO) *Expense {
 }
 
 func ToDataModel(e *Expense) *expenseDatamodel.Expense {
-	return &expenseDatamodel.Expense{
-		ID:              e.ID,
-		UserID:          e.UserID,
-		AmountIDR:       e.AmountIDR,
-		Description:     e.Description,
-		Category:        e.Category,
-		ReceiptURL:      e.ReceiptURL,
-		ReceiptFileName: e.ReceiptFileName,
-		ExpenseStatus:   e.ExpenseStatus,
-		ExpenseDate:     e.ExpenseDate,
-		SubmittedAt:     e.SubmittedAt,
-		ProcessedAt:     e.ProcessedAt,
-		CreatedAt:       e.CreatedAt,
-		UpdatedAt:       e.UpdatedAt,
+	data := &expenseDatamodel.Expense{
+		ID:               e.ID,
+		UserID:           e.UserID,
+		AmountIDR:        e.AmountIDR,
+		Description:      e.Description,
+		Category:         e.Category,
+		ReceiptURL:       e.ReceiptURL,
+		ReceiptFileName:  e.ReceiptFileName,
+		ReceiptKey:       e.ReceiptKey,
+		PayoutAccountID:  e.PayoutAccountID,
+		ExpenseStatus:    e.ExpenseStatus,
+		RejectionCode:    e.RejectionCode,
+		RejectionReason:  e.RejectionReason,
+		CannedResponseID: e.CannedResponseID,
+		ApprovedBy:       e.ApprovedBy,
+		ApprovedAt:       e.ApprovedAt,
+		RejectedBy:       e.RejectedBy,
+		ExpenseDate:      e.ExpenseDate,
+		SubmittedAt:      e.SubmittedAt,
+		ProcessedAt:      e.ProcessedAt,
+		CreatedAt:        e.CreatedAt,
+		UpdatedAt:        e.UpdatedAt,
 	}
+	data.ApprovedAmountIDR = e.ApprovedAmountIDR
+	data.AdjustmentReason = e.AdjustmentReason
+	data.ReceiptThumbnailKey = e.ReceiptThumbnailKey
+	data.ReceiptThumbnailError = e.ReceiptThumbnailError
+	data.ReceiptSHA256 = e.ReceiptSHA256
+	data.SubmittedBy = e.SubmittedBy
+	if r := e.ExchangeRate; r != nil {
+		data.OriginalAmount = &r.OriginalAmount.Amount
+		data.OriginalCurrency = &r.OriginalAmount.Currency
+		data.ExchangeRate = &r.Rate
+		data.ExchangeRateSource = &r.Source
+		data.ExchangeRateDate = &r.RateDate
+		data.ExchangeRateQuotedAt = &r.QuotedAt
+	}
+	return data
 }
 
 func FromDataModel(e *expenseDatamodel.Expense) *Expense {
-	return &Expense{
-		ID:              e.ID,
-		UserID:          e.UserID,
-		AmountIDR:       e.AmountIDR,
-		Description:     e.Description,
-		Category:        e.Category,
-		ReceiptURL:      e.ReceiptURL,
-		ReceiptFileName: e.ReceiptFileName,
-		ExpenseStatus:   e.ExpenseStatus,
-		ExpenseDate:     e.ExpenseDate,
-		SubmittedAt:     e.SubmittedAt,
-		ProcessedAt:     e.ProcessedAt,
-		CreatedAt:       e.CreatedAt,
-		UpdatedAt:       e.UpdatedAt,
+	expense := &Expense{
+		ID:               e.ID,
+		UserID:           e.UserID,
+		AmountIDR:        e.AmountIDR,
+		Description:      e.Description,
+		Category:         e.Category,
+		ReceiptURL:       e.ReceiptURL,
+		ReceiptFileName:  e.ReceiptFileName,
+		ReceiptKey:       e.ReceiptKey,
+		PayoutAccountID:  e.PayoutAccountID,
+		ExpenseStatus:    e.ExpenseStatus,
+		RejectionCode:    e.RejectionCode,
+		RejectionReason:  e.RejectionReason,
+		CannedResponseID: e.CannedResponseID,
+		ApprovedBy:       e.ApprovedBy,
+		ApprovedAt:       e.ApprovedAt,
+		RejectedBy:       e.RejectedBy,
+		ExpenseDate:      e.ExpenseDate,
+		SubmittedAt:      e.SubmittedAt,
+		ProcessedAt:      e.ProcessedAt,
+		CreatedAt:        e.CreatedAt,
+		UpdatedAt:        e.UpdatedAt,
+		ApprovalSeconds:  approvalSeconds(e.ApprovedBy, e.SubmittedAt, e.ApprovedAt),
+	}
+	expense.ApprovedAmountIDR = e.ApprovedAmountIDR
+	expense.AdjustmentReason = e.AdjustmentReason
+	expense.ReceiptThumbnailKey = e.ReceiptThumbnailKey
+	expense.ReceiptThumbnailError = e.ReceiptThumbnailError
+	expense.ReceiptSHA256 = e.ReceiptSHA256
+	expense.SubmittedBy = e.SubmittedBy
+	if e.OriginalAmount != nil && e.OriginalCurrency != nil && e.ExchangeRate != nil {
+		snapshot := &RateSnapshot{
+			OriginalAmount: money.New(*e.OriginalAmount, *e.OriginalCurrency),
+			Rate:           money.TrimRateValue(*e.ExchangeRate),
+		}
+		if e.ExchangeRateSource != nil {
+			snapshot.Source = *e.ExchangeRateSource
+		}
+		if e.ExchangeRateDate != nil {
+			snapshot.RateDate = *e.ExchangeRateDate
+		}
+		if e.ExchangeRateQuotedAt != nil {
+			snapshot.QuotedAt = *e.ExchangeRateQuotedAt
+		}
+		expense.ExchangeRate = snapshot
 	}
+	if p := e.Payment; p != nil {
+		expense.Payment = &PaymentSummary{Status: p.Status, RetryCount: p.RetryCount, ProcessedAt: p.ProcessedAt}
+	}
+	return expense
 }
 
 func FromDataModelSlice(expenses []*expenseDatamodel.Expense) []*Expense {
@@ -1,8 +1,7 @@
 package expense
 
 import (
-	"encoding/json"
-	"log/slog"
+	"context"
 	"net/http"
 	"strconv"
 
@@ -12,213 +11,309 @@ import (
 	"github.com/go-chi/chi"
 )
 
-type ServiceAPI interface {
-	CreateExpense(req *CreateExpenseDTO, userID int64) (*Expense, error)
-	GetExpenseByID(expenseID int64, userID int64, userPermissions []string) (*Expense, error)
-	GetExpensesForUser(userID int64, userPermissions []string, params *ExpenseQueryParams) ([]*Expense, error)
-	GetExpensesCountForUser(userID int64, userPermissions []string, params *ExpenseQueryParams) (int64, error)
-	UpdateExpenseStatus(expenseID int64, status string, userID int64, userPermissions []string) (*Expense, error)
-	SubmitExpenseForApproval(expenseID int64, userID int64, userPermissions []string) (*Expense, error)
-	ApproveExpense(expenseID int64, managerID int64, userPermissions []string) error
-	RejectExpense(expenseID int64, managerID int64, reason string, userPermissions []string) error
-	RetryPayment(expenseID int64, userPermissions []string) error
+// CommandServiceAPI is the part of CommandService the handler uses.
+type CommandServiceAPI interface {
+	CreateExpense(ctx context.Context, req *CreateExpenseDTO, userID int64) (*Expense, error)
+	ApproveExpense(ctx context.Context, expenseID int64, managerID int64, userPermissions []string) error
+	ApproveExpenseAdjusted(ctx context.Context, expenseID, managerID, approvedAmountIDR int64, reason string, userPermissions []string) error
+	RejectExpense(ctx context.Context, expenseID int64, managerID int64, code, reason string, userPermissions []string) error
+	RejectWithCannedResponse(ctx context.Context, expenseID, managerID, responseID int64, code, note string, userPermissions []string) error
+}
+
+// QueryServiceAPI is the part of QueryService the handler uses.
+type QueryServiceAPI interface {
+	GetExpenseByID(ctx context.Context, expenseID int64, userID int64, userPermissions []string) (*Expense, error)
+	GetExpensesForUser(ctx context.Context, userID int64, userPermissions []string, params *ExpenseQueryParams) ([]*Expense, error)
+	GetExpensesCountForUser(ctx context.Context, userID int64, userPermissions []string, params *ExpenseQueryParams) (int64, error)
 }
 
 type Handler struct {
 	*transport.BaseHandler
-	Service ServiceAPI
+	Commands CommandServiceAPI
+	Queries  QueryServiceAPI
 }
 
-func NewHandler(service ServiceAPI) *Handler {
-	lg := logger.LoggerWrapper()
-	if lg == nil {
-		lg = slog.Default()
-	}
+func NewHandler(commands CommandServiceAPI, queries QueryServiceAPI) *Handler {
 	return &Handler{
-		BaseHandler: transport.NewBaseHandler(lg),
-		Service:     service,
+		BaseHandler: transport.NewBaseHandler(logger.LoggerWrapper()),
+		Commands:    commands,
+		Queries:     queries,
 	}
 }
 
+// CreateExpense godoc
+// @Summary      Create expense
+// @Description  Expenses below the approval threshold are auto-approved and paid asynchronously. Expenses that would take the user past a daily or monthly spending limit fail with LIMIT_EXCEEDED, expenses above the tenant's receipt threshold without a receipt_url fail with RECEIPT_REQUIRED, and expenses dated in a locked month fail with PERIOD_LOCKED.
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      CreateExpenseDTO  true  "Expense"
+// @Success      201   {object}  Expense
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /expenses [post]
 func (h *Handler) CreateExpense(w http.ResponseWriter, r *http.Request) {
 	user, ok := internal.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("CreateExpense: user not found in context")
-		h.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		h.Log(r).Error("CreateExpense: user not found in context")
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	var dto CreateExpenseDTO
-	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
-		h.Logger.Error("CreateExpense: invalid request body", "error", err)
-		h.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if !h.DecodeJSON(w, r, &dto) {
 		return
 	}
 
-	expense, err := h.Service.CreateExpense(&dto, user.ID)
+	expense, err := h.Commands.CreateExpense(r.Context(), &dto, user.ID)
 	if err != nil {
-		h.Logger.Error("CreateExpense: service error", "error", err, "user_id", user.ID)
-		h.HandleServiceError(w, err)
+		h.Log(r).Error("CreateExpense: service error", "error", err, "user_id", user.ID)
+		h.HandleServiceError(w, r, err)
 		return
 	}
 
-	h.Logger.Info("CreateExpense: expense created successfully",
+	h.Log(r).Info("CreateExpense: expense created successfully",
 		"expense_id", expense.ID,
 		"user_id", user.ID,
 		"amount", expense.AmountIDR,
 		"status", expense.ExpenseStatus)
 
-	h.WriteJSON(w, http.StatusCreated, expense)
+	h.WriteJSON(w, http.StatusCreated, ExpenseResponse(transport.APIVersionFromContext(r.Context()), expense))
 }
 
+// GetExpense godoc
+// @Summary      Get expense
+// @Description  Visible to the owner, team managers and users who can view all expenses.
+// @Tags         expenses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Expense ID"
+// @Success      200  {object}  Expense
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.AppErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /expenses/{id} [get]
 func (h *Handler) GetExpense(w http.ResponseWriter, r *http.Request) {
 	user, ok := internal.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("GetExpense: user not found in context")
-		h.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		h.Log(r).Error("GetExpense: user not found in context")
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	expenseIDStr := chi.URLParam(r, "id")
 	expenseID, err := strconv.ParseInt(expenseIDStr, 10, 64)
 	if err != nil {
-		h.Logger.Error("GetExpense: invalid expense ID", "id", expenseIDStr)
-		h.WriteError(w, http.StatusBadRequest, "invalid expense ID")
+		h.Log(r).Error("GetExpense: invalid expense ID", "id", expenseIDStr)
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
 		return
 	}
 
-	expense, err := h.Service.GetExpenseByID(expenseID, user.ID, user.Permissions)
+	expense, err := h.Queries.GetExpenseByID(r.Context(), expenseID, user.ID, user.Permissions)
 	if err != nil {
-		h.Logger.Error("GetExpense: service error", "error", err, "expense_id", expenseID, "user_id", user.ID)
-		h.HandleServiceError(w, err)
+		h.Log(r).Error("GetExpense: service error", "error", err, "expense_id", expenseID, "user_id", user.ID)
+		h.HandleServiceError(w, r, err)
 		return
 	}
 
-	h.WriteJSON(w, http.StatusOK, expense)
+	h.WriteJSON(w, http.StatusOK, ExpenseResponse(transport.APIVersionFromContext(r.Context()), expense))
 }
 
+// GetAllExpenses godoc
+// @Summary      List expenses
+// @Description  Returns the expenses visible to the caller: own, team or all depending on permissions.
+// @Tags         expenses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page         query     int     false  "Page number"
+// @Param        per_page     query     int     false  "Page size"
+// @Param        search       query     string  false  "Full-text search over description and category; supports quoted phrases and -exclusions"
+// @Param        category_id  query     string  false  "Category"
+// @Param        status       query     string  false  "Expense status"
+// @Param        date_from    query     string  false  "Earliest expense date, YYYY-MM-DD"
+// @Param        date_to      query     string  false  "Latest expense date, YYYY-MM-DD (inclusive)"
+// @Param        min_amount   query     int     false  "Minimum amount in IDR"
+// @Param        max_amount   query     int     false  "Maximum amount in IDR"
+// @Param        sort_by      query     string  false  "createdAt, submittedAt, amount or relevance (default when searching)"
+// @Param        sort_order   query     string  false  "asc or desc"
+// @Param        include      query     string  false  "payment adds each expense's latest payment (status, retry_count, processed_at)"
+// @Success      200          {object}  ExpenseListV1
+// @Failure      401          {object}  transport.ErrorResponse
+// @Router       /expenses [get]
 func (h *Handler) GetAllExpenses(w http.ResponseWriter, r *http.Request) {
 	user, ok := internal.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("GetAllExpenses: user not found in context")
-		h.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		h.Log(r).Error("GetAllExpenses: user not found in context")
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	params := &ExpenseQueryParams{}
 	params.ParseFromRequest(r)
 
-	expenses, err := h.Service.GetExpensesForUser(user.ID, user.Permissions, params)
+	expenses, err := h.Queries.GetExpensesForUser(r.Context(), user.ID, user.Permissions, params)
 	if err != nil {
-		h.Logger.Error("GetAllExpenses: service error", "error", err, "user_id", user.ID)
-		h.WriteError(w, http.StatusInternalServerError, "failed to retrieve expenses")
+		h.Log(r).Error("GetAllExpenses: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to retrieve expenses")
 		return
 	}
 
-	totalCount, err := h.Service.GetExpensesCountForUser(user.ID, user.Permissions, params)
+	totalCount, err := h.Queries.GetExpensesCountForUser(r.Context(), user.ID, user.Permissions, params)
 	if err != nil {
-		h.Logger.Error("GetAllExpenses: failed to get count", "error", err, "user_id", user.ID)
-		h.WriteError(w, http.StatusInternalServerError, "failed to retrieve expenses count")
+		h.Log(r).Error("GetAllExpenses: failed to get count", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to retrieve expenses count")
 		return
 	}
 
-	h.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"expenses":   expenses,
-		"per_page":   params.PerPage,
-		"page":       params.Page,
-		"total_data": totalCount,
-		"search":     params.Search,
-		"status":     params.Status,
-		"sort_by":    params.SortBy,
-		"sort_order": params.SortOrder,
-	})
+	h.WriteJSON(w, http.StatusOK, ExpenseListResponse(transport.APIVersionFromContext(r.Context()), &ExpenseList{
+		Expenses:   expenses,
+		Params:     params,
+		TotalCount: totalCount,
+	}))
 }
 
+// ApproveExpense godoc
+// @Summary      Approve expense
+// @Description  The body is optional. With approved_amount_idr the manager approves less than was claimed: a reason is required, the expense is paid the reduced amount, and the submitter sees both amounts and the reason on the expense as amount_idr, approved_amount_idr and adjustment_reason. approved_amount_idr may not exceed amount_idr. Fails with LIMIT_EXCEEDED when approval would take the owner's approved spend past a daily or monthly limit, and with RECEIPT_REQUIRED when the expense is above the tenant's receipt threshold without a receipt.
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                true   "Expense ID"
+// @Param        body  body      ApproveExpenseDTO  false  "Reduced amount"
+// @Success      200   {object}  map[string]string
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.ErrorResponse
+// @Router       /expenses/{id}/approve [patch]
 func (h *Handler) ApproveExpense(w http.ResponseWriter, r *http.Request) {
 	user, ok := internal.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("ApproveExpense: user not found in context")
-		h.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		h.Log(r).Error("ApproveExpense: user not found in context")
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	expenseIDStr := chi.URLParam(r, "id")
 	expenseID, err := strconv.ParseInt(expenseIDStr, 10, 64)
 	if err != nil {
-		h.Logger.Error("ApproveExpense: invalid expense ID", "id", expenseIDStr)
-		h.WriteError(w, http.StatusBadRequest, "invalid expense ID")
+		h.Log(r).Error("ApproveExpense: invalid expense ID", "id", expenseIDStr)
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
 		return
 	}
 
-	if err := h.Service.ApproveExpense(expenseID, user.ID, user.Permissions); err != nil {
-		h.Logger.Error("ApproveExpense: service error", "error", err, "expense_id", expenseID, "manager_id", user.ID)
+	var dto ApproveExpenseDTO
+	if r.ContentLength != 0 && !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+	if err := dto.Validate(); err != nil {
+		h.Log(r).Error("ApproveExpense: validation error", "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	if dto.ApprovedAmountIDR != nil {
+		err = h.Commands.ApproveExpenseAdjusted(r.Context(), expenseID, user.ID, *dto.ApprovedAmountIDR, dto.Reason, user.Permissions)
+	} else {
+		err = h.Commands.ApproveExpense(r.Context(), expenseID, user.ID, user.Permissions)
+	}
+	if err != nil {
+		h.Log(r).Error("ApproveExpense: service error", "error", err, "expense_id", expenseID, "manager_id", user.ID)
 
 		switch err {
 		case ErrExpenseNotFound:
-			h.WriteError(w, http.StatusNotFound, "expense not found")
+			h.WriteError(w, r, http.StatusNotFound, "expense not found")
 		case ErrInvalidExpenseStatus:
-			h.WriteError(w, http.StatusBadRequest, "expense cannot be approved in current status")
+			h.WriteError(w, r, http.StatusBadRequest, "expense cannot be approved in current status")
 		case ErrUnauthorizedAccess:
-			h.WriteError(w, http.StatusForbidden, "manager access required")
+			h.WriteError(w, r, http.StatusForbidden, "manager access required")
 		default:
-			h.WriteError(w, http.StatusInternalServerError, "failed to approve expense")
+			if _, ok := internal.IsAppError(err); ok {
+				h.HandleError(w, r, err)
+				return
+			}
+			h.WriteError(w, r, http.StatusInternalServerError, "failed to approve expense")
 		}
 		return
 	}
 
-	h.Logger.Info("ApproveExpense: expense approved successfully", "expense_id", expenseID, "manager_id", user.ID)
+	h.Log(r).Info("ApproveExpense: expense approved successfully", "expense_id", expenseID, "manager_id", user.ID)
 	h.WriteJSON(w, http.StatusOK, map[string]string{"status": "approved"})
 }
 
+// RejectExpense godoc
+// @Summary      Reject expense
+// @Description  A reason code (policy_violation, missing_receipt, duplicate or other) and a comment are required; both are stored on the expense and returned as rejection_code and rejection_reason. Alternatively canned_response_id takes both from one of the tenant's canned responses, with reason added below its comment when given; the ID is returned as canned_response_id.
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int               true  "Expense ID"
+// @Param        body  body      RejectExpenseDTO  true  "Rejection reason"
+// @Success      200   {object}  map[string]string
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /expenses/{id}/reject [patch]
 func (h *Handler) RejectExpense(w http.ResponseWriter, r *http.Request) {
 	user, ok := internal.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("RejectExpense: user not found in context")
-		h.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		h.Log(r).Error("RejectExpense: user not found in context")
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	expenseIDStr := chi.URLParam(r, "id")
 	expenseID, err := strconv.ParseInt(expenseIDStr, 10, 64)
 	if err != nil {
-		h.Logger.Error("RejectExpense: invalid expense ID", "id", expenseIDStr)
-		h.WriteError(w, http.StatusBadRequest, "invalid expense ID")
+		h.Log(r).Error("RejectExpense: invalid expense ID", "id", expenseIDStr)
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
 		return
 	}
 
 	var dto RejectExpenseDTO
-	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
-		h.Logger.Error("RejectExpense: invalid request body", "error", err)
-		h.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if !h.DecodeJSON(w, r, &dto) {
 		return
 	}
 
 	if err := dto.Validate(); err != nil {
-		h.Logger.Error("RejectExpense: validation error", "error", err)
-		h.WriteError(w, http.StatusBadRequest, err.Error())
+		h.Log(r).Error("RejectExpense: validation error", "error", err)
+		h.HandleError(w, r, err)
 		return
 	}
 
-	if err := h.Service.RejectExpense(expenseID, user.ID, dto.Reason, user.Permissions); err != nil {
-		h.Logger.Error("RejectExpense: service error", "error", err, "expense_id", expenseID, "manager_id", user.ID)
+	if dto.CannedResponseID != nil {
+		err = h.Commands.RejectWithCannedResponse(r.Context(), expenseID, user.ID, *dto.CannedResponseID, dto.Code, dto.Reason, user.Permissions)
+	} else {
+		err = h.Commands.RejectExpense(r.Context(), expenseID, user.ID, dto.Code, dto.Reason, user.Permissions)
+	}
+	if err != nil {
+		h.Log(r).Error("RejectExpense: service error", "error", err, "expense_id", expenseID, "manager_id", user.ID)
 
 		switch err {
 		case ErrExpenseNotFound:
-			h.WriteError(w, http.StatusNotFound, "expense not found")
+			h.WriteError(w, r, http.StatusNotFound, "expense not found")
 		case ErrInvalidExpenseStatus:
-			h.WriteError(w, http.StatusBadRequest, "expense cannot be rejected in current status")
+			h.WriteError(w, r, http.StatusBadRequest, "expense cannot be rejected in current status")
 		case ErrUnauthorizedAccess:
-			h.WriteError(w, http.StatusForbidden, "manager access required")
+			h.WriteError(w, r, http.StatusForbidden, "manager access required")
 		default:
-			h.WriteError(w, http.StatusInternalServerError, "failed to reject expense")
+			if _, ok := internal.IsAppError(err); ok {
+				h.HandleError(w, r, err)
+				return
+			}
+			h.WriteError(w, r, http.StatusInternalServerError, "failed to reject expense")
 		}
 		return
 	}
 
-	h.Logger.Info("RejectExpense: expense rejected successfully",
+	h.Log(r).Info("RejectExpense: expense rejected successfully",
 		"expense_id", expenseID,
 		"manager_id", user.ID,
-		"reason", dto.Reason)
+		"code", dto.Code)
 
 	h.WriteJSON(w, http.StatusOK, map[string]string{"status": "rejected"})
 }
@@ -0,0 +1,77 @@
+package expense_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/go-chi/chi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		mockRepo *mockExpenseRepository
+		handler  *expense.Handler
+	)
+
+	BeforeEach(func() {
+		mockRepo = newMockExpenseRepository()
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		permissionChecker := auth.NewPermissionChecker()
+		commands := expense.NewCommandService(mockRepo, newMockPaymentProcessor(), mockCategoryValidator{"food": true}, mockApprovalRouter{}, &mockSpendingLimiter{}, nil, nil, nil, nil, nil, nil, permissionChecker, expense.DefaultStateMachine(), events.NewEventBus(logger), logger)
+		handler = expense.NewHandler(commands, expense.NewQueryService(mockRepo, nil, permissionChecker, logger))
+
+		delegate := int64(456)
+		mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+			ID:            1,
+			UserID:        123,
+			SubmittedBy:   &delegate,
+			AmountIDR:     75000,
+			Category:      "food",
+			ExpenseStatus: expense.ExpenseStatusPendingApproval,
+		})
+	})
+
+	approve := func(userID int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/expenses/1/approve", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "1")
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = internal.ContextWithUser(ctx, &internal.User{ID: userID, Permissions: []string{"approve_expenses"}})
+		recorder := httptest.NewRecorder()
+		handler.ApproveExpense(recorder, req.WithContext(ctx))
+		return recorder
+	}
+
+	expectOwnExpenseDecision := func(recorder *httptest.ResponseRecorder) {
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		var response transport.AppErrorResponse
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Error.Code).To(Equal(internal.ErrCodeOwnExpenseDecision))
+	}
+
+	Describe("ApproveExpense", func() {
+		It("should refuse the owner with 403", func() {
+			expectOwnExpenseDecision(approve(123))
+		})
+
+		It("should refuse the delegate who filed it with 403", func() {
+			expectOwnExpenseDecision(approve(456))
+		})
+
+		It("should approve for another manager", func() {
+			Expect(approve(789).Code).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"time"
 
 	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
@@ -16,13 +17,13 @@ func NewExpenseRepository(db *gorm.DB) expense.RepositoryAPI {
 	return &ExpenseRepository{db: db}
 }
 
-func (r *ExpenseRepository) Create(exp *expenseDatamodel.Expense) error {
-	return r.db.Create(exp).Error
+func (r *ExpenseRepository) Create(ctx context.Context, exp *expenseDatamodel.Expense) error {
+	return r.db.WithContext(ctx).Create(exp).Error
 }
 
-func (r *ExpenseRepository) GetByID(id int64) (*expenseDatamodel.Expense, error) {
+func (r *ExpenseRepository) GetByID(ctx context.Context, id int64) (*expenseDatamodel.Expense, error) {
 	var exp expenseDatamodel.Expense
-	err := r.db.Where("id = ?", id).First(&exp).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&exp).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, expense.ErrExpenseNotFound
@@ -32,127 +33,131 @@ func (r *ExpenseRepository) GetByID(id int64) (*expenseDatamodel.Expense, error)
 	return &exp, nil
 }
 
-func (r *ExpenseRepository) GetByUserID(userID int64, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
-	var expenses []*expenseDatamodel.Expense
-	query := r.db.Model(&expenseDatamodel.Expense{}).Where("user_id = ?", userID)
-
-	query = r.applyQueryFilters(query, params)
-
-	err := query.Find(&expenses).Error
-	return expenses, err
+func (r *ExpenseRepository) GetByUserID(ctx context.Context, userID int64, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).Where("user_id = ?", userID)
+	return newExpenseQuery(params).Find(query)
 }
 
-func (r *ExpenseRepository) GetAllExpenses(params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
-	var expenses []*expenseDatamodel.Expense
-	query := r.db.Model(&expenseDatamodel.Expense{})
-
-	query = r.applyQueryFilters(query, params)
-
-	err := query.Find(&expenses).Error
-	return expenses, err
+func (r *ExpenseRepository) GetAllExpenses(ctx context.Context, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{})
+	return newExpenseQuery(params).Find(query)
 }
 
-func (r *ExpenseRepository) applyQueryFilters(query *gorm.DB, params *expense.ExpenseQueryParams) *gorm.DB {
-
-	if params.Search != "" {
-		searchPattern := "%" + params.Search + "%"
-		query = query.Where("description ILIKE ? OR category ILIKE ?", searchPattern, searchPattern)
-	}
-
-	if params.CategoryID != "" {
-		query = query.Where("category = ?", params.CategoryID)
-	}
+// TeamMembersSQL selects the users of the manager's tenant in the manager's
+// department and every department below it. UNION (not UNION ALL) keeps it
+// terminating on cycles.
+const TeamMembersSQL = `SELECT u.id FROM users u
+JOIN users m ON m.id = ? AND m.tenant_id = u.tenant_id
+WHERE u.department = m.department
+OR u.department IN (
+	WITH RECURSIVE team(id, name) AS (
+		SELECT d.id, d.name FROM departments d JOIN users dm ON dm.department = d.name WHERE dm.id = ?
+		UNION
+		SELECT d.id, d.name FROM departments d JOIN team t ON d.parent_id = t.id
+	)
+	SELECT name FROM team
+)`
+
+func (r *ExpenseRepository) teamScope(query *gorm.DB, managerID int64) *gorm.DB {
+	return query.Where("(user_id = ? OR user_id IN ("+TeamMembersSQL+"))", managerID, managerID, managerID)
+}
 
-	if params.Status != "" {
-		query = query.Where("expense_status = ?", params.Status)
-	}
+func (r *ExpenseRepository) GetByTeam(ctx context.Context, managerID int64, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
+	query := r.teamScope(r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}), managerID)
+	return newExpenseQuery(params).Find(query)
+}
 
-	orderClause := "created_at DESC"
-	switch params.SortBy {
-	case "createdAt":
-		orderClause = "created_at"
-		if params.SortOrder == "desc" {
-			orderClause += " DESC"
-		} else {
-			orderClause += " ASC"
-		}
-	case "submittedAt":
-		orderClause = "submitted_at"
-		if params.SortOrder == "desc" {
-			orderClause += " DESC"
-		} else {
-			orderClause += " ASC"
-		}
-	case "amount":
-		orderClause = "amount_idr"
-		if params.SortOrder == "desc" {
-			orderClause += " DESC"
-		} else {
-			orderClause += " ASC"
-		}
-	}
+func (r *ExpenseRepository) CountByTeam(ctx context.Context, managerID int64, params *expense.ExpenseQueryParams) (int64, error) {
+	var count int64
+	query := r.teamScope(r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}), managerID)
 
-	offset := params.GetOffset()
+	query = newExpenseQuery(params).Filter(query)
 
-	return query.Order(orderClause).
-		Limit(params.PerPage).
-		Offset(offset)
+	err := query.Count(&count).Error
+	return count, err
 }
 
-func (r *ExpenseRepository) applyQueryFiltersForCount(query *gorm.DB, params *expense.ExpenseQueryParams) *gorm.DB {
-
-	if params.Search != "" {
-		searchPattern := "%" + params.Search + "%"
-		query = query.Where("description ILIKE ? OR category ILIKE ?", searchPattern, searchPattern)
-	}
-
-	if params.CategoryID != "" {
-		query = query.Where("category = ?", params.CategoryID)
-	}
+func (r *ExpenseRepository) IsTeamMember(ctx context.Context, managerID, userID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+TeamMembersSQL+") team_members WHERE team_members.id = ?", managerID, managerID, userID).
+		Scan(&count).Error
+	return count > 0, err
+}
 
-	if params.Status != "" {
-		query = query.Where("expense_status = ?", params.Status)
+// UserDepartment returns the user's department, or "" when they have none.
+func (r *ExpenseRepository) UserDepartment(ctx context.Context, userID int64) (string, error) {
+	var department *string
+	err := r.db.WithContext(ctx).Raw("SELECT department FROM users WHERE id = ?", userID).Scan(&department).Error
+	if err != nil || department == nil {
+		return "", err
 	}
-
-	return query
+	return *department, nil
 }
 
-func (r *ExpenseRepository) CountByUserID(userID int64, params *expense.ExpenseQueryParams) (int64, error) {
+func (r *ExpenseRepository) CountByUserID(ctx context.Context, userID int64, params *expense.ExpenseQueryParams) (int64, error) {
 	var count int64
-	query := r.db.Model(&expenseDatamodel.Expense{}).Where("user_id = ?", userID)
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).Where("user_id = ?", userID)
 
-	query = r.applyQueryFiltersForCount(query, params)
+	query = newExpenseQuery(params).Filter(query)
 
 	err := query.Count(&count).Error
 	return count, err
 }
 
-func (r *ExpenseRepository) CountAllExpenses(params *expense.ExpenseQueryParams) (int64, error) {
+func (r *ExpenseRepository) CountAllExpenses(ctx context.Context, params *expense.ExpenseQueryParams) (int64, error) {
 	var count int64
-	query := r.db.Model(&expenseDatamodel.Expense{})
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{})
 
-	query = r.applyQueryFiltersForCount(query, params)
+	query = newExpenseQuery(params).Filter(query)
 
 	err := query.Count(&count).Error
 	return count, err
 }
 
-func (r *ExpenseRepository) Update(exp *expenseDatamodel.Expense) error {
+func (r *ExpenseRepository) Update(ctx context.Context, exp *expenseDatamodel.Expense) error {
 	exp.UpdatedAt = time.Now()
-	return r.db.Save(exp).Error
+	return r.db.WithContext(ctx).Save(exp).Error
 }
 
-func (r *ExpenseRepository) UpdateStatus(id int64, status string, processedAt time.Time) error {
-	return r.db.Model(&expenseDatamodel.Expense{}).
-		Where("id = ?", id).
+func (r *ExpenseRepository) UpdateStatus(ctx context.Context, id int64, from, to string, processedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Where("id = ? AND expense_status = ?", id, from).
 		Updates(map[string]interface{}{
-			"expense_status": status,
+			"expense_status": to,
 			"processed_at":   processedAt,
 			"updated_at":     time.Now(),
-		}).Error
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return expense.ErrInvalidExpenseStatus
+	}
+	return nil
+}
+
+func (r *ExpenseRepository) Approve(ctx context.Context, e *expenseDatamodel.Expense, from string) error {
+	result := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Where("id = ? AND expense_status = ?", e.ID, from).
+		Updates(map[string]interface{}{
+			"expense_status":      e.ExpenseStatus,
+			"approved_by":         e.ApprovedBy,
+			"approved_at":         e.ApprovedAt,
+			"approved_amount_idr": e.ApprovedAmountIDR,
+			"adjustment_reason":   e.AdjustmentReason,
+			"processed_at":        e.ProcessedAt,
+			"updated_at":          time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return expense.ErrInvalidExpenseStatus
+	}
+	return nil
 }
 
-func (r *ExpenseRepository) UpdatePaymentInfo(id int64, paymentStatus, paymentID, paymentExternalID string, paidAt *time.Time) error {
+func (r *ExpenseRepository) UpdatePaymentInfo(ctx context.Context, id int64, paymentStatus, paymentID, paymentExternalID string, paidAt *time.Time) error {
 	updates := map[string]interface{}{
 		"payment_status":      paymentStatus,
 		"payment_id":          paymentID,
@@ -164,7 +169,36 @@ func (r *ExpenseRepository) UpdatePaymentInfo(id int64, paymentStatus, paymentID
 		updates["paid_at"] = *paidAt
 	}
 
-	return r.db.Model(&expenseDatamodel.Expense{}).
+	return r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
 		Where("id = ?", id).
 		Updates(updates).Error
 }
+
+// unclaimedStatuses are expenses that will not be paid, so reusing their
+// receipt is no double claim.
+var unclaimedStatuses = []string{expense.ExpenseStatusDraft, expense.ExpenseStatusRejected, expense.ExpenseStatusCancelled}
+
+func (r *ExpenseRepository) ReceiptClaims(ctx context.Context, hashes []string) (map[string][]expense.ReceiptClaim, error) {
+	claims := make(map[string][]expense.ReceiptClaim)
+	if len(hashes) == 0 {
+		return claims, nil
+	}
+
+	var rows []struct {
+		ID            int64
+		UserID        int64
+		ReceiptSHA256 string
+	}
+	err := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Select("id", "user_id", "receipt_sha256").
+		Where("receipt_sha256 IN ? AND expense_status NOT IN ?", hashes, unclaimedStatuses).
+		Order("id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		claims[row.ReceiptSHA256] = append(claims[row.ReceiptSHA256], expense.ReceiptClaim{ExpenseID: row.ID, UserID: row.UserID})
+	}
+	return claims, nil
+}
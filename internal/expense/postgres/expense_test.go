@@ -1,14 +1,17 @@
 package postgres
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
 	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/tenant"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -17,42 +20,21 @@ func TestExpenseRepository(t *testing.T) {
 	RunSpecs(t, "ExpenseRepository Suite")
 }
 
-type SQLiteExpense struct {
-	ID              int64      `gorm:"primaryKey"`
-	UserID          int64      `gorm:"column:user_id;not null"`
-	AmountIDR       int64      `gorm:"column:amount_idr;not null"`
-	Description     string     `gorm:"not null"`
-	Category        string     `gorm:"column:category"`
-	ReceiptURL      *string    `gorm:"column:receipt_url"`
-	ReceiptFileName *string    `gorm:"column:receipt_filename"`
-	ExpenseStatus   string     `gorm:"column:expense_status;default:'pending_approval'"`
-	ExpenseDate     time.Time  `gorm:"column:expense_date"`
-	SubmittedAt     time.Time  `gorm:"column:submitted_at"`
-	ProcessedAt     *time.Time `gorm:"column:processed_at"`
-	CreatedAt       time.Time  `gorm:"column:created_at"`
-	UpdatedAt       time.Time  `gorm:"column:updated_at"`
-}
-
-func (SQLiteExpense) TableName() string {
-	return "expenses"
-}
-
 var _ = Describe("ExpenseRepository", func() {
 	var (
 		db   *gorm.DB
 		repo expense.RepositoryAPI
+		ctx  context.Context
 	)
 
 	BeforeEach(func() {
 		var err error
 
-		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-		Expect(err).NotTo(HaveOccurred())
-
-		err = db.AutoMigrate(&SQLiteExpense{})
+		db, err = testdb.Open(&expenseDatamodel.Expense{}, &userDatamodel.User{}, &userDatamodel.Department{})
 		Expect(err).NotTo(HaveOccurred())
 
 		repo = NewExpenseRepository(db)
+		ctx = context.Background()
 	})
 
 	AfterEach(func() {
@@ -77,7 +59,7 @@ var _ = Describe("ExpenseRepository", func() {
 				UpdatedAt:     time.Now(),
 			}
 
-			err := repo.Create(expense)
+			err := repo.Create(ctx, expense)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(expense.ID).To(BeNumerically(">", 0))
 		})
@@ -96,12 +78,12 @@ var _ = Describe("ExpenseRepository", func() {
 				ExpenseDate:   time.Now(),
 				SubmittedAt:   time.Now(),
 			}
-			err := repo.Create(createdExpense)
+			err := repo.Create(ctx, createdExpense)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should retrieve expense by ID successfully", func() {
-			retrieved, err := repo.GetByID(createdExpense.ID)
+			retrieved, err := repo.GetByID(ctx, createdExpense.ID)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(retrieved).NotTo(BeNil())
 			Expect(retrieved.ID).To(Equal(createdExpense.ID))
@@ -113,7 +95,7 @@ var _ = Describe("ExpenseRepository", func() {
 		})
 
 		It("should return ErrExpenseNotFound for non-existent ID", func() {
-			retrieved, err := repo.GetByID(99999)
+			retrieved, err := repo.GetByID(ctx, 99999)
 			Expect(err).To(Equal(expense.ErrExpenseNotFound))
 			Expect(retrieved).To(BeNil())
 		})
@@ -132,7 +114,7 @@ var _ = Describe("ExpenseRepository", func() {
 				ExpenseDate:   time.Now(),
 				SubmittedAt:   time.Now(),
 			}
-			err := repo.Create(createdExpense)
+			err := repo.Create(ctx, createdExpense)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -142,10 +124,10 @@ var _ = Describe("ExpenseRepository", func() {
 			createdExpense.AmountIDR = 200000
 			createdExpense.Category = "Food"
 
-			err := repo.Update(createdExpense)
+			err := repo.Update(ctx, createdExpense)
 			Expect(err).NotTo(HaveOccurred())
 
-			retrieved, err := repo.GetByID(createdExpense.ID)
+			retrieved, err := repo.GetByID(ctx, createdExpense.ID)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(retrieved.Description).To(Equal("Updated description"))
 			Expect(retrieved.AmountIDR).To(Equal(int64(200000)))
@@ -166,21 +148,156 @@ var _ = Describe("ExpenseRepository", func() {
 				ExpenseDate:   time.Now(),
 				SubmittedAt:   time.Now(),
 			}
-			err := repo.Create(createdExpense)
+			err := repo.Create(ctx, createdExpense)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should update status and processed_at successfully", func() {
 			processedAt := time.Now()
 
-			err := repo.UpdateStatus(createdExpense.ID, "approved", processedAt)
+			err := repo.UpdateStatus(ctx, createdExpense.ID, "pending_approval", "approved", processedAt)
 			Expect(err).NotTo(HaveOccurred())
 
-			retrieved, err := repo.GetByID(createdExpense.ID)
+			retrieved, err := repo.GetByID(ctx, createdExpense.ID)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(retrieved.ExpenseStatus).To(Equal("approved"))
 			Expect(retrieved.ProcessedAt).NotTo(BeNil())
 			Expect(retrieved.ProcessedAt.Unix()).To(Equal(processedAt.Unix()))
 		})
+
+		It("should refuse the update when the status has moved on", func() {
+			err := repo.UpdateStatus(ctx, createdExpense.ID, "approved", "completed", time.Now())
+			Expect(err).To(MatchError(expense.ErrInvalidExpenseStatus))
+
+			retrieved, err := repo.GetByID(ctx, createdExpense.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.ExpenseStatus).To(Equal("pending_approval"))
+		})
+	})
+
+	Describe("Team scope", func() {
+		BeforeEach(func() {
+			engineering := userDatamodel.Department{ID: 1, Name: "engineering"}
+			platform := userDatamodel.Department{ID: 2, Name: "platform", ParentID: &engineering.ID}
+			finance := userDatamodel.Department{ID: 3, Name: "finance"}
+			Expect(db.Create([]*userDatamodel.Department{&engineering, &platform, &finance}).Error).NotTo(HaveOccurred())
+
+			users := []*userDatamodel.User{
+				{ID: 1, Email: "manager@mail.com", Department: "engineering"},
+				{ID: 2, Email: "dev@mail.com", Department: "engineering"},
+				{ID: 3, Email: "sre@mail.com", Department: "platform"},
+				{ID: 4, Email: "accountant@mail.com", Department: "finance"},
+			}
+			Expect(db.Create(users).Error).NotTo(HaveOccurred())
+
+			for _, userID := range []int64{1, 2, 3, 4} {
+				Expect(repo.Create(ctx, &expenseDatamodel.Expense{
+					UserID:        userID,
+					AmountIDR:     100000,
+					Description:   "Team expense",
+					Category:      "makan",
+					ExpenseStatus: "pending_approval",
+					ExpenseDate:   time.Now(),
+					SubmittedAt:   time.Now(),
+				})).To(Succeed())
+			}
+		})
+
+		It("should return expenses of the manager's department and sub-departments", func() {
+			params := &expense.ExpenseQueryParams{Page: 1, PerPage: 10}
+
+			expenses, err := repo.GetByTeam(ctx, 1, params)
+			Expect(err).NotTo(HaveOccurred())
+
+			userIDs := []int64{}
+			for _, e := range expenses {
+				userIDs = append(userIDs, e.UserID)
+			}
+			Expect(userIDs).To(ConsistOf(int64(1), int64(2), int64(3)))
+
+			count, err := repo.CountByTeam(ctx, 1, params)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(3)))
+		})
+
+		It("should not include parent departments for sub-department managers", func() {
+			count, err := repo.CountByTeam(ctx, 3, &expense.ExpenseQueryParams{Page: 1, PerPage: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(1)))
+		})
+
+		It("should report team membership", func() {
+			member, err := repo.IsTeamMember(ctx, 1, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(member).To(BeTrue())
+
+			member, err = repo.IsTeamMember(ctx, 1, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(member).To(BeFalse())
+		})
+	})
+
+	Describe("UserDepartment", func() {
+		It("should return the user's department, or empty when unknown", func() {
+			Expect(db.Create(&userDatamodel.User{ID: 1, Email: "a@example.com", Department: "sales"}).Error).To(Succeed())
+
+			department, err := repo.UserDepartment(ctx, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(department).To(Equal("sales"))
+
+			department, err = repo.UserDepartment(ctx, 99)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(department).To(BeEmpty())
+		})
+	})
+
+	Describe("Tenants", func() {
+		var acme, globex context.Context
+
+		BeforeEach(func() {
+			acme = tenant.NewContext(ctx, 2)
+			globex = tenant.NewContext(ctx, 3)
+
+			Expect(db.Create([]*userDatamodel.User{
+				{ID: 1, TenantID: 2, Email: "manager@acme.com", Department: "engineering"},
+				{ID: 2, TenantID: 2, Email: "dev@acme.com", Department: "engineering"},
+				{ID: 3, TenantID: 3, Email: "dev@globex.com", Department: "engineering"},
+			}).Error).NotTo(HaveOccurred())
+		})
+
+		newExpense := func(userID int64) *expenseDatamodel.Expense {
+			return &expenseDatamodel.Expense{
+				UserID:        userID,
+				AmountIDR:     100000,
+				Description:   "Tenant expense",
+				Category:      "makan",
+				ExpenseStatus: "pending_approval",
+				ExpenseDate:   time.Now(),
+				SubmittedAt:   time.Now(),
+			}
+		}
+
+		It("should hide other tenants' expenses", func() {
+			own := newExpense(2)
+			Expect(repo.Create(acme, own)).To(Succeed())
+			Expect(own.TenantID).To(Equal(int64(2)))
+
+			_, err := repo.GetByID(globex, own.ID)
+			Expect(err).To(Equal(expense.ErrExpenseNotFound))
+
+			count, err := repo.CountAllExpenses(globex, &expense.ExpenseQueryParams{Page: 1, PerPage: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(BeZero())
+		})
+
+		It("should keep same-named departments of other tenants out of the team", func() {
+			member, err := repo.IsTeamMember(acme, 1, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(member).To(BeTrue())
+
+			member, err = repo.IsTeamMember(acme, 1, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(member).To(BeFalse())
+		})
 	})
 })
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// expenseQuery turns ExpenseQueryParams into SQL. List and count queries
+// share Filter so their WHERE clauses cannot drift apart; only lists are
+// ordered and paged.
+type expenseQuery struct {
+	params *expense.ExpenseQueryParams
+}
+
+func newExpenseQuery(params *expense.ExpenseQueryParams) expenseQuery {
+	return expenseQuery{params: params}
+}
+
+// Filter adds the WHERE conditions for the search, category, status, date
+// and amount filters.
+func (q expenseQuery) Filter(query *gorm.DB) *gorm.DB {
+	p := q.params
+
+	if !p.SearchQuery.IsEmpty() {
+		query = query.Where("search_vector @@ to_tsquery('simple', ?)", p.SearchQuery.TSQuery())
+	}
+
+	if p.CategoryID != "" {
+		query = query.Where("category = ?", p.CategoryID)
+	}
+
+	if p.Status != "" {
+		query = query.Where("expense_status = ?", p.Status)
+	}
+
+	if !p.DateFrom.IsZero() {
+		query = query.Where("expense_date >= ?", p.DateFrom)
+	}
+
+	if !p.DateTo.IsZero() {
+		// DateTo is a whole day, so everything before the next midnight.
+		query = query.Where("expense_date < ?", p.DateTo.AddDate(0, 0, 1))
+	}
+
+	if p.MinAmount > 0 {
+		query = query.Where("amount_idr >= ?", p.MinAmount)
+	}
+
+	if p.MaxAmount > 0 {
+		query = query.Where("amount_idr <= ?", p.MaxAmount)
+	}
+
+	return query
+}
+
+// Page applies Filter followed by the requested ordering, limit and offset.
+func (q expenseQuery) Page(query *gorm.DB) *gorm.DB {
+	p := q.params
+	query = q.Filter(query)
+
+	orderClause := "created_at DESC"
+	switch p.SortBy {
+	case "createdAt":
+		orderClause = "created_at" + sortDirection(p.SortOrder)
+	case "submittedAt":
+		orderClause = "submitted_at" + sortDirection(p.SortOrder)
+	case "amount":
+		orderClause = "amount_idr" + sortDirection(p.SortOrder)
+	case expense.SortByRelevance:
+		if !p.SearchQuery.IsEmpty() {
+			query = query.Order(clause.Expr{
+				SQL:  "ts_rank(search_vector, to_tsquery('simple', ?)) DESC",
+				Vars: []interface{}{p.SearchQuery.TSQuery()},
+			})
+		}
+	}
+
+	return query.Order(orderClause).
+		Limit(p.PerPage).
+		Offset(p.GetOffset())
+}
+
+// latestPaymentColumns select the status, retry count and processing time
+// of each expense's latest payment. They are correlated subqueries rather
+// than a join so the unqualified columns in Filter stay unambiguous, and
+// they are evaluated only for the rows of the page, through the
+// payments.expense_id index.
+const latestPaymentColumns = `(SELECT p.status FROM payments p WHERE p.expense_id = expenses.id ORDER BY p.created_at DESC, p.id DESC LIMIT 1) AS payment_status,
+(SELECT p.retry_count FROM payments p WHERE p.expense_id = expenses.id ORDER BY p.created_at DESC, p.id DESC LIMIT 1) AS payment_retry_count,
+(SELECT p.processed_at FROM payments p WHERE p.expense_id = expenses.id ORDER BY p.created_at DESC, p.id DESC LIMIT 1) AS payment_processed_at`
+
+// expenseWithPayment is an expense row with its latest payment's columns,
+// which are null when it has none.
+type expenseWithPayment struct {
+	expenseDatamodel.Expense
+	PaymentStatus      *string    `gorm:"column:payment_status"`
+	PaymentRetryCount  *int       `gorm:"column:payment_retry_count"`
+	PaymentProcessedAt *time.Time `gorm:"column:payment_processed_at"`
+}
+
+// Find runs Page and scans the expenses, with their latest payment when
+// the params ask for it, in a single query.
+func (q expenseQuery) Find(query *gorm.DB) ([]*expenseDatamodel.Expense, error) {
+	query = q.Page(query)
+
+	var expenses []*expenseDatamodel.Expense
+	if !q.params.IncludePayment {
+		err := query.Find(&expenses).Error
+		return expenses, err
+	}
+
+	var rows []*expenseWithPayment
+	if err := query.Select("expenses.*, " + latestPaymentColumns).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	expenses = make([]*expenseDatamodel.Expense, 0, len(rows))
+	for _, row := range rows {
+		exp := row.Expense
+		if row.PaymentStatus != nil {
+			exp.Payment = &expenseDatamodel.PaymentSummary{
+				Status:      *row.PaymentStatus,
+				ProcessedAt: row.PaymentProcessedAt,
+			}
+			if row.PaymentRetryCount != nil {
+				exp.Payment.RetryCount = *row.PaymentRetryCount
+			}
+		}
+		expenses = append(expenses, &exp)
+	}
+	return expenses, nil
+}
+
+func sortDirection(order string) string {
+	if order == "desc" {
+		return " DESC"
+	}
+	return " ASC"
+}
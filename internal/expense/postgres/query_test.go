@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("expenseQuery", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		var err error
+		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// where returns the WHERE clause of stmt and its bind variables.
+	where := func(stmt *gorm.Statement) (string, []interface{}) {
+		sql := stmt.SQL.String()
+		start := strings.Index(sql, " WHERE ")
+		Expect(start).To(BeNumerically(">=", 0), sql)
+		sql = sql[start:]
+		if end := strings.Index(sql, " ORDER BY "); end >= 0 {
+			sql = sql[:end]
+		}
+		return sql, stmt.Vars
+	}
+
+	listAndCount := func(params *expense.ExpenseQueryParams) (*gorm.Statement, *gorm.Statement) {
+		q := newExpenseQuery(params)
+		var expenses []*expenseDatamodel.Expense
+		list := q.Page(db.Model(&expenseDatamodel.Expense{})).Find(&expenses).Statement
+		var count int64
+		counted := q.Filter(db.Model(&expenseDatamodel.Expense{})).Count(&count).Statement
+		return list, counted
+	}
+
+	It("should give list and count the same WHERE clause", func() {
+		params := &expense.ExpenseQueryParams{
+			Page:       2,
+			PerPage:    10,
+			Search:     "taxi -airport",
+			CategoryID: "transport",
+			Status:     expense.ExpenseStatusApproved,
+			SortBy:     "amount",
+			SortOrder:  "asc",
+			DateFrom:   time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			DateTo:     time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC),
+			MinAmount:  50000,
+			MaxAmount:  500000,
+		}
+		params.SetDefaults()
+
+		list, counted := listAndCount(params)
+		listWhere, listVars := where(list)
+		countWhere, countVars := where(counted)
+
+		Expect(listWhere).To(Equal(countWhere))
+		Expect(listVars[:len(countVars)]).To(Equal(countVars))
+		for _, column := range []string{"search_vector", "category", "expense_status", "expense_date >=", "expense_date <", "amount_idr >=", "amount_idr <="} {
+			Expect(countWhere).To(ContainSubstring(column))
+		}
+		Expect(list.SQL.String()).To(ContainSubstring("ORDER BY amount_idr ASC"))
+		Expect(counted.SQL.String()).NotTo(ContainSubstring("ORDER BY"))
+	})
+
+	It("should include the whole DateTo day", func() {
+		params := &expense.ExpenseQueryParams{DateTo: time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)}
+		params.SetDefaults()
+
+		_, counted := listAndCount(params)
+
+		Expect(counted.Vars).To(ContainElement(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("should not filter on zero values", func() {
+		params := &expense.ExpenseQueryParams{}
+		params.SetDefaults()
+
+		_, counted := listAndCount(params)
+
+		Expect(counted.SQL.String()).NotTo(ContainSubstring("WHERE"))
+	})
+})
+
+var _ = Describe("ExpenseRepository filters", func() {
+	var (
+		db   *gorm.DB
+		repo expense.RepositoryAPI
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, err = testdb.Open(&expenseDatamodel.Expense{})
+		Expect(err).NotTo(HaveOccurred())
+		repo = NewExpenseRepository(db)
+
+		for _, e := range []struct {
+			amount int64
+			day    int
+		}{{20000, 1}, {80000, 15}, {300000, 31}} {
+			Expect(repo.Create(context.Background(), &expenseDatamodel.Expense{
+				UserID:        1,
+				AmountIDR:     e.amount,
+				Description:   "Filtered expense",
+				Category:      "transport",
+				ExpenseStatus: expense.ExpenseStatusApproved,
+				ExpenseDate:   time.Date(2025, 10, e.day, 12, 0, 0, 0, time.UTC),
+				SubmittedAt:   time.Now(),
+			})).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		sqlDB, err := db.DB()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sqlDB.Close()).To(Succeed())
+	})
+
+	It("should list and count the same expenses for date and amount filters", func() {
+		params := &expense.ExpenseQueryParams{
+			DateFrom:  time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC),
+			DateTo:    time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC),
+			MaxAmount: 100000,
+		}
+		params.SetDefaults()
+
+		expenses, err := repo.GetAllExpenses(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		count, err := repo.CountAllExpenses(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(expenses).To(HaveLen(1))
+		Expect(expenses[0].AmountIDR).To(Equal(int64(80000)))
+		Expect(count).To(Equal(int64(1)))
+	})
+})
+
+var _ = Describe("ExpenseRepository payment summaries", func() {
+	var (
+		db   *gorm.DB
+		repo expense.RepositoryAPI
+		ids  []int64
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, err = testdb.Open(&expenseDatamodel.Expense{}, &paymentDatamodel.Payment{})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			sqlDB, err := db.DB()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sqlDB.Close()).To(Succeed())
+		})
+		repo = NewExpenseRepository(db)
+
+		ids = nil
+		for i := 0; i < 2; i++ {
+			exp := &expenseDatamodel.Expense{
+				UserID:        1,
+				AmountIDR:     50000,
+				Description:   "Paid expense",
+				Category:      "transport",
+				ExpenseStatus: expense.ExpenseStatusApproved,
+				ExpenseDate:   time.Now(),
+				SubmittedAt:   time.Now(),
+			}
+			Expect(repo.Create(context.Background(), exp)).To(Succeed())
+			ids = append(ids, exp.ID)
+		}
+
+		processed := time.Date(2025, 10, 2, 9, 0, 0, 0, time.UTC)
+		for _, p := range []*paymentDatamodel.Payment{
+			{ExpenseID: ids[0], ExternalID: "exp-1-a", AmountIDR: 50000, Status: "failed", RetryCount: 3, CreatedAt: time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)},
+			{ExpenseID: ids[0], ExternalID: "exp-1-b", AmountIDR: 50000, Status: "success", RetryCount: 1, ProcessedAt: &processed, CreatedAt: time.Date(2025, 10, 2, 8, 0, 0, 0, time.UTC)},
+		} {
+			Expect(db.Create(p).Error).To(Succeed())
+		}
+	})
+
+	list := func(include bool) map[int64]*expenseDatamodel.Expense {
+		params := &expense.ExpenseQueryParams{IncludePayment: include}
+		params.SetDefaults()
+		expenses, err := repo.GetAllExpenses(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		byID := map[int64]*expenseDatamodel.Expense{}
+		for _, e := range expenses {
+			byID[e.ID] = e
+		}
+		return byID
+	}
+
+	It("should add the latest payment of each expense when asked", func() {
+		expenses := list(true)
+
+		Expect(expenses).To(HaveLen(2))
+		payment := expenses[ids[0]].Payment
+		Expect(payment).NotTo(BeNil())
+		Expect(payment.Status).To(Equal("success"))
+		Expect(payment.RetryCount).To(Equal(1))
+		Expect(payment.ProcessedAt).NotTo(BeNil())
+		Expect(payment.ProcessedAt.Equal(time.Date(2025, 10, 2, 9, 0, 0, 0, time.UTC))).To(BeTrue())
+		Expect(expenses[ids[1]].Payment).To(BeNil())
+	})
+
+	It("should leave payments out otherwise", func() {
+		Expect(list(false)[ids[0]].Payment).To(BeNil())
+	})
+})
@@ -0,0 +1,218 @@
+package expense
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// QueryService reads expenses, limited to what the caller's permissions let
+// them see. Pending expenses that need a receipt before approval are flagged
+// with ReceiptMissing, and expenses whose receipt was also claimed on
+// another expense with DuplicateReceiptOf.
+type QueryService struct {
+	repo              RepositoryAPI
+	receipts          ReceiptPolicy
+	permissionChecker auth.PermissionChecker
+	logger            *slog.Logger
+}
+
+// NewQueryService takes a nil receipts when receipts are never required.
+func NewQueryService(repo RepositoryAPI, receipts ReceiptPolicy, permissionChecker auth.PermissionChecker, logger *slog.Logger) *QueryService {
+	return &QueryService{
+		repo:              repo,
+		receipts:          receipts,
+		permissionChecker: permissionChecker,
+		logger:            logger,
+	}
+}
+
+func (s *QueryService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *QueryService) GetExpenseByID(ctx context.Context, id, userID int64, userPermissions []string) (*Expense, error) {
+	expenseData, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log(ctx).Error("failed to get expense", "error", err, "expense_id", id)
+		return nil, ErrExpenseNotFound
+	}
+
+	expense := FromDataModel(expenseData)
+
+	canAccess, err := s.canViewExpense(ctx, expense, userID, userPermissions)
+	if err != nil {
+		s.log(ctx).Error("failed to check expense access", "error", err, "expense_id", id, "user_id", userID)
+		return nil, err
+	}
+	if !canAccess {
+		s.log(ctx).Warn("unauthorized access to expense", "expense_id", id, "user_id", userID, "expense_user_id", expense.UserID)
+		return nil, ErrUnauthorizedAccess
+	}
+
+	s.flag(ctx, s.viewScope(userPermissions), userID, expense)
+	return expense, nil
+}
+
+// flag sets the flags computed on read for approvers, as seen by userID
+// reading with scope.
+func (s *QueryService) flag(ctx context.Context, scope ViewScope, userID int64, expenses ...*Expense) []*Expense {
+	s.flagMissingReceipts(ctx, expenses...)
+	s.flagDuplicateReceipts(ctx, scope, userID, expenses...)
+	return expenses
+}
+
+// flagMissingReceipts sets ReceiptMissing on pending expenses above the
+// tenant's receipt threshold that have no receipt.
+func (s *QueryService) flagMissingReceipts(ctx context.Context, expenses ...*Expense) {
+	requiredAbove := receiptRequiredAbove(ctx, s.receipts)
+	for _, expense := range expenses {
+		expense.ReceiptMissing = expense.ExpenseStatus == ExpenseStatusPendingApproval && expense.NeedsReceipt(requiredAbove)
+	}
+}
+
+// flagDuplicateReceipts sets DuplicateReceiptOf on expenses whose uploaded
+// receipt was claimed on other expenses too. Only team and all scopes are
+// flagged, and only with expenses userID may view, so the flag does not
+// reveal other users' claims. The check is best effort: a failure is logged
+// and leaves the expenses unflagged.
+func (s *QueryService) flagDuplicateReceipts(ctx context.Context, scope ViewScope, userID int64, expenses ...*Expense) {
+	if scope == ViewScopeOwn {
+		return
+	}
+
+	var hashes []string
+	for _, expense := range expenses {
+		if expense.ReceiptSHA256 != nil {
+			hashes = append(hashes, *expense.ReceiptSHA256)
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+
+	claims, err := s.repo.ReceiptClaims(ctx, hashes)
+	if err != nil {
+		s.log(ctx).Error("failed to look up receipt claims", "error", err)
+		return
+	}
+	for _, expense := range expenses {
+		if expense.ReceiptSHA256 == nil {
+			continue
+		}
+		expense.DuplicateReceiptOf = nil
+		for _, claim := range claims[*expense.ReceiptSHA256] {
+			if claim.ExpenseID == expense.ID {
+				continue
+			}
+			visible, err := s.canView(ctx, scope, claim.UserID, userID)
+			if err != nil {
+				s.log(ctx).Error("failed to check receipt claim access", "error", err, "expense_id", claim.ExpenseID, "user_id", userID)
+				continue
+			}
+			if visible {
+				expense.DuplicateReceiptOf = append(expense.DuplicateReceiptOf, claim.ExpenseID)
+			}
+		}
+	}
+}
+
+func (s *QueryService) canViewExpense(ctx context.Context, expense *Expense, userID int64, userPermissions []string) (bool, error) {
+	return s.canView(ctx, s.viewScope(userPermissions), expense.UserID, userID)
+}
+
+// canView reports whether userID reading with scope may see expenses owned
+// by ownerID.
+func (s *QueryService) canView(ctx context.Context, scope ViewScope, ownerID, userID int64) (bool, error) {
+	switch scope {
+	case ViewScopeAll:
+		return true, nil
+	case ViewScopeTeam:
+		if ownerID == userID {
+			return true, nil
+		}
+		return s.repo.IsTeamMember(ctx, userID, ownerID)
+	default:
+		return ownerID == userID, nil
+	}
+}
+
+func (s *QueryService) viewScope(userPermissions []string) ViewScope {
+	switch {
+	case s.permissionChecker.CanViewAllExpenses(userPermissions):
+		return ViewScopeAll
+	case s.permissionChecker.CanViewTeamExpenses(userPermissions):
+		return ViewScopeTeam
+	default:
+		return ViewScopeOwn
+	}
+}
+
+func (s *QueryService) CheckExpenseAccess(ctx context.Context, expenseID, userID int64, userPermissions []string) error {
+	_, err := s.GetExpenseByID(ctx, expenseID, userID, userPermissions)
+	return err
+}
+
+func (s *QueryService) GetAllExpenses(ctx context.Context, params *ExpenseQueryParams) ([]*Expense, error) {
+	params.SetDefaults()
+
+	s.log(ctx).Info("GetAllExpenses: Starting with params",
+		"page", params.Page,
+		"per_page", params.PerPage,
+		"offset_calculated", params.GetOffset(),
+		"search", params.Search,
+		"category", params.CategoryID,
+		"status", params.Status)
+
+	expensesData, err := s.repo.GetAllExpenses(ctx, params)
+	if err != nil {
+		s.log(ctx).Error("failed to get all expenses", "error", err)
+		return nil, err
+	}
+
+	return s.flag(ctx, ViewScopeAll, 0, FromDataModelSlice(expensesData)...), nil
+}
+
+func (s *QueryService) GetExpensesForUser(ctx context.Context, userID int64, userPermissions []string, params *ExpenseQueryParams) ([]*Expense, error) {
+	params.SetDefaults()
+
+	switch s.viewScope(userPermissions) {
+	case ViewScopeAll:
+		s.log(ctx).Info("GetExpensesForUser: user can view all expenses",
+			"user_id", userID, "permissions", userPermissions)
+		return s.GetAllExpenses(ctx, params)
+	case ViewScopeTeam:
+		s.log(ctx).Info("GetExpensesForUser: returning team expenses",
+			"user_id", userID, "permissions", userPermissions)
+
+		expensesData, err := s.repo.GetByTeam(ctx, userID, params)
+		if err != nil {
+			s.log(ctx).Error("failed to get team expenses with query", "error", err, "user_id", userID)
+			return nil, err
+		}
+		return s.flag(ctx, ViewScopeTeam, userID, FromDataModelSlice(expensesData)...), nil
+	default:
+		s.log(ctx).Info("GetExpensesForUser: regular user, returning only user's expenses",
+			"user_id", userID, "permissions", userPermissions)
+
+		expensesData, err := s.repo.GetByUserID(ctx, userID, params)
+		if err != nil {
+			s.log(ctx).Error("failed to get user expenses with query", "error", err, "user_id", userID)
+			return nil, err
+		}
+		return s.flag(ctx, ViewScopeOwn, userID, FromDataModelSlice(expensesData)...), nil
+	}
+}
+
+func (s *QueryService) GetExpensesCountForUser(ctx context.Context, userID int64, userPermissions []string, params *ExpenseQueryParams) (int64, error) {
+	switch s.viewScope(userPermissions) {
+	case ViewScopeAll:
+		return s.repo.CountAllExpenses(ctx, params)
+	case ViewScopeTeam:
+		return s.repo.CountByTeam(ctx, userID, params)
+	default:
+		return s.repo.CountByUserID(ctx, userID, params)
+	}
+}
@@ -0,0 +1,178 @@
+package expense
+
+import (
+	"github.com/frahmantamala/expense-management/internal/core/money"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+const DefaultCurrency = money.IDR
+
+// ExpenseV2 is the /api/v2 representation of an expense.
+type ExpenseV2 struct {
+	ID              int64           `json:"id"`
+	UserID          int64           `json:"user_id"`
+	Amount          transport.Money `json:"amount"`
+	Description     string          `json:"description"`
+	Category        string          `json:"category"`
+	ReceiptURL      *string         `json:"receipt_url,omitempty"`
+	ReceiptFileName *string         `json:"receipt_filename,omitempty"`
+	PayoutAccountID *int64          `json:"payout_account_id,omitempty"`
+	Status          string          `json:"status"`
+	SubmittedBy     *int64          `json:"submitted_by,omitempty"`
+	ExpenseDate     string          `json:"expense_date"`
+	SubmittedAt     string          `json:"submitted_at"`
+	ProcessedAt     *string         `json:"processed_at,omitempty"`
+	ApprovedAt      *string         `json:"approved_at,omitempty"`
+	ApprovalSeconds *int64          `json:"approval_seconds,omitempty"`
+	// ApprovedAmount is set when less than Amount was approved.
+	ApprovedAmount   *transport.Money `json:"approved_amount,omitempty"`
+	AdjustmentReason *string          `json:"adjustment_reason,omitempty"`
+	CreatedAt        string           `json:"created_at"`
+	UpdatedAt        string           `json:"updated_at"`
+	ExchangeRate     *ExchangeRateV2  `json:"exchange_rate,omitempty"`
+	Payment          *PaymentV2       `json:"payment,omitempty"`
+	// DuplicateReceiptOf lists other expenses claimed with the same receipt.
+	DuplicateReceiptOf []int64 `json:"duplicate_receipt_of,omitempty"`
+}
+
+// PaymentV2 is the /api/v2 representation of a PaymentSummary.
+type PaymentV2 struct {
+	Status      string  `json:"status"`
+	RetryCount  int     `json:"retry_count"`
+	ProcessedAt *string `json:"processed_at,omitempty"`
+}
+
+// ExchangeRateV2 is the /api/v2 representation of a RateSnapshot.
+type ExchangeRateV2 struct {
+	OriginalAmount transport.Money `json:"original_amount"`
+	Rate           string          `json:"rate"`
+	Source         string          `json:"source"`
+	RateDate       string          `json:"rate_date"`
+	QuotedAt       string          `json:"quoted_at"`
+}
+
+func ToExpenseV2(e *Expense) ExpenseV2 {
+	v2 := ExpenseV2{
+		ID:              e.ID,
+		UserID:          e.UserID,
+		Amount:          e.Amount(),
+		Description:     e.Description,
+		Category:        e.Category,
+		ReceiptURL:      e.ReceiptURL,
+		ReceiptFileName: e.ReceiptFileName,
+		PayoutAccountID: e.PayoutAccountID,
+		Status:          e.ExpenseStatus,
+		SubmittedBy:     e.SubmittedBy,
+		ExpenseDate:     transport.FormatTimestamp(e.ExpenseDate),
+		SubmittedAt:     transport.FormatTimestamp(e.SubmittedAt),
+		ProcessedAt:     transport.FormatOptionalTimestamp(e.ProcessedAt),
+		ApprovedAt:      transport.FormatOptionalTimestamp(e.ApprovedAt),
+		ApprovalSeconds: e.ApprovalSeconds,
+		CreatedAt:       transport.FormatTimestamp(e.CreatedAt),
+		UpdatedAt:       transport.FormatTimestamp(e.UpdatedAt),
+	}
+	v2.DuplicateReceiptOf = e.DuplicateReceiptOf
+	if e.ApprovedAmountIDR != nil {
+		approved := money.Rupiah(*e.ApprovedAmountIDR)
+		v2.ApprovedAmount = &approved
+		v2.AdjustmentReason = e.AdjustmentReason
+	}
+	if r := e.ExchangeRate; r != nil {
+		v2.ExchangeRate = &ExchangeRateV2{
+			OriginalAmount: r.OriginalAmount,
+			Rate:           r.Rate,
+			Source:         r.Source,
+			RateDate:       transport.FormatTimestamp(r.RateDate),
+			QuotedAt:       transport.FormatTimestamp(r.QuotedAt),
+		}
+	}
+	if p := e.Payment; p != nil {
+		v2.Payment = &PaymentV2{
+			Status:      p.Status,
+			RetryCount:  p.RetryCount,
+			ProcessedAt: transport.FormatOptionalTimestamp(p.ProcessedAt),
+		}
+	}
+	return v2
+}
+
+// ExpenseList is the version-neutral result of a list query.
+type ExpenseList struct {
+	Expenses   []*Expense
+	Params     *ExpenseQueryParams
+	TotalCount int64
+}
+
+// ExpenseListV1 is the /api/v1 list response.
+type ExpenseListV1 struct {
+	Expenses  []*Expense `json:"expenses"`
+	PerPage   int        `json:"per_page"`
+	Page      int        `json:"page"`
+	TotalData int64      `json:"total_data"`
+	Search    string     `json:"search"`
+	Status    string     `json:"status"`
+	SortBy    string     `json:"sort_by"`
+	SortOrder string     `json:"sort_order"`
+}
+
+// ExpenseListFilters echoes the applied filters in v2 list metadata.
+type ExpenseListFilters struct {
+	Search    string `json:"search,omitempty"`
+	Status    string `json:"status,omitempty"`
+	SortBy    string `json:"sort_by,omitempty"`
+	SortOrder string `json:"sort_order,omitempty"`
+}
+
+type ExpenseListMetaV2 struct {
+	Pagination transport.PageMeta `json:"pagination"`
+	Filters    ExpenseListFilters `json:"filters"`
+}
+
+var expenseResponses = transport.ResponseMapper[*Expense]{
+	transport.APIVersionV2: func(e *Expense) interface{} {
+		return transport.Envelope{Data: ToExpenseV2(e)}
+	},
+}
+
+var expenseListResponses = transport.ResponseMapper[*ExpenseList]{
+	transport.APIVersionV1: func(l *ExpenseList) interface{} {
+		return ExpenseListV1{
+			Expenses:  l.Expenses,
+			PerPage:   l.Params.PerPage,
+			Page:      l.Params.Page,
+			TotalData: l.TotalCount,
+			Search:    l.Params.Search,
+			Status:    l.Params.Status,
+			SortBy:    l.Params.SortBy,
+			SortOrder: l.Params.SortOrder,
+		}
+	},
+	transport.APIVersionV2: func(l *ExpenseList) interface{} {
+		data := make([]ExpenseV2, 0, len(l.Expenses))
+		for _, e := range l.Expenses {
+			data = append(data, ToExpenseV2(e))
+		}
+		return transport.Envelope{
+			Data: data,
+			Meta: ExpenseListMetaV2{
+				Pagination: transport.NewPageMeta(l.Params.Page, l.Params.PerPage, l.TotalCount),
+				Filters: ExpenseListFilters{
+					Search:    l.Params.Search,
+					Status:    l.Params.Status,
+					SortBy:    l.Params.SortBy,
+					SortOrder: l.Params.SortOrder,
+				},
+			},
+		}
+	},
+}
+
+// ExpenseResponse maps an expense to the response shape of the given version.
+func ExpenseResponse(version transport.APIVersion, e *Expense) interface{} {
+	return expenseResponses.Map(version, e)
+}
+
+// ExpenseListResponse maps a list result to the response shape of the given version.
+func ExpenseListResponse(version transport.APIVersion, l *ExpenseList) interface{} {
+	return expenseListResponses.Map(version, l)
+}
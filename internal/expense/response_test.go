@@ -0,0 +1,95 @@
+package expense_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+var _ = Describe("Versioned expense responses", func() {
+	var e *expense.Expense
+
+	BeforeEach(func() {
+		jakarta := time.FixedZone("WIB", 7*3600)
+		e = &expense.Expense{
+			ID:            1,
+			UserID:        2,
+			AmountIDR:     150000,
+			Description:   "Team lunch",
+			Category:      "meals",
+			ExpenseStatus: expense.ExpenseStatusApproved,
+			ExpenseDate:   time.Date(2025, 9, 1, 12, 0, 0, 0, jakarta),
+			SubmittedAt:   time.Date(2025, 9, 1, 13, 0, 0, 0, jakarta),
+			CreatedAt:     time.Date(2025, 9, 1, 13, 0, 0, 0, jakarta),
+			UpdatedAt:     time.Date(2025, 9, 1, 13, 0, 0, 0, jakarta),
+		}
+	})
+
+	It("keeps the v1 shape unchanged", func() {
+		Expect(expense.ExpenseResponse(transport.APIVersionV1, e)).To(BeIdenticalTo(e))
+	})
+
+	It("renders v2 expenses with a money object and UTC timestamps", func() {
+		body, err := json.Marshal(expense.ExpenseResponse(transport.APIVersionV2, e))
+		Expect(err).NotTo(HaveOccurred())
+
+		var got map[string]map[string]interface{}
+		Expect(json.Unmarshal(body, &got)).To(Succeed())
+		Expect(got["data"]["amount"]).To(Equal(map[string]interface{}{"amount": float64(150000), "currency": "IDR"}))
+		Expect(got["data"]["status"]).To(Equal("approved"))
+		Expect(got["data"]["expense_date"]).To(Equal("2025-09-01T05:00:00Z"))
+		Expect(got["data"]).NotTo(HaveKey("processed_at"))
+	})
+
+	It("renders a reduced approval next to the claimed amount", func() {
+		approved, reason := int64(100000), "Alcohol is not reimbursed"
+		e.ApprovedAmountIDR = &approved
+		e.AdjustmentReason = &reason
+
+		body, err := json.Marshal(expense.ExpenseResponse(transport.APIVersionV2, e))
+		Expect(err).NotTo(HaveOccurred())
+
+		var got map[string]map[string]interface{}
+		Expect(json.Unmarshal(body, &got)).To(Succeed())
+		Expect(got["data"]["amount"]).To(Equal(map[string]interface{}{"amount": float64(150000), "currency": "IDR"}))
+		Expect(got["data"]["approved_amount"]).To(Equal(map[string]interface{}{"amount": float64(100000), "currency": "IDR"}))
+		Expect(got["data"]["adjustment_reason"]).To(Equal(reason))
+	})
+
+	It("envelopes v2 lists with pagination metadata", func() {
+		list := &expense.ExpenseList{
+			Expenses:   []*expense.Expense{e},
+			Params:     &expense.ExpenseQueryParams{Page: 2, PerPage: 10, Status: "approved"},
+			TotalCount: 25,
+		}
+
+		resp, ok := expense.ExpenseListResponse(transport.APIVersionV2, list).(transport.Envelope)
+		Expect(ok).To(BeTrue())
+		Expect(resp.Data).To(HaveLen(1))
+
+		meta := resp.Meta.(expense.ExpenseListMetaV2)
+		Expect(meta.Pagination).To(Equal(transport.PageMeta{Page: 2, PerPage: 10, Total: 25, TotalPages: 3}))
+		Expect(meta.Filters.Status).To(Equal("approved"))
+	})
+
+	It("keeps the v1 list keys", func() {
+		list := &expense.ExpenseList{
+			Expenses:   []*expense.Expense{e},
+			Params:     &expense.ExpenseQueryParams{Page: 1, PerPage: 10},
+			TotalCount: 1,
+		}
+
+		body, err := json.Marshal(expense.ExpenseListResponse(transport.APIVersionV1, list))
+		Expect(err).NotTo(HaveOccurred())
+
+		var got map[string]interface{}
+		Expect(json.Unmarshal(body, &got)).To(Succeed())
+		Expect(got).To(HaveKeyWithValue("total_data", float64(1)))
+		Expect(got).To(HaveKey("expenses"))
+	})
+})
@@ -0,0 +1,102 @@
+package expense
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSearchTerms bounds the size of the tsquery built from user input.
+const maxSearchTerms = 16
+
+// SearchQuery is a parsed free-text search. Bare words are prefix matched,
+// "quoted words" must appear next to each other and -word excludes matches.
+type SearchQuery struct {
+	Terms    []string
+	Phrases  [][]string
+	Excluded []string
+}
+
+// ParseSearch splits raw search input into lexemes safe to embed in a
+// Postgres tsquery. Anything that isn't a letter or digit is a separator.
+func ParseSearch(raw string) SearchQuery {
+	var q SearchQuery
+	count := 0
+
+	rest := raw
+	for rest != "" && count < maxSearchTerms {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			var phrase string
+			if end < 0 {
+				phrase, rest = rest[1:], ""
+			} else {
+				phrase, rest = rest[1:end+1], rest[end+2:]
+			}
+			words := lexemes(phrase)
+			if len(words) > maxSearchTerms-count {
+				words = words[:maxSearchTerms-count]
+			}
+			switch len(words) {
+			case 0:
+			case 1:
+				q.Terms = append(q.Terms, words[0])
+			default:
+				q.Phrases = append(q.Phrases, words)
+			}
+			count += len(words)
+			continue
+		}
+
+		token := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			token, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+
+		excluded := strings.HasPrefix(token, "-")
+		for _, word := range lexemes(token) {
+			if count >= maxSearchTerms {
+				break
+			}
+			if excluded {
+				q.Excluded = append(q.Excluded, word)
+			} else {
+				q.Terms = append(q.Terms, word)
+			}
+			count++
+		}
+	}
+
+	return q
+}
+
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0 && len(q.Excluded) == 0
+}
+
+// TSQuery renders the query in to_tsquery syntax; every clause must match.
+func (q SearchQuery) TSQuery() string {
+	clauses := make([]string, 0, len(q.Terms)+len(q.Phrases)+len(q.Excluded))
+	for _, term := range q.Terms {
+		clauses = append(clauses, term+":*")
+	}
+	for _, phrase := range q.Phrases {
+		clauses = append(clauses, "("+strings.Join(phrase, " <-> ")+")")
+	}
+	for _, term := range q.Excluded {
+		clauses = append(clauses, "!"+term)
+	}
+	return strings.Join(clauses, " & ")
+}
@@ -0,0 +1,90 @@
+package expense_test
+
+import (
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+var _ = Describe("Expense search parsing", func() {
+	It("prefix matches bare words and lowercases them", func() {
+		q := expense.ParseSearch("Taxi  AIRPORT")
+		Expect(q.Terms).To(Equal([]string{"taxi", "airport"}))
+		Expect(q.TSQuery()).To(Equal("taxi:* & airport:*"))
+	})
+
+	It("keeps quoted words together as a phrase", func() {
+		q := expense.ParseSearch(`"team lunch" jakarta`)
+		Expect(q.Phrases).To(Equal([][]string{{"team", "lunch"}}))
+		Expect(q.TSQuery()).To(Equal("jakarta:* & (team <-> lunch)"))
+	})
+
+	It("excludes words prefixed with a dash", func() {
+		q := expense.ParseSearch("hotel -bali")
+		Expect(q.Excluded).To(Equal([]string{"bali"}))
+		Expect(q.TSQuery()).To(Equal("hotel:* & !bali"))
+	})
+
+	It("strips tsquery operators from user input", func() {
+		q := expense.ParseSearch(`a&b | c:* !(d) "unterminated`)
+		Expect(q.TSQuery()).To(Equal("a:* & b:* & c:* & d:* & unterminated:*"))
+	})
+
+	It("treats punctuation-only input as no search", func() {
+		Expect(expense.ParseSearch(" &|! ").IsEmpty()).To(BeTrue())
+	})
+
+	It("caps the number of terms", func() {
+		q := expense.ParseSearch("a b c d e f g h i j k l m n o p q r s")
+		Expect(q.Terms).To(HaveLen(16))
+	})
+
+	Describe("query params", func() {
+		It("sorts by relevance when searching without an explicit sort", func() {
+			var params expense.ExpenseQueryParams
+			params.ParseFromRequest(httptest.NewRequest("GET", "/expenses?search=lunch", nil))
+
+			Expect(params.SortBy).To(Equal(expense.SortByRelevance))
+			Expect(params.SearchQuery.TSQuery()).To(Equal("lunch:*"))
+		})
+
+		It("keeps an explicit sort when searching", func() {
+			var params expense.ExpenseQueryParams
+			params.ParseFromRequest(httptest.NewRequest("GET", "/expenses?search=lunch&sort_by=amount", nil))
+
+			Expect(params.SortBy).To(Equal("amount"))
+		})
+
+		It("defaults to created_at without a search", func() {
+			var params expense.ExpenseQueryParams
+			params.ParseFromRequest(httptest.NewRequest("GET", "/expenses", nil))
+
+			Expect(params.SortBy).To(Equal("created_at"))
+			Expect(params.SearchQuery.IsEmpty()).To(BeTrue())
+		})
+
+		It("parses date and amount filters and ignores malformed ones", func() {
+			var params expense.ExpenseQueryParams
+			params.ParseFromRequest(httptest.NewRequest("GET", "/expenses?date_from=2025-10-01&date_to=31-10-2025&min_amount=50000&max_amount=-1", nil))
+
+			Expect(params.DateFrom).To(Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(params.DateTo.IsZero()).To(BeTrue())
+			Expect(params.MinAmount).To(Equal(int64(50000)))
+			Expect(params.MaxAmount).To(BeZero())
+		})
+
+		It("includes payments only when asked", func() {
+			var params expense.ExpenseQueryParams
+			params.ParseFromRequest(httptest.NewRequest("GET", "/expenses?include=approvals,payment", nil))
+			Expect(params.IncludePayment).To(BeTrue())
+
+			var plain expense.ExpenseQueryParams
+			plain.ParseFromRequest(httptest.NewRequest("GET", "/expenses", nil))
+			Expect(plain.IncludePayment).To(BeFalse())
+		})
+	})
+})
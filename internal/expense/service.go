@@ -4,22 +4,40 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
+	errors "github.com/frahmantamala/expense-management/internal"
 	"github.com/frahmantamala/expense-management/internal/auth"
 	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
 	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/core/money"
+	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
 type RepositoryAPI interface {
-	Create(expense *expenseDatamodel.Expense) error
-	GetByID(id int64) (*expenseDatamodel.Expense, error)
-	GetByUserID(userID int64, params *ExpenseQueryParams) ([]*expenseDatamodel.Expense, error)
-	GetAllExpenses(params *ExpenseQueryParams) ([]*expenseDatamodel.Expense, error)
-	CountByUserID(userID int64, params *ExpenseQueryParams) (int64, error)
-	CountAllExpenses(params *ExpenseQueryParams) (int64, error)
-	Update(expense *expenseDatamodel.Expense) error
-	UpdateStatus(id int64, status string, processedAt time.Time) error
+	Create(ctx context.Context, expense *expenseDatamodel.Expense) error
+	GetByID(ctx context.Context, id int64) (*expenseDatamodel.Expense, error)
+	GetByUserID(ctx context.Context, userID int64, params *ExpenseQueryParams) ([]*expenseDatamodel.Expense, error)
+	GetAllExpenses(ctx context.Context, params *ExpenseQueryParams) ([]*expenseDatamodel.Expense, error)
+	CountByUserID(ctx context.Context, userID int64, params *ExpenseQueryParams) (int64, error)
+	CountAllExpenses(ctx context.Context, params *ExpenseQueryParams) (int64, error)
+	GetByTeam(ctx context.Context, managerID int64, params *ExpenseQueryParams) ([]*expenseDatamodel.Expense, error)
+	CountByTeam(ctx context.Context, managerID int64, params *ExpenseQueryParams) (int64, error)
+	IsTeamMember(ctx context.Context, managerID, userID int64) (bool, error)
+	UserDepartment(ctx context.Context, userID int64) (string, error)
+	Update(ctx context.Context, expense *expenseDatamodel.Expense) error
+	// UpdateStatus moves the expense from status from to status to, failing
+	// with ErrInvalidExpenseStatus when it is no longer in from.
+	UpdateStatus(ctx context.Context, id int64, from, to string, processedAt time.Time) error
+	// Approve writes the approval on e, made in status from, failing with
+	// ErrInvalidExpenseStatus when the expense is no longer in from.
+	Approve(ctx context.Context, e *expenseDatamodel.Expense, from string) error
+	// ReceiptClaims returns, for each of hashes, the expenses claimed with a
+	// receipt of that hash, by any user, in ID order; drafts, rejected and
+	// cancelled expenses are not claims.
+	ReceiptClaims(ctx context.Context, hashes []string) (map[string][]ReceiptClaim, error)
 }
 
 type PaymentProcessorAPI interface {
@@ -28,20 +46,121 @@ type PaymentProcessorAPI interface {
 	GetPaymentStatus(expenseID int64) (interface{}, error)
 }
 
-type Service struct {
+// CategoryValidator reports whether a category name can be used on new
+// expenses; category.Service satisfies it.
+type CategoryValidator interface {
+	IsValidCategory(ctx context.Context, name string) bool
+	IsLeafCategory(ctx context.Context, name string) bool
+}
+
+// ApprovalRouter returns the routing rule for a category, or nil when the
+// default approval flow applies.
+type ApprovalRouter interface {
+	RouteFor(ctx context.Context, category string) (*ApprovalRoute, error)
+}
+
+// SpendingLimiter enforces per-user daily and monthly spending limits;
+// spendinglimit.Service satisfies it.
+type SpendingLimiter interface {
+	CheckNewExpense(ctx context.Context, userID, amountIDR int64, expenseDate time.Time) error
+	CheckApproval(ctx context.Context, userID, amountIDR int64, expenseDate time.Time) error
+}
+
+// PeriodGuard refuses changes to expenses dated in a locked month;
+// periodlock.Service satisfies it.
+type PeriodGuard interface {
+	CheckExpenseDate(ctx context.Context, expenseDate time.Time) error
+}
+
+// PayoutAccountChecker reports whether a user may be paid into one of their
+// bank accounts; bankaccount.Service satisfies it.
+type PayoutAccountChecker interface {
+	CheckPayoutAccount(ctx context.Context, userID, accountID int64) error
+}
+
+// ExchangeRates returns the rate to convert currency into rupiah with on
+// day; exchangerate.Service satisfies it.
+type ExchangeRates interface {
+	RateOn(ctx context.Context, currency string, day time.Time) (*money.Rate, error)
+}
+
+// CannedResponses looks up the canned rejection responses of the tenant ctx
+// is scoped to; cannedresponse.Service satisfies it.
+type CannedResponses interface {
+	CannedResponse(ctx context.Context, id int64) (*CannedResponse, error)
+}
+
+// Delegations decides who may file expenses for whom, and audits filings
+// made for another user; delegation.Service satisfies it.
+type Delegations interface {
+	CheckDelegation(ctx context.Context, principalID, delegateID int64) error
+	RecordFiling(ctx context.Context, expenseID, principalID, delegateID int64)
+}
+
+// TenantPolicy supplies the approval settings of the tenant ctx is scoped
+// to; tenant.SettingsService satisfies it. A nil policy applies
+// AutoApprovalThreshold and DefaultAmountLimits, lets any approver decide,
+// leaves receipts optional and does not cap pending expenses.
+type TenantPolicy interface {
+	// ExpenseAmountLimits bounds new claims in category, in rupiah.
+	ExpenseAmountLimits(ctx context.Context, category string) (min, max int64)
+	AutoApprovalThreshold(ctx context.Context) int64
+	ApprovalChain(ctx context.Context) []string
+	// PendingExpenseLimit is how many expenses a user of department may
+	// have waiting for approval at once; zero means no cap.
+	PendingExpenseLimit(ctx context.Context, department string) int64
+	ReceiptPolicy
+}
+
+// ReceiptPolicy supplies the amount above which the tenant ctx is scoped to
+// requires receipts; zero leaves them optional.
+type ReceiptPolicy interface {
+	ReceiptRequiredAbove(ctx context.Context) int64
+}
+
+// CommandService creates expenses and moves them through approval and
+// payment. It also marks expenses completed when their payment completes.
+type CommandService struct {
 	repo              RepositoryAPI
+	queries           *QueryService
 	paymentProcessor  PaymentProcessorAPI
+	categories        CategoryValidator
+	routes            ApprovalRouter
+	limits            SpendingLimiter
+	periods           PeriodGuard
+	payoutAccounts    PayoutAccountChecker
+	rates             ExchangeRates
+	cannedResponses   CannedResponses
+	delegations       Delegations
+	policy            TenantPolicy
 	permissionChecker auth.PermissionChecker
 	eventBus          *events.EventBus
+	states            *StateMachine
 	logger            *slog.Logger
 }
 
-func NewService(repo RepositoryAPI, paymentProcessor PaymentProcessorAPI, permissionChecker auth.PermissionChecker, eventBus *events.EventBus, logger *slog.Logger) *Service {
-	service := &Service{
+// NewCommandService subscribes the service to payment completion events on
+// eventBus. periods may be nil when months are never locked, rates when only
+// rupiah amounts are accepted, cannedResponses when rejections are always
+// typed, and delegations when users only file their own expenses. Every
+// status change is checked against states.
+func NewCommandService(repo RepositoryAPI, paymentProcessor PaymentProcessorAPI, categories CategoryValidator, routes ApprovalRouter, limits SpendingLimiter, periods PeriodGuard, payoutAccounts PayoutAccountChecker, rates ExchangeRates, cannedResponses CannedResponses, delegations Delegations, policy TenantPolicy, permissionChecker auth.PermissionChecker, states *StateMachine, eventBus *events.EventBus, logger *slog.Logger) *CommandService {
+	service := &CommandService{
 		repo:              repo,
+		queries:           NewQueryService(repo, policy, permissionChecker, logger),
 		paymentProcessor:  paymentProcessor,
+		categories:        categories,
+		routes:            routes,
+		limits:            limits,
+		periods:           periods,
+		payoutAccounts:    payoutAccounts,
+		rates:             rates,
+		cannedResponses:   cannedResponses,
+		delegations:       delegations,
+		policy:            policy,
 		permissionChecker: permissionChecker,
 		eventBus:          eventBus,
+		states:            states,
 		logger:            logger,
 	}
 
@@ -50,174 +169,287 @@ func NewService(repo RepositoryAPI, paymentProcessor PaymentProcessorAPI, permis
 	return service
 }
 
-func (s *Service) CreateExpense(req *CreateExpenseDTO, userID int64) (*Expense, error) {
-	if err := req.Validate(); err != nil {
-		s.logger.Error("expense validation failed", "error", err, "user_id", userID)
+// States is the state machine every status change goes through; hooks
+// added to it run after each change is stored.
+func (s *CommandService) States() *StateMachine {
+	return s.states
+}
+
+// log returns the request logger carried by ctx so service logs share the
+// request_id, trace_id and user_id of the call that triggered them.
+func (s *CommandService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// CreateExpense files an expense for userID, or for req.SubmittedForUserID
+// when that user granted userID delegation. Either way the expense belongs to
+// the user it is filed for: their limits, payout accounts and approvals
+// apply, and userID is kept as its submitter.
+func (s *CommandService) CreateExpense(ctx context.Context, req *CreateExpenseDTO, userID int64) (*Expense, error) {
+	ownerID, submittedBy, err := s.expenseOwner(ctx, req, userID)
+	if err != nil {
 		return nil, err
 	}
 
-	expense := NewExpense(userID, *req)
+	snapshot, err := s.convertAmount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(s.AmountLimits(ctx, req.Category)); err != nil {
+		s.log(ctx).Error("expense validation failed", "error", err, "user_id", ownerID)
+		return nil, err
+	}
+
+	if err := req.ValidateReceipt(receiptRequiredAbove(ctx, s.policy)); err != nil {
+		s.log(ctx).Warn("expense rejected without required receipt", "amount", req.AmountIDR, "user_id", ownerID)
+		return nil, err
+	}
+
+	if s.periods != nil {
+		if err := s.periods.CheckExpenseDate(ctx, req.ExpenseDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if !s.categories.IsValidCategory(ctx, req.Category) {
+		s.log(ctx).Warn("expense rejected for unknown category", "category", req.Category, "user_id", ownerID)
+		return nil, ErrInvalidCategory
+	}
+	if !s.categories.IsLeafCategory(ctx, req.Category) {
+		s.log(ctx).Warn("expense rejected for parent category", "category", req.Category, "user_id", ownerID)
+		return nil, ErrCategoryNotLeaf
+	}
+
+	if err := s.limits.CheckNewExpense(ctx, ownerID, req.AmountIDR, req.ExpenseDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPendingLimit(ctx, ownerID); err != nil {
+		return nil, err
+	}
+
+	if req.PayoutAccountID != nil {
+		if s.payoutAccounts == nil {
+			return nil, ErrPayoutAccountsDisabled
+		}
+		if err := s.payoutAccounts.CheckPayoutAccount(ctx, ownerID, *req.PayoutAccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	route, err := s.routes.RouteFor(ctx, req.Category)
+	if err != nil {
+		s.log(ctx).Error("failed to load approval route", "error", err, "category", req.Category)
+		return nil, fmt.Errorf("failed to load approval route: %w", err)
+	}
+
+	expense := NewExpense(ownerID, *req, route, s.autoApprovalThreshold(ctx))
+	expense.ExchangeRate = snapshot
+	expense.SubmittedBy = submittedBy
 
 	expenseData := ToDataModel(expense)
-	if err := s.repo.Create(expenseData); err != nil {
-		s.logger.Error("failed to create expense", "error", err, "user_id", userID)
+	if err := s.repo.Create(ctx, expenseData); err != nil {
+		s.log(ctx).Error("failed to create expense", "error", err, "user_id", ownerID)
 		return nil, fmt.Errorf("failed to create expense: %w", err)
 	}
 
 	expense.ID = expenseData.ID
+	if submittedBy != nil {
+		s.delegations.RecordFiling(ctx, expense.ID, ownerID, *submittedBy)
+		s.log(ctx).Info("expense filed by delegate", "expense_id", expense.ID, "user_id", ownerID, "submitted_by", *submittedBy)
+	}
 
 	if expense.NeedsPaymentProcessing() {
-		s.logger.Info("expense auto-approved, triggering payment via event",
+		s.notify(ctx, newStatusChange(expense, ExpenseStatusPendingApproval, nil))
+
+		s.log(ctx).Info("expense auto-approved, triggering payment via event",
 			"expense_id", expense.ID,
 			"amount", expense.AmountIDR)
 
 		event := events.NewExpenseApprovedEvent(expense.ID, expense.AmountIDR, expense.UserID, "IDR")
-		if err := s.eventBus.Publish(context.Background(), event); err != nil {
-			s.logger.Error("failed to publish auto-approval event",
+		if err := s.eventBus.Publish(context.WithoutCancel(ctx), event); err != nil {
+			s.log(ctx).Error("failed to publish auto-approval event",
 				"error", err,
 				"expense_id", expense.ID)
 
 		} else {
-			s.logger.Info("auto-approval event published for async payment processing",
+			s.log(ctx).Info("auto-approval event published for async payment processing",
 				"expense_id", expense.ID,
 				"event_id", event.EventID())
 		}
 	}
 
-	s.logger.Info("expense created successfully",
+	s.log(ctx).Info("expense created successfully",
 		"expense_id", expense.ID,
-		"user_id", userID,
+		"user_id", ownerID,
 		"amount", req.AmountIDR,
 		"status", expense.ExpenseStatus)
 
 	return expense, nil
 }
 
-func (s *Service) GetExpenseByID(id, userID int64, userPermissions []string) (*Expense, error) {
-	expenseData, err := s.repo.GetByID(id)
-	if err != nil {
-		s.logger.Error("failed to get expense", "error", err, "expense_id", id)
-		return nil, ErrExpenseNotFound
+// expenseOwner returns who an expense filed by userID belongs to, and the
+// delegate to record as its submitter when that is someone else.
+func (s *CommandService) expenseOwner(ctx context.Context, req *CreateExpenseDTO, userID int64) (int64, *int64, error) {
+	if req.SubmittedForUserID == nil || *req.SubmittedForUserID == userID {
+		return userID, nil, nil
 	}
-
-	expense := FromDataModel(expenseData)
-
-	canAccess := expense.UserID == userID || s.permissionChecker.CanViewAllExpenses(userPermissions)
-	if !canAccess {
-		s.logger.Warn("unauthorized access to expense", "expense_id", id, "user_id", userID, "expense_user_id", expense.UserID)
-		return nil, ErrUnauthorizedAccess
+	if s.delegations == nil {
+		return 0, nil, ErrDelegationDisabled
 	}
 
-	return expense, nil
+	principalID := *req.SubmittedForUserID
+	if err := s.delegations.CheckDelegation(ctx, principalID, userID); err != nil {
+		return 0, nil, err
+	}
+	return principalID, &userID, nil
 }
 
-func (s *Service) UpdateExpenseStatus(expenseID int64, status string, userID int64, userPermissions []string) (*Expense, error) {
+// convertAmount folds req.Amount into req.AmountIDR. An amount in another
+// currency is converted at the rate of the expense date, and the rate used
+// is returned so it can be kept on the expense; without exchange rates only
+// rupiah is accepted.
+func (s *CommandService) convertAmount(ctx context.Context, req *CreateExpenseDTO) (*RateSnapshot, error) {
+	if req.Amount == nil || req.Amount.Currency == DefaultCurrency || s.rates == nil {
+		return nil, req.resolveAmount()
+	}
+	if req.AmountIDR != 0 {
+		return nil, errors.NewValidationFieldError("amount_idr", "amount_idr cannot be sent with an amount in another currency", errors.ErrCodeValidationFailed)
+	}
+	if req.ExpenseDate.IsZero() {
+		return nil, errors.NewValidationFieldError("expense_date", "expense_date is required to convert an amount in another currency", errors.ErrCodeInvalidDate)
+	}
 
-	if err := s.repo.UpdateStatus(expenseID, status, time.Now()); err != nil {
-		s.logger.Error("failed to update expense status", "error", err, "expense_id", expenseID, "status", status)
+	rate, err := s.rates.RateOn(ctx, req.Amount.Currency, req.ExpenseDate)
+	if err != nil {
 		return nil, err
 	}
+	converted, err := rate.Convert(*req.Amount)
+	if err != nil {
+		return nil, errors.NewValidationFieldError("amount", err.Error(), errors.ErrCodeInvalidAmount)
+	}
 
-	return s.GetExpenseByID(expenseID, userID, userPermissions)
-}
-
-func (s *Service) SubmitExpenseForApproval(expenseID int64, userID int64, userPermissions []string) (*Expense, error) {
-	return s.UpdateExpenseStatus(expenseID, "submitted", userID, userPermissions)
+	req.AmountIDR = converted.Amount
+	return &RateSnapshot{
+		OriginalAmount: *req.Amount,
+		Rate:           rate.Value,
+		Source:         rate.Source,
+		RateDate:       rate.Date,
+		QuotedAt:       rate.QuotedAt,
+	}, nil
 }
 
-func (s *Service) GetAllExpenses(params *ExpenseQueryParams) ([]*Expense, error) {
-	params.SetDefaults()
-
-	s.logger.Info("GetAllExpenses: Starting with params",
-		"page", params.Page,
-		"per_page", params.PerPage,
-		"offset_calculated", params.GetOffset(),
-		"search", params.Search,
-		"category", params.CategoryID,
-		"status", params.Status)
-
-	expensesData, err := s.repo.GetAllExpenses(params)
-	if err != nil {
-		s.logger.Error("failed to get all expenses", "error", err)
+func (s *CommandService) UpdateExpenseStatus(ctx context.Context, expenseID int64, status string, userID int64, userPermissions []string) (*Expense, error) {
+	if _, err := s.transition(ctx, expenseID, status, &userID); err != nil {
+		s.log(ctx).Error("failed to update expense status", "error", err, "expense_id", expenseID, "status", status)
 		return nil, err
 	}
 
-	return FromDataModelSlice(expensesData), nil
+	return s.queries.GetExpenseByID(ctx, expenseID, userID, userPermissions)
 }
 
-func (s *Service) GetExpensesForUser(userID int64, userPermissions []string, params *ExpenseQueryParams) ([]*Expense, error) {
-	params.SetDefaults()
-
-	if s.permissionChecker.CanViewAllExpenses(userPermissions) {
-		s.logger.Info("GetExpensesForUser: user has management permissions, returning all expenses",
-			"user_id", userID, "permissions", userPermissions)
-		return s.GetAllExpenses(params)
-	} else {
-		s.logger.Info("GetExpensesForUser: regular user, returning only user's expenses",
-			"user_id", userID, "permissions", userPermissions)
+// SubmitExpenseForApproval moves a draft expense into the approval queue.
+func (s *CommandService) SubmitExpenseForApproval(ctx context.Context, expenseID int64, userID int64, userPermissions []string) (*Expense, error) {
+	return s.UpdateExpenseStatus(ctx, expenseID, ExpenseStatusPendingApproval, userID, userPermissions)
+}
 
-		expensesData, err := s.repo.GetByUserID(userID, params)
-		if err != nil {
-			s.logger.Error("failed to get user expenses with query", "error", err, "user_id", userID)
-			return nil, err
-		}
-		return FromDataModelSlice(expensesData), nil
-	}
+func (s *CommandService) ApproveExpense(ctx context.Context, expenseID, managerID int64, userPermissions []string) error {
+	return s.approve(ctx, expenseID, managerID, nil, "", userPermissions)
 }
 
-func (s *Service) GetExpensesCountForUser(userID int64, userPermissions []string, params *ExpenseQueryParams) (int64, error) {
-	if s.permissionChecker.CanViewAllExpenses(userPermissions) {
-		return s.repo.CountAllExpenses(params)
-	} else {
-		return s.repo.CountByUserID(userID, params)
+// ApproveExpenseAdjusted approves approvedAmountIDR of the claim, for
+// reason; the expense is paid that amount. It may not exceed the amount
+// claimed, and approving all of it is a plain approval.
+func (s *CommandService) ApproveExpenseAdjusted(ctx context.Context, expenseID, managerID, approvedAmountIDR int64, reason string, userPermissions []string) error {
+	if approvedAmountIDR <= 0 {
+		return errors.NewValidationFieldError("approved_amount_idr", "approved_amount_idr must be positive", errors.ErrCodeInvalidAmount)
 	}
+	return s.approve(ctx, expenseID, managerID, &approvedAmountIDR, strings.TrimSpace(reason), userPermissions)
 }
 
-func (s *Service) ApproveExpense(expenseID, managerID int64, userPermissions []string) error {
+func (s *CommandService) approve(ctx context.Context, expenseID, managerID int64, approvedAmountIDR *int64, reason string, userPermissions []string) error {
 	if !s.permissionChecker.CanApproveExpenses(userPermissions) {
-		s.logger.Warn("approve expense denied: insufficient permissions",
+		s.log(ctx).Warn("approve expense denied: insufficient permissions",
 			"expense_id", expenseID,
 			"manager_id", managerID,
 			"permissions", userPermissions)
 		return ErrUnauthorizedAccess
 	}
 
-	expenseData, err := s.repo.GetByID(expenseID)
+	expenseData, err := s.repo.GetByID(ctx, expenseID)
 	if err != nil {
-		s.logger.Error("expense not found for approval", "error", err, "expense_id", expenseID)
+		s.log(ctx).Error("expense not found for approval", "error", err, "expense_id", expenseID)
 		return ErrExpenseNotFound
 	}
 
 	expense := FromDataModel(expenseData)
 
-	if !expense.CanBeApproved() {
-		s.logger.Warn("cannot approve expense in current status",
+	from := expense.ExpenseStatus
+	if err := s.states.Check(from, ExpenseStatusApproved); err != nil {
+		s.log(ctx).Warn("cannot approve expense in current status",
 			"expense_id", expenseID,
-			"current_status", expense.ExpenseStatus)
-		return ErrInvalidExpenseStatus
+			"current_status", from)
+		return err
 	}
 
-	expense.Approve()
+	if err := s.checkOwnExpense(ctx, expense, managerID); err != nil {
+		return err
+	}
 
-	updatedExpenseData := ToDataModel(expense)
-	if err := s.repo.Update(updatedExpenseData); err != nil {
-		s.logger.Error("failed to update expense status to approved", "error", err, "expense_id", expenseID)
+	if err := s.checkRoutedApprover(ctx, expense, managerID, userPermissions); err != nil {
+		return err
+	}
+
+	if expense.NeedsReceipt(receiptRequiredAbove(ctx, s.policy)) {
+		s.log(ctx).Warn("cannot approve expense without required receipt",
+			"expense_id", expenseID,
+			"amount", expense.AmountIDR)
+		return ErrReceiptRequired
+	}
+
+	if approvedAmountIDR != nil && *approvedAmountIDR > expense.AmountIDR {
+		return errors.NewValidationFieldError("approved_amount_idr", "approved_amount_idr must not exceed the claimed "+strconv.FormatInt(expense.AmountIDR, 10), errors.ErrCodeInvalidAmount)
+	}
+	if approvedAmountIDR != nil && *approvedAmountIDR == expense.AmountIDR {
+		approvedAmountIDR = nil
+	}
+
+	amount := expense.AmountIDR
+	if approvedAmountIDR != nil {
+		amount = *approvedAmountIDR
+	}
+	if err := s.limits.CheckApproval(ctx, expense.UserID, amount, expense.ExpenseDate); err != nil {
+		return err
+	}
+
+	if approvedAmountIDR != nil {
+		expense.ApproveAdjustedBy(managerID, *approvedAmountIDR, reason)
+	} else {
+		expense.ApproveBy(managerID)
+	}
+
+	// Conditional on from, so of two approvals racing only one starts a
+	// payment.
+	if err := s.repo.Approve(ctx, ToDataModel(expense), from); err != nil {
+		s.log(ctx).Error("failed to update expense status to approved", "error", err, "expense_id", expenseID)
 		return err
 	}
+	s.notify(ctx, newStatusChange(expense, from, &managerID))
 
-	s.logger.Info("expense approved successfully",
+	s.log(ctx).Info("expense approved successfully",
 		"expense_id", expenseID,
 		"manager_id", managerID,
-		"amount", expense.AmountIDR)
+		"amount", expense.AmountIDR,
+		"approved_amount", expense.PayableAmountIDR())
 
-	event := events.NewExpenseApprovedEvent(expenseID, expense.AmountIDR, expense.UserID, "IDR")
-	if err := s.eventBus.Publish(context.Background(), event); err != nil {
-		s.logger.Error("failed to publish expense approved event",
+	event := events.NewExpenseApprovedEvent(expenseID, expense.PayableAmountIDR(), expense.UserID, "IDR")
+	if err := s.eventBus.Publish(context.WithoutCancel(ctx), event); err != nil {
+		s.log(ctx).Error("failed to publish expense approved event",
 			"error", err,
 			"expense_id", expenseID)
 
 	} else {
-		s.logger.Info("expense approved event published for async payment processing",
+		s.log(ctx).Info("expense approved event published for async payment processing",
 			"expense_id", expenseID,
 			"event_id", event.EventID())
 	}
@@ -225,103 +457,238 @@ func (s *Service) ApproveExpense(expenseID, managerID int64, userPermissions []s
 	return nil
 }
 
-func (s *Service) RejectExpense(expenseID, managerID int64, reason string, userPermissions []string) error {
+func (s *CommandService) RejectExpense(ctx context.Context, expenseID, managerID int64, code, reason string, userPermissions []string) error {
+	return s.reject(ctx, expenseID, managerID, code, reason, nil, userPermissions)
+}
+
+// RejectWithCannedResponse rejects with the code and comment of a canned
+// response. code, when given, must match the response's; note, when given,
+// is added below its comment.
+func (s *CommandService) RejectWithCannedResponse(ctx context.Context, expenseID, managerID, responseID int64, code, note string, userPermissions []string) error {
+	if s.cannedResponses == nil {
+		return ErrCannedResponsesDisabled
+	}
+	response, err := s.cannedResponses.CannedResponse(ctx, responseID)
+	if err != nil {
+		return err
+	}
+	if code != "" && code != response.Code {
+		return errors.NewValidationFieldError("code", "code must be "+response.Code+" to match the canned response", errors.ErrCodeValidationFailed)
+	}
+
+	reason := response.Body
+	if note = strings.TrimSpace(note); note != "" {
+		reason += "\n\n" + note
+	}
+	if len(reason) > MaxRejectionReasonLength {
+		return errors.NewValidationFieldError("reason", "reason must be at most "+strconv.Itoa(MaxRejectionReasonLength)+" characters including the canned response", errors.ErrCodeValidationFailed)
+	}
+
+	return s.reject(ctx, expenseID, managerID, response.Code, reason, &response.ID, userPermissions)
+}
+
+func (s *CommandService) reject(ctx context.Context, expenseID, managerID int64, code, reason string, cannedResponseID *int64, userPermissions []string) error {
 	if !s.permissionChecker.CanRejectExpenses(userPermissions) {
-		s.logger.Warn("reject expense denied: insufficient permissions",
+		s.log(ctx).Warn("reject expense denied: insufficient permissions",
 			"expense_id", expenseID,
 			"manager_id", managerID,
 			"permissions", userPermissions)
 		return ErrUnauthorizedAccess
 	}
 
-	expenseData, err := s.repo.GetByID(expenseID)
+	expenseData, err := s.repo.GetByID(ctx, expenseID)
 	if err != nil {
-		s.logger.Error("expense not found for rejection", "error", err, "expense_id", expenseID)
+		s.log(ctx).Error("expense not found for rejection", "error", err, "expense_id", expenseID)
 		return ErrExpenseNotFound
 	}
 
 	expense := FromDataModel(expenseData)
 
-	if !expense.CanBeRejected() {
-		s.logger.Warn("cannot reject expense in current status",
+	from := expense.ExpenseStatus
+	if err := s.states.Check(from, ExpenseStatusRejected); err != nil {
+		s.log(ctx).Warn("cannot reject expense in current status",
 			"expense_id", expenseID,
-			"current_status", expense.ExpenseStatus)
-		return ErrInvalidExpenseStatus
+			"current_status", from)
+		return err
 	}
 
-	expense.Reject()
+	if err := s.checkOwnExpense(ctx, expense, managerID); err != nil {
+		return err
+	}
+
+	if err := s.checkRoutedApprover(ctx, expense, managerID, userPermissions); err != nil {
+		return err
+	}
+
+	expense.Reject(managerID, code, reason)
+	expense.CannedResponseID = cannedResponseID
 
 	updatedExpenseData := ToDataModel(expense)
-	if err := s.repo.Update(updatedExpenseData); err != nil {
-		s.logger.Error("failed to update expense status to rejected", "error", err, "expense_id", expenseID)
+	if err := s.repo.Update(ctx, updatedExpenseData); err != nil {
+		s.log(ctx).Error("failed to update expense status to rejected", "error", err, "expense_id", expenseID)
 		return err
 	}
+	s.notify(ctx, newStatusChange(expense, from, &managerID))
 
-	s.logger.Info("expense rejected successfully",
+	s.log(ctx).Info("expense rejected successfully",
 		"expense_id", expenseID,
 		"manager_id", managerID,
-		"reason", reason,
+		"code", code,
 		"amount", expense.AmountIDR)
 
 	return nil
 }
 
-func (s *Service) RetryPayment(expenseID int64, userPermissions []string) error {
+func (s *CommandService) autoApprovalThreshold(ctx context.Context) int64 {
+	if s.policy == nil {
+		return AutoApprovalThreshold
+	}
+	return s.policy.AutoApprovalThreshold(ctx)
+}
+
+// AmountLimits bounds new claims in category for the tenant ctx is scoped
+// to.
+func (s *CommandService) AmountLimits(ctx context.Context, category string) AmountLimits {
+	if s.policy == nil {
+		return DefaultAmountLimits
+	}
+	min, max := s.policy.ExpenseAmountLimits(ctx, category)
+	return AmountLimits{Min: min, Max: max}
+}
+
+// checkPendingLimit refuses a new expense when the user already has as many
+// waiting for approval as their department's cap allows. The count and the
+// insert are not atomic, so concurrent submissions can overshoot the cap by
+// a few; it is a guard against floods, not an accounting rule.
+func (s *CommandService) checkPendingLimit(ctx context.Context, userID int64) error {
+	if s.policy == nil {
+		return nil
+	}
+
+	department, err := s.repo.UserDepartment(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load user department: %w", err)
+	}
+	limit := s.policy.PendingExpenseLimit(ctx, department)
+	if limit == 0 {
+		return nil
+	}
+
+	pending, err := s.repo.CountByUserID(ctx, userID, &ExpenseQueryParams{Status: ExpenseStatusPendingApproval})
+	if err != nil {
+		return fmt.Errorf("failed to count pending expenses: %w", err)
+	}
+	if pending >= limit {
+		s.log(ctx).Warn("expense rejected over pending limit", "user_id", userID, "pending", pending, "limit", limit)
+		return newPendingLimitError(PendingLimitExceeded{Limit: limit, Pending: pending})
+	}
+	return nil
+}
+
+func receiptRequiredAbove(ctx context.Context, policy ReceiptPolicy) int64 {
+	if policy == nil {
+		return 0
+	}
+	return policy.ReceiptRequiredAbove(ctx)
+}
+
+// checkRoutedApprover enforces the category's routing rule, or else the
+// tenant's approval chain, on top of the general approve/reject permission.
+func (s *CommandService) checkRoutedApprover(ctx context.Context, expense *Expense, managerID int64, userPermissions []string) error {
+	route, err := s.routes.RouteFor(ctx, expense.Category)
+	if err != nil {
+		s.log(ctx).Error("failed to load approval route", "error", err, "expense_id", expense.ID, "category", expense.Category)
+		return fmt.Errorf("failed to load approval route: %w", err)
+	}
+
+	var required []string
+	if route != nil && route.ApproverPermission != "" {
+		required = []string{route.ApproverPermission}
+	} else if s.policy != nil {
+		required = s.policy.ApprovalChain(ctx)
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	if !s.permissionChecker.HasAnyPermission(userPermissions, append(required, string(auth.PermAdmin))) {
+		s.log(ctx).Warn("decision denied by approval route",
+			"expense_id", expense.ID,
+			"manager_id", managerID,
+			"category", expense.Category,
+			"required_permissions", required)
+		return ErrUnauthorizedAccess
+	}
+	return nil
+}
+
+func (s *CommandService) RetryPayment(ctx context.Context, expenseID int64, userPermissions []string) error {
 	if !s.permissionChecker.CanRetryPayments(userPermissions) {
-		s.logger.Warn("user lacks permissions for payment retry", "expense_id", expenseID)
+		s.log(ctx).Warn("user lacks permissions for payment retry", "expense_id", expenseID)
 		return ErrUnauthorizedAccess
 	}
 
-	expense, err := s.repo.GetByID(expenseID)
+	expense, err := s.repo.GetByID(ctx, expenseID)
 	if err != nil {
-		s.logger.Error("failed to get expense for payment retry", "error", err, "expense_id", expenseID)
+		s.log(ctx).Error("failed to get expense for payment retry", "error", err, "expense_id", expenseID)
 		return ErrExpenseNotFound
 	}
 
-	if expense.ExpenseStatus != ExpenseStatusApproved {
-		s.logger.Error("expense not approved for payment retry", "expense_id", expenseID, "status", expense.ExpenseStatus)
+	if !s.states.Can(expense.ExpenseStatus, ExpenseStatusProcessingPayment) {
+		s.log(ctx).Error("expense not payable for payment retry", "expense_id", expenseID, "status", expense.ExpenseStatus)
 		return ErrInvalidExpenseStatus
 	}
 
 	_, err = s.paymentProcessor.GetPaymentStatus(expenseID)
 	if err != nil {
-		s.logger.Error("failed to get payment status", "error", err, "expense_id", expenseID)
+		s.log(ctx).Error("failed to get payment status", "error", err, "expense_id", expenseID)
 		return ErrInvalidExpenseStatus
 	}
 
-	s.logger.Info("retrying payment", "expense_id", expenseID, "amount", expense.AmountIDR)
+	if _, err := s.transition(ctx, expenseID, ExpenseStatusProcessingPayment, nil); err != nil {
+		return err
+	}
+
+	// The payment was created for the payable amount, which is less than
+	// AmountIDR after an adjusted approval.
+	amount := FromDataModel(expense).PayableAmountIDR()
+	s.log(ctx).Info("retrying payment", "expense_id", expenseID, "amount", amount)
 
-	externalID := fmt.Sprintf("exp-%d-%d", expenseID, expense.AmountIDR)
+	externalID := fmt.Sprintf("exp-%d-%d", expenseID, amount)
 	err = s.paymentProcessor.RetryPayment(expenseID, externalID)
 	if err != nil {
-		s.logger.Error("payment retry failed", "error", err, "expense_id", expenseID)
+		s.log(ctx).Error("payment retry failed", "error", err, "expense_id", expenseID)
+		if _, statusErr := s.transition(ctx, expenseID, ExpenseStatusPaymentFailed, nil); statusErr != nil {
+			s.log(ctx).Error("failed to mark expense payment failed", "error", statusErr, "expense_id", expenseID)
+		}
 		return fmt.Errorf("payment retry failed: %w", err)
 	}
 
 	return nil
 }
 
-func (s *Service) RegisterEventHandlers() {
+func (s *CommandService) RegisterEventHandlers() {
 	s.eventBus.Subscribe(events.EventTypePaymentCompleted, s.handlePaymentCompleted)
-	s.logger.Info("expense event handlers registered", "handlers", []string{events.EventTypePaymentCompleted})
+	s.eventBus.Subscribe(events.EventTypePaymentFailed, s.handlePaymentFailed)
+	s.logger.Info("expense event handlers registered", "handlers", []string{events.EventTypePaymentCompleted, events.EventTypePaymentFailed})
 }
 
-func (s *Service) handlePaymentCompleted(ctx context.Context, event events.Event) error {
+func (s *CommandService) handlePaymentCompleted(ctx context.Context, event events.Event) error {
 	paymentEvent, ok := event.(*events.PaymentCompletedEvent)
 	if !ok {
-		s.logger.Error("invalid event type for payment completed handler", "event_type", event.EventType())
+		s.log(ctx).Error("invalid event type for payment completed handler", "event_type", event.EventType())
 		return fmt.Errorf("expected PaymentCompletedEvent, got %T", event)
 	}
 
-	s.logger.Info("handling payment completed event to update expense status",
+	s.log(ctx).Info("handling payment completed event to update expense status",
 		"expense_id", paymentEvent.ExpenseID,
 		"payment_id", paymentEvent.PaymentID,
 		"external_id", paymentEvent.ExternalID,
 		"event_id", paymentEvent.EventID())
 
-	err := s.repo.UpdateStatus(paymentEvent.ExpenseID, ExpenseStatusCompleted, time.Now())
+	_, err := s.transition(ctx, paymentEvent.ExpenseID, ExpenseStatusCompleted, nil)
 	if err != nil {
-		s.logger.Error("failed to update expense status after payment completion",
+		s.log(ctx).Error("failed to update expense status after payment completion",
 			"error", err,
 			"expense_id", paymentEvent.ExpenseID,
 			"payment_id", paymentEvent.PaymentID,
@@ -329,7 +696,7 @@ func (s *Service) handlePaymentCompleted(ctx context.Context, event events.Event
 		return fmt.Errorf("expense status update failed for expense %d: %w", paymentEvent.ExpenseID, err)
 	}
 
-	s.logger.Info("expense status updated to completed successfully",
+	s.log(ctx).Info("expense status updated to completed successfully",
 		"expense_id", paymentEvent.ExpenseID,
 		"payment_id", paymentEvent.PaymentID,
 		"external_id", paymentEvent.ExternalID,
@@ -337,3 +704,86 @@ func (s *Service) handlePaymentCompleted(ctx context.Context, event events.Event
 
 	return nil
 }
+
+func (s *CommandService) handlePaymentFailed(ctx context.Context, event events.Event) error {
+	failedEvent, ok := event.(*events.PaymentFailedEvent)
+	if !ok {
+		s.log(ctx).Error("invalid event type for payment failed handler", "event_type", event.EventType())
+		return fmt.Errorf("expected PaymentFailedEvent, got %T", event)
+	}
+
+	if _, err := s.transition(ctx, failedEvent.ExpenseID, ExpenseStatusPaymentFailed, nil); err != nil {
+		s.log(ctx).Error("failed to update expense status after payment failure",
+			"error", err,
+			"expense_id", failedEvent.ExpenseID,
+			"payment_id", failedEvent.PaymentID,
+			"event_id", failedEvent.EventID())
+		return fmt.Errorf("expense status update failed for expense %d: %w", failedEvent.ExpenseID, err)
+	}
+
+	s.log(ctx).Info("expense status updated to payment failed",
+		"expense_id", failedEvent.ExpenseID,
+		"payment_id", failedEvent.PaymentID,
+		"failure_reason", failedEvent.FailureReason)
+
+	return nil
+}
+
+// notify runs the state machine's hooks for change, logging rather than
+// returning failures since the change is already stored.
+func (s *CommandService) notify(ctx context.Context, change StatusChange) {
+	if err := s.states.Notify(ctx, change); err != nil {
+		s.log(ctx).Error("expense status change hook failed",
+			"error", err,
+			"expense_id", change.ExpenseID,
+			"from", change.From,
+			"to", change.To)
+	}
+}
+
+// checkOwnExpense refuses a decision by the expense's owner or by the
+// delegate who filed it, so no one signs off on their own claim.
+func (s *CommandService) checkOwnExpense(ctx context.Context, expense *Expense, managerID int64) error {
+	if expense.UserID != managerID && (expense.SubmittedBy == nil || *expense.SubmittedBy != managerID) {
+		return nil
+	}
+	s.log(ctx).Warn("decision on own expense refused",
+		"expense_id", expense.ID,
+		"manager_id", managerID)
+	return ErrOwnExpenseDecision
+}
+
+// transition moves the stored expense to status to when the state machine
+// allows it from the status it is in now. The update only applies if the
+// status has not changed meanwhile. Moving to the current status is a no-op,
+// so redelivered payment events are harmless.
+func (s *CommandService) transition(ctx context.Context, expenseID int64, to string, actorID *int64) (*Expense, error) {
+	data, err := s.repo.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	expense := FromDataModel(data)
+
+	from := expense.ExpenseStatus
+	if from == to {
+		return expense, nil
+	}
+	if err := s.states.Check(from, to); err != nil {
+		s.log(ctx).Warn("expense status transition refused",
+			"expense_id", expenseID,
+			"from", from,
+			"to", to)
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.repo.UpdateStatus(ctx, expenseID, from, to, now); err != nil {
+		return nil, err
+	}
+	expense.ExpenseStatus = to
+	expense.ProcessedAt = &now
+	expense.UpdatedAt = now
+
+	s.notify(ctx, newStatusChange(expense, from, actorID))
+	return expense, nil
+}
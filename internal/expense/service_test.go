@@ -1,17 +1,21 @@
 package expense_test
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"os"
+	"sort"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/frahmantamala/expense-management/internal"
 	"github.com/frahmantamala/expense-management/internal/auth"
 	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
 	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/core/money"
 	"github.com/frahmantamala/expense-management/internal/expense"
 )
 
@@ -19,9 +23,12 @@ type mockExpenseRepository struct {
 	expenses       map[int64]*expenseDatamodel.Expense
 	expensesByUser map[int64][]*expenseDatamodel.Expense
 	allExpenses    []*expenseDatamodel.Expense
+	teamMembers    map[int64][]int64
+	departments    map[int64]string
 	createError    error
 	getError       error
 	updateError    error
+	beforeApprove  func()
 	nextID         int64
 }
 
@@ -30,11 +37,13 @@ func newMockExpenseRepository() *mockExpenseRepository {
 		expenses:       make(map[int64]*expenseDatamodel.Expense),
 		expensesByUser: make(map[int64][]*expenseDatamodel.Expense),
 		allExpenses:    make([]*expenseDatamodel.Expense, 0),
+		teamMembers:    make(map[int64][]int64),
+		departments:    make(map[int64]string),
 		nextID:         1,
 	}
 }
 
-func (m *mockExpenseRepository) Create(exp *expenseDatamodel.Expense) error {
+func (m *mockExpenseRepository) Create(_ context.Context, exp *expenseDatamodel.Expense) error {
 	if m.createError != nil {
 		return m.createError
 	}
@@ -51,7 +60,7 @@ func (m *mockExpenseRepository) Create(exp *expenseDatamodel.Expense) error {
 	return nil
 }
 
-func (m *mockExpenseRepository) GetByID(id int64) (*expenseDatamodel.Expense, error) {
+func (m *mockExpenseRepository) GetByID(_ context.Context, id int64) (*expenseDatamodel.Expense, error) {
 	if m.getError != nil {
 		return nil, m.getError
 	}
@@ -62,7 +71,7 @@ func (m *mockExpenseRepository) GetByID(id int64) (*expenseDatamodel.Expense, er
 	return exp, nil
 }
 
-func (m *mockExpenseRepository) GetByUserID(userID int64, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
+func (m *mockExpenseRepository) GetByUserID(_ context.Context, userID int64, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
 	if m.getError != nil {
 		return nil, m.getError
 	}
@@ -106,7 +115,7 @@ func (m *mockExpenseRepository) GetByUserID(userID int64, params *expense.Expens
 	return filtered[start:end], nil
 }
 
-func (m *mockExpenseRepository) GetAllExpenses(params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
+func (m *mockExpenseRepository) GetAllExpenses(_ context.Context, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
 	if m.getError != nil {
 		return nil, m.getError
 	}
@@ -170,7 +179,7 @@ func min(a, b int) int {
 	return b
 }
 
-func (m *mockExpenseRepository) Update(exp *expenseDatamodel.Expense) error {
+func (m *mockExpenseRepository) Update(_ context.Context, exp *expenseDatamodel.Expense) error {
 	if m.updateError != nil {
 		return m.updateError
 	}
@@ -179,16 +188,34 @@ func (m *mockExpenseRepository) Update(exp *expenseDatamodel.Expense) error {
 	return nil
 }
 
-func (m *mockExpenseRepository) UpdateStatus(id int64, status string, processedAt time.Time) error {
-	if exp, exists := m.expenses[id]; exists {
-		exp.ExpenseStatus = status
-		exp.ProcessedAt = &processedAt
-		exp.UpdatedAt = time.Now()
+func (m *mockExpenseRepository) UpdateStatus(_ context.Context, id int64, from, to string, processedAt time.Time) error {
+	exp, exists := m.expenses[id]
+	if !exists || exp.ExpenseStatus != from {
+		return expense.ErrInvalidExpenseStatus
+	}
+	exp.ExpenseStatus = to
+	exp.ProcessedAt = &processedAt
+	exp.UpdatedAt = time.Now()
+	return nil
+}
+
+func (m *mockExpenseRepository) Approve(_ context.Context, e *expenseDatamodel.Expense, from string) error {
+	if m.beforeApprove != nil {
+		m.beforeApprove()
 	}
+	exp, exists := m.expenses[e.ID]
+	if !exists || exp.ExpenseStatus != from {
+		return expense.ErrInvalidExpenseStatus
+	}
+	exp.ExpenseStatus = e.ExpenseStatus
+	exp.ApprovedBy, exp.ApprovedAt = e.ApprovedBy, e.ApprovedAt
+	exp.ApprovedAmountIDR, exp.AdjustmentReason = e.ApprovedAmountIDR, e.AdjustmentReason
+	exp.ProcessedAt = e.ProcessedAt
+	exp.UpdatedAt = time.Now()
 	return nil
 }
 
-func (m *mockExpenseRepository) CountByUserID(userID int64, params *expense.ExpenseQueryParams) (int64, error) {
+func (m *mockExpenseRepository) CountByUserID(_ context.Context, userID int64, params *expense.ExpenseQueryParams) (int64, error) {
 	if m.getError != nil {
 		return 0, m.getError
 	}
@@ -223,7 +250,7 @@ func (m *mockExpenseRepository) CountByUserID(userID int64, params *expense.Expe
 	return count, nil
 }
 
-func (m *mockExpenseRepository) CountAllExpenses(params *expense.ExpenseQueryParams) (int64, error) {
+func (m *mockExpenseRepository) CountAllExpenses(_ context.Context, params *expense.ExpenseQueryParams) (int64, error) {
 	if m.getError != nil {
 		return 0, m.getError
 	}
@@ -254,12 +281,60 @@ func (m *mockExpenseRepository) CountAllExpenses(params *expense.ExpenseQueryPar
 	return count, nil
 }
 
+func (m *mockExpenseRepository) GetByTeam(_ context.Context, managerID int64, params *expense.ExpenseQueryParams) ([]*expenseDatamodel.Expense, error) {
+	if m.getError != nil {
+		return nil, m.getError
+	}
+	team := append([]*expenseDatamodel.Expense{}, m.expensesByUser[managerID]...)
+	for _, memberID := range m.teamMembers[managerID] {
+		team = append(team, m.expensesByUser[memberID]...)
+	}
+	return team, nil
+}
+
+func (m *mockExpenseRepository) CountByTeam(ctx context.Context, managerID int64, params *expense.ExpenseQueryParams) (int64, error) {
+	team, err := m.GetByTeam(ctx, managerID, params)
+	return int64(len(team)), err
+}
+
+func (m *mockExpenseRepository) IsTeamMember(_ context.Context, managerID, userID int64) (bool, error) {
+	for _, memberID := range m.teamMembers[managerID] {
+		if memberID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockExpenseRepository) UserDepartment(_ context.Context, userID int64) (string, error) {
+	return m.departments[userID], nil
+}
+
+func (m *mockExpenseRepository) ReceiptClaims(_ context.Context, hashes []string) (map[string][]expense.ReceiptClaim, error) {
+	claims := make(map[string][]expense.ReceiptClaim)
+	for _, hash := range hashes {
+		for id, e := range m.expenses {
+			if e.ReceiptSHA256 == nil || *e.ReceiptSHA256 != hash {
+				continue
+			}
+			switch e.ExpenseStatus {
+			case expense.ExpenseStatusDraft, expense.ExpenseStatusRejected, expense.ExpenseStatusCancelled:
+				continue
+			}
+			claims[hash] = append(claims[hash], expense.ReceiptClaim{ExpenseID: id, UserID: e.UserID})
+		}
+		sort.Slice(claims[hash], func(i, j int) bool { return claims[hash][i].ExpenseID < claims[hash][j].ExpenseID })
+	}
+	return claims, nil
+}
+
 type mockPaymentProcessor struct {
 	processPaymentError   error
 	retryPaymentError     error
 	getPaymentStatusError error
 	paymentStatus         interface{}
 	externalID            string
+	retriedExternalIDs    []string
 }
 
 func newMockPaymentProcessor() *mockPaymentProcessor {
@@ -277,6 +352,7 @@ func (m *mockPaymentProcessor) ProcessPayment(expenseID int64, amount int64) (ex
 }
 
 func (m *mockPaymentProcessor) RetryPayment(expenseID int64, externalID string) error {
+	m.retriedExternalIDs = append(m.retriedExternalIDs, externalID)
 	return m.retryPaymentError
 }
 
@@ -287,21 +363,185 @@ func (m *mockPaymentProcessor) GetPaymentStatus(expenseID int64) (interface{}, e
 	return m.paymentStatus, nil
 }
 
+// mockCategoryValidator maps a category name to whether it is a leaf.
+type mockCategoryValidator map[string]bool
+
+func (m mockCategoryValidator) IsValidCategory(_ context.Context, name string) bool {
+	_, ok := m[name]
+	return ok
+}
+
+func (m mockCategoryValidator) IsLeafCategory(_ context.Context, name string) bool {
+	return m[name]
+}
+
+type mockApprovalRouter map[string]*expense.ApprovalRoute
+
+func (m mockApprovalRouter) RouteFor(_ context.Context, category string) (*expense.ApprovalRoute, error) {
+	return m[category], nil
+}
+
+// mockSpendingLimiter fails every check with err, when set.
+type mockSpendingLimiter struct {
+	err      error
+	approved []int64
+	amounts  []int64
+}
+
+func (m *mockSpendingLimiter) CheckNewExpense(_ context.Context, _, _ int64, _ time.Time) error {
+	return m.err
+}
+
+func (m *mockSpendingLimiter) CheckApproval(_ context.Context, userID, amountIDR int64, _ time.Time) error {
+	m.approved = append(m.approved, userID)
+	m.amounts = append(m.amounts, amountIDR)
+	return m.err
+}
+
+// mockPeriodGuard fails expenses dated in a locked month with err.
+type mockPeriodGuard struct {
+	locked map[time.Month]bool
+	err    error
+}
+
+func (m *mockPeriodGuard) CheckExpenseDate(_ context.Context, expenseDate time.Time) error {
+	if m.locked[expenseDate.Month()] {
+		return m.err
+	}
+	return nil
+}
+
+// mockExchangeRates quotes the rates in rates, keyed by currency, on any
+// day, and fails other currencies with err.
+type mockExchangeRates struct {
+	rates map[string]*money.Rate
+	err   error
+}
+
+func (m *mockExchangeRates) RateOn(_ context.Context, currency string, _ time.Time) (*money.Rate, error) {
+	if rate, ok := m.rates[currency]; ok {
+		return rate, nil
+	}
+	return nil, m.err
+}
+
+// mockCannedResponses serves the responses in responses and reports the
+// rest as not found.
+type mockCannedResponses struct {
+	responses map[int64]*expense.CannedResponse
+}
+
+func (m *mockCannedResponses) CannedResponse(_ context.Context, id int64) (*expense.CannedResponse, error) {
+	if response, ok := m.responses[id]; ok {
+		return response, nil
+	}
+	return nil, internal.NewNotFoundError("Canned response not found", internal.ErrCodeCannedResponseNotFound)
+}
+
+// mockPayoutAccounts accepts the accounts in owned, keyed by account ID to
+// owner, and fails the rest with err.
+type mockPayoutAccounts struct {
+	owned map[int64]int64
+	err   error
+}
+
+func (m *mockPayoutAccounts) CheckPayoutAccount(_ context.Context, userID, accountID int64) error {
+	if m.owned[accountID] == userID {
+		return nil
+	}
+	return m.err
+}
+
+// mockDelegations lets the delegates in grants, keyed by principal, file
+// for their principal and records the filings made.
+type mockDelegations struct {
+	grants  map[int64]int64
+	filings [][3]int64
+}
+
+func (m *mockDelegations) CheckDelegation(_ context.Context, principalID, delegateID int64) error {
+	if delegate, ok := m.grants[principalID]; ok && delegate == delegateID {
+		return nil
+	}
+	return internal.NewForbiddenError("You may not file expenses for this user", internal.ErrCodeNotDelegate)
+}
+
+func (m *mockDelegations) RecordFiling(_ context.Context, expenseID, principalID, delegateID int64) {
+	m.filings = append(m.filings, [3]int64{expenseID, principalID, delegateID})
+}
+
+type mockTenantPolicy struct {
+	threshold     int64
+	chain         []string
+	receiptsAbove int64
+	maxPending    int64
+	pendingByDept map[string]int64
+	amountLimits  map[string]expense.AmountLimits
+}
+
+func (m *mockTenantPolicy) AutoApprovalThreshold(_ context.Context) int64 {
+	return m.threshold
+}
+
+func (m *mockTenantPolicy) ApprovalChain(_ context.Context) []string {
+	return m.chain
+}
+
+func (m *mockTenantPolicy) PendingExpenseLimit(_ context.Context, department string) int64 {
+	if limit, ok := m.pendingByDept[department]; ok {
+		return limit
+	}
+	return m.maxPending
+}
+
+func (m *mockTenantPolicy) ReceiptRequiredAbove(_ context.Context) int64 {
+	return m.receiptsAbove
+}
+
+func (m *mockTenantPolicy) ExpenseAmountLimits(_ context.Context, category string) (int64, int64) {
+	if limits, ok := m.amountLimits[category]; ok {
+		return limits.Min, limits.Max
+	}
+	return expense.MinExpenseAmount, expense.MaxExpenseAmount
+}
+
 var _ = Describe("ExpenseService", func() {
 	var (
-		expenseService *expense.Service
+		expenseService *expense.CommandService
+		queryService   *expense.QueryService
 		mockRepo       *mockExpenseRepository
 		mockProcessor  *mockPaymentProcessor
+		routes         mockApprovalRouter
+		limits         *mockSpendingLimiter
+		payoutAccounts *mockPayoutAccounts
+		delegations    *mockDelegations
+		periods        *mockPeriodGuard
+		policy         *mockTenantPolicy
 		logger         *slog.Logger
+		eventBus       *events.EventBus
 	)
 
 	BeforeEach(func() {
 		mockRepo = newMockExpenseRepository()
 		mockProcessor = newMockPaymentProcessor()
 		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-		eventBus := events.NewEventBus(logger)
+		eventBus = events.NewEventBus(logger)
 		permissionChecker := auth.NewPermissionChecker()
-		expenseService = expense.NewService(mockRepo, mockProcessor, permissionChecker, eventBus, logger)
+		categories := mockCategoryValidator{"food": true, "transport": true, "travel": false, "it_equipment": true}
+		routes = mockApprovalRouter{}
+		limits = &mockSpendingLimiter{}
+		payoutAccounts = &mockPayoutAccounts{
+			owned: map[int64]int64{7: 123},
+			err:   internal.NewNotFoundError("Bank account not found", internal.ErrCodeBankAccountNotFound),
+		}
+		delegations = &mockDelegations{grants: map[int64]int64{123: 456}}
+		periods = &mockPeriodGuard{
+			locked: map[time.Month]bool{},
+			err:    internal.NewValidationError("expenses dated in 2026-03 can no longer be created or changed: the period is locked", internal.ErrCodePeriodLocked),
+		}
+		policy = &mockTenantPolicy{threshold: expense.AutoApprovalThreshold}
+		expenseService = expense.NewCommandService(mockRepo, mockProcessor, categories, routes, limits, periods, payoutAccounts, nil, nil, delegations, policy, permissionChecker, expense.DefaultStateMachine(), eventBus, logger)
+		queryService = expense.NewQueryService(mockRepo, policy, permissionChecker, logger)
 	})
 
 	Describe("CreateExpense", func() {
@@ -316,7 +556,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(result).ToNot(BeNil())
@@ -338,7 +578,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(result.ExpenseStatus).To(Equal(expense.ExpenseStatusApproved))
@@ -346,6 +586,111 @@ var _ = Describe("ExpenseService", func() {
 			})
 		})
 
+		Context("when the amount is sent as money", func() {
+			It("should book rupiah amounts", func() {
+				amount := money.Rupiah(25000)
+				dto := expense.CreateExpenseDTO{
+					Amount:      &amount,
+					Description: "Test expense",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.AmountIDR).To(Equal(int64(25000)))
+				Expect(result.Amount()).To(Equal(amount))
+			})
+
+			It("should refuse other currencies", func() {
+				amount := money.New(2500, "USD")
+				dto := expense.CreateExpenseDTO{
+					Amount:      &amount,
+					Description: "Test expense",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+
+				_, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).To(MatchError(ContainSubstring("currency must be IDR")))
+			})
+
+			Context("and exchange rates are enabled", func() {
+				var rateDate time.Time
+
+				BeforeEach(func() {
+					rateDate = time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+					rates := &mockExchangeRates{
+						rates: map[string]*money.Rate{
+							"USD": {From: "USD", To: money.IDR, Value: "16250.25", Source: "bank-indonesia", Date: rateDate, QuotedAt: rateDate.Add(9 * time.Hour)},
+						},
+						err: internal.NewValidationError("no EUR exchange rate is set", internal.ErrCodeExchangeRateUnavailable),
+					}
+					categories := mockCategoryValidator{"food": true}
+					expenseService = expense.NewCommandService(mockRepo, mockProcessor, categories, routes, limits, periods, payoutAccounts, rates, nil, nil, policy, auth.NewPermissionChecker(), expense.DefaultStateMachine(), events.NewEventBus(logger), logger)
+				})
+
+				It("should convert at the rate of the expense date and keep the rate on the expense", func() {
+					amount := money.New(1250, "USD")
+					dto := expense.CreateExpenseDTO{
+						Amount:      &amount,
+						Description: "Conference lunch",
+						Category:    "food",
+						ExpenseDate: time.Now(),
+					}
+
+					result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.AmountIDR).To(Equal(int64(203128)))
+					Expect(result.ExchangeRate).ToNot(BeNil())
+					Expect(result.ExchangeRate.OriginalAmount).To(Equal(amount))
+					Expect(result.ExchangeRate.Rate).To(Equal("16250.25"))
+					Expect(result.ExchangeRate.Source).To(Equal("bank-indonesia"))
+					Expect(result.ExchangeRate.RateDate).To(Equal(rateDate))
+
+					stored := mockRepo.expenses[result.ID]
+					Expect(*stored.OriginalCurrency).To(Equal("USD"))
+					Expect(*stored.ExchangeRate).To(Equal("16250.25"))
+					Expect(expense.FromDataModel(stored).ExchangeRate).To(Equal(result.ExchangeRate))
+				})
+
+				It("should refuse a currency without a rate", func() {
+					amount := money.New(1250, "EUR")
+					dto := expense.CreateExpenseDTO{
+						Amount:      &amount,
+						Description: "Conference lunch",
+						Category:    "food",
+						ExpenseDate: time.Now(),
+					}
+
+					_, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+					appErr, ok := internal.IsAppError(err)
+					Expect(ok).To(BeTrue())
+					Expect(appErr.Code).To(Equal(internal.ErrCodeExchangeRateUnavailable))
+				})
+
+				It("should refuse amount_idr sent alongside another currency", func() {
+					amount := money.New(1250, "USD")
+					dto := expense.CreateExpenseDTO{
+						AmountIDR:   200000,
+						Amount:      &amount,
+						Description: "Conference lunch",
+						Category:    "food",
+						ExpenseDate: time.Now(),
+					}
+
+					_, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+					Expect(err).To(HaveOccurred())
+					Expect(mockRepo.expenses).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("when creating a large expense (requires approval)", func() {
 			It("should create expense with pending approval status", func() {
 
@@ -357,7 +702,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(result).ToNot(BeNil())
@@ -377,7 +722,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("description"))
@@ -394,7 +739,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("amount must be positive"))
@@ -411,7 +756,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("amount must be at least 10,000 IDR"))
@@ -428,7 +773,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("amount must not exceed 50,000,000 IDR"))
@@ -436,6 +781,43 @@ var _ = Describe("ExpenseService", func() {
 			})
 		})
 
+		Context("when the category does not exist", func() {
+			It("should reject the expense with INVALID_CATEGORY", func() {
+
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Test expense",
+					Category:    "fod",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(result).To(BeNil())
+				Expect(err).To(MatchError(expense.ErrInvalidCategory))
+				Expect(err.Error()).To(Equal("category does not exist"))
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+		})
+
+		Context("when the category has subcategories", func() {
+			It("should reject the expense and ask for a subcategory", func() {
+
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Test expense",
+					Category:    "travel",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(result).To(BeNil())
+				Expect(err).To(MatchError(expense.ErrCategoryNotLeaf))
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+		})
+
 		Context("when repository fails", func() {
 			It("should return repository error", func() {
 
@@ -448,7 +830,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("database error"))
@@ -456,6 +838,352 @@ var _ = Describe("ExpenseService", func() {
 			})
 		})
 
+		Context("when the category has an approval routing rule", func() {
+			It("should keep small expenses pending when the rule requires approval", func() {
+
+				routes["it_equipment"] = &expense.ApprovalRoute{ApproverPermission: "approve_it", RequireApproval: true}
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Keyboard",
+					Category:    "it_equipment",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+
+			It("should still auto-approve when the rule only names the approver", func() {
+
+				routes["it_equipment"] = &expense.ApprovalRoute{ApproverPermission: "approve_it"}
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Keyboard",
+					Category:    "it_equipment",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ExpenseStatus).To(Equal(expense.ExpenseStatusApproved))
+			})
+		})
+
+		Context("when the tenant overrides the auto-approval threshold", func() {
+			It("should keep expenses at or above the tenant threshold pending", func() {
+				policy.threshold = 20000
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Lunch",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+
+			It("should never auto-approve when the threshold is zero", func() {
+				policy.threshold = 0
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   10000,
+					Description: "Parking",
+					Category:    "transport",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+		})
+
+		Context("when the tenant limits claim amounts by category", func() {
+			BeforeEach(func() {
+				policy.amountLimits = map[string]expense.AmountLimits{"food": {Min: 1000, Max: 200000}}
+			})
+
+			newDTO := func(amount int64, category string) *expense.CreateExpenseDTO {
+				return &expense.CreateExpenseDTO{
+					AmountIDR:   amount,
+					Description: "Lunch",
+					Category:    category,
+					ExpenseDate: time.Now(),
+				}
+			}
+
+			It("should accept amounts below the default minimum within the category's", func() {
+				result, err := expenseService.CreateExpense(context.Background(), newDTO(5000, "food"), 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.AmountIDR).To(Equal(int64(5000)))
+			})
+
+			It("should reject amounts above the category's maximum with AMOUNT_TOO_HIGH", func() {
+				result, err := expenseService.CreateExpense(context.Background(), newDTO(250000, "food"), 123)
+
+				Expect(result).To(BeNil())
+				appErr, ok := internal.IsAppError(err)
+				Expect(ok).To(BeTrue())
+				fieldErr := appErr.Details.(internal.ValidationErrors).Errors[0]
+				Expect(fieldErr.Field).To(Equal("amount_idr"))
+				Expect(fieldErr.Code).To(Equal(string(internal.ErrCodeAmountTooHigh)))
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+
+			It("should keep the default limits for other categories", func() {
+				_, err := expenseService.CreateExpense(context.Background(), newDTO(5000, "travel"), 123)
+
+				appErr, ok := internal.IsAppError(err)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Details.(internal.ValidationErrors).Errors[0].Code).To(Equal(string(internal.ErrCodeAmountTooLow)))
+			})
+		})
+
+		Context("when the tenant requires receipts above an amount", func() {
+			BeforeEach(func() {
+				policy.receiptsAbove = 500000
+			})
+
+			It("should reject expenses above it without a receipt_url", func() {
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   750000,
+					Description: "Hotel",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(result).To(BeNil())
+				appErr, ok := internal.IsAppError(err)
+				Expect(ok).To(BeTrue())
+				fieldErr := appErr.Details.(internal.ValidationErrors).Errors[0]
+				Expect(fieldErr.Field).To(Equal("receipt_url"))
+				Expect(fieldErr.Code).To(Equal(string(internal.ErrCodeReceiptRequired)))
+				Expect(fieldErr.Message).To(Equal("receipt_url is required for expenses above 500,000 IDR"))
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+
+			It("should accept expenses above it with a receipt_url", func() {
+				receipt := "https://example.com/hotel.pdf"
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   750000,
+					Description: "Hotel",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+					ReceiptURL:  &receipt,
+				}
+
+				_, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should accept expenses at the amount without a receipt", func() {
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   500000,
+					Description: "Taxi",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+
+				_, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the expense is dated in a locked month", func() {
+			It("should reject it with PERIOD_LOCKED and store nothing", func() {
+				expenseDate := time.Now().AddDate(0, -1, 0)
+				periods.locked[expenseDate.Month()] = true
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Dinner",
+					Category:    "food",
+					ExpenseDate: expenseDate,
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).To(MatchError(periods.err))
+				Expect(result).To(BeNil())
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+		})
+
+		Context("when the expense would exceed a spending limit", func() {
+			It("should reject it with LIMIT_EXCEEDED and store nothing", func() {
+				limits.err = internal.NewValidationError("daily spending limit of 100000 IDR exceeded", internal.ErrCodeLimitExceeded)
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Dinner",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).To(MatchError(limits.err))
+				Expect(result).To(BeNil())
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+		})
+
+		Context("when the tenant caps pending expenses", func() {
+			submit := func(userID int64) (*expense.Expense, error) {
+				dto := expense.CreateExpenseDTO{
+					AmountIDR:   2000000,
+					Description: "Conference ticket",
+					Category:    "food",
+					ExpenseDate: time.Now(),
+				}
+				return expenseService.CreateExpense(context.Background(), &dto, userID)
+			}
+
+			BeforeEach(func() {
+				policy.maxPending = 2
+			})
+
+			It("should reject expenses past the cap with PENDING_LIMIT_EXCEEDED", func() {
+				for i := 0; i < 2; i++ {
+					_, err := submit(123)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				result, err := submit(123)
+
+				Expect(result).To(BeNil())
+				appErr, ok := internal.IsAppError(err)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Code).To(Equal(internal.ErrCodePendingLimitExceeded))
+				Expect(appErr.Details).To(Equal(expense.PendingLimitExceeded{Limit: 2, Pending: 2}))
+				Expect(mockRepo.expenses).To(HaveLen(2))
+			})
+
+			It("should not count auto-approved expenses", func() {
+				for i := 0; i < 3; i++ {
+					dto := expense.CreateExpenseDTO{
+						AmountIDR:   25000,
+						Description: "Lunch",
+						Category:    "food",
+						ExpenseDate: time.Now(),
+					}
+					_, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				_, err := submit(123)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should apply the department's cap instead", func() {
+				mockRepo.departments[123] = "sales"
+				mockRepo.departments[456] = "finance"
+				policy.pendingByDept = map[string]int64{"sales": 1, "finance": 0}
+
+				_, err := submit(123)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = submit(123)
+				Expect(err).To(HaveOccurred())
+
+				for i := 0; i < 3; i++ {
+					_, err := submit(456)
+					Expect(err).NotTo(HaveOccurred())
+				}
+			})
+		})
+
+		Context("when a payout account is picked", func() {
+			var dto expense.CreateExpenseDTO
+
+			BeforeEach(func() {
+				dto = expense.CreateExpenseDTO{
+					AmountIDR:   25000,
+					Description: "Taxi",
+					Category:    "transport",
+					ExpenseDate: time.Now(),
+				}
+			})
+
+			It("should store the user's own account on the expense", func() {
+				accountID := int64(7)
+				dto.PayoutAccountID = &accountID
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.PayoutAccountID).To(Equal(&accountID))
+				Expect(mockRepo.expenses[result.ID].PayoutAccountID).To(Equal(&accountID))
+			})
+
+			It("should refuse another user's account", func() {
+				accountID := int64(7)
+				dto.PayoutAccountID = &accountID
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 456)
+
+				Expect(err).To(MatchError(payoutAccounts.err))
+				Expect(result).To(BeNil())
+				Expect(mockRepo.expenses).To(BeEmpty())
+			})
+		})
+
+		Context("when filed for another user", func() {
+			var dto expense.CreateExpenseDTO
+
+			BeforeEach(func() {
+				principalID := int64(123)
+				dto = expense.CreateExpenseDTO{
+					AmountIDR:          25000,
+					Description:        "Client dinner",
+					Category:           "food",
+					ExpenseDate:        time.Now(),
+					SubmittedForUserID: &principalID,
+				}
+			})
+
+			It("should give the expense to the principal and record the delegate", func() {
+				accountID := int64(7)
+				dto.PayoutAccountID = &accountID
+
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 456)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.UserID).To(Equal(int64(123)))
+				Expect(*result.SubmittedBy).To(Equal(int64(456)))
+				Expect(*mockRepo.expenses[result.ID].SubmittedBy).To(Equal(int64(456)))
+				Expect(delegations.filings).To(Equal([][3]int64{{result.ID, 123, 456}}))
+			})
+
+			It("should refuse a user without delegation", func() {
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 789)
+
+				appErr, ok := err.(*internal.AppError)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Code).To(Equal(internal.ErrCodeNotDelegate))
+				Expect(result).To(BeNil())
+				Expect(mockRepo.expenses).To(BeEmpty())
+				Expect(delegations.filings).To(BeEmpty())
+			})
+
+			It("should treat filing for oneself as an ordinary expense", func() {
+				result, err := expenseService.CreateExpense(context.Background(), &dto, 123)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.UserID).To(Equal(int64(123)))
+				Expect(result.SubmittedBy).To(BeNil())
+				Expect(delegations.filings).To(BeEmpty())
+			})
+		})
+
 		Context("when payment processing fails", func() {
 			It("should still create the expense but log payment error", func() {
 
@@ -468,7 +1196,7 @@ var _ = Describe("ExpenseService", func() {
 					ExpenseDate: time.Now(),
 				}
 
-				result, err := expenseService.CreateExpense(&dto, userID)
+				result, err := expenseService.CreateExpense(context.Background(), &dto, userID)
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(result).ToNot(BeNil())
@@ -495,12 +1223,151 @@ var _ = Describe("ExpenseService", func() {
 				managerID := int64(456)
 				permissions := []string{"approve_expenses"}
 
-				err := expenseService.ApproveExpense(1, managerID, permissions)
+				err := expenseService.ApproveExpense(context.Background(), 1, managerID, permissions)
 
 				Expect(err).ToNot(HaveOccurred())
 
-				updatedExpense, _ := mockRepo.GetByID(1)
+				updatedExpense, _ := mockRepo.GetByID(context.Background(), 1)
 				Expect(updatedExpense.ExpenseStatus).To(Equal(expense.ExpenseStatusApproved))
+				Expect(updatedExpense.ApprovedBy).To(Equal(&managerID))
+				Expect(updatedExpense.RejectedBy).To(BeNil())
+			})
+		})
+
+		Context("when the category routes approval to a specific permission", func() {
+			BeforeEach(func() {
+				routes["it_equipment"] = &expense.ApprovalRoute{ApproverPermission: "approve_it", RequireApproval: true}
+				mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+					ID:            1,
+					UserID:        123,
+					AmountIDR:     75000,
+					Category:      "it_equipment",
+					ExpenseStatus: expense.ExpenseStatusPendingApproval,
+				})
+			})
+
+			It("should deny approvers without the routed permission", func() {
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrUnauthorizedAccess))
+				Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+
+			It("should deny rejections without the routed permission", func() {
+				err := expenseService.RejectExpense(context.Background(), 1, 456, expense.RejectionOther, "no", []string{"reject_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrUnauthorizedAccess))
+			})
+
+			It("should allow holders of the routed permission", func() {
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses", "approve_it"})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusApproved))
+			})
+		})
+
+		Context("when the tenant has an approval chain", func() {
+			BeforeEach(func() {
+				policy.chain = []string{"approve_finance"}
+				mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+					ID:            1,
+					UserID:        123,
+					AmountIDR:     75000,
+					Category:      "food",
+					ExpenseStatus: expense.ExpenseStatusPendingApproval,
+				})
+			})
+
+			It("should deny approvers outside the chain", func() {
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrUnauthorizedAccess))
+			})
+
+			It("should allow approvers in the chain", func() {
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses", "approve_finance"})
+
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should let a category routing rule take precedence", func() {
+				routes["food"] = &expense.ApprovalRoute{ApproverPermission: "approve_food"}
+
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses", "approve_food"})
+
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when approval would exceed the owner's spending limit", func() {
+			It("should leave the expense pending", func() {
+				limits.err = internal.NewValidationError("monthly spending limit of 100000 IDR exceeded", internal.ErrCodeLimitExceeded)
+				mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+					ID:            1,
+					UserID:        123,
+					AmountIDR:     75000,
+					Category:      "food",
+					ExpenseStatus: expense.ExpenseStatusPendingApproval,
+				})
+
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses"})
+
+				Expect(err).To(MatchError(limits.err))
+				Expect(limits.approved).To(Equal([]int64{123}))
+				Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+		})
+
+		Context("when the expense needs a receipt it does not have", func() {
+			It("should refuse approval with RECEIPT_REQUIRED", func() {
+				policy.receiptsAbove = 50000
+				mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+					ID:            1,
+					UserID:        123,
+					AmountIDR:     75000,
+					Category:      "food",
+					ExpenseStatus: expense.ExpenseStatusPendingApproval,
+				})
+
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrReceiptRequired))
+				Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+		})
+
+		Context("when the approver owns or filed the expense", func() {
+			BeforeEach(func() {
+				delegate := int64(456)
+				mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+					ID:            1,
+					UserID:        123,
+					SubmittedBy:   &delegate,
+					AmountIDR:     75000,
+					ExpenseStatus: expense.ExpenseStatusPendingApproval,
+				})
+			})
+
+			It("should refuse the owner", func() {
+				err := expenseService.ApproveExpense(context.Background(), 1, 123, []string{"approve_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrOwnExpenseDecision))
+				Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+
+			It("should refuse the delegate who filed it", func() {
+				err := expenseService.ApproveExpense(context.Background(), 1, 456, []string{"approve_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrOwnExpenseDecision))
+				Expect(mockRepo.expenses[1].ApprovedBy).To(BeNil())
+			})
+
+			It("should refuse a rejection by the delegate too", func() {
+				err := expenseService.RejectExpense(context.Background(), 1, 456, expense.RejectionMissingReceipt, "", []string{"reject_expenses"})
+
+				Expect(err).To(MatchError(expense.ErrOwnExpenseDecision))
+				Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
 			})
 		})
 
@@ -511,7 +1378,7 @@ var _ = Describe("ExpenseService", func() {
 				managerID := int64(456)
 				permissions := []string{"approve_expenses"}
 
-				err := expenseService.ApproveExpense(expenseID, managerID, permissions)
+				err := expenseService.ApproveExpense(context.Background(), expenseID, managerID, permissions)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("not found"))
@@ -533,7 +1400,7 @@ var _ = Describe("ExpenseService", func() {
 				managerID := int64(456)
 				permissions := []string{"approve_expenses"}
 
-				err := expenseService.ApproveExpense(1, managerID, permissions)
+				err := expenseService.ApproveExpense(context.Background(), 1, managerID, permissions)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("invalid expense status"))
@@ -541,6 +1408,87 @@ var _ = Describe("ExpenseService", func() {
 		})
 	})
 
+	Describe("ApproveExpenseAdjusted", func() {
+		var approved chan *events.ExpenseApprovedEvent
+
+		BeforeEach(func() {
+			// A local channel, so a late delivery from an earlier spec's bus
+			// cannot land in this spec's.
+			ch := make(chan *events.ExpenseApprovedEvent, 1)
+			approved = ch
+			eventBus.Subscribe(events.EventTypeExpenseApproved, func(_ context.Context, event events.Event) error {
+				ch <- event.(*events.ExpenseApprovedEvent)
+				return nil
+			})
+			mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+				ID:            1,
+				UserID:        123,
+				AmountIDR:     200000,
+				Category:      "food",
+				ExpenseStatus: expense.ExpenseStatusPendingApproval,
+			})
+		})
+
+		It("should approve and pay the reduced amount, keeping the claim", func() {
+			err := expenseService.ApproveExpenseAdjusted(context.Background(), 1, 456, 150000, " Alcohol is not reimbursed ", []string{"approve_expenses"})
+
+			Expect(err).ToNot(HaveOccurred())
+			stored := expense.FromDataModel(mockRepo.expenses[1])
+			Expect(stored.ExpenseStatus).To(Equal(expense.ExpenseStatusApproved))
+			Expect(stored.AmountIDR).To(Equal(int64(200000)))
+			Expect(stored.ApprovedAmountIDR).To(HaveValue(Equal(int64(150000))))
+			Expect(stored.AdjustmentReason).To(HaveValue(Equal("Alcohol is not reimbursed")))
+			Expect(stored.PayableAmountIDR()).To(Equal(int64(150000)))
+			Expect(limits.amounts).To(Equal([]int64{150000}))
+
+			var event *events.ExpenseApprovedEvent
+			Eventually(approved).Should(Receive(&event))
+			Expect(event.Amount).To(Equal(int64(150000)))
+		})
+
+		It("should retry a failed payment of the reduced amount", func() {
+			Expect(expenseService.ApproveExpenseAdjusted(context.Background(), 1, 456, 150000, "Alcohol is not reimbursed", []string{"approve_expenses"})).To(Succeed())
+			mockRepo.expenses[1].ExpenseStatus = expense.ExpenseStatusPaymentFailed
+
+			err := expenseService.RetryPayment(context.Background(), 1, []string{"retry_payments"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockProcessor.retriedExternalIDs).To(Equal([]string{"exp-1-150000"}))
+		})
+
+		It("should not pay when another decision lands first", func() {
+			mockRepo.beforeApprove = func() {
+				mockRepo.expenses[1].ExpenseStatus = expense.ExpenseStatusRejected
+			}
+
+			err := expenseService.ApproveExpenseAdjusted(context.Background(), 1, 456, 150000, "Alcohol is not reimbursed", []string{"approve_expenses"})
+
+			Expect(err).To(MatchError(expense.ErrInvalidExpenseStatus))
+			Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusRejected))
+			Expect(mockRepo.expenses[1].ApprovedAmountIDR).To(BeNil())
+			Consistently(approved).ShouldNot(Receive())
+		})
+
+		It("should refuse more than was claimed", func() {
+			err := expenseService.ApproveExpenseAdjusted(context.Background(), 1, 456, 250000, "Tip included", []string{"approve_expenses"})
+
+			appErr, ok := internal.IsAppError(err)
+			Expect(ok).To(BeTrue())
+			details := appErr.Details.(internal.ValidationErrors)
+			Expect(details.Errors[0].Code).To(Equal(string(internal.ErrCodeInvalidAmount)))
+			Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+		})
+
+		It("should treat the full amount as a plain approval", func() {
+			err := expenseService.ApproveExpenseAdjusted(context.Background(), 1, 456, 200000, "All fine", []string{"approve_expenses"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusApproved))
+			Expect(mockRepo.expenses[1].ApprovedAmountIDR).To(BeNil())
+			Expect(mockRepo.expenses[1].AdjustmentReason).To(BeNil())
+		})
+	})
+
 	Describe("RejectExpense", func() {
 		Context("when rejecting a pending expense", func() {
 			It("should reject the expense successfully", func() {
@@ -558,12 +1506,60 @@ var _ = Describe("ExpenseService", func() {
 				reason := "Insufficient documentation"
 				permissions := []string{"reject_expenses"}
 
-				err := expenseService.RejectExpense(1, managerID, reason, permissions)
+				err := expenseService.RejectExpense(context.Background(), 1, managerID, expense.RejectionMissingReceipt, reason, permissions)
 
 				Expect(err).ToNot(HaveOccurred())
 
-				updatedExpense, _ := mockRepo.GetByID(1)
+				updatedExpense, _ := mockRepo.GetByID(context.Background(), 1)
+				Expect(updatedExpense.ExpenseStatus).To(Equal(expense.ExpenseStatusRejected))
+				Expect(*updatedExpense.RejectionCode).To(Equal(expense.RejectionMissingReceipt))
+				Expect(*updatedExpense.RejectionReason).To(Equal(reason))
+				Expect(updatedExpense.RejectedBy).To(Equal(&managerID))
+			})
+		})
+
+		Context("when rejecting with a canned response", func() {
+			permissions := []string{"reject_expenses"}
+
+			BeforeEach(func() {
+				responses := &mockCannedResponses{responses: map[int64]*expense.CannedResponse{
+					7: {ID: 7, Code: expense.RejectionMissingReceipt, Body: "Please attach an itemised receipt."},
+				}}
+				expenseService = expense.NewCommandService(mockRepo, mockProcessor, mockCategoryValidator{}, routes, limits, periods, payoutAccounts, nil, responses, nil, policy, auth.NewPermissionChecker(), expense.DefaultStateMachine(), events.NewEventBus(logger), logger)
+
+				mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+					ID:            1,
+					UserID:        123,
+					AmountIDR:     75000,
+					ExpenseStatus: expense.ExpenseStatusPendingApproval,
+				})
+			})
+
+			It("should take the code and comment from the response and record it", func() {
+				err := expenseService.RejectWithCannedResponse(context.Background(), 1, 456, 7, "", "  The taxi receipt is missing.  ", permissions)
+
+				Expect(err).ToNot(HaveOccurred())
+				updatedExpense, _ := mockRepo.GetByID(context.Background(), 1)
 				Expect(updatedExpense.ExpenseStatus).To(Equal(expense.ExpenseStatusRejected))
+				Expect(*updatedExpense.RejectionCode).To(Equal(expense.RejectionMissingReceipt))
+				Expect(*updatedExpense.RejectionReason).To(Equal("Please attach an itemised receipt.\n\nThe taxi receipt is missing."))
+				Expect(*updatedExpense.CannedResponseID).To(Equal(int64(7)))
+			})
+
+			It("should refuse a code that contradicts the response", func() {
+				err := expenseService.RejectWithCannedResponse(context.Background(), 1, 456, 7, expense.RejectionDuplicate, "", permissions)
+
+				Expect(err).To(HaveOccurred())
+				updatedExpense, _ := mockRepo.GetByID(context.Background(), 1)
+				Expect(updatedExpense.ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+			})
+
+			It("should report an unknown response", func() {
+				err := expenseService.RejectWithCannedResponse(context.Background(), 1, 456, 99, "", "", permissions)
+
+				appErr, ok := internal.IsAppError(err)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Code).To(Equal(internal.ErrCodeCannedResponseNotFound))
 			})
 		})
 	})
@@ -593,7 +1589,7 @@ var _ = Describe("ExpenseService", func() {
 					PerPage: 10,
 					Page:    1,
 				}
-				result, err := expenseService.GetAllExpenses(params)
+				result, err := queryService.GetAllExpenses(context.Background(), params)
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(result).To(HaveLen(2))
@@ -603,6 +1599,120 @@ var _ = Describe("ExpenseService", func() {
 		})
 	})
 
+	Describe("GetExpenseByID", func() {
+		BeforeEach(func() {
+			mockRepo.expenses[1] = expense.ToDataModel(&expense.Expense{
+				ID:            1,
+				UserID:        123,
+				AmountIDR:     75000,
+				ExpenseStatus: expense.ExpenseStatusPendingApproval,
+			})
+			mockRepo.teamMembers[456] = []int64{123}
+		})
+
+		It("should allow the owner", func() {
+			result, err := queryService.GetExpenseByID(context.Background(), 1, 123, []string{})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ID).To(Equal(int64(1)))
+		})
+
+		It("should allow a team manager for expenses of their team", func() {
+			result, err := queryService.GetExpenseByID(context.Background(), 1, 456, []string{"view_team_expenses"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ID).To(Equal(int64(1)))
+		})
+
+		It("should deny a team manager for expenses outside their team", func() {
+			_, err := queryService.GetExpenseByID(context.Background(), 1, 789, []string{"approve_expenses"})
+
+			Expect(err).To(Equal(expense.ErrUnauthorizedAccess))
+		})
+
+		It("should allow users that can view all expenses", func() {
+			result, err := queryService.GetExpenseByID(context.Background(), 1, 789, []string{"view_all_expenses"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ID).To(Equal(int64(1)))
+		})
+
+		It("should deny other regular users", func() {
+			_, err := queryService.GetExpenseByID(context.Background(), 1, 789, []string{"view_expenses"})
+
+			Expect(err).To(Equal(expense.ErrUnauthorizedAccess))
+		})
+
+		It("should flag a pending expense missing a required receipt", func() {
+			policy.receiptsAbove = 50000
+
+			result, err := queryService.GetExpenseByID(context.Background(), 1, 123, []string{})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ReceiptMissing).To(BeTrue())
+		})
+
+		It("should not flag it when receipts are optional", func() {
+			result, err := queryService.GetExpenseByID(context.Background(), 1, 123, []string{})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ReceiptMissing).To(BeFalse())
+		})
+
+		Context("when its receipt was claimed before", func() {
+			BeforeEach(func() {
+				hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+				mockRepo.expenses[1].ReceiptSHA256 = &hash
+				mockRepo.expenses[2] = expense.ToDataModel(&expense.Expense{ID: 2, UserID: 456, ExpenseStatus: expense.ExpenseStatusCompleted, ReceiptSHA256: &hash})
+				mockRepo.expenses[3] = expense.ToDataModel(&expense.Expense{ID: 3, UserID: 123, ExpenseStatus: expense.ExpenseStatusRejected, ReceiptSHA256: &hash})
+				mockRepo.expenses[4] = expense.ToDataModel(&expense.Expense{ID: 4, UserID: 789, ExpenseStatus: expense.ExpenseStatusApproved, ReceiptSHA256: &hash})
+			})
+
+			It("should list the other expenses claimed with it that the team manager can see", func() {
+				result, err := queryService.GetExpenseByID(context.Background(), 1, 456, []string{"view_team_expenses"})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.DuplicateReceiptOf).To(Equal([]int64{2}))
+			})
+
+			It("should list every other claim for users that can view all expenses", func() {
+				result, err := queryService.GetExpenseByID(context.Background(), 1, 999, []string{"view_all_expenses"})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.DuplicateReceiptOf).To(Equal([]int64{2, 4}))
+			})
+
+			It("should not flag it for the owner", func() {
+				result, err := queryService.GetExpenseByID(context.Background(), 1, 123, []string{})
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.DuplicateReceiptOf).To(BeNil())
+			})
+		})
+	})
+
+	Describe("GetExpensesForUser", func() {
+		It("should scope team managers to their team's expenses", func() {
+			for _, e := range []*expense.Expense{
+				{UserID: 123, AmountIDR: 20000, ExpenseStatus: expense.ExpenseStatusPendingApproval},
+				{UserID: 456, AmountIDR: 30000, ExpenseStatus: expense.ExpenseStatusPendingApproval},
+				{UserID: 789, AmountIDR: 40000, ExpenseStatus: expense.ExpenseStatusPendingApproval},
+			} {
+				Expect(mockRepo.Create(context.Background(), expense.ToDataModel(e))).To(Succeed())
+			}
+			mockRepo.teamMembers[456] = []int64{123}
+
+			params := &expense.ExpenseQueryParams{PerPage: 10, Page: 1}
+			result, err := queryService.GetExpensesForUser(context.Background(), 456, []string{"view_team_expenses"}, params)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveLen(2))
+			for _, e := range result {
+				Expect(e.UserID).To(BeElementOf(int64(123), int64(456)))
+			}
+		})
+	})
+
 	Describe("RetryPayment", func() {
 		Context("when retrying payment for an approved expense", func() {
 			It("should call payment processor retry", func() {
@@ -619,7 +1729,7 @@ var _ = Describe("ExpenseService", func() {
 				mockRepo.expenses[123] = expense.ToDataModel(testExpense)
 				permissions := []string{"retry_payments"}
 
-				err := expenseService.RetryPayment(expenseID, permissions)
+				err := expenseService.RetryPayment(context.Background(), expenseID, permissions)
 
 				Expect(err).ToNot(HaveOccurred())
 			})
@@ -631,7 +1741,7 @@ var _ = Describe("ExpenseService", func() {
 				expenseID := int64(123)
 				permissions := []string{"some:other:permission"}
 
-				err := expenseService.RetryPayment(expenseID, permissions)
+				err := expenseService.RetryPayment(context.Background(), expenseID, permissions)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unauthorized"))
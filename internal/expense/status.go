@@ -0,0 +1,149 @@
+package expense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+)
+
+// Transition is one move between expense statuses that a StateMachine
+// allows.
+type Transition struct {
+	From string
+	To   string
+}
+
+// DefaultTransitions is the expense lifecycle:
+//
+//	draft → pending_approval → approved → processing_payment → completed
+//	                        ↘ rejected   ↘ payment_failed ⇄ processing_payment
+//	draft, pending_approval → cancelled
+//
+// An approved expense is paid at once, so it may complete or fail without
+// passing through processing_payment; that status marks a retry in flight.
+// A failed payment may still complete when the gateway settles it late.
+var DefaultTransitions = []Transition{
+	{ExpenseStatusDraft, ExpenseStatusPendingApproval},
+	{ExpenseStatusDraft, ExpenseStatusCancelled},
+	{ExpenseStatusPendingApproval, ExpenseStatusApproved},
+	{ExpenseStatusPendingApproval, ExpenseStatusRejected},
+	{ExpenseStatusPendingApproval, ExpenseStatusCancelled},
+	{ExpenseStatusApproved, ExpenseStatusProcessingPayment},
+	{ExpenseStatusApproved, ExpenseStatusCompleted},
+	{ExpenseStatusApproved, ExpenseStatusPaymentFailed},
+	{ExpenseStatusProcessingPayment, ExpenseStatusCompleted},
+	{ExpenseStatusProcessingPayment, ExpenseStatusPaymentFailed},
+	{ExpenseStatusPaymentFailed, ExpenseStatusProcessingPayment},
+	{ExpenseStatusPaymentFailed, ExpenseStatusCompleted},
+}
+
+// StatusChange is a stored move of an expense from one status to another.
+// ActorID is nil when no user made it, such as a payment completing.
+type StatusChange struct {
+	ExpenseID int64
+	UserID    int64
+	From      string
+	To        string
+	ActorID   *int64
+	At        time.Time
+}
+
+func newStatusChange(e *Expense, from string, actorID *int64) StatusChange {
+	return StatusChange{
+		ExpenseID: e.ID,
+		UserID:    e.UserID,
+		From:      from,
+		To:        e.ExpenseStatus,
+		ActorID:   actorID,
+		At:        e.UpdatedAt,
+	}
+}
+
+// TransitionHook is told about every status change once it is stored.
+type TransitionHook func(ctx context.Context, change StatusChange) error
+
+// StateMachine is the one place expense status transitions are allowed or
+// refused.
+type StateMachine struct {
+	next  map[string]map[string]bool
+	hooks []TransitionHook
+}
+
+func NewStateMachine(transitions []Transition) *StateMachine {
+	next := make(map[string]map[string]bool)
+	for _, t := range transitions {
+		if next[t.From] == nil {
+			next[t.From] = make(map[string]bool)
+		}
+		next[t.From][t.To] = true
+	}
+	return &StateMachine{next: next}
+}
+
+func DefaultStateMachine() *StateMachine {
+	return NewStateMachine(DefaultTransitions)
+}
+
+func (m *StateMachine) Can(from, to string) bool {
+	return m.next[from][to]
+}
+
+// Check fails with ErrInvalidExpenseStatus when from may not move to to.
+func (m *StateMachine) Check(from, to string) error {
+	if !m.Can(from, to) {
+		return ErrInvalidExpenseStatus
+	}
+	return nil
+}
+
+// OnTransition adds hook to those run after every stored status change.
+func (m *StateMachine) OnTransition(hook TransitionHook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Notify runs every hook for change, whether or not an earlier one failed.
+func (m *StateMachine) Notify(ctx context.Context, change StatusChange) error {
+	var errs []error
+	for _, hook := range m.hooks {
+		if err := hook(ctx, change); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PublishStatusChanges publishes an ExpenseStatusChangedEvent for every
+// status change.
+func PublishStatusChanges(eventBus *events.EventBus) TransitionHook {
+	return func(ctx context.Context, change StatusChange) error {
+		event := events.NewExpenseStatusChangedEvent(change.ExpenseID, change.UserID, change.From, change.To, change.ActorID)
+		return eventBus.Publish(context.WithoutCancel(ctx), event)
+	}
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+// AuditStatusChanges records every status change in the audit log.
+func AuditStatusChanges(recorder AuditRecorder) TransitionHook {
+	return func(_ context.Context, change StatusChange) error {
+		err := recorder.Record(&audit.Entry{
+			Action:       audit.ActionExpenseStatusChanged,
+			ResourceType: audit.ResourceExpense,
+			ResourceID:   strconv.FormatInt(change.ExpenseID, 10),
+			ActorID:      change.ActorID,
+			Metadata:     map[string]interface{}{"from": change.From, "to": change.To},
+			CreatedAt:    change.At,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to audit expense status change: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+package expense_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+type recordingAuditor struct {
+	entries []*audit.Entry
+}
+
+func (r *recordingAuditor) Record(entry *audit.Entry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+var _ = Describe("StateMachine", func() {
+	states := expense.DefaultStateMachine()
+
+	DescribeTable("allows the expense lifecycle",
+		func(from, to string) {
+			Expect(states.Check(from, to)).To(Succeed())
+		},
+		Entry("submitting a draft", expense.ExpenseStatusDraft, expense.ExpenseStatusPendingApproval),
+		Entry("approving", expense.ExpenseStatusPendingApproval, expense.ExpenseStatusApproved),
+		Entry("rejecting", expense.ExpenseStatusPendingApproval, expense.ExpenseStatusRejected),
+		Entry("cancelling a pending expense", expense.ExpenseStatusPendingApproval, expense.ExpenseStatusCancelled),
+		Entry("paying an approved expense", expense.ExpenseStatusApproved, expense.ExpenseStatusCompleted),
+		Entry("failing a payment", expense.ExpenseStatusApproved, expense.ExpenseStatusPaymentFailed),
+		Entry("retrying a failed payment", expense.ExpenseStatusPaymentFailed, expense.ExpenseStatusProcessingPayment),
+		Entry("completing a retry", expense.ExpenseStatusProcessingPayment, expense.ExpenseStatusCompleted),
+	)
+
+	DescribeTable("refuses everything else",
+		func(from, to string) {
+			Expect(states.Check(from, to)).To(MatchError(expense.ErrInvalidExpenseStatus))
+		},
+		Entry("approving a rejected expense", expense.ExpenseStatusRejected, expense.ExpenseStatusApproved),
+		Entry("rejecting an approved expense", expense.ExpenseStatusApproved, expense.ExpenseStatusRejected),
+		Entry("cancelling an approved expense", expense.ExpenseStatusApproved, expense.ExpenseStatusCancelled),
+		Entry("paying a pending expense", expense.ExpenseStatusPendingApproval, expense.ExpenseStatusCompleted),
+		Entry("reopening a completed expense", expense.ExpenseStatusCompleted, expense.ExpenseStatusPendingApproval),
+		Entry("an unknown status", "submitted", expense.ExpenseStatusApproved),
+	)
+
+	It("can be built from other transitions", func() {
+		custom := expense.NewStateMachine([]expense.Transition{{From: expense.ExpenseStatusRejected, To: expense.ExpenseStatusPendingApproval}})
+		Expect(custom.Can(expense.ExpenseStatusRejected, expense.ExpenseStatusPendingApproval)).To(BeTrue())
+		Expect(custom.Can(expense.ExpenseStatusPendingApproval, expense.ExpenseStatusApproved)).To(BeFalse())
+	})
+
+	It("runs every hook even when one fails", func() {
+		machine := expense.DefaultStateMachine()
+		var seen []expense.StatusChange
+		machine.OnTransition(func(_ context.Context, change expense.StatusChange) error {
+			return errors.New("hook down")
+		})
+		machine.OnTransition(func(_ context.Context, change expense.StatusChange) error {
+			seen = append(seen, change)
+			return nil
+		})
+
+		err := machine.Notify(context.Background(), expense.StatusChange{ExpenseID: 1, From: expense.ExpenseStatusPendingApproval, To: expense.ExpenseStatusApproved})
+		Expect(err).To(MatchError("hook down"))
+		Expect(seen).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("CommandService status transitions", func() {
+	var (
+		ctx            context.Context
+		mockRepo       *mockExpenseRepository
+		mockProcessor  *mockPaymentProcessor
+		eventBus       *events.EventBus
+		expenseService *expense.CommandService
+		changes        []expense.StatusChange
+		auditor        *recordingAuditor
+	)
+
+	store := func(id int64, status string) {
+		mockRepo.expenses[id] = expense.ToDataModel(&expense.Expense{
+			ID:            id,
+			UserID:        123,
+			AmountIDR:     75000,
+			Category:      "food",
+			ExpenseStatus: status,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockRepo = newMockExpenseRepository()
+		mockProcessor = newMockPaymentProcessor()
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		eventBus = events.NewEventBus(logger)
+		categories := mockCategoryValidator{"food": true}
+		expenseService = expense.NewCommandService(mockRepo, mockProcessor, categories, mockApprovalRouter{}, &mockSpendingLimiter{}, nil, nil, nil, nil, nil, nil, auth.NewPermissionChecker(), expense.DefaultStateMachine(), eventBus, logger)
+
+		changes = nil
+		expenseService.States().OnTransition(func(_ context.Context, change expense.StatusChange) error {
+			changes = append(changes, change)
+			return nil
+		})
+		auditor = &recordingAuditor{}
+		expenseService.States().OnTransition(expense.AuditStatusChanges(auditor))
+	})
+
+	It("tells hooks and the audit log who approved an expense", func() {
+		store(1, expense.ExpenseStatusPendingApproval)
+
+		Expect(expenseService.ApproveExpense(ctx, 1, 456, []string{"approve_expenses"})).To(Succeed())
+
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].From).To(Equal(expense.ExpenseStatusPendingApproval))
+		Expect(changes[0].To).To(Equal(expense.ExpenseStatusApproved))
+		Expect(*changes[0].ActorID).To(Equal(int64(456)))
+		Expect(auditor.entries).To(HaveLen(1))
+		Expect(auditor.entries[0].Action).To(Equal(audit.ActionExpenseStatusChanged))
+		Expect(auditor.entries[0].ResourceID).To(Equal("1"))
+		Expect(auditor.entries[0].Metadata).To(Equal(map[string]interface{}{"from": "pending_approval", "to": "approved"}))
+	})
+
+	It("does not approve an expense twice", func() {
+		store(1, expense.ExpenseStatusCompleted)
+
+		err := expenseService.ApproveExpense(ctx, 1, 456, []string{"approve_expenses"})
+		Expect(err).To(MatchError(expense.ErrInvalidExpenseStatus))
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("follows payment failures and completions", func() {
+		store(1, expense.ExpenseStatusApproved)
+
+		Expect(eventBus.PublishSync(ctx, events.NewPaymentFailedEvent("9", 1, "exp-1-75000", 75000, "insufficient funds", 0))).To(Succeed())
+		Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPaymentFailed))
+
+		Expect(expenseService.RetryPayment(ctx, 1, []string{"retry_payments"})).To(Succeed())
+		Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusProcessingPayment))
+
+		completed := events.NewPaymentCompletedEvent("9", 1, "exp-1-75000", 75000, "success", "gw-1")
+		Expect(eventBus.PublishSync(ctx, completed)).To(Succeed())
+		Expect(eventBus.PublishSync(ctx, completed)).To(Succeed())
+		Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusCompleted))
+
+		var path []string
+		for _, c := range changes {
+			path = append(path, c.To)
+		}
+		Expect(path).To(Equal([]string{expense.ExpenseStatusPaymentFailed, expense.ExpenseStatusProcessingPayment, expense.ExpenseStatusCompleted}))
+	})
+
+	It("marks the payment failed again when a retry fails", func() {
+		store(1, expense.ExpenseStatusPaymentFailed)
+		mockProcessor.retryPaymentError = errors.New("gateway down")
+
+		Expect(expenseService.RetryPayment(ctx, 1, []string{"retry_payments"})).ToNot(Succeed())
+		Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPaymentFailed))
+	})
+
+	It("refuses to retry payments of expenses that are not approved", func() {
+		store(1, expense.ExpenseStatusRejected)
+
+		err := expenseService.RetryPayment(ctx, 1, []string{"retry_payments"})
+		Expect(err).To(MatchError(expense.ErrInvalidExpenseStatus))
+	})
+
+	It("submits drafts for approval", func() {
+		store(1, expense.ExpenseStatusDraft)
+
+		_, err := expenseService.SubmitExpenseForApproval(ctx, 1, 123, []string{"view_own_expenses"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mockRepo.expenses[1].ExpenseStatus).To(Equal(expense.ExpenseStatusPendingApproval))
+	})
+})
@@ -0,0 +1,13 @@
+package expenseimport_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestExpenseImport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ExpenseImport Suite")
+}
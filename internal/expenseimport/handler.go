@@ -0,0 +1,125 @@
+package expenseimport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	Start(ctx context.Context, userID int64, upload Upload, dryRun bool) (*Result, error)
+	Get(ctx context.Context, id, userID int64) (*Job, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+	// MaxFileBytes bounds uploaded files.
+	MaxFileBytes int64
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI, maxFileBytes int64) *Handler {
+	return &Handler{
+		BaseHandler:  baseHandler,
+		Service:      service,
+		MaxFileBytes: maxFileBytes,
+	}
+}
+
+// ImportExpenses godoc
+// @Summary      Import expenses from CSV
+// @Description  Admin only. The file needs the columns user_email, amount_idr, description, category and expense_date (YYYY-MM-DD); other columns are ignored. Each row becomes an expense of that user and goes through the usual validation, limits and approval. Rows that fail are reported and skipped. With dry_run=true nothing is created and the report says what would happen. Small files are imported within the request (200); larger ones are queued (202) and their progress is polled with GET /expenses/import/{id}.
+// @Tags         expenses
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file     formData  file  true   "CSV file"
+// @Param        dry_run  query     bool  false  "Validate only"
+// @Success      200      {object}  Report
+// @Success      202      {object}  Job
+// @Failure      400      {object}  transport.AppErrorResponse
+// @Failure      401      {object}  transport.ErrorResponse
+// @Failure      403      {object}  transport.ErrorResponse
+// @Failure      413      {object}  transport.AppErrorResponse
+// @Router       /expenses/import [post]
+func (h *Handler) ImportExpenses(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxFileBytes+transport.MultipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.HandleError(w, r, ErrFileTooLarge)
+			return
+		}
+		h.WriteError(w, r, http.StatusBadRequest, "multipart form with a file field is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > h.MaxFileBytes {
+		h.HandleError(w, r, ErrFileTooLarge)
+		return
+	}
+
+	result, err := h.Service.Start(r.Context(), user.ID, Upload{
+		Filename: header.Filename,
+		Size:     header.Size,
+		Body:     file,
+	}, dryRun)
+	if err != nil {
+		h.Log(r).Error("ImportExpenses: service error", "error", err, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	if result.Job != nil {
+		h.WriteJSON(w, http.StatusAccepted, result.Job)
+		return
+	}
+	h.WriteJSON(w, http.StatusOK, result.Report)
+}
+
+// GetImport godoc
+// @Summary      Get import progress
+// @Description  Reports rows processed so far and, once completed, the rows that failed (at most 100 are listed).
+// @Tags         expenses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Import ID"
+// @Success      200  {object}  Job
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /expenses/import/{id} [get]
+func (h *Handler) GetImport(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid import ID")
+		return
+	}
+
+	job, err := h.Service.Get(r.Context(), id, user.ID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,108 @@
+package expenseimport
+
+import (
+	"encoding/json"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	importDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expenseimport"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// MaxReportedErrors caps the row errors kept in a report; the counts still
+// cover every row.
+const MaxReportedErrors = 100
+
+var (
+	ErrJobNotFound   = errors.NewNotFoundError("Import not found", errors.ErrCodeImportJobNotFound)
+	ErrFileTooLarge  = errors.NewRequestTooLargeError("import file is too large", errors.ErrCodeRequestTooLarge)
+	ErrEmptyFile     = errors.NewValidationFieldError("file", "import file has no header row", errors.ErrCodeInvalidImportFile)
+	ErrMalformedFile = errors.NewValidationFieldError("file", "import file is not valid CSV", errors.ErrCodeInvalidImportFile)
+)
+
+// RowError explains why one CSV row was or would be skipped. Row is the line
+// number in the file, counting the header as line 1.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report summarises an import. A dry run validates every row without creating
+// anything; ImportedRows is then the number of rows that would be imported.
+type Report struct {
+	DryRun       bool       `json:"dry_run"`
+	TotalRows    int        `json:"total_rows"`
+	ImportedRows int        `json:"imported_rows"`
+	FailedRows   int        `json:"failed_rows"`
+	Errors       []RowError `json:"errors"`
+	// ErrorsTruncated is set when more than MaxReportedErrors rows failed.
+	ErrorsTruncated bool `json:"errors_truncated,omitempty"`
+}
+
+func (r *Report) fail(errs []RowError) {
+	r.FailedRows++
+	for _, e := range errs {
+		if len(r.Errors) >= MaxReportedErrors {
+			r.ErrorsTruncated = true
+			return
+		}
+		r.Errors = append(r.Errors, e)
+	}
+}
+
+// Job is a large import running in the background. TotalRows is known once
+// the worker has scanned the file; Progress is the percentage processed.
+type Job struct {
+	ID            int64      `json:"id"`
+	FileName      string     `json:"file_name"`
+	Status        string     `json:"status"`
+	TotalRows     *int       `json:"total_rows,omitempty"`
+	ProcessedRows int        `json:"processed_rows"`
+	ImportedRows  int        `json:"imported_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Progress      int        `json:"progress"`
+	Errors        []RowError `json:"errors,omitempty"`
+	Error         *string    `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// Result is what starting an import returns: a Report when the file was
+// handled inline, or a queued Job.
+type Result struct {
+	Report *Report
+	Job    *Job
+}
+
+func FromJobDataModel(j *importDatamodel.Job) *Job {
+	job := &Job{
+		ID:            j.ID,
+		FileName:      j.FileName,
+		Status:        j.Status,
+		TotalRows:     j.TotalRows,
+		ProcessedRows: j.ProcessedRows,
+		ImportedRows:  j.ImportedRows,
+		FailedRows:    j.FailedRows,
+		Error:         j.Error,
+		CreatedAt:     j.CreatedAt,
+		CompletedAt:   j.CompletedAt,
+	}
+	switch {
+	case j.Status == JobStatusCompleted:
+		job.Progress = 100
+	case j.TotalRows != nil && *j.TotalRows > 0:
+		job.Progress = j.ProcessedRows * 100 / *j.TotalRows
+	}
+	if len(j.RowErrors) > 0 {
+		_ = json.Unmarshal(j.RowErrors, &job.Errors)
+	}
+	return job
+}
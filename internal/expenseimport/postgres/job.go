@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	importDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expenseimport"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/expenseimport"
+	"gorm.io/gorm"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) expenseimport.RepositoryAPI {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) Create(job *importDatamodel.Job) error {
+	return r.db.Create(job).Error
+}
+
+func (r *JobRepository) GetByID(id int64) (*importDatamodel.Job, error) {
+	var job importDatamodel.Job
+	err := r.db.First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext uses SKIP LOCKED so several servers can run import workers.
+func (r *JobRepository) ClaimNext(now time.Time) (*importDatamodel.Job, error) {
+	var jobs []*importDatamodel.Job
+	err := r.db.Raw(`UPDATE expense_import_jobs SET status = ?, started_at = ?
+		WHERE id = (
+			SELECT id FROM expense_import_jobs WHERE status = ?
+			ORDER BY id LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, expenseimport.JobStatusRunning, now, expenseimport.JobStatusQueued).
+		Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+func (r *JobRepository) SetTotalRows(id int64, total int) error {
+	return r.db.Model(&importDatamodel.Job{}).
+		Where("id = ?", id).
+		Update("total_rows", total).Error
+}
+
+func (r *JobRepository) UpdateProgress(id int64, processed, imported, failed int) error {
+	return r.db.Model(&importDatamodel.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"processed_rows": processed,
+			"imported_rows":  imported,
+			"failed_rows":    failed,
+		}).Error
+}
+
+func (r *JobRepository) Complete(id int64, processed, imported, failed int, rowErrors json.RawMessage, completedAt time.Time) error {
+	return r.db.Model(&importDatamodel.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":         expenseimport.JobStatusCompleted,
+			"processed_rows": processed,
+			"imported_rows":  imported,
+			"failed_rows":    failed,
+			"row_errors":     rowErrors,
+			"completed_at":   completedAt,
+		}).Error
+}
+
+func (r *JobRepository) Fail(id int64, reason string, completedAt time.Time) error {
+	return r.db.Model(&importDatamodel.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       expenseimport.JobStatusFailed,
+			"error":        reason,
+			"completed_at": completedAt,
+		}).Error
+}
+
+// FindUserID is tenant scoped through the user model.
+func (r *JobRepository) FindUserID(ctx context.Context, email string) (int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&userDatamodel.User{}).
+		Where("LOWER(email) = LOWER(?) AND is_active = ?", email, true).
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,158 @@
+package expenseimport
+
+import (
+	"encoding/csv"
+	stderrors "errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+// Columns are the CSV columns an import needs, in the order the template
+// lists them. Header names are matched case-insensitively and other columns
+// are ignored, so spreadsheets can be exported as they are.
+var Columns = []string{"user_email", "amount_idr", "description", "category", "expense_date"}
+
+// dateLayout is the format of the expense_date column.
+const dateLayout = "2006-01-02"
+
+// Row is one parsed data row. Errors holds problems found while parsing;
+// Expense is only meaningful when it is empty.
+type Row struct {
+	Line      int
+	UserEmail string
+	Expense   expense.CreateExpenseDTO
+	Errors    []RowError
+}
+
+// Reader streams rows from a CSV file without loading it into memory.
+type Reader struct {
+	csv     *csv.Reader
+	columns map[string]int
+}
+
+// NewReader reads the header row and checks every column in Columns is
+// present.
+func NewReader(r io.Reader) (*Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, ErrMalformedFile
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a UTF-8 byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+
+	var missing []string
+	for _, name := range Columns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.NewValidationFieldError("file", "missing columns: "+strings.Join(missing, ", "), errors.ErrCodeInvalidImportFile)
+	}
+
+	return &Reader{csv: cr, columns: columns}, nil
+}
+
+// Next returns the next row, or io.EOF after the last one. Malformed lines
+// come back as rows with errors; only read failures are returned as errors.
+func (r *Reader) Next() (*Row, error) {
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if stderrors.As(err, &parseErr) {
+		return &Row{
+			Line:   parseErr.StartLine,
+			Errors: []RowError{{Row: parseErr.StartLine, Code: string(errors.ErrCodeInvalidImportFile), Message: parseErr.Err.Error()}},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	row := &Row{Line: line}
+	field := func(name string) string {
+		i := r.columns[name]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	invalid := func(column, message string, code errors.ErrorCode) {
+		row.Errors = append(row.Errors, RowError{Row: line, Field: column, Code: string(code), Message: message})
+	}
+
+	row.UserEmail = strings.ToLower(field("user_email"))
+	if row.UserEmail == "" {
+		invalid("user_email", "user_email is required", errors.ErrCodeValidationFailed)
+	}
+
+	if raw := field("amount_idr"); raw != "" {
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			invalid("amount_idr", "amount_idr must be a whole number of rupiah", errors.ErrCodeInvalidAmount)
+		}
+		row.Expense.AmountIDR = amount
+	}
+
+	row.Expense.Description = field("description")
+	row.Expense.Category = field("category")
+
+	if raw := field("expense_date"); raw != "" {
+		date, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			invalid("expense_date", "expense_date must be formatted as YYYY-MM-DD", errors.ErrCodeInvalidDate)
+		}
+		row.Expense.ExpenseDate = date
+	}
+
+	return row, nil
+}
+
+// CountRows counts the data rows after the header.
+func CountRows(r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	rows := -1
+	for {
+		_, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !stderrors.As(err, &parseErr) {
+			return 0, err
+		}
+		rows++
+	}
+	if rows < 0 {
+		return 0, nil
+	}
+	return rows, nil
+}
@@ -0,0 +1,64 @@
+package expenseimport_test
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/expenseimport"
+)
+
+var _ = Describe("Reader", func() {
+	It("matches columns by name regardless of order, case and byte order mark", func() {
+		r, err := expenseimport.NewReader(strings.NewReader("\ufeffCategory,Notes,USER_EMAIL,amount_idr,description,expense_date\n" +
+			"food,ignored,Ana@Example.com,150000,Team lunch,2025-03-01\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		row, err := r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(row.Errors).To(BeEmpty())
+		Expect(row.Line).To(Equal(2))
+		Expect(row.UserEmail).To(Equal("ana@example.com"))
+		Expect(row.Expense.AmountIDR).To(Equal(int64(150000)))
+		Expect(row.Expense.Category).To(Equal("food"))
+		Expect(row.Expense.ExpenseDate).To(Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+		_, err = r.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("rejects files missing required columns", func() {
+		_, err := expenseimport.NewReader(strings.NewReader("user_email,amount_idr\n"))
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Error()).To(ContainSubstring("description, category, expense_date"))
+
+		_, err = expenseimport.NewReader(strings.NewReader(""))
+		Expect(err).To(Equal(expenseimport.ErrEmptyFile))
+	})
+
+	It("reports unparseable values against their row", func() {
+		r, err := expenseimport.NewReader(strings.NewReader("user_email,amount_idr,description,category,expense_date\n" +
+			",150.000,Taxi,transport,01/03/2025\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		row, err := r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(row.Errors).To(HaveLen(3))
+		for _, e := range row.Errors {
+			Expect(e.Row).To(Equal(2))
+		}
+		Expect(row.Errors[1].Field).To(Equal("amount_idr"))
+		Expect(row.Errors[2].Code).To(Equal(string(errors.ErrCodeInvalidDate)))
+	})
+
+	It("counts data rows", func() {
+		n, err := expenseimport.CountRows(strings.NewReader("a,b\n1,2\n3,4\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+	})
+})
@@ -0,0 +1,326 @@
+package expenseimport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	importDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expenseimport"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// ExpenseCreator creates an expense on behalf of userID;
+// expense.CommandService satisfies it, so imported rows follow the same
+// rules as expenses created through the API.
+type ExpenseCreator interface {
+	CreateExpense(ctx context.Context, req *expense.CreateExpenseDTO, userID int64) (*expense.Expense, error)
+}
+
+// AmountLimiter supplies the claim limits dry runs check amounts against;
+// expense.CommandService implements it. When the ExpenseCreator does not,
+// expense.DefaultAmountLimits apply.
+type AmountLimiter interface {
+	AmountLimits(ctx context.Context, category string) expense.AmountLimits
+}
+
+type RepositoryAPI interface {
+	Create(job *importDatamodel.Job) error
+	GetByID(id int64) (*importDatamodel.Job, error)
+	// ClaimNext marks the oldest queued job running and returns it, or nil
+	// when the queue is empty. Concurrent workers never claim the same job.
+	ClaimNext(now time.Time) (*importDatamodel.Job, error)
+	SetTotalRows(id int64, total int) error
+	UpdateProgress(id int64, processed, imported, failed int) error
+	Complete(id int64, processed, imported, failed int, rowErrors json.RawMessage, completedAt time.Time) error
+	Fail(id int64, reason string, completedAt time.Time) error
+	// FindUserID returns the active user with email in the caller's tenant,
+	// or 0 when there is none.
+	FindUserID(ctx context.Context, email string) (int64, error)
+}
+
+type Config struct {
+	// SyncMaxBytes is the largest file imported within the request; larger
+	// files are queued for the worker.
+	SyncMaxBytes int64
+}
+
+// progressEvery is how many rows a background import processes between
+// progress updates.
+const progressEvery = 100
+
+// Upload is a CSV file received from the client.
+type Upload struct {
+	Filename string
+	Size     int64
+	Body     io.Reader
+}
+
+// Service imports expenses from CSV. Dry runs and small files are handled
+// inline; larger files are stored in blob storage and imported by a Worker,
+// which records progress on the job as it goes.
+type Service struct {
+	repo       RepositoryAPI
+	expenses   ExpenseCreator
+	categories expense.CategoryValidator
+	blob       storage.Blob
+	cfg        Config
+	logger     *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, expenses ExpenseCreator, categories expense.CategoryValidator, blob storage.Blob, cfg Config, logger *slog.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		expenses:   expenses,
+		categories: categories,
+		blob:       blob,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Start validates the file when dryRun is set, imports it inline when it is
+// small, and otherwise queues it.
+func (s *Service) Start(ctx context.Context, userID int64, upload Upload, dryRun bool) (*Result, error) {
+	if dryRun || upload.Size <= s.cfg.SyncMaxBytes {
+		report, err := s.process(ctx, upload.Body, dryRun, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.log(ctx).Info("expense import finished", "user_id", userID, "dry_run", dryRun,
+			"rows", report.TotalRows, "imported", report.ImportedRows, "failed", report.FailedRows)
+		return &Result{Report: report}, nil
+	}
+
+	job, err := s.enqueue(ctx, userID, upload)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Job: job}, nil
+}
+
+func (s *Service) enqueue(ctx context.Context, userID int64, upload Upload) (*Job, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate import key: %w", err)
+	}
+	key := "imports/" + hex.EncodeToString(token) + ".csv"
+	if err := s.blob.Put(ctx, key, upload.Body, storage.PutOptions{ContentType: "text/csv", Size: upload.Size}); err != nil {
+		return nil, fmt.Errorf("failed to store import file: %w", err)
+	}
+
+	name := path.Base(upload.Filename)
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	job := &importDatamodel.Job{
+		UserID:   userID,
+		FileName: name,
+		BlobKey:  key,
+		Status:   JobStatusQueued,
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		job.TenantID = tenantID
+	}
+	if err := s.repo.Create(job); err != nil {
+		_ = s.blob.Delete(ctx, key)
+		return nil, fmt.Errorf("failed to queue import: %w", err)
+	}
+
+	s.log(ctx).Info("expense import queued", "job_id", job.ID, "user_id", userID, "size", upload.Size)
+	return FromJobDataModel(job), nil
+}
+
+// Get returns the requester's own job; other users' jobs are reported as not
+// found.
+func (s *Service) Get(ctx context.Context, id, userID int64) (*Job, error) {
+	j, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import: %w", err)
+	}
+	if j == nil || j.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+	return FromJobDataModel(j), nil
+}
+
+// ProcessNext runs one queued job and reports whether there was one.
+func (s *Service) ProcessNext(ctx context.Context) (bool, error) {
+	j, err := s.repo.ClaimNext(time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim import job: %w", err)
+	}
+	if j == nil {
+		return false, nil
+	}
+
+	// Jobs are claimed across tenants; each runs within its requester's.
+	ctx = tenant.NewContext(ctx, j.TenantID)
+	report, runErr := s.run(ctx, j)
+	now := time.Now()
+	defer func() {
+		if err := s.blob.Delete(ctx, j.BlobKey); err != nil {
+			s.log(ctx).Error("failed to delete import file", "job_id", j.ID, "error", err)
+		}
+	}()
+
+	if runErr != nil {
+		s.log(ctx).Error("import job failed", "job_id", j.ID, "error", runErr)
+		if err := s.repo.Fail(j.ID, runErr.Error(), now); err != nil {
+			return true, fmt.Errorf("failed to record import failure: %w", err)
+		}
+		return true, nil
+	}
+
+	rowErrors, err := json.Marshal(report.Errors)
+	if err != nil {
+		return true, fmt.Errorf("failed to encode import errors: %w", err)
+	}
+	if err := s.repo.Complete(j.ID, report.TotalRows, report.ImportedRows, report.FailedRows, rowErrors, now); err != nil {
+		return true, fmt.Errorf("failed to record import completion: %w", err)
+	}
+
+	s.log(ctx).Info("import job completed", "job_id", j.ID, "rows", report.TotalRows, "imported", report.ImportedRows, "failed", report.FailedRows)
+	return true, nil
+}
+
+// run reads the file twice: once to count rows so progress can be reported
+// as a percentage, then to import them.
+func (s *Service) run(ctx context.Context, j *importDatamodel.Job) (*Report, error) {
+	body, err := s.blob.Get(ctx, j.BlobKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	total, err := CountRows(body)
+	body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+	if err := s.repo.SetTotalRows(j.ID, total); err != nil {
+		return nil, fmt.Errorf("failed to record import size: %w", err)
+	}
+
+	body, err = s.blob.Get(ctx, j.BlobKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer body.Close()
+
+	return s.process(ctx, body, false, func(r *Report) {
+		if err := s.repo.UpdateProgress(j.ID, r.TotalRows, r.ImportedRows, r.FailedRows); err != nil {
+			s.log(ctx).Warn("failed to record import progress", "job_id", j.ID, "error", err)
+		}
+	})
+}
+
+// process streams rows from body, validating each and, unless dryRun is set,
+// creating its expense. Rows that fail are counted and reported; the rest are
+// still imported.
+func (s *Service) process(ctx context.Context, body io.Reader, dryRun bool, progress func(*Report)) (*Report, error) {
+	reader, err := NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun, Errors: []RowError{}}
+	users := map[string]int64{}
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read import file: %w", err)
+		}
+
+		report.TotalRows++
+		rowErrs, err := s.importRow(ctx, row, dryRun, users)
+		if err != nil {
+			return nil, err
+		}
+		if len(rowErrs) > 0 {
+			report.fail(rowErrs)
+		} else {
+			report.ImportedRows++
+		}
+
+		if progress != nil && report.TotalRows%progressEvery == 0 {
+			progress(report)
+		}
+	}
+	return report, nil
+}
+
+func (s *Service) importRow(ctx context.Context, row *Row, dryRun bool, users map[string]int64) ([]RowError, error) {
+	if len(row.Errors) > 0 {
+		return row.Errors, nil
+	}
+
+	userID, cached := users[row.UserEmail]
+	if !cached {
+		var err error
+		if userID, err = s.repo.FindUserID(ctx, row.UserEmail); err != nil {
+			return nil, fmt.Errorf("failed to look up user: %w", err)
+		}
+		users[row.UserEmail] = userID
+	}
+	if userID == 0 {
+		return []RowError{{Row: row.Line, Field: "user_email", Code: string(errors.ErrCodeUserNotFound), Message: "no active user has this email"}}, nil
+	}
+
+	if dryRun {
+		limits := expense.DefaultAmountLimits
+		if limiter, ok := s.expenses.(AmountLimiter); ok {
+			limits = limiter.AmountLimits(ctx, row.Expense.Category)
+		}
+		if err := row.Expense.Validate(limits); err != nil {
+			return rowErrors(row.Line, err), nil
+		}
+		if !s.categories.IsValidCategory(ctx, row.Expense.Category) {
+			return rowErrors(row.Line, expense.ErrInvalidCategory), nil
+		}
+		if !s.categories.IsLeafCategory(ctx, row.Expense.Category) {
+			return rowErrors(row.Line, expense.ErrCategoryNotLeaf), nil
+		}
+		return nil, nil
+	}
+
+	if _, err := s.expenses.CreateExpense(ctx, &row.Expense, userID); err != nil {
+		if _, ok := errors.IsAppError(err); !ok {
+			s.log(ctx).Error("failed to import expense row", "row", row.Line, "error", err)
+		}
+		return rowErrors(row.Line, err), nil
+	}
+	return nil, nil
+}
+
+// rowErrors turns a validation error into one RowError per field. Other
+// application errors keep their code and message; anything else is reported
+// without internal detail.
+func rowErrors(line int, err error) []RowError {
+	appErr, ok := errors.IsAppError(err)
+	if !ok {
+		return []RowError{{Row: line, Message: "failed to create expense"}}
+	}
+	if details, ok := appErr.Details.(errors.ValidationErrors); ok && len(details.Errors) > 0 {
+		out := make([]RowError, len(details.Errors))
+		for i, ve := range details.Errors {
+			out[i] = RowError{Row: line, Field: ve.Field, Code: ve.Code, Message: ve.Message}
+		}
+		return out
+	}
+	return []RowError{{Row: line, Code: string(appErr.Code), Message: appErr.Message}}
+}
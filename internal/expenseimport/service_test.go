@@ -0,0 +1,274 @@
+package expenseimport_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	importDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expenseimport"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/expenseimport"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type fakeRepository struct {
+	jobs     map[int64]*importDatamodel.Job
+	nextID   int64
+	users    map[string]int64
+	progress []int
+}
+
+func (f *fakeRepository) Create(job *importDatamodel.Job) error {
+	f.nextID++
+	job.ID = f.nextID
+	job.CreatedAt = time.Now()
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeRepository) GetByID(id int64) (*importDatamodel.Job, error) {
+	return f.jobs[id], nil
+}
+
+func (f *fakeRepository) ClaimNext(now time.Time) (*importDatamodel.Job, error) {
+	for id := int64(1); id <= f.nextID; id++ {
+		if j := f.jobs[id]; j != nil && j.Status == expenseimport.JobStatusQueued {
+			j.Status, j.StartedAt = expenseimport.JobStatusRunning, &now
+			return j, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) SetTotalRows(id int64, total int) error {
+	f.jobs[id].TotalRows = &total
+	return nil
+}
+
+func (f *fakeRepository) UpdateProgress(id int64, processed, imported, failed int) error {
+	j := f.jobs[id]
+	j.ProcessedRows, j.ImportedRows, j.FailedRows = processed, imported, failed
+	f.progress = append(f.progress, processed)
+	return nil
+}
+
+func (f *fakeRepository) Complete(id int64, processed, imported, failed int, rowErrors json.RawMessage, completedAt time.Time) error {
+	j := f.jobs[id]
+	j.Status, j.ProcessedRows, j.ImportedRows, j.FailedRows = expenseimport.JobStatusCompleted, processed, imported, failed
+	j.RowErrors, j.CompletedAt = rowErrors, &completedAt
+	return nil
+}
+
+func (f *fakeRepository) Fail(id int64, reason string, completedAt time.Time) error {
+	j := f.jobs[id]
+	j.Status, j.Error, j.CompletedAt = expenseimport.JobStatusFailed, &reason, &completedAt
+	return nil
+}
+
+func (f *fakeRepository) FindUserID(ctx context.Context, email string) (int64, error) {
+	return f.users[email], nil
+}
+
+// fakeCreator applies the checks expense.CommandService would.
+type fakeCreator struct {
+	categories fakeCategories
+	created    []int64
+	tenantID   int64
+}
+
+func (f *fakeCreator) CreateExpense(ctx context.Context, req *expense.CreateExpenseDTO, userID int64) (*expense.Expense, error) {
+	if err := req.Validate(expense.DefaultAmountLimits); err != nil {
+		return nil, err
+	}
+	if !f.categories.IsLeafCategory(ctx, req.Category) {
+		return nil, expense.ErrCategoryNotLeaf
+	}
+	if req.AmountIDR > 1000000 {
+		return nil, errors.NewValidationError("daily spending limit exceeded", errors.ErrCodeLimitExceeded)
+	}
+	f.tenantID, _ = tenant.FromContext(ctx)
+	f.created = append(f.created, userID)
+	return &expense.Expense{ID: int64(len(f.created)), UserID: userID}, nil
+}
+
+type fakeCategories map[string]bool
+
+func (c fakeCategories) IsValidCategory(_ context.Context, name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+func (c fakeCategories) IsLeafCategory(_ context.Context, name string) bool {
+	return c[name]
+}
+
+const header = "user_email,amount_idr,description,category,expense_date\n"
+
+var _ = Describe("Service", func() {
+	var (
+		ctx      context.Context
+		repo     *fakeRepository
+		creator  *fakeCreator
+		blob     *storage.Local
+		service  *expenseimport.Service
+		upload   func(csv string) expenseimport.Upload
+		mixedCSV string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &fakeRepository{jobs: map[int64]*importDatamodel.Job{}, users: map[string]int64{"ana@example.com": 7, "budi@example.com": 8}}
+		categories := fakeCategories{"food": true, "travel": false}
+		creator = &fakeCreator{categories: categories}
+
+		var err error
+		blob, err = storage.NewLocal(GinkgoT().TempDir(), "http://localhost:8080", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		service = expenseimport.NewService(repo, creator, categories, blob,
+			expenseimport.Config{SyncMaxBytes: 1024}, logger)
+
+		upload = func(csv string) expenseimport.Upload {
+			return expenseimport.Upload{Filename: "expenses.csv", Size: int64(len(csv)), Body: strings.NewReader(csv)}
+		}
+		mixedCSV = header +
+			"ana@example.com,150000,Team lunch,food,2025-03-01\n" +
+			"nobody@example.com,150000,Team lunch,food,2025-03-01\n" +
+			"budi@example.com,150000,Flight,travel,2025-03-01\n" +
+			"budi@example.com,2000000,Offsite dinner,food,2025-03-01\n"
+	})
+
+	Describe("dry run", func() {
+		It("validates every row without creating expenses", func() {
+			result, err := service.Start(ctx, 1, upload(mixedCSV), true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Job).To(BeNil())
+
+			report := result.Report
+			Expect(report.DryRun).To(BeTrue())
+			Expect(report.TotalRows).To(Equal(4))
+			Expect(report.ImportedRows).To(Equal(2))
+			Expect(report.FailedRows).To(Equal(2))
+			Expect(report.Errors).To(ConsistOf(
+				expenseimport.RowError{Row: 3, Field: "user_email", Code: string(errors.ErrCodeUserNotFound), Message: "no active user has this email"},
+				HaveField("Row", 4),
+			))
+			Expect(report.Errors[1].Field).To(Equal("category"))
+			Expect(creator.created).To(BeEmpty())
+		})
+
+		It("rejects files that are not expense CSVs", func() {
+			_, err := service.Start(ctx, 1, upload("name,amount\n"), true)
+			_, ok := errors.IsAppError(err)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("inline import", func() {
+		It("creates the valid rows and reports the others", func() {
+			result, err := service.Start(ctx, 1, upload(mixedCSV), false)
+			Expect(err).NotTo(HaveOccurred())
+
+			report := result.Report
+			Expect(report.DryRun).To(BeFalse())
+			Expect(report.ImportedRows).To(Equal(1))
+			Expect(report.FailedRows).To(Equal(3))
+			Expect(report.Errors[2]).To(Equal(expenseimport.RowError{Row: 5, Code: string(errors.ErrCodeLimitExceeded), Message: "daily spending limit exceeded"}))
+			Expect(creator.created).To(Equal([]int64{7}))
+		})
+
+		It("caps the listed errors", func() {
+			var csv strings.Builder
+			csv.WriteString(header)
+			for i := 0; i < expenseimport.MaxReportedErrors+5; i++ {
+				csv.WriteString("nobody@example.com,150000,Lunch,food,2025-03-01\n")
+			}
+			service = expenseimport.NewService(repo, creator, creator.categories, blob, expenseimport.Config{SyncMaxBytes: 1 << 20}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			result, err := service.Start(ctx, 1, upload(csv.String()), false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Report.FailedRows).To(Equal(expenseimport.MaxReportedErrors + 5))
+			Expect(result.Report.Errors).To(HaveLen(expenseimport.MaxReportedErrors))
+			Expect(result.Report.ErrorsTruncated).To(BeTrue())
+		})
+	})
+
+	Describe("background import", func() {
+		var largeCSV string
+
+		BeforeEach(func() {
+			var csv strings.Builder
+			csv.WriteString(header)
+			for i := 1; i <= 250; i++ {
+				fmt.Fprintf(&csv, "ana@example.com,%d,Lunch %d,food,2025-03-01\n", 100000+i, i)
+			}
+			csv.WriteString("nobody@example.com,150000,Lunch,food,2025-03-01\n")
+			largeCSV = csv.String()
+		})
+
+		It("queues large files and tracks progress while importing them", func() {
+			result, err := service.Start(tenant.NewContext(ctx, 3), 1, upload(largeCSV), false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Report).To(BeNil())
+			Expect(result.Job.Status).To(Equal(expenseimport.JobStatusQueued))
+			Expect(result.Job.FileName).To(Equal("expenses.csv"))
+			key := repo.jobs[result.Job.ID].BlobKey
+
+			processed, err := service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeTrue())
+			Expect(repo.progress).To(Equal([]int{100, 200}))
+			Expect(creator.tenantID).To(Equal(int64(3)))
+
+			job, err := service.Get(ctx, result.Job.ID, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(expenseimport.JobStatusCompleted))
+			Expect(*job.TotalRows).To(Equal(251))
+			Expect(job.ProcessedRows).To(Equal(251))
+			Expect(job.ImportedRows).To(Equal(250))
+			Expect(job.FailedRows).To(Equal(1))
+			Expect(job.Progress).To(Equal(100))
+			Expect(job.Errors).To(HaveLen(1))
+			Expect(job.Errors[0].Row).To(Equal(252))
+
+			_, err = blob.Get(ctx, key)
+			Expect(err).To(MatchError(storage.ErrNotFound))
+
+			processed, err = service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeFalse())
+		})
+
+		It("fails the job when the file cannot be read", func() {
+			result, err := service.Start(ctx, 1, upload(largeCSV), false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blob.Delete(ctx, repo.jobs[result.Job.ID].BlobKey)).To(Succeed())
+
+			_, err = service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			job, err := service.Get(ctx, result.Job.ID, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(expenseimport.JobStatusFailed))
+			Expect(*job.Error).To(ContainSubstring("failed to open import file"))
+		})
+
+		It("hides other users' imports", func() {
+			result, err := service.Start(ctx, 1, upload(largeCSV), false)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Get(ctx, result.Job.ID, 2)
+			Expect(err).To(Equal(expenseimport.ErrJobNotFound))
+		})
+	})
+})
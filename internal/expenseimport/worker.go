@@ -0,0 +1,51 @@
+package expenseimport
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Worker polls for queued import jobs.
+type Worker struct {
+	service  *Service
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewWorker(service *Service, interval time.Duration, logger *slog.Logger) *Worker {
+	return &Worker{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("import worker started", "poll_interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("import worker stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// Drain the queue before sleeping again.
+		for ctx.Err() == nil {
+			processed, err := w.service.ProcessNext(ctx)
+			if err != nil {
+				w.logger.Error("import job run failed", "error", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+	}
+}
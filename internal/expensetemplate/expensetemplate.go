@@ -0,0 +1,71 @@
+package expensetemplate
+
+import (
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	templateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expensetemplate"
+)
+
+var ErrTemplateNotFound = errors.NewNotFoundError("Expense template not found", errors.ErrCodeExpenseTemplateNotFound)
+
+// Template is a user's shortcut for an expense they claim often, such as
+// the daily taxi. Templates are personal: only their owner sees and uses
+// them.
+type Template struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category"`
+	AmountIDR   *int64    `json:"amount_idr,omitempty"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TemplateDTO creates a template or replaces one. AmountIDR may be left out
+// for claims whose amount changes every time.
+type TemplateDTO struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Category    string `json:"category" validate:"required,max=255"`
+	AmountIDR   *int64 `json:"amount_idr,omitempty"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+func (dto TemplateDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if dto.AmountIDR != nil && *dto.AmountIDR <= 0 {
+		return errors.NewValidationFieldError("amount_idr", "amount_idr must be positive", errors.ErrCodeInvalidAmount)
+	}
+	return nil
+}
+
+// UseTemplateDTO overrides a template's defaults for one expense. The
+// expense is dated today unless ExpenseDate is set; AmountIDR is required
+// when the template has no amount.
+type UseTemplateDTO struct {
+	AmountIDR       *int64     `json:"amount_idr,omitempty"`
+	Description     *string    `json:"description,omitempty"`
+	ExpenseDate     *time.Time `json:"expense_date,omitempty"`
+	ReceiptURL      *string    `json:"receipt_url,omitempty"`
+	ReceiptFileName *string    `json:"receipt_filename,omitempty"`
+	PayoutAccountID *int64     `json:"payout_account_id,omitempty"`
+}
+
+type TemplatesResponse struct {
+	Templates []*Template `json:"templates"`
+}
+
+func fromDataModel(t *templateDatamodel.Template) *Template {
+	return &Template{
+		ID:          t.ID,
+		Name:        t.Name,
+		Category:    t.Category,
+		AmountIDR:   t.AmountIDR,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package expensetemplate_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestExpenseTemplate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expense Template Suite")
+}
@@ -0,0 +1,197 @@
+package expensetemplate
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	List(ctx context.Context, userID int64) ([]*Template, error)
+	Create(ctx context.Context, userID int64, dto TemplateDTO) (*Template, error)
+	Update(ctx context.Context, userID, id int64, dto TemplateDTO) (*Template, error)
+	Delete(ctx context.Context, userID, id int64) error
+	CreateExpense(ctx context.Context, userID, id int64, dto UseTemplateDTO) (*expense.Expense, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListTemplates godoc
+// @Summary      List my expense templates
+// @Tags         expense-templates
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  TemplatesResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /expense-templates [get]
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	templates, err := h.Service.List(r.Context(), user.ID)
+	if err != nil {
+		h.Log(r).Error("ListTemplates: service error", "error", err, "user_id", user.ID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list expense templates")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, TemplatesResponse{Templates: templates})
+}
+
+// CreateTemplate godoc
+// @Summary      Create an expense template
+// @Description  category must be a leaf category. amount_idr may be left out for claims whose amount changes each time.
+// @Tags         expense-templates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      TemplateDTO  true  "Template"
+// @Success      201   {object}  Template
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Router       /expense-templates [post]
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto TemplateDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	template, err := h.Service.Create(r.Context(), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, template)
+}
+
+// UpdateTemplate godoc
+// @Summary      Replace an expense template
+// @Tags         expense-templates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int          true  "Template ID"
+// @Param        body  body      TemplateDTO  true  "Template"
+// @Success      200   {object}  Template
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /expense-templates/{id} [put]
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	user, id, ok := h.userAndTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var dto TemplateDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	template, err := h.Service.Update(r.Context(), user.ID, id, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, template)
+}
+
+// DeleteTemplate godoc
+// @Summary      Delete an expense template
+// @Description  Expenses already created from the template are not affected.
+// @Tags         expense-templates
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Template ID"
+// @Success      204
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /expense-templates/{id} [delete]
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	user, id, ok := h.userAndTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(r.Context(), user.ID, id); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateExpenseFromTemplate godoc
+// @Summary      Create an expense from a template
+// @Description  Submits an expense with the template's category, amount and description, dated today (UTC). Any field in the body overrides the template; amount_idr is required when the template has none. The expense is checked exactly like one created through POST /expenses.
+// @Tags         expense-templates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int             true   "Template ID"
+// @Param        body  body      UseTemplateDTO  false  "Overrides"
+// @Success      201   {object}  expense.Expense
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /expense-templates/{id}/expenses [post]
+func (h *Handler) CreateExpenseFromTemplate(w http.ResponseWriter, r *http.Request) {
+	user, id, ok := h.userAndTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var dto UseTemplateDTO
+	if r.ContentLength != 0 && !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	created, err := h.Service.CreateExpense(r.Context(), user.ID, id, dto)
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, expense.ExpenseResponse(transport.APIVersionFromContext(r.Context()), created))
+}
+
+func (h *Handler) userAndTemplateID(w http.ResponseWriter, r *http.Request) (*internal.User, int64, bool) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid template ID")
+		return nil, 0, false
+	}
+	return user, id, true
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	templateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expensetemplate"
+	"github.com/frahmantamala/expense-management/internal/expensetemplate"
+	"gorm.io/gorm"
+)
+
+type TemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) expensetemplate.RepositoryAPI {
+	return &TemplateRepository{db: db}
+}
+
+func (r *TemplateRepository) ListByUser(ctx context.Context, userID int64) ([]*templateDatamodel.Template, error) {
+	var templates []*templateDatamodel.Template
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name, id").Find(&templates).Error
+	return templates, err
+}
+
+func (r *TemplateRepository) Get(ctx context.Context, id int64) (*templateDatamodel.Template, error) {
+	var template templateDatamodel.Template
+	err := r.db.WithContext(ctx).First(&template, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, t *templateDatamodel.Template) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+func (r *TemplateRepository) Update(ctx context.Context, t *templateDatamodel.Template) error {
+	return r.db.WithContext(ctx).Save(t).Error
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Delete(&templateDatamodel.Template{}, id).Error
+}
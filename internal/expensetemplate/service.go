@@ -0,0 +1,185 @@
+package expensetemplate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	templateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expensetemplate"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the templates of the tenant ctx is scoped
+// to.
+type RepositoryAPI interface {
+	ListByUser(ctx context.Context, userID int64) ([]*templateDatamodel.Template, error)
+	// Get returns nil when there is no such template.
+	Get(ctx context.Context, id int64) (*templateDatamodel.Template, error)
+	Create(ctx context.Context, t *templateDatamodel.Template) error
+	Update(ctx context.Context, t *templateDatamodel.Template) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// ExpenseCreator submits expenses with every check a direct submission
+// gets; expense.CommandService satisfies it.
+type ExpenseCreator interface {
+	CreateExpense(ctx context.Context, req *expense.CreateExpenseDTO, userID int64) (*expense.Expense, error)
+}
+
+type Service struct {
+	repo       RepositoryAPI
+	categories expense.CategoryValidator
+	expenses   ExpenseCreator
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+func NewService(repo RepositoryAPI, categories expense.CategoryValidator, expenses ExpenseCreator, logger *slog.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		categories: categories,
+		expenses:   expenses,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]*Template, error) {
+	rows, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expense templates: %w", err)
+	}
+
+	templates := make([]*Template, len(rows))
+	for i, row := range rows {
+		templates[i] = fromDataModel(row)
+	}
+	return templates, nil
+}
+
+func (s *Service) Create(ctx context.Context, userID int64, dto TemplateDTO) (*Template, error) {
+	if err := s.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+
+	row := &templateDatamodel.Template{UserID: userID}
+	apply(row, dto)
+	if err := s.repo.Create(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to create expense template: %w", err)
+	}
+
+	s.log(ctx).Info("expense template created", "template_id", row.ID, "user_id", userID)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) Update(ctx context.Context, userID, id int64, dto TemplateDTO) (*Template, error) {
+	if err := s.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+
+	row, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	apply(row, dto)
+	if err := s.repo.Update(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to update expense template: %w", err)
+	}
+
+	s.log(ctx).Info("expense template updated", "template_id", id, "user_id", userID)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID, id int64) error {
+	if _, err := s.owned(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete expense template: %w", err)
+	}
+
+	s.log(ctx).Info("expense template deleted", "template_id", id, "user_id", userID)
+	return nil
+}
+
+// CreateExpense submits an expense from the template, with dto's overrides
+// applied. The expense goes through the same validation, limits and
+// approval rules as one submitted directly.
+func (s *Service) CreateExpense(ctx context.Context, userID, id int64, dto UseTemplateDTO) (*expense.Expense, error) {
+	row, err := s.owned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.now().UTC()
+	req := &expense.CreateExpenseDTO{
+		Description:     row.Description,
+		Category:        row.Category,
+		ExpenseDate:     time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC),
+		ReceiptURL:      dto.ReceiptURL,
+		ReceiptFileName: dto.ReceiptFileName,
+		PayoutAccountID: dto.PayoutAccountID,
+	}
+	if row.AmountIDR != nil {
+		req.AmountIDR = *row.AmountIDR
+	}
+	if dto.AmountIDR != nil {
+		req.AmountIDR = *dto.AmountIDR
+	}
+	if dto.Description != nil {
+		req.Description = *dto.Description
+	}
+	if dto.ExpenseDate != nil {
+		req.ExpenseDate = *dto.ExpenseDate
+	}
+
+	created, err := s.expenses.CreateExpense(ctx, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("expense created from template", "template_id", id, "expense_id", created.ID, "user_id", userID)
+	return created, nil
+}
+
+// validate rejects categories expenses could not be filed under, so a
+// template never produces an expense that fails on its category.
+func (s *Service) validate(ctx context.Context, dto TemplateDTO) error {
+	if err := dto.Validate(); err != nil {
+		return err
+	}
+	if !s.categories.IsValidCategory(ctx, dto.Category) {
+		return expense.ErrInvalidCategory
+	}
+	if !s.categories.IsLeafCategory(ctx, dto.Category) {
+		return expense.ErrCategoryNotLeaf
+	}
+	return nil
+}
+
+// owned returns the template when userID owns it. Other users' templates
+// are reported as not found.
+func (s *Service) owned(ctx context.Context, userID, id int64) (*templateDatamodel.Template, error) {
+	row, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load expense template: %w", err)
+	}
+	if row == nil || row.UserID != userID {
+		return nil, ErrTemplateNotFound
+	}
+	return row, nil
+}
+
+func apply(row *templateDatamodel.Template, dto TemplateDTO) {
+	row.Name = dto.Name
+	row.Category = dto.Category
+	row.AmountIDR = dto.AmountIDR
+	row.Description = dto.Description
+}
@@ -0,0 +1,195 @@
+package expensetemplate_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	templateDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expensetemplate"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/expensetemplate"
+)
+
+type mockRepository struct {
+	templates map[int64]*templateDatamodel.Template
+	nextID    int64
+}
+
+func (m *mockRepository) ListByUser(_ context.Context, userID int64) ([]*templateDatamodel.Template, error) {
+	var out []*templateDatamodel.Template
+	for _, t := range m.templates {
+		if t.UserID == userID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) Get(_ context.Context, id int64) (*templateDatamodel.Template, error) {
+	return m.templates[id], nil
+}
+
+func (m *mockRepository) Create(_ context.Context, t *templateDatamodel.Template) error {
+	m.nextID++
+	t.ID = m.nextID
+	m.templates[t.ID] = t
+	return nil
+}
+
+func (m *mockRepository) Update(_ context.Context, t *templateDatamodel.Template) error {
+	m.templates[t.ID] = t
+	return nil
+}
+
+func (m *mockRepository) Delete(_ context.Context, id int64) error {
+	delete(m.templates, id)
+	return nil
+}
+
+type mockCategories struct{}
+
+func (mockCategories) IsValidCategory(_ context.Context, name string) bool {
+	return name == "transport" || name == "travel"
+}
+
+func (mockCategories) IsLeafCategory(_ context.Context, name string) bool {
+	return name == "transport"
+}
+
+type mockExpenses struct {
+	requests []*expense.CreateExpenseDTO
+}
+
+func (m *mockExpenses) CreateExpense(_ context.Context, req *expense.CreateExpenseDTO, userID int64) (*expense.Expense, error) {
+	if err := req.Validate(expense.DefaultAmountLimits); err != nil {
+		return nil, err
+	}
+	m.requests = append(m.requests, req)
+	return &expense.Expense{ID: 42, UserID: userID, AmountIDR: req.AmountIDR, Category: req.Category, Description: req.Description}, nil
+}
+
+func amount(v int64) *int64 { return &v }
+
+var _ = Describe("Service", func() {
+	var (
+		ctx      context.Context
+		repo     *mockRepository
+		expenses *mockExpenses
+		service  *expensetemplate.Service
+		taxi     expensetemplate.TemplateDTO
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{templates: map[int64]*templateDatamodel.Template{}}
+		expenses = &mockExpenses{}
+		service = expensetemplate.NewService(repo, mockCategories{}, expenses, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		taxi = expensetemplate.TemplateDTO{
+			Name:        "Taxi to office",
+			Category:    "transport",
+			AmountIDR:   amount(50000),
+			Description: "Taxi to office",
+		}
+	})
+
+	Describe("Create", func() {
+		It("stores the template for its owner", func() {
+			template, err := service.Create(ctx, 1, taxi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.ID).To(Equal(int64(1)))
+			Expect(repo.templates[1].UserID).To(Equal(int64(1)))
+		})
+
+		It("allows templates without an amount", func() {
+			taxi.AmountIDR = nil
+			_, err := service.Create(ctx, 1, taxi)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects non-positive amounts", func() {
+			taxi.AmountIDR = amount(0)
+			_, err := service.Create(ctx, 1, taxi)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects unknown and parent categories", func() {
+			taxi.Category = "snacks"
+			_, err := service.Create(ctx, 1, taxi)
+			Expect(err).To(Equal(expense.ErrInvalidCategory))
+
+			taxi.Category = "travel"
+			_, err = service.Create(ctx, 1, taxi)
+			Expect(err).To(Equal(expense.ErrCategoryNotLeaf))
+		})
+	})
+
+	Describe("ownership", func() {
+		It("hides other users' templates", func() {
+			created, err := service.Create(ctx, 1, taxi)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates, err := service.List(ctx, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates).To(BeEmpty())
+
+			_, err = service.Update(ctx, 2, created.ID, taxi)
+			Expect(err).To(Equal(expensetemplate.ErrTemplateNotFound))
+			Expect(service.Delete(ctx, 2, created.ID)).To(Equal(expensetemplate.ErrTemplateNotFound))
+			_, err = service.CreateExpense(ctx, 2, created.ID, expensetemplate.UseTemplateDTO{})
+			Expect(err).To(Equal(expensetemplate.ErrTemplateNotFound))
+			Expect(repo.templates).To(HaveKey(created.ID))
+		})
+	})
+
+	Describe("CreateExpense", func() {
+		It("submits the template's defaults dated today", func() {
+			created, err := service.Create(ctx, 1, taxi)
+			Expect(err).NotTo(HaveOccurred())
+
+			exp, err := service.CreateExpense(ctx, 1, created.ID, expensetemplate.UseTemplateDTO{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exp.ID).To(Equal(int64(42)))
+
+			Expect(expenses.requests).To(HaveLen(1))
+			req := expenses.requests[0]
+			Expect(req.AmountIDR).To(Equal(int64(50000)))
+			Expect(req.Category).To(Equal("transport"))
+			Expect(req.Description).To(Equal("Taxi to office"))
+			now := time.Now().UTC()
+			Expect(req.ExpenseDate).To(Equal(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("applies overrides", func() {
+			created, err := service.Create(ctx, 1, taxi)
+			Expect(err).NotTo(HaveOccurred())
+
+			date := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+			description := "Taxi to client"
+			_, err = service.CreateExpense(ctx, 1, created.ID, expensetemplate.UseTemplateDTO{
+				AmountIDR:   amount(75000),
+				Description: &description,
+				ExpenseDate: &date,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			req := expenses.requests[0]
+			Expect(req.AmountIDR).To(Equal(int64(75000)))
+			Expect(req.Description).To(Equal("Taxi to client"))
+			Expect(req.ExpenseDate).To(Equal(date))
+		})
+
+		It("needs an amount when the template has none", func() {
+			taxi.AmountIDR = nil
+			created, err := service.Create(ctx, 1, taxi)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.CreateExpense(ctx, 1, created.ID, expensetemplate.UseTemplateDTO{})
+			Expect(err).To(HaveOccurred())
+			Expect(expenses.requests).To(BeEmpty())
+		})
+	})
+})
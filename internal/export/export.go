@@ -0,0 +1,253 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSONL:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want csv or jsonl)", ErrUnsupportedFormat, s)
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
+
+// Range is a half-open [From, To) time window.
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+func (r Range) Validate() error {
+	if r.From.IsZero() || r.To.IsZero() {
+		return errors.New("both from and to are required")
+	}
+	if !r.From.Before(r.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// ParseDayRange turns inclusive YYYY-MM-DD bounds (UTC) into a half-open
+// range.
+func ParseDayRange(from, to string) (Range, error) {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid from %q: expected YYYY-MM-DD", from)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid to %q: expected YYYY-MM-DD", to)
+	}
+
+	rng := Range{From: start, To: end.AddDate(0, 0, 1)}
+	return rng, rng.Validate()
+}
+
+type record interface {
+	values() []string
+}
+
+var (
+	expenseHeader = []string{"id", "user_id", "amount_idr", "description", "category", "expense_status", "expense_date", "receipt_url", "submitted_at", "processed_at", "created_at", "approved_by", "rejected_by"}
+	paymentHeader = []string{"id", "expense_id", "external_id", "amount_idr", "status", "payment_method", "failure_reason", "retry_count", "processed_at", "created_at"}
+)
+
+type ExpenseRecord struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	AmountIDR     int64      `json:"amount_idr"`
+	Description   string     `json:"description"`
+	Category      string     `json:"category"`
+	ExpenseStatus string     `json:"expense_status"`
+	ExpenseDate   string     `json:"expense_date"`
+	ReceiptURL    *string    `json:"receipt_url,omitempty"`
+	SubmittedAt   time.Time  `json:"submitted_at"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ApprovedBy    *int64     `json:"approved_by,omitempty"`
+	RejectedBy    *int64     `json:"rejected_by,omitempty"`
+}
+
+func NewExpenseRecord(e *expenseDatamodel.Expense) *ExpenseRecord {
+	return &ExpenseRecord{
+		ID:            e.ID,
+		UserID:        e.UserID,
+		AmountIDR:     e.AmountIDR,
+		Description:   e.Description,
+		Category:      e.Category,
+		ExpenseStatus: e.ExpenseStatus,
+		ExpenseDate:   e.ExpenseDate.Format("2006-01-02"),
+		ReceiptURL:    e.ReceiptURL,
+		SubmittedAt:   e.SubmittedAt,
+		ProcessedAt:   e.ProcessedAt,
+		CreatedAt:     e.CreatedAt,
+		ApprovedBy:    e.ApprovedBy,
+		RejectedBy:    e.RejectedBy,
+	}
+}
+
+func (r *ExpenseRecord) values() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10),
+		strconv.FormatInt(r.UserID, 10),
+		strconv.FormatInt(r.AmountIDR, 10),
+		r.Description,
+		r.Category,
+		r.ExpenseStatus,
+		r.ExpenseDate,
+		optionalString(r.ReceiptURL),
+		formatTime(r.SubmittedAt),
+		optionalTime(r.ProcessedAt),
+		formatTime(r.CreatedAt),
+		optionalInt(r.ApprovedBy),
+		optionalInt(r.RejectedBy),
+	}
+}
+
+type PaymentRecord struct {
+	ID            int64      `json:"id"`
+	ExpenseID     int64      `json:"expense_id"`
+	ExternalID    string     `json:"external_id"`
+	AmountIDR     int64      `json:"amount_idr"`
+	Status        string     `json:"status"`
+	PaymentMethod *string    `json:"payment_method,omitempty"`
+	FailureReason *string    `json:"failure_reason,omitempty"`
+	RetryCount    int        `json:"retry_count"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func NewPaymentRecord(p *paymentDatamodel.Payment) *PaymentRecord {
+	return &PaymentRecord{
+		ID:            p.ID,
+		ExpenseID:     p.ExpenseID,
+		ExternalID:    p.ExternalID,
+		AmountIDR:     p.AmountIDR,
+		Status:        p.Status,
+		PaymentMethod: p.PaymentMethod,
+		FailureReason: p.FailureReason,
+		RetryCount:    p.RetryCount,
+		ProcessedAt:   p.ProcessedAt,
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+func (r *PaymentRecord) values() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10),
+		strconv.FormatInt(r.ExpenseID, 10),
+		r.ExternalID,
+		strconv.FormatInt(r.AmountIDR, 10),
+		r.Status,
+		optionalString(r.PaymentMethod),
+		optionalString(r.FailureReason),
+		strconv.Itoa(r.RetryCount),
+		optionalTime(r.ProcessedAt),
+		formatTime(r.CreatedAt),
+	}
+}
+
+// encoder writes one record at a time so exports never hold the full result
+// set in memory.
+type encoder interface {
+	begin(header []string) error
+	encode(rec record) error
+	flush() error
+}
+
+func newEncoder(format Format, w io.Writer) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case FormatJSONL:
+		return &jsonlEncoder{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) begin(header []string) error {
+	return e.w.Write(header)
+}
+
+func (e *csvEncoder) encode(rec record) error {
+	return e.w.Write(rec.values())
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlEncoder) begin([]string) error {
+	return nil
+}
+
+func (e *jsonlEncoder) encode(rec record) error {
+	return e.enc.Encode(rec)
+}
+
+func (e *jsonlEncoder) flush() error {
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optionalInt(n *int64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatInt(*n, 10)
+}
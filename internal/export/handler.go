@@ -0,0 +1,170 @@
+package export
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type JobServiceAPI interface {
+	Create(ctx context.Context, userID int64, userPermissions []string, dto CreateJobDTO) (*Job, error)
+	Get(ctx context.Context, id, userID int64) (*Job, error)
+	Share(ctx context.Context, id, userID int64) (*capability.Link, error)
+	Shared(ctx context.Context, id, sharedBy int64) (*Job, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service JobServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service JobServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// CreateExport godoc
+// @Summary      Start an export
+// @Description  Queues a CSV or JSONL export of expenses (by expense date) or payments (by creation date). The requester is emailed when it is ready. Requires permission to view all expenses.
+// @Tags         exports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      CreateJobDTO  true  "Export request"
+// @Success      202   {object}  Job
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /exports [post]
+func (h *Handler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto CreateJobDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	job, err := h.Service.Create(r.Context(), user.ID, user.Permissions, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusAccepted, job)
+}
+
+// GetExport godoc
+// @Summary      Get export status
+// @Description  Completed exports include a signed download_url valid until url_expires_at; the file itself is deleted at expires_at.
+// @Tags         exports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Export ID"
+// @Success      200  {object}  Job
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /exports/{id} [get]
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid export ID")
+		return
+	}
+
+	job, err := h.Service.Get(r.Context(), id, user.ID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, job)
+}
+
+// ShareExport godoc
+// @Summary      Share an export
+// @Description  Returns a short-lived link that downloads the requester's completed export for anyone who follows it, without a bearer token. The link only grants downloading this export and stops working once the file is deleted.
+// @Tags         exports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Export ID"
+// @Success      200  {object}  capability.Link
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Failure      409  {object}  transport.AppErrorResponse
+// @Router       /exports/{id}/share [post]
+func (h *Handler) ShareExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid export ID")
+		return
+	}
+
+	link, err := h.Service.Share(r.Context(), id, user.ID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, link)
+}
+
+// DownloadSharedExport godoc
+// @Summary      Download a shared export
+// @Description  Follows a link from POST /exports/{id}/share and redirects to a short-lived download URL for the file. The token grants downloading this export only; no bearer token is needed.
+// @Tags         exports
+// @Param        id     path   int     true  "Export ID"
+// @Param        token  query  string  true  "Capability token"
+// @Success      302
+// @Failure      401  {object}  transport.AppErrorResponse
+// @Failure      403  {object}  transport.AppErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /shared/exports/{id} [get]
+func (h *Handler) DownloadSharedExport(w http.ResponseWriter, r *http.Request) {
+	claims, ok := capability.FromContext(r.Context())
+	if !ok {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid export ID")
+		return
+	}
+
+	job, err := h.Service.Shared(r.Context(), id, claims.Subject)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+	if job.DownloadURL == nil {
+		h.HandleError(w, r, ErrJobNotFound)
+		return
+	}
+
+	http.Redirect(w, r, *job.DownloadURL, http.StatusFound)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	exportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/export"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	// JobStatusExpired jobs had their file removed after the retention period.
+	JobStatusExpired = "expired"
+
+	ResourceExpenses = "expenses"
+	ResourcePayments = "payments"
+)
+
+var (
+	ErrJobNotFound     = errors.NewNotFoundError("Export not found", errors.ErrCodeExportJobNotFound)
+	ErrExportForbidden = errors.NewForbiddenError("exports require permission to view all expenses", errors.ErrCodeExportForbidden)
+	ErrExportNotReady  = errors.NewConflictError("Export has no file to share", errors.ErrCodeExportNotReady)
+)
+
+// SharedPath is where a shared export link points, formatted with the job ID.
+const SharedPath = "/api/v1/shared/exports/%d"
+
+// CreateJobDTO requests an export; From and To are inclusive UTC days.
+type CreateJobDTO struct {
+	Resource string `json:"resource" validate:"required,oneof=expenses payments" example:"expenses"`
+	Format   string `json:"format" validate:"omitempty,oneof=csv jsonl" example:"csv"`
+	From     string `json:"from" validate:"required" example:"2025-01-01"`
+	To       string `json:"to" validate:"required" example:"2025-03-31"`
+}
+
+type Job struct {
+	ID          int64      `json:"id"`
+	Resource    string     `json:"resource"`
+	Format      Format     `json:"format"`
+	From        string     `json:"from"`
+	To          string     `json:"to"`
+	Status      string     `json:"status"`
+	RowCount    *int       `json:"row_count,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// ExpiresAt is when the file is deleted.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// DownloadURL is a signed link, valid until URLExpiresAt.
+	DownloadURL  *string    `json:"download_url,omitempty"`
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+}
+
+func FromJobDataModel(j *exportDatamodel.Job) *Job {
+	return &Job{
+		ID:          j.ID,
+		Resource:    j.Resource,
+		Format:      Format(j.Format),
+		From:        j.RangeFrom.UTC().Format("2006-01-02"),
+		To:          j.RangeTo.UTC().AddDate(0, 0, -1).Format("2006-01-02"),
+		Status:      j.Status,
+		RowCount:    j.RowCount,
+		Error:       j.Error,
+		CreatedAt:   j.CreatedAt,
+		CompletedAt: j.CompletedAt,
+		ExpiresAt:   j.ExpiresAt,
+	}
+}
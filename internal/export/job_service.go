@@ -0,0 +1,313 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	exportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/export"
+	"github.com/frahmantamala/expense-management/internal/notification"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type JobRepositoryAPI interface {
+	Create(job *exportDatamodel.Job) error
+	GetByID(id int64) (*exportDatamodel.Job, error)
+	// ClaimNext marks the oldest queued job running and returns it, or nil
+	// when the queue is empty. Concurrent workers never claim the same job.
+	ClaimNext(now time.Time) (*exportDatamodel.Job, error)
+	Complete(id int64, blobKey string, rowCount int, completedAt, expiresAt time.Time) error
+	Fail(id int64, reason string, completedAt time.Time) error
+	ListExpired(now time.Time, limit int) ([]*exportDatamodel.Job, error)
+	MarkExpired(id int64) error
+	// GetUserEmail returns an empty email when the user turned export
+	// emails off.
+	GetUserEmail(userID int64) (string, error)
+}
+
+type JobConfig struct {
+	// BaseURL is used for the link in the completion email.
+	BaseURL string
+	// Retention is how long finished files are kept.
+	Retention time.Duration
+	// LinkExpiry bounds the signed download URLs returned by Get.
+	LinkExpiry time.Duration
+}
+
+// expiredBatchSize bounds the files removed per purge pass.
+const expiredBatchSize = 100
+
+// JobService runs exports in the background: Create queues a job, a Worker
+// writes the file to blob storage and emails the requester, and Get hands out
+// a signed download link until the file expires.
+type JobService struct {
+	repo              JobRepositoryAPI
+	exporter          *Service
+	blob              storage.Blob
+	mailer            notification.Mailer
+	permissionChecker auth.PermissionChecker
+	links             *capability.Issuer
+	cfg               JobConfig
+	logger            *slog.Logger
+}
+
+func NewJobService(repo JobRepositoryAPI, exporter *Service, blob storage.Blob, mailer notification.Mailer, permissionChecker auth.PermissionChecker, links *capability.Issuer, cfg JobConfig, logger *slog.Logger) *JobService {
+	return &JobService{
+		repo:              repo,
+		exporter:          exporter,
+		blob:              blob,
+		mailer:            mailer,
+		permissionChecker: permissionChecker,
+		links:             links,
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+func (s *JobService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *JobService) Create(ctx context.Context, userID int64, userPermissions []string, dto CreateJobDTO) (*Job, error) {
+	if !s.permissionChecker.CanViewAllExpenses(userPermissions) {
+		return nil, ErrExportForbidden
+	}
+	if err := validation.Struct(dto); err != nil {
+		return nil, err
+	}
+
+	rng, err := ParseDayRange(dto.From, dto.To)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error(), errors.ErrCodeValidationFailed)
+	}
+	format := FormatCSV
+	if dto.Format != "" {
+		format = Format(dto.Format)
+	}
+
+	job := &exportDatamodel.Job{
+		UserID:    userID,
+		Resource:  dto.Resource,
+		Format:    string(format),
+		RangeFrom: rng.From,
+		RangeTo:   rng.To,
+		Status:    JobStatusQueued,
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		job.TenantID = tenantID
+	}
+	if err := s.repo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to queue export: %w", err)
+	}
+
+	s.log(ctx).Info("export queued", "job_id", job.ID, "user_id", userID, "resource", job.Resource, "format", job.Format)
+	return FromJobDataModel(job), nil
+}
+
+// Get returns the requester's own job, with a download link once completed.
+// Other users' jobs are reported as not found.
+func (s *JobService) Get(ctx context.Context, id, userID int64) (*Job, error) {
+	j, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	if j == nil || j.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+
+	return s.withDownloadURL(ctx, j)
+}
+
+// Share returns a link that downloads the requester's completed export for
+// whoever follows it, until the capability token in it expires or the file
+// is deleted, whichever comes first.
+func (s *JobService) Share(ctx context.Context, id, userID int64) (*capability.Link, error) {
+	j, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	if j == nil || j.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+	if !downloadable(j, time.Now()) {
+		return nil, ErrExportNotReady
+	}
+
+	link, err := s.links.Link(ctx, capability.ScopeExportDownload, id, userID, fmt.Sprintf(SharedPath, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue export link: %w", err)
+	}
+	s.log(ctx).Info("export link shared", "job_id", id, "user_id", userID, "expires_at", link.ExpiresAt)
+	return link, nil
+}
+
+// Shared returns the job with a download link for a request let in by an
+// export:download capability token; the token has already been checked.
+// sharedBy is the token's subject, who must still own the job.
+func (s *JobService) Shared(ctx context.Context, id, sharedBy int64) (*Job, error) {
+	j, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	if j == nil || j.UserID != sharedBy || !downloadable(j, time.Now()) {
+		return nil, ErrJobNotFound
+	}
+	return s.withDownloadURL(ctx, j)
+}
+
+func downloadable(j *exportDatamodel.Job, now time.Time) bool {
+	return j.Status == JobStatusCompleted && j.BlobKey != nil && j.ExpiresAt != nil && now.Before(*j.ExpiresAt)
+}
+
+// withDownloadURL signs a link to a completed job's file that never outlives
+// the file itself.
+func (s *JobService) withDownloadURL(ctx context.Context, j *exportDatamodel.Job) (*Job, error) {
+	job := FromJobDataModel(j)
+	if j.Status != JobStatusCompleted || j.BlobKey == nil || j.ExpiresAt == nil {
+		return job, nil
+	}
+
+	expiry := s.cfg.LinkExpiry
+	if remaining := time.Until(*j.ExpiresAt); remaining < expiry {
+		expiry = remaining
+	}
+	if expiry <= 0 {
+		return job, nil
+	}
+
+	url, err := s.blob.SignedURL(ctx, *j.BlobKey, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign export url: %w", err)
+	}
+	urlExpiresAt := time.Now().Add(expiry).UTC()
+	job.DownloadURL, job.URLExpiresAt = &url, &urlExpiresAt
+	return job, nil
+}
+
+// ProcessNext runs one queued job and reports whether there was one.
+func (s *JobService) ProcessNext(ctx context.Context) (bool, error) {
+	j, err := s.repo.ClaimNext(time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim export job: %w", err)
+	}
+	if j == nil {
+		return false, nil
+	}
+
+	// Jobs are claimed across tenants; each runs within its requester's.
+	ctx = tenant.NewContext(ctx, j.TenantID)
+	key, rows, runErr := s.run(ctx, j)
+	now := time.Now()
+	if runErr != nil {
+		s.log(ctx).Error("export job failed", "job_id", j.ID, "error", runErr)
+		if err := s.repo.Fail(j.ID, runErr.Error(), now); err != nil {
+			return true, fmt.Errorf("failed to record export failure: %w", err)
+		}
+		s.notify(ctx, j, "Your export failed", fmt.Sprintf("Export #%d of %s could not be generated: %v\n", j.ID, j.Resource, runErr))
+		return true, nil
+	}
+
+	expiresAt := now.Add(s.cfg.Retention)
+	if err := s.repo.Complete(j.ID, key, rows, now, expiresAt); err != nil {
+		return true, fmt.Errorf("failed to record export completion: %w", err)
+	}
+
+	s.log(ctx).Info("export job completed", "job_id", j.ID, "rows", rows, "key", key)
+	s.notify(ctx, j, "Your export is ready", fmt.Sprintf(
+		"Export #%d of %s (%d rows) is ready. Download it before %s from:\n%s/api/v1/exports/%d\n",
+		j.ID, j.Resource, rows, expiresAt.UTC().Format(time.RFC1123), s.cfg.BaseURL, j.ID))
+	return true, nil
+}
+
+// run writes the export to a temporary file first so the upload knows its size
+// and a failed query never leaves a partial object behind.
+func (s *JobService) run(ctx context.Context, j *exportDatamodel.Job) (string, int, error) {
+	tmp, err := os.CreateTemp("", "export-*."+j.Format)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	buffered := bufio.NewWriter(tmp)
+	rng := Range{From: j.RangeFrom, To: j.RangeTo}
+	var rows int
+	switch j.Resource {
+	case ResourceExpenses:
+		rows, err = s.exporter.ExportExpenses(ctx, buffered, Format(j.Format), rng)
+	case ResourcePayments:
+		rows, err = s.exporter.ExportPayments(ctx, buffered, Format(j.Format), rng)
+	default:
+		err = fmt.Errorf("unknown export resource %q", j.Resource)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return "", 0, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+
+	format := Format(j.Format)
+	key := fmt.Sprintf("exports/%d/%s_%s.%s", j.ID, j.Resource, j.RangeFrom.UTC().Format("2006-01-02"), format)
+	if err := s.blob.Put(ctx, key, tmp, storage.PutOptions{ContentType: format.ContentType(), Size: size}); err != nil {
+		return "", 0, fmt.Errorf("failed to upload export: %w", err)
+	}
+	return key, rows, nil
+}
+
+func (s *JobService) notify(ctx context.Context, j *exportDatamodel.Job, subject, body string) {
+	email, err := s.repo.GetUserEmail(j.UserID)
+	if err != nil {
+		s.log(ctx).Warn("export notification skipped", "job_id", j.ID, "error", err)
+		return
+	}
+	if email == "" {
+		s.log(ctx).Info("export notification skipped, user opted out", "job_id", j.ID, "user_id", j.UserID)
+		return
+	}
+	if err := s.mailer.Send(ctx, notification.Message{To: email, Subject: subject, Body: body}); err != nil {
+		s.log(ctx).Error("failed to send export notification", "job_id", j.ID, "error", err)
+	}
+}
+
+// PurgeExpired deletes files past their retention and returns how many jobs
+// were expired.
+func (s *JobService) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	jobs, err := s.repo.ListExpired(now, expiredBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired exports: %w", err)
+	}
+
+	purged := 0
+	for _, j := range jobs {
+		if j.BlobKey != nil {
+			if err := s.blob.Delete(ctx, *j.BlobKey); err != nil {
+				s.log(ctx).Error("failed to delete expired export", "job_id", j.ID, "error", err)
+				continue
+			}
+		}
+		if err := s.repo.MarkExpired(j.ID); err != nil {
+			return purged, fmt.Errorf("failed to mark export expired: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,270 @@
+package export_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/capability"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	exportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/export"
+	"github.com/frahmantamala/expense-management/internal/export"
+	"github.com/frahmantamala/expense-management/internal/notification"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type fakeJobRepository struct {
+	jobs   map[int64]*exportDatamodel.Job
+	nextID int64
+}
+
+func (f *fakeJobRepository) Create(job *exportDatamodel.Job) error {
+	f.nextID++
+	job.ID = f.nextID
+	job.CreatedAt = time.Now()
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeJobRepository) GetByID(id int64) (*exportDatamodel.Job, error) {
+	return f.jobs[id], nil
+}
+
+func (f *fakeJobRepository) ClaimNext(now time.Time) (*exportDatamodel.Job, error) {
+	for id := int64(1); id <= f.nextID; id++ {
+		if j := f.jobs[id]; j != nil && j.Status == export.JobStatusQueued {
+			j.Status, j.StartedAt = export.JobStatusRunning, &now
+			return j, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeJobRepository) Complete(id int64, blobKey string, rowCount int, completedAt, expiresAt time.Time) error {
+	j := f.jobs[id]
+	j.Status, j.BlobKey, j.RowCount, j.CompletedAt, j.ExpiresAt = export.JobStatusCompleted, &blobKey, &rowCount, &completedAt, &expiresAt
+	return nil
+}
+
+func (f *fakeJobRepository) Fail(id int64, reason string, completedAt time.Time) error {
+	j := f.jobs[id]
+	j.Status, j.Error, j.CompletedAt = export.JobStatusFailed, &reason, &completedAt
+	return nil
+}
+
+func (f *fakeJobRepository) ListExpired(now time.Time, limit int) ([]*exportDatamodel.Job, error) {
+	var out []*exportDatamodel.Job
+	for _, j := range f.jobs {
+		if j.Status == export.JobStatusCompleted && !j.ExpiresAt.After(now) {
+			out = append(out, j)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeJobRepository) MarkExpired(id int64) error {
+	f.jobs[id].Status = export.JobStatusExpired
+	return nil
+}
+
+func (f *fakeJobRepository) GetUserEmail(userID int64) (string, error) {
+	return "finance@example.com", nil
+}
+
+type recordingMailer struct {
+	sent []notification.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg notification.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var _ = Describe("JobService", func() {
+	var (
+		ctx      context.Context
+		repo     *fakeJobRepository
+		exports  *fakeExportRepository
+		blob     *storage.Local
+		mailer   *recordingMailer
+		links    *capability.Issuer
+		service  *export.JobService
+		finance  = []string{"view_all_expenses"}
+		validDTO export.CreateJobDTO
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &fakeJobRepository{jobs: map[int64]*exportDatamodel.Job{}}
+		day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		exports = &fakeExportRepository{expenses: []*expenseDatamodel.Expense{
+			{ID: 1, UserID: 7, AmountIDR: 150000, Description: "Taxi", Category: "travel", ExpenseStatus: "approved", ExpenseDate: day, SubmittedAt: day, CreatedAt: day},
+		}}
+		mailer = &recordingMailer{}
+
+		var err error
+		blob, err = storage.NewLocal(GinkgoT().TempDir(), "http://localhost:8080", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		links = capability.NewIssuer("capability-secret", "http://localhost:8080", 15*time.Minute)
+		service = export.NewJobService(repo, export.NewService(exports, logger), blob, mailer, auth.NewPermissionChecker(), links,
+			export.JobConfig{BaseURL: "http://localhost:8080", Retention: 24 * time.Hour, LinkExpiry: time.Hour}, logger)
+		validDTO = export.CreateJobDTO{Resource: "expenses", From: "2025-03-01", To: "2025-03-31"}
+	})
+
+	Describe("Create", func() {
+		It("queues a CSV export by default", func() {
+			job, err := service.Create(ctx, 5, finance, validDTO)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(export.JobStatusQueued))
+			Expect(job.Format).To(Equal(export.FormatCSV))
+			Expect(job.From).To(Equal("2025-03-01"))
+			Expect(job.To).To(Equal("2025-03-31"))
+		})
+
+		It("requires permission to view all expenses", func() {
+			_, err := service.Create(ctx, 5, []string{"approve_expenses"}, validDTO)
+			Expect(err).To(Equal(export.ErrExportForbidden))
+		})
+
+		It("validates the request", func() {
+			_, err := service.Create(ctx, 5, finance, export.CreateJobDTO{Resource: "users", Format: "xml", From: "2025-03-01", To: "2025-03-31"})
+			appErr, ok := errors.IsAppError(err)
+			Expect(ok).To(BeTrue())
+			Expect(appErr.Details.(errors.ValidationErrors).Errors).To(HaveLen(2))
+
+			_, err = service.Create(ctx, 5, finance, export.CreateJobDTO{Resource: "expenses", From: "2025-03-31", To: "2025-03-01"})
+			_, ok = errors.IsAppError(err)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("processing", func() {
+		var jobID int64
+
+		BeforeEach(func() {
+			job, err := service.Create(ctx, 5, finance, validDTO)
+			Expect(err).NotTo(HaveOccurred())
+			jobID = job.ID
+		})
+
+		It("uploads the file, emails the requester and signs a download link", func() {
+			processed, err := service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeTrue())
+
+			job, err := service.Get(ctx, jobID, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(export.JobStatusCompleted))
+			Expect(*job.RowCount).To(Equal(1))
+			Expect(job.DownloadURL).NotTo(BeNil())
+			Expect(job.URLExpiresAt.Before(*job.ExpiresAt)).To(BeTrue())
+
+			u, err := url.Parse(*job.DownloadURL)
+			Expect(err).NotTo(HaveOccurred())
+			rc, err := blob.Get(ctx, strings.TrimPrefix(u.Path, storage.LocalPathPrefix))
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := io.ReadAll(rc)
+			rc.Close()
+			Expect(string(body)).To(ContainSubstring("Taxi"))
+
+			Expect(mailer.sent).To(HaveLen(1))
+			Expect(mailer.sent[0].To).To(Equal("finance@example.com"))
+			Expect(mailer.sent[0].Body).To(ContainSubstring("/api/v1/exports/1"))
+
+			processed, err = service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeFalse())
+		})
+
+		It("runs the job within the requester's tenant", func() {
+			job, err := service.Create(tenant.NewContext(ctx, 4), 5, finance, validDTO)
+			Expect(err).NotTo(HaveOccurred())
+			repo.jobs[jobID].Status = export.JobStatusCompleted
+
+			_, err = service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.jobs[job.ID].TenantID).To(Equal(int64(4)))
+			Expect(exports.tenantID).To(Equal(int64(4)))
+		})
+
+		It("records failures and tells the requester", func() {
+			exports.streamErr = io.ErrUnexpectedEOF
+
+			_, err := service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			job, err := service.Get(ctx, jobID, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(export.JobStatusFailed))
+			Expect(*job.Error).To(ContainSubstring("unexpected EOF"))
+			Expect(job.DownloadURL).To(BeNil())
+			Expect(mailer.sent[0].Subject).To(Equal("Your export failed"))
+		})
+
+		It("hides other users' exports", func() {
+			_, err := service.Get(ctx, jobID, 6)
+			Expect(err).To(Equal(export.ErrJobNotFound))
+		})
+
+		It("shares completed exports with an export:download link", func() {
+			_, err := service.Share(ctx, jobID, 5)
+			Expect(err).To(Equal(export.ErrExportNotReady))
+
+			_, err = service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Share(ctx, jobID, 6)
+			Expect(err).To(Equal(export.ErrJobNotFound))
+
+			link, err := service.Share(ctx, jobID, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Scope).To(Equal(capability.ScopeExportDownload))
+			Expect(link.URL).To(HavePrefix("http://localhost:8080/api/v1/shared/exports/1?token="))
+
+			job, err := service.Shared(ctx, jobID, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.DownloadURL).NotTo(BeNil())
+
+			_, err = service.Shared(ctx, jobID, 6)
+			Expect(err).To(Equal(export.ErrJobNotFound))
+		})
+
+		It("stops serving shared links once the file expires", func() {
+			_, err := service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.PurgeExpired(ctx, time.Now().Add(48*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Shared(ctx, jobID, 5)
+			Expect(err).To(Equal(export.ErrJobNotFound))
+		})
+
+		It("deletes files once they expire", func() {
+			_, err := service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			key := *repo.jobs[jobID].BlobKey
+
+			purged, err := service.PurgeExpired(ctx, time.Now().Add(48*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(purged).To(Equal(1))
+
+			_, err = blob.Get(ctx, key)
+			Expect(err).To(MatchError(storage.ErrNotFound))
+			job, err := service.Get(ctx, jobID, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(export.JobStatusExpired))
+			Expect(job.DownloadURL).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/export"
+	"gorm.io/gorm"
+)
+
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) export.RepositoryAPI {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) StreamExpenses(ctx context.Context, rng export.Range, fn func(*expenseDatamodel.Expense) error) error {
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Where("expense_date >= ? AND expense_date < ?", rng.From, rng.To).
+		Order("id ASC")
+
+	return stream(r.db, query, fn)
+}
+
+func (r *ExportRepository) StreamPayments(ctx context.Context, rng export.Range, fn func(*paymentDatamodel.Payment) error) error {
+	query := r.db.WithContext(ctx).Model(&paymentDatamodel.Payment{}).
+		Where("created_at >= ? AND created_at < ?", rng.From, rng.To).
+		Order("id ASC")
+
+	return stream(r.db, query, fn)
+}
+
+// stream iterates a cursor instead of loading the result set, so exports of
+// large ranges run in constant memory.
+func stream[T any](db, query *gorm.DB, fn func(*T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
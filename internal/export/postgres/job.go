@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	exportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/export"
+	"github.com/frahmantamala/expense-management/internal/export"
+	"gorm.io/gorm"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) export.JobRepositoryAPI {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) Create(job *exportDatamodel.Job) error {
+	return r.db.Create(job).Error
+}
+
+func (r *JobRepository) GetByID(id int64) (*exportDatamodel.Job, error) {
+	var job exportDatamodel.Job
+	err := r.db.First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext uses SKIP LOCKED so several servers can run export workers.
+func (r *JobRepository) ClaimNext(now time.Time) (*exportDatamodel.Job, error) {
+	var jobs []*exportDatamodel.Job
+	err := r.db.Raw(`UPDATE export_jobs SET status = ?, started_at = ?
+		WHERE id = (
+			SELECT id FROM export_jobs WHERE status = ?
+			ORDER BY id LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, export.JobStatusRunning, now, export.JobStatusQueued).
+		Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+func (r *JobRepository) Complete(id int64, blobKey string, rowCount int, completedAt, expiresAt time.Time) error {
+	return r.db.Model(&exportDatamodel.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       export.JobStatusCompleted,
+			"blob_key":     blobKey,
+			"row_count":    rowCount,
+			"completed_at": completedAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *JobRepository) Fail(id int64, reason string, completedAt time.Time) error {
+	return r.db.Model(&exportDatamodel.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       export.JobStatusFailed,
+			"error":        reason,
+			"completed_at": completedAt,
+		}).Error
+}
+
+func (r *JobRepository) ListExpired(now time.Time, limit int) ([]*exportDatamodel.Job, error) {
+	var jobs []*exportDatamodel.Job
+	err := r.db.Where("status = ? AND expires_at <= ?", export.JobStatusCompleted, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *JobRepository) MarkExpired(id int64) error {
+	return r.db.Model(&exportDatamodel.Job{}).
+		Where("id = ?", id).
+		Update("status", export.JobStatusExpired).Error
+}
+
+func (r *JobRepository) GetUserEmail(userID int64) (string, error) {
+	var email string
+	err := r.db.Table("users").Select("email").Where("id = ? AND export_emails", userID).Scan(&email).Error
+	return email, err
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI streams rows to fn one at a time; returning an error from fn
+// stops the iteration.
+type RepositoryAPI interface {
+	StreamExpenses(ctx context.Context, r Range, fn func(*expenseDatamodel.Expense) error) error
+	StreamPayments(ctx context.Context, r Range, fn func(*paymentDatamodel.Payment) error) error
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// ExportExpenses writes expenses whose expense_date falls in r and returns the
+// number of rows written.
+func (s *Service) ExportExpenses(ctx context.Context, w io.Writer, format Format, r Range) (int, error) {
+	enc, err := s.start(format, w, r, expenseHeader)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = s.repo.StreamExpenses(ctx, r, func(e *expenseDatamodel.Expense) error {
+		count++
+		return enc.encode(NewExpenseRecord(e))
+	})
+	return s.finish(ctx, enc, "expenses", count, err)
+}
+
+// ExportPayments writes payments created in r and returns the number of rows
+// written.
+func (s *Service) ExportPayments(ctx context.Context, w io.Writer, format Format, r Range) (int, error) {
+	enc, err := s.start(format, w, r, paymentHeader)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = s.repo.StreamPayments(ctx, r, func(p *paymentDatamodel.Payment) error {
+		count++
+		return enc.encode(NewPaymentRecord(p))
+	})
+	return s.finish(ctx, enc, "payments", count, err)
+}
+
+func (s *Service) start(format Format, w io.Writer, r Range, header []string) (encoder, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	enc, err := newEncoder(format, w)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.begin(header); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return enc, nil
+}
+
+func (s *Service) finish(ctx context.Context, enc encoder, resource string, count int, streamErr error) (int, error) {
+	if flushErr := enc.flush(); streamErr == nil {
+		streamErr = flushErr
+	}
+	if streamErr != nil {
+		s.log(ctx).Error("export failed", "resource", resource, "rows_written", count, "error", streamErr)
+		return count, fmt.Errorf("failed to export %s: %w", resource, streamErr)
+	}
+
+	s.log(ctx).Info("export completed", "resource", resource, "rows", count)
+	return count, nil
+}
@@ -0,0 +1,132 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/export"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type fakeExportRepository struct {
+	expenses  []*expenseDatamodel.Expense
+	payments  []*paymentDatamodel.Payment
+	streamErr error
+	// tenantID is the tenant the last stream ran within.
+	tenantID int64
+}
+
+func (f *fakeExportRepository) StreamExpenses(ctx context.Context, _ export.Range, fn func(*expenseDatamodel.Expense) error) error {
+	f.tenantID, _ = tenant.FromContext(ctx)
+	for _, e := range f.expenses {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return f.streamErr
+}
+
+func (f *fakeExportRepository) StreamPayments(_ context.Context, _ export.Range, fn func(*paymentDatamodel.Payment) error) error {
+	for _, p := range f.payments {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return f.streamErr
+}
+
+var _ = Describe("Service", func() {
+	var (
+		repo *fakeExportRepository
+		svc  *export.Service
+		buf  *bytes.Buffer
+		rng  export.Range
+	)
+
+	BeforeEach(func() {
+		day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		approverID := int64(42)
+		repo = &fakeExportRepository{
+			expenses: []*expenseDatamodel.Expense{
+				{ID: 1, UserID: 7, AmountIDR: 150000, Description: "Taxi, airport", Category: "travel", ExpenseStatus: "approved", ExpenseDate: day, SubmittedAt: day, CreatedAt: day, ApprovedBy: &approverID},
+				{ID: 2, UserID: 8, AmountIDR: 50000, Description: "Lunch", Category: "meals", ExpenseStatus: "pending_approval", ExpenseDate: day, SubmittedAt: day, CreatedAt: day},
+			},
+			payments: []*paymentDatamodel.Payment{
+				{ID: 3, ExpenseID: 1, ExternalID: "exp-1-150000", AmountIDR: 150000, Status: "success", CreatedAt: day},
+			},
+		}
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		svc = export.NewService(repo, logger)
+		buf = &bytes.Buffer{}
+		rng = export.Range{From: day, To: day.AddDate(0, 1, 0)}
+	})
+
+	It("writes CSV with a header row and quoted fields", func() {
+		count, err := svc.ExportExpenses(context.Background(), buf, export.FormatCSV, rng)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(HavePrefix("id,user_id,amount_idr,description"))
+		Expect(lines[1]).To(ContainSubstring(`"Taxi, airport"`))
+		Expect(lines[1]).To(ContainSubstring("2025-03-01"))
+		Expect(lines[0]).To(HaveSuffix("approved_by,rejected_by"))
+		Expect(lines[1]).To(HaveSuffix(",42,"))
+	})
+
+	It("writes one JSON object per line", func() {
+		count, err := svc.ExportPayments(context.Background(), buf, export.FormatJSONL, rng)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+
+		var rec export.PaymentRecord
+		Expect(json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &rec)).To(Succeed())
+		Expect(rec.ExternalID).To(Equal("exp-1-150000"))
+		Expect(rec.Status).To(Equal("success"))
+	})
+
+	It("still writes the CSV header when nothing matches", func() {
+		repo.payments = nil
+
+		count, err := svc.ExportPayments(context.Background(), buf, export.FormatCSV, rng)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeZero())
+		Expect(strings.TrimSpace(buf.String())).To(HavePrefix("id,expense_id,external_id"))
+	})
+
+	It("rejects an empty or inverted range", func() {
+		_, err := svc.ExportExpenses(context.Background(), buf, export.FormatCSV, export.Range{From: rng.To, To: rng.From})
+		Expect(err).To(HaveOccurred())
+		Expect(buf.Len()).To(BeZero())
+	})
+
+	It("surfaces streaming errors", func() {
+		repo.streamErr = errors.New("connection reset")
+
+		_, err := svc.ExportExpenses(context.Background(), buf, export.FormatJSONL, rng)
+		Expect(err).To(MatchError(ContainSubstring("connection reset")))
+	})
+
+	It("parses formats case-insensitively", func() {
+		format, err := export.ParseFormat("JSONL")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(format).To(Equal(export.FormatJSONL))
+
+		_, err = export.ParseFormat("xml")
+		Expect(errors.Is(err, export.ErrUnsupportedFormat)).To(BeTrue())
+	})
+})
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Worker polls for queued export jobs and removes expired files.
+type Worker struct {
+	service  *JobService
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewWorker(service *JobService, interval time.Duration, logger *slog.Logger) *Worker {
+	return &Worker{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("export worker started", "poll_interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("export worker stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// Drain the queue before sleeping again.
+		for ctx.Err() == nil {
+			processed, err := w.service.ProcessNext(ctx)
+			if err != nil {
+				w.logger.Error("export job run failed", "error", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		if _, err := w.service.PurgeExpired(ctx, time.Now()); err != nil {
+			w.logger.Error("export purge failed", "error", err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+// maxBodyBytes bounds integration request bodies, which must be read whole
+// before their signature can be checked.
+const maxBodyBytes = 64 << 10
+
+type ServiceAPI interface {
+	Decide(ctx context.Context, client *Client, key string, body []byte, dto DecisionDTO) (*Result, bool, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+	clients []*Client
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI, clients []*Client) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+		clients:     clients,
+	}
+}
+
+// Decide godoc
+// @Summary      Decide an expense from a workflow engine
+// @Description  Approves or rejects an expense as the integration's service account, with that account's current permissions. Requests carry the integration's api_key as a bearer token, an X-Signature of "sha256=" + hex(HMAC-SHA256(signing_secret, timestamp + "." + body)) with the Unix timestamp in X-Signature-Timestamp, and an Idempotency-Key. A retry with the same key and body returns the original outcome with Idempotent-Replayed: true; reusing a key for another body fails with IDEMPOTENCY_CONFLICT. Every decision is audited under the integration's name, with reference and decided_by when given.
+// @Tags         integrations
+// @Accept       json
+// @Produce      json
+// @Param        Authorization          header  string       true  "Bearer api_key"
+// @Param        X-Signature-Timestamp  header  string       true  "Unix timestamp"
+// @Param        X-Signature            header  string       true  "Request signature"
+// @Param        Idempotency-Key        header  string       true  "Unique key per decision"
+// @Param        body                   body    DecisionDTO  true  "Decision"
+// @Success      200  {object}  Result
+// @Failure      400  {object}  transport.AppErrorResponse
+// @Failure      401  {object}  transport.AppErrorResponse
+// @Failure      403  {object}  transport.AppErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Failure      409  {object}  transport.AppErrorResponse
+// @Router       /integrations/approvals [post]
+func (h *Handler) Decide(w http.ResponseWriter, r *http.Request) {
+	client, body, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var dto DecisionDTO
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	result, replayed, err := h.Service.Decide(r.Context(), client, r.Header.Get(HeaderIdempotencyKey), body, dto)
+	if replayed {
+		w.Header().Set(HeaderReplayed, "true")
+	}
+	if err != nil {
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
+
+// authenticate picks the client by its api key and checks the body's
+// signature.
+func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (*Client, []byte, bool) {
+	token := h.ExtractTokenFromHeader(r)
+	var client *Client
+	for _, c := range h.clients {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(c.APIKey)) == 1 {
+			client = c
+		}
+	}
+	if token == "" || client == nil {
+		h.Log(r).Warn("integration request with invalid api key", "remote_addr", r.RemoteAddr)
+		h.HandleError(w, r, ErrInvalidAPIKey)
+		return nil, nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "failed to read request body")
+		return nil, nil, false
+	}
+
+	if err := client.Verify(r.Header, body, time.Now()); err != nil {
+		h.Log(r).Warn("integration request refused", "error", err, "integration", client.Name, "remote_addr", r.RemoteAddr)
+		h.HandleError(w, r, err)
+		return nil, nil, false
+	}
+	return client, body, true
+}
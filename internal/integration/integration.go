@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+const (
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderTimestamp      = "X-Signature-Timestamp"
+	HeaderSignature      = "X-Signature"
+	// HeaderReplayed is set on responses repeated from an earlier request
+	// with the same idempotency key.
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+// maxSkew is how far a request timestamp may be from now, which bounds
+// replays of captured requests.
+const maxSkew = 5 * time.Minute
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	ErrInvalidAPIKey    = errors.NewUnauthorizedError("Invalid integration api key", errors.ErrCodeInvalidToken)
+	ErrInvalidSignature = errors.NewUnauthorizedError("Invalid integration request signature", errors.ErrCodeInvalidToken)
+	ErrStaleRequest     = errors.NewUnauthorizedError("Integration request is too old", errors.ErrCodeTokenExpired)
+	ErrIdempotencyKey   = errors.NewValidationFieldError(HeaderIdempotencyKey, "Idempotency-Key header is required and at most 255 characters", errors.ErrCodeValidationFailed)
+	ErrKeyReused        = errors.NewConflictError("Idempotency-Key was already used for a different request", errors.ErrCodeIdempotencyConflict)
+	ErrInProgress       = errors.NewConflictError("A request with this Idempotency-Key is still being processed", errors.ErrCodeIdempotencyConflict)
+)
+
+// Client is an external workflow engine allowed to decide expenses. It
+// acts as the service account with email User.
+type Client struct {
+	Name          string
+	APIKey        string
+	SigningSecret string
+	User          string
+}
+
+// Verify checks that body was signed with the client's signing secret:
+// X-Signature is "sha256=" + hex(HMAC-SHA256(timestamp + "." + body)), with
+// the Unix timestamp sent in X-Signature-Timestamp.
+func (c *Client) Verify(header http.Header, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > maxSkew || skew < -maxSkew {
+		return ErrStaleRequest
+	}
+
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(c.Sign(ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the X-Signature of body sent at ts.
+func (c *Client) Sign(ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(c.SigningSecret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DecisionDTO asks for an expense to be approved or rejected. Reference
+// and DecidedBy are the workflow engine's own identifiers, such as the
+// process instance and the person who completed the task; they are
+// recorded in the audit log only.
+type DecisionDTO struct {
+	ExpenseID int64  `json:"expense_id" validate:"required"`
+	Decision  string `json:"decision" validate:"required,oneof=approve reject"`
+	// Code and Reason are required to reject, as on PATCH
+	// /expenses/{id}/reject.
+	Code      string `json:"code,omitempty" enums:"policy_violation,missing_receipt,duplicate,other"`
+	Reason    string `json:"reason,omitempty" validate:"max=1000"`
+	Reference string `json:"reference,omitempty" validate:"max=255"`
+	DecidedBy string `json:"decided_by,omitempty" validate:"max=255"`
+}
+
+func (dto DecisionDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if dto.Decision == DecisionReject {
+		return expense.RejectExpenseDTO{Code: dto.Code, Reason: dto.Reason}.Validate()
+	}
+	return nil
+}
+
+// Result is the outcome of a decision.
+type Result struct {
+	ExpenseID int64  `json:"expense_id"`
+	Decision  string `json:"decision"`
+	// Status is the expense status after the decision.
+	Status string `json:"status"`
+}
@@ -0,0 +1,56 @@
+package integration_test
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/integration"
+)
+
+var _ = Describe("Client.Verify", func() {
+	var (
+		client *integration.Client
+		now    time.Time
+		body   []byte
+	)
+
+	signed := func(ts int64, signature string) http.Header {
+		header := http.Header{}
+		header.Set(integration.HeaderTimestamp, strconv.FormatInt(ts, 10))
+		header.Set(integration.HeaderSignature, signature)
+		return header
+	}
+
+	BeforeEach(func() {
+		client = &integration.Client{Name: "camunda", SigningSecret: "0123456789abcdef0123456789abcdef"}
+		now = time.Unix(1760000000, 0)
+		body = []byte(`{"expense_id":1,"decision":"approve"}`)
+	})
+
+	It("accepts bodies signed with the client's secret", func() {
+		header := signed(now.Unix(), client.Sign(now.Unix(), body))
+		Expect(client.Verify(header, body, now)).To(Succeed())
+	})
+
+	It("refuses altered bodies and other secrets", func() {
+		header := signed(now.Unix(), client.Sign(now.Unix(), body))
+		Expect(client.Verify(header, []byte(`{"expense_id":2,"decision":"approve"}`), now)).To(Equal(integration.ErrInvalidSignature))
+
+		other := &integration.Client{SigningSecret: "fedcba9876543210fedcba9876543210"}
+		header = signed(now.Unix(), other.Sign(now.Unix(), body))
+		Expect(client.Verify(header, body, now)).To(Equal(integration.ErrInvalidSignature))
+	})
+
+	It("refuses requests signed more than five minutes away", func() {
+		ts := now.Add(-6 * time.Minute).Unix()
+		Expect(client.Verify(signed(ts, client.Sign(ts, body)), body, now)).To(Equal(integration.ErrStaleRequest))
+	})
+
+	It("refuses requests without a timestamp", func() {
+		Expect(client.Verify(http.Header{}, body, now)).To(Equal(integration.ErrInvalidSignature))
+	})
+})
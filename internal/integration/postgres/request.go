@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	integrationDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/integration"
+	"github.com/frahmantamala/expense-management/internal/integration"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RequestRepository struct {
+	db *gorm.DB
+}
+
+func NewRequestRepository(db *gorm.DB) integration.RepositoryAPI {
+	return &RequestRepository{db: db}
+}
+
+func (r *RequestRepository) Begin(ctx context.Context, req *integrationDatamodel.Request) (*integrationDatamodel.Request, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client"}, {Name: "idempotency_key"}},
+		DoNothing: true,
+	}).Create(req)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var earlier integrationDatamodel.Request
+	err := r.db.WithContext(ctx).
+		Where("client = ? AND idempotency_key = ?", req.Client, req.IdempotencyKey).
+		First(&earlier).Error
+	if err != nil {
+		return nil, err
+	}
+	return &earlier, nil
+}
+
+func (r *RequestRepository) Complete(ctx context.Context, id int64, statusCode int, response json.RawMessage, completedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&integrationDatamodel.Request{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status_code":  statusCode,
+			"response":     response,
+			"completed_at": completedAt,
+		}).Error
+}
+
+func (r *RequestRepository) Abandon(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Delete(&integrationDatamodel.Request{}, id).Error
+}
@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	integrationDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/integration"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/user"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI stores integration requests by idempotency key within the
+// tenant ctx is scoped to.
+type RepositoryAPI interface {
+	// Begin records req as in progress. When its client already used the
+	// key, nothing is recorded and the earlier request is returned.
+	Begin(ctx context.Context, req *integrationDatamodel.Request) (*integrationDatamodel.Request, error)
+	Complete(ctx context.Context, id int64, statusCode int, response json.RawMessage, completedAt time.Time) error
+	// Abandon drops a request that failed unexpectedly so the key can be
+	// retried.
+	Abandon(ctx context.Context, id int64) error
+}
+
+type UserLookup interface {
+	GetByEmail(email string) (*user.User, error)
+}
+
+type ExpenseDecider interface {
+	ApproveExpense(ctx context.Context, expenseID, managerID int64, userPermissions []string) error
+	RejectExpense(ctx context.Context, expenseID, managerID int64, code, reason string, userPermissions []string) error
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+// Service carries out decisions sent by integration clients. Each decision
+// is made as the client's service account, with its current permissions,
+// and runs at most once per idempotency key.
+type Service struct {
+	repo     RepositoryAPI
+	users    UserLookup
+	expenses ExpenseDecider
+	audit    AuditRecorder
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewService(repo RepositoryAPI, users UserLookup, expenses ExpenseDecider, audit AuditRecorder, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		users:    users,
+		expenses: expenses,
+		audit:    audit,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Decide approves or rejects the expense in dto. body is the raw request
+// the key was sent with: a retry with the same key and body gets the
+// original outcome back, with replayed set, while a different body is
+// refused with ErrKeyReused.
+func (s *Service) Decide(ctx context.Context, client *Client, key string, body []byte, dto DecisionDTO) (*Result, bool, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, false, ErrIdempotencyKey
+	}
+	if err := dto.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	principal, err := s.users.GetByEmail(client.User)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load service account of integration %s: %w", client.Name, err)
+	}
+	if !principal.IsActive {
+		s.log(ctx).Warn("integration service account is inactive", "integration", client.Name, "user_id", principal.ID)
+		return nil, false, errors.ErrUserInactive
+	}
+	ctx = tenant.NewContext(ctx, principal.TenantID)
+
+	hash := sha256.Sum256(body)
+	row := &integrationDatamodel.Request{
+		Client:         client.Name,
+		IdempotencyKey: key,
+		RequestHash:    hex.EncodeToString(hash[:]),
+	}
+	earlier, err := s.repo.Begin(ctx, row)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record integration request: %w", err)
+	}
+	if earlier != nil {
+		if earlier.RequestHash != row.RequestHash {
+			return nil, false, ErrKeyReused
+		}
+		if earlier.StatusCode == 0 {
+			return nil, false, ErrInProgress
+		}
+		result, err := replay(earlier)
+		return result, true, err
+	}
+
+	result, err := s.decide(ctx, client, principal, key, dto)
+	if err != nil {
+		appErr, ok := errors.IsAppError(err)
+		if !ok {
+			if abandonErr := s.repo.Abandon(ctx, row.ID); abandonErr != nil {
+				s.log(ctx).Error("failed to drop integration request", "error", abandonErr, "integration", client.Name)
+			}
+			return nil, false, err
+		}
+		s.complete(ctx, row.ID, appErr.StatusCode, appErr)
+		return nil, false, err
+	}
+
+	s.complete(ctx, row.ID, http.StatusOK, result)
+	return result, false, nil
+}
+
+func (s *Service) decide(ctx context.Context, client *Client, principal *user.User, key string, dto DecisionDTO) (*Result, error) {
+	result := &Result{ExpenseID: dto.ExpenseID, Decision: dto.Decision}
+	auditAction := audit.ActionExpenseApprovedViaIntegration
+	var err error
+	if dto.Decision == DecisionApprove {
+		err = s.expenses.ApproveExpense(ctx, dto.ExpenseID, principal.ID, principal.Permissions)
+		result.Status = expense.ExpenseStatusApproved
+	} else {
+		err = s.expenses.RejectExpense(ctx, dto.ExpenseID, principal.ID, dto.Code, dto.Reason, principal.Permissions)
+		result.Status = expense.ExpenseStatusRejected
+		auditAction = audit.ActionExpenseRejectedViaIntegration
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"integration":     client.Name,
+		"idempotency_key": key,
+	}
+	if dto.Reference != "" {
+		metadata["reference"] = dto.Reference
+	}
+	if dto.DecidedBy != "" {
+		metadata["decided_by"] = dto.DecidedBy
+	}
+	if err := s.audit.Record(&audit.Entry{
+		Action:       auditAction,
+		ResourceType: audit.ResourceExpense,
+		ResourceID:   strconv.FormatInt(dto.ExpenseID, 10),
+		ActorID:      &principal.ID,
+		Metadata:     metadata,
+	}); err != nil {
+		s.log(ctx).Error("failed to audit integration decision", "error", err, "action", auditAction)
+	}
+
+	s.log(ctx).Info("expense decided via integration",
+		"expense_id", dto.ExpenseID,
+		"integration", client.Name,
+		"principal_id", principal.ID,
+		"decision", dto.Decision)
+	return result, nil
+}
+
+// complete stores the outcome for replays. The decision has been made by
+// then, so a failure here is logged rather than returned; a retry would
+// then be refused as still in progress instead of deciding twice.
+func (s *Service) complete(ctx context.Context, id int64, statusCode int, outcome interface{}) {
+	raw, err := json.Marshal(outcome)
+	if err == nil {
+		err = s.repo.Complete(ctx, id, statusCode, raw, s.now())
+	}
+	if err != nil {
+		s.log(ctx).Error("failed to store integration request outcome", "error", err, "request_id", id)
+	}
+}
+
+// replay returns the stored outcome of a completed request.
+func replay(earlier *integrationDatamodel.Request) (*Result, error) {
+	if earlier.StatusCode != http.StatusOK {
+		appErr := &errors.AppError{}
+		if err := json.Unmarshal(earlier.Response, appErr); err != nil {
+			return nil, fmt.Errorf("failed to decode stored integration outcome: %w", err)
+		}
+		appErr.StatusCode = earlier.StatusCode
+		return nil, appErr
+	}
+
+	var result Result
+	if err := json.Unmarshal(earlier.Response, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode stored integration outcome: %w", err)
+	}
+	return &result, nil
+}
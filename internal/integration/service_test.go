@@ -0,0 +1,238 @@
+package integration_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	integrationDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/integration"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/integration"
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+type mockRepository struct {
+	requests map[string]*integrationDatamodel.Request
+	nextID   int64
+}
+
+func (m *mockRepository) Begin(_ context.Context, req *integrationDatamodel.Request) (*integrationDatamodel.Request, error) {
+	key := req.Client + "/" + req.IdempotencyKey
+	if earlier, ok := m.requests[key]; ok {
+		return earlier, nil
+	}
+	m.nextID++
+	req.ID = m.nextID
+	m.requests[key] = req
+	return nil, nil
+}
+
+func (m *mockRepository) Complete(_ context.Context, id int64, statusCode int, response json.RawMessage, completedAt time.Time) error {
+	for _, req := range m.requests {
+		if req.ID == id {
+			req.StatusCode = statusCode
+			req.Response = response
+			req.CompletedAt = &completedAt
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) Abandon(_ context.Context, id int64) error {
+	for key, req := range m.requests {
+		if req.ID == id {
+			delete(m.requests, key)
+		}
+	}
+	return nil
+}
+
+type mockUsers struct {
+	users map[string]*user.User
+}
+
+func (m *mockUsers) GetByEmail(email string) (*user.User, error) {
+	if u, ok := m.users[email]; ok {
+		return u, nil
+	}
+	return nil, user.ErrNotFound
+}
+
+type mockExpenses struct {
+	decisions []string
+	err       error
+}
+
+func (m *mockExpenses) ApproveExpense(_ context.Context, _, _ int64, _ []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.decisions = append(m.decisions, "approve")
+	return nil
+}
+
+func (m *mockExpenses) RejectExpense(_ context.Context, _, _ int64, code, _ string, _ []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.decisions = append(m.decisions, "reject:"+code)
+	return nil
+}
+
+type mockAudit struct {
+	entries []*audit.Entry
+}
+
+func (m *mockAudit) Record(entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx      context.Context
+		repo     *mockRepository
+		users    *mockUsers
+		expenses *mockExpenses
+		auditLog *mockAudit
+		service  *integration.Service
+		client   *integration.Client
+		approve  integration.DecisionDTO
+		body     []byte
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{requests: map[string]*integrationDatamodel.Request{}}
+		users = &mockUsers{users: map[string]*user.User{
+			"workflow@example.com": {ID: 9, TenantID: 2, IsActive: true, Permissions: []string{"approve_expenses", "reject_expenses"}},
+		}}
+		expenses = &mockExpenses{}
+		auditLog = &mockAudit{}
+		service = integration.NewService(repo, users, expenses, auditLog, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		client = &integration.Client{Name: "camunda", User: "workflow@example.com"}
+		approve = integration.DecisionDTO{ExpenseID: 7, Decision: integration.DecisionApprove, Reference: "proc-42", DecidedBy: "jdoe"}
+		body = []byte(`{"expense_id":7,"decision":"approve"}`)
+	})
+
+	It("decides as the service account and audits the integration", func() {
+		result, replayed, err := service.Decide(ctx, client, "key-1", body, approve)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replayed).To(BeFalse())
+		Expect(result).To(Equal(&integration.Result{ExpenseID: 7, Decision: "approve", Status: expense.ExpenseStatusApproved}))
+		Expect(expenses.decisions).To(Equal([]string{"approve"}))
+
+		Expect(auditLog.entries).To(HaveLen(1))
+		entry := auditLog.entries[0]
+		Expect(entry.Action).To(Equal(audit.ActionExpenseApprovedViaIntegration))
+		Expect(*entry.ActorID).To(Equal(int64(9)))
+		Expect(entry.Metadata).To(Equal(map[string]interface{}{
+			"integration":     "camunda",
+			"idempotency_key": "key-1",
+			"reference":       "proc-42",
+			"decided_by":      "jdoe",
+		}))
+	})
+
+	It("replays the outcome of a retried request without deciding again", func() {
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, replayed, err := service.Decide(ctx, client, "key-1", body, approve)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replayed).To(BeTrue())
+		Expect(result.Status).To(Equal(expense.ExpenseStatusApproved))
+		Expect(expenses.decisions).To(HaveLen(1))
+		Expect(auditLog.entries).To(HaveLen(1))
+	})
+
+	It("replays refused decisions too", func() {
+		expenses.err = expense.ErrInvalidExpenseStatus
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).To(Equal(expense.ErrInvalidExpenseStatus))
+
+		expenses.err = nil
+		_, replayed, err := service.Decide(ctx, client, "key-1", body, approve)
+
+		Expect(replayed).To(BeTrue())
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeInvalidExpenseStatus))
+		Expect(appErr.StatusCode).To(Equal(expense.ErrInvalidExpenseStatus.StatusCode))
+		Expect(expenses.decisions).To(BeEmpty())
+	})
+
+	It("records a decision on the service account's own expense as forbidden", func() {
+		expenses.err = expense.ErrOwnExpenseDecision
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).To(Equal(expense.ErrOwnExpenseDecision))
+
+		_, replayed, err := service.Decide(ctx, client, "key-1", body, approve)
+
+		Expect(replayed).To(BeTrue())
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeOwnExpenseDecision))
+		Expect(appErr.Type).To(Equal(errors.ErrorTypeForbidden))
+	})
+
+	It("refuses a key reused for a different request", func() {
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = service.Decide(ctx, client, "key-1", []byte(`{"expense_id":8,"decision":"approve"}`), approve)
+		Expect(err).To(Equal(integration.ErrKeyReused))
+	})
+
+	It("refuses a key whose request is still in progress", func() {
+		hash := sha256.Sum256(body)
+		repo.requests["camunda/key-1"] = &integrationDatamodel.Request{ID: 50, Client: "camunda", IdempotencyKey: "key-1", RequestHash: hex.EncodeToString(hash[:])}
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).To(Equal(integration.ErrInProgress))
+		Expect(expenses.decisions).To(BeEmpty())
+	})
+
+	It("frees the key when the decision fails unexpectedly", func() {
+		expenses.err = stderrors.New("database down")
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).To(HaveOccurred())
+		Expect(repo.requests).To(BeEmpty())
+	})
+
+	It("requires an idempotency key", func() {
+		_, _, err := service.Decide(ctx, client, "", body, approve)
+		Expect(err).To(Equal(integration.ErrIdempotencyKey))
+	})
+
+	It("requires a code and reason to reject", func() {
+		reject := integration.DecisionDTO{ExpenseID: 7, Decision: integration.DecisionReject}
+		_, _, err := service.Decide(ctx, client, "key-1", body, reject)
+		Expect(err).To(HaveOccurred())
+
+		reject.Code = expense.RejectionDuplicate
+		reject.Reason = "Already claimed"
+		_, _, err = service.Decide(ctx, client, "key-1", body, reject)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expenses.decisions).To(Equal([]string{"reject:duplicate"}))
+		Expect(auditLog.entries[0].Action).To(Equal(audit.ActionExpenseRejectedViaIntegration))
+	})
+
+	It("refuses to act as an inactive service account", func() {
+		users.users["workflow@example.com"].IsActive = false
+		_, _, err := service.Decide(ctx, client, "key-1", body, approve)
+		Expect(err).To(Equal(errors.ErrUserInactive))
+		Expect(expenses.decisions).To(BeEmpty())
+	})
+})
@@ -0,0 +1,76 @@
+package ledger
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	ListEntries(ctx context.Context, q ListQuery) (*EntriesResponse, error)
+	Reconcile(ctx context.Context) (*ReconciliationReport, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListEntries godoc
+// @Summary      List ledger entries
+// @Description  Debit and credit legs booked for approved expenses (payable), settled payments (cash_out) and refunds, newest first. Requires admin or finance_viewer.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        expense_id  query     int     false  "Expense ID"
+// @Param        payment_id  query     int     false  "Payment ID"
+// @Param        entry_type  query     string  false  "payable, cash_out or refund"
+// @Param        account     query     string  false  "expense, expenses_payable or cash"
+// @Param        page        query     int     false  "Page, from 1"
+// @Param        per_page    query     int     false  "Entries per page, at most 100"
+// @Success      200         {object}  EntriesResponse
+// @Failure      401         {object}  transport.ErrorResponse
+// @Failure      403         {object}  transport.ErrorResponse
+// @Router       /ledger [get]
+func (h *Handler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	var q ListQuery
+	q.ParseFromRequest(r)
+
+	resp, err := h.Service.ListEntries(r.Context(), q)
+	if err != nil {
+		h.Log(r).Error("ListEntries: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list ledger entries")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Reconcile godoc
+// @Summary      Reconcile the ledger against payments
+// @Description  Compares the cash booked for each settled payment with its settled amount in the payments table. Requires admin or finance_viewer.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  ReconciliationReport
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /ledger/reconciliation [get]
+func (h *Handler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Service.Reconcile(r.Context())
+	if err != nil {
+		h.Log(r).Error("Reconcile: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to reconcile ledger")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,189 @@
+package ledger
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	ledgerDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/ledger"
+)
+
+// Accounts. An approved expense is booked as an expense owed to the
+// employee, and paying it moves the liability out of cash.
+const (
+	AccountExpense  = "expense"
+	AccountPayable  = "expenses_payable"
+	AccountCash     = "cash"
+	DirectionDebit  = "debit"
+	DirectionCredit = "credit"
+)
+
+// Entry types, one per kind of posting.
+const (
+	// TypePayable debits expense and credits expenses_payable when an
+	// expense is approved.
+	TypePayable = "payable"
+	// TypeCashOut debits expenses_payable and credits cash when a payment
+	// settles in full.
+	TypeCashOut = "cash_out"
+	// TypeRefund reverses a cash out: money returned by the payee or the
+	// gateway debits cash and credits expenses_payable.
+	TypeRefund = "refund"
+)
+
+type Entry struct {
+	ID         int64     `json:"id"`
+	Reference  string    `json:"reference"`
+	EntryType  string    `json:"entry_type"`
+	Account    string    `json:"account"`
+	Direction  string    `json:"direction"`
+	AmountIDR  int64     `json:"amount_idr"`
+	ExpenseID  int64     `json:"expense_id"`
+	PaymentID  *int64    `json:"payment_id,omitempty"`
+	ExternalID *string   `json:"external_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListQuery filters ledger entries; zero values match everything.
+type ListQuery struct {
+	ExpenseID int64
+	PaymentID int64
+	EntryType string
+	Account   string
+	Page      int
+	PerPage   int
+}
+
+func (q *ListQuery) SetDefaults() {
+	if q.PerPage <= 0 || q.PerPage > 100 {
+		q.PerPage = 50
+	}
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+}
+
+// ParseFromRequest reads the filters from the query string, ignoring values
+// that do not parse.
+func (q *ListQuery) ParseFromRequest(r *http.Request) {
+	query := r.URL.Query()
+
+	if id, err := strconv.ParseInt(query.Get("expense_id"), 10, 64); err == nil && id > 0 {
+		q.ExpenseID = id
+	}
+	if id, err := strconv.ParseInt(query.Get("payment_id"), 10, 64); err == nil && id > 0 {
+		q.PaymentID = id
+	}
+	switch t := query.Get("entry_type"); t {
+	case TypePayable, TypeCashOut, TypeRefund:
+		q.EntryType = t
+	}
+	switch a := query.Get("account"); a {
+	case AccountExpense, AccountPayable, AccountCash:
+		q.Account = a
+	}
+	if pp, err := strconv.Atoi(query.Get("per_page")); err == nil {
+		q.PerPage = pp
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil {
+		q.Page = p
+	}
+
+	q.SetDefaults()
+}
+
+type EntriesResponse struct {
+	Entries []*Entry `json:"entries"`
+	Total   int64    `json:"total"`
+	Page    int      `json:"page"`
+	PerPage int      `json:"per_page"`
+}
+
+// PaymentBalance compares what the payments table says was paid out with
+// the cash the ledger booked for the same payment.
+type PaymentBalance struct {
+	PaymentID        int64  `json:"payment_id"`
+	ExpenseID        int64  `json:"expense_id"`
+	ExternalID       string `json:"external_id"`
+	Status           string `json:"status"`
+	SettledAmountIDR int64  `json:"settled_amount_idr"`
+	LedgerCashOutIDR int64  `json:"ledger_cash_out_idr"`
+	LedgerRefundIDR  int64  `json:"ledger_refund_idr"`
+}
+
+// Difference is positive when the payments table shows more paid out than
+// the ledger booked.
+func (b *PaymentBalance) Difference() int64 {
+	return b.SettledAmountIDR - b.LedgerCashOutIDR
+}
+
+type Discrepancy struct {
+	PaymentBalance
+	DifferenceIDR int64 `json:"difference_idr"`
+}
+
+type ReconciliationReport struct {
+	// Checked counts payments that are settled or have cash postings.
+	Checked       int64          `json:"checked"`
+	Balanced      bool           `json:"balanced"`
+	Discrepancies []*Discrepancy `json:"discrepancies"`
+}
+
+// RefundDTO books money returned for a settled payment. Reference
+// identifies the refund, e.g. the gateway's refund ID, so recording it twice
+// is a no-op.
+type RefundDTO struct {
+	PaymentID  int64
+	ExpenseID  int64
+	ExternalID string
+	Reference  string
+	AmountIDR  int64
+}
+
+var ErrInvalidRefund = errors.NewValidationError("refund needs a payment, a reference and a positive amount", errors.ErrCodeInvalidAmount)
+
+func payableReference(expenseID int64) string {
+	return fmt.Sprintf("payable:expense:%d", expenseID)
+}
+
+func cashOutReference(paymentID int64) string {
+	return fmt.Sprintf("cash_out:payment:%d", paymentID)
+}
+
+func refundReference(paymentID int64, reference string) string {
+	return fmt.Sprintf("refund:payment:%d:%s", paymentID, reference)
+}
+
+// posting returns the debit and credit legs of one posting.
+func posting(reference, entryType, debit, credit string, amount, expenseID int64, paymentID *int64, externalID *string) []*ledgerDatamodel.Entry {
+	leg := func(account, direction string) *ledgerDatamodel.Entry {
+		return &ledgerDatamodel.Entry{
+			Reference:  reference,
+			EntryType:  entryType,
+			Account:    account,
+			Direction:  direction,
+			AmountIDR:  amount,
+			ExpenseID:  expenseID,
+			PaymentID:  paymentID,
+			ExternalID: externalID,
+		}
+	}
+	return []*ledgerDatamodel.Entry{leg(debit, DirectionDebit), leg(credit, DirectionCredit)}
+}
+
+func fromDataModel(e *ledgerDatamodel.Entry) *Entry {
+	return &Entry{
+		ID:         e.ID,
+		Reference:  e.Reference,
+		EntryType:  e.EntryType,
+		Account:    e.Account,
+		Direction:  e.Direction,
+		AmountIDR:  e.AmountIDR,
+		ExpenseID:  e.ExpenseID,
+		PaymentID:  e.PaymentID,
+		ExternalID: e.ExternalID,
+		CreatedAt:  e.CreatedAt,
+	}
+}
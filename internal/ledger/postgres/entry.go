@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+
+	ledgerDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/ledger"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/ledger"
+	"github.com/frahmantamala/expense-management/internal/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EntryRepository struct {
+	db *gorm.DB
+}
+
+func NewEntryRepository(db *gorm.DB) ledger.RepositoryAPI {
+	return &EntryRepository{db: db}
+}
+
+func (r *EntryRepository) Post(ctx context.Context, legs []*ledgerDatamodel.Entry) (bool, error) {
+	var posted bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(legs)
+		posted = result.RowsAffected == int64(len(legs))
+		return result.Error
+	})
+	return posted, err
+}
+
+func (r *EntryRepository) List(ctx context.Context, q ledger.ListQuery) ([]*ledgerDatamodel.Entry, int64, error) {
+	query := r.db.WithContext(ctx).Model(&ledgerDatamodel.Entry{})
+	if q.ExpenseID > 0 {
+		query = query.Where("expense_id = ?", q.ExpenseID)
+	}
+	if q.PaymentID > 0 {
+		query = query.Where("payment_id = ?", q.PaymentID)
+	}
+	if q.EntryType != "" {
+		query = query.Where("entry_type = ?", q.EntryType)
+	}
+	if q.Account != "" {
+		query = query.Where("account = ?", q.Account)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*ledgerDatamodel.Entry
+	err := query.Order("id DESC").Limit(q.PerPage).Offset((q.Page - 1) * q.PerPage).Find(&entries).Error
+	return entries, total, err
+}
+
+// PaymentBalances selects from payments so the tenant filter applies there;
+// the ledger totals are joined by payment ID, which is unique across
+// tenants.
+func (r *EntryRepository) PaymentBalances(ctx context.Context) ([]*ledger.PaymentBalance, error) {
+	var balances []*ledger.PaymentBalance
+	err := r.db.WithContext(ctx).
+		Model(&paymentDatamodel.Payment{}).
+		Select(`payments.id AS payment_id, payments.expense_id, payments.external_id, payments.status,
+			payments.settled_amount AS settled_amount_idr,
+			COALESCE(l.cash_out, 0) AS ledger_cash_out_idr,
+			COALESCE(l.refund, 0) AS ledger_refund_idr`).
+		Joins(`LEFT JOIN (
+			SELECT payment_id,
+				SUM(CASE WHEN entry_type = ? THEN amount_idr ELSE 0 END) AS cash_out,
+				SUM(CASE WHEN entry_type = ? THEN amount_idr ELSE 0 END) AS refund
+			FROM ledger_entries
+			WHERE account = ? AND payment_id IS NOT NULL
+			GROUP BY payment_id
+		) l ON l.payment_id = payments.id`, ledger.TypeCashOut, ledger.TypeRefund, ledger.AccountCash).
+		Where("payments.status = ? OR l.payment_id IS NOT NULL", payment.StatusSuccess).
+		Order("payments.id").
+		Scan(&balances).Error
+	return balances, err
+}
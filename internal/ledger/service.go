@@ -0,0 +1,148 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	ledgerDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/ledger"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the ledger of the tenant ctx is scoped to.
+type RepositoryAPI interface {
+	// Post writes the legs of one posting atomically. A posting whose
+	// reference was already written is left untouched and reported as not
+	// posted.
+	Post(ctx context.Context, legs []*ledgerDatamodel.Entry) (bool, error)
+	List(ctx context.Context, q ListQuery) ([]*ledgerDatamodel.Entry, int64, error)
+	// PaymentBalances returns every payment that is settled or has cash
+	// postings, with the cash the ledger booked for it.
+	PaymentBalances(ctx context.Context) ([]*PaymentBalance, error)
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// RecordPayable books an approved expense as owed to its submitter.
+func (s *Service) RecordPayable(ctx context.Context, expenseID, amountIDR int64) error {
+	legs := posting(payableReference(expenseID), TypePayable, AccountExpense, AccountPayable, amountIDR, expenseID, nil, nil)
+	return s.post(ctx, legs)
+}
+
+// RecordCashOut books the settlement of a payment.
+func (s *Service) RecordCashOut(ctx context.Context, paymentID, expenseID int64, externalID string, amountIDR int64) error {
+	legs := posting(cashOutReference(paymentID), TypeCashOut, AccountPayable, AccountCash, amountIDR, expenseID, &paymentID, &externalID)
+	return s.post(ctx, legs)
+}
+
+// RecordRefund books money returned for a settled payment.
+func (s *Service) RecordRefund(ctx context.Context, dto RefundDTO) error {
+	if dto.PaymentID <= 0 || dto.Reference == "" || dto.AmountIDR <= 0 {
+		return ErrInvalidRefund
+	}
+	legs := posting(refundReference(dto.PaymentID, dto.Reference), TypeRefund, AccountCash, AccountPayable, dto.AmountIDR, dto.ExpenseID, &dto.PaymentID, &dto.ExternalID)
+	return s.post(ctx, legs)
+}
+
+func (s *Service) post(ctx context.Context, legs []*ledgerDatamodel.Entry) error {
+	posted, err := s.repo.Post(ctx, legs)
+	if err != nil {
+		return fmt.Errorf("failed to post ledger entries: %w", err)
+	}
+
+	if !posted {
+		s.log(ctx).Debug("ledger posting already recorded", "reference", legs[0].Reference)
+		return nil
+	}
+	s.log(ctx).Info("ledger posting recorded",
+		"reference", legs[0].Reference,
+		"entry_type", legs[0].EntryType,
+		"expense_id", legs[0].ExpenseID,
+		"amount", legs[0].AmountIDR)
+	return nil
+}
+
+func (s *Service) ListEntries(ctx context.Context, q ListQuery) (*EntriesResponse, error) {
+	q.SetDefaults()
+
+	rows, total, err := s.repo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+
+	entries := make([]*Entry, len(rows))
+	for i, row := range rows {
+		entries[i] = fromDataModel(row)
+	}
+	return &EntriesResponse{Entries: entries, Total: total, Page: q.Page, PerPage: q.PerPage}, nil
+}
+
+// Reconcile compares the cash booked for each payment with the amount the
+// payments table records as settled. Refunds are reported alongside but do
+// not count as a discrepancy, since the payments table has no refunds.
+func (s *Service) Reconcile(ctx context.Context) (*ReconciliationReport, error) {
+	balances, err := s.repo.PaymentBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payment balances: %w", err)
+	}
+
+	report := &ReconciliationReport{Checked: int64(len(balances)), Discrepancies: []*Discrepancy{}}
+	for _, b := range balances {
+		if diff := b.Difference(); diff != 0 {
+			report.Discrepancies = append(report.Discrepancies, &Discrepancy{PaymentBalance: *b, DifferenceIDR: diff})
+		}
+	}
+	report.Balanced = len(report.Discrepancies) == 0
+
+	if !report.Balanced {
+		s.log(ctx).Warn("ledger does not reconcile with payments",
+			"checked", report.Checked,
+			"discrepancies", len(report.Discrepancies))
+	}
+	return report, nil
+}
+
+func (s *Service) HandleExpenseApproved(ctx context.Context, event events.Event) error {
+	approved, ok := event.(*events.ExpenseApprovedEvent)
+	if !ok {
+		return fmt.Errorf("expected ExpenseApprovedEvent, got %T", event)
+	}
+	return s.RecordPayable(ctx, approved.ExpenseID, approved.Amount)
+}
+
+func (s *Service) HandlePaymentCompleted(ctx context.Context, event events.Event) error {
+	completed, ok := event.(*events.PaymentCompletedEvent)
+	if !ok {
+		return fmt.Errorf("expected PaymentCompletedEvent, got %T", event)
+	}
+
+	paymentID, err := strconv.ParseInt(completed.PaymentID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid payment id %q: %w", completed.PaymentID, err)
+	}
+	return s.RecordCashOut(ctx, paymentID, completed.ExpenseID, completed.ExternalID, completed.Amount)
+}
+
+func (s *Service) RegisterEventHandlers(eventBus *events.EventBus) {
+	eventBus.Subscribe(events.EventTypeExpenseApproved, s.HandleExpenseApproved)
+	eventBus.Subscribe(events.EventTypePaymentCompleted, s.HandlePaymentCompleted)
+
+	s.logger.Info("ledger event handlers registered",
+		"handlers", []string{events.EventTypeExpenseApproved, events.EventTypePaymentCompleted})
+}
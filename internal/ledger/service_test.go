@@ -0,0 +1,136 @@
+package ledger_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	ledgerDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/ledger"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/ledger"
+)
+
+type mockRepository struct {
+	entries  []*ledgerDatamodel.Entry
+	balances []*ledger.PaymentBalance
+}
+
+func (m *mockRepository) Post(_ context.Context, legs []*ledgerDatamodel.Entry) (bool, error) {
+	for _, e := range m.entries {
+		if e.Reference == legs[0].Reference {
+			return false, nil
+		}
+	}
+	for _, leg := range legs {
+		leg.ID = int64(len(m.entries) + 1)
+		m.entries = append(m.entries, leg)
+	}
+	return true, nil
+}
+
+func (m *mockRepository) List(_ context.Context, q ledger.ListQuery) ([]*ledgerDatamodel.Entry, int64, error) {
+	var out []*ledgerDatamodel.Entry
+	for _, e := range m.entries {
+		if q.ExpenseID > 0 && e.ExpenseID != q.ExpenseID {
+			continue
+		}
+		if q.EntryType != "" && e.EntryType != q.EntryType {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, int64(len(out)), nil
+}
+
+func (m *mockRepository) PaymentBalances(_ context.Context) ([]*ledger.PaymentBalance, error) {
+	return m.balances, nil
+}
+
+var _ = Describe("Ledger service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *ledger.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{}
+		service = ledger.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	sum := func(account string) int64 {
+		var balance int64
+		for _, e := range repo.entries {
+			if e.Account != account {
+				continue
+			}
+			if e.Direction == ledger.DirectionDebit {
+				balance += e.AmountIDR
+			} else {
+				balance -= e.AmountIDR
+			}
+		}
+		return balance
+	}
+
+	It("books an approval and its payment as balanced postings", func() {
+		Expect(service.HandleExpenseApproved(ctx, events.NewExpenseApprovedEvent(7, 150000, 3, "IDR"))).To(Succeed())
+		Expect(service.HandlePaymentCompleted(ctx, events.NewPaymentCompletedEvent("21", 7, "exp-7", 150000, "success", "gw-1"))).To(Succeed())
+
+		Expect(repo.entries).To(HaveLen(4))
+		Expect(sum(ledger.AccountExpense)).To(Equal(int64(150000)))
+		Expect(sum(ledger.AccountPayable)).To(BeZero())
+		Expect(sum(ledger.AccountCash)).To(Equal(int64(-150000)))
+
+		cashOut, err := service.ListEntries(ctx, ledger.ListQuery{EntryType: ledger.TypeCashOut})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cashOut.Entries).To(HaveLen(2))
+		Expect(*cashOut.Entries[0].PaymentID).To(Equal(int64(21)))
+		Expect(*cashOut.Entries[0].ExternalID).To(Equal("exp-7"))
+		Expect(cashOut.PerPage).To(Equal(50))
+	})
+
+	It("ignores a redelivered event", func() {
+		event := events.NewPaymentCompletedEvent("21", 7, "exp-7", 150000, "success", "gw-1")
+		Expect(service.HandlePaymentCompleted(ctx, event)).To(Succeed())
+		Expect(service.HandlePaymentCompleted(ctx, event)).To(Succeed())
+
+		Expect(repo.entries).To(HaveLen(2))
+	})
+
+	It("reverses cash out for a refund", func() {
+		Expect(service.RecordCashOut(ctx, 21, 7, "exp-7", 150000)).To(Succeed())
+		Expect(service.RecordRefund(ctx, ledger.RefundDTO{PaymentID: 21, ExpenseID: 7, ExternalID: "exp-7", Reference: "rf-1", AmountIDR: 50000})).To(Succeed())
+
+		Expect(sum(ledger.AccountCash)).To(Equal(int64(-100000)))
+		Expect(sum(ledger.AccountPayable)).To(Equal(int64(100000)))
+	})
+
+	It("rejects a refund without a reference", func() {
+		err := service.RecordRefund(ctx, ledger.RefundDTO{PaymentID: 21, AmountIDR: 50000})
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeInvalidAmount))
+		Expect(repo.entries).To(BeEmpty())
+	})
+
+	It("reports payments whose settled amount differs from the cash booked", func() {
+		repo.balances = []*ledger.PaymentBalance{
+			{PaymentID: 1, SettledAmountIDR: 100000, LedgerCashOutIDR: 100000, LedgerRefundIDR: 20000},
+			{PaymentID: 2, SettledAmountIDR: 80000},
+		}
+
+		report, err := service.Reconcile(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Checked).To(Equal(int64(2)))
+		Expect(report.Balanced).To(BeFalse())
+		Expect(report.Discrepancies).To(HaveLen(1))
+		Expect(report.Discrepancies[0].PaymentID).To(Equal(int64(2)))
+		Expect(report.Discrepancies[0].DifferenceIDR).To(Equal(int64(80000)))
+	})
+})
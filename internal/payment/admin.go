@@ -0,0 +1,242 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	apperrors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// StuckJobAfter is how long a job may sit queued or processing before it is
+// taken to be lost, as when the process holding it in memory restarted.
+const StuckJobAfter = 15 * time.Minute
+
+// PaymentRequeuer sends a payment to the gateway again; PaymentService
+// satisfies it.
+type PaymentRequeuer interface {
+	RetryPayment(req *PaymentRequest) (*PaymentResponse, error)
+}
+
+type AdminServiceAPI interface {
+	Requeue(ctx context.Context, paymentID, actorID int64, reason string) (*JobActionResult, error)
+	Cancel(ctx context.Context, paymentID, actorID int64, reason string) (*JobActionResult, error)
+}
+
+// JobActionResult is where a payment and its job stand after an operator
+// requeued or cancelled it.
+type JobActionResult struct {
+	PaymentID     int64           `json:"payment_id"`
+	ExternalID    string          `json:"external_id"`
+	PaymentStatus string          `json:"payment_status"`
+	Job           *PaymentJobView `json:"job,omitempty"`
+}
+
+// AdminService lets operators intervene on payments whose jobs are stuck.
+// Only pending payments are touched: anything further along has already
+// reached the gateway, and failed payments are retried by their owners.
+type AdminService struct {
+	payments      RepositoryAPI
+	jobs          JobRepositoryAPI
+	requeuer      PaymentRequeuer
+	eventBus      *events.EventBus
+	auditRecorder AuditRecorder
+	now           func() time.Time
+	logger        *slog.Logger
+}
+
+// NewAdminService takes a nil auditRecorder when interventions are only
+// logged.
+func NewAdminService(payments RepositoryAPI, jobs JobRepositoryAPI, requeuer PaymentRequeuer, eventBus *events.EventBus, auditRecorder AuditRecorder, logger *slog.Logger) *AdminService {
+	return &AdminService{
+		payments:      payments,
+		jobs:          jobs,
+		requeuer:      requeuer,
+		eventBus:      eventBus,
+		auditRecorder: auditRecorder,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+func (s *AdminService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Requeue sends a pending payment whose job failed, was dead-lettered or is
+// stuck back through the gateway queue. A dead-lettered job may have crashed
+// after the gateway took its payment, so check the gateway before requeueing
+// one.
+func (s *AdminService) Requeue(ctx context.Context, paymentID, actorID int64, reason string) (*JobActionResult, error) {
+	p, job, err := s.load(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusPending {
+		return nil, jobConflict("payment is %s; only pending payments can be requeued", p.Status)
+	}
+	if job != nil && !isStuck(job, s.now()) {
+		return nil, jobConflict("payment job is %s and not stuck", job.Status)
+	}
+
+	if _, err := s.requeuer.RetryPayment(&PaymentRequest{ExternalID: p.ExternalID, Amount: p.Amount()}); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("payment requeued by operator", "payment_id", p.ID, "external_id", p.ExternalID, "actor_id", actorID)
+	s.record(ctx, audit.ActionPaymentRequeued, p, job, actorID, reason)
+	return s.result(p.ID)
+}
+
+// Cancel stops a pending payment that has not reached the gateway: its job
+// is marked cancelled so no worker runs it, and the payment fails as if the
+// gateway had refused it, so the expense owner can retry.
+func (s *AdminService) Cancel(ctx context.Context, paymentID, actorID int64, reason string) (*JobActionResult, error) {
+	p, job, err := s.load(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusPending {
+		return nil, jobConflict("payment is %s; only pending payments can be cancelled", p.Status)
+	}
+
+	now := s.now()
+	failureReason := "cancelled by operator: " + reason
+	if job != nil {
+		if !isCancellable(job, now) {
+			return nil, jobConflict("payment job is %s and can no longer be cancelled", job.Status)
+		}
+		cancelled, err := s.jobs.MarkCancelled(p.ExternalID, job.Status, failureReason, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel payment job: %w", err)
+		}
+		if !cancelled {
+			return nil, jobConflict("payment job changed while it was being cancelled; try again")
+		}
+	}
+
+	err = s.payments.UpdateStatus(p.ID, StatusFailed, nil, nil, &failureReason)
+	if errors.Is(err, ErrInvalidPaymentStatus) {
+		// A callback moved the payment on after it was read; the job is
+		// cancelled but has nothing left to do.
+		s.log(ctx).Warn("payment moved on while being cancelled", "payment_id", p.ID, "external_id", p.ExternalID)
+		return nil, jobConflict("payment changed while it was being cancelled")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail cancelled payment: %w", err)
+	}
+
+	event := events.NewPaymentFailedEvent(
+		strconv.FormatInt(p.ID, 10),
+		p.ExpenseID,
+		p.ExternalID,
+		p.AmountIDR,
+		failureReason,
+		p.RetryCount,
+	)
+	s.eventBus.Publish(context.WithoutCancel(ctx), event)
+
+	s.log(ctx).Info("payment cancelled by operator", "payment_id", p.ID, "external_id", p.ExternalID, "actor_id", actorID)
+	s.record(ctx, audit.ActionPaymentCancelled, p, job, actorID, reason)
+	return s.result(p.ID)
+}
+
+func (s *AdminService) load(paymentID int64) (*paymentDatamodel.Payment, *paymentDatamodel.PaymentJob, error) {
+	p, err := s.payments.GetByID(paymentID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		return nil, nil, apperrors.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load payment: %w", err)
+	}
+
+	// A payment without a job row was never queued, or lost its job before
+	// one was recorded; either way nothing is holding it.
+	job, err := s.jobs.GetByExternalID(p.ExternalID)
+	if errors.Is(err, ErrPaymentJobNotFound) {
+		return p, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load payment job: %w", err)
+	}
+	return p, job, nil
+}
+
+func (s *AdminService) result(paymentID int64) (*JobActionResult, error) {
+	p, job, err := s.load(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &JobActionResult{
+		PaymentID:     p.ID,
+		ExternalID:    p.ExternalID,
+		PaymentStatus: p.Status,
+	}
+	if job != nil {
+		result.Job = ToJobView(job)
+	}
+	return result, nil
+}
+
+func (s *AdminService) record(ctx context.Context, action string, p *paymentDatamodel.Payment, job *paymentDatamodel.PaymentJob, actorID int64, reason string) {
+	if s.auditRecorder == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{
+		"external_id": p.ExternalID,
+		"expense_id":  p.ExpenseID,
+		"reason":      reason,
+	}
+	if job != nil {
+		metadata["job_status"] = job.Status
+		metadata["job_attempts"] = job.Attempts
+	}
+
+	entry := &audit.Entry{
+		Action:       action,
+		ResourceType: audit.ResourcePayment,
+		ResourceID:   strconv.FormatInt(p.ID, 10),
+		ActorID:      &actorID,
+		Metadata:     metadata,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.auditRecorder.Record(entry); err != nil {
+		s.log(ctx).Error("failed to record payment intervention audit entry", "error", err, "action", action, "payment_id", p.ID)
+	}
+}
+
+// isStuck reports whether job will not finish on its own: it failed, was
+// dead-lettered, or has sat queued or processing for StuckJobAfter.
+func isStuck(job *paymentDatamodel.PaymentJob, now time.Time) bool {
+	switch job.Status {
+	case JobStatusFailed, JobStatusDeadLettered:
+		return true
+	case JobStatusQueued, JobStatusProcessing:
+		return now.Sub(job.UpdatedAt) >= StuckJobAfter
+	}
+	return false
+}
+
+// isCancellable reports whether job has not yet handed its payment to the
+// gateway. A queued or spilled job is skipped by workers once cancelled; a
+// processing one may only be cancelled once it is stuck.
+func isCancellable(job *paymentDatamodel.PaymentJob, now time.Time) bool {
+	switch job.Status {
+	case JobStatusQueued, JobStatusSpilled:
+		return true
+	}
+	return isStuck(job, now)
+}
+
+func jobConflict(format string, args ...interface{}) error {
+	return apperrors.NewConflictError(fmt.Sprintf(format, args...), apperrors.ErrCodePaymentJobConflict)
+}
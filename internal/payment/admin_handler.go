@@ -0,0 +1,187 @@
+package payment
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type AdminHandler struct {
+	*transport.BaseHandler
+	Service      AdminServiceAPI
+	GatewayCalls GatewayCallServiceAPI
+	// Webhooks is nil when callbacks are applied inline rather than stored
+	// in the inbox.
+	Webhooks WebhookReplayerAPI
+}
+
+func NewAdminHandler(service AdminServiceAPI, gatewayCalls GatewayCallServiceAPI, webhooks WebhookReplayerAPI, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		BaseHandler:  transport.NewBaseHandler(logger),
+		Service:      service,
+		GatewayCalls: gatewayCalls,
+		Webhooks:     webhooks,
+	}
+}
+
+// RequeuePayment godoc
+// @Summary      Requeue stuck payment
+// @Description  Sends a pending payment whose job failed, was dead-lettered or has been queued or processing for 15 minutes back through the gateway queue. Admin only.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                      true  "Payment ID"
+// @Param        body  body      PaymentJobActionRequest  true  "Why the payment is requeued"
+// @Success      200   {object}  JobActionResult
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Failure      409   {object}  transport.AppErrorResponse
+// @Failure      429   {object}  transport.AppErrorResponse
+// @Router       /admin/payments/{id}/requeue [post]
+func (h *AdminHandler) RequeuePayment(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, "RequeuePayment", h.Service.Requeue)
+}
+
+// CancelPayment godoc
+// @Summary      Cancel stuck payment
+// @Description  Cancels a pending payment that has not reached the gateway and fails it so its owner can retry. Admin only.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                      true  "Payment ID"
+// @Param        body  body      PaymentJobActionRequest  true  "Why the payment is cancelled"
+// @Success      200   {object}  JobActionResult
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Failure      409   {object}  transport.AppErrorResponse
+// @Router       /admin/payments/{id}/cancel [post]
+func (h *AdminHandler) CancelPayment(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, "CancelPayment", h.Service.Cancel)
+}
+
+// ListGatewayCalls godoc
+// @Summary      List gateway calls of payment
+// @Description  Returns the requests sent to the payment gateway about a payment and the gateway's answers, newest first and at most 200, with credentials and account numbers redacted. For disputing a charge with the provider. Admin only.
+// @Tags         payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Payment ID"
+// @Success      200  {array}   GatewayCallView
+// @Failure      400  {object}  transport.AppErrorResponse
+// @Failure      403  {object}  transport.AppErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/payments/{id}/gateway-calls [get]
+func (h *AdminHandler) ListGatewayCalls(w http.ResponseWriter, r *http.Request) {
+	user, ok := errors.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.Log(r).Error("ListGatewayCalls: user not found in context")
+		h.HandleError(w, r, errors.NewUnauthorizedError("authentication required", errors.ErrCodeInvalidToken))
+		return
+	}
+
+	paymentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.HandleError(w, r, errors.NewValidationError("invalid payment ID", errors.ErrCodeValidationFailed))
+		return
+	}
+
+	calls, err := h.GatewayCalls.List(r.Context(), user.TenantID, paymentID)
+	if err != nil {
+		h.Log(r).Warn("ListGatewayCalls: failed", "error", err, "payment_id", paymentID, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, calls)
+}
+
+// ReplayWebhook godoc
+// @Summary      Replay stored payment webhook
+// @Description  Applies a gateway callback from the webhook inbox to its payment again, as when it was handled wrongly before a fix. Safe to repeat. Refused while the callback is still waiting to be processed, or when a newer callback for the same payment has arrived. Only available with the webhook inbox enabled. Admin only.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                   true  "Webhook inbox ID"
+// @Param        body  body      WebhookReplayRequest  true  "Why the webhook is replayed"
+// @Success      200   {object}  WebhookReplayResult
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Failure      409   {object}  transport.AppErrorResponse
+// @Router       /admin/webhooks/{id}/replay [post]
+func (h *AdminHandler) ReplayWebhook(w http.ResponseWriter, r *http.Request) {
+	user, ok := errors.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.Log(r).Error("ReplayWebhook: user not found in context")
+		h.HandleError(w, r, errors.NewUnauthorizedError("authentication required", errors.ErrCodeInvalidToken))
+		return
+	}
+
+	inboxID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.HandleError(w, r, errors.NewValidationError("invalid webhook ID", errors.ErrCodeValidationFailed))
+		return
+	}
+
+	var req WebhookReplayRequest
+	if !h.DecodeJSON(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	result, err := h.Webhooks.Replay(r.Context(), user.TenantID, inboxID, user.ID, req.Reason)
+	if err != nil {
+		h.Log(r).Warn("ReplayWebhook: failed", "error", err, "inbox_id", inboxID, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
+
+func (h *AdminHandler) act(w http.ResponseWriter, r *http.Request, name string, action func(ctx context.Context, paymentID, actorID int64, reason string) (*JobActionResult, error)) {
+	user, ok := errors.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.Log(r).Error(name + ": user not found in context")
+		h.HandleError(w, r, errors.NewUnauthorizedError("authentication required", errors.ErrCodeInvalidToken))
+		return
+	}
+
+	paymentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.HandleError(w, r, errors.NewValidationError("invalid payment ID", errors.ErrCodeValidationFailed))
+		return
+	}
+
+	var req PaymentJobActionRequest
+	if !h.DecodeJSON(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	result, err := action(r.Context(), paymentID, user.ID, req.Reason)
+	if err != nil {
+		h.Log(r).Warn(name+": failed", "error", err, "payment_id", paymentID, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
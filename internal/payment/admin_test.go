@@ -0,0 +1,304 @@
+package payment_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+)
+
+type fakeJobRepository struct {
+	jobs map[string]*payment.PaymentJob
+}
+
+func (f *fakeJobRepository) MarkQueued(externalID string, queuedAt time.Time) error {
+	f.jobs[externalID] = &payment.PaymentJob{ExternalID: externalID, Status: paymentpkg.JobStatusQueued, QueuedAt: queuedAt, UpdatedAt: queuedAt}
+	return nil
+}
+
+func (f *fakeJobRepository) MarkProcessing(string, int, time.Time) error        { return nil }
+func (f *fakeJobRepository) MarkCompleted(string, time.Time) error              { return nil }
+func (f *fakeJobRepository) MarkFailed(string, string, time.Time) error         { return nil }
+func (f *fakeJobRepository) MarkDeadLettered(string, string, time.Time) error   { return nil }
+func (f *fakeJobRepository) MarkSpilled(string, int64, string, time.Time) error { return nil }
+func (f *fakeJobRepository) MarkRouted(string, string, time.Time) error         { return nil }
+
+func (f *fakeJobRepository) MarkCancelled(externalID, from, reason string, cancelledAt time.Time) (bool, error) {
+	job, ok := f.jobs[externalID]
+	if !ok || job.Status != from {
+		return false, nil
+	}
+	job.Status = paymentpkg.JobStatusCancelled
+	job.LastError = &reason
+	job.UpdatedAt = cancelledAt
+	return true, nil
+}
+
+func (f *fakeJobRepository) ClaimSpilled(int, time.Time) ([]*payment.PaymentJob, error) {
+	return nil, nil
+}
+
+func (f *fakeJobRepository) GetByExternalID(externalID string) (*payment.PaymentJob, error) {
+	job, ok := f.jobs[externalID]
+	if !ok {
+		return nil, paymentpkg.ErrPaymentJobNotFound
+	}
+	stored := *job
+	return &stored, nil
+}
+
+// queueingRequeuer stands in for PaymentService: it queues the job again as
+// the gateway client would.
+type queueingRequeuer struct {
+	jobs     *fakeJobRepository
+	requests []*paymentpkg.PaymentRequest
+}
+
+func (q *queueingRequeuer) RetryPayment(req *paymentpkg.PaymentRequest) (*paymentpkg.PaymentResponse, error) {
+	q.requests = append(q.requests, req)
+	return &paymentpkg.PaymentResponse{}, q.jobs.MarkQueued(req.ExternalID, time.Now())
+}
+
+// fakeGatewayCallRepository keeps calls in the order they were saved.
+type fakeGatewayCallRepository struct {
+	calls []*payment.GatewayCall
+}
+
+func (f *fakeGatewayCallRepository) Save(call *payment.GatewayCall) error {
+	call.ID = int64(len(f.calls) + 1)
+	f.calls = append(f.calls, call)
+	return nil
+}
+
+func (f *fakeGatewayCallRepository) ListByPayment(paymentID int64, externalID string, limit int) ([]*payment.GatewayCall, error) {
+	var found []*payment.GatewayCall
+	for i := len(f.calls) - 1; i >= 0 && len(found) < limit; i-- {
+		if c := f.calls[i]; (c.PaymentID != nil && *c.PaymentID == paymentID) || c.ExternalID == externalID {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeGatewayCallRepository) PurgeBefore(before time.Time) (int64, error) {
+	kept := f.calls[:0]
+	for _, c := range f.calls {
+		if !c.CreatedAt.Before(before) {
+			kept = append(kept, c)
+		}
+	}
+	purged := int64(len(f.calls) - len(kept))
+	f.calls = kept
+	return purged, nil
+}
+
+var _ = ginkgo.Describe("AdminHandler", func() {
+	var (
+		router    *chi.Mux
+		payments  *mockPaymentRepository
+		jobs      *fakeJobRepository
+		requeuer  *queueingRequeuer
+		auditLog  *mockAuditRecorder
+		calls     *fakeGatewayCallRepository
+		published []events.Event
+		mu        sync.Mutex
+		stored    *payment.Payment
+	)
+
+	ginkgo.BeforeEach(func() {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		payments = newMockPaymentRepository()
+		stored = paymentpkg.NewPayment(42, "exp-42-150000", 150000)
+		gomega.Expect(payments.Create(stored)).To(gomega.Succeed())
+		jobs = &fakeJobRepository{jobs: map[string]*payment.PaymentJob{}}
+		requeuer = &queueingRequeuer{jobs: jobs}
+		auditLog = &mockAuditRecorder{}
+
+		eventBus := events.NewEventBus(logger)
+		published = nil
+		eventBus.Subscribe(events.EventTypePaymentFailed, func(ctx context.Context, event events.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			published = append(published, event)
+			return nil
+		})
+
+		calls = &fakeGatewayCallRepository{}
+		gatewayCalls := paymentpkg.NewGatewayCallService(payments, calls, 24*time.Hour, logger)
+		handler := paymentpkg.NewAdminHandler(paymentpkg.NewAdminService(payments, jobs, requeuer, eventBus, auditLog, logger), gatewayCalls, nil, logger)
+		router = chi.NewRouter()
+		router.Post("/admin/payments/{id}/requeue", handler.RequeuePayment)
+		router.Post("/admin/payments/{id}/cancel", handler.CancelPayment)
+		router.Get("/admin/payments/{id}/gateway-calls", handler.ListGatewayCalls)
+	})
+
+	send := func(target, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, createRequestWithUser("POST", target, []byte(body), createTestUser(9, []string{"admin"})))
+		var resp map[string]interface{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &resp)
+		return recorder, resp
+	}
+
+	withJob := func(status string, updatedAt time.Time) {
+		jobs.jobs[stored.ExternalID] = &payment.PaymentJob{ExternalID: stored.ExternalID, Status: status, Attempts: 2, UpdatedAt: updatedAt}
+	}
+
+	publishedEvents := func() []events.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]events.Event(nil), published...)
+	}
+
+	ginkgo.Describe("requeue", func() {
+		ginkgo.It("requeues a dead-lettered job and audits who did it and why", func() {
+			withJob(paymentpkg.JobStatusDeadLettered, time.Now())
+
+			recorder, resp := send("/admin/payments/1/requeue", `{"reason": "gateway confirmed it never received the payment"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(resp["payment_status"]).To(gomega.Equal(paymentpkg.StatusPending))
+			gomega.Expect(resp["job"]).To(gomega.HaveKeyWithValue("status", paymentpkg.JobStatusQueued))
+
+			gomega.Expect(requeuer.requests).To(gomega.HaveLen(1))
+			gomega.Expect(requeuer.requests[0].ExternalID).To(gomega.Equal("exp-42-150000"))
+			gomega.Expect(requeuer.requests[0].Amount.Amount).To(gomega.Equal(int64(150000)))
+
+			gomega.Expect(auditLog.entries).To(gomega.HaveLen(1))
+			entry := auditLog.entries[0]
+			gomega.Expect(entry.Action).To(gomega.Equal(audit.ActionPaymentRequeued))
+			gomega.Expect(entry.ResourceID).To(gomega.Equal("1"))
+			gomega.Expect(*entry.ActorID).To(gomega.Equal(int64(9)))
+			gomega.Expect(entry.Metadata).To(gomega.HaveKeyWithValue("job_status", paymentpkg.JobStatusDeadLettered))
+			gomega.Expect(entry.Metadata).To(gomega.HaveKeyWithValue("reason", "gateway confirmed it never received the payment"))
+		})
+
+		ginkgo.It("requeues a job left processing past the stuck threshold", func() {
+			withJob(paymentpkg.JobStatusProcessing, time.Now().Add(-paymentpkg.StuckJobAfter-time.Minute))
+
+			recorder, _ := send("/admin/payments/1/requeue", `{"reason": "worker lost in a restart"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(requeuer.requests).To(gomega.HaveLen(1))
+		})
+
+		ginkgo.It("refuses a job that is still making progress", func() {
+			withJob(paymentpkg.JobStatusProcessing, time.Now())
+
+			recorder, resp := send("/admin/payments/1/requeue", `{"reason": "impatient"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusConflict))
+			gomega.Expect(resp["error"]).To(gomega.HaveKeyWithValue("code", "PAYMENT_JOB_CONFLICT"))
+			gomega.Expect(requeuer.requests).To(gomega.BeEmpty())
+			gomega.Expect(auditLog.entries).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("refuses a payment that is no longer pending", func() {
+			withJob(paymentpkg.JobStatusFailed, time.Now())
+			stored.Status = paymentpkg.StatusSuccess
+
+			recorder, _ := send("/admin/payments/1/requeue", `{"reason": "retry"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusConflict))
+			gomega.Expect(requeuer.requests).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Describe("cancel", func() {
+		ginkgo.It("cancels a queued job, fails the payment and tells the expense", func() {
+			withJob(paymentpkg.JobStatusSpilled, time.Now())
+
+			recorder, resp := send("/admin/payments/1/cancel", `{"reason": "duplicate of expense 41"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(resp["payment_status"]).To(gomega.Equal(paymentpkg.StatusFailed))
+			gomega.Expect(resp["job"]).To(gomega.HaveKeyWithValue("status", paymentpkg.JobStatusCancelled))
+			gomega.Expect(*stored.FailureReason).To(gomega.Equal("cancelled by operator: duplicate of expense 41"))
+
+			gomega.Eventually(publishedEvents).Should(gomega.HaveLen(1))
+			failed, ok := publishedEvents()[0].(*events.PaymentFailedEvent)
+			gomega.Expect(ok).To(gomega.BeTrue())
+			gomega.Expect(failed.ExpenseID).To(gomega.Equal(int64(42)))
+
+			gomega.Expect(auditLog.entries).To(gomega.HaveLen(1))
+			gomega.Expect(auditLog.entries[0].Action).To(gomega.Equal(audit.ActionPaymentCancelled))
+			gomega.Expect(auditLog.entries[0].Metadata).To(gomega.HaveKeyWithValue("job_status", paymentpkg.JobStatusSpilled))
+		})
+
+		ginkgo.It("refuses a job already handed to the gateway", func() {
+			withJob(paymentpkg.JobStatusCompleted, time.Now().Add(-time.Hour))
+
+			recorder, _ := send("/admin/payments/1/cancel", `{"reason": "taking too long"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusConflict))
+			gomega.Expect(stored.Status).To(gomega.Equal(paymentpkg.StatusPending))
+			gomega.Consistently(publishedEvents, 100*time.Millisecond).Should(gomega.BeEmpty())
+		})
+
+		ginkgo.It("requires a reason", func() {
+			withJob(paymentpkg.JobStatusQueued, time.Now())
+
+			recorder, _ := send("/admin/payments/1/cancel", `{}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusBadRequest))
+			gomega.Expect(jobs.jobs[stored.ExternalID].Status).To(gomega.Equal(paymentpkg.JobStatusQueued))
+		})
+
+		ginkgo.It("answers 404 for an unknown payment", func() {
+			recorder, _ := send("/admin/payments/99/cancel", `{"reason": "typo"}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusNotFound))
+		})
+	})
+
+	ginkgo.Describe("gateway calls", func() {
+		list := func(target string, user *internal.User) (*httptest.ResponseRecorder, []map[string]interface{}) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, createRequestWithUser("GET", target, nil, user))
+			var resp []map[string]interface{}
+			_ = json.Unmarshal(recorder.Body.Bytes(), &resp)
+			return recorder, resp
+		}
+
+		ginkgo.BeforeEach(func() {
+			status := http.StatusOK
+			gomega.Expect(calls.Save(&payment.GatewayCall{ExternalID: stored.ExternalID, Method: "POST", URL: "https://gateway.example.com/payments", StatusCode: &status, LatencyMS: 120, CreatedAt: time.Now().Add(-time.Hour)})).To(gomega.Succeed())
+			gomega.Expect(calls.Save(&payment.GatewayCall{ExternalID: "exp-7-1000", Method: "POST", URL: "https://gateway.example.com/payments", CreatedAt: time.Now()})).To(gomega.Succeed())
+			gomega.Expect(calls.Save(&payment.GatewayCall{PaymentID: &stored.ID, ExternalID: stored.ExternalID, Method: "GET", URL: "https://gateway.example.com/payments?external_id=exp-42-150000", CreatedAt: time.Now()})).To(gomega.Succeed())
+		})
+
+		ginkgo.It("lists the payment's calls newest first", func() {
+			recorder, resp := list("/admin/payments/1/gateway-calls", createTestUser(9, []string{"admin"}))
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(resp).To(gomega.HaveLen(2))
+			gomega.Expect(resp[0]).To(gomega.HaveKeyWithValue("method", "GET"))
+			gomega.Expect(resp[1]).To(gomega.HaveKeyWithValue("method", "POST"))
+			gomega.Expect(resp[1]).To(gomega.HaveKeyWithValue("status_code", float64(http.StatusOK)))
+			gomega.Expect(resp[1]).To(gomega.HaveKeyWithValue("latency_ms", float64(120)))
+		})
+
+		ginkgo.It("answers 404 for a payment of another tenant", func() {
+			user := createTestUser(9, []string{"admin"})
+			user.TenantID = stored.TenantID + 1
+
+			recorder, _ := list("/admin/payments/1/gateway-calls", user)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusNotFound))
+		})
+
+		ginkgo.It("purges calls older than the retention", func() {
+			service := paymentpkg.NewGatewayCallService(payments, calls, 30*time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			purged, err := service.Purge(context.Background(), time.Now())
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(purged).To(gomega.Equal(int64(1)))
+			gomega.Expect(calls.calls).To(gomega.HaveLen(2))
+		})
+	})
+})
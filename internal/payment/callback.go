@@ -0,0 +1,287 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+// CallbackProcessor applies gateway callbacks to payments, either inline from
+// the webhook or from the callback inbox.
+type CallbackProcessor struct {
+	paymentService ServiceAPI
+	eventBus       *events.EventBus
+	auditRecorder  AuditRecorder
+	logger         *slog.Logger
+}
+
+func NewCallbackProcessor(paymentService ServiceAPI, eventBus *events.EventBus, auditRecorder AuditRecorder, logger *slog.Logger) *CallbackProcessor {
+	return &CallbackProcessor{
+		paymentService: paymentService,
+		eventBus:       eventBus,
+		auditRecorder:  auditRecorder,
+		logger:         logger,
+	}
+}
+
+func (c *CallbackProcessor) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, c.logger)
+}
+
+// Process applies a gateway callback to its payment. It returns
+// ErrCallbackMismatch, after alerting and auditing, when the callback does not
+// match the stored payment.
+func (c *CallbackProcessor) Process(ctx context.Context, req *PaymentCallbackRequest) error {
+	payment, err := c.paymentService.GetPaymentByExternalID(req.ExternalID)
+	if err != nil {
+		return fmt.Errorf("payment not found for external_id %s: %w", req.ExternalID, err)
+	}
+
+	c.log(ctx).Info("processing payment callback for payment record",
+		"payment_id", payment.ID,
+		"expense_id", payment.ExpenseID,
+		"external_id", req.ExternalID,
+		"current_status", payment.Status,
+		"new_status", req.Status)
+
+	internalStatus := MapExternalStatus(req.Status)
+
+	// A success callback for the outstanding balance of a payment that is
+	// already partly settled is its final installment.
+	finalInstallment := internalStatus == StatusSuccess && payment.SettledAmount > 0 &&
+		req.Amount == payment.AmountIDR-payment.SettledAmount
+	if internalStatus == StatusPartiallySettled || finalInstallment {
+		return c.processInstallment(ctx, payment, req)
+	}
+
+	if err := ValidateCallback(payment, req.ExternalID, req.Amount); err != nil {
+		c.handleCallbackMismatch(ctx, payment, req, err)
+		return err
+	}
+
+	if !CanTransition(payment.Status, internalStatus) {
+		c.handleOutOfOrderCallback(ctx, payment, req, internalStatus)
+		return nil
+	}
+
+	callbackJSON := callbackData(req)
+
+	var failureReason *string
+	if req.FailureReason != "" {
+		failureReason = &req.FailureReason
+	}
+
+	err = c.paymentService.UpdatePaymentStatus(payment.ID, internalStatus, nil, callbackJSON, failureReason)
+	if errors.Is(err, ErrInvalidPaymentStatus) {
+		// Another callback moved the payment on since it was read.
+		c.handleOutOfOrderCallback(ctx, payment, req, internalStatus)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	if internalStatus == StatusSuccess {
+		event := events.NewPaymentCompletedEvent(
+			fmt.Sprintf("%d", payment.ID),
+			payment.ExpenseID,
+			req.ExternalID,
+			req.Amount,
+			internalStatus,
+			req.GatewayPaymentID,
+		)
+		c.eventBus.Publish(context.WithoutCancel(ctx), event)
+		c.log(ctx).Info("published payment completed event", "event_id", event.EventID())
+	} else if internalStatus == StatusFailed {
+		retryAt, err := c.paymentService.RecordFailure(payment, req.FailureClass)
+		if err != nil {
+			c.log(ctx).Error("failed to record payment failure class", "error", err, "payment_id", payment.ID)
+		}
+		if retryAt != nil {
+			// The expense waits for the retry; it only fails when retries
+			// run out.
+			return nil
+		}
+
+		event := events.NewPaymentFailedEvent(
+			fmt.Sprintf("%d", payment.ID),
+			payment.ExpenseID,
+			req.ExternalID,
+			req.Amount,
+			req.FailureReason,
+			payment.RetryCount,
+		)
+		c.eventBus.Publish(context.WithoutCancel(ctx), event)
+		c.log(ctx).Info("published payment failed event", "event_id", event.EventID())
+	}
+
+	c.log(ctx).Info("payment status updated successfully",
+		"payment_id", payment.ID,
+		"external_id", req.ExternalID,
+		"old_status", payment.Status,
+		"new_status", internalStatus)
+
+	return nil
+}
+
+// processInstallment records one transfer of a payment that settles in parts.
+// The expense is only completed once the whole amount has arrived.
+func (c *CallbackProcessor) processInstallment(ctx context.Context, payment *paymentDatamodel.Payment, req *PaymentCallbackRequest) error {
+	if err := ValidateInstallment(payment, req.ExternalID, req.Amount); err != nil {
+		c.handleCallbackMismatch(ctx, payment, req, err)
+		return err
+	}
+	if !CanTransition(payment.Status, StatusPartiallySettled) {
+		c.handleOutOfOrderCallback(ctx, payment, req, StatusPartiallySettled)
+		return nil
+	}
+
+	installment := &paymentDatamodel.Installment{
+		GatewayPaymentID: req.GatewayPaymentID,
+		AmountIDR:        req.Amount,
+		SettledAt:        time.Now().UTC(),
+	}
+	updated, recorded, err := c.paymentService.RecordInstallment(payment.ID, installment, callbackData(req))
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		c.log(ctx).Info("duplicate installment callback ignored",
+			"payment_id", payment.ID,
+			"gateway_payment_id", req.GatewayPaymentID)
+		return nil
+	}
+
+	c.log(ctx).Info("payment installment recorded",
+		"payment_id", payment.ID,
+		"external_id", req.ExternalID,
+		"amount", req.Amount,
+		"settled_amount", updated.SettledAmount,
+		"total_amount", updated.AmountIDR)
+
+	if !IsFullySettled(updated) {
+		return nil
+	}
+
+	event := events.NewPaymentCompletedEvent(
+		fmt.Sprintf("%d", payment.ID),
+		payment.ExpenseID,
+		req.ExternalID,
+		updated.SettledAmount,
+		StatusSuccess,
+		req.GatewayPaymentID,
+	)
+	c.eventBus.Publish(context.WithoutCancel(ctx), event)
+	c.log(ctx).Info("published payment completed event", "event_id", event.EventID())
+	return nil
+}
+
+func callbackData(req *PaymentCallbackRequest) json.RawMessage {
+	data := map[string]interface{}{
+		"gateway_payment_id": req.GatewayPaymentID,
+		"gateway_status":     req.Status,
+		"amount":             req.Amount,
+		"callback_time":      time.Now().UTC(),
+	}
+
+	if req.FailureReason != "" {
+		data["failure_reason"] = req.FailureReason
+	}
+
+	raw, _ := json.Marshal(data)
+	return raw
+}
+
+// handleCallbackMismatch leaves the payment untouched, raises an alert and
+// records an audit entry so the discrepancy can be investigated.
+func (c *CallbackProcessor) handleCallbackMismatch(ctx context.Context, p *paymentDatamodel.Payment, req *PaymentCallbackRequest, cause error) {
+	c.log(ctx).Error("ALERT: payment callback does not match payment record",
+		"error", cause,
+		"payment_id", p.ID,
+		"expense_id", p.ExpenseID,
+		"external_id", p.ExternalID,
+		"expected_amount", p.AmountIDR,
+		"received_external_id", req.ExternalID,
+		"received_amount", req.Amount,
+		"gateway_status", req.Status)
+
+	event := events.NewPaymentMismatchEvent(
+		fmt.Sprintf("%d", p.ID),
+		p.ExpenseID,
+		p.ExternalID,
+		p.AmountIDR,
+		req.Amount,
+		req.ExternalID,
+		req.Status,
+	)
+	c.eventBus.Publish(context.WithoutCancel(ctx), event)
+
+	if c.auditRecorder == nil {
+		return
+	}
+
+	entry := &audit.Entry{
+		Action:       audit.ActionPaymentCallbackMismatch,
+		ResourceType: audit.ResourcePayment,
+		ResourceID:   fmt.Sprintf("%d", p.ID),
+		Metadata: map[string]interface{}{
+			"expense_id":           p.ExpenseID,
+			"external_id":          p.ExternalID,
+			"expected_amount":      p.AmountIDR,
+			"received_external_id": req.ExternalID,
+			"received_amount":      req.Amount,
+			"gateway_status":       req.Status,
+			"gateway_payment_id":   req.GatewayPaymentID,
+			"reason":               cause.Error(),
+		},
+	}
+	if err := c.auditRecorder.Record(entry); err != nil {
+		c.log(ctx).Error("failed to record payment mismatch audit entry", "error", err, "payment_id", p.ID)
+	}
+}
+
+// handleOutOfOrderCallback leaves the payment untouched when a callback would move
+// it backwards, such as a late pending after success. The callback is
+// acknowledged, since redelivering it cannot help, and audited.
+func (c *CallbackProcessor) handleOutOfOrderCallback(ctx context.Context, p *paymentDatamodel.Payment, req *PaymentCallbackRequest, status string) {
+	c.log(ctx).Warn("out-of-order payment callback ignored",
+		"payment_id", p.ID,
+		"expense_id", p.ExpenseID,
+		"external_id", p.ExternalID,
+		"current_status", p.Status,
+		"callback_status", status,
+		"gateway_status", req.Status)
+
+	if c.auditRecorder == nil {
+		return
+	}
+
+	entry := &audit.Entry{
+		Action:       audit.ActionPaymentCallbackOutOfOrder,
+		ResourceType: audit.ResourcePayment,
+		ResourceID:   fmt.Sprintf("%d", p.ID),
+		Metadata: map[string]interface{}{
+			"expense_id":         p.ExpenseID,
+			"external_id":        p.ExternalID,
+			"current_status":     p.Status,
+			"callback_status":    status,
+			"gateway_status":     req.Status,
+			"gateway_payment_id": req.GatewayPaymentID,
+		},
+	}
+	if err := c.auditRecorder.Record(entry); err != nil {
+		c.log(ctx).Error("failed to record out-of-order callback audit entry", "error", err, "payment_id", p.ID)
+	}
+}
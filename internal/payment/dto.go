@@ -3,11 +3,12 @@ package payment
 import (
 	errors "github.com/frahmantamala/expense-management/internal"
 	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	"github.com/frahmantamala/expense-management/internal/core/money"
 )
 
 type PaymentRequest struct {
-	Amount     int64  `json:"amount"`
-	ExternalID string `json:"external_id"`
+	Amount     money.Money `json:"amount"`
+	ExternalID string      `json:"external_id"`
 }
 
 type PaymentResponse struct {
@@ -32,12 +33,33 @@ type PaymentRetryRequest struct {
 }
 
 func (r *PaymentRetryRequest) Validate() error {
-	validator := validation.NewValidator()
+	if appErr := validation.Struct(r); appErr != nil {
+		return appErr
+	}
+	return nil
+}
 
-	validator.Field("external_id", r.ExternalID).Required()
-	validator.Field("expense_id", r.ExpenseID).Required()
+// PaymentJobActionRequest is why an operator requeued or cancelled a
+// payment; it goes into the audit log.
+type PaymentJobActionRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
 
-	if appErr := validator.Validate(); appErr != nil {
+func (r *PaymentJobActionRequest) Validate() error {
+	if appErr := validation.Struct(r); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// WebhookReplayRequest is why an operator replayed a stored webhook; it goes
+// into the audit log.
+type WebhookReplayRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *WebhookReplayRequest) Validate() error {
+	if appErr := validation.Struct(r); appErr != nil {
 		return appErr
 	}
 	return nil
@@ -46,7 +68,8 @@ func (r *PaymentRetryRequest) Validate() error {
 func (p *PaymentRequest) Validate() error {
 	validator := validation.NewValidator()
 
-	validator.Field("amount", p.Amount).Required().MinInt(10001, errors.ErrCodeInvalidAmount)
+	validator.Field("amount", p.Amount.Amount).Currency(p.Amount.Currency).Required().MinInt(10001, errors.ErrCodeInvalidAmount)
+	validator.Field("currency", p.Amount.Currency).OneOf(money.IDR)
 	validator.Field("external_id", p.ExternalID).Required()
 
 	if appErr := validator.Validate(); appErr != nil {
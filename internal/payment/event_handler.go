@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
 type EventHandler struct {
@@ -21,21 +22,23 @@ func NewEventHandler(orchestrator *PaymentOrchestrator, logger *slog.Logger) *Ev
 }
 
 func (h *EventHandler) HandleExpenseApproved(ctx context.Context, event events.Event) error {
+	log := logger.FromOr(ctx, h.logger)
+
 	expenseEvent, ok := event.(*events.ExpenseApprovedEvent)
 	if !ok {
-		h.logger.Error("invalid event type for expense approved handler", "event_type", event.EventType())
+		log.Error("invalid event type for expense approved handler", "event_type", event.EventType())
 		return fmt.Errorf("expected ExpenseApprovedEvent, got %T", event)
 	}
 
-	h.logger.Info("handling expense approved event for payment processing",
+	log.Info("handling expense approved event for payment processing",
 		"expense_id", expenseEvent.ExpenseID,
 		"amount", expenseEvent.Amount,
-		"user_id", expenseEvent.UserID,
+		"expense_owner_id", expenseEvent.UserID,
 		"event_id", expenseEvent.EventID())
 
 	externalID, err := h.orchestrator.ProcessPayment(expenseEvent.ExpenseID, expenseEvent.Amount)
 	if err != nil {
-		h.logger.Error("failed to process payment for approved expense",
+		log.Error("failed to process payment for approved expense",
 			"error", err,
 			"expense_id", expenseEvent.ExpenseID,
 			"amount", expenseEvent.Amount,
@@ -43,7 +46,7 @@ func (h *EventHandler) HandleExpenseApproved(ctx context.Context, event events.E
 		return fmt.Errorf("payment processing failed for expense %d: %w", expenseEvent.ExpenseID, err)
 	}
 
-	h.logger.Info("payment processing initiated successfully",
+	log.Info("payment processing initiated successfully",
 		"expense_id", expenseEvent.ExpenseID,
 		"external_id", externalID,
 		"amount", expenseEvent.Amount,
@@ -0,0 +1,144 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	apperrors "github.com/frahmantamala/expense-management/internal"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// MaxGatewayCalls bounds the calls listed for one payment; a payment polled
+// for its status for a long time can gather many.
+const MaxGatewayCalls = 200
+
+// GatewayCallRepositoryAPI stores the calls made to payment gateways.
+// paymentgateway/calllog saves them through it.
+type GatewayCallRepositoryAPI interface {
+	Save(call *paymentDatamodel.GatewayCall) error
+	ListByPayment(paymentID int64, externalID string, limit int) ([]*paymentDatamodel.GatewayCall, error)
+	PurgeBefore(before time.Time) (int64, error)
+}
+
+type GatewayCallServiceAPI interface {
+	List(ctx context.Context, tenantID, paymentID int64) ([]*GatewayCallView, error)
+}
+
+// GatewayCallView is one request sent to the gateway about a payment and
+// what the gateway answered, secrets redacted.
+type GatewayCallView struct {
+	ID             int64           `json:"id"`
+	ExternalID     string          `json:"external_id"`
+	Method         string          `json:"method" example:"POST"`
+	URL            string          `json:"url"`
+	RequestHeader  json.RawMessage `json:"request_header,omitempty" swaggertype:"object"`
+	RequestBody    string          `json:"request_body,omitempty"`
+	StatusCode     *int            `json:"status_code,omitempty"`
+	ResponseHeader json.RawMessage `json:"response_header,omitempty" swaggertype:"object"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          *string         `json:"error,omitempty"`
+	LatencyMS      int64           `json:"latency_ms"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func ToGatewayCallView(c *paymentDatamodel.GatewayCall) *GatewayCallView {
+	return &GatewayCallView{
+		ID:             c.ID,
+		ExternalID:     c.ExternalID,
+		Method:         c.Method,
+		URL:            c.URL,
+		RequestHeader:  c.RequestHeader,
+		RequestBody:    c.RequestBody,
+		StatusCode:     c.StatusCode,
+		ResponseHeader: c.ResponseHeader,
+		ResponseBody:   c.ResponseBody,
+		Error:          c.Error,
+		LatencyMS:      c.LatencyMS,
+		CreatedAt:      c.CreatedAt,
+	}
+}
+
+// GatewayCallService shows operators what was sent to the gateway about a
+// payment, and forgets calls once they are older than the retention.
+type GatewayCallService struct {
+	payments  RepositoryAPI
+	calls     GatewayCallRepositoryAPI
+	retention time.Duration
+	logger    *slog.Logger
+}
+
+// NewGatewayCallService keeps calls forever when retention is zero.
+func NewGatewayCallService(payments RepositoryAPI, calls GatewayCallRepositoryAPI, retention time.Duration, logger *slog.Logger) *GatewayCallService {
+	return &GatewayCallService{
+		payments:  payments,
+		calls:     calls,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+func (s *GatewayCallService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// List returns the newest calls about a payment of tenantID first. A
+// payment of another tenant is not found.
+func (s *GatewayCallService) List(ctx context.Context, tenantID, paymentID int64) ([]*GatewayCallView, error) {
+	p, err := s.payments.GetByID(paymentID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		return nil, apperrors.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payment: %w", err)
+	}
+	if p.TenantID != tenantID {
+		return nil, apperrors.ErrPaymentNotFound
+	}
+
+	calls, err := s.calls.ListByPayment(p.ID, p.ExternalID, MaxGatewayCalls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gateway calls: %w", err)
+	}
+	views := make([]*GatewayCallView, len(calls))
+	for i, c := range calls {
+		views[i] = ToGatewayCallView(c)
+	}
+	return views, nil
+}
+
+// Purge deletes the calls made longer than the retention before now and
+// returns how many it deleted.
+func (s *GatewayCallService) Purge(ctx context.Context, now time.Time) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	purged, err := s.calls.PurgeBefore(now.Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge gateway calls: %w", err)
+	}
+	if purged > 0 {
+		s.log(ctx).Info("purged gateway calls", "count", purged, "retention", s.retention.String())
+	}
+	return purged, nil
+}
+
+const GatewayCallPurgeJobName = "gateway_call_purge"
+
+// GatewayCallPurgeJob purges calls past their retention on every run of
+// schedule.
+func GatewayCallPurgeJob(s *GatewayCallService, schedule scheduler.Schedule) scheduler.Job {
+	return scheduler.Job{
+		Name:     GatewayCallPurgeJobName,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			_, err := s.Purge(ctx, time.Now())
+			return err
+		},
+	}
+}
@@ -1,68 +1,94 @@
 package payment
 
 import (
-	"encoding/json"
+	"context"
 	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi"
+
 	errors "github.com/frahmantamala/expense-management/internal"
 	"github.com/frahmantamala/expense-management/internal/transport"
 )
 
-type ExpenseServiceAPI interface {
-	RetryPayment(expenseID int64, userPermissions []string) error
+// PaymentRetrier re-queues an expense's payment; expense.CommandService
+// satisfies it.
+type PaymentRetrier interface {
+	RetryPayment(ctx context.Context, expenseID int64, userPermissions []string) error
+}
+
+// ExpenseAccessChecker decides who may see an expense's payment;
+// expense.QueryService satisfies it.
+type ExpenseAccessChecker interface {
+	CheckExpenseAccess(ctx context.Context, expenseID, userID int64, userPermissions []string) error
 }
 
 type Handler struct {
 	*transport.BaseHandler
-	ExpenseService ExpenseServiceAPI
+	Retrier        PaymentRetrier
+	Access         ExpenseAccessChecker
 	PaymentService ServiceAPI
+	JobService     JobServiceAPI
 }
 
-func NewHandler(expenseService ExpenseServiceAPI, paymentService ServiceAPI, logger *slog.Logger) *Handler {
+func NewHandler(retrier PaymentRetrier, access ExpenseAccessChecker, paymentService ServiceAPI, jobService JobServiceAPI, logger *slog.Logger) *Handler {
 	return &Handler{
 		BaseHandler:    transport.NewBaseHandler(logger),
-		ExpenseService: expenseService,
+		Retrier:        retrier,
+		Access:         access,
 		PaymentService: paymentService,
+		JobService:     jobService,
 	}
 }
 
+// RetryPayment godoc
+// @Summary      Retry payment
+// @Description  Re-queues the payment of an approved expense. Returns 429 when the payment queue is full.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      PaymentRetryRequest  true  "Payment to retry"
+// @Success      200   {object}  map[string]string
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Failure      429   {object}  transport.AppErrorResponse
+// @Router       /payment/retry [post]
 func (h *Handler) RetryPayment(w http.ResponseWriter, r *http.Request) {
 	user, ok := errors.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("RetryPayment: user not found in context")
-		h.HandleError(w, errors.NewUnauthorizedError("authentication required", errors.ErrCodeInvalidToken))
+		h.Log(r).Error("RetryPayment: user not found in context")
+		h.HandleError(w, r, errors.NewUnauthorizedError("authentication required", errors.ErrCodeInvalidToken))
 		return
 	}
 
 	var req PaymentRetryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.Logger.Error("RetryPayment: failed to parse request body", "error", err)
-		h.HandleError(w, errors.NewValidationError("invalid request body", errors.ErrCodeValidationFailed))
+	if !h.DecodeJSON(w, r, &req) {
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		h.Logger.Error("RetryPayment: validation error", "error", err)
-		h.HandleServiceError(w, err)
+		h.Log(r).Error("RetryPayment: validation error", "error", err)
+		h.HandleServiceError(w, r, err)
 		return
 	}
 
 	expenseID, err := strconv.ParseInt(req.ExpenseID, 10, 64)
 	if err != nil {
-		h.Logger.Error("RetryPayment: invalid expense ID", "expense_id", req.ExpenseID)
-		h.HandleError(w, errors.NewValidationError("invalid expense ID", errors.ErrCodeValidationFailed))
+		h.Log(r).Error("RetryPayment: invalid expense ID", "expense_id", req.ExpenseID)
+		h.HandleError(w, r, errors.NewValidationError("invalid expense ID", errors.ErrCodeValidationFailed))
 		return
 	}
 
-	if err := h.ExpenseService.RetryPayment(expenseID, user.Permissions); err != nil {
-		h.Logger.Error("RetryPayment: service error", "error", err, "expense_id", expenseID, "external_id", req.ExternalID, "user_id", user.ID)
-		h.HandleServiceError(w, err)
+	if err := h.Retrier.RetryPayment(r.Context(), expenseID, user.Permissions); err != nil {
+		h.Log(r).Error("RetryPayment: service error", "error", err, "expense_id", expenseID, "external_id", req.ExternalID, "user_id", user.ID)
+		h.HandleServiceError(w, r, err)
 		return
 	}
 
-	h.Logger.Info("RetryPayment: payment retry initiated",
+	h.Log(r).Info("RetryPayment: payment retry initiated",
 		"expense_id", expenseID,
 		"external_id", req.ExternalID,
 		"user_id", user.ID)
@@ -73,3 +99,50 @@ func (h *Handler) RetryPayment(w http.ResponseWriter, r *http.Request) {
 		"external_id": req.ExternalID,
 	})
 }
+
+// GetPaymentJob godoc
+// @Summary      Payment job status
+// @Tags         payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        external_id  path      string  true  "Payment external ID"
+// @Success      200          {object}  PaymentJobView
+// @Failure      403          {object}  transport.AppErrorResponse
+// @Failure      404          {object}  transport.AppErrorResponse
+// @Router       /payments/jobs/{external_id} [get]
+func (h *Handler) GetPaymentJob(w http.ResponseWriter, r *http.Request) {
+	user, ok := errors.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.Log(r).Error("GetPaymentJob: user not found in context")
+		h.HandleError(w, r, errors.NewUnauthorizedError("authentication required", errors.ErrCodeInvalidToken))
+		return
+	}
+
+	externalID := chi.URLParam(r, "external_id")
+	if externalID == "" {
+		h.HandleError(w, r, errors.NewValidationError("external_id is required", errors.ErrCodeValidationFailed))
+		return
+	}
+
+	paymentRecord, err := h.PaymentService.GetPaymentByExternalID(externalID)
+	if err != nil {
+		h.Log(r).Warn("GetPaymentJob: payment not found", "external_id", externalID, "error", err)
+		h.HandleError(w, r, ErrPaymentJobNotFound)
+		return
+	}
+
+	if err := h.Access.CheckExpenseAccess(r.Context(), paymentRecord.ExpenseID, user.ID, user.Permissions); err != nil {
+		h.Log(r).Warn("GetPaymentJob: access denied", "external_id", externalID, "user_id", user.ID, "error", err)
+		h.HandleServiceError(w, r, err)
+		return
+	}
+
+	job, err := h.JobService.GetJob(externalID)
+	if err != nil {
+		h.Log(r).Error("GetPaymentJob: failed to get job", "external_id", externalID, "error", err)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, ToJobView(job))
+}
@@ -2,28 +2,35 @@ package payment_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 
 	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/auth"
 	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
 	"github.com/frahmantamala/expense-management/internal/expense"
 	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
 )
 
 type mockExpenseService struct {
 	shouldReturnError error
 	shouldCheckPerm   bool
+	accessError       error
 }
 
-func (m *mockExpenseService) RetryPayment(expenseID int64, userPermissions []string) error {
+func (m *mockExpenseService) RetryPayment(ctx context.Context, expenseID int64, userPermissions []string) error {
 	if m.shouldReturnError != nil {
 		return m.shouldReturnError
 	}
@@ -31,7 +38,7 @@ func (m *mockExpenseService) RetryPayment(expenseID int64, userPermissions []str
 	if m.shouldCheckPerm {
 		hasPermission := false
 		for _, perm := range userPermissions {
-			if perm == "can_approve" {
+			if perm == string(auth.PermRetryPayments) {
 				hasPermission = true
 				break
 			}
@@ -44,6 +51,22 @@ func (m *mockExpenseService) RetryPayment(expenseID int64, userPermissions []str
 	return nil
 }
 
+func (m *mockExpenseService) CheckExpenseAccess(ctx context.Context, expenseID, userID int64, userPermissions []string) error {
+	return m.accessError
+}
+
+type mockJobService struct {
+	job *payment.PaymentJob
+	err error
+}
+
+func (m *mockJobService) GetJob(externalID string) (*payment.PaymentJob, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.job, nil
+}
+
 type mockPaymentService struct {
 	createPaymentError        error
 	processPaymentError       error
@@ -51,6 +74,10 @@ type mockPaymentService struct {
 	getPaymentByExpenseError  error
 	getPaymentByExternalError error
 	updatePaymentStatusError  error
+	updatedStatuses           []string
+	failureClasses            []string
+	retryAt                   *time.Time
+	installments              []*payment.Installment
 	payment                   *payment.Payment
 	response                  *paymentpkg.PaymentResponse
 }
@@ -91,9 +118,33 @@ func (m *mockPaymentService) GetPaymentByExternalID(externalID string) (*payment
 }
 
 func (m *mockPaymentService) UpdatePaymentStatus(paymentID int64, status string, paymentMethod *string, gatewayResponse json.RawMessage, failureReason *string) error {
+	m.updatedStatuses = append(m.updatedStatuses, status)
 	return m.updatePaymentStatusError
 }
 
+func (m *mockPaymentService) RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error) {
+	for _, existing := range m.installments {
+		if existing.GatewayPaymentID == installment.GatewayPaymentID {
+			return m.payment, false, nil
+		}
+	}
+	m.installments = append(m.installments, installment)
+
+	updated := *m.payment
+	updated.SettledAmount += installment.AmountIDR
+	m.payment = &updated
+	return m.payment, true, nil
+}
+
+func (m *mockPaymentService) ListInstallments(paymentID int64) ([]*payment.Installment, error) {
+	return m.installments, nil
+}
+
+func (m *mockPaymentService) RecordFailure(p *payment.Payment, failureClass string) (*time.Time, error) {
+	m.failureClasses = append(m.failureClasses, failureClass)
+	return m.retryAt, nil
+}
+
 func createTestUser(id int64, permissions []string) *internal.User {
 	return &internal.User{
 		ID:          id,
@@ -115,6 +166,7 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 		handler        *paymentpkg.Handler
 		expenseService *mockExpenseService
 		paymentService *mockPaymentService
+		jobService     *mockJobService
 		recorder       *httptest.ResponseRecorder
 		logger         *slog.Logger
 	)
@@ -122,19 +174,19 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 	ginkgo.BeforeEach(func() {
 		expenseService = &mockExpenseService{}
 		paymentService = &mockPaymentService{}
+		jobService = &mockJobService{}
 		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-		handler = paymentpkg.NewHandler(expenseService, paymentService, logger)
+		handler = paymentpkg.NewHandler(expenseService, expenseService, paymentService, jobService, logger)
 		recorder = httptest.NewRecorder()
 	})
 
 	ginkgo.Context("RetryPayment", func() {
 		ginkgo.When("retry request is valid", func() {
 			ginkgo.It("should retry payment successfully", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				reqBody := map[string]interface{}{
 					"expense_id":  "123",
 					"external_id": "test-external-id",
-					"amount":      100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -153,7 +205,7 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 
 		ginkgo.When("request body is invalid JSON", func() {
 			ginkgo.It("should return bad request", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", []byte("invalid json"), user)
 
 				handler.RetryPayment(recorder, req)
@@ -162,12 +214,24 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 			})
 		})
 
+		ginkgo.When("request body has unknown fields", func() {
+			ginkgo.It("should return bad request naming the field", func() {
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
+				jsonBody := []byte(`{"expense_id":"123","external_id":"test-external-id","amount":100.5}`)
+				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
+
+				handler.RetryPayment(recorder, req)
+
+				gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusBadRequest))
+				gomega.Expect(recorder.Body.String()).To(gomega.ContainSubstring(`"field":"amount"`))
+			})
+		})
+
 		ginkgo.Context("when request validation fails", func() {
 			ginkgo.It("should return validation error for missing expense_id", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				reqBody := map[string]interface{}{
 					"external_id": "test-external-id",
-					"amount":      100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -178,10 +242,9 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 			})
 
 			ginkgo.It("should return validation error for missing external_id", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				reqBody := map[string]interface{}{
 					"expense_id": "123",
-					"amount":     100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -194,11 +257,10 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 
 		ginkgo.Context("when expense ID is invalid", func() {
 			ginkgo.It("should return bad request for non-numeric expense ID", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				reqBody := map[string]interface{}{
 					"expense_id":  "invalid",
 					"external_id": "test-external-id",
-					"amount":      100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -211,12 +273,11 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 
 		ginkgo.Context("when expense service fails", func() {
 			ginkgo.It("should return internal server error", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				expenseService.shouldReturnError = errors.New("database error")
 				reqBody := map[string]interface{}{
 					"expense_id":  "123",
 					"external_id": "test-external-id",
-					"amount":      100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -227,14 +288,30 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 			})
 		})
 
+		ginkgo.Context("when the payment queue is full", func() {
+			ginkgo.It("should return too many requests", func() {
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
+				expenseService.shouldReturnError = fmt.Errorf("payment retry failed: %w", paymentgateway.ErrQueueFull)
+				reqBody := map[string]interface{}{
+					"expense_id":  "123",
+					"external_id": "test-external-id",
+				}
+				jsonBody, _ := json.Marshal(reqBody)
+				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
+
+				handler.RetryPayment(recorder, req)
+
+				gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusTooManyRequests))
+			})
+		})
+
 		ginkgo.Context("when user lacks permission", func() {
 			ginkgo.It("should return forbidden error", func() {
-				user := createTestUser(1, []string{"can_read_expense"})
+				user := createTestUser(1, []string{string(auth.PermViewExpenses)})
 				expenseService.shouldCheckPerm = true
 				reqBody := map[string]interface{}{
 					"expense_id":  "123",
 					"external_id": "test-external-id",
-					"amount":      100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -247,12 +324,11 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 
 		ginkgo.Context("when expense is not found", func() {
 			ginkgo.It("should return not found error", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				expenseService.shouldReturnError = expense.ErrExpenseNotFound
 				reqBody := map[string]interface{}{
 					"expense_id":  "999",
 					"external_id": "test-external-id",
-					"amount":      100.50,
 				}
 				jsonBody, _ := json.Marshal(reqBody)
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", jsonBody, user)
@@ -264,10 +340,68 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 		})
 	})
 
+	ginkgo.Context("GetPaymentJob", func() {
+		newJobRequest := func(externalID string, user *internal.User) *http.Request {
+			req := createRequestWithUser("GET", "/api/v1/payments/jobs/"+externalID, nil, user)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("external_id", externalID)
+			return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		}
+
+		ginkgo.BeforeEach(func() {
+			paymentService.payment = &payment.Payment{ID: 1, ExpenseID: 123, ExternalID: "exp-123-50000"}
+		})
+
+		ginkgo.It("should return the job status", func() {
+			workerID := 3
+			startedAt := time.Now()
+			jobService.job = &payment.PaymentJob{
+				ExternalID: "exp-123-50000",
+				Status:     paymentpkg.JobStatusProcessing,
+				WorkerID:   &workerID,
+				Attempts:   1,
+				QueuedAt:   startedAt.Add(-time.Second),
+				StartedAt:  &startedAt,
+			}
+
+			handler.GetPaymentJob(recorder, newJobRequest("exp-123-50000", createTestUser(1, []string{})))
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			var response map[string]interface{}
+			gomega.Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(gomega.Succeed())
+			gomega.Expect(response["status"]).To(gomega.Equal("processing"))
+			gomega.Expect(response["worker_id"]).To(gomega.BeNumerically("==", 3))
+		})
+
+		ginkgo.It("should return not found when the payment does not exist", func() {
+			paymentService.getPaymentByExternalError = errors.New("record not found")
+
+			handler.GetPaymentJob(recorder, newJobRequest("missing", createTestUser(1, []string{})))
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusNotFound))
+		})
+
+		ginkgo.It("should return not found when no job was recorded", func() {
+			jobService.err = paymentpkg.ErrPaymentJobNotFound
+
+			handler.GetPaymentJob(recorder, newJobRequest("exp-123-50000", createTestUser(1, []string{})))
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusNotFound))
+		})
+
+		ginkgo.It("should return forbidden when the user cannot view the expense", func() {
+			expenseService.accessError = expense.ErrUnauthorizedAccess
+
+			handler.GetPaymentJob(recorder, newJobRequest("exp-123-50000", createTestUser(2, []string{})))
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusForbidden))
+		})
+	})
+
 	ginkgo.Context("HTTP Method Validation", func() {
 		ginkgo.Context("when using wrong HTTP method", func() {
 			ginkgo.It("should handle GET requests gracefully", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				req := createRequestWithUser("GET", "/api/v1/payment/retry", []byte{}, user)
 
 				handler.RetryPayment(recorder, req)
@@ -280,7 +414,7 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 	ginkgo.Context("Content-Type Validation", func() {
 		ginkgo.Context("when Content-Type is not application/json", func() {
 			ginkgo.It("should handle different content types", func() {
-				user := createTestUser(1, []string{"can_approve"})
+				user := createTestUser(1, []string{string(auth.PermRetryPayments)})
 				req := createRequestWithUser("POST", "/api/v1/payment/retry", []byte("text content"), user)
 				req.Header.Set("Content-Type", "text/plain")
 
@@ -296,7 +430,6 @@ var _ = ginkgo.Describe("PaymentHandler", func() {
 			reqBody := map[string]interface{}{
 				"expense_id":  "123",
 				"external_id": "test-external-id",
-				"amount":      100.50,
 			}
 			jsonBody, _ := json.Marshal(reqBody)
 			req := httptest.NewRequest("POST", "/api/v1/payment/retry", bytes.NewBuffer(jsonBody))
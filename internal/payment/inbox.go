@@ -0,0 +1,371 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	apperrors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+const (
+	InboxStatusPending    = "pending"
+	InboxStatusProcessing = "processing"
+	InboxStatusProcessed  = "processed"
+	// InboxStatusRejected callbacks did not match their payment; retrying
+	// would not change that.
+	InboxStatusRejected = "rejected"
+	// InboxStatusFailed callbacks ran out of attempts.
+	InboxStatusFailed = "failed"
+)
+
+var ErrWebhookNotFound = apperrors.ErrWebhookNotFound
+
+type InboxRepositoryAPI interface {
+	// Create stores entry unless one with the same dedupe key exists, and
+	// reports whether it was stored.
+	Create(entry *paymentDatamodel.CallbackInboxEntry) (bool, error)
+	// ClaimDue marks up to limit pending entries, and processing entries
+	// whose lease ran out, as processing until leaseUntil.
+	ClaimDue(limit int, now, leaseUntil time.Time) ([]*paymentDatamodel.CallbackInboxEntry, error)
+	MarkProcessed(id int64, processedAt time.Time) error
+	MarkRejected(id int64, reason string, at time.Time) error
+	MarkFailed(id int64, reason string, at time.Time) error
+	ScheduleRetry(id int64, reason string, nextAttemptAt time.Time) error
+	GetByID(id int64) (*paymentDatamodel.CallbackInboxEntry, error)
+	// LatestByExternalID returns the last callback received for a payment.
+	LatestByExternalID(externalID string) (*paymentDatamodel.CallbackInboxEntry, error)
+	// ClaimReplay marks a processed, rejected or failed entry as processing
+	// until leaseUntil, reporting whether it did, so an entry is replayed
+	// once however many operators ask at the same time.
+	ClaimReplay(id int64, now, leaseUntil time.Time) (bool, error)
+}
+
+type WebhookReplayerAPI interface {
+	Replay(ctx context.Context, tenantID, inboxID, actorID int64, reason string) (*WebhookReplayResult, error)
+}
+
+// WebhookReplayResult is where a stored callback stands after an operator
+// replayed it.
+type WebhookReplayResult struct {
+	InboxID    int64  `json:"inbox_id"`
+	ExternalID string `json:"external_id"`
+	// Status is processed or rejected once the callback was applied, or
+	// pending when it failed again and will be retried.
+	Status        string     `json:"status" example:"processed"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error,omitempty"`
+	PaymentStatus string     `json:"payment_status"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+}
+
+// InboxConfig tunes callback retries. Zero values fall back to 10 attempts,
+// 5s initial backoff doubling up to 1h, and batches of 50.
+type InboxConfig struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+	// Lease is how long a claimed entry stays hidden from other workers.
+	Lease time.Duration
+}
+
+func (c InboxConfig) withDefaults() InboxConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 10
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 5 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Hour
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 50
+	}
+	if c.Lease <= 0 {
+		c.Lease = 5 * time.Minute
+	}
+	return c
+}
+
+// CallbackInbox stores gateway callbacks durably before they are applied, so a
+// processing failure is retried instead of losing the notification.
+type CallbackInbox struct {
+	repo          InboxRepositoryAPI
+	processor     *CallbackProcessor
+	cfg           InboxConfig
+	auditRecorder AuditRecorder
+	now           func() time.Time
+	logger        *slog.Logger
+}
+
+// NewCallbackInbox takes a nil auditRecorder when replays are only logged.
+func NewCallbackInbox(repo InboxRepositoryAPI, processor *CallbackProcessor, cfg InboxConfig, auditRecorder AuditRecorder, logger *slog.Logger) *CallbackInbox {
+	return &CallbackInbox{
+		repo:          repo,
+		processor:     processor,
+		cfg:           cfg.withDefaults(),
+		auditRecorder: auditRecorder,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+func (i *CallbackInbox) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, i.logger)
+}
+
+// Accept stores req for processing. Redelivered callbacks are acknowledged
+// without being stored twice.
+func (i *CallbackInbox) Accept(ctx context.Context, req *PaymentCallbackRequest, now time.Time) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode payment callback: %w", err)
+	}
+
+	entry := &paymentDatamodel.CallbackInboxEntry{
+		DedupeKey:     dedupeKey(req),
+		ExternalID:    req.ExternalID,
+		Payload:       payload,
+		Status:        InboxStatusPending,
+		NextAttemptAt: now,
+		ReceivedAt:    now,
+		UpdatedAt:     now,
+	}
+	stored, err := i.repo.Create(entry)
+	if err != nil {
+		return fmt.Errorf("failed to store payment callback: %w", err)
+	}
+
+	if !stored {
+		i.log(ctx).Info("duplicate payment callback ignored", "external_id", req.ExternalID, "status", req.Status)
+		return nil
+	}
+	i.log(ctx).Info("payment callback queued", "inbox_id", entry.ID, "external_id", req.ExternalID, "status", req.Status)
+	return nil
+}
+
+// ProcessDue applies one batch of due callbacks and returns how many it
+// claimed.
+func (i *CallbackInbox) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	entries, err := i.repo.ClaimDue(i.cfg.BatchSize, now, now.Add(i.cfg.Lease))
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim payment callbacks: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return len(entries), err
+		}
+		i.process(ctx, entry, now)
+	}
+	return len(entries), nil
+}
+
+func (i *CallbackInbox) process(ctx context.Context, entry *paymentDatamodel.CallbackInboxEntry, now time.Time) {
+	var req PaymentCallbackRequest
+	if err := json.Unmarshal(entry.Payload, &req); err != nil {
+		i.finish(ctx, entry, i.repo.MarkFailed(entry.ID, "undecodable payload: "+err.Error(), now))
+		return
+	}
+
+	err := i.processor.Process(ctx, &req)
+	switch {
+	case err == nil:
+		i.finish(ctx, entry, i.repo.MarkProcessed(entry.ID, now))
+	case errors.Is(err, ErrCallbackMismatch):
+		i.finish(ctx, entry, i.repo.MarkRejected(entry.ID, err.Error(), now))
+	case entry.Attempts >= i.cfg.MaxAttempts:
+		i.log(ctx).Error("ALERT: payment callback gave up after retries",
+			"error", err,
+			"inbox_id", entry.ID,
+			"external_id", entry.ExternalID,
+			"attempts", entry.Attempts)
+		i.finish(ctx, entry, i.repo.MarkFailed(entry.ID, err.Error(), now))
+	default:
+		next := now.Add(i.backoff(entry.Attempts))
+		i.log(ctx).Warn("payment callback failed, will retry",
+			"error", err,
+			"inbox_id", entry.ID,
+			"external_id", entry.ExternalID,
+			"attempts", entry.Attempts,
+			"next_attempt_at", next)
+		i.finish(ctx, entry, i.repo.ScheduleRetry(entry.ID, err.Error(), next))
+	}
+}
+
+// Replay applies a stored callback of a payment of tenantID again, as when
+// it was handled wrongly before a fix. Replaying is safe to repeat: a
+// payment already in the callback's status keeps it, and the completed or
+// failed event published again is ignored by the expense and ledger, which
+// already applied it. Callbacks still waiting for the worker, and ones a
+// newer callback for the same payment has superseded, are refused.
+func (i *CallbackInbox) Replay(ctx context.Context, tenantID, inboxID, actorID int64, reason string) (*WebhookReplayResult, error) {
+	entry, err := i.repo.GetByID(inboxID)
+	if errors.Is(err, ErrWebhookNotFound) {
+		return nil, apperrors.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payment callback: %w", err)
+	}
+	p, err := i.processor.paymentService.GetPaymentByExternalID(entry.ExternalID)
+	if err != nil || p.TenantID != tenantID {
+		// A callback for no known payment has nothing to replay onto.
+		return nil, apperrors.ErrWebhookNotFound
+	}
+
+	switch entry.Status {
+	case InboxStatusPending, InboxStatusProcessing:
+		return nil, replayConflict("webhook is still waiting to be processed")
+	}
+	latest, err := i.repo.LatestByExternalID(entry.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest payment callback: %w", err)
+	}
+	if latest.ID != entry.ID {
+		return nil, replayConflict(fmt.Sprintf("webhook %d for the same payment was received after this one", latest.ID))
+	}
+
+	now := i.now()
+	previous := entry.Status
+	claimed, err := i.repo.ClaimReplay(entry.ID, now, now.Add(i.cfg.Lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim payment callback: %w", err)
+	}
+	if !claimed {
+		return nil, replayConflict("webhook changed while it was being replayed; try again")
+	}
+	entry.Status = InboxStatusProcessing
+	entry.Attempts++
+
+	i.log(ctx).Info("replaying payment callback", "inbox_id", entry.ID, "external_id", entry.ExternalID, "previous_status", previous, "actor_id", actorID)
+	i.process(ctx, entry, now)
+
+	replayed, err := i.repo.GetByID(entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload payment callback: %w", err)
+	}
+	p, err = i.processor.paymentService.GetPaymentByExternalID(entry.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload payment: %w", err)
+	}
+	i.record(ctx, p, replayed, previous, actorID, reason)
+
+	return &WebhookReplayResult{
+		InboxID:       replayed.ID,
+		ExternalID:    replayed.ExternalID,
+		Status:        replayed.Status,
+		Attempts:      replayed.Attempts,
+		LastError:     replayed.LastError,
+		PaymentStatus: p.Status,
+		ProcessedAt:   replayed.ProcessedAt,
+	}, nil
+}
+
+func (i *CallbackInbox) record(ctx context.Context, p *paymentDatamodel.Payment, entry *paymentDatamodel.CallbackInboxEntry, previous string, actorID int64, reason string) {
+	if i.auditRecorder == nil {
+		return
+	}
+
+	entryAudit := &audit.Entry{
+		Action:       audit.ActionPaymentWebhookReplayed,
+		ResourceType: audit.ResourcePayment,
+		ResourceID:   fmt.Sprintf("%d", p.ID),
+		ActorID:      &actorID,
+		Metadata: map[string]interface{}{
+			"inbox_id":        entry.ID,
+			"external_id":     entry.ExternalID,
+			"previous_status": previous,
+			"status":          entry.Status,
+			"payment_status":  p.Status,
+			"reason":          reason,
+		},
+		CreatedAt: i.now().UTC(),
+	}
+	if err := i.auditRecorder.Record(entryAudit); err != nil {
+		i.log(ctx).Error("failed to record webhook replay audit entry", "error", err, "inbox_id", entry.ID)
+	}
+}
+
+func replayConflict(message string) error {
+	return apperrors.NewConflictError(message, apperrors.ErrCodeWebhookReplayConflict)
+}
+
+// finish logs a failed status write; the lease expires and the entry is
+// claimed again.
+func (i *CallbackInbox) finish(ctx context.Context, entry *paymentDatamodel.CallbackInboxEntry, err error) {
+	if err != nil {
+		i.log(ctx).Error("failed to update payment callback inbox", "error", err, "inbox_id", entry.ID)
+	}
+}
+
+// backoff doubles from BaseBackoff after each attempt, capped at MaxBackoff.
+func (i *CallbackInbox) backoff(attempts int) time.Duration {
+	d := i.cfg.BaseBackoff
+	for n := 1; n < attempts && d < i.cfg.MaxBackoff; n++ {
+		d *= 2
+	}
+	if d > i.cfg.MaxBackoff {
+		d = i.cfg.MaxBackoff
+	}
+	return d
+}
+
+func dedupeKey(req *PaymentCallbackRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%s",
+		req.ExternalID, req.Status, req.GatewayPaymentID, req.Amount, req.FailureReason)))
+	return hex.EncodeToString(sum[:])
+}
+
+// InboxWorker drains the callback inbox on an interval.
+type InboxWorker struct {
+	inbox    *CallbackInbox
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewInboxWorker(inbox *CallbackInbox, interval time.Duration, logger *slog.Logger) *InboxWorker {
+	return &InboxWorker{
+		inbox:    inbox,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w *InboxWorker) Run(ctx context.Context) {
+	w.logger.Info("payment callback inbox worker started", "poll_interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("payment callback inbox worker stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// Keep going while full batches come back.
+		for ctx.Err() == nil {
+			claimed, err := w.inbox.ProcessDue(ctx, time.Now())
+			if err != nil {
+				w.logger.Error("payment callback inbox run failed", "error", err)
+				break
+			}
+			if claimed < w.inbox.cfg.BatchSize {
+				break
+			}
+		}
+	}
+}
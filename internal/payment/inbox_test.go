@@ -0,0 +1,256 @@
+package payment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type fakeInboxRepository struct {
+	entries []*payment.CallbackInboxEntry
+}
+
+func (f *fakeInboxRepository) Create(entry *payment.CallbackInboxEntry) (bool, error) {
+	for _, e := range f.entries {
+		if e.DedupeKey == entry.DedupeKey {
+			return false, nil
+		}
+	}
+	entry.ID = int64(len(f.entries) + 1)
+	f.entries = append(f.entries, entry)
+	return true, nil
+}
+
+func (f *fakeInboxRepository) ClaimDue(limit int, now, leaseUntil time.Time) ([]*payment.CallbackInboxEntry, error) {
+	var claimed []*payment.CallbackInboxEntry
+	for _, e := range f.entries {
+		if len(claimed) == limit {
+			break
+		}
+		due := e.Status == paymentpkg.InboxStatusPending || e.Status == paymentpkg.InboxStatusProcessing
+		if due && !e.NextAttemptAt.After(now) {
+			e.Status = paymentpkg.InboxStatusProcessing
+			e.Attempts++
+			e.NextAttemptAt = leaseUntil
+			claimed = append(claimed, e)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeInboxRepository) MarkProcessed(id int64, processedAt time.Time) error {
+	f.entries[id-1].Status = paymentpkg.InboxStatusProcessed
+	return nil
+}
+
+func (f *fakeInboxRepository) MarkRejected(id int64, reason string, at time.Time) error {
+	f.entries[id-1].Status = paymentpkg.InboxStatusRejected
+	f.entries[id-1].LastError = &reason
+	return nil
+}
+
+func (f *fakeInboxRepository) MarkFailed(id int64, reason string, at time.Time) error {
+	f.entries[id-1].Status = paymentpkg.InboxStatusFailed
+	f.entries[id-1].LastError = &reason
+	return nil
+}
+
+func (f *fakeInboxRepository) ScheduleRetry(id int64, reason string, nextAttemptAt time.Time) error {
+	f.entries[id-1].Status = paymentpkg.InboxStatusPending
+	f.entries[id-1].LastError = &reason
+	f.entries[id-1].NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (f *fakeInboxRepository) GetByID(id int64) (*payment.CallbackInboxEntry, error) {
+	if id < 1 || int(id) > len(f.entries) {
+		return nil, paymentpkg.ErrWebhookNotFound
+	}
+	stored := *f.entries[id-1]
+	return &stored, nil
+}
+
+func (f *fakeInboxRepository) LatestByExternalID(externalID string) (*payment.CallbackInboxEntry, error) {
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		if f.entries[i].ExternalID == externalID {
+			return f.entries[i], nil
+		}
+	}
+	return nil, paymentpkg.ErrWebhookNotFound
+}
+
+func (f *fakeInboxRepository) ClaimReplay(id int64, now, leaseUntil time.Time) (bool, error) {
+	e := f.entries[id-1]
+	switch e.Status {
+	case paymentpkg.InboxStatusProcessed, paymentpkg.InboxStatusRejected, paymentpkg.InboxStatusFailed:
+		e.Status = paymentpkg.InboxStatusProcessing
+		e.Attempts++
+		e.NextAttemptAt = leaseUntil
+		return true, nil
+	}
+	return false, nil
+}
+
+var _ = ginkgo.Describe("CallbackInbox", func() {
+	var (
+		ctx            context.Context
+		now            time.Time
+		repo           *fakeInboxRepository
+		paymentService *mockPaymentService
+		inbox          *paymentpkg.CallbackInbox
+		handler        *paymentpkg.WebhookHandler
+		auditLog       *mockAuditRecorder
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 10, 13, 9, 0, 0, 0, time.UTC)
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		repo = &fakeInboxRepository{}
+		paymentService = &mockPaymentService{
+			payment: &payment.Payment{
+				ID:         7,
+				ExpenseID:  42,
+				ExternalID: "exp-42-150000",
+				AmountIDR:  150000,
+				Status:     paymentpkg.StatusPending,
+			},
+		}
+		processor := paymentpkg.NewCallbackProcessor(paymentService, events.NewEventBus(logger), &mockAuditRecorder{}, logger)
+		auditLog = &mockAuditRecorder{}
+		inbox = paymentpkg.NewCallbackInbox(repo, processor, paymentpkg.InboxConfig{MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute}, auditLog, logger)
+		handler = paymentpkg.NewWebhookHandler(transport.NewBaseHandler(logger), processor, inbox, testSigner, logger)
+	})
+
+	sendCallback := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		rec := httptest.NewRecorder()
+		handler.HandlePaymentCallback(rec, newSignedCallback(jsonBody))
+		return rec
+	}
+
+	accept := func(amount int64) {
+		err := inbox.Accept(ctx, &paymentpkg.PaymentCallbackRequest{ExternalID: "exp-42-150000", Status: "success", Amount: amount}, now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+	}
+
+	ginkgo.It("should acknowledge callbacks without applying them inline", func() {
+		body := map[string]interface{}{"external_id": "exp-42-150000", "status": "success", "amount": 150000}
+
+		first := sendCallback(body)
+		second := sendCallback(body)
+
+		gomega.Expect(first.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(first.Body.String()).To(gomega.ContainSubstring(`"accepted"`))
+		gomega.Expect(second.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(repo.entries).To(gomega.HaveLen(1))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should apply due callbacks and mark them processed", func() {
+		accept(150000)
+
+		claimed, err := inbox.ProcessDue(ctx, now)
+
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.Equal(1))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.Equal([]string{paymentpkg.StatusSuccess}))
+		gomega.Expect(repo.entries[0].Status).To(gomega.Equal(paymentpkg.InboxStatusProcessed))
+	})
+
+	ginkgo.It("should retry with growing backoff and give up after max attempts", func() {
+		paymentService.getPaymentByExternalError = errors.New("database unavailable")
+		accept(150000)
+
+		_, err := inbox.ProcessDue(ctx, now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.entries[0].Status).To(gomega.Equal(paymentpkg.InboxStatusPending))
+		gomega.Expect(repo.entries[0].NextAttemptAt).To(gomega.Equal(now.Add(time.Second)))
+
+		_, err = inbox.ProcessDue(ctx, now.Add(time.Second))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.entries[0].NextAttemptAt).To(gomega.Equal(now.Add(3 * time.Second)))
+
+		_, err = inbox.ProcessDue(ctx, now.Add(3*time.Second))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.entries[0].Status).To(gomega.Equal(paymentpkg.InboxStatusFailed))
+		gomega.Expect(*repo.entries[0].LastError).To(gomega.ContainSubstring("database unavailable"))
+	})
+
+	ginkgo.It("should reject mismatched callbacks without retrying", func() {
+		accept(1)
+
+		_, err := inbox.ProcessDue(ctx, now)
+
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.entries[0].Status).To(gomega.Equal(paymentpkg.InboxStatusRejected))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+	})
+
+	ginkgo.Describe("Replay", func() {
+		ginkgo.It("should apply a processed callback again and audit who did it and why", func() {
+			accept(150000)
+			_, err := inbox.ProcessDue(ctx, now)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			result, err := inbox.Replay(ctx, 0, 1, 9, "expense stuck after the status bug")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(result.Status).To(gomega.Equal(paymentpkg.InboxStatusProcessed))
+			gomega.Expect(result.Attempts).To(gomega.Equal(2))
+			gomega.Expect(paymentService.updatedStatuses).To(gomega.Equal([]string{paymentpkg.StatusSuccess, paymentpkg.StatusSuccess}))
+
+			gomega.Expect(auditLog.entries).To(gomega.HaveLen(1))
+			entry := auditLog.entries[0]
+			gomega.Expect(entry.Action).To(gomega.Equal(audit.ActionPaymentWebhookReplayed))
+			gomega.Expect(entry.ResourceID).To(gomega.Equal("7"))
+			gomega.Expect(*entry.ActorID).To(gomega.Equal(int64(9)))
+			gomega.Expect(entry.Metadata).To(gomega.HaveKeyWithValue("previous_status", paymentpkg.InboxStatusProcessed))
+			gomega.Expect(entry.Metadata).To(gomega.HaveKeyWithValue("reason", "expense stuck after the status bug"))
+		})
+
+		ginkgo.It("should refuse a callback still waiting for the worker", func() {
+			accept(150000)
+
+			_, err := inbox.Replay(ctx, 0, 1, 9, "impatient")
+			gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("still waiting")))
+			gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("should refuse a callback a newer one superseded", func() {
+			accept(1)
+			accept(150000)
+			_, err := inbox.ProcessDue(ctx, now)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			_, err = inbox.Replay(ctx, 0, 1, 9, "replay the rejected one")
+			gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("received after this one")))
+			gomega.Expect(repo.entries[0].Status).To(gomega.Equal(paymentpkg.InboxStatusRejected))
+		})
+
+		ginkgo.It("should not find callbacks of another tenant's payment or unknown ones", func() {
+			accept(150000)
+			_, err := inbox.ProcessDue(ctx, now)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			_, err = inbox.Replay(ctx, 3, 1, 9, "wrong tenant")
+			gomega.Expect(err).To(gomega.MatchError(paymentpkg.ErrWebhookNotFound))
+			_, err = inbox.Replay(ctx, 0, 99, 9, "typo")
+			gomega.Expect(err).To(gomega.MatchError(paymentpkg.ErrWebhookNotFound))
+			gomega.Expect(auditLog.entries).To(gomega.BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,174 @@
+package payment
+
+import (
+	"log/slog"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+const (
+	JobStatusQueued     = "queued"
+	JobStatusSpilled    = "spilled"
+	JobStatusProcessing = "processing"
+	JobStatusCompleted  = "completed"
+	JobStatusFailed     = "failed"
+	// JobStatusDeadLettered marks a poison job: it crashed the worker too
+	// many times and is no longer retried. Its payment stays pending.
+	JobStatusDeadLettered = "dead_lettered"
+	// JobStatusCancelled marks a job an operator cancelled; workers skip it
+	// and its payment is failed.
+	JobStatusCancelled = "cancelled"
+)
+
+var ErrPaymentJobNotFound = errors.ErrPaymentJobNotFound
+
+type JobRepositoryAPI interface {
+	MarkQueued(externalID string, queuedAt time.Time) error
+	MarkProcessing(externalID string, workerID int, startedAt time.Time) error
+	MarkCompleted(externalID string, completedAt time.Time) error
+	MarkFailed(externalID string, reason string, failedAt time.Time) error
+	MarkDeadLettered(externalID string, reason string, deadLetteredAt time.Time) error
+	// MarkCancelled cancels the job only while it is still in status from,
+	// reporting whether it did, so a job a worker took meanwhile is left
+	// alone.
+	MarkCancelled(externalID, from, reason string, cancelledAt time.Time) (bool, error)
+	MarkSpilled(externalID string, amountIDR int64, gatewayPaymentID string, spilledAt time.Time) error
+	// MarkRouted records the provider on both the job and its payment.
+	MarkRouted(externalID, provider string, routedAt time.Time) error
+	ClaimSpilled(limit int, claimedAt time.Time) ([]*payment.PaymentJob, error)
+	GetByExternalID(externalID string) (*payment.PaymentJob, error)
+}
+
+type JobServiceAPI interface {
+	GetJob(externalID string) (*payment.PaymentJob, error)
+}
+
+type PaymentJobView struct {
+	ExternalID  string     `json:"external_id"`
+	Status      string     `json:"status"`
+	WorkerID    *int       `json:"worker_id,omitempty"`
+	Attempts    int        `json:"attempts"`
+	LastError   *string    `json:"last_error,omitempty"`
+	Provider    *string    `json:"provider,omitempty"`
+	QueuedAt    time.Time  `json:"queued_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func ToJobView(j *payment.PaymentJob) *PaymentJobView {
+	return &PaymentJobView{
+		ExternalID:  j.ExternalID,
+		Status:      j.Status,
+		WorkerID:    j.WorkerID,
+		Attempts:    j.Attempts,
+		LastError:   j.LastError,
+		Provider:    j.Provider,
+		QueuedAt:    j.QueuedAt,
+		StartedAt:   j.StartedAt,
+		CompletedAt: j.CompletedAt,
+		FailedAt:    j.FailedAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+}
+
+// JobService persists gateway job transitions and implements
+// paymentgateway.JobRecorder, paymentgateway.ProviderRecorder,
+// paymentgateway.DeadLetterRecorder, paymentgateway.CancellationChecker and
+// paymentgateway.SpillStore. Recording failures are logged and never
+// interrupt payment processing.
+type JobService struct {
+	logger     *slog.Logger
+	repository JobRepositoryAPI
+}
+
+func NewJobService(logger *slog.Logger, repository JobRepositoryAPI) *JobService {
+	return &JobService{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+func (s *JobService) JobQueued(externalID string) {
+	if err := s.repository.MarkQueued(externalID, time.Now()); err != nil {
+		s.logger.Error("failed to record queued payment job", "error", err, "external_id", externalID)
+	}
+}
+
+func (s *JobService) JobStarted(externalID string, workerID int) {
+	if err := s.repository.MarkProcessing(externalID, workerID, time.Now()); err != nil {
+		s.logger.Error("failed to record processing payment job", "error", err, "external_id", externalID, "worker_id", workerID)
+	}
+}
+
+func (s *JobService) JobCompleted(externalID string) {
+	if err := s.repository.MarkCompleted(externalID, time.Now()); err != nil {
+		s.logger.Error("failed to record completed payment job", "error", err, "external_id", externalID)
+	}
+}
+
+func (s *JobService) JobFailed(externalID string, reason string) {
+	if err := s.repository.MarkFailed(externalID, reason, time.Now()); err != nil {
+		s.logger.Error("failed to record failed payment job", "error", err, "external_id", externalID)
+	}
+}
+
+func (s *JobService) JobDeadLettered(externalID string, attempts int, reason string) {
+	if err := s.repository.MarkDeadLettered(externalID, reason, time.Now()); err != nil {
+		s.logger.Error("failed to record dead-lettered payment job", "error", err, "external_id", externalID, "attempts", attempts)
+	}
+}
+
+func (s *JobService) JobRouted(externalID, provider string) {
+	if err := s.repository.MarkRouted(externalID, provider, time.Now()); err != nil {
+		s.logger.Error("failed to record payment provider", "error", err, "external_id", externalID, "provider", provider)
+	}
+}
+
+// JobCancelled reports whether an operator cancelled the job while it was
+// queued. A job whose status cannot be read is run.
+func (s *JobService) JobCancelled(externalID string) bool {
+	job, err := s.repository.GetByExternalID(externalID)
+	if err != nil {
+		if err != ErrPaymentJobNotFound {
+			s.logger.Error("failed to check payment job cancellation", "error", err, "external_id", externalID)
+		}
+		return false
+	}
+	return job.Status == JobStatusCancelled
+}
+
+func (s *JobService) Spill(job paymentgateway.PaymentJob) error {
+	return s.repository.MarkSpilled(job.ExternalID, job.Amount, job.PaymentID, time.Now())
+}
+
+func (s *JobService) ClaimSpilled(limit int) ([]paymentgateway.PaymentJob, error) {
+	rows, err := s.repository.ClaimSpilled(limit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]paymentgateway.PaymentJob, 0, len(rows))
+	for _, row := range rows {
+		job := paymentgateway.PaymentJob{ExternalID: row.ExternalID}
+		if row.AmountIDR != nil {
+			job.Amount = *row.AmountIDR
+		}
+		if row.GatewayID != nil {
+			job.PaymentID = *row.GatewayID
+		}
+		if row.Provider != nil {
+			job.Provider = *row.Provider
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *JobService) GetJob(externalID string) (*payment.PaymentJob, error) {
+	return s.repository.GetByExternalID(externalID)
+}
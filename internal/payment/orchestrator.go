@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+
+	"github.com/frahmantamala/expense-management/internal/core/money"
 )
 
 type PaymentOrchestrator struct {
@@ -44,7 +46,7 @@ func (p *PaymentOrchestrator) ProcessPayment(expenseID int64, amount int64) (ext
 	}
 
 	paymentReq := &PaymentRequest{
-		Amount:     amount,
+		Amount:     money.Rupiah(amount),
 		ExternalID: externalID,
 	}
 
@@ -101,7 +103,7 @@ func (p *PaymentOrchestrator) RetryPayment(expenseID int64, externalID string) e
 	}
 
 	paymentReq := &PaymentRequest{
-		Amount:     paymentRecord.AmountIDR,
+		Amount:     paymentRecord.Amount(),
 		ExternalID: externalID,
 	}
 
@@ -137,5 +139,13 @@ func (p *PaymentOrchestrator) GetPaymentStatus(expenseID int64) (interface{}, er
 		return nil, fmt.Errorf("no payment record found for expense %d", expenseID)
 	}
 
-	return ToView(paymentRecord), nil
+	view := ToView(paymentRecord)
+	if paymentRecord.SettledAmount > 0 {
+		installments, err := p.paymentService.ListInstallments(paymentRecord.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get payment installments: %w", err)
+		}
+		view.Installments = ToInstallmentViews(installments)
+	}
+	return view, nil
 }
@@ -3,6 +3,7 @@ package payment
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ var (
 	ErrExternalIDAlreadyExists = errors.New("external_id already exists")
 	ErrPaymentNotFound         = errors.New("payment not found")
 	ErrInvalidPaymentStatus    = errors.New("invalid payment status")
+	ErrCallbackMismatch        = errors.New("callback does not match payment record")
 )
 
 type ServiceAPI interface {
@@ -23,36 +25,56 @@ type ServiceAPI interface {
 	GetPaymentByExpenseID(expenseID int64) (*payment.Payment, error)
 	GetPaymentByExternalID(externalID string) (*payment.Payment, error)
 	UpdatePaymentStatus(paymentID int64, status string, paymentMethod *string, gatewayResponse json.RawMessage, failureReason *string) error
+	RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error)
+	ListInstallments(paymentID int64) ([]*payment.Installment, error)
+	// RecordFailure classifies a failed payment and returns when it will be
+	// retried automatically, or nil when it will not.
+	RecordFailure(p *payment.Payment, failureClass string) (*time.Time, error)
 }
 
 type PaymentView struct {
-	ID              int64           `json:"id"`
-	ExpenseID       int64           `json:"expense_id"`
-	ExternalID      string          `json:"external_id"`
-	AmountIDR       int64           `json:"amount_idr"`
-	Status          string          `json:"status"`
-	PaymentMethod   *string         `json:"payment_method,omitempty"`
-	GatewayResponse json.RawMessage `json:"gateway_response,omitempty"`
-	FailureReason   *string         `json:"failure_reason,omitempty"`
-	RetryCount      int             `json:"retry_count"`
-	ProcessedAt     *time.Time      `json:"processed_at,omitempty"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at"`
+	ID              int64              `json:"id"`
+	ExpenseID       int64              `json:"expense_id"`
+	ExternalID      string             `json:"external_id"`
+	AmountIDR       int64              `json:"amount_idr"`
+	SettledAmount   int64              `json:"settled_amount_idr"`
+	Status          string             `json:"status"`
+	PaymentMethod   *string            `json:"payment_method,omitempty"`
+	Provider        *string            `json:"provider,omitempty"`
+	GatewayResponse json.RawMessage    `json:"gateway_response,omitempty"`
+	FailureReason   *string            `json:"failure_reason,omitempty"`
+	FailureClass    *string            `json:"failure_class,omitempty"`
+	RetryCount      int                `json:"retry_count"`
+	NextRetryAt     *time.Time         `json:"next_retry_at,omitempty"`
+	ProcessedAt     *time.Time         `json:"processed_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	Installments    []*InstallmentView `json:"installments,omitempty"`
+}
+
+type InstallmentView struct {
+	GatewayPaymentID string    `json:"gateway_payment_id"`
+	AmountIDR        int64     `json:"amount_idr"`
+	SettledAt        time.Time `json:"settled_at"`
 }
 
 type PaymentSummaryView struct {
-	ID         int64     `json:"id"`
-	ExternalID string    `json:"external_id"`
-	AmountIDR  int64     `json:"amount_idr"`
-	Status     string    `json:"status"`
-	RetryCount int       `json:"retry_count"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID            int64     `json:"id"`
+	ExternalID    string    `json:"external_id"`
+	AmountIDR     int64     `json:"amount_idr"`
+	SettledAmount int64     `json:"settled_amount_idr"`
+	Status        string    `json:"status"`
+	RetryCount    int       `json:"retry_count"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 const (
 	StatusPending = "pending"
-	StatusSuccess = "success"
-	StatusFailed  = "failed"
+	// StatusPartiallySettled payments have received some, but not all, of
+	// their installments.
+	StatusPartiallySettled = "partially_settled"
+	StatusSuccess          = "success"
+	StatusFailed           = "failed"
 )
 
 func NewPayment(expenseID int64, externalID string, amountIDR int64) *payment.Payment {
@@ -91,8 +113,12 @@ func IncrementRetryCount(p *payment.Payment) {
 	p.UpdatedAt = time.Now()
 }
 
+// MaxRetries is how many times a payment may be retried, automatically or
+// not.
+const MaxRetries = 3
+
 func CanRetry(p *payment.Payment) bool {
-	return p.Status == StatusFailed && p.RetryCount < 3
+	return p.Status == StatusFailed && p.RetryCount < MaxRetries
 }
 
 func IsCompleted(p *payment.Payment) bool {
@@ -107,6 +133,8 @@ func MapExternalStatus(externalStatus string) string {
 	switch strings.ToLower(externalStatus) {
 	case "success", "completed", "paid":
 		return StatusSuccess
+	case "partial", "partially_paid", "partially_settled":
+		return StatusPartiallySettled
 	case "failed", "cancelled", "declined":
 		return StatusFailed
 	default:
@@ -114,17 +142,66 @@ func MapExternalStatus(externalStatus string) string {
 	}
 }
 
+// ValidateCallback checks that a gateway callback refers to the stored payment
+// and carries the exact amount that was requested.
+func ValidateCallback(p *payment.Payment, externalID string, amount int64) error {
+	if p.ExternalID != externalID {
+		return fmt.Errorf("%w: external_id %q does not match %q", ErrCallbackMismatch, externalID, p.ExternalID)
+	}
+	if p.AmountIDR != amount {
+		return fmt.Errorf("%w: amount %d does not match expected %d", ErrCallbackMismatch, amount, p.AmountIDR)
+	}
+	return nil
+}
+
+// ValidateInstallment checks a partial-settlement callback against the stored
+// payment: the installment must be positive and must not settle more than
+// is still outstanding.
+func ValidateInstallment(p *payment.Payment, externalID string, amount int64) error {
+	if p.ExternalID != externalID {
+		return fmt.Errorf("%w: external_id %q does not match %q", ErrCallbackMismatch, externalID, p.ExternalID)
+	}
+	if amount <= 0 {
+		return fmt.Errorf("%w: installment amount %d must be positive", ErrCallbackMismatch, amount)
+	}
+	if remaining := p.AmountIDR - p.SettledAmount; amount > remaining {
+		return fmt.Errorf("%w: installment amount %d exceeds outstanding %d", ErrCallbackMismatch, amount, remaining)
+	}
+	return nil
+}
+
+// IsFullySettled reports whether every installment of p has arrived.
+func IsFullySettled(p *payment.Payment) bool {
+	return p.SettledAmount >= p.AmountIDR
+}
+
+func ToInstallmentViews(installments []*payment.Installment) []*InstallmentView {
+	views := make([]*InstallmentView, len(installments))
+	for i, inst := range installments {
+		views[i] = &InstallmentView{
+			GatewayPaymentID: inst.GatewayPaymentID,
+			AmountIDR:        inst.AmountIDR,
+			SettledAt:        inst.SettledAt,
+		}
+	}
+	return views
+}
+
 func ToView(p *payment.Payment) *PaymentView {
 	return &PaymentView{
 		ID:              p.ID,
 		ExpenseID:       p.ExpenseID,
 		ExternalID:      p.ExternalID,
 		AmountIDR:       p.AmountIDR,
+		SettledAmount:   p.SettledAmount,
 		Status:          p.Status,
 		PaymentMethod:   p.PaymentMethod,
+		Provider:        p.Provider,
 		GatewayResponse: p.GatewayResponse,
 		FailureReason:   p.FailureReason,
+		FailureClass:    p.FailureClass,
 		RetryCount:      p.RetryCount,
+		NextRetryAt:     p.NextRetryAt,
 		ProcessedAt:     p.ProcessedAt,
 		CreatedAt:       p.CreatedAt,
 		UpdatedAt:       p.UpdatedAt,
@@ -133,11 +210,12 @@ func ToView(p *payment.Payment) *PaymentView {
 
 func ToSummaryView(p *payment.Payment) *PaymentSummaryView {
 	return &PaymentSummaryView{
-		ID:         p.ID,
-		ExternalID: p.ExternalID,
-		AmountIDR:  p.AmountIDR,
-		Status:     p.Status,
-		RetryCount: p.RetryCount,
-		CreatedAt:  p.CreatedAt,
+		ID:            p.ID,
+		ExternalID:    p.ExternalID,
+		AmountIDR:     p.AmountIDR,
+		SettledAmount: p.SettledAmount,
+		Status:        p.Status,
+		RetryCount:    p.RetryCount,
+		CreatedAt:     p.CreatedAt,
 	}
 }
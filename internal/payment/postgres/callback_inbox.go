@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CallbackInboxRepository struct {
+	db *gorm.DB
+}
+
+func NewCallbackInboxRepository(db *gorm.DB) paymentpkg.InboxRepositoryAPI {
+	return &CallbackInboxRepository{
+		db: db,
+	}
+}
+
+func (r *CallbackInboxRepository) Create(entry *payment.CallbackInboxEntry) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedupe_key"}},
+		DoNothing: true,
+	}).Create(entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ClaimDue takes the oldest due entries. As with ClaimSpilled, the
+// conditional update lets concurrent workers skip entries already taken.
+func (r *CallbackInboxRepository) ClaimDue(limit int, now, leaseUntil time.Time) ([]*payment.CallbackInboxEntry, error) {
+	var candidates []*payment.CallbackInboxEntry
+	err := r.db.Where("status IN ? AND next_attempt_at <= ?", []string{paymentpkg.InboxStatusPending, paymentpkg.InboxStatusProcessing}, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]*payment.CallbackInboxEntry, 0, len(candidates))
+	for _, entry := range candidates {
+		result := r.db.Model(&payment.CallbackInboxEntry{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", entry.ID, entry.Status, entry.NextAttemptAt).
+			Updates(map[string]interface{}{
+				"status":          paymentpkg.InboxStatusProcessing,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": leaseUntil,
+				"updated_at":      now,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			entry.Status = paymentpkg.InboxStatusProcessing
+			entry.Attempts++
+			entry.NextAttemptAt = leaseUntil
+			claimed = append(claimed, entry)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *CallbackInboxRepository) MarkProcessed(id int64, processedAt time.Time) error {
+	return r.db.Model(&payment.CallbackInboxEntry{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       paymentpkg.InboxStatusProcessed,
+			"last_error":   nil,
+			"processed_at": processedAt,
+			"updated_at":   processedAt,
+		}).Error
+}
+
+func (r *CallbackInboxRepository) MarkRejected(id int64, reason string, at time.Time) error {
+	return r.finish(id, paymentpkg.InboxStatusRejected, reason, at)
+}
+
+func (r *CallbackInboxRepository) MarkFailed(id int64, reason string, at time.Time) error {
+	return r.finish(id, paymentpkg.InboxStatusFailed, reason, at)
+}
+
+func (r *CallbackInboxRepository) finish(id int64, status, reason string, at time.Time) error {
+	return r.db.Model(&payment.CallbackInboxEntry{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"last_error":   reason,
+			"processed_at": at,
+			"updated_at":   at,
+		}).Error
+}
+
+func (r *CallbackInboxRepository) ScheduleRetry(id int64, reason string, nextAttemptAt time.Time) error {
+	return r.db.Model(&payment.CallbackInboxEntry{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          paymentpkg.InboxStatusPending,
+			"last_error":      reason,
+			"next_attempt_at": nextAttemptAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *CallbackInboxRepository) GetByID(id int64) (*payment.CallbackInboxEntry, error) {
+	var entry payment.CallbackInboxEntry
+	err := r.db.First(&entry, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, paymentpkg.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *CallbackInboxRepository) LatestByExternalID(externalID string) (*payment.CallbackInboxEntry, error) {
+	var entry payment.CallbackInboxEntry
+	err := r.db.Where("external_id = ?", externalID).Order("received_at DESC, id DESC").First(&entry).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, paymentpkg.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *CallbackInboxRepository) ClaimReplay(id int64, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&payment.CallbackInboxEntry{}).
+		Where("id = ? AND status IN ?", id, []string{paymentpkg.InboxStatusProcessed, paymentpkg.InboxStatusRejected, paymentpkg.InboxStatusFailed}).
+		Updates(map[string]interface{}{
+			"status":          paymentpkg.InboxStatusProcessing,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+)
+
+var _ = ginkgo.Describe("CallbackInboxRepository", func() {
+	var (
+		db   *gorm.DB
+		repo paymentpkg.InboxRepositoryAPI
+		now  time.Time
+	)
+
+	newEntry := func(key string, due time.Time) *payment.CallbackInboxEntry {
+		return &payment.CallbackInboxEntry{
+			DedupeKey:     key,
+			ExternalID:    "ext-" + key,
+			Payload:       json.RawMessage(`{}`),
+			Status:        paymentpkg.InboxStatusPending,
+			NextAttemptAt: due,
+			ReceivedAt:    now,
+		}
+	}
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		db, err = testdb.Open(&payment.CallbackInboxEntry{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		repo = NewCallbackInboxRepository(db)
+		now = time.Date(2025, 10, 13, 9, 0, 0, 0, time.UTC)
+	})
+
+	ginkgo.It("should store a redelivered callback only once", func() {
+		stored, err := repo.Create(newEntry("a", now))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(stored).To(gomega.BeTrue())
+
+		stored, err = repo.Create(newEntry("a", now))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(stored).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should claim due entries once and reclaim them when the lease runs out", func() {
+		_, err := repo.Create(newEntry("due", now))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		_, err = repo.Create(newEntry("later", now.Add(time.Hour)))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		claimed, err := repo.ClaimDue(10, now, now.Add(time.Minute))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.HaveLen(1))
+		gomega.Expect(claimed[0].DedupeKey).To(gomega.Equal("due"))
+		gomega.Expect(claimed[0].Attempts).To(gomega.Equal(1))
+
+		again, err := repo.ClaimDue(10, now, now.Add(time.Minute))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(again).To(gomega.BeEmpty())
+
+		reclaimed, err := repo.ClaimDue(10, now.Add(2*time.Minute), now.Add(3*time.Minute))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(reclaimed).To(gomega.HaveLen(1))
+		gomega.Expect(reclaimed[0].Attempts).To(gomega.Equal(2))
+	})
+
+	ginkgo.It("should not claim finished entries", func() {
+		entry := newEntry("done", now)
+		_, err := repo.Create(entry)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.MarkProcessed(entry.ID, now)).To(gomega.Succeed())
+
+		claimed, err := repo.ClaimDue(10, now.Add(time.Hour), now.Add(2*time.Hour))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should claim a finished entry for replay once", func() {
+		pending := newEntry("pending", now)
+		_, err := repo.Create(pending)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		done := newEntry("done", now)
+		_, err = repo.Create(done)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.MarkProcessed(done.ID, now)).To(gomega.Succeed())
+
+		claimed, err := repo.ClaimReplay(pending.ID, now, now.Add(time.Minute))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.BeFalse())
+
+		claimed, err = repo.ClaimReplay(done.ID, now, now.Add(time.Minute))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.BeTrue())
+		claimed, err = repo.ClaimReplay(done.ID, now, now.Add(time.Minute))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.BeFalse())
+
+		stored, err := repo.GetByID(done.ID)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(stored.Status).To(gomega.Equal(paymentpkg.InboxStatusProcessing))
+		gomega.Expect(stored.Attempts).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("should find the last callback received for a payment", func() {
+		first := newEntry("first", now)
+		first.ExternalID = "ext-1"
+		_, err := repo.Create(first)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		second := newEntry("second", now)
+		second.ExternalID = "ext-1"
+		second.ReceivedAt = now.Add(time.Minute)
+		_, err = repo.Create(second)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		latest, err := repo.LatestByExternalID("ext-1")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(latest.ID).To(gomega.Equal(second.ID))
+
+		_, err = repo.GetByID(99)
+		gomega.Expect(err).To(gomega.MatchError(paymentpkg.ErrWebhookNotFound))
+	})
+})
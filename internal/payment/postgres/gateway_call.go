@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+	"gorm.io/gorm"
+)
+
+type GatewayCallRepository struct {
+	db *gorm.DB
+}
+
+func NewGatewayCallRepository(db *gorm.DB) paymentpkg.GatewayCallRepositoryAPI {
+	return &GatewayCallRepository{
+		db: db,
+	}
+}
+
+// Save links the call to the payment with its external ID, when there is
+// one yet.
+func (r *GatewayCallRepository) Save(call *payment.GatewayCall) error {
+	if call.PaymentID == nil && call.ExternalID != "" {
+		var ids []int64
+		if err := r.db.Model(&payment.Payment{}).Where("external_id = ?", call.ExternalID).Limit(1).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 1 {
+			call.PaymentID = &ids[0]
+		}
+	}
+	return r.db.Create(call).Error
+}
+
+// ListByPayment returns the newest calls first. Calls made before the
+// payment row existed are found by its external ID.
+func (r *GatewayCallRepository) ListByPayment(paymentID int64, externalID string, limit int) ([]*payment.GatewayCall, error) {
+	var calls []*payment.GatewayCall
+	err := r.db.Where("payment_id = ? OR external_id = ?", paymentID, externalID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&calls).Error
+	return calls, err
+}
+
+func (r *GatewayCallRepository) PurgeBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&payment.GatewayCall{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+)
+
+var _ = ginkgo.Describe("GatewayCallRepository", func() {
+	var (
+		db     *gorm.DB
+		repo   paymentpkg.GatewayCallRepositoryAPI
+		stored *payment.Payment
+	)
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		db, err = testdb.Open(&payment.Payment{}, &payment.GatewayCall{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		stored = &payment.Payment{ExpenseID: 5, ExternalID: "exp-5-1000", AmountIDR: 1000, TenantID: 1}
+		gomega.Expect(db.Create(stored).Error).ToNot(gomega.HaveOccurred())
+		repo = NewGatewayCallRepository(db)
+	})
+
+	ginkgo.It("links a call to the payment with its external ID", func() {
+		call := &payment.GatewayCall{ExternalID: stored.ExternalID, Method: "POST", URL: "https://gateway.example.com/payments"}
+		gomega.Expect(repo.Save(call)).To(gomega.Succeed())
+		gomega.Expect(call.PaymentID).ToNot(gomega.BeNil())
+		gomega.Expect(*call.PaymentID).To(gomega.Equal(stored.ID))
+
+		unknown := &payment.GatewayCall{ExternalID: "exp-6-1000", Method: "POST", URL: "https://gateway.example.com/payments"}
+		gomega.Expect(repo.Save(unknown)).To(gomega.Succeed())
+		gomega.Expect(unknown.PaymentID).To(gomega.BeNil())
+	})
+
+	ginkgo.It("lists a payment's calls newest first, including ones made before it was stored", func() {
+		early := &payment.GatewayCall{ExternalID: "exp-5-1000", Method: "POST", URL: "https://gateway.example.com/payments", CreatedAt: time.Now().Add(-time.Minute)}
+		gomega.Expect(db.Create(early).Error).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.Save(&payment.GatewayCall{ExternalID: stored.ExternalID, Method: "GET", URL: "https://gateway.example.com/payments?external_id=exp-5-1000"})).To(gomega.Succeed())
+		gomega.Expect(repo.Save(&payment.GatewayCall{ExternalID: "exp-6-1000", Method: "POST", URL: "https://gateway.example.com/payments"})).To(gomega.Succeed())
+
+		calls, err := repo.ListByPayment(stored.ID, stored.ExternalID, 10)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(calls).To(gomega.HaveLen(2))
+		gomega.Expect(calls[0].Method).To(gomega.Equal("GET"))
+		gomega.Expect(calls[1].ID).To(gomega.Equal(early.ID))
+
+		calls, err = repo.ListByPayment(stored.ID, stored.ExternalID, 1)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(calls).To(gomega.HaveLen(1))
+	})
+
+	ginkgo.It("purges calls made before the cutoff", func() {
+		old := &payment.GatewayCall{ExternalID: stored.ExternalID, Method: "POST", URL: "https://gateway.example.com/payments", CreatedAt: time.Now().Add(-48 * time.Hour)}
+		gomega.Expect(db.Create(old).Error).ToNot(gomega.HaveOccurred())
+		gomega.Expect(repo.Save(&payment.GatewayCall{ExternalID: stored.ExternalID, Method: "GET", URL: "https://gateway.example.com/payments?external_id=exp-5-1000"})).To(gomega.Succeed())
+
+		purged, err := repo.PurgeBefore(time.Now().Add(-24 * time.Hour))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(purged).To(gomega.Equal(int64(1)))
+
+		var remaining int64
+		gomega.Expect(db.Model(&payment.GatewayCall{}).Count(&remaining).Error).ToNot(gomega.HaveOccurred())
+		gomega.Expect(remaining).To(gomega.Equal(int64(1)))
+	})
+})
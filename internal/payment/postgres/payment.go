@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/encryption"
 	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -19,7 +21,13 @@ func NewPaymentRepository(db *gorm.DB) paymentpkg.RepositoryAPI {
 	}
 }
 
+// Create files the payment under its expense's tenant.
 func (r *PaymentRepository) Create(p *payment.Payment) error {
+	if p.TenantID == 0 {
+		if err := r.db.Table("expenses").Select("tenant_id").Where("id = ?", p.ExpenseID).Scan(&p.TenantID).Error; err != nil {
+			return err
+		}
+	}
 	return r.db.Create(p).Error
 }
 
@@ -27,6 +35,9 @@ func (r *PaymentRepository) GetByID(id int64) (*payment.Payment, error) {
 	var p payment.Payment
 	err := r.db.First(&p, id).Error
 	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, paymentpkg.ErrPaymentNotFound
+		}
 		return nil, err
 	}
 	return &p, nil
@@ -67,16 +78,132 @@ func (r *PaymentRepository) UpdateStatus(id int64, status string, paymentMethod
 	}
 
 	if gatewayResponse != nil {
-		updates["gateway_response"] = gatewayResponse
+		sealed, err := encryption.SealJSON(gatewayResponse)
+		if err != nil {
+			return err
+		}
+		updates["gateway_response"] = sealed
 	}
 
 	if failureReason != nil {
 		updates["failure_reason"] = *failureReason
 	}
 
-	return r.db.Model(&payment.Payment{}).Where("id = ?", id).Updates(updates).Error
+	if status == paymentpkg.StatusSuccess {
+		updates["settled_amount"] = gorm.Expr("amount_idr")
+	}
+	if status != paymentpkg.StatusFailed {
+		updates["next_retry_at"] = nil
+	}
+
+	// The status guard is part of the update so a callback racing another
+	// cannot move the payment backwards.
+	result := r.db.Model(&payment.Payment{}).
+		Where("id = ? AND status IN ?", id, paymentpkg.StatusesBefore(status)).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var exists int64
+		if err := r.db.Model(&payment.Payment{}).Where("id = ?", id).Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return paymentpkg.ErrInvalidPaymentStatus
+		}
+	}
+	return nil
+}
+
+func (r *PaymentRepository) RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error) {
+	var (
+		updated  payment.Payment
+		recorded bool
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		installment.PaymentID = paymentID
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "payment_id"}, {Name: "gateway_payment_id"}},
+			DoNothing: true,
+		}).Create(installment)
+		if result.Error != nil {
+			return result.Error
+		}
+		recorded = result.RowsAffected == 1
+
+		if recorded {
+			now := time.Now()
+			updates := map[string]interface{}{
+				"settled_amount": gorm.Expr("settled_amount + ?", installment.AmountIDR),
+				"status": gorm.Expr("CASE WHEN settled_amount + ? >= amount_idr THEN ? ELSE ? END",
+					installment.AmountIDR, paymentpkg.StatusSuccess, paymentpkg.StatusPartiallySettled),
+				"processed_at": now,
+				"updated_at":   now,
+			}
+			if gatewayResponse != nil {
+				sealed, err := encryption.SealJSON(gatewayResponse)
+				if err != nil {
+					return err
+				}
+				updates["gateway_response"] = sealed
+			}
+			if err := tx.Model(&payment.Payment{}).Where("id = ?", paymentID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.First(&updated, paymentID).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &updated, recorded, nil
+}
+
+func (r *PaymentRepository) ListInstallments(paymentID int64) ([]*payment.Installment, error) {
+	var installments []*payment.Installment
+	err := r.db.Where("payment_id = ?", paymentID).Order("settled_at ASC, id ASC").Find(&installments).Error
+	return installments, err
 }
 
 func (r *PaymentRepository) IncrementRetryCount(id int64) error {
 	return r.db.Model(&payment.Payment{}).Where("id = ?", id).UpdateColumn("retry_count", gorm.Expr("retry_count + 1")).Error
 }
+
+func (r *PaymentRepository) ListPendingCreatedBefore(before time.Time, limit int) ([]*payment.Payment, error) {
+	var payments []*payment.Payment
+	query := r.db.Where("status = ? AND created_at < ?", paymentpkg.StatusPending, before).Order("created_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&payments).Error
+	return payments, err
+}
+
+func (r *PaymentRepository) RecordFailure(id int64, failureClass string, nextRetryAt *time.Time) error {
+	return r.db.Model(&payment.Payment{}).
+		Where("id = ? AND status = ?", id, paymentpkg.StatusFailed).
+		Updates(map[string]interface{}{
+			"failure_class": failureClass,
+			"next_retry_at": nextRetryAt,
+		}).Error
+}
+
+func (r *PaymentRepository) ListRetryDue(now time.Time, limit int) ([]*payment.Payment, error) {
+	var payments []*payment.Payment
+	query := r.db.Where("status = ? AND next_retry_at <= ?", paymentpkg.StatusFailed, now).Order("next_retry_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&payments).Error
+	return payments, err
+}
+
+func (r *PaymentRepository) ClaimRetry(id int64) (bool, error) {
+	result := r.db.Model(&payment.Payment{}).
+		Where("id = ? AND status = ? AND next_retry_at IS NOT NULL", id, paymentpkg.StatusFailed).
+		Update("next_retry_at", nil)
+	return result.RowsAffected == 1, result.Error
+}
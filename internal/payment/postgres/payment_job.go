@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentJobRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentJobRepository(db *gorm.DB) paymentpkg.JobRepositoryAPI {
+	return &PaymentJobRepository{
+		db: db,
+	}
+}
+
+// MarkQueued creates the job row or resets it when a payment is re-queued
+// after a retry, bumping the attempt counter.
+func (r *PaymentJobRepository) MarkQueued(externalID string, queuedAt time.Time) error {
+	job := &payment.PaymentJob{
+		ExternalID: externalID,
+		Status:     paymentpkg.JobStatusQueued,
+		Attempts:   1,
+		QueuedAt:   queuedAt,
+		CreatedAt:  queuedAt,
+		UpdatedAt:  queuedAt,
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "external_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":       paymentpkg.JobStatusQueued,
+			"attempts":     gorm.Expr("payment_jobs.attempts + 1"),
+			"worker_id":    nil,
+			"last_error":   nil,
+			"queued_at":    queuedAt,
+			"started_at":   nil,
+			"completed_at": nil,
+			"failed_at":    nil,
+			"updated_at":   queuedAt,
+		}),
+	}).Create(job).Error
+}
+
+func (r *PaymentJobRepository) MarkProcessing(externalID string, workerID int, startedAt time.Time) error {
+	return r.db.Model(&payment.PaymentJob{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+		"status":     paymentpkg.JobStatusProcessing,
+		"worker_id":  workerID,
+		"started_at": startedAt,
+		"updated_at": startedAt,
+	}).Error
+}
+
+func (r *PaymentJobRepository) MarkCompleted(externalID string, completedAt time.Time) error {
+	return r.db.Model(&payment.PaymentJob{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+		"status":       paymentpkg.JobStatusCompleted,
+		"completed_at": completedAt,
+		"updated_at":   completedAt,
+	}).Error
+}
+
+func (r *PaymentJobRepository) MarkFailed(externalID string, reason string, failedAt time.Time) error {
+	return r.db.Model(&payment.PaymentJob{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+		"status":     paymentpkg.JobStatusFailed,
+		"last_error": reason,
+		"failed_at":  failedAt,
+		"updated_at": failedAt,
+	}).Error
+}
+
+func (r *PaymentJobRepository) MarkDeadLettered(externalID string, reason string, deadLetteredAt time.Time) error {
+	return r.db.Model(&payment.PaymentJob{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+		"status":     paymentpkg.JobStatusDeadLettered,
+		"last_error": reason,
+		"failed_at":  deadLetteredAt,
+		"updated_at": deadLetteredAt,
+	}).Error
+}
+
+func (r *PaymentJobRepository) MarkCancelled(externalID, from, reason string, cancelledAt time.Time) (bool, error) {
+	result := r.db.Model(&payment.PaymentJob{}).
+		Where("external_id = ? AND status = ?", externalID, from).
+		Updates(map[string]interface{}{
+			"status":     paymentpkg.JobStatusCancelled,
+			"last_error": reason,
+			"failed_at":  cancelledAt,
+			"updated_at": cancelledAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *PaymentJobRepository) MarkSpilled(externalID string, amountIDR int64, gatewayPaymentID string, spilledAt time.Time) error {
+	return r.db.Model(&payment.PaymentJob{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+		"status":             paymentpkg.JobStatusSpilled,
+		"amount_idr":         amountIDR,
+		"gateway_payment_id": gatewayPaymentID,
+		"updated_at":         spilledAt,
+	}).Error
+}
+
+func (r *PaymentJobRepository) MarkRouted(externalID, provider string, routedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&payment.PaymentJob{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+			"provider":   provider,
+			"updated_at": routedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&payment.Payment{}).Where("external_id = ?", externalID).Updates(map[string]interface{}{
+			"provider":   provider,
+			"updated_at": routedAt,
+		}).Error
+	})
+}
+
+// ClaimSpilled moves up to limit spilled jobs back to queued, oldest first.
+// The conditional update makes concurrent claimers skip rows already taken.
+func (r *PaymentJobRepository) ClaimSpilled(limit int, claimedAt time.Time) ([]*payment.PaymentJob, error) {
+	var candidates []*payment.PaymentJob
+	err := r.db.Where("status = ?", paymentpkg.JobStatusSpilled).
+		Order("queued_at ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]*payment.PaymentJob, 0, len(candidates))
+	for _, job := range candidates {
+		result := r.db.Model(&payment.PaymentJob{}).
+			Where("id = ? AND status = ?", job.ID, paymentpkg.JobStatusSpilled).
+			Updates(map[string]interface{}{
+				"status":     paymentpkg.JobStatusQueued,
+				"updated_at": claimedAt,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.Status = paymentpkg.JobStatusQueued
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *PaymentJobRepository) GetByExternalID(externalID string) (*payment.PaymentJob, error) {
+	var job payment.PaymentJob
+	err := r.db.Where("external_id = ?", externalID).First(&job).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, paymentpkg.ErrPaymentJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
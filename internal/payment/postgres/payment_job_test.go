@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+)
+
+var _ = ginkgo.Describe("PaymentJobRepository", func() {
+	var (
+		db   *gorm.DB
+		repo paymentpkg.JobRepositoryAPI
+	)
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		db, err = testdb.Open(&payment.PaymentJob{}, &payment.Payment{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		repo = NewPaymentJobRepository(db)
+	})
+
+	ginkgo.It("should record queued, processing and completed transitions", func() {
+		now := time.Now()
+
+		gomega.Expect(repo.MarkQueued("ext-1", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkProcessing("ext-1", 4, now.Add(time.Second))).To(gomega.Succeed())
+		gomega.Expect(repo.MarkCompleted("ext-1", now.Add(2*time.Second))).To(gomega.Succeed())
+
+		job, err := repo.GetByExternalID("ext-1")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(job.Status).To(gomega.Equal(paymentpkg.JobStatusCompleted))
+		gomega.Expect(job.WorkerID).ToNot(gomega.BeNil())
+		gomega.Expect(*job.WorkerID).To(gomega.Equal(4))
+		gomega.Expect(job.StartedAt).ToNot(gomega.BeNil())
+		gomega.Expect(job.CompletedAt).ToNot(gomega.BeNil())
+		gomega.Expect(job.Attempts).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("should reset the job and bump attempts when re-queued", func() {
+		now := time.Now()
+
+		gomega.Expect(repo.MarkQueued("ext-2", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkFailed("ext-2", "webhook callback failed", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkQueued("ext-2", now.Add(time.Minute))).To(gomega.Succeed())
+
+		job, err := repo.GetByExternalID("ext-2")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(job.Status).To(gomega.Equal(paymentpkg.JobStatusQueued))
+		gomega.Expect(job.Attempts).To(gomega.Equal(2))
+		gomega.Expect(job.LastError).To(gomega.BeNil())
+		gomega.Expect(job.FailedAt).To(gomega.BeNil())
+	})
+
+	ginkgo.It("should set aside a dead-lettered job with its reason", func() {
+		now := time.Now()
+
+		gomega.Expect(repo.MarkQueued("ext-3", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkProcessing("ext-3", 1, now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkDeadLettered("ext-3", "payment job panicked: boom", now.Add(time.Second))).To(gomega.Succeed())
+
+		job, err := repo.GetByExternalID("ext-3")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(job.Status).To(gomega.Equal(paymentpkg.JobStatusDeadLettered))
+		gomega.Expect(job.LastError).ToNot(gomega.BeNil())
+		gomega.Expect(*job.LastError).To(gomega.Equal("payment job panicked: boom"))
+		gomega.Expect(job.FailedAt).ToNot(gomega.BeNil())
+	})
+
+	ginkgo.It("should cancel a job only while it is in the expected status", func() {
+		now := time.Now()
+
+		gomega.Expect(repo.MarkQueued("ext-4", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkProcessing("ext-4", 2, now)).To(gomega.Succeed())
+
+		cancelled, err := repo.MarkCancelled("ext-4", paymentpkg.JobStatusQueued, "cancelled by operator: duplicate", now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(cancelled).To(gomega.BeFalse())
+
+		cancelled, err = repo.MarkCancelled("ext-4", paymentpkg.JobStatusProcessing, "cancelled by operator: duplicate", now.Add(time.Second))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(cancelled).To(gomega.BeTrue())
+
+		job, err := repo.GetByExternalID("ext-4")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(job.Status).To(gomega.Equal(paymentpkg.JobStatusCancelled))
+		gomega.Expect(*job.LastError).To(gomega.Equal("cancelled by operator: duplicate"))
+		gomega.Expect(job.FailedAt).ToNot(gomega.BeNil())
+	})
+
+	ginkgo.It("should claim spilled jobs oldest first and only once", func() {
+		now := time.Now()
+
+		gomega.Expect(repo.MarkQueued("ext-a", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkSpilled("ext-a", 50000, "pay-a", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkQueued("ext-b", now.Add(time.Second))).To(gomega.Succeed())
+		gomega.Expect(repo.MarkSpilled("ext-b", 75000, "pay-b", now)).To(gomega.Succeed())
+
+		claimed, err := repo.ClaimSpilled(1, now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.HaveLen(1))
+		gomega.Expect(claimed[0].ExternalID).To(gomega.Equal("ext-a"))
+		gomega.Expect(*claimed[0].AmountIDR).To(gomega.Equal(int64(50000)))
+		gomega.Expect(*claimed[0].GatewayID).To(gomega.Equal("pay-a"))
+
+		claimed, err = repo.ClaimSpilled(10, now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.HaveLen(1))
+		gomega.Expect(claimed[0].ExternalID).To(gomega.Equal("ext-b"))
+
+		claimed, err = repo.ClaimSpilled(10, now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should record the provider on the job and its payment", func() {
+		now := time.Now()
+
+		gomega.Expect(db.Create(&payment.Payment{ExpenseID: 1, ExternalID: "ext-r", AmountIDR: 50000}).Error).To(gomega.Succeed())
+		gomega.Expect(repo.MarkQueued("ext-r", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkRouted("ext-r", "backup", now)).To(gomega.Succeed())
+		gomega.Expect(repo.MarkSpilled("ext-r", 50000, "pay-r", now)).To(gomega.Succeed())
+
+		claimed, err := repo.ClaimSpilled(1, now)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(claimed).To(gomega.HaveLen(1))
+		gomega.Expect(*claimed[0].Provider).To(gomega.Equal("backup"))
+
+		var stored payment.Payment
+		gomega.Expect(db.Where("external_id = ?", "ext-r").First(&stored).Error).To(gomega.Succeed())
+		gomega.Expect(*stored.Provider).To(gomega.Equal("backup"))
+	})
+
+	ginkgo.It("should return ErrPaymentJobNotFound for unknown jobs", func() {
+		job, err := repo.GetByExternalID("missing")
+		gomega.Expect(err).To(gomega.Equal(paymentpkg.ErrPaymentJobNotFound))
+		gomega.Expect(job).To(gomega.BeNil())
+	})
+})
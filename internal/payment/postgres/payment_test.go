@@ -7,10 +7,11 @@ import (
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
 	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
 	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
 )
 
@@ -19,37 +20,6 @@ func TestPaymentRepository(t *testing.T) {
 	ginkgo.RunSpecs(t, "Payment Repository Suite")
 }
 
-type PaymentSQLite struct {
-	ID              int64      `json:"id" gorm:"primaryKey"`
-	ExpenseID       int64      `json:"expense_id" gorm:"column:expense_id;not null"`
-	ExternalID      string     `json:"external_id" gorm:"column:external_id;not null;uniqueIndex"`
-	AmountIDR       int64      `json:"amount_idr" gorm:"column:amount_idr;not null"`
-	Status          string     `json:"status" gorm:"column:status;default:pending"`
-	PaymentMethod   *string    `json:"payment_method,omitempty" gorm:"column:payment_method"`
-	GatewayResponse string     `json:"gateway_response,omitempty" gorm:"column:gateway_response;type:text"`
-	FailureReason   *string    `json:"failure_reason,omitempty" gorm:"column:failure_reason"`
-	RetryCount      int        `json:"retry_count" gorm:"column:retry_count;default:0"`
-	ProcessedAt     *time.Time `json:"processed_at,omitempty" gorm:"column:processed_at"`
-	CreatedAt       time.Time  `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt       time.Time  `json:"updated_at" gorm:"column:updated_at"`
-}
-
-func (PaymentSQLite) TableName() string {
-	return "payments"
-}
-
-func (p *PaymentSQLite) BeforeCreate(tx *gorm.DB) error {
-	now := time.Now().UTC()
-	p.CreatedAt = now
-	p.UpdatedAt = now
-	return nil
-}
-
-func (p *PaymentSQLite) BeforeUpdate(tx *gorm.DB) error {
-	p.UpdatedAt = time.Now().UTC()
-	return nil
-}
-
 var _ = ginkgo.Describe("PaymentRepository", func() {
 	var (
 		db   *gorm.DB
@@ -59,14 +29,7 @@ var _ = ginkgo.Describe("PaymentRepository", func() {
 	ginkgo.BeforeEach(func() {
 
 		var err error
-		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-			NowFunc: func() time.Time {
-				return time.Now().UTC()
-			},
-		})
-		gomega.Expect(err).ToNot(gomega.HaveOccurred())
-
-		err = db.AutoMigrate(&PaymentSQLite{})
+		db, err = testdb.Open(&payment.Payment{}, &expenseDatamodel.Expense{}, &payment.Installment{})
 		gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 		repo = NewPaymentRepository(db)
@@ -90,6 +53,22 @@ var _ = ginkgo.Describe("PaymentRepository", func() {
 				gomega.Expect(testPayment.ID).To(gomega.BeNumerically(">", 0))
 
 			})
+
+			ginkgo.It("should file the payment under the expense's tenant", func() {
+				gomega.Expect(db.Create(&expenseDatamodel.Expense{ID: 321, TenantID: 7}).Error).ToNot(gomega.HaveOccurred())
+
+				testPayment := &payment.Payment{
+					ExpenseID:  321,
+					ExternalID: "ext-321",
+					AmountIDR:  50000,
+					Status:     paymentpkg.StatusPending,
+				}
+				gomega.Expect(repo.Create(testPayment)).To(gomega.Succeed())
+
+				stored, err := repo.GetByID(testPayment.ID)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(stored.TenantID).To(gomega.Equal(int64(7)))
+			})
 		})
 
 		ginkgo.Context("when creating payment with duplicate external ID", func() {
@@ -240,6 +219,7 @@ var _ = ginkgo.Describe("PaymentRepository", func() {
 				gomega.Expect(*updated.PaymentMethod).To(gomega.Equal("bank_transfer"))
 				gomega.Expect(*updated.FailureReason).To(gomega.Equal("Network timeout"))
 				gomega.Expect(updated.ProcessedAt).ToNot(gomega.BeNil())
+				gomega.Expect(updated.SettledAmount).To(gomega.Equal(int64(50000)))
 			})
 
 			ginkgo.It("should update status with nil optional fields", func() {
@@ -254,6 +234,19 @@ var _ = ginkgo.Describe("PaymentRepository", func() {
 			})
 		})
 
+		ginkgo.Context("when the status would move backwards", func() {
+			ginkgo.It("should refuse the update and leave the payment alone", func() {
+				gomega.Expect(repo.UpdateStatus(testPayment.ID, paymentpkg.StatusSuccess, nil, nil, nil)).To(gomega.Succeed())
+
+				err := repo.UpdateStatus(testPayment.ID, paymentpkg.StatusPending, nil, nil, nil)
+				gomega.Expect(err).To(gomega.MatchError(paymentpkg.ErrInvalidPaymentStatus))
+
+				updated, err := repo.GetByID(testPayment.ID)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(updated.Status).To(gomega.Equal(paymentpkg.StatusSuccess))
+			})
+		})
+
 		ginkgo.Context("when payment not found", func() {
 			ginkgo.It("should succeed but not affect any rows", func() {
 
@@ -264,6 +257,52 @@ var _ = ginkgo.Describe("PaymentRepository", func() {
 		})
 	})
 
+	ginkgo.Describe("RecordInstallment", func() {
+		var testPayment *payment.Payment
+
+		installment := func(gatewayID string, amount int64) *payment.Installment {
+			return &payment.Installment{GatewayPaymentID: gatewayID, AmountIDR: amount, SettledAt: time.Now()}
+		}
+
+		ginkgo.BeforeEach(func() {
+			testPayment = &payment.Payment{
+				ExpenseID:  123,
+				ExternalID: "ext-123",
+				AmountIDR:  50000,
+				Status:     paymentpkg.StatusPending,
+			}
+			gomega.Expect(repo.Create(testPayment)).To(gomega.Succeed())
+		})
+
+		ginkgo.It("should add installments up and mark the payment success once settled", func() {
+			updated, recorded, err := repo.RecordInstallment(testPayment.ID, installment("tr-1", 20000), nil)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(recorded).To(gomega.BeTrue())
+			gomega.Expect(updated.SettledAmount).To(gomega.Equal(int64(20000)))
+			gomega.Expect(updated.Status).To(gomega.Equal(paymentpkg.StatusPartiallySettled))
+
+			updated, _, err = repo.RecordInstallment(testPayment.ID, installment("tr-2", 30000), nil)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(updated.SettledAmount).To(gomega.Equal(int64(50000)))
+			gomega.Expect(updated.Status).To(gomega.Equal(paymentpkg.StatusSuccess))
+
+			installments, err := repo.ListInstallments(testPayment.ID)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(installments).To(gomega.HaveLen(2))
+		})
+
+		ginkgo.It("should ignore a redelivered installment", func() {
+			_, _, err := repo.RecordInstallment(testPayment.ID, installment("tr-1", 20000), nil)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			updated, recorded, err := repo.RecordInstallment(testPayment.ID, installment("tr-1", 20000), nil)
+
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(recorded).To(gomega.BeFalse())
+			gomega.Expect(updated.SettledAmount).To(gomega.Equal(int64(20000)))
+		})
+	})
+
 	ginkgo.Describe("IncrementRetryCount", func() {
 		var testPayment *payment.Payment
 
@@ -0,0 +1,185 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// StatusChecker looks up the gateway's view of a payment.
+type StatusChecker interface {
+	GetPaymentStatus(externalID string) (*paymentgatewaytypes.PaymentResponse, error)
+}
+
+type ReconcileOptions struct {
+	// CreatedBefore limits reconciliation to payments created before this
+	// instant so in-flight payments are left to their callbacks.
+	CreatedBefore time.Time
+	Limit         int
+	DryRun        bool
+	// Source is recorded with each reconciled status; empty means
+	// "backfill".
+	Source string
+}
+
+type ReconcileResult struct {
+	Checked      int `json:"checked"`
+	Succeeded    int `json:"succeeded"`
+	Failed       int `json:"failed"`
+	StillPending int `json:"still_pending"`
+	Errors       int `json:"errors"`
+}
+
+// Reconciler settles historical pending payments whose gateway callback never
+// arrived by asking the gateway for their current status.
+type Reconciler struct {
+	repository RepositoryAPI
+	gateway    StatusChecker
+	eventBus   *events.EventBus
+	logger     *slog.Logger
+}
+
+func NewReconciler(repository RepositoryAPI, gateway StatusChecker, eventBus *events.EventBus, logger *slog.Logger) *Reconciler {
+	return &Reconciler{
+		repository: repository,
+		gateway:    gateway,
+		eventBus:   eventBus,
+		logger:     logger,
+	}
+}
+
+func (r *Reconciler) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, r.logger)
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error) {
+	pending, err := r.repository.ListPendingCreatedBefore(opts.CreatedBefore, opts.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending payments: %w", err)
+	}
+
+	result := &ReconcileResult{}
+	for _, p := range pending {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		result.Checked++
+		status, err := r.reconcileOne(ctx, p, opts)
+		if err != nil {
+			result.Errors++
+			r.log(ctx).Error("failed to reconcile payment", "error", err, "payment_id", p.ID, "external_id", p.ExternalID)
+			continue
+		}
+
+		switch status {
+		case StatusSuccess:
+			result.Succeeded++
+		case StatusFailed:
+			result.Failed++
+		default:
+			result.StillPending++
+		}
+	}
+
+	r.log(ctx).Info("payment reconciliation finished",
+		"checked", result.Checked,
+		"succeeded", result.Succeeded,
+		"failed", result.Failed,
+		"still_pending", result.StillPending,
+		"errors", result.Errors,
+		"dry_run", opts.DryRun)
+
+	return result, nil
+}
+
+func (r *Reconciler) reconcileOne(ctx context.Context, p *payment.Payment, opts ReconcileOptions) (string, error) {
+	dryRun := opts.DryRun
+	resp, err := r.gateway.GetPaymentStatus(p.ExternalID)
+	if err != nil {
+		return "", fmt.Errorf("gateway status lookup failed: %w", err)
+	}
+
+	status := MapExternalStatus(string(resp.Data.Status))
+	// Installment details only arrive by callback, so partly settled
+	// payments are left for the callbacks to finish.
+	if status == StatusPending || status == StatusPartiallySettled || dryRun {
+		r.log(ctx).Info("payment reconciled",
+			"payment_id", p.ID,
+			"external_id", p.ExternalID,
+			"gateway_status", resp.Data.Status,
+			"new_status", status,
+			"dry_run", dryRun)
+		return status, nil
+	}
+
+	source := opts.Source
+	if source == "" {
+		source = "backfill"
+	}
+	gatewayResponse, _ := json.Marshal(map[string]interface{}{
+		"gateway_payment_id": resp.Data.ID,
+		"gateway_status":     resp.Data.Status,
+		"reconciled_at":      time.Now().UTC(),
+		"source":             source,
+		"response_version":   resp.Data.ResponseVersion,
+		"raw":                resp.Data.Raw,
+	})
+
+	var failureReason *string
+	if status == StatusFailed {
+		reason := "marked failed by gateway during reconciliation"
+		failureReason = &reason
+	}
+
+	if err := r.repository.UpdateStatus(p.ID, status, nil, gatewayResponse, failureReason); err != nil {
+		return "", fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	// Published synchronously so the expense is settled before a CLI run exits.
+	var event events.Event
+	if status == StatusSuccess {
+		event = events.NewPaymentCompletedEvent(fmt.Sprintf("%d", p.ID), p.ExpenseID, p.ExternalID, p.AmountIDR, status, resp.Data.ID)
+	} else {
+		event = events.NewPaymentFailedEvent(fmt.Sprintf("%d", p.ID), p.ExpenseID, p.ExternalID, p.AmountIDR, *failureReason, p.RetryCount)
+	}
+	if err := r.eventBus.PublishSync(ctx, event); err != nil {
+		return "", err
+	}
+
+	r.log(ctx).Info("payment reconciled",
+		"payment_id", p.ID,
+		"expense_id", p.ExpenseID,
+		"external_id", p.ExternalID,
+		"gateway_status", resp.Data.Status,
+		"new_status", status)
+
+	return status, nil
+}
+
+const ReconcileJobName = "payment_reconciliation"
+
+// ReconcileJob reconciles up to limit payments that have been pending for
+// longer than olderThan on every run of schedule.
+func ReconcileJob(r *Reconciler, schedule scheduler.Schedule, olderThan time.Duration, limit int) scheduler.Job {
+	return scheduler.Job{
+		Name:     ReconcileJobName,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			_, err := r.Reconcile(ctx, ReconcileOptions{
+				CreatedBefore: time.Now().Add(-olderThan),
+				Limit:         limit,
+				Source:        "scheduler",
+			})
+			return err
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+	paymentPkg "github.com/frahmantamala/expense-management/internal/payment"
+)
+
+type stubStatusChecker struct {
+	statuses map[string]paymentgatewaytypes.PaymentStatus
+}
+
+func (s *stubStatusChecker) GetPaymentStatus(externalID string) (*paymentgatewaytypes.PaymentResponse, error) {
+	status, ok := s.statuses[externalID]
+	if !ok {
+		return nil, errors.New("gateway unavailable")
+	}
+	return &paymentgatewaytypes.PaymentResponse{
+		Data: paymentgatewaytypes.PaymentData{ID: "gw-" + externalID, ExternalID: externalID, Status: status},
+	}, nil
+}
+
+var _ = Describe("Reconciler", func() {
+	var (
+		ctx        context.Context
+		repo       *mockPaymentRepository
+		gateway    *stubStatusChecker
+		eventBus   *events.EventBus
+		completed  []int64
+		reconciler *paymentPkg.Reconciler
+	)
+
+	seed := func(externalID string, expenseID int64, age time.Duration) *payment.Payment {
+		p := paymentPkg.NewPayment(expenseID, externalID, 50000)
+		Expect(repo.Create(p)).To(Succeed())
+		p.CreatedAt = time.Now().Add(-age)
+		return p
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = newMockPaymentRepository()
+		gateway = &stubStatusChecker{statuses: map[string]paymentgatewaytypes.PaymentStatus{}}
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		eventBus = events.NewEventBus(logger)
+
+		completed = nil
+		eventBus.Subscribe(events.EventTypePaymentCompleted, func(_ context.Context, e events.Event) error {
+			completed = append(completed, e.(*events.PaymentCompletedEvent).ExpenseID)
+			return nil
+		})
+
+		reconciler = paymentPkg.NewReconciler(repo, gateway, eventBus, logger)
+	})
+
+	It("settles stale pending payments and leaves recent ones alone", func() {
+		paid := seed("exp-1", 1, 48*time.Hour)
+		declined := seed("exp-2", 2, 48*time.Hour)
+		waiting := seed("exp-3", 3, 48*time.Hour)
+		recent := seed("exp-4", 4, time.Minute)
+
+		gateway.statuses["exp-1"] = paymentgatewaytypes.PaymentStatusSuccess
+		gateway.statuses["exp-2"] = paymentgatewaytypes.PaymentStatusFailed
+		gateway.statuses["exp-3"] = paymentgatewaytypes.PaymentStatusPending
+		gateway.statuses["exp-4"] = paymentgatewaytypes.PaymentStatusSuccess
+
+		result, err := reconciler.Reconcile(ctx, paymentPkg.ReconcileOptions{CreatedBefore: time.Now().Add(-time.Hour)})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*result).To(Equal(paymentPkg.ReconcileResult{Checked: 3, Succeeded: 1, Failed: 1, StillPending: 1}))
+		Expect(paid.Status).To(Equal(paymentPkg.StatusSuccess))
+		Expect(declined.Status).To(Equal(paymentPkg.StatusFailed))
+		Expect(declined.FailureReason).NotTo(BeNil())
+		Expect(waiting.Status).To(Equal(paymentPkg.StatusPending))
+		Expect(recent.Status).To(Equal(paymentPkg.StatusPending))
+		Expect(completed).To(ConsistOf(int64(1)))
+	})
+
+	It("does not write anything on a dry run", func() {
+		p := seed("exp-1", 1, 48*time.Hour)
+		gateway.statuses["exp-1"] = paymentgatewaytypes.PaymentStatusSuccess
+
+		result, err := reconciler.Reconcile(ctx, paymentPkg.ReconcileOptions{CreatedBefore: time.Now(), DryRun: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Succeeded).To(Equal(1))
+		Expect(p.Status).To(Equal(paymentPkg.StatusPending))
+		Expect(completed).To(BeEmpty())
+	})
+
+	It("counts gateway errors and keeps going", func() {
+		seed("exp-1", 1, 48*time.Hour)
+		seed("exp-2", 2, 48*time.Hour)
+		gateway.statuses["exp-2"] = paymentgatewaytypes.PaymentStatusSuccess
+
+		result, err := reconciler.Reconcile(ctx, paymentPkg.ReconcileOptions{CreatedBefore: time.Now()})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Errors).To(Equal(1))
+		Expect(result.Succeeded).To(Equal(1))
+	})
+
+	It("honours the limit", func() {
+		seed("exp-1", 1, 48*time.Hour)
+		seed("exp-2", 2, 48*time.Hour)
+		gateway.statuses["exp-1"] = paymentgatewaytypes.PaymentStatusPending
+
+		result, err := reconciler.Reconcile(ctx, paymentPkg.ReconcileOptions{CreatedBefore: time.Now(), Limit: 1})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Checked).To(Equal(1))
+	})
+
+	It("runs as a scheduled job over payments older than its threshold", func() {
+		stale := seed("exp-1", 1, 2*time.Hour)
+		recent := seed("exp-2", 2, time.Minute)
+		gateway.statuses["exp-1"] = paymentgatewaytypes.PaymentStatusSuccess
+		gateway.statuses["exp-2"] = paymentgatewaytypes.PaymentStatusSuccess
+
+		job := paymentPkg.ReconcileJob(reconciler, scheduler.Every(time.Hour), time.Hour, 0)
+		Expect(job.Name).To(Equal(paymentPkg.ReconcileJobName))
+		Expect(job.Run(ctx)).To(Succeed())
+
+		Expect(stale.Status).To(Equal(paymentPkg.StatusSuccess))
+		Expect(string(stale.GatewayResponse)).To(ContainSubstring(`"source":"scheduler"`))
+		Expect(recent.Status).To(Equal(paymentPkg.StatusPending))
+	})
+})
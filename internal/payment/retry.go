@@ -0,0 +1,107 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// Retrier retries payments whose transient failure's retry is due. A retry
+// that fails again is rescheduled by the payment service while retries
+// remain; the one that exhausts them fails the expense.
+type Retrier struct {
+	repository RepositoryAPI
+	payments   PaymentRequeuer
+	eventBus   *events.EventBus
+	logger     *slog.Logger
+}
+
+func NewRetrier(repository RepositoryAPI, payments PaymentRequeuer, eventBus *events.EventBus, logger *slog.Logger) *Retrier {
+	return &Retrier{
+		repository: repository,
+		payments:   payments,
+		eventBus:   eventBus,
+		logger:     logger,
+	}
+}
+
+func (r *Retrier) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, r.logger)
+}
+
+// RetryDue retries up to limit payments due by now and returns how many it
+// sent to the gateway again.
+func (r *Retrier) RetryDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	due, err := r.repository.ListRetryDue(now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list payments due for retry: %w", err)
+	}
+
+	retried := 0
+	for _, p := range due {
+		if err := ctx.Err(); err != nil {
+			return retried, err
+		}
+
+		claimed, err := r.repository.ClaimRetry(p.ID)
+		if err != nil {
+			r.log(ctx).Error("failed to claim payment retry", "error", err, "payment_id", p.ID)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		retried++
+		r.log(ctx).Info("retrying transiently failed payment",
+			"payment_id", p.ID,
+			"external_id", p.ExternalID,
+			"retry_count", p.RetryCount)
+		if _, err := r.payments.RetryPayment(&PaymentRequest{ExternalID: p.ExternalID, Amount: p.Amount()}); err != nil {
+			r.log(ctx).Warn("automatic payment retry failed", "error", err, "payment_id", p.ID)
+			r.failIfExhausted(ctx, p.ID)
+		}
+	}
+	return retried, nil
+}
+
+// failIfExhausted publishes the failure of a payment that failed again
+// without a further retry scheduled, so its expense stops waiting.
+func (r *Retrier) failIfExhausted(ctx context.Context, paymentID int64) {
+	p, err := r.repository.GetByID(paymentID)
+	if err != nil {
+		r.log(ctx).Error("failed to reload payment after retry", "error", err, "payment_id", paymentID)
+		return
+	}
+	if p.Status != StatusFailed || p.NextRetryAt != nil {
+		return
+	}
+
+	reason := ""
+	if p.FailureReason != nil {
+		reason = *p.FailureReason
+	}
+	event := events.NewPaymentFailedEvent(fmt.Sprintf("%d", p.ID), p.ExpenseID, p.ExternalID, p.AmountIDR, reason, p.RetryCount)
+	if err := r.eventBus.PublishSync(ctx, event); err != nil {
+		r.log(ctx).Error("failed to publish payment failed event", "error", err, "payment_id", p.ID)
+	}
+}
+
+const RetryJobName = "payment_retry"
+
+// RetryJob retries up to limit due payments on every run of schedule.
+func RetryJob(r *Retrier, schedule scheduler.Schedule, limit int) scheduler.Job {
+	return scheduler.Job{
+		Name:     RetryJobName,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			_, err := r.RetryDue(ctx, time.Now(), limit)
+			return err
+		},
+	}
+}
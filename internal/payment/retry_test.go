@@ -0,0 +1,144 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	paymentPkg "github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+type stubRequeuer struct {
+	retried []string
+	retry   func(externalID string) error
+}
+
+func (s *stubRequeuer) RetryPayment(req *paymentPkg.PaymentRequest) (*paymentPkg.PaymentResponse, error) {
+	s.retried = append(s.retried, req.ExternalID)
+	if s.retry != nil {
+		if err := s.retry(req.ExternalID); err != nil {
+			return nil, err
+		}
+	}
+	return &paymentPkg.PaymentResponse{Data: paymentPkg.PaymentData{ExternalID: req.ExternalID, Status: paymentPkg.StatusPending}}, nil
+}
+
+var _ = Describe("Automatic payment retries", func() {
+	var (
+		repo   *mockPaymentRepository
+		logger *slog.Logger
+	)
+
+	seedFailed := func(externalID string, expenseID int64, retryCount int) *payment.Payment {
+		p := paymentPkg.NewPayment(expenseID, externalID, 50000)
+		Expect(repo.Create(p)).To(Succeed())
+		p.Status = paymentPkg.StatusFailed
+		p.RetryCount = retryCount
+		return p
+	}
+
+	BeforeEach(func() {
+		repo = newMockPaymentRepository()
+		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	})
+
+	Describe("RecordFailure", func() {
+		var service *paymentPkg.PaymentService
+
+		BeforeEach(func() {
+			service = paymentPkg.NewPaymentService(logger, repo, nil, nil, nil, paymentPkg.RetryPolicy{MaxRetries: 3, Backoff: time.Minute})
+		})
+
+		It("schedules transient failures with a doubling backoff", func() {
+			p := seedFailed("exp-1", 1, 2)
+
+			retryAt, err := service.RecordFailure(p, paymentgateway.FailureTransient)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retryAt).NotTo(BeNil())
+			Expect(*retryAt).To(BeTemporally("~", time.Now().Add(4*time.Minute), 5*time.Second))
+			Expect(*p.FailureClass).To(Equal(paymentgateway.FailureTransient))
+			Expect(p.NextRetryAt).To(Equal(retryAt))
+		})
+
+		DescribeTable("does not retry",
+			func(failureClass string, retryCount int, want string) {
+				p := seedFailed("exp-1", 1, retryCount)
+
+				retryAt, err := service.RecordFailure(p, failureClass)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retryAt).To(BeNil())
+				Expect(*p.FailureClass).To(Equal(want))
+				Expect(p.NextRetryAt).To(BeNil())
+			},
+			Entry("permanent failures", paymentgateway.FailurePermanent, 0, paymentgateway.FailurePermanent),
+			Entry("failures of unknown class", "", 0, paymentgateway.FailurePermanent),
+			Entry("payments out of retries", paymentgateway.FailureTransient, 3, paymentgateway.FailureTransient),
+		)
+
+		It("retries nothing under the zero policy", func() {
+			service = paymentPkg.NewPaymentService(logger, repo, nil, nil, nil, paymentPkg.RetryPolicy{})
+			p := seedFailed("exp-1", 1, 0)
+
+			retryAt, err := service.RecordFailure(p, paymentgateway.FailureTransient)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retryAt).To(BeNil())
+		})
+	})
+
+	Describe("Retrier", func() {
+		var (
+			requeuer *stubRequeuer
+			failed   []int64
+			retrier  *paymentPkg.Retrier
+		)
+
+		BeforeEach(func() {
+			requeuer = &stubRequeuer{}
+			eventBus := events.NewEventBus(logger)
+			failed = nil
+			eventBus.Subscribe(events.EventTypePaymentFailed, func(_ context.Context, e events.Event) error {
+				failed = append(failed, e.(*events.PaymentFailedEvent).ExpenseID)
+				return nil
+			})
+			retrier = paymentPkg.NewRetrier(repo, requeuer, eventBus, logger)
+		})
+
+		It("retries due payments once and leaves the rest", func() {
+			past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+			seedFailed("exp-1", 1, 0).NextRetryAt = &past
+			seedFailed("exp-2", 2, 0).NextRetryAt = &future
+			seedFailed("exp-3", 3, 0)
+
+			retried, err := retrier.RetryDue(context.Background(), time.Now(), 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retried).To(Equal(1))
+			Expect(requeuer.retried).To(Equal([]string{"exp-1"}))
+
+			retried, err = retrier.RetryDue(context.Background(), time.Now(), 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retried).To(BeZero())
+			Expect(failed).To(BeEmpty())
+		})
+
+		It("fails the expense when the last retry fails", func() {
+			past := time.Now().Add(-time.Minute)
+			seedFailed("exp-1", 1, 2).NextRetryAt = &past
+			requeuer.retry = func(string) error { return errors.New("payment processing failed") }
+
+			_, err := retrier.RetryDue(context.Background(), time.Now(), 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failed).To(Equal([]int64{1}))
+		})
+	})
+})
@@ -0,0 +1,241 @@
+package payment_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	paymentPkg "github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/sandbox"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+// lockedPaymentRepository serialises access to the mock repository, which is
+// shared between the test and the webhook server goroutines.
+type lockedPaymentRepository struct {
+	mu    sync.Mutex
+	inner *mockPaymentRepository
+}
+
+func (r *lockedPaymentRepository) Create(p *payment.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.Create(p)
+}
+
+func (r *lockedPaymentRepository) GetByID(id int64) (*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.copyOf(r.inner.GetByID(id))
+}
+
+func (r *lockedPaymentRepository) GetByExternalID(externalID string) (*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.copyOf(r.inner.GetByExternalID(externalID))
+}
+
+func (r *lockedPaymentRepository) GetByExpenseID(expenseID int64) ([]*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.GetByExpenseID(expenseID)
+}
+
+func (r *lockedPaymentRepository) GetLatestByExpenseID(expenseID int64) (*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.copyOf(r.inner.GetLatestByExpenseID(expenseID))
+}
+
+func (r *lockedPaymentRepository) UpdateStatus(id int64, status string, paymentMethod *string, gatewayResponse json.RawMessage, failureReason *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.UpdateStatus(id, status, paymentMethod, gatewayResponse, failureReason)
+}
+
+func (r *lockedPaymentRepository) IncrementRetryCount(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.IncrementRetryCount(id)
+}
+
+func (r *lockedPaymentRepository) ListPendingCreatedBefore(before time.Time, limit int) ([]*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.ListPendingCreatedBefore(before, limit)
+}
+
+func (r *lockedPaymentRepository) RecordFailure(id int64, failureClass string, nextRetryAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.RecordFailure(id, failureClass, nextRetryAt)
+}
+
+func (r *lockedPaymentRepository) ListRetryDue(now time.Time, limit int) ([]*payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.ListRetryDue(now, limit)
+}
+
+func (r *lockedPaymentRepository) ClaimRetry(id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.ClaimRetry(id)
+}
+
+func (r *lockedPaymentRepository) RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, recorded, err := r.inner.RecordInstallment(paymentID, installment, gatewayResponse)
+	p, err = r.copyOf(p, err)
+	return p, recorded, err
+}
+
+func (r *lockedPaymentRepository) ListInstallments(paymentID int64) ([]*payment.Installment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inner.ListInstallments(paymentID)
+}
+
+func (r *lockedPaymentRepository) copyOf(p *payment.Payment, err error) (*payment.Payment, error) {
+	if err != nil {
+		return nil, err
+	}
+	c := *p
+	return &c, nil
+}
+
+func (r *lockedPaymentRepository) status(externalID string) string {
+	p, err := r.GetByExternalID(externalID)
+	if err != nil {
+		return ""
+	}
+	return p.Status
+}
+
+var _ = Describe("Payment flow against the sandbox gateway", func() {
+	var (
+		gateway      *sandbox.Server
+		repo         *lockedPaymentRepository
+		orchestrator *paymentPkg.PaymentOrchestrator
+		audits       *mockAuditRecorder
+		mu           sync.Mutex
+		published    []string
+	)
+
+	BeforeEach(func() {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+		gateway = sandbox.NewForTest(GinkgoT())
+		gateway.SetWebhookSecret(testWebhookSecret)
+		// Real gateways never call back before initiation has returned.
+		gateway.SetDefault(sandbox.Success().Delayed(20 * time.Millisecond))
+
+		repo = &lockedPaymentRepository{inner: newMockPaymentRepository()}
+		audits = &mockAuditRecorder{}
+
+		eventBus := events.NewEventBus(logger)
+		published = nil
+		record := func(_ context.Context, e events.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			published = append(published, e.EventType())
+			return nil
+		}
+		eventBus.Subscribe(events.EventTypePaymentCompleted, record)
+		eventBus.Subscribe(events.EventTypePaymentMismatch, record)
+
+		var service *paymentPkg.PaymentService
+		webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := paymentPkg.NewWebhookHandler(transport.NewBaseHandler(logger), paymentPkg.NewCallbackProcessor(service, eventBus, audits, logger), nil, testSigner, logger)
+			handler.HandlePaymentCallback(w, r)
+		}))
+		DeferCleanup(webhook.Close)
+
+		client := paymentgateway.NewClient(paymentgateway.Config{
+			MockAPIURL:     gateway.URL(),
+			WebhookURL:     webhook.URL,
+			PaymentTimeout: time.Second,
+			MaxWorkers:     1,
+		}, nil, logger)
+		DeferCleanup(client.Shutdown)
+
+		service = paymentPkg.NewPaymentService(logger, repo, client, nil, nil, paymentPkg.RetryPolicy{})
+		orchestrator = paymentPkg.NewPaymentOrchestrator(service, logger)
+	})
+
+	publishedEvents := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), published...)
+	}
+
+	It("settles a payment from the gateway callback", func() {
+		externalID, err := orchestrator.ProcessPayment(42, 150000)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() string { return repo.status(externalID) }).Should(Equal(paymentPkg.StatusSuccess))
+		Eventually(publishedEvents).Should(ContainElement(events.EventTypePaymentCompleted))
+	})
+
+	It("records a declined payment as failed", func() {
+		gateway.Script("exp-42-150000", sandbox.Failure("card declined").Delayed(20*time.Millisecond))
+
+		externalID, err := orchestrator.ProcessPayment(42, 150000)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() string { return repo.status(externalID) }).Should(Equal(paymentPkg.StatusFailed))
+	})
+
+	It("rejects a callback reporting the wrong amount", func() {
+		gateway.Script("exp-42-150000", sandbox.Success().Delayed(20*time.Millisecond).WithAmountMismatch(1))
+
+		externalID, err := orchestrator.ProcessPayment(42, 150000)
+		Expect(err).NotTo(HaveOccurred())
+
+		callbacks, err := gateway.WaitForCallbacks(1, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(callbacks[0].StatusCode).To(Equal(http.StatusUnprocessableEntity))
+		Expect(repo.status(externalID)).To(Equal(paymentPkg.StatusPending))
+		Eventually(publishedEvents).Should(ContainElement(events.EventTypePaymentMismatch))
+		Expect(audits.entries).To(HaveLen(1))
+		Expect(audits.entries[0].Action).To(Equal(audit.ActionPaymentCallbackMismatch))
+	})
+
+	It("accepts a callback delivered twice", func() {
+		gateway.Script("exp-42-150000", sandbox.Success().Delayed(20*time.Millisecond).Duplicated(2))
+
+		externalID, err := orchestrator.ProcessPayment(42, 150000)
+		Expect(err).NotTo(HaveOccurred())
+
+		callbacks, err := gateway.WaitForCallbacks(2, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(callbacks[0].StatusCode).To(Equal(http.StatusOK))
+		Expect(callbacks[1].StatusCode).To(Equal(http.StatusOK))
+		Expect(repo.status(externalID)).To(Equal(paymentPkg.StatusSuccess))
+	})
+
+	It("refuses callbacks from a gateway without the webhook secret", func() {
+		gateway.SetWebhookSecret("spoofing-gateway-0123456789abcdefgh")
+
+		externalID, err := orchestrator.ProcessPayment(42, 150000)
+		Expect(err).NotTo(HaveOccurred())
+
+		callbacks, err := gateway.WaitForCallbacks(1, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(callbacks[0].StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(repo.status(externalID)).To(Equal(paymentPkg.StatusPending))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
 	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
@@ -12,26 +13,94 @@ import (
 
 type RepositoryAPI interface {
 	Create(p *payment.Payment) error
+	// GetByID fails with ErrPaymentNotFound when there is no such payment.
 	GetByID(id int64) (*payment.Payment, error)
 	GetByExternalID(externalID string) (*payment.Payment, error)
 	GetByExpenseID(expenseID int64) ([]*payment.Payment, error)
 	GetLatestByExpenseID(expenseID int64) (*payment.Payment, error)
+	// UpdateStatus fails with ErrInvalidPaymentStatus when the payment's
+	// current status may not move to status.
 	UpdateStatus(id int64, status string, paymentMethod *string, gatewayResponse json.RawMessage, failureReason *string) error
 	IncrementRetryCount(id int64) error
+	// RecordInstallment stores the installment and adds it to the payment's
+	// settled amount in one transaction, marking the payment success once
+	// fully settled. An installment already recorded for the same gateway
+	// transfer is ignored and reported as not recorded.
+	RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error)
+	ListInstallments(paymentID int64) ([]*payment.Installment, error)
+	ListPendingCreatedBefore(before time.Time, limit int) ([]*payment.Payment, error)
+	// RecordFailure stores the failure class of a failed payment and when it
+	// is retried automatically; a nil nextRetryAt means never.
+	RecordFailure(id int64, failureClass string, nextRetryAt *time.Time) error
+	// ListRetryDue lists failed payments whose automatic retry is due,
+	// earliest first.
+	ListRetryDue(now time.Time, limit int) ([]*payment.Payment, error)
+	// ClaimRetry takes a due retry so no one else runs it; it reports false
+	// when the retry was already taken or the payment moved on.
+	ClaimRetry(id int64) (bool, error)
+}
+
+// RetryPolicy schedules automatic retries of payments that failed
+// transiently. The zero policy retries nothing.
+type RetryPolicy struct {
+	// MaxRetries caps the automatic retries of one payment; retries are
+	// never made past MaxRetries in any case.
+	MaxRetries int
+	// Backoff is the wait before the first retry; it doubles with each one.
+	Backoff time.Duration
+}
+
+// next returns when a payment retried retryCount times is retried next, or
+// false when it is not.
+func (p RetryPolicy) next(retryCount int, now time.Time) (time.Time, bool) {
+	if retryCount >= p.MaxRetries || retryCount >= MaxRetries {
+		return time.Time{}, false
+	}
+	return now.Add(p.Backoff << retryCount), true
+}
+
+// PayoutResolver returns the bank details the expense is paid into;
+// bankaccount.Service satisfies it.
+type PayoutResolver interface {
+	PayoutForExpense(expenseID int64) (*paymentgatewaytypes.Payout, error)
 }
 
 type PaymentService struct {
 	logger     *slog.Logger
 	repository RepositoryAPI
 	gateway    *paymentgateway.Client
+	payouts    PayoutResolver
+	shadow     *shadowProcessor
+	retry      RetryPolicy
+	now        func() time.Time
 }
 
-func NewPaymentService(logger *slog.Logger, repository RepositoryAPI, gateway *paymentgateway.Client) *PaymentService {
-	return &PaymentService{
+// NewPaymentService takes a nil payouts when bank accounts are not enabled;
+// the gateway then pays out from its own records. A non-nil shadow turns on
+// shadow mode: every payment is also sent to it and the answers compared.
+// retry schedules automatic retries of transient failures.
+func NewPaymentService(logger *slog.Logger, repository RepositoryAPI, gateway *paymentgateway.Client, payouts PayoutResolver, shadow ShadowGateway, retry RetryPolicy) *PaymentService {
+	s := &PaymentService{
 		logger:     logger,
 		repository: repository,
 		gateway:    gateway,
+		payouts:    payouts,
+		retry:      retry,
+		now:        time.Now,
+	}
+	if shadow != nil {
+		s.shadow = newShadowProcessor(shadow, logger)
 	}
+	return s
+}
+
+// ShadowStats reports how the shadow gateway compared so far; ok is false
+// when shadow mode is off.
+func (s *PaymentService) ShadowStats() (stats ShadowStats, ok bool) {
+	if s.shadow == nil {
+		return ShadowStats{}, false
+	}
+	return s.shadow.stats(), true
 }
 
 func (s *PaymentService) CreatePayment(expenseID int64, externalID string, amountIDR int64) (*payment.Payment, error) {
@@ -66,20 +135,39 @@ func (s *PaymentService) ProcessPayment(req *PaymentRequest) (*PaymentResponse,
 		return nil, fmt.Errorf("payment record not found: %w", err)
 	}
 
-	gatewayReq := &paymentgatewaytypes.PaymentRequest{
-		ExternalID: req.ExternalID,
-		Amount:     req.Amount,
-		Currency:   "IDR",
+	var payout *paymentgatewaytypes.Payout
+	if s.payouts != nil {
+		payout, err = s.payouts.PayoutForExpense(paymentRecord.ExpenseID)
+		if err != nil {
+			s.logger.Error("payout account unavailable", "error", err, "external_id", req.ExternalID, "expense_id", paymentRecord.ExpenseID)
+
+			failureReason := err.Error()
+			if updateErr := s.repository.UpdateStatus(paymentRecord.ID, StatusFailed, nil, nil, &failureReason); updateErr != nil {
+				s.logger.Error("failed to update payment status after payout lookup error", "error", updateErr, "payment_id", paymentRecord.ID)
+			} else if _, classErr := s.RecordFailure(paymentRecord, paymentgateway.FailurePermanent); classErr != nil {
+				s.logger.Error("failed to record payment failure class", "error", classErr, "payment_id", paymentRecord.ID)
+			}
+
+			return nil, fmt.Errorf("payout account unavailable: %w", err)
+		}
 	}
 
+	gatewayReq := paymentgatewaytypes.NewPaymentRequest(req.ExternalID, req.Amount, payout)
+
 	gatewayResp, err := s.gateway.ProcessPayment(gatewayReq)
+	if s.shadow != nil {
+		s.shadow.mirror(gatewayReq, gatewayResp, err)
+	}
 	if err != nil {
-		s.logger.Error("payment gateway error", "error", err, "external_id", req.ExternalID)
+		failureClass := paymentgateway.Classify(err)
+		s.logger.Error("payment gateway error", "error", err, "external_id", req.ExternalID, "failure_class", failureClass)
 
 		failureReason := err.Error()
 		updateErr := s.repository.UpdateStatus(paymentRecord.ID, StatusFailed, nil, nil, &failureReason)
 		if updateErr != nil {
 			s.logger.Error("failed to update payment status after gateway error", "error", updateErr, "payment_id", paymentRecord.ID)
+		} else if _, classErr := s.RecordFailure(paymentRecord, failureClass); classErr != nil {
+			s.logger.Error("failed to record payment failure class", "error", classErr, "payment_id", paymentRecord.ID)
 		}
 
 		return nil, fmt.Errorf("payment processing failed: %w", err)
@@ -126,6 +214,34 @@ func (s *PaymentService) RetryPayment(req *PaymentRequest) (*PaymentResponse, er
 	return s.ProcessPayment(req)
 }
 
+// RecordFailure schedules a retry for a transient failure while the retry
+// policy allows one. A failure of unknown class, such as a gateway that
+// declined the payment, is permanent.
+func (s *PaymentService) RecordFailure(p *payment.Payment, failureClass string) (*time.Time, error) {
+	if failureClass != paymentgateway.FailureTransient {
+		failureClass = paymentgateway.FailurePermanent
+	}
+
+	var nextRetryAt *time.Time
+	if failureClass == paymentgateway.FailureTransient {
+		if at, ok := s.retry.next(p.RetryCount, s.now()); ok {
+			nextRetryAt = &at
+		}
+	}
+	if err := s.repository.RecordFailure(p.ID, failureClass, nextRetryAt); err != nil {
+		return nil, fmt.Errorf("failed to record payment failure: %w", err)
+	}
+
+	if nextRetryAt != nil {
+		s.logger.Info("payment failed transiently, retry scheduled",
+			"payment_id", p.ID,
+			"external_id", p.ExternalID,
+			"retry_count", p.RetryCount,
+			"next_retry_at", nextRetryAt)
+	}
+	return nextRetryAt, nil
+}
+
 func (s *PaymentService) GetPaymentByExpenseID(expenseID int64) (*payment.Payment, error) {
 	return s.repository.GetLatestByExpenseID(expenseID)
 }
@@ -137,3 +253,15 @@ func (s *PaymentService) GetPaymentByExternalID(externalID string) (*payment.Pay
 func (s *PaymentService) UpdatePaymentStatus(paymentID int64, status string, paymentMethod *string, gatewayResponse json.RawMessage, failureReason *string) error {
 	return s.repository.UpdateStatus(paymentID, status, paymentMethod, gatewayResponse, failureReason)
 }
+
+func (s *PaymentService) RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error) {
+	updated, recorded, err := s.repository.RecordInstallment(paymentID, installment, gatewayResponse)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record installment: %w", err)
+	}
+	return updated, recorded, nil
+}
+
+func (s *PaymentService) ListInstallments(paymentID int64) ([]*payment.Installment, error) {
+	return s.repository.ListInstallments(paymentID)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,10 +15,21 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/core/money"
 	paymentPkg "github.com/frahmantamala/expense-management/internal/payment"
 	"github.com/frahmantamala/expense-management/internal/paymentgateway"
 )
 
+type mockPayoutResolver struct {
+	payout *paymentgatewaytypes.Payout
+	err    error
+}
+
+func (m *mockPayoutResolver) PayoutForExpense(int64) (*paymentgatewaytypes.Payout, error) {
+	return m.payout, m.err
+}
+
 type mockPaymentRepository struct {
 	payments            map[string]*payment.Payment
 	paymentsByExpense   map[int64]*payment.Payment
@@ -25,6 +37,7 @@ type mockPaymentRepository struct {
 	getError            error
 	updateStatusError   error
 	incrementRetryError error
+	installments        map[int64][]*payment.Installment
 }
 
 func newMockPaymentRepository() *mockPaymentRepository {
@@ -110,7 +123,7 @@ func (m *mockPaymentRepository) GetByID(id int64) (*payment.Payment, error) {
 			return p, nil
 		}
 	}
-	return nil, errors.New("payment not found")
+	return nil, paymentPkg.ErrPaymentNotFound
 }
 
 func (m *mockPaymentRepository) GetByExpenseID(expenseID int64) ([]*payment.Payment, error) {
@@ -126,6 +139,87 @@ func (m *mockPaymentRepository) GetByExpenseID(expenseID int64) ([]*payment.Paym
 	return payments, nil
 }
 
+func (m *mockPaymentRepository) RecordInstallment(paymentID int64, installment *payment.Installment, gatewayResponse json.RawMessage) (*payment.Payment, bool, error) {
+	p, err := m.GetByID(paymentID)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, existing := range m.installments[paymentID] {
+		if existing.GatewayPaymentID == installment.GatewayPaymentID {
+			return p, false, nil
+		}
+	}
+	if m.installments == nil {
+		m.installments = make(map[int64][]*payment.Installment)
+	}
+	installment.PaymentID = paymentID
+	m.installments[paymentID] = append(m.installments[paymentID], installment)
+
+	p.SettledAmount += installment.AmountIDR
+	p.Status = paymentPkg.StatusPartiallySettled
+	if p.SettledAmount >= p.AmountIDR {
+		p.Status = paymentPkg.StatusSuccess
+	}
+	return p, true, nil
+}
+
+func (m *mockPaymentRepository) ListInstallments(paymentID int64) ([]*payment.Installment, error) {
+	return m.installments[paymentID], nil
+}
+
+func (m *mockPaymentRepository) RecordFailure(id int64, failureClass string, nextRetryAt *time.Time) error {
+	p, err := m.GetByID(id)
+	if err != nil {
+		return err
+	}
+	p.FailureClass = &failureClass
+	p.NextRetryAt = nextRetryAt
+	return nil
+}
+
+func (m *mockPaymentRepository) ListRetryDue(now time.Time, limit int) ([]*payment.Payment, error) {
+	var payments []*payment.Payment
+	for _, p := range m.payments {
+		if p.Status == paymentPkg.StatusFailed && p.NextRetryAt != nil && !p.NextRetryAt.After(now) {
+			payments = append(payments, p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].NextRetryAt.Before(*payments[j].NextRetryAt) })
+	if limit > 0 && len(payments) > limit {
+		payments = payments[:limit]
+	}
+	return payments, nil
+}
+
+func (m *mockPaymentRepository) ClaimRetry(id int64) (bool, error) {
+	p, err := m.GetByID(id)
+	if err != nil {
+		return false, err
+	}
+	if p.Status != paymentPkg.StatusFailed || p.NextRetryAt == nil {
+		return false, nil
+	}
+	p.NextRetryAt = nil
+	return true, nil
+}
+
+func (m *mockPaymentRepository) ListPendingCreatedBefore(before time.Time, limit int) ([]*payment.Payment, error) {
+	if m.getError != nil {
+		return nil, m.getError
+	}
+	var payments []*payment.Payment
+	for _, p := range m.payments {
+		if p.Status == paymentPkg.StatusPending && p.CreatedAt.Before(before) {
+			payments = append(payments, p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	if limit > 0 && len(payments) > limit {
+		payments = payments[:limit]
+	}
+	return payments, nil
+}
+
 var _ = Describe("PaymentService", func() {
 	var (
 		paymentService *paymentPkg.PaymentService
@@ -184,9 +278,9 @@ var _ = Describe("PaymentService", func() {
 			MaxWorkers:     2,
 			JobQueueSize:   10,
 			WorkerPoolSize: 2,
-		}, logger)
+		}, nil, logger)
 
-		paymentService = paymentPkg.NewPaymentService(logger, mockRepo, mockGateway)
+		paymentService = paymentPkg.NewPaymentService(logger, mockRepo, mockGateway, nil, nil, paymentPkg.RetryPolicy{})
 	})
 
 	AfterEach(func() {
@@ -236,7 +330,7 @@ var _ = Describe("PaymentService", func() {
 			It("should process payment successfully", func() {
 
 				req := &paymentPkg.PaymentRequest{
-					Amount:     50000,
+					Amount:     money.Rupiah(50000),
 					ExternalID: "test-external-id",
 				}
 
@@ -244,7 +338,7 @@ var _ = Describe("PaymentService", func() {
 					ID:         1,
 					ExpenseID:  123,
 					ExternalID: req.ExternalID,
-					AmountIDR:  req.Amount,
+					AmountIDR:  req.Amount.Amount,
 					Status:     paymentPkg.StatusPending,
 				}
 				mockRepo.payments[req.ExternalID] = testPayment
@@ -262,7 +356,7 @@ var _ = Describe("PaymentService", func() {
 			It("should return validation error for empty external ID", func() {
 
 				req := &paymentPkg.PaymentRequest{
-					Amount:     50000,
+					Amount:     money.Rupiah(50000),
 					ExternalID: "",
 				}
 
@@ -276,7 +370,7 @@ var _ = Describe("PaymentService", func() {
 			It("should return validation error for invalid amount", func() {
 
 				req := &paymentPkg.PaymentRequest{
-					Amount:     0,
+					Amount:     money.Rupiah(0),
 					ExternalID: "test-external-id",
 				}
 
@@ -292,7 +386,7 @@ var _ = Describe("PaymentService", func() {
 			It("should return an error", func() {
 
 				req := &paymentPkg.PaymentRequest{
-					Amount:     50000,
+					Amount:     money.Rupiah(50000),
 					ExternalID: "non-existent-external-id",
 				}
 
@@ -372,14 +466,14 @@ var _ = Describe("PaymentService", func() {
 					MaxWorkers:     2,
 					JobQueueSize:   10,
 					WorkerPoolSize: 2,
-				}, logger)
-				paymentService = paymentPkg.NewPaymentService(logger, mockRepo, mockGateway)
+				}, nil, logger)
+				paymentService = paymentPkg.NewPaymentService(logger, mockRepo, mockGateway, nil, nil, paymentPkg.RetryPolicy{})
 			})
 
 			It("should handle API errors gracefully", func() {
 
 				req := &paymentPkg.PaymentRequest{
-					Amount:     50000,
+					Amount:     money.Rupiah(50000),
 					ExternalID: "test-external-id",
 				}
 
@@ -387,7 +481,7 @@ var _ = Describe("PaymentService", func() {
 					ID:         1,
 					ExpenseID:  123,
 					ExternalID: req.ExternalID,
-					AmountIDR:  req.Amount,
+					AmountIDR:  req.Amount.Amount,
 					Status:     paymentPkg.StatusPending,
 				}
 				mockRepo.payments[req.ExternalID] = testPayment
@@ -400,4 +494,171 @@ var _ = Describe("PaymentService", func() {
 			})
 		})
 	})
+	Describe("Payout details", func() {
+		var (
+			payouts  *mockPayoutResolver
+			received chan map[string]interface{}
+			req      *paymentPkg.PaymentRequest
+		)
+
+		BeforeEach(func() {
+			received = make(chan map[string]interface{}, 1)
+			mockServer.Close()
+			mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				select {
+				case received <- body:
+				default:
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": map[string]string{"id": "gw-1", "external_id": "exp-123-50000", "status": "PENDING"},
+				})
+			}))
+
+			gateway := paymentgateway.NewClient(paymentgateway.Config{
+				MockAPIURL:     mockServer.URL,
+				PaymentTimeout: 10 * time.Second,
+				MaxWorkers:     1,
+				JobQueueSize:   10,
+				WorkerPoolSize: 1,
+			}, nil, logger)
+			payouts = &mockPayoutResolver{}
+			paymentService = paymentPkg.NewPaymentService(logger, mockRepo, gateway, payouts, nil, paymentPkg.RetryPolicy{})
+
+			req = &paymentPkg.PaymentRequest{Amount: money.Rupiah(50000), ExternalID: "exp-123-50000"}
+			mockRepo.payments[req.ExternalID] = &payment.Payment{
+				ID:         1,
+				ExpenseID:  123,
+				ExternalID: req.ExternalID,
+				AmountIDR:  req.Amount.Amount,
+				Status:     paymentPkg.StatusPending,
+			}
+		})
+
+		It("should send the expense's bank account to the gateway", func() {
+			payouts.payout = &paymentgatewaytypes.Payout{BankCode: "BCA", AccountNumber: "1234567890", AccountHolder: "Jane Doe"}
+
+			_, err := paymentService.ProcessPayment(req)
+
+			Expect(err).ToNot(HaveOccurred())
+			var body map[string]interface{}
+			Eventually(received).Should(Receive(&body))
+			Expect(body["payout"]).To(Equal(map[string]interface{}{
+				"bank_code":      "BCA",
+				"account_number": "1234567890",
+				"account_holder": "Jane Doe",
+			}))
+		})
+
+		It("should fail the payment without calling the gateway when no account can be paid into", func() {
+			payouts.err = errors.New("Payout bank account has not been verified")
+
+			result, err := paymentService.ProcessPayment(req)
+
+			Expect(err).To(MatchError(ContainSubstring("payout account unavailable")))
+			Expect(result).To(BeNil())
+			Expect(mockRepo.payments[req.ExternalID].Status).To(Equal(paymentPkg.StatusFailed))
+			Expect(*mockRepo.payments[req.ExternalID].FailureReason).To(Equal("Payout bank account has not been verified"))
+			Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+		})
+	})
+
+	Describe("Shadow mode", func() {
+		var (
+			shadowServer *httptest.Server
+			shadowStatus int
+			shadowState  string
+			shadowBodies chan map[string]interface{}
+			req          *paymentPkg.PaymentRequest
+		)
+
+		BeforeEach(func() {
+			shadowStatus = http.StatusCreated
+			shadowState = "PENDING"
+			shadowBodies = make(chan map[string]interface{}, 1)
+			shadowServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				shadowBodies <- body
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(shadowStatus)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": map[string]string{"id": "shadow-1", "external_id": "exp-7-50000", "status": shadowState},
+				})
+			}))
+			DeferCleanup(shadowServer.Close)
+
+			gateway := paymentgateway.NewClient(paymentgateway.Config{
+				MockAPIURL:     mockServer.URL,
+				PaymentTimeout: 10 * time.Second,
+				MaxWorkers:     1,
+				JobQueueSize:   10,
+				WorkerPoolSize: 1,
+			}, nil, logger)
+			DeferCleanup(gateway.Shutdown)
+			shadow := paymentgateway.NewShadow(paymentgateway.ShadowConfig{
+				Name:    "new-gateway",
+				APIURL:  shadowServer.URL,
+				Timeout: time.Second,
+			}, logger)
+			paymentService = paymentPkg.NewPaymentService(logger, mockRepo, gateway, nil, shadow, paymentPkg.RetryPolicy{})
+
+			req = &paymentPkg.PaymentRequest{Amount: money.Rupiah(50000), ExternalID: "exp-7-50000"}
+			mockRepo.payments[req.ExternalID] = &payment.Payment{
+				ID:         1,
+				ExpenseID:  7,
+				ExternalID: req.ExternalID,
+				AmountIDR:  req.Amount.Amount,
+				Status:     paymentPkg.StatusPending,
+			}
+		})
+
+		shadowStats := func() paymentPkg.ShadowStats {
+			stats, _ := paymentService.ShadowStats()
+			return stats
+		}
+
+		It("should mirror the payment without giving the shadow gateway the payment webhook", func() {
+			result, err := paymentService.ProcessPayment(req)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Data.ID).To(Equal("mock-payment-id-12345"))
+			var body map[string]interface{}
+			Eventually(shadowBodies).Should(Receive(&body))
+			Expect(body["external_id"]).To(Equal("exp-7-50000"))
+			Expect(body["callback_url"]).To(BeEmpty())
+			Eventually(shadowStats).Should(Equal(paymentPkg.ShadowStats{Gateway: "new-gateway", Compared: 1, Matched: 1}))
+		})
+
+		It("should count a shadow gateway that refuses the payment as an outcome divergence", func() {
+			shadowStatus = http.StatusServiceUnavailable
+
+			_, err := paymentService.ProcessPayment(req)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockRepo.payments[req.ExternalID].Status).To(Equal(paymentPkg.StatusPending))
+			Eventually(shadowStats).Should(Equal(paymentPkg.ShadowStats{
+				Gateway: "new-gateway", Compared: 1, OutcomeDivergences: 1, ShadowFailures: 1,
+			}))
+		})
+
+		It("should count a different status as a status divergence", func() {
+			shadowState = "SUCCESS"
+
+			_, err := paymentService.ProcessPayment(req)
+
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(shadowStats).Should(Equal(paymentPkg.ShadowStats{Gateway: "new-gateway", Compared: 1, StatusDivergences: 1}))
+		})
+
+		It("should report shadow mode as off without a shadow gateway", func() {
+			gateway := paymentgateway.NewClient(paymentgateway.Config{MockAPIURL: mockServer.URL, PaymentTimeout: time.Second, MaxWorkers: 1}, nil, logger)
+			DeferCleanup(gateway.Shutdown)
+
+			_, ok := paymentPkg.NewPaymentService(logger, mockRepo, gateway, nil, nil, paymentPkg.RetryPolicy{}).ShadowStats()
+			Expect(ok).To(BeFalse())
+		})
+	})
 })
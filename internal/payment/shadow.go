@@ -0,0 +1,117 @@
+package payment
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/core/metrics"
+)
+
+// ShadowGateway receives a copy of every payment in shadow mode, so a new
+// gateway can be compared with the live one before switching to it; its
+// answers are never applied. paymentgateway.Shadow satisfies it.
+type ShadowGateway interface {
+	Name() string
+	Initiate(req *paymentgatewaytypes.PaymentRequest) (*paymentgatewaytypes.PaymentResponse, error)
+}
+
+const (
+	// DivergenceOutcome means one gateway accepted the payment and the other
+	// did not.
+	DivergenceOutcome = "outcome"
+	// DivergenceStatus means both accepted the payment with different
+	// statuses.
+	DivergenceStatus = "status"
+)
+
+// ShadowStats counts how the shadow gateway's answers compared with the
+// live gateway's. It is published under payment_shadow in the metrics.
+type ShadowStats struct {
+	Gateway            string `json:"gateway"`
+	Compared           int64  `json:"compared"`
+	Matched            int64  `json:"matched"`
+	OutcomeDivergences int64  `json:"outcome_divergences"`
+	StatusDivergences  int64  `json:"status_divergences"`
+	// ShadowFailures counts payments the shadow gateway did not accept,
+	// whether or not the live gateway did.
+	ShadowFailures int64 `json:"shadow_failures"`
+}
+
+type shadowProcessor struct {
+	gateway ShadowGateway
+	logger  *slog.Logger
+
+	compared atomic.Int64
+	matched  atomic.Int64
+	outcome  atomic.Int64
+	status   atomic.Int64
+	failures atomic.Int64
+}
+
+func newShadowProcessor(gateway ShadowGateway, logger *slog.Logger) *shadowProcessor {
+	p := &shadowProcessor{gateway: gateway, logger: logger}
+	metrics.Register("payment_shadow", func() interface{} {
+		return p.stats()
+	})
+	return p
+}
+
+// mirror sends req to the shadow gateway in the background, so the live
+// payment never waits for it, and compares the answer with live. live is
+// accepted when the live gateway initiated the payment with a provider; a
+// payment left to the background retry counts as not accepted.
+func (p *shadowProcessor) mirror(req *paymentgatewaytypes.PaymentRequest, live *paymentgatewaytypes.PaymentResponse, liveErr error) {
+	go func() {
+		shadow, shadowErr := p.gateway.Initiate(req)
+		p.compare(req.ExternalID, live, liveErr, shadow, shadowErr)
+	}()
+}
+
+func (p *shadowProcessor) compare(externalID string, live *paymentgatewaytypes.PaymentResponse, liveErr error, shadow *paymentgatewaytypes.PaymentResponse, shadowErr error) {
+	p.compared.Add(1)
+	liveAccepted := liveErr == nil && live.Data.Provider != ""
+	shadowAccepted := shadowErr == nil
+	if !shadowAccepted {
+		p.failures.Add(1)
+	}
+
+	log := p.logger.With("external_id", externalID, "shadow", p.gateway.Name())
+	switch {
+	case liveAccepted != shadowAccepted:
+		p.outcome.Add(1)
+		log.Warn("shadow payment diverged",
+			"divergence", DivergenceOutcome,
+			"live_accepted", liveAccepted,
+			"live_error", errString(liveErr),
+			"shadow_accepted", shadowAccepted,
+			"shadow_error", errString(shadowErr))
+	case shadowAccepted && live.Data.Status != shadow.Data.Status:
+		p.status.Add(1)
+		log.Warn("shadow payment diverged",
+			"divergence", DivergenceStatus,
+			"live_status", live.Data.Status,
+			"shadow_status", shadow.Data.Status)
+	default:
+		p.matched.Add(1)
+		log.Debug("shadow payment matched", "accepted", liveAccepted)
+	}
+}
+
+func (p *shadowProcessor) stats() ShadowStats {
+	return ShadowStats{
+		Gateway:            p.gateway.Name(),
+		Compared:           p.compared.Load(),
+		Matched:            p.matched.Load(),
+		OutcomeDivergences: p.outcome.Load(),
+		StatusDivergences:  p.status.Load(),
+		ShadowFailures:     p.failures.Load(),
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,37 @@
+package payment
+
+// statusTransitions lists where each payment status may move. A failed
+// payment goes back to pending when it is retried, and may still succeed
+// when the gateway settles it late. Success is final, so a late pending or
+// failed callback can never undo it.
+var statusTransitions = map[string][]string{
+	StatusPending:          {StatusPartiallySettled, StatusSuccess, StatusFailed},
+	StatusPartiallySettled: {StatusSuccess},
+	StatusFailed:           {StatusPending, StatusSuccess},
+}
+
+// CanTransition reports whether a payment in status from may move to to.
+// Staying in a status is allowed so redelivered callbacks are harmless.
+func CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusesBefore lists the statuses a payment may be in to move to to,
+// to itself included.
+func StatusesBefore(to string) []string {
+	statuses := []string{to}
+	for _, from := range []string{StatusPending, StatusPartiallySettled, StatusSuccess, StatusFailed} {
+		if from != to && CanTransition(from, to) {
+			statuses = append(statuses, from)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,38 @@
+package payment_test
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+)
+
+var _ = ginkgo.Describe("CanTransition", func() {
+	ginkgo.DescribeTable("allows payments to move forward",
+		func(from, to string) {
+			gomega.Expect(paymentpkg.CanTransition(from, to)).To(gomega.BeTrue())
+		},
+		ginkgo.Entry("pending to success", paymentpkg.StatusPending, paymentpkg.StatusSuccess),
+		ginkgo.Entry("pending to failed", paymentpkg.StatusPending, paymentpkg.StatusFailed),
+		ginkgo.Entry("pending to partially settled", paymentpkg.StatusPending, paymentpkg.StatusPartiallySettled),
+		ginkgo.Entry("partially settled to success", paymentpkg.StatusPartiallySettled, paymentpkg.StatusSuccess),
+		ginkgo.Entry("failed to pending on retry", paymentpkg.StatusFailed, paymentpkg.StatusPending),
+		ginkgo.Entry("failed to success when settled late", paymentpkg.StatusFailed, paymentpkg.StatusSuccess),
+		ginkgo.Entry("a redelivered status", paymentpkg.StatusSuccess, paymentpkg.StatusSuccess),
+	)
+
+	ginkgo.DescribeTable("refuses regressions",
+		func(from, to string) {
+			gomega.Expect(paymentpkg.CanTransition(from, to)).To(gomega.BeFalse())
+		},
+		ginkgo.Entry("success to pending", paymentpkg.StatusSuccess, paymentpkg.StatusPending),
+		ginkgo.Entry("success to failed", paymentpkg.StatusSuccess, paymentpkg.StatusFailed),
+		ginkgo.Entry("partially settled to pending", paymentpkg.StatusPartiallySettled, paymentpkg.StatusPending),
+		ginkgo.Entry("partially settled to failed", paymentpkg.StatusPartiallySettled, paymentpkg.StatusFailed),
+	)
+
+	ginkgo.It("lists the statuses a payment may reach a status from", func() {
+		gomega.Expect(paymentpkg.StatusesBefore(paymentpkg.StatusPending)).To(gomega.ConsistOf(paymentpkg.StatusPending, paymentpkg.StatusFailed))
+		gomega.Expect(paymentpkg.StatusesBefore(paymentpkg.StatusFailed)).To(gomega.ConsistOf(paymentpkg.StatusFailed, paymentpkg.StatusPending))
+	})
+})
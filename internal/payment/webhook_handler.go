@@ -3,37 +3,60 @@ package payment
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
 
-	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/webhooksig"
 	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
+// CallbackQueue durably accepts callbacks for later processing.
+type CallbackQueue interface {
+	Accept(ctx context.Context, req *PaymentCallbackRequest, now time.Time) error
+}
+
+// maxCallbackBytes bounds a callback body, which is read whole to check its
+// signature.
+const maxCallbackBytes = 64 << 10
+
 type WebhookHandler struct {
 	*transport.BaseHandler
-	paymentService ServiceAPI
-	eventBus       *events.EventBus
-	logger         *slog.Logger
+	processor *CallbackProcessor
+	inbox     CallbackQueue
+	signer    *webhooksig.Signer
+	logger    *slog.Logger
 }
 
-func NewWebhookHandler(baseHandler *transport.BaseHandler, paymentService ServiceAPI, eventBus *events.EventBus, logger *slog.Logger) *WebhookHandler {
+// NewWebhookHandler queues callbacks in inbox when it is set and applies them
+// inline otherwise. Only callbacks signed by signer are accepted, whether
+// they come from the gateway or from the client's own workers.
+func NewWebhookHandler(baseHandler *transport.BaseHandler, processor *CallbackProcessor, inbox CallbackQueue, signer *webhooksig.Signer, logger *slog.Logger) *WebhookHandler {
 	return &WebhookHandler{
-		BaseHandler:    baseHandler,
-		paymentService: paymentService,
-		eventBus:       eventBus,
-		logger:         logger,
+		BaseHandler: baseHandler,
+		processor:   processor,
+		inbox:       inbox,
+		signer:      signer,
+		logger:      logger,
 	}
 }
 
+func (h *WebhookHandler) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, h.logger)
+}
+
 type PaymentCallbackRequest struct {
 	ExternalID       string `json:"external_id"`
 	Status           string `json:"status"`
 	GatewayPaymentID string `json:"gateway_payment_id"`
 	Amount           int64  `json:"amount"`
 	FailureReason    string `json:"failure_reason,omitempty"`
+	// FailureClass is transient when a failed payment is worth retrying;
+	// anything else, or nothing, is a permanent failure.
+	FailureClass string `json:"failure_class,omitempty"`
 }
 
 type PaymentCallbackResponse struct {
@@ -41,35 +64,86 @@ type PaymentCallbackResponse struct {
 	Message string `json:"message"`
 }
 
+// HandlePaymentCallback godoc
+// @Summary      Payment gateway callback
+// @Description  Callbacks must be signed with the webhook secret shared with the gateway: X-Signature is "sha256=" + hex(HMAC-SHA256(webhook_secret, timestamp + "." + body)), with the Unix timestamp in X-Signature-Timestamp, at most 5 minutes from now. Unsigned, badly signed and stale callbacks are rejected with 401. With the callback inbox enabled, a well-formed callback is stored and acknowledged at once, then applied in the background with retries; redelivered callbacks are acknowledged without being stored twice. Otherwise it is applied inline, and callbacks whose external_id or amount do not match the stored payment are rejected with 422. A "partial" status settles amount as one installment identified by gateway_payment_id; the expense is completed once installments add up to the payment amount.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Param        body  body      PaymentCallbackRequest  true  "Gateway callback"
+// @Success      200   {object}  PaymentCallbackResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      422   {object}  map[string]string
+// @Router       /payment/callback [post]
 func (h *WebhookHandler) HandlePaymentCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCallbackBytes))
+	if err != nil {
+		h.log(ctx).Error("failed to read payment callback", "error", err)
+		h.WriteErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.signer.Verify(r.Header, body, time.Now()); err != nil {
+		h.log(ctx).Warn("payment callback rejected", "error", err, "remote_addr", r.RemoteAddr)
+		h.WriteErrorResponse(w, http.StatusUnauthorized, "invalid callback signature")
+		return
+	}
+
 	var req PaymentCallbackRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("invalid payment callback request", "error", err)
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.log(ctx).Error("invalid payment callback request", "error", err)
 		h.WriteErrorResponse(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	h.logger.Info("received payment callback",
+	h.log(ctx).Info("received payment callback",
 		"external_id", req.ExternalID,
 		"status", req.Status,
 		"gateway_payment_id", req.GatewayPaymentID,
 		"amount", req.Amount)
 
 	if req.ExternalID == "" {
-		h.logger.Error("payment callback missing external_id")
+		h.log(ctx).Error("payment callback missing external_id")
 		h.WriteErrorResponse(w, http.StatusBadRequest, "external_id is required")
 		return
 	}
 
 	if req.Status == "" {
-		h.logger.Error("payment callback missing status", "external_id", req.ExternalID)
+		h.log(ctx).Error("payment callback missing status", "external_id", req.ExternalID)
 		h.WriteErrorResponse(w, http.StatusBadRequest, "status is required")
 		return
 	}
 
-	err := h.processPaymentCallback(&req)
+	// Installments are told apart, and deduplicated, by their transfer ID.
+	if MapExternalStatus(req.Status) == StatusPartiallySettled && req.GatewayPaymentID == "" {
+		h.log(ctx).Error("partial payment callback missing gateway_payment_id", "external_id", req.ExternalID)
+		h.WriteErrorResponse(w, http.StatusBadRequest, "gateway_payment_id is required for partial settlements")
+		return
+	}
+
+	if h.inbox != nil {
+		if err := h.inbox.Accept(ctx, &req, time.Now()); err != nil {
+			h.log(ctx).Error("failed to queue payment callback", "error", err, "external_id", req.ExternalID)
+			h.WriteErrorResponse(w, http.StatusInternalServerError, "failed to accept payment callback")
+			return
+		}
+		h.WriteJSON(w, http.StatusOK, PaymentCallbackResponse{
+			Status:  "accepted",
+			Message: "callback queued for processing",
+		})
+		return
+	}
+
+	err = h.processor.Process(ctx, &req)
+	if errors.Is(err, ErrCallbackMismatch) {
+		h.WriteErrorResponse(w, http.StatusUnprocessableEntity, "callback does not match payment record")
+		return
+	}
 	if err != nil {
-		h.logger.Error("failed to process payment callback",
+		h.log(ctx).Error("failed to process payment callback",
 			"error", err,
 			"external_id", req.ExternalID,
 			"status", req.Status)
@@ -82,85 +156,13 @@ func (h *WebhookHandler) HandlePaymentCallback(w http.ResponseWriter, r *http.Re
 		Message: "callback processed successfully",
 	}
 
-	h.logger.Info("payment callback processed successfully",
+	h.log(ctx).Info("payment callback processed successfully",
 		"external_id", req.ExternalID,
 		"status", req.Status)
 
 	h.WriteJSON(w, http.StatusOK, response)
 }
 
-func (h *WebhookHandler) processPaymentCallback(req *PaymentCallbackRequest) error {
-
-	payment, err := h.paymentService.GetPaymentByExternalID(req.ExternalID)
-	if err != nil {
-		return fmt.Errorf("payment not found for external_id %s: %w", req.ExternalID, err)
-	}
-
-	h.logger.Info("processing payment callback for payment record",
-		"payment_id", payment.ID,
-		"expense_id", payment.ExpenseID,
-		"external_id", req.ExternalID,
-		"current_status", payment.Status,
-		"new_status", req.Status)
-
-	internalStatus := MapExternalStatus(req.Status)
-
-	callbackData := map[string]interface{}{
-		"gateway_payment_id": req.GatewayPaymentID,
-		"gateway_status":     req.Status,
-		"amount":             req.Amount,
-		"callback_time":      time.Now().UTC(),
-	}
-
-	if req.FailureReason != "" {
-		callbackData["failure_reason"] = req.FailureReason
-	}
-
-	callbackJSON, _ := json.Marshal(callbackData)
-
-	var failureReason *string
-	if req.FailureReason != "" {
-		failureReason = &req.FailureReason
-	}
-
-	err = h.paymentService.UpdatePaymentStatus(payment.ID, internalStatus, nil, callbackJSON, failureReason)
-	if err != nil {
-		return fmt.Errorf("failed to update payment status: %w", err)
-	}
-
-	if internalStatus == StatusSuccess {
-		event := events.NewPaymentCompletedEvent(
-			fmt.Sprintf("%d", payment.ID),
-			payment.ExpenseID,
-			req.ExternalID,
-			req.Amount,
-			internalStatus,
-			req.GatewayPaymentID,
-		)
-		h.eventBus.Publish(context.Background(), event)
-		h.logger.Info("published payment completed event", "event_id", event.EventID())
-	} else if internalStatus == StatusFailed {
-		event := events.NewPaymentFailedEvent(
-			fmt.Sprintf("%d", payment.ID),
-			payment.ExpenseID,
-			req.ExternalID,
-			req.Amount,
-			req.FailureReason,
-			payment.RetryCount,
-		)
-		h.eventBus.Publish(context.Background(), event)
-		h.logger.Info("published payment failed event", "event_id", event.EventID())
-	}
-
-	h.logger.Info("payment status updated successfully",
-		"payment_id", payment.ID,
-		"external_id", req.ExternalID,
-		"old_status", payment.Status,
-		"new_status", internalStatus)
-
-	return nil
-}
-
 func (h *WebhookHandler) WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	response := map[string]string{
 		"error": message,
@@ -0,0 +1,295 @@
+package payment_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	paymentpkg "github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/webhooksig"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+const testWebhookSecret = "test-webhook-secret-0123456789abcdef"
+
+var testSigner = webhooksig.New(testWebhookSecret)
+
+// newSignedCallback is a callback request signed as the gateway signs it.
+func newSignedCallback(body []byte) *http.Request {
+	req := httptest.NewRequest("POST", "/api/v1/payment/callback", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	testSigner.SignRequest(req, body, time.Now())
+	return req
+}
+
+type mockAuditRecorder struct {
+	entries []*audit.Entry
+}
+
+func (m *mockAuditRecorder) Record(entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+var _ = ginkgo.Describe("WebhookHandler", func() {
+	var (
+		handler        *paymentpkg.WebhookHandler
+		paymentService *mockPaymentService
+		auditRecorder  *mockAuditRecorder
+		eventBus       *events.EventBus
+		recorder       *httptest.ResponseRecorder
+		published      []string
+		mu             sync.Mutex
+	)
+
+	ginkgo.BeforeEach(func() {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		paymentService = &mockPaymentService{
+			payment: &payment.Payment{
+				ID:         7,
+				ExpenseID:  42,
+				ExternalID: "exp-42-150000",
+				AmountIDR:  150000,
+				Status:     paymentpkg.StatusPending,
+			},
+		}
+		auditRecorder = &mockAuditRecorder{}
+		eventBus = events.NewEventBus(logger)
+		published = nil
+		record := func(ctx context.Context, event events.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			published = append(published, event.EventType())
+			return nil
+		}
+		eventBus.Subscribe(events.EventTypePaymentCompleted, record)
+		eventBus.Subscribe(events.EventTypePaymentMismatch, record)
+		eventBus.Subscribe(events.EventTypePaymentFailed, record)
+
+		handler = paymentpkg.NewWebhookHandler(transport.NewBaseHandler(logger), paymentpkg.NewCallbackProcessor(paymentService, eventBus, auditRecorder, logger), nil, testSigner, logger)
+		recorder = httptest.NewRecorder()
+	})
+
+	publishedEvents := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), published...)
+	}
+
+	sendCallback := func(body map[string]interface{}) {
+		jsonBody, _ := json.Marshal(body)
+		handler.HandlePaymentCallback(recorder, newSignedCallback(jsonBody))
+	}
+
+	ginkgo.Describe("signatures", func() {
+		body := []byte(`{"external_id":"exp-42-150000","status":"success","amount":150000}`)
+
+		ginkgo.It("rejects an unsigned callback", func() {
+			req := httptest.NewRequest("POST", "/api/v1/payment/callback", bytes.NewBuffer(body))
+			handler.HandlePaymentCallback(recorder, req)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("rejects a callback signed with another secret", func() {
+			req := httptest.NewRequest("POST", "/api/v1/payment/callback", bytes.NewBuffer(body))
+			webhooksig.New("forged-secret-0123456789abcdefghijkl").SignRequest(req, body, time.Now())
+			handler.HandlePaymentCallback(recorder, req)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("rejects a callback whose body was changed after signing", func() {
+			req := newSignedCallback(body)
+			req.Body = io.NopCloser(bytes.NewBufferString(`{"external_id":"exp-42-150000","status":"success","amount":1}`))
+			handler.HandlePaymentCallback(recorder, req)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+
+		ginkgo.It("rejects a replayed callback signed long ago", func() {
+			req := httptest.NewRequest("POST", "/api/v1/payment/callback", bytes.NewBuffer(body))
+			testSigner.SignRequest(req, body, time.Now().Add(-time.Hour))
+			handler.HandlePaymentCallback(recorder, req)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.It("should mark the payment successful when the callback matches", func() {
+		sendCallback(map[string]interface{}{
+			"external_id": "exp-42-150000",
+			"status":      "success",
+			"amount":      150000,
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.Equal([]string{paymentpkg.StatusSuccess}))
+		gomega.Expect(auditRecorder.entries).To(gomega.BeEmpty())
+		gomega.Eventually(publishedEvents).Should(gomega.ContainElement(events.EventTypePaymentCompleted))
+	})
+
+	ginkgo.It("should fail the expense at once when the failure is permanent", func() {
+		sendCallback(map[string]interface{}{
+			"external_id":    "exp-42-150000",
+			"status":         "failed",
+			"amount":         150000,
+			"failure_reason": "invalid account",
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(paymentService.failureClasses).To(gomega.Equal([]string{""}))
+		gomega.Eventually(publishedEvents).Should(gomega.ContainElement(events.EventTypePaymentFailed))
+	})
+
+	ginkgo.It("should hold the expense back while a transient failure is retried", func() {
+		retryAt := time.Now().Add(time.Minute)
+		paymentService.retryAt = &retryAt
+
+		sendCallback(map[string]interface{}{
+			"external_id":    "exp-42-150000",
+			"status":         "failed",
+			"amount":         150000,
+			"failure_reason": "Payment initiation failed: connection reset",
+			"failure_class":  "transient",
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.Equal([]string{paymentpkg.StatusFailed}))
+		gomega.Expect(paymentService.failureClasses).To(gomega.Equal([]string{"transient"}))
+		gomega.Consistently(publishedEvents, 100*time.Millisecond).ShouldNot(gomega.ContainElement(events.EventTypePaymentFailed))
+	})
+
+	ginkgo.It("should reject a callback whose amount differs from the payment", func() {
+		sendCallback(map[string]interface{}{
+			"external_id": "exp-42-150000",
+			"status":      "success",
+			"amount":      1500000,
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnprocessableEntity))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		gomega.Expect(auditRecorder.entries).To(gomega.HaveLen(1))
+		gomega.Expect(auditRecorder.entries[0].Action).To(gomega.Equal(audit.ActionPaymentCallbackMismatch))
+		gomega.Expect(auditRecorder.entries[0].ResourceID).To(gomega.Equal("7"))
+		gomega.Eventually(publishedEvents).Should(gomega.ContainElement(events.EventTypePaymentMismatch))
+		gomega.Consistently(publishedEvents).ShouldNot(gomega.ContainElement(events.EventTypePaymentCompleted))
+	})
+
+	ginkgo.It("should reject a callback whose external_id differs from the payment", func() {
+		sendCallback(map[string]interface{}{
+			"external_id": "exp-42-150000-other",
+			"status":      "success",
+			"amount":      150000,
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnprocessableEntity))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		gomega.Expect(auditRecorder.entries).To(gomega.HaveLen(1))
+	})
+	ginkgo.It("should ignore and audit a late callback that would undo a success", func() {
+		paymentService.payment.Status = paymentpkg.StatusSuccess
+
+		sendCallback(map[string]interface{}{
+			"external_id": "exp-42-150000",
+			"status":      "pending",
+			"amount":      150000,
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(paymentService.updatedStatuses).To(gomega.BeEmpty())
+		gomega.Expect(auditRecorder.entries).To(gomega.HaveLen(1))
+		gomega.Expect(auditRecorder.entries[0].Action).To(gomega.Equal(audit.ActionPaymentCallbackOutOfOrder))
+		gomega.Expect(auditRecorder.entries[0].Metadata).To(gomega.HaveKeyWithValue("current_status", paymentpkg.StatusSuccess))
+		gomega.Expect(auditRecorder.entries[0].Metadata).To(gomega.HaveKeyWithValue("callback_status", paymentpkg.StatusPending))
+	})
+
+	ginkgo.It("should treat a status change lost to another callback as out of order", func() {
+		paymentService.updatePaymentStatusError = paymentpkg.ErrInvalidPaymentStatus
+
+		sendCallback(map[string]interface{}{
+			"external_id": "exp-42-150000",
+			"status":      "failed",
+			"amount":      150000,
+		})
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(auditRecorder.entries).To(gomega.HaveLen(1))
+		gomega.Expect(auditRecorder.entries[0].Action).To(gomega.Equal(audit.ActionPaymentCallbackOutOfOrder))
+	})
+
+	ginkgo.Describe("partial settlements", func() {
+		partial := func(gatewayID string, amount int64) {
+			recorder = httptest.NewRecorder()
+			sendCallback(map[string]interface{}{
+				"external_id":        "exp-42-150000",
+				"status":             "partial",
+				"gateway_payment_id": gatewayID,
+				"amount":             amount,
+			})
+		}
+
+		ginkgo.It("should complete the expense only once installments add up", func() {
+			partial("tr-1", 100000)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(paymentService.payment.SettledAmount).To(gomega.Equal(int64(100000)))
+			gomega.Consistently(publishedEvents).ShouldNot(gomega.ContainElement(events.EventTypePaymentCompleted))
+
+			partial("tr-1", 100000)
+			gomega.Expect(paymentService.installments).To(gomega.HaveLen(1))
+
+			partial("tr-2", 50000)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(paymentService.payment.SettledAmount).To(gomega.Equal(int64(150000)))
+			gomega.Eventually(publishedEvents).Should(gomega.ContainElement(events.EventTypePaymentCompleted))
+		})
+
+		ginkgo.It("should treat a success callback for the balance as the last installment", func() {
+			partial("tr-1", 100000)
+			recorder = httptest.NewRecorder()
+
+			sendCallback(map[string]interface{}{
+				"external_id":        "exp-42-150000",
+				"status":             "success",
+				"gateway_payment_id": "tr-2",
+				"amount":             50000,
+			})
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(paymentService.installments).To(gomega.HaveLen(2))
+			gomega.Eventually(publishedEvents).Should(gomega.ContainElement(events.EventTypePaymentCompleted))
+		})
+
+		ginkgo.It("should reject an installment larger than the outstanding amount", func() {
+			partial("tr-1", 200000)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnprocessableEntity))
+			gomega.Expect(paymentService.installments).To(gomega.BeEmpty())
+			gomega.Expect(auditRecorder.entries).To(gomega.HaveLen(1))
+		})
+
+		ginkgo.It("should require the transfer ID", func() {
+			partial("", 50000)
+
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusBadRequest))
+		})
+	})
+})
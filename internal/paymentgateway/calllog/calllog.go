@@ -0,0 +1,157 @@
+// Package calllog keeps every request the Client sends to a payment gateway,
+// with the answer and how long it took, so a charge disputed with the
+// provider can be checked against what was actually said. Secrets are
+// redacted as on a cassette.
+package calllog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/cassette"
+)
+
+// DefaultMaxBodyBytes bounds each stored body when no limit is given.
+const DefaultMaxBodyBytes = 64 << 10
+
+// Store keeps calls. It is called on the request's goroutine, before the
+// response is handed back to the Client.
+type Store interface {
+	Save(call *payment.GatewayCall) error
+}
+
+// Transport is an http.RoundTripper for the gateway client. A call that
+// cannot be stored is logged and otherwise ignored: losing the evidence
+// must not fail the payment.
+type Transport struct {
+	store        Store
+	next         http.RoundTripper
+	maxBodyBytes int
+	now          func() time.Time
+	logger       *slog.Logger
+}
+
+// New sends requests through next, or http.DefaultTransport when nil, and
+// stores at most maxBodyBytes of each body, DefaultMaxBodyBytes when zero.
+func New(store Store, next http.RoundTripper, maxBodyBytes int, logger *slog.Logger) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Transport{
+		store:        store,
+		next:         next,
+		maxBodyBytes: maxBodyBytes,
+		now:          time.Now,
+		logger:       logger,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	call := &payment.GatewayCall{
+		ExternalID:    externalID(req, body),
+		Method:        req.Method,
+		URL:           req.URL.Redacted(),
+		RequestHeader: encodeHeader(req.Header),
+		RequestBody:   t.truncate(cassette.RedactBody(body)),
+	}
+
+	start := t.now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.save(call, start, err)
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	statusCode := resp.StatusCode
+	call.StatusCode = &statusCode
+	call.ResponseHeader = encodeHeader(resp.Header)
+	if err != nil {
+		err = fmt.Errorf("failed to read gateway response: %w", err)
+		t.save(call, start, err)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	call.ResponseBody = t.truncate(cassette.RedactBody(respBody))
+	t.save(call, start, nil)
+	return resp, nil
+}
+
+func (t *Transport) save(call *payment.GatewayCall, start time.Time, callErr error) {
+	call.LatencyMS = t.now().Sub(start).Milliseconds()
+	if callErr != nil {
+		message := callErr.Error()
+		call.Error = &message
+	}
+	if err := t.store.Save(call); err != nil {
+		t.logger.Error("failed to store gateway call",
+			"error", err,
+			"external_id", call.ExternalID,
+			"method", call.Method,
+			"url", call.URL)
+	}
+}
+
+// truncate cuts body to maxBodyBytes without splitting a UTF-8 sequence.
+func (t *Transport) truncate(body string) string {
+	if len(body) <= t.maxBodyBytes {
+		return body
+	}
+	return strings.ToValidUTF8(body[:t.maxBodyBytes], "")
+}
+
+// readBody drains the request body and puts it back for the transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gateway request: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// externalID is the payment a request is about: the external_id of an
+// initiation's body, or of a status check's query.
+func externalID(req *http.Request, body []byte) string {
+	if id := req.URL.Query().Get("external_id"); id != "" {
+		return id
+	}
+	var payload struct {
+		ExternalID string `json:"external_id"`
+	}
+	if len(body) > 0 && json.Unmarshal(body, &payload) == nil {
+		return payload.ExternalID
+	}
+	return ""
+}
+
+func encodeHeader(header http.Header) json.RawMessage {
+	redacted := cassette.RedactHeader(header)
+	if redacted == nil {
+		return nil
+	}
+	raw, err := json.Marshal(redacted)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,13 @@
+package calllog_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCallLog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gateway Call Log Suite")
+}
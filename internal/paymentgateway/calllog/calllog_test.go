@@ -0,0 +1,121 @@
+package calllog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/calllog"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/cassette"
+)
+
+type memoryStore struct {
+	calls []*payment.GatewayCall
+	err   error
+}
+
+func (m *memoryStore) Save(call *payment.GatewayCall) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.calls = append(m.calls, call)
+	return nil
+}
+
+var _ = Describe("Transport", func() {
+	var (
+		gateway *httptest.Server
+		store   *memoryStore
+		client  *http.Client
+	)
+
+	BeforeEach(func() {
+		gateway = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Set-Cookie", "session=abc")
+			w.WriteHeader(http.StatusAccepted)
+			io.WriteString(w, `{"data":{"id":"gw-1","status":"PENDING","account_number":"1234567890"}}`)
+		}))
+		DeferCleanup(gateway.Close)
+
+		store = &memoryStore{}
+		client = &http.Client{Transport: calllog.New(store, nil, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))}
+	})
+
+	initiate := func(body string) (*http.Response, error) {
+		req, err := http.NewRequest("POST", gateway.URL+"/payments", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer secret-key")
+		return client.Do(req)
+	}
+
+	It("stores the request and the answer with secrets redacted", func() {
+		resp, err := initiate(`{"external_id":"exp-1-1000","amount":1000,"api_key":"secret-key"}`)
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("1234567890"), "the client still gets the whole answer")
+
+		Expect(store.calls).To(HaveLen(1))
+		call := store.calls[0]
+		Expect(call.ExternalID).To(Equal("exp-1-1000"))
+		Expect(call.Method).To(Equal("POST"))
+		Expect(call.URL).To(Equal(gateway.URL + "/payments"))
+		Expect(*call.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(call.Error).To(BeNil())
+		Expect(call.LatencyMS).To(BeNumerically(">=", 0))
+
+		Expect(call.RequestBody).NotTo(ContainSubstring("secret-key"))
+		Expect(call.ResponseBody).NotTo(ContainSubstring("1234567890"))
+		Expect(call.ResponseBody).To(ContainSubstring(cassette.Redacted))
+
+		var requestHeader, responseHeader http.Header
+		Expect(json.Unmarshal(call.RequestHeader, &requestHeader)).To(Succeed())
+		Expect(requestHeader.Get("Authorization")).To(Equal(cassette.Redacted))
+		Expect(json.Unmarshal(call.ResponseHeader, &responseHeader)).To(Succeed())
+		Expect(responseHeader.Get("Set-Cookie")).To(Equal(cassette.Redacted))
+	})
+
+	It("takes a status check's external ID from its query", func() {
+		_, err := client.Get(gateway.URL + "/payments?external_id=exp-2-1000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.calls).To(HaveLen(1))
+		Expect(store.calls[0].ExternalID).To(Equal("exp-2-1000"))
+		Expect(store.calls[0].RequestBody).To(BeEmpty())
+	})
+
+	It("stores calls that got no answer", func() {
+		gateway.Close()
+
+		_, err := initiate(`{"external_id":"exp-3-1000"}`)
+		Expect(err).To(HaveOccurred())
+		Expect(store.calls).To(HaveLen(1))
+		Expect(store.calls[0].StatusCode).To(BeNil())
+		Expect(store.calls[0].Error).NotTo(BeNil())
+	})
+
+	It("cuts long bodies short", func() {
+		client.Transport = calllog.New(store, nil, 16, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		_, err := initiate(`{"external_id":"exp-4-1000","description":"` + strings.Repeat("é", 20) + `"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.calls[0].RequestBody).To(HaveLen(16))
+		Expect(len(store.calls[0].ResponseBody)).To(BeNumerically("<=", 16))
+	})
+
+	It("does not fail the call when it cannot be stored", func() {
+		store.err = errors.New("database down")
+
+		resp, err := initiate(`{"external_id":"exp-5-1000"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+	})
+})
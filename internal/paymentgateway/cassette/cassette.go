@@ -0,0 +1,330 @@
+// Package cassette records the Client's gateway traffic to a file and plays
+// it back, so tests against a real or Postman-mocked gateway can run offline
+// and give the same answers every time. Credentials and account numbers are
+// redacted before anything is written.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type Mode string
+
+const (
+	// ModeRecord sends requests to the gateway and appends each exchange to
+	// the cassette.
+	ModeRecord Mode = "record"
+	// ModeReplay answers requests from the cassette without any network
+	// access.
+	ModeReplay Mode = "replay"
+)
+
+// Redacted replaces secret header and body values on the cassette.
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned in replay mode for a request the cassette
+// holds no unused answer for.
+var ErrNoInteraction = errors.New("no recorded gateway interaction matches the request")
+
+// redactedHeaders and redactedFields are compared case-insensitively.
+var (
+	redactedHeaders = []string{"Authorization", "X-Api-Key", "Api-Key", "Cookie", "Set-Cookie"}
+	redactedFields  = []string{"api_key", "secret", "password", "token", "access_token", "account_number"}
+)
+
+// ignoredFields are left out when matching request bodies: callback_url
+// points at the local webhook, whose port changes between test runs.
+var ignoredFields = []string{"callback_url"}
+
+// Interaction is one recorded request and the gateway's response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is stored without scheme and host so a cassette recorded against
+// one gateway URL replays against another.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper for the gateway client.
+type Recorder struct {
+	mode Mode
+	path string
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New opens the cassette at path. In replay mode the file must exist; in
+// record mode it is created, or appended to when it already exists, and
+// requests go out through next, or http.DefaultTransport when nil.
+func New(mode Mode, path string, next http.RoundTripper) (*Recorder, error) {
+	if path == "" {
+		return nil, errors.New("cassette path is required")
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	r := &Recorder{mode: mode, path: path, next: next}
+	switch mode {
+	case ModeReplay:
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+	case ModeRecord:
+		if err := r.load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q", mode)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+func (r *Recorder) load() error {
+	raw, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return fmt.Errorf("failed to decode cassette %s: %w", r.path, err)
+	}
+	r.interactions = f.Interactions
+	return nil
+}
+
+// Interactions returns the exchanges on the cassette.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := recordRequest(req, body)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+// replay answers with the first unused interaction that matches, so a
+// request made twice gets the answers in the order they were recorded.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.interactions {
+		if r.used[i] || !matches(in.Request, recorded) {
+			continue
+		}
+		r.used[i] = true
+		return in.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gateway response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     RedactHeader(resp.Header),
+			Body:       RedactBody(body),
+		},
+	})
+	r.used = append(r.used, true)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save rewrites the whole cassette after every exchange, so a recording
+// survives the process being stopped at any point.
+func (r *Recorder) save() error {
+	raw, err := json.MarshalIndent(file{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// readBody drains the request body and puts it back for the transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gateway request: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func recordRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Header: RedactHeader(req.Header),
+		Body:   RedactBody(body),
+	}
+}
+
+func matches(recorded, req Request) bool {
+	return recorded.Method == req.Method &&
+		recorded.Path == req.Path &&
+		recorded.Query == req.Query &&
+		comparableBody(recorded.Body) == comparableBody(req.Body)
+}
+
+// comparableBody drops ignoredFields from JSON bodies and re-encodes them,
+// which also sorts their keys. Other bodies are compared as they are.
+func comparableBody(body string) string {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &obj); err != nil {
+		return body
+	}
+	for _, field := range ignoredFields {
+		delete(obj, field)
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return string(raw)
+}
+
+// RedactHeader returns a copy of header with credentials and cookies masked.
+func RedactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	out := header.Clone()
+	for name := range out {
+		for _, secret := range redactedHeaders {
+			if strings.EqualFold(name, secret) {
+				out[name] = []string{Redacted}
+			}
+		}
+	}
+	return out
+}
+
+// RedactBody masks secret fields at any depth of a JSON body. Bodies that
+// are not JSON are kept as they are.
+func RedactBody(body []byte) string {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return string(body)
+	}
+	raw, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(raw)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSecretField(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
+
+func isSecretField(key string) bool {
+	for _, secret := range redactedFields {
+		if strings.EqualFold(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	// The recorded length may no longer hold once the body was redacted.
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
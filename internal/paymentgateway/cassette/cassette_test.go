@@ -0,0 +1,138 @@
+package cassette_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/cassette"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		gateway *httptest.Server
+		path    string
+		hits    int
+	)
+
+	BeforeEach(func() {
+		hits = 0
+		path = filepath.Join(GinkgoT().TempDir(), "gateway.json")
+		gateway = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Set-Cookie", "session=abc")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{
+					"id":          "gw-" + r.URL.Query().Get("external_id"),
+					"external_id": r.URL.Query().Get("external_id"),
+					"status":      "SUCCESS",
+				},
+			})
+		}))
+		DeferCleanup(gateway.Close)
+	})
+
+	newClient := func(transport http.RoundTripper) *paymentgateway.Client {
+		client := paymentgateway.NewClient(paymentgateway.Config{
+			MockAPIURL:     gateway.URL,
+			PaymentTimeout: time.Second,
+			MaxWorkers:     1,
+			Transport:      transport,
+		}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		DeferCleanup(client.Shutdown)
+		return client
+	}
+
+	It("replays a recorded exchange without calling the gateway", func() {
+		recorder, err := cassette.New(cassette.ModeRecord, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		recorded, err := newClient(recorder).GetPaymentStatus("exp-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hits).To(Equal(1))
+
+		gateway.Close()
+		player, err := cassette.New(cassette.ModeReplay, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		replayed, err := newClient(player).GetPaymentStatus("exp-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replayed).To(Equal(recorded))
+		Expect(replayed.Data.ID).To(Equal("gw-exp-1"))
+	})
+
+	It("refuses requests the cassette has no answer for", func() {
+		recorder, err := cassette.New(cassette.ModeRecord, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = newClient(recorder).GetPaymentStatus("exp-1")
+		Expect(err).NotTo(HaveOccurred())
+
+		player, err := cassette.New(cassette.ModeReplay, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		client := newClient(player)
+		_, err = client.GetPaymentStatus("exp-2")
+		Expect(err).To(MatchError(ContainSubstring(cassette.ErrNoInteraction.Error())))
+
+		_, err = client.GetPaymentStatus("exp-1")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetPaymentStatus("exp-1")
+		Expect(err).To(HaveOccurred(), "each exchange answers once")
+		Expect(hits).To(Equal(1))
+	})
+
+	It("matches bodies regardless of key order and the callback URL", func() {
+		Expect(os.WriteFile(path, []byte(`{"interactions":[{
+			"request":{"method":"POST","path":"/payments","body":"{\"callback_url\":\"http://localhost:1/cb\",\"external_id\":\"exp-1\",\"amount\":1000}"},
+			"response":{"status_code":201,"body":"{\"data\":{\"id\":\"gw-1\"}}"}}]}`), 0o644)).To(Succeed())
+		player, err := cassette.New(cassette.ModeReplay, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		req, _ := http.NewRequest(http.MethodPost, "http://gateway.invalid/payments",
+			strings.NewReader(`{"amount":1000,"external_id":"exp-1","callback_url":"http://localhost:2/cb"}`))
+		resp, err := player.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	})
+
+	It("redacts credentials and account numbers on the cassette", func() {
+		recorder, err := cassette.New(cassette.ModeRecord, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		payload, _ := json.Marshal(map[string]interface{}{
+			"external_id": "exp-1",
+			"payout":      paymentgatewaytypes.Payout{BankCode: "BCA", AccountNumber: "1234567890"},
+		})
+		req, _ := http.NewRequest(http.MethodPost, gateway.URL+"/payments?external_id=exp-1", strings.NewReader(string(payload)))
+		req.Header.Set("Authorization", "Bearer live-secret")
+		req.Header.Set("X-API-Key", "live-key")
+		resp, err := recorder.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+
+		raw, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(raw)).NotTo(ContainSubstring("live-secret"))
+		Expect(string(raw)).NotTo(ContainSubstring("live-key"))
+		Expect(string(raw)).NotTo(ContainSubstring("1234567890"))
+		Expect(string(raw)).NotTo(ContainSubstring("session=abc"))
+
+		in := recorder.Interactions()[0]
+		Expect(in.Request.Header.Get("Authorization")).To(Equal(cassette.Redacted))
+		Expect(in.Request.Body).To(ContainSubstring(`"bank_code":"BCA"`))
+	})
+
+	It("requires an existing cassette to replay", func() {
+		_, err := cassette.New(cassette.ModeReplay, path, nil)
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
@@ -5,20 +5,65 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
-	"math/rand"
 	"net/http"
+	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/core/httpclient"
+	"github.com/frahmantamala/expense-management/internal/core/metrics"
+	"github.com/frahmantamala/expense-management/internal/core/money"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/webhooksig"
 )
 
 type PaymentJob struct {
 	ExternalID string
 	Amount     int64
 	PaymentID  string
+	WorkerID   int
+	// Provider is the name of the provider the payment was initiated with;
+	// empty until initiation succeeds.
+	Provider string
+	// Payout is needed to re-initiate a payment whose first initiation
+	// failed. It holds the plain account number, so it is never spilled.
+	Payout *paymentgatewaytypes.Payout
+	// Attempts counts the workers that have picked the job up. It only grows
+	// past one when a run panics and the job is re-queued.
+	Attempts int
+}
+
+// JobRecorder receives lifecycle transitions of queued payment jobs so they
+// can be persisted and exposed to callers polling for progress.
+type JobRecorder interface {
+	JobQueued(externalID string)
+	JobStarted(externalID string, workerID int)
+	JobCompleted(externalID string)
+	JobFailed(externalID string, reason string)
+}
+
+type noopJobRecorder struct{}
+
+func (noopJobRecorder) JobQueued(string)         {}
+func (noopJobRecorder) JobStarted(string, int)   {}
+func (noopJobRecorder) JobCompleted(string)      {}
+func (noopJobRecorder) JobFailed(string, string) {}
+
+// DeadLetterRecorder is told about jobs given up on after crashing a worker
+// PoisonThreshold times, so they can be set aside for investigation. When
+// the JobRecorder does not implement it, such jobs are recorded as failed.
+type DeadLetterRecorder interface {
+	JobDeadLettered(externalID string, attempts int, reason string)
+}
+
+// CancellationChecker is asked before each job runs whether it was
+// cancelled while queued; cancelled jobs are dropped without being recorded.
+type CancellationChecker interface {
+	JobCancelled(externalID string) bool
 }
 
 type Worker struct {
@@ -26,6 +71,10 @@ type Worker struct {
 	WorkerPool chan chan PaymentJob
 	JobChannel chan PaymentJob
 	Logger     *slog.Logger
+	// OnPanic is called with a job whose run panicked, after the worker has
+	// recovered; the worker then carries on with the next job.
+	OnPanic func(job PaymentJob, recovered interface{})
+	quit    chan struct{}
 }
 
 func NewWorker(id int, workerPool chan chan PaymentJob, logger *slog.Logger) *Worker {
@@ -34,6 +83,7 @@ func NewWorker(id int, workerPool chan chan PaymentJob, logger *slog.Logger) *Wo
 		WorkerPool: workerPool,
 		JobChannel: make(chan PaymentJob),
 		Logger:     logger,
+		quit:       make(chan struct{}),
 	}
 }
 
@@ -44,12 +94,25 @@ func (w *Worker) Start(ctx context.Context, wg *sync.WaitGroup, processFunc func
 
 		for {
 
-			w.WorkerPool <- w.JobChannel
+			select {
+			case w.WorkerPool <- w.JobChannel:
+			case <-w.quit:
+				w.Logger.Debug("worker stopped", "worker_id", w.ID)
+				return
+			case <-ctx.Done():
+				w.Logger.Debug("worker shutting down", "worker_id", w.ID)
+				return
+			}
 
 			select {
 			case job := <-w.JobChannel:
 				w.Logger.Debug("worker processing job", "worker_id", w.ID, "external_id", job.ExternalID)
-				processFunc(job)
+				job.WorkerID = w.ID
+				job.Attempts++
+				w.process(job, processFunc)
+			case <-w.quit:
+				w.Logger.Debug("worker stopped", "worker_id", w.ID)
+				return
 			case <-ctx.Done():
 				w.Logger.Debug("worker shutting down", "worker_id", w.ID)
 				return
@@ -58,33 +121,124 @@ func (w *Worker) Start(ctx context.Context, wg *sync.WaitGroup, processFunc func
 	}()
 }
 
+// process runs one job, recovering a panic so that a bad job cannot take
+// the worker down with it.
+func (w *Worker) process(job PaymentJob, processFunc func(PaymentJob)) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.Logger.Error("payment job panicked",
+				"worker_id", w.ID,
+				"external_id", job.ExternalID,
+				"attempt", job.Attempts,
+				"panic", r,
+				"stack", string(debug.Stack()))
+			if w.OnPanic != nil {
+				w.OnPanic(job, r)
+			}
+		}
+	}()
+	processFunc(job)
+}
+
+// Stop terminates an idle worker. Callers must first take the worker's
+// JobChannel out of the pool so the dispatcher cannot hand it another job.
+func (w *Worker) Stop() {
+	close(w.quit)
+}
+
 type Client struct {
-	mockAPIURL     string
-	apiKey         string
+	providers      []*provider
+	failover       FailoverConfig
 	webhookURL     string
+	webhookSigner  *webhooksig.Signer
 	paymentTimeout time.Duration
+	statusTimeout  time.Duration
+	hedge          HedgeConfig
+	transport      http.RoundTripper
+	http           *httpclient.Factory
 	logger         *slog.Logger
-
-	jobQueue   chan PaymentJob
-	workerPool chan chan PaymentJob
-	maxWorkers int
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
-	once       sync.Once
+	recorder       JobRecorder
+	routes         ProviderRecorder
+	spill          SpillStore
+	deadLetters    DeadLetterRecorder
+	cancellations  CancellationChecker
+
+	jobQueue        chan PaymentJob
+	workerPool      chan chan PaymentJob
+	minWorkers      int
+	maxWorkers      int
+	overflow        OverflowStrategy
+	enqueueTimeout  time.Duration
+	scaleInterval   time.Duration
+	poisonThreshold int
+
+	mu           sync.Mutex
+	workers      map[chan PaymentJob]*Worker
+	nextWorkerID int
+
+	busyWorkers  atomic.Int64
+	enqueued     atomic.Int64
+	spilled      atomic.Int64
+	shed         atomic.Int64
+	panics       atomic.Int64
+	requeued     atomic.Int64
+	deadLettered atomic.Int64
+	statusChecks statusCheckCounters
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
 }
 
 type Config struct {
-	MockAPIURL     string
-	APIKey         string
-	WebhookURL     string
-	PaymentTimeout time.Duration
-	MaxWorkers     int
-	JobQueueSize   int
-	WorkerPoolSize int
+	// MockAPIURL, APIKey and Driver describe the only provider when
+	// Providers is empty.
+	MockAPIURL       string
+	APIKey           string
+	WebhookURL       string
+	PaymentTimeout   time.Duration
+	MinWorkers       int
+	MaxWorkers       int
+	JobQueueSize     int
+	WorkerPoolSize   int
+	OverflowStrategy OverflowStrategy
+	EnqueueTimeout   time.Duration
+	ScaleInterval    time.Duration
+	// PoisonThreshold is how many runs of one job may panic before it is
+	// dead-lettered instead of re-queued; zero means 3.
+	PoisonThreshold int
+	// Driver settles initiated jobs; nil waits for the gateway's own callback.
+	Driver Driver
+	// Providers are tried in order; a payment fails over to the next one
+	// when initiation fails or the provider's circuit is open.
+	Providers []Provider
+	Failover  FailoverConfig
+	// Transport carries requests to the gateways, such as a cassette
+	// recorder; nil uses http.DefaultTransport. Webhook callbacks do not go
+	// through it.
+	Transport http.RoundTripper
+	// HTTP builds the clients for gateway requests and webhook callbacks;
+	// nil uses httpclient.Default.
+	HTTP    *httpclient.Factory
+	Budgets LatencyBudgets
+	Hedge   HedgeConfig
+	// WebhookSecret signs the callbacks the client sends to WebhookURL
+	// itself, so the webhook can tell them from forged ones.
+	WebhookSecret string
 }
 
-func NewClient(config Config, logger *slog.Logger) *Client {
+// NewClient starts the worker pool. When recorder also implements SpillStore
+// it is used as the persistent queue for the spill overflow strategy, when
+// it implements ProviderRecorder it is told which provider each payment was
+// initiated with, when it implements DeadLetterRecorder it is told about
+// poison jobs, and when it implements CancellationChecker cancelled jobs are
+// skipped.
+func NewClient(config Config, recorder JobRecorder, logger *slog.Logger) *Client {
+	if recorder == nil {
+		recorder = noopJobRecorder{}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	maxWorkers := config.MaxWorkers
@@ -92,6 +246,11 @@ func NewClient(config Config, logger *slog.Logger) *Client {
 		maxWorkers = 10
 	}
 
+	minWorkers := config.MinWorkers
+	if minWorkers <= 0 || minWorkers > maxWorkers {
+		minWorkers = maxWorkers
+	}
+
 	jobQueueSize := config.JobQueueSize
 	if jobQueueSize <= 0 {
 		jobQueueSize = 100
@@ -102,44 +261,188 @@ func NewClient(config Config, logger *slog.Logger) *Client {
 		workerPoolSize = maxWorkers
 	}
 
+	overflow := config.OverflowStrategy
+	if overflow == "" {
+		overflow = OverflowShed
+	}
+
+	enqueueTimeout := config.EnqueueTimeout
+	if enqueueTimeout <= 0 {
+		enqueueTimeout = 2 * time.Second
+	}
+
+	scaleInterval := config.ScaleInterval
+	if scaleInterval <= 0 {
+		scaleInterval = 5 * time.Second
+	}
+
+	paymentTimeout := config.PaymentTimeout
+	if config.Budgets.Initiate > 0 {
+		paymentTimeout = config.Budgets.Initiate
+	}
+	statusTimeout := config.Budgets.Status
+	if statusTimeout <= 0 {
+		statusTimeout = config.PaymentTimeout
+	}
+
+	poisonThreshold := config.PoisonThreshold
+	if poisonThreshold <= 0 {
+		poisonThreshold = 3
+	}
+
+	configured := config.Providers
+	if len(configured) == 0 {
+		configured = []Provider{{
+			Name:   DefaultProviderName,
+			APIURL: config.MockAPIURL,
+			APIKey: config.APIKey,
+			Driver: config.Driver,
+		}}
+	}
+	providers := make([]*provider, len(configured))
+	for i, p := range configured {
+		if p.Driver == nil {
+			p.Driver = gatewayDriver{}
+		}
+		providers[i] = &provider{Provider: p}
+	}
+
+	failover := config.Failover
+	if failover.FailureThreshold <= 0 {
+		failover.FailureThreshold = 5
+	}
+	if failover.OpenDuration <= 0 {
+		failover.OpenDuration = 30 * time.Second
+	}
+
 	client := &Client{
-		mockAPIURL:     config.MockAPIURL,
-		apiKey:         config.APIKey,
+		providers:      providers,
+		failover:       failover,
 		webhookURL:     config.WebhookURL,
-		paymentTimeout: config.PaymentTimeout,
+		webhookSigner:  webhooksig.New(config.WebhookSecret),
+		paymentTimeout: paymentTimeout,
+		statusTimeout:  statusTimeout,
+		hedge:          config.Hedge,
+		transport:      config.Transport,
+		http:           config.HTTP,
 		logger:         logger,
+		recorder:       recorder,
 
-		maxWorkers: maxWorkers,
-		jobQueue:   make(chan PaymentJob, jobQueueSize),
-		workerPool: make(chan chan PaymentJob, workerPoolSize),
-		ctx:        ctx,
-		cancel:     cancel,
+		minWorkers:      minWorkers,
+		maxWorkers:      maxWorkers,
+		overflow:        overflow,
+		enqueueTimeout:  enqueueTimeout,
+		scaleInterval:   scaleInterval,
+		poisonThreshold: poisonThreshold,
+		jobQueue:        make(chan PaymentJob, jobQueueSize),
+		workerPool:      make(chan chan PaymentJob, workerPoolSize),
+		workers:         make(map[chan PaymentJob]*Worker),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+
+	if spill, ok := recorder.(SpillStore); ok {
+		client.spill = spill
+	}
+	if routes, ok := recorder.(ProviderRecorder); ok {
+		client.routes = routes
+	}
+	if deadLetters, ok := recorder.(DeadLetterRecorder); ok {
+		client.deadLetters = deadLetters
+	}
+	if cancellations, ok := recorder.(CancellationChecker); ok {
+		client.cancellations = cancellations
 	}
 
 	client.startWorkerPool()
 
+	metrics.Register("payment_gateway_queue", func() interface{} {
+		return client.Stats()
+	})
+	metrics.Register("payment_gateway_providers", func() interface{} {
+		return client.ProviderStats()
+	})
+	metrics.Register("payment_gateway_status_checks", func() interface{} {
+		return client.StatusCheckStats()
+	})
+
 	return client
 }
 
 func (c *Client) startWorkerPool() {
 	c.once.Do(func() {
 
-		for i := 0; i < c.maxWorkers; i++ {
-			worker := NewWorker(i, c.workerPool, c.logger)
-			worker.Start(c.ctx, &c.wg, c.processPaymentJob)
+		c.mu.Lock()
+		for i := 0; i < c.minWorkers; i++ {
+			c.startWorkerLocked()
 		}
+		c.mu.Unlock()
 
+		c.wg.Add(2)
 		go c.dispatch()
+		go c.autoscale()
 
 		c.logger.Info("payment gateway worker pool started",
+			"min_workers", c.minWorkers,
 			"max_workers", c.maxWorkers,
-			"queue_size", cap(c.jobQueue))
+			"queue_size", cap(c.jobQueue),
+			"overflow_strategy", c.overflow,
+			"providers", c.providerNames())
 	})
 }
 
+func (c *Client) startWorkerLocked() {
+	worker := NewWorker(c.nextWorkerID, c.workerPool, c.logger)
+	worker.OnPanic = c.jobPanicked
+	c.nextWorkerID++
+	c.workers[worker.JobChannel] = worker
+	worker.Start(c.ctx, &c.wg, c.runJob)
+}
+
+func (c *Client) runJob(job PaymentJob) {
+	c.busyWorkers.Add(1)
+	defer c.busyWorkers.Add(-1)
+	c.processPaymentJob(job)
+}
+
+// jobPanicked re-queues a job whose run panicked, or dead-letters it once it
+// has crashed poisonThreshold workers. A dead-lettered payment stays pending
+// for reconciliation: the panic may have come after the gateway took it, so
+// it is never reported as failed to the webhook.
+func (c *Client) jobPanicked(job PaymentJob, recovered interface{}) {
+	c.panics.Add(1)
+	reason := fmt.Sprintf("payment job panicked: %v", recovered)
+
+	if job.Attempts >= c.poisonThreshold {
+		c.deadLettered.Add(1)
+		c.logger.Error("payment job dead-lettered after repeated panics",
+			"external_id", job.ExternalID,
+			"attempts", job.Attempts)
+		if c.deadLetters != nil {
+			c.deadLetters.JobDeadLettered(job.ExternalID, job.Attempts, reason)
+			return
+		}
+		c.recorder.JobFailed(job.ExternalID, reason)
+		return
+	}
+
+	// Re-queued jobs skip the overflow strategy: a spilled job would lose
+	// its attempt count and could crash workers forever.
+	c.recorder.JobQueued(job.ExternalID)
+	select {
+	case c.jobQueue <- job:
+		c.requeued.Add(1)
+		c.logger.Warn("payment job re-queued after panic",
+			"external_id", job.ExternalID,
+			"attempts", job.Attempts)
+	default:
+		c.logger.Error("payment queue full, could not re-queue panicked job", "external_id", job.ExternalID)
+		c.recorder.JobFailed(job.ExternalID, reason)
+	}
+}
+
 func (c *Client) dispatch() {
 	defer c.wg.Done()
-	c.wg.Add(1)
 
 	for {
 		select {
@@ -181,16 +484,16 @@ func (c *Client) ProcessPayment(req *paymentgatewaytypes.PaymentRequest) (*payme
 
 	c.logger.Info("postman: initiating async payment processing",
 		"external_id", req.ExternalID,
-		"amount", req.Amount,
-		"api_url", c.mockAPIURL)
+		"amount", req.Amount)
 
-	paymentID, err := c.initiatePaymentWithPostman(req)
+	initiated, providerName, err := c.initiate(req)
+	paymentID := fmt.Sprintf("postman_%s", req.ExternalID)
 	if err != nil {
 		c.logger.Warn("payment initiation failed, will handle in background worker",
 			"external_id", req.ExternalID,
 			"error", err)
-
-		paymentID = fmt.Sprintf("postman_%s", req.ExternalID)
+	} else {
+		paymentID = initiated.ID
 	}
 
 	resp := &paymentgatewaytypes.PaymentResponse{
@@ -198,32 +501,115 @@ func (c *Client) ProcessPayment(req *paymentgatewaytypes.PaymentRequest) (*payme
 			ID:         paymentID,
 			ExternalID: req.ExternalID,
 			Status:     paymentgatewaytypes.PaymentStatusPending,
+			Provider:   providerName,
 		},
 	}
+	if initiated != nil {
+		resp.Data.ResponseVersion = initiated.ResponseVersion
+		resp.Data.Raw = initiated.Raw
+	}
 
 	job := PaymentJob{
 		ExternalID: req.ExternalID,
 		Amount:     req.Amount,
 		PaymentID:  paymentID,
+		Payout:     req.Payout,
+		Provider:   providerName,
 	}
 
-	select {
-	case c.jobQueue <- job:
-		c.logger.Info("postman: payment job queued for processing",
-			"external_id", req.ExternalID,
-			"payment_id", resp.Data.ID,
-			"queue_length", len(c.jobQueue))
-	default:
+	c.recorder.JobQueued(req.ExternalID)
+	c.recordRoute(req.ExternalID, providerName)
+
+	if err := c.enqueue(job); err != nil {
 		c.logger.Warn("postman: job queue full, rejecting payment",
 			"external_id", req.ExternalID,
-			"queue_capacity", cap(c.jobQueue))
-		return nil, fmt.Errorf("payment queue full, please try again later")
+			"queue_capacity", cap(c.jobQueue),
+			"overflow_strategy", c.overflow)
+		c.recorder.JobFailed(req.ExternalID, "payment queue full")
+		return nil, err
 	}
 
+	c.logger.Info("postman: payment job accepted for processing",
+		"external_id", req.ExternalID,
+		"payment_id", resp.Data.ID,
+		"queue_length", len(c.jobQueue))
+
 	return resp, nil
 }
 
-func (c *Client) initiatePaymentWithPostman(req *paymentgatewaytypes.PaymentRequest) (string, error) {
+// initiate sends the payment to the first provider that accepts it, skipping
+// providers whose circuit is open, and returns its answer and name.
+func (c *Client) initiate(req *paymentgatewaytypes.PaymentRequest) (*paymentgatewaytypes.PaymentData, string, error) {
+	var lastErr error
+	for _, p := range c.providers {
+		if !p.allow(time.Now()) {
+			c.logger.Debug("payment provider circuit open, skipping",
+				"provider", p.Name,
+				"external_id", req.ExternalID)
+			continue
+		}
+
+		data, err := c.initiatePaymentWithPostman(p, req)
+		if err == nil {
+			p.succeeded()
+			return data, p.Name, nil
+		}
+
+		lastErr = fmt.Errorf("%s: %w", p.Name, err)
+		if p.failed(time.Now(), c.failover) {
+			c.logger.Warn("payment provider circuit opened",
+				"provider", p.Name,
+				"open_for", c.failover.OpenDuration,
+				"error", err)
+		}
+		c.logger.Warn("payment initiation failed, failing over",
+			"provider", p.Name,
+			"external_id", req.ExternalID,
+			"error", err)
+	}
+
+	if lastErr == nil {
+		return nil, "", ErrNoProviderAvailable
+	}
+	return nil, "", lastErr
+}
+
+func (c *Client) recordRoute(externalID, providerName string) {
+	if c.routes != nil && providerName != "" {
+		c.routes.JobRouted(externalID, providerName)
+	}
+}
+
+// provider returns the named provider, or the first one for jobs restored
+// from the spill store before a provider was recorded.
+func (c *Client) provider(name string) *provider {
+	for _, p := range c.providers {
+		if p.Name == name {
+			return p
+		}
+	}
+	return c.providers[0]
+}
+
+func (c *Client) providerNames() []string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// ProviderStats reports each provider's circuit state, in failover order.
+func (c *Client) ProviderStats() []ProviderStats {
+	now := time.Now()
+	stats := make([]ProviderStats, len(c.providers))
+	for i, p := range c.providers {
+		stats[i] = p.stats(now)
+	}
+	return stats
+}
+
+func (c *Client) initiatePaymentWithPostman(p *provider, req *paymentgatewaytypes.PaymentRequest) (*paymentgatewaytypes.PaymentData, error) {
 
 	payload := map[string]interface{}{
 		"external_id":  req.ExternalID,
@@ -232,131 +618,233 @@ func (c *Client) initiatePaymentWithPostman(req *paymentgatewaytypes.PaymentRequ
 		"description":  "Payment processing",
 		"callback_url": c.webhookURL,
 	}
+	if req.Payout != nil {
+		payload["payout"] = req.Payout
+	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal payment request: %w", err)
+		return nil, fmt.Errorf("failed to marshal payment request: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), c.paymentTimeout)
 	defer cancel()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.mockAPIURL+"/payments", bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.APIURL+"/payments", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: c.paymentTimeout}
-	resp, err := client.Do(httpReq)
+	resp, err := c.gatewayClient(c.paymentTimeout).Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("Postman API returned status %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
-	var apiResponse struct {
-		Data struct {
-			ID         string `json:"id"`
-			ExternalID string `json:"external_id"`
-			Status     string `json:"status"`
-		} `json:"data"`
+	data, err := c.decodeResponse(p, resp.Body, req.ExternalID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	c.logger.Info("payment initiated with Postman API",
+		"provider", p.Name,
+		"payment_id", data.ID,
+		"external_id", data.ExternalID,
+		"status", data.Status,
+		"response_version", data.ResponseVersion)
+
+	return data, nil
+}
+
+// decodeResponse decodes a provider's answer about the payment externalID
+// with the provider's adapter. An answer that leaves out the external_id is
+// taken to be about externalID. One naming another payment is only logged:
+// canned mock gateways answer every request with the same external_id.
+func (c *Client) decodeResponse(p *provider, r io.Reader, externalID string) (*paymentgatewaytypes.PaymentData, error) {
+	body, err := readResponse(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.response().Decode(body)
+	if err != nil {
+		c.logger.Warn("undecodable gateway response",
+			"provider", p.Name,
+			"external_id", externalID,
+			"error", err,
+			"bytes", len(body))
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	switch data.ExternalID {
+	case "":
+		data.ExternalID = externalID
+	case externalID:
+	default:
+		c.logger.Warn("gateway response names another payment",
+			"provider", p.Name,
+			"external_id", externalID,
+			"response_external_id", data.ExternalID)
 	}
+	return data, nil
+}
 
-	c.logger.Info("payment initiated with Postman API",
-		"payment_id", apiResponse.Data.ID,
-		"external_id", apiResponse.Data.ExternalID,
-		"status", apiResponse.Data.Status)
+func (c *Client) httpClients() *httpclient.Factory {
+	if c.http == nil {
+		return httpclient.Default()
+	}
+	return c.http
+}
 
-	return apiResponse.Data.ID, nil
+// gatewayClient carries requests to providers, through Config.Transport
+// when one is set. A zero timeout leaves the request to its context.
+func (c *Client) gatewayClient(timeout time.Duration) *http.Client {
+	client := c.httpClients().Client(timeout)
+	if timeout <= 0 {
+		client.Timeout = 0
+	}
+	if c.transport != nil {
+		client.Transport = c.transport
+	}
+	return client
 }
 
 func (c *Client) processPaymentJob(job PaymentJob) {
-	c.logger.Info("processing payment job", "external_id", job.ExternalID)
+	if c.cancellations != nil && c.cancellations.JobCancelled(job.ExternalID) {
+		c.logger.Info("skipping cancelled payment job", "external_id", job.ExternalID, "worker_id", job.WorkerID)
+		return
+	}
+
+	c.logger.Info("processing payment job", "external_id", job.ExternalID, "worker_id", job.WorkerID)
+	c.recorder.JobStarted(job.ExternalID, job.WorkerID)
 
 	isFallback := strings.HasPrefix(job.PaymentID, "postman_")
 
 	var status paymentgatewaytypes.PaymentStatus
 	var failureReason string
+	var failureClass string
+	var initiationFailed bool
 
 	if isFallback {
 
 		c.logger.Info("retrying payment initiation", "external_id", job.ExternalID)
 
-		req := &paymentgatewaytypes.PaymentRequest{
-			Amount:     job.Amount,
-			ExternalID: job.ExternalID,
-		}
+		req := paymentgatewaytypes.NewPaymentRequest(job.ExternalID, money.Rupiah(job.Amount), job.Payout)
 
-		realPaymentID, err := c.initiatePaymentWithPostman(req)
+		initiated, providerName, err := c.initiate(req)
 		if err != nil {
 
 			status = paymentgatewaytypes.PaymentStatusFailed
 			failureReason = fmt.Sprintf("Payment initiation failed: %v", err)
+			failureClass = Classify(err)
+			initiationFailed = true
 			c.logger.Error("payment initiation retry failed",
 				"external_id", job.ExternalID,
+				"failure_class", failureClass,
 				"error", err)
 		} else {
 
-			job.PaymentID = realPaymentID
+			job.PaymentID = initiated.ID
+			job.Provider = providerName
+			c.recordRoute(job.ExternalID, providerName)
 			c.logger.Info("payment initiation retry successful",
 				"external_id", job.ExternalID,
-				"payment_id", realPaymentID)
+				"provider", providerName,
+				"payment_id", initiated.ID)
 		}
 	}
 
 	if status == "" {
-
-		delay := time.Duration(1+rand.Intn(4)) * time.Second
-
-		select {
-		case <-time.After(delay):
-
-		case <-c.ctx.Done():
-			c.logger.Info("payment job cancelled", "external_id", job.ExternalID)
+		driver := c.provider(job.Provider).Driver
+		settlement, settled := driver.Settle(c.ctx, job)
+		if !settled {
+			if c.ctx.Err() != nil {
+				c.logger.Info("payment job cancelled", "external_id", job.ExternalID)
+				c.recorder.JobFailed(job.ExternalID, "payment job cancelled")
+				return
+			}
+			c.logger.Info("payment handed to gateway, awaiting its callback",
+				"external_id", job.ExternalID,
+				"payment_id", job.PaymentID)
+			c.recorder.JobCompleted(job.ExternalID)
 			return
 		}
 
-		if rand.Float32() < 0.9 {
-			status = paymentgatewaytypes.PaymentStatusSuccess
-			c.logger.Info("postman simulation: payment successful",
-				"external_id", job.ExternalID,
-				"delay_seconds", delay.Seconds())
-		} else {
-			status = paymentgatewaytypes.PaymentStatusFailed
-			failureReason = "Insufficient funds"
-			c.logger.Info("postman simulation: payment failed",
-				"external_id", job.ExternalID,
-				"reason", failureReason,
-				"delay_seconds", delay.Seconds())
-		}
+		status = settlement.Status
+		failureReason = settlement.FailureReason
+		c.logger.Info("payment settled by driver",
+			"driver", driver.Name(),
+			"provider", job.Provider,
+			"external_id", job.ExternalID,
+			"status", status)
+	}
+
+	if err := c.sendCallbackToWebhook(job.ExternalID, status, job.Amount, job.PaymentID, failureReason, failureClass); err != nil {
+		c.recorder.JobFailed(job.ExternalID, err.Error())
+		return
 	}
 
-	c.sendCallbackToWebhook(job.ExternalID, status, job.Amount, job.PaymentID, failureReason)
+	if initiationFailed {
+		c.recorder.JobFailed(job.ExternalID, failureReason)
+		return
+	}
+
+	c.recorder.JobCompleted(job.ExternalID)
 }
 
+// GetPaymentStatus asks each provider in turn, since the payment may have
+// been failed over, and returns the first answer.
 func (c *Client) GetPaymentStatus(externalID string) (*paymentgatewaytypes.PaymentResponse, error) {
 	c.logger.Info("postman: getting payment status", "external_id", externalID)
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.paymentTimeout)
+	var lastErr error
+	for _, p := range c.providers {
+		resp, err := c.getPaymentStatus(p, externalID)
+		if err == nil {
+			resp.Data.Provider = p.Name
+			return resp, nil
+		}
+		lastErr = fmt.Errorf("%s: %w", p.Name, err)
+	}
+	return nil, lastErr
+}
+
+// getPaymentStatus asks one provider within the status budget, hedging the
+// request when configured.
+func (c *Client) getPaymentStatus(p *provider, externalID string) (*paymentgatewaytypes.PaymentResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.statusTimeout)
 	defer cancel()
+	c.statusChecks.checks.Add(1)
 
-	url := fmt.Sprintf("%s/payments?external_id=%s", c.mockAPIURL, externalID)
+	var resp *paymentgatewaytypes.PaymentResponse
+	var err error
+	if c.hedge.Delay > 0 {
+		resp, err = c.hedgedPaymentStatus(ctx, p, externalID)
+	} else {
+		resp, err = c.fetchPaymentStatus(ctx, p, externalID)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		c.statusChecks.budgetExceeded.Add(1)
+		c.logger.Warn("status check exceeded its latency budget",
+			"provider", p.Name,
+			"external_id", externalID,
+			"budget", c.statusTimeout)
+	}
+	return resp, err
+}
+
+func (c *Client) fetchPaymentStatus(ctx context.Context, p *provider, externalID string) (*paymentgatewaytypes.PaymentResponse, error) {
+	url := fmt.Sprintf("%s/payments?external_id=%s", p.APIURL, externalID)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.gatewayClient(0).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment status: %w", err)
 	}
@@ -366,25 +854,25 @@ func (c *Client) GetPaymentStatus(externalID string) (*paymentgatewaytypes.Payme
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	var apiResponse struct {
-		Data paymentgatewaytypes.PaymentData `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	data, err := c.decodeResponse(p, resp.Body, externalID)
+	if err != nil {
+		return nil, err
 	}
 
 	return &paymentgatewaytypes.PaymentResponse{
-		Data: apiResponse.Data,
+		Data: *data,
 	}, nil
 }
 
-func (c *Client) sendCallbackToWebhook(externalID string, status paymentgatewaytypes.PaymentStatus, amount int64, paymentID string, failureReason string) {
+// sendCallbackToWebhook reports a payment's outcome as the gateway would.
+// failureClass, when known, says whether a failed initiation is worth
+// retrying.
+func (c *Client) sendCallbackToWebhook(externalID string, status paymentgatewaytypes.PaymentStatus, amount int64, paymentID string, failureReason, failureClass string) error {
 
 	select {
 	case <-c.ctx.Done():
 		c.logger.Info("webhook callback cancelled", "external_id", externalID)
-		return
+		return fmt.Errorf("webhook callback cancelled")
 	default:
 
 	}
@@ -399,14 +887,17 @@ func (c *Client) sendCallbackToWebhook(externalID string, status paymentgatewayt
 	if failureReason != "" {
 		callbackPayload["failure_reason"] = failureReason
 	}
+	if failureClass != "" {
+		callbackPayload["failure_class"] = failureClass
+	}
 
 	jsonData, err := json.Marshal(callbackPayload)
 	if err != nil {
-		c.logger.Error("postman simulation: failed to marshal callback", "error", err)
-		return
+		c.logger.Error("failed to marshal callback", "error", err)
+		return fmt.Errorf("failed to marshal callback: %w", err)
 	}
 
-	c.logger.Info("postman simulation: sending webhook callback",
+	c.logger.Info("sending webhook callback",
 		"external_id", externalID,
 		"status", status,
 		"webhook_url", c.webhookURL)
@@ -416,30 +907,32 @@ func (c *Client) sendCallbackToWebhook(externalID string, status paymentgatewayt
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.webhookURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		c.logger.Error("postman simulation: failed to create webhook request",
+		c.logger.Error("failed to create webhook request",
 			"error", err,
 			"external_id", externalID)
-		return
+		return fmt.Errorf("failed to create webhook request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	c.webhookSigner.SignRequest(req, jsonData, time.Now())
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.httpClients().Client(10 * time.Second).Do(req)
 	if err != nil {
-		c.logger.Error("postman simulation: webhook callback failed",
+		c.logger.Error("webhook callback failed",
 			"error", err,
 			"external_id", externalID)
-		return
+		return fmt.Errorf("webhook callback failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusOK {
-		c.logger.Info("postman simulation: webhook callback successful",
-			"external_id", externalID,
-			"status_code", resp.StatusCode)
-	} else {
-		c.logger.Warn("postman simulation: webhook callback error",
+		c.logger.Info("webhook callback successful",
 			"external_id", externalID,
 			"status_code", resp.StatusCode)
+		return nil
 	}
+
+	c.logger.Warn("webhook callback error",
+		"external_id", externalID,
+		"status_code", resp.StatusCode)
+	return fmt.Errorf("webhook callback returned status %d", resp.StatusCode)
 }
@@ -0,0 +1,456 @@
+package paymentgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/mockgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/webhooksig"
+)
+
+const webhookSecret = "test-webhook-secret-0123456789abcdef"
+
+type recordingJobRecorder struct {
+	mu           sync.Mutex
+	completed    []string
+	failed       []string
+	deadLettered map[string]int
+	routes       map[string]string
+	cancelled    map[string]bool
+}
+
+func (r *recordingJobRecorder) JobQueued(string)       {}
+func (r *recordingJobRecorder) JobStarted(string, int) {}
+
+func (r *recordingJobRecorder) JobCompleted(externalID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed = append(r.completed, externalID)
+}
+
+func (r *recordingJobRecorder) JobFailed(externalID, _ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed = append(r.failed, externalID)
+}
+
+func (r *recordingJobRecorder) JobDeadLettered(externalID string, attempts int, _ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deadLettered == nil {
+		r.deadLettered = map[string]int{}
+	}
+	r.deadLettered[externalID] = attempts
+}
+
+func (r *recordingJobRecorder) DeadLettered() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dead := make(map[string]int, len(r.deadLettered))
+	for id, attempts := range r.deadLettered {
+		dead[id] = attempts
+	}
+	return dead
+}
+
+func (r *recordingJobRecorder) JobRouted(externalID, provider string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.routes == nil {
+		r.routes = map[string]string{}
+	}
+	r.routes[externalID] = provider
+}
+
+func (r *recordingJobRecorder) Route(externalID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.routes[externalID]
+}
+
+func (r *recordingJobRecorder) JobCancelled(externalID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cancelled[externalID]
+}
+
+func (r *recordingJobRecorder) Completed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.completed...)
+}
+
+var _ = Describe("Client drivers", func() {
+	var (
+		gatewayServer *httptest.Server
+		webhookServer *httptest.Server
+		callbacks     chan map[string]interface{}
+		recorder      *recordingJobRecorder
+		logger        *slog.Logger
+	)
+
+	BeforeEach(func() {
+		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		recorder = &recordingJobRecorder{}
+		callbacks = make(chan map[string]interface{}, 4)
+
+		gatewayServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"id": "gw-1", "external_id": "exp-1-1000", "status": "PENDING"},
+			})
+		}))
+
+		webhookServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, _ := io.ReadAll(r.Body)
+			if err := webhooksig.New(webhookSecret).Verify(r.Header, raw, time.Now()); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body map[string]interface{}
+			json.Unmarshal(raw, &body)
+			callbacks <- body
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		gatewayServer.Close()
+		webhookServer.Close()
+	})
+
+	newClient := func(driver paymentgateway.Driver) *paymentgateway.Client {
+		client := paymentgateway.NewClient(paymentgateway.Config{
+			MockAPIURL:     gatewayServer.URL,
+			WebhookURL:     webhookServer.URL,
+			PaymentTimeout: time.Second,
+			MaxWorkers:     1,
+			Driver:         driver,
+			WebhookSecret:  webhookSecret,
+		}, recorder, logger)
+		DeferCleanup(client.Shutdown)
+		return client
+	}
+
+	process := func(client *paymentgateway.Client) {
+		_, err := client.ProcessPayment(&paymentgatewaytypes.PaymentRequest{ExternalID: "exp-1-1000", Amount: 1000, Currency: "IDR"})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("reports a forced success through the webhook", func() {
+		process(newClient(mockgateway.Succeed()))
+
+		var callback map[string]interface{}
+		Eventually(callbacks).Should(Receive(&callback))
+		Expect(callback["status"]).To(Equal("SUCCESS"))
+		Expect(callback["gateway_payment_id"]).To(Equal("gw-1"))
+		Eventually(recorder.Completed).Should(ConsistOf("exp-1-1000"))
+	})
+
+	It("is refused by a webhook that has another secret", func() {
+		client := paymentgateway.NewClient(paymentgateway.Config{
+			MockAPIURL:     gatewayServer.URL,
+			WebhookURL:     webhookServer.URL,
+			PaymentTimeout: time.Second,
+			MaxWorkers:     1,
+			Driver:         mockgateway.Succeed(),
+			WebhookSecret:  "another-secret-0123456789abcdefghij",
+		}, recorder, logger)
+		DeferCleanup(client.Shutdown)
+		process(client)
+
+		Consistently(callbacks, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("reports a forced failure with its reason", func() {
+		process(newClient(mockgateway.Fail("card declined")))
+
+		var callback map[string]interface{}
+		Eventually(callbacks).Should(Receive(&callback))
+		Expect(callback["status"]).To(Equal("FAILED"))
+		Expect(callback["failure_reason"]).To(Equal("card declined"))
+	})
+
+	It("leaves the callback to the gateway by default", func() {
+		process(newClient(nil))
+
+		Eventually(recorder.Completed).Should(ConsistOf("exp-1-1000"))
+		Consistently(callbacks, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("drops a job cancelled while it was queued", func() {
+		recorder.cancelled = map[string]bool{"exp-1-1000": true}
+		process(newClient(mockgateway.Succeed()))
+
+		Consistently(callbacks, 200*time.Millisecond).ShouldNot(Receive())
+		Expect(recorder.Completed()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Client failover", func() {
+	var (
+		primary, backup *httptest.Server
+		primaryDown     atomic.Bool
+		primaryHits     atomic.Int64
+		recorder        *recordingJobRecorder
+		client          *paymentgateway.Client
+	)
+
+	gateway := func(id string, down *atomic.Bool, hits *atomic.Int64) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hits != nil {
+				hits.Add(1)
+			}
+			if down != nil && down.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"id": id, "external_id": r.URL.Query().Get("external_id"), "status": "PENDING"},
+			})
+		}))
+	}
+
+	BeforeEach(func() {
+		primaryDown.Store(true)
+		primaryHits.Store(0)
+		primary = gateway("primary-1", &primaryDown, &primaryHits)
+		backup = gateway("backup-1", nil, nil)
+		DeferCleanup(primary.Close)
+		DeferCleanup(backup.Close)
+
+		recorder = &recordingJobRecorder{}
+		client = paymentgateway.NewClient(paymentgateway.Config{
+			PaymentTimeout: time.Second,
+			MaxWorkers:     1,
+			Providers: []paymentgateway.Provider{
+				{Name: "primary", APIURL: primary.URL},
+				{Name: "backup", APIURL: backup.URL},
+			},
+			Failover: paymentgateway.FailoverConfig{FailureThreshold: 2, OpenDuration: 200 * time.Millisecond},
+		}, recorder, slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})))
+		DeferCleanup(client.Shutdown)
+	})
+
+	process := func(externalID string) *paymentgatewaytypes.PaymentResponse {
+		resp, err := client.ProcessPayment(&paymentgatewaytypes.PaymentRequest{ExternalID: externalID, Amount: 1000, Currency: "IDR"})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("fails over to the next provider and records it", func() {
+		resp := process("exp-1-1000")
+		Expect(resp.Data.ID).To(Equal("backup-1"))
+		Expect(resp.Data.Provider).To(Equal("backup"))
+		Expect(recorder.Route("exp-1-1000")).To(Equal("backup"))
+	})
+
+	It("skips a provider while its circuit is open and probes it afterwards", func() {
+		process("exp-1-1000")
+		process("exp-2-1000")
+		Expect(primaryHits.Load()).To(Equal(int64(2)))
+		Expect(client.ProviderStats()[0].Circuit).To(Equal(paymentgateway.CircuitOpen))
+
+		process("exp-3-1000")
+		Expect(primaryHits.Load()).To(Equal(int64(2)))
+
+		primaryDown.Store(false)
+		time.Sleep(250 * time.Millisecond)
+		Expect(client.ProviderStats()[0].Circuit).To(Equal(paymentgateway.CircuitHalfOpen))
+
+		resp := process("exp-4-1000")
+		Expect(resp.Data.Provider).To(Equal("primary"))
+		Expect(client.ProviderStats()[0]).To(Equal(paymentgateway.ProviderStats{Name: "primary", Circuit: paymentgateway.CircuitClosed}))
+	})
+
+	It("looks up status with each provider in turn", func() {
+		resp, err := client.GetPaymentStatus("exp-1-1000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Data.Provider).To(Equal("backup"))
+	})
+})
+
+// panickingDriver panics while settling the listed jobs, at most times
+// per job, then settles them as successful.
+type panickingDriver struct {
+	mu     sync.Mutex
+	times  int
+	panics map[string]int
+}
+
+func (d *panickingDriver) Name() string { return "panicking" }
+
+func (d *panickingDriver) Settle(_ context.Context, job paymentgateway.PaymentJob) (paymentgateway.Settlement, bool) {
+	d.mu.Lock()
+	panicked := d.panics[job.ExternalID]
+	if _, listed := d.panics[job.ExternalID]; listed && panicked < d.times {
+		d.panics[job.ExternalID]++
+		d.mu.Unlock()
+		panic("settlement blew up")
+	}
+	d.mu.Unlock()
+	return paymentgateway.Settlement{Status: paymentgatewaytypes.PaymentStatusSuccess}, true
+}
+
+var _ = Describe("Client worker panics", func() {
+	var (
+		gatewayServer *httptest.Server
+		webhookServer *httptest.Server
+		callbacks     chan string
+		recorder      *recordingJobRecorder
+	)
+
+	BeforeEach(func() {
+		recorder = &recordingJobRecorder{}
+		callbacks = make(chan string, 4)
+
+		gatewayServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"id": "gw-1", "status": "PENDING"},
+			})
+		}))
+		webhookServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			callbacks <- body["external_id"].(string)
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(gatewayServer.Close)
+		DeferCleanup(webhookServer.Close)
+	})
+
+	newClient := func(driver paymentgateway.Driver) *paymentgateway.Client {
+		client := paymentgateway.NewClient(paymentgateway.Config{
+			MockAPIURL:      gatewayServer.URL,
+			WebhookURL:      webhookServer.URL,
+			PaymentTimeout:  time.Second,
+			MaxWorkers:      1,
+			PoisonThreshold: 2,
+			Driver:          driver,
+		}, recorder, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		DeferCleanup(client.Shutdown)
+		return client
+	}
+
+	process := func(client *paymentgateway.Client, externalID string) {
+		_, err := client.ProcessPayment(&paymentgatewaytypes.PaymentRequest{ExternalID: externalID, Amount: 1000, Currency: "IDR"})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("re-queues a job that panicked and keeps the worker alive", func() {
+		client := newClient(&panickingDriver{times: 1, panics: map[string]int{"exp-1-1000": 0}})
+		process(client, "exp-1-1000")
+
+		Eventually(callbacks).Should(Receive(Equal("exp-1-1000")))
+		Eventually(recorder.Completed).Should(ConsistOf("exp-1-1000"))
+		stats := client.Stats()
+		Expect(stats.PanicsTotal).To(Equal(int64(1)))
+		Expect(stats.RequeuedTotal).To(Equal(int64(1)))
+		Expect(stats.DeadLetteredTotal).To(BeZero())
+		Expect(stats.Workers).To(Equal(1))
+	})
+
+	It("dead-letters a job that keeps panicking and carries on with the next one", func() {
+		client := newClient(&panickingDriver{times: 10, panics: map[string]int{"exp-1-1000": 0}})
+		process(client, "exp-1-1000")
+		process(client, "exp-2-1000")
+
+		Eventually(callbacks).Should(Receive(Equal("exp-2-1000")))
+		Eventually(recorder.DeadLettered).Should(Equal(map[string]int{"exp-1-1000": 2}))
+		Consistently(callbacks, 200*time.Millisecond).ShouldNot(Receive())
+		Expect(recorder.Completed()).To(ConsistOf("exp-2-1000"))
+		stats := client.Stats()
+		Expect(stats.PanicsTotal).To(Equal(int64(2)))
+		Expect(stats.RequeuedTotal).To(Equal(int64(1)))
+		Expect(stats.DeadLetteredTotal).To(Equal(int64(1)))
+	})
+})
+
+var _ = Describe("Client status checks", func() {
+	var (
+		gateway *httptest.Server
+		// delays holds how long the gateway takes to answer each request,
+		// in arrival order; later requests answer at once.
+		delays []time.Duration
+		hits   atomic.Int64
+	)
+
+	BeforeEach(func() {
+		delays = nil
+		hits.Store(0)
+		gateway = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(hits.Add(1)) - 1
+			if n < len(delays) {
+				select {
+				case <-time.After(delays[n]):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"id": "gw-1", "external_id": r.URL.Query().Get("external_id"), "status": "SUCCESS"},
+			})
+		}))
+		DeferCleanup(gateway.Close)
+	})
+
+	newClient := func(budgets paymentgateway.LatencyBudgets, hedge paymentgateway.HedgeConfig) *paymentgateway.Client {
+		client := paymentgateway.NewClient(paymentgateway.Config{
+			MockAPIURL:     gateway.URL,
+			PaymentTimeout: 5 * time.Second,
+			MaxWorkers:     1,
+			Budgets:        budgets,
+			Hedge:          hedge,
+		}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		DeferCleanup(client.Shutdown)
+		return client
+	}
+
+	It("answers from the hedged request when the first one is slow", func() {
+		delays = []time.Duration{2 * time.Second}
+		client := newClient(paymentgateway.LatencyBudgets{}, paymentgateway.HedgeConfig{Delay: 50 * time.Millisecond})
+
+		started := time.Now()
+		resp, err := client.GetPaymentStatus("exp-1-1000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Data.Status).To(Equal(paymentgatewaytypes.PaymentStatusSuccess))
+		Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+		Expect(client.StatusCheckStats()).To(Equal(paymentgateway.StatusCheckStats{Checks: 1, Hedged: 1, HedgeWins: 1}))
+	})
+
+	It("does not hedge a check that answers within the delay", func() {
+		client := newClient(paymentgateway.LatencyBudgets{}, paymentgateway.HedgeConfig{Delay: 500 * time.Millisecond})
+
+		_, err := client.GetPaymentStatus("exp-1-1000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hits.Load()).To(Equal(int64(1)))
+		Expect(client.StatusCheckStats()).To(Equal(paymentgateway.StatusCheckStats{Checks: 1}))
+	})
+
+	It("gives up once the status budget is spent", func() {
+		delays = []time.Duration{2 * time.Second, 2 * time.Second}
+		client := newClient(paymentgateway.LatencyBudgets{Status: 150 * time.Millisecond}, paymentgateway.HedgeConfig{Delay: 50 * time.Millisecond})
+
+		started := time.Now()
+		_, err := client.GetPaymentStatus("exp-1-1000")
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+		Expect(client.StatusCheckStats()).To(Equal(paymentgateway.StatusCheckStats{Checks: 1, Hedged: 1, BudgetExceeded: 1}))
+	})
+})
@@ -0,0 +1,32 @@
+package paymentgateway
+
+import (
+	"context"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+)
+
+// Settlement is the outcome a Driver reports for a payment job.
+type Settlement struct {
+	Status        paymentgatewaytypes.PaymentStatus
+	FailureReason string
+}
+
+// Driver decides how an initiated payment job is settled. The default driver
+// leaves settlement to the gateway, which calls the webhook on its own;
+// drivers that return settled=true have the client deliver the callback
+// instead.
+type Driver interface {
+	Name() string
+	Settle(ctx context.Context, job PaymentJob) (settlement Settlement, settled bool)
+}
+
+const DriverGateway = "gateway"
+
+type gatewayDriver struct{}
+
+func (gatewayDriver) Name() string { return DriverGateway }
+
+func (gatewayDriver) Settle(context.Context, PaymentJob) (Settlement, bool) {
+	return Settlement{}, false
+}
@@ -0,0 +1,53 @@
+package paymentgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Failure classes of gateway errors. A transient failure may go through
+// when the payment is tried again; a permanent one will not, so it is
+// reported at once.
+const (
+	FailureTransient = "transient"
+	FailurePermanent = "permanent"
+)
+
+// StatusError is a gateway answering a payment with an unexpected HTTP
+// status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Postman API returned status %d", e.StatusCode)
+}
+
+// Classify tells transient errors — the network, timeouts, 5xx, 408 and 429
+// answers, a full queue and every provider's circuit being open — from
+// permanent ones, such as a request the gateway or the client refused as
+// invalid or an answer that could not be decoded. Errors it does not know
+// are permanent, so nothing is retried by guesswork.
+func Classify(err error) string {
+	var statusErr *StatusError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &statusErr):
+		switch code := statusErr.StatusCode; {
+		case code >= 500, code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+			return FailureTransient
+		}
+		return FailurePermanent
+	case errors.Is(err, ErrQueueFull), errors.Is(err, ErrNoProviderAvailable),
+		errors.Is(err, context.DeadlineExceeded), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &netErr):
+		return FailureTransient
+	}
+	return FailurePermanent
+}
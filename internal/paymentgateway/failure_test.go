@@ -0,0 +1,32 @@
+package paymentgateway_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+var _ = Describe("Classify", func() {
+	DescribeTable("tells transient failures from permanent ones",
+		func(err error, want string) {
+			Expect(paymentgateway.Classify(err)).To(Equal(want))
+		},
+		Entry("no error", nil, ""),
+		Entry("a 503", &paymentgateway.StatusError{StatusCode: 503}, paymentgateway.FailureTransient),
+		Entry("a 429", fmt.Errorf("gw: %w", &paymentgateway.StatusError{StatusCode: 429}), paymentgateway.FailureTransient),
+		Entry("a 422", &paymentgateway.StatusError{StatusCode: 422}, paymentgateway.FailurePermanent),
+		Entry("a refused connection", &url.Error{Op: "Post", URL: "http://gw", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, paymentgateway.FailureTransient),
+		Entry("a timeout", fmt.Errorf("HTTP request failed: %w", context.DeadlineExceeded), paymentgateway.FailureTransient),
+		Entry("every circuit open", paymentgateway.ErrNoProviderAvailable, paymentgateway.FailureTransient),
+		Entry("a full queue", paymentgateway.ErrQueueFull, paymentgateway.FailureTransient),
+		Entry("an undecodable answer", &paymentgateway.ResponseError{Version: "v1", Reason: "missing id"}, paymentgateway.FailurePermanent),
+		Entry("an invalid request", errors.New("validation error: amount is required"), paymentgateway.FailurePermanent),
+	)
+})
@@ -0,0 +1,108 @@
+package paymentgateway
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+)
+
+// LatencyBudgets bound each gateway endpoint. Zero falls back to
+// Config.PaymentTimeout.
+type LatencyBudgets struct {
+	// Initiate bounds one initiation request to one provider.
+	Initiate time.Duration
+	// Status bounds a status check with one provider, hedged attempt
+	// included.
+	Status time.Duration
+}
+
+// HedgeConfig turns on hedged status checks. When a check has not answered
+// after Delay, a second identical request is sent and whichever answers
+// first is used. Only status checks are hedged: sending an initiation twice
+// could pay twice. Zero Delay disables hedging.
+type HedgeConfig struct {
+	Delay time.Duration
+}
+
+// StatusCheckStats is published under payment_gateway_status_checks in the
+// metrics.
+type StatusCheckStats struct {
+	Checks int64 `json:"checks"`
+	// Hedged counts checks that sent a second request; HedgeWins those whose
+	// answer came from it.
+	Hedged    int64 `json:"hedged"`
+	HedgeWins int64 `json:"hedge_wins"`
+	// BudgetExceeded counts checks that ran out of the status budget.
+	BudgetExceeded int64 `json:"budget_exceeded"`
+}
+
+type statusCheckCounters struct {
+	checks         atomic.Int64
+	hedged         atomic.Int64
+	hedgeWins      atomic.Int64
+	budgetExceeded atomic.Int64
+}
+
+func (c *Client) StatusCheckStats() StatusCheckStats {
+	return StatusCheckStats{
+		Checks:         c.statusChecks.checks.Load(),
+		Hedged:         c.statusChecks.hedged.Load(),
+		HedgeWins:      c.statusChecks.hedgeWins.Load(),
+		BudgetExceeded: c.statusChecks.budgetExceeded.Load(),
+	}
+}
+
+type statusResult struct {
+	resp   *paymentgatewaytypes.PaymentResponse
+	err    error
+	hedged bool
+}
+
+// hedgedPaymentStatus sends a second status request once the first has been
+// outstanding for the hedge delay and returns the first successful answer.
+// A first request that fails before the delay is returned as is, leaving
+// the next provider to the caller.
+func (c *Client) hedgedPaymentStatus(ctx context.Context, p *provider, externalID string) (*paymentgatewaytypes.PaymentResponse, error) {
+	// Cancelling ctx on return abandons the slower request.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan statusResult, 2)
+	send := func(hedged bool) {
+		resp, err := c.fetchPaymentStatus(ctx, p, externalID)
+		results <- statusResult{resp: resp, err: err, hedged: hedged}
+	}
+
+	go send(false)
+	timer := time.NewTimer(c.hedge.Delay)
+	defer timer.Stop()
+	hedge := timer.C
+
+	pending := 1
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			pending++
+			c.statusChecks.hedged.Add(1)
+			c.logger.Debug("status check slow, sending hedged request",
+				"provider", p.Name,
+				"external_id", externalID,
+				"delay", c.hedge.Delay)
+			go send(true)
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				if res.hedged {
+					c.statusChecks.hedgeWins.Add(1)
+				}
+				return res.resp, nil
+			}
+			if pending == 0 {
+				return nil, res.err
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package mockgateway simulates gateway settlement for local development and
+// tests. It is only linked into binaries built without the production tag.
+package mockgateway
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+const DriverName = "mock"
+
+type Mode string
+
+const (
+	// ModeRandom succeeds with probability SuccessRate.
+	ModeRandom  Mode = "random"
+	ModeSuccess Mode = "success"
+	ModeFailure Mode = "failure"
+)
+
+const DefaultFailureReason = "Insufficient funds"
+
+type Config struct {
+	Mode          Mode
+	SuccessRate   float64
+	MinDelay      time.Duration
+	MaxDelay      time.Duration
+	FailureReason string
+	// Seed makes ModeRandom reproducible; zero seeds from the clock.
+	Seed int64
+}
+
+type Driver struct {
+	cfg Config
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func New(cfg Config) (*Driver, error) {
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = ModeRandom
+	case ModeRandom, ModeSuccess, ModeFailure:
+	default:
+		return nil, fmt.Errorf("unknown mock gateway mode %q", cfg.Mode)
+	}
+	if cfg.SuccessRate < 0 || cfg.SuccessRate > 1 {
+		return nil, fmt.Errorf("mock gateway success rate must be between 0 and 1, got %v", cfg.SuccessRate)
+	}
+	if cfg.MaxDelay < cfg.MinDelay {
+		return nil, fmt.Errorf("mock gateway max delay %s is below min delay %s", cfg.MaxDelay, cfg.MinDelay)
+	}
+	if cfg.FailureReason == "" {
+		cfg.FailureReason = DefaultFailureReason
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &Driver{cfg: cfg, rng: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Succeed and Fail return drivers that settle every payment immediately with
+// a fixed outcome.
+func Succeed() *Driver {
+	d, _ := New(Config{Mode: ModeSuccess})
+	return d
+}
+
+func Fail(reason string) *Driver {
+	d, _ := New(Config{Mode: ModeFailure, FailureReason: reason})
+	return d
+}
+
+func (d *Driver) Name() string { return DriverName }
+
+func (d *Driver) Settle(ctx context.Context, job paymentgateway.PaymentJob) (paymentgateway.Settlement, bool) {
+	if delay := d.delay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return paymentgateway.Settlement{}, false
+		}
+	}
+
+	if d.succeeds() {
+		return paymentgateway.Settlement{Status: paymentgatewaytypes.PaymentStatusSuccess}, true
+	}
+	return paymentgateway.Settlement{
+		Status:        paymentgatewaytypes.PaymentStatusFailed,
+		FailureReason: d.cfg.FailureReason,
+	}, true
+}
+
+func (d *Driver) delay() time.Duration {
+	spread := d.cfg.MaxDelay - d.cfg.MinDelay
+	if spread <= 0 {
+		return d.cfg.MinDelay
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cfg.MinDelay + time.Duration(d.rng.Int63n(int64(spread)+1))
+}
+
+func (d *Driver) succeeds() bool {
+	switch d.cfg.Mode {
+	case ModeSuccess:
+		return true
+	case ModeFailure:
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rng.Float64() < d.cfg.SuccessRate
+}
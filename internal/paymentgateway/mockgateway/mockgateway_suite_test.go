@@ -0,0 +1,13 @@
+package mockgateway_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMockGateway(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mock Gateway Suite")
+}
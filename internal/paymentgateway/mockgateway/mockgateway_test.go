@@ -0,0 +1,68 @@
+package mockgateway_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/mockgateway"
+)
+
+var _ = Describe("Driver", func() {
+	job := paymentgateway.PaymentJob{ExternalID: "exp-1-1000", Amount: 1000}
+
+	It("settles with the forced outcome", func() {
+		settlement, settled := mockgateway.Succeed().Settle(context.Background(), job)
+		Expect(settled).To(BeTrue())
+		Expect(settlement.Status).To(Equal(paymentgatewaytypes.PaymentStatusSuccess))
+
+		settlement, settled = mockgateway.Fail("").Settle(context.Background(), job)
+		Expect(settled).To(BeTrue())
+		Expect(settlement.Status).To(Equal(paymentgatewaytypes.PaymentStatusFailed))
+		Expect(settlement.FailureReason).To(Equal(mockgateway.DefaultFailureReason))
+	})
+
+	It("is reproducible in random mode with a fixed seed", func() {
+		outcomes := func() []paymentgatewaytypes.PaymentStatus {
+			d, err := mockgateway.New(mockgateway.Config{Mode: mockgateway.ModeRandom, SuccessRate: 0.5, Seed: 42})
+			Expect(err).NotTo(HaveOccurred())
+			var out []paymentgatewaytypes.PaymentStatus
+			for i := 0; i < 20; i++ {
+				s, _ := d.Settle(context.Background(), job)
+				out = append(out, s.Status)
+			}
+			return out
+		}
+
+		first := outcomes()
+		Expect(outcomes()).To(Equal(first))
+		Expect(first).To(ContainElement(paymentgatewaytypes.PaymentStatusSuccess))
+		Expect(first).To(ContainElement(paymentgatewaytypes.PaymentStatusFailed))
+	})
+
+	It("gives up when cancelled during the simulated delay", func() {
+		d, err := mockgateway.New(mockgateway.Config{Mode: mockgateway.ModeSuccess, MinDelay: time.Minute, MaxDelay: time.Minute})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, settled := d.Settle(ctx, job)
+		Expect(settled).To(BeFalse())
+	})
+
+	It("rejects invalid configuration", func() {
+		_, err := mockgateway.New(mockgateway.Config{Mode: "sometimes"})
+		Expect(err).To(HaveOccurred())
+
+		_, err = mockgateway.New(mockgateway.Config{SuccessRate: 1.5})
+		Expect(err).To(HaveOccurred())
+
+		_, err = mockgateway.New(mockgateway.Config{MinDelay: time.Second})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,193 @@
+package paymentgateway
+
+import (
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+)
+
+type OverflowStrategy string
+
+const (
+	// OverflowBlock waits up to EnqueueTimeout for room in the queue before shedding.
+	OverflowBlock OverflowStrategy = "block"
+	// OverflowSpill writes the job to the persistent SpillStore and drains it later.
+	OverflowSpill OverflowStrategy = "spill"
+	// OverflowShed rejects the job immediately.
+	OverflowShed OverflowStrategy = "shed"
+)
+
+func (s OverflowStrategy) IsValid() bool {
+	switch s {
+	case OverflowBlock, OverflowSpill, OverflowShed:
+		return true
+	}
+	return false
+}
+
+var ErrQueueFull = errors.ErrPaymentQueueFull
+
+// SpillStore is the persistent overflow queue used by OverflowSpill. Claimed
+// jobs are moved back to queued before being returned.
+type SpillStore interface {
+	Spill(job PaymentJob) error
+	ClaimSpilled(limit int) ([]PaymentJob, error)
+}
+
+type QueueStats struct {
+	QueueDepth       int              `json:"queue_depth"`
+	QueueCapacity    int              `json:"queue_capacity"`
+	Workers          int              `json:"workers"`
+	BusyWorkers      int64            `json:"busy_workers"`
+	MinWorkers       int              `json:"min_workers"`
+	MaxWorkers       int              `json:"max_workers"`
+	OverflowStrategy OverflowStrategy `json:"overflow_strategy"`
+	EnqueuedTotal    int64            `json:"enqueued_total"`
+	SpilledTotal     int64            `json:"spilled_total"`
+	ShedTotal        int64            `json:"shed_total"`
+	// PanicsTotal counts job runs that panicked; each is either re-queued
+	// or, past the poison threshold, dead-lettered.
+	PanicsTotal       int64 `json:"panics_total"`
+	RequeuedTotal     int64 `json:"requeued_total"`
+	DeadLetteredTotal int64 `json:"dead_lettered_total"`
+}
+
+func (c *Client) Stats() QueueStats {
+	c.mu.Lock()
+	workers := len(c.workers)
+	c.mu.Unlock()
+
+	return QueueStats{
+		QueueDepth:        len(c.jobQueue),
+		QueueCapacity:     cap(c.jobQueue),
+		Workers:           workers,
+		BusyWorkers:       c.busyWorkers.Load(),
+		MinWorkers:        c.minWorkers,
+		MaxWorkers:        c.maxWorkers,
+		OverflowStrategy:  c.overflow,
+		EnqueuedTotal:     c.enqueued.Load(),
+		SpilledTotal:      c.spilled.Load(),
+		ShedTotal:         c.shed.Load(),
+		PanicsTotal:       c.panics.Load(),
+		RequeuedTotal:     c.requeued.Load(),
+		DeadLetteredTotal: c.deadLettered.Load(),
+	}
+}
+
+func (c *Client) enqueue(job PaymentJob) error {
+	select {
+	case c.jobQueue <- job:
+		c.enqueued.Add(1)
+		return nil
+	default:
+	}
+
+	switch c.overflow {
+	case OverflowBlock:
+		timer := time.NewTimer(c.enqueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case c.jobQueue <- job:
+			c.enqueued.Add(1)
+			return nil
+		case <-timer.C:
+		case <-c.ctx.Done():
+		}
+	case OverflowSpill:
+		if c.spill == nil {
+			c.logger.Warn("spill overflow strategy configured without a spill store, shedding job",
+				"external_id", job.ExternalID)
+			break
+		}
+		if err := c.spill.Spill(job); err != nil {
+			c.logger.Error("failed to spill payment job", "error", err, "external_id", job.ExternalID)
+			break
+		}
+		c.spilled.Add(1)
+		c.logger.Info("payment job spilled to persistent queue", "external_id", job.ExternalID)
+		return nil
+	}
+
+	c.shed.Add(1)
+	return ErrQueueFull
+}
+
+func (c *Client) autoscale() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.scaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.scale()
+			c.drainSpilled()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// scale grows the pool towards maxWorkers while jobs are waiting and retires
+// one idle worker per tick once the queue is empty.
+func (c *Client) scale() {
+	depth := len(c.jobQueue)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := len(c.workers)
+
+	switch {
+	case depth > 0 && active < c.maxWorkers:
+		add := min(depth, c.maxWorkers-active)
+		for i := 0; i < add; i++ {
+			c.startWorkerLocked()
+		}
+		c.logger.Info("payment worker pool scaled up", "workers", active+add, "queue_depth", depth)
+	case depth == 0 && active > c.minWorkers:
+		select {
+		case jobChannel := <-c.workerPool:
+			if worker, ok := c.workers[jobChannel]; ok {
+				delete(c.workers, jobChannel)
+				worker.Stop()
+				c.logger.Info("payment worker pool scaled down", "workers", active-1, "worker_id", worker.ID)
+			}
+		default:
+		}
+	}
+}
+
+func (c *Client) drainSpilled() {
+	if c.spill == nil {
+		return
+	}
+
+	free := cap(c.jobQueue) - len(c.jobQueue)
+	if free <= 0 {
+		return
+	}
+
+	jobs, err := c.spill.ClaimSpilled(free)
+	if err != nil {
+		c.logger.Error("failed to claim spilled payment jobs", "error", err)
+		return
+	}
+
+	for _, job := range jobs {
+		select {
+		case c.jobQueue <- job:
+			c.enqueued.Add(1)
+		default:
+			if err := c.spill.Spill(job); err != nil {
+				c.logger.Error("failed to return payment job to spill store", "error", err, "external_id", job.ExternalID)
+			}
+		}
+	}
+
+	if len(jobs) > 0 {
+		c.logger.Info("drained spilled payment jobs", "count", len(jobs))
+	}
+}
@@ -0,0 +1,13 @@
+package paymentgateway_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPaymentGateway(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Payment Gateway Suite")
+}
@@ -0,0 +1,127 @@
+package paymentgateway
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoProviderAvailable is returned when every provider's circuit is open.
+var ErrNoProviderAvailable = errors.New("no payment provider available")
+
+// DefaultProviderName names the single provider built from Config.MockAPIURL
+// when no providers are listed.
+const DefaultProviderName = "default"
+
+// Provider is one payment gateway the client can route payments to. Payments
+// are initiated with the first provider, in configuration order, whose
+// circuit is not open.
+type Provider struct {
+	Name   string
+	APIURL string
+	APIKey string
+	// Driver settles jobs initiated with this provider; nil waits for the
+	// gateway's own callback.
+	Driver Driver
+	// Response decodes the provider's answers; nil decodes ResponseV1.
+	Response ResponseAdapter
+}
+
+// FailoverConfig controls the per-provider circuit breaker.
+type FailoverConfig struct {
+	// FailureThreshold consecutive initiation failures open a provider's
+	// circuit.
+	FailureThreshold int
+	// OpenDuration is how long an open circuit skips its provider before a
+	// single trial request is let through.
+	OpenDuration time.Duration
+}
+
+// ProviderRecorder is implemented by JobRecorders that persist which
+// provider a payment was initiated with.
+type ProviderRecorder interface {
+	JobRouted(externalID, provider string)
+}
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+type ProviderStats struct {
+	Name                string `json:"name"`
+	Circuit             string `json:"circuit"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+}
+
+type provider struct {
+	Provider
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent. Once an open circuit's
+// duration has passed, one request probes the provider; the circuit closes
+// if it succeeds and reopens if it fails.
+func (p *provider) allow(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(p.openUntil) || p.probing {
+		return false
+	}
+	p.probing = true
+	return true
+}
+
+func (p *provider) succeeded() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures = 0
+	p.openUntil = time.Time{}
+	p.probing = false
+}
+
+// failed records a failure and reports whether it opened the circuit.
+func (p *provider) failed(now time.Time, cfg FailoverConfig) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures++
+	p.probing = false
+	if p.failures < cfg.FailureThreshold {
+		return false
+	}
+	p.openUntil = now.Add(cfg.OpenDuration)
+	return true
+}
+
+func (p *provider) response() ResponseAdapter {
+	if p.Response == nil {
+		return envelopedAdapter{}
+	}
+	return p.Response
+}
+
+func (p *provider) stats(now time.Time) ProviderStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	circuit := CircuitClosed
+	switch {
+	case p.openUntil.IsZero():
+	case now.Before(p.openUntil):
+		circuit = CircuitOpen
+	default:
+		circuit = CircuitHalfOpen
+	}
+	return ProviderStats{Name: p.Name, Circuit: circuit, ConsecutiveFailures: p.failures}
+}
@@ -0,0 +1,167 @@
+package paymentgateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+)
+
+// Response versions a provider can be configured with. V1 wraps the payment
+// in a data envelope, {"data": {"id", "external_id", "status"}}; V2 sends
+// the same fields at the top level.
+const (
+	ResponseV1 = "v1"
+	ResponseV2 = "v2"
+)
+
+// maxResponseBytes bounds how much of a gateway response is read.
+const maxResponseBytes = 1 << 20
+
+// ResponseError describes a gateway response its adapter could not decode.
+type ResponseError struct {
+	Version string
+	Reason  string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("gateway response (%s): %s", e.Version, e.Reason)
+}
+
+// ResponseAdapter decodes one version of a provider's payment responses,
+// for initiation and status lookups alike. Unknown fields are ignored and
+// the id may be a string or a number, but a response without an id or a
+// status is an error. The decoded data keeps the response as received.
+type ResponseAdapter interface {
+	Version() string
+	Decode(body []byte) (*paymentgatewaytypes.PaymentData, error)
+}
+
+// NewResponseAdapter returns the adapter for version; empty means V1.
+func NewResponseAdapter(version string) (ResponseAdapter, error) {
+	switch version {
+	case "", ResponseV1:
+		return envelopedAdapter{}, nil
+	case ResponseV2:
+		return flatAdapter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown gateway response version %q", version)
+	}
+}
+
+type envelopedAdapter struct{}
+
+func (envelopedAdapter) Version() string { return ResponseV1 }
+
+func (a envelopedAdapter) Decode(body []byte) (*paymentgatewaytypes.PaymentData, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, &ResponseError{Version: ResponseV1, Reason: "body is not a JSON object: " + err.Error()}
+	}
+	data, ok := envelope["data"]
+	if !ok {
+		reason := "missing data envelope"
+		if _, flat := envelope["id"]; flat {
+			reason += "; the provider looks like it sends v2 responses"
+		}
+		return nil, &ResponseError{Version: ResponseV1, Reason: reason}
+	}
+	return decodePaymentData(ResponseV1, "data.", data, body)
+}
+
+type flatAdapter struct{}
+
+func (flatAdapter) Version() string { return ResponseV2 }
+
+func (a flatAdapter) Decode(body []byte) (*paymentgatewaytypes.PaymentData, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, &ResponseError{Version: ResponseV2, Reason: "body is not a JSON object: " + err.Error()}
+	}
+	if _, enveloped := fields["data"]; enveloped {
+		if _, flat := fields["id"]; !flat {
+			return nil, &ResponseError{Version: ResponseV2, Reason: "missing id; the provider looks like it sends v1 responses"}
+		}
+	}
+	return decodePaymentData(ResponseV2, "", body, body)
+}
+
+// decodePaymentData reads the payment fields of raw, naming them with
+// prefix in errors, and keeps body, compacted, as the data's raw response.
+func decodePaymentData(version, prefix string, raw, body []byte) (*paymentgatewaytypes.PaymentData, error) {
+	var fields struct {
+		ID         json.RawMessage `json:"id"`
+		ExternalID *string         `json:"external_id"`
+		Status     *string         `json:"status"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, &ResponseError{Version: version, Reason: describeFieldError(prefix, err)}
+	}
+
+	id, err := idString(fields.ID)
+	if err != nil {
+		return nil, &ResponseError{Version: version, Reason: prefix + "id " + err.Error()}
+	}
+	if id == "" {
+		return nil, &ResponseError{Version: version, Reason: "missing " + prefix + "id"}
+	}
+	if fields.Status == nil || *fields.Status == "" {
+		return nil, &ResponseError{Version: version, Reason: "missing " + prefix + "status"}
+	}
+
+	// The body was parsed above, so it compacts without error.
+	var compact bytes.Buffer
+	_ = json.Compact(&compact, body)
+
+	data := &paymentgatewaytypes.PaymentData{
+		ID:              id,
+		Status:          paymentgatewaytypes.PaymentStatus(*fields.Status),
+		ResponseVersion: version,
+		Raw:             compact.Bytes(),
+	}
+	if fields.ExternalID != nil {
+		data.ExternalID = *fields.ExternalID
+	}
+	return data, nil
+}
+
+// idString accepts an id sent as a JSON string or number.
+func idString(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String(), nil
+	}
+	return "", fmt.Errorf("must be a string or a number, got %s", raw)
+}
+
+func describeFieldError(prefix string, err error) string {
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field != "" {
+		return fmt.Sprintf("%s%s must be a %s, got %s", prefix, typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	if prefix == "" {
+		return "body is not a JSON object: " + err.Error()
+	}
+	return prefix[:len(prefix)-1] + " is not a JSON object: " + err.Error()
+}
+
+// readResponse reads at most maxResponseBytes of a gateway response.
+func readResponse(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxResponseBytes)
+	}
+	return body, nil
+}
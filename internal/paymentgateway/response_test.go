@@ -0,0 +1,106 @@
+package paymentgateway_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/paymentgateway"
+)
+
+var _ = Describe("Response adapters", func() {
+	adapter := func(version string) paymentgateway.ResponseAdapter {
+		a, err := paymentgateway.NewResponseAdapter(version)
+		Expect(err).NotTo(HaveOccurred())
+		return a
+	}
+
+	It("decodes the v1 data envelope and keeps the raw response", func() {
+		data, err := adapter("v1").Decode([]byte(`{"data": {"id": "pay-1", "external_id": "exp-1", "status": "PENDING", "fee": 0}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.ID).To(Equal("pay-1"))
+		Expect(data.ExternalID).To(Equal("exp-1"))
+		Expect(string(data.Status)).To(Equal("PENDING"))
+		Expect(data.ResponseVersion).To(Equal("v1"))
+		Expect(string(data.Raw)).To(Equal(`{"data":{"id":"pay-1","external_id":"exp-1","status":"PENDING","fee":0}}`))
+	})
+
+	It("decodes flat v2 responses with a numeric id", func() {
+		data, err := adapter("v2").Decode([]byte(`{"id": 42, "external_id": "exp-1", "status": "SUCCESS"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.ID).To(Equal("42"))
+		Expect(data.ResponseVersion).To(Equal("v2"))
+	})
+
+	It("defaults to v1", func() {
+		Expect(adapter("").Version()).To(Equal("v1"))
+	})
+
+	It("refuses unknown versions", func() {
+		_, err := paymentgateway.NewResponseAdapter("v9")
+		Expect(err).To(MatchError(ContainSubstring(`unknown gateway response version "v9"`)))
+	})
+
+	DescribeTable("describes responses it cannot decode",
+		func(version, body, reason string) {
+			_, err := adapter(version).Decode([]byte(body))
+			var respErr *paymentgateway.ResponseError
+			Expect(errors.As(err, &respErr)).To(BeTrue())
+			Expect(respErr.Version).To(Equal(version))
+			Expect(respErr.Reason).To(ContainSubstring(reason))
+		},
+		Entry("v2 body for v1", "v1", `{"id": "pay-1", "status": "PENDING"}`, "the provider looks like it sends v2 responses"),
+		Entry("v1 body for v2", "v2", `{"data": {"id": "pay-1", "status": "PENDING"}}`, "the provider looks like it sends v1 responses"),
+		Entry("missing id", "v1", `{"data": {"status": "PENDING"}}`, "missing data.id"),
+		Entry("missing status", "v2", `{"id": "pay-1"}`, "missing status"),
+		Entry("id of the wrong type", "v1", `{"data": {"id": true, "status": "PENDING"}}`, "data.id must be a string or a number"),
+		Entry("status of the wrong type", "v1", `{"data": {"id": "pay-1", "status": 3}}`, "data.status must be a string"),
+		Entry("data that is not an object", "v1", `{"data": []}`, "data is not a JSON object"),
+		Entry("not JSON", "v2", `<html>`, "body is not a JSON object"),
+	)
+
+	Describe("Client", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "pay-9", "status": "SUCCESS", "trace": "abc"}`))
+			}))
+			DeferCleanup(server.Close)
+		})
+
+		newClient := func(version string) *paymentgateway.Client {
+			client := paymentgateway.NewClient(paymentgateway.Config{
+				Providers: []paymentgateway.Provider{{
+					Name:     "flat",
+					APIURL:   server.URL,
+					Response: adapter(version),
+				}},
+				PaymentTimeout: time.Second,
+				MaxWorkers:     1,
+			}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			DeferCleanup(client.Shutdown)
+			return client
+		}
+
+		It("decodes status lookups with the provider's adapter", func() {
+			resp, err := newClient("v2").GetPaymentStatus("exp-9")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Data.ID).To(Equal("pay-9"))
+			Expect(resp.Data.ExternalID).To(Equal("exp-9"))
+			Expect(string(resp.Data.Raw)).To(ContainSubstring(`"trace":"abc"`))
+		})
+
+		It("fails a lookup whose response does not match the adapter", func() {
+			_, err := newClient("v1").GetPaymentStatus("exp-9")
+			Expect(err).To(MatchError(ContainSubstring("gateway response (v1): missing data envelope")))
+		})
+	})
+})
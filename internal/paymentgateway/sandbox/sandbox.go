@@ -0,0 +1,345 @@
+// Package sandbox is an in-process stand-in for the payment gateway. It
+// speaks the same HTTP API as the gateway the Client talks to and calls the
+// webhook back according to scriptable scenarios, so payment flows can be
+// exercised end to end without any external service.
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/webhooksig"
+)
+
+// Scenario scripts how the sandbox settles one payment.
+type Scenario struct {
+	Status        paymentgatewaytypes.PaymentStatus
+	FailureReason string
+	// Delay before the first callback is sent.
+	Delay time.Duration
+	// Callbacks is how many times the callback is delivered; values above one
+	// simulate a gateway retrying delivery. Zero means no callback at all.
+	Callbacks int
+	// AmountDelta is added to the amount reported in the callback.
+	AmountDelta int64
+	// ExternalID, when set, replaces the external_id reported in the callback.
+	ExternalID string
+	// InitiateStatus, when set, is returned by POST /payments instead of 201.
+	InitiateStatus int
+}
+
+func Success() Scenario {
+	return Scenario{Status: paymentgatewaytypes.PaymentStatusSuccess, Callbacks: 1}
+}
+
+func Failure(reason string) Scenario {
+	return Scenario{Status: paymentgatewaytypes.PaymentStatusFailed, FailureReason: reason, Callbacks: 1}
+}
+
+// NoCallback leaves the payment pending forever, like a lost callback.
+func NoCallback() Scenario {
+	return Scenario{Status: paymentgatewaytypes.PaymentStatusPending}
+}
+
+// Unavailable makes payment initiation fail with the given HTTP status.
+func Unavailable(status int) Scenario {
+	return Scenario{Status: paymentgatewaytypes.PaymentStatusPending, InitiateStatus: status}
+}
+
+func (s Scenario) Delayed(d time.Duration) Scenario {
+	s.Delay = d
+	return s
+}
+
+func (s Scenario) Duplicated(times int) Scenario {
+	s.Callbacks = times
+	return s
+}
+
+func (s Scenario) WithAmountMismatch(delta int64) Scenario {
+	s.AmountDelta = delta
+	return s
+}
+
+func (s Scenario) WithExternalID(externalID string) Scenario {
+	s.ExternalID = externalID
+	return s
+}
+
+// Callback is a callback the sandbox delivered, with the webhook's response.
+type Callback struct {
+	ExternalID string
+	Status     paymentgatewaytypes.PaymentStatus
+	Amount     int64
+	StatusCode int
+	Err        error
+}
+
+type payment struct {
+	id         string
+	externalID string
+	amount     int64
+	status     paymentgatewaytypes.PaymentStatus
+}
+
+type Server struct {
+	srv        *httptest.Server
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	scenarios map[string]Scenario
+	fallback  Scenario
+	payments  map[string]*payment
+	callbacks []Callback
+	signer    *webhooksig.Signer
+	nextID    atomic.Int64
+	pending   sync.WaitGroup
+	closed    chan struct{}
+}
+
+// New starts a sandbox that settles every payment successfully unless a
+// different scenario is scripted.
+func New() *Server {
+	s := &Server{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		scenarios:  make(map[string]Scenario),
+		fallback:   Success(),
+		payments:   make(map[string]*payment),
+		closed:     make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /payments", s.initiate)
+	mux.HandleFunc("GET /payments", s.status)
+	s.srv = httptest.NewServer(mux)
+	return s
+}
+
+// TB is the part of testing.TB (and GinkgoT) NewForTest needs.
+type TB interface {
+	Helper()
+	Cleanup(func())
+}
+
+// NewForTest starts a sandbox that is closed when tb finishes.
+func NewForTest(tb TB) *Server {
+	tb.Helper()
+	s := New()
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// URL is the base URL to use as the gateway URL of the Client.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Close stops pending callbacks and shuts the server down.
+func (s *Server) Close() {
+	select {
+	case <-s.closed:
+		return
+	default:
+		close(s.closed)
+	}
+	s.pending.Wait()
+	s.srv.Close()
+}
+
+// Script sets the scenario for one external ID.
+func (s *Server) Script(externalID string, scenario Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[externalID] = scenario
+}
+
+// SetDefault sets the scenario for payments without a scripted one.
+func (s *Server) SetDefault(scenario Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = scenario
+}
+
+// SetWebhookSecret signs the callbacks sent from now on with secret, as a
+// real gateway does with the secret it shares with the webhook. Callbacks
+// are unsigned until it is called.
+func (s *Server) SetWebhookSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signer = webhooksig.New(secret)
+}
+
+// Callbacks returns the callbacks delivered so far.
+func (s *Server) Callbacks() []Callback {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Callback(nil), s.callbacks...)
+}
+
+// WaitForCallbacks blocks until at least n callbacks were delivered.
+func (s *Server) WaitForCallbacks(n int, timeout time.Duration) ([]Callback, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		callbacks := s.Callbacks()
+		if len(callbacks) >= n {
+			return callbacks, nil
+		}
+		if time.Now().After(deadline) {
+			return callbacks, fmt.Errorf("sandbox: got %d callbacks, want %d", len(callbacks), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (s *Server) scenarioFor(externalID string) Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scenario, ok := s.scenarios[externalID]; ok {
+		return scenario
+	}
+	return s.fallback
+}
+
+type initiateRequest struct {
+	ExternalID  string `json:"external_id"`
+	Amount      int64  `json:"amount"`
+	CallbackURL string `json:"callback_url"`
+}
+
+func (s *Server) initiate(w http.ResponseWriter, r *http.Request) {
+	var req initiateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ExternalID == "" {
+		http.Error(w, "invalid payment request", http.StatusBadRequest)
+		return
+	}
+
+	scenario := s.scenarioFor(req.ExternalID)
+	if scenario.InitiateStatus != 0 {
+		http.Error(w, "sandbox: scripted initiation failure", scenario.InitiateStatus)
+		return
+	}
+
+	s.mu.Lock()
+	p, exists := s.payments[req.ExternalID]
+	if !exists {
+		p = &payment{
+			id:         fmt.Sprintf("sandbox-%d", s.nextID.Add(1)),
+			externalID: req.ExternalID,
+			amount:     req.Amount,
+			status:     paymentgatewaytypes.PaymentStatusPending,
+		}
+		s.payments[req.ExternalID] = p
+	}
+	s.mu.Unlock()
+
+	if !exists && req.CallbackURL != "" && scenario.Callbacks > 0 {
+		s.pending.Add(1)
+		go s.settle(p, scenario, req.CallbackURL)
+	}
+
+	writeJSON(w, http.StatusCreated, s.view(p))
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	externalID := r.URL.Query().Get("external_id")
+
+	s.mu.Lock()
+	p, ok := s.payments[externalID]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "payment not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.view(p))
+}
+
+func (s *Server) view(p *payment) map[string]paymentgatewaytypes.PaymentData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]paymentgatewaytypes.PaymentData{
+		"data": {ID: p.id, ExternalID: p.externalID, Status: p.status},
+	}
+}
+
+func (s *Server) settle(p *payment, scenario Scenario, callbackURL string) {
+	defer s.pending.Done()
+
+	if scenario.Delay > 0 {
+		select {
+		case <-time.After(scenario.Delay):
+		case <-s.closed:
+			return
+		}
+	}
+
+	s.mu.Lock()
+	p.status = scenario.Status
+	s.mu.Unlock()
+
+	externalID := p.externalID
+	if scenario.ExternalID != "" {
+		externalID = scenario.ExternalID
+	}
+
+	body := map[string]interface{}{
+		"external_id":        externalID,
+		"status":             string(scenario.Status),
+		"gateway_payment_id": p.id,
+		"amount":             p.amount + scenario.AmountDelta,
+	}
+	if scenario.FailureReason != "" {
+		body["failure_reason"] = scenario.FailureReason
+	}
+	payload, _ := json.Marshal(body)
+
+	for i := 0; i < scenario.Callbacks; i++ {
+		select {
+		case <-s.closed:
+			return
+		default:
+		}
+
+		cb := Callback{ExternalID: externalID, Status: scenario.Status, Amount: p.amount + scenario.AmountDelta}
+		resp, err := s.deliver(callbackURL, payload)
+		if err != nil {
+			cb.Err = err
+		} else {
+			cb.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+
+		s.mu.Lock()
+		s.callbacks = append(s.callbacks, cb)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) deliver(callbackURL string, payload []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	s.mu.Lock()
+	signer := s.signer
+	s.mu.Unlock()
+	if signer != nil {
+		signer.SignRequest(req, payload, time.Now())
+	}
+	return s.httpClient.Do(req)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
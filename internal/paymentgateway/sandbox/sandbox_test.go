@@ -0,0 +1,108 @@
+package sandbox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/paymentgateway/sandbox"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		gateway  *sandbox.Server
+		webhook  *httptest.Server
+		mu       sync.Mutex
+		received []map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		gateway = sandbox.NewForTest(GinkgoT())
+		received = nil
+		webhook = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			received = append(received, body)
+			mu.Unlock()
+		}))
+		DeferCleanup(webhook.Close)
+	})
+
+	initiate := func(externalID string, amount int64) *http.Response {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"external_id":  externalID,
+			"amount":       amount,
+			"callback_url": webhook.URL,
+		})
+		resp, err := http.Post(gateway.URL()+"/payments", "application/json", bytes.NewReader(payload))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(resp.Body.Close)
+		return resp
+	}
+
+	gatewayStatus := func(externalID string) paymentgatewaytypes.PaymentStatus {
+		resp, err := http.Get(gateway.URL() + "/payments?external_id=" + externalID)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		var body struct {
+			Data paymentgatewaytypes.PaymentData `json:"data"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return body.Data.Status
+	}
+
+	It("settles successfully by default and reports the status", func() {
+		Expect(initiate("exp-1", 1000).StatusCode).To(Equal(http.StatusCreated))
+
+		callbacks, err := gateway.WaitForCallbacks(1, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(callbacks[0].Status).To(Equal(paymentgatewaytypes.PaymentStatusSuccess))
+		Expect(callbacks[0].StatusCode).To(Equal(http.StatusOK))
+		Expect(gatewayStatus("exp-1")).To(Equal(paymentgatewaytypes.PaymentStatusSuccess))
+	})
+
+	It("delays the callback", func() {
+		gateway.Script("exp-2", sandbox.Success().Delayed(150*time.Millisecond))
+		initiate("exp-2", 1000)
+
+		Consistently(gateway.Callbacks, 100*time.Millisecond).Should(BeEmpty())
+		Expect(gatewayStatus("exp-2")).To(Equal(paymentgatewaytypes.PaymentStatusPending))
+		Eventually(gateway.Callbacks).Should(HaveLen(1))
+	})
+
+	It("delivers duplicate callbacks", func() {
+		gateway.Script("exp-3", sandbox.Failure("card declined").Duplicated(3))
+		initiate("exp-3", 1000)
+
+		_, err := gateway.WaitForCallbacks(3, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(received).To(HaveLen(3))
+		Expect(received[2]["failure_reason"]).To(Equal("card declined"))
+	})
+
+	It("reports a mismatched amount", func() {
+		gateway.Script("exp-4", sandbox.Success().WithAmountMismatch(-1))
+		initiate("exp-4", 1000)
+
+		callbacks, err := gateway.WaitForCallbacks(1, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(callbacks[0].Amount).To(Equal(int64(999)))
+	})
+
+	It("fails initiation when scripted as unavailable", func() {
+		gateway.Script("exp-5", sandbox.Unavailable(http.StatusServiceUnavailable))
+
+		Expect(initiate("exp-5", 1000).StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Consistently(gateway.Callbacks, 50*time.Millisecond).Should(BeEmpty())
+	})
+})
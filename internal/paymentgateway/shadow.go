@@ -0,0 +1,75 @@
+package paymentgateway
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	paymentgatewaytypes "github.com/frahmantamala/expense-management/internal/core/datamodel/paymentgateway"
+	"github.com/frahmantamala/expense-management/internal/core/httpclient"
+)
+
+// ShadowConfig describes a gateway payments are mirrored to while it is
+// evaluated, typically the new gateway's test mode so no money moves.
+type ShadowConfig struct {
+	Name   string
+	APIURL string
+	APIKey string
+	// WebhookURL is sent to the shadow gateway as the callback URL. Keep it
+	// away from the payment webhook, or its settlements would be applied to
+	// the real payments; empty asks for no callback.
+	WebhookURL string
+	Timeout    time.Duration
+	Transport  http.RoundTripper
+	// HTTP builds the client for the shadow gateway; nil uses
+	// httpclient.Default.
+	HTTP *httpclient.Factory
+	// Response decodes the shadow gateway's answers; nil decodes ResponseV1.
+	Response ResponseAdapter
+}
+
+// Shadow initiates payments with a secondary gateway and reports its answer.
+// Unlike Client it has no worker pool: nothing is queued, retried or
+// settled.
+type Shadow struct {
+	client   *Client
+	provider *provider
+}
+
+func NewShadow(cfg ShadowConfig, logger *slog.Logger) *Shadow {
+	if cfg.Name == "" {
+		cfg.Name = "shadow"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 15 * time.Second
+	}
+	return &Shadow{
+		client: &Client{
+			webhookURL:     cfg.WebhookURL,
+			paymentTimeout: cfg.Timeout,
+			transport:      cfg.Transport,
+			http:           cfg.HTTP,
+			logger:         logger.With("shadow", cfg.Name),
+		},
+		provider: &provider{Provider: Provider{Name: cfg.Name, APIURL: cfg.APIURL, APIKey: cfg.APIKey, Response: cfg.Response}},
+	}
+}
+
+func (s *Shadow) Name() string {
+	return s.provider.Name
+}
+
+// Initiate sends req to the shadow gateway and returns what it answered.
+func (s *Shadow) Initiate(req *paymentgatewaytypes.PaymentRequest) (*paymentgatewaytypes.PaymentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	data, err := s.client.initiatePaymentWithPostman(s.provider, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.provider.Name, err)
+	}
+	data.Provider = s.provider.Name
+	return &paymentgatewaytypes.PaymentResponse{Data: *data}, nil
+}
@@ -0,0 +1,111 @@
+package periodlock
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	ListLocks(ctx context.Context) ([]*Lock, error)
+	LockPeriod(ctx context.Context, period string, lockedBy int64, dto LockPeriodDTO) (*Lock, error)
+	UnlockPeriod(ctx context.Context, period string, unlockedBy int64) error
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListLocks godoc
+// @Summary      List locked periods
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  LocksResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/period-locks [get]
+func (h *Handler) ListLocks(w http.ResponseWriter, r *http.Request) {
+	locks, err := h.Service.ListLocks(r.Context())
+	if err != nil {
+		h.Log(r).Error("ListLocks: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list period locks")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, LocksResponse{Locks: locks})
+}
+
+// LockPeriod godoc
+// @Summary      Lock a month for month-end close
+// @Description  Expenses dated in the month can no longer be created, imported or have receipts attached; those changes fail with PERIOD_LOCKED. Locking a locked month returns the existing lock. Requires close_periods or admin.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period  path      string         true   "Month, YYYY-MM"
+// @Param        body    body      LockPeriodDTO  true   "Lock"
+// @Success      200     {object}  Lock
+// @Failure      400     {object}  transport.AppErrorResponse
+// @Failure      401     {object}  transport.ErrorResponse
+// @Failure      403     {object}  transport.ErrorResponse
+// @Failure      413     {object}  transport.AppErrorResponse
+// @Router       /admin/period-locks/{period} [put]
+func (h *Handler) LockPeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto LockPeriodDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	lock, err := h.Service.LockPeriod(r.Context(), chi.URLParam(r, "period"), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, lock)
+}
+
+// UnlockPeriod godoc
+// @Summary      Unlock a month
+// @Description  Requires close_periods or admin.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        period  path  string  true  "Month, YYYY-MM"
+// @Success      204
+// @Failure      400  {object}  transport.AppErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/period-locks/{period} [delete]
+func (h *Handler) UnlockPeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.Service.UnlockPeriod(r.Context(), chi.URLParam(r, "period"), user.ID); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package periodlock
+
+import (
+	"fmt"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	lockDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/periodlock"
+)
+
+// PeriodLayout formats a locked period, a calendar month in UTC.
+const PeriodLayout = "2006-01"
+
+// Lock closes a month: expenses dated in it can no longer be created or
+// changed until it is unlocked.
+type Lock struct {
+	Period   string    `json:"period"`
+	Reason   string    `json:"reason,omitempty"`
+	LockedBy int64     `json:"locked_by"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+type LockPeriodDTO struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+func (dto LockPeriodDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+type LocksResponse struct {
+	Locks []*Lock `json:"locks"`
+}
+
+var (
+	ErrLockNotFound  = errors.NewNotFoundError("Period is not locked", errors.ErrCodePeriodLockNotFound)
+	ErrInvalidPeriod = errors.NewValidationFieldError("period", "period must be formatted as YYYY-MM", errors.ErrCodeInvalidDate)
+)
+
+// ParsePeriod returns the first day of the month named by period (YYYY-MM).
+func ParsePeriod(period string) (time.Time, error) {
+	start, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}, ErrInvalidPeriod
+	}
+	return start, nil
+}
+
+// PeriodStart returns the first day of the month containing t, in UTC.
+func PeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func newPeriodLockedError(lock *Lock) *errors.AppError {
+	message := fmt.Sprintf("expenses dated in %s can no longer be created or changed: the period is locked", lock.Period)
+	return errors.NewValidationError(message, errors.ErrCodePeriodLocked).WithDetails(lock)
+}
+
+func fromDataModel(l *lockDatamodel.Lock) *Lock {
+	return &Lock{
+		Period:   l.PeriodStart.Format(PeriodLayout),
+		Reason:   l.Reason,
+		LockedBy: l.LockedBy,
+		LockedAt: l.LockedAt,
+	}
+}
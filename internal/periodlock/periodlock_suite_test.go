@@ -0,0 +1,13 @@
+package periodlock_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPeriodLock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Period Lock Suite")
+}
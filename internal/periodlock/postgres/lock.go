@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	lockDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/periodlock"
+	"github.com/frahmantamala/expense-management/internal/periodlock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LockRepository struct {
+	db *gorm.DB
+}
+
+func NewLockRepository(db *gorm.DB) periodlock.RepositoryAPI {
+	return &LockRepository{db: db}
+}
+
+func (r *LockRepository) List(ctx context.Context) ([]*lockDatamodel.Lock, error) {
+	var locks []*lockDatamodel.Lock
+	err := r.db.WithContext(ctx).Order("period_start DESC").Find(&locks).Error
+	return locks, err
+}
+
+// Periods are compared as YYYY-MM-DD so the DATE column is not shifted by
+// the session time zone.
+func (r *LockRepository) Get(ctx context.Context, periodStart time.Time) (*lockDatamodel.Lock, error) {
+	var lock lockDatamodel.Lock
+	err := r.db.WithContext(ctx).Where("period_start = ?", periodStart.Format(time.DateOnly)).First(&lock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+func (r *LockRepository) Create(ctx context.Context, lock *lockDatamodel.Lock) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *LockRepository) Delete(ctx context.Context, periodStart time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Where("period_start = ?", periodStart.Format(time.DateOnly)).Delete(&lockDatamodel.Lock{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,119 @@
+package periodlock
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	lockDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/periodlock"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the locks of the tenant ctx is scoped to.
+type RepositoryAPI interface {
+	List(ctx context.Context) ([]*lockDatamodel.Lock, error)
+	// Get returns nil when periodStart is not locked.
+	Get(ctx context.Context, periodStart time.Time) (*lockDatamodel.Lock, error)
+	// Create leaves an existing lock of the same period untouched and
+	// reports whether it inserted one.
+	Create(ctx context.Context, lock *lockDatamodel.Lock) (bool, error)
+	Delete(ctx context.Context, periodStart time.Time) (bool, error)
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) ListLocks(ctx context.Context) ([]*Lock, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list period locks: %w", err)
+	}
+
+	locks := make([]*Lock, len(rows))
+	for i, row := range rows {
+		locks[i] = fromDataModel(row)
+	}
+	return locks, nil
+}
+
+// LockPeriod locks period (YYYY-MM). Locking a period that is already locked
+// returns the existing lock.
+func (s *Service) LockPeriod(ctx context.Context, period string, lockedBy int64, dto LockPeriodDTO) (*Lock, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	start, err := ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &lockDatamodel.Lock{
+		PeriodStart: start,
+		Reason:      dto.Reason,
+		LockedBy:    lockedBy,
+	}
+	created, err := s.repo.Create(ctx, row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock period: %w", err)
+	}
+	if !created {
+		existing, err := s.repo.Get(ctx, start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load period lock: %w", err)
+		}
+		if existing != nil {
+			return fromDataModel(existing), nil
+		}
+	}
+
+	s.log(ctx).Info("period locked", "period", period, "locked_by", lockedBy)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) UnlockPeriod(ctx context.Context, period string, unlockedBy int64) error {
+	start, err := ParsePeriod(period)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, start)
+	if err != nil {
+		return fmt.Errorf("failed to unlock period: %w", err)
+	}
+	if !deleted {
+		return ErrLockNotFound
+	}
+
+	s.log(ctx).Info("period unlocked", "period", period, "unlocked_by", unlockedBy)
+	return nil
+}
+
+// CheckExpenseDate reports a PERIOD_LOCKED error when the month containing
+// expenseDate is locked.
+func (s *Service) CheckExpenseDate(ctx context.Context, expenseDate time.Time) error {
+	row, err := s.repo.Get(ctx, PeriodStart(expenseDate))
+	if err != nil {
+		return fmt.Errorf("failed to check period lock: %w", err)
+	}
+	if row == nil {
+		return nil
+	}
+
+	lock := fromDataModel(row)
+	s.log(ctx).Warn("expense change refused in locked period", "period", lock.Period, "expense_date", expenseDate)
+	return newPeriodLockedError(lock)
+}
@@ -0,0 +1,118 @@
+package periodlock_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	lockDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/periodlock"
+	"github.com/frahmantamala/expense-management/internal/periodlock"
+)
+
+type mockRepository struct {
+	locks map[time.Time]*lockDatamodel.Lock
+}
+
+func (m *mockRepository) List(_ context.Context) ([]*lockDatamodel.Lock, error) {
+	var out []*lockDatamodel.Lock
+	for _, l := range m.locks {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PeriodStart.After(out[j].PeriodStart) })
+	return out, nil
+}
+
+func (m *mockRepository) Get(_ context.Context, periodStart time.Time) (*lockDatamodel.Lock, error) {
+	return m.locks[periodStart], nil
+}
+
+func (m *mockRepository) Create(_ context.Context, lock *lockDatamodel.Lock) (bool, error) {
+	if _, ok := m.locks[lock.PeriodStart]; ok {
+		return false, nil
+	}
+	lock.LockedAt = time.Now()
+	m.locks[lock.PeriodStart] = lock
+	return true, nil
+}
+
+func (m *mockRepository) Delete(_ context.Context, periodStart time.Time) (bool, error) {
+	_, ok := m.locks[periodStart]
+	delete(m.locks, periodStart)
+	return ok, nil
+}
+
+var _ = Describe("Period lock service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *periodlock.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{locks: map[time.Time]*lockDatamodel.Lock{}}
+		service = periodlock.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("refuses expenses dated anywhere in a locked month", func() {
+		lock, err := service.LockPeriod(ctx, "2026-03", 9, periodlock.LockPeriodDTO{Reason: "Q1 close"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lock.Period).To(Equal("2026-03"))
+		Expect(lock.LockedBy).To(Equal(int64(9)))
+
+		for _, day := range []string{"2026-03-01", "2026-03-31"} {
+			date, _ := time.Parse(time.DateOnly, day)
+			err := service.CheckExpenseDate(ctx, date)
+			appErr, ok := errors.IsAppError(err)
+			Expect(ok).To(BeTrue(), day)
+			Expect(appErr.Code).To(Equal(errors.ErrCodePeriodLocked))
+		}
+
+		april, _ := time.Parse(time.DateOnly, "2026-04-01")
+		Expect(service.CheckExpenseDate(ctx, april)).To(Succeed())
+	})
+
+	It("keeps the first lock when a month is locked twice", func() {
+		_, err := service.LockPeriod(ctx, "2026-03", 9, periodlock.LockPeriodDTO{Reason: "Q1 close"})
+		Expect(err).NotTo(HaveOccurred())
+
+		lock, err := service.LockPeriod(ctx, "2026-03", 10, periodlock.LockPeriodDTO{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lock.LockedBy).To(Equal(int64(9)))
+		Expect(lock.Reason).To(Equal("Q1 close"))
+	})
+
+	It("lets expenses in again once unlocked", func() {
+		_, err := service.LockPeriod(ctx, "2026-03", 9, periodlock.LockPeriodDTO{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.UnlockPeriod(ctx, "2026-03", 9)).To(Succeed())
+		date, _ := time.Parse(time.DateOnly, "2026-03-15")
+		Expect(service.CheckExpenseDate(ctx, date)).To(Succeed())
+		Expect(service.UnlockPeriod(ctx, "2026-03", 9)).To(MatchError(periodlock.ErrLockNotFound))
+	})
+
+	It("lists locks newest first", func() {
+		for _, period := range []string{"2026-01", "2026-03", "2026-02"} {
+			_, err := service.LockPeriod(ctx, period, 9, periodlock.LockPeriodDTO{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		locks, err := service.ListLocks(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(locks).To(HaveLen(3))
+		Expect(locks[0].Period).To(Equal("2026-03"))
+		Expect(locks[2].Period).To(Equal("2026-01"))
+	})
+
+	It("rejects periods that are not YYYY-MM", func() {
+		_, err := service.LockPeriod(ctx, "March 2026", 9, periodlock.LockPeriodDTO{})
+		Expect(err).To(MatchError(periodlock.ErrInvalidPeriod))
+	})
+})
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	auditDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/audit"
+	bankaccountDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/bankaccount"
+	cardfeedDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/cardfeed"
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	expenseimportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expenseimport"
+	exportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/export"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/privacy"
+	"gorm.io/gorm"
+)
+
+// openExpenseStatuses still need the submitter's bank details.
+var openExpenseStatuses = []string{
+	expense.ExpenseStatusPendingApproval,
+	expense.ExpenseStatusApproved,
+	expense.ExpenseStatusProcessingPayment,
+	expense.ExpenseStatusPaymentFailed,
+}
+
+// finishedPaymentStatuses will not be sent to the gateway again.
+var finishedPaymentStatuses = []string{payment.StatusSuccess, payment.StatusFailed}
+
+// settledInboxStatuses will not be applied again.
+var settledInboxStatuses = []string{payment.InboxStatusProcessed, payment.InboxStatusRejected, payment.InboxStatusFailed}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) privacy.RepositoryAPI {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetUser(ctx context.Context, id int64) (*privacy.User, error) {
+	var u userDatamodel.User
+	err := r.db.WithContext(ctx).Select("id", "is_active").Where("id = ?", id).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &privacy.User{ID: u.ID, IsActive: u.IsActive}, nil
+}
+
+func (r *Repository) CountOpenExpenses(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Where("user_id = ? AND expense_status IN ?", userID, openExpenseStatuses).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) FileKeys(ctx context.Context, userID int64) ([]string, error) {
+	db := r.db.WithContext(ctx)
+	var keys []string
+	for _, q := range []struct {
+		model  interface{}
+		owner  string
+		column string
+	}{
+		{&expenseDatamodel.Expense{}, "user_id", "receipt_key"},
+		{&expenseDatamodel.Expense{}, "user_id", "receipt_thumbnail_key"},
+		{&userDatamodel.User{}, "id", "avatar_key"},
+		{&expenseimportDatamodel.Job{}, "user_id", "blob_key"},
+		{&exportDatamodel.Job{}, "user_id", "blob_key"},
+	} {
+		var found []string
+		err := db.Model(q.model).
+			Where(q.owner+" = ? AND "+q.column+" IS NOT NULL AND "+q.column+" <> ''", userID).
+			Pluck(q.column, &found).Error
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+	}
+	return keys, nil
+}
+
+func (r *Repository) Anonymize(ctx context.Context, erasure *privacy.Erasure) error {
+	userID := erasure.UserID
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&userDatamodel.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"name":                privacy.ErasedName(userID),
+			"email":               privacy.ErasedEmail(userID),
+			"password_hash":       privacy.ErasedPasswordHash,
+			"avatar_key":          nil,
+			"login_alerts":        false,
+			"export_emails":       false,
+			"sessions_revoked_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// The blank account number is written as an expression so it never
+		// passes through the encrypting serializer.
+		result := tx.Model(&bankaccountDatamodel.Account{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"account_holder":           privacy.ErasedName(userID),
+			"account_number_encrypted": gorm.Expr("''"),
+			"account_number_last4":     "",
+			"verification_note":        nil,
+			"is_default":               false,
+			"deleted_at":               gorm.Expr("COALESCE(deleted_at, NOW())"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.BankAccounts = result.RowsAffected
+
+		result = tx.Model(&expenseDatamodel.Expense{}).
+			Where("user_id = ? AND (receipt_url IS NOT NULL OR receipt_key IS NOT NULL OR receipt_thumbnail_key IS NOT NULL)", userID).
+			Updates(map[string]interface{}{
+				"receipt_url":             nil,
+				"receipt_filename":        nil,
+				"receipt_key":             nil,
+				"receipt_thumbnail_key":   nil,
+				"receipt_thumbnail_error": nil,
+				"receipt_sha256":          nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.Receipts = result.RowsAffected
+
+		result = tx.Model(&cardfeedDatamodel.Transaction{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"cardholder_email": privacy.ErasedEmail(userID),
+			"card_last4":       "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.CardTransactions = result.RowsAffected
+
+		userPayments := tx.Model(&paymentDatamodel.Payment{}).Select("id").
+			Where("expense_id IN (?)", tx.Model(&expenseDatamodel.Expense{}).Select("id").Where("user_id = ?", userID))
+		result = tx.Model(&paymentDatamodel.Payment{}).
+			Where("id IN (?) AND gateway_response IS NOT NULL", userPayments).
+			UpdateColumn("gateway_response", gorm.Expr("NULL"))
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.GatewayPayloads = result.RowsAffected
+		result = tx.Model(&paymentDatamodel.GatewayCall{}).
+			Where("payment_id IN (?) AND (request_body <> '' OR response_body <> '')", userPayments).
+			Updates(map[string]interface{}{"request_body": "", "response_body": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.GatewayPayloads += result.RowsAffected
+
+		result = tx.Table("login_sessions").Where("user_id = ?", userID).Delete(nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.LoginSessions = result.RowsAffected
+		return nil
+	})
+}
+
+// PurgeAuditLogs keeps the newest chained entry however old it is, so the
+// next entry still links to it and the hash chain carries on.
+func (r *Repository) PurgeAuditLogs(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ? AND (seq IS NULL OR seq < (SELECT MAX(seq) FROM audit_logs))", before).
+		Delete(&auditDatamodel.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *Repository) PurgeGatewayPayloads(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&paymentDatamodel.Payment{}).
+			Where("gateway_response IS NOT NULL AND status IN ? AND updated_at < ?", finishedPaymentStatuses, before).
+			UpdateColumn("gateway_response", gorm.Expr("NULL"))
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+
+		result = tx.Where("status IN ? AND updated_at < ?", settledInboxStatuses, before).
+			Delete(&paymentDatamodel.CallbackInboxEntry{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+		return nil
+	})
+	return purged, err
+}
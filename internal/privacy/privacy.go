@@ -0,0 +1,60 @@
+// Package privacy erases the personal data of employees who have left, and
+// purges records once they are past their retention. Erasure keeps the
+// user's expenses and payments, so amounts, categories and dates still add
+// up in reports and the ledger; only what identifies the person goes.
+package privacy
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserActive is returned for users who have not been deactivated
+	// yet; erasing someone still employed would lock them out for good.
+	ErrUserActive = errors.New("user is still active; deactivate them first")
+	// ErrOpenExpenses is returned while the user has expenses waiting for
+	// approval or payment, which need their bank details.
+	ErrOpenExpenses = errors.New("user has expenses awaiting approval or payment")
+)
+
+// ErasedPasswordHash matches no password, so an erased user cannot sign in
+// even if reactivated.
+const ErasedPasswordHash = "!"
+
+// ErasedName replaces the name of user id.
+func ErasedName(id int64) string {
+	return fmt.Sprintf("Erased user %d", id)
+}
+
+// ErasedEmail replaces the email of user id. It is unique, as users.email
+// must be, and cannot be delivered to.
+func ErasedEmail(id int64) string {
+	return fmt.Sprintf("erased-%d@erased.invalid", id)
+}
+
+// User is what erasure needs to know about the user being erased.
+type User struct {
+	ID       int64
+	IsActive bool
+}
+
+// Erasure counts the rows anonymized and files deleted for one user.
+type Erasure struct {
+	UserID       int64 `json:"user_id"`
+	BankAccounts int64 `json:"bank_accounts"`
+	Receipts     int64 `json:"receipts"`
+	// Files counts the stored receipts, thumbnails, avatar and import and
+	// export files deleted.
+	Files            int   `json:"files"`
+	CardTransactions int64 `json:"card_transactions"`
+	GatewayPayloads  int64 `json:"gateway_payloads"`
+	LoginSessions    int64 `json:"login_sessions"`
+}
+
+// Purge counts the rows purged past their retention.
+type Purge struct {
+	AuditLogs       int64 `json:"audit_logs"`
+	GatewayPayloads int64 `json:"gateway_payloads"`
+}
@@ -0,0 +1,183 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and rewrites personal data across tenants; erasure is
+// run by operators, outside any tenant.
+type RepositoryAPI interface {
+	// GetUser returns nil when there is no user with id.
+	GetUser(ctx context.Context, id int64) (*User, error)
+	// CountOpenExpenses counts the user's expenses awaiting approval or
+	// payment.
+	CountOpenExpenses(ctx context.Context, userID int64) (int64, error)
+	// FileKeys returns the storage keys of the user's receipts, receipt
+	// thumbnails, avatar, and expense import and export files.
+	FileKeys(ctx context.Context, userID int64) ([]string, error)
+	// Anonymize overwrites the user's personal data in one transaction and
+	// fills in the row counts of erasure.
+	Anonymize(ctx context.Context, erasure *Erasure) error
+	PurgeAuditLogs(ctx context.Context, before time.Time) (int64, error)
+	// PurgeGatewayPayloads clears the gateway responses of finished
+	// payments and deletes settled webhook inbox entries, both last
+	// touched before before.
+	PurgeGatewayPayloads(ctx context.Context, before time.Time) (int64, error)
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+type Service struct {
+	repo          RepositoryAPI
+	blob          storage.Blob
+	auditRecorder AuditRecorder
+	retention     internal.RetentionConfig
+	logger        *slog.Logger
+}
+
+// NewService takes a nil auditRecorder when erasures are only logged.
+func NewService(repo RepositoryAPI, blob storage.Blob, auditRecorder AuditRecorder, retention internal.RetentionConfig, logger *slog.Logger) *Service {
+	return &Service{
+		repo:          repo,
+		blob:          blob,
+		auditRecorder: auditRecorder,
+		retention:     retention,
+		logger:        logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// EraseUser anonymizes the personal data of a deactivated user: their name,
+// email and password, bank accounts, receipts, avatar, imported and exported
+// files, corporate card details, sign-in history and the gateway payloads of
+// their payments. Expenses and payments
+// keep their amounts, so totals do not change.
+//
+// Stored files are deleted before any row is touched; if a deletion fails
+// nothing has been anonymized and EraseUser can be run again.
+func (s *Service) EraseUser(ctx context.Context, userID int64) (*Erasure, error) {
+	u, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if u.IsActive {
+		return nil, ErrUserActive
+	}
+	open, err := s.repo.CountOpenExpenses(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open expenses: %w", err)
+	}
+	if open > 0 {
+		return nil, fmt.Errorf("%w: %d", ErrOpenExpenses, open)
+	}
+
+	keys, err := s.repo.FileKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored files: %w", err)
+	}
+	for _, key := range keys {
+		if err := s.blob.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+
+	erasure := &Erasure{UserID: userID, Files: len(keys)}
+	if err := s.repo.Anonymize(ctx, erasure); err != nil {
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	s.record(ctx, erasure)
+	s.log(ctx).Info("user erased",
+		"user_id", userID,
+		"bank_accounts", erasure.BankAccounts,
+		"receipts", erasure.Receipts,
+		"files", erasure.Files,
+		"card_transactions", erasure.CardTransactions,
+		"gateway_payloads", erasure.GatewayPayloads,
+		"login_sessions", erasure.LoginSessions)
+	return erasure, nil
+}
+
+// Purge deletes audit log entries and gateway payloads older than their
+// retention before now. A zero retention keeps them.
+func (s *Service) Purge(ctx context.Context, now time.Time) (*Purge, error) {
+	var purge Purge
+	if s.retention.AuditLogs > 0 {
+		n, err := s.repo.PurgeAuditLogs(ctx, now.Add(-s.retention.AuditLogs))
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge audit logs: %w", err)
+		}
+		purge.AuditLogs = n
+	}
+	if s.retention.GatewayPayloads > 0 {
+		n, err := s.repo.PurgeGatewayPayloads(ctx, now.Add(-s.retention.GatewayPayloads))
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge gateway payloads: %w", err)
+		}
+		purge.GatewayPayloads = n
+	}
+
+	if purge.AuditLogs > 0 || purge.GatewayPayloads > 0 {
+		s.log(ctx).Info("purged records past retention",
+			"audit_logs", purge.AuditLogs,
+			"gateway_payloads", purge.GatewayPayloads)
+	}
+	return &purge, nil
+}
+
+// record audits the erasure without an actor: it is run from the operator
+// CLI.
+func (s *Service) record(ctx context.Context, erasure *Erasure) {
+	if s.auditRecorder == nil {
+		return
+	}
+
+	entry := &audit.Entry{
+		Action:       audit.ActionUserErased,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   strconv.FormatInt(erasure.UserID, 10),
+		Metadata: map[string]interface{}{
+			"bank_accounts":     erasure.BankAccounts,
+			"receipts":          erasure.Receipts,
+			"files":             erasure.Files,
+			"card_transactions": erasure.CardTransactions,
+			"gateway_payloads":  erasure.GatewayPayloads,
+			"login_sessions":    erasure.LoginSessions,
+		},
+	}
+	if err := s.auditRecorder.Record(entry); err != nil {
+		s.log(ctx).Error("failed to record erasure audit entry", "error", err, "user_id", erasure.UserID)
+	}
+}
+
+const PurgeJobName = "retention_purge"
+
+// PurgeJob purges records past their retention on every run of schedule.
+func PurgeJob(s *Service, schedule scheduler.Schedule) scheduler.Job {
+	return scheduler.Job{
+		Name:     PurgeJobName,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			_, err := s.Purge(ctx, time.Now())
+			return err
+		},
+	}
+}
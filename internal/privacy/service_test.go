@@ -0,0 +1,186 @@
+package privacy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/privacy"
+	"github.com/frahmantamala/expense-management/internal/storage"
+)
+
+type mockRepository struct {
+	user        *privacy.User
+	open        int64
+	keys        []string
+	anonymized  []int64
+	auditBefore *time.Time
+	gwBefore    *time.Time
+}
+
+func (m *mockRepository) GetUser(_ context.Context, id int64) (*privacy.User, error) {
+	if m.user == nil || m.user.ID != id {
+		return nil, nil
+	}
+	return m.user, nil
+}
+
+func (m *mockRepository) CountOpenExpenses(_ context.Context, _ int64) (int64, error) {
+	return m.open, nil
+}
+
+func (m *mockRepository) FileKeys(_ context.Context, _ int64) ([]string, error) {
+	return m.keys, nil
+}
+
+func (m *mockRepository) Anonymize(_ context.Context, erasure *privacy.Erasure) error {
+	m.anonymized = append(m.anonymized, erasure.UserID)
+	erasure.BankAccounts = 1
+	erasure.Receipts = 2
+	return nil
+}
+
+func (m *mockRepository) PurgeAuditLogs(_ context.Context, before time.Time) (int64, error) {
+	m.auditBefore = &before
+	return 3, nil
+}
+
+func (m *mockRepository) PurgeGatewayPayloads(_ context.Context, before time.Time) (int64, error) {
+	m.gwBefore = &before
+	return 4, nil
+}
+
+type mockBlob struct {
+	storage.Blob
+	deleted []string
+	err     error
+}
+
+func (m *mockBlob) Delete(_ context.Context, key string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+type mockRecorder struct {
+	entries []*audit.Entry
+}
+
+func (m *mockRecorder) Record(entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx       context.Context
+		repo      *mockRepository
+		blob      *mockBlob
+		recorder  *mockRecorder
+		retention internal.RetentionConfig
+		svc       *privacy.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{
+			user: &privacy.User{ID: 42},
+			keys: []string{"receipts/42/a.pdf", "thumbnails/42/a.jpg", "avatars/42.png"},
+		}
+		blob = &mockBlob{}
+		recorder = &mockRecorder{}
+		retention = internal.RetentionConfig{}
+	})
+
+	JustBeforeEach(func() {
+		svc = privacy.NewService(repo, blob, recorder, retention, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	Describe("EraseUser", func() {
+		It("deletes the stored files, anonymizes the rows and audits the erasure", func() {
+			erasure, err := svc.EraseUser(ctx, 42)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blob.deleted).To(Equal(repo.keys))
+			Expect(repo.anonymized).To(Equal([]int64{42}))
+			Expect(erasure.Files).To(Equal(3))
+			Expect(erasure.Receipts).To(Equal(int64(2)))
+			Expect(recorder.entries).To(HaveLen(1))
+			Expect(recorder.entries[0].Action).To(Equal(audit.ActionUserErased))
+			Expect(recorder.entries[0].ResourceID).To(Equal("42"))
+			Expect(recorder.entries[0].ActorID).To(BeNil())
+		})
+
+		It("refuses an unknown user", func() {
+			_, err := svc.EraseUser(ctx, 7)
+
+			Expect(err).To(Equal(privacy.ErrUserNotFound))
+		})
+
+		It("refuses a user who is still active", func() {
+			repo.user.IsActive = true
+
+			_, err := svc.EraseUser(ctx, 42)
+
+			Expect(err).To(Equal(privacy.ErrUserActive))
+			Expect(blob.deleted).To(BeEmpty())
+			Expect(repo.anonymized).To(BeEmpty())
+		})
+
+		It("refuses a user with expenses awaiting approval or payment", func() {
+			repo.open = 2
+
+			_, err := svc.EraseUser(ctx, 42)
+
+			Expect(errors.Is(err, privacy.ErrOpenExpenses)).To(BeTrue())
+			Expect(repo.anonymized).To(BeEmpty())
+		})
+
+		It("anonymizes nothing when a file cannot be deleted", func() {
+			blob.err = errors.New("bucket unavailable")
+
+			_, err := svc.EraseUser(ctx, 42)
+
+			Expect(err).To(HaveOccurred())
+			Expect(repo.anonymized).To(BeEmpty())
+			Expect(recorder.entries).To(BeEmpty())
+		})
+	})
+
+	Describe("Purge", func() {
+		now := time.Date(2025, 11, 14, 3, 30, 0, 0, time.UTC)
+
+		It("keeps everything without a retention", func() {
+			purge, err := svc.Purge(ctx, now)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*purge).To(Equal(privacy.Purge{}))
+			Expect(repo.auditBefore).To(BeNil())
+			Expect(repo.gwBefore).To(BeNil())
+		})
+
+		Context("with retention periods", func() {
+			BeforeEach(func() {
+				retention = internal.RetentionConfig{AuditLogs: 365 * 24 * time.Hour, GatewayPayloads: 90 * 24 * time.Hour}
+			})
+
+			It("purges each kind of record past its own retention", func() {
+				purge, err := svc.Purge(ctx, now)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*purge).To(Equal(privacy.Purge{AuditLogs: 3, GatewayPayloads: 4}))
+				Expect(*repo.auditBefore).To(Equal(now.Add(-365 * 24 * time.Hour)))
+				Expect(*repo.gwBefore).To(Equal(now.Add(-90 * 24 * time.Hour)))
+			})
+		})
+	})
+})
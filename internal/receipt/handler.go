@@ -0,0 +1,276 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	Upload(ctx context.Context, expenseID, userID int64, userPermissions []string, upload Upload) (*ReceiptResponse, error)
+	Get(ctx context.Context, expenseID, userID int64, userPermissions []string) (*ReceiptResponse, error)
+	GetThumbnail(ctx context.Context, expenseID, userID int64, userPermissions []string) (*ReceiptResponse, error)
+	UploadURL(ctx context.Context, expenseID, userID int64, userPermissions []string, req UploadURLRequest) (*UploadURLResponse, error)
+	ConfirmUpload(ctx context.Context, expenseID, userID int64, userPermissions []string, req ConfirmUploadRequest) (*ReceiptResponse, error)
+	Share(ctx context.Context, expenseID, userID int64, userPermissions []string) (*capability.Link, error)
+	Shared(ctx context.Context, expenseID int64) (*ReceiptResponse, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// UploadReceipt godoc
+// @Summary      Upload expense receipt
+// @Description  Attaches a JPEG, PNG or PDF (at most 10 MB) to the caller's own expense, replacing any earlier receipt. Fails with PERIOD_LOCKED when the expense's month is locked.
+// @Tags         expenses
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int   true  "Expense ID"
+// @Param        file  formData  file  true  "Receipt file"
+// @Success      200   {object}  ReceiptResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /expenses/{id}/receipt [put]
+func (h *Handler) UploadReceipt(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	expenseID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxReceiptSize+transport.MultipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.HandleError(w, r, ErrReceiptTooLarge)
+			return
+		}
+		h.WriteError(w, r, http.StatusBadRequest, "multipart form with a file field is required")
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.Service.Upload(r.Context(), expenseID, user.ID, user.Permissions, Upload{
+		Filename: header.Filename,
+		Size:     header.Size,
+		Body:     file,
+	})
+	if err != nil {
+		h.Log(r).Error("UploadReceipt: service error", "error", err, "expense_id", expenseID, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetReceipt godoc
+// @Summary      Get expense receipt link
+// @Description  Returns a short-lived download URL for the expense's receipt. Visible to anyone who can view the expense. With size=thumb the URL is for a small JPEG preview instead: the receipt scaled down, or the first page of a PDF. Previews are rendered in the background shortly after upload; until then this fails with RECEIPT_PREVIEW_NOT_READY, and for receipts that cannot be previewed with RECEIPT_PREVIEW_UNAVAILABLE.
+// @Tags         expenses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int     true   "Expense ID"
+// @Param        size  query     string  false  "Preview instead of the original"  Enums(thumb)
+// @Success      200   {object}  ReceiptResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /expenses/{id}/receipt [get]
+func (h *Handler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	expenseID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
+		return
+	}
+
+	var resp *ReceiptResponse
+	switch size := r.URL.Query().Get("size"); size {
+	case "":
+		resp, err = h.Service.Get(r.Context(), expenseID, user.ID, user.Permissions)
+	case SizeThumb:
+		resp, err = h.Service.GetThumbnail(r.Context(), expenseID, user.ID, user.Permissions)
+	default:
+		h.HandleError(w, r, internal.NewValidationFieldError("size", "size must be "+SizeThumb, internal.ErrCodeValidationFailed))
+		return
+	}
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// CreateUploadURL godoc
+// @Summary      Get a direct receipt upload URL
+// @Description  Returns a short-lived URL the client PUTs the receipt file to, straight to storage, for photos too large to send through the API comfortably. content_type is image/jpeg, image/png or application/pdf. The file is attached only once confirmed with POST /expenses/{id}/receipt/confirm. Only the expense owner may upload; fails with PERIOD_LOCKED when the expense's month is locked.
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int               true  "Expense ID"
+// @Param        body  body      UploadURLRequest  true  "Receipt type"
+// @Success      200   {object}  UploadURLResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /expenses/{id}/receipt/upload-url [post]
+func (h *Handler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	expenseID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
+		return
+	}
+
+	var req UploadURLRequest
+	if !h.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	resp, err := h.Service.UploadURL(r.Context(), expenseID, user.ID, user.Permissions, req)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ConfirmUpload godoc
+// @Summary      Attach a directly uploaded receipt
+// @Description  Checks the file uploaded to the URL from POST /expenses/{id}/receipt/upload-url and attaches it to the expense, replacing any earlier receipt. The file must be at most 10 MB and its contents must match the declared content_type; otherwise it is deleted and the request fails.
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                   true  "Expense ID"
+// @Param        body  body      ConfirmUploadRequest  true  "Upload to attach"
+// @Success      200   {object}  ReceiptResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      403   {object}  transport.AppErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /expenses/{id}/receipt/confirm [post]
+func (h *Handler) ConfirmUpload(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	expenseID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
+		return
+	}
+
+	var req ConfirmUploadRequest
+	if !h.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	resp, err := h.Service.ConfirmUpload(r.Context(), expenseID, user.ID, user.Permissions, req)
+	if err != nil {
+		h.Log(r).Error("ConfirmUpload: service error", "error", err, "expense_id", expenseID, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ShareReceipt godoc
+// @Summary      Share expense receipt
+// @Description  Returns a short-lived link that shows the receipt to anyone who follows it, without a bearer token. The link only grants viewing this receipt. Visible to anyone who can view the expense.
+// @Tags         expenses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Expense ID"
+// @Success      200  {object}  capability.Link
+// @Failure      403  {object}  transport.AppErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /expenses/{id}/receipt/share [post]
+func (h *Handler) ShareReceipt(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	expenseID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
+		return
+	}
+
+	link, err := h.Service.Share(r.Context(), expenseID, user.ID, user.Permissions)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, link)
+}
+
+// OpenSharedReceipt godoc
+// @Summary      Open a shared receipt
+// @Description  Follows a link from POST /expenses/{id}/receipt/share and redirects to a short-lived download URL for the receipt. The token grants viewing this receipt only; no bearer token is needed.
+// @Tags         expenses
+// @Param        id     path   int     true  "Expense ID"
+// @Param        token  query  string  true  "Capability token"
+// @Success      302
+// @Failure      401  {object}  transport.AppErrorResponse
+// @Failure      403  {object}  transport.AppErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /shared/receipts/{id} [get]
+func (h *Handler) OpenSharedReceipt(w http.ResponseWriter, r *http.Request) {
+	expenseID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid expense ID")
+		return
+	}
+
+	resp, err := h.Service.Shared(r.Context(), expenseID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, resp.URL, http.StatusFound)
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	"github.com/frahmantamala/expense-management/internal/receipt"
+	"gorm.io/gorm"
+)
+
+type ReceiptRepository struct {
+	db *gorm.DB
+}
+
+func NewReceiptRepository(db *gorm.DB) receipt.RepositoryAPI {
+	return &ReceiptRepository{db: db}
+}
+
+func NewThumbnailRepository(db *gorm.DB) receipt.ThumbnailRepositoryAPI {
+	return &ReceiptRepository{db: db}
+}
+
+// SetReceipt clears the preview of the receipt it replaces, so the
+// thumbnail worker previews the new one.
+func (r *ReceiptRepository) SetReceipt(expenseID int64, key, filename, sha256 string) error {
+	result := r.db.Model(&expenseDatamodel.Expense{}).
+		Where("id = ?", expenseID).
+		Updates(map[string]interface{}{
+			"receipt_key":             key,
+			"receipt_filename":        filename,
+			"receipt_sha256":          sha256,
+			"receipt_thumbnail_key":   nil,
+			"receipt_thumbnail_error": nil,
+			"updated_at":              time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ReceiptRepository) GetReceipt(expenseID int64) (string, string, error) {
+	var row struct {
+		ReceiptKey      *string
+		ReceiptFilename *string
+	}
+	result := r.db.Model(&expenseDatamodel.Expense{}).
+		Select("receipt_key", "receipt_filename").
+		Where("id = ?", expenseID).
+		Limit(1).
+		Scan(&row)
+	if result.Error != nil {
+		return "", "", result.Error
+	}
+	if row.ReceiptKey == nil {
+		return "", "", nil
+	}
+	filename := ""
+	if row.ReceiptFilename != nil {
+		filename = *row.ReceiptFilename
+	}
+	return *row.ReceiptKey, filename, nil
+}
+
+func (r *ReceiptRepository) NextWithoutThumbnail() (*receipt.PendingThumbnail, error) {
+	var rows []struct {
+		ID         int64
+		ReceiptKey string
+	}
+	err := r.db.Model(&expenseDatamodel.Expense{}).
+		Select("id", "receipt_key").
+		Where("receipt_key IS NOT NULL AND receipt_thumbnail_key IS NULL AND receipt_thumbnail_error IS NULL").
+		Order("id").
+		Limit(1).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &receipt.PendingThumbnail{ExpenseID: rows[0].ID, ReceiptKey: rows[0].ReceiptKey}, nil
+}
+
+func (r *ReceiptRepository) SetThumbnail(expenseID int64, receiptKey, thumbnailKey string) (bool, error) {
+	return r.setThumbnail(expenseID, receiptKey, map[string]interface{}{"receipt_thumbnail_key": thumbnailKey})
+}
+
+func (r *ReceiptRepository) SetThumbnailError(expenseID int64, receiptKey, message string) (bool, error) {
+	return r.setThumbnail(expenseID, receiptKey, map[string]interface{}{"receipt_thumbnail_error": message})
+}
+
+// setThumbnail leaves updated_at alone: a preview does not change the
+// expense.
+func (r *ReceiptRepository) setThumbnail(expenseID int64, receiptKey string, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&expenseDatamodel.Expense{}).
+		Where("id = ? AND receipt_key = ?", expenseID, receiptKey).
+		UpdateColumns(updates)
+	return result.RowsAffected > 0, result.Error
+}
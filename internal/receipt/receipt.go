@@ -0,0 +1,89 @@
+package receipt
+
+import (
+	"io"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+)
+
+const (
+	// MaxReceiptSize bounds uploads; larger bodies are rejected unread.
+	MaxReceiptSize = 10 << 20
+	// downloadURLExpiry is how long a receipt link handed to a client works.
+	downloadURLExpiry = 15 * time.Minute
+	// uploadURLExpiry is how long a client has to upload to storage directly.
+	uploadURLExpiry = 15 * time.Minute
+)
+
+// SizeThumb asks the receipt download endpoint for the preview.
+const SizeThumb = "thumb"
+
+// SharedPath is where a shared receipt link points, formatted with the
+// expense ID.
+const SharedPath = "/api/v1/shared/receipts/%d"
+
+// allowedTypes maps sniffed content types to the extension stored in the key.
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+var (
+	ErrReceiptNotFound    = errors.NewNotFoundError("Expense has no receipt", errors.ErrCodeReceiptNotFound)
+	ErrPreviewNotReady    = errors.NewNotFoundError("Receipt preview is not ready yet", errors.ErrCodePreviewNotReady)
+	ErrPreviewUnavailable = errors.NewNotFoundError("Receipt cannot be previewed", errors.ErrCodeNoPreview)
+	ErrUnsupportedReceipt = errors.NewValidationFieldError("file", "receipt must be a JPEG, PNG or PDF file", errors.ErrCodeInvalidReceipt)
+	ErrReceiptTooLarge    = errors.NewValidationFieldError("file", "receipt must be at most 10 MB", errors.ErrCodeInvalidReceipt)
+	ErrUploadNotFound     = errors.NewValidationFieldError("upload_id", "no file was uploaded for this upload_id", errors.ErrCodeInvalidReceipt)
+	ErrUploadMismatch     = errors.NewValidationFieldError("upload_id", "uploaded file does not match the declared content_type", errors.ErrCodeInvalidReceipt)
+)
+
+type Upload struct {
+	Filename string
+	Size     int64
+	Body     io.Reader
+}
+
+type ReceiptResponse struct {
+	Filename  string    `json:"filename"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UploadURLRequest declares the type of a receipt the client will upload
+// straight to storage.
+type UploadURLRequest struct {
+	ContentType string `json:"content_type" validate:"required,oneof=image/jpeg image/png application/pdf"`
+}
+
+func (r UploadURLRequest) Validate() error {
+	if appErr := validation.Struct(r); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// UploadURLResponse tells the client where to PUT the file. The upload is
+// only attached to the expense once it is confirmed with UploadID.
+type UploadURLResponse struct {
+	UploadID  string    `json:"upload_id"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	MaxBytes  int64     `json:"max_bytes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ConfirmUploadRequest struct {
+	UploadID string `json:"upload_id" validate:"required,max=64"`
+	Filename string `json:"filename" validate:"max=255"`
+}
+
+func (r ConfirmUploadRequest) Validate() error {
+	if appErr := validation.Struct(r); appErr != nil {
+		return appErr
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package receipt
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/google/uuid"
+)
+
+type RepositoryAPI interface {
+	// SetReceipt stores the receipt's key, name and hex SHA-256.
+	SetReceipt(expenseID int64, key, filename, sha256 string) error
+	// GetReceipt returns an empty key when the expense has no receipt.
+	GetReceipt(expenseID int64) (key, filename string, err error)
+}
+
+// ExpenseReader loads an expense after checking the caller may see it;
+// expense.QueryService satisfies it.
+type ExpenseReader interface {
+	GetExpenseByID(ctx context.Context, id, userID int64, userPermissions []string) (*expense.Expense, error)
+}
+
+type Service struct {
+	repo     RepositoryAPI
+	expenses ExpenseReader
+	periods  expense.PeriodGuard
+	blob     storage.Blob
+	links    *capability.Issuer
+	logger   *slog.Logger
+}
+
+// NewService takes a nil periods when months are never locked.
+func NewService(repo RepositoryAPI, expenses ExpenseReader, periods expense.PeriodGuard, blob storage.Blob, links *capability.Issuer, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		expenses: expenses,
+		periods:  periods,
+		blob:     blob,
+		links:    links,
+		logger:   logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Upload stores a receipt for the caller's own expense, replacing any earlier
+// one. The file type is sniffed from its contents rather than trusted from
+// the client.
+func (s *Service) Upload(ctx context.Context, expenseID, userID int64, userPermissions []string, upload Upload) (*ReceiptResponse, error) {
+	exp, err := s.ownExpense(ctx, expenseID, userID, userPermissions)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Size > MaxReceiptSize {
+		return nil, ErrReceiptTooLarge
+	}
+
+	body := bufio.NewReaderSize(upload.Body, 512)
+	head, _ := body.Peek(512)
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedReceipt
+	}
+
+	key := fmt.Sprintf("receipts/%d/%s%s", expenseID, uuid.NewString(), ext)
+	hash := sha256.New()
+	if err := s.blob.Put(ctx, key, io.TeeReader(body, hash), storage.PutOptions{ContentType: contentType, Size: upload.Size}); err != nil {
+		return nil, fmt.Errorf("failed to store receipt: %w", err)
+	}
+
+	resp, err := s.attach(ctx, exp, key, upload.Filename, ext, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return nil, err
+	}
+	s.log(ctx).Info("receipt uploaded", "expense_id", expenseID, "user_id", userID, "content_type", contentType)
+	return resp, nil
+}
+
+// UploadURL lets the owner upload a receipt straight to storage, which suits
+// large photos from mobile clients. Nothing changes on the expense until the
+// upload is confirmed with ConfirmUpload.
+func (s *Service) UploadURL(ctx context.Context, expenseID, userID int64, userPermissions []string, req UploadURLRequest) (*UploadURLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.ownExpense(ctx, expenseID, userID, userPermissions); err != nil {
+		return nil, err
+	}
+
+	uploadID := uuid.NewString() + allowedTypes[req.ContentType]
+	url, err := s.blob.SignedUploadURL(ctx, uploadKey(expenseID, uploadID), MaxReceiptSize, uploadURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receipt upload url: %w", err)
+	}
+
+	return &UploadURLResponse{
+		UploadID:  uploadID,
+		Method:    http.MethodPut,
+		URL:       url,
+		MaxBytes:  MaxReceiptSize,
+		ExpiresAt: time.Now().Add(uploadURLExpiry).UTC(),
+	}, nil
+}
+
+// ConfirmUpload checks the file uploaded through UploadURL and attaches it
+// to the expense, replacing any earlier receipt. Files that are too large or
+// whose contents do not match the declared type are deleted. Confirming the
+// current receipt again is a no-op.
+func (s *Service) ConfirmUpload(ctx context.Context, expenseID, userID int64, userPermissions []string, req ConfirmUploadRequest) (*ReceiptResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	exp, err := s.ownExpense(ctx, expenseID, userID, userPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	ext, ok := parseUploadID(req.UploadID)
+	if !ok {
+		return nil, ErrUploadNotFound
+	}
+	key := uploadKey(expenseID, req.UploadID)
+	if exp.ReceiptKey != nil && *exp.ReceiptKey == key {
+		filename := ""
+		if exp.ReceiptFileName != nil {
+			filename = *exp.ReceiptFileName
+		}
+		return s.response(ctx, key, filename)
+	}
+
+	contentType, size, sum, err := s.inspect(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var invalid error
+	switch {
+	case size > MaxReceiptSize:
+		invalid = ErrReceiptTooLarge
+	case allowedTypes[contentType] == "":
+		invalid = ErrUnsupportedReceipt
+	case allowedTypes[contentType] != ext:
+		invalid = ErrUploadMismatch
+	}
+	if invalid != nil {
+		if err := s.blob.Delete(ctx, key); err != nil {
+			s.log(ctx).Warn("failed to remove rejected receipt upload", "error", err, "key", key)
+		}
+		return nil, invalid
+	}
+
+	resp, err := s.attach(ctx, exp, key, req.Filename, ext, sum)
+	if err != nil {
+		return nil, err
+	}
+	s.log(ctx).Info("receipt upload confirmed", "expense_id", expenseID, "user_id", userID, "content_type", contentType, "size", size)
+	return resp, nil
+}
+
+// ownExpense loads an expense the caller owns and may still change.
+func (s *Service) ownExpense(ctx context.Context, expenseID, userID int64, userPermissions []string) (*expense.Expense, error) {
+	exp, err := s.expenses.GetExpenseByID(ctx, expenseID, userID, userPermissions)
+	if err != nil {
+		return nil, err
+	}
+	if exp.UserID != userID {
+		return nil, expense.ErrUnauthorizedAccess
+	}
+	if s.periods != nil {
+		if err := s.periods.CheckExpenseDate(ctx, exp.ExpenseDate); err != nil {
+			return nil, err
+		}
+	}
+	return exp, nil
+}
+
+// inspect sniffs the type of a stored object, measures it and hashes it,
+// reading no more than one byte past the size limit.
+func (s *Service) inspect(ctx context.Context, key string) (string, int64, string, error) {
+	rc, err := s.blob.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", 0, "", ErrUploadNotFound
+	}
+	if err != nil {
+		return "", 0, "", fmt.Errorf("failed to read receipt upload: %w", err)
+	}
+	defer rc.Close()
+
+	hash := sha256.New()
+	body := io.TeeReader(io.LimitReader(rc, MaxReceiptSize+1), hash)
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", 0, "", fmt.Errorf("failed to read receipt upload: %w", err)
+	}
+	rest, err := io.Copy(io.Discard, body)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("failed to read receipt upload: %w", err)
+	}
+	return http.DetectContentType(head[:n]), int64(n) + rest, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// attach points the expense at the stored receipt key and removes the
+// receipt it replaces. The stored object is removed if that fails.
+func (s *Service) attach(ctx context.Context, exp *expense.Expense, key, filename, ext, sum string) (*ReceiptResponse, error) {
+	previous := exp.ReceiptKey
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = "receipt" + ext
+	}
+	if err := s.repo.SetReceipt(exp.ID, key, filename, sum); err != nil {
+		if delErr := s.blob.Delete(ctx, key); delErr != nil {
+			s.log(ctx).Warn("failed to remove orphaned receipt", "error", delErr, "key", key)
+		}
+		return nil, fmt.Errorf("failed to save receipt: %w", err)
+	}
+
+	if previous != nil {
+		if err := s.blob.Delete(ctx, *previous); err != nil {
+			s.log(ctx).Warn("failed to remove replaced receipt", "error", err, "key", *previous)
+		}
+		if err := s.blob.Delete(ctx, ThumbnailKey(*previous)); err != nil {
+			s.log(ctx).Warn("failed to remove preview of replaced receipt", "error", err, "key", ThumbnailKey(*previous))
+		}
+	}
+
+	return s.response(ctx, key, filename)
+}
+
+// Get returns a short-lived download link for anyone who can view the expense.
+func (s *Service) Get(ctx context.Context, expenseID, userID int64, userPermissions []string) (*ReceiptResponse, error) {
+	exp, err := s.expenses.GetExpenseByID(ctx, expenseID, userID, userPermissions)
+	if err != nil {
+		return nil, err
+	}
+	if exp.ReceiptKey == nil {
+		return nil, ErrReceiptNotFound
+	}
+
+	filename := ""
+	if exp.ReceiptFileName != nil {
+		filename = *exp.ReceiptFileName
+	}
+	return s.response(ctx, *exp.ReceiptKey, filename)
+}
+
+// GetThumbnail returns a short-lived download link for the receipt's
+// preview, for anyone who can view the expense. It fails with
+// ErrPreviewNotReady until the thumbnail worker has rendered it.
+func (s *Service) GetThumbnail(ctx context.Context, expenseID, userID int64, userPermissions []string) (*ReceiptResponse, error) {
+	exp, err := s.expenses.GetExpenseByID(ctx, expenseID, userID, userPermissions)
+	if err != nil {
+		return nil, err
+	}
+	if exp.ReceiptKey == nil {
+		return nil, ErrReceiptNotFound
+	}
+	if exp.ReceiptThumbnailKey == nil {
+		if exp.ReceiptThumbnailError != nil {
+			return nil, ErrPreviewUnavailable
+		}
+		return nil, ErrPreviewNotReady
+	}
+
+	filename := ""
+	if exp.ReceiptFileName != nil {
+		filename = *exp.ReceiptFileName
+	}
+	return s.response(ctx, *exp.ReceiptThumbnailKey, thumbnailFilename(filename))
+}
+
+// Share returns a link that shows the receipt to whoever follows it, for
+// anyone who can view the expense. The link works until the capability
+// token in it expires.
+func (s *Service) Share(ctx context.Context, expenseID, userID int64, userPermissions []string) (*capability.Link, error) {
+	exp, err := s.expenses.GetExpenseByID(ctx, expenseID, userID, userPermissions)
+	if err != nil {
+		return nil, err
+	}
+	if exp.ReceiptKey == nil {
+		return nil, ErrReceiptNotFound
+	}
+
+	link, err := s.links.Link(ctx, capability.ScopeReceiptView, expenseID, userID, fmt.Sprintf(SharedPath, expenseID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue receipt link: %w", err)
+	}
+	s.log(ctx).Info("receipt link shared", "expense_id", expenseID, "user_id", userID, "expires_at", link.ExpiresAt)
+	return link, nil
+}
+
+// Shared returns a short-lived download link for a request let in by a
+// receipt:view capability token; the token has already been checked.
+func (s *Service) Shared(ctx context.Context, expenseID int64) (*ReceiptResponse, error) {
+	key, filename, err := s.repo.GetReceipt(expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load receipt: %w", err)
+	}
+	if key == "" {
+		return nil, ErrReceiptNotFound
+	}
+	return s.response(ctx, key, filename)
+}
+
+func (s *Service) response(ctx context.Context, key, filename string) (*ReceiptResponse, error) {
+	url, err := s.blob.SignedURL(ctx, key, downloadURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receipt url: %w", err)
+	}
+	return &ReceiptResponse{
+		Filename:  filename,
+		URL:       url,
+		ExpiresAt: time.Now().Add(downloadURLExpiry).UTC(),
+	}, nil
+}
+
+// uploadKey is where a direct upload is stored; it is also the receipt's key
+// once confirmed.
+func uploadKey(expenseID int64, uploadID string) string {
+	return fmt.Sprintf("receipts/%d/%s", expenseID, uploadID)
+}
+
+// parseUploadID checks that id is a UUID followed by a receipt extension,
+// as issued by UploadURL, and returns the extension.
+func parseUploadID(id string) (string, bool) {
+	ext := filepath.Ext(id)
+	base := strings.TrimSuffix(id, ext)
+	if parsed, err := uuid.Parse(base); err != nil || parsed.String() != base {
+		return "", false
+	}
+	for _, allowed := range allowedTypes {
+		if ext == allowed {
+			return ext, true
+		}
+	}
+	return "", false
+}
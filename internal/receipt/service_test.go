@@ -0,0 +1,323 @@
+package receipt_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/receipt"
+	"github.com/frahmantamala/expense-management/internal/storage"
+)
+
+type mockExpenseReader struct {
+	expenses map[int64]*expense.Expense
+}
+
+func (m *mockExpenseReader) GetExpenseByID(ctx context.Context, id, userID int64, userPermissions []string) (*expense.Expense, error) {
+	e, ok := m.expenses[id]
+	if !ok {
+		return nil, expense.ErrExpenseNotFound
+	}
+	return e, nil
+}
+
+type mockRepository struct {
+	reader *mockExpenseReader
+	err    error
+}
+
+func (m *mockRepository) SetReceipt(expenseID int64, key, filename, sha256 string) error {
+	if m.err != nil {
+		return m.err
+	}
+	e := m.reader.expenses[expenseID]
+	e.ReceiptKey, e.ReceiptFileName, e.ReceiptSHA256 = &key, &filename, &sha256
+	e.ReceiptThumbnailKey, e.ReceiptThumbnailError = nil, nil
+	return nil
+}
+
+func (m *mockRepository) GetReceipt(expenseID int64) (string, string, error) {
+	e, ok := m.reader.expenses[expenseID]
+	if !ok || e.ReceiptKey == nil {
+		return "", "", nil
+	}
+	return *e.ReceiptKey, *e.ReceiptFileName, nil
+}
+
+type mockPeriodGuard struct {
+	locked bool
+}
+
+var errPeriodLocked = errors.New("period locked")
+
+func (m *mockPeriodGuard) CheckExpenseDate(context.Context, time.Time) error {
+	if m.locked {
+		return errPeriodLocked
+	}
+	return nil
+}
+
+var pdf = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+
+var _ = Describe("Receipt Service", func() {
+	var (
+		ctx     context.Context
+		reader  *mockExpenseReader
+		repo    *mockRepository
+		periods *mockPeriodGuard
+		blob    *storage.Local
+		links   *capability.Issuer
+		service *receipt.Service
+	)
+
+	upload := func(body []byte) receipt.Upload {
+		return receipt.Upload{Filename: "../taxi.pdf", Size: int64(len(body)), Body: bytes.NewReader(body)}
+	}
+
+	stored := func(key string) bool {
+		rc, err := blob.Get(ctx, key)
+		if err != nil {
+			return false
+		}
+		rc.Close()
+		return true
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reader = &mockExpenseReader{expenses: map[int64]*expense.Expense{
+			1: {ID: 1, UserID: 10},
+		}}
+		repo = &mockRepository{reader: reader}
+		periods = &mockPeriodGuard{}
+
+		var err error
+		blob, err = storage.NewLocal(GinkgoT().TempDir(), "http://localhost:8080", "secret")
+		Expect(err).NotTo(HaveOccurred())
+		links = capability.NewIssuer("capability-secret", "http://localhost:8080", 15*time.Minute)
+		service = receipt.NewService(repo, reader, periods, blob, links, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("stores the file and returns a signed link", func() {
+		resp, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Filename).To(Equal("taxi.pdf"))
+		Expect(resp.URL).To(HavePrefix("http://localhost:8080/files/receipts/1/"))
+		Expect(resp.URL).To(ContainSubstring(".pdf?expires="))
+
+		key := *reader.expenses[1].ReceiptKey
+		Expect(key).To(HavePrefix("receipts/1/"))
+		rc, err := blob.Get(ctx, key)
+		Expect(err).NotTo(HaveOccurred())
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		Expect(body).To(Equal(pdf))
+		sum := sha256.Sum256(pdf)
+		Expect(*reader.expenses[1].ReceiptSHA256).To(Equal(hex.EncodeToString(sum[:])))
+	})
+
+	It("removes the previous receipt when replacing it", func() {
+		_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+		Expect(err).NotTo(HaveOccurred())
+		first := *reader.expenses[1].ReceiptKey
+
+		_, err = service.Upload(ctx, 1, 10, nil, upload(pdf))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*reader.expenses[1].ReceiptKey).NotTo(Equal(first))
+		Expect(stored(first)).To(BeFalse())
+	})
+
+	It("rejects files that are not images or PDFs", func() {
+		_, err := service.Upload(ctx, 1, 10, nil, upload([]byte("#!/bin/sh\nrm -rf /\n")))
+		Expect(err).To(Equal(receipt.ErrUnsupportedReceipt))
+	})
+
+	It("rejects files over the size limit", func() {
+		_, err := service.Upload(ctx, 1, 10, nil, receipt.Upload{Size: receipt.MaxReceiptSize + 1, Body: strings.NewReader("")})
+		Expect(err).To(Equal(receipt.ErrReceiptTooLarge))
+	})
+
+	It("only lets the owner upload", func() {
+		_, err := service.Upload(ctx, 1, 99, []string{"admin"}, upload(pdf))
+		Expect(err).To(Equal(expense.ErrUnauthorizedAccess))
+	})
+
+	It("refuses receipts for expenses in a locked month", func() {
+		periods.locked = true
+		_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+		Expect(err).To(MatchError(errPeriodLocked))
+		Expect(reader.expenses[1].ReceiptKey).To(BeNil())
+	})
+
+	It("cleans up the stored file when saving the key fails", func() {
+		repo.err = errors.New("db down")
+		_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+		Expect(err).To(HaveOccurred())
+		Expect(reader.expenses[1].ReceiptKey).To(BeNil())
+	})
+
+	Describe("Get", func() {
+		It("returns not found for an expense without a receipt", func() {
+			_, err := service.Get(ctx, 1, 10, nil)
+			Expect(err).To(Equal(receipt.ErrReceiptNotFound))
+		})
+
+		It("signs a link to the stored receipt", func() {
+			_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := service.Get(ctx, 1, 20, []string{"view_all_expenses"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Filename).To(Equal("taxi.pdf"))
+			Expect(resp.URL).To(ContainSubstring(*reader.expenses[1].ReceiptKey))
+		})
+	})
+
+	Describe("GetThumbnail", func() {
+		BeforeEach(func() {
+			_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("is not ready until the worker has rendered the preview", func() {
+			_, err := service.GetThumbnail(ctx, 1, 10, nil)
+			Expect(err).To(Equal(receipt.ErrPreviewNotReady))
+		})
+
+		It("is unavailable for receipts that could not be previewed", func() {
+			reason := "thumbnail: receipt type cannot be previewed"
+			reader.expenses[1].ReceiptThumbnailError = &reason
+
+			_, err := service.GetThumbnail(ctx, 1, 10, nil)
+			Expect(err).To(Equal(receipt.ErrPreviewUnavailable))
+		})
+
+		It("signs a link to the preview", func() {
+			key := receipt.ThumbnailKey(*reader.expenses[1].ReceiptKey)
+			reader.expenses[1].ReceiptThumbnailKey = &key
+
+			resp, err := service.GetThumbnail(ctx, 1, 20, []string{"view_all_expenses"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Filename).To(Equal("taxi.thumb.jpg"))
+			Expect(resp.URL).To(ContainSubstring(key))
+		})
+
+		It("removes the preview with the receipt it replaces", func() {
+			key := receipt.ThumbnailKey(*reader.expenses[1].ReceiptKey)
+			Expect(blob.Put(ctx, key, strings.NewReader("jpeg"), storage.PutOptions{ContentType: "image/jpeg"})).To(Succeed())
+			reader.expenses[1].ReceiptThumbnailKey = &key
+
+			_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored(key)).To(BeFalse())
+			Expect(reader.expenses[1].ReceiptThumbnailKey).To(BeNil())
+		})
+	})
+
+	Describe("sharing", func() {
+		It("issues a receipt:view link for anyone who can view the expense", func() {
+			_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+			Expect(err).NotTo(HaveOccurred())
+
+			link, err := service.Share(ctx, 1, 20, []string{"view_all_expenses"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Scope).To(Equal(capability.ScopeReceiptView))
+			Expect(link.URL).To(HavePrefix("http://localhost:8080/api/v1/shared/receipts/1?token="))
+
+			u, err := url.Parse(link.URL)
+			Expect(err).NotTo(HaveOccurred())
+			claims, err := links.Parse(u.Query().Get("token"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims.ResourceID).To(Equal(int64(1)))
+			Expect(claims.Subject).To(Equal(int64(20)))
+		})
+
+		It("refuses to share a missing receipt", func() {
+			_, err := service.Share(ctx, 1, 10, nil)
+			Expect(err).To(Equal(receipt.ErrReceiptNotFound))
+
+			_, err = service.Share(ctx, 2, 10, nil)
+			Expect(err).To(Equal(expense.ErrExpenseNotFound))
+		})
+
+		It("signs a download link for a shared receipt", func() {
+			_, err := service.Upload(ctx, 1, 10, nil, upload(pdf))
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := service.Shared(ctx, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Filename).To(Equal("taxi.pdf"))
+			Expect(resp.URL).To(ContainSubstring(*reader.expenses[1].ReceiptKey))
+		})
+	})
+
+	Describe("direct uploads", func() {
+		put := func(resp *receipt.UploadURLResponse, body []byte) {
+			signed, err := url.Parse(resp.URL)
+			Expect(err).NotTo(HaveOccurred())
+			rec := httptest.NewRecorder()
+			blob.Handler().ServeHTTP(rec, httptest.NewRequest(resp.Method, signed.RequestURI(), bytes.NewReader(body)))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		}
+
+		It("attaches the uploaded file once confirmed", func() {
+			resp, err := service.UploadURL(ctx, 1, 10, nil, receipt.UploadURLRequest{ContentType: "application/pdf"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.UploadID).To(HaveSuffix(".pdf"))
+			Expect(resp.MaxBytes).To(Equal(int64(receipt.MaxReceiptSize)))
+			put(resp, pdf)
+			Expect(reader.expenses[1].ReceiptKey).To(BeNil())
+
+			confirmed, err := service.ConfirmUpload(ctx, 1, 10, nil, receipt.ConfirmUploadRequest{UploadID: resp.UploadID, Filename: "taxi.pdf"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(confirmed.Filename).To(Equal("taxi.pdf"))
+			Expect(*reader.expenses[1].ReceiptKey).To(Equal("receipts/1/" + resp.UploadID))
+			sum := sha256.Sum256(pdf)
+			Expect(*reader.expenses[1].ReceiptSHA256).To(Equal(hex.EncodeToString(sum[:])))
+
+			_, err = service.ConfirmUpload(ctx, 1, 10, nil, receipt.ConfirmUploadRequest{UploadID: resp.UploadID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored("receipts/1/" + resp.UploadID)).To(BeTrue())
+		})
+
+		It("deletes uploads whose contents do not match the declared type", func() {
+			resp, err := service.UploadURL(ctx, 1, 10, nil, receipt.UploadURLRequest{ContentType: "image/png"})
+			Expect(err).NotTo(HaveOccurred())
+			put(resp, pdf)
+
+			_, err = service.ConfirmUpload(ctx, 1, 10, nil, receipt.ConfirmUploadRequest{UploadID: resp.UploadID})
+			Expect(err).To(Equal(receipt.ErrUploadMismatch))
+			Expect(stored("receipts/1/" + resp.UploadID)).To(BeFalse())
+			Expect(reader.expenses[1].ReceiptKey).To(BeNil())
+		})
+
+		It("refuses upload IDs that were not issued or not uploaded", func() {
+			for _, id := range []string{"../2/secret.pdf", "invoice.pdf", "6ba7b810-9dad-11d1-80b4-00c04fd430c8.exe", "6ba7b810-9dad-11d1-80b4-00c04fd430c8.pdf"} {
+				_, err := service.ConfirmUpload(ctx, 1, 10, nil, receipt.ConfirmUploadRequest{UploadID: id})
+				Expect(err).To(Equal(receipt.ErrUploadNotFound), id)
+			}
+		})
+
+		It("only lets the owner upload a receipt type we accept", func() {
+			_, err := service.UploadURL(ctx, 1, 99, []string{"admin"}, receipt.UploadURLRequest{ContentType: "application/pdf"})
+			Expect(err).To(Equal(expense.ErrUnauthorizedAccess))
+
+			_, err = service.UploadURL(ctx, 1, 10, nil, receipt.UploadURLRequest{ContentType: "text/html"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
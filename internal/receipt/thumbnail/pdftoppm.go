@@ -0,0 +1,40 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Pdftoppm renders PDFs with poppler's pdftoppm, run as Path; the binary
+// is not part of this service and must be installed next to it.
+type Pdftoppm struct {
+	Path string
+}
+
+func (p Pdftoppm) FirstPage(ctx context.Context, pdf []byte, size int) (image.Image, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path,
+		"-f", "1", "-l", "1", "-singlefile", "-png",
+		"-scale-to", strconv.Itoa(size),
+		"-", "-")
+	cmd.Stdin = bytes.NewReader(pdf)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("pdftoppm failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("pdftoppm failed: %w", err)
+	}
+	img, err := png.Decode(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdftoppm output: %w", err)
+	}
+	return img, nil
+}
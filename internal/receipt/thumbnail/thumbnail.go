@@ -0,0 +1,146 @@
+// Package thumbnail renders small JPEG previews of receipts for the approval
+// UI: images are scaled down, and PDFs are previewed by their first page.
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	// DefaultSize is the longest side of a preview when no size is given.
+	DefaultSize = 320
+	// ContentType is the type of every preview.
+	ContentType = "image/jpeg"
+	// maxPixels refuses images whose decoded form would take too much
+	// memory, whatever their file size.
+	maxPixels = 50_000_000
+	quality   = 80
+)
+
+var (
+	// ErrUnsupported is returned for receipts that cannot be previewed,
+	// such as PDFs when no PDF renderer is configured.
+	ErrUnsupported = errors.New("thumbnail: receipt type cannot be previewed")
+	ErrTooLarge    = errors.New("thumbnail: image is too large to preview")
+)
+
+// PDFRenderer renders the first page of a PDF, at least size pixels on its
+// longest side.
+type PDFRenderer interface {
+	FirstPage(ctx context.Context, pdf []byte, size int) (image.Image, error)
+}
+
+type Renderer struct {
+	size int
+	pdf  PDFRenderer
+}
+
+// New renders previews size pixels on their longest side, DefaultSize when
+// zero. pdf may be nil, in which case PDFs fail with ErrUnsupported.
+func New(size int, pdf PDFRenderer) *Renderer {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Renderer{size: size, pdf: pdf}
+}
+
+// Render returns a JPEG preview of a JPEG, PNG or PDF receipt. Other types
+// fail with ErrUnsupported.
+func (r *Renderer) Render(ctx context.Context, receipt []byte) ([]byte, error) {
+	var (
+		img image.Image
+		err error
+	)
+	switch http.DetectContentType(receipt) {
+	case "image/jpeg", "image/png":
+		img, err = decode(receipt)
+	case "application/pdf":
+		if r.pdf == nil {
+			return nil, ErrUnsupported
+		}
+		img, err = r.pdf.FirstPage(ctx, receipt, r.size)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, Scale(img, r.size), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(body []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// Scale shrinks img so its longest side is at most size, averaging the
+// source pixels behind each preview pixel. Transparent areas become white.
+// Images already small enough are only flattened.
+func Scale(img image.Image, size int) *image.RGBA {
+	src := flatten(img)
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+
+	dw, dh := size, h*size/w
+	if h > w {
+		dw, dh = w*size/h, size
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var rs, gs, bs, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4:]
+					rs += int(p[0])
+					gs += int(p[1])
+					bs += int(p[2])
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(rs / n), G: uint8(gs / n), B: uint8(bs / n), A: 0xff})
+		}
+	}
+	return dst
+}
+
+// flatten draws img over white into an RGBA image anchored at 0,0.
+func flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
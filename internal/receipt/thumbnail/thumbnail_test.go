@@ -0,0 +1,99 @@
+package thumbnail_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/receipt/thumbnail"
+)
+
+type fakePDFRenderer struct {
+	size int
+}
+
+func (f *fakePDFRenderer) FirstPage(_ context.Context, _ []byte, size int) (image.Image, error) {
+	f.size = size
+	return solid(size*3/4, size, color.RGBA{B: 0xff, A: 0xff}), nil
+}
+
+func solid(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	Expect(png.Encode(&buf, img)).To(Succeed())
+	return buf.Bytes()
+}
+
+func decodeJPEG(body []byte) image.Image {
+	img, err := jpeg.Decode(bytes.NewReader(body))
+	Expect(err).NotTo(HaveOccurred())
+	return img
+}
+
+var pdf = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+
+var _ = Describe("Renderer", func() {
+	ctx := context.Background()
+
+	It("scales images down to size on their longest side", func() {
+		preview, err := thumbnail.New(100, nil).Render(ctx, encodePNG(solid(800, 400, color.RGBA{R: 0xff, A: 0xff})))
+		Expect(err).NotTo(HaveOccurred())
+
+		img := decodeJPEG(preview)
+		Expect(img.Bounds().Size()).To(Equal(image.Pt(100, 50)))
+		r, g, b, _ := img.At(50, 25).RGBA()
+		Expect(r >> 8).To(BeNumerically(">", 0xf0))
+		Expect(g >> 8).To(BeNumerically("<", 0x10))
+		Expect(b >> 8).To(BeNumerically("<", 0x10))
+	})
+
+	It("keeps small images at their size and puts transparency on white", func() {
+		preview, err := thumbnail.New(100, nil).Render(ctx, encodePNG(image.NewRGBA(image.Rect(0, 0, 40, 60))))
+		Expect(err).NotTo(HaveOccurred())
+
+		img := decodeJPEG(preview)
+		Expect(img.Bounds().Size()).To(Equal(image.Pt(40, 60)))
+		r, g, b, _ := img.At(20, 30).RGBA()
+		Expect([]uint32{r >> 8, g >> 8, b >> 8}).To(HaveEach(BeNumerically(">", 0xf0)))
+	})
+
+	It("previews the first page of PDFs with the PDF renderer", func() {
+		renderer := &fakePDFRenderer{}
+		preview, err := thumbnail.New(0, renderer).Render(ctx, pdf)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(renderer.size).To(Equal(thumbnail.DefaultSize))
+		Expect(decodeJPEG(preview).Bounds().Size()).To(Equal(image.Pt(240, 320)))
+	})
+
+	It("cannot preview PDFs without a PDF renderer", func() {
+		_, err := thumbnail.New(100, nil).Render(ctx, pdf)
+		Expect(err).To(MatchError(thumbnail.ErrUnsupported))
+	})
+
+	It("cannot preview other files", func() {
+		_, err := thumbnail.New(100, nil).Render(ctx, []byte("plain text"))
+		Expect(err).To(MatchError(thumbnail.ErrUnsupported))
+	})
+
+	It("fails on broken images", func() {
+		body := encodePNG(solid(10, 10, color.Black))
+		_, err := thumbnail.New(100, nil).Render(ctx, body[:len(body)/2])
+		Expect(err).To(HaveOccurred())
+	})
+})
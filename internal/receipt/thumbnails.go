@@ -0,0 +1,196 @@
+package receipt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/receipt/thumbnail"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// ThumbnailRepositoryAPI is what the thumbnail worker needs of the stored
+// receipts.
+type ThumbnailRepositoryAPI interface {
+	// NextWithoutThumbnail returns the oldest receipt that has neither a
+	// preview nor an error, or nil when there is none.
+	NextWithoutThumbnail() (*PendingThumbnail, error)
+	// SetThumbnail and SetThumbnailError report false, and change nothing,
+	// when the expense's receipt is no longer receiptKey.
+	SetThumbnail(expenseID int64, receiptKey, thumbnailKey string) (bool, error)
+	SetThumbnailError(expenseID int64, receiptKey, message string) (bool, error)
+}
+
+// PendingThumbnail is a receipt still to be previewed.
+type PendingThumbnail struct {
+	ExpenseID  int64
+	ReceiptKey string
+}
+
+// ThumbnailRenderer turns a receipt into a JPEG preview; *thumbnail.Renderer
+// satisfies it.
+type ThumbnailRenderer interface {
+	Render(ctx context.Context, receipt []byte) ([]byte, error)
+}
+
+// ThumbnailService renders previews of receipts in the background and
+// stores them next to the originals.
+type ThumbnailService struct {
+	repo     ThumbnailRepositoryAPI
+	blob     storage.Blob
+	renderer ThumbnailRenderer
+	logger   *slog.Logger
+}
+
+func NewThumbnailService(repo ThumbnailRepositoryAPI, blob storage.Blob, renderer ThumbnailRenderer, logger *slog.Logger) *ThumbnailService {
+	return &ThumbnailService{
+		repo:     repo,
+		blob:     blob,
+		renderer: renderer,
+		logger:   logger,
+	}
+}
+
+func (s *ThumbnailService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// ProcessNext previews the oldest receipt without a preview and reports
+// whether there was one. A receipt that cannot be previewed is marked with
+// the reason and not tried again. Storage errors are returned, and the
+// receipt is tried again on the next call.
+func (s *ThumbnailService) ProcessNext(ctx context.Context) (bool, error) {
+	pending, err := s.repo.NextWithoutThumbnail()
+	if err != nil {
+		return false, fmt.Errorf("failed to find receipt to preview: %w", err)
+	}
+	if pending == nil {
+		return false, nil
+	}
+
+	body, err := s.read(ctx, pending.ReceiptKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return true, s.fail(ctx, pending, errors.New("receipt file is missing"))
+	}
+	if err != nil {
+		return false, err
+	}
+
+	preview, err := s.renderer.Render(ctx, body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, s.fail(ctx, pending, err)
+	}
+
+	key := ThumbnailKey(pending.ReceiptKey)
+	if err := s.blob.Put(ctx, key, bytes.NewReader(preview), storage.PutOptions{ContentType: thumbnail.ContentType, Size: int64(len(preview))}); err != nil {
+		return false, fmt.Errorf("failed to store receipt preview: %w", err)
+	}
+	updated, err := s.repo.SetThumbnail(pending.ExpenseID, pending.ReceiptKey, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to save receipt preview: %w", err)
+	}
+	if !updated {
+		// The receipt was replaced while it was being previewed.
+		if err := s.blob.Delete(ctx, key); err != nil {
+			s.log(ctx).Warn("failed to remove preview of replaced receipt", "error", err, "key", key)
+		}
+		return true, nil
+	}
+
+	s.log(ctx).Info("receipt preview rendered", "expense_id", pending.ExpenseID, "key", key, "size", len(preview))
+	return true, nil
+}
+
+// read loads a receipt, which is never larger than MaxReceiptSize.
+func (s *ThumbnailService) read(ctx context.Context, key string) ([]byte, error) {
+	rc, err := s.blob.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt: %w", err)
+	}
+	defer rc.Close()
+
+	body, err := io.ReadAll(io.LimitReader(rc, MaxReceiptSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt: %w", err)
+	}
+	return body, nil
+}
+
+func (s *ThumbnailService) fail(ctx context.Context, pending *PendingThumbnail, cause error) error {
+	s.log(ctx).Warn("receipt cannot be previewed", "error", cause, "expense_id", pending.ExpenseID, "key", pending.ReceiptKey)
+	if _, err := s.repo.SetThumbnailError(pending.ExpenseID, pending.ReceiptKey, cause.Error()); err != nil {
+		return fmt.Errorf("failed to save receipt preview error: %w", err)
+	}
+	return nil
+}
+
+// ThumbnailKey is where the preview of the receipt stored at key is kept:
+// next to it, as "receipts/42/<uuid>.thumb.jpg".
+func ThumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + ".thumb.jpg"
+}
+
+// thumbnailFilename names a preview after the receipt it shows.
+func thumbnailFilename(filename string) string {
+	if filename == "" {
+		return "receipt.thumb.jpg"
+	}
+	return strings.TrimSuffix(filename, path.Ext(filename)) + ".thumb.jpg"
+}
+
+// ThumbnailWorker polls for receipts without a preview.
+type ThumbnailWorker struct {
+	service  *ThumbnailService
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewThumbnailWorker(service *ThumbnailService, interval time.Duration, logger *slog.Logger) *ThumbnailWorker {
+	return &ThumbnailWorker{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w *ThumbnailWorker) Run(ctx context.Context) {
+	w.logger.Info("receipt thumbnail worker started", "poll_interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("receipt thumbnail worker stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// Drain the backlog before sleeping again.
+		for ctx.Err() == nil {
+			processed, err := w.service.ProcessNext(ctx)
+			if err != nil {
+				w.logger.Error("receipt preview failed", "error", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+	}
+}
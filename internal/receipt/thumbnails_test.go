@@ -0,0 +1,153 @@
+package receipt_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/receipt"
+	"github.com/frahmantamala/expense-management/internal/receipt/thumbnail"
+	"github.com/frahmantamala/expense-management/internal/storage"
+)
+
+// mockThumbnailRepository keeps the receipt of each expense and what the
+// worker made of it.
+type mockThumbnailRepository struct {
+	receipts map[int64]string
+	previews map[int64]string
+	failures map[int64]string
+}
+
+func (m *mockThumbnailRepository) NextWithoutThumbnail() (*receipt.PendingThumbnail, error) {
+	var next *receipt.PendingThumbnail
+	for id, key := range m.receipts {
+		_, done := m.previews[id]
+		_, failed := m.failures[id]
+		if !done && !failed && (next == nil || id < next.ExpenseID) {
+			next = &receipt.PendingThumbnail{ExpenseID: id, ReceiptKey: key}
+		}
+	}
+	return next, nil
+}
+
+func (m *mockThumbnailRepository) SetThumbnail(expenseID int64, receiptKey, thumbnailKey string) (bool, error) {
+	if m.receipts[expenseID] != receiptKey {
+		return false, nil
+	}
+	m.previews[expenseID] = thumbnailKey
+	return true, nil
+}
+
+func (m *mockThumbnailRepository) SetThumbnailError(expenseID int64, receiptKey, message string) (bool, error) {
+	if m.receipts[expenseID] != receiptKey {
+		return false, nil
+	}
+	m.failures[expenseID] = message
+	return true, nil
+}
+
+// replacingRenderer replaces the receipt of expense 1 while it renders.
+type replacingRenderer struct {
+	repo *mockThumbnailRepository
+}
+
+func (r replacingRenderer) Render(ctx context.Context, body []byte) ([]byte, error) {
+	r.repo.receipts[1] = "receipts/1/new.png"
+	return []byte("jpeg"), nil
+}
+
+var _ = Describe("ThumbnailService", func() {
+	var (
+		ctx     context.Context
+		repo    *mockThumbnailRepository
+		blob    *storage.Local
+		service *receipt.ThumbnailService
+	)
+
+	put := func(key string, body []byte) {
+		Expect(blob.Put(ctx, key, bytes.NewReader(body), storage.PutOptions{})).To(Succeed())
+	}
+
+	pngReceipt := func() []byte {
+		var buf bytes.Buffer
+		Expect(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 640, 480)))).To(Succeed())
+		return buf.Bytes()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockThumbnailRepository{receipts: map[int64]string{}, previews: map[int64]string{}, failures: map[int64]string{}}
+
+		var err error
+		blob, err = storage.NewLocal(GinkgoT().TempDir(), "http://localhost:8080", "secret")
+		Expect(err).NotTo(HaveOccurred())
+		service = receipt.NewThumbnailService(repo, blob, thumbnail.New(64, nil), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("stores a preview next to the receipt", func() {
+		repo.receipts[1] = "receipts/1/photo.png"
+		put("receipts/1/photo.png", pngReceipt())
+
+		processed, err := service.ProcessNext(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(BeTrue())
+		Expect(repo.previews[1]).To(Equal("receipts/1/photo.thumb.jpg"))
+
+		rc, err := blob.Get(ctx, "receipts/1/photo.thumb.jpg")
+		Expect(err).NotTo(HaveOccurred())
+		defer rc.Close()
+		cfg, format, err := image.DecodeConfig(rc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(format).To(Equal("jpeg"))
+		Expect([]int{cfg.Width, cfg.Height}).To(Equal([]int{64, 48}))
+	})
+
+	It("reports when there is nothing to preview", func() {
+		processed, err := service.ProcessNext(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(BeFalse())
+	})
+
+	It("marks receipts that cannot be previewed and moves on", func() {
+		repo.receipts[1] = "receipts/1/taxi.pdf"
+		repo.receipts[2] = "receipts/2/missing.png"
+		put("receipts/1/taxi.pdf", pdf)
+
+		for i := 0; i < 2; i++ {
+			processed, err := service.ProcessNext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeTrue())
+		}
+		Expect(repo.failures[1]).To(Equal(thumbnail.ErrUnsupported.Error()))
+		Expect(repo.failures[2]).To(ContainSubstring("missing"))
+		Expect(repo.previews).To(BeEmpty())
+
+		processed, err := service.ProcessNext(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(BeFalse())
+	})
+
+	It("drops the preview of a receipt replaced while rendering", func() {
+		repo.receipts[1] = "receipts/1/old.png"
+		put("receipts/1/old.png", pngReceipt())
+		service = receipt.NewThumbnailService(repo, blob, replacingRenderer{repo: repo}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		processed, err := service.ProcessNext(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(BeTrue())
+		Expect(repo.previews).To(BeEmpty())
+		_, err = blob.Get(ctx, "receipts/1/old.thumb.jpg")
+		Expect(errors.Is(err, storage.ErrNotFound)).To(BeTrue())
+	})
+
+	It("names previews after the receipt key", func() {
+		Expect(receipt.ThumbnailKey("receipts/42/invoice.pdf")).To(Equal("receipts/42/invoice.thumb.jpg"))
+	})
+})
@@ -0,0 +1,190 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/expense"
+)
+
+const (
+	// DefaultForecastWeeks is how many weeks a cash-flow forecast covers
+	// when the caller does not say.
+	DefaultForecastWeeks = 8
+	// MaxForecastWeeks bounds the weeks of one forecast.
+	MaxForecastWeeks = 26
+	// DefaultLeadTimeDays is how many days of payments the lead time is
+	// learnt from when the caller does not say.
+	DefaultLeadTimeDays = 90
+	// MaxLeadTimeDays bounds the lead time history.
+	MaxLeadTimeDays = 365
+)
+
+// agingBuckets split unpaid expenses by whole days since approval; a bucket
+// holds the days up to and including its limit, the last one everything
+// older.
+var agingBuckets = []struct {
+	label string
+	limit int
+}{
+	{"0-7", 7},
+	{"8-14", 14},
+	{"15-30", 30},
+	{"31+", -1},
+}
+
+// Unpaid is an approved expense still waiting to be paid.
+type Unpaid struct {
+	ExpenseID int64
+	AmountIDR int64
+	Status    string
+	// ApprovedAt falls back to when the expense was submitted for expenses
+	// approved before approval times were kept.
+	ApprovedAt time.Time
+}
+
+// LeadTime is how long successful payments took from approval to payout.
+type LeadTime struct {
+	Payments      int64   `json:"payments"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P95Seconds    float64 `json:"p95_seconds"`
+}
+
+// Outstanding counts and totals unpaid expenses.
+type Outstanding struct {
+	Expenses  int64 `json:"expenses"`
+	AmountIDR int64 `json:"amount_idr"`
+}
+
+// AgingBucket is the unpaid expenses approved Days days ago.
+type AgingBucket struct {
+	Days string `json:"days" example:"8-14"`
+	Outstanding
+}
+
+// CashFlowWeek is the money expected to be paid out in the week starting
+// on WeekStart, a Monday.
+type CashFlowWeek struct {
+	WeekStart string `json:"week_start" example:"2025-11-03"`
+	Outstanding
+	// Overdue counts the expenses that were expected to be paid before
+	// today, or whose payment failed; they are all due in the first week.
+	Overdue int64 `json:"overdue"`
+}
+
+// CashFlowReport is the caller's tenant's approved-but-unpaid expenses,
+// how old they are, and when they are expected to be paid. An expense is
+// expected to be paid the median lead time after its approval.
+type CashFlowReport struct {
+	AsOf         string          `json:"as_of" example:"2025-11-05"`
+	LeadTime     LeadTime        `json:"lead_time"`
+	LeadTimeDays int             `json:"lead_time_days"`
+	Outstanding  Outstanding     `json:"outstanding"`
+	Aging        []*AgingBucket  `json:"aging"`
+	Weeks        []*CashFlowWeek `json:"weeks"`
+	// Later is expected after the last forecast week.
+	Later Outstanding `json:"later"`
+}
+
+// CashFlowQuery selects how far the forecast looks ahead and how much
+// payment history it learns the lead time from.
+type CashFlowQuery struct {
+	Weeks        int
+	LeadTimeDays int
+}
+
+// ParseFromRequest reads weeks and lead_time_days, ignoring values that do
+// not parse.
+func (q *CashFlowQuery) ParseFromRequest(r *http.Request) {
+	query := r.URL.Query()
+	if weeks, err := strconv.Atoi(query.Get("weeks")); err == nil {
+		q.Weeks = weeks
+	}
+	if days, err := strconv.Atoi(query.Get("lead_time_days")); err == nil {
+		q.LeadTimeDays = days
+	}
+}
+
+// SetDefaults fills in unset values and clamps them to their maximum.
+func (q *CashFlowQuery) SetDefaults() {
+	if q.Weeks <= 0 {
+		q.Weeks = DefaultForecastWeeks
+	}
+	if q.Weeks > MaxForecastWeeks {
+		q.Weeks = MaxForecastWeeks
+	}
+	if q.LeadTimeDays <= 0 {
+		q.LeadTimeDays = DefaultLeadTimeDays
+	}
+	if q.LeadTimeDays > MaxLeadTimeDays {
+		q.LeadTimeDays = MaxLeadTimeDays
+	}
+}
+
+// CashFlow reports unpaid expenses by age and by the week they are expected
+// to be paid, starting with the current week.
+func (s *Service) CashFlow(ctx context.Context, q CashFlowQuery, now time.Time) (*CashFlowReport, error) {
+	q.SetDefaults()
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	leadTime, err := s.repo.PaymentLeadTime(ctx, today.AddDate(0, 0, -q.LeadTimeDays), today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure payment lead time: %w", err)
+	}
+	unpaid, err := s.repo.Unpaid(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unpaid expenses: %w", err)
+	}
+
+	result := &CashFlowReport{
+		AsOf:         today.Format(time.DateOnly),
+		LeadTime:     *leadTime,
+		LeadTimeDays: q.LeadTimeDays,
+		Aging:        make([]*AgingBucket, len(agingBuckets)),
+		Weeks:        make([]*CashFlowWeek, q.Weeks),
+	}
+	for i, bucket := range agingBuckets {
+		result.Aging[i] = &AgingBucket{Days: bucket.label}
+	}
+	// Weeks start on Monday.
+	firstWeek := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	for i := range result.Weeks {
+		result.Weeks[i] = &CashFlowWeek{WeekStart: firstWeek.AddDate(0, 0, 7*i).Format(time.DateOnly)}
+	}
+
+	lead := time.Duration(leadTime.MedianSeconds * float64(time.Second))
+	for _, e := range unpaid {
+		result.Outstanding.add(e.AmountIDR)
+
+		age := int(today.Sub(e.ApprovedAt.UTC()).Hours() / 24)
+		for i, bucket := range agingBuckets {
+			if bucket.limit < 0 || age <= bucket.limit {
+				result.Aging[i].add(e.AmountIDR)
+				break
+			}
+		}
+
+		expected := e.ApprovedAt.UTC().Add(lead)
+		if e.Status == expense.ExpenseStatusPaymentFailed || expected.Before(today) {
+			result.Weeks[0].add(e.AmountIDR)
+			result.Weeks[0].Overdue++
+			continue
+		}
+		week := int(expected.Sub(firstWeek).Hours() / (7 * 24))
+		if week >= len(result.Weeks) {
+			result.Later.add(e.AmountIDR)
+			continue
+		}
+		result.Weeks[week].add(e.AmountIDR)
+	}
+	return result, nil
+}
+
+func (o *Outstanding) add(amountIDR int64) {
+	o.Expenses++
+	o.AmountIDR += amountIDR
+}
@@ -0,0 +1,240 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	ListSchedules(ctx context.Context) ([]*Schedule, error)
+	CreateSchedule(ctx context.Context, createdBy int64, dto ScheduleDTO) (*Schedule, error)
+	UpdateSchedule(ctx context.Context, id int64, dto ScheduleDTO) (*Schedule, error)
+	DeleteSchedule(ctx context.Context, id int64) error
+	SendNow(ctx context.Context, id int64, now time.Time) (*Schedule, error)
+	ApprovalSLA(ctx context.Context, q SLAQuery, now time.Time) (*ApprovalSLAReport, error)
+	CashFlow(ctx context.Context, q CashFlowQuery, now time.Time) (*CashFlowReport, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListSchedules godoc
+// @Summary      List scheduled reports
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SchedulesResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/report-schedules [get]
+func (h *Handler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.Service.ListSchedules(r.Context())
+	if err != nil {
+		h.Log(r).Error("ListSchedules: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list report schedules")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, SchedulesResponse{Schedules: schedules})
+}
+
+// CreateSchedule godoc
+// @Summary      Schedule a report
+// @Description  Emails the report to every recipient at hour (UTC): daily, weekly on weekday (0 is Sunday) or monthly on the 1st. Each run covers the previous day, seven days or calendar month. report_type is spend_by_department or spend_by_category and format is xlsx or csv. Requires admin.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      ScheduleDTO  true  "Schedule"
+// @Success      201   {object}  Schedule
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Router       /admin/report-schedules [post]
+func (h *Handler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto ScheduleDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	schedule, err := h.Service.CreateSchedule(r.Context(), user.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, schedule)
+}
+
+// UpdateSchedule godoc
+// @Summary      Replace a scheduled report
+// @Description  The next run is worked out again from the new timing. Requires admin.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int          true  "Schedule ID"
+// @Param        body  body      ScheduleDTO  true  "Schedule"
+// @Success      200   {object}  Schedule
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Router       /admin/report-schedules/{id} [put]
+func (h *Handler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	var dto ScheduleDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	schedule, err := h.Service.UpdateSchedule(r.Context(), id, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, schedule)
+}
+
+// DeleteSchedule godoc
+// @Summary      Delete a scheduled report
+// @Description  Requires admin.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        id  path  int  true  "Schedule ID"
+// @Success      204
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/report-schedules/{id} [delete]
+func (h *Handler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteSchedule(r.Context(), id); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendSchedule godoc
+// @Summary      Send a scheduled report now
+// @Description  Sends the report for the period before now without moving the next scheduled run. The outcome is recorded in last_run_at and last_error. Requires admin.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path      int  true  "Schedule ID"
+// @Success      200 {object}  Schedule
+// @Failure      400 {object}  transport.ErrorResponse
+// @Failure      401 {object}  transport.ErrorResponse
+// @Failure      403 {object}  transport.ErrorResponse
+// @Failure      404 {object}  transport.AppErrorResponse
+// @Router       /admin/report-schedules/{id}/send [post]
+func (h *Handler) SendSchedule(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.Service.SendNow(r.Context(), id, time.Now())
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, schedule)
+}
+
+// GetApprovalSLA godoc
+// @Summary      Approval SLA report
+// @Description  How long expenses approved in the range waited from submission to approval: the median and 95th percentile overall, per approving manager and per submitter department, slowest first, with how many were approved within the target (reports.approval_sla). Auto-approved expenses are left out. Covers the admin's tenant. Requires admin.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  false  "First approval day, YYYY-MM-DD; 30 days before to by default"
+// @Param        to    query     string  false  "Last approval day, YYYY-MM-DD; today by default"
+// @Success      200   {object}  ApprovalSLAReport
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      500   {object}  transport.ErrorResponse
+// @Router       /reports/approval-sla [get]
+func (h *Handler) GetApprovalSLA(w http.ResponseWriter, r *http.Request) {
+	var q SLAQuery
+	q.ParseFromRequest(r)
+
+	result, err := h.Service.ApprovalSLA(r.Context(), q, time.Now())
+	if err != nil {
+		h.Log(r).Error("GetApprovalSLA: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load approval SLA report")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
+
+// GetCashFlow godoc
+// @Summary      Payments aging and cash-flow forecast
+// @Description  Approved expenses not yet paid, for treasury planning: their total, their age in days since approval, and the weeks (starting Monday, from the current one) they are expected to be paid in. An expense is expected to be paid the median payment lead time after its approval; the lead time is learnt from the successful payments of the last lead_time_days days. Expenses expected before today, and those whose payment failed, are overdue and counted in the first week. Covers the caller's tenant. Requires admin or finance_viewer.
+// @Tags         payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        weeks           query     int  false  "Weeks to forecast, 8 by default, at most 26"
+// @Param        lead_time_days  query     int  false  "Days of payments to learn the lead time from, 90 by default, at most 365"
+// @Success      200             {object}  CashFlowReport
+// @Failure      401             {object}  transport.ErrorResponse
+// @Failure      403             {object}  transport.ErrorResponse
+// @Failure      500             {object}  transport.ErrorResponse
+// @Router       /reports/cash-flow [get]
+func (h *Handler) GetCashFlow(w http.ResponseWriter, r *http.Request) {
+	var q CashFlowQuery
+	q.ParseFromRequest(r)
+
+	result, err := h.Service.CashFlow(r.Context(), q, time.Now())
+	if err != nil {
+		h.Log(r).Error("GetCashFlow: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load cash-flow report")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) scheduleID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid schedule ID")
+		return 0, false
+	}
+	return id, true
+}
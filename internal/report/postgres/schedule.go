@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	reportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/report"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/report"
+	"gorm.io/gorm"
+)
+
+type ScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) report.RepositoryAPI {
+	return &ScheduleRepository{db: db}
+}
+
+// spendStatuses are the statuses that count as money spent.
+var spendStatuses = expense.ApprovedStatuses
+
+func (r *ScheduleRepository) List(ctx context.Context) ([]*reportDatamodel.Schedule, error) {
+	var schedules []*reportDatamodel.Schedule
+	err := r.db.WithContext(ctx).Order("id").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *ScheduleRepository) Get(ctx context.Context, id int64) (*reportDatamodel.Schedule, error) {
+	var schedule reportDatamodel.Schedule
+	err := r.db.WithContext(ctx).First(&schedule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *ScheduleRepository) Create(ctx context.Context, s *reportDatamodel.Schedule) error {
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+func (r *ScheduleRepository) Update(ctx context.Context, s *reportDatamodel.Schedule) error {
+	return r.db.WithContext(ctx).Save(s).Error
+}
+
+func (r *ScheduleRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&reportDatamodel.Schedule{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimDue uses SKIP LOCKED so several servers can run report workers; the
+// row stays locked until its next run has been moved.
+func (r *ScheduleRepository) ClaimDue(ctx context.Context, now time.Time, next func(*reportDatamodel.Schedule) time.Time) (*reportDatamodel.Schedule, error) {
+	var claimed *reportDatamodel.Schedule
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var schedules []*reportDatamodel.Schedule
+		err := tx.Raw(`SELECT * FROM report_schedules
+			WHERE enabled AND next_run_at <= ?
+			ORDER BY next_run_at LIMIT 1
+			FOR UPDATE SKIP LOCKED`, now).
+			Scan(&schedules).Error
+		if err != nil || len(schedules) == 0 {
+			return err
+		}
+
+		claimed = schedules[0]
+		return tx.Exec(`UPDATE report_schedules SET next_run_at = ? WHERE id = ?`, next(claimed), claimed.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (r *ScheduleRepository) RecordRun(ctx context.Context, id int64, ranAt time.Time, runErr *string) error {
+	return r.db.WithContext(ctx).Model(&reportDatamodel.Schedule{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_run_at": ranAt,
+			"last_error":  runErr,
+		}).Error
+}
+
+// Spend selects from expenses so the tenant filter applies. Dates are
+// compared as YYYY-MM-DD so the DATE column is not shifted by the session
+// time zone; expenses of users without a department are grouped under an
+// empty name.
+func (r *ScheduleRepository) Spend(ctx context.Context, reportType string, from, to time.Time) ([]*report.Line, error) {
+	group := "COALESCE(expenses.category, '')"
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{})
+	if reportType == report.TypeSpendByDepartment {
+		group = "COALESCE(users.department, '')"
+		query = query.Joins("JOIN users ON users.id = expenses.user_id")
+	}
+
+	var lines []*report.Line
+	err := query.
+		Select(group+` AS "group", COUNT(*) AS expenses, COALESCE(SUM(expenses.amount_idr), 0) AS amount_idr`).
+		Where("expenses.expense_status IN ?", spendStatuses).
+		Where("expenses.expense_date >= ? AND expenses.expense_date < ?", from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Group(group).
+		Order("amount_idr DESC").
+		Scan(&lines).Error
+	return lines, err
+}
+
+// approvalSeconds is how long an expense waited from submission to
+// approval.
+const approvalSeconds = "EXTRACT(EPOCH FROM expenses.approved_at - expenses.submitted_at)"
+
+// ApprovalLatency selects from expenses so the tenant filter applies.
+// Managers are grouped by email, departments by the submitter's.
+func (r *ScheduleRepository) ApprovalLatency(ctx context.Context, group string, from, to time.Time, target time.Duration) ([]*report.ApprovalLatency, error) {
+	key := "''"
+	query := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{})
+	switch group {
+	case report.GroupManager:
+		key = "approvers.email"
+		query = query.Joins("JOIN users approvers ON approvers.id = expenses.approved_by")
+	case report.GroupDepartment:
+		key = "COALESCE(submitters.department, '')"
+		query = query.Joins("JOIN users submitters ON submitters.id = expenses.user_id")
+	}
+
+	var rows []*report.ApprovalLatency
+	err := query.
+		Select(key+` AS "group", COUNT(*) AS approvals,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY `+approvalSeconds+`) AS median_seconds,
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY `+approvalSeconds+`) AS p95_seconds,
+			COUNT(*) FILTER (WHERE `+approvalSeconds+` <= ?) AS within_target`, target.Seconds()).
+		Where("expenses.approved_by IS NOT NULL").
+		Where("expenses.approved_at >= ? AND expenses.approved_at < ?", from, to).
+		Group(key).
+		Order("p95_seconds DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Unpaid falls back to submitted_at for expenses approved before
+// approved_at was kept.
+func (r *ScheduleRepository) Unpaid(ctx context.Context) ([]*report.Unpaid, error) {
+	var rows []*report.Unpaid
+	err := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Select(`expenses.id AS expense_id, expenses.amount_idr, expenses.expense_status AS status,
+			COALESCE(expenses.approved_at, expenses.submitted_at) AS approved_at`).
+		Where("expenses.expense_status IN ?", expense.UnpaidStatuses).
+		Order("expenses.id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// paymentLeadSeconds is how long a payment took from its expense's
+// approval to payout.
+const paymentLeadSeconds = "EXTRACT(EPOCH FROM payments.processed_at - COALESCE(expenses.approved_at, expenses.submitted_at))"
+
+func (r *ScheduleRepository) PaymentLeadTime(ctx context.Context, from, to time.Time) (*report.LeadTime, error) {
+	var leadTime report.LeadTime
+	err := r.db.WithContext(ctx).Model(&expenseDatamodel.Expense{}).
+		Select(`COUNT(*) AS payments,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY `+paymentLeadSeconds+`), 0) AS median_seconds,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY `+paymentLeadSeconds+`), 0) AS p95_seconds`).
+		Joins("JOIN payments ON payments.expense_id = expenses.id").
+		Where("payments.status = ?", payment.StatusSuccess).
+		Where("payments.processed_at >= ? AND payments.processed_at < ?", from, to).
+		Scan(&leadTime).Error
+	if err != nil {
+		return nil, err
+	}
+	return &leadTime, nil
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// dateLayout formats report periods.
+const dateLayout = "2006-01-02"
+
+// ContentType returns the MIME type of reports rendered as format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Filename names the attachment of r rendered as format, e.g.
+// spend-by-department-2025-03-03-to-2025-03-09.xlsx.
+func Filename(r *Report, format string) string {
+	last := r.To.AddDate(0, 0, -1)
+	return fmt.Sprintf("%s-%s-to-%s.%s", strings.ReplaceAll(r.Type, "_", "-"), r.From.Format(dateLayout), last.Format(dateLayout), format)
+}
+
+// Render writes r as a sheet with one row per line and a total row. Counts
+// and amounts are numbers, so the spreadsheet can sum and chart them.
+// Expenses without a department or category are listed as Unassigned.
+func Render(r *Report, format string) ([]byte, error) {
+	rows := [][]interface{}{{r.groupHeader(), "Expenses", "Amount (IDR)"}}
+	for _, l := range r.Lines {
+		group := l.Group
+		if group == "" {
+			group = "Unassigned"
+		}
+		rows = append(rows, []interface{}{group, l.Expenses, l.AmountIDR})
+	}
+	expenses, amount := r.Totals()
+	rows = append(rows, []interface{}{"Total", expenses, amount})
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatXLSX:
+		err = writeXLSX(&buf, r.Title(), rows)
+	case FormatCSV:
+		err = writeCSV(&buf, rows)
+	default:
+		err = fmt.Errorf("unsupported report format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCSV(w io.Writer, rows [][]interface{}) error {
+	cw := csv.NewWriter(w)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = fmt.Sprint(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// xlsxParts are the fixed parts of a single-sheet workbook.
+var xlsxParts = []struct {
+	name, body string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// writeXLSX writes a minimal workbook with one sheet, using inline strings
+// so no shared string table is needed.
+func writeXLSX(w io.Writer, sheetName string, rows [][]interface{}) error {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/workbook.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`, escapeXML(sheetName)); err != nil {
+		return err
+	}
+
+	f, err = zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, v := range row {
+			ref := columnName(j) + strconv.Itoa(i+1)
+			if n, ok := v.(int64); ok {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%d</v></c>`, ref, n)
+				continue
+			}
+			fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escapeXML(fmt.Sprint(v)))
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if _, err := io.WriteString(f, sheet.String()); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// columnName returns the spreadsheet column for a 0-based index: A, B, ...,
+// Z, AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
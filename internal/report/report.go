@@ -0,0 +1,186 @@
+package report
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	reportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/report"
+)
+
+const (
+	TypeSpendByDepartment = "spend_by_department"
+	TypeSpendByCategory   = "spend_by_category"
+)
+
+const (
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv"
+)
+
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// MaxRecipients caps a schedule's distribution list.
+const MaxRecipients = 50
+
+var ErrScheduleNotFound = errors.NewNotFoundError("Report schedule not found", errors.ErrCodeReportScheduleNotFound)
+
+// Schedule emails a report to Recipients at Hour UTC: every day, every week
+// on Weekday (0 is Sunday) or on the first of every month. Each run covers
+// the day, week or month before it.
+type Schedule struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	ReportType string     `json:"report_type"`
+	Format     string     `json:"format"`
+	Frequency  string     `json:"frequency"`
+	Weekday    int        `json:"weekday"`
+	Hour       int        `json:"hour"`
+	Recipients []string   `json:"recipients"`
+	Enabled    bool       `json:"enabled"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastError  *string    `json:"last_error,omitempty"`
+	CreatedBy  int64      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ScheduleDTO creates a schedule or replaces one. Weekday is only used by
+// weekly schedules; Enabled defaults to true.
+type ScheduleDTO struct {
+	Name       string   `json:"name" validate:"required,max=255"`
+	ReportType string   `json:"report_type" validate:"required,oneof=spend_by_department spend_by_category"`
+	Format     string   `json:"format" validate:"required,oneof=xlsx csv"`
+	Frequency  string   `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	Weekday    int      `json:"weekday" validate:"min=0,max=6"`
+	Hour       int      `json:"hour" validate:"min=0,max=23"`
+	Recipients []string `json:"recipients"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+func (dto ScheduleDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if len(dto.Recipients) == 0 || len(dto.Recipients) > MaxRecipients {
+		return errors.NewValidationFieldError("recipients", fmt.Sprintf("between 1 and %d recipients are required", MaxRecipients), errors.ErrCodeValidationFailed)
+	}
+	for _, r := range dto.Recipients {
+		if addr, err := mail.ParseAddress(r); err != nil || addr.Address != r {
+			return errors.NewValidationFieldError("recipients", fmt.Sprintf("%q is not an email address", r), errors.ErrCodeValidationFailed)
+		}
+	}
+	return nil
+}
+
+type SchedulesResponse struct {
+	Schedules []*Schedule `json:"schedules"`
+}
+
+// Line is one row of a spend report: the approved and paid expenses of one
+// department or category.
+type Line struct {
+	Group     string `json:"group"`
+	Expenses  int64  `json:"expenses"`
+	AmountIDR int64  `json:"amount_idr"`
+}
+
+// Report is a generated spend report over [From, To).
+type Report struct {
+	Type  string
+	From  time.Time
+	To    time.Time
+	Lines []*Line
+}
+
+func (r *Report) Title() string {
+	if r.Type == TypeSpendByCategory {
+		return "Spend by category"
+	}
+	return "Spend by department"
+}
+
+func (r *Report) groupHeader() string {
+	if r.Type == TypeSpendByCategory {
+		return "Category"
+	}
+	return "Department"
+}
+
+// Totals sums every line.
+func (r *Report) Totals() (expenses, amountIDR int64) {
+	for _, l := range r.Lines {
+		expenses += l.Expenses
+		amountIDR += l.AmountIDR
+	}
+	return expenses, amountIDR
+}
+
+// Period returns the half-open range a run at runAt reports on: the
+// previous day, the previous seven days or the previous calendar month, all
+// in UTC.
+func Period(frequency string, runAt time.Time) (time.Time, time.Time) {
+	runAt = runAt.UTC()
+	today := time.Date(runAt.Year(), runAt.Month(), runAt.Day(), 0, 0, 0, 0, time.UTC)
+	switch frequency {
+	case FrequencyWeekly:
+		return today.AddDate(0, 0, -7), today
+	case FrequencyMonthly:
+		month := time.Date(runAt.Year(), runAt.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return month.AddDate(0, -1, 0), month
+	default:
+		return today.AddDate(0, 0, -1), today
+	}
+}
+
+// NextRun returns the first run of s strictly after after.
+func NextRun(s *reportDatamodel.Schedule, after time.Time) time.Time {
+	after = after.UTC()
+	switch s.Frequency {
+	case FrequencyMonthly:
+		next := time.Date(after.Year(), after.Month(), 1, s.Hour, 0, 0, 0, time.UTC)
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	case FrequencyWeekly:
+		next := time.Date(after.Year(), after.Month(), after.Day(), s.Hour, 0, 0, 0, time.UTC)
+		for int(next.Weekday()) != s.Weekday || !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	default:
+		next := time.Date(after.Year(), after.Month(), after.Day(), s.Hour, 0, 0, 0, time.UTC)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+}
+
+func fromDataModel(s *reportDatamodel.Schedule) *Schedule {
+	return &Schedule{
+		ID:         s.ID,
+		Name:       s.Name,
+		ReportType: s.ReportType,
+		Format:     s.Format,
+		Frequency:  s.Frequency,
+		Weekday:    s.Weekday,
+		Hour:       s.Hour,
+		Recipients: s.Recipients,
+		Enabled:    s.Enabled,
+		NextRunAt:  s.NextRunAt,
+		LastRunAt:  s.LastRunAt,
+		LastError:  s.LastError,
+		CreatedBy:  s.CreatedBy,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
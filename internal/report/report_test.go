@@ -0,0 +1,83 @@
+package report_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	reportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/report"
+	"github.com/frahmantamala/expense-management/internal/report"
+)
+
+var _ = Describe("Schedule timing", func() {
+	// 2025-03-12 is a Wednesday.
+	now := time.Date(2025, 3, 12, 9, 30, 0, 0, time.UTC)
+
+	DescribeTable("NextRun",
+		func(frequency string, weekday, hour int, want time.Time) {
+			s := &reportDatamodel.Schedule{Frequency: frequency, Weekday: weekday, Hour: hour}
+			Expect(report.NextRun(s, now)).To(Equal(want))
+		},
+		Entry("daily, later today", report.FrequencyDaily, 0, 10, time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)),
+		Entry("daily, hour passed", report.FrequencyDaily, 0, 9, time.Date(2025, 3, 13, 9, 0, 0, 0, time.UTC)),
+		Entry("weekly on Monday", report.FrequencyWeekly, 1, 7, time.Date(2025, 3, 17, 7, 0, 0, 0, time.UTC)),
+		Entry("weekly on today, hour passed", report.FrequencyWeekly, 3, 7, time.Date(2025, 3, 19, 7, 0, 0, 0, time.UTC)),
+		Entry("monthly", report.FrequencyMonthly, 0, 7, time.Date(2025, 4, 1, 7, 0, 0, 0, time.UTC)),
+	)
+
+	DescribeTable("Period",
+		func(frequency string, from, to time.Time) {
+			gotFrom, gotTo := report.Period(frequency, now)
+			Expect(gotFrom).To(Equal(from))
+			Expect(gotTo).To(Equal(to))
+		},
+		Entry("daily", report.FrequencyDaily, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)),
+		Entry("weekly", report.FrequencyWeekly, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)),
+		Entry("monthly", report.FrequencyMonthly, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)),
+	)
+})
+
+var _ = Describe("Render", func() {
+	r := &report.Report{
+		Type: report.TypeSpendByDepartment,
+		From: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		Lines: []*report.Line{
+			{Group: "R&D", Expenses: 3, AmountIDR: 900000},
+			{Group: "", Expenses: 1, AmountIDR: 50000},
+		},
+	}
+
+	It("writes CSV with a total row", func() {
+		data, err := report.Render(r, report.FormatCSV)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("Department,Expenses,Amount (IDR)\nR&D,3,900000\nUnassigned,1,50000\nTotal,4,950000\n"))
+	})
+
+	It("writes an XLSX workbook with numeric cells", func() {
+		data, err := report.Render(r, report.FormatXLSX)
+		Expect(err).NotTo(HaveOccurred())
+
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).NotTo(HaveOccurred())
+		var sheet []byte
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, err := f.Open()
+				Expect(err).NotTo(HaveOccurred())
+				sheet, _ = io.ReadAll(rc)
+				rc.Close()
+			}
+		}
+		Expect(string(sheet)).To(ContainSubstring(`<c r="A2" t="inlineStr"><is><t>R&amp;D</t></is></c><c r="B2"><v>3</v></c><c r="C2"><v>900000</v></c>`))
+		Expect(string(sheet)).To(ContainSubstring(`<row r="4"><c r="A4" t="inlineStr"><is><t>Total</t></is></c>`))
+	})
+
+	It("names the attachment after the report and its last day", func() {
+		Expect(report.Filename(r, report.FormatXLSX)).To(Equal("spend-by-department-2025-03-03-to-2025-03-09.xlsx"))
+	})
+})
@@ -0,0 +1,242 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	reportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/report"
+	"github.com/frahmantamala/expense-management/internal/notification"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI reads and writes the schedules of the tenant ctx is scoped
+// to; ClaimDue and RecordRun work across tenants for the worker.
+type RepositoryAPI interface {
+	List(ctx context.Context) ([]*reportDatamodel.Schedule, error)
+	// Get returns nil when there is no such schedule.
+	Get(ctx context.Context, id int64) (*reportDatamodel.Schedule, error)
+	Create(ctx context.Context, s *reportDatamodel.Schedule) error
+	Update(ctx context.Context, s *reportDatamodel.Schedule) error
+	Delete(ctx context.Context, id int64) (bool, error)
+	// ClaimDue returns an enabled schedule whose next run is at or before
+	// now, as it was before claiming, after moving its next run to
+	// next(schedule). It returns nil when none is due; concurrent workers
+	// never claim the same run.
+	ClaimDue(ctx context.Context, now time.Time, next func(*reportDatamodel.Schedule) time.Time) (*reportDatamodel.Schedule, error)
+	RecordRun(ctx context.Context, id int64, ranAt time.Time, runErr *string) error
+	// Spend totals approved and paid expenses dated in [from, to) by
+	// department or category, depending on reportType.
+	Spend(ctx context.Context, reportType string, from, to time.Time) ([]*Line, error)
+	// ApprovalLatency measures expenses a user approved in [from, to) by
+	// group, one of the Group constants, counting those approved within
+	// target.
+	ApprovalLatency(ctx context.Context, group string, from, to time.Time, target time.Duration) ([]*ApprovalLatency, error)
+	// Unpaid lists approved expenses still to be paid.
+	Unpaid(ctx context.Context) ([]*Unpaid, error)
+	// PaymentLeadTime measures the successful payments made in [from, to)
+	// from approval to payout.
+	PaymentLeadTime(ctx context.Context, from, to time.Time) (*LeadTime, error)
+}
+
+// Service manages report schedules and sends the reports they describe.
+type Service struct {
+	repo   RepositoryAPI
+	mailer notification.Mailer
+	// approvalSLA is the time within which expenses should be approved.
+	approvalSLA time.Duration
+	logger      *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, mailer notification.Mailer, approvalSLA time.Duration, logger *slog.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		mailer:      mailer,
+		approvalSLA: approvalSLA,
+		logger:      logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) ListSchedules(ctx context.Context) ([]*Schedule, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report schedules: %w", err)
+	}
+
+	schedules := make([]*Schedule, len(rows))
+	for i, row := range rows {
+		schedules[i] = fromDataModel(row)
+	}
+	return schedules, nil
+}
+
+func (s *Service) CreateSchedule(ctx context.Context, createdBy int64, dto ScheduleDTO) (*Schedule, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	row := &reportDatamodel.Schedule{CreatedBy: createdBy}
+	apply(row, dto)
+	row.NextRunAt = NextRun(row, time.Now())
+	if err := s.repo.Create(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to create report schedule: %w", err)
+	}
+
+	s.log(ctx).Info("report schedule created", "schedule_id", row.ID, "report_type", row.ReportType, "frequency", row.Frequency, "created_by", createdBy)
+	return fromDataModel(row), nil
+}
+
+// UpdateSchedule replaces the schedule and works out its next run again.
+func (s *Service) UpdateSchedule(ctx context.Context, id int64, dto ScheduleDTO) (*Schedule, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	row, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load report schedule: %w", err)
+	}
+	if row == nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	apply(row, dto)
+	row.NextRunAt = NextRun(row, time.Now())
+	if err := s.repo.Update(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to update report schedule: %w", err)
+	}
+
+	s.log(ctx).Info("report schedule updated", "schedule_id", id)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) DeleteSchedule(ctx context.Context, id int64) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete report schedule: %w", err)
+	}
+	if !deleted {
+		return ErrScheduleNotFound
+	}
+
+	s.log(ctx).Info("report schedule deleted", "schedule_id", id)
+	return nil
+}
+
+// SendNow sends the schedule's report for the period before now, without
+// moving its next run. Delivery failures are recorded on the schedule, not
+// returned.
+func (s *Service) SendNow(ctx context.Context, id int64, now time.Time) (*Schedule, error) {
+	row, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load report schedule: %w", err)
+	}
+	if row == nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	if err := s.run(ctx, row, now); err != nil {
+		return nil, err
+	}
+	return fromDataModel(row), nil
+}
+
+// ProcessDue sends the next due schedule, reporting whether there was one.
+func (s *Service) ProcessDue(ctx context.Context, now time.Time) (bool, error) {
+	row, err := s.repo.ClaimDue(ctx, now, func(sc *reportDatamodel.Schedule) time.Time {
+		return NextRun(sc, now)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim report schedule: %w", err)
+	}
+	if row == nil {
+		return false, nil
+	}
+
+	// Schedules are claimed across tenants; each report covers only its
+	// own tenant's expenses.
+	ctx = tenant.NewContext(ctx, row.TenantID)
+	return true, s.run(ctx, row, row.NextRunAt)
+}
+
+// run generates the report for the period before runAt, mails it to every
+// recipient and records the outcome on row. Only a failure to record the
+// outcome is returned.
+func (s *Service) run(ctx context.Context, row *reportDatamodel.Schedule, runAt time.Time) error {
+	runErr := s.send(ctx, row, runAt)
+
+	ranAt := time.Now()
+	var lastError *string
+	if runErr != nil {
+		msg := runErr.Error()
+		lastError = &msg
+		s.log(ctx).Error("scheduled report failed", "schedule_id", row.ID, "error", runErr)
+	}
+	if err := s.repo.RecordRun(ctx, row.ID, ranAt, lastError); err != nil {
+		return fmt.Errorf("failed to record report run: %w", err)
+	}
+	row.LastRunAt = &ranAt
+	row.LastError = lastError
+	return nil
+}
+
+func (s *Service) send(ctx context.Context, row *reportDatamodel.Schedule, runAt time.Time) error {
+	from, to := Period(row.Frequency, runAt)
+	lines, err := s.repo.Spend(ctx, row.ReportType, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	r := &Report{Type: row.ReportType, From: from, To: to, Lines: lines}
+	data, err := Render(r, row.Format)
+	if err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	expenses, amount := r.Totals()
+	last := to.AddDate(0, 0, -1)
+	msg := notification.Message{
+		Subject: fmt.Sprintf("%s: %s", row.Name, r.Title()),
+		Body: fmt.Sprintf("%s from %s to %s: %d approved expenses totalling %d IDR.\nThe full report is attached.\n",
+			r.Title(), from.Format(dateLayout), last.Format(dateLayout), expenses, amount),
+		Attachments: []notification.Attachment{{
+			Filename:    Filename(r, row.Format),
+			ContentType: ContentType(row.Format),
+			Data:        data,
+		}},
+	}
+
+	var failed []string
+	for _, to := range row.Recipients {
+		msg.To = to
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.log(ctx).Error("failed to send scheduled report", "schedule_id", row.ID, "to", to, "error", err)
+			failed = append(failed, to)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send to %s", strings.Join(failed, ", "))
+	}
+
+	s.log(ctx).Info("scheduled report sent", "schedule_id", row.ID, "report_type", row.ReportType,
+		"from", from.Format(dateLayout), "to", last.Format(dateLayout), "recipients", len(row.Recipients))
+	return nil
+}
+
+func apply(row *reportDatamodel.Schedule, dto ScheduleDTO) {
+	row.Name = dto.Name
+	row.ReportType = dto.ReportType
+	row.Format = dto.Format
+	row.Frequency = dto.Frequency
+	row.Weekday = dto.Weekday
+	row.Hour = dto.Hour
+	row.Recipients = dto.Recipients
+	row.Enabled = dto.Enabled == nil || *dto.Enabled
+}
@@ -0,0 +1,343 @@
+package report_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	reportDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/report"
+	"github.com/frahmantamala/expense-management/internal/notification"
+	"github.com/frahmantamala/expense-management/internal/report"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type mockRepository struct {
+	schedules map[int64]*reportDatamodel.Schedule
+	lines     []*report.Line
+	spendFrom time.Time
+	spendTo   time.Time
+	nextID    int64
+	latency   map[string][]*report.ApprovalLatency
+	latencyTo time.Time
+	target    time.Duration
+	unpaid    []*report.Unpaid
+	leadTime  report.LeadTime
+	leadFrom  time.Time
+}
+
+func (m *mockRepository) List(_ context.Context) ([]*reportDatamodel.Schedule, error) {
+	var out []*reportDatamodel.Schedule
+	for _, s := range m.schedules {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (m *mockRepository) Get(_ context.Context, id int64) (*reportDatamodel.Schedule, error) {
+	return m.schedules[id], nil
+}
+
+func (m *mockRepository) Create(_ context.Context, s *reportDatamodel.Schedule) error {
+	m.nextID++
+	s.ID = m.nextID
+	m.schedules[s.ID] = s
+	return nil
+}
+
+func (m *mockRepository) Update(_ context.Context, s *reportDatamodel.Schedule) error {
+	m.schedules[s.ID] = s
+	return nil
+}
+
+func (m *mockRepository) Delete(_ context.Context, id int64) (bool, error) {
+	_, ok := m.schedules[id]
+	delete(m.schedules, id)
+	return ok, nil
+}
+
+func (m *mockRepository) ClaimDue(_ context.Context, now time.Time, next func(*reportDatamodel.Schedule) time.Time) (*reportDatamodel.Schedule, error) {
+	for _, s := range m.schedules {
+		if s.Enabled && !s.NextRunAt.After(now) {
+			claimed := *s
+			s.NextRunAt = next(s)
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) RecordRun(_ context.Context, id int64, ranAt time.Time, runErr *string) error {
+	m.schedules[id].LastRunAt = &ranAt
+	m.schedules[id].LastError = runErr
+	return nil
+}
+
+func (m *mockRepository) Spend(_ context.Context, _ string, from, to time.Time) ([]*report.Line, error) {
+	m.spendFrom, m.spendTo = from, to
+	return m.lines, nil
+}
+
+func (m *mockRepository) ApprovalLatency(_ context.Context, group string, _, to time.Time, target time.Duration) ([]*report.ApprovalLatency, error) {
+	m.latencyTo, m.target = to, target
+	return m.latency[group], nil
+}
+
+func (m *mockRepository) Unpaid(_ context.Context) ([]*report.Unpaid, error) {
+	return m.unpaid, nil
+}
+
+func (m *mockRepository) PaymentLeadTime(_ context.Context, from, _ time.Time) (*report.LeadTime, error) {
+	m.leadFrom = from
+	lt := m.leadTime
+	return &lt, nil
+}
+
+type sentMessage struct {
+	notification.Message
+	tenantID int64
+}
+
+type mockMailer struct {
+	sent []sentMessage
+	fail map[string]bool
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg notification.Message) error {
+	if m.fail[msg.To] {
+		return fmt.Errorf("mailbox unavailable")
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	m.sent = append(m.sent, sentMessage{Message: msg, tenantID: tenantID})
+	return nil
+}
+
+var _ = Describe("Report service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		mailer  *mockMailer
+		service *report.Service
+	)
+
+	validDTO := func() report.ScheduleDTO {
+		return report.ScheduleDTO{
+			Name:       "Weekly department spend",
+			ReportType: report.TypeSpendByDepartment,
+			Format:     report.FormatXLSX,
+			Frequency:  report.FrequencyWeekly,
+			Weekday:    1,
+			Hour:       7,
+			Recipients: []string{"finance@example.com", "cfo@example.com"},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{schedules: map[int64]*reportDatamodel.Schedule{}}
+		mailer = &mockMailer{fail: map[string]bool{}}
+		service = report.NewService(repo, mailer, 48*time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	Describe("CreateSchedule", func() {
+		It("enables the schedule and plans its first run", func() {
+			s, err := service.CreateSchedule(ctx, 1, validDTO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Enabled).To(BeTrue())
+			Expect(s.NextRunAt.Weekday()).To(Equal(time.Monday))
+			Expect(s.NextRunAt.Hour()).To(Equal(7))
+			Expect(s.NextRunAt).To(BeTemporally(">", time.Now()))
+		})
+
+		DescribeTable("rejects invalid schedules",
+			func(mutate func(*report.ScheduleDTO), field string) {
+				dto := validDTO()
+				mutate(&dto)
+				_, err := service.CreateSchedule(ctx, 1, dto)
+				appErr, ok := errors.IsAppError(err)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Details.(errors.ValidationErrors).Errors[0].Field).To(Equal(field))
+				Expect(repo.schedules).To(BeEmpty())
+			},
+			Entry("unknown report type", func(d *report.ScheduleDTO) { d.ReportType = "spend_by_moon" }, "report_type"),
+			Entry("unknown format", func(d *report.ScheduleDTO) { d.Format = "pdf" }, "format"),
+			Entry("hour out of range", func(d *report.ScheduleDTO) { d.Hour = 24 }, "hour"),
+			Entry("no recipients", func(d *report.ScheduleDTO) { d.Recipients = nil }, "recipients"),
+			Entry("invalid recipient", func(d *report.ScheduleDTO) { d.Recipients = []string{"Finance <finance@example.com>"} }, "recipients"),
+		)
+	})
+
+	Describe("UpdateSchedule", func() {
+		It("reports unknown schedules as not found", func() {
+			_, err := service.UpdateSchedule(ctx, 42, validDTO())
+			Expect(err).To(Equal(report.ErrScheduleNotFound))
+		})
+
+		It("can disable a schedule", func() {
+			s, err := service.CreateSchedule(ctx, 1, validDTO())
+			Expect(err).NotTo(HaveOccurred())
+
+			dto := validDTO()
+			disabled := false
+			dto.Enabled = &disabled
+			s, err = service.UpdateSchedule(ctx, s.ID, dto)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Enabled).To(BeFalse())
+		})
+	})
+
+	Describe("ProcessDue", func() {
+		var due time.Time
+
+		BeforeEach(func() {
+			// Monday 2025-03-17 07:00 UTC.
+			due = time.Date(2025, 3, 17, 7, 0, 0, 0, time.UTC)
+			repo.lines = []*report.Line{{Group: "Sales", Expenses: 2, AmountIDR: 300000}}
+			repo.schedules[1] = &reportDatamodel.Schedule{
+				ID: 1, TenantID: 3, Name: "Weekly department spend",
+				ReportType: report.TypeSpendByDepartment, Format: report.FormatXLSX,
+				Frequency: report.FrequencyWeekly, Weekday: 1, Hour: 7,
+				Recipients: []string{"finance@example.com", "cfo@example.com"},
+				Enabled:    true, NextRunAt: due,
+			}
+		})
+
+		It("emails the previous week's report to every recipient within the schedule's tenant", func() {
+			processed, err := service.ProcessDue(ctx, due.Add(30*time.Second))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeTrue())
+
+			Expect(repo.spendFrom).To(Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)))
+			Expect(repo.spendTo).To(Equal(time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)))
+
+			Expect(mailer.sent).To(HaveLen(2))
+			Expect(mailer.sent[0].To).To(Equal("finance@example.com"))
+			Expect(mailer.sent[0].tenantID).To(Equal(int64(3)))
+			Expect(mailer.sent[0].Body).To(ContainSubstring("from 2025-03-10 to 2025-03-16: 2 approved expenses totalling 300000 IDR"))
+			Expect(mailer.sent[0].Attachments).To(HaveLen(1))
+			Expect(mailer.sent[0].Attachments[0].Filename).To(Equal("spend-by-department-2025-03-10-to-2025-03-16.xlsx"))
+			Expect(mailer.sent[0].Attachments[0].ContentType).To(Equal(report.ContentType(report.FormatXLSX)))
+
+			Expect(repo.schedules[1].NextRunAt).To(Equal(time.Date(2025, 3, 24, 7, 0, 0, 0, time.UTC)))
+			Expect(repo.schedules[1].LastRunAt).NotTo(BeNil())
+			Expect(repo.schedules[1].LastError).To(BeNil())
+
+			processed, err = service.ProcessDue(ctx, due.Add(time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeFalse())
+		})
+
+		It("records recipients that could not be reached", func() {
+			mailer.fail["cfo@example.com"] = true
+
+			processed, err := service.ProcessDue(ctx, due)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeTrue())
+			Expect(mailer.sent).To(HaveLen(1))
+			Expect(*repo.schedules[1].LastError).To(ContainSubstring("cfo@example.com"))
+		})
+
+		It("skips disabled schedules", func() {
+			repo.schedules[1].Enabled = false
+
+			processed, err := service.ProcessDue(ctx, due)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processed).To(BeFalse())
+		})
+	})
+
+	It("sends a report on demand without moving the next run", func() {
+		s, err := service.CreateSchedule(ctx, 1, validDTO())
+		Expect(err).NotTo(HaveOccurred())
+
+		sent, err := service.SendNow(ctx, s.ID, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sent.LastRunAt).NotTo(BeNil())
+		Expect(sent.NextRunAt).To(Equal(s.NextRunAt))
+		Expect(mailer.sent).To(HaveLen(2))
+	})
+
+	Describe("ApprovalSLA", func() {
+		now := time.Date(2025, 11, 2, 15, 0, 0, 0, time.UTC)
+
+		It("defaults to the last 30 days and reports every grouping", func() {
+			repo.latency = map[string][]*report.ApprovalLatency{
+				report.GroupOverall: {{Approvals: 3, MedianSeconds: 3600, P95Seconds: 200000, WithinTarget: 2}},
+				report.GroupManager: {{Group: "manager@example.com", Approvals: 3, MedianSeconds: 3600, P95Seconds: 200000, WithinTarget: 2}},
+			}
+
+			result, err := service.ApprovalSLA(ctx, report.SLAQuery{}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.From).To(Equal("2025-10-04"))
+			Expect(result.To).To(Equal("2025-11-02"))
+			Expect(result.TargetSeconds).To(Equal(int64(48 * 3600)))
+			Expect(result.Overall.Approvals).To(Equal(int64(3)))
+			Expect(result.Managers).To(HaveLen(1))
+			Expect(result.Departments).To(BeEmpty())
+			Expect(repo.latencyTo).To(Equal(time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)))
+			Expect(repo.target).To(Equal(48 * time.Hour))
+		})
+
+		It("reports zeroes when nothing was approved", func() {
+			result, err := service.ApprovalSLA(ctx, report.SLAQuery{}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Overall).To(Equal(&report.ApprovalLatency{}))
+		})
+	})
+
+	It("clamps approval SLA ranges to a year", func() {
+		q := report.SLAQuery{From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC)}
+		q.SetDefaults(time.Now())
+		Expect(q.From).To(Equal(time.Date(2024, 11, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	Describe("CashFlow", func() {
+		// A Wednesday; the forecast starts on Monday 3 November.
+		now := time.Date(2025, 11, 5, 9, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			repo.leadTime = report.LeadTime{Payments: 40, MedianSeconds: 6 * 24 * 3600, P95Seconds: 9 * 24 * 3600}
+			repo.unpaid = []*report.Unpaid{
+				{ExpenseID: 1, AmountIDR: 100, Status: "approved", ApprovedAt: time.Date(2025, 11, 4, 10, 0, 0, 0, time.UTC)},
+				{ExpenseID: 2, AmountIDR: 200, Status: "processing_payment", ApprovedAt: time.Date(2025, 10, 20, 10, 0, 0, 0, time.UTC)},
+				{ExpenseID: 3, AmountIDR: 300, Status: "payment_failed", ApprovedAt: time.Date(2025, 11, 4, 10, 0, 0, 0, time.UTC)},
+				{ExpenseID: 4, AmountIDR: 500, Status: "approved", ApprovedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)},
+			}
+		})
+
+		It("ages unpaid expenses and forecasts them by week from the median lead time", func() {
+			result, err := service.CashFlow(ctx, report.CashFlowQuery{}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.leadFrom).To(Equal(time.Date(2025, 8, 7, 0, 0, 0, 0, time.UTC)))
+			Expect(result.AsOf).To(Equal("2025-11-05"))
+			Expect(result.LeadTime.Payments).To(Equal(int64(40)))
+			Expect(result.Outstanding).To(Equal(report.Outstanding{Expenses: 4, AmountIDR: 1100}))
+
+			Expect(result.Aging).To(HaveLen(4))
+			Expect(result.Aging[0].Outstanding).To(Equal(report.Outstanding{Expenses: 2, AmountIDR: 400}))
+			Expect(result.Aging[1].Outstanding).To(Equal(report.Outstanding{}))
+			Expect(result.Aging[2].Outstanding).To(Equal(report.Outstanding{Expenses: 1, AmountIDR: 200}))
+			Expect(result.Aging[3].Outstanding).To(Equal(report.Outstanding{Expenses: 1, AmountIDR: 500}))
+
+			Expect(result.Weeks).To(HaveLen(report.DefaultForecastWeeks))
+			Expect(result.Weeks[0].WeekStart).To(Equal("2025-11-03"))
+			Expect(result.Weeks[0].Outstanding).To(Equal(report.Outstanding{Expenses: 3, AmountIDR: 1000}))
+			Expect(result.Weeks[0].Overdue).To(Equal(int64(3)))
+			Expect(result.Weeks[1].WeekStart).To(Equal("2025-11-10"))
+			Expect(result.Weeks[1].Outstanding).To(Equal(report.Outstanding{Expenses: 1, AmountIDR: 100}))
+			Expect(result.Later).To(Equal(report.Outstanding{}))
+		})
+
+		It("reports expenses expected after the forecast as later", func() {
+			result, err := service.CashFlow(ctx, report.CashFlowQuery{Weeks: 1}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Weeks).To(HaveLen(1))
+			Expect(result.Later).To(Equal(report.Outstanding{Expenses: 1, AmountIDR: 100}))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Approval latency groupings. GroupOverall puts every approval in one
+// group.
+const (
+	GroupOverall    = ""
+	GroupManager    = "manager"
+	GroupDepartment = "department"
+)
+
+const (
+	// DefaultSLADays is how many days an approval SLA report covers when
+	// the caller does not say.
+	DefaultSLADays = 30
+	// MaxSLADays bounds the range of one report.
+	MaxSLADays = 366
+)
+
+// ApprovalLatency is how long one group's approvals took, from submission
+// to approval. Only expenses a user approved count; auto-approved ones
+// took no one's time.
+type ApprovalLatency struct {
+	// Group is the approving manager's email or the submitter's
+	// department, empty for the overall figures and for users without a
+	// department.
+	Group         string  `json:"group"`
+	Approvals     int64   `json:"approvals"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P95Seconds    float64 `json:"p95_seconds"`
+	// WithinTarget counts the approvals made within the SLA target.
+	WithinTarget int64 `json:"within_target"`
+}
+
+// ApprovalSLAReport is the approval latency of the caller's tenant for
+// expenses approved from From to To, both included.
+type ApprovalSLAReport struct {
+	From          string             `json:"from" example:"2025-10-04"`
+	To            string             `json:"to" example:"2025-11-02"`
+	TargetSeconds int64              `json:"target_seconds"`
+	Overall       *ApprovalLatency   `json:"overall"`
+	Managers      []*ApprovalLatency `json:"managers"`
+	Departments   []*ApprovalLatency `json:"departments"`
+}
+
+// SLAQuery selects the days whose approvals are reported. To defaults to
+// today and From to DefaultSLADays before To.
+type SLAQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseFromRequest reads from and to (YYYY-MM-DD), ignoring values that do
+// not parse.
+func (q *SLAQuery) ParseFromRequest(r *http.Request) {
+	query := r.URL.Query()
+	if from, err := time.Parse(time.DateOnly, query.Get("from")); err == nil {
+		q.From = from
+	}
+	if to, err := time.Parse(time.DateOnly, query.Get("to")); err == nil {
+		q.To = to
+	}
+}
+
+// SetDefaults fills in the range relative to now and clamps it to
+// MaxSLADays.
+func (q *SLAQuery) SetDefaults(now time.Time) {
+	if q.To.IsZero() {
+		now = now.UTC()
+		q.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if q.From.IsZero() || q.From.After(q.To) {
+		q.From = q.To.AddDate(0, 0, -(DefaultSLADays - 1))
+	}
+	if earliest := q.To.AddDate(0, 0, -(MaxSLADays - 1)); q.From.Before(earliest) {
+		q.From = earliest
+	}
+}
+
+// ApprovalSLA reports median and 95th percentile approval latency overall,
+// per approving manager and per submitter department, slowest first.
+func (s *Service) ApprovalSLA(ctx context.Context, q SLAQuery, now time.Time) (*ApprovalSLAReport, error) {
+	q.SetDefaults(now)
+	from, to := q.From, q.To.AddDate(0, 0, 1)
+
+	latency := func(group string) ([]*ApprovalLatency, error) {
+		rows, err := s.repo.ApprovalLatency(ctx, group, from, to, s.approvalSLA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure approval latency: %w", err)
+		}
+		return rows, nil
+	}
+
+	overall, err := latency(GroupOverall)
+	if err != nil {
+		return nil, err
+	}
+	managers, err := latency(GroupManager)
+	if err != nil {
+		return nil, err
+	}
+	departments, err := latency(GroupDepartment)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApprovalSLAReport{
+		From:          q.From.Format(time.DateOnly),
+		To:            q.To.Format(time.DateOnly),
+		TargetSeconds: int64(s.approvalSLA / time.Second),
+		Overall:       &ApprovalLatency{},
+		Managers:      managers,
+		Departments:   departments,
+	}
+	if len(overall) > 0 {
+		result.Overall = overall[0]
+	}
+	if result.Managers == nil {
+		result.Managers = []*ApprovalLatency{}
+	}
+	if result.Departments == nil {
+		result.Departments = []*ApprovalLatency{}
+	}
+	return result, nil
+}
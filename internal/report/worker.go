@@ -0,0 +1,51 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Worker polls for schedules that are due and sends their reports.
+type Worker struct {
+	service  *Service
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewWorker(service *Service, interval time.Duration, logger *slog.Logger) *Worker {
+	return &Worker{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("report worker started", "poll_interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("report worker stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// Send everything that is due before sleeping again.
+		for ctx.Err() == nil {
+			processed, err := w.service.ProcessDue(ctx, time.Now())
+			if err != nil {
+				w.logger.Error("report run failed", "error", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+	}
+}
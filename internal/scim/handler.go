@@ -0,0 +1,233 @@
+package scim
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	CreateUser(ctx context.Context, in *User) (*User, error)
+	GetUser(ctx context.Context, id string) (*User, error)
+	ListUsers(ctx context.Context, filter string, startIndex, count int) (*ListResponse, error)
+	ReplaceUser(ctx context.Context, id string, in *User) (*User, error)
+	PatchUser(ctx context.Context, id string, req *PatchRequest) (*User, error)
+	DeleteUser(ctx context.Context, id string) error
+	ListGroups(ctx context.Context) *ListResponse
+	GetGroup(ctx context.Context, id string) (*Group, error)
+	PatchGroup(ctx context.Context, id string, req *PatchRequest) error
+}
+
+// Handler serves the SCIM 2.0 endpoints under /scim/v2. They speak SCIM
+// rather than the API's JSON conventions, so they are not part of the
+// OpenAPI spec.
+type Handler struct {
+	*transport.BaseHandler
+	Service  ServiceAPI
+	apiKey   string
+	tenantID int64
+}
+
+// NewHandler provisions into tenantID for callers presenting apiKey as a
+// bearer token.
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI, apiKey string, tenantID int64) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+		apiKey:      apiKey,
+		tenantID:    tenantID,
+	}
+}
+
+// Authenticate checks the provisioning API key and scopes the request to the
+// provisioned tenant.
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := h.ExtractTokenFromHeader(r)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.apiKey)) != 1 {
+			h.Log(r).Warn("scim request with invalid api key", "remote_addr", r.RemoteAddr)
+			h.writeError(w, r, newError(http.StatusUnauthorized, "", "invalid provisioning api key"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), h.tenantID)))
+	})
+}
+
+func (h *Handler) Routes(r chi.Router) {
+	r.Use(h.Authenticate)
+	r.Get("/ServiceProviderConfig", h.ServiceProviderConfig)
+	r.Route("/Users", func(ur chi.Router) {
+		ur.Get("/", h.ListUsers)
+		ur.Post("/", h.CreateUser)
+		ur.Get("/{id}", h.GetUser)
+		ur.Put("/{id}", h.ReplaceUser)
+		ur.Patch("/{id}", h.PatchUser)
+		ur.Delete("/{id}", h.DeleteUser)
+	})
+	r.Route("/Groups", func(gr chi.Router) {
+		gr.Get("/", h.ListGroups)
+		gr.Get("/{id}", h.GetGroup)
+		gr.Patch("/{id}", h.PatchGroup)
+	})
+}
+
+func (h *Handler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	h.write(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": MaxPageSize},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "Provisioning API key",
+			"description": "The scim.api_key sent as a bearer token",
+		}},
+	})
+}
+
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	startIndex, _ := strconv.Atoi(query.Get("startIndex"))
+	count := DefaultPageSize
+	if raw := query.Get("count"); raw != "" {
+		count, _ = strconv.Atoi(raw)
+	}
+
+	list, err := h.Service.ListUsers(r.Context(), query.Get("filter"), startIndex, count)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, list)
+}
+
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var in User
+	if !h.decode(w, r, &in) {
+		return
+	}
+
+	created, err := h.Service.CreateUser(r.Context(), &in)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", created.Meta.Location)
+	h.write(w, http.StatusCreated, created)
+}
+
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	u, err := h.Service.GetUser(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, u)
+}
+
+func (h *Handler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var in User
+	if !h.decode(w, r, &in) {
+		return
+	}
+
+	u, err := h.Service.ReplaceUser(r.Context(), chi.URLParam(r, "id"), &in)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, u)
+}
+
+func (h *Handler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	var req PatchRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	u, err := h.Service.PatchUser(r.Context(), chi.URLParam(r, "id"), &req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, u)
+}
+
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.DeleteUser(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	h.write(w, http.StatusOK, h.Service.ListGroups(r.Context()))
+}
+
+func (h *Handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	g, err := h.Service.GetGroup(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, g)
+}
+
+func (h *Handler) PatchGroup(w http.ResponseWriter, r *http.Request) {
+	var req PatchRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.Service.PatchGroup(r.Context(), chi.URLParam(r, "id"), &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decode reads a SCIM body. Unlike DecodeJSON it ignores unknown attributes,
+// since identity providers send more of the schema than is stored.
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, transport.DefaultMaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			h.writeError(w, r, newError(http.StatusRequestEntityTooLarge, "", "request body is too large"))
+			return false
+		}
+		h.writeError(w, r, newError(http.StatusBadRequest, "invalidSyntax", "request body is not valid JSON"))
+		return false
+	}
+	return true
+}
+
+func (h *Handler) write(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.Logger.Error("failed to encode SCIM response", "error", err)
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *Error
+	if !stderrors.As(err, &scimErr) {
+		h.Log(r).Error("scim request failed", "error", err)
+		scimErr = newError(http.StatusInternalServerError, "", "internal server error")
+	} else {
+		h.Log(r).Warn("scim request rejected", "status", scimErr.Status, "scim_type", scimErr.ScimType, "detail", scimErr.Detail)
+	}
+	h.write(w, scimErr.StatusCode(), scimErr)
+}
@@ -0,0 +1,90 @@
+package scim_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/scim"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+var _ = Describe("Handler", func() {
+	const apiKey = "provisioning-key-0123456789abcdef"
+
+	var (
+		dir    *fakeDirectory
+		router *chi.Mux
+	)
+
+	BeforeEach(func() {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		dir = newFakeDirectory()
+		service := scim.NewService(dir, map[string][]string{"employees": {"create_expenses"}}, "https://expenses.example.com/scim/v2", logger)
+		handler := scim.NewHandler(transport.NewBaseHandler(logger), service, apiKey, 3)
+		router = chi.NewRouter()
+		router.Route("/scim/v2", handler.Routes)
+	})
+
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", scim.ContentType)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("rejects requests without the provisioning key", func() {
+		rec := serve(http.MethodGet, "/scim/v2/Users", "wrong", "")
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(rec.Header().Get("Content-Type")).To(Equal(scim.ContentType))
+
+		var body scim.Error
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Schemas).To(ConsistOf(scim.SchemaError))
+		Expect(body.Status).To(Equal("401"))
+	})
+
+	It("creates users and points Location at them", func() {
+		rec := serve(http.MethodPost, "/scim/v2/Users", apiKey, `{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+			"userName": "jane@acme.com",
+			"name": {"givenName": "Jane", "familyName": "Doe"},
+			"externalId": "00u1",
+			"groups": [{"display": "Employees"}]
+		}`)
+
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(rec.Header().Get("Location")).To(Equal("https://expenses.example.com/scim/v2/Users/1"))
+		Expect(dir.users[1].Permissions).To(ConsistOf("create_expenses"))
+	})
+
+	It("answers a duplicate userName with a uniqueness conflict", func() {
+		body := `{"userName": "jane@acme.com", "displayName": "Jane"}`
+		serve(http.MethodPost, "/scim/v2/Users", apiKey, body)
+		rec := serve(http.MethodPost, "/scim/v2/Users", apiKey, body)
+
+		Expect(rec.Code).To(Equal(http.StatusConflict))
+		Expect(rec.Body.String()).To(ContainSubstring(`"scimType":"uniqueness"`))
+	})
+
+	It("deprovisions on delete", func() {
+		serve(http.MethodPost, "/scim/v2/Users", apiKey, `{"userName": "jane@acme.com", "displayName": "Jane"}`)
+
+		rec := serve(http.MethodDelete, "/scim/v2/Users/1", apiKey, "")
+
+		Expect(rec.Code).To(Equal(http.StatusNoContent))
+		Expect(dir.users[1].IsActive).To(BeFalse())
+	})
+})
@@ -0,0 +1,214 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+// ContentType is the media type of SCIM requests and responses (RFC 7644).
+const ContentType = "application/scim+json"
+
+const (
+	SchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// User is the SCIM view of a user. userName is the user's email, and groups
+// are the configured groups whose permissions the user holds.
+type User struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Emails      []Email         `json:"emails,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Password    string          `json:"password,omitempty"`
+	Groups      []GroupRef      `json:"groups,omitempty"`
+	Enterprise  *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// GroupRef names a group a user belongs to. Identity providers send either
+// the group's value (its id) or its display name.
+type GroupRef struct {
+	Value   string `json:"value,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+func (g GroupRef) name() string {
+	if g.Value != "" {
+		return g.Value
+	}
+	return g.Display
+}
+
+type EnterpriseUser struct {
+	Department string `json:"department,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// Group is a configured group. Groups are defined by the group_permissions
+// mapping and cannot be created or renamed through SCIM.
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	DisplayName string      `json:"displayName"`
+	Members     []MemberRef `json:"members,omitempty"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+type MemberRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// PatchRequest is a SCIM PatchOp message.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Error is a SCIM error response; the service returns it for requests the
+// protocol defines an error type for.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) StatusCode() int {
+	status, _ := strconv.Atoi(e.Status)
+	return status
+}
+
+func newError(status int, scimType, format string, args ...interface{}) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+	}
+}
+
+var (
+	ErrUserNotFound  = newError(http.StatusNotFound, "", "user not found")
+	ErrGroupNotFound = newError(http.StatusNotFound, "", "group not found")
+	ErrUserNameTaken = newError(http.StatusConflict, "uniqueness", "userName is already in use")
+)
+
+// displayName picks the user's name from whichever attributes the identity
+// provider filled in.
+func (u *User) displayName() string {
+	if name := strings.TrimSpace(u.DisplayName); name != "" {
+		return name
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(u.Name.Formatted); name != "" {
+		return name
+	}
+	return strings.TrimSpace(strings.TrimSpace(u.Name.GivenName) + " " + strings.TrimSpace(u.Name.FamilyName))
+}
+
+func (u *User) department() string {
+	if u.Enterprise == nil {
+		return ""
+	}
+	return u.Enterprise.Department
+}
+
+// email is userName, or the primary email when userName is not an address.
+func (u *User) email() string {
+	if strings.Contains(u.UserName, "@") || len(u.Emails) == 0 {
+		return u.UserName
+	}
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	return u.Emails[0].Value
+}
+
+func userID(id string) (int64, bool) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	return n, err == nil && n > 0
+}
+
+func fromUser(u *user.User, groups []string, baseURL string) *User {
+	id := strconv.FormatInt(u.ID, 10)
+	active := u.IsActive
+	created, modified := u.CreatedAt, u.UpdatedAt
+
+	out := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          id,
+		UserName:    u.Email,
+		Name:        &Name{Formatted: u.Name},
+		DisplayName: u.Name,
+		Emails:      []Email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     baseURL + "/Users/" + id,
+		},
+	}
+	if u.Department != "" {
+		out.Schemas = append(out.Schemas, SchemaEnterpriseUser)
+		out.Enterprise = &EnterpriseUser{Department: u.Department}
+	}
+	for _, group := range groups {
+		out.Groups = append(out.Groups, GroupRef{Value: group, Display: group})
+	}
+	return out
+}
@@ -0,0 +1,13 @@
+package scim_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSCIM(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SCIM Suite")
+}
@@ -0,0 +1,643 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/user"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 200
+)
+
+// UserDirectory is the slice of the user module SCIM provisions through;
+// user.AdminService satisfies it. Every call is confined to the tenant ctx is
+// scoped to.
+type UserDirectory interface {
+	CreateUser(ctx context.Context, dto user.CreateUserDTO) (*user.User, error)
+	GetUser(ctx context.Context, userID int64) (*user.User, error)
+	ListUsers(ctx context.Context, email string, offset, limit int) ([]*user.User, int64, error)
+	UpdateProfile(ctx context.Context, userID int64, name, department string) error
+	SetActive(ctx context.Context, userID int64, active bool) error
+	SyncPermissions(ctx context.Context, userID int64, managed, wanted []string) error
+}
+
+// Service maps SCIM users and groups onto the user module. Groups are
+// the keys of the group→permission mapping: a user is a member of a group
+// when they hold all of its permissions, and joining or leaving a group
+// grants or revokes them. Permissions outside the mapping are never touched.
+type Service struct {
+	users   UserDirectory
+	groups  map[string][]string
+	managed []string
+	baseURL string
+	logger  *slog.Logger
+}
+
+// NewService builds the service. baseURL is the public URL of the SCIM root
+// (…/scim/v2), used for meta.location.
+func NewService(users UserDirectory, groups map[string][]string, baseURL string, logger *slog.Logger) *Service {
+	var managed []string
+	for _, permissions := range groups {
+		for _, permission := range permissions {
+			if !slices.Contains(managed, permission) {
+				managed = append(managed, permission)
+			}
+		}
+	}
+	sort.Strings(managed)
+
+	return &Service{
+		users:   users,
+		groups:  groups,
+		managed: managed,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		logger:  logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *Service) CreateUser(ctx context.Context, in *User) (*User, error) {
+	password := in.Password
+	if password == "" {
+		// Provisioned users sign in through the identity provider; give them
+		// a password nobody knows until an admin resets it.
+		var err error
+		if password, err = randomPassword(); err != nil {
+			return nil, err
+		}
+	}
+
+	name := in.displayName()
+	if name == "" {
+		name = in.UserName
+	}
+
+	created, err := s.users.CreateUser(ctx, user.CreateUserDTO{
+		Email:       in.email(),
+		Name:        name,
+		Password:    password,
+		Department:  in.department(),
+		Permissions: s.permissionsFor(ctx, in.Groups),
+	})
+	if err != nil {
+		return nil, s.translate(err)
+	}
+
+	if in.Active != nil && !*in.Active {
+		if err := s.users.SetActive(ctx, created.ID, false); err != nil {
+			return nil, s.translate(err)
+		}
+	}
+
+	s.log(ctx).Info("scim user provisioned", "user_id", created.ID, "email", created.Email)
+	return s.getUser(ctx, created.ID)
+}
+
+func (s *Service) GetUser(ctx context.Context, id string) (*User, error) {
+	userID, ok := userID(id)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return s.getUser(ctx, userID)
+}
+
+var filterPattern = regexp.MustCompile(`(?i)^\s*(userName|emails\.value|emails)\s+eq\s+"([^"]*)"\s*$`)
+
+// ListUsers supports the equality filter on userName identity providers use
+// to look a user up before creating them. startIndex is 1-based.
+func (s *Service) ListUsers(ctx context.Context, filter string, startIndex, count int) (*ListResponse, error) {
+	var email string
+	if filter != "" {
+		match := filterPattern.FindStringSubmatch(filter)
+		if match == nil {
+			return nil, newError(http.StatusBadRequest, "invalidFilter", "only userName eq \"…\" filters are supported")
+		}
+		email = match[2]
+	}
+
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > MaxPageSize {
+		count = MaxPageSize
+	}
+
+	users, total, err := s.users.ListUsers(ctx, email, startIndex-1, count)
+	if err != nil {
+		return nil, s.translate(err)
+	}
+
+	resources := make([]*User, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, fromUser(u, s.groupsOf(u.Permissions), s.baseURL))
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// ReplaceUser applies a full user resource. userName cannot change, since it
+// is the user's login. Groups are synced only when the request lists them.
+func (s *Service) ReplaceUser(ctx context.Context, id string, in *User) (*User, error) {
+	current, err := s.loadUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(in.email(), current.Email) {
+		return nil, newError(http.StatusBadRequest, "mutability", "userName cannot be changed")
+	}
+
+	state := stateOf(current, s.groupsOf(current.Permissions))
+	if name := in.displayName(); name != "" {
+		state.name = name
+	}
+	state.department = in.department()
+	if in.Active != nil {
+		state.active = *in.Active
+	}
+	if in.Groups != nil {
+		state.groups = s.groupNames(ctx, in.Groups)
+	}
+
+	return s.save(ctx, current, state)
+}
+
+// PatchUser applies add, replace and remove operations to name,
+// displayName, active, the enterprise department and groups.
+func (s *Service) PatchUser(ctx context.Context, id string, req *PatchRequest) (*User, error) {
+	current, err := s.loadUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	state := stateOf(current, s.groupsOf(current.Permissions))
+	for _, op := range req.Operations {
+		if err := s.applyUserOp(ctx, state, op); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.save(ctx, current, state)
+}
+
+// DeleteUser deprovisions the user by deactivating them. The row stays so
+// their expenses and approvals keep their owner.
+func (s *Service) DeleteUser(ctx context.Context, id string) error {
+	current, err := s.loadUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.users.SetActive(ctx, current.ID, false); err != nil {
+		return s.translate(err)
+	}
+
+	s.log(ctx).Info("scim user deprovisioned", "user_id", current.ID, "email", current.Email)
+	return nil
+}
+
+func (s *Service) ListGroups(ctx context.Context) *ListResponse {
+	names := make([]string, 0, len(s.groups))
+	for name := range s.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := make([]*Group, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, s.group(name))
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: int64(len(groups)),
+		StartIndex:   1,
+		ItemsPerPage: len(groups),
+		Resources:    groups,
+	}
+}
+
+func (s *Service) GetGroup(ctx context.Context, id string) (*Group, error) {
+	name, ok := s.groupName(id)
+	if !ok {
+		return nil, ErrGroupNotFound
+	}
+	return s.group(name), nil
+}
+
+// PatchGroup adds and removes members. Replacing the whole member list is
+// not supported; identity providers push membership changes one by one.
+func (s *Service) PatchGroup(ctx context.Context, id string, req *PatchRequest) error {
+	name, ok := s.groupName(id)
+	if !ok {
+		return ErrGroupNotFound
+	}
+
+	for _, op := range req.Operations {
+		var join bool
+		switch strings.ToLower(op.Op) {
+		case "add":
+			join = true
+		case "remove":
+		default:
+			return newError(http.StatusBadRequest, "invalidValue", "group members can only be added or removed")
+		}
+
+		members, err := memberIDs(op)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			if err := s.setMembership(ctx, member, name, join); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) setMembership(ctx context.Context, id, group string, join bool) error {
+	current, err := s.loadUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	state := stateOf(current, s.groupsOf(current.Permissions))
+	if join {
+		state.addGroup(group)
+	} else {
+		state.removeGroup(group)
+	}
+	_, err = s.save(ctx, current, state)
+	return err
+}
+
+// userState is the provisioned view of a user that patches are applied to.
+type userState struct {
+	name       string
+	department string
+	active     bool
+	groups     []string
+}
+
+func stateOf(u *user.User, groups []string) *userState {
+	return &userState{
+		name:       u.Name,
+		department: u.Department,
+		active:     u.IsActive,
+		groups:     groups,
+	}
+}
+
+func (st *userState) addGroup(group string) {
+	if !slices.Contains(st.groups, group) {
+		st.groups = append(st.groups, group)
+	}
+}
+
+func (st *userState) removeGroup(group string) {
+	st.groups = slices.DeleteFunc(st.groups, func(g string) bool { return g == group })
+}
+
+func (s *Service) save(ctx context.Context, current *user.User, st *userState) (*User, error) {
+	if st.name == "" {
+		return nil, newError(http.StatusBadRequest, "invalidValue", "name must not be empty")
+	}
+	if st.name != current.Name || st.department != current.Department {
+		if err := s.users.UpdateProfile(ctx, current.ID, st.name, st.department); err != nil {
+			return nil, s.translate(err)
+		}
+	}
+
+	wanted := s.permissionsOf(st.groups)
+	if !slices.Equal(s.groupsOf(wanted), s.groupsOf(current.Permissions)) {
+		if err := s.users.SyncPermissions(ctx, current.ID, s.managed, wanted); err != nil {
+			return nil, s.translate(err)
+		}
+	}
+
+	if st.active != current.IsActive {
+		if err := s.users.SetActive(ctx, current.ID, st.active); err != nil {
+			return nil, s.translate(err)
+		}
+	}
+
+	return s.getUser(ctx, current.ID)
+}
+
+func (s *Service) applyUserOp(ctx context.Context, st *userState, op PatchOperation) error {
+	path := strings.TrimSpace(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		if path == "" {
+			attrs, ok := op.Value.(map[string]interface{})
+			if !ok {
+				return newError(http.StatusBadRequest, "invalidValue", "value must be an object when path is omitted")
+			}
+			for attr, value := range attrs {
+				if err := s.setAttr(ctx, st, attr, value, op.Op); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return s.setAttr(ctx, st, path, op.Value, op.Op)
+	case "remove":
+		if strings.EqualFold(path, "groups") {
+			if op.Value == nil {
+				st.groups = nil
+				return nil
+			}
+			for _, ref := range groupRefs(op.Value) {
+				if name, ok := s.groupName(ref); ok {
+					st.removeGroup(name)
+				}
+			}
+			return nil
+		}
+		if match := groupFilterPattern.FindStringSubmatch(path); match != nil {
+			if name, ok := s.groupName(match[1]); ok {
+				st.removeGroup(name)
+			}
+			return nil
+		}
+		if strings.EqualFold(path, SchemaEnterpriseUser+":department") {
+			st.department = ""
+			return nil
+		}
+		return newError(http.StatusBadRequest, "noTarget", "%s cannot be removed", path)
+	default:
+		return newError(http.StatusBadRequest, "invalidValue", "unsupported patch op %q", op.Op)
+	}
+}
+
+var groupFilterPattern = regexp.MustCompile(`(?i)^groups\[value eq "([^"]*)"\]$`)
+
+func (s *Service) setAttr(ctx context.Context, st *userState, attr string, value interface{}, op string) error {
+	switch strings.ToLower(attr) {
+	case "active":
+		active, ok := boolValue(value)
+		if !ok {
+			return newError(http.StatusBadRequest, "invalidValue", "active must be a boolean")
+		}
+		st.active = active
+	case "displayname", "name.formatted":
+		name, _ := value.(string)
+		st.name = strings.TrimSpace(name)
+	case "name.givenname", "name.familyname":
+		part, _ := value.(string)
+		given, family, _ := strings.Cut(st.name, " ")
+		if strings.EqualFold(attr, "name.givenName") {
+			given = part
+		} else {
+			family = part
+		}
+		st.name = strings.TrimSpace(strings.TrimSpace(given) + " " + strings.TrimSpace(family))
+	case "name":
+		fields, _ := value.(map[string]interface{})
+		in := &User{Name: &Name{}}
+		in.Name.Formatted, _ = fields["formatted"].(string)
+		in.Name.GivenName, _ = fields["givenName"].(string)
+		in.Name.FamilyName, _ = fields["familyName"].(string)
+		if name := in.displayName(); name != "" {
+			st.name = name
+		}
+	case strings.ToLower(SchemaEnterpriseUser + ":department"):
+		department, _ := value.(string)
+		st.department = strings.TrimSpace(department)
+	case strings.ToLower(SchemaEnterpriseUser):
+		fields, _ := value.(map[string]interface{})
+		if department, ok := fields["department"].(string); ok {
+			st.department = strings.TrimSpace(department)
+		}
+	case "groups":
+		refs := groupRefs(value)
+		if strings.EqualFold(op, "replace") {
+			st.groups = nil
+		}
+		for _, ref := range refs {
+			name, ok := s.groupName(ref)
+			if !ok {
+				s.log(ctx).Warn("ignoring unmapped scim group", "group", ref)
+				continue
+			}
+			st.addGroup(name)
+		}
+	case "username", "emails", "password", "externalid":
+		// userName is the login and cannot change; passwords are managed
+		// by the identity provider. externalId is not stored.
+	default:
+		return newError(http.StatusBadRequest, "invalidPath", "unsupported attribute %q", attr)
+	}
+	return nil
+}
+
+// boolValue accepts JSON booleans and the "True"/"False" strings some
+// identity providers send.
+func boolValue(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// groupRefs reads group names from a patch value: a list of {value,display}
+// objects or a single one.
+func groupRefs(value interface{}) []string {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		items = []interface{}{v}
+	}
+
+	var names []string
+	for _, item := range items {
+		fields, _ := item.(map[string]interface{})
+		ref := GroupRef{}
+		ref.Value, _ = fields["value"].(string)
+		ref.Display, _ = fields["display"].(string)
+		if name := ref.name(); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+var memberFilterPattern = regexp.MustCompile(`(?i)^members\[value eq "([^"]*)"\]$`)
+
+func memberIDs(op PatchOperation) ([]string, error) {
+	path := strings.TrimSpace(op.Path)
+	if match := memberFilterPattern.FindStringSubmatch(path); match != nil {
+		return []string{match[1]}, nil
+	}
+	if !strings.EqualFold(path, "members") {
+		return nil, newError(http.StatusBadRequest, "invalidPath", "only members can be patched")
+	}
+
+	items, _ := op.Value.([]interface{})
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		fields, _ := item.(map[string]interface{})
+		if id, ok := fields["value"].(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, newError(http.StatusBadRequest, "invalidValue", "members must list at least one value")
+	}
+	return ids, nil
+}
+
+func (s *Service) group(name string) *Group {
+	return &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          name,
+		DisplayName: name,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Location:     s.baseURL + "/Groups/" + name,
+		},
+	}
+}
+
+// groupsOf returns the configured groups whose permissions are all in
+// permissions, sorted.
+func (s *Service) groupsOf(permissions []string) []string {
+	var groups []string
+	for name, required := range s.groups {
+		if len(required) == 0 {
+			continue
+		}
+		member := true
+		for _, permission := range required {
+			if !slices.Contains(permissions, permission) {
+				member = false
+				break
+			}
+		}
+		if member {
+			groups = append(groups, name)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func (s *Service) permissionsOf(groups []string) []string {
+	var permissions []string
+	for _, group := range groups {
+		for _, permission := range s.groups[group] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// groupNames keeps the mapped groups of refs. Identity providers push every
+// group a user is in, so unmapped ones are skipped rather than refused.
+func (s *Service) groupNames(ctx context.Context, refs []GroupRef) []string {
+	groups := []string{}
+	for _, ref := range refs {
+		name, ok := s.groupName(ref.name())
+		if !ok {
+			s.log(ctx).Warn("ignoring unmapped scim group", "group", ref.name())
+			continue
+		}
+		if !slices.Contains(groups, name) {
+			groups = append(groups, name)
+		}
+	}
+	return groups
+}
+
+// groupName returns the configured name of group. Names match regardless
+// of case, since config keys are case-insensitive.
+func (s *Service) groupName(group string) (string, bool) {
+	for name := range s.groups {
+		if strings.EqualFold(name, group) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (s *Service) permissionsFor(ctx context.Context, refs []GroupRef) []string {
+	return s.permissionsOf(s.groupNames(ctx, refs))
+}
+
+func (s *Service) loadUser(ctx context.Context, id string) (*user.User, error) {
+	userID, ok := userID(id)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	u, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, s.translate(err)
+	}
+	return u, nil
+}
+
+func (s *Service) getUser(ctx context.Context, userID int64) (*User, error) {
+	u, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, s.translate(err)
+	}
+	return fromUser(u, s.groupsOf(u.Permissions), s.baseURL), nil
+}
+
+// translate turns user module errors into SCIM errors. Anything else is
+// returned as is and becomes a 500.
+func (s *Service) translate(err error) error {
+	switch {
+	case stderrors.Is(err, user.ErrNotFound):
+		return ErrUserNotFound
+	case stderrors.Is(err, user.ErrEmailTaken):
+		return ErrUserNameTaken
+	case stderrors.Is(err, user.ErrUnknownPermission):
+		return fmt.Errorf("group_permissions names a missing permission: %w", err)
+	}
+	if appErr, ok := errors.IsAppError(err); ok && appErr.StatusCode == http.StatusBadRequest {
+		return newError(http.StatusBadRequest, "invalidValue", "%s", appErr.Error())
+	}
+	return err
+}
+
+func randomPassword() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
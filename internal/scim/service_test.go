@@ -0,0 +1,269 @@
+package scim_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/scim"
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+type fakeDirectory struct {
+	users  map[int64]*user.User
+	nextID int64
+}
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{users: map[int64]*user.User{}, nextID: 1}
+}
+
+func (f *fakeDirectory) CreateUser(_ context.Context, dto user.CreateUserDTO) (*user.User, error) {
+	for _, u := range f.users {
+		if u.Email == dto.Email {
+			return nil, user.ErrEmailTaken
+		}
+	}
+	u := &user.User{
+		ID:          f.nextID,
+		Email:       dto.Email,
+		Name:        dto.Name,
+		Department:  dto.Department,
+		IsActive:    true,
+		Permissions: slices.Clone(dto.Permissions),
+	}
+	f.users[u.ID] = u
+	f.nextID++
+	return u, nil
+}
+
+func (f *fakeDirectory) GetUser(_ context.Context, userID int64) (*user.User, error) {
+	u, ok := f.users[userID]
+	if !ok {
+		return nil, user.ErrNotFound
+	}
+	copied := *u
+	copied.Permissions = slices.Clone(u.Permissions)
+	return &copied, nil
+}
+
+func (f *fakeDirectory) ListUsers(_ context.Context, email string, offset, limit int) ([]*user.User, int64, error) {
+	var out []*user.User
+	for id := int64(1); id < f.nextID; id++ {
+		if u, ok := f.users[id]; ok && (email == "" || u.Email == email) {
+			out = append(out, u)
+		}
+	}
+	total := int64(len(out))
+	if offset >= len(out) {
+		return nil, total, nil
+	}
+	out = out[offset:]
+	if limit < len(out) {
+		out = out[:limit]
+	}
+	return out, total, nil
+}
+
+func (f *fakeDirectory) UpdateProfile(_ context.Context, userID int64, name, department string) error {
+	f.users[userID].Name = name
+	f.users[userID].Department = department
+	return nil
+}
+
+func (f *fakeDirectory) SetActive(_ context.Context, userID int64, active bool) error {
+	f.users[userID].IsActive = active
+	return nil
+}
+
+func (f *fakeDirectory) SyncPermissions(_ context.Context, userID int64, managed, wanted []string) error {
+	u := f.users[userID]
+	u.Permissions = slices.DeleteFunc(u.Permissions, func(p string) bool {
+		return slices.Contains(managed, p) && !slices.Contains(wanted, p)
+	})
+	for _, p := range wanted {
+		if !slices.Contains(u.Permissions, p) {
+			u.Permissions = append(u.Permissions, p)
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx     context.Context
+		dir     *fakeDirectory
+		service *scim.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = newFakeDirectory()
+		groups := map[string][]string{
+			"employees": {"create_expenses"},
+			"managers":  {"approve_expenses", "view_reports"},
+		}
+		service = scim.NewService(dir, groups, "https://expenses.example.com/scim/v2/", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	provision := func(groups ...string) *scim.User {
+		in := &scim.User{
+			UserName: "jane@acme.com",
+			Name:     &scim.Name{GivenName: "Jane", FamilyName: "Doe"},
+			Enterprise: &scim.EnterpriseUser{
+				Department: "Finance",
+			},
+		}
+		for _, g := range groups {
+			in.Groups = append(in.Groups, scim.GroupRef{Display: g})
+		}
+		u, err := service.CreateUser(ctx, in)
+		Expect(err).NotTo(HaveOccurred())
+		return u
+	}
+
+	Describe("CreateUser", func() {
+		It("creates an active user with the permissions of their mapped groups", func() {
+			u := provision("Employees", "Managers", "Everyone")
+
+			Expect(u.ID).To(Equal("1"))
+			Expect(u.UserName).To(Equal("jane@acme.com"))
+			Expect(u.DisplayName).To(Equal("Jane Doe"))
+			Expect(*u.Active).To(BeTrue())
+			Expect(u.Enterprise.Department).To(Equal("Finance"))
+			Expect(u.Meta.Location).To(Equal("https://expenses.example.com/scim/v2/Users/1"))
+			Expect(dir.users[1].Permissions).To(ConsistOf("create_expenses", "approve_expenses", "view_reports"))
+			Expect(u.Groups).To(ConsistOf(
+				scim.GroupRef{Value: "employees", Display: "employees"},
+				scim.GroupRef{Value: "managers", Display: "managers"},
+			))
+		})
+
+		It("refuses a userName that is already in use", func() {
+			provision()
+
+			_, err := service.CreateUser(ctx, &scim.User{UserName: "jane@acme.com"})
+			Expect(err).To(MatchError(scim.ErrUserNameTaken))
+		})
+	})
+
+	Describe("ListUsers", func() {
+		It("filters by userName", func() {
+			provision()
+			_, err := service.CreateUser(ctx, &scim.User{UserName: "eko@acme.com", DisplayName: "Eko"})
+			Expect(err).NotTo(HaveOccurred())
+
+			list, err := service.ListUsers(ctx, `userName eq "eko@acme.com"`, 1, 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.TotalResults).To(Equal(int64(1)))
+			Expect(list.Resources.([]*scim.User)[0].DisplayName).To(Equal("Eko"))
+		})
+
+		It("rejects unsupported filters", func() {
+			_, err := service.ListUsers(ctx, `title co "x"`, 1, 100)
+
+			scimErr, ok := err.(*scim.Error)
+			Expect(ok).To(BeTrue())
+			Expect(scimErr.ScimType).To(Equal("invalidFilter"))
+		})
+	})
+
+	Describe("PatchUser", func() {
+		It("deactivates users from string booleans", func() {
+			u := provision()
+
+			patched, err := service.PatchUser(ctx, u.ID, &scim.PatchRequest{Operations: []scim.PatchOperation{
+				{Op: "Replace", Path: "active", Value: "False"},
+			}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*patched.Active).To(BeFalse())
+			Expect(dir.users[1].IsActive).To(BeFalse())
+		})
+
+		It("applies path-less replacements", func() {
+			u := provision()
+
+			patched, err := service.PatchUser(ctx, u.ID, &scim.PatchRequest{Operations: []scim.PatchOperation{
+				{Op: "replace", Value: map[string]interface{}{"displayName": "Jane Smith", "active": true}},
+			}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patched.DisplayName).To(Equal("Jane Smith"))
+		})
+
+		It("revokes a group's permissions when the user leaves it", func() {
+			u := provision("employees", "managers")
+			dir.users[1].Permissions = append(dir.users[1].Permissions, "admin")
+
+			_, err := service.PatchUser(ctx, u.ID, &scim.PatchRequest{Operations: []scim.PatchOperation{
+				{Op: "remove", Path: `groups[value eq "managers"]`},
+			}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dir.users[1].Permissions).To(ConsistOf("create_expenses", "admin"))
+		})
+	})
+
+	Describe("ReplaceUser", func() {
+		It("does not allow the userName to change", func() {
+			u := provision()
+
+			_, err := service.ReplaceUser(ctx, u.ID, &scim.User{UserName: "other@acme.com", DisplayName: "Jane"})
+
+			scimErr, ok := err.(*scim.Error)
+			Expect(ok).To(BeTrue())
+			Expect(scimErr.ScimType).To(Equal("mutability"))
+		})
+
+		It("syncs groups when they are listed", func() {
+			u := provision("employees")
+
+			replaced, err := service.ReplaceUser(ctx, u.ID, &scim.User{
+				UserName: "jane@acme.com",
+				Groups:   []scim.GroupRef{{Value: "managers"}},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dir.users[1].Permissions).To(ConsistOf("approve_expenses", "view_reports"))
+			Expect(replaced.DisplayName).To(Equal("Jane Doe"))
+		})
+	})
+
+	Describe("DeleteUser", func() {
+		It("deactivates instead of deleting", func() {
+			u := provision()
+
+			Expect(service.DeleteUser(ctx, u.ID)).To(Succeed())
+			Expect(dir.users[1].IsActive).To(BeFalse())
+			Expect(service.DeleteUser(ctx, "42")).To(MatchError(scim.ErrUserNotFound))
+		})
+	})
+
+	Describe("PatchGroup", func() {
+		It("adds and removes members", func() {
+			u := provision()
+			id, _ := strconv.ParseInt(u.ID, 10, 64)
+
+			Expect(service.PatchGroup(ctx, "Managers", &scim.PatchRequest{Operations: []scim.PatchOperation{
+				{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": u.ID}}},
+			}})).To(Succeed())
+			Expect(dir.users[id].Permissions).To(ConsistOf("approve_expenses", "view_reports"))
+
+			Expect(service.PatchGroup(ctx, "managers", &scim.PatchRequest{Operations: []scim.PatchOperation{
+				{Op: "remove", Path: `members[value eq "` + u.ID + `"]`},
+			}})).To(Succeed())
+			Expect(dir.users[id].Permissions).To(BeEmpty())
+		})
+
+		It("returns not found for unmapped groups", func() {
+			err := service.PatchGroup(ctx, "contractors", &scim.PatchRequest{})
+			Expect(err).To(MatchError(scim.ErrGroupNotFound))
+		})
+	})
+})
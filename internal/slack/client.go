@@ -0,0 +1,139 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the Slack Web API base URL.
+const DefaultAPIURL = "https://slack.com/api"
+
+// Client calls the Slack Web API with a bot token. The app needs the
+// chat:write, users:read and users:read.email scopes.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewClient(baseURL, botToken string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   botToken,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type usersInfoResponse struct {
+	apiResponse
+	User struct {
+		Profile struct {
+			Email string `json:"email"`
+		} `json:"profile"`
+	} `json:"user"`
+}
+
+// UserEmail returns the email on the Slack user's profile.
+func (c *Client) UserEmail(ctx context.Context, userID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/users.info?user="+url.QueryEscape(userID), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result usersInfoResponse
+	if err := c.do(req, &result); err != nil {
+		return "", fmt.Errorf("slack users.info failed: %w", err)
+	}
+	if !result.OK {
+		return "", fmt.Errorf("slack users.info failed: %s", result.Error)
+	}
+	if result.User.Profile.Email == "" {
+		return "", fmt.Errorf("slack user %s has no email on their profile", userID)
+	}
+	return result.User.Profile.Email, nil
+}
+
+// PostMessage posts msg to its channel.
+func (c *Client) PostMessage(ctx context.Context, msg Message) error {
+	req, err := c.jsonRequest(ctx, c.baseURL+"/chat.postMessage", msg)
+	if err != nil {
+		return err
+	}
+
+	var result apiResponse
+	if err := c.do(req, &result); err != nil {
+		return fmt.Errorf("slack chat.postMessage failed: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack chat.postMessage failed: %s", result.Error)
+	}
+	return nil
+}
+
+// Respond sends msg to the response_url of a command or interaction.
+// response_url replies are not token authenticated and answer "ok" rather
+// than JSON.
+func (c *Client) Respond(ctx context.Context, responseURL string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack response failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack response failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) jsonRequest(ctx context.Context, endpoint string, payload interface{}) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, result interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
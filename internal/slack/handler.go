@@ -0,0 +1,123 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+// maxBodyBytes bounds Slack request bodies, which must be read whole before
+// their signature can be checked.
+const maxBodyBytes = 64 << 10
+
+type ServiceAPI interface {
+	Command(ctx context.Context, cmd Command) (*Message, error)
+	Interact(ctx context.Context, in Interaction) error
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service  ServiceAPI
+	verifier *Verifier
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI, verifier *Verifier) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+		verifier:    verifier,
+	}
+}
+
+// Command godoc
+// @Summary      Slack slash command
+// @Description  Called by Slack for `/expenses pending`, which lists the expenses awaiting the caller's approval with approve and reject buttons. The caller is matched to a user by the email on their Slack profile. Requests must carry a valid X-Slack-Signature; no bearer token is needed.
+// @Tags         slack
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        X-Slack-Request-Timestamp  header    string  true  "Request timestamp"
+// @Param        X-Slack-Signature          header    string  true  "Request signature"
+// @Param        user_id                    formData  string  true  "Slack user ID"
+// @Param        text                       formData  string  false "Subcommand"
+// @Success      200  {object}  Message
+// @Failure      401  {object}  transport.AppErrorResponse
+// @Router       /slack/commands [post]
+func (h *Handler) Command(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.readForm(w, r)
+	if !ok {
+		return
+	}
+
+	msg, err := h.Service.Command(r.Context(), Command{
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		UserID:      form.Get("user_id"),
+		ResponseURL: form.Get("response_url"),
+	})
+	if err != nil {
+		// Slack shows non-200 responses as a generic failure; tell the
+		// user something more useful.
+		h.Log(r).Error("Command: service error", "error", err)
+		msg = ephemeral("Sorry, something went wrong. Please try again later.")
+	}
+
+	h.WriteJSON(w, http.StatusOK, msg)
+}
+
+// Interaction godoc
+// @Summary      Slack interaction
+// @Description  Called by Slack when an approve or reject button from `/expenses pending` is clicked. The decision is made with the clicker's current permissions and the list is refreshed through the interaction's response_url. Requests must carry a valid X-Slack-Signature; no bearer token is needed.
+// @Tags         slack
+// @Accept       x-www-form-urlencoded
+// @Param        X-Slack-Request-Timestamp  header    string  true  "Request timestamp"
+// @Param        X-Slack-Signature          header    string  true  "Request signature"
+// @Param        payload                    formData  string  true  "Interaction payload (JSON)"
+// @Success      200
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.AppErrorResponse
+// @Router       /slack/interactions [post]
+func (h *Handler) Interaction(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.readForm(w, r)
+	if !ok {
+		return
+	}
+
+	var in Interaction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &in); err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid interaction payload")
+		return
+	}
+
+	// The outcome goes back through response_url; Slack only needs the 200.
+	if err := h.Service.Interact(r.Context(), in); err != nil {
+		h.Log(r).Error("Interaction: service error", "error", err, "slack_user_id", in.User.ID)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// readForm reads the body, checks its Slack signature and parses it.
+func (h *Handler) readForm(w http.ResponseWriter, r *http.Request) (url.Values, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "failed to read request body")
+		return nil, false
+	}
+
+	if err := h.verifier.Verify(r.Header, body, time.Now()); err != nil {
+		h.Log(r).Warn("slack request refused", "error", err, "remote_addr", r.RemoteAddr)
+		h.HandleError(w, r, err)
+		return nil, false
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid form body")
+		return nil, false
+	}
+	return form, true
+}
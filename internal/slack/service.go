@@ -0,0 +1,279 @@
+package slack
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/user"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+const rejectReason = "Rejected from Slack"
+
+const usage = "Usage: `/expenses pending` lists the expenses waiting for your approval."
+
+// API is the slice of the Slack Web API the integration uses; Client
+// satisfies it.
+type API interface {
+	UserEmail(ctx context.Context, userID string) (string, error)
+	PostMessage(ctx context.Context, msg Message) error
+	Respond(ctx context.Context, responseURL string, msg Message) error
+}
+
+// UserLookup finds the app user behind a Slack account by the email on
+// their Slack profile.
+type UserLookup interface {
+	GetByEmail(email string) (*user.User, error)
+}
+
+type ExpenseLister interface {
+	GetExpensesForUser(ctx context.Context, userID int64, userPermissions []string, params *expense.ExpenseQueryParams) ([]*expense.Expense, error)
+}
+
+type ExpenseDecider interface {
+	ApproveExpense(ctx context.Context, expenseID, managerID int64, userPermissions []string) error
+	RejectExpense(ctx context.Context, expenseID, managerID int64, code, reason string, userPermissions []string) error
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+// Service answers the /expenses slash command, carries out button clicks
+// and posts payment failure alerts.
+type Service struct {
+	api          API
+	users        UserLookup
+	expenses     ExpenseLister
+	decider      ExpenseDecider
+	audit        AuditRecorder
+	alertChannel string
+	logger       *slog.Logger
+}
+
+// NewService posts payment failure alerts to alertChannel; an empty channel
+// disables them.
+func NewService(api API, users UserLookup, expenses ExpenseLister, decider ExpenseDecider, audit AuditRecorder, alertChannel string, logger *slog.Logger) *Service {
+	return &Service{
+		api:          api,
+		users:        users,
+		expenses:     expenses,
+		decider:      decider,
+		audit:        audit,
+		alertChannel: alertChannel,
+		logger:       logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Command answers a slash command with a message only the caller sees.
+// Problems the caller can fix, such as an unlinked account, are answered
+// rather than returned.
+func (s *Service) Command(ctx context.Context, cmd Command) (*Message, error) {
+	if sub := strings.TrimSpace(cmd.Text); sub != "pending" && sub != "" {
+		return ephemeral(usage), nil
+	}
+
+	approver, reply, err := s.resolve(ctx, cmd.UserID)
+	if err != nil || reply != nil {
+		return reply, err
+	}
+	ctx = tenant.NewContext(ctx, approver.TenantID)
+
+	return s.pending(ctx, approver, "")
+}
+
+// pending lists the expenses waiting for approver, oldest first, under
+// note when it is set.
+func (s *Service) pending(ctx context.Context, approver *user.User, note string) (*Message, error) {
+	params := &expense.ExpenseQueryParams{
+		Status:    expense.ExpenseStatusPendingApproval,
+		PerPage:   100,
+		SortBy:    "submittedAt",
+		SortOrder: "asc",
+	}
+	expenses, err := s.expenses.GetExpensesForUser(ctx, approver.ID, approver.Permissions, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending expenses: %w", err)
+	}
+
+	// Approvers do not act on their own expenses.
+	pending := make([]*expense.Expense, 0, len(expenses))
+	for _, e := range expenses {
+		if e.UserID != approver.ID {
+			pending = append(pending, e)
+		}
+	}
+
+	msg := pendingMessage(pending)
+	if note != "" {
+		msg.Text = note + " " + msg.Text
+		msg.Blocks = append([]Block{section(note)}, msg.Blocks...)
+	}
+	return msg, nil
+}
+
+func pendingMessage(pending []*expense.Expense) *Message {
+	if len(pending) == 0 {
+		msg := ephemeral("No expenses are waiting for your approval.")
+		msg.Blocks = []Block{section(msg.Text)}
+		return msg
+	}
+
+	msg := ephemeral(fmt.Sprintf("%d expense(s) awaiting your approval", len(pending)))
+	msg.Blocks = []Block{section(fmt.Sprintf("*%d expense(s) awaiting your approval*", len(pending)))}
+	if len(pending) > MaxPending {
+		msg.Blocks[0].Text.Text += fmt.Sprintf("\nShowing the oldest %d.", MaxPending)
+		pending = pending[:MaxPending]
+	}
+
+	for _, e := range pending {
+		id := strconv.FormatInt(e.ID, 10)
+		msg.Blocks = append(msg.Blocks,
+			section(fmt.Sprintf("*#%d* %s\n%d IDR · %s · submitted %s", e.ID, e.Description, e.AmountIDR, e.Category, e.SubmittedAt.Format("2006-01-02"))),
+			Block{Type: "actions", Elements: []Element{
+				button("Approve", ActionApprove, id, "primary"),
+				button("Reject", ActionReject, id, "danger"),
+			}},
+		)
+	}
+	return msg
+}
+
+// Interact carries out an approve or reject button click and replies
+// through the interaction's response_url, replacing the pending list with a
+// fresh one headed by the outcome. The clicker's current permissions apply;
+// refused decisions are reported the same way rather than returned.
+func (s *Service) Interact(ctx context.Context, in Interaction) error {
+	if in.Type != "block_actions" || len(in.Actions) == 0 {
+		return nil
+	}
+	action := in.Actions[0]
+	if action.ActionID != ActionApprove && action.ActionID != ActionReject {
+		return nil
+	}
+	expenseID, err := strconv.ParseInt(action.Value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expense id %q: %w", action.Value, err)
+	}
+
+	approver, reply, err := s.resolve(ctx, in.User.ID)
+	if err != nil {
+		return err
+	}
+	if reply != nil {
+		return s.api.Respond(ctx, in.ResponseURL, *reply)
+	}
+	ctx = tenant.NewContext(ctx, approver.TenantID)
+
+	auditAction := audit.ActionExpenseApprovedViaSlack
+	outcome := "approved"
+	if action.ActionID == ActionApprove {
+		err = s.decider.ApproveExpense(ctx, expenseID, approver.ID, approver.Permissions)
+	} else {
+		err = s.decider.RejectExpense(ctx, expenseID, approver.ID, expense.RejectionOther, rejectReason, approver.Permissions)
+		auditAction = audit.ActionExpenseRejectedViaSlack
+		outcome = "rejected"
+	}
+	if err != nil {
+		appErr, ok := errors.IsAppError(err)
+		if !ok {
+			return err
+		}
+		return s.respondPending(ctx, in.ResponseURL, approver, fmt.Sprintf(":no_entry: Expense #%d was not %s: %s", expenseID, outcome, appErr.Message))
+	}
+
+	s.record(ctx, &audit.Entry{
+		Action:       auditAction,
+		ResourceType: audit.ResourceExpense,
+		ResourceID:   strconv.FormatInt(expenseID, 10),
+		ActorID:      &approver.ID,
+		Metadata: map[string]interface{}{
+			"slack_user_id": in.User.ID,
+		},
+	})
+
+	s.log(ctx).Info("expense decided via slack",
+		"expense_id", expenseID,
+		"approver_id", approver.ID,
+		"action", outcome)
+
+	return s.respondPending(ctx, in.ResponseURL, approver, fmt.Sprintf(":white_check_mark: Expense #%d %s.", expenseID, outcome))
+}
+
+func (s *Service) respondPending(ctx context.Context, responseURL string, approver *user.User, note string) error {
+	msg, err := s.pending(ctx, approver, note)
+	if err != nil {
+		return err
+	}
+	msg.ReplaceOriginal = true
+	return s.api.Respond(ctx, responseURL, *msg)
+}
+
+// resolve maps a Slack user to an active app user with the same email.
+// When there is none it returns the reply explaining why instead.
+func (s *Service) resolve(ctx context.Context, slackUserID string) (*user.User, *Message, error) {
+	email, err := s.api.UserEmail(ctx, slackUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.users.GetByEmail(email)
+	if stderrors.Is(err, user.ErrNotFound) {
+		s.log(ctx).Warn("slack user not linked", "slack_user_id", slackUserID)
+		return nil, ephemeral("Your Slack account is not linked to an expense management account with the same email."), nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if !u.IsActive {
+		return nil, ephemeral("Your expense management account is inactive."), nil
+	}
+	return u, nil, nil
+}
+
+// record never fails the request; the audit service logs its own errors.
+func (s *Service) record(ctx context.Context, entry *audit.Entry) {
+	if err := s.audit.Record(entry); err != nil {
+		s.log(ctx).Error("failed to audit slack decision", "error", err, "action", entry.Action)
+	}
+}
+
+// HandlePaymentFailed posts the failure to the alert channel.
+func (s *Service) HandlePaymentFailed(ctx context.Context, event events.Event) error {
+	failed, ok := event.(*events.PaymentFailedEvent)
+	if !ok {
+		return fmt.Errorf("expected PaymentFailedEvent, got %T", event)
+	}
+
+	text := fmt.Sprintf(":warning: Payment for expense #%d failed after %d retries: %d IDR, external ID %s.\nReason: %s",
+		failed.ExpenseID, failed.RetryCount, failed.Amount, failed.ExternalID, failed.FailureReason)
+	if err := s.api.PostMessage(ctx, Message{Channel: s.alertChannel, Text: text}); err != nil {
+		return fmt.Errorf("failed to post payment failure alert: %w", err)
+	}
+	return nil
+}
+
+// RegisterEventHandlers subscribes to payment failures when an alert
+// channel is configured.
+func (s *Service) RegisterEventHandlers(eventBus *events.EventBus) {
+	if s.alertChannel == "" {
+		return
+	}
+	eventBus.Subscribe(events.EventTypePaymentFailed, s.HandlePaymentFailed)
+
+	s.logger.Info("slack event handlers registered",
+		"handlers", []string{events.EventTypePaymentFailed})
+}
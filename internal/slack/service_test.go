@@ -0,0 +1,199 @@
+package slack_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/core/events"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/slack"
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+type mockAPI struct {
+	emails    map[string]string
+	posted    []slack.Message
+	responses []slack.Message
+}
+
+func (m *mockAPI) UserEmail(_ context.Context, userID string) (string, error) {
+	return m.emails[userID], nil
+}
+
+func (m *mockAPI) PostMessage(_ context.Context, msg slack.Message) error {
+	m.posted = append(m.posted, msg)
+	return nil
+}
+
+func (m *mockAPI) Respond(_ context.Context, _ string, msg slack.Message) error {
+	m.responses = append(m.responses, msg)
+	return nil
+}
+
+type mockUsers struct {
+	users map[string]*user.User
+}
+
+func (m *mockUsers) GetByEmail(email string) (*user.User, error) {
+	if u, ok := m.users[email]; ok {
+		return u, nil
+	}
+	return nil, user.ErrNotFound
+}
+
+type mockExpenses struct {
+	pending   []*expense.Expense
+	decisions []string
+	err       error
+}
+
+func (m *mockExpenses) GetExpensesForUser(_ context.Context, _ int64, _ []string, _ *expense.ExpenseQueryParams) ([]*expense.Expense, error) {
+	return m.pending, nil
+}
+
+func (m *mockExpenses) ApproveExpense(_ context.Context, expenseID, _ int64, _ []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.decisions = append(m.decisions, "approve")
+	m.remove(expenseID)
+	return nil
+}
+
+func (m *mockExpenses) RejectExpense(_ context.Context, expenseID, _ int64, code, _ string, _ []string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.decisions = append(m.decisions, "reject:"+code)
+	m.remove(expenseID)
+	return nil
+}
+
+func (m *mockExpenses) remove(expenseID int64) {
+	for i, e := range m.pending {
+		if e.ID == expenseID {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+type mockAudit struct {
+	entries []*audit.Entry
+}
+
+func (m *mockAudit) Record(entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func click(actionID, value string) slack.Interaction {
+	in := slack.Interaction{
+		Type:        "block_actions",
+		Actions:     []slack.BlockAction{{ActionID: actionID, Value: value}},
+		ResponseURL: "https://hooks.slack.com/actions/1",
+	}
+	in.User.ID = "U1"
+	return in
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx      context.Context
+		api      *mockAPI
+		users    *mockUsers
+		expenses *mockExpenses
+		auditLog *mockAudit
+		svc      *slack.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = &mockAPI{emails: map[string]string{"U1": "manager@example.com", "U2": "stranger@example.com"}}
+		users = &mockUsers{users: map[string]*user.User{
+			"manager@example.com": {ID: 7, IsActive: true, Permissions: []string{"approve_expenses", "reject_expenses"}},
+		}}
+		expenses = &mockExpenses{pending: []*expense.Expense{
+			{ID: 41, UserID: 7, AmountIDR: 50000, Description: "Own lunch"},
+			{ID: 42, UserID: 3, AmountIDR: 1500000, Description: "Taxi", Category: "transport"},
+		}}
+		auditLog = &mockAudit{}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		svc = slack.NewService(api, users, expenses, expenses, auditLog, "C123", logger)
+	})
+
+	It("lists other users' pending expenses with approve and reject buttons", func() {
+		msg, err := svc.Command(ctx, slack.Command{Command: "/expenses", Text: "pending", UserID: "U1"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.ResponseType).To(Equal("ephemeral"))
+		Expect(msg.Blocks).To(HaveLen(3))
+		Expect(msg.Blocks[1].Text.Text).To(ContainSubstring("*#42* Taxi"))
+		Expect(msg.Blocks[2].Elements[0].ActionID).To(Equal(slack.ActionApprove))
+		Expect(msg.Blocks[2].Elements[1].Value).To(Equal("42"))
+	})
+
+	It("answers unlinked Slack users and unknown subcommands", func() {
+		msg, err := svc.Command(ctx, slack.Command{Text: "pending", UserID: "U2"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Text).To(ContainSubstring("not linked"))
+
+		msg, err = svc.Command(ctx, slack.Command{Text: "approve 42", UserID: "U1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Text).To(HavePrefix("Usage:"))
+	})
+
+	It("approves on click, audits the decision and refreshes the list", func() {
+		Expect(svc.Interact(ctx, click(slack.ActionApprove, "42"))).To(Succeed())
+
+		Expect(expenses.decisions).To(Equal([]string{"approve"}))
+		Expect(auditLog.entries).To(HaveLen(1))
+		Expect(auditLog.entries[0].Action).To(Equal(audit.ActionExpenseApprovedViaSlack))
+		Expect(*auditLog.entries[0].ActorID).To(Equal(int64(7)))
+		Expect(api.responses).To(HaveLen(1))
+		Expect(api.responses[0].ReplaceOriginal).To(BeTrue())
+		Expect(api.responses[0].Blocks[0].Text.Text).To(ContainSubstring("Expense #42 approved"))
+		Expect(api.responses[0].Blocks[1].Text.Text).To(Equal("No expenses are waiting for your approval."))
+	})
+
+	It("rejects with the other reason code", func() {
+		Expect(svc.Interact(ctx, click(slack.ActionReject, "42"))).To(Succeed())
+
+		Expect(expenses.decisions).To(Equal([]string{"reject:" + expense.RejectionOther}))
+		Expect(auditLog.entries[0].Action).To(Equal(audit.ActionExpenseRejectedViaSlack))
+	})
+
+	It("reports refused decisions to the clicker without auditing them", func() {
+		expenses.err = expense.ErrInvalidExpenseStatus
+
+		Expect(svc.Interact(ctx, click(slack.ActionApprove, "42"))).To(Succeed())
+
+		Expect(auditLog.entries).To(BeEmpty())
+		Expect(api.responses[0].Blocks[0].Text.Text).To(ContainSubstring("Expense #42 was not approved"))
+	})
+
+	It("tells an approver they cannot decide their own expense", func() {
+		expenses.err = expense.ErrOwnExpenseDecision
+
+		Expect(svc.Interact(ctx, click(slack.ActionApprove, "42"))).To(Succeed())
+
+		Expect(auditLog.entries).To(BeEmpty())
+		Expect(api.responses[0].Blocks[0].Text.Text).To(ContainSubstring(expense.ErrOwnExpenseDecision.Message))
+	})
+
+	It("posts payment failures to the alert channel", func() {
+		event := events.NewPaymentFailedEvent("9", 42, "EXP-42", 1500000, "insufficient balance", 2)
+
+		Expect(svc.HandlePaymentFailed(ctx, event)).To(Succeed())
+
+		Expect(api.posted).To(HaveLen(1))
+		Expect(api.posted[0].Channel).To(Equal("C123"))
+		Expect(api.posted[0].Text).To(ContainSubstring("expense #42"))
+		Expect(api.posted[0].Text).To(ContainSubstring("insufficient balance"))
+	})
+})
@@ -0,0 +1,142 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+)
+
+// Action IDs of the buttons under each pending expense; the button value is
+// the expense ID.
+const (
+	ActionApprove = "approve_expense"
+	ActionReject  = "reject_expense"
+)
+
+// MaxPending caps the expenses listed by /expenses pending; Slack allows 50
+// blocks per message and each expense takes two.
+const MaxPending = 20
+
+// maxSkew is how far a request timestamp may be from now, which bounds
+// replays of captured requests.
+const maxSkew = 5 * time.Minute
+
+const (
+	headerTimestamp = "X-Slack-Request-Timestamp"
+	headerSignature = "X-Slack-Signature"
+)
+
+var (
+	ErrInvalidSignature = errors.NewUnauthorizedError("Invalid Slack request signature", errors.ErrCodeInvalidToken)
+	ErrStaleRequest     = errors.NewUnauthorizedError("Slack request is too old", errors.ErrCodeTokenExpired)
+)
+
+// Verifier checks that requests were signed by Slack with the app's signing
+// secret: X-Slack-Signature is "v0=" + hex(HMAC-SHA256("v0:" + timestamp +
+// ":" + body)).
+type Verifier struct {
+	secret []byte
+}
+
+func NewVerifier(signingSecret string) *Verifier {
+	return &Verifier{secret: []byte(signingSecret)}
+}
+
+func (v *Verifier) Verify(header http.Header, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(header.Get(headerTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > maxSkew || skew < -maxSkew {
+		return ErrStaleRequest
+	}
+
+	if !hmac.Equal([]byte(header.Get(headerSignature)), []byte(v.Sign(ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the X-Slack-Signature of body sent at ts.
+func (v *Verifier) Sign(ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte("v0:" + strconv.FormatInt(ts, 10) + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Command is a slash command invocation.
+type Command struct {
+	Command     string
+	Text        string
+	UserID      string
+	ResponseURL string
+}
+
+// Interaction is a block_actions payload: a user clicked a button.
+type Interaction struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions     []BlockAction `json:"actions"`
+	ResponseURL string        `json:"response_url"`
+}
+
+type BlockAction struct {
+	ActionID string `json:"action_id"`
+	Value    string `json:"value"`
+}
+
+// Message is a Block Kit message, used for command responses, response_url
+// updates and channel posts.
+type Message struct {
+	Channel         string  `json:"channel,omitempty"`
+	ResponseType    string  `json:"response_type,omitempty"`
+	ReplaceOriginal bool    `json:"replace_original,omitempty"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks,omitempty"`
+}
+
+type Block struct {
+	Type     string    `json:"type"`
+	Text     *Text     `json:"text,omitempty"`
+	Elements []Element `json:"elements,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Element struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+// ephemeral is a command response only the invoking user sees.
+func ephemeral(text string) *Message {
+	return &Message{ResponseType: "ephemeral", Text: text}
+}
+
+func section(markdown string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: markdown}}
+}
+
+func button(label, actionID, value, style string) Element {
+	return Element{
+		Type:     "button",
+		Text:     &Text{Type: "plain_text", Text: label},
+		ActionID: actionID,
+		Value:    value,
+		Style:    style,
+	}
+}
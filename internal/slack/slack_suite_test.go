@@ -0,0 +1,13 @@
+package slack_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSlack(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Slack Suite")
+}
@@ -0,0 +1,56 @@
+package slack_test
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/slack"
+)
+
+var _ = Describe("Verifier", func() {
+	var (
+		verifier *slack.Verifier
+		now      time.Time
+		body     []byte
+	)
+
+	BeforeEach(func() {
+		verifier = slack.NewVerifier("8f742231b10e8888abcd99yyyzzz85a5")
+		now = time.Unix(1531420618, 0)
+		body = []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&command=%2Fexpenses&text=pending")
+	})
+
+	signed := func(ts int64, signature string) http.Header {
+		header := http.Header{}
+		header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(ts, 10))
+		header.Set("X-Slack-Signature", signature)
+		return header
+	}
+
+	It("accepts requests signed with the signing secret", func() {
+		header := signed(now.Unix(), verifier.Sign(now.Unix(), body))
+
+		Expect(verifier.Verify(header, body, now)).To(Succeed())
+	})
+
+	It("refuses altered bodies and other secrets", func() {
+		header := signed(now.Unix(), verifier.Sign(now.Unix(), body))
+		Expect(verifier.Verify(header, append(body, '1'), now)).To(Equal(slack.ErrInvalidSignature))
+
+		forged := signed(now.Unix(), slack.NewVerifier("other").Sign(now.Unix(), body))
+		Expect(verifier.Verify(forged, body, now)).To(Equal(slack.ErrInvalidSignature))
+
+		Expect(verifier.Verify(http.Header{}, body, now)).To(Equal(slack.ErrInvalidSignature))
+	})
+
+	It("refuses requests more than five minutes old", func() {
+		ts := now.Add(-6 * time.Minute).Unix()
+		header := signed(ts, verifier.Sign(ts, body))
+
+		Expect(verifier.Verify(header, body, now)).To(Equal(slack.ErrStaleRequest))
+	})
+})
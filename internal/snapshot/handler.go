@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	Trends(ctx context.Context, q TrendQuery, now time.Time) (*TrendsResponse, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// GetDailyStats godoc
+// @Summary      Daily spend and payment facts
+// @Description  Expenses submitted each day by category, submitter department and status, and payments created each day with their success rate, as written by the nightly daily_snapshot job. A day's figures stay as they were when it was snapshotted. Covers the admin's tenant. Requires admin.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  false  "First day, YYYY-MM-DD; 30 days before to by default"
+// @Param        to    query     string  false  "Last day, YYYY-MM-DD; yesterday by default"
+// @Success      200   {object}  TrendsResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      500   {object}  transport.ErrorResponse
+// @Router       /admin/stats/daily [get]
+func (h *Handler) GetDailyStats(w http.ResponseWriter, r *http.Request) {
+	var q TrendQuery
+	q.ParseFromRequest(r)
+
+	trends, err := h.Service.Trends(r.Context(), q, time.Now())
+	if err != nil {
+		h.Log(r).Error("GetDailyStats: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load daily stats")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, trends)
+}
@@ -0,0 +1,23 @@
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/scheduler"
+)
+
+const JobName = "daily_snapshot"
+
+// Job writes the facts of the days not snapshotted yet, up to yesterday, on
+// every run of schedule.
+func Job(service *Service, schedule scheduler.Schedule) scheduler.Job {
+	return scheduler.Job{
+		Name:     JobName,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			_, err := service.CatchUp(ctx, time.Now().UTC())
+			return err
+		},
+	}
+}
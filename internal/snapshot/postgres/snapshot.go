@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	snapshotDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/snapshot"
+	"github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/snapshot"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewSnapshotRepository(db *gorm.DB) snapshot.RepositoryAPI {
+	return &SnapshotRepository{db: db}
+}
+
+func (r *SnapshotRepository) LastDay(ctx context.Context) (*time.Time, error) {
+	var days []*snapshotDatamodel.Day
+	if err := r.db.WithContext(ctx).Order("fact_date DESC").Limit(1).Find(&days).Error; err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, nil
+	}
+	return &days[0].FactDate, nil
+}
+
+// WriteDay runs from the scheduler or backfill, unscoped by any tenant, and
+// covers every tenant. Dates are compared as YYYY-MM-DD so the session time
+// zone cannot shift them.
+func (r *SnapshotRepository) WriteDay(ctx context.Context, day, snapshotAt time.Time) (*snapshotDatamodel.Day, error) {
+	next := day.AddDate(0, 0, 1)
+	written := &snapshotDatamodel.Day{FactDate: day, SnapshotAt: snapshotAt}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var expenseFacts []*snapshotDatamodel.ExpenseFact
+		err := tx.Table("expenses").
+			Select(`expenses.tenant_id,
+				COALESCE(expenses.category, '') AS category,
+				COALESCE(users.department, '') AS department,
+				expenses.expense_status,
+				COUNT(*) AS expense_count,
+				COALESCE(SUM(expenses.amount_idr), 0) AS amount_idr`).
+			Joins("LEFT JOIN users ON users.id = expenses.user_id").
+			Where("expenses.submitted_at >= ? AND expenses.submitted_at < ?", day, next).
+			Group("expenses.tenant_id, COALESCE(expenses.category, ''), COALESCE(users.department, ''), expenses.expense_status").
+			Scan(&expenseFacts).Error
+		if err != nil {
+			return err
+		}
+
+		var paymentFacts []*snapshotDatamodel.PaymentFact
+		err = tx.Table("payments").
+			Select(`tenant_id,
+				COUNT(*) AS payment_count,
+				COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS succeeded,
+				COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS failed,
+				COALESCE(SUM(amount_idr), 0) AS amount_idr,
+				COALESCE(SUM(settled_amount), 0) AS settled_amount_idr`, payment.StatusSuccess, payment.StatusFailed).
+			Where("created_at >= ? AND created_at < ?", day, next).
+			Group("tenant_id").
+			Scan(&paymentFacts).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("fact_date >= ? AND fact_date < ?", day.Format(time.DateOnly), next.Format(time.DateOnly)).Delete(&snapshotDatamodel.ExpenseFact{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("fact_date >= ? AND fact_date < ?", day.Format(time.DateOnly), next.Format(time.DateOnly)).Delete(&snapshotDatamodel.PaymentFact{}).Error; err != nil {
+			return err
+		}
+		for _, f := range expenseFacts {
+			f.FactDate, f.SnapshotAt = day, snapshotAt
+		}
+		for _, f := range paymentFacts {
+			f.FactDate, f.SnapshotAt = day, snapshotAt
+		}
+		if len(expenseFacts) > 0 {
+			if err := tx.CreateInBatches(expenseFacts, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(paymentFacts) > 0 {
+			if err := tx.CreateInBatches(paymentFacts, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		written.ExpenseFacts, written.PaymentFacts = len(expenseFacts), len(paymentFacts)
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(written).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+func (r *SnapshotRepository) ExpenseFacts(ctx context.Context, from, to time.Time) ([]*snapshotDatamodel.ExpenseFact, error) {
+	var facts []*snapshotDatamodel.ExpenseFact
+	err := r.db.WithContext(ctx).
+		Where("fact_date >= ? AND fact_date < ?", from.Format(time.DateOnly), to.AddDate(0, 0, 1).Format(time.DateOnly)).
+		Order("fact_date, category, department, expense_status").
+		Find(&facts).Error
+	return facts, err
+}
+
+func (r *SnapshotRepository) PaymentFacts(ctx context.Context, from, to time.Time) ([]*snapshotDatamodel.PaymentFact, error) {
+	var facts []*snapshotDatamodel.PaymentFact
+	err := r.db.WithContext(ctx).
+		Where("fact_date >= ? AND fact_date < ?", from.Format(time.DateOnly), to.AddDate(0, 0, 1).Format(time.DateOnly)).
+		Order("fact_date").
+		Find(&facts).Error
+	return facts, err
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	paymentDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/payment"
+	snapshotDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/snapshot"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	"github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/snapshot"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestSnapshotRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SnapshotRepository Suite")
+}
+
+var _ = Describe("SnapshotRepository", func() {
+	var (
+		db   *gorm.DB
+		repo snapshot.RepositoryAPI
+		ctx  context.Context
+		day  time.Time
+	)
+
+	submit := func(tenantID, userID int64, category, status string, amount int64, at time.Time) {
+		exp := &expenseDatamodel.Expense{
+			TenantID:      tenantID,
+			UserID:        userID,
+			AmountIDR:     amount,
+			Description:   "Taxi",
+			Category:      category,
+			ExpenseStatus: status,
+			ExpenseDate:   at,
+			SubmittedAt:   at,
+		}
+		Expect(db.Create(exp).Error).To(Succeed())
+	}
+
+	pay := func(tenantID int64, status string, amount int64, at time.Time) {
+		p := &paymentDatamodel.Payment{
+			TenantID:   tenantID,
+			ExpenseID:  1,
+			ExternalID: "ext-" + at.Format(time.RFC3339Nano) + status,
+			AmountIDR:  amount,
+			Status:     status,
+			CreatedAt:  at,
+		}
+		if status == payment.StatusSuccess {
+			p.SettledAmount = amount
+		}
+		Expect(db.Create(p).Error).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		db, err = testdb.Open()
+		Expect(err).NotTo(HaveOccurred())
+		// The facts live in the reporting schema; SQLite reaches it as an
+		// attached database.
+		Expect(db.Exec("ATTACH DATABASE ':memory:' AS reporting").Error).To(Succeed())
+		Expect(db.AutoMigrate(
+			&expenseDatamodel.Expense{}, &userDatamodel.User{}, &paymentDatamodel.Payment{},
+			&snapshotDatamodel.ExpenseFact{}, &snapshotDatamodel.PaymentFact{}, &snapshotDatamodel.Day{},
+		)).To(Succeed())
+
+		Expect(db.Create(&userDatamodel.User{ID: 1, TenantID: 2, Email: "a@acme.test", Name: "A", PasswordHash: "x", Department: "Sales"}).Error).To(Succeed())
+		Expect(db.Create(&userDatamodel.User{ID: 2, TenantID: 3, Email: "b@globex.test", Name: "B", PasswordHash: "x", Department: "Ops"}).Error).To(Succeed())
+
+		repo = NewSnapshotRepository(db)
+		ctx = context.Background()
+		day = time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("totals the day's expenses and payments per tenant", func() {
+		submit(2, 1, "travel", "approved", 100000, day.Add(9*time.Hour))
+		submit(2, 1, "travel", "approved", 50000, day.Add(15*time.Hour))
+		submit(2, 1, "meals", "pending_approval", 20000, day.Add(10*time.Hour))
+		submit(3, 2, "travel", "approved", 70000, day.Add(11*time.Hour))
+		submit(2, 1, "travel", "approved", 999999, day.Add(-time.Hour))
+		pay(2, payment.StatusSuccess, 100000, day.Add(12*time.Hour))
+		pay(2, payment.StatusFailed, 50000, day.Add(13*time.Hour))
+		pay(2, payment.StatusPending, 30000, day.Add(14*time.Hour))
+
+		written, err := repo.WriteDay(ctx, day, day.AddDate(0, 0, 1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written.ExpenseFacts).To(Equal(3))
+		Expect(written.PaymentFacts).To(Equal(1))
+
+		acme := tenant.NewContext(ctx, 2)
+		expenses, err := repo.ExpenseFacts(acme, day, day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expenses).To(HaveLen(2))
+		Expect(expenses[1].Category).To(Equal("travel"))
+		Expect(expenses[1].Department).To(Equal("Sales"))
+		Expect(expenses[1].ExpenseCount).To(Equal(int64(2)))
+		Expect(expenses[1].AmountIDR).To(Equal(int64(150000)))
+
+		payments, err := repo.PaymentFacts(acme, day, day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payments).To(HaveLen(1))
+		Expect(payments[0].PaymentCount).To(Equal(int64(3)))
+		Expect(payments[0].Succeeded).To(Equal(int64(1)))
+		Expect(payments[0].Failed).To(Equal(int64(1)))
+		Expect(payments[0].SettledAmountIDR).To(Equal(int64(100000)))
+	})
+
+	It("replaces a day when it is snapshotted again", func() {
+		submit(2, 1, "travel", "pending_approval", 100000, day.Add(9*time.Hour))
+		_, err := repo.WriteDay(ctx, day, day.AddDate(0, 0, 1))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(db.Model(&expenseDatamodel.Expense{}).Where("1 = 1").Update("expense_status", "approved").Error).To(Succeed())
+		_, err = repo.WriteDay(ctx, day, day.AddDate(0, 0, 2))
+		Expect(err).NotTo(HaveOccurred())
+
+		expenses, err := repo.ExpenseFacts(tenant.NewContext(ctx, 2), day, day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expenses).To(HaveLen(1))
+		Expect(expenses[0].ExpenseStatus).To(Equal("approved"))
+
+		last, err := repo.LastDay(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(last.Format(time.DateOnly)).To(Equal("2025-11-02"))
+	})
+
+	It("reports no last day before the first snapshot", func() {
+		last, err := repo.LastDay(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(last).To(BeNil())
+	})
+})
@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	snapshotDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/snapshot"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// RepositoryAPI writes the daily facts of every tenant and reads those of
+// the tenant ctx is scoped to.
+type RepositoryAPI interface {
+	// LastDay returns the latest day snapshotted, nil before the first run.
+	LastDay(ctx context.Context) (*time.Time, error)
+	// WriteDay replaces the facts of day, for every tenant, with totals of
+	// the expenses and payments as they are at snapshotAt, and records the
+	// day as snapshotted.
+	WriteDay(ctx context.Context, day, snapshotAt time.Time) (*snapshotDatamodel.Day, error)
+	ExpenseFacts(ctx context.Context, from, to time.Time) ([]*snapshotDatamodel.ExpenseFact, error)
+	PaymentFacts(ctx context.Context, from, to time.Time) ([]*snapshotDatamodel.PaymentFact, error)
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// CatchUp snapshots every day after the last one snapshotted up to
+// yesterday, at most maxCatchUpDays of them; the first run only snapshots
+// yesterday. Today is never snapshotted, since it is not over.
+func (s *Service) CatchUp(ctx context.Context, now time.Time) (*RunResult, error) {
+	yesterday := day(now).AddDate(0, 0, -1)
+
+	last, err := s.repo.LastDay(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := yesterday
+	if last != nil {
+		from = day(*last).AddDate(0, 0, 1)
+		if earliest := yesterday.AddDate(0, 0, -(maxCatchUpDays - 1)); from.Before(earliest) {
+			s.log(ctx).Warn("daily snapshot fell behind, skipping days",
+				"last_day", last.Format(time.DateOnly),
+				"resuming_at", earliest.Format(time.DateOnly))
+			from = earliest
+		}
+	}
+	return s.Snapshot(ctx, from, yesterday, now)
+}
+
+// Snapshot (re)writes the facts of every day from from to to, both
+// included, as the data stands at now.
+func (s *Service) Snapshot(ctx context.Context, from, to, now time.Time) (*RunResult, error) {
+	result := &RunResult{Days: []string{}}
+	for d := day(from); !d.After(day(to)); d = d.AddDate(0, 0, 1) {
+		written, err := s.repo.WriteDay(ctx, d, now)
+		if err != nil {
+			return result, err
+		}
+		result.Days = append(result.Days, d.Format(time.DateOnly))
+		result.ExpenseFacts += written.ExpenseFacts
+		result.PaymentFacts += written.PaymentFacts
+
+		s.log(ctx).Info("daily snapshot written",
+			"day", d.Format(time.DateOnly),
+			"expense_facts", written.ExpenseFacts,
+			"payment_facts", written.PaymentFacts)
+	}
+	return result, nil
+}
+
+// Trends returns the daily facts of the tenant ctx is scoped to.
+func (s *Service) Trends(ctx context.Context, q TrendQuery, now time.Time) (*TrendsResponse, error) {
+	q.SetDefaults(now)
+
+	expenseFacts, err := s.repo.ExpenseFacts(ctx, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	paymentFacts, err := s.repo.PaymentFacts(ctx, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TrendsResponse{
+		From:     q.From.Format(time.DateOnly),
+		To:       q.To.Format(time.DateOnly),
+		Expenses: make([]*ExpenseFact, len(expenseFacts)),
+		Payments: make([]*PaymentFact, len(paymentFacts)),
+	}
+	for i, f := range expenseFacts {
+		resp.Expenses[i] = expenseFactFromDataModel(f)
+	}
+	for i, f := range paymentFacts {
+		resp.Payments[i] = paymentFactFromDataModel(f)
+	}
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package snapshot_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	snapshotDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/snapshot"
+	"github.com/frahmantamala/expense-management/internal/snapshot"
+)
+
+type mockRepository struct {
+	last         *time.Time
+	written      []string
+	paymentFacts []*snapshotDatamodel.PaymentFact
+	from, to     time.Time
+}
+
+func (m *mockRepository) LastDay(_ context.Context) (*time.Time, error) {
+	return m.last, nil
+}
+
+func (m *mockRepository) WriteDay(_ context.Context, day, snapshotAt time.Time) (*snapshotDatamodel.Day, error) {
+	m.written = append(m.written, day.Format(time.DateOnly))
+	return &snapshotDatamodel.Day{FactDate: day, ExpenseFacts: 2, PaymentFacts: 1, SnapshotAt: snapshotAt}, nil
+}
+
+func (m *mockRepository) ExpenseFacts(_ context.Context, from, to time.Time) ([]*snapshotDatamodel.ExpenseFact, error) {
+	m.from, m.to = from, to
+	return nil, nil
+}
+
+func (m *mockRepository) PaymentFacts(_ context.Context, from, to time.Time) ([]*snapshotDatamodel.PaymentFact, error) {
+	return m.paymentFacts, nil
+}
+
+var _ = Describe("Snapshot service", func() {
+	var (
+		ctx     context.Context
+		repo    *mockRepository
+		service *snapshot.Service
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &mockRepository{}
+		service = snapshot.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		now = time.Date(2025, 11, 3, 0, 30, 0, 0, time.UTC)
+	})
+
+	Describe("CatchUp", func() {
+		It("snapshots only yesterday on the first run", func() {
+			result, err := service.CatchUp(ctx, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.written).To(Equal([]string{"2025-11-02"}))
+			Expect(result.ExpenseFacts).To(Equal(2))
+			Expect(result.PaymentFacts).To(Equal(1))
+		})
+
+		It("fills the days missed since the last snapshot", func() {
+			last := time.Date(2025, 10, 30, 0, 0, 0, 0, time.UTC)
+			repo.last = &last
+
+			result, err := service.CatchUp(ctx, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Days).To(Equal([]string{"2025-10-31", "2025-11-01", "2025-11-02"}))
+		})
+
+		It("does nothing when yesterday is already snapshotted", func() {
+			last := time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC)
+			repo.last = &last
+
+			result, err := service.CatchUp(ctx, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Days).To(BeEmpty())
+			Expect(repo.written).To(BeEmpty())
+		})
+
+		It("catches up on at most 31 days", func() {
+			last := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			repo.last = &last
+
+			result, err := service.CatchUp(ctx, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Days).To(HaveLen(31))
+			Expect(result.Days[0]).To(Equal("2025-10-03"))
+		})
+	})
+
+	Describe("Trends", func() {
+		It("defaults to the 30 days up to yesterday", func() {
+			resp, err := service.Trends(ctx, snapshot.TrendQuery{}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.From).To(Equal("2025-10-04"))
+			Expect(resp.To).To(Equal("2025-11-02"))
+			Expect(repo.from.Format(time.DateOnly)).To(Equal("2025-10-04"))
+		})
+
+		It("computes the payment success rate over settled payments", func() {
+			repo.paymentFacts = []*snapshotDatamodel.PaymentFact{
+				{FactDate: time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC), PaymentCount: 5, Succeeded: 3, Failed: 1},
+			}
+
+			resp, err := service.Trends(ctx, snapshot.TrendQuery{}, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Payments).To(HaveLen(1))
+			Expect(resp.Payments[0].Date).To(Equal("2025-11-02"))
+			Expect(resp.Payments[0].SuccessRate).To(Equal(0.75))
+		})
+	})
+})
@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"net/http"
+	"time"
+
+	snapshotDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/snapshot"
+)
+
+const (
+	// DefaultTrendDays and MaxTrendDays bound the days a trend query covers.
+	DefaultTrendDays = 30
+	MaxTrendDays     = 366
+
+	// maxCatchUpDays caps how many missed days one run snapshots, so a job
+	// enabled on an old database does not scan its whole history at once;
+	// older days are filled with backfill daily-facts.
+	maxCatchUpDays = 31
+)
+
+// ExpenseFact totals the expenses submitted on Date that share a category,
+// submitter department and status, as they stood when the day was
+// snapshotted.
+type ExpenseFact struct {
+	Date       string `json:"date" example:"2025-11-02"`
+	Category   string `json:"category"`
+	Department string `json:"department"`
+	Status     string `json:"status"`
+	Count      int64  `json:"count"`
+	AmountIDR  int64  `json:"amount_idr"`
+}
+
+// PaymentFact totals the payments created on Date. SuccessRate is
+// Succeeded over payments that succeeded or failed, 0 when none has.
+type PaymentFact struct {
+	Date             string  `json:"date" example:"2025-11-02"`
+	Count            int64   `json:"count"`
+	Succeeded        int64   `json:"succeeded"`
+	Failed           int64   `json:"failed"`
+	SuccessRate      float64 `json:"success_rate"`
+	AmountIDR        int64   `json:"amount_idr"`
+	SettledAmountIDR int64   `json:"settled_amount_idr"`
+}
+
+// TrendsResponse is the daily facts of the caller's tenant for the days
+// from From to To, both included. Days not snapshotted yet are missing.
+type TrendsResponse struct {
+	From     string         `json:"from" example:"2025-10-04"`
+	To       string         `json:"to" example:"2025-11-02"`
+	Expenses []*ExpenseFact `json:"expenses"`
+	Payments []*PaymentFact `json:"payments"`
+}
+
+// TrendQuery selects the days to read. To defaults to yesterday and From to
+// DefaultTrendDays before To.
+type TrendQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseFromRequest reads from and to (YYYY-MM-DD), ignoring values that do
+// not parse.
+func (q *TrendQuery) ParseFromRequest(r *http.Request) {
+	query := r.URL.Query()
+	if from, err := time.Parse(time.DateOnly, query.Get("from")); err == nil {
+		q.From = from
+	}
+	if to, err := time.Parse(time.DateOnly, query.Get("to")); err == nil {
+		q.To = to
+	}
+}
+
+// SetDefaults fills in the range relative to now and clamps it to
+// MaxTrendDays.
+func (q *TrendQuery) SetDefaults(now time.Time) {
+	if q.To.IsZero() {
+		q.To = day(now).AddDate(0, 0, -1)
+	}
+	if q.From.IsZero() || q.From.After(q.To) {
+		q.From = q.To.AddDate(0, 0, -(DefaultTrendDays - 1))
+	}
+	if earliest := q.To.AddDate(0, 0, -(MaxTrendDays - 1)); q.From.Before(earliest) {
+		q.From = earliest
+	}
+}
+
+// RunResult reports the days a run snapshotted.
+type RunResult struct {
+	Days         []string `json:"days"`
+	ExpenseFacts int      `json:"expense_facts"`
+	PaymentFacts int      `json:"payment_facts"`
+}
+
+// day truncates t to midnight UTC.
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func expenseFactFromDataModel(f *snapshotDatamodel.ExpenseFact) *ExpenseFact {
+	return &ExpenseFact{
+		Date:       f.FactDate.Format(time.DateOnly),
+		Category:   f.Category,
+		Department: f.Department,
+		Status:     f.ExpenseStatus,
+		Count:      f.ExpenseCount,
+		AmountIDR:  f.AmountIDR,
+	}
+}
+
+func paymentFactFromDataModel(f *snapshotDatamodel.PaymentFact) *PaymentFact {
+	fact := &PaymentFact{
+		Date:             f.FactDate.Format(time.DateOnly),
+		Count:            f.PaymentCount,
+		Succeeded:        f.Succeeded,
+		Failed:           f.Failed,
+		AmountIDR:        f.AmountIDR,
+		SettledAmountIDR: f.SettledAmountIDR,
+	}
+	if settled := f.Succeeded + f.Failed; settled > 0 {
+		fact.SuccessRate = float64(f.Succeeded) / float64(settled)
+	}
+	return fact
+}
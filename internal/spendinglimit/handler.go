@@ -0,0 +1,99 @@
+package spendinglimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type ServiceAPI interface {
+	GrantOverride(ctx context.Context, userID, grantedBy int64, dto GrantOverrideDTO) (*Override, error)
+	ListOverrides(ctx context.Context, userID int64) ([]*Override, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// ListOverrides godoc
+// @Summary      List a user's spending limit overrides
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  OverridesResponse
+// @Failure      400  {object}  transport.ErrorResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/users/{id}/spending-limit-overrides [get]
+func (h *Handler) ListOverrides(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	overrides, err := h.Service.ListOverrides(r.Context(), userID)
+	if err != nil {
+		h.Log(r).Error("ListOverrides: service error", "error", err, "user_id", userID)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to list spending limit overrides")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, OverridesResponse{Overrides: overrides})
+}
+
+// GrantOverride godoc
+// @Summary      Raise a user's spending limit
+// @Description  Adds amount_idr to the user's daily or monthly limit for the period containing date. The granting admin and reason are recorded. Overrides for the same period add up.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int               true  "User ID"
+// @Param        body  body      GrantOverrideDTO  true  "Override"
+// @Success      201   {object}  Override
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      404   {object}  transport.AppErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /admin/users/{id}/spending-limit-overrides [post]
+func (h *Handler) GrantOverride(w http.ResponseWriter, r *http.Request) {
+	admin, ok := internal.UserFromContext(r.Context())
+	if !ok || admin == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.WriteError(w, r, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	var dto GrantOverrideDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	override, err := h.Service.GrantOverride(r.Context(), userID, admin.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusCreated, override)
+}
@@ -0,0 +1,115 @@
+package spendinglimit
+
+import (
+	"fmt"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	limitDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/spendinglimit"
+)
+
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodMonth Period = "month"
+)
+
+// Window returns the UTC [start, end) range of the period containing t.
+func (p Period) Window(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	switch p {
+	case PeriodMonth:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// Limits caps how much a single user may spend, by expense date, per day and
+// per calendar month. Zero disables a cap.
+type Limits struct {
+	DailyIDR   int64
+	MonthlyIDR int64
+}
+
+func (l Limits) For(p Period) int64 {
+	if p == PeriodMonth {
+		return l.MonthlyIDR
+	}
+	return l.DailyIDR
+}
+
+// Override raises a user's limit for one day or month by AmountIDR. It is the
+// record of who approved spending past the configured limit and why.
+type Override struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	Period      Period    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	AmountIDR   int64     `json:"amount_idr"`
+	Reason      string    `json:"reason"`
+	GrantedBy   int64     `json:"granted_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type GrantOverrideDTO struct {
+	Period Period `json:"period" validate:"required,oneof=day month"`
+	// Date is any day (YYYY-MM-DD) inside the period being raised.
+	Date      string `json:"date" validate:"required"`
+	AmountIDR int64  `json:"amount_idr" validate:"required,min=1"`
+	Reason    string `json:"reason" validate:"required,max=500"`
+}
+
+func (dto GrantOverrideDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	if _, err := time.Parse(time.DateOnly, dto.Date); err != nil {
+		return errors.NewValidationFieldError("date", "date must be formatted as YYYY-MM-DD", errors.ErrCodeInvalidDate)
+	}
+	return nil
+}
+
+type OverridesResponse struct {
+	Overrides []*Override `json:"overrides"`
+}
+
+// LimitExceeded is the detail attached to a LIMIT_EXCEEDED error.
+type LimitExceeded struct {
+	Period      Period    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	LimitIDR    int64     `json:"limit_idr"`
+	SpentIDR    int64     `json:"spent_idr"`
+	AmountIDR   int64     `json:"amount_idr"`
+}
+
+func newLimitExceededError(detail LimitExceeded) *errors.AppError {
+	message := fmt.Sprintf("%s spending limit of %d IDR exceeded", detail.Period.label(), detail.LimitIDR)
+	return errors.NewValidationError(message, errors.ErrCodeLimitExceeded).WithDetails(detail)
+}
+
+func (p Period) label() string {
+	if p == PeriodMonth {
+		return "monthly"
+	}
+	return "daily"
+}
+
+var ErrUserNotFound = errors.NewNotFoundError("User not found", errors.ErrCodeUserNotFound)
+
+func fromDataModel(o *limitDatamodel.Override) *Override {
+	return &Override{
+		ID:          o.ID,
+		UserID:      o.UserID,
+		Period:      Period(o.Period),
+		PeriodStart: o.PeriodStart,
+		AmountIDR:   o.AmountIDR,
+		Reason:      o.Reason,
+		GrantedBy:   o.GrantedBy,
+		CreatedAt:   o.CreatedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	limitDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/spendinglimit"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/spendinglimit"
+	"gorm.io/gorm"
+)
+
+type LimitRepository struct {
+	db *gorm.DB
+}
+
+func NewLimitRepository(db *gorm.DB) spendinglimit.RepositoryAPI {
+	return &LimitRepository{db: db}
+}
+
+// Dates are compared as YYYY-MM-DD so the DATE columns are not shifted by
+// the session time zone. An expense approved for less counts what it is
+// paid.
+func (r *LimitRepository) SumSpent(userID int64, from, to time.Time, statuses []string) (int64, error) {
+	var total int64
+	err := r.db.Model(&expenseDatamodel.Expense{}).
+		Select("COALESCE(SUM(COALESCE(approved_amount_idr, amount_idr)), 0)").
+		Where("user_id = ? AND expense_date >= ? AND expense_date < ?", userID, from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Where("expense_status IN ?", statuses).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *LimitRepository) SumOverrides(userID int64, period string, periodStart time.Time) (int64, error) {
+	var total int64
+	err := r.db.Model(&limitDatamodel.Override{}).
+		Select("COALESCE(SUM(amount_idr), 0)").
+		Where("user_id = ? AND period = ?", userID, period).
+		Where("period_start >= ? AND period_start < ?", periodStart.Format(time.DateOnly), periodStart.AddDate(0, 0, 1).Format(time.DateOnly)).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *LimitRepository) CreateOverride(override *limitDatamodel.Override) error {
+	return r.db.Create(override).Error
+}
+
+func (r *LimitRepository) ListOverrides(userID int64) ([]*limitDatamodel.Override, error) {
+	var overrides []*limitDatamodel.Override
+	err := r.db.Where("user_id = ?", userID).Order("period_start DESC, id DESC").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *LimitRepository) UserExists(userID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&userDatamodel.User{}).Where("id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
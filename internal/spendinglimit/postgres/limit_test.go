@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	expenseDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/expense"
+	limitDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/spendinglimit"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/core/testdb"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/spendinglimit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func TestLimitRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LimitRepository Suite")
+}
+
+var _ = Describe("LimitRepository", func() {
+	var (
+		db   *gorm.DB
+		repo spendinglimit.RepositoryAPI
+		day  time.Time
+	)
+
+	addExpense := func(userID, amount int64, status string, date time.Time) {
+		e := expenseDatamodel.Expense{UserID: userID, AmountIDR: amount, Description: "expense", ExpenseStatus: status, ExpenseDate: date}
+		Expect(db.Create(&e).Error).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = testdb.Open(&expenseDatamodel.Expense{}, &userDatamodel.User{}, &limitDatamodel.Override{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Create(&userDatamodel.User{ID: 7, Email: "dev@example.com"}).Error).NotTo(HaveOccurred())
+
+		repo = NewLimitRepository(db)
+		day = time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		sqlDB, err := db.DB()
+		Expect(err).NotTo(HaveOccurred())
+		sqlDB.Close()
+	})
+
+	It("sums the user's spend in the window for the given statuses", func() {
+		addExpense(7, 100000, expense.ExpenseStatusApproved, day)
+		addExpense(7, 200000, expense.ExpenseStatusPendingApproval, day)
+		addExpense(7, 400000, expense.ExpenseStatusRejected, day)
+		addExpense(7, 800000, expense.ExpenseStatusApproved, day.AddDate(0, 0, 1))
+		addExpense(8, 1600000, expense.ExpenseStatusApproved, day)
+
+		total, err := repo.SumSpent(7, day, day.AddDate(0, 0, 1), []string{expense.ExpenseStatusApproved, expense.ExpenseStatusPendingApproval})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(int64(300000)))
+
+		total, err = repo.SumSpent(7, day.AddDate(0, 1, 0), day.AddDate(0, 2, 0), []string{expense.ExpenseStatusApproved})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(BeZero())
+	})
+
+	It("sums overrides for one period", func() {
+		for _, o := range []*limitDatamodel.Override{
+			{UserID: 7, Period: "day", PeriodStart: day, AmountIDR: 100000, Reason: "a", GrantedBy: 1},
+			{UserID: 7, Period: "day", PeriodStart: day, AmountIDR: 50000, Reason: "b", GrantedBy: 1},
+			{UserID: 7, Period: "month", PeriodStart: day, AmountIDR: 900000, Reason: "c", GrantedBy: 1},
+			{UserID: 7, Period: "day", PeriodStart: day.AddDate(0, 0, 1), AmountIDR: 900000, Reason: "d", GrantedBy: 1},
+		} {
+			Expect(repo.CreateOverride(o)).To(Succeed())
+		}
+
+		total, err := repo.SumOverrides(7, "day", day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(int64(150000)))
+
+		overrides, err := repo.ListOverrides(7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides).To(HaveLen(4))
+		Expect(overrides[0].Reason).To(Equal("d"))
+	})
+
+	It("reports whether the user exists", func() {
+		Expect(repo.UserExists(7)).To(BeTrue())
+		Expect(repo.UserExists(8)).To(BeFalse())
+	})
+})
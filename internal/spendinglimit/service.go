@@ -0,0 +1,160 @@
+package spendinglimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	limitDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/spendinglimit"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+type RepositoryAPI interface {
+	// SumSpent totals the user's expenses dated in [from, to) whose status is
+	// one of statuses.
+	SumSpent(userID int64, from, to time.Time, statuses []string) (int64, error)
+	SumOverrides(userID int64, period string, periodStart time.Time) (int64, error)
+	CreateOverride(override *limitDatamodel.Override) error
+	ListOverrides(userID int64) ([]*limitDatamodel.Override, error)
+	UserExists(userID int64) (bool, error)
+}
+
+var (
+	// New expenses count everything still in play so a user cannot queue up
+	// more than the limit while approvals are pending.
+	filedStatuses = append([]string{expense.ExpenseStatusPendingApproval}, expense.ApprovedStatuses...)
+	// Approvals only count money that is already committed.
+	committedStatuses = expense.ApprovedStatuses
+)
+
+type Service struct {
+	repo   RepositoryAPI
+	limits Limits
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, limits Limits, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		limits: limits,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// CheckNewExpense reports a LIMIT_EXCEEDED error when filing amountIDR on
+// expenseDate would take the user past a daily or monthly limit.
+func (s *Service) CheckNewExpense(ctx context.Context, userID, amountIDR int64, expenseDate time.Time) error {
+	return s.check(ctx, userID, amountIDR, expenseDate, filedStatuses)
+}
+
+// CheckApproval reports a LIMIT_EXCEEDED error when approving the expense
+// would take its owner's approved spend past a limit.
+func (s *Service) CheckApproval(ctx context.Context, userID, amountIDR int64, expenseDate time.Time) error {
+	return s.check(ctx, userID, amountIDR, expenseDate, committedStatuses)
+}
+
+func (s *Service) check(ctx context.Context, userID, amountIDR int64, expenseDate time.Time, statuses []string) error {
+	for _, period := range []Period{PeriodDay, PeriodMonth} {
+		limit := s.limits.For(period)
+		if limit <= 0 {
+			continue
+		}
+
+		from, to := period.Window(expenseDate)
+		spent, err := s.repo.SumSpent(userID, from, to, statuses)
+		if err != nil {
+			return fmt.Errorf("failed to sum %s spending: %w", period, err)
+		}
+		if spent+amountIDR <= limit {
+			continue
+		}
+
+		extra, err := s.repo.SumOverrides(userID, string(period), from)
+		if err != nil {
+			return fmt.Errorf("failed to load %s limit overrides: %w", period, err)
+		}
+		if spent+amountIDR <= limit+extra {
+			s.log(ctx).Info("spending limit raised by override",
+				"user_id", userID,
+				"period", period,
+				"period_start", from,
+				"limit", limit,
+				"override", extra)
+			continue
+		}
+
+		s.log(ctx).Warn("spending limit exceeded",
+			"user_id", userID,
+			"period", period,
+			"period_start", from,
+			"limit", limit+extra,
+			"spent", spent,
+			"amount", amountIDR)
+		return newLimitExceededError(LimitExceeded{
+			Period:      period,
+			PeriodStart: from,
+			LimitIDR:    limit + extra,
+			SpentIDR:    spent,
+			AmountIDR:   amountIDR,
+		})
+	}
+	return nil
+}
+
+// GrantOverride raises userID's limit for the period containing dto.Date and
+// records grantedBy as the approver of the exception.
+func (s *Service) GrantOverride(ctx context.Context, userID, grantedBy int64, dto GrantOverrideDTO) (*Override, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	date, _ := time.Parse(time.DateOnly, dto.Date)
+	start, _ := dto.Period.Window(date)
+
+	row := &limitDatamodel.Override{
+		UserID:      userID,
+		Period:      string(dto.Period),
+		PeriodStart: start,
+		AmountIDR:   dto.AmountIDR,
+		Reason:      dto.Reason,
+		GrantedBy:   grantedBy,
+	}
+	if err := s.repo.CreateOverride(row); err != nil {
+		return nil, fmt.Errorf("failed to save limit override: %w", err)
+	}
+
+	s.log(ctx).Info("spending limit override granted",
+		"user_id", userID,
+		"granted_by", grantedBy,
+		"period", dto.Period,
+		"period_start", start,
+		"amount", dto.AmountIDR)
+	return fromDataModel(row), nil
+}
+
+func (s *Service) ListOverrides(ctx context.Context, userID int64) ([]*Override, error) {
+	rows, err := s.repo.ListOverrides(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit overrides: %w", err)
+	}
+
+	overrides := make([]*Override, len(rows))
+	for i, row := range rows {
+		overrides[i] = fromDataModel(row)
+	}
+	return overrides, nil
+}
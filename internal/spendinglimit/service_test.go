@@ -0,0 +1,215 @@
+package spendinglimit_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	limitDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/spendinglimit"
+	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/spendinglimit"
+)
+
+type spend struct {
+	date   time.Time
+	amount int64
+	status string
+}
+
+type mockRepository struct {
+	spends    []spend
+	overrides []*limitDatamodel.Override
+	users     map[int64]bool
+}
+
+func (m *mockRepository) SumSpent(_ int64, from, to time.Time, statuses []string) (int64, error) {
+	var total int64
+	for _, s := range m.spends {
+		if s.date.Before(from) || !s.date.Before(to) {
+			continue
+		}
+		for _, status := range statuses {
+			if s.status == status {
+				total += s.amount
+			}
+		}
+	}
+	return total, nil
+}
+
+func (m *mockRepository) SumOverrides(userID int64, period string, periodStart time.Time) (int64, error) {
+	var total int64
+	for _, o := range m.overrides {
+		if o.UserID == userID && o.Period == period && o.PeriodStart.Equal(periodStart) {
+			total += o.AmountIDR
+		}
+	}
+	return total, nil
+}
+
+func (m *mockRepository) CreateOverride(override *limitDatamodel.Override) error {
+	override.ID = int64(len(m.overrides) + 1)
+	m.overrides = append(m.overrides, override)
+	return nil
+}
+
+func (m *mockRepository) ListOverrides(userID int64) ([]*limitDatamodel.Override, error) {
+	var out []*limitDatamodel.Override
+	for _, o := range m.overrides {
+		if o.UserID == userID {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepository) UserExists(userID int64) (bool, error) {
+	return m.users[userID], nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx  context.Context
+		repo *mockRepository
+		svc  *spendinglimit.Service
+		day  time.Time
+	)
+
+	newService := func(limits spendinglimit.Limits) *spendinglimit.Service {
+		return spendinglimit.NewService(repo, limits, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	}
+
+	limitDetail := func(err error) spendinglimit.LimitExceeded {
+		appErr, ok := errors.IsAppError(err)
+		Expect(ok).To(BeTrue())
+		Expect(appErr.Code).To(Equal(errors.ErrCodeLimitExceeded))
+		return appErr.Details.(spendinglimit.LimitExceeded)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		day = time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+		repo = &mockRepository{users: map[int64]bool{7: true}}
+		svc = newService(spendinglimit.Limits{DailyIDR: 500000, MonthlyIDR: 2000000})
+	})
+
+	Describe("CheckNewExpense", func() {
+		It("allows spending up to the limit", func() {
+			repo.spends = []spend{{day, 300000, expense.ExpenseStatusPendingApproval}}
+
+			Expect(svc.CheckNewExpense(ctx, 7, 200000, day)).To(Succeed())
+		})
+
+		It("rejects spending past the daily limit, counting pending expenses", func() {
+			repo.spends = []spend{
+				{day, 300000, expense.ExpenseStatusPendingApproval},
+				{day, 900000, expense.ExpenseStatusRejected},
+				{day.AddDate(0, 0, 1), 400000, expense.ExpenseStatusApproved},
+			}
+
+			err := svc.CheckNewExpense(ctx, 7, 200001, day.Add(15*time.Hour))
+
+			Expect(limitDetail(err)).To(Equal(spendinglimit.LimitExceeded{
+				Period:      spendinglimit.PeriodDay,
+				PeriodStart: day,
+				LimitIDR:    500000,
+				SpentIDR:    300000,
+				AmountIDR:   200001,
+			}))
+		})
+
+		It("rejects spending past the monthly limit", func() {
+			repo.spends = []spend{
+				{day.AddDate(0, 0, -10), 1000000, expense.ExpenseStatusApproved},
+				{day.AddDate(0, 0, 5), 900000, expense.ExpenseStatusCompleted},
+				{day.AddDate(0, -1, 0), 900000, expense.ExpenseStatusCompleted},
+			}
+
+			detail := limitDetail(svc.CheckNewExpense(ctx, 7, 200000, day))
+
+			Expect(detail.Period).To(Equal(spendinglimit.PeriodMonth))
+			Expect(detail.PeriodStart).To(Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(detail.SpentIDR).To(Equal(int64(1900000)))
+		})
+
+		It("ignores disabled limits", func() {
+			svc = newService(spendinglimit.Limits{})
+
+			Expect(svc.CheckNewExpense(ctx, 7, 50000000, day)).To(Succeed())
+		})
+	})
+
+	Describe("CheckApproval", func() {
+		It("only counts approved and completed spend", func() {
+			repo.spends = []spend{
+				{day, 450000, expense.ExpenseStatusPendingApproval},
+				{day, 100000, expense.ExpenseStatusApproved},
+			}
+
+			Expect(svc.CheckApproval(ctx, 7, 400000, day)).To(Succeed())
+			Expect(limitDetail(svc.CheckApproval(ctx, 7, 400001, day)).SpentIDR).To(Equal(int64(100000)))
+		})
+	})
+
+	Describe("GrantOverride", func() {
+		It("raises the limit for the period and records who granted it", func() {
+			repo.spends = []spend{{day, 500000, expense.ExpenseStatusApproved}}
+			Expect(svc.CheckNewExpense(ctx, 7, 100000, day)).NotTo(Succeed())
+
+			override, err := svc.GrantOverride(ctx, 7, 1, spendinglimit.GrantOverrideDTO{
+				Period:    spendinglimit.PeriodDay,
+				Date:      "2026-03-14",
+				AmountIDR: 100000,
+				Reason:    "conference travel",
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(override.GrantedBy).To(Equal(int64(1)))
+			Expect(override.PeriodStart).To(Equal(day))
+			Expect(svc.CheckNewExpense(ctx, 7, 100000, day)).To(Succeed())
+			Expect(svc.CheckNewExpense(ctx, 7, 100000, day.AddDate(0, 0, 1))).To(Succeed())
+			Expect(svc.CheckNewExpense(ctx, 7, 100001, day)).NotTo(Succeed())
+		})
+
+		It("anchors monthly overrides to the first of the month", func() {
+			override, err := svc.GrantOverride(ctx, 7, 1, spendinglimit.GrantOverrideDTO{
+				Period:    spendinglimit.PeriodMonth,
+				Date:      "2026-03-14",
+				AmountIDR: 100000,
+				Reason:    "relocation",
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(override.PeriodStart).To(Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("rejects unknown users", func() {
+			_, err := svc.GrantOverride(ctx, 99, 1, spendinglimit.GrantOverrideDTO{
+				Period: spendinglimit.PeriodDay, Date: "2026-03-14", AmountIDR: 1, Reason: "x",
+			})
+
+			Expect(err).To(MatchError(spendinglimit.ErrUserNotFound))
+		})
+
+		It("validates the request", func() {
+			_, err := svc.GrantOverride(ctx, 7, 1, spendinglimit.GrantOverrideDTO{
+				Period: "week", Date: "14/03/2026", AmountIDR: 0,
+			})
+
+			Expect(err).To(HaveOccurred())
+			Expect(repo.overrides).To(BeEmpty())
+
+			_, err = svc.GrantOverride(ctx, 7, 1, spendinglimit.GrantOverrideDTO{
+				Period: spendinglimit.PeriodDay, Date: "14/03/2026", AmountIDR: 1, Reason: "x",
+			})
+
+			appErr, _ := errors.IsAppError(err)
+			Expect(appErr.Details.(errors.ValidationErrors).Errors[0].Field).To(Equal("date"))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package spendinglimit_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSpendingLimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Spending Limit Suite")
+}
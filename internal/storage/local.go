@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalPathPrefix is where the local driver's signed URLs are served.
+const LocalPathPrefix = "/files/"
+
+var ErrInvalidSignature = errors.New("storage: invalid or expired signature")
+
+// Local keeps objects on the local filesystem. It is meant for development
+// and single-node deployments; Handler serves its signed URLs.
+type Local struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+	now        func() time.Time
+}
+
+func NewLocal(root, baseURL, signingKey string) (*Local, error) {
+	if root == "" {
+		return nil, errors.New("local storage requires a root directory")
+	}
+	if signingKey == "" {
+		return nil, errors.New("local storage requires a signing key")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	return &Local{
+		root:       root,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		signingKey: []byte(signingKey),
+		now:        time.Now,
+	}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first so readers never see a partial object.
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, _ PutOptions) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) SignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(l.now().Add(expiry).Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", l.sign(key, expires))
+	return l.baseURL + LocalPathPrefix + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+// SignedUploadURL signs a PUT to Handler, which refuses bodies over
+// maxBytes.
+func (l *Local) SignedUploadURL(_ context.Context, key string, maxBytes int64, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(l.now().Add(expiry).Unix(), 10)
+	limit := strconv.FormatInt(maxBytes, 10)
+
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("max_bytes", limit)
+	q.Set("signature", l.sign(http.MethodPut+"\n"+key, expires+"\n"+limit))
+	return l.baseURL + LocalPathPrefix + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by SignedURL.
+func (l *Local) Verify(key, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l.now().Unix() > unix {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyUpload checks a signature produced by SignedUploadURL and returns
+// the size limit it carries.
+func (l *Local) verifyUpload(key string, q url.Values) (int64, error) {
+	expires, limit := q.Get("expires"), q.Get("max_bytes")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l.now().Unix() > unix {
+		return 0, ErrInvalidSignature
+	}
+	maxBytes, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(q.Get("signature")), []byte(l.sign(http.MethodPut+"\n"+key, expires+"\n"+limit))) {
+		return 0, ErrInvalidSignature
+	}
+	return maxBytes, nil
+}
+
+// Handler serves objects at LocalPathPrefix<key> for requests carrying a
+// valid signature, and accepts PUTs signed by SignedUploadURL.
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, LocalPathPrefix)
+		q := r.URL.Query()
+
+		if r.Method == http.MethodPut {
+			l.serveUpload(w, r, key, q)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := l.Verify(key, q.Get("expires"), q.Get("signature")); err != nil {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		p, err := l.path(key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(key)))
+		http.ServeContent(w, r, path.Base(key), info.ModTime(), f)
+	})
+}
+
+func (l *Local) serveUpload(w http.ResponseWriter, r *http.Request, key string, q url.Values) {
+	maxBytes, err := l.verifyUpload(key, q)
+	if err != nil {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if r.ContentLength > maxBytes {
+		http.Error(w, "request entity too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := l.Put(r.Context(), key, body, PutOptions{ContentType: r.Header.Get("Content-Type")}); err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "request entity too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, ErrInvalidKey):
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "upload failed", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
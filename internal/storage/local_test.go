@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Local", func() {
+	var (
+		local *Local
+		ctx   context.Context
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		local, err = NewLocal(GinkgoT().TempDir(), "http://files.test/", "signing-key")
+		Expect(err).NotTo(HaveOccurred())
+		now = time.Unix(1700000000, 0)
+		local.now = func() time.Time { return now }
+		ctx = context.Background()
+	})
+
+	It("round-trips objects and treats deleting a missing key as success", func() {
+		Expect(local.Put(ctx, "receipts/1/a.pdf", strings.NewReader("pdf"), PutOptions{})).To(Succeed())
+
+		rc, err := local.Get(ctx, "receipts/1/a.pdf")
+		Expect(err).NotTo(HaveOccurred())
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		Expect(string(body)).To(Equal("pdf"))
+
+		Expect(local.Delete(ctx, "receipts/1/a.pdf")).To(Succeed())
+		Expect(local.Delete(ctx, "receipts/1/a.pdf")).To(Succeed())
+		_, err = local.Get(ctx, "receipts/1/a.pdf")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("rejects keys that escape the root", func() {
+		for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b"} {
+			Expect(local.Put(ctx, key, strings.NewReader("x"), PutOptions{})).To(MatchError(ErrInvalidKey), key)
+		}
+	})
+
+	Describe("signed URLs", func() {
+		var signed *url.URL
+
+		BeforeEach(func() {
+			Expect(local.Put(ctx, "exports/report 1.csv", strings.NewReader("id\n1\n"), PutOptions{})).To(Succeed())
+			raw, err := local.SignedURL(ctx, "exports/report 1.csv", time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			signed, err = url.Parse(raw)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		serve := func(target string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			local.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			return rec
+		}
+
+		It("serves the object before expiry", func() {
+			Expect(signed.Host).To(Equal("files.test"))
+			rec := serve(signed.RequestURI())
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(Equal("id\n1\n"))
+		})
+
+		It("refuses expired links", func() {
+			now = now.Add(2 * time.Hour)
+			Expect(serve(signed.RequestURI()).Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("refuses a signature used for another key", func() {
+			Expect(local.Put(ctx, "exports/other.csv", strings.NewReader("x"), PutOptions{})).To(Succeed())
+			target := strings.Replace(signed.RequestURI(), "report%201.csv", "other.csv", 1)
+			Expect(serve(target).Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("signed upload URLs", func() {
+		put := func(raw, body string) int {
+			signed, err := url.Parse(raw)
+			Expect(err).NotTo(HaveOccurred())
+			rec := httptest.NewRecorder()
+			local.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, signed.RequestURI(), strings.NewReader(body)))
+			return rec.Code
+		}
+
+		It("stores the uploaded body", func() {
+			raw, err := local.SignedUploadURL(ctx, "receipts/1/a.jpg", 10, time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(put(raw, "photo")).To(Equal(http.StatusOK))
+			rc, err := local.Get(ctx, "receipts/1/a.jpg")
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := io.ReadAll(rc)
+			rc.Close()
+			Expect(string(body)).To(Equal("photo"))
+		})
+
+		It("refuses bodies over the signed limit, raised limits and expired links", func() {
+			raw, err := local.SignedUploadURL(ctx, "receipts/1/a.jpg", 4, time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(put(raw, "photo")).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(put(strings.Replace(raw, "max_bytes=4", "max_bytes=40", 1), "photo")).To(Equal(http.StatusForbidden))
+			now = now.Add(2 * time.Hour)
+			Expect(put(raw, "ph")).To(Equal(http.StatusForbidden))
+		})
+
+		It("does not accept download signatures for uploads", func() {
+			raw, err := local.SignedURL(ctx, "receipts/1/a.jpg", time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(put(raw+"&max_bytes=10", "photo")).To(Equal(http.StatusForbidden))
+		})
+	})
+})
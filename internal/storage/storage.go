@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+	DriverGCS   = "gcs"
+	DriverMinIO = "minio"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+type PutOptions struct {
+	ContentType string
+	// Size is the body length when known; object stores need it up front, so
+	// unknown-length bodies that cannot seek are buffered in memory.
+	Size int64
+}
+
+// Blob stores opaque objects under slash-separated keys such as
+// "receipts/42/invoice.pdf".
+type Blob interface {
+	Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error
+	// Get returns ErrNotFound for a missing key. Callers close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when the key does not exist.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads key without credentials until
+	// expiry has passed.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// SignedUploadURL returns a URL that accepts a PUT of key without
+	// credentials until expiry has passed. Stores that can enforce it refuse
+	// bodies over maxBytes; callers must still check what was uploaded.
+	SignedUploadURL(ctx context.Context, key string, maxBytes int64, expiry time.Duration) (string, error)
+}
+
+type Config struct {
+	Driver string
+
+	// Local driver: files live under Root and signed URLs point at
+	// BaseURL/files/<key>, signed with SigningKey.
+	Root       string
+	BaseURL    string
+	SigningKey string
+
+	// S3-compatible drivers. GCS is used through its XML API with HMAC keys.
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	UsePathStyle    bool
+}
+
+// NewBlob returns the store selected by cfg.Driver; local is the default.
+func NewBlob(cfg Config) (Blob, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocal(cfg.Root, cfg.BaseURL, cfg.SigningKey)
+	case DriverS3:
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+		}
+		return NewS3(cfg)
+	case DriverGCS:
+		if cfg.Region == "" {
+			cfg.Region = "auto"
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://storage.googleapis.com"
+		}
+		return NewS3(cfg)
+	case DriverMinIO:
+		if cfg.Endpoint == "" {
+			return nil, errors.New("minio storage requires an endpoint")
+		}
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+		cfg.UsePathStyle = true
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// ValidateKey rejects keys that could escape the bucket or root directory.
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type ServiceAPI interface {
+	Resolve(ctx context.Context, slug string) (*Tenant, error)
+}
+
+type Handler struct {
+	*transport.BaseHandler
+	Service ServiceAPI
+}
+
+func NewHandler(baseHandler *transport.BaseHandler, service ServiceAPI) *Handler {
+	return &Handler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// Middleware scopes the request to the tenant named by the X-Tenant header.
+// Requests without one stay unscoped until authentication scopes them to the
+// caller's tenant; unauthenticated endpoints that serve tenant data pick
+// their own fallback (see OrDefault).
+func (h *Handler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug := r.Header.Get(Header)
+		if slug == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t, err := h.Service.Resolve(r.Context(), slug)
+		if err != nil {
+			h.HandleError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), t.ID)))
+	})
+}
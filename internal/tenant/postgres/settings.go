@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	tenantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/tenant"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingsRepository struct {
+	db *gorm.DB
+}
+
+func NewSettingsRepository(db *gorm.DB) tenant.SettingsRepositoryAPI {
+	return &SettingsRepository{db: db}
+}
+
+func (r *SettingsRepository) List(ctx context.Context) ([]*tenantDatamodel.Setting, error) {
+	var settings []*tenantDatamodel.Setting
+	err := r.db.WithContext(ctx).Order("key ASC").Find(&settings).Error
+	return settings, err
+}
+
+func (r *SettingsRepository) Save(ctx context.Context, settings []*tenantDatamodel.Setting) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+	}).Create(&settings).Error
+}
+
+func (r *SettingsRepository) Delete(ctx context.Context, key string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("key = ?", key).Delete(&tenantDatamodel.Setting{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *SettingsRepository) PermissionExists(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("permissions").Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
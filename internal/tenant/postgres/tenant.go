@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	tenantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/tenant"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) tenant.RepositoryAPI {
+	return &TenantRepository{db: db}
+}
+
+func (r *TenantRepository) GetBySlug(slug string) (*tenantDatamodel.Tenant, error) {
+	var t tenantDatamodel.Tenant
+	err := r.db.Where("slug = ?", slug).First(&t).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TenantRepository) Create(t *tenantDatamodel.Tenant) error {
+	return r.db.Create(t).Error
+}
+
+// CopyCategories gives toID the categories of fromID, keeping the parent
+// links between them.
+func (r *TenantRepository) CopyCategories(fromID, toID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO expense_categories (tenant_id, name, description, is_active, created_at, updated_at)
+SELECT ?, name, description, is_active, now(), now() FROM expense_categories WHERE tenant_id = ?
+ON CONFLICT (tenant_id, name) DO NOTHING`, toID, fromID).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE expense_categories c SET parent_id = np.id
+FROM expense_categories oc
+JOIN expense_categories op ON op.id = oc.parent_id
+JOIN expense_categories np ON np.tenant_id = ? AND np.name = op.name
+WHERE c.tenant_id = ? AND oc.tenant_id = ? AND oc.name = c.name`, toID, toID, fromID).Error
+	})
+}
@@ -0,0 +1,96 @@
+package tenant
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const column = "tenant_id"
+
+var errForeignTenant = errors.New("row belongs to another tenant")
+
+// Scoping is a GORM plugin that confines statements run with a tenant
+// context (see NewContext) to that tenant: reads, updates and deletes of
+// models with a tenant_id column are filtered by it, and creates are stamped
+// with it. Raw SQL is not rewritten and must filter on its own.
+type Scoping struct{}
+
+func (Scoping) Name() string {
+	return "tenant:scoping"
+}
+
+func (Scoping) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tenant:assign", assign); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenant:scope", scope); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:scope", scope); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:scope", scope); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("tenant:scope", scope)
+}
+
+func tenantField(db *gorm.DB) (*schema.Field, int64, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, 0, false
+	}
+	id, ok := FromContext(db.Statement.Context)
+	if !ok {
+		return nil, 0, false
+	}
+	field := db.Statement.Schema.LookUpField(column)
+	if field == nil {
+		return nil, 0, false
+	}
+	return field, id, true
+}
+
+func scope(db *gorm.DB) {
+	field, id, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: id},
+	}})
+}
+
+func assign(db *gorm.DB) {
+	field, id, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	stamp := func(rv reflect.Value) {
+		current, zero := field.ValueOf(ctx, rv)
+		if zero {
+			db.AddError(field.Set(ctx, rv, id))
+			return
+		}
+		if current != id {
+			db.AddError(errForeignTenant)
+		}
+	}
+
+	switch rv := reflect.Indirect(db.Statement.ReflectValue); rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if elem := reflect.Indirect(rv.Index(i)); elem.Kind() == reflect.Struct {
+				stamp(elem)
+			}
+		}
+	case reflect.Struct:
+		stamp(rv)
+	}
+}
@@ -0,0 +1,97 @@
+package tenant_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+type scopedNote struct {
+	ID       int64  `gorm:"primaryKey"`
+	TenantID int64  `gorm:"column:tenant_id;not null;default:1"`
+	Body     string `gorm:"column:body"`
+}
+
+type globalNote struct {
+	ID   int64  `gorm:"primaryKey"`
+	Body string `gorm:"column:body"`
+}
+
+var _ = Describe("Scoping", func() {
+	var (
+		db          *gorm.DB
+		acme, other context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Use(tenant.Scoping{})).To(Succeed())
+		Expect(db.AutoMigrate(&scopedNote{}, &globalNote{})).To(Succeed())
+
+		acme = tenant.NewContext(context.Background(), 2)
+		other = tenant.NewContext(context.Background(), 3)
+		Expect(db.WithContext(acme).Create(&[]scopedNote{{Body: "a1"}, {Body: "a2"}}).Error).To(Succeed())
+		Expect(db.WithContext(other).Create(&scopedNote{Body: "o1"}).Error).To(Succeed())
+	})
+
+	It("stamps created rows with the tenant", func() {
+		var notes []scopedNote
+		Expect(db.Order("id").Find(&notes).Error).To(Succeed())
+		Expect(notes).To(HaveLen(3))
+		Expect(notes[0].TenantID).To(Equal(int64(2)))
+		Expect(notes[2].TenantID).To(Equal(int64(3)))
+	})
+
+	It("refuses to create rows for another tenant", func() {
+		err := db.WithContext(acme).Create(&scopedNote{TenantID: 3, Body: "sneaky"}).Error
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("only reads and counts the tenant's rows", func() {
+		var notes []scopedNote
+		Expect(db.WithContext(acme).Find(&notes).Error).To(Succeed())
+		Expect(notes).To(HaveLen(2))
+
+		var count int64
+		Expect(db.WithContext(other).Model(&scopedNote{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(1)))
+
+		var note scopedNote
+		err := db.WithContext(other).Where("body = ?", "a1").First(&note).Error
+		Expect(err).To(MatchError(gorm.ErrRecordNotFound))
+	})
+
+	It("only updates and deletes the tenant's rows", func() {
+		result := db.WithContext(other).Model(&scopedNote{}).Where("body = ?", "a1").Update("body", "changed")
+		Expect(result.Error).NotTo(HaveOccurred())
+		Expect(result.RowsAffected).To(BeZero())
+
+		result = db.WithContext(other).Where("body LIKE ?", "%1").Delete(&scopedNote{})
+		Expect(result.Error).NotTo(HaveOccurred())
+		Expect(result.RowsAffected).To(Equal(int64(1)))
+
+		var count int64
+		Expect(db.Model(&scopedNote{}).Where("body = ?", "a1").Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(1)))
+	})
+
+	It("leaves unscoped contexts and models without a tenant alone", func() {
+		Expect(db.WithContext(acme).Create(&globalNote{Body: "shared"}).Error).To(Succeed())
+
+		var globals []globalNote
+		Expect(db.WithContext(other).Find(&globals).Error).To(Succeed())
+		Expect(globals).To(HaveLen(1))
+
+		var count int64
+		Expect(db.WithContext(context.Background()).Model(&scopedNote{}).Count(&count).Error).To(Succeed())
+		Expect(count).To(Equal(int64(3)))
+	})
+})
@@ -0,0 +1,506 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+	tenantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// Keys of the settings a tenant can override, as stored in tenant_settings.
+const (
+	SettingAutoApprovalThreshold = "auto_approval_threshold_idr"
+	SettingCurrency              = "currency"
+	SettingApprovalChain         = "approval_chain"
+	SettingNotificationChannels  = "notification_channels"
+	SettingReceiptRequiredAbove  = "receipt_required_above_idr"
+	SettingMaxPendingExpenses    = "max_pending_expenses"
+	SettingMaxPendingByDept      = "max_pending_expenses_by_department"
+	SettingMinExpenseAmount      = "min_expense_amount_idr"
+	SettingMaxExpenseAmount      = "max_expense_amount_idr"
+	SettingAmountLimitsByCat     = "expense_amount_limits_by_category"
+)
+
+const ChannelEmail = "email"
+
+// Channels lists the notification channels a tenant can enable.
+var Channels = []string{ChannelEmail}
+
+// Settings is a tenant's effective configuration: its overrides on top of
+// the server defaults.
+type Settings struct {
+	// AutoApprovalThresholdIDR is the amount below which expenses are
+	// approved on submission. Zero disables auto-approval.
+	AutoApprovalThresholdIDR int64  `json:"auto_approval_threshold_idr"`
+	Currency                 string `json:"currency"`
+	// ApprovalChain lists the permissions allowed to decide expenses in
+	// categories without a routing rule. Empty lets any approver decide.
+	ApprovalChain        []string `json:"approval_chain"`
+	NotificationChannels []string `json:"notification_channels"`
+	// ReceiptRequiredAboveIDR is the amount above which expenses must carry
+	// a receipt. Zero leaves receipts optional.
+	ReceiptRequiredAboveIDR int64 `json:"receipt_required_above_idr"`
+	// MaxPendingExpenses caps how many expenses each user can have waiting
+	// for approval at once. Zero leaves it uncapped.
+	MaxPendingExpenses int64 `json:"max_pending_expenses"`
+	// MaxPendingByDepartment replaces MaxPendingExpenses for users of the
+	// named departments; zero uncaps a department.
+	MaxPendingByDepartment map[string]int64 `json:"max_pending_expenses_by_department"`
+	// MinExpenseAmountIDR and MaxExpenseAmountIDR bound the amount of each
+	// new claim.
+	MinExpenseAmountIDR int64 `json:"min_expense_amount_idr"`
+	MaxExpenseAmountIDR int64 `json:"max_expense_amount_idr"`
+	// AmountLimitsByCategory replaces those bounds for claims in the named
+	// categories.
+	AmountLimitsByCategory map[string]AmountLimits `json:"expense_amount_limits_by_category"`
+}
+
+// AmountLimits bounds the claims of one category; a zero bound falls back
+// to the tenant-wide one.
+type AmountLimits struct {
+	MinIDR int64 `json:"min_idr"`
+	MaxIDR int64 `json:"max_idr"`
+}
+
+type SettingsResponse struct {
+	Settings
+	// Overridden lists the keys set for this tenant; the others are defaults.
+	Overridden []string `json:"overridden"`
+}
+
+// UpdateSettingsDTO overrides the settings it carries and leaves the
+// others as they are.
+type UpdateSettingsDTO struct {
+	AutoApprovalThresholdIDR *int64   `json:"auto_approval_threshold_idr,omitempty"`
+	Currency                 *string  `json:"currency,omitempty"`
+	ApprovalChain            []string `json:"approval_chain,omitempty"`
+	NotificationChannels     []string `json:"notification_channels,omitempty"`
+	ReceiptRequiredAboveIDR  *int64   `json:"receipt_required_above_idr,omitempty"`
+	MaxPendingExpenses       *int64   `json:"max_pending_expenses,omitempty"`
+	// MaxPendingByDepartment replaces the whole department map.
+	MaxPendingByDepartment map[string]int64 `json:"max_pending_expenses_by_department,omitempty"`
+	MinExpenseAmountIDR    *int64           `json:"min_expense_amount_idr,omitempty"`
+	MaxExpenseAmountIDR    *int64           `json:"max_expense_amount_idr,omitempty"`
+	// AmountLimitsByCategory replaces the whole category map.
+	AmountLimitsByCategory map[string]AmountLimits `json:"expense_amount_limits_by_category,omitempty"`
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func (dto UpdateSettingsDTO) Validate() error {
+	validator := validation.NewValidator()
+	if dto.AutoApprovalThresholdIDR != nil {
+		validator.Field(SettingAutoApprovalThreshold, *dto.AutoApprovalThresholdIDR).
+			Currency("IDR").
+			MinInt(0, errors.ErrCodeInvalidAmount)
+	}
+	if dto.Currency != nil {
+		validator.Field(SettingCurrency, *dto.Currency).
+			Required().
+			Custom(validCurrency)
+	}
+	if dto.ReceiptRequiredAboveIDR != nil {
+		validator.Field(SettingReceiptRequiredAbove, *dto.ReceiptRequiredAboveIDR).
+			Currency("IDR").
+			MinInt(0, errors.ErrCodeInvalidAmount)
+	}
+	if dto.MaxPendingExpenses != nil {
+		validator.Field(SettingMaxPendingExpenses, *dto.MaxPendingExpenses).
+			MinInt(0, errors.ErrCodeValidationFailed)
+	}
+	for department, limit := range dto.MaxPendingByDepartment {
+		validator.Field(SettingMaxPendingByDept, department).
+			Required().
+			MaxLength(255)
+		validator.Field(SettingMaxPendingByDept, limit).
+			MinInt(0, errors.ErrCodeValidationFailed)
+	}
+	if dto.MinExpenseAmountIDR != nil {
+		validator.Field(SettingMinExpenseAmount, *dto.MinExpenseAmountIDR).
+			Currency("IDR").
+			MinInt(1, errors.ErrCodeInvalidAmount)
+	}
+	if dto.MaxExpenseAmountIDR != nil {
+		validator.Field(SettingMaxExpenseAmount, *dto.MaxExpenseAmountIDR).
+			Currency("IDR").
+			MinInt(1, errors.ErrCodeInvalidAmount)
+	}
+	for category, limits := range dto.AmountLimitsByCategory {
+		validator.Field(SettingAmountLimitsByCat, category).
+			Required().
+			MaxLength(255)
+		validator.Field(SettingAmountLimitsByCat, limits.MinIDR).
+			Currency("IDR").
+			MinInt(0, errors.ErrCodeInvalidAmount)
+		validator.Field(SettingAmountLimitsByCat, limits.MaxIDR).
+			Currency("IDR").
+			MinInt(0, errors.ErrCodeInvalidAmount)
+	}
+	for _, permission := range dto.ApprovalChain {
+		validator.Field(SettingApprovalChain, permission).
+			Required().
+			MaxLength(255)
+	}
+	for _, channel := range dto.NotificationChannels {
+		validator.Field(SettingNotificationChannels, channel).
+			Required().
+			OneOf(Channels...)
+	}
+
+	if appErr := validator.Validate(); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+func validCurrency(value interface{}) *errors.AppError {
+	code, _ := value.(string)
+	if code != "" && !currencyPattern.MatchString(code) {
+		return errors.NewLocalizedFieldError(SettingCurrency, "validation.currency", nil, errors.ErrCodeValidationFailed)
+	}
+	return nil
+}
+
+var (
+	ErrSettingNotFound     = errors.NewNotFoundError("Tenant setting is not overridden", errors.ErrCodeTenantSettingNotFound)
+	ErrUnknownPermission   = errors.NewLocalizedFieldError(SettingApprovalChain, "validation.permission", nil, errors.ErrCodeValidationFailed)
+	ErrAmountLimitsCrossed = errors.NewValidationFieldError(SettingMaxExpenseAmount, "the maximum claim amount must not be below the minimum", errors.ErrCodeValidationFailed)
+)
+
+// SettingsRepositoryAPI reads and writes the overrides of the tenant ctx is
+// scoped to.
+type SettingsRepositoryAPI interface {
+	List(ctx context.Context) ([]*tenantDatamodel.Setting, error)
+	// Save upserts settings in one statement.
+	Save(ctx context.Context, settings []*tenantDatamodel.Setting) error
+	Delete(ctx context.Context, key string) (bool, error)
+	PermissionExists(ctx context.Context, name string) (bool, error)
+}
+
+type cachedSettings struct {
+	settings *SettingsResponse
+	expires  time.Time
+}
+
+// SettingsService resolves per-tenant settings and caches them for ttl.
+// Writes drop the tenant's cache entry on this instance only; other
+// instances pick the change up once their entry expires.
+type SettingsService struct {
+	repo     SettingsRepositoryAPI
+	defaults Settings
+	ttl      time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[int64]cachedSettings
+}
+
+func NewSettingsService(repo SettingsRepositoryAPI, defaults Settings, ttl time.Duration, logger *slog.Logger) *SettingsService {
+	return &SettingsService{
+		repo:     repo,
+		defaults: defaults,
+		ttl:      ttl,
+		logger:   logger,
+		now:      time.Now,
+		cache:    make(map[int64]cachedSettings),
+	}
+}
+
+func (s *SettingsService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Get returns the settings of the tenant ctx is scoped to, or of the
+// default tenant when it is not scoped.
+func (s *SettingsService) Get(ctx context.Context) (*SettingsResponse, error) {
+	ctx, tenantID := OrDefault(ctx)
+
+	s.mu.Lock()
+	entry, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && s.now().Before(entry.expires) {
+		return entry.settings, nil
+	}
+
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	settings := s.resolve(ctx, rows)
+
+	s.mu.Lock()
+	s.cache[tenantID] = cachedSettings{settings: settings, expires: s.now().Add(s.ttl)}
+	s.mu.Unlock()
+	return settings, nil
+}
+
+// effective is Get for callers that cannot fail: when the overrides cannot
+// be loaded the defaults apply.
+func (s *SettingsService) effective(ctx context.Context) Settings {
+	settings, err := s.Get(ctx)
+	if err != nil {
+		s.log(ctx).Error("falling back to default tenant settings", "error", err)
+		return s.defaults
+	}
+	return settings.Settings
+}
+
+func (s *SettingsService) AutoApprovalThreshold(ctx context.Context) int64 {
+	return s.effective(ctx).AutoApprovalThresholdIDR
+}
+
+func (s *SettingsService) Currency(ctx context.Context) string {
+	return s.effective(ctx).Currency
+}
+
+func (s *SettingsService) ApprovalChain(ctx context.Context) []string {
+	return slices.Clone(s.effective(ctx).ApprovalChain)
+}
+
+func (s *SettingsService) NotificationChannels(ctx context.Context) []string {
+	return slices.Clone(s.effective(ctx).NotificationChannels)
+}
+
+func (s *SettingsService) ReceiptRequiredAbove(ctx context.Context) int64 {
+	return s.effective(ctx).ReceiptRequiredAboveIDR
+}
+
+// PendingExpenseLimit returns how many expenses a user of department may
+// have waiting for approval at once; zero means no cap.
+func (s *SettingsService) PendingExpenseLimit(ctx context.Context, department string) int64 {
+	settings := s.effective(ctx)
+	if limit, ok := settings.MaxPendingByDepartment[department]; ok {
+		return limit
+	}
+	return settings.MaxPendingExpenses
+}
+
+// ExpenseAmountLimits returns the bounds of a new claim in category, in
+// rupiah.
+func (s *SettingsService) ExpenseAmountLimits(ctx context.Context, category string) (min, max int64) {
+	return s.effective(ctx).amountLimits(category)
+}
+
+func (s Settings) amountLimits(category string) (min, max int64) {
+	min, max = s.MinExpenseAmountIDR, s.MaxExpenseAmountIDR
+	if limits, ok := s.AmountLimitsByCategory[category]; ok {
+		if limits.MinIDR > 0 {
+			min = limits.MinIDR
+		}
+		if limits.MaxIDR > 0 {
+			max = limits.MaxIDR
+		}
+	}
+	return min, max
+}
+
+// NotifiesBy reports whether the tenant has channel enabled.
+func (s *SettingsService) NotifiesBy(ctx context.Context, channel string) bool {
+	return slices.Contains(s.effective(ctx).NotificationChannels, channel)
+}
+
+// Update overrides the settings in dto for the tenant ctx is scoped to.
+func (s *SettingsService) Update(ctx context.Context, updatedBy int64, dto UpdateSettingsDTO) (*SettingsResponse, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, tenantID := OrDefault(ctx)
+
+	for _, permission := range dto.ApprovalChain {
+		exists, err := s.repo.PermissionExists(ctx, permission)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check permission: %w", err)
+		}
+		if !exists {
+			return nil, ErrUnknownPermission
+		}
+	}
+
+	if dto.MinExpenseAmountIDR != nil || dto.MaxExpenseAmountIDR != nil || dto.AmountLimitsByCategory != nil {
+		if err := s.checkAmountLimits(ctx, dto); err != nil {
+			return nil, err
+		}
+	}
+
+	values := map[string]interface{}{}
+	if dto.AutoApprovalThresholdIDR != nil {
+		values[SettingAutoApprovalThreshold] = *dto.AutoApprovalThresholdIDR
+	}
+	if dto.Currency != nil {
+		values[SettingCurrency] = *dto.Currency
+	}
+	if dto.ApprovalChain != nil {
+		values[SettingApprovalChain] = dto.ApprovalChain
+	}
+	if dto.NotificationChannels != nil {
+		values[SettingNotificationChannels] = dto.NotificationChannels
+	}
+	if dto.ReceiptRequiredAboveIDR != nil {
+		values[SettingReceiptRequiredAbove] = *dto.ReceiptRequiredAboveIDR
+	}
+	if dto.MaxPendingExpenses != nil {
+		values[SettingMaxPendingExpenses] = *dto.MaxPendingExpenses
+	}
+	if dto.MaxPendingByDepartment != nil {
+		values[SettingMaxPendingByDept] = dto.MaxPendingByDepartment
+	}
+	if dto.MinExpenseAmountIDR != nil {
+		values[SettingMinExpenseAmount] = *dto.MinExpenseAmountIDR
+	}
+	if dto.MaxExpenseAmountIDR != nil {
+		values[SettingMaxExpenseAmount] = *dto.MaxExpenseAmountIDR
+	}
+	if dto.AmountLimitsByCategory != nil {
+		values[SettingAmountLimitsByCat] = dto.AmountLimitsByCategory
+	}
+	if len(values) == 0 {
+		return s.Get(ctx)
+	}
+
+	rows := make([]*tenantDatamodel.Setting, 0, len(values))
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode setting %s: %w", key, err)
+		}
+		rows = append(rows, &tenantDatamodel.Setting{Key: key, Value: raw, UpdatedBy: &updatedBy})
+		keys = append(keys, key)
+	}
+	if err := s.repo.Save(ctx, rows); err != nil {
+		return nil, fmt.Errorf("failed to save tenant settings: %w", err)
+	}
+	s.invalidate(tenantID)
+
+	sort.Strings(keys)
+	s.log(ctx).Info("tenant settings updated", "tenant_id", tenantID, "keys", keys, "updated_by", updatedBy)
+	return s.Get(ctx)
+}
+
+// checkAmountLimits refuses an update that would leave a maximum claim
+// amount below its minimum, tenant-wide or for any category.
+func (s *SettingsService) checkAmountLimits(ctx context.Context, dto UpdateSettingsDTO) error {
+	current, err := s.Get(ctx)
+	if err != nil {
+		return err
+	}
+	next := current.Settings
+	if dto.MinExpenseAmountIDR != nil {
+		next.MinExpenseAmountIDR = *dto.MinExpenseAmountIDR
+	}
+	if dto.MaxExpenseAmountIDR != nil {
+		next.MaxExpenseAmountIDR = *dto.MaxExpenseAmountIDR
+	}
+	if dto.AmountLimitsByCategory != nil {
+		next.AmountLimitsByCategory = dto.AmountLimitsByCategory
+	}
+
+	if next.MaxExpenseAmountIDR < next.MinExpenseAmountIDR {
+		return ErrAmountLimitsCrossed
+	}
+	for category := range next.AmountLimitsByCategory {
+		if min, max := next.amountLimits(category); max < min {
+			return errors.NewValidationFieldError(SettingAmountLimitsByCat,
+				fmt.Sprintf("the maximum claim amount of %s must not be below its minimum", category), errors.ErrCodeValidationFailed)
+		}
+	}
+	return nil
+}
+
+// Reset drops the tenant's override of key so the default applies again.
+func (s *SettingsService) Reset(ctx context.Context, key string) error {
+	ctx, tenantID := OrDefault(ctx)
+
+	deleted, err := s.repo.Delete(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to reset tenant setting: %w", err)
+	}
+	if !deleted {
+		return ErrSettingNotFound
+	}
+	s.invalidate(tenantID)
+
+	s.log(ctx).Info("tenant setting reset", "tenant_id", tenantID, "key", key)
+	return nil
+}
+
+func (s *SettingsService) invalidate(tenantID int64) {
+	s.mu.Lock()
+	delete(s.cache, tenantID)
+	s.mu.Unlock()
+}
+
+// resolve applies rows on top of the defaults. Rows with unknown keys or
+// values that no longer decode are skipped, so a bad row falls back to the
+// default instead of breaking every request of the tenant.
+func (s *SettingsService) resolve(ctx context.Context, rows []*tenantDatamodel.Setting) *SettingsResponse {
+	settings := &SettingsResponse{
+		Settings:   s.defaults,
+		Overridden: []string{},
+	}
+	settings.ApprovalChain = slices.Clone(s.defaults.ApprovalChain)
+	settings.NotificationChannels = slices.Clone(s.defaults.NotificationChannels)
+	settings.MaxPendingByDepartment = maps.Clone(s.defaults.MaxPendingByDepartment)
+	settings.AmountLimitsByCategory = maps.Clone(s.defaults.AmountLimitsByCategory)
+
+	for _, row := range rows {
+		var target interface{}
+		switch row.Key {
+		case SettingAutoApprovalThreshold:
+			target = &settings.AutoApprovalThresholdIDR
+		case SettingCurrency:
+			target = &settings.Currency
+		case SettingApprovalChain:
+			target = &settings.ApprovalChain
+		case SettingNotificationChannels:
+			target = &settings.NotificationChannels
+		case SettingReceiptRequiredAbove:
+			target = &settings.ReceiptRequiredAboveIDR
+		case SettingMaxPendingExpenses:
+			target = &settings.MaxPendingExpenses
+		case SettingMaxPendingByDept:
+			target = &settings.MaxPendingByDepartment
+		case SettingMinExpenseAmount:
+			target = &settings.MinExpenseAmountIDR
+		case SettingMaxExpenseAmount:
+			target = &settings.MaxExpenseAmountIDR
+		case SettingAmountLimitsByCat:
+			target = &settings.AmountLimitsByCategory
+		default:
+			s.log(ctx).Warn("ignoring unknown tenant setting", "key", row.Key)
+			continue
+		}
+		if err := json.Unmarshal(row.Value, target); err != nil {
+			s.log(ctx).Warn("ignoring malformed tenant setting", "key", row.Key, "error", err)
+			continue
+		}
+		settings.Overridden = append(settings.Overridden, row.Key)
+	}
+
+	if settings.ApprovalChain == nil {
+		settings.ApprovalChain = []string{}
+	}
+	if settings.NotificationChannels == nil {
+		settings.NotificationChannels = []string{}
+	}
+	if settings.MaxPendingByDepartment == nil {
+		settings.MaxPendingByDepartment = map[string]int64{}
+	}
+	if settings.AmountLimitsByCategory == nil {
+		settings.AmountLimitsByCategory = map[string]AmountLimits{}
+	}
+	sort.Strings(settings.Overridden)
+	return settings
+}
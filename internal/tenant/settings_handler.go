@@ -0,0 +1,103 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/transport"
+	"github.com/go-chi/chi"
+)
+
+type SettingsServiceAPI interface {
+	Get(ctx context.Context) (*SettingsResponse, error)
+	Update(ctx context.Context, updatedBy int64, dto UpdateSettingsDTO) (*SettingsResponse, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type SettingsHandler struct {
+	*transport.BaseHandler
+	Service SettingsServiceAPI
+}
+
+func NewSettingsHandler(baseHandler *transport.BaseHandler, service SettingsServiceAPI) *SettingsHandler {
+	return &SettingsHandler{
+		BaseHandler: baseHandler,
+		Service:     service,
+	}
+}
+
+// GetSettings godoc
+// @Summary      Current tenant's settings
+// @Description  The effective settings of the caller's tenant. overridden lists the keys set for the tenant; the others are server defaults.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SettingsResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Router       /admin/tenant/settings [get]
+func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.Service.Get(r.Context())
+	if err != nil {
+		h.Log(r).Error("GetSettings: service error", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "failed to load tenant settings")
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings godoc
+// @Summary      Override the current tenant's settings
+// @Description  Omitted fields keep their current value. approval_chain entries must be existing permissions; an empty list lets any approver decide. max_pending_expenses_by_department and expense_amount_limits_by_category replace the whole map; a category bound of 0 keeps the tenant-wide one, and no maximum claim amount may be below its minimum.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      UpdateSettingsDTO  true  "Settings to override"
+// @Success      200   {object}  SettingsResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Failure      403   {object}  transport.ErrorResponse
+// @Failure      413   {object}  transport.AppErrorResponse
+// @Router       /admin/tenant/settings [put]
+func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	admin, ok := internal.UserFromContext(r.Context())
+	if !ok || admin == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto UpdateSettingsDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	settings, err := h.Service.Update(r.Context(), admin.ID, dto)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, settings)
+}
+
+// ResetSetting godoc
+// @Summary      Restore the server default of a tenant setting
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        key  path  string  true  "Setting key"
+// @Success      204
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      403  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /admin/tenant/settings/{key} [delete]
+func (h *SettingsHandler) ResetSetting(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.Reset(r.Context(), chi.URLParam(r, "key")); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,227 @@
+package tenant_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	tenantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/tenant"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+)
+
+// fakeSettingsRepository keeps settings per tenant, scoped by ctx like the
+// postgres repository.
+type fakeSettingsRepository struct {
+	rows        map[int64]map[string]json.RawMessage
+	lists       int
+	permissions map[string]bool
+}
+
+func (f *fakeSettingsRepository) tenantRows(ctx context.Context) map[string]json.RawMessage {
+	id, _ := tenant.FromContext(ctx)
+	if f.rows[id] == nil {
+		f.rows[id] = map[string]json.RawMessage{}
+	}
+	return f.rows[id]
+}
+
+func (f *fakeSettingsRepository) List(ctx context.Context) ([]*tenantDatamodel.Setting, error) {
+	f.lists++
+	var out []*tenantDatamodel.Setting
+	for key, value := range f.tenantRows(ctx) {
+		out = append(out, &tenantDatamodel.Setting{Key: key, Value: value})
+	}
+	return out, nil
+}
+
+func (f *fakeSettingsRepository) Save(ctx context.Context, settings []*tenantDatamodel.Setting) error {
+	rows := f.tenantRows(ctx)
+	for _, s := range settings {
+		rows[s.Key] = s.Value
+	}
+	return nil
+}
+
+func (f *fakeSettingsRepository) Delete(ctx context.Context, key string) (bool, error) {
+	rows := f.tenantRows(ctx)
+	_, ok := rows[key]
+	delete(rows, key)
+	return ok, nil
+}
+
+func (f *fakeSettingsRepository) PermissionExists(_ context.Context, name string) (bool, error) {
+	return f.permissions[name], nil
+}
+
+var _ = Describe("SettingsService", func() {
+	var (
+		ctx      context.Context
+		repo     *fakeSettingsRepository
+		defaults tenant.Settings
+		service  *tenant.SettingsService
+	)
+
+	BeforeEach(func() {
+		ctx = tenant.NewContext(context.Background(), 2)
+		repo = &fakeSettingsRepository{
+			rows:        map[int64]map[string]json.RawMessage{},
+			permissions: map[string]bool{"approve_finance": true},
+		}
+		defaults = tenant.Settings{
+			AutoApprovalThresholdIDR: 1000000,
+			Currency:                 "IDR",
+			NotificationChannels:     []string{tenant.ChannelEmail},
+			MinExpenseAmountIDR:      10000,
+			MaxExpenseAmountIDR:      50000000,
+		}
+		service = tenant.NewSettingsService(repo, defaults, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	It("returns the defaults when the tenant overrides nothing", func() {
+		settings, err := service.Get(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings.Settings.AutoApprovalThresholdIDR).To(Equal(int64(1000000)))
+		Expect(settings.ApprovalChain).To(BeEmpty())
+		Expect(settings.Overridden).To(BeEmpty())
+		Expect(service.NotifiesBy(ctx, tenant.ChannelEmail)).To(BeTrue())
+	})
+
+	It("applies overrides to the tenant that set them only", func() {
+		threshold := int64(250000)
+		currency := "USD"
+		receiptsAbove := int64(500000)
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{
+			AutoApprovalThresholdIDR: &threshold,
+			Currency:                 &currency,
+			ApprovalChain:            []string{"approve_finance"},
+			NotificationChannels:     []string{},
+			ReceiptRequiredAboveIDR:  &receiptsAbove,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.AutoApprovalThreshold(ctx)).To(Equal(threshold))
+		Expect(service.ReceiptRequiredAbove(ctx)).To(Equal(receiptsAbove))
+		Expect(service.Currency(ctx)).To(Equal("USD"))
+		Expect(service.ApprovalChain(ctx)).To(Equal([]string{"approve_finance"}))
+		Expect(service.NotifiesBy(ctx, tenant.ChannelEmail)).To(BeFalse())
+
+		other := tenant.NewContext(context.Background(), 3)
+		Expect(service.AutoApprovalThreshold(other)).To(Equal(int64(1000000)))
+		Expect(service.ReceiptRequiredAbove(other)).To(BeZero())
+		Expect(service.NotifiesBy(other, tenant.ChannelEmail)).To(BeTrue())
+	})
+
+	It("lets departments replace the pending expense cap", func() {
+		maxPending := int64(10)
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{
+			MaxPendingExpenses:     &maxPending,
+			MaxPendingByDepartment: map[string]int64{"sales": 3, "finance": 0},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.PendingExpenseLimit(ctx, "engineering")).To(Equal(int64(10)))
+		Expect(service.PendingExpenseLimit(ctx, "sales")).To(Equal(int64(3)))
+		Expect(service.PendingExpenseLimit(ctx, "finance")).To(BeZero())
+	})
+
+	It("bounds claims by category on top of the tenant-wide limits", func() {
+		minAmount, maxAmount := int64(5000), int64(20000000)
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{
+			MinExpenseAmountIDR:    &minAmount,
+			MaxExpenseAmountIDR:    &maxAmount,
+			AmountLimitsByCategory: map[string]tenant.AmountLimits{"travel": {MaxIDR: 100000000}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		min, max := service.ExpenseAmountLimits(ctx, "travel")
+		Expect(min).To(Equal(int64(5000)))
+		Expect(max).To(Equal(int64(100000000)))
+		min, max = service.ExpenseAmountLimits(ctx, "food")
+		Expect(min).To(Equal(int64(5000)))
+		Expect(max).To(Equal(int64(20000000)))
+	})
+
+	It("rejects claim limits whose maximum is below the minimum", func() {
+		maxAmount := int64(5000)
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{MaxExpenseAmountIDR: &maxAmount})
+		Expect(err).To(MatchError(tenant.ErrAmountLimitsCrossed))
+
+		_, err = service.Update(ctx, 9, tenant.UpdateSettingsDTO{
+			AmountLimitsByCategory: map[string]tenant.AmountLimits{"food": {MinIDR: 60000000}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(repo.rows[2]).To(BeEmpty())
+	})
+
+	It("rejects negative pending expense caps", func() {
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{
+			MaxPendingByDepartment: map[string]int64{"sales": -1},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(repo.rows).To(BeEmpty())
+	})
+
+	It("lists the overridden keys", func() {
+		currency := "SGD"
+		settings, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{Currency: &currency})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings.Overridden).To(Equal([]string{tenant.SettingCurrency}))
+	})
+
+	It("caches settings until they change", func() {
+		_, _ = service.Get(ctx)
+		_, _ = service.Get(ctx)
+		Expect(repo.lists).To(Equal(1))
+
+		currency := "SGD"
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{Currency: &currency})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(service.Currency(ctx)).To(Equal("SGD"))
+		Expect(repo.lists).To(Equal(2))
+	})
+
+	It("restores the default on reset", func() {
+		currency := "SGD"
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{Currency: &currency})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.Reset(ctx, tenant.SettingCurrency)).To(Succeed())
+		Expect(service.Currency(ctx)).To(Equal("IDR"))
+		Expect(service.Reset(ctx, tenant.SettingCurrency)).To(MatchError(tenant.ErrSettingNotFound))
+	})
+
+	It("skips rows that no longer decode", func() {
+		repo.rows[2] = map[string]json.RawMessage{tenant.SettingAutoApprovalThreshold: json.RawMessage(`"lots"`)}
+
+		Expect(service.AutoApprovalThreshold(ctx)).To(Equal(int64(1000000)))
+	})
+
+	It("rejects invalid values", func() {
+		negative := int64(-1)
+		_, err := service.Update(ctx, 9, tenant.UpdateSettingsDTO{AutoApprovalThresholdIDR: &negative})
+		Expect(err).To(HaveOccurred())
+
+		currency := "rupiah"
+		_, err = service.Update(ctx, 9, tenant.UpdateSettingsDTO{Currency: &currency})
+		Expect(err).To(HaveOccurred())
+
+		_, err = service.Update(ctx, 9, tenant.UpdateSettingsDTO{NotificationChannels: []string{"pager"}})
+		Expect(err).To(HaveOccurred())
+
+		_, err = service.Update(ctx, 9, tenant.UpdateSettingsDTO{ApprovalChain: []string{"approve_nothing"}})
+		Expect(err).To(MatchError(tenant.ErrUnknownPermission))
+
+		var appErr *errors.AppError
+		_, err = service.Update(ctx, 9, tenant.UpdateSettingsDTO{Currency: &currency})
+		Expect(err).To(BeAssignableToTypeOf(appErr))
+		Expect(repo.rows[2]).To(BeEmpty())
+	})
+})
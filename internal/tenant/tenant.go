@@ -0,0 +1,145 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	tenantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// DefaultID is the tenant that owns data created before multi-tenancy, and
+// the one anonymous requests without a tenant header are served from.
+const DefaultID int64 = 1
+
+// Header names the tenant of unauthenticated requests by slug. Authenticated
+// requests belong to the caller's tenant; a conflicting header is refused.
+const Header = "X-Tenant"
+
+type ctxKey struct{}
+
+// NewContext scopes ctx to tenantID. Repository queries run with ctx only
+// see and create rows of that tenant.
+func NewContext(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, ctxKey{}, tenantID)
+}
+
+// FromContext returns the tenant ctx is scoped to. Contexts without one, as
+// used by background workers and CLI commands, are not scoped.
+func FromContext(ctx context.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	id, ok := ctx.Value(ctxKey{}).(int64)
+	return id, ok
+}
+
+// OrDefault scopes ctx to the default tenant unless it already has one.
+func OrDefault(ctx context.Context) (context.Context, int64) {
+	if id, ok := FromContext(ctx); ok {
+		return ctx, id
+	}
+	return NewContext(ctx, DefaultID), DefaultID
+}
+
+type Tenant struct {
+	ID        int64     `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+var (
+	ErrTenantNotFound = errors.NewNotFoundError("Tenant not found", errors.ErrCodeTenantNotFound)
+	ErrTenantInactive = errors.NewForbiddenError("Tenant is inactive", errors.ErrCodeTenantInactive)
+	ErrTenantMismatch = errors.NewForbiddenError("Request tenant does not match the authenticated user", errors.ErrCodeTenantMismatch)
+	ErrInvalidSlug    = errors.NewValidationError("tenant slug must be lowercase letters, digits and dashes", errors.ErrCodeValidationFailed)
+)
+
+type RepositoryAPI interface {
+	// GetBySlug returns nil when no tenant has slug.
+	GetBySlug(slug string) (*tenantDatamodel.Tenant, error)
+	Create(t *tenantDatamodel.Tenant) error
+	CopyCategories(fromID, toID int64) error
+}
+
+type Service struct {
+	repo   RepositoryAPI
+	logger *slog.Logger
+}
+
+func NewService(repo RepositoryAPI, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+// Resolve returns the active tenant named slug.
+func (s *Service) Resolve(ctx context.Context, slug string) (*Tenant, error) {
+	row, err := s.repo.GetBySlug(strings.ToLower(strings.TrimSpace(slug)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up tenant: %w", err)
+	}
+	if row == nil {
+		return nil, ErrTenantNotFound
+	}
+	if !row.IsActive {
+		return nil, ErrTenantInactive
+	}
+	return fromDataModel(row), nil
+}
+
+// Ensure returns the tenant named slug, creating it when it does not exist.
+// New tenants start with a copy of the default tenant's categories, since
+// expenses cannot be filed without one.
+func (s *Service) Ensure(ctx context.Context, slug, name string) (*Tenant, bool, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugPattern.MatchString(slug) {
+		return nil, false, ErrInvalidSlug
+	}
+
+	row, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to look up tenant: %w", err)
+	}
+	if row != nil {
+		return fromDataModel(row), false, nil
+	}
+
+	if name == "" {
+		name = slug
+	}
+	row = &tenantDatamodel.Tenant{Slug: slug, Name: name, IsActive: true}
+	if err := s.repo.Create(row); err != nil {
+		return nil, false, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	if err := s.repo.CopyCategories(DefaultID, row.ID); err != nil {
+		return nil, false, fmt.Errorf("failed to copy categories to tenant: %w", err)
+	}
+
+	s.log(ctx).Info("tenant created", "tenant_id", row.ID, "slug", slug)
+	return fromDataModel(row), true, nil
+}
+
+func fromDataModel(t *tenantDatamodel.Tenant) *Tenant {
+	return &Tenant{
+		ID:        t.ID,
+		Slug:      t.Slug,
+		Name:      t.Name,
+		IsActive:  t.IsActive,
+		CreatedAt: t.CreatedAt,
+	}
+}
@@ -0,0 +1,143 @@
+package tenant_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	tenantDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/tenant"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type fakeRepository struct {
+	tenants map[string]*tenantDatamodel.Tenant
+	copied  [][2]int64
+}
+
+func (f *fakeRepository) GetBySlug(slug string) (*tenantDatamodel.Tenant, error) {
+	return f.tenants[slug], nil
+}
+
+func (f *fakeRepository) Create(t *tenantDatamodel.Tenant) error {
+	t.ID = int64(len(f.tenants) + 1)
+	f.tenants[t.Slug] = t
+	return nil
+}
+
+func (f *fakeRepository) CopyCategories(fromID, toID int64) error {
+	f.copied = append(f.copied, [2]int64{fromID, toID})
+	return nil
+}
+
+var _ = Describe("Service", func() {
+	var (
+		ctx     context.Context
+		repo    *fakeRepository
+		service *tenant.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &fakeRepository{tenants: map[string]*tenantDatamodel.Tenant{
+			"default": {ID: 1, Slug: "default", Name: "Default", IsActive: true},
+			"closed":  {ID: 2, Slug: "closed", Name: "Closed", IsActive: false},
+		}}
+		service = tenant.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	Describe("Resolve", func() {
+		It("returns the active tenant, ignoring case", func() {
+			t, err := service.Resolve(ctx, " Default ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.ID).To(Equal(tenant.DefaultID))
+		})
+
+		It("rejects unknown and inactive tenants", func() {
+			_, err := service.Resolve(ctx, "nobody")
+			Expect(err).To(MatchError(tenant.ErrTenantNotFound))
+
+			_, err = service.Resolve(ctx, "closed")
+			Expect(err).To(MatchError(tenant.ErrTenantInactive))
+		})
+	})
+
+	Describe("Ensure", func() {
+		It("creates a missing tenant with the default tenant's categories", func() {
+			t, created, err := service.Ensure(ctx, "acme", "Acme Corp")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+			Expect(t.Name).To(Equal("Acme Corp"))
+			Expect(t.IsActive).To(BeTrue())
+			Expect(repo.copied).To(Equal([][2]int64{{tenant.DefaultID, t.ID}}))
+		})
+
+		It("returns an existing tenant unchanged", func() {
+			t, created, err := service.Ensure(ctx, "default", "Renamed")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+			Expect(t.Name).To(Equal("Default"))
+			Expect(repo.copied).To(BeEmpty())
+		})
+
+		It("rejects slugs that cannot go in a header", func() {
+			_, _, err := service.Ensure(ctx, "acme corp", "")
+			Expect(err).To(MatchError(tenant.ErrInvalidSlug))
+		})
+	})
+})
+
+var _ = Describe("Middleware", func() {
+	var (
+		handler *tenant.Handler
+		seen    int64
+		scoped  bool
+		next    http.Handler
+	)
+
+	BeforeEach(func() {
+		repo := &fakeRepository{tenants: map[string]*tenantDatamodel.Tenant{
+			"acme": {ID: 5, Slug: "acme", IsActive: true},
+		}}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		handler = tenant.NewHandler(transport.NewBaseHandler(logger), tenant.NewService(repo, logger))
+		seen, scoped = 0, false
+		next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen, scoped = tenant.FromContext(r.Context())
+		})
+	})
+
+	serve := func(slug string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+		if slug != "" {
+			req.Header.Set(tenant.Header, slug)
+		}
+		rec := httptest.NewRecorder()
+		handler.Middleware(next).ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("scopes the request to the tenant in the header", func() {
+		rec := serve("acme")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(scoped).To(BeTrue())
+		Expect(seen).To(Equal(int64(5)))
+	})
+
+	It("leaves requests without the header unscoped", func() {
+		rec := serve("")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(scoped).To(BeFalse())
+	})
+
+	It("refuses unknown tenants", func() {
+		rec := serve("nobody")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+		Expect(scoped).To(BeFalse())
+	})
+})
@@ -6,23 +6,44 @@ import (
 	"net/http"
 
 	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
 	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
+// ErrorResponse is the body written by WriteError.
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// AppErrorResponse is the body written by HandleError for application errors.
+type AppErrorResponse struct {
+	Error *errors.AppError `json:"error"`
+}
+
 type BaseHandler struct {
 	Logger *slog.Logger
+	// MaxBodyBytes bounds bodies read by DecodeJSON; zero means
+	// DefaultMaxBodyBytes.
+	MaxBodyBytes int64
 }
 
 func NewBaseHandler(lg *slog.Logger) *BaseHandler {
 	if lg == nil {
 		lg = logger.LoggerWrapper()
-		if lg == nil {
-			lg = slog.Default()
-		}
 	}
 	return &BaseHandler{Logger: lg}
 }
 
+// Log returns the request-scoped logger carrying request_id, trace_id and,
+// once authenticated, user_id. It falls back to the handler logger.
+func (h *BaseHandler) Log(r *http.Request) *slog.Logger {
+	if r == nil {
+		return h.Logger
+	}
+	return logger.FromOr(r.Context(), h.Logger)
+}
+
 func (h *BaseHandler) WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -31,24 +52,25 @@ func (h *BaseHandler) WriteJSON(w http.ResponseWriter, status int, data interfac
 	}
 }
 
-func (h *BaseHandler) WriteError(w http.ResponseWriter, status int, message string) {
-	h.Logger.Error("http error", "status", status, "message", message)
+func (h *BaseHandler) WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	h.Log(r).Error("http error", "status", status, "message", message)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
-	errorResp := map[string]interface{}{
-		"code":    status,
-		"message": message,
+	errorResp := ErrorResponse{
+		Code:    status,
+		Message: message,
 	}
 
 	if err := json.NewEncoder(w).Encode(errorResp); err != nil {
-		h.Logger.Error("failed to encode error response", "error", err)
+		h.Log(r).Error("failed to encode error response", "error", err)
 	}
 }
 
-func (h *BaseHandler) HandleError(w http.ResponseWriter, err error) {
+func (h *BaseHandler) HandleError(w http.ResponseWriter, r *http.Request, err error) {
 	if appErr, ok := errors.IsAppError(err); ok {
-		h.Logger.Error("application error",
+		appErr = appErr.Localize(i18n.FromContext(r.Context()))
+		h.Log(r).Error("application error",
 			"type", appErr.Type,
 			"code", appErr.Code,
 			"message", appErr.Message,
@@ -58,27 +80,27 @@ func (h *BaseHandler) HandleError(w http.ResponseWriter, err error) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(appErr.StatusCode)
 
-		if encodeErr := json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": appErr,
+		if encodeErr := json.NewEncoder(w).Encode(AppErrorResponse{
+			Error: appErr,
 		}); encodeErr != nil {
-			h.Logger.Error("failed to encode error response", "error", encodeErr)
+			h.Log(r).Error("failed to encode error response", "error", encodeErr)
 		}
 		return
 	}
 
-	h.Logger.Error("internal error", "error", err)
-	h.WriteError(w, http.StatusInternalServerError, "Internal server error")
+	h.Log(r).Error("internal error", "error", err)
+	h.WriteError(w, r, http.StatusInternalServerError, "Internal server error")
 }
 
-func (h *BaseHandler) HandleServiceError(w http.ResponseWriter, err error) {
+func (h *BaseHandler) HandleServiceError(w http.ResponseWriter, r *http.Request, err error) {
 
 	switch err.Error() {
 	case "record not found", "sql: no rows in result set":
-		h.HandleError(w, errors.ErrExpenseNotFound)
+		h.HandleError(w, r, errors.ErrExpenseNotFound)
 		return
 	}
 
-	h.HandleError(w, err)
+	h.HandleError(w, r, err)
 }
 
 func (h *BaseHandler) ExtractTokenFromHeader(r *http.Request) string {
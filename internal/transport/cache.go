@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ETag builds a strong entity tag from parts that together identify a
+// representation.
+func ETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified sets the ETag and Cache-Control headers and, when the request's
+// If-None-Match already matches etag, writes 304 and returns true.
+func (h *BaseHandler) NotModified(w http.ResponseWriter, r *http.Request, etag, cacheControl string) bool {
+	w.Header().Set("ETag", etag)
+	if cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// WriteJSONCached writes data like WriteJSON, tagging it with an ETag derived
+// from the encoded body so unchanged responses revalidate with a 304.
+func (h *BaseHandler) WriteJSONCached(w http.ResponseWriter, r *http.Request, status int, data interface{}, cacheControl string) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(data); err != nil {
+		h.Log(r).Error("failed to encode JSON response", "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	if h.NotModified(w, r, ETag(body.String()), cacheControl) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		h.Log(r).Error("failed to write JSON response", "error", err)
+	}
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
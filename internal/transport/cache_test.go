@@ -0,0 +1,67 @@
+package transport_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+var _ = Describe("HTTP caching", func() {
+	var handler *transport.BaseHandler
+
+	BeforeEach(func() {
+		handler = transport.NewBaseHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	})
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.WriteJSONCached(w, req, http.StatusOK, map[string]string{"name": "taxi"}, "private, no-cache")
+		return w
+	}
+
+	It("tags responses with an ETag and Cache-Control", func() {
+		w := get("")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).To(MatchRegexp(`^"[0-9a-f]{32}"$`))
+		Expect(w.Header().Get("Cache-Control")).To(Equal("private, no-cache"))
+		Expect(w.Body.String()).To(MatchJSON(`{"name":"taxi"}`))
+	})
+
+	It("returns 304 without a body when the ETag matches", func() {
+		etag := get("").Header().Get("ETag")
+
+		w := get(`"stale", W/` + etag)
+
+		Expect(w.Code).To(Equal(http.StatusNotModified))
+		Expect(w.Body.Len()).To(BeZero())
+		Expect(w.Header().Get("ETag")).To(Equal(etag))
+	})
+
+	It("returns the full response when the ETag differs", func() {
+		Expect(get(`"stale"`).Code).To(Equal(http.StatusOK))
+	})
+
+	It("never short-circuits unsafe methods", func() {
+		etag := transport.ETag("x")
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		req.Header.Set("If-None-Match", etag)
+
+		Expect(handler.NotModified(httptest.NewRecorder(), req, etag, "")).To(BeFalse())
+	})
+
+	It("derives distinct tags from distinct parts", func() {
+		Expect(transport.ETag("a", "bc")).NotTo(Equal(transport.ETag("ab", "c")))
+		Expect(transport.ETag("a", "b")).To(Equal(transport.ETag("a", "b")))
+	})
+})
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
+)
+
+// DefaultMaxBodyBytes caps JSON request bodies read through DecodeJSON.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// MultipartOverhead is added to a file size limit when capping a multipart
+// upload, leaving room for form boundaries and headers around the file.
+const MultipartOverhead = 1 << 20
+
+// DecodeJSON decodes a single JSON object from the request body into dst,
+// rejecting unknown fields and bodies over the handler's size limit. On
+// failure it writes a 400 or 413 response and returns false.
+func (h *BaseHandler) DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	limit := h.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+
+	if err := DecodeJSONBody(w, r, dst, limit); err != nil {
+		h.Log(r).Warn("invalid request body", "error", err)
+		h.HandleError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// DecodeJSONBody is the strict decoder behind DecodeJSON. The returned error
+// is always an *errors.AppError.
+func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err, maxBytes)
+	}
+
+	if err := dec.Decode(&struct{}{}); !stderrors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			return decodeError(err, maxBytes)
+		}
+		return errors.NewValidationError("request body must contain a single JSON object", errors.ErrCodeInvalidRequestBody)
+	}
+	return nil
+}
+
+func decodeError(err error, maxBytes int64) *errors.AppError {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		maxBytesErr  *http.MaxBytesError
+		unknownField = "json: unknown field "
+	)
+
+	switch {
+	case stderrors.As(err, &maxBytesErr):
+		return errors.NewRequestTooLargeError(
+			fmt.Sprintf("request body must not exceed %d bytes", maxBytes),
+			errors.ErrCodeRequestTooLarge,
+		)
+	case stderrors.Is(err, io.EOF):
+		return errors.NewValidationError("request body must not be empty", errors.ErrCodeInvalidRequestBody)
+	case stderrors.As(err, &syntaxErr):
+		return errors.NewValidationError(
+			fmt.Sprintf("request body contains malformed JSON at position %d", syntaxErr.Offset),
+			errors.ErrCodeInvalidRequestBody,
+		)
+	case stderrors.Is(err, io.ErrUnexpectedEOF):
+		return errors.NewValidationError("request body contains malformed JSON", errors.ErrCodeInvalidRequestBody)
+	case stderrors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return errors.NewValidationError("request body must be a JSON object", errors.ErrCodeInvalidRequestBody)
+		}
+		return errors.NewLocalizedFieldError(typeErr.Field, "validation.type",
+			i18n.Params{"type": typeErr.Type.String()}, errors.ErrCodeInvalidRequestBody)
+	case strings.HasPrefix(err.Error(), unknownField):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownField), `"`)
+		return errors.NewLocalizedFieldError(field, "validation.unknown_field", nil, errors.ErrCodeInvalidRequestBody)
+	default:
+		return errors.NewValidationError("invalid request body", errors.ErrCodeInvalidRequestBody)
+	}
+}
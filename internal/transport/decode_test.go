@@ -0,0 +1,95 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/transport"
+)
+
+type decodeTarget struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+var _ = Describe("DecodeJSON", func() {
+	var (
+		handler  *transport.BaseHandler
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		handler = transport.NewBaseHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		recorder = httptest.NewRecorder()
+	})
+
+	decode := func(body string) (decodeTarget, bool) {
+		var dst decodeTarget
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		return dst, handler.DecodeJSON(recorder, req, &dst)
+	}
+
+	errorBody := func() map[string]interface{} {
+		var resp struct {
+			Error map[string]interface{} `json:"error"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		return resp.Error
+	}
+
+	It("decodes a valid object", func() {
+		dst, ok := decode(`{"name":"taxi","count":2}`)
+
+		Expect(ok).To(BeTrue())
+		Expect(dst).To(Equal(decodeTarget{Name: "taxi", Count: 2}))
+	})
+
+	It("rejects unknown fields", func() {
+		_, ok := decode(`{"name":"taxi","amount":1}`)
+
+		Expect(ok).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"field":"amount"`))
+	})
+
+	It("reports type mismatches against the field", func() {
+		_, ok := decode(`{"count":"two"}`)
+
+		Expect(ok).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("count must be a int"))
+	})
+
+	DescribeTable("rejects malformed bodies",
+		func(body, message string) {
+			_, ok := decode(body)
+
+			Expect(ok).To(BeFalse())
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorBody()).To(HaveKeyWithValue("code", "INVALID_REQUEST_BODY"))
+			Expect(errorBody()["message"]).To(ContainSubstring(message))
+		},
+		Entry("empty", ``, "must not be empty"),
+		Entry("syntax", `{"name":}`, "malformed JSON"),
+		Entry("truncated", `{"name":"taxi"`, "malformed JSON"),
+		Entry("not an object", `["taxi"]`, "must be a JSON object"),
+		Entry("trailing data", `{"name":"taxi"}{"name":"bus"}`, "single JSON object"),
+	)
+
+	It("returns 413 when the body exceeds the limit", func() {
+		handler.MaxBodyBytes = 16
+
+		_, ok := decode(`{"name":"` + strings.Repeat("x", 32) + `"}`)
+
+		Expect(ok).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(errorBody()).To(HaveKeyWithValue("code", "REQUEST_TOO_LARGE"))
+	})
+})
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+
+	"github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/tracing"
+	pkglogger "github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+// DeadlineConfig controls the per-request deadline and slow-request logging.
+type DeadlineConfig struct {
+	// Timeout bounds how long a handler may run; zero disables the deadline.
+	Timeout time.Duration
+	// SlowThreshold is the latency above which a request is logged with its
+	// spans; zero disables slow-request logging.
+	SlowThreshold time.Duration
+	// SkipPaths lists path prefixes, such as uploads and exports, that run
+	// without a deadline. They are still logged when slow.
+	SkipPaths []string
+}
+
+// Deadline cancels the request context once cfg.Timeout passes and answers
+// 503 REQUEST_TIMEOUT if the handler has not responded by then; what the
+// handler writes afterwards is discarded. Requests slower than
+// cfg.SlowThreshold are logged with the spans recorded through
+// tracing.StartSpan.
+func Deadline(logger *slog.Logger, cfg DeadlineConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if cfg.Timeout <= 0 && cfg.SlowThreshold <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ctx := r.Context()
+			var rec *tracing.Recorder
+			if cfg.SlowThreshold > 0 {
+				ctx, rec = tracing.NewContext(ctx)
+			}
+
+			status, timedOut := http.StatusOK, false
+			if cfg.Timeout > 0 && !cfg.skips(r.URL.Path) {
+				status, timedOut = serveWithDeadline(w, r.WithContext(ctx), next, cfg.Timeout)
+			} else {
+				ww := &responseWriter{ResponseWriter: w}
+				next.ServeHTTP(ww, r.WithContext(ctx))
+				status = ww.status()
+			}
+
+			duration := time.Since(start)
+			reqLogger := pkglogger.FromOr(r.Context(), logger.With("request_id", middleware.GetReqID(r.Context())))
+			if timedOut {
+				reqLogger.Warn("request timed out",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"timeout_ms", cfg.Timeout.Milliseconds())
+			}
+			if rec != nil && duration >= cfg.SlowThreshold {
+				// A timed-out handler may still be routing, so its route
+				// pattern is only read once it has returned.
+				route := ""
+				if routeCtx := chi.RouteContext(r.Context()); routeCtx != nil && !timedOut {
+					route = routeCtx.RoutePattern()
+				}
+				logSlowRequest(reqLogger, r, route, status, duration, cfg.SlowThreshold, rec)
+			}
+		})
+	}
+}
+
+func (c DeadlineConfig) skips(path string) bool {
+	for _, prefix := range c.SkipPaths {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveWithDeadline runs next on its own goroutine against a buffered writer,
+// so the timeout response can replace a response still being written. A
+// panic is re-raised here for RecoveryMiddleware.
+func serveWithDeadline(w http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration) (int, bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	tw := &timeoutWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(tw, r)
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for name, values := range tw.header {
+			dst[name] = values
+		}
+		if tw.code == 0 {
+			tw.code = http.StatusOK
+		}
+		w.WriteHeader(tw.code)
+		_, _ = w.Write(tw.buf.Bytes())
+		return tw.code, false
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		tw.timedOut = true
+		status, body := internal.ErrRequestTimeout.ToHTTPResponse()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+		return status, true
+	}
+}
+
+// timeoutWriter buffers a response until the handler returns; once the
+// deadline has passed, writes fail with http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(b)
+}
+
+func logSlowRequest(logger *slog.Logger, r *http.Request, route string, status int, duration, threshold time.Duration, rec *tracing.Recorder) {
+	recorded, dropped := rec.Spans()
+	spans := make([]map[string]interface{}, len(recorded))
+	for i, span := range recorded {
+		spans[i] = map[string]interface{}{
+			"name":        span.Name,
+			"offset_ms":   span.Offset.Milliseconds(),
+			"duration_ms": span.Duration.Milliseconds(),
+		}
+	}
+
+	attrs := []any{
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", status,
+		"duration_ms", duration.Milliseconds(),
+		"threshold_ms", threshold.Milliseconds(),
+		"spans", spans,
+	}
+	if route != "" {
+		attrs = append(attrs, "route", route)
+	}
+	if dropped > 0 {
+		attrs = append(attrs, "spans_dropped", dropped)
+	}
+	logger.Warn("slow request", attrs...)
+}
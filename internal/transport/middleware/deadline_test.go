@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/tracing"
+	"github.com/frahmantamala/expense-management/internal/transport/middleware"
+)
+
+var _ = Describe("Deadline", func() {
+	var (
+		logs   *bytes.Buffer
+		logger *slog.Logger
+	)
+
+	serve := func(cfg middleware.DeadlineConfig, h http.HandlerFunc, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		middleware.Deadline(logger, cfg)(h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	BeforeEach(func() {
+		logs = &bytes.Buffer{}
+		logger = slog.New(slog.NewTextHandler(logs, nil))
+	})
+
+	It("passes a response written before the deadline through", func() {
+		rec := serve(middleware.DeadlineConfig{Timeout: time.Second}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":1}`))
+		}, "/api/v1/expenses")
+
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(rec.Body.String()).To(Equal(`{"id":1}`))
+	})
+
+	It("cancels the handler and answers 503 once the deadline passes", func() {
+		cancelled := make(chan struct{})
+		rec := serve(middleware.DeadlineConfig{Timeout: 20 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			close(cancelled)
+			w.Write([]byte("too slow"))
+		}, "/api/v1/expenses")
+
+		Eventually(cancelled).Should(BeClosed())
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+
+		var body struct {
+			Error struct {
+				Type string `json:"type"`
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Error.Type).To(Equal("SERVICE_UNAVAILABLE"))
+		Expect(body.Error.Code).To(Equal("REQUEST_TIMEOUT"))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("too slow"))
+		Expect(logs.String()).To(ContainSubstring("request timed out"))
+	})
+
+	It("leaves skipped paths without a deadline", func() {
+		cfg := middleware.DeadlineConfig{Timeout: 10 * time.Millisecond, SkipPaths: []string{"/api/v1/exports"}}
+		rec := serve(cfg, func(w http.ResponseWriter, r *http.Request) {
+			_, hasDeadline := r.Context().Deadline()
+			Expect(hasDeadline).To(BeFalse())
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusAccepted)
+		}, "/api/v1/exports/1")
+
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+	})
+
+	It("re-raises a handler panic on the serving goroutine", func() {
+		h := middleware.Deadline(logger, middleware.DeadlineConfig{Timeout: time.Second})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		Expect(func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil))
+		}).To(PanicWith("boom"))
+	})
+
+	It("logs slow requests with their spans", func() {
+		serve(middleware.DeadlineConfig{SlowThreshold: 5 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+			end := tracing.StartSpan(r.Context(), "db query expenses")
+			time.Sleep(10 * time.Millisecond)
+			end()
+		}, "/api/v1/expenses")
+
+		Expect(logs.String()).To(ContainSubstring("slow request"))
+		Expect(logs.String()).To(ContainSubstring("db query expenses"))
+	})
+
+	It("does not log requests under the threshold", func() {
+		serve(middleware.DeadlineConfig{SlowThreshold: time.Second}, func(w http.ResponseWriter, r *http.Request) {}, "/api/v1/expenses")
+
+		Expect(logs.String()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
+)
+
+// Locale negotiates the response language from Accept-Language and stores it
+// in the request context for error rendering.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loc := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", string(loc))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), loc)))
+	})
+}
@@ -0,0 +1,30 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/core/common/i18n"
+	"github.com/frahmantamala/expense-management/internal/transport/middleware"
+)
+
+var _ = Describe("Locale", func() {
+	It("stores the negotiated locale and announces it", func() {
+		var got i18n.Locale
+		h := middleware.Locale(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = i18n.FromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil)
+		req.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		Expect(got).To(Equal(i18n.Indonesian))
+		Expect(rec.Header().Get("Content-Language")).To(Equal("id"))
+		Expect(rec.Header().Values("Vary")).To(ContainElement("Accept-Language"))
+	})
+})
@@ -2,13 +2,17 @@ package middleware
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"math/rand"
+	"mime"
 	"net/http"
 	"strings"
 	"time"
 
+	pkglogger "github.com/frahmantamala/expense-management/pkg/logger"
 	"github.com/go-chi/chi/middleware"
 )
 
@@ -27,35 +31,216 @@ var sensitiveFields = []string{
 	"session",
 	"credential",
 	"auth",
+	"account_number",
 }
 
-func LoggingMiddleware(logger *slog.Logger) func(next http.Handler) http.Handler {
+// LogConfig controls what the logging middleware writes.
+type LogConfig struct {
+	Body BodyLogConfig
+	// Redactor masks sensitive data; nil masks the built-in fields only.
+	Redactor *Redactor
+	// SuccessSampleRate is the fraction (0..1) of requests answered with a
+	// 2xx status that are logged at all. Other requests are always logged.
+	SuccessSampleRate float64
+}
+
+// DefaultLogConfig logs every request with DefaultBodyLogConfig.
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Body:              DefaultBodyLogConfig(),
+		Redactor:          DefaultRedactor(),
+		SuccessSampleRate: 1.0,
+	}
+}
+
+// BodyLogConfig controls how much of the request and response bodies the
+// logging middleware captures.
+type BodyLogConfig struct {
+	Enabled bool
+	// MaxBytes caps how many bytes of each body are captured; the rest of the
+	// payload streams through untouched.
+	MaxBytes int
+	// ContentTypes is an allow-list of media types whose bodies may be logged.
+	// An empty list allows every content type.
+	ContentTypes []string
+	// SampleRate is the fraction of requests (0..1) whose bodies are logged.
+	SampleRate float64
+	// SkipPaths lists path prefixes whose bodies are never logged.
+	SkipPaths []string
+}
+
+// DefaultBodyLogConfig captures small JSON and text bodies for every request.
+func DefaultBodyLogConfig() BodyLogConfig {
+	return BodyLogConfig{
+		Enabled:      true,
+		MaxBytes:     4096,
+		ContentTypes: []string{"application/json", "text/plain", "application/x-www-form-urlencoded"},
+		SampleRate:   1.0,
+	}
+}
+
+type bodyLogKey struct{}
+
+// bodyLogState is shared between LoggingMiddleware and SkipBodyLogging so a
+// route mounted below the global middleware can still opt out.
+type bodyLogState struct {
+	disabled bool
+}
+
+// SkipBodyLogging disables body capture for the routes it wraps.
+func SkipBodyLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if state, ok := r.Context().Value(bodyLogKey{}).(*bodyLogState); ok {
+			state.disabled = true
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoggingMiddleware logs each request and its response. A request sampled
+// out by SuccessSampleRate is logged only if it is not answered with a 2xx,
+// in which case its request line is written with the response.
+func LoggingMiddleware(logger *slog.Logger, logCfg LogConfig) func(next http.Handler) http.Handler {
+	cfg := logCfg.Body
+	redactor := logCfg.Redactor
+	if redactor == nil {
+		redactor = DefaultRedactor()
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
-			reqID := middleware.GetReqID(r.Context())
+			reqLogger := pkglogger.FromOr(r.Context(), logger.With("request_id", middleware.GetReqID(r.Context())))
+			rules := redactor.forPath(r.URL.Path)
 
-			logRequest(logger, r, reqID)
+			sampled := logCfg.sampleSuccess()
+			if sampled {
+				logRequest(reqLogger, r, rules)
+			}
+
+			state := &bodyLogState{disabled: !cfg.shouldCapture(r)}
+			r = r.WithContext(context.WithValue(r.Context(), bodyLogKey{}, state))
+
+			var reqBody *cappedBuffer
+			if !state.disabled && r.Body != nil && cfg.allowsContentType(r.Header.Get("Content-Type")) {
+				reqBody = &cappedBuffer{max: cfg.MaxBytes}
+				r.Body = &teeReadCloser{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+			}
 
 			ww := &responseWriter{
 				ResponseWriter: w,
-				body:           &bytes.Buffer{},
+			}
+			if !state.disabled {
+				ww.body = &cappedBuffer{max: cfg.MaxBytes}
 			}
 
 			next.ServeHTTP(ww, r)
 
 			duration := time.Since(start)
-			logResponse(logger, ww, duration, reqID)
+			if !sampled {
+				if status := ww.status(); status >= 200 && status < 300 {
+					return
+				}
+				logRequest(reqLogger, r, rules)
+			}
+
+			var reqCapture, respCapture *cappedBuffer
+			if !state.disabled {
+				reqCapture = reqBody
+				if cfg.allowsContentType(ww.Header().Get("Content-Type")) {
+					respCapture = ww.body
+				}
+			}
+			logResponse(reqLogger, ww, duration, reqCapture, respCapture, rules)
 		})
 	}
 }
 
-// responseWriter wraps http.ResponseWriter to capture response body
+func (c LogConfig) sampleSuccess() bool {
+	if c.SuccessSampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < c.SuccessSampleRate
+}
+
+func (c BodyLogConfig) shouldCapture(r *http.Request) bool {
+	if !c.Enabled || c.MaxBytes <= 0 {
+		return false
+	}
+	for _, prefix := range c.SkipPaths {
+		if prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	if c.SampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < c.SampleRate
+}
+
+func (c BodyLogConfig) allowsContentType(contentType string) bool {
+	if len(c.ContentTypes) == 0 {
+		return true
+	}
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.ContentTypes {
+		if strings.EqualFold(mediaType, strings.TrimSpace(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cappedBuffer keeps the first max bytes written to it and counts the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	total     int
+	truncated bool
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.total += len(p)
+	if remaining := c.max - c.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			c.buf.Write(p[:remaining])
+			c.truncated = true
+		} else {
+			c.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		c.truncated = true
+	}
+	return len(p), nil
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseWriter wraps http.ResponseWriter to capture the status code, the
+// response size and, when enabled, a capped copy of the body.
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
-	body       *bytes.Buffer
+	size       int
+	body       *cappedBuffer
+}
+
+// status is the response status, 200 if the handler never set one.
+func (rw *responseWriter) status() int {
+	if rw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rw.statusCode
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
@@ -64,41 +249,29 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	rw.body.Write(b)
-	return rw.ResponseWriter.Write(b)
-}
-
-// logRequest logs the incoming HTTP request with sensitive data filtered
-func logRequest(logger *slog.Logger, r *http.Request, reqID string) {
-	var bodyBytes []byte
-	if r.Body != nil {
-		bodyBytes, _ = io.ReadAll(r.Body)
-		r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	if rw.body != nil {
+		rw.body.Write(b)
 	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += n
+	return n, err
+}
 
-	headers := filterSensitiveHeaders(r.Header)
-
-	filteredBody := filterSensitiveBody(bodyBytes)
-
+// logRequest logs the incoming HTTP request with sensitive headers filtered.
+// Bodies are logged with the response once the handler has consumed them.
+func logRequest(logger *slog.Logger, r *http.Request, rules *fieldRules) {
 	logger.Info("incoming request",
-		"request_id", reqID,
 		"method", r.Method,
 		"path", r.URL.Path,
-		"query", r.URL.RawQuery,
+		"query", rules.query(r.URL.Query()),
 		"remote_addr", r.RemoteAddr,
 		"user_agent", r.UserAgent(),
-		"headers", headers,
-		"body", filteredBody,
+		"headers", rules.headers(r.Header),
 	)
 }
 
-func logResponse(logger *slog.Logger, rw *responseWriter, duration time.Duration, reqID string) {
-	statusCode := rw.statusCode
-	if statusCode == 0 {
-		statusCode = 200
-	}
-
-	filteredBody := filterSensitiveBody(rw.body.Bytes())
+func logResponse(logger *slog.Logger, rw *responseWriter, duration time.Duration, reqBody, respBody *cappedBuffer, rules *fieldRules) {
+	statusCode := rw.status()
 
 	logLevel := slog.LevelInfo
 	if statusCode >= 400 && statusCode < 500 {
@@ -107,103 +280,24 @@ func logResponse(logger *slog.Logger, rw *responseWriter, duration time.Duration
 		logLevel = slog.LevelError
 	}
 
-	logger.Log(nil, logLevel, "response",
-		"request_id", reqID,
+	attrs := []any{
 		"status_code", statusCode,
 		"duration_ms", duration.Milliseconds(),
-		"response_size", rw.body.Len(),
-		"body", filteredBody,
-	)
-}
-
-// filterSensitiveHeaders removes or masks sensitive headers
-func filterSensitiveHeaders(headers http.Header) map[string]string {
-	filtered := make(map[string]string)
-
-	for name, values := range headers {
-		lowerName := strings.ToLower(name)
-
-		// Check if header contains sensitive data
-		isSensitive := false
-		for _, sensitiveField := range sensitiveFields {
-			if strings.Contains(lowerName, sensitiveField) {
-				isSensitive = true
-				break
-			}
-		}
-
-		if isSensitive {
-			filtered[name] = "[FILTERED]"
-		} else {
-			filtered[name] = strings.Join(values, ", ")
-		}
+		"response_size", rw.size,
 	}
-
-	return filtered
-}
-
-// filterSensitiveBody removes or masks sensitive fields from JSON body
-func filterSensitiveBody(body []byte) string {
-	if len(body) == 0 {
-		return ""
-	}
-
-	// Try to parse as JSON
-	var jsonData interface{}
-	if err := json.Unmarshal(body, &jsonData); err != nil {
-		// If not JSON, return as string but check for sensitive patterns
-		bodyStr := string(body)
-		for _, sensitiveField := range sensitiveFields {
-			if strings.Contains(strings.ToLower(bodyStr), sensitiveField) {
-				return "[FILTERED - Contains sensitive data]"
-			}
-		}
-		return bodyStr
+	if reqBody != nil {
+		attrs = append(attrs, "request_body", capturedBody(reqBody, rules))
 	}
-
-	// Filter sensitive fields from JSON
-	filtered := filterSensitiveJSON(jsonData)
-
-	// Convert back to JSON string
-	filteredBytes, err := json.Marshal(filtered)
-	if err != nil {
-		return "[ERROR - Failed to marshal filtered JSON]"
+	if respBody != nil {
+		attrs = append(attrs, "body", capturedBody(respBody, rules))
 	}
 
-	return string(filteredBytes)
+	logger.Log(context.Background(), logLevel, "response", attrs...)
 }
 
-// filterSensitiveJSON recursively filters sensitive fields from JSON data
-func filterSensitiveJSON(data interface{}) interface{} {
-	switch v := data.(type) {
-	case map[string]interface{}:
-		filtered := make(map[string]interface{})
-		for key, value := range v {
-			lowerKey := strings.ToLower(key)
-
-			// Check if key is sensitive
-			isSensitive := false
-			for _, sensitiveField := range sensitiveFields {
-				if strings.Contains(lowerKey, sensitiveField) {
-					isSensitive = true
-					break
-				}
-			}
-
-			if isSensitive {
-				filtered[key] = "[FILTERED]"
-			} else {
-				filtered[key] = filterSensitiveJSON(value)
-			}
-		}
-		return filtered
-	case []interface{}:
-		filtered := make([]interface{}, len(v))
-		for i, item := range v {
-			filtered[i] = filterSensitiveJSON(item)
-		}
-		return filtered
-	default:
-		return v
+func capturedBody(c *cappedBuffer, rules *fieldRules) string {
+	if c.truncated {
+		return rules.text(c.buf.String()) + fmt.Sprintf("...[TRUNCATED %d bytes]", c.total-c.buf.Len())
 	}
+	return rules.body(c.buf.Bytes())
 }
@@ -0,0 +1,180 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/transport/middleware"
+)
+
+var _ = Describe("LoggingMiddleware", func() {
+	var (
+		logs   *bytes.Buffer
+		logger *slog.Logger
+		cfg    middleware.LogConfig
+	)
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	})
+
+	serve := func(h http.Handler, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		middleware.LoggingMiddleware(logger, cfg)(h).ServeHTTP(rec, req)
+		return rec
+	}
+
+	BeforeEach(func() {
+		logs = &bytes.Buffer{}
+		logger = slog.New(slog.NewTextHandler(logs, nil))
+		cfg = middleware.DefaultLogConfig()
+	})
+
+	It("logs allowed bodies and passes the full payload through", func() {
+		rec := serve(echo, "application/json", `{"description":"lunch"}`)
+
+		Expect(rec.Body.String()).To(Equal(`{"description":"lunch"}`))
+		Expect(logs.String()).To(ContainSubstring("request_body="))
+		Expect(logs.String()).To(ContainSubstring("lunch"))
+	})
+
+	It("truncates bodies larger than the cap", func() {
+		cfg.Body.MaxBytes = 8
+		payload := strings.Repeat("a", 100)
+
+		rec := serve(echo, "text/plain", payload)
+
+		Expect(rec.Body.String()).To(Equal(payload))
+		Expect(logs.String()).To(ContainSubstring("[TRUNCATED 92 bytes]"))
+		Expect(logs.String()).NotTo(ContainSubstring(strings.Repeat("a", 9)))
+	})
+
+	It("skips content types outside the allow-list", func() {
+		serve(echo, "application/octet-stream", "binary-data")
+
+		Expect(logs.String()).NotTo(ContainSubstring("binary-data"))
+		Expect(logs.String()).To(ContainSubstring("response_size=11"))
+	})
+
+	It("skips bodies when sampled out", func() {
+		cfg.Body.SampleRate = 0
+
+		serve(echo, "application/json", `{"description":"lunch"}`)
+
+		Expect(logs.String()).NotTo(ContainSubstring("lunch"))
+	})
+
+	It("honours the per-route opt-out", func() {
+		serve(middleware.SkipBodyLogging(echo), "application/json", `{"description":"lunch"}`)
+
+		Expect(logs.String()).NotTo(ContainSubstring("lunch"))
+	})
+
+	It("still filters sensitive fields", func() {
+		serve(echo, "application/json", `{"password":"hunter2"}`)
+
+		Expect(logs.String()).NotTo(ContainSubstring("hunter2"))
+	})
+
+	It("filters bank account numbers", func() {
+		serve(echo, "application/json", `{"bank_code":"BCA","account_number":"1234567890"}`)
+
+		Expect(logs.String()).NotTo(ContainSubstring("1234567890"))
+	})
+	It("filters tokens from the query string", func() {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/approvals/act?token=abc.def&page=2", nil)
+		middleware.LoggingMiddleware(logger, cfg)(echo).ServeHTTP(httptest.NewRecorder(), req)
+
+		Expect(logs.String()).NotTo(ContainSubstring("abc.def"))
+		Expect(logs.String()).To(ContainSubstring("page=2"))
+	})
+
+	Describe("redaction policy", func() {
+		redact := func(policy middleware.RedactionPolicy) {
+			redactor, err := middleware.NewRedactor(policy)
+			Expect(err).NotTo(HaveOccurred())
+			cfg.Redactor = redactor
+		}
+
+		It("masks emails and account numbers in values", func() {
+			redact(middleware.RedactionPolicy{MaskEmails: true, MaskAccountNumbers: true})
+
+			serve(echo, "application/json", `{"note":"paid to jane@mail.com from 1234567890123","amount_idr":150000}`)
+
+			Expect(logs.String()).NotTo(ContainSubstring("jane@mail.com"))
+			Expect(logs.String()).To(ContainSubstring("j***@mail.com"))
+			Expect(logs.String()).NotTo(ContainSubstring("1234567890123"))
+			Expect(logs.String()).To(ContainSubstring("*********0123"))
+			Expect(logs.String()).To(ContainSubstring("150000"))
+		})
+
+		It("masks custom patterns and fields", func() {
+			redact(middleware.RedactionPolicy{Fields: []string{"phone"}, Patterns: []string{`NIK-\d+`}})
+
+			serve(echo, "application/json", `{"phone_number":"0812345","description":"NIK-3171 reimbursement"}`)
+
+			Expect(logs.String()).NotTo(ContainSubstring("0812345"))
+			Expect(logs.String()).NotTo(ContainSubstring("NIK-3171"))
+			Expect(logs.String()).To(ContainSubstring("reimbursement"))
+		})
+
+		It("allows and denies fields per route", func() {
+			redact(middleware.RedactionPolicy{Routes: []middleware.RouteRedaction{
+				{PathPrefix: "/api/v1", Deny: []string{"description"}},
+				{PathPrefix: "/api/v1/expenses", Allow: []string{"idempotency_key"}},
+			}})
+
+			serve(echo, "application/json", `{"idempotency_key":"k-42","description":"lunch"}`)
+
+			Expect(logs.String()).To(ContainSubstring("k-42"))
+			Expect(logs.String()).To(ContainSubstring("lunch"))
+
+			logs.Reset()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(`{"idempotency_key":"k-43","description":"travel"}`))
+			req.Header.Set("Content-Type", "application/json")
+			middleware.LoggingMiddleware(logger, cfg)(echo).ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(logs.String()).NotTo(ContainSubstring("k-43"))
+			Expect(logs.String()).NotTo(ContainSubstring("travel"))
+		})
+
+		It("rejects invalid patterns", func() {
+			_, err := middleware.NewRedactor(middleware.RedactionPolicy{Patterns: []string{"("}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("success sampling", func() {
+		BeforeEach(func() {
+			cfg.SuccessSampleRate = 0
+		})
+
+		It("drops requests answered with a 2xx", func() {
+			serve(echo, "application/json", `{"description":"lunch"}`)
+
+			Expect(logs.String()).To(BeEmpty())
+		})
+
+		It("still logs failed requests with their request line", func() {
+			failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			})
+
+			serve(failing, "application/json", `{"description":"lunch"}`)
+
+			Expect(logs.String()).To(ContainSubstring("incoming request"))
+			Expect(logs.String()).To(ContainSubstring("status_code=400"))
+		})
+	})
+})
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"net/http"
 	"runtime/debug"
+
+	pkglogger "github.com/frahmantamala/expense-management/pkg/logger"
 )
 
 // RecoveryMiddleware provides panic recovery with detailed logging
@@ -13,7 +15,7 @@ func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					logger.Error("panic recovered",
+					pkglogger.FromOr(r.Context(), logger).Error("panic recovered",
 						"error", err,
 						"method", r.Method,
 						"url", r.URL.String(),
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const filtered = "[FILTERED]"
+
+var (
+	emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+	// accountNumberPattern matches runs of 10 to 19 digits, the length of
+	// bank account and card numbers; IDs and amounts are shorter.
+	accountNumberPattern = regexp.MustCompile(`\b\d{10,19}\b`)
+)
+
+// RedactionPolicy extends the built-in sensitive fields, which are always
+// masked, with what a deployment considers sensitive.
+type RedactionPolicy struct {
+	// Fields are field name fragments masked on every route, matched like
+	// the built-in ones: a field is masked when its name contains one.
+	Fields []string
+	// MaskEmails keeps the first character and the domain of email
+	// addresses, e.g. j***@mail.com.
+	MaskEmails bool
+	// MaskAccountNumbers keeps the last four digits of account and card
+	// numbers.
+	MaskAccountNumbers bool
+	// Patterns are regular expressions whose matches are replaced with
+	// [FILTERED].
+	Patterns []string
+	Routes   []RouteRedaction
+}
+
+// RouteRedaction adjusts the policy for the routes under PathPrefix; only
+// the longest matching prefix applies.
+type RouteRedaction struct {
+	PathPrefix string
+	// Allow lists field names logged as-is on these routes although they
+	// match a sensitive field, e.g. idempotency_key.
+	Allow []string
+	// Deny lists field name fragments masked on these routes only.
+	Deny []string
+}
+
+// Redactor masks sensitive headers, query parameters and body fields, and
+// the values matching its patterns, before they are logged.
+type Redactor struct {
+	fields   []string
+	maskers  []func(string) string
+	routes   []RouteRedaction
+	fallback *fieldRules
+}
+
+// fieldRules is a Redactor narrowed to one route.
+type fieldRules struct {
+	fields  []string
+	allow   map[string]bool
+	maskers []func(string) string
+}
+
+// NewRedactor compiles policy; an invalid pattern is an error.
+func NewRedactor(policy RedactionPolicy) (*Redactor, error) {
+	r := &Redactor{fields: append(append([]string{}, sensitiveFields...), lowerAll(policy.Fields)...)}
+
+	if policy.MaskEmails {
+		r.maskers = append(r.maskers, func(s string) string {
+			return emailPattern.ReplaceAllString(s, "$1***@$2")
+		})
+	}
+	if policy.MaskAccountNumbers {
+		r.maskers = append(r.maskers, func(s string) string {
+			return accountNumberPattern.ReplaceAllStringFunc(s, func(digits string) string {
+				return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+			})
+		})
+	}
+	for _, pattern := range policy.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.maskers = append(r.maskers, func(s string) string {
+			return re.ReplaceAllString(s, filtered)
+		})
+	}
+
+	r.routes = append(r.routes, policy.Routes...)
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return len(r.routes[i].PathPrefix) > len(r.routes[j].PathPrefix)
+	})
+	r.fallback = &fieldRules{fields: r.fields, maskers: r.maskers}
+	return r, nil
+}
+
+// DefaultRedactor masks the built-in sensitive fields only.
+func DefaultRedactor() *Redactor {
+	r, _ := NewRedactor(RedactionPolicy{})
+	return r
+}
+
+func (r *Redactor) forPath(path string) *fieldRules {
+	for _, route := range r.routes {
+		if route.PathPrefix == "" || !strings.HasPrefix(path, route.PathPrefix) {
+			continue
+		}
+		rules := &fieldRules{
+			fields:  append(append([]string{}, r.fields...), lowerAll(route.Deny)...),
+			allow:   make(map[string]bool, len(route.Allow)),
+			maskers: r.maskers,
+		}
+		for _, name := range route.Allow {
+			rules.allow[strings.ToLower(name)] = true
+		}
+		return rules
+	}
+	return r.fallback
+}
+
+func (f *fieldRules) sensitive(name string) bool {
+	lowerName := strings.ToLower(name)
+	if f.allow[lowerName] {
+		return false
+	}
+	for _, field := range f.fields {
+		if strings.Contains(lowerName, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fieldRules) mask(s string) string {
+	for _, masker := range f.maskers {
+		s = masker(s)
+	}
+	return s
+}
+
+// headers masks sensitive headers and the values matching the patterns.
+func (f *fieldRules) headers(headers http.Header) map[string]string {
+	out := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if f.sensitive(name) {
+			out[name] = filtered
+		} else {
+			out[name] = f.mask(strings.Join(values, ", "))
+		}
+	}
+	return out
+}
+
+// query masks query parameters such as signed link tokens.
+func (f *fieldRules) query(query url.Values) string {
+	for name, values := range query {
+		if f.sensitive(name) {
+			query[name] = []string{filtered}
+			continue
+		}
+		for i, value := range values {
+			values[i] = f.mask(value)
+		}
+	}
+	return query.Encode()
+}
+
+// body masks sensitive fields of a JSON body, or filters a body that is not
+// JSON as text.
+func (f *fieldRules) body(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return f.text(string(body))
+	}
+
+	out, err := json.Marshal(f.json(data))
+	if err != nil {
+		return "[ERROR - Failed to marshal filtered JSON]"
+	}
+	return string(out)
+}
+
+// text filters non-JSON (or truncated JSON) bodies: one that mentions any
+// sensitive field name is dropped, since its values cannot be told apart.
+func (f *fieldRules) text(body string) string {
+	lower := strings.ToLower(body)
+	for _, field := range f.fields {
+		if strings.Contains(lower, field) {
+			return "[FILTERED - Contains sensitive data]"
+		}
+	}
+	return f.mask(body)
+}
+
+// json recursively masks sensitive fields and string values matching the
+// patterns.
+func (f *fieldRules) json(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if f.sensitive(key) {
+				out[key] = filtered
+			} else {
+				out[key] = f.json(value)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = f.json(item)
+		}
+		return out
+	case string:
+		return f.mask(v)
+	default:
+		return v
+	}
+}
+
+func lowerAll(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
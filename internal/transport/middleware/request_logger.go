@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/frahmantamala/expense-management/pkg/logger"
+	"github.com/go-chi/chi/middleware"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TraceIDHeader     = "X-Trace-ID"
+)
+
+// RequestLogger injects a logger carrying request_id and trace_id into the
+// request context. Handlers and services retrieve it with logger.From /
+// logger.FromOr; the auth middleware later adds user_id.
+func RequestLogger(base *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID := traceIDFromRequest(r)
+			w.Header().Set(TraceIDHeader, traceID)
+
+			l := base.With(
+				"request_id", middleware.GetReqID(r.Context()),
+				"trace_id", traceID,
+			)
+
+			ctx := logger.NewContext(r.Context(), l)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// traceIDFromRequest reads the trace id from a W3C traceparent header
+// (version-traceid-spanid-flags) or X-Trace-ID, generating one otherwise.
+func traceIDFromRequest(r *http.Request) string {
+	if tp := r.Header.Get(TraceparentHeader); tp != "" {
+		parts := strings.Split(tp, "-")
+		if len(parts) == 4 && len(parts[1]) == 32 {
+			return parts[1]
+		}
+	}
+
+	if id := strings.TrimSpace(r.Header.Get(TraceIDHeader)); id != "" && len(id) <= 64 {
+		return id
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return middleware.GetReqID(r.Context())
+	}
+	return hex.EncodeToString(b)
+}
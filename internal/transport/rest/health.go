@@ -37,14 +37,25 @@ func NewHealthHandler(db *sql.DB) *HealthHandler {
 	return &HealthHandler{db: db}
 }
 
-// HandleLiveness → just says service is up
+// pingHandler godoc
+// @Summary      Liveness probe
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Router       /ping [get]
 func (h *HealthHandler) pingHandler(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]string{"status": "OK"}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
-// HandleReadiness → checks DB connection
+// healthCheckHandler godoc
+// @Summary      Readiness probe
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Failure      503  {object}  HealthResponse
+// @Router       /health [get]
 func (h *HealthHandler) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 	defer cancel()
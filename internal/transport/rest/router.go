@@ -3,12 +3,37 @@ package rest
 import (
 	"database/sql"
 	"log/slog"
-	"net/http"
 
+	"github.com/frahmantamala/expense-management/internal/approvalaction"
+	"github.com/frahmantamala/expense-management/internal/approvalrouting"
 	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/bankaccount"
+	"github.com/frahmantamala/expense-management/internal/calendar"
+	"github.com/frahmantamala/expense-management/internal/cannedresponse"
+	"github.com/frahmantamala/expense-management/internal/capability"
+	"github.com/frahmantamala/expense-management/internal/cardfeed"
 	"github.com/frahmantamala/expense-management/internal/category"
+	"github.com/frahmantamala/expense-management/internal/changefeed"
+	"github.com/frahmantamala/expense-management/internal/dashboard"
+	"github.com/frahmantamala/expense-management/internal/delegation"
+	"github.com/frahmantamala/expense-management/internal/digest"
+	"github.com/frahmantamala/expense-management/internal/exchangerate"
 	"github.com/frahmantamala/expense-management/internal/expense"
+	"github.com/frahmantamala/expense-management/internal/expenseimport"
+	"github.com/frahmantamala/expense-management/internal/expensetemplate"
+	"github.com/frahmantamala/expense-management/internal/export"
+	"github.com/frahmantamala/expense-management/internal/integration"
+	"github.com/frahmantamala/expense-management/internal/ledger"
 	"github.com/frahmantamala/expense-management/internal/payment"
+	"github.com/frahmantamala/expense-management/internal/periodlock"
+	"github.com/frahmantamala/expense-management/internal/receipt"
+	"github.com/frahmantamala/expense-management/internal/report"
+	"github.com/frahmantamala/expense-management/internal/scim"
+	"github.com/frahmantamala/expense-management/internal/slack"
+	"github.com/frahmantamala/expense-management/internal/snapshot"
+	"github.com/frahmantamala/expense-management/internal/spendinglimit"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/internal/transport"
 	"github.com/frahmantamala/expense-management/internal/transport/middleware"
 	"github.com/frahmantamala/expense-management/internal/transport/swagger"
 	"github.com/frahmantamala/expense-management/internal/user"
@@ -16,7 +41,7 @@ import (
 	chiMiddleware "github.com/go-chi/chi/middleware"
 )
 
-func RegisterAllRoutes(router *chi.Mux, db *sql.DB, authHandler *auth.Handler, authService *auth.Service, userHandler *user.Handler, expenseHandler *expense.Handler, categoryHandler *category.Handler, paymentHandler *payment.Handler, webhookHandler *payment.WebhookHandler, logger *slog.Logger) {
+func RegisterAllRoutes(router *chi.Mux, db *sql.DB, authHandler *auth.Handler, authService *auth.Service, tenantHandler *tenant.Handler, userHandler *user.Handler, expenseHandler *expense.Handler, categoryHandler *category.Handler, paymentHandler *payment.Handler, webhookHandler *payment.WebhookHandler, paymentAdminHandler *payment.AdminHandler, digestHandler *digest.Handler, routingHandler *approvalrouting.Handler, dashboardHandler *dashboard.Handler, snapshotHandler *snapshot.Handler, receiptHandler *receipt.Handler, exportHandler *export.Handler, importHandler *expenseimport.Handler, limitHandler *spendinglimit.Handler, periodLockHandler *periodlock.Handler, exchangeRateHandler *exchangerate.Handler, calendarHandler *calendar.Handler, cannedResponseHandler *cannedresponse.Handler, ledgerHandler *ledger.Handler, changeFeedHandler *changefeed.Handler, cardFeedHandler *cardfeed.Handler, reportHandler *report.Handler, approvalActionHandler *approvalaction.Handler, slackHandler *slack.Handler, integrationHandler *integration.Handler, introspectionHandler *auth.IntrospectionHandler, impersonationHandler *auth.ImpersonationHandler, templateHandler *expensetemplate.Handler, bankAccountHandler *bankaccount.Handler, delegationHandler *delegation.Handler, settingsHandler *tenant.SettingsHandler, scimHandler *scim.Handler, capabilities *capability.Middleware, logCfg middleware.LogConfig, deadlineCfg middleware.DeadlineConfig, logger *slog.Logger) {
 	healthHandler := NewHealthHandler(db)
 
 	// Get RBAC authorization from auth service
@@ -24,79 +49,339 @@ func RegisterAllRoutes(router *chi.Mux, db *sql.DB, authHandler *auth.Handler, a
 
 	// Apply global middleware
 	router.Use(middleware.CORS)
+	router.Use(middleware.Locale)
 	router.Use(chiMiddleware.RequestID)
+	router.Use(middleware.RequestLogger(logger))
 	router.Use(middleware.RecoveryMiddleware(logger))
+	router.Use(middleware.LoggingMiddleware(logger, logCfg))
+	router.Use(middleware.Deadline(logger, deadlineCfg))
+	router.Use(tenantHandler.Middleware)
 
-	// Serve OpenAPI spec at root (outside API prefix)
-	router.Get("/openapi.yml", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./api/openapi.yml")
-	})
+	// Serve the spec generated from handler annotations at root (outside API prefix)
+	router.With(middleware.SkipBodyLogging).Handle("/openapi.yml", swagger.SpecHandler())
 	// Swagger UI route at root
-	router.Handle("/swagger/*", swagger.Handler())
-
-	// Mount API under /api/v1 to match OpenAPI basePath
-	router.Route("/api/v1", func(r chi.Router) {
-		// Health check route
-		r.Get("/health", healthHandler.healthCheckHandler)
-		r.Get("/ping", healthHandler.pingHandler)
-
-		if webhookHandler != nil {
-			r.Post("/payment/callback", webhookHandler.HandlePaymentCallback)
-		}
-
-		// Auth routes
-		if authHandler != nil {
-			r.Route("/auth", func(sr chi.Router) {
-				sr.Post("/login", authHandler.Login)
-				sr.Post("/refresh", authHandler.RefreshToken)
-				sr.Post("/logout", authHandler.Logout)
-			})
-		}
-
-		// Public categories route (no auth required)
-		if categoryHandler != nil {
-			r.Get("/categories", categoryHandler.GetCategories)
-		}
-
-		if authHandler != nil {
-			// Protected routes that require authentication
-			r.Group(func(pr chi.Router) {
-				pr.Use(authHandler.AuthMiddleware)
-
-				// Current user
-				if userHandler != nil {
-					pr.Get("/users/me", userHandler.GetCurrentUser)
+	router.With(middleware.SkipBodyLogging).Handle("/swagger/*", swagger.Handler())
+
+	// SCIM provisioning speaks its own protocol and is not versioned with the API
+	if scimHandler != nil {
+		router.Route("/scim/v2", scimHandler.Routes)
+	}
+
+	// Every API version shares the same handlers; responses are shaped per
+	// version by the handlers' response mappers. /api/v1 matches the OpenAPI basePath.
+	for _, version := range transport.SupportedAPIVersions {
+		router.Route("/api/"+string(version), func(r chi.Router) {
+			r.Use(transport.WithAPIVersion(version))
+			registerAPIRoutes(r, healthHandler, rbac, authHandler, userHandler, expenseHandler, categoryHandler, paymentHandler, webhookHandler, paymentAdminHandler, digestHandler, routingHandler, dashboardHandler, snapshotHandler, receiptHandler, exportHandler, importHandler, limitHandler, periodLockHandler, exchangeRateHandler, calendarHandler, cannedResponseHandler, ledgerHandler, changeFeedHandler, cardFeedHandler, reportHandler, approvalActionHandler, slackHandler, integrationHandler, introspectionHandler, impersonationHandler, templateHandler, bankAccountHandler, delegationHandler, settingsHandler, capabilities)
+		})
+	}
+}
+
+func registerAPIRoutes(r chi.Router, healthHandler *HealthHandler, rbac *auth.RBACAuthorization, authHandler *auth.Handler, userHandler *user.Handler, expenseHandler *expense.Handler, categoryHandler *category.Handler, paymentHandler *payment.Handler, webhookHandler *payment.WebhookHandler, paymentAdminHandler *payment.AdminHandler, digestHandler *digest.Handler, routingHandler *approvalrouting.Handler, dashboardHandler *dashboard.Handler, snapshotHandler *snapshot.Handler, receiptHandler *receipt.Handler, exportHandler *export.Handler, importHandler *expenseimport.Handler, limitHandler *spendinglimit.Handler, periodLockHandler *periodlock.Handler, exchangeRateHandler *exchangerate.Handler, calendarHandler *calendar.Handler, cannedResponseHandler *cannedresponse.Handler, ledgerHandler *ledger.Handler, changeFeedHandler *changefeed.Handler, cardFeedHandler *cardfeed.Handler, reportHandler *report.Handler, approvalActionHandler *approvalaction.Handler, slackHandler *slack.Handler, integrationHandler *integration.Handler, introspectionHandler *auth.IntrospectionHandler, impersonationHandler *auth.ImpersonationHandler, templateHandler *expensetemplate.Handler, bankAccountHandler *bankaccount.Handler, delegationHandler *delegation.Handler, settingsHandler *tenant.SettingsHandler, capabilities *capability.Middleware) {
+	// Health check route
+	r.Get("/health", healthHandler.healthCheckHandler)
+	r.Get("/ping", healthHandler.pingHandler)
+
+	if webhookHandler != nil {
+		r.Post("/payment/callback", webhookHandler.HandlePaymentCallback)
+	}
+
+	// Auth routes
+	if authHandler != nil {
+		r.Route("/auth", func(sr chi.Router) {
+			sr.Post("/login", authHandler.Login)
+			sr.Post("/refresh", authHandler.RefreshToken)
+			sr.With(middleware.SkipBodyLogging).Post("/token", authHandler.Token)
+			sr.Post("/logout", authHandler.Logout)
+			// Sibling services authenticate with client credentials
+			if introspectionHandler != nil {
+				sr.With(middleware.SkipBodyLogging).Post("/introspect", introspectionHandler.Introspect)
+			}
+		})
+	}
+
+	// Email approval links carry their own signed token instead of a bearer token
+	if approvalActionHandler != nil {
+		r.Get("/approvals/act", approvalActionHandler.Act)
+	}
+
+	// Shared links carry a capability token for one resource instead of a bearer token
+	if capabilities != nil {
+		r.Route("/shared", func(sr chi.Router) {
+			if receiptHandler != nil {
+				sr.With(capabilities.Require(capability.ScopeReceiptView, "id")).Get("/receipts/{id}", receiptHandler.OpenSharedReceipt)
+			}
+			if exportHandler != nil {
+				sr.With(capabilities.Require(capability.ScopeExportDownload, "id")).Get("/exports/{id}", exportHandler.DownloadSharedExport)
+			}
+		})
+	}
+
+	// Slack signs its requests instead of sending a bearer token
+	if slackHandler != nil {
+		r.Route("/slack", func(sr chi.Router) {
+			sr.Use(middleware.SkipBodyLogging)
+			sr.Post("/commands", slackHandler.Command)
+			sr.Post("/interactions", slackHandler.Interaction)
+		})
+	}
+
+	// Workflow engines sign their requests and authenticate with an api key
+	if integrationHandler != nil {
+		r.Post("/integrations/approvals", integrationHandler.Decide)
+	}
+
+	// Public categories route (no auth required)
+	if categoryHandler != nil {
+		r.Get("/categories", categoryHandler.GetCategories)
+	}
+
+	if authHandler != nil {
+		// Protected routes that require authentication
+		r.Group(func(pr chi.Router) {
+			pr.Use(authHandler.AuthMiddleware)
+			if impersonationHandler != nil {
+				pr.Use(impersonationHandler.Guard)
+				pr.With(rbac.RequireAdmin()).Post("/admin/users/{id}/impersonate", impersonationHandler.Impersonate)
+			}
+
+			// Current user
+			if userHandler != nil {
+				pr.Get("/users/me", userHandler.GetCurrentUser)
+				pr.Get("/users/me/avatar", userHandler.GetAvatar)
+				pr.Group(func(ur chi.Router) {
+					ur.Use(rbac.DenyImpersonation())
+					ur.Patch("/users/me", userHandler.UpdateCurrentUser)
+					ur.Put("/users/me/password", userHandler.ChangePassword)
+					ur.Put("/users/me/avatar", userHandler.UploadAvatar)
+					ur.Delete("/users/me/avatar", userHandler.DeleteAvatar)
+				})
+			}
+
+			if digestHandler != nil {
+				pr.Get("/users/me/digest-preferences", digestHandler.GetPreferences)
+				pr.Put("/users/me/digest-preferences", digestHandler.UpdatePreferences)
+			}
+
+			if bankAccountHandler != nil {
+				pr.Route("/users/me/bank-accounts", func(br chi.Router) {
+					br.Get("/", bankAccountHandler.ListAccounts)
+					br.With(rbac.DenyImpersonation()).Post("/", bankAccountHandler.CreateAccount)
+					br.With(rbac.DenyImpersonation()).Delete("/{id}", bankAccountHandler.DeleteAccount)
+					br.With(rbac.DenyImpersonation()).Put("/{id}/default", bankAccountHandler.SetDefaultAccount)
+				})
+				pr.Group(func(ar chi.Router) {
+					ar.Use(rbac.RequireAdmin())
+					ar.Get("/admin/users/{id}/bank-accounts", bankAccountHandler.ListUserAccounts)
+					ar.Put("/admin/bank-accounts/{id}/verification", bankAccountHandler.VerifyAccount)
+				})
+			}
+
+			if delegationHandler != nil {
+				pr.Get("/users/me/principals", delegationHandler.ListPrincipals)
+				pr.Route("/users/me/delegates", func(dr chi.Router) {
+					dr.Get("/", delegationHandler.ListDelegates)
+					dr.With(rbac.DenyImpersonation()).Post("/", delegationHandler.GrantDelegate)
+					dr.With(rbac.DenyImpersonation()).Delete("/{id}", delegationHandler.RevokeDelegate)
+				})
+			}
+
+			if dashboardHandler != nil {
+				pr.Get("/dashboard", dashboardHandler.GetDashboard)
+				pr.With(rbac.RequireAdmin()).Get("/admin/stats", dashboardHandler.GetOpsStats)
+				if snapshotHandler != nil {
+					pr.With(rbac.RequireAdmin()).Get("/admin/stats/daily", snapshotHandler.GetDailyStats)
 				}
+				pr.With(rbac.RequireAdmin()).Get("/admin/diagnostics/events", dashboardHandler.GetEventBusStats)
+			}
 
-				// Expense routes
-				if expenseHandler != nil {
-					pr.Route("/expenses", func(er chi.Router) {
-						// User expense routes
-						er.Post("/", expenseHandler.CreateExpense) // POST /expenses
-						er.Get("/", expenseHandler.GetAllExpenses) // GET /expenses
-						er.Get("/{id}", expenseHandler.GetExpense) // GET /expenses/:id
-
-						// Manager routes with permission protection
-						er.Group(func(mr chi.Router) {
-							mr.Use(rbac.RequireApproveExpense())
-							mr.Patch("/{id}/approve", expenseHandler.ApproveExpense) // PATCH /expenses/:id/approve
-						})
+			// Expense routes
+			if expenseHandler != nil {
+				pr.Route("/expenses", func(er chi.Router) {
+					// User expense routes
+					er.Post("/", expenseHandler.CreateExpense) // POST /expenses
+					er.Get("/", expenseHandler.GetAllExpenses) // GET /expenses
+					er.Get("/{id}", expenseHandler.GetExpense) // GET /expenses/:id
 
-						er.Group(func(mr chi.Router) {
-							mr.Use(rbac.RequireRejectExpense())
-							mr.Patch("/{id}/reject", expenseHandler.RejectExpense) // PATCH /expenses/:id/reject
-						})
+					// Manager routes with permission protection
+					er.Group(func(mr chi.Router) {
+						mr.Use(rbac.RequireApproveExpense(), rbac.DenyImpersonation())
+						mr.Patch("/{id}/approve", expenseHandler.ApproveExpense) // PATCH /expenses/:id/approve
 					})
-				}
 
-				// Payment routes (requires retry_payments permission)
-				if paymentHandler != nil {
-					pr.Group(func(pmr chi.Router) {
-						pmr.Use(rbac.RequireRetryPayment())
-						pmr.Post("/payment/retry", paymentHandler.RetryPayment) // POST /payment/retry
+					er.Group(func(mr chi.Router) {
+						mr.Use(rbac.RequireRejectExpense())
+						mr.With(rbac.DenyImpersonation()).Patch("/{id}/reject", expenseHandler.RejectExpense) // PATCH /expenses/:id/reject
+						if cannedResponseHandler != nil {
+							mr.Get("/rejection-reasons", cannedResponseHandler.ListRejectionReasons) // GET /expenses/rejection-reasons
+						}
 					})
+
+					if importHandler != nil {
+						er.Group(func(ar chi.Router) {
+							ar.Use(rbac.RequireAdmin())
+							ar.Post("/import", importHandler.ImportExpenses) // POST /expenses/import
+							ar.Get("/import/{id}", importHandler.GetImport)  // GET /expenses/import/:id
+						})
+					}
+
+					if receiptHandler != nil {
+						er.Put("/{id}/receipt", receiptHandler.UploadReceipt)               // PUT /expenses/:id/receipt
+						er.Get("/{id}/receipt", receiptHandler.GetReceipt)                  // GET /expenses/:id/receipt
+						er.Post("/{id}/receipt/upload-url", receiptHandler.CreateUploadURL) // POST /expenses/:id/receipt/upload-url
+						er.Post("/{id}/receipt/confirm", receiptHandler.ConfirmUpload)      // POST /expenses/:id/receipt/confirm
+						er.Post("/{id}/receipt/share", receiptHandler.ShareReceipt)         // POST /expenses/:id/receipt/share
+					}
+				})
+			}
+
+			if templateHandler != nil {
+				pr.Route("/expense-templates", func(tr chi.Router) {
+					tr.Get("/", templateHandler.ListTemplates)
+					tr.Post("/", templateHandler.CreateTemplate)
+					tr.Put("/{id}", templateHandler.UpdateTemplate)
+					tr.Delete("/{id}", templateHandler.DeleteTemplate)
+					tr.Post("/{id}/expenses", templateHandler.CreateExpenseFromTemplate)
+				})
+			}
+
+			if exportHandler != nil {
+				pr.Post("/exports", exportHandler.CreateExport)
+				pr.Get("/exports/{id}", exportHandler.GetExport)
+				pr.Post("/exports/{id}/share", exportHandler.ShareExport)
+			}
+
+			if routingHandler != nil {
+				pr.Route("/admin/approval-routes", func(ar chi.Router) {
+					ar.Use(rbac.RequireAdmin())
+					ar.Get("/", routingHandler.ListRules)
+					ar.Put("/{category}", routingHandler.SaveRule)
+					ar.Delete("/{category}", routingHandler.DeleteRule)
+				})
+				pr.Route("/admin/approval-matrix", func(mr chi.Router) {
+					mr.Use(rbac.RequireAdmin())
+					mr.Get("/", routingHandler.ExportMatrix)
+					mr.Put("/", routingHandler.ImportMatrix)
+				})
+			}
+
+			if settingsHandler != nil {
+				pr.Route("/admin/tenant/settings", func(sr chi.Router) {
+					sr.Use(rbac.RequireAdmin())
+					sr.Get("/", settingsHandler.GetSettings)
+					sr.Put("/", settingsHandler.UpdateSettings)
+					sr.Delete("/{key}", settingsHandler.ResetSetting)
+				})
+			}
+
+			if reportHandler != nil {
+				pr.Route("/admin/report-schedules", func(rr chi.Router) {
+					rr.Use(rbac.RequireAdmin())
+					rr.Get("/", reportHandler.ListSchedules)
+					rr.Post("/", reportHandler.CreateSchedule)
+					rr.Put("/{id}", reportHandler.UpdateSchedule)
+					rr.Delete("/{id}", reportHandler.DeleteSchedule)
+					rr.Post("/{id}/send", reportHandler.SendSchedule)
+				})
+				pr.With(rbac.RequireAdmin()).Get("/reports/approval-sla", reportHandler.GetApprovalSLA)
+				pr.With(rbac.RequireViewAllPayments()).Get("/reports/cash-flow", reportHandler.GetCashFlow)
+			}
+
+			if limitHandler != nil {
+				pr.Route("/admin/users/{id}/spending-limit-overrides", func(lr chi.Router) {
+					lr.Use(rbac.RequireAdmin())
+					lr.Get("/", limitHandler.ListOverrides)
+					lr.Post("/", limitHandler.GrantOverride)
+				})
+			}
+
+			if periodLockHandler != nil {
+				pr.Route("/admin/period-locks", func(lr chi.Router) {
+					lr.Use(rbac.RequireClosePeriods(), rbac.DenyImpersonation())
+					lr.Get("/", periodLockHandler.ListLocks)
+					lr.Put("/{period}", periodLockHandler.LockPeriod)
+					lr.Delete("/{period}", periodLockHandler.UnlockPeriod)
+				})
+			}
+
+			if exchangeRateHandler != nil {
+				pr.Route("/admin/exchange-rates", func(er chi.Router) {
+					er.Use(rbac.RequireAdmin())
+					er.Get("/", exchangeRateHandler.ListRates)                // GET /admin/exchange-rates?date=
+					er.Put("/{date}/{currency}", exchangeRateHandler.SetRate) // PUT /admin/exchange-rates/{date}/{currency}
+				})
+			}
+
+			if calendarHandler != nil {
+				pr.Route("/admin/calendar", func(cr chi.Router) {
+					cr.Use(rbac.RequireAdmin())
+					cr.Get("/", calendarHandler.GetCalendar)             // GET /admin/calendar?year=
+					cr.Put("/days/{date}", calendarHandler.SetDay)       // PUT /admin/calendar/days/{date}
+					cr.Delete("/days/{date}", calendarHandler.DeleteDay) // DELETE /admin/calendar/days/{date}
+				})
+				pr.Get("/calendar/working-days", calendarHandler.WorkingDays) // GET /calendar/working-days?from=&to=
+			}
+
+			if cannedResponseHandler != nil {
+				pr.Route("/admin/canned-responses", func(cr chi.Router) {
+					cr.Use(rbac.RequireAdmin())
+					cr.Get("/", cannedResponseHandler.ListResponses)
+					cr.Post("/", cannedResponseHandler.CreateResponse)
+					cr.Put("/{id}", cannedResponseHandler.UpdateResponse)
+					cr.Delete("/{id}", cannedResponseHandler.ArchiveResponse)
+				})
+			}
+
+			if ledgerHandler != nil {
+				pr.Route("/ledger", func(lr chi.Router) {
+					lr.Use(rbac.RequireViewAllPayments())
+					lr.Get("/", ledgerHandler.ListEntries)             // GET /ledger
+					lr.Get("/reconciliation", ledgerHandler.Reconcile) // GET /ledger/reconciliation
+				})
+			}
+
+			if changeFeedHandler != nil {
+				pr.Route("/admin/changes", func(cr chi.Router) {
+					cr.Use(rbac.RequireAdmin())
+					cr.Get("/", changeFeedHandler.ListChanges)                  // GET /admin/changes
+					cr.Get("/consumers/{name}", changeFeedHandler.GetConsumer)  // GET /admin/changes/consumers/:name
+					cr.Put("/consumers/{name}", changeFeedHandler.CommitOffset) // PUT /admin/changes/consumers/:name
+				})
+			}
+
+			if cardFeedHandler != nil {
+				pr.Route("/card-transactions", func(cr chi.Router) {
+					cr.Use(rbac.RequireReconcileCards())
+					cr.Get("/", cardFeedHandler.ListTransactions)        // GET /card-transactions
+					cr.Post("/", cardFeedHandler.ImportTransactions)     // POST /card-transactions
+					cr.Post("/import", cardFeedHandler.ImportCSV)        // POST /card-transactions/import
+					cr.Post("/match", cardFeedHandler.MatchTransactions) // POST /card-transactions/match
+				})
+			}
+
+			// Payment routes (requires retry_payments permission)
+			if paymentHandler != nil {
+				pr.Get("/payments/jobs/{external_id}", paymentHandler.GetPaymentJob) // GET /payments/jobs/:external_id
+
+				pr.Group(func(pmr chi.Router) {
+					pmr.Use(rbac.RequireRetryPayment(), rbac.DenyImpersonation())
+					pmr.Post("/payment/retry", paymentHandler.RetryPayment) // POST /payment/retry
+				})
+			}
+
+			if paymentAdminHandler != nil {
+				pr.Route("/admin/payments/{id}", func(ar chi.Router) {
+					ar.Use(rbac.RequireAdmin())
+					ar.Post("/requeue", paymentAdminHandler.RequeuePayment) // POST /admin/payments/:id/requeue
+					ar.Post("/cancel", paymentAdminHandler.CancelPayment)   // POST /admin/payments/:id/cancel
+					if paymentAdminHandler.GatewayCalls != nil {
+						ar.Get("/gateway-calls", paymentAdminHandler.ListGatewayCalls) // GET /admin/payments/:id/gateway-calls
+					}
+				})
+				if paymentAdminHandler.Webhooks != nil {
+					pr.With(rbac.RequireAdmin()).Post("/admin/webhooks/{id}/replay", paymentAdminHandler.ReplayWebhook) // POST /admin/webhooks/:id/replay
 				}
-			})
-		}
-	})
+			}
+		})
+	}
 }
@@ -0,0 +1,15 @@
+// Package swagger serves the Swagger UI and the OpenAPI document generated
+// from the handler annotations. Regenerate with `go generate ./...` or
+// `make swagger` after changing handlers or DTOs.
+//
+// @title                       Expense Management API
+// @version                     1.0.0
+// @description                 Expense submission, approval and payment API.
+// @BasePath                    /api/v1
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Bearer access token, e.g. "Bearer eyJ..."
+package swagger
+
+//go:generate go run github.com/swaggo/swag/cmd/swag init --dir ../../.. --generalInfo internal/transport/swagger/doc.go --output . --outputTypes go,json,yaml --parseInternal
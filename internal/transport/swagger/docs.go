@@ -0,0 +1,8891 @@
+// Code generated by swaggo/swag. DO NOT EDIT.
+
+package swagger
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/admin/approval-matrix": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads the tenant's routing rules, category amount limits and tenant-wide approval chain and thresholds as one file, in the layout PUT /admin/approval-matrix reads. In CSV the tenant-wide policy is the row whose category is *, with the approval chain space-separated in approver_permission.",
+                "produces": [
+                    "application/yaml",
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export the approval matrix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "yaml (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approvalrouting.Matrix"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the tenant's routing rules and category amount limits with the file in the body, in the layout GET /admin/approval-matrix exports; categories left out lose their rule and limits. The tenant-wide approval chain and thresholds are replaced too when the file has them. The whole file is checked first: if any row has a problem nothing changes and every problem is listed in the error details. With dry_run=true nothing changes and the report says what would.",
+                "consumes": [
+                    "application/yaml",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Import the approval matrix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "yaml (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate only",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approvalrouting.MatrixReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/approval-routes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List approval routing rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approvalrouting.RulesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/approval-routes/{category}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses in the category and its subcategories can only be approved or rejected by holders of approver_permission (or admins). require_approval disables auto-approval regardless of amount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or replace a category's approval routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category name",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/approvalrouting.SaveRuleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_approvalrouting.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove a category's approval routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category name",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/bank-accounts/{id}/verification": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only verified accounts are paid into. The verifying admin and time are recorded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Verify or reject a bank account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Verification decision",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bankaccount.VerifyAccountDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_bankaccount.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/calendar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The weekend, and the holidays of the year: Indonesia's national holidays, less those the tenant works on, and the tenant's own. Working days are counted on this calendar. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Show the working calendar of a year",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Year; the current one when omitted",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.CalendarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/calendar/days/{date}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "kind holiday adds a day off, such as collective leave or a company holiday; working_day makes a weekend day or national holiday worked. Replaces the day's previous override. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Make a day a holiday or a working day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day, YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Override",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/calendar.SetDayDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_calendar.Day"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The day is then worked or not as the weekend and national holidays say. Admin only.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove a day's override",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day, YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/canned-responses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List canned responses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cannedresponse.ResponsesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approvers reject with it by passing canned_response_id to PATCH /expenses/{id}/reject.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create a canned response",
+                "parameters": [
+                    {
+                        "description": "Canned response",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/cannedresponse.ResponseDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_cannedresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/canned-responses/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses already rejected with it keep the comment they were rejected with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace a canned response",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Canned response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Canned response",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/cannedresponse.ResponseDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_cannedresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approvers can no longer pick it; expenses rejected with it keep its ID.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Archive a canned response",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Canned response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Inserts and updates of expenses and payments, oldest first, for warehouses to sync incrementally. Pass after, or consumer to resume from its committed offset; then commit next_offset with PUT /admin/changes/consumers/{name}. Changes appear about 10 seconds after they are written. Requires admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Read the change feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consumer whose committed offset to resume from",
+                        "name": "consumer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return changes with an ID above this; overrides consumer",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Changes per page, at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/changefeed.ChangesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/changes/consumers/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a change consumer's offset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consumer name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_changefeed.Consumer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates the consumer on its first commit. A lower offset than the current one replays the changes after it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Commit a change consumer's offset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consumer name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID of the last change stored",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/changefeed.CommitDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_changefeed.Consumer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/diagnostics/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Event types on this instance's event bus with how often each was published, the handlers subscribed to it, and per handler the calls, failures and last error. Counts start at zero when the instance starts and are not tenant scoped. Use it to see why an event such as payment.completed did not update an expense. Requires admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Event bus diagnostics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.BusStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange-rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Each currency's rate into IDR for the day, or its latest earlier one. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List exchange rates in effect on a day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day, YYYY-MM-DD; today when omitted",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/exchangerate.RatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange-rates/{date}/{currency}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets what one unit of the currency is worth in IDR on the day. Expenses already converted keep the rate they were converted at. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set or override a daily exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day, YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rate",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/exchangerate.SetRateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_exchangerate.Rate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels a pending payment that has not reached the gateway and fails it so its owner can retry. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Cancel stuck payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the payment is cancelled",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentJobActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.JobActionResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/gateway-calls": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the requests sent to the payment gateway about a payment and the gateway's answers, newest first and at most 200, with credentials and account numbers redacted. For disputing a charge with the provider. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "List gateway calls of payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payment.GatewayCallView"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/requeue": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends a pending payment whose job failed, was dead-lettered or has been queued or processing for 15 minutes back through the gateway queue. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Requeue stuck payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the payment is requeued",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentJobActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.JobActionResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/period-locks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List locked periods",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodlock.LocksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/period-locks/{period}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses dated in the month can no longer be created, imported or have receipts attached; those changes fail with PERIOD_LOCKED. Locking a locked month returns the existing lock. Requires close_periods or admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lock a month for month-end close",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month, YYYY-MM",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lock",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodlock.LockPeriodDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_periodlock.Lock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires close_periods or admin.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unlock a month",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month, YYYY-MM",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/report-schedules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List scheduled reports",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.SchedulesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Emails the report to every recipient at hour (UTC): daily, weekly on weekday (0 is Sunday) or monthly on the 1st. Each run covers the previous day, seven days or calendar month. report_type is spend_by_department or spend_by_category and format is xlsx or csv. Requires admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Schedule a report",
+                "parameters": [
+                    {
+                        "description": "Schedule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/report.ScheduleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_report.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/report-schedules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The next run is worked out again from the new timing. Requires admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace a scheduled report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/report.ScheduleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_report.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires admin.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a scheduled report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/report-schedules/{id}/send": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends the report for the period before now without moving the next scheduled run. The outcome is recorded in last_run_at and last_error. Requires admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Send a scheduled report now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_report.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Payments created in the window by current status, expenses submitted in the window with the auto-approval rate and the average time to a manual decision, and this instance's payment queue. Covers the admin's tenant, except the queue, which is shared. Requires admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Operational stats for the ops dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Window in days, 30 by default and at most 365",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dashboard.OpsStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/daily": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses submitted each day by category, submitter department and status, and payments created each day with their success rate, as written by the nightly daily_snapshot job. A day's figures stay as they were when it was snapshotted. Covers the admin's tenant. Requires admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Daily spend and payment facts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD; 30 days before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD; yesterday by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/snapshot.TrendsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenant/settings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The effective settings of the caller's tenant. overridden lists the keys set for the tenant; the others are server defaults.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Current tenant's settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tenant.SettingsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Omitted fields keep their current value. approval_chain entries must be existing permissions; an empty list lets any approver decide. max_pending_expenses_by_department and expense_amount_limits_by_category replace the whole map; a category bound of 0 keeps the tenant-wide one, and no maximum claim amount may be below its minimum.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Override the current tenant's settings",
+                "parameters": [
+                    {
+                        "description": "Settings to override",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/tenant.UpdateSettingsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tenant.SettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenant/settings/{key}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Restore the server default of a tenant setting",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Setting key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/bank-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List a user's bank accounts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bankaccount.AccountsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a short-lived access token acting as another active, non-admin user of the caller's tenant, for support. The token carries an impersonator_id claim and cannot be refreshed. Every response to it carries X-Impersonator-ID so clients can show a banner. Impersonation tokens cannot make DELETE requests, approve or reject expenses, retry payments, close periods, or change the user's password, profile, avatar or bank accounts. Starting an impersonation and every request made with the token are audit-logged; the reason is required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the user is impersonated",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ImpersonateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Impersonation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/spending-limit-overrides": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List a user's spending limit overrides",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/spendinglimit.OverridesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds amount_idr to the user's daily or monthly limit for the period containing date. The granting admin and reason are recorded. Overrides for the same period add up.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Raise a user's spending limit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Override",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/spendinglimit.GrantOverrideDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_spendinglimit.Override"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Applies a gateway callback from the webhook inbox to its payment again, as when it was handled wrongly before a fix. Safe to repeat. Refused while the callback is still waiting to be processed, or when a newer callback for the same payment has arrived. Only available with the webhook inbox enabled. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Replay stored payment webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook inbox ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the webhook is replayed",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.WebhookReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.WebhookReplayResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/approvals/act": {
+            "get": {
+                "description": "Follows a signed link from a notification email. The token identifies the expense, the approver and the decision; it expires and works only once. No bearer token is needed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Approve or reject an expense from an email link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed action token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/approvalaction.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/introspect": {
+            "post": {
+                "description": "RFC 7662 token introspection for sibling services. The client authenticates with HTTP Basic auth using its client_id and client_secret and posts the token as a form field. An active token's answer carries the user, tenant and permissions and may be cached as Cache-Control says, never past the token's expiry. Invalid, expired, revoked and foreign tokens all answer {\"active\": false}, which must not be cached.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Introspect an access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic base64(client_id:client_secret)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Introspection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Exchanges email and password for an access and refresh token pair.\nAfter repeated failures the login answers 428 until a CAPTCHA token is sent in captcha_token, and 429 with Retry-After once the client IP is locked out.\nEach successful login is recorded with the client's IP, user agent and location; a login from a new device or location emails the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "Credentials",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RefreshTokenDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token": {
+            "post": {
+                "description": "OAuth2 token endpoint (RFC 6749) for standard clients. Only the refresh_token grant is supported: it answers a new access and refresh token pair with the access token's lifetime in expires_in. The request may be a form or JSON. Errors use the OAuth2 shape: invalid_request, unsupported_grant_type, or invalid_grant for a refresh token that is invalid, expired or belongs to an inactive user.",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "OAuth2 token exchange",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "refresh_token",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.OAuthToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/auth.OAuthError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/auth.OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar/working-days": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "How many of the days from from to to, both included, are worked in the caller's tenant, such as the days of a trip a per-diem is paid for. At most 366 days.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Count working days",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.WorkingDaysResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/card-transactions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Newest charge first. Use status=unmatched to see charges no expense has been matched to. Requires reconcile_cards or admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "card-transactions"
+                ],
+                "summary": "List card transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unmatched or matched",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (max 100)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cardfeed.TransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "For issuers that deliver their feed through an API. Same rules as the CSV import; errors name the 1-based position of the transaction in the list. Requires reconcile_cards or admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "card-transactions"
+                ],
+                "summary": "Push card transactions",
+                "parameters": [
+                    {
+                        "description": "Transactions",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/cardfeed.ImportTransactionsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cardfeed.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/card-transactions/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The file needs the columns transaction_id, cardholder_email, merchant, amount_idr and transaction_date (YYYY-MM-DD); card_last4 is optional and other columns are ignored. Transactions already imported under the same transaction_id are counted as duplicates and left as they are. New transactions are matched to the cardholder's expenses straight away. Requires reconcile_cards or admin.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "card-transactions"
+                ],
+                "summary": "Import a card feed from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cardfeed.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/card-transactions/match": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tries every unmatched transaction against the cardholder's expenses again, picking up expenses submitted since the feed was imported. Requires reconcile_cards or admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "card-transactions"
+                ],
+                "summary": "Match unmatched card transactions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cardfeed.MatchReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Returns a flat list, or nested parent/child categories with tree=true. Expenses can only use leaf categories.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "List categories",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Return categories nested under their parents",
+                        "name": "tree",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant slug; defaults to the default tenant",
+                        "name": "X-Tenant",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/category.CategoryTreeResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Counts of the current user's expenses by status, month-to-date approved spend and recent payment failures. Approvers also get the pending approvals they can act on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Expense summary dashboard",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dashboard.Dashboard"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expense-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expense-templates"
+                ],
+                "summary": "List my expense templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/expensetemplate.TemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "category must be a leaf category. amount_idr may be left out for claims whose amount changes each time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expense-templates"
+                ],
+                "summary": "Create an expense template",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/expensetemplate.TemplateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expensetemplate.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expense-templates/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expense-templates"
+                ],
+                "summary": "Replace an expense template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/expensetemplate.TemplateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expensetemplate.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses already created from the template are not affected.",
+                "tags": [
+                    "expense-templates"
+                ],
+                "summary": "Delete an expense template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expense-templates/{id}/expenses": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submits an expense with the template's category, amount and description, dated today (UTC). Any field in the body overrides the template; amount_idr is required when the template has none. The expense is checked exactly like one created through POST /expenses.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expense-templates"
+                ],
+                "summary": "Create an expense from a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Overrides",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/expensetemplate.UseTemplateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expense.Expense"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the expenses visible to the caller: own, team or all depending on permissions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "List expenses",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search over description and category; supports quoted phrases and -exclusions",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expense status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest expense date, YYYY-MM-DD",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest expense date, YYYY-MM-DD (inclusive)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount in IDR",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount in IDR",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "createdAt, submittedAt, amount or relevance (default when searching)",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payment adds each expense's latest payment (status, retry_count, processed_at)",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/expense.ExpenseListV1"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses below the approval threshold are auto-approved and paid asynchronously. Expenses that would take the user past a daily or monthly spending limit fail with LIMIT_EXCEEDED, expenses above the tenant's receipt threshold without a receipt_url fail with RECEIPT_REQUIRED, and expenses dated in a locked month fail with PERIOD_LOCKED.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Create expense",
+                "parameters": [
+                    {
+                        "description": "Expense",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/expense.CreateExpenseDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expense.Expense"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin only. The file needs the columns user_email, amount_idr, description, category and expense_date (YYYY-MM-DD); other columns are ignored. Each row becomes an expense of that user and goes through the usual validation, limits and approval. Rows that fail are reported and skipped. With dry_run=true nothing is created and the report says what would happen. Small files are imported within the request (200); larger ones are queued (202) and their progress is polled with GET /expenses/import/{id}.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Import expenses from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate only",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/expenseimport.Report"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expenseimport.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/import/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports rows processed so far and, once completed, the rows that failed (at most 100 are listed).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Get import progress",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expenseimport.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/rejection-reasons": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The rejection codes and the tenant's canned responses, for approvers to pick from when rejecting. Requires reject_expenses or admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "List rejection reasons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cannedresponse.RejectionReasonsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Visible to the owner, team managers and users who can view all expenses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Get expense",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expense.Expense"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}/approve": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The body is optional. With approved_amount_idr the manager approves less than was claimed: a reason is required, the expense is paid the reduced amount, and the submitter sees both amounts and the reason on the expense as amount_idr, approved_amount_idr and adjustment_reason. approved_amount_idr may not exceed amount_idr. Fails with LIMIT_EXCEEDED when approval would take the owner's approved spend past a daily or monthly limit, and with RECEIPT_REQUIRED when the expense is above the tenant's receipt threshold without a receipt.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Approve expense",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reduced amount",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/expense.ApproveExpenseDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a short-lived download URL for the expense's receipt. Visible to anyone who can view the expense. With size=thumb the URL is for a small JPEG preview instead: the receipt scaled down, or the first page of a PDF. Previews are rendered in the background shortly after upload; until then this fails with RECEIPT_PREVIEW_NOT_READY, and for receipts that cannot be previewed with RECEIPT_PREVIEW_UNAVAILABLE.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Get expense receipt link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "thumb"
+                        ],
+                        "type": "string",
+                        "description": "Preview instead of the original",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/receipt.ReceiptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attaches a JPEG, PNG or PDF (at most 10 MB) to the caller's own expense, replacing any earlier receipt. Fails with PERIOD_LOCKED when the expense's month is locked.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Upload expense receipt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Receipt file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/receipt.ReceiptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}/receipt/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks the file uploaded to the URL from POST /expenses/{id}/receipt/upload-url and attaches it to the expense, replacing any earlier receipt. The file must be at most 10 MB and its contents must match the declared content_type; otherwise it is deleted and the request fails.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Attach a directly uploaded receipt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upload to attach",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/receipt.ConfirmUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/receipt.ReceiptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}/receipt/share": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a short-lived link that shows the receipt to anyone who follows it, without a bearer token. The link only grants viewing this receipt. Visible to anyone who can view the expense.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Share expense receipt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/capability.Link"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}/receipt/upload-url": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a short-lived URL the client PUTs the receipt file to, straight to storage, for photos too large to send through the API comfortably. content_type is image/jpeg, image/png or application/pdf. The file is attached only once confirmed with POST /expenses/{id}/receipt/confirm. Only the expense owner may upload; fails with PERIOD_LOCKED when the expense's month is locked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Get a direct receipt upload URL",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Receipt type",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/receipt.UploadURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/receipt.UploadURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}/reject": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "A reason code (policy_violation, missing_receipt, duplicate or other) and a comment are required; both are stored on the expense and returned as rejection_code and rejection_reason. Alternatively canned_response_id takes both from one of the tenant's canned responses, with reason added below its comment when given; the ID is returned as canned_response_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Reject expense",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rejection reason",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/expense.RejectExpenseDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a CSV or JSONL export of expenses (by expense date) or payments (by creation date). The requester is emailed when it is ready. Requires permission to view all expenses.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Start an export",
+                "parameters": [
+                    {
+                        "description": "Export request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/export.CreateJobDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_export.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Completed exports include a signed download_url valid until url_expires_at; the file itself is deleted at expires_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Get export status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_export.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}/share": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a short-lived link that downloads the requester's completed export for anyone who follows it, without a bearer token. The link only grants downloading this export and stops working once the file is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Share an export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/capability.Link"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/rest.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/integrations/approvals": {
+            "post": {
+                "description": "Approves or rejects an expense as the integration's service account, with that account's current permissions. Requests carry the integration's api_key as a bearer token, an X-Signature of \"sha256=\" + hex(HMAC-SHA256(signing_secret, timestamp + \".\" + body)) with the Unix timestamp in X-Signature-Timestamp, and an Idempotency-Key. A retry with the same key and body returns the original outcome with Idempotent-Replayed: true; reusing a key for another body fails with IDEMPOTENCY_CONFLICT. Every decision is audited under the integration's name, with reference and decided_by when given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrations"
+                ],
+                "summary": "Decide an expense from a workflow engine",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer api_key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix timestamp",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request signature",
+                        "name": "X-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key per decision",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/integration.DecisionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/integration.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Debit and credit legs booked for approved expenses (payable), settled payments (cash_out) and refunds, newest first. Requires admin or finance_viewer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List ledger entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "expense_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "payment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payable, cash_out or refund",
+                        "name": "entry_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "expense, expenses_payable or cash",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page, at most 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.EntriesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/reconciliation": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compares the cash booked for each settled payment with its settled amount in the payments table. Requires admin or finance_viewer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reconcile the ledger against payments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.ReconciliationReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/callback": {
+            "post": {
+                "description": "Callbacks must be signed with the webhook secret shared with the gateway: X-Signature is \"sha256=\" + hex(HMAC-SHA256(webhook_secret, timestamp + \".\" + body)), with the Unix timestamp in X-Signature-Timestamp, at most 5 minutes from now. Unsigned, badly signed and stale callbacks are rejected with 401. With the callback inbox enabled, a well-formed callback is stored and acknowledged at once, then applied in the background with retries; redelivered callbacks are acknowledged without being stored twice. Otherwise it is applied inline, and callbacks whose external_id or amount do not match the stored payment are rejected with 422. A \"partial\" status settles amount as one installment identified by gateway_payment_id; the expense is completed once installments add up to the payment amount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Payment gateway callback",
+                "parameters": [
+                    {
+                        "description": "Gateway callback",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentCallbackResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-queues the payment of an approved expense. Returns 429 when the payment queue is full.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Retry payment",
+                "parameters": [
+                    {
+                        "description": "Payment to retry",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentRetryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/jobs/{external_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Payment job status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment external ID",
+                        "name": "external_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.PaymentJobView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ping": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/approval-sla": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "How long expenses approved in the range waited from submission to approval: the median and 95th percentile overall, per approving manager and per submitter department, slowest first, with how many were approved within the target (reports.approval_sla). Auto-approved expenses are left out. Covers the admin's tenant. Requires admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Approval SLA report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First approval day, YYYY-MM-DD; 30 days before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last approval day, YYYY-MM-DD; today by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.ApprovalSLAReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/cash-flow": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approved expenses not yet paid, for treasury planning: their total, their age in days since approval, and the weeks (starting Monday, from the current one) they are expected to be paid in. An expense is expected to be paid the median payment lead time after its approval; the lead time is learnt from the successful payments of the last lead_time_days days. Expenses expected before today, and those whose payment failed, are overdue and counted in the first week. Covers the caller's tenant. Requires admin or finance_viewer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Payments aging and cash-flow forecast",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Weeks to forecast, 8 by default, at most 26",
+                        "name": "weeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days of payments to learn the lead time from, 90 by default, at most 365",
+                        "name": "lead_time_days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.CashFlowReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared/exports/{id}": {
+            "get": {
+                "description": "Follows a link from POST /exports/{id}/share and redirects to a short-lived download URL for the file. The token grants downloading this export only; no bearer token is needed.",
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Download a shared export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Capability token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared/receipts/{id}": {
+            "get": {
+                "description": "Follows a link from POST /expenses/{id}/receipt/share and redirects to a short-lived download URL for the receipt. The token grants viewing this receipt only; no bearer token is needed.",
+                "tags": [
+                    "expenses"
+                ],
+                "summary": "Open a shared receipt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Capability token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slack/commands": {
+            "post": {
+                "description": "Called by Slack for ` + "`" + `/expenses pending` + "`" + `, which lists the expenses awaiting the caller's approval with approve and reject buttons. The caller is matched to a user by the email on their Slack profile. Requests must carry a valid X-Slack-Signature; no bearer token is needed.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "slack"
+                ],
+                "summary": "Slack slash command",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request timestamp",
+                        "name": "X-Slack-Request-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request signature",
+                        "name": "X-Slack-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slack user ID",
+                        "name": "user_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subcommand",
+                        "name": "text",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/slack.Message"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slack/interactions": {
+            "post": {
+                "description": "Called by Slack when an approve or reject button from ` + "`" + `/expenses pending` + "`" + ` is clicked. The decision is made with the clicker's current permissions and the list is refreshed through the interaction's response_url. Requests must carry a valid X-Slack-Signature; no bearer token is needed.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "slack"
+                ],
+                "summary": "Slack interaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request timestamp",
+                        "name": "X-Slack-Request-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request signature",
+                        "name": "X-Slack-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Interaction payload (JSON)",
+                        "name": "payload",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user with their permissions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_user.User"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the caller's name and notification preferences; omitted fields keep their current value. Department is set by an administrator and may only be sent unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update current user",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateProfileDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_user.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/avatar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a short-lived download link for the caller's avatar.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get avatar",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.AvatarResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the caller's avatar to a JPEG, PNG or WebP image of at most 2 MB, replacing any earlier one.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Upload avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.AvatarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove avatar",
+                "responses": {
+                    "204": {
+                        "description": "Avatar removed"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/bank-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Account numbers are masked to their last four digits.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List my bank accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bankaccount.AccountsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "New accounts are unverified; an admin must verify an account before it is paid into. The user's first account becomes the default.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Add a bank account for reimbursements",
+                "parameters": [
+                    {
+                        "description": "Bank account",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bankaccount.CreateAccountDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_bankaccount.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/bank-accounts/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses that picked the account are paid into the default account instead.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a bank account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/bank-accounts/{id}/default": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Make a bank account the default for payouts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_bankaccount.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/delegates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The users who may file expenses on my behalf.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List my delegates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delegation.GrantsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The delegate files expenses with submitted_for_user_id set to my ID. Those expenses are mine: I see them, am paid for them and have them counted against my limits. Granting the same delegate again replaces the expiry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Let a user file expenses on my behalf",
+                "parameters": [
+                    {
+                        "description": "Delegate",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delegation.GrantDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_delegation.Grant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/delegates/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expenses the delegate already filed stay mine.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Stop a user filing expenses on my behalf",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delegate user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/digest-preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Which digest emails the current user receives. Users without saved preferences receive all of them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Digest email preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/digest.Preferences"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Omitted fields keep their current value.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Opt in or out of digest emails",
+                "parameters": [
+                    {
+                        "description": "Preferences to change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/digest.UpdatePreferencesDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/digest.Preferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the caller's password. current_password must match the password in use; new_password follows the usual password rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ChangePasswordDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Password changed"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.AppErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/principals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List the users I may file expenses for",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delegation.GrantsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "approvalaction.Action": {
+            "type": "string",
+            "enum": [
+                "approve",
+                "reject"
+            ],
+            "x-enum-varnames": [
+                "ActionApprove",
+                "ActionReject"
+            ]
+        },
+        "approvalaction.Result": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/approvalaction.Action"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "approvalrouting.Matrix": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/approvalrouting.MatrixRule"
+                    }
+                },
+                "default": {
+                    "description": "Default is nil when a file leaves the tenant-wide policy as it is.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/approvalrouting.MatrixDefault"
+                        }
+                    ]
+                }
+            }
+        },
+        "approvalrouting.MatrixChanges": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "deleted": {
+                    "type": "integer"
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "approvalrouting.MatrixDefault": {
+            "type": "object",
+            "properties": {
+                "approval_chain": {
+                    "description": "ApprovalChain lists the permissions allowed to decide expenses in\ncategories without a rule; empty lets any approver decide.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auto_approval_threshold_idr": {
+                    "type": "integer"
+                },
+                "max_amount_idr": {
+                    "type": "integer"
+                },
+                "min_amount_idr": {
+                    "type": "integer"
+                },
+                "receipt_required_above_idr": {
+                    "type": "integer"
+                }
+            }
+        },
+        "approvalrouting.MatrixProblem": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "approvalrouting.MatrixReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/approvalrouting.MatrixProblem"
+                    }
+                },
+                "rules": {
+                    "$ref": "#/definitions/approvalrouting.MatrixChanges"
+                },
+                "settings": {
+                    "description": "Settings lists the tenant settings the import overrides.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "approvalrouting.MatrixRule": {
+            "type": "object",
+            "properties": {
+                "approver_permission": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "max_amount_idr": {
+                    "type": "integer"
+                },
+                "min_amount_idr": {
+                    "type": "integer"
+                },
+                "require_approval": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "approvalrouting.RulesResponse": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_approvalrouting.Rule"
+                    }
+                }
+            }
+        },
+        "approvalrouting.SaveRuleDTO": {
+            "type": "object",
+            "required": [
+                "approver_permission"
+            ],
+            "properties": {
+                "approver_permission": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "require_approval": {
+                    "description": "RequireApproval disables auto-approval for small amounts.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "auth.AuthTokens": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ImpersonateDTO": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason is recorded in the audit log, for example a support ticket.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.Impersonation": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "impersonator_id": {
+                    "type": "integer"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.Introspection": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "exp": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "impersonator_id": {
+                    "description": "ImpersonatorID is set when an administrator is acting as the user.",
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scope": {
+                    "description": "Scope is Permissions joined by spaces, as RFC 7662 expects.",
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginDTO": {
+            "type": "object",
+            "properties": {
+                "captcha_token": {
+                    "description": "CaptchaToken is required once a client has failed to log in too\noften; the login answers 428 until it is sent.",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.OAuthError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_description": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.OAuthToken": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.RefreshTokenDTO": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "bankaccount.AccountsResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_bankaccount.Account"
+                    }
+                }
+            }
+        },
+        "bankaccount.CreateAccountDTO": {
+            "type": "object",
+            "required": [
+                "account_holder",
+                "account_number",
+                "bank_code"
+            ],
+            "properties": {
+                "account_holder": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "account_number": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 6
+                },
+                "bank_code": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "make_default": {
+                    "description": "MakeDefault selects the account for payouts; a user's first account is\nalways the default.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "bankaccount.VerifyAccountDTO": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "verified",
+                        "rejected"
+                    ]
+                }
+            }
+        },
+        "calendar.CalendarResponse": {
+            "type": "object",
+            "properties": {
+                "holidays": {
+                    "description": "Holidays lists the days off other than the weekend, national ones\nthe tenant works on left out.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/calendar.Holiday"
+                    }
+                },
+                "overrides": {
+                    "description": "Overrides lists the tenant's own days, holidays and working days.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_calendar.Day"
+                    }
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                },
+                "weekend": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "saturday",
+                        "sunday"
+                    ]
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "calendar.Holiday": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-08-17"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "national"
+                }
+            }
+        },
+        "calendar.SetDayDTO": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "holiday",
+                        "working_day"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "calendar.WorkingDaysResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-08-11"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-08-22"
+                },
+                "working_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "cannedresponse.RejectionReasonsResponse": {
+            "type": "object",
+            "properties": {
+                "canned_responses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_cannedresponse.Response"
+                    }
+                },
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "cannedresponse.ResponseDTO": {
+            "type": "object",
+            "required": [
+                "body",
+                "code",
+                "title"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "policy_violation",
+                        "missing_receipt",
+                        "duplicate",
+                        "other"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "cannedresponse.ResponsesResponse": {
+            "type": "object",
+            "properties": {
+                "canned_responses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_cannedresponse.Response"
+                    }
+                }
+            }
+        },
+        "capability.Link": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "scope": {
+                    "$ref": "#/definitions/capability.Scope"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "capability.Scope": {
+            "type": "string",
+            "enum": [
+                "export:download",
+                "receipt:view"
+            ],
+            "x-enum-varnames": [
+                "ScopeExportDownload",
+                "ScopeReceiptView"
+            ]
+        },
+        "cardfeed.ImportReport": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/cardfeed.RowError"
+                    }
+                },
+                "errors_truncated": {
+                    "description": "ErrorsTruncated is set when more than MaxReportedErrors rows failed.",
+                    "type": "boolean"
+                },
+                "failed_rows": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "matched": {
+                    "type": "integer"
+                },
+                "total_rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "cardfeed.ImportTransactionsDTO": {
+            "type": "object",
+            "properties": {
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/cardfeed.TransactionDTO"
+                    }
+                }
+            }
+        },
+        "cardfeed.MatchReport": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer"
+                },
+                "matched": {
+                    "type": "integer"
+                },
+                "unmatched": {
+                    "type": "integer"
+                }
+            }
+        },
+        "cardfeed.RowError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "cardfeed.TransactionDTO": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "card_last4": {
+                    "type": "string"
+                },
+                "cardholder_email": {
+                    "type": "string"
+                },
+                "merchant": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "description": "TransactionDate is formatted as YYYY-MM-DD.",
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "cardfeed.TransactionsResponse": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_cardfeed.Transaction"
+                    }
+                }
+            }
+        },
+        "category.CategoriesResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/category.CategoryResponse"
+                    }
+                }
+            }
+        },
+        "category.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "category.CategoryTreeNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/category.CategoryTreeNode"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "category.CategoryTreeResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/category.CategoryTreeNode"
+                    }
+                }
+            }
+        },
+        "changefeed.ChangesResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_changefeed.Change"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "changefeed.CommitDTO": {
+            "type": "object",
+            "properties": {
+                "offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dashboard.ApprovalStats": {
+            "type": "object",
+            "properties": {
+                "auto_approval_rate": {
+                    "type": "number"
+                },
+                "auto_approved": {
+                    "type": "integer"
+                },
+                "average_latency_seconds": {
+                    "type": "number"
+                },
+                "manual_decisions": {
+                    "description": "ManualDecisions counts expenses an approver approved or rejected;\nAverageLatencySeconds is their mean time from submission to decision.",
+                    "type": "integer"
+                },
+                "submitted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dashboard.Dashboard": {
+            "type": "object",
+            "properties": {
+                "month_to_date": {
+                    "$ref": "#/definitions/dashboard.Spend"
+                },
+                "pending_approvals": {
+                    "$ref": "#/definitions/dashboard.PendingApprovals"
+                },
+                "recent_payment_failures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dashboard.PaymentFailure"
+                    }
+                },
+                "status_counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "dashboard.OpsStats": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "$ref": "#/definitions/dashboard.ApprovalStats"
+                },
+                "payment_queue": {
+                    "description": "PaymentQueue is the gateway worker pool of this instance, shared by\nevery tenant; it is omitted when the server runs without one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/paymentgateway.QueueStats"
+                        }
+                    ]
+                },
+                "payments_by_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dashboard.PaymentStatusStats"
+                    }
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "dashboard.PaymentFailure": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "retry_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dashboard.PaymentStatusStats": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dashboard.PendingApprovals": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "oldest_submitted_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dashboard.Spend": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "delegation.GrantDTO": {
+            "type": "object",
+            "required": [
+                "delegate_id"
+            ],
+            "properties": {
+                "delegate_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "delegation.GrantsResponse": {
+            "type": "object",
+            "properties": {
+                "grants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_delegation.Grant"
+                    }
+                }
+            }
+        },
+        "digest.Preferences": {
+            "type": "object",
+            "properties": {
+                "employee_weekly": {
+                    "type": "boolean"
+                },
+                "manager_daily": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "digest.UpdatePreferencesDTO": {
+            "type": "object",
+            "properties": {
+                "employee_weekly": {
+                    "type": "boolean"
+                },
+                "manager_daily": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "events.BusStats": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/events.EventTypeStats"
+                    }
+                }
+            }
+        },
+        "events.EventTypeStats": {
+            "type": "object",
+            "properties": {
+                "event_type": {
+                    "type": "string"
+                },
+                "handlers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/events.HandlerStats"
+                    }
+                },
+                "last_event_id": {
+                    "type": "string"
+                },
+                "last_published_at": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "integer"
+                }
+            }
+        },
+        "events.HandlerStats": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the latest failure and LastFailedEventID\nthe event it failed on; they stay set after later calls succeed.",
+                    "type": "string"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "last_failed_event_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "exchangerate.RatesResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_exchangerate.Rate"
+                    }
+                }
+            }
+        },
+        "exchangerate.SetRateDTO": {
+            "type": "object",
+            "required": [
+                "rate"
+            ],
+            "properties": {
+                "rate": {
+                    "description": "Rate is a decimal string such as \"16250.25\", with at most 14 digits\nbefore the point and 10 after it.",
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "expense.ApproveExpenseDTO": {
+            "type": "object",
+            "properties": {
+                "approved_amount_idr": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "expense.CreateExpenseDTO": {
+            "type": "object",
+            "required": [
+                "amount_idr",
+                "category",
+                "description",
+                "expense_date"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount may be sent instead of AmountIDR as {\"amount\", \"currency\"} in\nminor units. Other currencies are converted into AmountIDR at the rate\nof the expense date when exchange rates are enabled.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/money.Money"
+                        }
+                    ]
+                },
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 1
+                },
+                "expense_date": {
+                    "type": "string"
+                },
+                "payout_account_id": {
+                    "description": "PayoutAccountID picks one of the user's bank accounts to be paid into;\nthe default account is used when omitted.",
+                    "type": "integer"
+                },
+                "receipt_filename": {
+                    "type": "string"
+                },
+                "receipt_url": {
+                    "type": "string"
+                },
+                "submitted_for_user_id": {
+                    "description": "SubmittedForUserID files the expense for another user who granted the\ncaller delegation. The expense is theirs; the caller is recorded as\nits submitter.",
+                    "type": "integer"
+                }
+            }
+        },
+        "expense.ExpenseListV1": {
+            "type": "object",
+            "properties": {
+                "expenses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expense.Expense"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "search": {
+                    "type": "string"
+                },
+                "sort_by": {
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_data": {
+                    "type": "integer"
+                }
+            }
+        },
+        "expense.RateSnapshot": {
+            "type": "object",
+            "properties": {
+                "original_amount": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "quoted_at": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "rate_date": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "expense.RejectExpenseDTO": {
+            "type": "object",
+            "properties": {
+                "canned_response_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "policy_violation",
+                        "missing_receipt",
+                        "duplicate",
+                        "other"
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "expenseimport.Report": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/expenseimport.RowError"
+                    }
+                },
+                "errors_truncated": {
+                    "description": "ErrorsTruncated is set when more than MaxReportedErrors rows failed.",
+                    "type": "boolean"
+                },
+                "failed_rows": {
+                    "type": "integer"
+                },
+                "imported_rows": {
+                    "type": "integer"
+                },
+                "total_rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "expenseimport.RowError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "expensetemplate.TemplateDTO": {
+            "type": "object",
+            "required": [
+                "category",
+                "name"
+            ],
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "expensetemplate.TemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expensetemplate.Template"
+                    }
+                }
+            }
+        },
+        "expensetemplate.UseTemplateDTO": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expense_date": {
+                    "type": "string"
+                },
+                "payout_account_id": {
+                    "type": "integer"
+                },
+                "receipt_filename": {
+                    "type": "string"
+                },
+                "receipt_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "export.CreateJobDTO": {
+            "type": "object",
+            "required": [
+                "from",
+                "resource",
+                "to"
+            ],
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "jsonl"
+                    ],
+                    "example": "csv"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "resource": {
+                    "type": "string",
+                    "enum": [
+                        "expenses",
+                        "payments"
+                    ],
+                    "example": "expenses"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-03-31"
+                }
+            }
+        },
+        "export.Format": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "jsonl"
+            ],
+            "x-enum-varnames": [
+                "FormatCSV",
+                "FormatJSONL"
+            ]
+        },
+        "github_com_frahmantamala_expense-management_internal_approvalrouting.Rule": {
+            "type": "object",
+            "properties": {
+                "approver_permission": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "require_approval": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_bankaccount.Account": {
+            "type": "object",
+            "properties": {
+                "account_holder": {
+                    "type": "string"
+                },
+                "account_number_masked": {
+                    "type": "string"
+                },
+                "bank_code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "verification_note": {
+                    "type": "string"
+                },
+                "verification_status": {
+                    "type": "string"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_calendar.Day": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-12-24"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "holiday"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "set_by": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_cannedresponse.Response": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_cardfeed.Transaction": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "card_last4": {
+                    "type": "string"
+                },
+                "cardholder_email": {
+                    "type": "string"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imported_at": {
+                    "type": "string"
+                },
+                "matched_at": {
+                    "type": "string"
+                },
+                "merchant": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_changefeed.Change": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "diff": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "insert",
+                        "update"
+                    ]
+                },
+                "row_id": {
+                    "type": "integer"
+                },
+                "table": {
+                    "type": "string",
+                    "enum": [
+                        "expenses",
+                        "payments"
+                    ]
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_changefeed.Consumer": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_delegation.Grant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "delegate_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is unset for grants that last until they are revoked.",
+                    "type": "string"
+                },
+                "granted_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "principal_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_exchangerate.Rate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "set_by": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_expense.Expense": {
+            "type": "object",
+            "properties": {
+                "adjustment_reason": {
+                    "type": "string"
+                },
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "approval_seconds": {
+                    "type": "integer"
+                },
+                "approved_amount_idr": {
+                    "description": "ApprovedAmountIDR is set when the approver approved less than\nAmountIDR; the expense is paid that amount instead, for the reason in\nAdjustmentReason.",
+                    "type": "integer"
+                },
+                "approved_at": {
+                    "description": "ApprovedAt is when the expense was approved, by a user or\nautomatically; ApprovalSeconds is how long it waited after\nsubmission, set only when a user approved it.",
+                    "type": "string"
+                },
+                "approved_by": {
+                    "description": "ApprovedBy and RejectedBy are the users who decided the expense;\nauto-approved expenses have neither.",
+                    "type": "integer"
+                },
+                "canned_response_id": {
+                    "description": "CannedResponseID is the canned response the rejection was made with,\nif any.",
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duplicate_receipt_of": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "exchange_rate": {
+                    "description": "ExchangeRate is set when the expense was submitted in another currency\nand converted into AmountIDR.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/expense.RateSnapshot"
+                        }
+                    ]
+                },
+                "expense_date": {
+                    "type": "string"
+                },
+                "expense_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment": {
+                    "description": "Payment summarises the latest payment. Lists fill it in only when\nasked with include=payment, and only for expenses that have one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_expense.PaymentSummary"
+                        }
+                    ]
+                },
+                "payout_account_id": {
+                    "type": "integer"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "receipt_filename": {
+                    "type": "string"
+                },
+                "receipt_missing": {
+                    "description": "ReceiptMissing flags pending expenses that cannot be approved until a\nreceipt is attached. It is computed on read, not stored.",
+                    "type": "boolean"
+                },
+                "receipt_url": {
+                    "type": "string"
+                },
+                "rejected_by": {
+                    "type": "integer"
+                },
+                "rejection_code": {
+                    "description": "RejectionCode is one of RejectionCodes; it and RejectionReason are set\nonce the expense is rejected.",
+                    "type": "string"
+                },
+                "rejection_reason": {
+                    "type": "string"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "submitted_by": {
+                    "description": "SubmittedBy is the delegate who filed the expense for UserID, who\nowns it; unset when UserID filed it.",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_expense.PaymentSummary": {
+            "type": "object",
+            "properties": {
+                "processed_at": {
+                    "type": "string"
+                },
+                "retry_count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_expenseimport.Job": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/expenseimport.RowError"
+                    }
+                },
+                "failed_rows": {
+                    "type": "integer"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imported_rows": {
+                    "type": "integer"
+                },
+                "processed_rows": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_expensetemplate.Template": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_export.Job": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "description": "DownloadURL is a signed link, valid until URLExpiresAt.",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the file is deleted.",
+                    "type": "string"
+                },
+                "format": {
+                    "$ref": "#/definitions/export.Format"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "row_count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "url_expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_ledger.Entry": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "direction": {
+                    "type": "string"
+                },
+                "entry_type": {
+                    "type": "string"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "reference": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_periodlock.Lock": {
+            "type": "object",
+            "properties": {
+                "locked_at": {
+                    "type": "string"
+                },
+                "locked_by": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_report.Schedule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "type": "string"
+                },
+                "hour": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report_type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weekday": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_snapshot.ExpenseFact": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-11-02"
+                },
+                "department": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_snapshot.PaymentFact": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-11-02"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "settled_amount_idr": {
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "success_rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_spendinglimit.Override": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "granted_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "period": {
+                    "$ref": "#/definitions/spendinglimit.Period"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_frahmantamala_expense-management_internal_user.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "department": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "has_avatar": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notification_preferences": {
+                    "description": "NotificationPreferences are the emails the user chose to receive.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/user.NotificationPreferences"
+                        }
+                    ]
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "integration.DecisionDTO": {
+            "type": "object",
+            "required": [
+                "decision",
+                "expense_id"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Code and Reason are required to reject, as on PATCH\n/expenses/{id}/reject.",
+                    "type": "string",
+                    "enum": [
+                        "policy_violation",
+                        "missing_receipt",
+                        "duplicate",
+                        "other"
+                    ]
+                },
+                "decided_by": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "approve",
+                        "reject"
+                    ]
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "reference": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "integration.Result": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "type": "string"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is the expense status after the decision.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal.AppError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/internal.ErrorCode"
+                },
+                "details": {},
+                "message": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/internal.ErrorType"
+                }
+            }
+        },
+        "internal.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "VALIDATION_FAILED",
+                "INVALID_AMOUNT",
+                "INVALID_DESCRIPTION",
+                "INVALID_CATEGORY",
+                "INVALID_DATE",
+                "AMOUNT_TOO_LOW",
+                "AMOUNT_TOO_HIGH",
+                "INVALID_REQUEST_BODY",
+                "REQUEST_TOO_LARGE",
+                "REQUEST_TIMEOUT",
+                "CATEGORY_NOT_FOUND",
+                "CATEGORY_CYCLE",
+                "ROUTING_RULE_NOT_FOUND",
+                "RECEIPT_NOT_FOUND",
+                "INVALID_RECEIPT",
+                "RECEIPT_REQUIRED",
+                "RECEIPT_PREVIEW_NOT_READY",
+                "RECEIPT_PREVIEW_UNAVAILABLE",
+                "LIMIT_EXCEEDED",
+                "PENDING_LIMIT_EXCEEDED",
+                "USER_NOT_FOUND",
+                "AVATAR_NOT_FOUND",
+                "INVALID_AVATAR",
+                "ACTION_LINK_USED",
+                "BANK_ACCOUNT_NOT_FOUND",
+                "PAYOUT_ACCOUNT_UNVERIFIED",
+                "TENANT_NOT_FOUND",
+                "TENANT_INACTIVE",
+                "TENANT_MISMATCH",
+                "TENANT_SETTING_NOT_FOUND",
+                "EXPORT_JOB_NOT_FOUND",
+                "EXPORT_FORBIDDEN",
+                "EXPORT_NOT_READY",
+                "IMPORT_JOB_NOT_FOUND",
+                "INVALID_IMPORT_FILE",
+                "REPORT_SCHEDULE_NOT_FOUND",
+                "EXPENSE_TEMPLATE_NOT_FOUND",
+                "CANNED_RESPONSE_NOT_FOUND",
+                "DELEGATION_NOT_FOUND",
+                "NOT_DELEGATE",
+                "CHANGE_CONSUMER_NOT_FOUND",
+                "IDEMPOTENCY_CONFLICT",
+                "PERIOD_LOCKED",
+                "PERIOD_LOCK_NOT_FOUND",
+                "CALENDAR_DAY_NOT_FOUND",
+                "EXCHANGE_RATE_UNAVAILABLE",
+                "EXPENSE_NOT_FOUND",
+                "UNAUTHORIZED_ACCESS",
+                "INVALID_EXPENSE_STATUS",
+                "CANNOT_MODIFY_EXPENSE",
+                "OWN_EXPENSE_DECISION",
+                "INVALID_CREDENTIALS",
+                "USER_INACTIVE",
+                "INVALID_TOKEN",
+                "TOKEN_EXPIRED",
+                "IMPERSONATION_NOT_ALLOWED",
+                "IMPERSONATION_RESTRICTED",
+                "PAYMENT_FAILED",
+                "PAYMENT_RETRY_FAILED",
+                "PAYMENT_JOB_NOT_FOUND",
+                "PAYMENT_QUEUE_FULL",
+                "PAYMENT_NOT_FOUND",
+                "PAYMENT_JOB_CONFLICT",
+                "WEBHOOK_NOT_FOUND",
+                "WEBHOOK_REPLAY_CONFLICT"
+            ],
+            "x-enum-varnames": [
+                "ErrCodeValidationFailed",
+                "ErrCodeInvalidAmount",
+                "ErrCodeInvalidDescription",
+                "ErrCodeInvalidCategory",
+                "ErrCodeInvalidDate",
+                "ErrCodeAmountTooLow",
+                "ErrCodeAmountTooHigh",
+                "ErrCodeInvalidRequestBody",
+                "ErrCodeRequestTooLarge",
+                "ErrCodeRequestTimeout",
+                "ErrCodeCategoryNotFound",
+                "ErrCodeCategoryCycle",
+                "ErrCodeRoutingRuleNotFound",
+                "ErrCodeReceiptNotFound",
+                "ErrCodeInvalidReceipt",
+                "ErrCodeReceiptRequired",
+                "ErrCodePreviewNotReady",
+                "ErrCodeNoPreview",
+                "ErrCodeLimitExceeded",
+                "ErrCodePendingLimitExceeded",
+                "ErrCodeUserNotFound",
+                "ErrCodeAvatarNotFound",
+                "ErrCodeInvalidAvatar",
+                "ErrCodeActionLinkUsed",
+                "ErrCodeBankAccountNotFound",
+                "ErrCodePayoutAccountUnverified",
+                "ErrCodeTenantNotFound",
+                "ErrCodeTenantInactive",
+                "ErrCodeTenantMismatch",
+                "ErrCodeTenantSettingNotFound",
+                "ErrCodeExportJobNotFound",
+                "ErrCodeExportForbidden",
+                "ErrCodeExportNotReady",
+                "ErrCodeImportJobNotFound",
+                "ErrCodeInvalidImportFile",
+                "ErrCodeReportScheduleNotFound",
+                "ErrCodeExpenseTemplateNotFound",
+                "ErrCodeCannedResponseNotFound",
+                "ErrCodeDelegationNotFound",
+                "ErrCodeNotDelegate",
+                "ErrCodeChangeConsumerNotFound",
+                "ErrCodeIdempotencyConflict",
+                "ErrCodePeriodLocked",
+                "ErrCodePeriodLockNotFound",
+                "ErrCodeCalendarDayNotFound",
+                "ErrCodeExchangeRateUnavailable",
+                "ErrCodeExpenseNotFound",
+                "ErrCodeUnauthorizedAccess",
+                "ErrCodeInvalidExpenseStatus",
+                "ErrCodeCannotModifyExpense",
+                "ErrCodeOwnExpenseDecision",
+                "ErrCodeInvalidCredentials",
+                "ErrCodeUserInactive",
+                "ErrCodeInvalidToken",
+                "ErrCodeTokenExpired",
+                "ErrCodeImpersonationNotAllowed",
+                "ErrCodeImpersonationRestricted",
+                "ErrCodePaymentFailed",
+                "ErrCodePaymentRetryFailed",
+                "ErrCodePaymentJobNotFound",
+                "ErrCodePaymentQueueFull",
+                "ErrCodePaymentNotFound",
+                "ErrCodePaymentJobConflict",
+                "ErrCodeWebhookNotFound",
+                "ErrCodeWebhookReplayConflict"
+            ]
+        },
+        "internal.ErrorType": {
+            "type": "string",
+            "enum": [
+                "VALIDATION_ERROR",
+                "NOT_FOUND",
+                "UNAUTHORIZED",
+                "FORBIDDEN",
+                "CONFLICT",
+                "RATE_LIMITED",
+                "PAYLOAD_TOO_LARGE",
+                "INTERNAL_ERROR",
+                "EXTERNAL_ERROR",
+                "SERVICE_UNAVAILABLE"
+            ],
+            "x-enum-varnames": [
+                "ErrorTypeValidation",
+                "ErrorTypeNotFound",
+                "ErrorTypeUnauthorized",
+                "ErrorTypeForbidden",
+                "ErrorTypeConflict",
+                "ErrorTypeRateLimited",
+                "ErrorTypeTooLarge",
+                "ErrorTypeInternal",
+                "ErrorTypeExternal",
+                "ErrorTypeUnavailable"
+            ]
+        },
+        "ledger.Discrepancy": {
+            "type": "object",
+            "properties": {
+                "difference_idr": {
+                    "type": "integer"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "ledger_cash_out_idr": {
+                    "type": "integer"
+                },
+                "ledger_refund_idr": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "settled_amount_idr": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "ledger.EntriesResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_ledger.Entry"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ledger.ReconciliationReport": {
+            "type": "object",
+            "properties": {
+                "balanced": {
+                    "type": "boolean"
+                },
+                "checked": {
+                    "description": "Checked counts payments that are settled or have cash postings.",
+                    "type": "integer"
+                },
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ledger.Discrepancy"
+                    }
+                }
+            }
+        },
+        "money.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.GatewayCallView": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "request_body": {
+                    "type": "string"
+                },
+                "request_header": {
+                    "type": "object"
+                },
+                "response_body": {
+                    "type": "string"
+                },
+                "response_header": {
+                    "type": "object"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.JobActionResult": {
+            "type": "object",
+            "properties": {
+                "external_id": {
+                    "type": "string"
+                },
+                "job": {
+                    "$ref": "#/definitions/payment.PaymentJobView"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "payment_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.PaymentCallbackRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "failure_class": {
+                    "description": "FailureClass is transient when a failed payment is worth retrying;\nanything else, or nothing, is a permanent failure.",
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "gateway_payment_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.PaymentCallbackResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.PaymentJobActionRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "payment.PaymentJobView": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "queued_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "worker_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.PaymentRetryRequest": {
+            "type": "object",
+            "required": [
+                "expense_id",
+                "external_id"
+            ],
+            "properties": {
+                "expense_id": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.WebhookReplayRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "payment.WebhookReplayResult": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "inbox_id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "type": "string"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is processed or rejected once the callback was applied, or\npending when it failed again and will be retried.",
+                    "type": "string",
+                    "example": "processed"
+                }
+            }
+        },
+        "paymentgateway.OverflowStrategy": {
+            "type": "string",
+            "enum": [
+                "block",
+                "spill",
+                "shed"
+            ],
+            "x-enum-varnames": [
+                "OverflowBlock",
+                "OverflowSpill",
+                "OverflowShed"
+            ]
+        },
+        "paymentgateway.QueueStats": {
+            "type": "object",
+            "properties": {
+                "busy_workers": {
+                    "type": "integer"
+                },
+                "dead_lettered_total": {
+                    "type": "integer"
+                },
+                "enqueued_total": {
+                    "type": "integer"
+                },
+                "max_workers": {
+                    "type": "integer"
+                },
+                "min_workers": {
+                    "type": "integer"
+                },
+                "overflow_strategy": {
+                    "$ref": "#/definitions/paymentgateway.OverflowStrategy"
+                },
+                "panics_total": {
+                    "description": "PanicsTotal counts job runs that panicked; each is either re-queued\nor, past the poison threshold, dead-lettered.",
+                    "type": "integer"
+                },
+                "queue_capacity": {
+                    "type": "integer"
+                },
+                "queue_depth": {
+                    "type": "integer"
+                },
+                "requeued_total": {
+                    "type": "integer"
+                },
+                "shed_total": {
+                    "type": "integer"
+                },
+                "spilled_total": {
+                    "type": "integer"
+                },
+                "workers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "periodlock.LockPeriodDTO": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "periodlock.LocksResponse": {
+            "type": "object",
+            "properties": {
+                "locks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_periodlock.Lock"
+                    }
+                }
+            }
+        },
+        "receipt.ConfirmUploadRequest": {
+            "type": "object",
+            "required": [
+                "upload_id"
+            ],
+            "properties": {
+                "filename": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "upload_id": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "receipt.ReceiptResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "receipt.UploadURLRequest": {
+            "type": "object",
+            "required": [
+                "content_type"
+            ],
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "enum": [
+                        "image/jpeg",
+                        "image/png",
+                        "application/pdf"
+                    ]
+                }
+            }
+        },
+        "receipt.UploadURLResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_bytes": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "upload_id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.AgingBucket": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "days": {
+                    "type": "string",
+                    "example": "8-14"
+                },
+                "expenses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.ApprovalLatency": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "type": "integer"
+                },
+                "group": {
+                    "description": "Group is the approving manager's email or the submitter's\ndepartment, empty for the overall figures and for users without a\ndepartment.",
+                    "type": "string"
+                },
+                "median_seconds": {
+                    "type": "number"
+                },
+                "p95_seconds": {
+                    "type": "number"
+                },
+                "within_target": {
+                    "description": "WithinTarget counts the approvals made within the SLA target.",
+                    "type": "integer"
+                }
+            }
+        },
+        "report.ApprovalSLAReport": {
+            "type": "object",
+            "properties": {
+                "departments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.ApprovalLatency"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-10-04"
+                },
+                "managers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.ApprovalLatency"
+                    }
+                },
+                "overall": {
+                    "$ref": "#/definitions/report.ApprovalLatency"
+                },
+                "target_seconds": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-11-02"
+                }
+            }
+        },
+        "report.CashFlowReport": {
+            "type": "object",
+            "properties": {
+                "aging": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.AgingBucket"
+                    }
+                },
+                "as_of": {
+                    "type": "string",
+                    "example": "2025-11-05"
+                },
+                "later": {
+                    "description": "Later is expected after the last forecast week.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/report.Outstanding"
+                        }
+                    ]
+                },
+                "lead_time": {
+                    "$ref": "#/definitions/report.LeadTime"
+                },
+                "lead_time_days": {
+                    "type": "integer"
+                },
+                "outstanding": {
+                    "$ref": "#/definitions/report.Outstanding"
+                },
+                "weeks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.CashFlowWeek"
+                    }
+                }
+            }
+        },
+        "report.CashFlowWeek": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "expenses": {
+                    "type": "integer"
+                },
+                "overdue": {
+                    "description": "Overdue counts the expenses that were expected to be paid before\ntoday, or whose payment failed; they are all due in the first week.",
+                    "type": "integer"
+                },
+                "week_start": {
+                    "type": "string",
+                    "example": "2025-11-03"
+                }
+            }
+        },
+        "report.LeadTime": {
+            "type": "object",
+            "properties": {
+                "median_seconds": {
+                    "type": "number"
+                },
+                "p95_seconds": {
+                    "type": "number"
+                },
+                "payments": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.Outstanding": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "expenses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.ScheduleDTO": {
+            "type": "object",
+            "required": [
+                "format",
+                "frequency",
+                "name",
+                "report_type"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "xlsx",
+                        "csv"
+                    ]
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ]
+                },
+                "hour": {
+                    "type": "integer",
+                    "maximum": 23,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report_type": {
+                    "type": "string",
+                    "enum": [
+                        "spend_by_department",
+                        "spend_by_category"
+                    ]
+                },
+                "weekday": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "report.SchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_report.Schedule"
+                    }
+                }
+            }
+        },
+        "rest.CheckEntry": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/rest.HealthStatus"
+                }
+            }
+        },
+        "rest.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/rest.CheckEntry"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/rest.HealthStatus"
+                }
+            }
+        },
+        "rest.HealthStatus": {
+            "type": "string",
+            "enum": [
+                "healthy",
+                "unhealthy"
+            ],
+            "x-enum-varnames": [
+                "HealthHealthy",
+                "HealthUnhealthy"
+            ]
+        },
+        "slack.Block": {
+            "type": "object",
+            "properties": {
+                "elements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/slack.Element"
+                    }
+                },
+                "text": {
+                    "$ref": "#/definitions/slack.Text"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "slack.Element": {
+            "type": "object",
+            "properties": {
+                "action_id": {
+                    "type": "string"
+                },
+                "style": {
+                    "type": "string"
+                },
+                "text": {
+                    "$ref": "#/definitions/slack.Text"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "slack.Message": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/slack.Block"
+                    }
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "replace_original": {
+                    "type": "boolean"
+                },
+                "response_type": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "slack.Text": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "snapshot.TrendsResponse": {
+            "type": "object",
+            "properties": {
+                "expenses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_snapshot.ExpenseFact"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-10-04"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_snapshot.PaymentFact"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-11-02"
+                }
+            }
+        },
+        "spendinglimit.GrantOverrideDTO": {
+            "type": "object",
+            "required": [
+                "amount_idr",
+                "date",
+                "period",
+                "reason"
+            ],
+            "properties": {
+                "amount_idr": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "date": {
+                    "description": "Date is any day (YYYY-MM-DD) inside the period being raised.",
+                    "type": "string"
+                },
+                "period": {
+                    "enum": [
+                        "day",
+                        "month"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/spendinglimit.Period"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "spendinglimit.OverridesResponse": {
+            "type": "object",
+            "properties": {
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_frahmantamala_expense-management_internal_spendinglimit.Override"
+                    }
+                }
+            }
+        },
+        "spendinglimit.Period": {
+            "type": "string",
+            "enum": [
+                "day",
+                "month"
+            ],
+            "x-enum-varnames": [
+                "PeriodDay",
+                "PeriodMonth"
+            ]
+        },
+        "tenant.AmountLimits": {
+            "type": "object",
+            "properties": {
+                "max_idr": {
+                    "type": "integer"
+                },
+                "min_idr": {
+                    "type": "integer"
+                }
+            }
+        },
+        "tenant.SettingsResponse": {
+            "type": "object",
+            "properties": {
+                "approval_chain": {
+                    "description": "ApprovalChain lists the permissions allowed to decide expenses in\ncategories without a routing rule. Empty lets any approver decide.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auto_approval_threshold_idr": {
+                    "description": "AutoApprovalThresholdIDR is the amount below which expenses are\napproved on submission. Zero disables auto-approval.",
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "expense_amount_limits_by_category": {
+                    "description": "AmountLimitsByCategory replaces those bounds for claims in the named\ncategories.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/tenant.AmountLimits"
+                    }
+                },
+                "max_expense_amount_idr": {
+                    "type": "integer"
+                },
+                "max_pending_expenses": {
+                    "description": "MaxPendingExpenses caps how many expenses each user can have waiting\nfor approval at once. Zero leaves it uncapped.",
+                    "type": "integer"
+                },
+                "max_pending_expenses_by_department": {
+                    "description": "MaxPendingByDepartment replaces MaxPendingExpenses for users of the\nnamed departments; zero uncaps a department.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "min_expense_amount_idr": {
+                    "description": "MinExpenseAmountIDR and MaxExpenseAmountIDR bound the amount of each\nnew claim.",
+                    "type": "integer"
+                },
+                "notification_channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "overridden": {
+                    "description": "Overridden lists the keys set for this tenant; the others are defaults.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "receipt_required_above_idr": {
+                    "description": "ReceiptRequiredAboveIDR is the amount above which expenses must carry\na receipt. Zero leaves receipts optional.",
+                    "type": "integer"
+                }
+            }
+        },
+        "tenant.UpdateSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "approval_chain": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auto_approval_threshold_idr": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "expense_amount_limits_by_category": {
+                    "description": "AmountLimitsByCategory replaces the whole category map.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/tenant.AmountLimits"
+                    }
+                },
+                "max_expense_amount_idr": {
+                    "type": "integer"
+                },
+                "max_pending_expenses": {
+                    "type": "integer"
+                },
+                "max_pending_expenses_by_department": {
+                    "description": "MaxPendingByDepartment replaces the whole department map.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "min_expense_amount_idr": {
+                    "type": "integer"
+                },
+                "notification_channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "receipt_required_above_idr": {
+                    "type": "integer"
+                }
+            }
+        },
+        "transport.AppErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/internal.AppError"
+                }
+            }
+        },
+        "transport.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.AvatarResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.ChangePasswordDTO": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.NotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "export_emails": {
+                    "description": "ExportEmails are sent when a requested export is ready or has failed.",
+                    "type": "boolean"
+                },
+                "login_alerts": {
+                    "description": "LoginAlerts are sent on a login from a new device or location.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "user.UpdateNotificationPreferencesDTO": {
+            "type": "object",
+            "properties": {
+                "export_emails": {
+                    "type": "boolean"
+                },
+                "login_alerts": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "user.UpdateProfileDTO": {
+            "type": "object",
+            "properties": {
+                "department": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notification_preferences": {
+                    "$ref": "#/definitions/user.UpdateNotificationPreferencesDTO"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Bearer access token, e.g. \"Bearer eyJ...\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0.0",
+	Host:             "",
+	BasePath:         "/api/v1",
+	Schemes:          []string{},
+	Title:            "Expense Management API",
+	Description:      "Expense submission, approval and payment API.",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
@@ -1,14 +1,26 @@
 package swagger
 
 import (
+	_ "embed"
 	"net/http"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+//go:embed swagger.yaml
+var specYAML []byte
+
+// Handler serves the Swagger UI backed by the generated doc.json.
 func Handler() http.Handler {
-	// Serve the comprehensive OpenAPI spec from api/openapi3.yml
 	return httpSwagger.Handler(
-		httpSwagger.URL("/openapi.yml"), // URL to the OpenAPI spec served at root
+		httpSwagger.URL("/swagger/doc.json"),
 	)
 }
+
+// SpecHandler serves the generated spec as YAML.
+func SpecHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(specYAML)
+	})
+}
@@ -0,0 +1,7 @@
+//go:build tools
+
+package swagger
+
+// Pins the swag CLI in go.mod so `go generate` uses the same version as the
+// generated docs.go expects.
+import _ "github.com/swaggo/swag/cmd/swag"
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/core/money"
+)
+
+// APIVersion identifies the wire contract a request was routed through.
+// Handlers are shared between versions; only the response mappers differ.
+type APIVersion string
+
+const (
+	APIVersionV1 APIVersion = "v1"
+	APIVersionV2 APIVersion = "v2"
+)
+
+// SupportedAPIVersions lists the versions mounted under /api, oldest first.
+var SupportedAPIVersions = []APIVersion{APIVersionV1, APIVersionV2}
+
+const APIVersionHeader = "API-Version"
+
+type versionCtxKey struct{}
+
+// WithAPIVersion tags requests with the API version of the mount point.
+func WithAPIVersion(version APIVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, string(version))
+			ctx := context.WithValue(r.Context(), versionCtxKey{}, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIVersionFromContext returns the request's API version, defaulting to v1.
+func APIVersionFromContext(ctx context.Context) APIVersion {
+	if v, ok := ctx.Value(versionCtxKey{}).(APIVersion); ok {
+		return v
+	}
+	return APIVersionV1
+}
+
+// ResponseMapper converts a domain value into the response shape of each API
+// version. Versions without a mapper get the value as-is, which keeps v1
+// responses identical to what handlers have always returned.
+type ResponseMapper[T any] map[APIVersion]func(T) interface{}
+
+func (m ResponseMapper[T]) Map(version APIVersion, value T) interface{} {
+	if fn, ok := m[version]; ok {
+		return fn(value)
+	}
+	return value
+}
+
+// Money is the v2 representation of monetary amounts: minor units plus an
+// ISO 4217 currency code.
+type Money = money.Money
+
+// Envelope wraps v2 payloads so metadata can be added without breaking clients.
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+// PageMeta is the v2 pagination metadata for list endpoints.
+type PageMeta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+func NewPageMeta(page, perPage int, total int64) PageMeta {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	}
+	return PageMeta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages}
+}
+
+// FormatTimestamp renders times as ISO 8601 / RFC 3339 in UTC.
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// FormatOptionalTimestamp is FormatTimestamp for nullable columns.
+func FormatOptionalTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := FormatTimestamp(*t)
+	return &s
+}
@@ -0,0 +1,393 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/tenant"
+	"github.com/frahmantamala/expense-management/pkg/logger"
+)
+
+var (
+	ErrEmailTaken        = errors.New("email already registered")
+	ErrUnknownPermission = errors.New("permission does not exist")
+	// ErrInvalidReassignment is returned when approver permissions would be
+	// handed to the user being deactivated, an inactive user or a user of
+	// another tenant.
+	ErrInvalidReassignment = errors.New("approvals can only be reassigned to another active user of the same tenant")
+)
+
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+}
+
+type AuditRecorder interface {
+	Record(entry *audit.Entry) error
+}
+
+// AdminService backs the operator commands (user create, grant-permission,
+// deactivate, reset-password) so they apply the same validation and hashing
+// as the API instead of writing to the database directly.
+type AdminService struct {
+	repo          RepositoryAPI
+	hasher        PasswordHasher
+	auditRecorder AuditRecorder
+	logger        *slog.Logger
+	now           func() time.Time
+}
+
+// NewAdminService takes a nil auditRecorder when permission changes are only
+// logged.
+func NewAdminService(repo RepositoryAPI, hasher PasswordHasher, auditRecorder AuditRecorder, logger *slog.Logger) *AdminService {
+	return &AdminService{
+		repo:          repo,
+		hasher:        hasher,
+		auditRecorder: auditRecorder,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+func (s *AdminService) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
+func (s *AdminService) CreateUser(ctx context.Context, dto CreateUserDTO) (*User, error) {
+	dto.Email = strings.TrimSpace(strings.ToLower(dto.Email))
+	dto.Name = strings.TrimSpace(dto.Name)
+
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validatePermissions(dto.Permissions); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByEmail(dto.Email); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrEmailTaken, dto.Email)
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	hash, err := s.hasher.HashPassword(dto.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	data := &userDatamodel.User{
+		Email:        dto.Email,
+		Name:         dto.Name,
+		PasswordHash: hash,
+		Department:   strings.TrimSpace(dto.Department),
+		IsActive:     true,
+	}
+	// Without a tenant the column default, the default tenant, applies.
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		data.TenantID = tenantID
+	}
+	if err := s.repo.Create(data); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	for _, permission := range dto.Permissions {
+		if err := s.repo.GrantPermission(data.ID, permission, nil); err != nil {
+			return nil, fmt.Errorf("failed to grant %s: %w", permission, err)
+		}
+		s.record(ctx, audit.ActionPermissionGranted, data.ID, permission)
+	}
+
+	s.log(ctx).Info("user created", "user_id", data.ID, "tenant_id", data.TenantID, "email", data.Email, "permissions", dto.Permissions)
+
+	return FromDataModelWithPermissions(data, dto.Permissions), nil
+}
+
+func (s *AdminService) GrantPermission(ctx context.Context, email, permission string) error {
+	permission = strings.TrimSpace(permission)
+	if err := validatePermissions([]string{permission}); err != nil {
+		return err
+	}
+
+	u, err := s.getByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.GrantPermission(u.ID, permission, nil); err != nil {
+		return fmt.Errorf("failed to grant %s: %w", permission, err)
+	}
+	s.record(ctx, audit.ActionPermissionGranted, u.ID, permission)
+
+	s.log(ctx).Info("permission granted", "user_id", u.ID, "email", u.Email, "permission", permission)
+	return nil
+}
+
+// Deactivate blocks the user from logging in, ends their sessions and takes
+// away their approver permissions. With reassignTo, those permissions are
+// granted to that user so nothing waits on an approver who has left. It is a
+// no-op for users that are already inactive.
+func (s *AdminService) Deactivate(ctx context.Context, email, reassignTo string) error {
+	u, err := s.getByEmail(email)
+	if err != nil {
+		return err
+	}
+	if !u.IsActive {
+		return nil
+	}
+
+	var successor *userDatamodel.User
+	if strings.TrimSpace(reassignTo) != "" {
+		successor, err = s.getByEmail(reassignTo)
+		if err != nil {
+			return err
+		}
+		if successor.ID == u.ID || !successor.IsActive || successor.TenantID != u.TenantID {
+			return fmt.Errorf("%w: %s", ErrInvalidReassignment, successor.Email)
+		}
+	}
+
+	return s.deactivate(ctx, u, successor)
+}
+
+// deactivate hands u's approver permissions to successor, if any, before
+// marking u inactive, so a failure part way leaves u active and the call can
+// be repeated.
+func (s *AdminService) deactivate(ctx context.Context, u, successor *userDatamodel.User) error {
+	permissions, err := s.repo.GetPermissions(u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user permissions: %w", err)
+	}
+
+	var unassigned []string
+	for _, permission := range permissions {
+		if auth.IsApproverPermission(permission) {
+			unassigned = append(unassigned, permission)
+		}
+	}
+	for _, permission := range unassigned {
+		if successor != nil {
+			if err := s.repo.GrantPermission(successor.ID, permission, nil); err != nil {
+				return fmt.Errorf("failed to grant %s: %w", permission, err)
+			}
+			s.record(ctx, audit.ActionPermissionGranted, successor.ID, permission)
+		}
+		if err := s.repo.RevokePermission(u.ID, permission); err != nil {
+			return fmt.Errorf("failed to revoke %s: %w", permission, err)
+		}
+		s.record(ctx, audit.ActionPermissionRevoked, u.ID, permission)
+	}
+
+	if err := s.repo.SetActive(u.ID, false); err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+	if err := s.repo.RevokeSessions(u.ID, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	args := []any{"user_id", u.ID, "email", u.Email, "approver_permissions", unassigned}
+	if successor != nil {
+		args = append(args, "reassigned_to", successor.ID)
+	} else if len(unassigned) > 0 {
+		s.log(ctx).Warn("approver deactivated without reassignment", "user_id", u.ID, "permissions", unassigned)
+	}
+	s.log(ctx).Info("user deactivated", args...)
+	return nil
+}
+
+func (s *AdminService) ResetPassword(ctx context.Context, email, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	u, err := s.getByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	hash, err := s.hasher.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.repo.UpdatePassword(u.ID, hash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	s.log(ctx).Info("password reset", "user_id", u.ID, "email", u.Email)
+	return nil
+}
+
+// GetUser returns a user of the tenant ctx is scoped to, active or not,
+// with their permissions.
+func (s *AdminService) GetUser(ctx context.Context, userID int64) (*User, error) {
+	u, err := s.lookup(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPermissions(u)
+}
+
+// ListUsers pages through the users of the tenant ctx is scoped to. An empty
+// email matches every user.
+func (s *AdminService) ListUsers(ctx context.Context, email string, offset, limit int) ([]*User, int64, error) {
+	rows, total, err := s.repo.Find(ctx, strings.TrimSpace(strings.ToLower(email)), offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users := make([]*User, 0, len(rows))
+	for _, row := range rows {
+		u, err := s.withPermissions(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+	return users, total, nil
+}
+
+func (s *AdminService) UpdateProfile(ctx context.Context, userID int64, name, department string) error {
+	if _, err := s.lookup(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateProfile(userID, strings.TrimSpace(name), strings.TrimSpace(department)); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// SetActive activates or deactivates a user of the tenant ctx is scoped to.
+func (s *AdminService) SetActive(ctx context.Context, userID int64, active bool) error {
+	u, err := s.lookup(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.IsActive == active {
+		return nil
+	}
+	if !active {
+		return s.deactivate(ctx, u, nil)
+	}
+
+	if err := s.repo.SetActive(userID, active); err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	s.log(ctx).Info("user status changed", "user_id", userID, "email", u.Email, "active", active)
+	return nil
+}
+
+// SyncPermissions grants the user every permission in wanted and revokes the
+// ones in managed that are not wanted. Permissions outside managed are left
+// alone, so grants made by hand survive a sync.
+func (s *AdminService) SyncPermissions(ctx context.Context, userID int64, managed, wanted []string) error {
+	if err := validatePermissions(wanted); err != nil {
+		return err
+	}
+	if _, err := s.lookup(ctx, userID); err != nil {
+		return err
+	}
+	current, err := s.repo.GetPermissions(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user permissions: %w", err)
+	}
+
+	var granted, revoked []string
+	for _, permission := range wanted {
+		if slices.Contains(current, permission) {
+			continue
+		}
+		if err := s.repo.GrantPermission(userID, permission, nil); err != nil {
+			return fmt.Errorf("failed to grant %s: %w", permission, err)
+		}
+		s.record(ctx, audit.ActionPermissionGranted, userID, permission)
+		granted = append(granted, permission)
+	}
+	for _, permission := range managed {
+		if slices.Contains(wanted, permission) || !slices.Contains(current, permission) {
+			continue
+		}
+		if err := s.repo.RevokePermission(userID, permission); err != nil {
+			return fmt.Errorf("failed to revoke %s: %w", permission, err)
+		}
+		s.record(ctx, audit.ActionPermissionRevoked, userID, permission)
+		revoked = append(revoked, permission)
+	}
+
+	if len(granted) > 0 || len(revoked) > 0 {
+		s.log(ctx).Info("permissions synced", "user_id", userID, "granted", granted, "revoked", revoked)
+	}
+	return nil
+}
+
+// record audits a permission change. Operator commands and SCIM act without
+// a user, so no actor is recorded.
+func (s *AdminService) record(ctx context.Context, action string, userID int64, permission string) {
+	if s.auditRecorder == nil {
+		return
+	}
+
+	entry := &audit.Entry{
+		Action:       action,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+		Metadata:     map[string]interface{}{"permission": permission},
+	}
+	if err := s.auditRecorder.Record(entry); err != nil {
+		s.log(ctx).Error("failed to record permission change audit entry", "error", err, "action", action, "user_id", userID)
+	}
+}
+
+// validatePermissions rejects names outside the auth registry before anything
+// is written, so a typo cannot leave a half-created user behind.
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if err := auth.ValidatePermission(permission); err != nil {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+		}
+	}
+	return nil
+}
+
+func (s *AdminService) lookup(ctx context.Context, userID int64) (*userDatamodel.User, error) {
+	u, err := s.repo.Lookup(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	return u, nil
+}
+
+func (s *AdminService) withPermissions(u *userDatamodel.User) (*User, error) {
+	permissions, err := s.repo.GetPermissions(u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return FromDataModelWithPermissions(u, permissions), nil
+}
+
+func (s *AdminService) getByEmail(email string) (*userDatamodel.User, error) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	u, err := s.repo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, email)
+		}
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,353 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/audit"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+type mockUserRepository struct {
+	users       map[string]*userDatamodel.User
+	permissions map[int64][]string
+	known       map[string]bool
+	nextID      int64
+}
+
+func newMockUserRepository() *mockUserRepository {
+	return &mockUserRepository{
+		users:       map[string]*userDatamodel.User{},
+		permissions: map[int64][]string{},
+		known:       map[string]bool{"create_expenses": true, "approve_expenses": true},
+		nextID:      1,
+	}
+}
+
+func (m *mockUserRepository) byID(id int64) *userDatamodel.User {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (m *mockUserRepository) GetByID(userID int64) (*userDatamodel.User, error) {
+	if u := m.byID(userID); u != nil {
+		return u, nil
+	}
+	return nil, user.ErrNotFound
+}
+
+func (m *mockUserRepository) GetPermissions(userID int64) ([]string, error) {
+	return m.permissions[userID], nil
+}
+
+func (m *mockUserRepository) GetByEmail(email string) (*userDatamodel.User, error) {
+	if u, ok := m.users[email]; ok {
+		return u, nil
+	}
+	return nil, user.ErrNotFound
+}
+
+func (m *mockUserRepository) Create(u *userDatamodel.User) error {
+	u.ID = m.nextID
+	m.nextID++
+	m.users[u.Email] = u
+	return nil
+}
+
+func (m *mockUserRepository) GrantPermission(userID int64, permission string, grantedBy *int64) error {
+	if !m.known[permission] {
+		return user.ErrUnknownPermission
+	}
+	for _, p := range m.permissions[userID] {
+		if p == permission {
+			return nil
+		}
+	}
+	m.permissions[userID] = append(m.permissions[userID], permission)
+	return nil
+}
+
+func (m *mockUserRepository) SetActive(userID int64, active bool) error {
+	m.byID(userID).IsActive = active
+	return nil
+}
+
+func (m *mockUserRepository) RevokeSessions(userID int64, at time.Time) error {
+	m.byID(userID).SessionsRevokedAt = &at
+	return nil
+}
+
+func (m *mockUserRepository) UpdatePassword(userID int64, passwordHash string) error {
+	m.byID(userID).PasswordHash = passwordHash
+	return nil
+}
+
+func (m *mockUserRepository) RevokePermission(userID int64, permission string) error {
+	m.permissions[userID] = slices.DeleteFunc(m.permissions[userID], func(p string) bool { return p == permission })
+	return nil
+}
+
+func (m *mockUserRepository) UpdateProfile(userID int64, name, department string) error {
+	u := m.byID(userID)
+	u.Name, u.Department = name, department
+	return nil
+}
+
+func (m *mockUserRepository) UpdateOwnProfile(userID int64, update user.ProfileUpdate) error {
+	u := m.byID(userID)
+	if update.Name != nil {
+		u.Name = *update.Name
+	}
+	if update.LoginAlerts != nil {
+		u.LoginAlerts = *update.LoginAlerts
+	}
+	if update.ExportEmails != nil {
+		u.ExportEmails = *update.ExportEmails
+	}
+	return nil
+}
+
+func (m *mockUserRepository) SetAvatar(userID int64, key *string) error {
+	m.byID(userID).AvatarKey = key
+	return nil
+}
+
+func (m *mockUserRepository) Lookup(_ context.Context, userID int64) (*userDatamodel.User, error) {
+	if u := m.byID(userID); u != nil {
+		return u, nil
+	}
+	return nil, user.ErrNotFound
+}
+
+func (m *mockUserRepository) Find(_ context.Context, email string, offset, limit int) ([]*userDatamodel.User, int64, error) {
+	var matched []*userDatamodel.User
+	for id := int64(1); id < m.nextID; id++ {
+		if u := m.byID(id); u != nil && (email == "" || u.Email == email) {
+			matched = append(matched, u)
+		}
+	}
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return nil, total, nil
+	}
+	matched = matched[offset:]
+	if limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+type recordingAudit struct {
+	entries []*audit.Entry
+}
+
+func (r *recordingAudit) Record(entry *audit.Entry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+type fakeHasher struct{}
+
+func (fakeHasher) HashPassword(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+var _ = Describe("AdminService", func() {
+	var (
+		ctx      context.Context
+		repo     *mockUserRepository
+		auditLog *recordingAudit
+		svc      *user.AdminService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = newMockUserRepository()
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		auditLog = &recordingAudit{}
+		svc = user.NewAdminService(repo, fakeHasher{}, auditLog, logger)
+	})
+
+	createJane := func() *user.User {
+		u, err := svc.CreateUser(ctx, user.CreateUserDTO{
+			Email:       "Jane@Mail.com",
+			Name:        "Jane",
+			Password:    "s3cretpass",
+			Permissions: []string{"create_expenses"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return u
+	}
+
+	It("creates an active user with a hashed password and permissions", func() {
+		u := createJane()
+
+		stored := repo.users["jane@mail.com"]
+		Expect(stored).NotTo(BeNil())
+		Expect(stored.PasswordHash).To(Equal("hashed:s3cretpass"))
+		Expect(stored.IsActive).To(BeTrue())
+		Expect(repo.permissions[u.ID]).To(ConsistOf("create_expenses"))
+	})
+
+	It("rejects duplicate emails", func() {
+		createJane()
+
+		_, err := svc.CreateUser(ctx, user.CreateUserDTO{Email: "jane@mail.com", Name: "Jane", Password: "s3cretpass"})
+		Expect(errors.Is(err, user.ErrEmailTaken)).To(BeTrue())
+	})
+
+	It("validates input before touching the repository", func() {
+		_, err := svc.CreateUser(ctx, user.CreateUserDTO{Email: "not-an-email", Name: "Jane", Password: "short"})
+		Expect(err).To(HaveOccurred())
+		Expect(repo.users).To(BeEmpty())
+	})
+
+	It("rejects permissions outside the registry before creating the user", func() {
+		_, err := svc.CreateUser(ctx, user.CreateUserDTO{
+			Email:       "jane@mail.com",
+			Name:        "Jane",
+			Password:    "s3cretpass",
+			Permissions: []string{"create_expense"},
+		})
+		Expect(errors.Is(err, user.ErrUnknownPermission)).To(BeTrue())
+		Expect(repo.users).To(BeEmpty())
+	})
+
+	It("grants permissions idempotently and rejects unknown ones", func() {
+		u := createJane()
+
+		Expect(svc.GrantPermission(ctx, "jane@mail.com", "approve_expenses")).To(Succeed())
+		Expect(svc.GrantPermission(ctx, "jane@mail.com", "approve_expenses")).To(Succeed())
+		Expect(repo.permissions[u.ID]).To(ConsistOf("create_expenses", "approve_expenses"))
+
+		err := svc.GrantPermission(ctx, "jane@mail.com", "launch_rockets")
+		Expect(errors.Is(err, user.ErrUnknownPermission)).To(BeTrue())
+	})
+
+	It("deactivates users and revokes their sessions", func() {
+		createJane()
+
+		Expect(svc.Deactivate(ctx, "jane@mail.com", "")).To(Succeed())
+		Expect(repo.users["jane@mail.com"].IsActive).To(BeFalse())
+		Expect(repo.users["jane@mail.com"].SessionsRevokedAt).NotTo(BeNil())
+	})
+
+	It("returns not found for unknown users", func() {
+		err := svc.Deactivate(ctx, "ghost@mail.com", "")
+		Expect(errors.Is(err, user.ErrNotFound)).To(BeTrue())
+	})
+
+	Describe("deactivating an approver", func() {
+		var jane, lead *user.User
+
+		BeforeEach(func() {
+			repo.known["approve_it"] = true
+			jane = createJane()
+			Expect(svc.GrantPermission(ctx, "jane@mail.com", "approve_expenses")).To(Succeed())
+			Expect(svc.GrantPermission(ctx, "jane@mail.com", "approve_it")).To(Succeed())
+
+			var err error
+			lead, err = svc.CreateUser(ctx, user.CreateUserDTO{Email: "lead@mail.com", Name: "Lead", Password: "s3cretpass"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("revokes the approver permissions and keeps the others", func() {
+			Expect(svc.Deactivate(ctx, "jane@mail.com", "")).To(Succeed())
+			Expect(repo.permissions[jane.ID]).To(ConsistOf("create_expenses"))
+			Expect(repo.permissions[lead.ID]).To(BeEmpty())
+		})
+
+		It("hands the approver permissions to the successor", func() {
+			Expect(svc.Deactivate(ctx, "jane@mail.com", "lead@mail.com")).To(Succeed())
+			Expect(repo.permissions[jane.ID]).To(ConsistOf("create_expenses"))
+			Expect(repo.permissions[lead.ID]).To(ConsistOf("approve_expenses", "approve_it"))
+		})
+
+		It("refuses to reassign to the same or an inactive user", func() {
+			err := svc.Deactivate(ctx, "jane@mail.com", "jane@mail.com")
+			Expect(errors.Is(err, user.ErrInvalidReassignment)).To(BeTrue())
+
+			Expect(svc.Deactivate(ctx, "lead@mail.com", "")).To(Succeed())
+			err = svc.Deactivate(ctx, "jane@mail.com", "lead@mail.com")
+			Expect(errors.Is(err, user.ErrInvalidReassignment)).To(BeTrue())
+			Expect(repo.users["jane@mail.com"].IsActive).To(BeTrue())
+		})
+
+		It("applies the same cascade when SCIM deactivates", func() {
+			Expect(svc.SetActive(ctx, jane.ID, false)).To(Succeed())
+			Expect(repo.permissions[jane.ID]).To(ConsistOf("create_expenses"))
+			Expect(repo.users["jane@mail.com"].SessionsRevokedAt).NotTo(BeNil())
+		})
+	})
+
+	It("resets passwords", func() {
+		createJane()
+
+		Expect(svc.ResetPassword(ctx, "jane@mail.com", "n3wpassword")).To(Succeed())
+		Expect(repo.users["jane@mail.com"].PasswordHash).To(Equal("hashed:n3wpassword"))
+
+		Expect(svc.ResetPassword(ctx, "jane@mail.com", "short")).NotTo(Succeed())
+	})
+
+	It("syncs only the managed permissions", func() {
+		u := createJane()
+		Expect(svc.GrantPermission(ctx, "jane@mail.com", "approve_expenses")).To(Succeed())
+
+		Expect(svc.SyncPermissions(ctx, u.ID, []string{"approve_expenses"}, nil)).To(Succeed())
+		Expect(repo.permissions[u.ID]).To(ConsistOf("create_expenses"))
+
+		Expect(svc.SyncPermissions(ctx, u.ID, []string{"approve_expenses"}, []string{"approve_expenses"})).To(Succeed())
+		Expect(repo.permissions[u.ID]).To(ConsistOf("create_expenses", "approve_expenses"))
+	})
+
+	It("audits every permission granted or revoked", func() {
+		u := createJane()
+		Expect(svc.SyncPermissions(ctx, u.ID, []string{"create_expenses"}, []string{"approve_expenses"})).To(Succeed())
+
+		actions := make([]string, 0, len(auditLog.entries))
+		for _, entry := range auditLog.entries {
+			Expect(entry.ResourceType).To(Equal(audit.ResourceUser))
+			Expect(entry.ResourceID).To(Equal("1"))
+			actions = append(actions, entry.Action+" "+entry.Metadata["permission"].(string))
+		}
+		Expect(actions).To(Equal([]string{
+			audit.ActionPermissionGranted + " create_expenses",
+			audit.ActionPermissionGranted + " approve_expenses",
+			audit.ActionPermissionRevoked + " create_expenses",
+		}))
+	})
+
+	It("looks users up by ID whether active or not", func() {
+		u := createJane()
+		Expect(svc.SetActive(ctx, u.ID, false)).To(Succeed())
+
+		found, err := svc.GetUser(ctx, u.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.IsActive).To(BeFalse())
+		Expect(found.Permissions).To(ConsistOf("create_expenses"))
+
+		_, err = svc.GetUser(ctx, 99)
+		Expect(errors.Is(err, user.ErrNotFound)).To(BeTrue())
+	})
+
+	It("lists users by email", func() {
+		createJane()
+
+		users, total, err := svc.ListUsers(ctx, " JANE@mail.com", 0, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(int64(1)))
+		Expect(users[0].Email).To(Equal("jane@mail.com"))
+	})
+})
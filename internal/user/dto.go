@@ -0,0 +1,101 @@
+package user
+
+import (
+	"net/mail"
+	"strings"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/core/common/validation"
+)
+
+const MinPasswordLength = 8
+
+type CreateUserDTO struct {
+	Email       string   `json:"email" validate:"required,email"`
+	Name        string   `json:"name" validate:"required,max=255"`
+	Password    string   `json:"password" validate:"required,password"`
+	Department  string   `json:"department,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+func init() {
+	validation.RegisterRule("email", func(fv *validation.FieldValidator, _ string) {
+		fv.Custom(validEmail)
+	})
+	validation.RegisterRule("password", func(fv *validation.FieldValidator, _ string) {
+		fv.MinLength(MinPasswordLength)
+	})
+}
+
+func (dto CreateUserDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// ValidatePassword applies the same rules CreateUserDTO uses to a new password.
+func ValidatePassword(password string) error {
+	validator := validation.NewValidator()
+	validator.Field("password", password).
+		Required().
+		MinLength(MinPasswordLength)
+
+	if appErr := validator.Validate(); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// UpdateProfileDTO changes the caller's own profile; omitted fields keep
+// their current value. Department is set by administrators, so it may only
+// be sent unchanged.
+type UpdateProfileDTO struct {
+	Name                    *string                           `json:"name,omitempty"`
+	Department              *string                           `json:"department,omitempty"`
+	NotificationPreferences *UpdateNotificationPreferencesDTO `json:"notification_preferences,omitempty"`
+}
+
+type UpdateNotificationPreferencesDTO struct {
+	LoginAlerts  *bool `json:"login_alerts,omitempty"`
+	ExportEmails *bool `json:"export_emails,omitempty"`
+}
+
+func (dto UpdateProfileDTO) Validate() error {
+	validator := validation.NewValidator()
+	if dto.Name != nil {
+		validator.Field("name", strings.TrimSpace(*dto.Name)).
+			Required().
+			MaxLength(255)
+	}
+
+	if appErr := validator.Validate(); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// ChangePasswordDTO replaces the caller's password; the current one must be
+// given so a stolen session cannot lock the user out.
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,password"`
+}
+
+func (dto ChangePasswordDTO) Validate() error {
+	if appErr := validation.Struct(dto); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+func validEmail(value interface{}) *errors.AppError {
+	email, _ := value.(string)
+	if email == "" {
+		return nil
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return errors.NewLocalizedFieldError("email", "validation.email", nil, errors.ErrCodeValidationFailed)
+	}
+	return nil
+}
@@ -1,7 +1,8 @@
 package user
 
 import (
-	"log/slog"
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/frahmantamala/expense-management/internal"
@@ -12,6 +13,11 @@ import (
 type ServiceAPI interface {
 	GetByID(userID int64) (*User, error)
 	GetPermissions(userID int64) ([]string, error)
+	UpdateOwnProfile(ctx context.Context, userID int64, dto UpdateProfileDTO) (*User, error)
+	ChangePassword(ctx context.Context, userID int64, dto ChangePasswordDTO) error
+	UploadAvatar(ctx context.Context, userID int64, upload AvatarUpload) (*AvatarResponse, error)
+	GetAvatar(ctx context.Context, userID int64) (*AvatarResponse, error)
+	DeleteAvatar(ctx context.Context, userID int64) error
 }
 
 type Handler struct {
@@ -20,39 +26,201 @@ type Handler struct {
 }
 
 func NewHandler(svc ServiceAPI) *Handler {
-	lg := logger.LoggerWrapper()
-	if lg == nil {
-		lg = slog.Default()
-	}
 	return &Handler{
-		BaseHandler: transport.NewBaseHandler(lg),
+		BaseHandler: transport.NewBaseHandler(logger.LoggerWrapper()),
 		Service:     svc,
 	}
 }
 
 // GetCurrentUser handles GET /users/me
+// @Summary      Current user
+// @Description  Returns the authenticated user with their permissions.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        If-None-Match  header  string  false  "ETag from a previous response"
+// @Success      200  {object}  User
+// @Success      304  "Not modified"
+// @Failure      401  {object}  transport.ErrorResponse
+// @Router       /users/me [get]
 func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
-	h.Logger.Info("GetCurrentUser: starting request")
+	h.Log(r).Info("GetCurrentUser: starting request")
 
 	user, ok := internal.UserFromContext(r.Context())
 	if !ok || user == nil {
-		h.Logger.Error("GetCurrentUser: user not found in context", "ok", ok, "user_nil", user == nil)
-		h.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		h.Log(r).Error("GetCurrentUser: user not found in context", "ok", ok, "user_nil", user == nil)
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	h.Logger.Info("GetCurrentUser: user found in context", "user_id", user.ID, "email", user.Email)
+	h.Log(r).Info("GetCurrentUser: user found in context", "user_id", user.ID, "email", user.Email)
 
 	u, err := h.Service.GetByID(user.ID)
 	if err != nil {
-		h.Logger.Error("GetCurrentUser: service GetByID failed", "user_id", user.ID, "error", err)
-		h.WriteError(w, http.StatusInternalServerError, "internal server error")
+		h.Log(r).Error("GetCurrentUser: service GetByID failed", "user_id", user.ID, "error", err)
+		h.WriteError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	h.Log(r).Info("GetCurrentUser: service returned user", "user_id", u.ID, "email", u.Email, "name", u.Name)
+
+	h.Log(r).Info("GetCurrentUser: sending response", "user_id", u.ID, "email", u.Email, "name", u.Name)
+
+	h.WriteJSONCached(w, r, http.StatusOK, u, "private, no-cache")
+}
+
+// UpdateCurrentUser godoc
+// @Summary      Update current user
+// @Description  Changes the caller's name and notification preferences; omitted fields keep their current value. Department is set by an administrator and may only be sent unchanged.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      UpdateProfileDTO  true  "Fields to change"
+// @Success      200   {object}  User
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Router       /users/me [patch]
+func (h *Handler) UpdateCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	h.Logger.Info("GetCurrentUser: service returned user", "user_id", u.ID, "email", u.Email, "name", u.Name)
+	var dto UpdateProfileDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
 
-	h.Logger.Info("GetCurrentUser: sending response", "user_id", u.ID, "email", u.Email, "name", u.Name)
+	u, err := h.Service.UpdateOwnProfile(r.Context(), user.ID, dto)
+	if err != nil {
+		h.Log(r).Error("UpdateCurrentUser: service error", "error", err, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
 
 	h.WriteJSON(w, http.StatusOK, u)
 }
+
+// ChangePassword godoc
+// @Summary      Change password
+// @Description  Replaces the caller's password. current_password must match the password in use; new_password follows the usual password rules.
+// @Tags         users
+// @Accept       json
+// @Security     BearerAuth
+// @Param        body  body  ChangePasswordDTO  true  "Current and new password"
+// @Success      204   "Password changed"
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Router       /users/me/password [put]
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var dto ChangePasswordDTO
+	if !h.DecodeJSON(w, r, &dto) {
+		return
+	}
+
+	if err := h.Service.ChangePassword(r.Context(), user.ID, dto); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UploadAvatar godoc
+// @Summary      Upload avatar
+// @Description  Sets the caller's avatar to a JPEG, PNG or WebP image of at most 2 MB, replacing any earlier one.
+// @Tags         users
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file  formData  file  true  "Avatar image"
+// @Success      200   {object}  AvatarResponse
+// @Failure      400   {object}  transport.AppErrorResponse
+// @Failure      401   {object}  transport.ErrorResponse
+// @Router       /users/me/avatar [put]
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxAvatarSize+transport.MultipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.HandleError(w, r, ErrAvatarTooLarge)
+			return
+		}
+		h.WriteError(w, r, http.StatusBadRequest, "multipart form with a file field is required")
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.Service.UploadAvatar(r.Context(), user.ID, AvatarUpload{Size: header.Size, Body: file})
+	if err != nil {
+		h.Log(r).Error("UploadAvatar: service error", "error", err, "user_id", user.ID)
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetAvatar godoc
+// @Summary      Get avatar
+// @Description  Returns a short-lived download link for the caller's avatar.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  AvatarResponse
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /users/me/avatar [get]
+func (h *Handler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	resp, err := h.Service.GetAvatar(r.Context(), user.ID)
+	if err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	h.WriteJSON(w, http.StatusOK, resp)
+}
+
+// DeleteAvatar godoc
+// @Summary      Remove avatar
+// @Tags         users
+// @Security     BearerAuth
+// @Success      204  "Avatar removed"
+// @Failure      401  {object}  transport.ErrorResponse
+// @Failure      404  {object}  transport.AppErrorResponse
+// @Router       /users/me/avatar [delete]
+func (h *Handler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := internal.UserFromContext(r.Context())
+	if !ok || user == nil {
+		h.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.Service.DeleteAvatar(r.Context(), user.ID); err != nil {
+		h.HandleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,8 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
 	"github.com/frahmantamala/expense-management/internal/user"
@@ -21,11 +25,11 @@ func (r *Repository) GetByID(userID int64) (*userDatamodel.User, error) {
 	var u userDatamodel.User
 	var department sql.NullString
 
-	query := `SELECT id, email, name, department, is_active, password_hash, created_at, updated_at
+	query := `SELECT id, email, name, department, is_active, password_hash, login_alerts, export_emails, avatar_key, created_at, updated_at
 			  FROM users WHERE id = ? AND is_active = true`
 
 	row := r.db.Raw(query, userID).Row()
-	if err := row.Scan(&u.ID, &u.Email, &u.Name, &department, &u.IsActive, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt); err != nil {
+	if err := row.Scan(&u.ID, &u.Email, &u.Name, &department, &u.IsActive, &u.PasswordHash, &u.LoginAlerts, &u.ExportEmails, &u.AvatarKey, &u.CreatedAt, &u.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows || err == gorm.ErrRecordNotFound {
 			return nil, user.ErrNotFound
 		}
@@ -60,3 +64,172 @@ func (r *Repository) GetPermissions(userID int64) ([]string, error) {
 	}
 	return permissions, nil
 }
+
+func (r *Repository) GetByEmail(email string) (*userDatamodel.User, error) {
+	var u userDatamodel.User
+	err := r.db.Where("email = ?", email).First(&u).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *Repository) Create(u *userDatamodel.User) error {
+	return r.db.Create(u).Error
+}
+
+// GrantPermission is idempotent: granting a permission the user already has
+// succeeds without creating a duplicate row.
+func (r *Repository) GrantPermission(userID int64, permission string, grantedBy *int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var p userDatamodel.Permission
+		if err := tx.Where("name = ?", permission).First(&p).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", user.ErrUnknownPermission, permission)
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&userDatamodel.UserPermission{}).
+			Where("user_id = ? AND permission_id = ?", userID, p.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		return tx.Create(&userDatamodel.UserPermission{
+			UserID:       userID,
+			PermissionID: p.ID,
+			GrantedBy:    grantedBy,
+		}).Error
+	})
+}
+
+func (r *Repository) SetActive(userID int64, active bool) error {
+	result := r.db.Model(&userDatamodel.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"is_active": active, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) RevokeSessions(userID int64, at time.Time) error {
+	result := r.db.Model(&userDatamodel.User{}).
+		Where("id = ?", userID).
+		Update("sessions_revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) UpdatePassword(userID int64, passwordHash string) error {
+	result := r.db.Model(&userDatamodel.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"password_hash": passwordHash, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) RevokePermission(userID int64, permission string) error {
+	return r.db.Exec(`DELETE FROM user_permissions
+WHERE user_id = ? AND permission_id IN (SELECT id FROM permissions WHERE name = ?)`, userID, permission).Error
+}
+
+func (r *Repository) UpdateProfile(userID int64, name, department string) error {
+	result := r.db.Model(&userDatamodel.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"name": name, "department": department, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) UpdateOwnProfile(userID int64, update user.ProfileUpdate) error {
+	fields := map[string]interface{}{"updated_at": gorm.Expr("CURRENT_TIMESTAMP")}
+	if update.Name != nil {
+		fields["name"] = *update.Name
+	}
+	if update.LoginAlerts != nil {
+		fields["login_alerts"] = *update.LoginAlerts
+	}
+	if update.ExportEmails != nil {
+		fields["export_emails"] = *update.ExportEmails
+	}
+
+	result := r.db.Model(&userDatamodel.User{}).Where("id = ?", userID).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) SetAvatar(userID int64, key *string) error {
+	result := r.db.Model(&userDatamodel.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"avatar_key": key, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) Lookup(ctx context.Context, userID int64) (*userDatamodel.User, error) {
+	var u userDatamodel.User
+	err := r.db.WithContext(ctx).Where("id = ?", userID).First(&u).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *Repository) Find(ctx context.Context, email string, offset, limit int) ([]*userDatamodel.User, int64, error) {
+	query := func() *gorm.DB {
+		q := r.db.WithContext(ctx).Model(&userDatamodel.User{})
+		if email != "" {
+			q = q.Where("email = ?", email)
+		}
+		return q
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*userDatamodel.User
+	err := query().Order("id ASC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, total, err
+}
@@ -0,0 +1,209 @@
+package user
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	errors "github.com/frahmantamala/expense-management/internal"
+	"github.com/frahmantamala/expense-management/internal/auth"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxAvatarSize bounds avatar uploads; larger bodies are rejected unread.
+	MaxAvatarSize = 2 << 20
+	// avatarURLExpiry is how long an avatar link handed to a client works.
+	avatarURLExpiry = 15 * time.Minute
+)
+
+// avatarTypes maps sniffed content types to the extension stored in the key.
+var avatarTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+var (
+	ErrDepartmentReadOnly = errors.NewValidationFieldError("department", "department is set by an administrator", errors.ErrCodeValidationFailed)
+	ErrWrongPassword      = errors.NewValidationFieldError("current_password", "current password is incorrect", errors.ErrCodeInvalidCredentials)
+	ErrAvatarNotFound     = errors.NewNotFoundError("User has no avatar", errors.ErrCodeAvatarNotFound)
+	ErrUnsupportedAvatar  = errors.NewValidationFieldError("file", "avatar must be a JPEG, PNG or WebP image", errors.ErrCodeInvalidAvatar)
+	ErrAvatarTooLarge     = errors.NewValidationFieldError("file", "avatar must be at most 2 MB", errors.ErrCodeInvalidAvatar)
+)
+
+// ProfileUpdate holds what a user may change on their own profile; nil
+// fields are left alone.
+type ProfileUpdate struct {
+	Name         *string
+	LoginAlerts  *bool
+	ExportEmails *bool
+}
+
+func (u ProfileUpdate) empty() bool {
+	return u.Name == nil && u.LoginAlerts == nil && u.ExportEmails == nil
+}
+
+type AvatarUpload struct {
+	Size int64
+	Body io.Reader
+}
+
+type AvatarResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UpdateOwnProfile changes the caller's name and notification preferences
+// and returns the updated user.
+func (s *Service) UpdateOwnProfile(ctx context.Context, userID int64, dto UpdateProfileDTO) (*User, error) {
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	}
+	if dto.Department != nil && strings.TrimSpace(*dto.Department) != current.Department {
+		return nil, ErrDepartmentReadOnly
+	}
+
+	var update ProfileUpdate
+	if dto.Name != nil {
+		name := strings.TrimSpace(*dto.Name)
+		update.Name = &name
+	}
+	if prefs := dto.NotificationPreferences; prefs != nil {
+		update.LoginAlerts = prefs.LoginAlerts
+		update.ExportEmails = prefs.ExportEmails
+	}
+
+	if !update.empty() {
+		if err := s.repo.UpdateOwnProfile(userID, update); err != nil {
+			return nil, fmt.Errorf("failed to update profile: %w", err)
+		}
+		s.log(ctx).Info("profile updated", "user_id", userID)
+	}
+	return s.GetByID(userID)
+}
+
+// ChangePassword replaces the caller's password once the current one is
+// verified.
+func (s *Service) ChangePassword(ctx context.Context, userID int64, dto ChangePasswordDTO) error {
+	if err := dto.Validate(); err != nil {
+		return err
+	}
+
+	current, err := s.repo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user by ID: %w", err)
+	}
+	if err := auth.VerifyPassword(current.PasswordHash, dto.CurrentPassword); err != nil {
+		s.log(ctx).Warn("password change with wrong current password", "user_id", userID)
+		return ErrWrongPassword
+	}
+
+	hash, err := s.hasher.HashPassword(dto.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.repo.UpdatePassword(userID, hash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	s.log(ctx).Info("password changed", "user_id", userID)
+	return nil
+}
+
+// UploadAvatar stores the caller's avatar, replacing any earlier one. The
+// image type is sniffed from its contents rather than trusted from the
+// client.
+func (s *Service) UploadAvatar(ctx context.Context, userID int64, upload AvatarUpload) (*AvatarResponse, error) {
+	if upload.Size > MaxAvatarSize {
+		return nil, ErrAvatarTooLarge
+	}
+
+	current, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	}
+	previous := current.AvatarKey
+
+	body := bufio.NewReaderSize(upload.Body, 512)
+	head, _ := body.Peek(512)
+	contentType := http.DetectContentType(head)
+	ext, ok := avatarTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedAvatar
+	}
+
+	key := fmt.Sprintf("avatars/%d/%s%s", userID, uuid.NewString(), ext)
+	if err := s.blob.Put(ctx, key, body, storage.PutOptions{ContentType: contentType, Size: upload.Size}); err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+
+	if err := s.repo.SetAvatar(userID, &key); err != nil {
+		if delErr := s.blob.Delete(ctx, key); delErr != nil {
+			s.log(ctx).Warn("failed to remove orphaned avatar", "error", delErr, "key", key)
+		}
+		return nil, fmt.Errorf("failed to save avatar: %w", err)
+	}
+	if previous != nil {
+		if err := s.blob.Delete(ctx, *previous); err != nil {
+			s.log(ctx).Warn("failed to remove replaced avatar", "error", err, "key", *previous)
+		}
+	}
+
+	s.log(ctx).Info("avatar uploaded", "user_id", userID, "content_type", contentType)
+	return s.avatarResponse(ctx, key)
+}
+
+// GetAvatar returns a short-lived link to the caller's avatar.
+func (s *Service) GetAvatar(ctx context.Context, userID int64) (*AvatarResponse, error) {
+	current, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	}
+	if current.AvatarKey == nil {
+		return nil, ErrAvatarNotFound
+	}
+	return s.avatarResponse(ctx, *current.AvatarKey)
+}
+
+// DeleteAvatar removes the caller's avatar.
+func (s *Service) DeleteAvatar(ctx context.Context, userID int64) error {
+	current, err := s.repo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user by ID: %w", err)
+	}
+	if current.AvatarKey == nil {
+		return ErrAvatarNotFound
+	}
+	key := *current.AvatarKey
+
+	if err := s.repo.SetAvatar(userID, nil); err != nil {
+		return fmt.Errorf("failed to remove avatar: %w", err)
+	}
+	if err := s.blob.Delete(ctx, key); err != nil {
+		s.log(ctx).Warn("failed to remove deleted avatar", "error", err, "key", key)
+	}
+	s.log(ctx).Info("avatar removed", "user_id", userID)
+	return nil
+}
+
+func (s *Service) avatarResponse(ctx context.Context, key string) (*AvatarResponse, error) {
+	url, err := s.blob.SignedURL(ctx, key, avatarURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign avatar url: %w", err)
+	}
+	return &AvatarResponse{
+		URL:       url,
+		ExpiresAt: time.Now().Add(avatarURLExpiry).UTC(),
+	}, nil
+}
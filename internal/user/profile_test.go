@@ -0,0 +1,151 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/internal/auth"
+	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/internal/user"
+)
+
+type bcryptHasher struct{}
+
+func (bcryptHasher) HashPassword(password string) (string, error) {
+	return auth.HashPassword(password, 4)
+}
+
+var _ = Describe("Service profile updates", func() {
+	var (
+		ctx  context.Context
+		repo *mockUserRepository
+		blob storage.Blob
+		svc  *user.Service
+	)
+
+	pngHeader := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = newMockUserRepository()
+		var err error
+		blob, err = storage.NewLocal(GinkgoT().TempDir(), "http://localhost:8080", "secret")
+		Expect(err).NotTo(HaveOccurred())
+		svc = user.NewService(repo, bcryptHasher{}, blob, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		hash, err := auth.HashPassword("old-password", 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo.Create(&userDatamodel.User{
+			Email:        "jane@mail.com",
+			Name:         "Jane",
+			Department:   "Finance",
+			PasswordHash: hash,
+			IsActive:     true,
+			LoginAlerts:  true,
+			ExportEmails: true,
+		})).To(Succeed())
+	})
+
+	ptr := func(s string) *string { return &s }
+	off := false
+
+	It("changes the name and notification preferences", func() {
+		u, err := svc.UpdateOwnProfile(ctx, 1, user.UpdateProfileDTO{
+			Name:                    ptr("  Jane Doe "),
+			NotificationPreferences: &user.UpdateNotificationPreferencesDTO{ExportEmails: &off},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Name).To(Equal("Jane Doe"))
+		Expect(u.NotificationPreferences).To(Equal(user.NotificationPreferences{LoginAlerts: true, ExportEmails: false}))
+	})
+
+	It("accepts the department unchanged but refuses a new one", func() {
+		_, err := svc.UpdateOwnProfile(ctx, 1, user.UpdateProfileDTO{Department: ptr("Finance")})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = svc.UpdateOwnProfile(ctx, 1, user.UpdateProfileDTO{Department: ptr("Sales")})
+		Expect(err).To(Equal(user.ErrDepartmentReadOnly))
+		Expect(repo.byID(1).Department).To(Equal("Finance"))
+	})
+
+	It("refuses a blank name", func() {
+		_, err := svc.UpdateOwnProfile(ctx, 1, user.UpdateProfileDTO{Name: ptr(" ")})
+		Expect(err).To(HaveOccurred())
+		Expect(repo.byID(1).Name).To(Equal("Jane"))
+	})
+
+	Describe("ChangePassword", func() {
+		It("replaces the password once the current one is verified", func() {
+			Expect(svc.ChangePassword(ctx, 1, user.ChangePasswordDTO{CurrentPassword: "old-password", NewPassword: "new-password"})).To(Succeed())
+			Expect(auth.VerifyPassword(repo.byID(1).PasswordHash, "new-password")).To(Succeed())
+		})
+
+		It("refuses a wrong current password", func() {
+			err := svc.ChangePassword(ctx, 1, user.ChangePasswordDTO{CurrentPassword: "guess-password", NewPassword: "new-password"})
+			Expect(err).To(Equal(user.ErrWrongPassword))
+			Expect(auth.VerifyPassword(repo.byID(1).PasswordHash, "old-password")).To(Succeed())
+		})
+
+		It("applies the password rules to the new password", func() {
+			err := svc.ChangePassword(ctx, 1, user.ChangePasswordDTO{CurrentPassword: "old-password", NewPassword: "short"})
+			Expect(err).To(HaveOccurred())
+			Expect(auth.VerifyPassword(repo.byID(1).PasswordHash, "old-password")).To(Succeed())
+		})
+	})
+
+	Describe("avatars", func() {
+		upload := func(body []byte) (*user.AvatarResponse, error) {
+			return svc.UploadAvatar(ctx, 1, user.AvatarUpload{Size: int64(len(body)), Body: bytes.NewReader(body)})
+		}
+
+		It("stores an avatar and replaces the previous one", func() {
+			resp, err := upload(pngHeader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.URL).To(ContainSubstring("avatars/1/"))
+			first := *repo.byID(1).AvatarKey
+			Expect(first).To(HaveSuffix(".png"))
+
+			_, err = upload(pngHeader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*repo.byID(1).AvatarKey).NotTo(Equal(first))
+			_, err = blob.Get(ctx, first)
+			Expect(errors.Is(err, storage.ErrNotFound)).To(BeTrue())
+
+			u, err := svc.GetByID(1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.HasAvatar).To(BeTrue())
+		})
+
+		It("refuses files that are not images", func() {
+			_, err := upload([]byte("%PDF-1.4 not an avatar"))
+			Expect(err).To(Equal(user.ErrUnsupportedAvatar))
+		})
+
+		It("refuses avatars over the size limit", func() {
+			_, err := svc.UploadAvatar(ctx, 1, user.AvatarUpload{Size: user.MaxAvatarSize + 1, Body: strings.NewReader("")})
+			Expect(err).To(Equal(user.ErrAvatarTooLarge))
+		})
+
+		It("removes the avatar", func() {
+			_, err := upload(pngHeader)
+			Expect(err).NotTo(HaveOccurred())
+			key := *repo.byID(1).AvatarKey
+
+			Expect(svc.DeleteAvatar(ctx, 1)).To(Succeed())
+			Expect(repo.byID(1).AvatarKey).To(BeNil())
+			_, err = blob.Get(ctx, key)
+			Expect(errors.Is(err, storage.ErrNotFound)).To(BeTrue())
+
+			_, err = svc.GetAvatar(ctx, 1)
+			Expect(err).To(Equal(user.ErrAvatarNotFound))
+		})
+	})
+})
@@ -1,26 +1,61 @@
 package user
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
 	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
+	"github.com/frahmantamala/expense-management/internal/storage"
+	"github.com/frahmantamala/expense-management/pkg/logger"
 )
 
 type RepositoryAPI interface {
 	GetByID(userID int64) (*userDatamodel.User, error)
 	GetPermissions(userID int64) ([]string, error)
+	GetByEmail(email string) (*userDatamodel.User, error)
+	Create(u *userDatamodel.User) error
+	GrantPermission(userID int64, permission string, grantedBy *int64) error
+	SetActive(userID int64, active bool) error
+	// RevokeSessions refuses every token issued to the user before at.
+	RevokeSessions(userID int64, at time.Time) error
+	UpdatePassword(userID int64, passwordHash string) error
+	RevokePermission(userID int64, permission string) error
+	UpdateProfile(userID int64, name, department string) error
+	// UpdateOwnProfile applies the fields of update that are set.
+	UpdateOwnProfile(userID int64, update ProfileUpdate) error
+	// SetAvatar points the user at a stored avatar, or at none for a nil
+	// key.
+	SetAvatar(userID int64, key *string) error
+	// Lookup returns the user, active or not, within the tenant ctx is
+	// scoped to.
+	Lookup(ctx context.Context, userID int64) (*userDatamodel.User, error)
+	// Find pages through the users of the tenant ctx is scoped to, ordered
+	// by ID. An empty email matches every user.
+	Find(ctx context.Context, email string, offset, limit int) ([]*userDatamodel.User, int64, error)
 }
 
 type Service struct {
-	repo RepositoryAPI
+	repo   RepositoryAPI
+	hasher PasswordHasher
+	blob   storage.Blob
+	logger *slog.Logger
 }
 
-func NewService(repo RepositoryAPI) *Service {
+func NewService(repo RepositoryAPI, hasher PasswordHasher, blob storage.Blob, logger *slog.Logger) *Service {
 	return &Service{
-		repo: repo,
+		repo:   repo,
+		hasher: hasher,
+		blob:   blob,
+		logger: logger,
 	}
 }
 
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromOr(ctx, s.logger)
+}
+
 func (s *Service) GetByID(userID int64) (*User, error) {
 	dataUser, err := s.repo.GetByID(userID)
 	if err != nil {
@@ -37,6 +72,21 @@ func (s *Service) GetByID(userID int64) (*User, error) {
 	return FromDataModelWithPermissions(dataUser, permissions), nil
 }
 
+// GetByEmail finds a user by email across every tenant; emails are unique.
+func (s *Service) GetByEmail(email string) (*User, error) {
+	dataUser, err := s.repo.GetByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	permissions, err := s.repo.GetPermissions(dataUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+
+	return FromDataModelWithPermissions(dataUser, permissions), nil
+}
+
 func (s *Service) GetPermissions(userID int64) ([]string, error) {
 	return s.repo.GetPermissions(userID)
 }
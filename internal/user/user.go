@@ -4,19 +4,36 @@ import (
 	"errors"
 	"time"
 
+	"github.com/frahmantamala/expense-management/internal/auth"
 	userDatamodel "github.com/frahmantamala/expense-management/internal/core/datamodel/user"
 )
 
 type User struct {
-	ID           int64     `json:"id"`
-	Email        string    `json:"email"`
-	Name         string    `json:"name"`
-	PasswordHash string    `json:"-"`
-	Department   string    `json:"department"`
-	IsActive     bool      `json:"is_active"`
-	Permissions  []string  `json:"permissions,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int64    `json:"id"`
+	TenantID     int64    `json:"tenant_id"`
+	Email        string   `json:"email"`
+	Name         string   `json:"name"`
+	PasswordHash string   `json:"-"`
+	Department   string   `json:"department"`
+	IsActive     bool     `json:"is_active"`
+	Permissions  []string `json:"permissions,omitempty"`
+	// NotificationPreferences are the emails the user chose to receive.
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	// AvatarKey is where the avatar is stored; clients fetch it through
+	// GET /users/me/avatar when HasAvatar is set.
+	AvatarKey *string   `json:"-"`
+	HasAvatar bool      `json:"has_avatar"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreferences opt a user out of emails about their own account
+// activity. Digest emails have their own preferences.
+type NotificationPreferences struct {
+	// LoginAlerts are sent on a login from a new device or location.
+	LoginAlerts bool `json:"login_alerts"`
+	// ExportEmails are sent when a requested export is ready or has failed.
+	ExportEmails bool `json:"export_emails"`
 }
 
 func (u *User) HasPermission(permission string) bool {
@@ -40,12 +57,11 @@ func (u *User) HasAnyPermission(permissions []string) bool {
 }
 
 func (u *User) IsManager() bool {
-	managerPerms := []string{"approve_expenses", "reject_expenses", "admin"}
-	return u.HasAnyPermission(managerPerms)
+	return u.HasAnyPermission([]string{string(auth.PermApproveExpenses), string(auth.PermRejectExpenses), string(auth.PermAdmin)})
 }
 
 func (u *User) IsAdmin() bool {
-	return u.HasPermission("admin")
+	return u.HasPermission(string(auth.PermAdmin))
 }
 
 func (u *User) IsActiveUser() bool {
@@ -72,11 +88,15 @@ var ErrNotFound = errors.New("user not found")
 func ToDataModel(u *User) *userDatamodel.User {
 	return &userDatamodel.User{
 		ID:           u.ID,
+		TenantID:     u.TenantID,
 		Email:        u.Email,
 		Name:         u.Name,
 		PasswordHash: u.PasswordHash,
 		Department:   u.Department,
 		IsActive:     u.IsActive,
+		LoginAlerts:  u.NotificationPreferences.LoginAlerts,
+		ExportEmails: u.NotificationPreferences.ExportEmails,
+		AvatarKey:    u.AvatarKey,
 		CreatedAt:    u.CreatedAt,
 		UpdatedAt:    u.UpdatedAt,
 	}
@@ -85,14 +105,21 @@ func ToDataModel(u *User) *userDatamodel.User {
 func FromDataModel(u *userDatamodel.User) *User {
 	return &User{
 		ID:           u.ID,
+		TenantID:     u.TenantID,
 		Email:        u.Email,
 		Name:         u.Name,
 		PasswordHash: u.PasswordHash,
 		Department:   u.Department,
 		IsActive:     u.IsActive,
-		CreatedAt:    u.CreatedAt,
-		UpdatedAt:    u.UpdatedAt,
-		Permissions:  []string{},
+		NotificationPreferences: NotificationPreferences{
+			LoginAlerts:  u.LoginAlerts,
+			ExportEmails: u.ExportEmails,
+		},
+		AvatarKey:   u.AvatarKey,
+		HasAvatar:   u.AvatarKey != nil,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+		Permissions: []string{},
 	}
 }
 
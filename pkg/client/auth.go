@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrNoRefreshToken is returned by Refresh before Login or WithTokens.
+var ErrNoRefreshToken = errors.New("client: no refresh token")
+
+// Login authenticates with email and password and stores the issued tokens.
+// After repeated failures the server wants a CAPTCHA; see IsCaptchaRequired.
+func (c *Client) Login(ctx context.Context, email, password string) (*Tokens, error) {
+	return c.LoginWithCaptcha(ctx, email, password, "")
+}
+
+// LoginWithCaptcha logs in with the token of a CAPTCHA the user solved.
+func (c *Client) LoginWithCaptcha(ctx context.Context, email, password, captchaToken string) (*Tokens, error) {
+	body := map[string]string{"email": email, "password": password}
+	if captchaToken != "" {
+		body["captcha_token"] = captchaToken
+	}
+
+	var tokens Tokens
+	err := c.doWithRetry(ctx, request{
+		method: http.MethodPost,
+		path:   "/auth/login",
+		body:   body,
+	}, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setTokens(tokens)
+	return &tokens, nil
+}
+
+// Refresh exchanges the stored refresh token for a new token pair. Callers
+// rarely need it: authenticated calls refresh automatically on 401.
+func (c *Client) Refresh(ctx context.Context) (*Tokens, error) {
+	refreshToken := c.Tokens().RefreshToken
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	var tokens Tokens
+	err := c.doWithRetry(ctx, request{
+		method: http.MethodPost,
+		path:   "/auth/refresh",
+		body:   map[string]string{"refresh_token": refreshToken},
+	}, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setTokens(tokens)
+	return &tokens, nil
+}
+
+// Logout calls the logout endpoint and forgets the local tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/logout", auth: true}, nil); err != nil {
+		return err
+	}
+	c.setTokens(Tokens{})
+	return nil
+}
+
+// Me returns the authenticated user with their permissions.
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var u User
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/users/me", auth: true}, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Categories lists the active expense categories.
+func (c *Client) Categories(ctx context.Context) ([]Category, error) {
+	var resp struct {
+		Categories []Category `json:"categories"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/categories"}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Categories, nil
+}
@@ -0,0 +1,291 @@
+// Package client is a Go SDK for the expense management HTTP API (/api/v1).
+//
+// It wraps login and token refresh, expenses, approvals and payments with
+// typed methods, refreshes the access token automatically when it expires,
+// retries transient failures and iterates over paginated expense lists.
+//
+//	c := client.New("http://localhost:8080")
+//	if _, err := c.Login(ctx, "jane@example.com", "secret"); err != nil { ... }
+//	it := c.Expenses(ctx, client.ListExpensesParams{Status: "pending_approval"})
+//	for it.Next() { fmt.Println(it.Expense().ID) }
+//	if err := it.Err(); err != nil { ... }
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAPIPrefix    = "/api/v1"
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 200 * time.Millisecond
+	maxRetryAfter       = 30 * time.Second
+)
+
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	userAgent    string
+	maxRetries   int
+	retryBackoff time.Duration
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	onTokens     func(Tokens)
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default http.Client (30s timeout).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetries sets how many times transient failures are retried and the base
+// delay of the exponential backoff between attempts.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithTokens starts the client with a previously issued token pair.
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) {
+		c.accessToken = tokens.AccessToken
+		c.refreshToken = tokens.RefreshToken
+	}
+}
+
+// WithTokenCallback is invoked whenever login or an automatic refresh issues
+// new tokens, so callers can persist them.
+func WithTokenCallback(fn func(Tokens)) Option {
+	return func(c *Client) { c.onTokens = fn }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New creates a client for the API served at baseURL, e.g.
+// "http://localhost:8080". The /api/v1 prefix is appended unless baseURL
+// already contains an /api/ path.
+func New(baseURL string, opts ...Option) *Client {
+	base := strings.TrimRight(baseURL, "/")
+	if !strings.Contains(base, "/api/") {
+		base += DefaultAPIPrefix
+	}
+
+	c := &Client{
+		baseURL:      base,
+		httpClient:   &http.Client{Timeout: DefaultTimeout},
+		userAgent:    "expense-management-go-client",
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tokens returns the current token pair.
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Tokens{AccessToken: c.accessToken, RefreshToken: c.refreshToken}
+}
+
+func (c *Client) setTokens(t Tokens) {
+	c.mu.Lock()
+	c.accessToken = t.AccessToken
+	c.refreshToken = t.RefreshToken
+	cb := c.onTokens
+	c.mu.Unlock()
+
+	if cb != nil {
+		cb(t)
+	}
+}
+
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	auth   bool
+}
+
+// do sends the request, retrying transient failures and refreshing the access
+// token once when an authenticated call is rejected with 401.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	err := c.doWithRetry(ctx, req, out)
+
+	var apiErr *APIError
+	if req.auth && asAPIError(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized && c.Tokens().RefreshToken != "" {
+		if _, refreshErr := c.Refresh(ctx); refreshErr != nil {
+			return err
+		}
+		return c.doWithRetry(ctx, req, out)
+	}
+	return err
+}
+
+func (c *Client) doWithRetry(ctx context.Context, req request, out interface{}) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("client: encode request body: %w", err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt, lastErr)); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.send(ctx, req, payload)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			if isIdempotent(req.method) {
+				continue
+			}
+			return err
+		}
+
+		lastErr = c.decode(resp, out)
+		if lastErr == nil || !retryable(req.method, lastErr) {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+func (c *Client) send(ctx context.Context, req request, payload []byte) (*http.Response, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("client: build request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.auth {
+		if token := c.Tokens().AccessToken; token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+func (c *Client) decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("client: read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return newAPIError(resp, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("client: decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) backoff(attempt int, lastErr error) time.Duration {
+	var apiErr *APIError
+	if asAPIError(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	return c.retryBackoff * time.Duration(1<<(attempt-1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether a failed response may be retried. 429 means the
+// server rejected the request before doing any work, so it is safe for every
+// method; gateway errors are only retried for idempotent methods.
+func retryable(method string, err error) bool {
+	var apiErr *APIError
+	if !asAPIError(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, maxRetryAfter)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return min(d, maxRetryAfter)
+		}
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
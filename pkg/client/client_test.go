@@ -0,0 +1,173 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/frahmantamala/expense-management/pkg/client"
+)
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+var _ = Describe("Client", func() {
+	var (
+		ctx    context.Context
+		mux    *http.ServeMux
+		server *httptest.Server
+		c      *client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		c = client.New(server.URL, client.WithRetries(2, time.Millisecond))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("logs in and sends the access token on authenticated calls", func() {
+		mux.HandleFunc("POST /api/v1/auth/login", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, client.Tokens{AccessToken: "access-1", RefreshToken: "refresh-1"})
+		})
+		mux.HandleFunc("GET /api/v1/users/me", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer access-1"))
+			writeJSON(w, http.StatusOK, client.User{ID: 7, Email: "jane@example.com"})
+		})
+
+		_, err := c.Login(ctx, "jane@example.com", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		u, err := c.Me(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.ID).To(Equal(int64(7)))
+	})
+
+	It("sends a CAPTCHA token when the login asks for one", func() {
+		mux.HandleFunc("POST /api/v1/auth/login", func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			if body["captcha_token"] == "" {
+				writeJSON(w, http.StatusPreconditionRequired, map[string]interface{}{"code": 428, "message": "captcha required"})
+				return
+			}
+			Expect(body["captcha_token"]).To(Equal("solved"))
+			writeJSON(w, http.StatusOK, client.Tokens{AccessToken: "access-1", RefreshToken: "refresh-1"})
+		})
+
+		_, err := c.Login(ctx, "jane@example.com", "secret")
+		Expect(client.IsCaptchaRequired(err)).To(BeTrue())
+
+		tokens, err := c.LoginWithCaptcha(ctx, "jane@example.com", "secret", "solved")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tokens.AccessToken).To(Equal("access-1"))
+	})
+
+	It("refreshes the access token once on 401 and replays the call", func() {
+		var refreshed []client.Tokens
+		c = client.New(server.URL,
+			client.WithTokens(client.Tokens{AccessToken: "expired", RefreshToken: "refresh-1"}),
+			client.WithTokenCallback(func(t client.Tokens) { refreshed = append(refreshed, t) }),
+		)
+
+		mux.HandleFunc("POST /api/v1/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, client.Tokens{AccessToken: "access-2", RefreshToken: "refresh-2"})
+		})
+		mux.HandleFunc("GET /api/v1/expenses/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer access-2" {
+				writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"code": 401, "message": "invalid token"})
+				return
+			}
+			writeJSON(w, http.StatusOK, client.Expense{ID: 42})
+		})
+
+		e, err := c.GetExpense(ctx, 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.ID).To(Equal(int64(42)))
+		Expect(refreshed).To(HaveLen(1))
+		Expect(c.Tokens().RefreshToken).To(Equal("refresh-2"))
+	})
+
+	It("retries 429 responses", func() {
+		var calls int32
+		mux.HandleFunc("POST /api/v1/payment/retry", func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+					"error": map[string]string{"type": "RATE_LIMITED", "code": "PAYMENT_QUEUE_FULL", "message": "queue full"},
+				})
+				return
+			}
+			writeJSON(w, http.StatusOK, client.PaymentRetryResponse{Status: "payment retry initiated", ExpenseID: "1"})
+		})
+
+		resp, err := c.RetryPayment(ctx, 1, "exp-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.ExpenseID).To(Equal("1"))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	})
+
+	It("does not retry non-idempotent calls on server errors", func() {
+		var calls int32
+		mux.HandleFunc("PATCH /api/v1/expenses/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"code": 503, "message": "unavailable"})
+		})
+
+		err := c.ApproveExpense(ctx, 1)
+		Expect(err).To(HaveOccurred())
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+	})
+
+	It("decodes application errors", func() {
+		mux.HandleFunc("POST /api/v1/expenses", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error": map[string]string{"type": "VALIDATION_ERROR", "code": "AMOUNT_TOO_LOW", "message": "amount too low"},
+			})
+		})
+
+		_, err := c.CreateExpense(ctx, client.CreateExpenseRequest{AmountIDR: 1})
+
+		var apiErr *client.APIError
+		Expect(err).To(BeAssignableToTypeOf(apiErr))
+		apiErr = err.(*client.APIError)
+		Expect(apiErr.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(apiErr.Code).To(Equal("AMOUNT_TOO_LOW"))
+		Expect(apiErr.Message).To(Equal("amount too low"))
+	})
+
+	It("iterates over every page", func() {
+		mux.HandleFunc("GET /api/v1/expenses", func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			Expect(r.URL.Query().Get("status")).To(Equal("approved"))
+
+			var expenses []*client.Expense
+			for i := 0; i < 2 && (page-1)*2+i < 5; i++ {
+				expenses = append(expenses, &client.Expense{ID: int64((page-1)*2 + i + 1)})
+			}
+			writeJSON(w, http.StatusOK, client.ExpensePage{Expenses: expenses, Page: page, PerPage: 2, TotalData: 5})
+		})
+
+		it := c.Expenses(ctx, client.ListExpensesParams{PerPage: 2, Status: "approved"})
+		var ids []int64
+		for it.Next() {
+			ids = append(ids, it.Expense().ID)
+		}
+
+		Expect(it.Err()).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int64{1, 2, 3, 4, 5}))
+	})
+})
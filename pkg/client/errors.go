@@ -0,0 +1,95 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIError is returned for every non-2xx response.
+type APIError struct {
+	StatusCode int
+	// Type and Code are set for application errors, e.g. VALIDATION_ERROR /
+	// AMOUNT_TOO_LOW. Plain HTTP errors only carry a message.
+	Type       string
+	Code       string
+	Message    string
+	Details    json.RawMessage
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool { return hasStatus(err, http.StatusNotFound) }
+
+// IsForbidden reports whether err is a 403 from the API.
+func IsForbidden(err error) bool { return hasStatus(err, http.StatusForbidden) }
+
+// IsUnauthorized reports whether err is a 401 from the API.
+func IsUnauthorized(err error) bool { return hasStatus(err, http.StatusUnauthorized) }
+
+// IsCaptchaRequired reports whether a login was refused until a CAPTCHA
+// token is sent with LoginWithCaptcha.
+func IsCaptchaRequired(err error) bool { return hasStatus(err, http.StatusPreconditionRequired) }
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return asAPIError(err, &apiErr) && apiErr.StatusCode == status
+}
+
+func asAPIError(err error, target **APIError) bool {
+	return err != nil && errors.As(err, target)
+}
+
+// newAPIError understands both error bodies the server writes:
+// {"code": 400, "message": "..."} and {"error": {"type", "code", "message"}}
+// (the webhook endpoint uses {"error": "..."}).
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	var envelope struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return apiErr
+	}
+	if envelope.Message != "" {
+		apiErr.Message = envelope.Message
+	}
+
+	var appErr struct {
+		Type    string          `json:"type"`
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+	}
+	if len(envelope.Error) > 0 {
+		if json.Unmarshal(envelope.Error, &appErr) == nil {
+			apiErr.Type = appErr.Type
+			apiErr.Code = appErr.Code
+			apiErr.Details = appErr.Details
+			if appErr.Message != "" {
+				apiErr.Message = appErr.Message
+			}
+		} else {
+			var msg string
+			if json.Unmarshal(envelope.Error, &msg) == nil && msg != "" {
+				apiErr.Message = msg
+			}
+		}
+	}
+	return apiErr
+}
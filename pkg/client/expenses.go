@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) CreateExpense(ctx context.Context, req CreateExpenseRequest) (*Expense, error) {
+	var e Expense
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/expenses", body: req, auth: true}, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (c *Client) GetExpense(ctx context.Context, id int64) (*Expense, error) {
+	var e Expense
+	if err := c.do(ctx, request{method: http.MethodGet, path: expensePath(id), auth: true}, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ListExpenses returns a single page of the expenses visible to the caller.
+func (c *Client) ListExpenses(ctx context.Context, params ListExpensesParams) (*ExpensePage, error) {
+	var page ExpensePage
+	err := c.do(ctx, request{method: http.MethodGet, path: "/expenses", query: params.values(), auth: true}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *Client) ApproveExpense(ctx context.Context, id int64) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: expensePath(id) + "/approve", auth: true}, nil)
+}
+
+// RejectExpense rejects with one of the server's reason codes
+// (policy_violation, missing_receipt, duplicate or other) and a comment.
+func (c *Client) RejectExpense(ctx context.Context, id int64, code, reason string) error {
+	return c.do(ctx, request{
+		method: http.MethodPatch,
+		path:   expensePath(id) + "/reject",
+		body:   map[string]string{"code": code, "reason": reason},
+		auth:   true,
+	}, nil)
+}
+
+// RetryPayment re-queues the payment of an approved expense. A full payment
+// queue (429) is retried automatically with the server's Retry-After.
+func (c *Client) RetryPayment(ctx context.Context, expenseID int64, externalID string) (*PaymentRetryResponse, error) {
+	var resp PaymentRetryResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/payment/retry",
+		body: map[string]string{
+			"expense_id":  strconv.FormatInt(expenseID, 10),
+			"external_id": externalID,
+		},
+		auth: true,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetPaymentJob(ctx context.Context, externalID string) (*PaymentJob, error) {
+	var job PaymentJob
+	path := "/payments/jobs/" + url.PathEscape(externalID)
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, auth: true}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Expenses returns an iterator over every expense matching params, fetching
+// pages lazily starting at params.Page.
+func (c *Client) Expenses(ctx context.Context, params ListExpensesParams) *ExpenseIterator {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	return &ExpenseIterator{ctx: ctx, client: c, params: params}
+}
+
+// ExpenseIterator walks a paginated expense list:
+//
+//	for it.Next() { use(it.Expense()) }
+//	if err := it.Err(); err != nil { ... }
+type ExpenseIterator struct {
+	ctx     context.Context
+	client  *Client
+	params  ListExpensesParams
+	page    *ExpensePage
+	index   int
+	current *Expense
+	err     error
+	done    bool
+}
+
+func (it *ExpenseIterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+
+	for it.page == nil || it.index >= len(it.page.Expenses) {
+		if it.page != nil {
+			if !it.page.HasNext() || len(it.page.Expenses) == 0 {
+				it.done = true
+				return false
+			}
+			it.params.Page = it.page.Page + 1
+		}
+
+		page, err := it.client.ListExpenses(it.ctx, it.params)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+		it.index = 0
+	}
+
+	it.current = it.page.Expenses[it.index]
+	it.index++
+	return true
+}
+
+func (it *ExpenseIterator) Expense() *Expense { return it.current }
+
+func (it *ExpenseIterator) Err() error { return it.err }
+
+func expensePath(id int64) string {
+	return "/expenses/" + strconv.FormatInt(id, 10)
+}
+
+func (p ListExpensesParams) values() url.Values {
+	q := url.Values{}
+	if p.Page > 0 {
+		q.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(p.PerPage))
+	}
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	set("search", p.Search)
+	set("category_id", p.CategoryID)
+	set("status", p.Status)
+	set("sort_by", p.SortBy)
+	set("sort_order", p.SortOrder)
+	if !p.DateFrom.IsZero() {
+		q.Set("date_from", p.DateFrom.Format("2006-01-02"))
+	}
+	if !p.DateTo.IsZero() {
+		q.Set("date_to", p.DateTo.Format("2006-01-02"))
+	}
+	if p.MinAmount > 0 {
+		q.Set("min_amount", strconv.FormatInt(p.MinAmount, 10))
+	}
+	if p.MaxAmount > 0 {
+		q.Set("max_amount", strconv.FormatInt(p.MaxAmount, 10))
+	}
+	return q
+}
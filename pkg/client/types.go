@@ -0,0 +1,112 @@
+package client
+
+import "time"
+
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type User struct {
+	ID          int64     `json:"id"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	Department  string    `json:"department"`
+	IsActive    bool      `json:"is_active"`
+	Permissions []string  `json:"permissions,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Category struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+const (
+	ExpenseStatusPendingApproval = "pending_approval"
+	ExpenseStatusApproved        = "approved"
+	ExpenseStatusRejected        = "rejected"
+	ExpenseStatusCompleted       = "completed"
+)
+
+type Expense struct {
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"user_id"`
+	AmountIDR       int64      `json:"amount_idr"`
+	Description     string     `json:"description"`
+	Category        string     `json:"category"`
+	ReceiptURL      *string    `json:"receipt_url,omitempty"`
+	ReceiptFileName *string    `json:"receipt_filename,omitempty"`
+	ExpenseStatus   string     `json:"expense_status"`
+	RejectionCode   *string    `json:"rejection_code,omitempty"`
+	RejectionReason *string    `json:"rejection_reason,omitempty"`
+	ApprovedBy      *int64     `json:"approved_by,omitempty"`
+	RejectedBy      *int64     `json:"rejected_by,omitempty"`
+	ExpenseDate     time.Time  `json:"expense_date"`
+	SubmittedAt     time.Time  `json:"submitted_at"`
+	ProcessedAt     *time.Time `json:"processed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type CreateExpenseRequest struct {
+	AmountIDR       int64     `json:"amount_idr"`
+	Description     string    `json:"description"`
+	Category        string    `json:"category"`
+	ExpenseDate     time.Time `json:"expense_date"`
+	ReceiptURL      *string   `json:"receipt_url,omitempty"`
+	ReceiptFileName *string   `json:"receipt_filename,omitempty"`
+}
+
+// ListExpensesParams mirrors the GET /expenses query string. Zero values are
+// omitted and fall back to the server defaults (page 1, 20 per page).
+type ListExpensesParams struct {
+	Page       int
+	PerPage    int
+	Search     string
+	CategoryID string
+	Status     string
+	SortBy     string
+	SortOrder  string
+	// DateFrom and DateTo filter on the expense date (days, inclusive).
+	DateFrom  time.Time
+	DateTo    time.Time
+	MinAmount int64
+	MaxAmount int64
+}
+
+type ExpensePage struct {
+	Expenses  []*Expense `json:"expenses"`
+	Page      int        `json:"page"`
+	PerPage   int        `json:"per_page"`
+	TotalData int64      `json:"total_data"`
+	Search    string     `json:"search"`
+	Status    string     `json:"status"`
+	SortBy    string     `json:"sort_by"`
+	SortOrder string     `json:"sort_order"`
+}
+
+// HasNext reports whether more pages follow this one.
+func (p *ExpensePage) HasNext() bool {
+	return int64(p.Page*p.PerPage) < p.TotalData
+}
+
+type PaymentRetryResponse struct {
+	Status     string `json:"status"`
+	ExpenseID  string `json:"expense_id"`
+	ExternalID string `json:"external_id"`
+}
+
+type PaymentJob struct {
+	ExternalID  string     `json:"external_id"`
+	Status      string     `json:"status"`
+	WorkerID    *int       `json:"worker_id,omitempty"`
+	Attempts    int        `json:"attempts"`
+	LastError   *string    `json:"last_error,omitempty"`
+	QueuedAt    time.Time  `json:"queued_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
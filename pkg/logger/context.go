@@ -22,3 +22,23 @@ func From(ctx context.Context) *slog.Logger {
 	}
 	return LoggerWrapper()
 }
+
+// NewContext returns a copy of ctx carrying l as the request logger.
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromOr returns the logger stored in context, or fallback if missing.
+// Services use it so request correlation fields survive into their logs while
+// background callers keep the service's own logger.
+func FromOr(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+			return l
+		}
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return LoggerWrapper()
+}